@@ -0,0 +1,49 @@
+#!/usr/bin/env node
+
+// Entry point of `npx dev-env-sentinel`: runs the binary for this platform with the configs
+// bundled in the package. With no arguments the binary starts the MCP server on stdio.
+
+const { spawn } = require('child_process');
+const fs = require('fs');
+const path = require('path');
+const { currentPlatform } = require('../scripts/platforms');
+const postinstall = require('../scripts/postinstall');
+
+const PACKAGE_ROOT = path.join(__dirname, '..');
+
+async function run() {
+  const platform = currentPlatform();
+  if (!platform) {
+    console.error(`dev-env-sentinel: unsupported platform ${process.platform} ${process.arch}`);
+    process.exit(1);
+  }
+
+  const binaryPath = path.join(__dirname, platform.binary);
+  if (!fs.existsSync(binaryPath)) {
+    // Install scripts are skipped with --ignore-scripts, and by some npx setups
+    await postinstall.main();
+  }
+
+  // The MCP client's working directory is kept; configs are found through SENTINEL_CONFIG_DIR
+  const env = { ...process.env };
+  if (!env.SENTINEL_CONFIG_DIR) {
+    env.SENTINEL_CONFIG_DIR = PACKAGE_ROOT;
+  }
+
+  const child = spawn(binaryPath, process.argv.slice(2), { stdio: 'inherit', env });
+  for (const signal of ['SIGINT', 'SIGTERM', 'SIGHUP']) {
+    process.on(signal, () => child.kill(signal));
+  }
+  child.on('error', error => {
+    console.error(`dev-env-sentinel: failed to start ${binaryPath}: ${error.message}`);
+    process.exit(1);
+  });
+  child.on('exit', (code, signal) => {
+    process.exit(signal ? 1 : code);
+  });
+}
+
+run().catch(error => {
+  console.error(`dev-env-sentinel: ${error.message}`);
+  process.exit(1);
+});
@@ -0,0 +1,638 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/doctor"
+	"dev-env-sentinel/internal/i18n"
+	"dev-env-sentinel/internal/license"
+	"dev-env-sentinel/internal/machine"
+	"dev-env-sentinel/internal/marketplace"
+	"dev-env-sentinel/internal/mcp"
+	"dev-env-sentinel/internal/profile"
+	"dev-env-sentinel/internal/redact"
+	"dev-env-sentinel/internal/report"
+	"dev-env-sentinel/internal/runner"
+	"dev-env-sentinel/internal/settings"
+	"dev-env-sentinel/internal/state"
+	"dev-env-sentinel/internal/storage"
+	"dev-env-sentinel/internal/templates"
+)
+
+// Exit codes used by CLI commands
+const (
+	exitOK     = 0
+	exitIssues = 1
+	exitUsage  = 2
+)
+
+// defaultChecks are the read-only checks run by `sentinel check` when none are named
+var defaultChecks = []string{"verify_build_freshness", "check_infrastructure_parity", "env_var_audit"}
+
+// runCLIMode runs a CLI command and returns the process exit code
+func runCLIMode(args []string, stdout, stderr io.Writer) int {
+	switch args[0] {
+	case "check":
+		return runCheckCommand(args[1:], stdout, stderr)
+	case "cleanup":
+		return runCleanupCommand(args[1:], stdout, stderr)
+	case "completion":
+		return runCompletionCommand(args[1:], stdout, stderr)
+	case "config":
+		return runConfigCommand(args[1:], stdout, stderr)
+	case "doctor":
+		return runDoctorCommand(args[1:], stdout, stderr)
+	case "license":
+		return runLicenseCommand(args[1:], stdout, stderr)
+	case "lsp":
+		return runLSPCommand(args[1:], os.Stdin, stdout, stderr)
+	case "man":
+		return runManCommand(args[1:], stdout, stderr)
+	case "self-update":
+		return runSelfUpdateCommand(args[1:], stdout, stderr)
+	case "state":
+		return runStateCommand(args[1:], stdout, stderr)
+	case "telemetry":
+		return runTelemetryCommand(args[1:], stdout, stderr)
+	case "tui":
+		return runTUICommand(args[1:], os.Stdin, stdout, stderr)
+	case "version", "--version":
+		return runVersionCommand(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		printUsage(stdout)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "unknown command: %s\n\n", args[0])
+		printUsage(stderr)
+		return exitUsage
+	}
+}
+
+// printUsage prints CLI usage
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, `Usage:
+  sentinel                      Start the MCP server
+  sentinel check [flags] [tool...]
+                                Run checks once and report the results
+  sentinel cleanup [flags] [category...]
+                                Clear cached data from the state directories
+  sentinel completion SHELL     Print the completion script for bash, zsh, fish or powershell
+  sentinel config dump ID       Print an ecosystem config with the configs it extends merged in
+  sentinel config list [--project-root DIR] [--format text|json]
+                                List loaded configs, their source files and detection rules
+  sentinel config add [--index URL] [NAME]
+                                Install a community config into the user config layer, or list them
+  sentinel config sign-index --private-key FILE INDEX
+                                Update the checksums of a config index and sign it
+  sentinel doctor [--format text|json]
+                                Check that the sentinel itself is set up correctly
+  sentinel license generate --tier TIER --private-key FILE [--expires DATE] [--features LIST]
+                                Generate a license key for a self-hosted deployment
+  sentinel license keygen --private-key FILE
+                                Create a key pair for signing license keys
+  sentinel lsp [--suite SUITE] [tool...]
+                                Publish issues as LSP diagnostics over stdio for editors
+  sentinel man                  Print the man page
+  sentinel self-update [--check] [--force]
+                                Replace this binary with the latest release
+  sentinel state export [--output FILE]
+                                Export snapshots and check history as JSON
+  sentinel state import FILE    Import snapshots and check history from an export
+  sentinel telemetry status      Show whether telemetry is on, where it is sent and how much is queued
+  sentinel telemetry enable      Opt in to anonymous usage statistics
+  sentinel telemetry disable     Opt out and delete the queued statistics
+  sentinel telemetry show        Print the queued statistics as JSON
+  sentinel tui [--project-root DIR] [--interval 30s] [tool...]
+                                Browse checks and run fixes in an interactive terminal UI
+  sentinel version [--format text|json]
+                                Print the build version and commit
+
+Check flags:
+  --project-root DIR            Project to check (default ".")
+  --format FORMAT               Output format: text, json, github, gitlab (default "text")
+  --suite SUITE                 Check suite: quick, full, pre-commit or one named in configs (default: full)
+  --lang LANG                   Output language, e.g. de; C or en.ascii for ASCII-only output (default: $SENTINEL_LANG)
+  --template NAME               Text output template from the output.templates directory, or default
+  --verbosity LEVEL             Text output detail: summary, normal or detailed (default "normal")
+  --executor SPEC               Where commands run: local, docker:CONTAINER or ssh:HOST (default: execution.executor of the settings)
+
+Default checks: verify_build_freshness, check_infrastructure_parity, env_var_audit
+
+Cleanup flags:
+  --project-root DIR            Also clean the project's .sentinel directory
+  --dry-run                     Show what would be removed without removing it
+
+Cleanup categories: cache, snapshots, logs, history (default: all)
+
+State is stored in SQLite (sentinel.db); set SENTINEL_STATE_BACKEND=files for JSON files
+`)
+}
+
+// runCheckCommand runs checks against a project and renders the results in the requested format.
+// It exits non-zero when any check fails or reports an unhealthy environment.
+func runCheckCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	projectRoot := flags.String("project-root", ".", "project to check")
+	format := flags.String("format", "text", "output format: text, json, github, gitlab")
+	suite := flags.String("suite", "", "check suite to run, e.g. quick, full, pre-commit")
+	lang := flags.String("lang", os.Getenv(i18n.EnvVar), "output language and charset, e.g. de or C for ASCII-only")
+	tmpl := flags.String("template", "", "output template for text format (default: output.template of the settings)")
+	verbosity := flags.String("verbosity", mcp.VerbosityNormal, "text output detail: summary, normal or detailed")
+	executor := flags.String("executor", "", "where commands run: local, docker:CONTAINER or ssh:HOST")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	switch *format {
+	case "text", "json", "github", "gitlab":
+	default:
+		fmt.Fprintf(stderr, "unknown format: %s (expected text, json, github or gitlab)\n", *format)
+		return exitUsage
+	}
+
+	if err := mcp.ValidateVerbosity(*verbosity); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if _, err := runner.ParseExecutor(*executor); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	root, err := filepath.Abs(*projectRoot)
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid project root: %v\n", err)
+		return exitUsage
+	}
+
+	server, configs, _, err := newCheckServer()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if *suite != "" {
+		if err := config.ValidateSuite(configs, *suite); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+
+	checks := flags.Args()
+	if len(checks) == 0 {
+		checks = defaultChecks
+	}
+
+	healthy := true
+	findings := []report.Finding{}
+	for _, check := range checks {
+		checkArgs := map[string]interface{}{
+			"project_root": root,
+		}
+		if *suite != "" {
+			checkArgs["suite"] = *suite
+		}
+		if *tmpl != "" {
+			checkArgs["template"] = *tmpl
+		}
+		if *verbosity != mcp.VerbosityNormal {
+			checkArgs["verbosity"] = *verbosity
+		}
+		if *executor != "" {
+			checkArgs["executor"] = *executor
+		}
+		result, err := server.CallTool(context.Background(), check, checkArgs)
+		if err != nil {
+			healthy = false
+			fmt.Fprintf(stderr, "%s failed: %v\n", check, err)
+			findings = append(findings, report.Finding{Check: check, Severity: report.SeverityError, Message: err.Error()})
+			continue
+		}
+
+		if !mcp.IsHealthyResult(result) {
+			healthy = false
+		}
+		findings = append(findings, report.Collect(check, root, result)...)
+
+		if *format == "text" {
+			checkArgs["lang"] = *lang
+			fmt.Fprintf(stdout, "%s\n\n", server.RenderResult(check, checkArgs, result))
+		}
+	}
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(findings)
+	case "github":
+		err = report.WriteGitHub(stdout, findings)
+	case "gitlab":
+		err = report.WriteGitLab(stdout, findings)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "error writing output: %v\n", err)
+		return exitIssues
+	}
+
+	if !healthy {
+		return exitIssues
+	}
+	return exitOK
+}
+
+// newCheckServer loads the configs and settings and returns a server running checks under
+// the same tool profile as the MCP server
+func newCheckServer() (*mcp.Server, []*config.EcosystemConfig, *settings.ServerSettings, error) {
+	baseDir := getConfigBaseDir()
+	configs, err := config.DiscoverEcosystemConfigs(baseDir)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error loading configs from %s: %v", baseDir, err)
+	}
+
+	serverSettings, err := settings.Discover(baseDir)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error loading server settings: %v", err)
+	}
+	policy, err := profile.Resolve(serverSettings.Tools.Profile, serverSettings.Tools.Disabled)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error configuring tools: %v", err)
+	}
+
+	set, err := loadTemplates(baseDir, serverSettings.Output)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error loading output templates: %v", err)
+	}
+
+	server := mcp.NewServer()
+	server.SetToolPolicy(policy)
+	server.SetHeadroom(serverSettings.Headroom.Policy())
+	server.SetFixPolicy(serverSettings.FixPolicy.Policy())
+	server.SetFixLoopLimits(serverSettings.FixPolicy.LoopLimits())
+	server.SetExecutor(serverSettings.Execution.CommandExecutor())
+	redact.Configure(serverSettings.Redaction.Options()) // Validated with the settings
+	server.SetFeatureFlags(serverSettings.Features)
+	symbols, _ := i18n.ParseSymbols(serverSettings.Output.Symbols)
+	server.SetSymbols(symbols)
+	server.SetTemplates(set, serverSettings.Output.Template)
+	mcp.RegisterAllTools(server, configs)
+	return server, configs, serverSettings, nil
+}
+
+// loadTemplates loads the output templates directory of the server settings, if one is set.
+// A relative directory is resolved against the config base directory.
+func loadTemplates(baseDir string, output settings.Output) (*templates.Set, error) {
+	if output.Templates == "" {
+		return nil, nil
+	}
+	dir := output.Templates
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(baseDir, dir)
+	}
+	set, err := templates.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	if output.Template != "" && !set.Has(output.Template) {
+		return nil, fmt.Errorf("output.template %q not found in %s", output.Template, dir)
+	}
+	return set, nil
+}
+
+// runCleanupCommand purges state categories from the user and project state directories
+func runCleanupCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("cleanup", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	projectRoot := flags.String("project-root", "", "also clean this project's .sentinel directory")
+	dryRun := flags.Bool("dry-run", false, "show what would be removed")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	stateDir, err := state.Open(state.DefaultRoot())
+	if err != nil {
+		fmt.Fprintf(stderr, "error opening state directory: %v\n", err)
+		return exitIssues
+	}
+
+	server := mcp.NewServer()
+	mcp.RegisterAllTools(server, nil)
+	server.SetState(stateDir)
+
+	categories := make([]interface{}, 0, flags.NArg())
+	for _, category := range flags.Args() {
+		categories = append(categories, category)
+	}
+
+	result, err := server.CallTool(context.Background(), "purge_state", map[string]interface{}{
+		"categories":   categories,
+		"project_root": *projectRoot,
+		"dry_run":      *dryRun,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return exitUsage
+	}
+
+	fmt.Fprint(stdout, i18n.FromEnv().Localize(mcp.FormatResult(result)))
+	return exitOK
+}
+
+// runStateCommand exports or imports the snapshots and check history of the user state directory
+func runStateCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || (args[0] != "export" && args[0] != "import") {
+		fmt.Fprintln(stderr, "usage: sentinel state export [--output FILE] | sentinel state import FILE")
+		return exitUsage
+	}
+
+	flags := flag.NewFlagSet("state "+args[0], flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	output := flags.String("output", "", "write the export to FILE instead of stdout")
+	if err := flags.Parse(args[1:]); err != nil {
+		return exitUsage
+	}
+	if args[0] == "import" && flags.NArg() != 1 {
+		fmt.Fprintln(stderr, "usage: sentinel state import FILE")
+		return exitUsage
+	}
+
+	stateDir, err := state.Open(state.DefaultRoot())
+	if err != nil {
+		fmt.Fprintf(stderr, "error opening state directory: %v\n", err)
+		return exitIssues
+	}
+	store, err := storage.Open(stateDir)
+	if err != nil {
+		fmt.Fprintf(stderr, "error opening state store: %v\n", err)
+		return exitIssues
+	}
+	defer store.Close()
+
+	if args[0] == "import" {
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			fmt.Fprintf(stderr, "error reading export: %v\n", err)
+			return exitIssues
+		}
+		defer file.Close()
+
+		export, err := storage.ReadExport(file)
+		if err == nil {
+			err = storage.ImportStore(store, export)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "error importing state: %v\n", err)
+			return exitIssues
+		}
+		fmt.Fprintf(stdout, "Imported %d check result(s) and %d snapshot entries\n", len(export.History), len(export.Latest))
+		if export.Machine != nil {
+			// Results from another platform may differ for reasons no configuration explains
+			if diffs := machine.Current().Differences(export.Machine); len(diffs) > 0 {
+				fmt.Fprintf(stdout, "Exported on a different platform: %s\n", strings.Join(diffs, ", "))
+			}
+		}
+		return exitOK
+	}
+
+	export, err := storage.ExportStore(store)
+	if err != nil {
+		fmt.Fprintf(stderr, "error exporting state: %v\n", err)
+		return exitIssues
+	}
+
+	w := stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(stderr, "error writing export: %v\n", err)
+			return exitIssues
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := storage.WriteExport(w, export); err != nil {
+		fmt.Fprintf(stderr, "error writing export: %v\n", err)
+		return exitIssues
+	}
+	return exitOK
+}
+
+// runConfigCommand prints the resolved form of an ecosystem config, for debugging `extends`
+func runConfigCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "list":
+			return runConfigListCommand(args[1:], stdout, stderr)
+		case "add":
+			return runConfigAddCommand(args[1:], stdout, stderr)
+		case "sign-index":
+			return runConfigSignIndexCommand(args[1:], stdout, stderr)
+		}
+	}
+	if len(args) != 2 || args[0] != "dump" {
+		fmt.Fprintln(stderr, "usage: sentinel config dump ID | sentinel config list [--project-root DIR] [--format text|json] | sentinel config add [--index URL] [NAME] | sentinel config sign-index --private-key FILE INDEX")
+		return exitUsage
+	}
+
+	baseDir := getConfigBaseDir()
+	configs, err := config.DiscoverEcosystemConfigs(baseDir)
+	if err != nil {
+		fmt.Fprintf(stderr, "error loading configs from %s: %v\n", baseDir, err)
+		return exitUsage
+	}
+	for _, c := range configs {
+		if c.Ecosystem.ID != args[1] {
+			continue
+		}
+		data, err := c.Dump()
+		if err != nil {
+			fmt.Fprintf(stderr, "error dumping config: %v\n", err)
+			return exitIssues
+		}
+		stdout.Write(data)
+		return exitOK
+	}
+
+	fmt.Fprintf(stderr, "unknown ecosystem: %s (run `sentinel doctor` to list configs that failed to load)\n", args[1])
+	return exitUsage
+}
+
+// runConfigListCommand lists the loaded configs with their sources and detection rules
+func runConfigListCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("config list", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	projectRoot := flags.String("project-root", "", "also show which ecosystems are detected in this project")
+	format := flags.String("format", "text", "output format: text, json")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "unknown format: %s (expected text or json)\n", *format)
+		return exitUsage
+	}
+
+	baseDir := getConfigBaseDir()
+	configs, err := config.DiscoverEcosystemConfigs(baseDir)
+	if err != nil {
+		fmt.Fprintf(stderr, "error loading configs from %s: %v\n", baseDir, err)
+		return exitUsage
+	}
+
+	server := mcp.NewServer()
+	mcp.RegisterAllTools(server, configs)
+	listArgs := map[string]interface{}{}
+	if *projectRoot != "" {
+		root, err := filepath.Abs(*projectRoot)
+		if err != nil {
+			fmt.Fprintf(stderr, "invalid project root: %v\n", err)
+			return exitUsage
+		}
+		listArgs["project_root"] = root
+	}
+	result, err := server.CallTool(context.Background(), "list_configs", listArgs)
+	if err != nil {
+		fmt.Fprintf(stderr, "list_configs failed: %v\n", err)
+		return exitIssues
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			fmt.Fprintf(stderr, "error writing output: %v\n", err)
+			return exitIssues
+		}
+		return exitOK
+	}
+	fmt.Fprint(stdout, i18n.FromEnv().Localize(mcp.FormatResult(result)))
+	return exitOK
+}
+
+// runConfigAddCommand installs a community config into the user config layer, or without a
+// name lists the configs of the index
+func runConfigAddCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("config add", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	index := flags.String("index", "", "fetch from this index URL or directory instead of "+marketplace.IndexEnvVar+" or the community index")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() > 1 {
+		fmt.Fprintln(stderr, "usage: sentinel config add [--index URL] [NAME]")
+		return exitUsage
+	}
+
+	server := mcp.NewServer()
+	mcp.RegisterAllTools(server, nil)
+	fetchArgs := map[string]interface{}{"index": *index, "name": flags.Arg(0)}
+	result, err := server.CallTool(context.Background(), "fetch_config", fetchArgs)
+	if err != nil {
+		fmt.Fprintf(stderr, "fetch_config failed: %v\n", err)
+		return exitIssues
+	}
+	fmt.Fprintln(stdout, i18n.FromEnv().Localize(mcp.FormatResult(result)))
+	return exitOK
+}
+
+// runConfigSignIndexCommand updates the checksums of a config index manifest and signs it, for
+// maintainers of the community index or a team's own
+func runConfigSignIndexCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("config sign-index", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	privateKeyFile := flags.String("private-key", "", "sign with the private key in FILE, created by sentinel license keygen")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *privateKeyFile == "" || flags.NArg() != 1 {
+		fmt.Fprintln(stderr, "usage: sentinel config sign-index --private-key FILE INDEX")
+		return exitUsage
+	}
+
+	data, err := os.ReadFile(*privateKeyFile)
+	if err != nil {
+		fmt.Fprintf(stderr, "error reading private key: %v\n", err)
+		return exitUsage
+	}
+	privateKey, err := license.ParsePrivateKey(string(data))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	index, err := marketplace.SignIndex(flags.Arg(0), privateKey)
+	if err != nil {
+		fmt.Fprintf(stderr, "error signing %s: %v\n", flags.Arg(0), err)
+		return exitIssues
+	}
+	fmt.Fprintf(stdout, "Signed %s with %d configs\n", flags.Arg(0), len(index.Configs))
+	return exitOK
+}
+
+// runDoctorCommand checks the sentinel's own prerequisites and prints a readiness report
+func runDoctorCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", "text", "output format: text, json")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "unknown format: %s (expected text or json)\n", *format)
+		return exitUsage
+	}
+
+	result := doctor.Run(context.Background(), doctor.Options{
+		ConfigBaseDir: getConfigBaseDir(),
+		StateRoot:     state.DefaultRoot(),
+	})
+
+	if *format == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(result)
+	} else {
+		fmt.Fprint(stdout, formatDoctorReport(result))
+	}
+
+	if !result.IsReady {
+		return exitIssues
+	}
+	return exitOK
+}
+
+// formatDoctorReport formats a doctor readiness report
+func formatDoctorReport(result *doctor.Report) string {
+	msg := ""
+	for _, check := range result.Checks {
+		symbol := "✅"
+		switch check.Status {
+		case doctor.StatusWarn:
+			symbol = "⚠️"
+		case doctor.StatusFail:
+			symbol = "❌"
+		}
+
+		lines := strings.Split(check.Message, "\n")
+		msg += fmt.Sprintf("%s %s: %s\n", symbol, check.Name, lines[0])
+		for _, line := range lines[1:] {
+			msg += fmt.Sprintf("   %s\n", line)
+		}
+		if check.Hint != "" {
+			msg += fmt.Sprintf("   Hint: %s\n", check.Hint)
+		}
+	}
+
+	if result.IsReady {
+		msg += "\nSentinel is ready.\n"
+	} else {
+		msg += "\nSentinel is not ready; fix the failures above.\n"
+	}
+	return msg
+}
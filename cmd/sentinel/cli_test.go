@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"dev-env-sentinel/internal/license"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCLIMode_Usage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitOK, runCLIMode([]string{"help"}, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "sentinel check")
+
+	assert.Equal(t, exitUsage, runCLIMode([]string{"bogus"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "unknown command: bogus")
+
+	stderr.Reset()
+	assert.Equal(t, exitUsage, runCLIMode([]string{"check", "--format", "xml"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "unknown format: xml")
+}
+
+// javaOnlyConfigDir creates a config base directory containing only the Java language config
+func javaOnlyConfigDir(t *testing.T) string {
+	content, err := os.ReadFile(filepath.Join("..", "..", "config", "languages", "java.yaml"))
+	require.NoError(t, err)
+
+	baseDir := t.TempDir()
+	languagesDir := filepath.Join(baseDir, "config", "languages")
+	require.NoError(t, os.MkdirAll(languagesDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(languagesDir, "java.yaml"), content, 0644))
+	return baseDir
+}
+
+func TestRunCheckCommand_GitHubFormat(t *testing.T) {
+	t.Setenv("SENTINEL_CONFIG_DIR", javaOnlyConfigDir(t))
+
+	project := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(project, "src"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "pom.xml"), []byte("<project/>"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "src", "App.java"),
+		[]byte("class App {\n  String key = System.getenv(\"SENTINEL_CLI_TEST_UNSET\");\n}\n"), 0644))
+
+	var stdout, stderr bytes.Buffer
+	code := runCLIMode([]string{"check", "--project-root", project, "--format", "github", "env_var_audit"}, &stdout, &stderr)
+
+	assert.Equal(t, exitIssues, code)
+	assert.Contains(t, stdout.String(), "::error file=src/App.java,title=sentinel%3A env_var_audit,line=2::Environment variable SENTINEL_CLI_TEST_UNSET is not set")
+}
+
+func TestRunCheckCommand_HealthyGitLab(t *testing.T) {
+	t.Setenv("SENTINEL_CONFIG_DIR", javaOnlyConfigDir(t))
+
+	var stdout, stderr bytes.Buffer
+	code := runCLIMode([]string{"check", "--project-root", t.TempDir(), "--format", "gitlab", "env_var_audit"}, &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code, stderr.String())
+	assert.Equal(t, "[]\n", stdout.String())
+}
+
+func TestRunCheckCommand_Template(t *testing.T) {
+	baseDir := javaOnlyConfigDir(t)
+	t.Setenv("SENTINEL_CONFIG_DIR", baseDir)
+	t.Setenv("SENTINEL_LANG", "")
+	require.NoError(t, os.MkdirAll(filepath.Join(baseDir, "templates"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "templates", "acme.env_var_audit.tmpl"), []byte("ACME env: {{len .Result.Missing}} missing"), 0644))
+	settingsPath := filepath.Join(baseDir, "sentinel.yaml")
+	require.NoError(t, os.WriteFile(settingsPath, []byte("output:\n  templates: templates\n"), 0644))
+	t.Setenv("SENTINEL_SETTINGS", settingsPath)
+
+	var stdout, stderr bytes.Buffer
+	code := runCLIMode([]string{"check", "--project-root", t.TempDir(), "--template", "acme", "env_var_audit"}, &stdout, &stderr)
+	assert.Equal(t, exitOK, code, stderr.String())
+	assert.Equal(t, "ACME env: 0 missing\n\n", stdout.String())
+
+	stdout.Reset()
+	code = runCLIMode([]string{"check", "--project-root", t.TempDir(), "--template", "compact", "env_var_audit"}, &stdout, &stderr)
+	assert.Equal(t, exitIssues, code)
+	assert.Contains(t, stderr.String(), "unknown template: compact (available: default, acme)")
+}
+
+func TestRunCleanupCommand(t *testing.T) {
+	stateRoot := t.TempDir()
+	t.Setenv("SENTINEL_STATE_DIR", stateRoot)
+	require.NoError(t, os.MkdirAll(filepath.Join(stateRoot, "cache"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(stateRoot, "cache", "item"), []byte("data"), 0644))
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitOK, runCLIMode([]string{"cleanup", "--dry-run"}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "Would remove")
+	assert.DirExists(t, filepath.Join(stateRoot, "cache"))
+
+	stdout.Reset()
+	assert.Equal(t, exitOK, runCLIMode([]string{"cleanup", "cache"}, &stdout, &stderr), stderr.String())
+	assert.NoDirExists(t, filepath.Join(stateRoot, "cache"))
+
+	assert.Equal(t, exitUsage, runCLIMode([]string{"cleanup", "license"}, &stdout, &stderr))
+}
+
+func TestRunCheckCommand_Suite(t *testing.T) {
+	t.Setenv("SENTINEL_CONFIG_DIR", javaOnlyConfigDir(t))
+	project := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	code := runCLIMode([]string{"check", "--project-root", project, "--suite", "quick", "verify_build_freshness"}, &stdout, &stderr)
+	assert.NotEqual(t, exitUsage, code, stderr.String())
+
+	assert.Equal(t, exitUsage, runCLIMode([]string{"check", "--suite", "nightly"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "unknown suite nightly")
+}
+
+func TestRunDoctorCommand(t *testing.T) {
+	t.Setenv("SENTINEL_CONFIG_DIR", javaOnlyConfigDir(t))
+	t.Setenv("SENTINEL_STATE_DIR", t.TempDir())
+	t.Setenv("SENTINEL_SETTINGS", "")
+	t.Setenv("HOME", t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	code := runCLIMode([]string{"doctor"}, &stdout, &stderr)
+
+	assert.Contains(t, stdout.String(), "✅ config directory: 1 ecosystem configs loaded")
+	assert.Contains(t, stdout.String(), "state directory")
+	if code == exitOK {
+		assert.Contains(t, stdout.String(), "Sentinel is ready.")
+	}
+
+	stdout.Reset()
+	runCLIMode([]string{"doctor", "--format", "json"}, &stdout, &stderr)
+	assert.Contains(t, stdout.String(), `"is_ready"`)
+}
+
+func TestRunConfigCommand(t *testing.T) {
+	baseDir := javaOnlyConfigDir(t)
+	t.Setenv("SENTINEL_CONFIG_DIR", baseDir)
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "config", "languages", "kotlin.yaml"), []byte(`
+ecosystem:
+  name: "Kotlin"
+  id: "kotlin"
+  extends: "java"
+`), 0644))
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitOK, runCLIMode([]string{"config", "dump", "kotlin"}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "# extends "+filepath.Join(baseDir, "config", "languages", "java.yaml"))
+	assert.Contains(t, stdout.String(), "name: Kotlin")
+	assert.Contains(t, stdout.String(), "    primary_file: '*.java'\n", "inherited from java")
+
+	assert.Equal(t, exitUsage, runCLIMode([]string{"config", "dump", "missing"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "unknown ecosystem: missing")
+	assert.Equal(t, exitUsage, runCLIMode([]string{"config"}, &stdout, &stderr))
+}
+
+func TestRunConfigAddCommand(t *testing.T) {
+	t.Setenv("SENTINEL_CONFIG_DIR", javaOnlyConfigDir(t))
+	userDir := t.TempDir()
+	t.Setenv("SENTINEL_USER_CONFIG_DIR", userDir)
+
+	// A team index signed with its own key
+	var stdout, stderr bytes.Buffer
+	keyFile := filepath.Join(t.TempDir(), "index.key")
+	require.Equal(t, exitOK, runCLIMode([]string{"license", "keygen", "--private-key", keyFile}, &stdout, &stderr), stderr.String())
+	t.Setenv("SENTINEL_CONFIG_INDEX_PUBLIC_KEY", strings.TrimSpace(stdout.String()))
+
+	index := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(index, "java-quarkus.yaml"), []byte(`
+ecosystem:
+  name: "Java Quarkus"
+  id: "java-quarkus"
+  extends: "java"
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(index, "index.json"), []byte(`{"configs": [{"name": "java-quarkus", "version": "1.0.0", "description": "Quarkus projects", "path": "java-quarkus.yaml"}]}`), 0644))
+	stdout.Reset()
+	assert.Equal(t, exitOK, runCLIMode([]string{"config", "sign-index", "--private-key", keyFile, filepath.Join(index, "index.json")}, &stdout, &stderr), stderr.String())
+	assert.Equal(t, "Signed "+filepath.Join(index, "index.json")+" with 1 configs\n", stdout.String())
+
+	stdout.Reset()
+	assert.Equal(t, exitOK, runCLIMode([]string{"config", "add", "--index", index}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "- java-quarkus 1.0.0: Quarkus projects")
+
+	stdout.Reset()
+	assert.Equal(t, exitOK, runCLIMode([]string{"config", "add", "--index", index, "java-quarkus"}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "Installed java-quarkus 1.0.0 at "+filepath.Join(userDir, "java-quarkus.yaml"))
+
+	// Later commands load it from the user config layer
+	stdout.Reset()
+	assert.Equal(t, exitOK, runCLIMode([]string{"config", "list"}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "- java-quarkus (Java Quarkus)")
+	assert.Contains(t, stdout.String(), "  Source: "+filepath.Join(userDir, "java-quarkus.yaml"))
+
+	stderr.Reset()
+	assert.Equal(t, exitIssues, runCLIMode([]string{"config", "add", "--index", index, "java-micronaut"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "java-micronaut is not in the config index")
+}
+
+func TestRunConfigListCommand(t *testing.T) {
+	t.Setenv("SENTINEL_CONFIG_DIR", javaOnlyConfigDir(t))
+	project := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(project, "pom.xml"), []byte("<project/>"), 0644))
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitOK, runCLIMode([]string{"config", "list", "--project-root", project}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "- java (Java), language")
+	assert.Contains(t, stdout.String(), ": detected (confidence")
+	assert.Contains(t, stdout.String(), "  Source: "+filepath.Join(os.Getenv("SENTINEL_CONFIG_DIR"), "config", "languages", "java.yaml"))
+
+	stdout.Reset()
+	assert.Equal(t, exitOK, runCLIMode([]string{"config", "list", "--format", "json"}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), `"kind": "language"`)
+
+	assert.Equal(t, exitUsage, runCLIMode([]string{"config", "list", "--format", "xml"}, &stdout, &stderr))
+}
+
+func TestRunStateCommand(t *testing.T) {
+	t.Setenv("SENTINEL_STATE_DIR", t.TempDir())
+	export := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(export, []byte(`{"latest":[{"job":"api","check":"env_var_audit","healthy":true}],"history":[{"job":"api","check":"env_var_audit","healthy":true}]}`), 0644))
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitOK, runCLIMode([]string{"state", "import", export}, &stdout, &stderr), stderr.String())
+	assert.FileExists(t, filepath.Join(os.Getenv("SENTINEL_STATE_DIR"), "sentinel.db"))
+
+	stdout.Reset()
+	assert.Equal(t, exitOK, runCLIMode([]string{"state", "export"}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), `"job": "api"`)
+
+	assert.Equal(t, exitUsage, runCLIMode([]string{"state"}, &stdout, &stderr))
+	assert.Equal(t, exitUsage, runCLIMode([]string{"state", "import"}, &stdout, &stderr))
+}
+
+func TestRunTelemetryCommand(t *testing.T) {
+	t.Setenv("SENTINEL_STATE_DIR", t.TempDir())
+	t.Setenv("SENTINEL_SETTINGS", "")
+	t.Setenv("SENTINEL_TELEMETRY_ENDPOINT", "")
+	t.Setenv("DO_NOT_TRACK", "")
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitOK, runCLIMode([]string{"telemetry", "status"}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "Telemetry: off\n")
+
+	stdout.Reset()
+	assert.Equal(t, exitOK, runCLIMode([]string{"telemetry", "enable"}, &stdout, &stderr), stderr.String())
+	stdout.Reset()
+	assert.Equal(t, exitOK, runCLIMode([]string{"telemetry", "status"}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "Telemetry: on\n")
+	assert.Contains(t, stdout.String(), "Endpoint: none configured")
+	assert.Contains(t, stdout.String(), "Queued batches: 0")
+
+	t.Setenv("DO_NOT_TRACK", "1")
+	stdout.Reset()
+	assert.Equal(t, exitOK, runCLIMode([]string{"telemetry", "status"}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "Telemetry: off (DO_NOT_TRACK is set)")
+
+	stdout.Reset()
+	assert.Equal(t, exitOK, runCLIMode([]string{"telemetry", "show"}, &stdout, &stderr), stderr.String())
+	assert.Equal(t, "[]\n", stdout.String())
+
+	assert.Equal(t, exitOK, runCLIMode([]string{"telemetry", "disable"}, &stdout, &stderr), stderr.String())
+	assert.Equal(t, exitUsage, runCLIMode([]string{"telemetry"}, &stdout, &stderr))
+	assert.Equal(t, exitUsage, runCLIMode([]string{"telemetry", "send"}, &stdout, &stderr))
+}
+
+func TestRunLicenseGenerateCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitUsage, runCLIMode([]string{"license", "generate", "--tier", "pro"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "--private-key are required")
+
+	privateKeyFile := filepath.Join(t.TempDir(), "license.key")
+	assert.Equal(t, exitOK, runCLIMode([]string{"license", "keygen", "--private-key", privateKeyFile}, &stdout, &stderr), stderr.String())
+	publicKey := strings.TrimSpace(stdout.String())
+	info, err := os.Stat(privateKeyFile)
+	require.NoError(t, err)
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+	assert.Equal(t, exitIssues, runCLIMode([]string{"license", "keygen", "--private-key", privateKeyFile}, &stdout, &stderr), "an existing key is never overwritten")
+
+	stdout.Reset()
+	stderr.Reset()
+	assert.Equal(t, exitOK, runCLIMode([]string{"license", "generate", "--tier", "pro", "--expires", "2099-12-31", "--private-key", privateKeyFile}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stderr.String(), "pro license, valid until 2099-12-31")
+
+	trusted, err := license.ParsePublicKey(publicKey)
+	require.NoError(t, err)
+	t.Cleanup(license.TrustKeyForTesting(trusted))
+	lic, err := license.NewLicenseValidator().ValidateLicense(strings.TrimSpace(stdout.String()))
+	require.NoError(t, err)
+	assert.True(t, lic.IsValid)
+
+	stdout.Reset()
+	assert.Equal(t, exitOK, runCLIMode([]string{"license", "generate", "--tier", "pro", "--features", "beta_ui, new_dashboard", "--private-key", privateKeyFile}, &stdout, &stderr), stderr.String())
+	lic, err = license.NewLicenseValidator().ValidateLicense(strings.TrimSpace(stdout.String()))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"beta_ui", "new_dashboard"}, lic.Claims)
+
+	assert.Equal(t, exitUsage, runCLIMode([]string{"license", "generate", "--tier", "pro", "--expires", "soon", "--private-key", privateKeyFile}, &stdout, &stderr))
+	assert.Equal(t, exitUsage, runCLIMode([]string{"license"}, &stdout, &stderr))
+}
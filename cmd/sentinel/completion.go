@@ -0,0 +1,546 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"dev-env-sentinel/internal/buildinfo"
+	"dev-env-sentinel/internal/license"
+	"dev-env-sentinel/internal/state"
+)
+
+// cliCommand describes a CLI command for shell completions and the man page. Flags must
+// match the flag sets of the commands; TestCLICommands_MatchFlags checks that they do.
+type cliCommand struct {
+	Name     string
+	Usage    string // Arguments after the command name
+	Summary  string
+	Flags    []cliFlag
+	Args     []string     // Values completed for positional arguments
+	Commands []cliCommand // Subcommands
+}
+
+// cliFlag describes a command flag. Flags without a Value are booleans.
+type cliFlag struct {
+	Name   string
+	Value  string // Placeholder of the value: DIR and FILE complete paths
+	Usage  string
+	Values []string // Values completed for the flag
+}
+
+// shells are the shells `sentinel completion` generates scripts for
+var shells = []string{"bash", "zsh", "fish", "powershell"}
+
+// cliCommands are the commands of the CLI
+var cliCommands = []cliCommand{
+	{
+		Name: "check", Usage: "[flags] [tool...]", Summary: "Run checks once and report the results",
+		Flags: []cliFlag{
+			{Name: "project-root", Value: "DIR", Usage: "project to check (default \".\")"},
+			{Name: "format", Value: "FORMAT", Usage: "output format", Values: []string{"text", "json", "github", "gitlab"}},
+			{Name: "suite", Value: "SUITE", Usage: "check suite: quick, full, pre-commit or one named in configs", Values: []string{"quick", "full", "pre-commit"}},
+			{Name: "lang", Value: "LANG", Usage: "output language, e.g. de; C or en.ascii for ASCII-only output"},
+			{Name: "template", Value: "NAME", Usage: "text output template from the output.templates directory, or default"},
+			{Name: "verbosity", Value: "LEVEL", Usage: "text output detail", Values: []string{"summary", "normal", "detailed"}},
+			{Name: "executor", Value: "SPEC", Usage: "where commands run: local, docker:CONTAINER or ssh:HOST"},
+		},
+		Args: defaultChecks,
+	},
+	{
+		Name: "cleanup", Usage: "[flags] [category...]", Summary: "Clear cached data from the state directories",
+		Flags: []cliFlag{
+			{Name: "project-root", Value: "DIR", Usage: "also clean the project's .sentinel directory"},
+			{Name: "dry-run", Usage: "show what would be removed without removing it"},
+		},
+		Args: state.Categories,
+	},
+	{
+		Name: "completion", Usage: "SHELL", Summary: "Print the completion script for bash, zsh, fish or powershell",
+		Args: shells,
+	},
+	{
+		Name: "config", Summary: "Inspect and install ecosystem configs",
+		Commands: []cliCommand{
+			{Name: "dump", Usage: "ID", Summary: "Print an ecosystem config with the configs it extends merged in"},
+			{
+				Name: "list", Usage: "[flags]", Summary: "List loaded configs, their source files and detection rules",
+				Flags: []cliFlag{
+					{Name: "project-root", Value: "DIR", Usage: "also show which ecosystems are detected in this project"},
+					{Name: "format", Value: "FORMAT", Usage: "output format", Values: []string{"text", "json"}},
+				},
+			},
+			{
+				Name: "add", Usage: "[flags] [NAME]", Summary: "Install a community config into the user config layer, or list them",
+				Flags: []cliFlag{{Name: "index", Value: "URL", Usage: "fetch from this index URL or directory"}},
+			},
+			{
+				Name: "sign-index", Usage: "[flags] INDEX", Summary: "Update the checksums of a config index and sign it",
+				Flags: []cliFlag{{Name: "private-key", Value: "FILE", Usage: "sign with the private key in FILE, created by sentinel license keygen"}},
+			},
+		},
+	},
+	{
+		Name: "doctor", Usage: "[flags]", Summary: "Check that the sentinel itself is set up correctly",
+		Flags: []cliFlag{{Name: "format", Value: "FORMAT", Usage: "output format", Values: []string{"text", "json"}}},
+	},
+	{
+		Name: "license", Summary: "Administer license keys",
+		Commands: []cliCommand{
+			{
+				Name: "generate", Usage: "[flags]", Summary: "Generate a license key for a self-hosted deployment",
+				Flags: []cliFlag{
+					{Name: "tier", Value: "TIER", Usage: "license tier", Values: license.GeneratableTiers},
+					{Name: "expires", Value: "DATE", Usage: "date (YYYY-MM-DD) from which the key is invalid; never expires if unset"},
+					{Name: "private-key", Value: "FILE", Usage: "sign with the private key in FILE, created by sentinel license keygen"},
+					{Name: "features", Value: "LIST", Usage: "comma-separated feature flags the key grants on top of its tier"},
+				},
+			},
+			{
+				Name: "keygen", Usage: "[flags]", Summary: "Create a key pair for signing license keys",
+				Flags: []cliFlag{{Name: "private-key", Value: "FILE", Usage: "write the private key to FILE, which must not exist"}},
+			},
+		},
+	},
+	{
+		Name: "lsp", Usage: "[flags] [tool...]", Summary: "Publish issues as LSP diagnostics over stdio for editors",
+		Flags: []cliFlag{{Name: "suite", Value: "SUITE", Usage: "check suite run on each save (default \"quick\")", Values: []string{"quick", "full", "pre-commit"}}},
+		Args:  defaultChecks,
+	},
+	{Name: "man", Summary: "Print the man page"},
+	{
+		Name: "self-update", Usage: "[flags]", Summary: "Replace this binary with the latest release",
+		Flags: []cliFlag{
+			{Name: "check", Usage: "only check whether an update is available"},
+			{Name: "force", Usage: "install the latest release even if it isn't newer, or the binary is managed by npm"},
+		},
+	},
+	{
+		Name: "state", Summary: "Export and import snapshots and check history",
+		Commands: []cliCommand{
+			{
+				Name: "export", Usage: "[flags]", Summary: "Export snapshots and check history as JSON",
+				Flags: []cliFlag{{Name: "output", Value: "FILE", Usage: "write the export to FILE instead of stdout"}},
+			},
+			{Name: "import", Usage: "FILE", Summary: "Import snapshots and check history from an export"},
+		},
+	},
+	{
+		Name: "telemetry", Summary: "Manage opt-in anonymous usage statistics",
+		Commands: []cliCommand{
+			{Name: "status", Summary: "Show whether telemetry is on, where it is sent and how much is queued"},
+			{Name: "enable", Summary: "Opt in to anonymous usage statistics"},
+			{Name: "disable", Summary: "Opt out and delete the queued statistics"},
+			{Name: "show", Summary: "Print the queued statistics as JSON"},
+		},
+	},
+	{
+		Name: "tui", Usage: "[flags] [tool...]", Summary: "Browse checks and run fixes in an interactive terminal UI",
+		Flags: []cliFlag{
+			{Name: "project-root", Value: "DIR", Usage: "project to check (default \".\")"},
+			{Name: "suite", Value: "SUITE", Usage: "check suite to run (default \"quick\")", Values: []string{"quick", "full", "pre-commit"}},
+			{Name: "interval", Value: "DURATION", Usage: "re-check this often; 0 only re-checks on r (default 30s)"},
+		},
+		Args: defaultChecks,
+	},
+	{
+		Name: "version", Usage: "[flags]", Summary: "Print the build version and commit",
+		Flags: []cliFlag{{Name: "format", Value: "FORMAT", Usage: "output format", Values: []string{"text", "json"}}},
+	},
+}
+
+// runCompletionCommand prints the completion script of a shell
+func runCompletionCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintf(stderr, "usage: sentinel completion %s\n", strings.Join(shells, "|"))
+		return exitUsage
+	}
+	switch args[0] {
+	case "bash":
+		writeBashCompletion(stdout)
+	case "zsh":
+		writeZshCompletion(stdout)
+	case "fish":
+		writeFishCompletion(stdout)
+	case "powershell":
+		writePowerShellCompletion(stdout)
+	default:
+		fmt.Fprintf(stderr, "unknown shell: %s (use %s)\n", args[0], strings.Join(shells, ", "))
+		return exitUsage
+	}
+	return exitOK
+}
+
+// runManCommand prints the man page
+func runManCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 0 {
+		fmt.Fprintln(stderr, "usage: sentinel man")
+		return exitUsage
+	}
+	writeManPage(stdout)
+	return exitOK
+}
+
+// words returns the words completed after a command: its subcommands, or its flags and arguments
+func (c cliCommand) words() []string {
+	var words []string
+	for _, sub := range c.Commands {
+		words = append(words, sub.Name)
+	}
+	for _, f := range c.Flags {
+		words = append(words, "--"+f.Name)
+	}
+	return append(words, c.Args...)
+}
+
+// walkCommands calls fn for every command with the names of the commands leading to it
+func walkCommands(commands []cliCommand, path []string, fn func(path []string, cmd cliCommand)) {
+	for _, cmd := range commands {
+		cmdPath := append(append([]string(nil), path...), cmd.Name)
+		fn(cmdPath, cmd)
+		walkCommands(cmd.Commands, cmdPath, fn)
+	}
+}
+
+// rootCommand is the sentinel command itself, with the CLI commands as subcommands
+func rootCommand() cliCommand {
+	return cliCommand{Name: "sentinel", Commands: cliCommands}
+}
+
+// writeBashCompletion writes a bash completion script
+func writeBashCompletion(w io.Writer) {
+	fmt.Fprint(w, `# bash completion for sentinel; load with: source <(sentinel completion bash)
+_sentinel() {
+    local cur prev path i
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+    path=""
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        case "${path:+$path }${COMP_WORDS[i]}" in
+`)
+	walkCommands(cliCommands, nil, func(path []string, cmd cliCommand) {
+		if len(cmd.Commands) > 0 {
+			fmt.Fprintf(w, "            %q) path=%q ;;\n", strings.Join(path, " "), strings.Join(path, " "))
+		}
+	})
+	walkCommands(cliCommands, nil, func(path []string, cmd cliCommand) {
+		if len(cmd.Commands) == 0 {
+			fmt.Fprintf(w, "            %q) path=%q; break ;;\n", strings.Join(path, " "), strings.Join(path, " "))
+		}
+	})
+	fmt.Fprint(w, `        esac
+    done
+    case "${path}:${prev}" in
+`)
+	walkCommands(cliCommands, nil, func(path []string, cmd cliCommand) {
+		for _, f := range cmd.Flags {
+			pattern := fmt.Sprintf("%q", strings.Join(path, " ")+":")
+			pattern += "--" + f.Name + "|" + pattern + "-" + f.Name
+			switch {
+			case len(f.Values) > 0:
+				fmt.Fprintf(w, "        %s) COMPREPLY=($(compgen -W %q -- \"$cur\")); return ;;\n", pattern, strings.Join(f.Values, " "))
+			case f.Value == "DIR":
+				fmt.Fprintf(w, "        %s) COMPREPLY=($(compgen -d -- \"$cur\")); return ;;\n", pattern)
+			case f.Value == "FILE":
+				fmt.Fprintf(w, "        %s) COMPREPLY=($(compgen -f -- \"$cur\")); return ;;\n", pattern)
+			case f.Value != "":
+				fmt.Fprintf(w, "        %s) return ;;\n", pattern)
+			}
+		}
+	})
+	fmt.Fprint(w, `    esac
+    case "${path}" in
+`)
+	fmt.Fprintf(w, "        \"\") COMPREPLY=($(compgen -W %q -- \"$cur\")) ;;\n", strings.Join(rootCommand().words(), " "))
+	walkCommands(cliCommands, nil, func(path []string, cmd cliCommand) {
+		fmt.Fprintf(w, "        %q) COMPREPLY=($(compgen -W %q -- \"$cur\")) ;;\n", strings.Join(path, " "), strings.Join(cmd.words(), " "))
+	})
+	fmt.Fprint(w, `    esac
+}
+complete -o default -F _sentinel sentinel
+`)
+}
+
+// writeZshCompletion writes a zsh completion script
+func writeZshCompletion(w io.Writer) {
+	fmt.Fprint(w, "#compdef sentinel\n# zsh completion for sentinel; load with: source <(sentinel completion zsh)\n\n")
+	writeZshFunction(w, "_sentinel", rootCommand(), 1)
+	fmt.Fprint(w, "\nif [ \"$funcstack[1]\" = \"_sentinel\" ]; then\n    _sentinel \"$@\"\nelse\n    compdef _sentinel sentinel\nfi\n")
+}
+
+// writeZshFunction writes the zsh completion function of a command and its subcommands.
+// depth is the number of words before the command's own arguments.
+func writeZshFunction(w io.Writer, name string, cmd cliCommand, depth int) {
+	fmt.Fprintf(w, "%s() {\n", name)
+	if len(cmd.Commands) > 0 {
+		fmt.Fprintf(w, "    if (( CURRENT == %d )); then\n        local -a commands\n        commands=(\n", depth+1)
+		for _, sub := range cmd.Commands {
+			fmt.Fprintf(w, "            %s\n", zshQuote(sub.Name+":"+sub.Summary))
+		}
+		fmt.Fprint(w, "        )\n        _describe 'command' commands\n        return\n    fi\n")
+		fmt.Fprintf(w, "    case ${words[%d]} in\n", depth+1)
+		for _, sub := range cmd.Commands {
+			fmt.Fprintf(w, "        %s) %s_%s ;;\n", sub.Name, name, zshName(sub.Name))
+		}
+		fmt.Fprint(w, "    esac\n}\n\n")
+		for _, sub := range cmd.Commands {
+			writeZshFunction(w, name+"_"+zshName(sub.Name), sub, depth+1)
+		}
+		return
+	}
+
+	// _arguments takes the first word as the command name
+	fmt.Fprintf(w, "    shift %d words\n    (( CURRENT -= %d ))\n    _arguments", depth-1, depth-1)
+	for _, f := range cmd.Flags {
+		spec := "--" + f.Name + "[" + zshEscape(f.Usage) + "]"
+		switch {
+		case len(f.Values) > 0:
+			spec += ":" + strings.ToLower(f.Value) + ":(" + strings.Join(f.Values, " ") + ")"
+		case f.Value == "DIR":
+			spec += ":dir:_files -/"
+		case f.Value == "FILE":
+			spec += ":file:_files"
+		case f.Value != "":
+			spec += ":" + strings.ToLower(f.Value) + ": "
+		}
+		fmt.Fprintf(w, " \\\n        %s", zshQuote(spec))
+	}
+	if len(cmd.Args) > 0 {
+		fmt.Fprintf(w, " \\\n        %s", zshQuote("*:argument:("+strings.Join(cmd.Args, " ")+")"))
+	} else if strings.Contains(cmd.Usage, "FILE") {
+		fmt.Fprintf(w, " \\\n        %s", zshQuote("*:file:_files"))
+	}
+	fmt.Fprint(w, "\n}\n\n")
+}
+
+// writeFishCompletion writes a fish completion script
+func writeFishCompletion(w io.Writer) {
+	fmt.Fprint(w, "# fish completion for sentinel; load with: sentinel completion fish | source\ncomplete -c sentinel -f\n")
+	var top []string
+	for _, cmd := range cliCommands {
+		top = append(top, cmd.Name)
+	}
+	for _, cmd := range cliCommands {
+		fmt.Fprintf(w, "complete -c sentinel -n '__fish_use_subcommand' -a %s -d %s\n", cmd.Name, fishQuote(cmd.Summary))
+	}
+	walkCommands(cliCommands, nil, func(path []string, cmd cliCommand) {
+		var conditions []string
+		for _, name := range path {
+			conditions = append(conditions, "__fish_seen_subcommand_from "+name)
+		}
+		seen := strings.Join(conditions, "; and ")
+		if len(cmd.Commands) > 0 {
+			var subs []string
+			for _, sub := range cmd.Commands {
+				subs = append(subs, sub.Name)
+			}
+			for _, sub := range cmd.Commands {
+				fmt.Fprintf(w, "complete -c sentinel -n %s -a %s -d %s\n", fishQuote(seen+"; and not __fish_seen_subcommand_from "+strings.Join(subs, " ")), sub.Name, fishQuote(sub.Summary))
+			}
+			return
+		}
+		for _, f := range cmd.Flags {
+			line := fmt.Sprintf("complete -c sentinel -n %s -l %s -d %s", fishQuote(seen), f.Name, fishQuote(f.Usage))
+			switch {
+			case len(f.Values) > 0:
+				line += " -x -a " + fishQuote(strings.Join(f.Values, " "))
+			case f.Value == "DIR" || f.Value == "FILE":
+				line += " -r -F"
+			case f.Value != "":
+				line += " -x"
+			}
+			fmt.Fprintln(w, line)
+		}
+		if len(cmd.Args) > 0 {
+			fmt.Fprintf(w, "complete -c sentinel -n %s -a %s\n", fishQuote(seen), fishQuote(strings.Join(cmd.Args, " ")))
+		} else if strings.Contains(cmd.Usage, "FILE") {
+			fmt.Fprintf(w, "complete -c sentinel -n %s -F\n", fishQuote(seen))
+		}
+	})
+}
+
+// writePowerShellCompletion writes a PowerShell completion script
+func writePowerShellCompletion(w io.Writer) {
+	fmt.Fprint(w, `# PowerShell completion for sentinel; load with: sentinel completion powershell | Out-String | Invoke-Expression
+Register-ArgumentCompleter -Native -CommandName sentinel -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $completions = @{
+`)
+	completions := map[string][]string{"": rootCommand().words()}
+	walkCommands(cliCommands, nil, func(path []string, cmd cliCommand) {
+		completions[strings.Join(path, " ")] = cmd.words()
+		for _, f := range cmd.Flags {
+			if len(f.Values) > 0 {
+				completions[strings.Join(path, " ")+" --"+f.Name] = f.Values
+			}
+		}
+	})
+	keys := make([]string, 0, len(completions))
+	for key := range completions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		quoted := make([]string, len(completions[key]))
+		for i, word := range completions[key] {
+			quoted[i] = psQuote(word)
+		}
+		fmt.Fprintf(w, "        %s = @(%s)\n", psQuote(key), strings.Join(quoted, ", "))
+	}
+	fmt.Fprint(w, `    }
+    $elements = @($commandAst.CommandElements | Select-Object -Skip 1 | ForEach-Object { $_.ToString() })
+    if ($wordToComplete -ne '' -and $elements.Count -gt 0) {
+        $elements = @($elements | Select-Object -First ($elements.Count - 1))
+    }
+    $path = ''
+    foreach ($element in $elements) {
+        $next = ($path + ' ' + ($element -replace '^-+', '--')).Trim()
+        if ($completions.ContainsKey($next)) {
+            $path = $next
+        } elseif ($path -match ' --') {
+            $path = $path -replace ' --.*$', ''
+        }
+    }
+    $completions[$path] | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`)
+}
+
+// writeManPage writes the sentinel(1) man page in roff
+func writeManPage(w io.Writer) {
+	fmt.Fprintf(w, ".TH SENTINEL 1 \"\" %s \"User Commands\"\n", roffQuote("sentinel "+buildinfo.Get().Version))
+	fmt.Fprint(w, `.SH NAME
+sentinel \- detect and fix development environment drift
+.SH SYNOPSIS
+.B sentinel
+.br
+.B sentinel
+.I command
+.RI [ flags ]
+.RI [ arguments ]
+.SH DESCRIPTION
+Without a command,
+.B sentinel
+starts the MCP server, which AI agents and editors call to check build freshness, infrastructure,
+environment variables and more, and to fix the issues found.
+The commands run the same checks once from a terminal or CI job, and manage the sentinel's own state.
+.SH COMMANDS
+`)
+	walkCommands(cliCommands, nil, func(path []string, cmd cliCommand) {
+		if len(cmd.Commands) > 0 {
+			return
+		}
+		synopsis := "sentinel " + strings.Join(path, " ")
+		if cmd.Usage != "" {
+			synopsis += " " + cmd.Usage
+		}
+		fmt.Fprintf(w, ".TP\n.B %s\n%s\n", roffEscape(synopsis), roffEscape(cmd.Summary))
+		if len(cmd.Flags) > 0 {
+			fmt.Fprint(w, ".RS\n")
+			for _, f := range cmd.Flags {
+				fmt.Fprintf(w, ".TP\n.B \\-\\-%s", roffEscape(f.Name))
+				if f.Value != "" {
+					fmt.Fprintf(w, " \\fI%s\\fR", roffEscape(f.Value))
+				}
+				usage := f.Usage
+				if len(f.Values) > 0 {
+					usage += ": " + strings.Join(f.Values, ", ")
+				}
+				fmt.Fprintf(w, "\n%s\n", roffEscape(usage))
+			}
+			fmt.Fprint(w, ".RE\n")
+		}
+	})
+	fmt.Fprintf(w, `.SH EXIT STATUS
+.TP
+.B %d
+Success; checks found no issues
+.TP
+.B %d
+Checks found issues, or a command failed
+.TP
+.B %d
+Invalid usage
+.SH ENVIRONMENT
+.TP
+.B SENTINEL_CONFIG_DIR
+Directory the ecosystem configs are loaded from
+.TP
+.B SENTINEL_USER_CONFIG_DIR
+Directory of the user config layer, loaded after the ecosystem configs (default: ~/.dev-env-sentinel/configs)
+.TP
+.B SENTINEL_CONFIG_INDEX
+Index URL or directory sentinel config add fetches configs from
+.TP
+.B SENTINEL_STATE_DIR
+Directory snapshots, history and logs are kept in
+.TP
+.B SENTINEL_STATE_BACKEND
+Set to files to keep state in JSON files instead of SQLite
+.TP
+.B SENTINEL_LANG
+Output language and character set, e.g. de, or C for ASCII-only output
+.TP
+.B SENTINEL_READ_ONLY
+Set to true to never run state-changing commands
+.TP
+.B SENTINEL_TRANSPORT
+Transport of the MCP server
+.TP
+.B SENTINEL_PROJECTS
+Set to true to enable the project registry of an HTTP or gRPC server
+.TP
+.B SENTINEL_ADMIN_TOKEN
+Token callers of the project registry tools of a shared server must send
+.SH FILES
+.TP
+.I sentinel.yaml
+Server settings, looked up next to the config directory
+.SH SEE ALSO
+Run
+.B sentinel help
+for a summary of the commands.
+`, exitOK, exitIssues, exitUsage)
+}
+
+// zshName turns a command name into part of a zsh function name
+func zshName(name string) string {
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// zshQuote quotes a word for zsh
+func zshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// zshEscape escapes the characters _arguments gives a meaning in descriptions
+func zshEscape(s string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`, ":", `\:`).Replace(s)
+}
+
+// fishQuote quotes a word for fish
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+// psQuote quotes a word for PowerShell
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// roffEscape escapes text for roff
+func roffEscape(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, "-", `\-`).Replace(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+// roffQuote quotes a macro argument for roff
+func roffQuote(s string) string {
+	return `"` + strings.ReplaceAll(roffEscape(s), `"`, `\(dq`) + `"`
+}
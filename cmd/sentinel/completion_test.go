@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCLICommands_MatchFlags(t *testing.T) {
+	var usage bytes.Buffer
+	printUsage(&usage)
+
+	walkCommands(cliCommands, nil, func(path []string, cmd cliCommand) {
+		if len(cmd.Commands) > 0 {
+			return
+		}
+		name := strings.Join(path, " ")
+		assert.Contains(t, usage.String(), "sentinel "+name, "usage lists %s", name)
+		if len(cmd.Flags) == 0 {
+			return
+		}
+
+		// -h prints the flags the command really has
+		var stdout, stderr bytes.Buffer
+		runCLIMode(append(path, "-h"), &stdout, &stderr)
+		for _, f := range cmd.Flags {
+			assert.Contains(t, stderr.String(), "-"+f.Name, "%s has flag --%s", name, f.Name)
+		}
+		assert.Equal(t, len(cmd.Flags), strings.Count(stderr.String(), "\n  -"), "%s lists all its flags", name)
+	})
+}
+
+func TestRunCompletionCommand(t *testing.T) {
+	for _, shell := range shells {
+		var stdout, stderr bytes.Buffer
+		require.Equal(t, exitOK, runCLIMode([]string{"completion", shell}, &stdout, &stderr), shell)
+		assert.Contains(t, stdout.String(), "self-update", shell)
+		assert.Contains(t, stdout.String(), "gitlab", shell)
+	}
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitUsage, runCLIMode([]string{"completion", "tcsh"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "unknown shell: tcsh")
+}
+
+func TestBashCompletion_Completes(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+	var script bytes.Buffer
+	writeBashCompletion(&script)
+	path := filepath.Join(t.TempDir(), "sentinel.bash")
+	require.NoError(t, os.WriteFile(path, script.Bytes(), 0644))
+
+	complete := func(words string, cword int) []string {
+		out, err := exec.Command(bash, "-c", fmt.Sprintf(`source %s; COMP_WORDS=(%s); COMP_CWORD=%d; _sentinel; echo "${COMPREPLY[*]}"`, path, words, cword)).CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.Fields(string(out))
+	}
+	assert.Equal(t, []string{"check", "cleanup", "completion", "config"}, complete(`sentinel c`, 1))
+	assert.Equal(t, []string{"dump", "list", "add", "sign-index"}, complete(`sentinel config ""`, 2))
+	assert.Equal(t, []string{"text", "json"}, complete(`sentinel config list --format ""`, 4))
+	assert.Equal(t, []string{"history"}, complete(`sentinel cleanup --dry-run h`, 3))
+}
+
+func TestRunManCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitOK, runCLIMode([]string{"man"}, &stdout, &stderr))
+	page := stdout.String()
+	assert.True(t, strings.HasPrefix(page, ".TH SENTINEL 1"))
+	for _, section := range []string{".SH NAME", ".SH SYNOPSIS", ".SH COMMANDS", ".SH EXIT STATUS", ".SH ENVIRONMENT"} {
+		assert.Contains(t, page, section)
+	}
+	assert.Contains(t, page, `.B sentinel state export [flags]`)
+	assert.Contains(t, page, `.B \-\-project\-root \fIDIR\fR`)
+}
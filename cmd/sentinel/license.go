@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/license"
+)
+
+// runLicenseCommand runs the license administration commands
+func runLicenseCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "generate":
+			return runLicenseGenerateCommand(args[1:], stdout, stderr)
+		case "keygen":
+			return runLicenseKeygenCommand(args[1:], stdout, stderr)
+		}
+	}
+	fmt.Fprintln(stderr, "usage: sentinel license keygen --private-key FILE | sentinel license generate --tier TIER --private-key FILE [--expires DATE] [--features LIST]")
+	return exitUsage
+}
+
+// runLicenseKeygenCommand creates a key pair to sign license keys with. The private key is
+// written to a file; the public key is printed, to embed as license.EmbeddedPublicKey in the
+// builds that accept its keys.
+func runLicenseKeygenCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("license keygen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	privateKeyFile := flags.String("private-key", "", "write the private key to FILE, which must not exist")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *privateKeyFile == "" {
+		fmt.Fprintln(stderr, "--private-key is required")
+		return exitUsage
+	}
+
+	public, private, err := license.GenerateKeyPair()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitIssues
+	}
+	file, err := os.OpenFile(*privateKeyFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		fmt.Fprintf(stderr, "error writing private key: %v\n", err)
+		return exitIssues
+	}
+	defer file.Close()
+	if _, err := fmt.Fprintln(file, private); err != nil {
+		fmt.Fprintf(stderr, "error writing private key: %v\n", err)
+		return exitIssues
+	}
+	fmt.Fprintln(stdout, public)
+	fmt.Fprintf(stderr, "Private key written to %s; builds accept its keys once the public key above is their license.EmbeddedPublicKey\n", *privateKeyFile)
+	return exitOK
+}
+
+// runLicenseGenerateCommand signs a license key for self-hosted deployments. Only holders of
+// the private key matching the public key the deployment verifies with can mint keys it accepts.
+func runLicenseGenerateCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("license generate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	tier := flags.String("tier", "", "license tier: "+strings.Join(license.GeneratableTiers, " or "))
+	expires := flags.String("expires", "", "date (YYYY-MM-DD) from which the key is invalid; never expires if unset")
+	privateKeyFile := flags.String("private-key", "", "sign with the private key in FILE, created by sentinel license keygen")
+	featureList := flags.String("features", "", "comma-separated feature flags the key grants on top of its tier")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *tier == "" || *privateKeyFile == "" {
+		fmt.Fprintln(stderr, "--tier and --private-key are required")
+		return exitUsage
+	}
+
+	var expiresAt *time.Time
+	if *expires != "" {
+		date, err := time.Parse("2006-01-02", *expires)
+		if err != nil {
+			fmt.Fprintf(stderr, "invalid --expires date %q (expected YYYY-MM-DD)\n", *expires)
+			return exitUsage
+		}
+		expiresAt = &date
+	}
+
+	data, err := os.ReadFile(*privateKeyFile)
+	if err != nil {
+		fmt.Fprintf(stderr, "error reading private key: %v\n", err)
+		return exitUsage
+	}
+	privateKey, err := license.ParsePrivateKey(string(data))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	var claims []string
+	for _, f := range strings.Split(*featureList, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			claims = append(claims, f)
+		}
+	}
+
+	key, err := license.SignLicense(privateKey, *tier, expiresAt, claims...)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	fmt.Fprintln(stdout, key)
+	if expiresAt != nil {
+		fmt.Fprintf(stderr, "%s license, valid until %s\n", *tier, expiresAt.Format("2006-01-02"))
+	} else {
+		fmt.Fprintf(stderr, "%s license, never expires\n", *tier)
+	}
+	return exitOK
+}
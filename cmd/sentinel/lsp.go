@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/lsp"
+	"dev-env-sentinel/internal/mcp"
+	"dev-env-sentinel/internal/report"
+)
+
+// runLSPCommand serves environment issues as LSP diagnostics on stdin and stdout until the
+// editor exits
+func runLSPCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lsp", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	suite := flags.String("suite", "quick", "check suite run on each save")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	server, configs, _, err := newCheckServer()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if *suite != "" {
+		if err := config.ValidateSuite(configs, *suite); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+
+	checks := flags.Args()
+	if len(checks) == 0 {
+		checks = defaultChecks
+	}
+
+	checker := lspChecker(server, configs, checks, *suite)
+	if err := lsp.NewServer(checker, stdin, stdout).Serve(context.Background()); err != nil {
+		fmt.Fprintf(stderr, "error serving diagnostics: %v\n", err)
+		return exitIssues
+	}
+	return exitOK
+}
+
+// lspChecker runs the checks for the language server. Findings without a file, or pointing at
+// a file that doesn't exist such as a missing build output, are shown on the file to fix
+// instead: .env for environment variables, otherwise the project's manifest.
+func lspChecker(server *mcp.Server, configs []*config.EcosystemConfig, checks []string, suite string) lsp.Checker {
+	return func(ctx context.Context, projectRoot string) ([]report.Finding, error) {
+		manifest := manifestFile(ctx, projectRoot, configs)
+
+		var findings []report.Finding
+		for _, check := range checks {
+			checkArgs := map[string]interface{}{"project_root": projectRoot}
+			if suite != "" {
+				checkArgs["suite"] = suite
+			}
+
+			var checkFindings []report.Finding
+			result, err := server.CallTool(ctx, check, checkArgs)
+			if err != nil {
+				checkFindings = []report.Finding{{Check: check, Severity: report.SeverityError, Message: fmt.Sprintf("%s failed: %v", check, err)}}
+			} else {
+				checkFindings = report.Collect(check, projectRoot, result)
+			}
+
+			fallback := manifest
+			if check == "env_var_audit" && common.FileExists(filepath.Join(projectRoot, ".env")) {
+				fallback = ".env"
+			}
+			for _, f := range checkFindings {
+				if f.File == "" || !common.FileExists(filepath.Join(projectRoot, filepath.FromSlash(f.File))) {
+					f.File, f.Line = fallback, 0
+				}
+				findings = append(findings, f)
+			}
+		}
+		return findings, nil
+	}
+}
+
+// manifestFile returns the first manifest of the detected ecosystems present in the project,
+// such as pom.xml or package.json
+func manifestFile(ctx context.Context, projectRoot string, configs []*config.EcosystemConfig) string {
+	ecosystems, err := detector.DetectEcosystems(ctx, projectRoot, configs)
+	if err != nil {
+		return ""
+	}
+	for _, eco := range ecosystems {
+		candidates := append([]string{eco.Config.Ecosystem.Manifest.PrimaryFile}, eco.Config.Ecosystem.Detection.ManifestFiles...)
+		for _, manifest := range candidates {
+			if manifest == "" || strings.ContainsAny(manifest, "*?[") {
+				continue
+			}
+			if common.FileExists(filepath.Join(projectRoot, manifest)) {
+				return manifest
+			}
+		}
+	}
+	return ""
+}
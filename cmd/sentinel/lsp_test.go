@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLSPChecker_MapsFindingsToFiles(t *testing.T) {
+	t.Setenv("SENTINEL_CONFIG_DIR", javaOnlyConfigDir(t))
+
+	project := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(project, "src"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "pom.xml"), []byte("<project/>"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "src", "App.java"),
+		[]byte("class App {\n  String key = System.getenv(\"SENTINEL_LSP_TEST_UNSET\");\n}\n"), 0644))
+
+	server, configs, _, err := newCheckServer()
+	require.NoError(t, err)
+	checker := lspChecker(server, configs, []string{"verify_build_freshness", "env_var_audit"}, "")
+
+	findings, err := checker(context.Background(), project)
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	for _, f := range findings {
+		files[f.Message] = f.File
+	}
+	assert.Equal(t, "src/App.java", files["Environment variable SENTINEL_LSP_TEST_UNSET is not set"])
+	// Missing build outputs are shown on the manifest that builds them
+	verified := 0
+	for _, f := range findings {
+		if f.Check == "verify_build_freshness" {
+			assert.Equal(t, "pom.xml", f.File)
+			verified++
+		}
+	}
+	assert.Positive(t, verified)
+}
+
+func TestRunLSPCommand_UnknownSuite(t *testing.T) {
+	t.Setenv("SENTINEL_CONFIG_DIR", javaOnlyConfigDir(t))
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitUsage, runLSPCommand([]string{"--suite", "nightly"}, nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "nightly")
+}
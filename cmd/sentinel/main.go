@@ -1,22 +1,38 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/i18n"
 	"dev-env-sentinel/internal/mcp"
+	"dev-env-sentinel/internal/notify"
+	"dev-env-sentinel/internal/profile"
+	"dev-env-sentinel/internal/queue"
+	"dev-env-sentinel/internal/redact"
+	"dev-env-sentinel/internal/registry"
+	"dev-env-sentinel/internal/settings"
+	"dev-env-sentinel/internal/state"
+	"dev-env-sentinel/internal/telemetry"
+	"dev-env-sentinel/internal/tickets"
+	"dev-env-sentinel/internal/trace"
 )
 
+// telemetryInterval is how often usage statistics are queued and sent, once the user opted in
+const telemetryInterval = time.Hour
+
 func main() {
 	// Check if running as MCP server (no args) or CLI mode
 	if len(os.Args) == 1 {
 		// MCP server mode
 		runMCPServer()
 	} else {
-		// CLI mode (for testing)
-		runCLIMode()
+		// CLI mode
+		os.Exit(runCLIMode(os.Args[1:], os.Stdout, os.Stderr))
 	}
 }
 
@@ -60,21 +76,107 @@ func runMCPServer() {
 
 	// Create MCP server
 	server := mcp.NewServer()
+	// Licenses expiring soon or lapsed since the last run are reminded of right away
+	server.LogRenewalNotice()
+
+	// Load server settings
+	serverSettings, err := settings.Discover(baseDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading server settings: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Restrict exposed tools to the operator's profile
+	policy, err := profile.Resolve(serverSettings.Tools.Profile, serverSettings.Tools.Disabled)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error configuring tools: %v\n", err)
+		os.Exit(1)
+	}
+	server.SetToolPolicy(policy)
+	server.SetHeadroom(serverSettings.Headroom.Policy())
+	server.SetFixPolicy(serverSettings.FixPolicy.Policy())
+	server.SetFixLoopLimits(serverSettings.FixPolicy.LoopLimits())
+	server.SetExecutor(serverSettings.Execution.CommandExecutor())
+	server.SetToolTimeout(serverSettings.Execution.Timeout())
+	redact.Configure(serverSettings.Redaction.Options()) // Validated with the settings
+	server.SetFeatureFlags(serverSettings.Features)
+	symbols, _ := i18n.ParseSymbols(serverSettings.Output.Symbols)
+	server.SetSymbols(symbols)
+	set, err := loadTemplates(baseDir, serverSettings.Output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading output templates: %v\n", err)
+		os.Exit(1)
+	}
+	server.SetTemplates(set, serverSettings.Output.Template)
 
 	// Register all tools
 	mcp.RegisterAllTools(server, configs)
 
+	// Persist snapshots and history in the user state directory
+	var recorder *telemetry.Recorder
+	if stateDir, err := state.Open(state.DefaultRoot()); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: state directory unavailable, snapshots will not persist: %v\n", err)
+	} else {
+		server.SetStrictPrivacy(serverSettings.Privacy.Strict())
+		server.SetState(stateDir)
+		server.SetRetention(serverSettings.Retention.Policy())
+		// Protocol traces for client compatibility bug reports; they hold whole tool results
+		if trace.Enabled() && serverSettings.Privacy.Strict() {
+			fmt.Fprintln(os.Stderr, "warning: SENTINEL_TRACE is ignored in strict privacy mode")
+		} else if trace.Enabled() {
+			server.SetTracer(trace.New(stateDir))
+		}
+		// Anonymous usage statistics, only once the user opted in with `sentinel telemetry enable`
+		recorder = telemetry.NewRecorder(stateDir, telemetry.Endpoint(serverSettings.Telemetry.Endpoint))
+		server.SetTelemetry(recorder)
+	}
+
+	// A shared HTTP or gRPC deployment serves several repositories, each registered under an ID,
+	// once SENTINEL_PROJECTS enables the registry
+	transport := mcp.DetectTransport()
+	if _, ok := transport.(*mcp.StdioTransport); !ok && mcp.ProjectsEnabled() {
+		reg, err := registry.Open(state.DefaultRoot())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error loading project registry: %v\n", err)
+			os.Exit(1)
+		}
+		server.SetRegistry(reg)
+	}
+
+	// Background jobs, and over HTTP every tool call, run on a bounded number of workers
+	// so bursts of requests from several agents don't all build at once
+	server.SetQueue(queue.New(serverSettings.Execution.Workers, serverSettings.Execution.QueueSize))
+
+	// Notify on drift and start scheduled background checks
+	notifier, err := notify.NewDispatcher(serverSettings.Notifications)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error configuring notifications: %v\n", err)
+		os.Exit(1)
+	}
+	server.SetNotifier(notifier)
+	exporter, err := tickets.New(serverSettings.Tickets)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error configuring ticket export: %v\n", err)
+		os.Exit(1)
+	}
+	server.SetTickets(exporter)
+	server.SetSlack(serverSettings.Slack)
+	server.SetWebhooks(serverSettings.Webhooks)
+	if len(serverSettings.Schedule) > 0 {
+		if _, err := mcp.StartScheduler(context.Background(), server, serverSettings.Schedule); err != nil {
+			fmt.Fprintf(os.Stderr, "error starting scheduler: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go recorder.Run(ctx, telemetryInterval)
+
 	// Start server
-	if err := server.Start(); err != nil {
+	if err := server.StartWithTransport(transport); err != nil {
 		fmt.Fprintf(os.Stderr, "error starting server: %v\n", err)
 		os.Exit(1)
 	}
+	cancel()
+	recorder.Flush(context.Background())
 }
-
-// runCLIMode runs in CLI mode for testing
-func runCLIMode() {
-	fmt.Fprintf(os.Stderr, "CLI mode not yet implemented\n")
-	fmt.Fprintf(os.Stderr, "Run without arguments to start MCP server\n")
-	os.Exit(1)
-}
-
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"dev-env-sentinel/internal/settings"
+	"dev-env-sentinel/internal/state"
+	"dev-env-sentinel/internal/telemetry"
+)
+
+// runTelemetryCommand shows, enables or disables anonymous usage statistics, and prints the
+// batches waiting to be sent
+func runTelemetryCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(stderr, "usage: sentinel telemetry status|enable|disable|show")
+		return exitUsage
+	}
+
+	stateDir, err := state.Open(state.DefaultRoot())
+	if err != nil {
+		fmt.Fprintf(stderr, "error opening state directory: %v\n", err)
+		return exitIssues
+	}
+
+	switch args[0] {
+	case "status":
+		serverSettings, err := settings.Discover(getConfigBaseDir())
+		if err != nil {
+			fmt.Fprintf(stderr, "error loading server settings: %v\n", err)
+			return exitUsage
+		}
+		consent := telemetry.LoadConsent(stateDir)
+		switch reason := telemetry.Disabled(); {
+		case reason != "":
+			fmt.Fprintf(stdout, "Telemetry: off (%s)\n", reason)
+		case consent.Enabled:
+			fmt.Fprintln(stdout, "Telemetry: on")
+		default:
+			fmt.Fprintln(stdout, "Telemetry: off")
+		}
+		if consent.InstallID != "" {
+			fmt.Fprintf(stdout, "Install ID: %s\n", consent.InstallID)
+		}
+		if endpoint := telemetry.Endpoint(serverSettings.Telemetry.Endpoint); endpoint != "" {
+			fmt.Fprintf(stdout, "Endpoint: %s\n", endpoint)
+		} else {
+			fmt.Fprintln(stdout, "Endpoint: none configured; batches stay queued locally")
+		}
+		batches, err := telemetry.Queued(stateDir)
+		if err != nil {
+			fmt.Fprintf(stderr, "error reading queued telemetry: %v\n", err)
+			return exitIssues
+		}
+		fmt.Fprintf(stdout, "Queued batches: %d (inspect them with sentinel telemetry show)\n", len(batches))
+	case "enable":
+		if _, err := telemetry.SetEnabled(stateDir, true); err != nil {
+			fmt.Fprintf(stderr, "error enabling telemetry: %v\n", err)
+			return exitIssues
+		}
+		fmt.Fprintln(stdout, "Telemetry enabled. Only tool call counts, durations and issue types are collected, never paths or values.")
+		if reason := telemetry.Disabled(); reason != "" {
+			fmt.Fprintf(stdout, "It stays off in this environment: %s\n", reason)
+		}
+	case "disable":
+		if _, err := telemetry.SetEnabled(stateDir, false); err != nil {
+			fmt.Fprintf(stderr, "error disabling telemetry: %v\n", err)
+			return exitIssues
+		}
+		fmt.Fprintln(stdout, "Telemetry disabled; queued batches were deleted")
+	case "show":
+		batches, err := telemetry.Queued(stateDir)
+		if err != nil {
+			fmt.Fprintf(stderr, "error reading queued telemetry: %v\n", err)
+			return exitIssues
+		}
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(batches)
+	default:
+		fmt.Fprintf(stderr, "unknown telemetry command: %s\n", args[0])
+		return exitUsage
+	}
+	return exitOK
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/i18n"
+	"dev-env-sentinel/internal/mcp"
+	"dev-env-sentinel/internal/reconciler"
+	"dev-env-sentinel/internal/report"
+	"dev-env-sentinel/internal/tui"
+	"golang.org/x/term"
+)
+
+// runTUICommand shows the health of a project in an interactive terminal UI
+func runTUICommand(args []string, stdin *os.File, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("tui", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	projectRoot := flags.String("project-root", ".", "project to check")
+	suite := flags.String("suite", "quick", "check suite to run")
+	interval := flags.Duration("interval", 30*time.Second, "re-check this often; 0 only re-checks on r")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	root, err := filepath.Abs(*projectRoot)
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid project root: %v\n", err)
+		return exitUsage
+	}
+	server, configs, serverSettings, err := newCheckServer()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if *suite != "" {
+		if err := config.ValidateSuite(configs, *suite); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+	checks := flags.Args()
+	if len(checks) == 0 {
+		checks = defaultChecks
+	}
+
+	fd := int(stdin.Fd())
+	if !term.IsTerminal(fd) {
+		fmt.Fprintln(stderr, "sentinel tui needs a terminal; use sentinel check in scripts")
+		return exitUsage
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		fmt.Fprintf(stderr, "error setting up the terminal: %v\n", err)
+		return exitIssues
+	}
+	defer term.Restore(fd, state)
+
+	symbols, _ := i18n.ParseSymbols(serverSettings.Output.Symbols)
+	if i18n.FromEnv().ASCII {
+		symbols = i18n.SymbolsASCII
+	}
+	backend := &serverBackend{server: server, projectRoot: root, checks: checks, suite: *suite}
+	if err := tui.Run(context.Background(), tui.NewModel(backend, root), stdin, stdout, symbols, *interval); err != nil {
+		term.Restore(fd, state)
+		fmt.Fprintf(stderr, "error running the terminal UI: %v\n", err)
+		return exitIssues
+	}
+	return exitOK
+}
+
+// serverBackend runs the checks and fixes of the terminal UI as tool calls
+type serverBackend struct {
+	server      *mcp.Server
+	projectRoot string
+	checks      []string
+	suite       string
+}
+
+// Check runs the checks
+func (b *serverBackend) Check(ctx context.Context) []tui.Check {
+	var results []tui.Check
+	for _, check := range b.checks {
+		checkArgs := map[string]interface{}{"project_root": b.projectRoot}
+		if b.suite != "" {
+			checkArgs["suite"] = b.suite
+		}
+		result, err := b.server.CallTool(ctx, check, checkArgs)
+		if err != nil {
+			results = append(results, tui.Check{Name: check, Error: err.Error()})
+			continue
+		}
+		results = append(results, tui.Check{
+			Name:     check,
+			Healthy:  mcp.IsHealthyResult(result),
+			Findings: report.Collect(check, b.projectRoot, result),
+		})
+	}
+	return results
+}
+
+// Fixes plans the fixes of reconcile_environment without running them
+func (b *serverBackend) Fixes(ctx context.Context) ([]tui.Fix, string) {
+	result, err := b.server.CallTool(ctx, "reconcile_environment", map[string]interface{}{"project_root": b.projectRoot, "plan": true})
+	if err != nil {
+		return nil, fmt.Sprintf("Fixes unavailable: %v", err)
+	}
+	plan, ok := result.(*reconciler.ReconciliationReport)
+	if !ok {
+		return nil, ""
+	}
+
+	var fixes []tui.Fix
+	for _, results := range [][]reconciler.FixResult{plan.Planned, plan.Pending} {
+		for _, r := range results {
+			fixes = append(fixes, tui.Fix{
+				IssueType:   r.IssueType,
+				Command:     r.Command,
+				Fingerprint: r.Fingerprint,
+				Message:     r.Message,
+				Runnable:    r.Fingerprint != "",
+			})
+		}
+	}
+	for _, r := range plan.Manual {
+		fixes = append(fixes, tui.Fix{IssueType: r.IssueType, Command: r.Command, Message: r.Message})
+	}
+	return fixes, ""
+}
+
+// Fix runs a single fix with reconcile_issue
+func (b *serverBackend) Fix(ctx context.Context, fingerprint string) (string, error) {
+	result, err := b.server.CallTool(ctx, "reconcile_issue", map[string]interface{}{"project_root": b.projectRoot, "fingerprint": fingerprint})
+	if err != nil {
+		return "", err
+	}
+	fixed, ok := result.(*reconciler.ReconciliationReport)
+	if !ok {
+		return fmt.Sprint(result), nil
+	}
+	for _, results := range [][]reconciler.FixResult{fixed.Failed, fixed.Manual, fixed.Pending} {
+		if len(results) > 0 {
+			return "", fmt.Errorf("%s", results[0].Message)
+		}
+	}
+	return fixed.Message, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"dev-env-sentinel/internal/buildinfo"
+	"dev-env-sentinel/internal/selfupdate"
+)
+
+// runVersionCommand prints the build version and commit
+func runVersionCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("version", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", "text", "output format: text or json")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	info := buildinfo.Get()
+	switch *format {
+	case "text":
+		fmt.Fprintf(stdout, "dev-env-sentinel %s\n", info)
+	case "json":
+		data, _ := json.MarshalIndent(info, "", "  ")
+		fmt.Fprintln(stdout, string(data))
+	default:
+		fmt.Fprintf(stderr, "unknown format: %s\n", *format)
+		return exitUsage
+	}
+	return exitOK
+}
+
+// runSelfUpdateCommand replaces the running binary with the latest release. With --check it only
+// reports whether an update is available, exiting with exitIssues when there is one.
+func runSelfUpdateCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("self-update", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	checkOnly := flags.Bool("check", false, "only check whether an update is available")
+	force := flags.Bool("force", false, "install the latest release even if it isn't newer, or the binary is managed by npm")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	current := buildinfo.Get().Version
+	updater := selfupdate.NewUpdater()
+	release, err := updater.Latest(context.Background())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitIssues
+	}
+
+	newer := selfupdate.Newer(release.Version, current)
+	if *checkOnly {
+		if !newer {
+			fmt.Fprintf(stdout, "dev-env-sentinel %s is up to date\n", current)
+			return exitOK
+		}
+		fmt.Fprintf(stdout, "dev-env-sentinel %s is available (installed: %s): %s\n", release.Version, current, release.URL)
+		return exitIssues
+	}
+	if !newer && !*force {
+		fmt.Fprintf(stdout, "dev-env-sentinel %s is up to date\n", current)
+		return exitOK
+	}
+
+	exePath, err := os.Executable()
+	if err == nil {
+		exePath, err = filepath.EvalSymlinks(exePath)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to locate the running binary: %v\n", err)
+		return exitIssues
+	}
+	// npm would put its own binary back on the next install, so let npm do the update
+	if strings.Contains(filepath.ToSlash(exePath), "/node_modules/") && !*force {
+		fmt.Fprintln(stderr, "this binary is managed by npm; update it with `npm install -g dev-env-sentinel@latest` (or pass --force)")
+		return exitUsage
+	}
+
+	fmt.Fprintf(stdout, "Updating %s from %s to %s...\n", exePath, current, release.Version)
+	if err := updater.Apply(context.Background(), release, exePath); err != nil {
+		fmt.Fprintf(stderr, "update failed: %v\n", err)
+		return exitIssues
+	}
+	fmt.Fprintf(stdout, "✅ Updated to dev-env-sentinel %s\n", release.Version)
+	return exitOK
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"dev-env-sentinel/internal/selfupdate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunVersionCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitOK, runCLIMode([]string{"--version"}, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "dev-env-sentinel ")
+
+	stdout.Reset()
+	assert.Equal(t, exitOK, runCLIMode([]string{"version", "--format", "json"}, &stdout, &stderr))
+	var info map[string]interface{}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &info))
+	assert.Contains(t, info, "version")
+	assert.Contains(t, info, "platform")
+}
+
+func TestRunSelfUpdateCommand_Check(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/"+selfupdate.DefaultRepo+"/releases/latest", r.URL.Path)
+		fmt.Fprint(w, `{"tag_name": "v99.0.0", "html_url": "https://example.com/v99.0.0"}`)
+	}))
+	defer server.Close()
+	t.Setenv("SENTINEL_UPDATE_URL", server.URL)
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitIssues, runCLIMode([]string{"self-update", "--check"}, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "dev-env-sentinel 99.0.0 is available")
+}
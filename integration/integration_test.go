@@ -280,7 +280,7 @@ func TestIntegration_EcosystemDetectionWithRealProject(t *testing.T) {
 	require.NotEmpty(t, configs, "Should load at least one config file")
 
 	// Detect ecosystems
-	ecosystems, err := detector.DetectEcosystems(projectRoot, configs)
+	ecosystems, err := detector.DetectEcosystems(context.Background(), projectRoot, configs)
 	require.NoError(t, err)
 	
 	// Log what was found for debugging
@@ -17,6 +17,15 @@ const (
 	EventEnvVarAudit             EventType = "env_var_audit"
 	EventCheckLicenseStatus      EventType = "check_license_status"
 	EventGetProLicense           EventType = "get_pro_license"
+	EventCheckTrustStores        EventType = "check_trust_stores"
+	EventCheckLocale             EventType = "check_locale"
+	EventCheckPortability        EventType = "check_portability"
+	EventCheckBuildWrappers      EventType = "check_build_wrappers"
+	EventCheckMirrors            EventType = "check_mirrors"
+	EventCheckContainerRuntime   EventType = "check_container_runtime"
+	EventCheckDockerfileParity   EventType = "check_dockerfile_parity"
+	EventCheckNixEnvironment     EventType = "check_nix_environment"
+	EventPreflightCheck          EventType = "preflight_check"
 
 	// Premium tier events (billable)
 	EventReconcileEnvironment    EventType = "reconcile_environment"    // $0.05
@@ -114,6 +123,15 @@ func GetEventPrice(eventType EventType) float64 {
 		EventEnvVarAudit:             0.00,
 		EventCheckLicenseStatus:      0.00,
 		EventGetProLicense:           0.00,
+		EventCheckTrustStores:        0.00,
+		EventCheckLocale:             0.00,
+		EventCheckPortability:        0.00,
+		EventCheckBuildWrappers:      0.00,
+		EventCheckMirrors:            0.00,
+		EventCheckContainerRuntime:   0.00,
+		EventCheckDockerfileParity:   0.00,
+		EventCheckNixEnvironment:     0.00,
+		EventPreflightCheck:          0.00,
 
 		// Premium tier - billable
 		EventReconcileEnvironment:    0.05, // Auto-fix is high value
@@ -142,6 +160,15 @@ func GetEventDescription(eventType EventType) string {
 		EventEnvVarAudit:             "Audit environment variables",
 		EventCheckLicenseStatus:      "Check license status",
 		EventGetProLicense:           "Get Pro license information",
+		EventCheckTrustStores:        "Check certificate trust stores",
+		EventCheckLocale:             "Check time zone, locale and clock drift",
+		EventCheckPortability:        "Check script line endings and file modes",
+		EventCheckBuildWrappers:      "Check Maven and Gradle wrapper integrity",
+		EventCheckMirrors:            "Check artifact repository mirror health",
+		EventCheckContainerRuntime:   "Check Docker/Podman daemon, context and disk usage",
+		EventCheckDockerfileParity:   "Compare Dockerfile tool versions with the local environment",
+		EventCheckNixEnvironment:     "Check the Nix/devenv dev shell is active and provides the declared tools",
+		EventPreflightCheck:          "Quick pass/fail check before a build or test run",
 		EventReconcileEnvironment:    "Auto-fix environment issues (Premium)",
 		EventAutoFix:                 "Automatic issue resolution (Premium)",
 		EventAdvancedDiagnostics:     "Advanced diagnostic analysis (Premium)",
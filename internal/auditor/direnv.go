@@ -0,0 +1,174 @@
+package auditor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/runner"
+)
+
+// DirenvReport describes the project's .envrc and whether direnv loads it into the environment
+// the sentinel and the tools it checks run in
+type DirenvReport struct {
+	Envrc     string   // .envrc path relative to the project root
+	Installed bool     // direnv is on the PATH
+	Allowed   bool     // direnv status reports the .envrc allowed
+	Loaded    bool     // The current environment was loaded by direnv from the project
+	Exports   []string // Variables the .envrc exports, directly or from the dotenv files it loads
+	Dynamic   bool     // The .envrc also loads variables that can't be listed, e.g. use flake
+	Absent    []string // Exported variables not present in the current environment
+	Provides  []string // Missing variables of the audit the .envrc would export
+	Fix       string   // What gets direnv to load the .envrc
+	Issues    []string
+}
+
+var (
+	// envrcExport matches export NAME=value and export NAME
+	envrcExport = regexp.MustCompile(`^export\s+([A-Za-z_][A-Za-z0-9_]*)`)
+	// envrcDotenv matches the stdlib functions that load a dotenv file, with its optional path
+	envrcDotenv = regexp.MustCompile(`^(?:dotenv|dotenv_if_exists)(?:\s+(\S+))?\s*$`)
+	// envrcSource matches the stdlib functions that load another .envrc
+	envrcSource = regexp.MustCompile(`^(?:source_env|source_env_if_exists)\s+(\S+)`)
+	// envrcDynamic matches the stdlib functions that export variables the file doesn't name
+	envrcDynamic = regexp.MustCompile(`^(?:use\s+\S+|use_\w+|layout\s+\S+|layout_\w+|source_up\b|source_url\b|eval\b)`)
+	// direnvAllowed matches the allowed state direnv status reports for the found .envrc;
+	// recent versions print 0 for allowed, older ones true
+	direnvAllowed = regexp.MustCompile(`(?m)^Found RC allowed (\S+)`)
+)
+
+// direnvInstalled and direnvStatus are replaced by tests
+var (
+	direnvInstalled = func() bool {
+		_, err := exec.LookPath("direnv")
+		return err == nil
+	}
+	direnvStatus = func(ctx context.Context, projectRoot string) (string, error) {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		output, err := runner.Output(ctx, projectRoot, "direnv status")
+		return string(output), err
+	}
+)
+
+// AuditDirenv checks the project's .envrc: whether direnv is installed, has the file allowed
+// and loaded it into the current environment, and which of the missing variables of an audit
+// the file would export. It returns nil when the project has no .envrc.
+func AuditDirenv(ctx context.Context, projectRoot string, missing []string) (*DirenvReport, error) {
+	path := filepath.Join(projectRoot, ".envrc")
+	if !common.FileExists(path) {
+		return nil, nil
+	}
+
+	report := &DirenvReport{Envrc: ".envrc", Exports: []string{}, Absent: []string{}, Provides: []string{}, Issues: []string{}}
+	if err := report.readEnvrc(path, 0); err != nil {
+		return nil, fmt.Errorf("failed to read .envrc: %w", err)
+	}
+	if dir := os.Getenv("DIRENV_DIR"); dir != "" {
+		rel, err := filepath.Rel(filepath.Clean(strings.TrimPrefix(dir, "-")), filepath.Clean(projectRoot))
+		report.Loaded = err == nil && !strings.HasPrefix(rel, "..")
+	}
+	report.Installed = direnvInstalled()
+	if report.Installed {
+		if status, err := direnvStatus(ctx, projectRoot); err == nil {
+			match := direnvAllowed.FindStringSubmatch(status)
+			report.Allowed = match != nil && (match[1] == "0" || match[1] == "true")
+		}
+	}
+
+	for _, name := range report.Exports {
+		if _, ok := os.LookupEnv(name); ok {
+			continue
+		}
+		report.Absent = append(report.Absent, name)
+		if contains(missing, name) {
+			report.Provides = append(report.Provides, name)
+		}
+	}
+
+	switch {
+	case !report.Installed:
+		report.Fix = "Install direnv, hook it into your shell (https://direnv.net/docs/hook.html) and run direnv allow"
+	case !report.Allowed:
+		report.Fix = "direnv allow"
+	case !report.Loaded:
+		report.Fix = "Start the editor from a shell in the project directory so direnv loads .envrc, or run commands through direnv exec ."
+	}
+	if len(report.Provides) > 0 && report.Fix != "" {
+		report.Issues = append(report.Issues, fmt.Sprintf("Exported by .envrc, which direnv hasn't loaded: %s (fix: %s)",
+			strings.Join(report.Provides, ", "), report.Fix))
+	}
+	return report, nil
+}
+
+// Fixes reports whether getting direnv to load the .envrc sets a missing variable
+func (r *DirenvReport) Fixes(name string) bool {
+	return r != nil && r.Fix != "" && contains(r.Provides, name)
+}
+
+// readEnvrc collects the variables an .envrc exports, following the dotenv files and .envrc
+// files it loads a few levels deep
+func (r *DirenvReport) readEnvrc(path string, depth int) error {
+	f, err := common.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dir := filepath.Dir(path)
+	resolve := func(name string) string {
+		name = strings.Trim(name, `"'`)
+		if filepath.IsAbs(name) {
+			return name
+		}
+		return filepath.Join(dir, name)
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if match := envrcExport.FindStringSubmatch(line); match != nil {
+			r.export(match[1])
+			continue
+		}
+		if match := envrcDotenv.FindStringSubmatch(line); match != nil {
+			file := ".env"
+			if match[1] != "" {
+				file = match[1]
+			}
+			entries, err := parseDotenvEntries(resolve(file))
+			if err != nil {
+				continue // dotenv_if_exists, or a file still to create
+			}
+			for _, entry := range entries {
+				r.export(entry.Key)
+			}
+			continue
+		}
+		if match := envrcSource.FindStringSubmatch(line); match != nil && depth < 3 {
+			target := resolve(match[1])
+			if common.DirExists(target) {
+				target = filepath.Join(target, ".envrc")
+			}
+			_ = r.readEnvrc(target, depth+1)
+			continue
+		}
+		if envrcDynamic.MatchString(line) {
+			r.Dynamic = true
+		}
+	}
+	return scanner.Err()
+}
+
+// export records an exported variable once
+func (r *DirenvReport) export(name string) {
+	if !contains(r.Exports, name) {
+		r.Exports = append(r.Exports, name)
+	}
+}
@@ -0,0 +1,84 @@
+package auditor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDirenv replaces the direnv installation for a test; status is the output of direnv status
+func fakeDirenv(t *testing.T, installed bool, status string) {
+	t.Helper()
+	oldInstalled, oldStatus := direnvInstalled, direnvStatus
+	t.Cleanup(func() { direnvInstalled, direnvStatus = oldInstalled, oldStatus })
+	direnvInstalled = func() bool { return installed }
+	direnvStatus = func(ctx context.Context, projectRoot string) (string, error) { return status, nil }
+}
+
+func TestAuditDirenv_None(t *testing.T) {
+	report, err := AuditDirenv(context.Background(), t.TempDir(), []string{"API_URL"})
+	require.NoError(t, err)
+	assert.Nil(t, report)
+}
+
+func TestAuditDirenv(t *testing.T) {
+	t.Setenv("DIRENV_DIR", "")
+	t.Setenv("EDITOR_SET", "vim")
+	for _, name := range []string{"API_URL", "DB_HOST", "SHARED_FLAG"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "config"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".envrc"), []byte(`# Project environment
+export API_URL=http://localhost:8080
+export EDITOR_SET
+dotenv_if_exists .env.local
+dotenv_if_exists .env.missing
+source_env config
+use flake
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".env.local"), []byte("DB_HOST=localhost\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "config", ".envrc"), []byte("export SHARED_FLAG=1\n"), 0644))
+	ctx := context.Background()
+	missing := []string{"API_URL", "DB_HOST", "OTHER"}
+
+	fakeDirenv(t, false, "")
+	report, err := AuditDirenv(ctx, root, missing)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"API_URL", "EDITOR_SET", "DB_HOST", "SHARED_FLAG"}, report.Exports)
+	assert.True(t, report.Dynamic)
+	assert.Equal(t, []string{"API_URL", "DB_HOST", "SHARED_FLAG"}, report.Absent)
+	assert.Equal(t, []string{"API_URL", "DB_HOST"}, report.Provides)
+	assert.False(t, report.Installed)
+	assert.Contains(t, report.Fix, "Install direnv")
+	assert.True(t, report.Fixes("DB_HOST"))
+	assert.False(t, report.Fixes("OTHER"))
+
+	fakeDirenv(t, true, "Found RC path "+root+"/.envrc\nFound RC allowed 1\n")
+	report, err = AuditDirenv(ctx, root, missing)
+	require.NoError(t, err)
+	assert.False(t, report.Allowed)
+	assert.Equal(t, "direnv allow", report.Fix)
+	assert.Equal(t, []string{"Exported by .envrc, which direnv hasn't loaded: API_URL, DB_HOST (fix: direnv allow)"}, report.Issues)
+
+	fakeDirenv(t, true, "Found RC allowed true\n")
+	report, err = AuditDirenv(ctx, root, missing)
+	require.NoError(t, err)
+	assert.True(t, report.Allowed)
+	assert.Contains(t, report.Fix, "direnv exec .")
+
+	// Allowed and loaded: nothing left for direnv to fix
+	t.Setenv("DIRENV_DIR", "-"+root)
+	fakeDirenv(t, true, "Found RC allowed 0\n")
+	report, err = AuditDirenv(ctx, root, missing)
+	require.NoError(t, err)
+	assert.True(t, report.Loaded)
+	assert.Empty(t, report.Fix)
+	assert.Empty(t, report.Issues)
+	assert.False(t, report.Fixes("API_URL"))
+}
@@ -0,0 +1,278 @@
+package auditor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+)
+
+// dotenvTemplates are the committed files that document a project's variables, in preference order
+var dotenvTemplates = []string{".env.example", ".env.template", ".env.sample", ".env.dist"}
+
+// DotenvTemplateReport compares the local .env against the committed template
+type DotenvTemplateReport struct {
+	Template    string            // Template file name, e.g. .env.example
+	Declared    []string          // Variables declared by the template
+	Missing     []string          // Declared variables set neither in .env nor the environment
+	Undeclared  []string          // Variables in .env that the template does not declare
+	Suggestions map[string]string // Undeclared variable -> likely intended template variable
+	IsHealthy   bool
+	Issues      []string
+}
+
+// dotenvEntry is a KEY=VALUE line of a dotenv file
+type dotenvEntry struct {
+	Key   string
+	Value string
+}
+
+// GeneratedDotenv is a candidate .env built from the template
+type GeneratedDotenv struct {
+	Template     string
+	Content      string
+	FromTemplate []string // Variables filled with the template's example value
+	Kept         []string // Variables whose existing .env value was kept
+}
+
+// FindDotenvTemplate returns the path of the project's dotenv template, or "" if there is none
+func FindDotenvTemplate(projectRoot string) string {
+	for _, name := range dotenvTemplates {
+		path := filepath.Join(projectRoot, name)
+		if common.FileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// AuditDotenvTemplate validates the local .env against the project's .env.example
+// (or .env.template). It returns nil when the project has no template.
+func AuditDotenvTemplate(projectRoot string) (*DotenvTemplateReport, error) {
+	templatePath := FindDotenvTemplate(projectRoot)
+	if templatePath == "" {
+		return nil, nil
+	}
+
+	entries, err := parseDotenvEntries(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", templatePath, err)
+	}
+
+	local, err := parseDotenvValues(filepath.Join(projectRoot, ".env"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read .env: %w", err)
+	}
+
+	report := &DotenvTemplateReport{
+		Template:    filepath.Base(templatePath),
+		Declared:    []string{},
+		Missing:     []string{},
+		Undeclared:  []string{},
+		Suggestions: make(map[string]string),
+		IsHealthy:   true,
+		Issues:      []string{},
+	}
+
+	declared := make(map[string]bool)
+	for _, entry := range entries {
+		declared[entry.Key] = true
+		report.Declared = append(report.Declared, entry.Key)
+
+		if _, ok := local[entry.Key]; ok {
+			continue
+		}
+		if _, ok := os.LookupEnv(entry.Key); ok {
+			continue
+		}
+		report.Missing = append(report.Missing, entry.Key)
+		report.Issues = append(report.Issues, fmt.Sprintf("Variable %s required by %s is not set in .env or the environment", entry.Key, report.Template))
+		report.IsHealthy = false
+	}
+
+	var localKeys []string
+	for key := range local {
+		localKeys = append(localKeys, key)
+	}
+	sort.Strings(localKeys)
+
+	for _, key := range localKeys {
+		if declared[key] {
+			continue
+		}
+		report.Undeclared = append(report.Undeclared, key)
+
+		// A near miss of a declared name is most likely a typo
+		if suggestion := closestName(key, report.Declared); suggestion != "" {
+			report.Suggestions[key] = suggestion
+			report.Issues = append(report.Issues, fmt.Sprintf("Variable %s in .env is not declared in %s (did you mean %s?)", key, report.Template, suggestion))
+			report.IsHealthy = false
+		}
+	}
+
+	return report, nil
+}
+
+// TemplateValues returns the example values documented in the project's dotenv
+// template. It returns an empty map when the project has no template.
+func TemplateValues(projectRoot string) (map[string]string, error) {
+	templatePath := FindDotenvTemplate(projectRoot)
+	if templatePath == "" {
+		return map[string]string{}, nil
+	}
+	return parseDotenvValues(templatePath)
+}
+
+// LocalDotenvValues returns the variables defined in the project's .env, or an empty map
+func LocalDotenvValues(projectRoot string) (map[string]string, error) {
+	values, err := parseDotenvValues(filepath.Join(projectRoot, ".env"))
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	return values, err
+}
+
+// GenerateDotenv builds a candidate .env from the template, keeping values already in
+// the local .env and appending local variables the template does not declare
+func GenerateDotenv(projectRoot string) (*GeneratedDotenv, error) {
+	templatePath := FindDotenvTemplate(projectRoot)
+	if templatePath == "" {
+		return nil, &common.ErrNotFound{Resource: "dotenv template", Path: filepath.Join(projectRoot, dotenvTemplates[0])}
+	}
+
+	content, err := common.ReadFile(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", templatePath, err)
+	}
+
+	local, err := parseDotenvValues(filepath.Join(projectRoot, ".env"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read .env: %w", err)
+	}
+
+	generated := &GeneratedDotenv{Template: filepath.Base(templatePath)}
+	declared := make(map[string]bool)
+
+	var out strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(string(content), "\n"), "\n") {
+		entry, ok := parseDotenvLine(line)
+		if !ok {
+			out.WriteString(line + "\n")
+			continue
+		}
+
+		declared[entry.Key] = true
+		if value, exists := local[entry.Key]; exists {
+			generated.Kept = append(generated.Kept, entry.Key)
+			out.WriteString(fmt.Sprintf("%s=%s\n", entry.Key, quoteDotenvValue(value)))
+			continue
+		}
+		generated.FromTemplate = append(generated.FromTemplate, entry.Key)
+		out.WriteString(fmt.Sprintf("%s=%s\n", entry.Key, quoteDotenvValue(entry.Value)))
+	}
+
+	var extra []string
+	for key := range local {
+		if !declared[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	if len(extra) > 0 {
+		out.WriteString(fmt.Sprintf("\n# Not declared in %s\n", generated.Template))
+		for _, key := range extra {
+			generated.Kept = append(generated.Kept, key)
+			out.WriteString(fmt.Sprintf("%s=%s\n", key, quoteDotenvValue(local[key])))
+		}
+	}
+
+	generated.Content = out.String()
+	return generated, nil
+}
+
+// parseDotenvEntries reads the KEY=VALUE entries of a dotenv file in order
+func parseDotenvEntries(path string) ([]dotenvEntry, error) {
+	content, err := common.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []dotenvEntry
+	for _, line := range strings.Split(string(content), "\n") {
+		if entry, ok := parseDotenvLine(line); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// parseDotenvLine parses a KEY=VALUE line, skipping comments and blank lines
+func parseDotenvLine(line string) (dotenvEntry, bool) {
+	line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "export "))
+	if strings.HasPrefix(line, "#") || line == "" {
+		return dotenvEntry{}, false
+	}
+	idx := strings.Index(line, "=")
+	if idx <= 0 {
+		return dotenvEntry{}, false
+	}
+
+	value := strings.TrimSpace(line[idx+1:])
+	// Strip an inline comment after the value
+	if value != "" && strings.ContainsRune(`"'`, rune(value[0])) {
+		if end := strings.IndexByte(value[1:], value[0]); end >= 0 {
+			value = value[:end+2]
+		}
+	} else if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return dotenvEntry{Key: strings.TrimSpace(line[:idx]), Value: unquote(value)}, true
+}
+
+// FormatDotenvLine formats a KEY=VALUE line, quoting the value when needed
+func FormatDotenvLine(key, value string) string {
+	return key + "=" + quoteDotenvValue(value)
+}
+
+// quoteDotenvValue quotes a value that contains whitespace or comment characters
+func quoteDotenvValue(value string) string {
+	if strings.ContainsAny(value, " \t#\"'") {
+		return fmt.Sprintf("%q", value)
+	}
+	return value
+}
+
+// closestName returns the candidate within a small edit distance of name, if any
+func closestName(name string, candidates []string) string {
+	best, bestDistance := "", 3
+	for _, candidate := range candidates {
+		if d := editDistance(strings.ToUpper(name), strings.ToUpper(candidate)); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
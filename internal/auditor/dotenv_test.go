@@ -0,0 +1,89 @@
+package auditor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dev-env-sentinel/internal/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTemplate = `# Database
+SENTINEL_TEST_DB_URL=postgres://localhost/app
+SENTINEL_TEST_API_KEY=
+SENTINEL_TEST_GREETING="hello world" # shown on the home page
+`
+
+func TestAuditDotenvTemplate_None(t *testing.T) {
+	report, err := AuditDotenvTemplate(t.TempDir())
+	require.NoError(t, err)
+	assert.Nil(t, report)
+}
+
+func TestAuditDotenvTemplate(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".env.example"), []byte(testTemplate), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".env"), []byte(`SENTINEL_TEST_DB_URL=postgres://db/app
+SENTINEL_TEST_API_KYE=abc
+SENTINEL_TEST_LOCAL_ONLY=1
+`), 0644))
+	t.Setenv("SENTINEL_TEST_GREETING", "hi")
+
+	report, err := AuditDotenvTemplate(root)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+
+	assert.Equal(t, ".env.example", report.Template)
+	assert.Equal(t, []string{"SENTINEL_TEST_DB_URL", "SENTINEL_TEST_API_KEY", "SENTINEL_TEST_GREETING"}, report.Declared)
+	assert.Equal(t, []string{"SENTINEL_TEST_API_KEY"}, report.Missing)
+	assert.Equal(t, []string{"SENTINEL_TEST_API_KYE", "SENTINEL_TEST_LOCAL_ONLY"}, report.Undeclared)
+	assert.Equal(t, map[string]string{"SENTINEL_TEST_API_KYE": "SENTINEL_TEST_API_KEY"}, report.Suggestions)
+	assert.False(t, report.IsHealthy)
+	assert.Len(t, report.Issues, 2)
+}
+
+func TestAuditDotenvTemplate_UndeclaredOnly(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".env.template"), []byte("SENTINEL_TEST_DB_URL=\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".env"), []byte("SENTINEL_TEST_DB_URL=x\nDEBUG=true\n"), 0644))
+
+	report, err := AuditDotenvTemplate(root)
+	require.NoError(t, err)
+	assert.True(t, report.IsHealthy, "undeclared variables without a close match are not an error")
+	assert.Equal(t, []string{"DEBUG"}, report.Undeclared)
+}
+
+func TestGenerateDotenv(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".env.example"), []byte(testTemplate), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".env"), []byte("SENTINEL_TEST_DB_URL=postgres://db/app\nEXTRA=1\n"), 0644))
+
+	generated, err := GenerateDotenv(root)
+	require.NoError(t, err)
+
+	assert.Equal(t, `# Database
+SENTINEL_TEST_DB_URL=postgres://db/app
+SENTINEL_TEST_API_KEY=
+SENTINEL_TEST_GREETING="hello world"
+
+# Not declared in .env.example
+EXTRA=1
+`, generated.Content)
+	assert.Equal(t, []string{"SENTINEL_TEST_API_KEY", "SENTINEL_TEST_GREETING"}, generated.FromTemplate)
+	assert.Equal(t, []string{"SENTINEL_TEST_DB_URL", "EXTRA"}, generated.Kept)
+}
+
+func TestGenerateDotenv_NoTemplate(t *testing.T) {
+	_, err := GenerateDotenv(t.TempDir())
+	var notFound *common.ErrNotFound
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("API_KEY", "API_KEY"))
+	assert.Equal(t, 2, editDistance("API_KYE", "API_KEY"))
+	assert.Equal(t, 1, editDistance("DB_URL", "DB_URI"))
+	assert.Equal(t, "", closestName("DEBUG", []string{"DB_URL"}))
+}
@@ -1,10 +1,12 @@
 package auditor
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"dev-env-sentinel/internal/common"
@@ -13,24 +15,34 @@ import (
 
 // EnvVarReference represents a reference to an environment variable
 type EnvVarReference struct {
-	Name      string
-	File      string
-	Line      int
-	Pattern   string
-	IsSet     bool
-	Value     string
+	Name       string
+	File       string
+	Line       int
+	Pattern    string
+	IsSet      bool
+	Value      string
+	Source     string // Where the value came from: "environment" or ".env"
+	Default    string // Fallback value supplied in code or a placeholder
+	HasDefault bool   // The reference has a fallback, so the variable is optional
+	dotenv     bool   // The framework loads .env for this reference itself
 }
 
 // EnvVarReport contains environment variable audit results
 type EnvVarReport struct {
-	References []EnvVarReference
-	Missing    []string
-	IsHealthy  bool
-	Issues     []string
+	References     []EnvVarReference
+	Missing        []string
+	IsHealthy      bool
+	Issues         []string
+	SpringProfiles []string              // Active Spring profiles used to resolve application config placeholders
+	Template       *DotenvTemplateReport // Local .env checked against .env.example, if the project has one
+	Direnv         *DirenvReport         // The project's .envrc and whether direnv loaded it, if it has one
+	Ecosystems     []string              // Ecosystems audited, when the audits of several are combined
+	MissingIn      map[string][]string   // Missing variable -> ecosystems referencing it, in combined reports
 }
 
-// AuditEnvironmentVariables audits environment variables for an ecosystem
-func AuditEnvironmentVariables(projectRoot string, cfg *config.EcosystemConfig) (*EnvVarReport, error) {
+// AuditEnvironmentVariables audits environment variables for an ecosystem. Scanning the
+// project stops with ctx's error once ctx is done.
+func AuditEnvironmentVariables(ctx context.Context, projectRoot string, cfg *config.EcosystemConfig) (*EnvVarReport, error) {
 	report := &EnvVarReport{
 		References: []EnvVarReference{},
 		Missing:    []string{},
@@ -39,11 +51,27 @@ func AuditEnvironmentVariables(projectRoot string, cfg *config.EcosystemConfig)
 	}
 
 	// Find all environment variable references in code
-	refs, err := findEnvVarReferences(projectRoot, cfg.Ecosystem.Environment.VariablePatterns)
+	refs, loadsDotenv, err := scanEnvVarReferences(ctx, projectRoot, cfg.Ecosystem.Environment.VariablePatterns, cfg.Ecosystem.Environment.ConfigFiles)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find env var references: %w", err)
 	}
 
+	// Resolve Spring Boot placeholders for the active profiles only
+	spring, err := LoadSpringConfig(projectRoot)
+	if err != nil {
+		report.IsHealthy = false
+		report.Issues = append(report.Issues, fmt.Sprintf("Failed to load Spring config: %v", err))
+	} else if spring != nil {
+		report.SpringProfiles = spring.ActiveProfiles
+		refs = append(refs, spring.references()...)
+	}
+
+	// Variables in the project's .env count as set when something loads it
+	dotenvVars := map[string]string{}
+	if values, err := parseDotenvValues(filepath.Join(projectRoot, ".env")); err == nil {
+		dotenvVars = values
+	}
+
 	// Check which variables are set
 	missing := make(map[string]bool)
 	for i := range refs {
@@ -51,7 +79,12 @@ func AuditEnvironmentVariables(projectRoot string, cfg *config.EcosystemConfig)
 		if value, exists := os.LookupEnv(ref.Name); exists {
 			ref.IsSet = true
 			ref.Value = value
-		} else {
+			ref.Source = "environment"
+		} else if value, exists := dotenvVars[ref.Name]; exists && (loadsDotenv || ref.dotenv) {
+			ref.IsSet = true
+			ref.Value = value
+			ref.Source = ".env"
+		} else if !ref.HasDefault {
 			ref.IsSet = false
 			missing[ref.Name] = true
 			report.IsHealthy = false
@@ -59,16 +92,24 @@ func AuditEnvironmentVariables(projectRoot string, cfg *config.EcosystemConfig)
 		report.References = append(report.References, *ref)
 	}
 
-	// Convert missing map to slice
+	// Convert missing map to slice, sorted so reports don't change between runs
+	var names []string
 	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
 		report.Missing = append(report.Missing, name)
 		report.Issues = append(report.Issues, fmt.Sprintf("Missing environment variable: %s", name))
 	}
 
 	// Check config files for declared variables
-	configVars, err := findConfigFileVars(projectRoot, cfg.Ecosystem.Environment.ConfigFiles)
+	configVars, err := findConfigFileVars(ctx, projectRoot, cfg.Ecosystem.Environment.ConfigFiles)
 	if err == nil {
 		for _, varName := range configVars {
+			if _, loaded := dotenvVars[varName]; loaded && loadsDotenv {
+				continue
+			}
 			if _, exists := os.LookupEnv(varName); !exists {
 				if !contains(report.Missing, varName) {
 					report.Missing = append(report.Missing, varName)
@@ -79,15 +120,106 @@ func AuditEnvironmentVariables(projectRoot string, cfg *config.EcosystemConfig)
 		}
 	}
 
+	// Check the local .env against the committed template
+	template, err := AuditDotenvTemplate(projectRoot)
+	if err != nil {
+		report.Issues = append(report.Issues, err.Error())
+	} else if template != nil {
+		report.Template = template
+		for _, name := range template.Missing {
+			if !contains(report.Missing, name) {
+				report.Missing = append(report.Missing, name)
+			}
+		}
+		report.Issues = append(report.Issues, template.Issues...)
+		if !template.IsHealthy {
+			report.IsHealthy = false
+		}
+	}
+
 	return report, nil
 }
 
+// AddDirenv adds the audit of the project's .envrc to the report
+func (r *EnvVarReport) AddDirenv(direnv *DirenvReport) {
+	if direnv == nil {
+		return
+	}
+	r.Direnv = direnv
+	r.Issues = append(r.Issues, direnv.Issues...)
+}
+
+// CombineEnvVarReports merges the audits of several ecosystems, given in the same order as
+// their IDs, into one report. A variable or issue found by several ecosystems is listed
+// once, with all the ecosystems that reported it.
+func CombineEnvVarReports(ecosystems []string, reports []*EnvVarReport) *EnvVarReport {
+	if len(reports) == 1 {
+		return reports[0]
+	}
+
+	combined := &EnvVarReport{
+		References: []EnvVarReference{},
+		Missing:    []string{},
+		IsHealthy:  true,
+		Issues:     []string{},
+		Ecosystems: ecosystems,
+		MissingIn:  make(map[string][]string),
+	}
+	seenRefs := make(map[string]bool)
+	for i, report := range reports {
+		if !report.IsHealthy {
+			combined.IsHealthy = false
+		}
+		for _, ref := range report.References {
+			key := fmt.Sprintf("%s\x00%s\x00%d", ref.Name, ref.File, ref.Line)
+			if !seenRefs[key] {
+				seenRefs[key] = true
+				combined.References = append(combined.References, ref)
+			}
+		}
+		for _, name := range report.Missing {
+			if _, ok := combined.MissingIn[name]; !ok {
+				combined.Missing = append(combined.Missing, name)
+			}
+			if !contains(combined.MissingIn[name], ecosystems[i]) {
+				combined.MissingIn[name] = append(combined.MissingIn[name], ecosystems[i])
+			}
+		}
+		for _, issue := range report.Issues {
+			if !contains(combined.Issues, issue) {
+				combined.Issues = append(combined.Issues, issue)
+			}
+		}
+		if combined.SpringProfiles == nil {
+			combined.SpringProfiles = report.SpringProfiles
+		}
+		if combined.Template == nil {
+			combined.Template = report.Template
+		}
+	}
+	return combined
+}
+
 // findEnvVarReferences finds environment variable references in code
-func findEnvVarReferences(projectRoot string, patterns []string) ([]EnvVarReference, error) {
+func findEnvVarReferences(ctx context.Context, projectRoot string, patterns []string) ([]EnvVarReference, error) {
+	refs, _, err := scanEnvVarReferences(ctx, projectRoot, patterns, nil)
+	return refs, err
+}
+
+// scanEnvVarReferences finds environment variable references using the config
+// patterns and the built-in pattern library, and reports whether any source
+// file loads a .env file at runtime. Placeholders are only resolved in known
+// config files: Spring and compose files, and those matching configFiles.
+func scanEnvVarReferences(ctx context.Context, projectRoot string, patterns, configFiles []string) ([]EnvVarReference, bool, error) {
 	var refs []EnvVarReference
+	loadsDotenv := false
+	placeholderFiles := matchConfigFiles(ctx, projectRoot, configFiles)
 
 	// Walk through source directories
-	err := filepath.Walk(projectRoot, func(path string, info os.FileInfo, err error) error {
+	err := common.Walk(projectRoot, func(path string, info os.FileInfo, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		if err != nil {
 			return nil // Skip errors
 		}
@@ -104,24 +236,50 @@ func findEnvVarReferences(projectRoot string, patterns []string) ([]EnvVarRefere
 			return nil
 		}
 
-		// Only check source files
-		if !isSourceFile(path) {
+		// Only check source files and files that can hold ${VAR} placeholders
+		if !isSourceFile(path) && !isPlaceholderFile(path, placeholderFiles) {
 			return nil
 		}
 
+		// Spring application config is resolved per active profile by LoadSpringConfig
+		if isSpringConfigFile(path) {
+			if rel, err := filepath.Rel(projectRoot, filepath.Dir(path)); err == nil && contains(springLocations, rel) {
+				return nil
+			}
+		}
+
 		// Read file content
-		content, err := os.ReadFile(path)
+		content, err := common.ReadFile(path)
 		if err != nil {
 			return nil
 		}
 
-		// Check each pattern
+		if usesDotenv(string(content)) {
+			loadsDotenv = true
+		}
+
+		// Check each pattern; a reference matched by several patterns is kept once
+		seen := make(map[string]int)
+		add := func(ref EnvVarReference) {
+			key := fmt.Sprintf("%d:%s", ref.Line, ref.Name)
+			if i, ok := seen[key]; ok {
+				// Prefer the match that saw a fallback value
+				if ref.HasDefault && !refs[i].HasDefault {
+					refs[i].Default, refs[i].HasDefault = ref.Default, true
+				}
+				return
+			}
+			seen[key] = len(refs)
+			refs = append(refs, ref)
+		}
+
+		builtins := builtinPatternsFor(path)
 		lines := strings.Split(string(content), "\n")
 		for lineNum, line := range lines {
 			for _, pattern := range patterns {
 				matches := findPatternMatches(line, pattern)
 				for _, match := range matches {
-					refs = append(refs, EnvVarReference{
+					add(EnvVarReference{
 						Name:    match,
 						File:    path,
 						Line:    lineNum + 1,
@@ -130,12 +288,25 @@ func findEnvVarReferences(projectRoot string, patterns []string) ([]EnvVarRefere
 					})
 				}
 			}
+			for _, builtin := range builtins {
+				for _, match := range builtin.match(line) {
+					add(EnvVarReference{
+						Name:       match.Name,
+						File:       path,
+						Line:       lineNum + 1,
+						Pattern:    builtin.re.String(),
+						Default:    match.Default,
+						HasDefault: match.HasDefault,
+						dotenv:     match.Dotenv,
+					})
+				}
+			}
 		}
 
 		return nil
 	})
 
-	return refs, err
+	return refs, loadsDotenv, err
 }
 
 // findPatternMatches finds matches for a regex pattern in a line
@@ -165,18 +336,47 @@ func isSourceFile(path string) bool {
 			return true
 		}
 	}
-	return false
+	language, ok := languageExtensions[ext]
+	return ok && language != "config"
+}
+
+// composeFile matches Docker Compose file names
+var composeFile = regexp.MustCompile(`^(?:docker-)?compose(?:[.-][A-Za-z0-9_.-]+)?\.ya?ml$`)
+
+// isPlaceholderFile checks if a file is a config file that may reference variables through
+// placeholders. Other YAML files, such as CI pipelines, use ${VAR} for variables set elsewhere.
+func isPlaceholderFile(path string, configFiles map[string]bool) bool {
+	if languageExtensions[strings.ToLower(filepath.Ext(path))] != "config" {
+		return false
+	}
+	base := filepath.Base(path)
+	return isSpringConfigFile(path) || composeFile.MatchString(base) || configFiles[filepath.Clean(path)]
+}
+
+// matchConfigFiles finds the files matching the config file patterns of an ecosystem
+func matchConfigFiles(ctx context.Context, projectRoot string, configFiles []string) map[string]bool {
+	matched := make(map[string]bool)
+	for _, pattern := range configFiles {
+		matches, err := common.FindFilesByPattern(ctx, filepath.Join(projectRoot, common.ExpandPattern(pattern)))
+		if err != nil {
+			continue
+		}
+		for _, match := range matches {
+			matched[filepath.Clean(match)] = true
+		}
+	}
+	return matched
 }
 
 // findConfigFileVars finds variables declared in config files
-func findConfigFileVars(projectRoot string, configFiles []string) ([]string, error) {
+func findConfigFileVars(ctx context.Context, projectRoot string, configFiles []string) ([]string, error) {
 	var vars []string
 
 	for _, pattern := range configFiles {
 		expanded := common.ExpandPattern(pattern)
 		fullPattern := filepath.Join(projectRoot, expanded)
 
-		matches, err := common.FindFilesByPattern(fullPattern)
+		matches, err := common.FindFilesByPattern(ctx, fullPattern)
 		if err != nil {
 			continue
 		}
@@ -195,7 +395,7 @@ func findConfigFileVars(projectRoot string, configFiles []string) ([]string, err
 
 // parseConfigFile parses a config file for environment variables
 func parseConfigFile(path string) ([]string, error) {
-	content, err := os.ReadFile(path)
+	content, err := common.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
@@ -223,6 +423,20 @@ func parseConfigFile(path string) ([]string, error) {
 	return vars, nil
 }
 
+// parseDotenvValues reads KEY=VALUE pairs from a .env file
+func parseDotenvValues(path string) (map[string]string, error) {
+	entries, err := parseDotenvEntries(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	for _, entry := range entries {
+		values[entry.Key] = entry.Value
+	}
+	return values, nil
+}
+
 // contains checks if a slice contains a string
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
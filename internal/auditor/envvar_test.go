@@ -1,6 +1,7 @@
 package auditor
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -46,7 +47,7 @@ public class App {
 	os.Setenv("DATABASE_URL", "postgres://localhost/db")
 	defer os.Unsetenv("DATABASE_URL")
 
-	report, err := AuditEnvironmentVariables(tmpDir, cfg)
+	report, err := AuditEnvironmentVariables(context.Background(), tmpDir, cfg)
 	require.NoError(t, err)
 	require.NotNil(t, report)
 
@@ -75,6 +76,45 @@ public class App {
 	assert.Contains(t, report.Missing, "API_KEY")
 }
 
+func TestAuditEnvironmentVariables_MissingSorted(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(`os.Getenv("ZETA_URL")
+os.Getenv("ALPHA_KEY")
+os.Getenv("MID_TOKEN")`), 0644))
+	cfg := &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		Environment: config.Environment{VariablePatterns: []string{`os\.Getenv\("([A-Z_]+)"\)`}},
+	}}
+
+	report, err := AuditEnvironmentVariables(context.Background(), tmpDir, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ALPHA_KEY", "MID_TOKEN", "ZETA_URL"}, report.Missing)
+	assert.Equal(t, "Missing environment variable: ALPHA_KEY", report.Issues[0])
+}
+
+func TestAuditEnvironmentVariables_PlaceholderFiles(t *testing.T) {
+	for _, name := range []string{"CI_REGISTRY_IMAGE", "CI_COMMIT_SHA", "PG_PASSWORD", "APP_SECRET"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\n"), 0644))
+	// CI pipelines reference variables the CI server sets
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".gitlab-ci.yml"), []byte(`build:
+  script:
+    - docker build -t ${CI_REGISTRY_IMAGE}:${CI_COMMIT_SHA} .
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "docker-compose.yml"), []byte("    password: ${PG_PASSWORD}\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "config"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "config", "app.yml"), []byte("secret: ${APP_SECRET}\n"), 0644))
+	cfg := &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		Environment: config.Environment{ConfigFiles: []string{"config/app.yml"}},
+	}}
+
+	report, err := AuditEnvironmentVariables(context.Background(), tmpDir, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"APP_SECRET", "PG_PASSWORD"}, report.Missing)
+}
+
 func TestAuditEnvironmentVariables_AllSet(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -106,7 +146,7 @@ public class App {
 	os.Setenv("DATABASE_URL", "postgres://localhost/db")
 	defer os.Unsetenv("DATABASE_URL")
 
-	report, err := AuditEnvironmentVariables(tmpDir, cfg)
+	report, err := AuditEnvironmentVariables(context.Background(), tmpDir, cfg)
 	require.NoError(t, err)
 
 	assert.True(t, report.IsHealthy)
@@ -140,7 +180,7 @@ OTHER_VAR=value`
 	os.Setenv("DATABASE_URL", "postgres://localhost/db")
 	defer os.Unsetenv("DATABASE_URL")
 
-	report, err := AuditEnvironmentVariables(tmpDir, cfg)
+	report, err := AuditEnvironmentVariables(context.Background(), tmpDir, cfg)
 	require.NoError(t, err)
 
 	// Should detect missing API_KEY and OTHER_VAR from config file
@@ -149,6 +189,18 @@ OTHER_VAR=value`
 	assert.Contains(t, report.Missing, "OTHER_VAR")
 }
 
+func TestFindConfigFileVars_DoubleStar(t *testing.T) {
+	tmpDir := t.TempDir()
+	dir := filepath.Join(tmpDir, "services", "api")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("API_KEY=x\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".env"), []byte("DB_URL=x\n"), 0644))
+
+	vars, err := findConfigFileVars(context.Background(), tmpDir, []string{"**/.env"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"API_KEY", "DB_URL"}, vars)
+}
+
 func TestFindEnvVarReferences(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -170,7 +222,7 @@ func main() {
 		`os\.Getenv\("([A-Z_][A-Z0-9_]*)"\)`,
 	}
 
-	refs, err := findEnvVarReferences(tmpDir, patterns)
+	refs, err := findEnvVarReferences(context.Background(), tmpDir, patterns)
 	require.NoError(t, err)
 	assert.Len(t, refs, 2)
 
@@ -185,6 +237,16 @@ func main() {
 	assert.True(t, names["API_KEY"])
 }
 
+func TestFindEnvVarReferences_Cancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(`os.Getenv("API_KEY")`), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := findEnvVarReferences(ctx, tmpDir, []string{`os\.Getenv\("([A-Z_]+)"\)`})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
 func TestFindPatternMatches(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -326,7 +388,7 @@ func TestFindEnvVarReferences_SkipsDirectories(t *testing.T) {
 
 	patterns := []string{`os\.Getenv\("([A-Z_][A-Z0-9_]*)"\)`}
 
-	refs, err := findEnvVarReferences(tmpDir, patterns)
+	refs, err := findEnvVarReferences(context.Background(), tmpDir, patterns)
 	require.NoError(t, err)
 
 	// Should only find DATABASE_URL, not API_KEY from node_modules
@@ -334,3 +396,29 @@ func TestFindEnvVarReferences_SkipsDirectories(t *testing.T) {
 	assert.Equal(t, "DATABASE_URL", refs[0].Name)
 }
 
+
+func TestCombineEnvVarReports(t *testing.T) {
+	ref := EnvVarReference{Name: "API_KEY", File: "src/app.js", Line: 3}
+	java := &EnvVarReport{
+		References: []EnvVarReference{ref},
+		Missing:    []string{"API_KEY"},
+		Issues:     []string{"Missing environment variable: API_KEY"},
+	}
+	node := &EnvVarReport{
+		References: []EnvVarReference{ref, {Name: "PORT", File: "src/app.js", Line: 4}},
+		Missing:    []string{"API_KEY", "PORT"},
+		Issues:     []string{"Missing environment variable: API_KEY", "Missing environment variable: PORT"},
+	}
+
+	combined := CombineEnvVarReports([]string{"java", "javascript"}, []*EnvVarReport{java, node})
+	assert.False(t, combined.IsHealthy)
+	assert.Equal(t, []string{"java", "javascript"}, combined.Ecosystems)
+	assert.Equal(t, []string{"API_KEY", "PORT"}, combined.Missing)
+	assert.Equal(t, []string{"java", "javascript"}, combined.MissingIn["API_KEY"])
+	assert.Equal(t, []string{"javascript"}, combined.MissingIn["PORT"])
+	assert.Len(t, combined.References, 2)
+	assert.Len(t, combined.Issues, 2)
+
+	// A single report is returned as is
+	assert.Same(t, java, CombineEnvVarReports([]string{"java"}, []*EnvVarReport{java}))
+}
@@ -0,0 +1,204 @@
+package auditor
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// envName matches the variable names the auditor tracks
+const envName = `(?P<name>[A-Z_][A-Z0-9_]*)`
+
+// fallback matches an inline `||`, `??` or `?:` fallback after a reference
+const fallback = `(?:\s*(?:\|\||\?\?|\?:)\s*(?P<default>[^\s,;)]+))?`
+
+// builtinPattern is an env var reference pattern shipped with the auditor.
+// The "name" group captures the variable; an optional "default" group captures
+// a fallback value, and when it participates in a match the variable is optional.
+type builtinPattern struct {
+	re *regexp.Regexp
+	// placeholder marks ${VAR:default} style patterns whose "op" group selects
+	// between a default value and a required-variable marker
+	placeholder bool
+	// implicit lists names the framework always defines (e.g. Vite's MODE)
+	implicit map[string]bool
+	// dotenv is set when the framework loads .env files for these references itself
+	dotenv bool
+}
+
+// placeholderPattern resolves Spring ${VAR:default} and Compose ${VAR:-default},
+// ${VAR-default} and ${VAR:?error} placeholders
+var placeholderPattern = builtinPattern{
+	re:          regexp.MustCompile(`\$\{` + envName + `(?:(?P<op>:-|:\?|:|-|\?)(?P<default>[^}]*))?\}`),
+	placeholder: true,
+}
+
+// builtinPatterns are the reference patterns for each language, applied in
+// addition to the patterns from the ecosystem configs
+var builtinPatterns = map[string][]builtinPattern{
+	"python": {
+		{re: regexp.MustCompile(`os\.environ\[\s*['"]` + envName + `['"]\s*\]`)},
+		{re: regexp.MustCompile(`os\.(?:getenv|environ\.get)\(\s*['"]` + envName + `['"]\s*(?:,\s*(?P<default>[^)]*))?\)`)},
+	},
+	"java": {
+		{re: regexp.MustCompile(`System\.getenv\(\s*"` + envName + `"\s*\)`)},
+		{re: regexp.MustCompile(`System\.getenv\(\)\.getOrDefault\(\s*"` + envName + `"\s*,\s*(?P<default>[^)]*)\)`)},
+		placeholderPattern,
+	},
+	"kotlin": {
+		{re: regexp.MustCompile(`System\.getenv\(\s*"` + envName + `"\s*\)` + fallback)},
+		{re: regexp.MustCompile(`System\.getenv\(\)\[\s*"` + envName + `"\s*\]` + fallback)},
+		{re: regexp.MustCompile(`System\.getenv\(\)\.getOrDefault\(\s*"` + envName + `"\s*,\s*(?P<default>[^)]*)\)`)},
+		placeholderPattern,
+	},
+	"javascript": {
+		{re: regexp.MustCompile(`process\.env\.` + envName + `\b` + fallback)},
+		{re: regexp.MustCompile(`process\.env\[\s*['"]` + envName + `['"]\s*\]` + fallback)},
+		{
+			re:       regexp.MustCompile(`import\.meta\.env\.` + envName + `\b` + fallback),
+			implicit: map[string]bool{"MODE": true, "BASE_URL": true, "PROD": true, "DEV": true, "SSR": true},
+			dotenv:   true,
+		},
+		{re: regexp.MustCompile(`Deno\.env\.get\(\s*['"]` + envName + `['"]\s*\)` + fallback)},
+		{re: regexp.MustCompile(`Bun\.env\.` + envName + `\b` + fallback)},
+	},
+	"go": {
+		{re: regexp.MustCompile(`os\.Getenv\(\s*"` + envName + `"\s*\)`)},
+		// LookupEnv callers handle the unset case themselves
+		{re: regexp.MustCompile(`os\.LookupEnv\(\s*"` + envName + `"\s*\)(?P<default>)`)},
+	},
+	"ruby": {
+		{re: regexp.MustCompile(`ENV\[\s*['"]` + envName + `['"]\s*\]` + fallback)},
+		{re: regexp.MustCompile(`ENV\.fetch\(\s*['"]` + envName + `['"]\s*(?:,\s*(?P<default>[^)]*))?\)`)},
+	},
+	"csharp": {
+		{re: regexp.MustCompile(`Environment\.GetEnvironmentVariable\(\s*"` + envName + `"\s*\)` + fallback)},
+	},
+	"rust": {
+		{re: regexp.MustCompile(`env::var\(\s*"` + envName + `"\s*\)(?:\.unwrap_or(?:_else|_default)?\((?P<default>[^)]*)\))?`)},
+		{re: regexp.MustCompile(`\benv!\(\s*"` + envName + `"\s*\)`)},
+		{re: regexp.MustCompile(`option_env!\(\s*"` + envName + `"\s*\)(?P<default>)`)},
+	},
+	"php": {
+		{re: regexp.MustCompile(`getenv\(\s*['"]` + envName + `['"]\s*\)`)},
+		{re: regexp.MustCompile(`\$_(?:ENV|SERVER)\[\s*['"]` + envName + `['"]\s*\]`)},
+	},
+	"config": {
+		placeholderPattern,
+	},
+}
+
+// languageExtensions maps file extensions to built-in pattern sets
+var languageExtensions = map[string]string{
+	".py":         "python",
+	".java":       "java",
+	".kt":         "kotlin",
+	".kts":        "kotlin",
+	".js":         "javascript",
+	".jsx":        "javascript",
+	".mjs":        "javascript",
+	".cjs":        "javascript",
+	".ts":         "javascript",
+	".tsx":        "javascript",
+	".vue":        "javascript",
+	".svelte":     "javascript",
+	".go":         "go",
+	".rb":         "ruby",
+	".cs":         "csharp",
+	".rs":         "rust",
+	".php":        "php",
+	".properties": "config",
+	".yml":        "config",
+	".yaml":       "config",
+}
+
+// dotenvLoaders detect code that loads a .env file into the process environment
+var dotenvLoaders = []*regexp.Regexp{
+	regexp.MustCompile(`require\(\s*['"]dotenv['"]\s*\)`),
+	regexp.MustCompile(`from\s+['"]dotenv(?:/config)?['"]`),
+	regexp.MustCompile(`import\s+['"]dotenv/config['"]`),
+	regexp.MustCompile(`\bload_dotenv\(`),
+	regexp.MustCompile(`\bgodotenv\.(?:Load|Overload)\(`),
+	regexp.MustCompile(`\bDotenv\.(?:load|configure)\b`),
+	regexp.MustCompile(`\bdotenv\s*\{|\bdotenv\(\)`),
+	regexp.MustCompile(`\bDotNetEnv\.Env\.Load\(|\bEnv\.Load\(`),
+	regexp.MustCompile(`\bdotenvy?::dotenv\(`),
+}
+
+// builtinPatternsFor returns the built-in patterns for a file
+func builtinPatternsFor(path string) []builtinPattern {
+	return builtinPatterns[languageExtensions[strings.ToLower(filepath.Ext(path))]]
+}
+
+// builtinMatch is a reference found by a built-in pattern
+type builtinMatch struct {
+	Name       string
+	Default    string
+	HasDefault bool
+	Dotenv     bool
+}
+
+// match finds the references to env vars in a line
+func (p builtinPattern) match(line string) []builtinMatch {
+	var result []builtinMatch
+	nameIdx := p.re.SubexpIndex("name")
+	defaultIdx := p.re.SubexpIndex("default")
+	opIdx := p.re.SubexpIndex("op")
+
+	for _, m := range p.re.FindAllStringSubmatchIndex(line, -1) {
+		name := line[m[2*nameIdx]:m[2*nameIdx+1]]
+		if p.implicit[name] {
+			continue
+		}
+
+		found := builtinMatch{Name: name, Dotenv: p.dotenv}
+		if defaultIdx >= 0 && m[2*defaultIdx] >= 0 {
+			found.Default = line[m[2*defaultIdx]:m[2*defaultIdx+1]]
+			found.HasDefault = true
+		}
+		if p.placeholder {
+			op := ""
+			if m[2*opIdx] >= 0 {
+				op = line[m[2*opIdx]:m[2*opIdx+1]]
+			}
+			// ${VAR}, ${VAR:?msg} and ${VAR?msg} all require the variable
+			found.HasDefault = op != "" && !strings.HasSuffix(op, "?")
+			if !found.HasDefault {
+				found.Default = ""
+			}
+		}
+		if found.HasDefault {
+			found.Default, found.HasDefault = normalizeDefault(found.Default)
+		}
+		result = append(result, found)
+	}
+	return result
+}
+
+// normalizeDefault unquotes a fallback value; a null-like fallback is not a default
+func normalizeDefault(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	switch value {
+	case "None", "null", "nil", "undefined":
+		return "", false
+	}
+	return unquote(value), true
+}
+
+// unquote strips matching single, double or back quotes
+func unquote(value string) string {
+	if len(value) >= 2 && strings.ContainsRune(`"'`+"`", rune(value[0])) && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// usesDotenv reports whether source content loads a .env file at runtime
+func usesDotenv(content string) bool {
+	for _, re := range dotenvLoaders {
+		if re.MatchString(content) {
+			return true
+		}
+	}
+	return false
+}
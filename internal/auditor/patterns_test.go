@@ -0,0 +1,114 @@
+package auditor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinPatterns(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		line       string
+		expected   string
+		hasDefault bool
+		defaultVal string
+	}{
+		{"python environ index", "app.py", `url = os.environ["DATABASE_URL"]`, "DATABASE_URL", false, ""},
+		{"python environ get default", "app.py", `port = os.environ.get("PORT", "8080")`, "PORT", true, "8080"},
+		{"python getenv none", "app.py", `key = os.getenv('API_KEY', None)`, "API_KEY", false, ""},
+		{"kotlin getenv elvis", "App.kt", `val host = System.getenv("HOST") ?: "localhost"`, "HOST", true, "localhost"},
+		{"kotlin getenv index", "build.gradle.kts", `val token = System.getenv()["GH_TOKEN"]`, "GH_TOKEN", false, ""},
+		{"java getOrDefault", "App.java", `String mode = System.getenv().getOrDefault("MODE", "dev");`, "MODE", true, "dev"},
+		{"spring value default", "App.java", `@Value("${SERVER_PORT:8080}")`, "SERVER_PORT", true, "8080"},
+		{"spring value required", "App.java", `@Value("${JWT_SECRET}")`, "JWT_SECRET", false, ""},
+		{"vite import meta", "main.ts", `const api = import.meta.env.VITE_API_URL`, "VITE_API_URL", false, ""},
+		{"node nullish default", "server.mjs", `const port = process.env.PORT ?? 3000;`, "PORT", true, "3000"},
+		{"node bracket", "server.js", `const key = process.env['API_KEY']`, "API_KEY", false, ""},
+		{"go lookup", "main.go", `v, ok := os.LookupEnv("DEBUG")`, "DEBUG", true, ""},
+		{"ruby fetch default", "config.rb", `ENV.fetch("RAILS_ENV", "development")`, "RAILS_ENV", true, "development"},
+		{"csharp", "Program.cs", `var conn = Environment.GetEnvironmentVariable("CONN");`, "CONN", false, ""},
+		{"rust unwrap_or_default", "main.rs", `let lvl = env::var("RUST_LOG").unwrap_or_default();`, "RUST_LOG", true, ""},
+		{"properties spring default", "application.properties", `spring.datasource.url=${DB_URL:jdbc:h2:mem:test}`, "DB_URL", true, "jdbc:h2:mem:test"},
+		{"compose default", "docker-compose.yml", `image: postgres:${PG_VERSION:-16}`, "PG_VERSION", true, "16"},
+		{"compose required", "docker-compose.yml", `password: ${PG_PASSWORD:?set PG_PASSWORD}`, "PG_PASSWORD", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var matches []builtinMatch
+			for _, pattern := range builtinPatternsFor(tt.file) {
+				matches = append(matches, pattern.match(tt.line)...)
+			}
+			require.NotEmpty(t, matches)
+			assert.Equal(t, tt.expected, matches[0].Name)
+			assert.Equal(t, tt.hasDefault, matches[0].HasDefault)
+			assert.Equal(t, tt.defaultVal, matches[0].Default)
+		})
+	}
+}
+
+func TestBuiltinPatterns_ViteImplicit(t *testing.T) {
+	var matches []builtinMatch
+	for _, pattern := range builtinPatternsFor("main.ts") {
+		matches = append(matches, pattern.match(`if (import.meta.env.DEV || import.meta.env.MODE === "x") {}`)...)
+	}
+	assert.Empty(t, matches)
+}
+
+func TestUsesDotenv(t *testing.T) {
+	assert.True(t, usesDotenv(`require('dotenv').config()`))
+	assert.True(t, usesDotenv(`import 'dotenv/config'`))
+	assert.True(t, usesDotenv("from dotenv import load_dotenv\nload_dotenv()"))
+	assert.True(t, usesDotenv(`godotenv.Load()`))
+	assert.False(t, usesDotenv(`const port = process.env.PORT`))
+}
+
+func TestAuditEnvironmentVariables_BuiltinPatterns(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "app.py"), []byte(`import os
+from dotenv import load_dotenv
+load_dotenv()
+secret = os.environ["SENTINEL_TEST_SECRET"]
+port = os.getenv("SENTINEL_TEST_PORT", "8080")
+url = os.environ["SENTINEL_TEST_FROM_DOTENV"]
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".env"), []byte("SENTINEL_TEST_FROM_DOTENV=\"postgres://db\"\n"), 0644))
+
+	// The config pattern overlaps the built-in one; each reference is reported once
+	cfg := &config.EcosystemConfig{
+		Ecosystem: config.Ecosystem{
+			ID: "python",
+			Environment: config.Environment{
+				VariablePatterns: []string{`os\.environ\[['"]([A-Z_][A-Z0-9_]*)['"]\]`},
+				ConfigFiles:      []string{".env"},
+			},
+		},
+	}
+
+	report, err := AuditEnvironmentVariables(context.Background(), tmpDir, cfg)
+	require.NoError(t, err)
+	require.Len(t, report.References, 3)
+
+	byName := make(map[string]EnvVarReference)
+	for _, ref := range report.References {
+		byName[ref.Name] = ref
+	}
+
+	assert.False(t, byName["SENTINEL_TEST_SECRET"].IsSet)
+	assert.True(t, byName["SENTINEL_TEST_PORT"].HasDefault)
+	assert.Equal(t, "8080", byName["SENTINEL_TEST_PORT"].Default)
+	assert.True(t, byName["SENTINEL_TEST_FROM_DOTENV"].IsSet)
+	assert.Equal(t, ".env", byName["SENTINEL_TEST_FROM_DOTENV"].Source)
+	assert.Equal(t, "postgres://db", byName["SENTINEL_TEST_FROM_DOTENV"].Value)
+
+	assert.False(t, report.IsHealthy)
+	assert.Equal(t, []string{"SENTINEL_TEST_SECRET"}, report.Missing)
+}
@@ -0,0 +1,417 @@
+package auditor
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+	"gopkg.in/yaml.v3"
+)
+
+// springLocations are the directories Spring Boot loads application config from, in load order
+var springLocations = []string{
+	filepath.Join("src", "main", "resources"),
+	filepath.Join("src", "main", "resources", "config"),
+	".",
+	"config",
+}
+
+// springConfigFile matches application.properties, application.yml and application-{profile}.yml
+var springConfigFile = regexp.MustCompile(`^application(?:-([A-Za-z0-9_.-]+))?\.(?:properties|ya?ml)$`)
+
+// springEnvName matches placeholder names the auditor treats as environment variables
+var springEnvName = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// SpringConfig is the Spring Boot configuration that applies to the active profiles
+type SpringConfig struct {
+	ActiveProfiles []string
+	Files          []string          // Config files that were applied, in load order
+	Properties     map[string]string // Flattened properties from the applied documents
+	placeholders   []springProperty
+}
+
+// springProperty is a property value and where it was defined
+type springProperty struct {
+	Key   string
+	Value string
+	File  string
+	Line  int
+}
+
+// springDocument is one document of a config file (files may hold several, split by ---)
+type springDocument struct {
+	File       string
+	OnProfile  []string // Profiles the document is limited to; empty applies always
+	Properties []springProperty
+}
+
+// Placeholder is a ${NAME:default} placeholder; the default may hold further placeholders
+type Placeholder struct {
+	Name       string
+	Default    string
+	HasDefault bool
+	Nested     []Placeholder // Placeholders inside the default value
+}
+
+// isSpringConfigFile checks if a file is a Spring Boot application config file
+func isSpringConfigFile(path string) bool {
+	return springConfigFile.MatchString(filepath.Base(path))
+}
+
+// LoadSpringConfig loads the Spring Boot config for a project, resolving the active
+// profiles from SPRING_PROFILES_ACTIVE or spring.profiles.active. It returns nil
+// when the project has no application config files.
+func LoadSpringConfig(projectRoot string) (*SpringConfig, error) {
+	var base, profiled []springDocument
+	profileFiles := make(map[string][]springDocument)
+
+	for _, location := range springLocations {
+		names, err := common.ReadDir(filepath.Join(projectRoot, location))
+		if err != nil {
+			continue
+		}
+		for _, name := range names {
+			m := springConfigFile.FindStringSubmatch(name)
+			path := filepath.Join(projectRoot, location, name)
+			if m == nil || common.DirExists(path) {
+				continue
+			}
+			docs, err := parseSpringFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+			if m[1] == "" {
+				base = append(base, docs...)
+			} else {
+				profileFiles[m[1]] = append(profileFiles[m[1]], docs...)
+			}
+		}
+	}
+
+	if len(base) == 0 && len(profileFiles) == 0 {
+		return nil, nil
+	}
+
+	cfg := &SpringConfig{Properties: make(map[string]string)}
+	cfg.ActiveProfiles = activeSpringProfiles(base)
+
+	// Profile-specific files load after the base files, in the order the profiles are listed
+	for _, profile := range cfg.ActiveProfiles {
+		profiled = append(profiled, profileFiles[profile]...)
+	}
+
+	// Later documents override earlier ones, so only the winning definition of a key counts
+	var keys []string
+	winners := make(map[string]springProperty)
+	seenFiles := make(map[string]bool)
+	for _, doc := range append(base, profiled...) {
+		if !doc.appliesTo(cfg.ActiveProfiles) {
+			continue
+		}
+		if !seenFiles[doc.File] {
+			seenFiles[doc.File] = true
+			cfg.Files = append(cfg.Files, doc.File)
+		}
+		for _, prop := range doc.Properties {
+			if _, ok := winners[prop.Key]; !ok {
+				keys = append(keys, prop.Key)
+			}
+			winners[prop.Key] = prop
+			cfg.Properties[prop.Key] = prop.Value
+		}
+	}
+
+	for _, key := range keys {
+		if prop := winners[key]; strings.Contains(prop.Value, "${") {
+			cfg.placeholders = append(cfg.placeholders, prop)
+		}
+	}
+
+	return cfg, nil
+}
+
+// activeSpringProfiles resolves the active profiles, falling back to "default"
+func activeSpringProfiles(base []springDocument) []string {
+	value := os.Getenv("SPRING_PROFILES_ACTIVE")
+	var include []string
+	for _, doc := range base {
+		if len(doc.OnProfile) > 0 {
+			continue
+		}
+		for _, prop := range doc.Properties {
+			switch prop.Key {
+			case "spring.profiles.active":
+				if value == "" {
+					value = prop.Value
+				}
+			case "spring.profiles.include":
+				include = append(include, splitProfiles(prop.Value)...)
+			}
+		}
+	}
+
+	profiles := append(splitProfiles(value), include...)
+	if len(profiles) == 0 {
+		return []string{"default"}
+	}
+
+	var unique []string
+	seen := make(map[string]bool)
+	for _, profile := range profiles {
+		if !seen[profile] {
+			seen[profile] = true
+			unique = append(unique, profile)
+		}
+	}
+	return unique
+}
+
+// splitProfiles splits a comma-separated profile list
+func splitProfiles(value string) []string {
+	var profiles []string
+	for _, profile := range strings.Split(value, ",") {
+		if profile = strings.TrimSpace(profile); profile != "" && !strings.Contains(profile, "${") {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+// appliesTo reports whether a document is active for the given profiles
+func (d springDocument) appliesTo(active []string) bool {
+	if len(d.OnProfile) == 0 {
+		return true
+	}
+	for _, profile := range d.OnProfile {
+		negate := strings.HasPrefix(profile, "!")
+		name := strings.TrimPrefix(profile, "!")
+		if contains(active, name) != negate {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSpringFile parses a .properties or .yml Spring config file into documents
+func parseSpringFile(path string) ([]springDocument, error) {
+	content, err := common.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []springDocument
+	if strings.HasSuffix(path, ".properties") {
+		docs = parseSpringProperties(path, content)
+	} else {
+		docs, err = parseSpringYAML(path, content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range docs {
+		for _, prop := range docs[i].Properties {
+			if prop.Key == "spring.config.activate.on-profile" || prop.Key == "spring.profiles" {
+				docs[i].OnProfile = splitProfiles(prop.Value)
+			}
+		}
+	}
+	return docs, nil
+}
+
+// parseSpringProperties parses a .properties file; "#---" starts a new document
+func parseSpringProperties(path string, content []byte) []springDocument {
+	docs := []springDocument{{File: path}}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "#---" || line == "!---" {
+			docs = append(docs, springDocument{File: path})
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		idx := strings.IndexAny(line, "=:")
+		if idx <= 0 {
+			continue
+		}
+		docs[len(docs)-1].Properties = append(docs[len(docs)-1].Properties, springProperty{
+			Key:   strings.TrimSpace(line[:idx]),
+			Value: strings.TrimSpace(line[idx+1:]),
+			File:  path,
+			Line:  lineNum,
+		})
+	}
+	return docs
+}
+
+// parseSpringYAML parses a (possibly multi-document) YAML file into flattened properties
+func parseSpringYAML(path string, content []byte) ([]springDocument, error) {
+	var docs []springDocument
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		doc := springDocument{File: path}
+		if len(node.Content) > 0 {
+			flattenYAML("", node.Content[0], path, &doc.Properties)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// flattenYAML flattens a YAML node into dotted property keys
+func flattenYAML(prefix string, node *yaml.Node, path string, props *[]springProperty) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenYAML(key, node.Content[i+1], path, props)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			flattenYAML(fmt.Sprintf("%s[%d]", prefix, i), item, path, props)
+		}
+	case yaml.ScalarNode:
+		*props = append(*props, springProperty{Key: prefix, Value: node.Value, File: path, Line: node.Line})
+	}
+}
+
+// ParsePlaceholders finds the ${NAME:default} placeholders in a value, including nested defaults
+func ParsePlaceholders(value string) []Placeholder {
+	var placeholders []Placeholder
+	for i := 0; i < len(value); i++ {
+		if !strings.HasPrefix(value[i:], "${") {
+			continue
+		}
+		end := matchingBrace(value, i+2)
+		if end < 0 {
+			break
+		}
+
+		body := value[i+2 : end]
+		p := Placeholder{Name: body}
+		if sep := topLevelColon(body); sep >= 0 {
+			p.Name = body[:sep]
+			p.Default = body[sep+1:]
+			p.HasDefault = true
+			p.Nested = ParsePlaceholders(p.Default)
+		}
+		placeholders = append(placeholders, p)
+		i = end
+	}
+	return placeholders
+}
+
+// matchingBrace returns the index of the } closing a placeholder body starting at start
+func matchingBrace(value string, start int) int {
+	depth := 1
+	for i := start; i < len(value); i++ {
+		switch {
+		case strings.HasPrefix(value[i:], "${"):
+			depth++
+			i++
+		case value[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// topLevelColon returns the index of the first : outside nested placeholders
+func topLevelColon(body string) int {
+	depth := 0
+	for i := 0; i < len(body); i++ {
+		switch {
+		case strings.HasPrefix(body[i:], "${"):
+			depth++
+			i++
+		case body[i] == '}':
+			depth--
+		case body[i] == ':' && depth == 0:
+			return i
+		}
+	}
+	return -1
+}
+
+// unresolved returns the variables that leave a placeholder without a value.
+// A placeholder resolves if its name is defined, or if its default resolves.
+func (p Placeholder) unresolved(defined func(string) bool) []string {
+	if defined(p.Name) {
+		return nil
+	}
+	if !p.HasDefault {
+		return []string{p.Name}
+	}
+	var missing []string
+	for _, nested := range p.Nested {
+		missing = append(missing, nested.unresolved(defined)...)
+	}
+	return missing
+}
+
+// references resolves placeholders in the applied config against the environment and
+// the config's own properties, returning a reference for each environment variable
+func (c *SpringConfig) references() []EnvVarReference {
+	defined := func(name string) bool {
+		if _, ok := os.LookupEnv(name); ok {
+			return true
+		}
+		_, ok := c.Properties[name]
+		return ok
+	}
+
+	var refs []EnvVarReference
+	for _, prop := range c.placeholders {
+		for _, placeholder := range ParsePlaceholders(prop.Value) {
+			refs = append(refs, c.placeholderReferences(placeholder, prop, defined)...)
+		}
+	}
+	return refs
+}
+
+// placeholderReferences returns the references for a placeholder and, when its own
+// variable is unset, for the variables its default falls back to
+func (c *SpringConfig) placeholderReferences(p Placeholder, prop springProperty, defined func(string) bool) []EnvVarReference {
+	var refs []EnvVarReference
+	if _, isProperty := c.Properties[p.Name]; !isProperty && springEnvName.MatchString(p.Name) {
+		refs = append(refs, EnvVarReference{
+			Name:       p.Name,
+			File:       prop.File,
+			Line:       prop.Line,
+			Pattern:    prop.Key,
+			Default:    p.Default,
+			HasDefault: p.HasDefault && len(p.unresolved(defined)) == 0,
+		})
+	}
+	if !defined(p.Name) {
+		for _, nested := range p.Nested {
+			refs = append(refs, c.placeholderReferences(nested, prop, defined)...)
+		}
+	}
+	return refs
+}
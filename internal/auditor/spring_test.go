@@ -0,0 +1,152 @@
+package auditor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSpringFile writes a file under src/main/resources
+func writeSpringFile(t *testing.T, root, name, content string) {
+	t.Helper()
+	dir := filepath.Join(root, "src", "main", "resources")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func TestParsePlaceholders(t *testing.T) {
+	placeholders := ParsePlaceholders("jdbc:${DB_HOST:localhost}:${DB_PORT}/${DB_NAME:${APP_NAME:app}}")
+	require.Len(t, placeholders, 3)
+
+	assert.Equal(t, Placeholder{Name: "DB_HOST", Default: "localhost", HasDefault: true}, placeholders[0])
+	assert.Equal(t, Placeholder{Name: "DB_PORT"}, placeholders[1])
+	assert.Equal(t, "DB_NAME", placeholders[2].Name)
+	assert.Equal(t, "${APP_NAME:app}", placeholders[2].Default)
+	require.Len(t, placeholders[2].Nested, 1)
+	assert.Equal(t, "APP_NAME", placeholders[2].Nested[0].Name)
+
+	assert.Empty(t, ParsePlaceholders("${UNCLOSED"))
+}
+
+func TestLoadSpringConfig_None(t *testing.T) {
+	cfg, err := LoadSpringConfig(t.TempDir())
+	require.NoError(t, err)
+	assert.Nil(t, cfg)
+}
+
+func TestLoadSpringConfig_Profiles(t *testing.T) {
+	t.Setenv("SPRING_PROFILES_ACTIVE", "")
+	root := t.TempDir()
+	writeSpringFile(t, root, "application.properties", `spring.profiles.active=dev
+spring.datasource.url=${DB_URL}
+server.port=${PORT:8080}
+`)
+	writeSpringFile(t, root, "application-dev.yml", `spring:
+  datasource:
+    url: jdbc:h2:mem:dev
+app:
+  secret: ${DEV_SECRET}
+---
+spring:
+  config:
+    activate:
+      on-profile: cloud
+app:
+  region: ${CLOUD_REGION}
+`)
+	writeSpringFile(t, root, "application-prod.yml", `app:
+  key: ${PROD_KEY}
+`)
+
+	cfg, err := LoadSpringConfig(root)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.Equal(t, []string{"dev"}, cfg.ActiveProfiles)
+	assert.Equal(t, "jdbc:h2:mem:dev", cfg.Properties["spring.datasource.url"], "profile files override the base file")
+	assert.Len(t, cfg.Files, 2)
+
+	names := make(map[string]EnvVarReference)
+	for _, ref := range cfg.references() {
+		names[ref.Name] = ref
+	}
+	assert.NotContains(t, names, "DB_URL", "overridden by the dev profile")
+	assert.NotContains(t, names, "PROD_KEY", "prod profile is not active")
+	assert.NotContains(t, names, "CLOUD_REGION", "document limited to the cloud profile")
+	assert.Contains(t, names, "DEV_SECRET")
+	assert.True(t, names["PORT"].HasDefault)
+	assert.Equal(t, "8080", names["PORT"].Default)
+	assert.Equal(t, "server.port", names["PORT"].Pattern)
+	assert.Equal(t, 3, names["PORT"].Line)
+}
+
+func TestLoadSpringConfig_EnvironmentProfile(t *testing.T) {
+	t.Setenv("SPRING_PROFILES_ACTIVE", "prod,cloud")
+	root := t.TempDir()
+	writeSpringFile(t, root, "application.yml", `spring:
+  profiles:
+    active: dev
+`)
+	writeSpringFile(t, root, "application-prod.properties", "app.key=${PROD_KEY}\n")
+
+	cfg, err := LoadSpringConfig(root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod", "cloud"}, cfg.ActiveProfiles)
+	refs := cfg.references()
+	require.Len(t, refs, 1)
+	assert.Equal(t, "PROD_KEY", refs[0].Name)
+}
+
+func TestLoadSpringConfig_NestedDefaults(t *testing.T) {
+	t.Setenv("SPRING_PROFILES_ACTIVE", "")
+	root := t.TempDir()
+	writeSpringFile(t, root, "application.properties", `APP_HOME=/opt/app
+log.dir=${LOG_DIR:${APP_HOME}/logs}
+cache.dir=${CACHE_DIR:${SENTINEL_TEST_TMP}}
+`)
+
+	cfg, err := LoadSpringConfig(root)
+	require.NoError(t, err)
+
+	refs := make(map[string]EnvVarReference)
+	for _, ref := range cfg.references() {
+		refs[ref.Name] = ref
+	}
+	assert.True(t, refs["LOG_DIR"].HasDefault, "APP_HOME is defined as a property")
+	assert.NotContains(t, refs, "APP_HOME")
+	assert.False(t, refs["CACHE_DIR"].HasDefault, "the fallback variable is unset")
+	assert.False(t, refs["SENTINEL_TEST_TMP"].HasDefault)
+}
+
+func TestAuditEnvironmentVariables_Spring(t *testing.T) {
+	t.Setenv("SPRING_PROFILES_ACTIVE", "")
+	root := t.TempDir()
+	writeSpringFile(t, root, "application.properties", `spring.datasource.url=${SENTINEL_TEST_DB_URL}
+server.port=${SENTINEL_TEST_PORT:8080}
+`)
+	writeSpringFile(t, root, "application-prod.properties", "app.key=${SENTINEL_TEST_PROD_KEY}\n")
+
+	cfg := &config.EcosystemConfig{
+		Ecosystem: config.Ecosystem{
+			ID: "java-maven",
+			Environment: config.Environment{
+				VariablePatterns: []string{`\$\{([A-Z_][A-Z0-9_]*)\}`},
+			},
+		},
+	}
+
+	report, err := AuditEnvironmentVariables(context.Background(), root, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default"}, report.SpringProfiles)
+	assert.Equal(t, []string{"SENTINEL_TEST_DB_URL"}, report.Missing)
+	assert.Len(t, report.References, 2)
+
+	t.Setenv("SENTINEL_TEST_DB_URL", "jdbc:postgresql://localhost/app")
+	report, err = AuditEnvironmentVariables(context.Background(), root, cfg)
+	require.NoError(t, err)
+	assert.True(t, report.IsHealthy)
+}
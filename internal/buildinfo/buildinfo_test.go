@@ -0,0 +1,23 @@
+package buildinfo
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	info := Get()
+	assert.NotEmpty(t, info.Version)
+	assert.Equal(t, runtime.GOOS+"/"+runtime.GOARCH, info.Platform)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+}
+
+func TestInfo_String(t *testing.T) {
+	info := Info{Version: "1.2.3", Commit: "0a1b2c3d4e5f6a7b", Modified: true, GoVersion: "go1.24.4", Platform: "linux/amd64"}
+	assert.Equal(t, "1.2.3 (commit 0a1b2c3d4e5f-dirty, linux/amd64, go1.24.4)", info.String())
+
+	info.Commit = ""
+	assert.Equal(t, "1.2.3 (linux/amd64, go1.24.4)", info.String())
+}
@@ -0,0 +1,173 @@
+package cmdlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/privacy"
+	"dev-env-sentinel/internal/redact"
+	"dev-env-sentinel/internal/runner"
+	"dev-env-sentinel/internal/state"
+)
+
+// Dir is the subdirectory of the logs category holding command logs
+const Dir = "commands"
+
+// MaxLogBytes is the size past which a log file is rotated. One rotated file is kept.
+const MaxLogBytes = 1 << 20
+
+// MaxLogs is the number of log keys kept; the least recently written are removed first
+const MaxLogs = 200
+
+// keyPattern matches log keys: job IDs and fix fingerprints
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Log stores the full output of executed commands in one file per log key,
+// under the logs category of a state directory
+type Log struct {
+	mu     sync.Mutex
+	dir    string
+	strict bool // Strict privacy mode: no output, variable values or outside paths
+}
+
+// New creates a command log in a state directory
+func New(dir *state.Dir) *Log {
+	return &Log{dir: dir.Path(state.CategoryLogs, Dir)}
+}
+
+// SetStrictPrivacy sets whether commands are logged without their output, the values of
+// variables they set and the paths outside the directory they ran in
+func (l *Log) SetStrictPrivacy(strict bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.strict = strict
+}
+
+// Record appends a command and its output to the log of each of its keys
+func (l *Log) Record(record runner.Record) {
+	l.mu.Lock()
+	strict := l.strict
+	l.mu.Unlock()
+	if strict {
+		record = privacy.Command(record)
+	}
+	entry := redact.String(format(record))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(l.dir, 0755); err != nil {
+		return
+	}
+	for _, key := range record.Keys {
+		if keyPattern.MatchString(key) {
+			l.append(key, entry)
+		}
+	}
+}
+
+// append writes an entry to a key's log, rotating the file when it grows too large.
+// Callers hold the lock.
+func (l *Log) append(key, entry string) {
+	path := filepath.Join(l.dir, key+".log")
+	created := !common.FileExists(path)
+	if info, err := os.Stat(path); err == nil && info.Size()+int64(len(entry)) > MaxLogBytes {
+		os.Rename(path, path+".1")
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	file.WriteString(entry)
+	file.Close()
+
+	if created {
+		l.prune()
+	}
+}
+
+// prune removes the least recently written logs beyond MaxLogs. Callers hold the lock.
+func (l *Log) prune() {
+	paths, err := filepath.Glob(filepath.Join(l.dir, "*.log"))
+	if err != nil || len(paths) <= MaxLogs {
+		return
+	}
+
+	modified := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			modified[path] = info.ModTime()
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool { return modified[paths[i]].Before(modified[paths[j]]) })
+
+	for _, path := range paths[:len(paths)-MaxLogs] {
+		os.Remove(path)
+		os.Remove(path + ".1")
+	}
+}
+
+// Read returns the logged commands of a key, oldest first, including the rotated file
+func (l *Log) Read(key string) (string, error) {
+	if !keyPattern.MatchString(key) {
+		return "", fmt.Errorf("invalid log key: %q", key)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	path := filepath.Join(l.dir, key+".log")
+	var b strings.Builder
+	for _, p := range []string{path + ".1", path} {
+		if data, err := os.ReadFile(p); err == nil {
+			b.Write(data)
+		}
+	}
+	if b.Len() == 0 {
+		return "", &common.ErrNotFound{Resource: "command log", Path: key}
+	}
+	return b.String(), nil
+}
+
+// format renders a command record as a log entry
+func format(record runner.Record) string {
+	var b strings.Builder
+	kind := "check"
+	if record.Mutating {
+		kind = "fix"
+	}
+	fmt.Fprintf(&b, "=== %s %s, exit %d after %s\n", record.Started.UTC().Format(time.RFC3339), kind, record.ExitCode, record.Duration.Round(time.Millisecond))
+	fmt.Fprintf(&b, "$ %s\n", record.Command)
+	if record.Executor != "" {
+		fmt.Fprintf(&b, "on: %s\n", record.Executor)
+	}
+	if record.Dir != "" {
+		fmt.Fprintf(&b, "dir: %s\n", record.Dir)
+	}
+	if record.Error != "" {
+		fmt.Fprintf(&b, "error: %s\n", record.Error)
+	}
+	writeStream(&b, "stdout", record.Stdout)
+	writeStream(&b, "stderr", record.Stderr)
+	b.WriteString("\n")
+	return b.String()
+}
+
+// writeStream writes one output stream of a command, if it produced any
+func writeStream(b *strings.Builder, name string, output []byte) {
+	if len(output) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "--- %s\n", name)
+	b.Write(output)
+	if output[len(output)-1] != '\n' {
+		b.WriteString("\n")
+	}
+}
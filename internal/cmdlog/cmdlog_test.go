@@ -0,0 +1,112 @@
+package cmdlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/runner"
+	"dev-env-sentinel/internal/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLog(t *testing.T) (*Log, string) {
+	t.Helper()
+	dir, err := state.Open(t.TempDir())
+	require.NoError(t, err)
+	return New(dir), dir.Path(state.CategoryLogs, Dir)
+}
+
+func TestLog_RecordAndRead(t *testing.T) {
+	log, _ := newLog(t)
+
+	log.Record(runner.Record{
+		Keys:     []string{"job-1", "abc123"},
+		Command:  "mvn -q install",
+		Dir:      "/work/api",
+		Mutating: true,
+		Started:  time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+		Duration: 1500 * time.Millisecond,
+		ExitCode: 1,
+		Error:    "exit status 1",
+		Stdout:   []byte("[INFO] Building api"),
+		Stderr:   []byte("[ERROR] Failed to resolve dependencies\n"),
+	})
+	log.Record(runner.Record{Keys: []string{"job-1"}, Command: "mvn -v", Stdout: []byte("Apache Maven 3.9.6\n")})
+
+	output, err := log.Read("job-1")
+	require.NoError(t, err)
+	assert.Contains(t, output, "=== 2026-10-16T12:00:00Z fix, exit 1 after 1.5s\n$ mvn -q install\ndir: /work/api\nerror: exit status 1\n")
+	assert.Contains(t, output, "--- stdout\n[INFO] Building api\n--- stderr\n[ERROR] Failed to resolve dependencies\n")
+	assert.Less(t, strings.Index(output, "mvn -q install"), strings.Index(output, "mvn -v"), "oldest first")
+
+	fix, err := log.Read("abc123")
+	require.NoError(t, err)
+	assert.NotContains(t, fix, "mvn -v")
+
+	_, err = log.Read("job-2")
+	var notFound *common.ErrNotFound
+	assert.ErrorAs(t, err, &notFound)
+	_, err = log.Read("../state")
+	assert.ErrorContains(t, err, "invalid log key")
+}
+
+func TestLog_StrictPrivacy(t *testing.T) {
+	log, _ := newLog(t)
+	log.SetStrictPrivacy(true)
+
+	log.Record(runner.Record{
+		Keys:     []string{"job-1"},
+		Command:  "MAVEN_OPTS=-Xmx2g mvn -s /home/me/.m2/settings.xml -f /work/api/pom.xml install",
+		Dir:      "/work/api",
+		ExitCode: 1,
+		Error:    "exit status 1",
+		Stdout:   []byte("[INFO] Using /home/me/.m2/repository"),
+		Stderr:   []byte("[ERROR] Failed to resolve dependencies\n"),
+	})
+
+	output, err := log.Read("job-1")
+	require.NoError(t, err)
+	assert.Contains(t, output, "$ MAVEN_OPTS=[withheld] mvn -s [withheld] -f /work/api/pom.xml install\ndir: /work/api\nerror: [withheld]\n")
+	assert.NotContains(t, output, "/home/me")
+	assert.NotContains(t, output, "---", "no output is logged")
+}
+
+func TestLog_Rotation(t *testing.T) {
+	log, dir := newLog(t)
+	big := []byte(strings.Repeat("x", MaxLogBytes/2))
+
+	for i := 0; i < 3; i++ {
+		log.Record(runner.Record{Keys: []string{"job-1"}, Command: fmt.Sprintf("run %d", i), Stdout: big})
+	}
+
+	assert.FileExists(t, filepath.Join(dir, "job-1.log.1"))
+	output, err := log.Read("job-1")
+	require.NoError(t, err)
+	assert.NotContains(t, output, "run 0", "the oldest output is rotated out")
+	assert.Contains(t, output, "run 1")
+	assert.Contains(t, output, "run 2")
+}
+
+func TestLog_Prune(t *testing.T) {
+	log, dir := newLog(t)
+	old := time.Now().Add(-time.Hour)
+
+	for i := 0; i < MaxLogs; i++ {
+		key := fmt.Sprintf("job-%d", i)
+		log.Record(runner.Record{Keys: []string{key}, Command: "true"})
+		require.NoError(t, os.Chtimes(filepath.Join(dir, key+".log"), old, old.Add(time.Duration(i)*time.Second)))
+	}
+	log.Record(runner.Record{Keys: []string{"job-new"}, Command: "true"})
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	require.NoError(t, err)
+	assert.Len(t, paths, MaxLogs)
+	assert.NoFileExists(t, filepath.Join(dir, "job-0.log"), "the least recently written log is removed")
+	assert.FileExists(t, filepath.Join(dir, "job-new.log"))
+}
@@ -1,78 +1,45 @@
 package common
 
 import (
-	"os"
-	"path/filepath"
+	"context"
 	"time"
 )
 
 // FileInfo holds file information
 type FileInfo struct {
-	Path    string
-	ModTime time.Time
-	Size    int64
-	IsDir   bool
+	Path     string
+	ModTime  time.Time
+	Size     int64
+	IsDir    bool
+	IsLink   bool   // Path is a symbolic link
+	Resolved string // Real path, with links and Yarn PnP virtual paths resolved
 }
 
-// GetFileInfo returns file information
+// GetFileInfo returns file information, following symbolic links
 func GetFileInfo(path string) (*FileInfo, error) {
-	info, err := os.Stat(path)
-	if err != nil {
-		return nil, err
-	}
-	
-	return &FileInfo{
-		Path:    path,
-		ModTime: info.ModTime(),
-		Size:    info.Size(),
-		IsDir:   info.IsDir(),
-	}, nil
+	return GetFileInfoWithLinks(path, FollowLinks)
 }
 
 // FileExists checks if a file exists
 func FileExists(path string) bool {
-	_, err := os.Stat(path)
+	_, err := stat(ResolveVirtualPath(path))
 	return err == nil
 }
 
 // DirExists checks if a directory exists
 func DirExists(path string) bool {
-	info, err := os.Stat(path)
+	info, err := stat(ResolveVirtualPath(path))
 	return err == nil && info.IsDir()
 }
 
-// FindFilesByPattern finds files matching a glob pattern
-func FindFilesByPattern(pattern string) ([]string, error) {
-	matches, err := filepath.Glob(pattern)
-	if err != nil {
-		return nil, err
-	}
-	
-	var files []string
-	for _, match := range matches {
-		if info, err := os.Stat(match); err == nil && !info.IsDir() {
-			files = append(files, match)
-		}
-	}
-	
-	return files, nil
+// FindFilesByPattern finds files matching a glob pattern, following symbolic links
+func FindFilesByPattern(ctx context.Context, pattern string) ([]string, error) {
+	return FindFilesByPatternWithLinks(ctx, pattern, FollowLinks)
 }
 
-// FindDirsByPattern finds directories matching a glob pattern
-func FindDirsByPattern(pattern string) ([]string, error) {
-	matches, err := filepath.Glob(pattern)
-	if err != nil {
-		return nil, err
-	}
-	
-	var dirs []string
-	for _, match := range matches {
-		if info, err := os.Stat(match); err == nil && info.IsDir() {
-			dirs = append(dirs, match)
-		}
-	}
-	
-	return dirs, nil
+// FindDirsByPattern finds directories matching a glob pattern, following symbolic links
+func FindDirsByPattern(ctx context.Context, pattern string) ([]string, error) {
+	return FindDirsByPatternWithLinks(ctx, pattern, FollowLinks)
 }
 
 // CompareTimestamps compares modification times of two files
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -87,7 +88,7 @@ func TestFindFilesByPattern(t *testing.T) {
 
 	// Test pattern matching
 	pattern := filepath.Join(tmpDir, "*.txt")
-	matches, err := FindFilesByPattern(pattern)
+	matches, err := FindFilesByPattern(context.Background(), pattern)
 	require.NoError(t, err)
 	assert.Len(t, matches, 2)
 
@@ -100,7 +101,7 @@ func TestFindFilesByPattern(t *testing.T) {
 func TestFindFilesByPattern_NoMatches(t *testing.T) {
 	tmpDir := t.TempDir()
 	pattern := filepath.Join(tmpDir, "*.nonexistent")
-	matches, err := FindFilesByPattern(pattern)
+	matches, err := FindFilesByPattern(context.Background(), pattern)
 	require.NoError(t, err)
 	assert.Empty(t, matches)
 }
@@ -123,7 +124,7 @@ func TestFindDirsByPattern(t *testing.T) {
 
 	// Test pattern matching
 	pattern := filepath.Join(tmpDir, "dir*")
-	matches, err := FindDirsByPattern(pattern)
+	matches, err := FindDirsByPattern(context.Background(), pattern)
 	require.NoError(t, err)
 	assert.Len(t, matches, 3)
 
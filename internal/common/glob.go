@@ -0,0 +1,136 @@
+package common
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// doubleStar is the pattern component that matches any number of directories
+const doubleStar = "**"
+
+// Glob returns the paths matching a pattern, in lexical order. Besides the syntax of
+// filepath.Match, a ** component matches any number of directories, including none, so
+// target/**/*.class matches target/A.class and target/classes/com/A.class. ** doesn't
+// descend into hidden directories such as .git or .venv; name them to match inside them.
+// The walk stops with ctx's error once ctx is done.
+func Glob(ctx context.Context, pattern string, mode LinkMode) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	remote := IsMounted(pattern)
+	if !HasDoubleStar(pattern) && !remote {
+		return filepath.Glob(pattern)
+	}
+	if remote && !hasMeta(pattern) {
+		if _, err := lstat(pattern); err != nil {
+			return nil, nil
+		}
+		return []string{pattern}, nil
+	}
+
+	parts := strings.Split(filepath.ToSlash(pattern), "/")
+	for _, part := range parts {
+		if _, err := filepath.Match(part, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	// Walk from the longest leading path without wildcards
+	i := 0
+	for i < len(parts) && !hasMeta(parts[i]) {
+		i++
+	}
+	root := strings.Join(parts[:i], "/")
+	if root == "" && strings.HasPrefix(pattern, "/") {
+		root = "/"
+	} else if root == "" {
+		root = "."
+	}
+	root = filepath.FromSlash(root)
+	rest := parts[i:]
+
+	var matches []string
+	err := walk(ctx, root, mode, func(path string, info *FileInfo) error {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		names := strings.Split(filepath.ToSlash(rel), "/")
+		if matchParts(rest, names) {
+			matches = append(matches, path)
+		}
+		if info.IsDir && !matchPrefix(rest, names) {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return matches, err
+}
+
+// HasDoubleStar reports whether a pattern has a ** component
+func HasDoubleStar(pattern string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(pattern), "/") {
+		if part == doubleStar {
+			return true
+		}
+	}
+	return false
+}
+
+// PatternExists reports whether a path or glob pattern matches anything
+func PatternExists(ctx context.Context, pattern string) bool {
+	if !hasMeta(pattern) {
+		return FileExists(pattern)
+	}
+	matches, err := Glob(ctx, pattern, FollowLinks)
+	return err == nil && len(matches) > 0
+}
+
+// hasMeta reports whether a pattern has wildcards
+func hasMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// matchParts reports whether path components match pattern components
+func matchParts(pattern, names []string) bool {
+	if len(pattern) == 0 {
+		return len(names) == 0
+	}
+	if pattern[0] == doubleStar {
+		if matchParts(pattern[1:], names) {
+			return true
+		}
+		return len(names) > 0 && !isHidden(names[0]) && matchParts(pattern, names[1:])
+	}
+	if len(names) == 0 {
+		return false
+	}
+	ok, _ := filepath.Match(pattern[0], names[0])
+	return ok && matchParts(pattern[1:], names[1:])
+}
+
+// matchPrefix reports whether a directory's components could begin a match, so the walk
+// only descends where matches can be
+func matchPrefix(pattern, names []string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	if len(pattern) == 0 {
+		return false
+	}
+	if pattern[0] == doubleStar {
+		return matchPrefix(pattern[1:], names) || (!isHidden(names[0]) && matchPrefix(pattern, names[1:]))
+	}
+	ok, _ := filepath.Match(pattern[0], names[0])
+	return ok && matchPrefix(pattern[1:], names[1:])
+}
+
+// isHidden reports whether a file or directory name is hidden
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
@@ -0,0 +1,75 @@
+package common
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlob_DoubleStar(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{
+		"target/A.class",
+		"target/classes/com/example/B.class",
+		"target/classes/com/example/B.java",
+		"target/.hidden/C.class",
+		"src/main/java/App.java",
+	} {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(""), 0644))
+	}
+
+	rel := func(paths []string) []string {
+		out := make([]string, len(paths))
+		for i, p := range paths {
+			r, err := filepath.Rel(tmpDir, p)
+			require.NoError(t, err)
+			out[i] = filepath.ToSlash(r)
+		}
+		return out
+	}
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"target/**/*.class", []string{"target/A.class", "target/classes/com/example/B.class"}},
+		{"**/*.java", []string{"src/main/java/App.java", "target/classes/com/example/B.java"}},
+		{"target/.hidden/**/*.class", []string{"target/.hidden/C.class"}},
+		{"**/example", []string{"target/classes/com/example"}},
+		{"src/**/App.java", []string{"src/main/java/App.java"}},
+		{"dist/**/*", []string{}},
+	}
+	for _, tt := range tests {
+		matches, err := Glob(context.Background(), filepath.Join(tmpDir, tt.pattern), FollowLinks)
+		require.NoError(t, err, tt.pattern)
+		assert.Equal(t, tt.expected, rel(matches), tt.pattern)
+	}
+
+	files, err := FindFilesByPattern(context.Background(), filepath.Join(tmpDir, "**", "*"))
+	require.NoError(t, err)
+	assert.Len(t, files, 4)
+
+	dirs, err := FindDirsByPattern(context.Background(), filepath.Join(tmpDir, "target", "**"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"target/classes", "target/classes/com", "target/classes/com/example"}, rel(dirs))
+
+	_, err = Glob(context.Background(), filepath.Join(tmpDir, "**", "[a"), FollowLinks)
+	assert.ErrorIs(t, err, filepath.ErrBadPattern)
+}
+
+func TestPatternExists(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "migrations", "v1"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "migrations", "v1", "init.sql"), []byte(""), 0644))
+
+	assert.True(t, PatternExists(context.Background(), filepath.Join(tmpDir, "migrations", "**", "*.sql")))
+	assert.True(t, PatternExists(context.Background(), filepath.Join(tmpDir, "migrations")))
+	assert.False(t, PatternExists(context.Background(), filepath.Join(tmpDir, "*.sql")))
+	assert.False(t, PatternExists(context.Background(), filepath.Join(tmpDir, "schema.sql")))
+}
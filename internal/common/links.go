@@ -0,0 +1,199 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// LinkMode selects how file utilities treat symbolic links, such as the symlinked
+// node_modules of pnpm. Windows junctions are resolved by the OS like directories.
+type LinkMode int
+
+const (
+	// FollowLinks reports the file a link points at, so a symlinked build output has the
+	// modification time of the real output. Matches that resolve to the same file are listed once.
+	FollowLinks LinkMode = iota
+	// NoFollowLinks reports links themselves
+	NoFollowLinks
+	// SkipLinks leaves links out of pattern matches and walks
+	SkipLinks
+)
+
+// ErrLinkLoop is returned for a path whose symbolic links point back at themselves
+var ErrLinkLoop = errors.New("symbolic link loop")
+
+// virtualPathPattern matches Yarn PnP virtual paths: .yarn/__virtual__/<name>-<hash>/<depth>/<subpath>
+var virtualPathPattern = regexp.MustCompile(`^(.*[/\\](?:__virtual__|\$\$virtual))[/\\][^/\\]+[/\\](\d+)(?:[/\\](.*))?$`)
+
+// ResolveVirtualPath maps a Yarn PnP virtual path to the real path it stands for: <depth>
+// directories up from the directory holding __virtual__, then <subpath>. Other paths are
+// returned unchanged.
+func ResolveVirtualPath(path string) string {
+	for {
+		match := virtualPathPattern.FindStringSubmatch(path)
+		if match == nil {
+			return path
+		}
+		depth, _ := strconv.Atoi(match[2])
+		target := filepath.Dir(match[1])
+		parts := []string{target}
+		for i := 0; i < depth; i++ {
+			parts = append(parts, "..")
+		}
+		if match[3] != "" {
+			parts = append(parts, match[3])
+		}
+		path = filepath.Join(parts...)
+	}
+}
+
+// GetFileInfoWithLinks returns file information, following a symbolic link at path or not
+// depending on mode. SkipLinks reports links themselves, like NoFollowLinks.
+func GetFileInfoWithLinks(path string, mode LinkMode) (*FileInfo, error) {
+	real := ResolveVirtualPath(path)
+	info, err := lstat(real)
+	if err != nil {
+		return nil, linkError(path, err)
+	}
+
+	isLink := info.Mode()&os.ModeSymlink != 0
+	if mode == FollowLinks {
+		resolved, err := evalSymlinks(real)
+		if err != nil {
+			return nil, linkError(path, err)
+		}
+		if info, err = stat(real); err != nil {
+			return nil, linkError(path, err)
+		}
+		real = resolved
+	} else if dir, err := evalSymlinks(filepath.Dir(real)); err == nil {
+		real = filepath.Join(dir, filepath.Base(real))
+	}
+
+	return &FileInfo{
+		Path:     path,
+		ModTime:  info.ModTime(),
+		Size:     info.Size(),
+		IsDir:    info.IsDir(),
+		IsLink:   isLink,
+		Resolved: real,
+	}, nil
+}
+
+// FindFilesByPatternWithLinks finds files matching a glob pattern, treating links as mode selects
+func FindFilesByPatternWithLinks(ctx context.Context, pattern string, mode LinkMode) ([]string, error) {
+	return findByPattern(ctx, pattern, mode, false)
+}
+
+// FindDirsByPatternWithLinks finds directories matching a glob pattern, treating links as mode selects
+func FindDirsByPatternWithLinks(ctx context.Context, pattern string, mode LinkMode) ([]string, error) {
+	return findByPattern(ctx, pattern, mode, true)
+}
+
+// findByPattern finds files or directories matching a glob pattern
+func findByPattern(ctx context.Context, pattern string, mode LinkMode, dirs bool) ([]string, error) {
+	matches, err := Glob(ctx, ResolveVirtualPath(pattern), mode)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []string
+	seen := make(map[string]bool)
+	for _, match := range matches {
+		info, err := GetFileInfoWithLinks(match, mode)
+		if err != nil || info.IsDir != dirs || (info.IsLink && mode == SkipLinks) {
+			continue
+		}
+		if seen[info.Resolved] {
+			continue
+		}
+		seen[info.Resolved] = true
+		found = append(found, match)
+	}
+	return found, nil
+}
+
+// WalkFiles calls fn for every file under root, in lexical order. With FollowLinks, symlinked
+// directories are walked too, except a link back into a directory being walked, which would
+// never end; with the other modes, links are reported (NoFollowLinks) or left out (SkipLinks).
+// The walk stops with ctx's error once ctx is done.
+func WalkFiles(ctx context.Context, root string, mode LinkMode, fn func(path string, info *FileInfo) error) error {
+	return walk(ctx, root, mode, func(path string, info *FileInfo) error {
+		if info.IsDir {
+			return nil
+		}
+		return fn(path, info)
+	})
+}
+
+// walk calls fn for every file and directory under root, like WalkFiles. Returning
+// filepath.SkipDir for a directory skips its contents.
+func walk(ctx context.Context, root string, mode LinkMode, fn func(path string, info *FileInfo) error) error {
+	rootInfo, err := stat(root)
+	if err != nil {
+		return err
+	}
+	return walkDir(ctx, root, mode, []os.FileInfo{rootInfo}, fn)
+}
+
+// walkDir walks one directory; ancestors are the directories being walked, for loop protection
+func walkDir(ctx context.Context, dir string, mode LinkMode, ancestors []os.FileInfo, fn func(string, *FileInfo) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	names, err := readDirNames(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		info, err := GetFileInfoWithLinks(path, mode)
+		if err != nil || (info.IsLink && mode == SkipLinks) {
+			continue
+		}
+		if err := fn(path, info); err != nil {
+			if err == filepath.SkipDir && info.IsDir {
+				continue
+			}
+			return err
+		}
+		if !info.IsDir {
+			continue
+		}
+
+		dirInfo, err := stat(path)
+		if err != nil || isAncestor(dir, dirInfo, ancestors) {
+			continue
+		}
+		if err := walkDir(ctx, path, mode, append(ancestors, dirInfo), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isAncestor reports whether a directory in parent is one of the directories being walked
+func isAncestor(parent string, dir os.FileInfo, ancestors []os.FileInfo) bool {
+	for _, ancestor := range ancestors {
+		if sameFile(parent, dir, ancestor) {
+			return true
+		}
+	}
+	return false
+}
+
+// linkError reports a symbolic link loop as ErrLinkLoop
+func linkError(path string, err error) error {
+	if errors.Is(err, syscall.ELOOP) || strings.Contains(err.Error(), "too many links") {
+		return fmt.Errorf("%s: %w", path, ErrLinkLoop)
+	}
+	return err
+}
@@ -0,0 +1,161 @@
+package common
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFileInfoWithLinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "app.jar")
+	require.NoError(t, os.WriteFile(target, []byte("jar"), 0644))
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(target, old, old))
+	link := filepath.Join(tmpDir, "latest.jar")
+	require.NoError(t, os.Symlink(target, link))
+
+	// Following the link reports the real file, not the newer link
+	info, err := GetFileInfo(link)
+	require.NoError(t, err)
+	assert.Equal(t, link, info.Path)
+	assert.True(t, info.IsLink)
+	assert.True(t, info.ModTime.Equal(old))
+	assert.Equal(t, int64(3), info.Size)
+	realTarget, err := filepath.EvalSymlinks(target)
+	require.NoError(t, err)
+	assert.Equal(t, realTarget, info.Resolved)
+
+	info, err = GetFileInfoWithLinks(link, NoFollowLinks)
+	require.NoError(t, err)
+	assert.True(t, info.IsLink)
+	assert.False(t, info.ModTime.Equal(old))
+
+	// A broken link can be reported but not followed
+	require.NoError(t, os.Remove(target))
+	_, err = GetFileInfo(link)
+	assert.Error(t, err)
+	_, err = GetFileInfoWithLinks(link, NoFollowLinks)
+	assert.NoError(t, err)
+}
+
+func TestGetFileInfoWithLinks_Loop(t *testing.T) {
+	tmpDir := t.TempDir()
+	a := filepath.Join(tmpDir, "a")
+	b := filepath.Join(tmpDir, "b")
+	require.NoError(t, os.Symlink(b, a))
+	require.NoError(t, os.Symlink(a, b))
+
+	_, err := GetFileInfo(a)
+	assert.ErrorIs(t, err, ErrLinkLoop)
+	assert.False(t, FileExists(a))
+}
+
+func TestFindFilesByPatternWithLinks(t *testing.T) {
+	// A pnpm-style layout: node_modules/foo links into the store
+	tmpDir := t.TempDir()
+	store := filepath.Join(tmpDir, "node_modules", ".pnpm", "foo@1.0.0")
+	require.NoError(t, os.MkdirAll(store, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(store, "index.js"), []byte(""), 0644))
+	require.NoError(t, os.Symlink(store, filepath.Join(tmpDir, "node_modules", "foo")))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.js"), []byte(""), 0644))
+	require.NoError(t, os.Symlink(filepath.Join(tmpDir, "main.js"), filepath.Join(tmpDir, "alias.js")))
+
+	files, err := FindFilesByPattern(context.Background(), filepath.Join(tmpDir, "node_modules", "foo", "*.js"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(tmpDir, "node_modules", "foo", "index.js")}, files)
+
+	dirs, err := FindDirsByPattern(context.Background(), filepath.Join(tmpDir, "node_modules", "*"))
+	require.NoError(t, err)
+	assert.Len(t, dirs, 2)
+
+	// Links to the same file are listed once when followed
+	files, err = FindFilesByPattern(context.Background(), filepath.Join(tmpDir, "*.js"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(tmpDir, "alias.js")}, files)
+
+	files, err = FindFilesByPatternWithLinks(context.Background(), filepath.Join(tmpDir, "*.js"), NoFollowLinks)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	files, err = FindFilesByPatternWithLinks(context.Background(), filepath.Join(tmpDir, "*.js"), SkipLinks)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(tmpDir, "main.js")}, files)
+
+	dirs, err = FindDirsByPatternWithLinks(context.Background(), filepath.Join(tmpDir, "node_modules", "*"), SkipLinks)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(tmpDir, "node_modules", ".pnpm")}, dirs)
+}
+
+func TestResolveVirtualPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/app/.yarn/__virtual__/react-dom-virtual-1a2b3c/0/cache/react-dom.zip", "/app/.yarn/cache/react-dom.zip"},
+		{"/app/.yarn/__virtual__/foo-virtual-1a2b3c/2/packages/foo", "/packages/foo"},
+		{"/app/.yarn/$$virtual/foo-virtual-1a2b3c/1/packages/foo/index.js", "/app/packages/foo/index.js"},
+		{"/app/node_modules/foo/index.js", "/app/node_modules/foo/index.js"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, filepath.FromSlash(tt.expected), ResolveVirtualPath(filepath.FromSlash(tt.path)), tt.path)
+	}
+
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, ".yarn", "unplugged"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".yarn", "unplugged", "pkg.js"), []byte(""), 0644))
+	virtual := filepath.Join(tmpDir, ".yarn", "__virtual__", "pkg-virtual-abc", "0", "unplugged", "pkg.js")
+	assert.True(t, FileExists(virtual))
+	info, err := GetFileInfo(virtual)
+	require.NoError(t, err)
+	assert.Equal(t, virtual, info.Path)
+}
+
+func TestWalkFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "src", "pkg"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "src", "pkg", "a.go"), []byte(""), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "src", "b.go"), []byte(""), 0644))
+	// A link back to an ancestor would make the walk endless
+	require.NoError(t, os.Symlink(filepath.Join(tmpDir, "src"), filepath.Join(tmpDir, "src", "pkg", "loop")))
+	shared := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(shared, "c.go"), []byte(""), 0644))
+	require.NoError(t, os.Symlink(shared, filepath.Join(tmpDir, "src", "shared")))
+
+	walk := func(mode LinkMode) []string {
+		var files []string
+		err := WalkFiles(context.Background(), tmpDir, mode, func(path string, info *FileInfo) error {
+			rel, _ := filepath.Rel(tmpDir, path)
+			files = append(files, filepath.ToSlash(rel))
+			return nil
+		})
+		require.NoError(t, err)
+		return files
+	}
+
+	assert.Equal(t, []string{"src/b.go", "src/pkg/a.go", "src/shared/c.go"}, walk(FollowLinks))
+	assert.Equal(t, []string{"src/b.go", "src/pkg/a.go"}, walk(SkipLinks))
+}
+
+func TestWalkFiles_Cancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "src"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "src", "a.go"), []byte(""), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := WalkFiles(ctx, tmpDir, FollowLinks, func(path string, info *FileInfo) error {
+		t.Errorf("walked %s after the context was cancelled", path)
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = Glob(ctx, filepath.Join(tmpDir, "**", "*.go"), FollowLinks)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, PatternExists(ctx, filepath.Join(tmpDir, "**", "*.go")))
+}
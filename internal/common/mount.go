@@ -0,0 +1,231 @@
+package common
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FileSystem holds the files of a project that lives on another machine, such as a remote dev
+// server read over SFTP. Names are slash separated and relative to the mounted root, "." for
+// the root itself.
+type FileSystem interface {
+	Lstat(name string) (os.FileInfo, error)
+	Stat(name string) (os.FileInfo, error)
+	// ReadDir returns the names in a directory in lexical order
+	ReadDir(name string) ([]string, error)
+	Open(name string) (io.ReadCloser, error)
+	// RealPath resolves the symbolic links of a name; a result outside the root starts with ..
+	RealPath(name string) (string, error)
+	// SameFile reports whether two results of Stat describe the same file
+	SameFile(a, b os.FileInfo) bool
+}
+
+var (
+	mountsMu sync.RWMutex
+	mounts   = map[string]FileSystem{}
+)
+
+// Mount serves the paths under a local root from a file system, so the file checks of a
+// project on another machine run unchanged against the root. It returns the function that
+// removes the mount.
+func Mount(root string, fs FileSystem) func() {
+	root = filepath.Clean(root)
+	mountsMu.Lock()
+	defer mountsMu.Unlock()
+	mounts[root] = fs
+	return func() {
+		mountsMu.Lock()
+		defer mountsMu.Unlock()
+		if mounts[root] == fs {
+			delete(mounts, root)
+		}
+	}
+}
+
+// mounted returns the file system serving a path and the path's name in it
+func mounted(path string) (FileSystem, string, bool) {
+	mountsMu.RLock()
+	defer mountsMu.RUnlock()
+	if len(mounts) == 0 {
+		return nil, "", false
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, "", false
+	}
+	// The deepest root wins for nested mounts
+	best := ""
+	for root := range mounts {
+		if (path == root || strings.HasPrefix(path, root+string(filepath.Separator))) && len(root) > len(best) {
+			best = root
+		}
+	}
+	if best == "" {
+		return nil, "", false
+	}
+	rel, _ := filepath.Rel(best, path)
+	return mounts[best], filepath.ToSlash(rel), true
+}
+
+// IsMounted reports whether a path is served by a mounted file system
+func IsMounted(path string) bool {
+	_, _, ok := mounted(path)
+	return ok
+}
+
+// mountRoot returns the local root of the mount serving a path
+func mountRoot(path, name string) string {
+	abs, _ := filepath.Abs(path)
+	return strings.TrimSuffix(abs, string(filepath.Separator)+filepath.FromSlash(name))
+}
+
+func lstat(path string) (os.FileInfo, error) {
+	if fs, name, ok := mounted(path); ok {
+		return fs.Lstat(name)
+	}
+	return os.Lstat(path)
+}
+
+func stat(path string) (os.FileInfo, error) {
+	if fs, name, ok := mounted(path); ok {
+		return fs.Stat(name)
+	}
+	return os.Stat(path)
+}
+
+// evalSymlinks resolves the symbolic links of a path. Under a mount the result is the local
+// path standing for the real one, which is only an identity for paths resolving outside it.
+func evalSymlinks(path string) (string, error) {
+	fs, name, ok := mounted(path)
+	if !ok {
+		return filepath.EvalSymlinks(path)
+	}
+	real, err := fs.RealPath(name)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(mountRoot(path, name), filepath.FromSlash(real)), nil
+}
+
+// readDirNames returns the names in a directory in lexical order
+func readDirNames(dir string) ([]string, error) {
+	if fs, name, ok := mounted(dir); ok {
+		return fs.ReadDir(name)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	return names, nil
+}
+
+// sameFile reports whether two results of Stat for paths under dir describe the same file
+func sameFile(dir string, a, b os.FileInfo) bool {
+	if fs, _, ok := mounted(dir); ok {
+		return fs.SameFile(a, b)
+	}
+	return os.SameFile(a, b)
+}
+
+// Open opens a file for reading, from its mount if it has one
+func Open(path string) (io.ReadCloser, error) {
+	if fs, name, ok := mounted(path); ok {
+		return fs.Open(name)
+	}
+	return os.Open(path)
+}
+
+// ReadFile reads a whole file, from its mount if it has one
+func ReadFile(path string) ([]byte, error) {
+	fs, name, ok := mounted(path)
+	if !ok {
+		return os.ReadFile(path)
+	}
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, f); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Stat returns the information of a file, following links, from its mount if it has one
+func Stat(path string) (os.FileInfo, error) {
+	return stat(path)
+}
+
+// ReadDir returns the names in a directory in lexical order, from its mount if it has one
+func ReadDir(dir string) ([]string, error) {
+	return readDirNames(dir)
+}
+
+// Walk walks the tree under root like filepath.Walk, from its mount if it has one
+func Walk(root string, fn filepath.WalkFunc) error {
+	if !IsMounted(root) {
+		return filepath.Walk(root, fn)
+	}
+	info, err := lstat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = walkMounted(root, info, fn)
+	}
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+// WalkDir walks the tree under root like filepath.WalkDir, from its mount if it has one
+func WalkDir(root string, fn fs.WalkDirFunc) error {
+	if !IsMounted(root) {
+		return filepath.WalkDir(root, fn)
+	}
+	return Walk(root, func(path string, info os.FileInfo, err error) error {
+		var d fs.DirEntry
+		if info != nil {
+			d = fs.FileInfoToDirEntry(info)
+		}
+		return fn(path, d, err)
+	})
+}
+
+// walkMounted walks a mounted path with the semantics of filepath.Walk
+func walkMounted(path string, info os.FileInfo, fn filepath.WalkFunc) error {
+	if !info.IsDir() {
+		return fn(path, info, nil)
+	}
+	names, err := readDirNames(path)
+	if fnErr := fn(path, info, err); err != nil || fnErr != nil {
+		return fnErr
+	}
+	for _, name := range names {
+		child := filepath.Join(path, name)
+		childInfo, err := lstat(child)
+		if err != nil {
+			if err := fn(child, nil, err); err != nil && err != filepath.SkipDir {
+				return err
+			}
+			continue
+		}
+		if err := walkMounted(child, childInfo, fn); err != nil {
+			if !childInfo.IsDir() || err != filepath.SkipDir {
+				return err
+			}
+		}
+	}
+	return nil
+}
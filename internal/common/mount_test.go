@@ -0,0 +1,132 @@
+package common
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dirFS serves a local directory as a mounted file system, standing in for a remote one
+type dirFS struct {
+	dir   string
+	calls int
+}
+
+func (d *dirFS) path(name string) string {
+	d.calls++
+	return filepath.Join(d.dir, filepath.FromSlash(name))
+}
+
+func (d *dirFS) Lstat(name string) (os.FileInfo, error) { return os.Lstat(d.path(name)) }
+func (d *dirFS) Stat(name string) (os.FileInfo, error)  { return os.Stat(d.path(name)) }
+func (d *dirFS) Open(name string) (io.ReadCloser, error) {
+	return os.Open(d.path(name))
+}
+func (d *dirFS) SameFile(a, b os.FileInfo) bool { return os.SameFile(a, b) }
+
+func (d *dirFS) ReadDir(name string) ([]string, error) {
+	entries, err := os.ReadDir(d.path(name))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names, nil
+}
+
+func (d *dirFS) RealPath(name string) (string, error) {
+	real, err := filepath.EvalSymlinks(d.path(name))
+	if err != nil {
+		return "", err
+	}
+	root, _ := filepath.EvalSymlinks(d.dir)
+	rel, err := filepath.Rel(root, real)
+	return filepath.ToSlash(rel), err
+}
+
+func TestMount(t *testing.T) {
+	files := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(files, "target", "classes", "com"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(files, "pom.xml"), []byte("<project/>"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(files, "target", "app.jar"), []byte("jar"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(files, "target", "classes", "com", "A.class"), []byte("class"), 0644))
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(filepath.Join(files, "pom.xml"), old, old))
+	// A link back up the tree must not make walks endless
+	require.NoError(t, os.Symlink("..", filepath.Join(files, "target", "up")))
+
+	// The mount point doesn't exist on this machine; everything under it comes from the mount
+	root := filepath.Join(t.TempDir(), "remote")
+	fs := &dirFS{dir: files}
+	unmount := Mount(root, fs)
+
+	assert.True(t, IsMounted(filepath.Join(root, "pom.xml")))
+	assert.False(t, IsMounted(files))
+	assert.True(t, FileExists(filepath.Join(root, "pom.xml")))
+	assert.True(t, DirExists(filepath.Join(root, "target")))
+	assert.False(t, FileExists(filepath.Join(root, "missing")))
+
+	info, err := GetFileInfo(filepath.Join(root, "pom.xml"))
+	require.NoError(t, err)
+	assert.True(t, info.ModTime.Equal(old))
+	assert.Equal(t, filepath.Join(root, "pom.xml"), info.Resolved)
+
+	jars, err := FindFilesByPattern(context.Background(), filepath.Join(root, "target", "*.jar"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(root, "target", "app.jar")}, jars)
+	classes, err := FindFilesByPattern(context.Background(), filepath.Join(root, "target", "**", "*.class"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(root, "target", "classes", "com", "A.class")}, classes)
+	assert.True(t, PatternExists(context.Background(), filepath.Join(root, "pom.xml")))
+
+	var walked []string
+	require.NoError(t, WalkFiles(context.Background(), root, FollowLinks, func(path string, info *FileInfo) error {
+		rel, _ := filepath.Rel(root, path)
+		walked = append(walked, filepath.ToSlash(rel))
+		return nil
+	}))
+	assert.Contains(t, walked, "pom.xml")
+	assert.Contains(t, walked, "target/classes/com/A.class")
+
+	// Walk and WalkDir don't follow links, like their filepath counterparts
+	walked = nil
+	require.NoError(t, Walk(root, func(path string, info os.FileInfo, err error) error {
+		require.NoError(t, err)
+		if info.IsDir() && info.Name() == "classes" {
+			return filepath.SkipDir
+		}
+		rel, _ := filepath.Rel(root, path)
+		walked = append(walked, filepath.ToSlash(rel))
+		return nil
+	}))
+	assert.Equal(t, []string{".", "pom.xml", "target", "target/app.jar", "target/up"}, walked)
+	walked = nil
+	require.NoError(t, WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		require.NoError(t, err)
+		if !d.IsDir() {
+			walked = append(walked, d.Name())
+		}
+		return nil
+	}))
+	assert.Equal(t, []string{"pom.xml", "app.jar", "A.class", "up"}, walked)
+	names, err := ReadDir(filepath.Join(root, "target"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app.jar", "classes", "up"}, names)
+
+	data, err := ReadFile(filepath.Join(root, "target", "app.jar"))
+	require.NoError(t, err)
+	assert.Equal(t, "jar", string(data))
+
+	unmount()
+	calls := fs.calls
+	assert.False(t, FileExists(filepath.Join(root, "pom.xml")))
+	assert.Equal(t, calls, fs.calls, "unmounted paths are local again")
+}
@@ -0,0 +1,389 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"dev-env-sentinel/internal/common"
+)
+
+// Facts answers the questions `when` conditions ask about a project
+type Facts interface {
+	// FileExists reports whether a file matching a path or glob relative to the project root exists
+	FileExists(pattern string) bool
+	// DirExists reports whether a directory relative to the project root exists
+	DirExists(path string) bool
+	// CompareVersion compares the ecosystem's detected version with v: -1, 0 or 1.
+	// ok is false when the version cannot be detected.
+	CompareVersion(v string) (cmp int, ok bool)
+}
+
+// Condition is a parsed `when` expression, such as
+//
+//	file_exists("Dockerfile") && version >= 17
+//
+// It supports file_exists("path or glob"), dir_exists("path"), comparisons of the detected
+// version with ==, !=, <, <=, > and >=, and !, &&, || and parentheses.
+type Condition struct {
+	expr string
+	root node
+}
+
+// node is a term of a condition
+type node interface {
+	eval(facts Facts) bool
+}
+
+// ParseCondition parses a `when` expression
+func ParseCondition(expr string) (*Condition, error) {
+	p := &conditionParser{input: expr}
+	p.next()
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", expr, err)
+	}
+	if p.tok.kind != tokEOF {
+		return nil, fmt.Errorf("invalid condition %q: unexpected %s", expr, p.tok)
+	}
+	return &Condition{expr: expr, root: root}, nil
+}
+
+// Eval evaluates the condition. Version comparisons are false when the version is unknown.
+func (c *Condition) Eval(facts Facts) bool {
+	return c.root.eval(facts)
+}
+
+// String returns the condition's source
+func (c *Condition) String() string {
+	return c.expr
+}
+
+type notNode struct{ operand node }
+
+func (n notNode) eval(facts Facts) bool { return !n.operand.eval(facts) }
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(facts Facts) bool { return n.left.eval(facts) && n.right.eval(facts) }
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(facts Facts) bool { return n.left.eval(facts) || n.right.eval(facts) }
+
+type fileExistsNode struct{ pattern string }
+
+func (n fileExistsNode) eval(facts Facts) bool { return facts.FileExists(n.pattern) }
+
+type dirExistsNode struct{ path string }
+
+func (n dirExistsNode) eval(facts Facts) bool { return facts.DirExists(n.path) }
+
+type versionNode struct {
+	op      string
+	version string
+}
+
+func (n versionNode) eval(facts Facts) bool {
+	cmp, ok := facts.CompareVersion(n.version)
+	if !ok {
+		return false
+	}
+	switch n.op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default: // ">="
+		return cmp >= 0
+	}
+}
+
+// Token kinds of the condition language
+const (
+	tokEOF = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+	tokError
+)
+
+type token struct {
+	kind  int
+	value string
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of condition"
+	case tokString:
+		return fmt.Sprintf("string %q", t.value)
+	default:
+		return fmt.Sprintf("%q", t.value)
+	}
+}
+
+// conditionParser is a recursive descent parser over a condition's tokens
+type conditionParser struct {
+	input string
+	pos   int
+	tok   token
+}
+
+// next advances to the next token
+func (p *conditionParser) next() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+	if p.pos >= len(p.input) {
+		p.tok = token{kind: tokEOF}
+		return
+	}
+
+	start := p.pos
+	c := rune(p.input[p.pos])
+	switch {
+	case c == '"':
+		end := strings.IndexByte(p.input[start+1:], '"')
+		if end < 0 {
+			p.tok = token{kind: tokError, value: "unterminated string"}
+			p.pos = len(p.input)
+			return
+		}
+		p.tok = token{kind: tokString, value: p.input[start+1 : start+1+end]}
+		p.pos = start + end + 2
+	case unicode.IsLetter(c) || c == '_':
+		for p.pos < len(p.input) && (unicode.IsLetter(rune(p.input[p.pos])) || unicode.IsDigit(rune(p.input[p.pos])) || p.input[p.pos] == '_') {
+			p.pos++
+		}
+		p.tok = token{kind: tokIdent, value: p.input[start:p.pos]}
+	case unicode.IsDigit(c):
+		for p.pos < len(p.input) && (unicode.IsDigit(rune(p.input[p.pos])) || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		p.tok = token{kind: tokNumber, value: p.input[start:p.pos]}
+	default:
+		for _, op := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")"} {
+			if strings.HasPrefix(p.input[start:], op) {
+				p.pos += len(op)
+				p.tok = token{kind: tokOp, value: op}
+				return
+			}
+		}
+		p.tok = token{kind: tokError, value: string(c)}
+		p.pos = len(p.input)
+	}
+}
+
+// expect consumes an operator token
+func (p *conditionParser) expect(op string) error {
+	if p.tok.kind != tokOp || p.tok.value != op {
+		return fmt.Errorf("expected %q, found %s", op, p.tok)
+	}
+	p.next()
+	return nil
+}
+
+func (p *conditionParser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOp && p.tok.value == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOp && p.tok.value == "&&" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseUnary() (node, error) {
+	switch {
+	case p.tok.kind == tokOp && p.tok.value == "!":
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	case p.tok.kind == tokOp && p.tok.value == "(":
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	case p.tok.kind == tokIdent && p.tok.value == "version":
+		return p.parseVersion()
+	case p.tok.kind == tokIdent:
+		return p.parseCall()
+	default:
+		return nil, fmt.Errorf("unexpected %s", p.tok)
+	}
+}
+
+// parseCall parses file_exists("...") and dir_exists("...")
+func (p *conditionParser) parseCall() (node, error) {
+	name := p.tok.value
+	if name != "file_exists" && name != "dir_exists" {
+		return nil, fmt.Errorf("unknown function %s (expected file_exists, dir_exists or version)", name)
+	}
+	p.next()
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokString {
+		return nil, fmt.Errorf("%s expects a quoted path, found %s", name, p.tok)
+	}
+	arg := p.tok.value
+	p.next()
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	if name == "dir_exists" {
+		return dirExistsNode{path: arg}, nil
+	}
+	return fileExistsNode{pattern: arg}, nil
+}
+
+// comparisons are the operators a version can be compared with
+var comparisons = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+// parseVersion parses a comparison of the detected version, e.g. version >= 17
+func (p *conditionParser) parseVersion() (node, error) {
+	p.next()
+	if p.tok.kind != tokOp || !comparisons[p.tok.value] {
+		return nil, fmt.Errorf("version must be compared with ==, !=, <, <=, > or >=, found %s", p.tok)
+	}
+	op := p.tok.value
+	p.next()
+	if p.tok.kind != tokNumber && p.tok.kind != tokString {
+		return nil, fmt.Errorf("expected a version after version %s, found %s", op, p.tok)
+	}
+	v := p.tok.value
+	p.next()
+	return versionNode{op: op, version: v}, nil
+}
+
+// conditions returns the `when` conditions of a config's entries by field path
+func (c *EcosystemConfig) conditions() map[string]string {
+	conditions := make(map[string]string)
+	add := func(field string, i int, when string) {
+		if when != "" {
+			conditions[fmt.Sprintf("ecosystem.%s[%d].when", field, i)] = when
+		}
+	}
+
+	eco := c.Ecosystem
+	for i, cmd := range eco.Verification.BuildFreshness.Commands {
+		add("verification.build_freshness.commands", i, cmd.When)
+	}
+	for i, cmd := range eco.Verification.DependencyAudit.Commands {
+		add("verification.dependency_audit.commands", i, cmd.When)
+	}
+	for i, service := range eco.Infrastructure.Services {
+		add("infrastructure.services", i, service.When)
+	}
+	for i, daemon := range eco.Infrastructure.Daemons {
+		add("infrastructure.daemons", i, daemon.When)
+	}
+	for i, cache := range eco.Infrastructure.BuildCaches {
+		add("infrastructure.build_caches", i, cache.When)
+	}
+	for i, store := range eco.Trust.Stores {
+		add("trust.stores", i, store.When)
+	}
+	for i, fix := range eco.Reconciliation.Fixes {
+		add("reconciliation.fixes", i, fix.When)
+	}
+	for i, sig := range eco.FailureSignatures {
+		add("failure_signatures", i, sig.When)
+	}
+	return conditions
+}
+
+// validateConditions checks that every `when` condition of a config parses
+func validateConditions(config *EcosystemConfig) error {
+	conditions := config.conditions()
+	fields := make([]string, 0, len(conditions))
+	for field := range conditions {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		if _, err := ParseCondition(conditions[field]); err != nil {
+			return &common.ErrInvalidConfig{Field: field, Message: err.Error()}
+		}
+	}
+	return nil
+}
+
+// Select returns the config as it applies to a project: entries whose `when` condition is
+// false for the project's facts are left out. Configs without conditions are returned as is.
+func (c *EcosystemConfig) Select(facts Facts) *EcosystemConfig {
+	if len(c.conditions()) == 0 {
+		return c
+	}
+
+	selected := *c
+	eco := &selected.Ecosystem
+	eco.Verification.BuildFreshness.Commands = selectEntries(eco.Verification.BuildFreshness.Commands, facts, func(cmd VerificationCommand) string { return cmd.When })
+	eco.Verification.DependencyAudit.Commands = selectEntries(eco.Verification.DependencyAudit.Commands, facts, func(cmd VerificationCommand) string { return cmd.When })
+	eco.Infrastructure.Services = selectEntries(eco.Infrastructure.Services, facts, func(service Service) string { return service.When })
+	eco.Infrastructure.Daemons = selectEntries(eco.Infrastructure.Daemons, facts, func(daemon Daemon) string { return daemon.When })
+	eco.Infrastructure.BuildCaches = selectEntries(eco.Infrastructure.BuildCaches, facts, func(cache BuildCache) string { return cache.When })
+	eco.Trust.Stores = selectEntries(eco.Trust.Stores, facts, func(store TrustStore) string { return store.When })
+	eco.Reconciliation.Fixes = selectEntries(eco.Reconciliation.Fixes, facts, func(fix Fix) string { return fix.When })
+	eco.FailureSignatures = selectEntries(eco.FailureSignatures, facts, func(sig FailureSignature) string { return sig.When })
+	return &selected
+}
+
+// selectEntries returns the entries without a condition or whose condition holds.
+// Entries with invalid conditions, which loaded configs never have, are left out.
+func selectEntries[T any](entries []T, facts Facts, when func(T) string) []T {
+	var selected []T
+	for _, entry := range entries {
+		expr := when(entry)
+		if expr == "" {
+			selected = append(selected, entry)
+			continue
+		}
+		if condition, err := ParseCondition(expr); err == nil && condition.Eval(facts) {
+			selected = append(selected, entry)
+		}
+	}
+	return selected
+}
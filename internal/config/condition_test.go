@@ -0,0 +1,157 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dev-env-sentinel/internal/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeFacts answers conditions from fixed values
+type fakeFacts struct {
+	files   map[string]bool
+	dirs    map[string]bool
+	version int // Major version; 0 when unknown
+}
+
+func (f fakeFacts) FileExists(pattern string) bool { return f.files[pattern] }
+
+func (f fakeFacts) DirExists(path string) bool { return f.dirs[path] }
+
+func (f fakeFacts) CompareVersion(v string) (int, bool) {
+	if f.version == 0 {
+		return 0, false
+	}
+	var want int
+	for _, c := range v {
+		if c == '.' {
+			break
+		}
+		want = want*10 + int(c-'0')
+	}
+	switch {
+	case f.version < want:
+		return -1, true
+	case f.version > want:
+		return 1, true
+	}
+	return 0, true
+}
+
+func TestCondition_Eval(t *testing.T) {
+	facts := fakeFacts{
+		files:   map[string]bool{"Dockerfile": true},
+		dirs:    map[string]bool{"src/main/java": true},
+		version: 17,
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`file_exists("Dockerfile")`, true},
+		{`file_exists("docker-compose.yml")`, false},
+		{`dir_exists("src/main/java")`, true},
+		{`version >= 17`, true},
+		{`version > 17`, false},
+		{`version < "21"`, true},
+		{`version == 17.0`, true},
+		{`version != 17`, false},
+		{`file_exists("Dockerfile") && version >= 21`, false},
+		{`file_exists("compose.yml") || version <= 17`, true},
+		{`!file_exists("Dockerfile")`, false},
+		{`!(file_exists("a") || file_exists("b")) && dir_exists("src/main/java")`, true},
+		{`file_exists("a") || file_exists("Dockerfile") && version >= 11`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			condition, err := ParseCondition(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, condition.Eval(facts))
+			assert.Equal(t, tt.expr, condition.String())
+		})
+	}
+
+	unknown, err := ParseCondition("version >= 11 || version < 11")
+	require.NoError(t, err)
+	assert.False(t, unknown.Eval(fakeFacts{}), "version comparisons are false when the version is unknown")
+}
+
+func TestParseCondition_Errors(t *testing.T) {
+	tests := map[string]string{
+		``:                             "unexpected end of condition",
+		`file_exists(Dockerfile)`:      "expects a quoted path",
+		`file_exists("Dockerfile"`:     `expected ")"`,
+		`exists("Dockerfile")`:         "unknown function exists",
+		`version 17`:                   "version must be compared",
+		`version >= `:                  "expected a version",
+		`file_exists("a") &&`:          "unexpected end of condition",
+		`file_exists("a") file_exists`: "unexpected",
+		`file_exists("a) `:             "unterminated string",
+		`version >= 17 & true`:         `"&"`,
+	}
+	for expr, want := range tests {
+		t.Run(expr, func(t *testing.T) {
+			_, err := ParseCondition(expr)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), want)
+		})
+	}
+}
+
+func TestEcosystemConfig_Select(t *testing.T) {
+	cfg := &EcosystemConfig{Ecosystem: Ecosystem{
+		ID: "java",
+		Infrastructure: Infrastructure{Services: []Service{
+			{Name: "java"},
+			{Name: "docker", When: `file_exists("Dockerfile")`},
+		}},
+		Reconciliation: Reconciliation{Fixes: []Fix{
+			{IssueType: "stale_build", Command: "mvn -q compile", When: "version >= 17"},
+			{IssueType: "stale_build", Command: "mvn compile", When: "version < 17"},
+		}},
+	}}
+
+	modern := cfg.Select(fakeFacts{version: 21})
+	require.Len(t, modern.Ecosystem.Infrastructure.Services, 1)
+	assert.Equal(t, "java", modern.Ecosystem.Infrastructure.Services[0].Name)
+	require.Len(t, modern.Ecosystem.Reconciliation.Fixes, 1)
+	assert.Equal(t, "mvn -q compile", modern.Ecosystem.Reconciliation.Fixes[0].Command)
+
+	legacy := cfg.Select(fakeFacts{files: map[string]bool{"Dockerfile": true}, version: 11})
+	assert.Len(t, legacy.Ecosystem.Infrastructure.Services, 2)
+	require.Len(t, legacy.Ecosystem.Reconciliation.Fixes, 1)
+	assert.Equal(t, "mvn compile", legacy.Ecosystem.Reconciliation.Fixes[0].Command)
+
+	assert.Len(t, cfg.Ecosystem.Infrastructure.Services, 2, "the original config is unchanged")
+
+	plain := &EcosystemConfig{Ecosystem: Ecosystem{ID: "plain"}}
+	assert.Same(t, plain, plain.Select(fakeFacts{}))
+}
+
+func TestLoadEcosystemConfig_InvalidCondition(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docker.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+ecosystem:
+  id: "docker"
+  manifest:
+    primary_file: "Dockerfile"
+  infrastructure:
+    services:
+      - name: "docker"
+        check_command: "docker --version"
+      - name: "compose"
+        check_command: "docker compose version"
+        when: 'file_exists(compose.yml)'
+`), 0644))
+
+	_, err := LoadEcosystemConfig(path)
+	var invalid *common.ErrInvalidConfig
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "ecosystem.infrastructure.services[1].when", invalid.Field)
+	assert.Contains(t, invalid.Message, "expects a quoted path")
+}
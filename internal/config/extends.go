@@ -0,0 +1,213 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+	"gopkg.in/yaml.v3"
+)
+
+// configFile is a parsed config file whose extends have not been resolved yet
+type configFile struct {
+	path    string
+	doc     map[string]interface{}
+	id      string
+	extends string
+	err     error
+}
+
+// resolved is a config file merged with the configs it extends
+type resolved struct {
+	doc     map[string]interface{}
+	sources []string
+	err     error
+}
+
+// LoadEcosystemConfigs loads config files together, so that a config can extend any other
+// config among them by ID. It returns the configs that loaded, in path order, and the errors
+// of the files that did not, by path.
+//
+// A config with `extends: <id>` is deep-merged over the config it extends: mappings are merged
+// key by key, while scalars and lists in the extending config replace the inherited value and
+// a null value removes it. Configs may extend configs that extend others; cycles are errors.
+func LoadEcosystemConfigs(paths []string) ([]*EcosystemConfig, map[string]error) {
+	files := make([]*configFile, 0, len(paths))
+	byID := make(map[string]*configFile)
+	for _, path := range paths {
+		file := parseConfigFile(path)
+		files = append(files, file)
+		if file.err == nil && file.id != "" {
+			if _, ok := byID[file.id]; !ok {
+				byID[file.id] = file
+			}
+		}
+	}
+
+	r := &resolver{byID: byID, done: make(map[*configFile]*resolved)}
+	var configs []*EcosystemConfig
+	errs := make(map[string]error)
+	for _, file := range files {
+		config, err := r.load(file)
+		if err != nil {
+			errs[file.path] = err
+			continue
+		}
+		configs = append(configs, config)
+	}
+	return configs, errs
+}
+
+// parseConfigFile reads a config file as a generic YAML document
+func parseConfigFile(path string) *configFile {
+	file := &configFile{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		file.err = &common.ErrNotFound{Resource: "config file", Path: path}
+		return file
+	}
+	if err := yaml.Unmarshal(data, &file.doc); err != nil {
+		file.err = &common.ErrInvalidConfig{Message: fmt.Sprintf("failed to parse YAML: %v", err)}
+		return file
+	}
+
+	if ecosystem, ok := file.doc["ecosystem"].(map[string]interface{}); ok {
+		file.id, _ = ecosystem["id"].(string)
+		file.extends, _ = ecosystem["extends"].(string)
+	}
+	return file
+}
+
+// resolver merges config files with the configs they extend, remembering the results
+// so shared parents are merged once
+type resolver struct {
+	byID map[string]*configFile
+	done map[*configFile]*resolved
+}
+
+// load resolves a config file and decodes it into a validated config
+func (r *resolver) load(file *configFile) (*EcosystemConfig, error) {
+	result := r.resolve(file, nil)
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	// Round-trip through YAML so the merged document decodes like a single file
+	data, err := yaml.Marshal(result.doc)
+	if err != nil {
+		return nil, &common.ErrInvalidConfig{Message: fmt.Sprintf("failed to encode merged config: %v", err)}
+	}
+	var config EcosystemConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, &common.ErrInvalidConfig{Message: fmt.Sprintf("failed to parse YAML: %v", err)}
+	}
+	if err := validateConfig(&config); err != nil {
+		return nil, err
+	}
+
+	config.Sources = result.sources
+	config.merged = result.doc
+	return &config, nil
+}
+
+// resolve merges a config file over the configs it extends. chain holds the IDs of the
+// configs currently being resolved, to detect cycles.
+func (r *resolver) resolve(file *configFile, chain []string) *resolved {
+	if result, ok := r.done[file]; ok {
+		return result
+	}
+	if file.err != nil {
+		return &resolved{err: file.err}
+	}
+	if file.extends == "" {
+		return r.remember(file, &resolved{doc: file.doc, sources: []string{file.path}})
+	}
+
+	if file.id == "" {
+		return r.remember(file, &resolved{err: &common.ErrInvalidConfig{Field: "ecosystem.id", Message: "required"}})
+	}
+	chain = append(chain, file.id)
+	for _, id := range chain {
+		if id == file.extends {
+			return &resolved{err: &common.ErrInvalidConfig{
+				Field:   "ecosystem.extends",
+				Message: fmt.Sprintf("cycle: %s -> %s", strings.Join(chain, " -> "), file.extends),
+			}}
+		}
+	}
+
+	parentFile, ok := r.byID[file.extends]
+	if !ok {
+		return r.remember(file, &resolved{err: &common.ErrInvalidConfig{Field: "ecosystem.extends", Message: fmt.Sprintf("unknown ecosystem %s", file.extends)}})
+	}
+	parent := r.resolve(parentFile, chain)
+	if parent.err != nil {
+		return &resolved{err: &common.ErrInvalidConfig{
+			Field:   "ecosystem.extends",
+			Message: fmt.Sprintf("%s failed to load: %v", file.extends, parent.err),
+		}}
+	}
+
+	return r.remember(file, &resolved{
+		doc:     mergeYAML(parent.doc, file.doc),
+		sources: append(append([]string{}, parent.sources...), file.path),
+	})
+}
+
+// remember stores the result of resolving a file. Results depending on the chain being
+// resolved, such as cycles, are not stored.
+func (r *resolver) remember(file *configFile, result *resolved) *resolved {
+	r.done[file] = result
+	return result
+}
+
+// mergeYAML deep-merges override into base without modifying either: mappings are merged
+// key by key, other values in override replace those in base and null values remove them
+func mergeYAML(base, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		if v == nil {
+			delete(merged, k)
+			continue
+		}
+		baseMap, baseIsMap := merged[k].(map[string]interface{})
+		overrideMap, overrideIsMap := v.(map[string]interface{})
+		if baseIsMap && overrideIsMap {
+			merged[k] = mergeYAML(baseMap, overrideMap)
+			continue
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+// Dump renders a config as the single YAML document it was resolved to, with the configs
+// it extends merged in, for debugging inheritance. Comments list the source files.
+func (c *EcosystemConfig) Dump() ([]byte, error) {
+	var doc interface{} = c.merged
+	if c.merged == nil {
+		doc = c
+	}
+
+	var b bytes.Buffer
+	for i, source := range c.Sources {
+		if i == len(c.Sources)-1 {
+			fmt.Fprintf(&b, "# %s\n", source)
+		} else {
+			fmt.Fprintf(&b, "# extends %s\n", source)
+		}
+	}
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	encoder.Close()
+	return b.Bytes(), nil
+}
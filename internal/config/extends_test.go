@@ -0,0 +1,180 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dev-env-sentinel/internal/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfigs writes config files into a directory and returns their paths in name order
+func writeConfigs(t *testing.T, files map[string]string) (string, []string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	paths, err := findConfigFiles(dir, false)
+	require.NoError(t, err)
+	return dir, paths
+}
+
+const baseJVMConfig = `
+ecosystem:
+  name: "JVM base"
+  id: "jvm-base"
+  manifest:
+    primary_file: "pom.xml"
+    format: "xml"
+  build:
+    output_directories:
+      - "target/classes"
+    clean_command: "mvn clean"
+  environment:
+    config_files:
+      - "src/main/resources/application.properties"
+    required_vars:
+      - "JAVA_HOME"
+`
+
+func TestLoadEcosystemConfigs_Extends(t *testing.T) {
+	dir, paths := writeConfigs(t, map[string]string{
+		"a-base.yaml": baseJVMConfig,
+		"b-gradle.yaml": `
+ecosystem:
+  name: "JVM Gradle"
+  id: "jvm-gradle"
+  extends: "jvm-base"
+  manifest:
+    primary_file: "build.gradle"
+  build:
+    output_directories:
+      - "build/classes"
+  environment:
+    required_vars: ~
+`,
+		"c-kotlin.yaml": `
+ecosystem:
+  id: "jvm-kotlin"
+  extends: "jvm-gradle"
+  build:
+    clean_command: "gradle clean"
+`,
+	})
+
+	configs, errs := LoadEcosystemConfigs(paths)
+	require.Empty(t, errs)
+	require.Len(t, configs, 3)
+
+	gradle := configs[1].Ecosystem
+	assert.Equal(t, "jvm-gradle", gradle.ID)
+	assert.Equal(t, "jvm-base", gradle.Extends)
+	assert.Equal(t, "build.gradle", gradle.Manifest.PrimaryFile)
+	assert.Equal(t, "xml", gradle.Manifest.Format, "mappings are merged key by key")
+	assert.Equal(t, []string{"build/classes"}, gradle.Build.OutputDirectories, "lists are replaced")
+	assert.Equal(t, "mvn clean", gradle.Build.CleanCommand)
+	assert.Equal(t, []string{"src/main/resources/application.properties"}, gradle.Environment.ConfigFiles)
+	assert.Empty(t, gradle.Environment.RequiredVars, "null removes the inherited value")
+
+	kotlin := configs[2]
+	assert.Equal(t, "JVM Gradle", kotlin.Ecosystem.Name)
+	assert.Equal(t, "gradle clean", kotlin.Ecosystem.Build.CleanCommand)
+	assert.Equal(t, "build.gradle", kotlin.Ecosystem.Manifest.PrimaryFile)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "a-base.yaml"),
+		filepath.Join(dir, "b-gradle.yaml"),
+		filepath.Join(dir, "c-kotlin.yaml"),
+	}, kotlin.Sources)
+
+	assert.Equal(t, "xml", configs[0].Ecosystem.Manifest.Format)
+	assert.Equal(t, []string{"JAVA_HOME"}, configs[0].Ecosystem.Environment.RequiredVars, "the extended config is unchanged")
+}
+
+func TestLoadEcosystemConfigs_ExtendsErrors(t *testing.T) {
+	_, paths := writeConfigs(t, map[string]string{
+		"base.yaml": baseJVMConfig,
+		"cycle-a.yaml": `
+ecosystem:
+  id: "cycle-a"
+  extends: "cycle-b"
+`,
+		"cycle-b.yaml": `
+ecosystem:
+  id: "cycle-b"
+  extends: "cycle-a"
+`,
+		"self.yaml": `
+ecosystem:
+  id: "self"
+  extends: "self"
+`,
+		"unknown.yaml": `
+ecosystem:
+  id: "orphan"
+  extends: "missing"
+  manifest:
+    primary_file: "x"
+`,
+		"broken.yaml": `ecosystem: [`,
+		"broken-child.yaml": `
+ecosystem:
+  id: "broken-child"
+  extends: "cycle-a"
+`,
+	})
+
+	configs, errs := LoadEcosystemConfigs(paths)
+	require.Len(t, configs, 1)
+	assert.Equal(t, "jvm-base", configs[0].Ecosystem.ID)
+	assert.Len(t, errs, 6)
+
+	errorOf := func(name string) string {
+		for path, err := range errs {
+			if filepath.Base(path) == name {
+				return err.Error()
+			}
+		}
+		t.Fatalf("no error for %s", name)
+		return ""
+	}
+	assert.Contains(t, errorOf("cycle-a.yaml"), "cycle: cycle-a -> cycle-b -> cycle-a")
+	assert.Contains(t, errorOf("cycle-b.yaml"), "cycle: cycle-b -> cycle-a -> cycle-b")
+	assert.Contains(t, errorOf("self.yaml"), "cycle: self -> self")
+	assert.Contains(t, errorOf("unknown.yaml"), "unknown ecosystem missing")
+	assert.Contains(t, errorOf("broken.yaml"), "failed to parse YAML")
+	assert.Contains(t, errorOf("broken-child.yaml"), "cycle-a failed to load")
+
+	for _, err := range errs {
+		var invalid *common.ErrInvalidConfig
+		assert.ErrorAs(t, err, &invalid)
+	}
+}
+
+func TestEcosystemConfig_Dump(t *testing.T) {
+	dir, paths := writeConfigs(t, map[string]string{
+		"base.yaml": baseJVMConfig,
+		"gradle.yaml": `
+ecosystem:
+  id: "jvm-gradle"
+  extends: "jvm-base"
+  manifest:
+    primary_file: "build.gradle"
+`,
+	})
+
+	configs, errs := LoadEcosystemConfigs(paths)
+	require.Empty(t, errs)
+	require.Len(t, configs, 2)
+
+	data, err := configs[1].Dump()
+	require.NoError(t, err)
+	dump := string(data)
+	assert.Contains(t, dump, "# extends "+filepath.Join(dir, "base.yaml")+"\n# "+filepath.Join(dir, "gradle.yaml")+"\n")
+	assert.Contains(t, dump, "primary_file: build.gradle")
+	assert.Contains(t, dump, "clean_command: mvn clean")
+	assert.Contains(t, dump, "extends: jvm-base")
+}
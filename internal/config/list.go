@@ -0,0 +1,66 @@
+package config
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Summary describes a loaded config for listing: where it came from and the rules
+// that detect its ecosystem in a project
+type Summary struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Version string   `json:"version"`
+	Kind    string   `json:"kind"`    // "language", "tool" or "infrastructure", from the directory the config is in
+	Sources []string `json:"sources"` // Files the config was loaded from, the configs it extends first
+	Extends string   `json:"extends,omitempty"`
+
+	RequiredFiles     []string `json:"required_files,omitempty"`     // All must exist for the ecosystem to be detected
+	OptionalFiles     []string `json:"optional_files,omitempty"`     // Raise detection confidence
+	DirectoryPatterns []string `json:"directory_patterns,omitempty"` // Raise detection confidence
+}
+
+// kindDirs maps config directories to the kind of config they hold
+var kindDirs = map[string]string{
+	"languages":        "language",
+	"language-configs": "language",
+	"tools":            "tool",
+	"tool-configs":     "tool",
+	"infrastructure":   "infrastructure",
+}
+
+// Summarize describes configs, sorted by ID
+func Summarize(configs []*EcosystemConfig) []Summary {
+	summaries := make([]Summary, 0, len(configs))
+	for _, c := range configs {
+		eco := c.Ecosystem
+		summary := Summary{
+			ID:                eco.ID,
+			Name:              eco.Name,
+			Version:           eco.Version,
+			Sources:           c.Sources,
+			Extends:           eco.Extends,
+			RequiredFiles:     eco.Detection.RequiredFiles,
+			OptionalFiles:     eco.Detection.OptionalFiles,
+			DirectoryPatterns: eco.Detection.DirectoryPatterns,
+		}
+		if len(c.Sources) > 0 {
+			summary.Kind = configKind(c.Sources[len(c.Sources)-1])
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].ID < summaries[j].ID })
+	return summaries
+}
+
+// configKind returns the kind of config a file holds from the nearest known directory above it
+func configKind(path string) string {
+	dirs := strings.Split(filepath.ToSlash(filepath.Dir(path)), "/")
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if kind, ok := kindDirs[dirs[i]]; ok {
+			return kind
+		}
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	java := &EcosystemConfig{Sources: []string{filepath.Join("config", "languages", "java.yaml")}}
+	java.Ecosystem.ID = "java"
+	java.Ecosystem.Version = "1.0"
+	java.Ecosystem.Detection.OptionalFiles = []string{"pom.xml"}
+
+	maven := &EcosystemConfig{Sources: []string{filepath.Join("config", "languages", "java.yaml"), filepath.Join("config", "tools", "java", "maven.yaml")}}
+	maven.Ecosystem.ID = "maven"
+	maven.Ecosystem.Extends = "java"
+	maven.Ecosystem.Detection.RequiredFiles = []string{"pom.xml"}
+
+	summaries := Summarize([]*EcosystemConfig{maven, java})
+	require.Len(t, summaries, 2)
+	assert.Equal(t, "java", summaries[0].ID)
+	assert.Equal(t, "language", summaries[0].Kind)
+	assert.Equal(t, []string{"pom.xml"}, summaries[0].OptionalFiles)
+	assert.Equal(t, "maven", summaries[1].ID)
+	assert.Equal(t, "tool", summaries[1].Kind, "kind comes from the config's own file, not the one it extends")
+	assert.Equal(t, "java", summaries[1].Extends)
+}
+
+func TestConfigKind(t *testing.T) {
+	assert.Equal(t, "infrastructure", configKind(filepath.Join("base", "config", "infrastructure", "databases", "postgres.yaml")))
+	assert.Equal(t, "tool", configKind(filepath.Join("base", "tool-configs", "npm.yaml")))
+	assert.Equal(t, "", configKind(filepath.Join("testdata", "go.yaml")))
+}
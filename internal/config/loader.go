@@ -2,39 +2,55 @@ package config
 
 import (
 	"fmt"
+	"io"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"dev-env-sentinel/internal/common"
-	"gopkg.in/yaml.v3"
+	"dev-env-sentinel/internal/installer"
 )
 
-// LoadEcosystemConfig loads an ecosystem configuration from a YAML file
+// LoadEcosystemConfig loads an ecosystem configuration from a YAML file. A config that
+// extends another can only be loaded together with it, using LoadEcosystemConfigs.
 func LoadEcosystemConfig(path string) (*EcosystemConfig, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, &common.ErrNotFound{Resource: "config file", Path: path}
-	}
-
-	var config EcosystemConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, &common.ErrInvalidConfig{Message: fmt.Sprintf("failed to parse YAML: %v", err)}
-	}
-
-	if err := validateConfig(&config); err != nil {
+	configs, errs := LoadEcosystemConfigs([]string{path})
+	if err := errs[path]; err != nil {
 		return nil, err
 	}
-
-	return &config, nil
+	return configs[0], nil
 }
 
 // DiscoverEcosystemConfigs finds all ecosystem config files in the config directory structure
 // New structure: config/languages/ (language yamls only), config/tools/{lang}/ (language-specific tool yamls),
 // config/infrastructure/ (infrastructure tools including databases, containers, docker, etc.)
 // Falls back to old structure (language-configs, tool-configs) or baseDir for backwards compatibility
+// Configs of the user layer (UserConfigDir) are loaded after them and replace configs with their ID.
+// Configs that fail to load are skipped. Files that fail to load and keys that are not part of
+// the config schema are reported as warnings on WarningOutput.
 func DiscoverEcosystemConfigs(baseDir string) ([]*EcosystemConfig, error) {
-	var configs []*EcosystemConfig
+	configs, warnings, err := DiscoverEcosystemConfigsWithWarnings(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(WarningOutput, "warning: config %s\n", w)
+	}
+	return configs, nil
+}
+
+// WarningOutput receives the config warnings of DiscoverEcosystemConfigs
+var WarningOutput io.Writer = os.Stderr
+
+// DiscoverEcosystemConfigsWithWarnings discovers configs like DiscoverEcosystemConfigs and
+// returns the problems found with the config files instead of printing them
+func DiscoverEcosystemConfigsWithWarnings(baseDir string) ([]*EcosystemConfig, []Warning, error) {
+	var paths []string
 
 	configDir := filepath.Join(baseDir, "config")
 	langDir := filepath.Join(configDir, "languages")
@@ -45,29 +61,29 @@ func DiscoverEcosystemConfigs(baseDir string) ([]*EcosystemConfig, error) {
 	if common.DirExists(configDir) {
 		// Discover language configs (YAML files directly in languages directory, not recursive)
 		if common.DirExists(langDir) {
-			langConfigs, err := discoverConfigsInDir(langDir, false)
+			langPaths, err := findConfigFiles(langDir, false)
 			if err != nil {
-				return nil, fmt.Errorf("failed to discover language configs: %w", err)
+				return nil, nil, fmt.Errorf("failed to discover language configs: %w", err)
 			}
-			configs = append(configs, langConfigs...)
+			paths = append(paths, langPaths...)
 		}
 
 		// Discover language-specific tool configs recursively (config/tools/{lang}/*.yaml)
 		if common.DirExists(toolsDir) {
-			toolConfigs, err := discoverConfigsInDir(toolsDir, true)
+			toolPaths, err := findConfigFiles(toolsDir, true)
 			if err != nil {
-				return nil, fmt.Errorf("failed to discover tool configs: %w", err)
+				return nil, nil, fmt.Errorf("failed to discover tool configs: %w", err)
 			}
-			configs = append(configs, toolConfigs...)
+			paths = append(paths, toolPaths...)
 		}
 
 		// Discover infrastructure tool configs recursively (includes databases, containers, docker, etc.)
 		if common.DirExists(infraDir) {
-			infraConfigs, err := discoverConfigsInDir(infraDir, true)
+			infraPaths, err := findConfigFiles(infraDir, true)
 			if err != nil {
-				return nil, fmt.Errorf("failed to discover infrastructure configs: %w", err)
+				return nil, nil, fmt.Errorf("failed to discover infrastructure configs: %w", err)
 			}
-			configs = append(configs, infraConfigs...)
+			paths = append(paths, infraPaths...)
 		}
 	} else {
 		// Fallback to old structure: language-configs and tool-configs
@@ -77,43 +93,113 @@ func DiscoverEcosystemConfigs(baseDir string) ([]*EcosystemConfig, error) {
 		if common.DirExists(oldLangDir) || common.DirExists(oldToolDir) {
 			// Discover language configs
 			if common.DirExists(oldLangDir) {
-				langConfigs, err := discoverConfigsInDir(oldLangDir, false)
+				langPaths, err := findConfigFiles(oldLangDir, false)
 				if err != nil {
-					return nil, fmt.Errorf("failed to discover language configs: %w", err)
+					return nil, nil, fmt.Errorf("failed to discover language configs: %w", err)
 				}
-				configs = append(configs, langConfigs...)
+				paths = append(paths, langPaths...)
 			}
 
 			// Discover tool configs recursively
 			if common.DirExists(oldToolDir) {
-				toolConfigs, err := discoverConfigsInDir(oldToolDir, true)
+				toolPaths, err := findConfigFiles(oldToolDir, true)
 				if err != nil {
-					return nil, fmt.Errorf("failed to discover tool configs: %w", err)
+					return nil, nil, fmt.Errorf("failed to discover tool configs: %w", err)
 				}
-				configs = append(configs, toolConfigs...)
+				paths = append(paths, toolPaths...)
 			}
 		} else {
 			// Final fallback: discover configs directly in baseDir (for tests)
 			if !common.DirExists(baseDir) {
-				return nil, &common.ErrNotFound{Resource: "config directory", Path: baseDir}
+				return nil, nil, &common.ErrNotFound{Resource: "config directory", Path: baseDir}
 			}
 			
-			flatConfigs, err := discoverConfigsInDir(baseDir, false)
+			flatPaths, err := findConfigFiles(baseDir, false)
 			if err != nil {
-				return nil, fmt.Errorf("failed to discover configs: %w", err)
+				return nil, nil, fmt.Errorf("failed to discover configs: %w", err)
 			}
-			configs = append(configs, flatConfigs...)
+			paths = append(paths, flatPaths...)
 		}
 	}
 
-	return configs, nil
+	// The user layer comes last, so its configs can extend the shipped ones
+	userDir := UserConfigDir()
+	var userPaths []string
+	if userDir != "" && common.DirExists(userDir) {
+		var err error
+		userPaths, err = findConfigFiles(userDir, true)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to discover user configs: %w", err)
+		}
+		paths = append(paths, userPaths...)
+	}
+
+	configs, errs := LoadEcosystemConfigs(paths)
+	configs = overrideByUserLayer(configs, userPaths)
+	var warnings []Warning
+	for _, path := range paths {
+		if err := errs[path]; err != nil {
+			warnings = append(warnings, Warning{File: path, Message: fmt.Sprintf("skipped: %v", err)})
+			continue
+		}
+		unknown, _ := UnknownKeys(path)
+		warnings = append(warnings, unknown...)
+	}
+	return configs, warnings, nil
+}
+
+// UserConfigDirEnvVar overrides the directory of the user config layer
+const UserConfigDirEnvVar = "SENTINEL_USER_CONFIG_DIR"
+
+// UserConfigDir returns the directory of the user config layer: configs installed with
+// `sentinel config add` or written by hand, loaded after the shipped configs. It is
+// SENTINEL_USER_CONFIG_DIR, else ~/.dev-env-sentinel/configs.
+func UserConfigDir() string {
+	if dir := os.Getenv(UserConfigDirEnvVar); dir != "" {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".dev-env-sentinel", "configs")
+}
+
+// overrideByUserLayer drops the configs whose ID a config of the user layer defines too, so
+// an installed config replaces the shipped one
+func overrideByUserLayer(configs []*EcosystemConfig, userPaths []string) []*EcosystemConfig {
+	if len(userPaths) == 0 {
+		return configs
+	}
+	isUser := make(map[string]bool, len(userPaths))
+	for _, path := range userPaths {
+		isUser[path] = true
+	}
+	fromUser := func(c *EcosystemConfig) bool {
+		return len(c.Sources) > 0 && isUser[c.Sources[len(c.Sources)-1]]
+	}
+
+	userIDs := make(map[string]bool)
+	for _, c := range configs {
+		if fromUser(c) {
+			userIDs[c.Ecosystem.ID] = true
+		}
+	}
+	kept := configs[:0]
+	for _, c := range configs {
+		if userIDs[c.Ecosystem.ID] && !fromUser(c) {
+			continue
+		}
+		kept = append(kept, c)
+	}
+	return kept
 }
 
-// discoverConfigsInDir finds all YAML config files in a directory, optionally recursing into subdirectories
+// findConfigFiles finds all YAML config files in a directory, optionally recursing into subdirectories
 // When recursive=false, it discovers YAML files in the current directory only
 // When recursive=true, it discovers YAML files in the current directory AND recursively in subdirectories
-func discoverConfigsInDir(dir string, recursive bool) ([]*EcosystemConfig, error) {
-	var configs []*EcosystemConfig
+func findConfigFiles(dir string, recursive bool) ([]string, error) {
+	var paths []string
 
 	entries, err := os.ReadDir(dir)
 	if err != nil {
@@ -125,29 +211,23 @@ func discoverConfigsInDir(dir string, recursive bool) ([]*EcosystemConfig, error
 			if recursive {
 				// Recursively discover configs in subdirectories
 				subDir := filepath.Join(dir, entry.Name())
-				subConfigs, err := discoverConfigsInDir(subDir, true)
+				subPaths, err := findConfigFiles(subDir, true)
 				if err != nil {
 					// Log error but continue with other directories
 					continue
 				}
-				configs = append(configs, subConfigs...)
+				paths = append(paths, subPaths...)
 			}
 			continue
 		}
 
 		// Process YAML files in current directory
 		if isYAMLFile(entry.Name()) {
-			configPath := filepath.Join(dir, entry.Name())
-			config, err := LoadEcosystemConfig(configPath)
-			if err != nil {
-				// Log error but continue with other configs
-				continue
-			}
-			configs = append(configs, config)
+			paths = append(paths, filepath.Join(dir, entry.Name()))
 		}
 	}
 
-	return configs, nil
+	return paths, nil
 }
 
 // validateConfig validates the configuration structure
@@ -160,9 +240,410 @@ func validateConfig(config *EcosystemConfig) error {
 		return &common.ErrInvalidConfig{Field: "ecosystem.manifest.primary_file", Message: "required"}
 	}
 
+	if err := validateVerification(config); err != nil {
+		return err
+	}
+	if err := validateServices(config); err != nil {
+		return err
+	}
+	if err := validateVersionConfig(config.Ecosystem.VersionConfig); err != nil {
+		return err
+	}
+	if err := validateDaemons(config); err != nil {
+		return err
+	}
+	if err := validateLimits(config.Ecosystem.Infrastructure.Limits); err != nil {
+		return err
+	}
+	if err := validateBuildCaches(config.Ecosystem.Infrastructure.BuildCaches); err != nil {
+		return err
+	}
+	if err := validateDocs(config.Ecosystem.Docs); err != nil {
+		return err
+	}
+	if err := validateFixes(config.Ecosystem.Reconciliation.Fixes); err != nil {
+		return err
+	}
+	if err := validateFailureSignatures(config.Ecosystem.FailureSignatures); err != nil {
+		return err
+	}
+	return validateConditions(config)
+}
+
+// validateVerification checks the concurrency, target selection and durations of verification commands
+func validateVerification(config *EcosystemConfig) error {
+	if config.Ecosystem.Verification.BuildFreshness.MaxParallel < 0 {
+		return &common.ErrInvalidConfig{
+			Field:   "verification.build_freshness.max_parallel",
+			Message: "must not be negative",
+		}
+	}
+	for _, cmd := range config.Ecosystem.Verification.BuildFreshness.Commands {
+		if cmd.TargetSelection != "" && !isTargetSelection(cmd.TargetSelection) {
+			return &common.ErrInvalidConfig{
+				Field:   "verification.build_freshness.commands." + cmd.Name + ".target_selection",
+				Message: fmt.Sprintf("unknown strategy %q (use %s)", cmd.TargetSelection, strings.Join(TargetSelections, ", ")),
+			}
+		}
+		if cmd.MaxSpread != "" {
+			if _, err := time.ParseDuration(cmd.MaxSpread); err != nil {
+				return &common.ErrInvalidConfig{
+					Field:   "verification.build_freshness.commands." + cmd.Name + ".max_spread",
+					Message: fmt.Sprintf("invalid duration %q (use e.g. 10m)", cmd.MaxSpread),
+				}
+			}
+		}
+		if cmd.MinMatches < 0 {
+			return &common.ErrInvalidConfig{
+				Field:   "verification.build_freshness.commands." + cmd.Name + ".min_matches",
+				Message: "must not be negative",
+			}
+		}
+		if cmd.Type == "module_check" && !isModuleLayout(cmd.Modules) {
+			return &common.ErrInvalidConfig{
+				Field:   "verification.build_freshness.commands." + cmd.Name + ".modules",
+				Message: fmt.Sprintf("unknown module layout %q (use %s)", cmd.Modules, strings.Join(ModuleLayouts, ", ")),
+			}
+		}
+		if err := validateClassify("verification.build_freshness.commands."+cmd.Name, cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateClassify checks that the outcomes of a command check match something and name an issue
+func validateClassify(field string, cmd VerificationCommand) error {
+	if cmd.Timeout != "" {
+		if timeout, err := time.ParseDuration(cmd.Timeout); err != nil || timeout <= 0 {
+			return &common.ErrInvalidConfig{Field: field + ".timeout", Message: fmt.Sprintf("invalid duration %q (use e.g. 5m)", cmd.Timeout)}
+		}
+	}
+	if len(cmd.Classify) > 0 && cmd.Command == "" {
+		return &common.ErrInvalidConfig{Field: field + ".command", Message: "required with classify"}
+	}
+	for i, outcome := range cmd.Classify {
+		outcomeField := fmt.Sprintf("%s.classify[%d]", field, i)
+		if outcome.ExitCode == nil && outcome.Output == "" {
+			return &common.ErrInvalidConfig{Field: outcomeField, Message: "exit_code or output is required"}
+		}
+		if _, err := regexp.Compile(outcome.Output); err != nil {
+			return &common.ErrInvalidConfig{Field: outcomeField + ".output", Message: fmt.Sprintf("invalid regex: %v", err)}
+		}
+		if outcome.IssueType == "" {
+			return &common.ErrInvalidConfig{Field: outcomeField + ".issue_type", Message: "required"}
+		}
+		switch outcome.Severity {
+		case "", "warning", "error", "critical":
+		default:
+			return &common.ErrInvalidConfig{Field: outcomeField + ".severity", Message: fmt.Sprintf("unknown severity %q (use warning, error or critical)", outcome.Severity)}
+		}
+	}
+	return nil
+}
+
+// validateServices checks that services with a version constraint can extract their version
+func validateServices(config *EcosystemConfig) error {
+	for _, service := range config.Ecosystem.Infrastructure.Services {
+		field := "infrastructure.services." + service.Name
+		if (service.ExpectedVersion != "" || service.MinVersion != "") && service.VersionExtract == "" {
+			return &common.ErrInvalidConfig{Field: field + ".version_extract", Message: "required with expected_version or min_version"}
+		}
+		if _, err := regexp.Compile(service.VersionExtract); err != nil {
+			return &common.ErrInvalidConfig{Field: field + ".version_extract", Message: fmt.Sprintf("invalid regex: %v", err)}
+		}
+		if service.Logs != nil {
+			if err := validateServiceLogs(field+".logs", service.Logs); err != nil {
+				return err
+			}
+		}
+		if err := validatePackages(field+".packages", service.Packages); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateVersionConfig checks that a version is detected with either a shell command or argv,
+// and that install locations say where to look
+func validateVersionConfig(versionCfg VersionConfig) error {
+	if len(versionCfg.VersionArgs) > 0 {
+		if versionCfg.VersionCommand != "" {
+			return &common.ErrInvalidConfig{Field: "version_config.version_args", Message: "use version_command or version_args, not both"}
+		}
+		if strings.TrimSpace(versionCfg.VersionArgs[0]) == "" {
+			return &common.ErrInvalidConfig{Field: "version_config.version_args", Message: "the first argument must name a program"}
+		}
+	}
+	for i, location := range versionCfg.InstallLocations {
+		field := fmt.Sprintf("version_config.install_locations[%d]", i)
+		if location.Source == "" {
+			return &common.ErrInvalidConfig{Field: field + ".source", Message: "required"}
+		}
+		if location.Path == "" {
+			return &common.ErrInvalidConfig{Field: field + ".path", Message: "required"}
+		}
+		if _, err := filepath.Match(filepath.ToSlash(location.Path), ""); err != nil {
+			return &common.ErrInvalidConfig{Field: field + ".path", Message: fmt.Sprintf("invalid glob: %v", err)}
+		}
+	}
+	return validatePackages("version_config.packages", versionCfg.Packages)
+}
+
+// validatePackages checks that packages are given for known installers
+func validatePackages(field string, packages map[string]string) error {
+	names := make([]string, 0, len(packages))
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := installer.Lookup(name); !ok {
+			return &common.ErrInvalidConfig{Field: field + "." + name, Message: fmt.Sprintf("unknown installer (use %s)", strings.Join(installer.Names(), ", "))}
+		}
+		if packages[name] == "" {
+			return &common.ErrInvalidConfig{Field: field + "." + name, Message: "required"}
+		}
+	}
+	return nil
+}
+
+// validateServiceLogs checks that service logs have exactly one source and valid patterns
+func validateServiceLogs(field string, logs *ServiceLogs) error {
+	sources := 0
+	for _, source := range []string{logs.Container, logs.Unit, logs.File} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return &common.ErrInvalidConfig{Field: field, Message: "exactly one of container, unit or file is required"}
+	}
+	if logs.Lines < 0 {
+		return &common.ErrInvalidConfig{Field: field + ".lines", Message: "must not be negative"}
+	}
+	if logs.MaxMatches < 0 {
+		return &common.ErrInvalidConfig{Field: field + ".max_matches", Message: "must not be negative"}
+	}
+	for _, pattern := range logs.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return &common.ErrInvalidConfig{Field: field + ".patterns", Message: fmt.Sprintf("invalid regex: %v", err)}
+		}
+	}
+	return nil
+}
+
+// validateDaemons checks that daemons have a process pattern and their patterns compile
+func validateDaemons(config *EcosystemConfig) error {
+	for _, daemon := range config.Ecosystem.Infrastructure.Daemons {
+		field := "infrastructure.daemons." + daemon.Name
+		if daemon.Process == "" {
+			return &common.ErrInvalidConfig{Field: field + ".process", Message: "required"}
+		}
+		patterns := []struct{ name, value string }{
+			{"process", daemon.Process},
+			{"version_extract", daemon.VersionExtract},
+			{"expected_version.pattern", daemon.ExpectedVersion.Pattern},
+			{"status_pattern", daemon.StatusPattern},
+		}
+		for _, p := range patterns {
+			if _, err := regexp.Compile(p.value); err != nil {
+				return &common.ErrInvalidConfig{Field: field + "." + p.name, Message: fmt.Sprintf("invalid regex: %v", err)}
+			}
+		}
+		if daemon.MaxInstances < 0 {
+			return &common.ErrInvalidConfig{Field: field + ".max_instances", Message: "must not be negative"}
+		}
+	}
+	return nil
+}
+
+// validateBuildCaches checks that build caches are named, their settings say where to look and
+// their patterns compile
+func validateBuildCaches(caches []BuildCache) error {
+	for _, cache := range caches {
+		field := "infrastructure.build_caches." + cache.Name
+		if cache.Name == "" {
+			return &common.ErrInvalidConfig{Field: "infrastructure.build_caches.name", Message: "required"}
+		}
+		type namedSetting struct {
+			name    string
+			setting CacheSetting
+		}
+		var settings []namedSetting
+		for i, setting := range cache.Configured {
+			settings = append(settings, namedSetting{fmt.Sprintf("configured[%d]", i), setting})
+		}
+		for i, setting := range cache.Remote {
+			settings = append(settings, namedSetting{fmt.Sprintf("remote[%d]", i), setting})
+		}
+		for i, rule := range cache.Disabled {
+			settings = append(settings, namedSetting{fmt.Sprintf("disabled[%d]", i), rule.CacheSetting})
+			if rule.Message == "" {
+				return &common.ErrInvalidConfig{Field: fmt.Sprintf("%s.disabled[%d].message", field, i), Message: "required"}
+			}
+		}
+		for _, s := range settings {
+			if (s.setting.Env == "") == (s.setting.File == "") {
+				return &common.ErrInvalidConfig{Field: field + "." + s.name, Message: "exactly one of env or file is required"}
+			}
+			if _, err := regexp.Compile(s.setting.Pattern); err != nil {
+				return &common.ErrInvalidConfig{Field: field + "." + s.name + ".pattern", Message: fmt.Sprintf("invalid regex: %v", err)}
+			}
+		}
+		patterns := []struct{ name, value string }{
+			{"hits", cache.Hits},
+			{"misses", cache.Misses},
+			{"errors", cache.Errors},
+		}
+		for _, p := range patterns {
+			if _, err := regexp.Compile(p.value); err != nil {
+				return &common.ErrInvalidConfig{Field: field + "." + p.name, Message: fmt.Sprintf("invalid regex: %v", err)}
+			}
+		}
+		if cache.StatsCommand != "" && (cache.Hits == "" || cache.Misses == "") {
+			return &common.ErrInvalidConfig{Field: field + ".stats_command", Message: "needs hits and misses patterns"}
+		}
+		if cache.MinHitRate < 0 || cache.MinHitRate > 100 {
+			return &common.ErrInvalidConfig{Field: field + ".min_hit_rate", Message: "must be between 0 and 100"}
+		}
+		if cache.MinRequests < 0 {
+			return &common.ErrInvalidConfig{Field: field + ".min_requests", Message: "must not be negative"}
+		}
+	}
 	return nil
 }
 
+// validateLimits checks that declared OS limits are not negative
+func validateLimits(limits Limits) error {
+	values := []struct {
+		name  string
+		value int
+	}{
+		{"watches", limits.Watches},
+		{"watcher_instances", limits.WatcherInstances},
+		{"open_files", limits.OpenFiles},
+	}
+	for _, v := range values {
+		if v.value < 0 {
+			return &common.ErrInvalidConfig{Field: "infrastructure.limits." + v.name, Message: "must not be negative"}
+		}
+	}
+	return nil
+}
+
+// validateDocs checks that issue docs name their issue type and link or show something
+func validateDocs(docs []IssueDoc) error {
+	for i, doc := range docs {
+		field := fmt.Sprintf("docs[%d]", i)
+		if doc.IssueType == "" {
+			return &common.ErrInvalidConfig{Field: field + ".issue_type", Message: "required"}
+		}
+		if doc.URL == "" && doc.Snippet == "" {
+			return &common.ErrInvalidConfig{Field: field, Message: "url or snippet is required"}
+		}
+		if doc.URL != "" {
+			if u, err := url.Parse(doc.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return &common.ErrInvalidConfig{Field: field + ".url", Message: fmt.Sprintf("invalid URL %q (use an http or https URL)", doc.URL)}
+			}
+		}
+	}
+	return nil
+}
+
+// validateFailureSignatures checks that failure signatures have a name, a valid pattern, an
+// issue type and a message
+func validateFailureSignatures(signatures []FailureSignature) error {
+	for i, sig := range signatures {
+		field := fmt.Sprintf("failure_signatures[%d]", i)
+		if sig.Name == "" {
+			return &common.ErrInvalidConfig{Field: field + ".name", Message: "required"}
+		}
+		field = "failure_signatures." + sig.Name
+		if sig.Pattern == "" {
+			return &common.ErrInvalidConfig{Field: field + ".pattern", Message: "required"}
+		}
+		if _, err := regexp.Compile(sig.Pattern); err != nil {
+			return &common.ErrInvalidConfig{Field: field + ".pattern", Message: fmt.Sprintf("invalid regex: %v", err)}
+		}
+		if sig.IssueType == "" {
+			return &common.ErrInvalidConfig{Field: field + ".issue_type", Message: "required"}
+		}
+		if sig.Message == "" {
+			return &common.ErrInvalidConfig{Field: field + ".message", Message: "required"}
+		}
+	}
+	return nil
+}
+
+// validateFixes checks the severities of fixes and the health checks they wait for
+func validateFixes(fixes []Fix) error {
+	for _, fix := range fixes {
+		switch fix.Severity {
+		case "", "warning", "error", "critical":
+		default:
+			return &common.ErrInvalidConfig{Field: "reconciliation.fixes." + fix.IssueType + ".severity", Message: fmt.Sprintf("unknown severity %q (use warning, error or critical)", fix.Severity)}
+		}
+		if fix.Verify == nil {
+			continue
+		}
+		field := "reconciliation.fixes." + fix.IssueType + ".verify"
+		checks := 0
+		for _, check := range []string{fix.Verify.Command, fix.Verify.Port, fix.Verify.HTTP} {
+			if check != "" {
+				checks++
+			}
+		}
+		if checks != 1 {
+			return &common.ErrInvalidConfig{Field: field, Message: "exactly one of command, port or http is required"}
+		}
+		if fix.Verify.Port != "" {
+			if _, _, err := net.SplitHostPort(fix.Verify.Port); err != nil {
+				return &common.ErrInvalidConfig{Field: field + ".port", Message: fmt.Sprintf("invalid address %q (use host:port)", fix.Verify.Port)}
+			}
+		}
+		if fix.Verify.HTTP != "" {
+			if u, err := url.Parse(fix.Verify.HTTP); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return &common.ErrInvalidConfig{Field: field + ".http", Message: fmt.Sprintf("invalid URL %q (use an http or https URL)", fix.Verify.HTTP)}
+			}
+		}
+		durations := []struct{ name, value string }{
+			{"timeout", fix.Verify.Timeout},
+			{"interval", fix.Verify.Interval},
+			{"max_interval", fix.Verify.MaxInterval},
+		}
+		for _, d := range durations {
+			if d.value == "" {
+				continue
+			}
+			if value, err := time.ParseDuration(d.value); err != nil || value <= 0 {
+				return &common.ErrInvalidConfig{Field: field + "." + d.name, Message: fmt.Sprintf("invalid duration %q (use e.g. 30s)", d.value)}
+			}
+		}
+	}
+	return nil
+}
+
+// isTargetSelection reports whether a value is a target selection strategy
+func isTargetSelection(value string) bool {
+	for _, strategy := range TargetSelections {
+		if value == strategy {
+			return true
+		}
+	}
+	return false
+}
+
+// isModuleLayout checks whether a value is a known module layout
+func isModuleLayout(value string) bool {
+	for _, layout := range ModuleLayouts {
+		if value == layout {
+			return true
+		}
+	}
+	return false
+}
+
 // isYAMLFile checks if a file is a YAML file
 func isYAMLFile(filename string) bool {
 	ext := filepath.Ext(filename)
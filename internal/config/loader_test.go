@@ -222,6 +222,52 @@ func TestDiscoverEcosystemConfigs_EmptyDirectory(t *testing.T) {
 	assert.Empty(t, configs)
 }
 
+func TestDiscoverEcosystemConfigs_UserLayer(t *testing.T) {
+	baseDir := t.TempDir()
+	userDir := t.TempDir()
+	t.Setenv(UserConfigDirEnvVar, userDir)
+
+	write := func(path, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	write(filepath.Join(baseDir, "config", "languages", "java.yaml"), `
+ecosystem:
+  id: "java-maven"
+  name: "Java Maven"
+  manifest:
+    primary_file: "pom.xml"
+`)
+	write(filepath.Join(baseDir, "config", "languages", "node.yaml"), `
+ecosystem:
+  id: "node-npm"
+  manifest:
+    primary_file: "package.json"
+`)
+	// A community config extending a shipped one, and one replacing a shipped one
+	write(filepath.Join(userDir, "java-quarkus.yaml"), `
+ecosystem:
+  id: "java-quarkus"
+  extends: "java-maven"
+`)
+	write(filepath.Join(userDir, "node-npm.yaml"), `
+ecosystem:
+  id: "node-npm"
+  name: "Node (community)"
+  manifest:
+    primary_file: "package.json"
+`)
+
+	configs, err := DiscoverEcosystemConfigs(baseDir)
+	require.NoError(t, err)
+	require.Len(t, configs, 3)
+	assert.Equal(t, "java-maven", configs[0].Ecosystem.ID)
+	assert.Equal(t, "java-quarkus", configs[1].Ecosystem.ID)
+	assert.Equal(t, "pom.xml", configs[1].Ecosystem.Manifest.PrimaryFile)
+	assert.Equal(t, "node-npm", configs[2].Ecosystem.ID)
+	assert.Equal(t, "Node (community)", configs[2].Ecosystem.Name)
+}
+
 func TestIsYAMLFile(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -262,6 +308,319 @@ func TestValidateConfig(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "unknown target selection",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:       "test",
+					Manifest: Manifest{PrimaryFile: "pom.xml"},
+					Verification: Verification{BuildFreshness: BuildFreshness{Commands: []VerificationCommand{
+						{Name: "classes", Type: "timestamp_compare", TargetPattern: "target/**/*.class", TargetSelection: "latest"},
+					}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid max spread",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:       "test",
+					Manifest: Manifest{PrimaryFile: "pom.xml"},
+					Verification: Verification{BuildFreshness: BuildFreshness{Commands: []VerificationCommand{
+						{Name: "partial", Type: "partial_build_check", MaxSpread: "ten minutes"},
+					}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown module layout",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:       "test",
+					Manifest: Manifest{PrimaryFile: "pom.xml"},
+					Verification: Verification{BuildFreshness: BuildFreshness{Commands: []VerificationCommand{
+						{Name: "modules", Type: "module_check", Modules: "gradle"},
+					}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "target selection and min matches",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:       "test",
+					Manifest: Manifest{PrimaryFile: "pom.xml"},
+					Verification: Verification{BuildFreshness: BuildFreshness{Commands: []VerificationCommand{
+						{Name: "classes", Type: "timestamp_compare", TargetPattern: "target/**/*.class", TargetSelection: TargetAll, MinMatches: 2},
+					}}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "daemon without process",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:             "test",
+					Manifest:       Manifest{PrimaryFile: "build.gradle"},
+					Infrastructure: Infrastructure{Daemons: []Daemon{{Name: "gradle daemon"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "daemon with invalid version pattern",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:             "test",
+					Manifest:       Manifest{PrimaryFile: "build.gradle"},
+					Infrastructure: Infrastructure{Daemons: []Daemon{{Name: "gradle daemon", Process: "GradleDaemon", VersionExtract: "GradleDaemon (\\d+"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative open files limit",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:             "test",
+					Manifest:       Manifest{PrimaryFile: "package.json"},
+					Infrastructure: Infrastructure{Limits: Limits{OpenFiles: -1}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "build cache",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:       "test",
+					Manifest: Manifest{PrimaryFile: "CMakeLists.txt"},
+					Infrastructure: Infrastructure{BuildCaches: []BuildCache{{
+						Name:         "ccache",
+						Configured:   []CacheSetting{{Env: "CMAKE_CXX_COMPILER_LAUNCHER", Pattern: "ccache"}},
+						Disabled:     []CacheRule{{CacheSetting: CacheSetting{Env: "CCACHE_DISABLE"}, Message: "CCACHE_DISABLE is set"}},
+						StatsCommand: "ccache --print-stats",
+						Hits:         `(?m)^direct_cache_hit\t(\d+)`,
+						Misses:       `(?m)^cache_miss\t(\d+)`,
+						MinHitRate:   20,
+					}}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "build cache setting without env or file",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:             "test",
+					Manifest:       Manifest{PrimaryFile: "CMakeLists.txt"},
+					Infrastructure: Infrastructure{BuildCaches: []BuildCache{{Name: "ccache", Remote: []CacheSetting{{Pattern: "(.+)"}}}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "build cache stats without misses pattern",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:             "test",
+					Manifest:       Manifest{PrimaryFile: "CMakeLists.txt"},
+					Infrastructure: Infrastructure{BuildCaches: []BuildCache{{Name: "ccache", StatsCommand: "ccache --print-stats", Hits: `hit\t(\d+)`}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "build cache hit rate above 100",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:             "test",
+					Manifest:       Manifest{PrimaryFile: "CMakeLists.txt"},
+					Infrastructure: Infrastructure{BuildCaches: []BuildCache{{Name: "ccache", MinHitRate: 150}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "issue doc",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:       "test",
+					Manifest: Manifest{PrimaryFile: "pom.xml"},
+					Docs:     []IssueDoc{{IssueType: "missing_target", URL: "https://wiki.example.com/setup"}, {IssueType: "stale_build", Snippet: "Run `mvn install`"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "issue doc without url or snippet",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:       "test",
+					Manifest: Manifest{PrimaryFile: "pom.xml"},
+					Docs:     []IssueDoc{{IssueType: "missing_target", Title: "Setup"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "issue doc with relative url",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:       "test",
+					Manifest: Manifest{PrimaryFile: "pom.xml"},
+					Docs:     []IssueDoc{{IssueType: "missing_target", URL: "wiki/setup"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "failure signature",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:                "test",
+					Manifest:          Manifest{PrimaryFile: "pom.xml"},
+					FailureSignatures: []FailureSignature{{Name: "no_db", Pattern: `Connection to (\S+) refused`, IssueType: "service_unavailable", Message: "Nothing listens on {1}"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "failure signature with invalid pattern",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:                "test",
+					Manifest:          Manifest{PrimaryFile: "pom.xml"},
+					FailureSignatures: []FailureSignature{{Name: "no_db", Pattern: `Connection to (\S+ refused`, IssueType: "service_unavailable", Message: "Nothing listens"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "failure signature without issue type",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:                "test",
+					Manifest:          Manifest{PrimaryFile: "pom.xml"},
+					FailureSignatures: []FailureSignature{{Name: "no_db", Pattern: `refused`, Message: "Nothing listens"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "service min version without version extract",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:             "test",
+					Manifest:       Manifest{PrimaryFile: "pom.xml"},
+					Infrastructure: Infrastructure{Services: []Service{{Name: "java", CheckCommand: "java -version", MinVersion: "17"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "service logs with two sources",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:             "test",
+					Manifest:       Manifest{PrimaryFile: "pom.xml"},
+					Infrastructure: Infrastructure{Services: []Service{{Name: "postgres", CheckCommand: "pg_isready", Logs: &ServiceLogs{Container: "db", File: "logs/db.log"}}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "service logs with invalid pattern",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:             "test",
+					Manifest:       Manifest{PrimaryFile: "pom.xml"},
+					Infrastructure: Infrastructure{Services: []Service{{Name: "postgres", CheckCommand: "pg_isready", Logs: &ServiceLogs{Container: "db", Patterns: []string{"("}}}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "fix verify with two checks",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:             "test",
+					Manifest:       Manifest{PrimaryFile: "pom.xml"},
+					Reconciliation: Reconciliation{Fixes: []Fix{{IssueType: "db_down", Command: "docker compose up -d db", Verify: &FixVerify{Port: "localhost:5432", HTTP: "http://localhost:8080/health"}}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "fix verify with invalid timeout",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:             "test",
+					Manifest:       Manifest{PrimaryFile: "pom.xml"},
+					Reconciliation: Reconciliation{Fixes: []Fix{{IssueType: "db_down", Command: "docker compose up -d db", Verify: &FixVerify{Port: "localhost:5432", Timeout: "a minute"}}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown fix severity",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:             "test",
+					Manifest:       Manifest{PrimaryFile: "pom.xml"},
+					Reconciliation: Reconciliation{Fixes: []Fix{{IssueType: "corrupt_db", Command: "make db-reset", Severity: "blocker"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "fix verify polling a port",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:             "test",
+					Manifest:       Manifest{PrimaryFile: "pom.xml"},
+					Reconciliation: Reconciliation{Fixes: []Fix{{IssueType: "db_down", Command: "docker compose up -d db", Verify: &FixVerify{Port: "localhost:5432", Timeout: "90s"}}}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "service logs from a unit",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:             "test",
+					Manifest:       Manifest{PrimaryFile: "pom.xml"},
+					Infrastructure: Infrastructure{Services: []Service{{Name: "postgres", CheckCommand: "pg_isready", Logs: &ServiceLogs{Unit: "postgresql", Patterns: []string{"FATAL"}}}}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "classified command check",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:           "test",
+					Manifest:     Manifest{PrimaryFile: "package.json"},
+					Verification: Verification{BuildFreshness: BuildFreshness{Commands: []VerificationCommand{{Name: "tsc", Type: "command", Command: "npx tsc --noEmit", Classify: []CommandOutcome{{Output: `error TS\d+`, IssueType: "compile_error"}}}}}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "command outcome without a match",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:           "test",
+					Manifest:     Manifest{PrimaryFile: "package.json"},
+					Verification: Verification{BuildFreshness: BuildFreshness{Commands: []VerificationCommand{{Name: "tsc", Type: "command", Command: "npx tsc --noEmit", Classify: []CommandOutcome{{IssueType: "compile_error"}}}}}},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing id",
 			config: &EcosystemConfig{
@@ -273,6 +632,50 @@ func TestValidateConfig(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "version args and command",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:            "test",
+					Manifest:      Manifest{PrimaryFile: "pom.xml"},
+					VersionConfig: VersionConfig{VersionCommand: "java -version 2>&1", VersionArgs: []string{"java", "-version"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "install location without a path",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:            "test",
+					Manifest:      Manifest{PrimaryFile: "pom.xml"},
+					VersionConfig: VersionConfig{InstallLocations: []InstallLocation{{Source: "sdkman"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "version args without a program",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:            "test",
+					Manifest:      Manifest{PrimaryFile: "pom.xml"},
+					VersionConfig: VersionConfig{VersionArgs: []string{"", "--version"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "package for an unknown installer",
+			config: &EcosystemConfig{
+				Ecosystem: Ecosystem{
+					ID:            "test",
+					Manifest:      Manifest{PrimaryFile: "pom.xml"},
+					VersionConfig: VersionConfig{Packages: map[string]string{"pacman": "jdk-openjdk"}},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing primary_file",
 			config: &EcosystemConfig{
@@ -1,8 +1,14 @@
 package config
 
+import "strings"
+
 // EcosystemConfig represents the complete ecosystem configuration
 type EcosystemConfig struct {
 	Ecosystem Ecosystem `yaml:"ecosystem"`
+
+	// Sources are the files the config was loaded from: the configs it extends, then its own
+	Sources []string `yaml:"-" json:"-"`
+	merged  map[string]interface{}
 }
 
 // Ecosystem defines an ecosystem (language/tool combination)
@@ -10,6 +16,7 @@ type Ecosystem struct {
 	Name    string `yaml:"name"`
 	ID      string `yaml:"id"`
 	Version string `yaml:"version"` // Config schema version
+	Extends string `yaml:"extends,omitempty"` // ID of a config this one is deep-merged over
 	
 	Detection      Detection      `yaml:"detection"`
 	Manifest       Manifest       `yaml:"manifest"`
@@ -20,8 +27,12 @@ type Ecosystem struct {
 	Environment    Environment    `yaml:"environment"`
 	Infrastructure Infrastructure `yaml:"infrastructure"`
 	Reconciliation Reconciliation `yaml:"reconciliation"`
+	Docs           []IssueDoc     `yaml:"docs,omitempty"` // Runbooks shown alongside issues of a type
+	FailureSignatures []FailureSignature `yaml:"failure_signatures,omitempty"` // Error messages in logs mapped to environment issues
 	VersionConfig  VersionConfig  `yaml:"version_config"` // Renamed to avoid conflict
 	Requirements   Requirements   `yaml:"requirements"`
+	Trust          Trust          `yaml:"trust"`
+	Locale         Locale         `yaml:"locale"`
 }
 
 // Detection defines how to detect this ecosystem
@@ -73,6 +84,7 @@ type BuildFreshness struct {
 	CacheTimestampCheck    bool              `yaml:"cache_timestamp_check"`
 	BuildOutputCheck       bool              `yaml:"build_output_check"`
 	Commands               []VerificationCommand `yaml:"commands"`
+	MaxParallel            int                   `yaml:"max_parallel,omitempty"` // Commands run at once; default: 4, 1 runs them in order
 }
 
 // DependencyAudit defines dependency audit checks
@@ -86,12 +98,57 @@ type VerificationCommand struct {
 	Name        string `yaml:"name"`
 	Type        string `yaml:"type"`
 	Source      string `yaml:"source,omitempty"`
+	SourcePattern string `yaml:"source_pattern,omitempty"` // Glob of sources; the newest match is compared
 	Target      string `yaml:"target,omitempty"`
 	TargetPattern string `yaml:"target_pattern,omitempty"`
+	TargetSelection string `yaml:"target_selection,omitempty"` // Which targets of target_pattern must be newer; default: newest
+	MinMatches  int    `yaml:"min_matches,omitempty"` // Fewest targets target_pattern must match; default: 1
+	SourceRoots []string `yaml:"source_roots,omitempty"` // orphan_check, partial_build_check: directories sources may be in; default: source
+	SourceExtensions []string `yaml:"source_extensions,omitempty"` // orphan_check, partial_build_check: extensions a source may have, e.g. ".java"
+	TargetExtension string `yaml:"target_extension,omitempty"` // partial_build_check: extension of a source's output, e.g. ".class"
+	MaxSpread   string `yaml:"max_spread,omitempty"` // partial_build_check: longest time outputs built together may span, e.g. "10m"
+	Modules     string `yaml:"modules,omitempty"` // module_check: how the build lists its modules: "maven" (<modules> of pom.xml), "nx" or "turbo"
+	Task        string `yaml:"task,omitempty"` // module_check: task whose runs nx or turbo record, e.g. "build" (default)
 	Command     string `yaml:"command,omitempty"`
 	Description string `yaml:"description"`
+	When        string `yaml:"when,omitempty"` // Condition under which the command applies
+	Suites      []string `yaml:"suites,omitempty"` // Suites the command runs in; default: all
+	Serial      bool   `yaml:"serial,omitempty"` // Run alone, after the concurrent commands, for commands sharing state
+	Classify    []CommandOutcome `yaml:"classify,omitempty"` // command: issues for exit codes and output; the command only runs with them
+	Timeout     string `yaml:"timeout,omitempty"` // command: longest the command may run; default: 2m
+}
+
+// CommandOutcome maps an exit code and/or output of a command check to an issue. The first
+// outcome matching the command's result decides its issue.
+type CommandOutcome struct {
+	ExitCode  *int   `yaml:"exit_code,omitempty"` // Exit code to match; any exit code when unset
+	Output    string `yaml:"output,omitempty"`    // Regex matched against the combined output
+	IssueType string `yaml:"issue_type"`
+	Severity  string `yaml:"severity,omitempty"` // default: error
+	Message   string `yaml:"message,omitempty"`  // default: the command's description
 }
 
+// Target selection strategies of timestamp_compare commands with a target_pattern
+const (
+	TargetNewest = "newest" // The most recently modified target must be newer than the source
+	TargetOldest = "oldest" // The least recently modified target must be newer than the source
+	TargetAny    = "any"    // At least one target must be newer than the source
+	TargetAll    = "all"    // Every target must be newer than the source; stale targets are counted
+)
+
+// TargetSelections lists the target selection strategies
+var TargetSelections = []string{TargetNewest, TargetOldest, TargetAny, TargetAll}
+
+// Module layouts of module_check commands
+const (
+	ModulesMaven = "maven" // The <modules> of pom.xml, recursively
+	ModulesNx    = "nx"    // The projects of the Nx project graph
+	ModulesTurbo = "turbo" // The packages of the package manager workspaces Turborepo runs
+)
+
+// ModuleLayouts lists the module layouts
+var ModuleLayouts = []string{ModulesMaven, ModulesNx, ModulesTurbo}
+
 // Environment defines environment variable handling
 type Environment struct {
 	VariablePatterns []string `yaml:"variable_patterns"`
@@ -101,7 +158,17 @@ type Environment struct {
 
 // Infrastructure defines infrastructure requirements
 type Infrastructure struct {
-	Services []Service `yaml:"services"`
+	Services    []Service    `yaml:"services"`
+	Daemons     []Daemon     `yaml:"daemons,omitempty"`
+	Limits      Limits       `yaml:"limits,omitempty"`
+	BuildCaches []BuildCache `yaml:"build_caches,omitempty"`
+}
+
+// Limits declares the OS limits an ecosystem's tools need; 0 means no requirement
+type Limits struct {
+	Watches          int `yaml:"watches,omitempty"`           // Linux fs.inotify.max_user_watches; file watchers need one per directory
+	WatcherInstances int `yaml:"watcher_instances,omitempty"` // Linux fs.inotify.max_user_instances
+	OpenFiles        int `yaml:"open_files,omitempty"`        // Soft open-file limit (ulimit -n)
 }
 
 // Service defines a service requirement
@@ -110,6 +177,108 @@ type Service struct {
 	Type           string `yaml:"type"`
 	CheckCommand   string `yaml:"check_command"`
 	VersionExtract string `yaml:"version_extract"`
+	When           string `yaml:"when,omitempty"` // Condition under which the service is required
+	// ExpectedVersion is the version the project needs; "17" accepts any 17.x
+	ExpectedVersion string `yaml:"expected_version,omitempty"`
+	MinVersion      string `yaml:"min_version,omitempty"` // Oldest version the project works with
+	Logs            *ServiceLogs `yaml:"logs,omitempty"` // Where to look for the cause when the service is unhealthy
+	// Packages is the package of a command service's program by installer, e.g. brew: "maven",
+	// to suggest how to install it when it's missing
+	Packages map[string]string `yaml:"packages,omitempty"`
+}
+
+// ServiceLogs defines where the recent logs of a service are read from when it is unhealthy,
+// and which of their lines are errors. Exactly one of container, unit and file is set.
+type ServiceLogs struct {
+	Container  string   `yaml:"container,omitempty"`   // Read with docker logs
+	Unit       string   `yaml:"unit,omitempty"`        // Read with journalctl
+	File       string   `yaml:"file,omitempty"`        // Log file; relative paths are below the project root
+	Lines      int      `yaml:"lines,omitempty"`       // Recent lines read (default 200)
+	Patterns   []string `yaml:"patterns,omitempty"`    // Regexes of error lines (default: error, fatal, panic, exception...)
+	MaxMatches int      `yaml:"max_matches,omitempty"` // Matched lines reported (default 5)
+}
+
+// Daemon defines a long-running dev process, such as the Gradle daemon, a bundler's watcher or
+// a dev server, that can hang or keep serving outdated code
+type Daemon struct {
+	Name            string          `yaml:"name"`
+	Process         string          `yaml:"process"`                    // Regex matching the daemon's command line
+	ProjectScoped   bool            `yaml:"project_scoped,omitempty"`   // Only processes whose command line contains the project root are the project's
+	Detached        bool            `yaml:"detached,omitempty"`         // Outlives the command that started it by design; otherwise a process whose parent exited is orphaned
+	VersionExtract  string          `yaml:"version_extract,omitempty"`  // Regex extracting the daemon's version from its command line
+	ExpectedVersion ExpectedVersion `yaml:"expected_version,omitempty"` // Where the project declares the version the daemon should have
+	StatusCommand   string          `yaml:"status_command,omitempty"`   // Command checking that the daemon responds
+	StatusPattern   string          `yaml:"status_pattern,omitempty"`   // Regex the status output must match
+	RestartOn       []string        `yaml:"restart_on,omitempty"`       // Files whose changes the daemon only picks up when restarted
+	MaxInstances    int             `yaml:"max_instances,omitempty"`    // More running instances are reported; 0 for no limit
+	RestartCommand  string          `yaml:"restart_command,omitempty"`
+	When            string          `yaml:"when,omitempty"` // Condition under which the daemon is checked
+}
+
+// BuildCache defines a build cache the ecosystem's tools can use, such as the Gradle build
+// cache, sccache or ccache: how to tell the project uses it, where its remote is configured,
+// which settings silently turn it off, and how to read its hit statistics
+type BuildCache struct {
+	Name         string         `yaml:"name"`
+	Configured   []CacheSetting `yaml:"configured,omitempty"`    // The cache is checked when any is found; always when none are given
+	Remote       []CacheSetting `yaml:"remote,omitempty"`        // Where the URL of a remote cache is set; the first found is used
+	Disabled     []CacheRule    `yaml:"disabled,omitempty"`      // Settings that turn the cache off without an error
+	StatsCommand string         `yaml:"stats_command,omitempty"` // Prints the cache's statistics; run in the project root
+	Hits         string         `yaml:"hits,omitempty"`          // Regex whose first group counts hits; the counts of all matches add up
+	Misses       string         `yaml:"misses,omitempty"`        // Regex whose first group counts misses, added up the same way
+	Errors       string         `yaml:"errors,omitempty"`        // Regex whose first group counts failed reads and writes of the cache
+	MinHitRate   float64        `yaml:"min_hit_rate,omitempty"`  // Lower hit rates, in percent, are reported; 0 to not check
+	MinRequests  int            `yaml:"min_requests,omitempty"`  // Hits and misses needed before the hit rate is checked (default 20)
+	When         string         `yaml:"when,omitempty"`          // Condition under which the cache is checked
+}
+
+// CacheSetting is a setting of a build cache, found in an environment variable or a project
+// file. Exactly one of env and file is set.
+type CacheSetting struct {
+	Env     string `yaml:"env,omitempty"`     // Variable holding the setting
+	File    string `yaml:"file,omitempty"`    // Project file or glob holding the setting
+	Pattern string `yaml:"pattern,omitempty"` // Regex the variable or file must match; for remotes its first group is the URL
+}
+
+// CacheRule is a setting that silently disables a build cache, reported with its fix
+type CacheRule struct {
+	CacheSetting `yaml:",inline"`
+	Absent       bool   `yaml:"absent,omitempty"` // The cache is off when the setting is not found, rather than when it is
+	Message      string `yaml:"message"`
+	Fix          string `yaml:"fix,omitempty"`
+}
+
+// ExpectedVersion reads a version from a project file, e.g. the Gradle wrapper properties
+type ExpectedVersion struct {
+	File    string `yaml:"file"`
+	Pattern string `yaml:"pattern"` // Regex whose first group is the version
+}
+
+// Trust defines where this ecosystem expects custom CA certificates to be installed
+type Trust struct {
+	Stores []TrustStore `yaml:"stores"`
+}
+
+// TrustStore defines a single trust configuration to verify (e.g., Java cacerts, NODE_EXTRA_CA_CERTS)
+type TrustStore struct {
+	Name         string `yaml:"name"`
+	Type         string `yaml:"type"`                    // "env_var" or "command"
+	EnvVar       string `yaml:"env_var,omitempty"`       // For env_var stores
+	CheckCommand string `yaml:"check_command,omitempty"` // For command stores
+	Match        string `yaml:"match,omitempty"`         // "fingerprint" or "path" (command stores)
+	FixCommand   string `yaml:"fix_command,omitempty"`   // Template: "... {ca_file}"
+	Description  string `yaml:"description"`
+	When         string `yaml:"when,omitempty"` // Condition under which the store is checked
+}
+
+// Locale defines optional time-zone, locale and clock sanity checks
+type Locale struct {
+	Enabled          bool     `yaml:"enabled"`
+	RequiredTimezone string   `yaml:"required_timezone,omitempty"` // IANA name, compared by UTC offset
+	RequiredLocales  []string `yaml:"required_locales,omitempty"`  // e.g. "en_US.UTF-8"
+	RequireUTF8      bool     `yaml:"require_utf8"`
+	MaxClockDrift    string   `yaml:"max_clock_drift,omitempty"` // Duration, e.g. "2s"; empty disables the NTP check
+	NTPServer        string   `yaml:"ntp_server,omitempty"`      // Default: pool.ntp.org:123
 }
 
 // Reconciliation defines auto-fix commands
@@ -119,20 +288,90 @@ type Reconciliation struct {
 
 // Fix defines a fix command
 type Fix struct {
-	IssueType     string `yaml:"issue_type"`
-	Command       string `yaml:"command"`
-	VerifyCommand string `yaml:"verify_command"`
-	Description   string `yaml:"description"`
+	IssueType     string          `yaml:"issue_type"`
+	Command       string          `yaml:"command"`
+	VerifyCommand string          `yaml:"verify_command"`
+	Description   string          `yaml:"description"`
+	When          string          `yaml:"when,omitempty"`     // Condition under which the fix applies
+	Heavy         bool            `yaml:"heavy,omitempty"`    // Full rebuilds, image builds and the like; run only with enough free memory, CPU and disk
+	Requires      FixRequirements `yaml:"requires,omitempty"` // Headroom the fix needs beyond the server's minimums
+	Verify        *FixVerify      `yaml:"verify,omitempty"`   // Waits for a started service to become healthy; replaces verify_command
+	Severity      string          `yaml:"severity,omitempty"` // Severity the fix policy treats the issue as: warning, error or critical (default: the issue's)
+}
+
+// ModulesPlaceholder in a fix command is replaced with the modules a module_check found stale,
+// as in "mvn -pl {modules} -am compile". The modules are comma separated, except for turbo,
+// which takes one per filter: "--filter={modules}" becomes "--filter=a --filter=b".
+const ModulesPlaceholder = "{modules}"
+
+// Targeted reports whether the fix command is filled in by the check reporting the issue, with
+// the modules to rebuild
+func (f *Fix) Targeted() bool {
+	return strings.Contains(f.Command, ModulesPlaceholder)
+}
+
+// FixVerify polls a health check after a fix until it passes or times out, so a service that
+// is still booting isn't reported as a failed fix. Exactly one of command, port and http is set.
+type FixVerify struct {
+	Command      string `yaml:"command,omitempty"`       // Passes when it exits 0
+	Port         string `yaml:"port,omitempty"`          // host:port that accepts TCP connections
+	HTTP         string `yaml:"http,omitempty"`          // URL answering with expect_status
+	ExpectStatus int    `yaml:"expect_status,omitempty"` // Default: any 2xx or 3xx
+	Timeout      string `yaml:"timeout,omitempty"`       // Longest wait, e.g. "90s" (default 60s)
+	Interval     string `yaml:"interval,omitempty"`      // First wait between checks, doubled after each (default 1s)
+	MaxInterval  string `yaml:"max_interval,omitempty"`  // Longest wait between checks (default 10s)
+}
+
+// IssueDoc links an issue type to the team's canonical runbook for it, such as a wiki page on
+// setting up the database. Reports show it alongside the automated fix.
+type IssueDoc struct {
+	IssueType string `yaml:"issue_type"`
+	Title     string `yaml:"title,omitempty"`
+	URL       string `yaml:"url,omitempty"`
+	Snippet   string `yaml:"snippet,omitempty"` // Markdown shown inline, for short instructions
+}
+
+// FailureSignature maps an error message that shows up in test, build and command output to
+// the environment issue behind it, such as a driver's message when its database isn't running
+type FailureSignature struct {
+	Name      string `yaml:"name"`
+	Pattern   string `yaml:"pattern"` // Regex matched against each output line
+	IssueType string `yaml:"issue_type"`
+	Message   string `yaml:"message"`         // May use the pattern's groups as {1} or {name}
+	Fix       string `yaml:"fix,omitempty"`   // May use the groups too; default: the ecosystem's fix for the issue type
+	Check     string `yaml:"check,omitempty"` // Tool that confirms the issue, e.g. env_var_audit
+	When      string `yaml:"when,omitempty"`  // Condition under which the signature applies
+}
+
+// FixRequirements is the free memory and disk a heavy fix needs
+type FixRequirements struct {
+	MemoryMB int `yaml:"memory_mb,omitempty"`
+	DiskMB   int `yaml:"disk_mb,omitempty"`
+}
+
+// IsHeavy reports whether a fix needs its headroom checked before it runs
+func (f Fix) IsHeavy() bool {
+	return f.Heavy || f.Requires != (FixRequirements{})
 }
 
 // VersionConfig defines version management configuration
 type VersionConfig struct {
 	Language          string   `yaml:"language"`
 	VersionCommand    string   `yaml:"version_command"`
+	VersionArgs       []string `yaml:"version_args,omitempty"` // Program and arguments run without a shell, instead of version_command
 	VersionPattern    string   `yaml:"version_pattern"`
 	RuntimePattern    string   `yaml:"runtime_pattern,omitempty"` // For Java and similar
 	VersionManagers   []VersionManager `yaml:"version_managers"`
 	RuntimeVariants   []RuntimeVariant `yaml:"runtime_variants,omitempty"` // For Java
+	InstallLocations  []InstallLocation `yaml:"install_locations,omitempty"`
+	Packages          map[string]string `yaml:"packages,omitempty"` // Package of the language by installer, e.g. brew: "openjdk@{version}"
+}
+
+// InstallLocation is where a version manager, package manager or installer puts copies of
+// the language's program
+type InstallLocation struct {
+	Source string `yaml:"source"` // e.g. "homebrew"; the name of a version manager switches with its switch_command
+	Path   string `yaml:"path"`   // Glob of the program, e.g. "$HOME/.sdkman/candidates/java/*/bin/java"
 }
 
 // VersionManager defines a version management tool
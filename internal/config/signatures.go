@@ -0,0 +1,45 @@
+package config
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Match reports whether a line of output shows the failure, with the signature's message and
+// fix filled in from the pattern's groups. Signatures with an invalid pattern, which loaded
+// configs never have, match nothing.
+func (s FailureSignature) Match(line string) (message, fix string, ok bool) {
+	pattern, err := regexp.Compile(s.Pattern)
+	if err != nil {
+		return "", "", false
+	}
+	groups := pattern.FindStringSubmatch(line)
+	if groups == nil {
+		return "", "", false
+	}
+
+	var replacements []string
+	for i, name := range pattern.SubexpNames() {
+		if i == 0 {
+			continue
+		}
+		replacements = append(replacements, "{"+strconv.Itoa(i)+"}", groups[i])
+		if name != "" {
+			replacements = append(replacements, "{"+name+"}", groups[i])
+		}
+	}
+	expand := strings.NewReplacer(replacements...)
+	return expand.Replace(s.Message), expand.Replace(s.Fix), true
+}
+
+// MatchFailureSignature returns the first signature a line of output shows, with its message
+// and fix filled in
+func MatchFailureSignature(signatures []FailureSignature, line string) (*FailureSignature, string, string) {
+	for i, sig := range signatures {
+		if message, fix, ok := sig.Match(line); ok {
+			return &signatures[i], message, fix
+		}
+	}
+	return nil, "", ""
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailureSignature_Match(t *testing.T) {
+	sig := FailureSignature{
+		Pattern: `FATAL: database "(?P<db>\w+)" does not exist on (\S+)`,
+		Message: "Database {db} is missing on {2}",
+		Fix:     "createdb {1}",
+	}
+
+	message, fix, ok := sig.Match(`psql: FATAL: database "orders" does not exist on localhost`)
+	require.True(t, ok)
+	assert.Equal(t, "Database orders is missing on localhost", message)
+	assert.Equal(t, "createdb orders", fix)
+
+	_, _, ok = sig.Match("FATAL: password authentication failed")
+	assert.False(t, ok)
+
+	_, _, ok = FailureSignature{Pattern: "("}.Match("(")
+	assert.False(t, ok, "an invalid pattern matches nothing")
+}
+
+func TestMatchFailureSignature(t *testing.T) {
+	signatures := []FailureSignature{
+		{Name: "first", Pattern: "refused", IssueType: "service_unavailable", Message: "refused"},
+		{Name: "second", Pattern: "refused|reset", IssueType: "network", Message: "network"},
+	}
+
+	sig, message, _ := MatchFailureSignature(signatures, "connection reset by peer")
+	require.NotNil(t, sig)
+	assert.Equal(t, "second", sig.Name)
+	assert.Equal(t, "network", message)
+
+	sig, _, _ = MatchFailureSignature(signatures, "connection refused")
+	require.NotNil(t, sig)
+	assert.Equal(t, "first", sig.Name, "the first matching signature wins")
+
+	sig, _, _ = MatchFailureSignature(signatures, "ok")
+	assert.Nil(t, sig)
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+	"gopkg.in/yaml.v3"
+)
+
+// Warning is a problem with a config file that doesn't stop other configs from loading
+type Warning struct {
+	File    string
+	Line    int // 0 when the problem has no position
+	Message string
+}
+
+func (w Warning) String() string {
+	if w.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", w.File, w.Line, w.Message)
+	}
+	return fmt.Sprintf("%s: %s", w.File, w.Message)
+}
+
+// unknownFieldPattern matches the errors yaml.v3 reports for keys not in the schema
+var unknownFieldPattern = regexp.MustCompile(`^line (\d+): field (\S+) not found in type \S+$`)
+
+// UnknownKeys reports the keys of a config file that are not part of the config schema,
+// such as a misspelled `requred_files`, with their line numbers. Such keys are otherwise
+// ignored when the config loads.
+func UnknownKeys(path string) ([]Warning, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &common.ErrNotFound{Resource: "config file", Path: path}
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var config EcosystemConfig
+	err = decoder.Decode(&config)
+	var typeErr *yaml.TypeError
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if !errors.As(err, &typeErr) {
+		return nil, &common.ErrInvalidConfig{Message: fmt.Sprintf("failed to parse YAML: %v", err)}
+	}
+
+	var warnings []Warning
+	for _, msg := range typeErr.Errors {
+		match := unknownFieldPattern.FindStringSubmatch(msg)
+		if match == nil {
+			continue
+		}
+		line, _ := strconv.Atoi(match[1])
+		warnings = append(warnings, Warning{File: path, Line: line, Message: fmt.Sprintf("unknown key %s", match[2])})
+	}
+	return warnings, nil
+}
+
+// LoadEcosystemConfigStrict loads a config like LoadEcosystemConfig, but fails when the file
+// has keys that are not part of the config schema
+func LoadEcosystemConfigStrict(path string) (*EcosystemConfig, error) {
+	config, err := LoadEcosystemConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	warnings, err := UnknownKeys(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(warnings) > 0 {
+		messages := make([]string, len(warnings))
+		for i, w := range warnings {
+			messages[i] = fmt.Sprintf("%s (line %d)", w.Message, w.Line)
+		}
+		return nil, &common.ErrInvalidConfig{Message: strings.Join(messages, ", ")}
+	}
+	return config, nil
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dev-env-sentinel/internal/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const typoConfig = `ecosystem:
+  id: "go"
+  manifest:
+    primary_file: "go.mod"
+    requred_files: ["go.sum"]
+  verification:
+    build_freshness:
+      commands:
+        - name: "check"
+          type: "timestamp_compare"
+          sourse: "go.mod"
+`
+
+func TestUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "go.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(typoConfig), 0644))
+
+	warnings, err := UnknownKeys(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Warning{
+		{File: path, Line: 5, Message: "unknown key requred_files"},
+		{File: path, Line: 11, Message: "unknown key sourse"},
+	}, warnings)
+	assert.Equal(t, path+":5: unknown key requred_files", warnings[0].String())
+}
+
+func TestUnknownKeys_Clean(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "go.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("ecosystem:\n  id: go\n  manifest:\n    primary_file: go.mod\n"), 0644))
+
+	warnings, err := UnknownKeys(path)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+}
+
+func TestLoadEcosystemConfigStrict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "go.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(typoConfig), 0644))
+
+	// The lenient loader ignores unknown keys
+	_, err := LoadEcosystemConfig(path)
+	require.NoError(t, err)
+
+	_, err = LoadEcosystemConfigStrict(path)
+	var cfgErr *common.ErrInvalidConfig
+	require.ErrorAs(t, err, &cfgErr)
+	assert.Equal(t, "unknown key requred_files (line 5), unknown key sourse (line 11)", cfgErr.Message)
+}
+
+func TestDiscoverEcosystemConfigs_Warnings(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.yaml"), []byte(typoConfig), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("ecosystem:\n  id: a\n  id: b\n"), 0644))
+
+	configs, warnings, err := DiscoverEcosystemConfigsWithWarnings(dir)
+	require.NoError(t, err)
+	assert.Len(t, configs, 1)
+	require.Len(t, warnings, 3)
+	assert.Contains(t, warnings[0].String(), "broken.yaml: skipped: invalid config")
+	assert.Equal(t, "unknown key requred_files", warnings[1].Message)
+
+	var out bytes.Buffer
+	WarningOutput = &out
+	defer func() { WarningOutput = os.Stderr }()
+	_, err = DiscoverEcosystemConfigs(dir)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "warning: config "+filepath.Join(dir, "go.yaml")+":5: unknown key requred_files")
+}
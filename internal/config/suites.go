@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Built-in check suites. A verification command lists the suites it runs in with `suites`;
+// commands without suites run in every suite, and the full suite runs every command.
+const (
+	SuiteQuick     = "quick"
+	SuiteFull      = "full"
+	SuitePreCommit = "pre-commit"
+)
+
+// InSuite reports whether a verification command runs in a suite. The empty suite is the full suite.
+func (c VerificationCommand) InSuite(suite string) bool {
+	if suite == "" || suite == SuiteFull || len(c.Suites) == 0 {
+		return true
+	}
+	for _, s := range c.Suites {
+		if s == suite {
+			return true
+		}
+	}
+	return false
+}
+
+// ForSuite returns the config with only the verification commands that run in a suite
+func (c *EcosystemConfig) ForSuite(suite string) *EcosystemConfig {
+	if suite == "" || suite == SuiteFull {
+		return c
+	}
+
+	selected := *c
+	eco := &selected.Ecosystem
+	eco.Verification.BuildFreshness.Commands = commandsInSuite(eco.Verification.BuildFreshness.Commands, suite)
+	eco.Verification.DependencyAudit.Commands = commandsInSuite(eco.Verification.DependencyAudit.Commands, suite)
+	return &selected
+}
+
+// commandsInSuite returns the verification commands that run in a suite
+func commandsInSuite(commands []VerificationCommand, suite string) []VerificationCommand {
+	var selected []VerificationCommand
+	for _, cmd := range commands {
+		if cmd.InSuite(suite) {
+			selected = append(selected, cmd)
+		}
+	}
+	return selected
+}
+
+// Suites returns the built-in suites and the suites named by the configs' verification commands, sorted
+func Suites(configs []*EcosystemConfig) []string {
+	seen := map[string]bool{SuiteQuick: true, SuiteFull: true, SuitePreCommit: true}
+	for _, cfg := range configs {
+		verification := cfg.Ecosystem.Verification
+		for _, commands := range [][]VerificationCommand{verification.BuildFreshness.Commands, verification.DependencyAudit.Commands} {
+			for _, cmd := range commands {
+				for _, suite := range cmd.Suites {
+					seen[suite] = true
+				}
+			}
+		}
+	}
+
+	suites := make([]string, 0, len(seen))
+	for suite := range seen {
+		suites = append(suites, suite)
+	}
+	sort.Strings(suites)
+	return suites
+}
+
+// ValidateSuite checks that a suite is built in or named by one of the configs
+func ValidateSuite(configs []*EcosystemConfig, suite string) error {
+	suites := Suites(configs)
+	for _, s := range suites {
+		if s == suite {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown suite %s (available: %s)", suite, strings.Join(suites, ", "))
+}
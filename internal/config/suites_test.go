@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerificationCommand_InSuite(t *testing.T) {
+	untagged := VerificationCommand{Name: "pom_vs_classes"}
+	slow := VerificationCommand{Name: "dependency_tree", Suites: []string{SuiteFull, SuitePreCommit}}
+
+	for _, suite := range []string{"", SuiteQuick, SuiteFull, SuitePreCommit} {
+		assert.True(t, untagged.InSuite(suite), "untagged commands run in %q", suite)
+	}
+	assert.False(t, slow.InSuite(SuiteQuick))
+	assert.True(t, slow.InSuite(SuitePreCommit))
+	assert.True(t, slow.InSuite(""), "the full suite runs every command")
+}
+
+func TestSuites(t *testing.T) {
+	configs := []*EcosystemConfig{{Ecosystem: Ecosystem{Verification: Verification{
+		BuildFreshness:  BuildFreshness{Commands: []VerificationCommand{{Suites: []string{"release"}}}},
+		DependencyAudit: DependencyAudit{Commands: []VerificationCommand{{Suites: []string{"nightly", SuiteFull}}}},
+	}}}}
+
+	assert.Equal(t, []string{"full", "nightly", "pre-commit", "quick", "release"}, Suites(configs))
+	assert.NoError(t, ValidateSuite(configs, "release"))
+	assert.NoError(t, ValidateSuite(nil, SuiteQuick))
+	assert.EqualError(t, ValidateSuite(nil, "release"), "unknown suite release (available: full, pre-commit, quick)")
+
+	quick := configs[0].ForSuite(SuiteQuick)
+	assert.Empty(t, quick.Ecosystem.Verification.BuildFreshness.Commands)
+	assert.Empty(t, quick.Ecosystem.Verification.DependencyAudit.Commands)
+	assert.Same(t, configs[0], configs[0].ForSuite(""))
+}
@@ -0,0 +1,536 @@
+// Package container checks the container runtime itself: whether Docker or Podman is
+// installed, its daemon is reachable through the current context, how much disk its images,
+// volumes and build cache take, and whether the client and daemon agree on rootless mode.
+// On macOS and Windows it also checks the VM of Docker Desktop, Colima, Rancher Desktop,
+// OrbStack or Podman: that it runs, has the CPUs and memory the project's compose file
+// reserves, and shares the project directory. Every compose-based stack breaks when one of
+// these is wrong.
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"dev-env-sentinel/internal/runner"
+)
+
+// Issue types reported by the container runtime check
+const (
+	IssueMissing          = "container_runtime_missing"
+	IssueUnreachable      = "container_daemon_unreachable"
+	IssuePermission       = "container_socket_permission"
+	IssueRootlessMismatch = "container_rootless_mismatch"
+	IssueDiskUsage        = "container_disk_usage"
+)
+
+// DefaultMaxDiskGB is the disk usage of images, containers, volumes and build cache above
+// which the runtime is reported as using too much disk
+const DefaultMaxDiskGB = 50
+
+// Runtime is an installed container runtime CLI and the daemon it talks to
+type Runtime struct {
+	Name          string // "docker" or "podman"
+	ClientVersion string
+	ServerVersion string // Empty when the daemon isn't reachable
+	Reachable     bool
+	Context       string // Docker context in use
+	Endpoint      string // Socket or URL of the daemon the client talks to
+	Rootless      bool   // The daemon runs rootless
+	Backend       string // What runs the daemon, e.g. BackendColima
+	VM            *VM    // The backend's VM, nil for native daemons or when unreachable
+	Error         string // Why the daemon isn't reachable
+	DiskUsage     []DiskUsage
+}
+
+// DiskUsage is the disk used by one kind of runtime data, as reported by system df
+type DiskUsage struct {
+	Type        string // "Images", "Containers", "Local Volumes" or "Build Cache"
+	Count       int
+	Size        int64 // Bytes
+	Reclaimable int64 // Bytes a prune would free
+}
+
+// Issue is a container runtime problem with an optional fix
+type Issue struct {
+	Type       string
+	Severity   string // "error" or "warning"
+	Message    string
+	FixCommand string
+}
+
+// RuntimeReport contains the container runtime check results
+type RuntimeReport struct {
+	ProjectRoot string
+	Runtimes    []Runtime
+	Required    bool       // The project has a Dockerfile, Containerfile or compose file
+	Needs       *Resources // CPUs and memory the project needs, nil when undeclared
+	MaxDiskGB   float64
+	IsHealthy   bool
+	Issues      []Issue
+}
+
+// Options configure CheckRuntime
+type Options struct {
+	MaxDiskGB   float64 // Default: DefaultMaxDiskGB
+	MinCPUs     float64 // CPUs the project needs; overrides its compose file
+	MinMemoryGB float64 // Memory the project needs; overrides its compose file
+}
+
+// projectFiles mark a project that needs a container runtime
+var projectFiles = []string{"Dockerfile*", "Containerfile*", "*compose.y*ml", ".devcontainer"}
+
+// CheckRuntime checks the Docker and Podman installations of the machine for a project. A
+// missing or stopped runtime is only an issue for projects that use containers.
+func CheckRuntime(ctx context.Context, projectRoot string, opts Options) (*RuntimeReport, error) {
+	if opts.MaxDiskGB <= 0 {
+		opts.MaxDiskGB = DefaultMaxDiskGB
+	}
+	report := &RuntimeReport{
+		ProjectRoot: projectRoot,
+		Runtimes:    []Runtime{},
+		Required:    usesContainers(projectRoot),
+		MaxDiskGB:   opts.MaxDiskGB,
+		IsHealthy:   true,
+		Issues:      []Issue{},
+	}
+	if abs, err := filepath.Abs(projectRoot); err == nil {
+		report.ProjectRoot = abs
+	}
+
+	needs, ok, err := ComposeNeeds(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MinCPUs > 0 || opts.MinMemoryGB > 0 {
+		needs = Resources{CPUs: opts.MinCPUs, MemoryBytes: int64(opts.MinMemoryGB * (1 << 30)), Source: "the requested minimum"}
+		ok = true
+	}
+	if ok {
+		report.Needs = &needs
+	}
+
+	home, _ := os.UserHomeDir()
+	for _, probe := range []func(context.Context, string, string) *Runtime{probeDocker, probePodman} {
+		if rt := probe(ctx, runtime.GOOS, home); rt != nil {
+			report.Runtimes = append(report.Runtimes, *rt)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report.evaluate(runtime.GOOS, rootlessDockerSocket())
+	return report, nil
+}
+
+// usesContainers reports whether a project has files that need a container runtime
+func usesContainers(projectRoot string) bool {
+	for _, pattern := range projectFiles {
+		if matches, _ := filepath.Glob(filepath.Join(projectRoot, pattern)); len(matches) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluate turns the probed runtimes into issues. rootlessSocket is the socket of a rootless
+// Docker daemon of the user, if one exists.
+func (r *RuntimeReport) evaluate(goos, rootlessSocket string) {
+	if len(r.Runtimes) == 0 {
+		if r.Required {
+			r.add(Issue{Type: IssueMissing, Severity: "error", Message: "Neither docker nor podman is installed, but the project uses containers", FixCommand: installHint(goos)})
+		}
+		return
+	}
+
+	reachable := false
+	for _, rt := range r.Runtimes {
+		if rt.Reachable {
+			reachable = true
+		}
+	}
+
+	for _, rt := range r.Runtimes {
+		if !rt.Reachable {
+			// A stopped runtime is fine when another one serves the project
+			if reachable || !r.Required {
+				continue
+			}
+			if rt.Backend != "" && rt.Backend != BackendNative {
+				r.add(Issue{
+					Type:       IssueVMStopped,
+					Severity:   "error",
+					Message:    fmt.Sprintf("The %s VM running the %s daemon is not running%s: %s", rt.Backend, rt.Name, describeEndpoint(rt), rt.Error),
+					FixCommand: vmStartHint(rt.Backend),
+				})
+				continue
+			}
+			if isPermissionError(rt.Error) {
+				r.add(Issue{
+					Type:       IssuePermission,
+					Severity:   "error",
+					Message:    fmt.Sprintf("Your user may not access the %s daemon socket %s: %s", rt.Name, rt.Endpoint, rt.Error),
+					FixCommand: permissionHint(rt),
+				})
+				continue
+			}
+			r.add(Issue{
+				Type:       IssueUnreachable,
+				Severity:   "error",
+				Message:    fmt.Sprintf("The %s daemon is not reachable%s: %s", rt.Name, describeEndpoint(rt), rt.Error),
+				FixCommand: startHint(rt, goos),
+			})
+			continue
+		}
+
+		if issue, ok := rootlessMismatch(rt, rootlessSocket); ok {
+			r.add(issue)
+		}
+		if issue, ok := diskIssue(rt, r.MaxDiskGB); ok {
+			r.add(issue)
+		}
+		needs := Resources{}
+		if r.Needs != nil {
+			needs = *r.Needs
+		}
+		for _, issue := range vmIssues(rt, needs, r.ProjectRoot) {
+			r.add(issue)
+		}
+	}
+}
+
+func (r *RuntimeReport) add(issue Issue) {
+	r.IsHealthy = false
+	r.Issues = append(r.Issues, issue)
+}
+
+// rootlessMismatch reports a client and daemon that disagree on rootless mode: a client
+// pointed at a rootless socket served by a rootful daemon, or a rootful client while the user
+// runs a rootless daemon whose containers it can't see
+func rootlessMismatch(rt Runtime, rootlessSocket string) (Issue, bool) {
+	if rt.Name != "docker" {
+		return Issue{}, false
+	}
+	rootlessEndpoint := isRootlessEndpoint(rt.Endpoint)
+	switch {
+	case rootlessEndpoint && !rt.Rootless:
+		return Issue{
+			Type:     IssueRootlessMismatch,
+			Severity: "warning",
+			Message:  fmt.Sprintf("The docker client uses the rootless socket %s, but the daemon behind it runs as root", rt.Endpoint),
+		}, true
+	case !rootlessEndpoint && !rt.Rootless && rootlessSocket != "":
+		return Issue{
+			Type:       IssueRootlessMismatch,
+			Severity:   "warning",
+			Message:    fmt.Sprintf("A rootless docker daemon listens on %s, but the client%s talks to the rootful daemon; containers and images of one aren't visible to the other", rootlessSocket, describeEndpoint(rt)),
+			FixCommand: "docker context use rootless",
+		}, true
+	}
+	return Issue{}, false
+}
+
+// diskIssue reports a runtime whose data uses more disk than the threshold
+func diskIssue(rt Runtime, maxGB float64) (Issue, bool) {
+	var total, reclaimable int64
+	for _, usage := range rt.DiskUsage {
+		total += usage.Size
+		reclaimable += usage.Reclaimable
+	}
+	if float64(total) <= maxGB*gigabyte {
+		return Issue{}, false
+	}
+	return Issue{
+		Type:       IssueDiskUsage,
+		Severity:   "warning",
+		Message:    fmt.Sprintf("%s images, containers, volumes and build cache use %s (limit %s), %s of it reclaimable", rt.Name, FormatBytes(total), FormatBytes(int64(maxGB*gigabyte)), FormatBytes(reclaimable)),
+		FixCommand: rt.Name + " system prune",
+	}, true
+}
+
+// probeDocker inspects the docker CLI and its daemon, or returns nil if docker isn't installed
+func probeDocker(ctx context.Context, goos, home string) *Runtime {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return nil
+	}
+	rt := &Runtime{Name: "docker"}
+	rt.ClientVersion = output(ctx, `docker version --format '{{.Client.Version}}'`)
+	rt.Context = output(ctx, "docker context show")
+	rt.Endpoint = os.Getenv("DOCKER_HOST")
+	if rt.Endpoint == "" {
+		rt.Endpoint = output(ctx, `docker context inspect --format '{{.Endpoints.docker.Host}}'`)
+	}
+
+	data, err := runner.Output(ctx, "", `docker info --format '{{json .}}'`)
+	info, ok := parseDockerInfo(data)
+	rt.Backend = detectBackend(rt.Name, goos, rt.Context, rt.Endpoint, info.OperatingSystem, info.Name)
+	if !ok || len(info.ServerErrors) > 0 {
+		rt.Error = commandError(err, info.ServerErrors)
+		return rt
+	}
+	rt.Reachable = true
+	rt.ServerVersion = info.ServerVersion
+	rt.Rootless = info.rootless()
+	if rt.Backend != BackendNative {
+		rt.VM = &VM{CPUs: info.NCPU, MemoryBytes: info.MemTotal, Shares: vmShares(rt.Backend, goos, home)}
+	}
+
+	if data, err := runner.Output(ctx, "", `docker system df --format '{{json .}}'`); err == nil {
+		rt.DiskUsage = ParseDiskUsage(string(data))
+	}
+	return rt
+}
+
+// probePodman inspects the podman CLI and its service, or returns nil if podman isn't installed
+func probePodman(ctx context.Context, goos, home string) *Runtime {
+	if _, err := exec.LookPath("podman"); err != nil {
+		return nil
+	}
+	rt := &Runtime{Name: "podman", Backend: detectBackend("podman", goos, "", "", "", "")}
+	rt.ClientVersion = output(ctx, `podman version --format '{{.Client.Version}}'`)
+
+	data, err := runner.Output(ctx, "", "podman info --format json")
+	var info podmanInfo
+	if err != nil || json.Unmarshal(data, &info) != nil {
+		rt.Error = commandError(err, nil)
+		return rt
+	}
+	rt.Reachable = true
+	rt.ServerVersion = info.Version.Version
+	rt.Rootless = info.Host.Security.Rootless
+	rt.Endpoint = info.Host.RemoteSocket.Path
+	if rt.Backend != BackendNative {
+		rt.VM = &VM{CPUs: info.Host.CPUs, MemoryBytes: info.Host.MemTotal, Shares: vmShares(rt.Backend, goos, home)}
+	}
+
+	if data, err := runner.Output(ctx, "", `podman system df --format '{{json .}}'`); err == nil {
+		rt.DiskUsage = ParseDiskUsage(string(data))
+	}
+	return rt
+}
+
+// dockerInfo is the part of docker info the check uses
+type dockerInfo struct {
+	ServerVersion   string
+	SecurityOptions []string
+	ServerErrors    []string
+	OperatingSystem string // "Docker Desktop" for Docker Desktop's VM
+	Name            string // Host name of the daemon, e.g. "colima"
+	NCPU            int
+	MemTotal        int64
+}
+
+func (i dockerInfo) rootless() bool {
+	for _, option := range i.SecurityOptions {
+		if strings.Contains(option, "name=rootless") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDockerInfo parses docker info JSON. Recent clients print it with ServerErrors even
+// when the daemon is down.
+func parseDockerInfo(data []byte) (dockerInfo, bool) {
+	var info dockerInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return dockerInfo{}, false
+	}
+	return info, true
+}
+
+// podmanInfo is the part of podman info the check uses
+type podmanInfo struct {
+	Host struct {
+		CPUs     int   `json:"cpus"`
+		MemTotal int64 `json:"memTotal"`
+		Security struct {
+			Rootless bool `json:"rootless"`
+		} `json:"security"`
+		RemoteSocket struct {
+			Path string `json:"path"`
+		} `json:"remoteSocket"`
+	} `json:"host"`
+	Version struct {
+		Version string `json:"Version"`
+	} `json:"version"`
+}
+
+// ParseDiskUsage parses the JSON lines of docker or podman system df. Docker counts with
+// TotalCount and podman with Total; reclaimable sizes may carry a percentage.
+func ParseDiskUsage(output string) []DiskUsage {
+	var usages []DiskUsage
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var row map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			continue
+		}
+		usage := DiskUsage{Type: fmt.Sprint(row["Type"])}
+		for _, key := range []string{"TotalCount", "Total"} {
+			if count, ok := toInt(row[key]); ok {
+				usage.Count = count
+				break
+			}
+		}
+		usage.Size, _ = ParseSize(fmt.Sprint(row["Size"]))
+		usage.Reclaimable, _ = ParseSize(fmt.Sprint(row["Reclaimable"]))
+		usages = append(usages, usage)
+	}
+	return usages
+}
+
+func toInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case float64:
+		return int(n), true
+	case string:
+		i, err := strconv.Atoi(n)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+const gigabyte = 1e9
+
+// sizeUnits are the decimal units docker and podman print sizes in
+var sizeUnits = []struct {
+	suffix string
+	factor float64
+}{
+	{"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"kB", 1e3}, {"KB", 1e3}, {"B", 1},
+}
+
+// ParseSize parses a size such as "1.23GB" or "512MB (40%)" into bytes
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, " ("); i >= 0 {
+		s = s[:i]
+	}
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(s, unit.suffix) {
+			value, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, unit.suffix)), 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid size %q", s)
+			}
+			return int64(value * unit.factor), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid size %q", s)
+}
+
+// FormatBytes formats a size in decimal units, like docker does
+func FormatBytes(n int64) string {
+	for _, unit := range sizeUnits[:4] {
+		if float64(n) >= unit.factor {
+			return strconv.FormatFloat(float64(n)/unit.factor, 'f', 1, 64) + " " + unit.suffix
+		}
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// output runs a probe command and returns its trimmed stdout, or "" if it fails
+func output(ctx context.Context, command string) string {
+	data, err := runner.Output(ctx, "", command)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// commandError describes why a daemon isn't reachable: the daemon's own errors, or the
+// command's stderr
+func commandError(err error, serverErrors []string) string {
+	if len(serverErrors) > 0 {
+		return strings.Join(serverErrors, "; ")
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if stderr := strings.TrimSpace(string(exitErr.Stderr)); stderr != "" {
+			return strings.Split(stderr, "\n")[0]
+		}
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return "unexpected output"
+}
+
+func isPermissionError(msg string) bool {
+	return strings.Contains(strings.ToLower(msg), "permission denied")
+}
+
+// isRootlessEndpoint reports whether a daemon endpoint is a per-user socket
+func isRootlessEndpoint(endpoint string) bool {
+	return strings.Contains(endpoint, "/run/user/") || strings.Contains(endpoint, "rootless")
+}
+
+// rootlessDockerSocket returns the socket of the user's rootless Docker daemon, if it exists
+func rootlessDockerSocket() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		return ""
+	}
+	socket := filepath.Join(dir, "docker.sock")
+	if _, err := os.Stat(socket); err != nil {
+		return ""
+	}
+	return socket
+}
+
+func describeEndpoint(rt Runtime) string {
+	switch {
+	case rt.Context != "" && rt.Endpoint != "":
+		return fmt.Sprintf(" (context %s, %s)", rt.Context, rt.Endpoint)
+	case rt.Endpoint != "":
+		return fmt.Sprintf(" (%s)", rt.Endpoint)
+	case rt.Context != "":
+		return fmt.Sprintf(" (context %s)", rt.Context)
+	}
+	return ""
+}
+
+// startHint is the command that starts a runtime's daemon
+func startHint(rt Runtime, goos string) string {
+	switch {
+	case rt.Name == "podman" && goos != "linux":
+		return "podman machine start"
+	case rt.Name == "podman":
+		return "systemctl --user start podman.socket"
+	case goos == "darwin" || goos == "windows":
+		return "Start Docker Desktop"
+	case isRootlessEndpoint(rt.Endpoint):
+		return "systemctl --user start docker"
+	default:
+		return "sudo systemctl start docker"
+	}
+}
+
+// permissionHint is how a user gets access to a rootful daemon's socket
+func permissionHint(rt Runtime) string {
+	if rt.Name == "podman" {
+		return "Run podman as your user (rootless), or start the service with systemctl --user start podman.socket"
+	}
+	return "sudo usermod -aG docker $USER, then log in again (or use rootless docker: dockerd-rootless-setuptool.sh install)"
+}
+
+// installHint is how to install a container runtime on a platform
+func installHint(goos string) string {
+	switch goos {
+	case "darwin", "windows":
+		return "Install Docker Desktop, Podman Desktop or (macOS) Colima"
+	default:
+		return "Install Docker Engine (https://docs.docker.com/engine/install/) or podman from your distribution"
+	}
+}
@@ -0,0 +1,142 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"1.5GB", 1500000000},
+		{"512MB (40%)", 512000000},
+		{"12.3kB", 12300},
+		{"0B", 0},
+		{"2TB", 2000000000000},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	_, err := ParseSize("lots")
+	assert.Error(t, err)
+}
+
+func TestParseDiskUsage(t *testing.T) {
+	docker := `{"Active":"2","Reclaimable":"3.1GB (62%)","Size":"5GB","TotalCount":"14","Type":"Images"}
+{"Active":"0","Reclaimable":"0B","Size":"120MB","TotalCount":"3","Type":"Local Volumes"}
+`
+	assert.Equal(t, []DiskUsage{
+		{Type: "Images", Count: 14, Size: 5000000000, Reclaimable: 3100000000},
+		{Type: "Local Volumes", Count: 3, Size: 120000000},
+	}, ParseDiskUsage(docker))
+
+	podman := `{"Type":"Images","Total":7,"Active":1,"Size":"2GB","Reclaimable":"1GB (50%)"}`
+	assert.Equal(t, []DiskUsage{{Type: "Images", Count: 7, Size: 2000000000, Reclaimable: 1000000000}}, ParseDiskUsage(podman))
+}
+
+func TestParseDockerInfo(t *testing.T) {
+	info, ok := parseDockerInfo([]byte(`{"ServerVersion":"27.1.1","SecurityOptions":["name=seccomp,profile=builtin","name=rootless"]}`))
+	require.True(t, ok)
+	assert.Equal(t, "27.1.1", info.ServerVersion)
+	assert.True(t, info.rootless())
+
+	info, ok = parseDockerInfo([]byte(`{"ServerErrors":["Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?"]}`))
+	require.True(t, ok)
+	assert.Len(t, info.ServerErrors, 1)
+	assert.False(t, info.rootless())
+}
+
+func TestEvaluate_NoRuntime(t *testing.T) {
+	report := &RuntimeReport{Required: true, IsHealthy: true}
+	report.evaluate("linux", "")
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, IssueMissing, report.Issues[0].Type)
+	assert.False(t, report.IsHealthy)
+
+	report = &RuntimeReport{IsHealthy: true}
+	report.evaluate("linux", "")
+	assert.True(t, report.IsHealthy, "projects without containers don't need a runtime")
+}
+
+func TestEvaluate_Unreachable(t *testing.T) {
+	down := Runtime{Name: "docker", Context: "default", Endpoint: "unix:///var/run/docker.sock", Error: "Cannot connect to the Docker daemon"}
+
+	report := &RuntimeReport{Required: true, IsHealthy: true, Runtimes: []Runtime{down}}
+	report.evaluate("linux", "")
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, IssueUnreachable, report.Issues[0].Type)
+	assert.Equal(t, "sudo systemctl start docker", report.Issues[0].FixCommand)
+
+	report = &RuntimeReport{Required: true, IsHealthy: true, Runtimes: []Runtime{down}}
+	report.evaluate("darwin", "")
+	assert.Equal(t, "Start Docker Desktop", report.Issues[0].FixCommand)
+
+	denied := down
+	denied.Error = "permission denied while trying to connect to the Docker daemon socket"
+	report = &RuntimeReport{Required: true, IsHealthy: true, Runtimes: []Runtime{denied}}
+	report.evaluate("linux", "")
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, IssuePermission, report.Issues[0].Type)
+	assert.Contains(t, report.Issues[0].FixCommand, "usermod -aG docker")
+
+	podman := Runtime{Name: "podman", Reachable: true, Rootless: true}
+	report = &RuntimeReport{Required: true, IsHealthy: true, MaxDiskGB: DefaultMaxDiskGB, Runtimes: []Runtime{down, podman}}
+	report.evaluate("linux", "")
+	assert.True(t, report.IsHealthy, "a reachable podman serves the project")
+}
+
+func TestEvaluate_RootlessMismatch(t *testing.T) {
+	rootful := Runtime{Name: "docker", Reachable: true, Endpoint: "unix:///var/run/docker.sock"}
+	report := &RuntimeReport{IsHealthy: true, MaxDiskGB: DefaultMaxDiskGB, Runtimes: []Runtime{rootful}}
+	report.evaluate("linux", "/run/user/1000/docker.sock")
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, IssueRootlessMismatch, report.Issues[0].Type)
+	assert.Equal(t, "docker context use rootless", report.Issues[0].FixCommand)
+
+	wrongDaemon := Runtime{Name: "docker", Reachable: true, Endpoint: "unix:///run/user/1000/docker.sock"}
+	report = &RuntimeReport{IsHealthy: true, MaxDiskGB: DefaultMaxDiskGB, Runtimes: []Runtime{wrongDaemon}}
+	report.evaluate("linux", "")
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, IssueRootlessMismatch, report.Issues[0].Type)
+
+	rootless := Runtime{Name: "docker", Reachable: true, Rootless: true, Endpoint: "unix:///run/user/1000/docker.sock"}
+	report = &RuntimeReport{IsHealthy: true, MaxDiskGB: DefaultMaxDiskGB, Runtimes: []Runtime{rootless}}
+	report.evaluate("linux", "/run/user/1000/docker.sock")
+	assert.True(t, report.IsHealthy)
+}
+
+func TestEvaluate_DiskUsage(t *testing.T) {
+	rt := Runtime{Name: "docker", Reachable: true, DiskUsage: []DiskUsage{
+		{Type: "Images", Size: 40e9, Reclaimable: 25e9},
+		{Type: "Build Cache", Size: 15e9, Reclaimable: 15e9},
+	}}
+
+	report := &RuntimeReport{IsHealthy: true, MaxDiskGB: 50, Runtimes: []Runtime{rt}}
+	report.evaluate("linux", "")
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, IssueDiskUsage, report.Issues[0].Type)
+	assert.Equal(t, "warning", report.Issues[0].Severity)
+	assert.Equal(t, "docker system prune", report.Issues[0].FixCommand)
+	assert.Contains(t, report.Issues[0].Message, "55.0 GB")
+
+	report = &RuntimeReport{IsHealthy: true, MaxDiskGB: 100, Runtimes: []Runtime{rt}}
+	report.evaluate("linux", "")
+	assert.True(t, report.IsHealthy)
+}
+
+func TestUsesContainers(t *testing.T) {
+	dir := t.TempDir()
+	assert.False(t, usesContainers(dir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte("services: {}\n"), 0644))
+	assert.True(t, usesContainers(dir))
+}
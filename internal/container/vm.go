@@ -0,0 +1,401 @@
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+	"gopkg.in/yaml.v3"
+)
+
+// Issue types of the VM that runs the daemon on macOS and Windows
+const (
+	IssueVMStopped     = "container_vm_stopped"
+	IssueVMResources   = "container_vm_resources"
+	IssueVMFileSharing = "container_vm_file_sharing"
+)
+
+// Backends that run the container daemon
+const (
+	BackendNative         = "native" // Daemon on the host, as on Linux
+	BackendDockerDesktop  = "docker-desktop"
+	BackendColima         = "colima"
+	BackendRancherDesktop = "rancher-desktop"
+	BackendOrbStack       = "orbstack"
+	BackendPodmanMachine  = "podman-machine"
+)
+
+// VM is the virtual machine a backend runs the daemon in
+type VM struct {
+	CPUs        int   // CPUs allocated to the VM, 0 when unknown
+	MemoryBytes int64 // Memory allocated to the VM, 0 when unknown
+	Shares      []Share
+}
+
+// Share is a host directory the VM mounts, so containers can bind-mount paths below it
+type Share struct {
+	Path     string
+	Writable bool
+}
+
+// Resources are the CPUs and memory a project needs from the runtime
+type Resources struct {
+	CPUs        float64
+	MemoryBytes int64
+	Source      string // Where the needs are declared, e.g. "docker-compose.yml" or "max_memory_gb"
+}
+
+// composeFiles are the compose files resource needs are read from, in lookup order
+var composeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// composeFile is the part of a compose file that declares resources and published ports
+type composeFile struct {
+	Services map[string]struct {
+		CPUs           interface{} `yaml:"cpus"`
+		MemLimit       interface{} `yaml:"mem_limit"`
+		MemReservation interface{} `yaml:"mem_reservation"`
+		Deploy         struct {
+			Resources struct {
+				Limits       composeResources `yaml:"limits"`
+				Reservations composeResources `yaml:"reservations"`
+			} `yaml:"resources"`
+		} `yaml:"deploy"`
+		Ports []interface{} `yaml:"ports"`
+	} `yaml:"services"`
+}
+
+type composeResources struct {
+	CPUs   interface{} `yaml:"cpus"`
+	Memory interface{} `yaml:"memory"`
+}
+
+// ComposeNeeds sums the CPUs and memory the services of a project's compose file reserve, or
+// limit themselves to when they reserve nothing. ok is false without a compose file declaring
+// resources.
+func ComposeNeeds(projectRoot string) (Resources, bool, error) {
+	for _, name := range composeFiles {
+		data, err := common.ReadFile(filepath.Join(projectRoot, name))
+		if err != nil {
+			continue
+		}
+		var compose composeFile
+		if err := yaml.Unmarshal(data, &compose); err != nil {
+			return Resources{}, false, fmt.Errorf("invalid %s: %w", name, err)
+		}
+
+		needs := Resources{Source: name}
+		for _, svc := range compose.Services {
+			reserved, limits := svc.Deploy.Resources.Reservations, svc.Deploy.Resources.Limits
+			needs.CPUs += parseCPUs(first(reserved.CPUs, limits.CPUs, svc.CPUs))
+			needs.MemoryBytes += parseMemory(first(reserved.Memory, svc.MemReservation, limits.Memory, svc.MemLimit))
+		}
+		return needs, needs.CPUs > 0 || needs.MemoryBytes > 0, nil
+	}
+	return Resources{}, false, nil
+}
+
+// ComposePorts returns the host ports the services of a project's compose file publish, sorted.
+// Port ranges are skipped.
+func ComposePorts(projectRoot string) ([]int, error) {
+	for _, name := range composeFiles {
+		data, err := common.ReadFile(filepath.Join(projectRoot, name))
+		if err != nil {
+			continue
+		}
+		var compose composeFile
+		if err := yaml.Unmarshal(data, &compose); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+
+		seen := make(map[int]bool)
+		var ports []int
+		for _, svc := range compose.Services {
+			for _, port := range svc.Ports {
+				if n := publishedPort(port); n > 0 && !seen[n] {
+					seen[n] = true
+					ports = append(ports, n)
+				}
+			}
+		}
+		sort.Ints(ports)
+		return ports, nil
+	}
+	return nil, nil
+}
+
+// publishedPort returns the host port of a compose port mapping: "8080", "8080:80",
+// "127.0.0.1:8080:80/tcp" or the long syntax with published, or 0
+func publishedPort(v interface{}) int {
+	switch p := v.(type) {
+	case int:
+		return p
+	case string:
+		fields := strings.Split(strings.SplitN(p, "/", 2)[0], ":")
+		host := fields[0]
+		if len(fields) > 1 {
+			host = fields[len(fields)-2]
+		}
+		n, _ := strconv.Atoi(host)
+		return n
+	case map[string]interface{}:
+		return publishedPort(p["published"])
+	}
+	return 0
+}
+
+// first returns the first non-nil value
+func first(values ...interface{}) interface{} {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+func parseCPUs(v interface{}) float64 {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case float64:
+		return n
+	case string:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f
+	}
+	return 0
+}
+
+// parseMemory parses a compose memory value: bytes, or a number with a b, k, m or g suffix
+// (binary units, case-insensitive, optionally followed by b)
+func parseMemory(v interface{}) int64 {
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case float64:
+		return int64(n)
+	case string:
+		s := strings.ToLower(strings.TrimSpace(n))
+		s = strings.TrimSuffix(s, "b")
+		factor := 1.0
+		switch {
+		case strings.HasSuffix(s, "k"):
+			factor = 1 << 10
+		case strings.HasSuffix(s, "m"):
+			factor = 1 << 20
+		case strings.HasSuffix(s, "g"):
+			factor = 1 << 30
+		}
+		f, err := strconv.ParseFloat(strings.TrimRight(s, "kmg"), 64)
+		if err != nil {
+			return 0
+		}
+		return int64(f * factor)
+	}
+	return 0
+}
+
+// detectBackend works out what runs the daemon from the client's context and endpoint, and
+// the operating system and host name the daemon reports
+func detectBackend(runtimeName, goos, contextName, endpoint, daemonOS, daemonHost string) string {
+	if runtimeName == "podman" {
+		if goos == "linux" {
+			return BackendNative
+		}
+		return BackendPodmanMachine
+	}
+
+	signals := strings.ToLower(strings.Join([]string{contextName, endpoint, daemonOS, daemonHost}, " "))
+	switch {
+	case strings.Contains(signals, "docker desktop") || strings.Contains(signals, "desktop-linux") || strings.Contains(signals, "docker-desktop"):
+		return BackendDockerDesktop
+	case strings.Contains(signals, "colima"):
+		return BackendColima
+	case strings.Contains(signals, "rancher-desktop") || strings.Contains(signals, ".rd/docker.sock"):
+		return BackendRancherDesktop
+	case strings.Contains(signals, "orbstack"):
+		return BackendOrbStack
+	case goos == "darwin" || goos == "windows":
+		// Only Docker Desktop's default context has no telling name
+		return BackendDockerDesktop
+	}
+	return BackendNative
+}
+
+// vmShares returns the host directories a backend's VM shares with containers. Explicit
+// configuration is read from the backend's settings; otherwise its defaults apply.
+func vmShares(backend, goos, home string) []Share {
+	switch backend {
+	case BackendDockerDesktop:
+		if goos == "windows" {
+			return nil // WSL 2 reaches every Windows drive
+		}
+		return dockerDesktopShares(home)
+	case BackendColima:
+		return colimaShares(home)
+	case BackendRancherDesktop, BackendPodmanMachine:
+		return []Share{{Path: home, Writable: true}}
+	}
+	return nil // OrbStack and native daemons see the whole file system
+}
+
+// dockerDesktopShares reads Docker Desktop's file sharing directories
+func dockerDesktopShares(home string) []Share {
+	group := filepath.Join(home, "Library", "Group Containers", "group.com.docker")
+	for _, name := range []string{"settings-store.json", "settings.json"} {
+		data, err := os.ReadFile(filepath.Join(group, name))
+		if err != nil {
+			continue
+		}
+		var settings struct {
+			FilesharingDirectories []string `json:"filesharingDirectories"`
+		}
+		if json.Unmarshal(data, &settings) == nil && len(settings.FilesharingDirectories) > 0 {
+			shares := make([]Share, 0, len(settings.FilesharingDirectories))
+			for _, dir := range settings.FilesharingDirectories {
+				shares = append(shares, Share{Path: dir, Writable: true})
+			}
+			return shares
+		}
+	}
+	// Docker Desktop's defaults
+	return []Share{{"/Users", true}, {"/Volumes", true}, {"/private", true}, {"/tmp", true}, {"/var/folders", true}}
+}
+
+// colimaShares reads the mounts of the default Colima profile. Without mounts, Colima shares
+// the home directory.
+func colimaShares(home string) []Share {
+	data, err := os.ReadFile(filepath.Join(home, ".colima", "default", "colima.yaml"))
+	if err == nil {
+		var cfg struct {
+			Mounts []struct {
+				Location string `yaml:"location"`
+				Writable bool   `yaml:"writable"`
+			} `yaml:"mounts"`
+		}
+		if yaml.Unmarshal(data, &cfg) == nil && len(cfg.Mounts) > 0 {
+			shares := make([]Share, 0, len(cfg.Mounts))
+			for _, m := range cfg.Mounts {
+				location := m.Location
+				if location == "~" || strings.HasPrefix(location, "~/") {
+					location = filepath.Join(home, strings.TrimPrefix(location, "~"))
+				}
+				shares = append(shares, Share{Path: location, Writable: m.Writable})
+			}
+			return shares
+		}
+	}
+	return []Share{{Path: home, Writable: true}}
+}
+
+// findShare returns the share a path is below
+func findShare(shares []Share, path string) (Share, bool) {
+	for _, share := range shares {
+		rel, err := filepath.Rel(share.Path, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return share, true
+		}
+	}
+	return Share{}, false
+}
+
+// vmIssues checks a reachable VM backend's resources against the project's needs, and that
+// it shares the project directory writable
+func vmIssues(rt Runtime, needs Resources, projectRoot string) []Issue {
+	if rt.VM == nil {
+		return nil
+	}
+	var issues []Issue
+
+	cpus, memory := float64(rt.VM.CPUs), rt.VM.MemoryBytes
+	shortCPUs := rt.VM.CPUs > 0 && needs.CPUs > cpus
+	shortMemory := memory > 0 && needs.MemoryBytes > memory
+	if shortCPUs || shortMemory {
+		wantCPUs := int(math.Max(cpus, math.Ceil(needs.CPUs)))
+		wantGB := int(math.Max(math.Ceil(float64(memory)/(1<<30)), math.Ceil(float64(needs.MemoryBytes)/(1<<30))))
+		issues = append(issues, Issue{
+			Type:     IssueVMResources,
+			Severity: "warning",
+			Message: fmt.Sprintf("The %s VM has %d CPUs and %s of memory, but %s needs %s CPUs and %s",
+				rt.Backend, rt.VM.CPUs, formatGiB(memory), needs.Source,
+				strconv.FormatFloat(needs.CPUs, 'f', -1, 64), formatGiB(needs.MemoryBytes)),
+			FixCommand: resizeHint(rt.Backend, wantCPUs, wantGB),
+		})
+	}
+
+	if len(rt.VM.Shares) > 0 && projectRoot != "" {
+		share, ok := findShare(rt.VM.Shares, projectRoot)
+		switch {
+		case !ok:
+			issues = append(issues, Issue{
+				Type:       IssueVMFileSharing,
+				Severity:   "error",
+				Message:    fmt.Sprintf("The %s VM doesn't share %s, so bind mounts of the project are empty in containers", rt.Backend, projectRoot),
+				FixCommand: shareHint(rt.Backend, projectRoot),
+			})
+		case !share.Writable:
+			issues = append(issues, Issue{
+				Type:       IssueVMFileSharing,
+				Severity:   "warning",
+				Message:    fmt.Sprintf("The %s VM shares %s read-only, so containers can't write to the project", rt.Backend, share.Path),
+				FixCommand: shareHint(rt.Backend, share.Path),
+			})
+		}
+	}
+	return issues
+}
+
+func formatGiB(n int64) string {
+	return strconv.FormatFloat(float64(n)/(1<<30), 'f', 1, 64) + " GiB"
+}
+
+// vmStartHint is the command that starts a backend's VM
+func vmStartHint(backend string) string {
+	switch backend {
+	case BackendDockerDesktop:
+		return "Start Docker Desktop"
+	case BackendColima:
+		return "colima start"
+	case BackendRancherDesktop:
+		return "rdctl start"
+	case BackendOrbStack:
+		return "orb start"
+	case BackendPodmanMachine:
+		return "podman machine start"
+	}
+	return ""
+}
+
+// resizeHint is how to give a backend's VM more CPUs and memory
+func resizeHint(backend string, cpus, memoryGB int) string {
+	switch backend {
+	case BackendColima:
+		return fmt.Sprintf("colima stop && colima start --cpu %d --memory %d", cpus, memoryGB)
+	case BackendRancherDesktop:
+		return fmt.Sprintf("rdctl set --virtual-machine.number-cpus %d --virtual-machine.memory-in-gb %d", cpus, memoryGB)
+	case BackendOrbStack:
+		return fmt.Sprintf("orb config set cpu %d && orb config set memory_mib %d", cpus, memoryGB*1024)
+	case BackendPodmanMachine:
+		return fmt.Sprintf("podman machine stop && podman machine set --cpus %d --memory %d && podman machine start", cpus, memoryGB*1024)
+	}
+	return fmt.Sprintf("Set CPUs to %d and memory to %d GB in Docker Desktop > Settings > Resources", cpus, memoryGB)
+}
+
+// shareHint is how to share a directory with a backend's VM
+func shareHint(backend, path string) string {
+	switch backend {
+	case BackendColima:
+		return fmt.Sprintf("colima stop && colima start --mount %s:w", path)
+	case BackendRancherDesktop:
+		return "Move the project below your home directory, or add it to the mounts of ~/Library/Application Support/rancher-desktop/lima/_config/override.yaml"
+	case BackendPodmanMachine:
+		return fmt.Sprintf("podman machine init with -v %s:%s, or move the project below your home directory", path, path)
+	}
+	return fmt.Sprintf("Add %s in Docker Desktop > Settings > Resources > File sharing", path)
+}
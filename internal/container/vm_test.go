@@ -0,0 +1,149 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectBackend(t *testing.T) {
+	tests := []struct {
+		name                                       string
+		runtime, goos, context, endpoint, os, host string
+		want                                       string
+	}{
+		{"docker desktop", "docker", "darwin", "desktop-linux", "unix:///Users/me/.docker/run/docker.sock", "Docker Desktop", "docker-desktop", BackendDockerDesktop},
+		{"colima", "docker", "darwin", "colima", "unix:///Users/me/.colima/default/docker.sock", "Ubuntu 24.04 LTS", "colima", BackendColima},
+		{"rancher desktop", "docker", "darwin", "rancher-desktop", "unix:///Users/me/.rd/docker.sock", "Alpine Linux", "lima-rancher-desktop", BackendRancherDesktop},
+		{"orbstack", "docker", "darwin", "orbstack", "unix:///Users/me/.orbstack/run/docker.sock", "OrbStack", "orbstack", BackendOrbStack},
+		{"unreachable default context on mac", "docker", "darwin", "default", "unix:///var/run/docker.sock", "", "", BackendDockerDesktop},
+		{"linux engine", "docker", "linux", "default", "unix:///var/run/docker.sock", "Ubuntu 24.04 LTS", "dev", BackendNative},
+		{"podman on mac", "podman", "darwin", "", "", "", "", BackendPodmanMachine},
+		{"podman on linux", "podman", "linux", "", "", "", "", BackendNative},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, detectBackend(tt.runtime, tt.goos, tt.context, tt.endpoint, tt.os, tt.host))
+		})
+	}
+}
+
+func TestComposeNeeds(t *testing.T) {
+	dir := t.TempDir()
+	_, ok, err := ComposeNeeds(dir)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	compose := `services:
+  db:
+    image: postgres:16
+    deploy:
+      resources:
+        reservations:
+          cpus: "1.5"
+          memory: 2g
+        limits:
+          cpus: "4"
+          memory: 8g
+  search:
+    image: opensearch
+    mem_limit: 1536m
+    cpus: 2
+  web:
+    image: nginx
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(compose), 0644))
+	needs, ok, err := ComposeNeeds(dir)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, 3.5, needs.CPUs)
+	assert.Equal(t, int64(3.5*(1<<30)), needs.MemoryBytes)
+	assert.Equal(t, "docker-compose.yml", needs.Source)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte("services: [\n"), 0644))
+	_, _, err = ComposeNeeds(dir)
+	assert.ErrorContains(t, err, "invalid compose.yaml")
+}
+
+func TestComposePorts(t *testing.T) {
+	dir := t.TempDir()
+	compose := `services:
+  db:
+    image: postgres:16
+    ports: ["5432:5432"]
+  web:
+    image: nginx
+    ports:
+      - "127.0.0.1:8080:80/tcp"
+      - 9229
+      - target: 443
+        published: "8443"
+      - "3000-3005:3000-3005"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte(compose), 0644))
+	ports, err := ComposePorts(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []int{5432, 8080, 8443, 9229}, ports)
+}
+
+func TestVMShares(t *testing.T) {
+	home := t.TempDir()
+	assert.Equal(t, []Share{{Path: home, Writable: true}}, vmShares(BackendColima, "darwin", home))
+	assert.Contains(t, vmShares(BackendDockerDesktop, "darwin", home), Share{Path: "/Users", Writable: true})
+	assert.Nil(t, vmShares(BackendDockerDesktop, "windows", home))
+	assert.Nil(t, vmShares(BackendOrbStack, "darwin", home))
+
+	profile := filepath.Join(home, ".colima", "default")
+	require.NoError(t, os.MkdirAll(profile, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(profile, "colima.yaml"), []byte("mounts:\n  - location: ~/src\n    writable: false\n"), 0644))
+	assert.Equal(t, []Share{{Path: filepath.Join(home, "src"), Writable: false}}, vmShares(BackendColima, "darwin", home))
+
+	settings := filepath.Join(home, "Library", "Group Containers", "group.com.docker")
+	require.NoError(t, os.MkdirAll(settings, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(settings, "settings-store.json"), []byte(`{"filesharingDirectories":["/Users/me/work"]}`), 0644))
+	assert.Equal(t, []Share{{Path: "/Users/me/work", Writable: true}}, vmShares(BackendDockerDesktop, "darwin", home))
+}
+
+func TestEvaluate_VM(t *testing.T) {
+	colima := Runtime{Name: "docker", Reachable: true, Backend: BackendColima, Context: "colima", VM: &VM{
+		CPUs:        2,
+		MemoryBytes: 2 << 30,
+		Shares:      []Share{{Path: "/Users/me", Writable: true}},
+	}}
+	needs := &Resources{CPUs: 3.5, MemoryBytes: 6 << 30, Source: "docker-compose.yml"}
+
+	report := &RuntimeReport{ProjectRoot: "/Users/me/src/app", Needs: needs, MaxDiskGB: DefaultMaxDiskGB, IsHealthy: true, Runtimes: []Runtime{colima}}
+	report.evaluate("darwin", "")
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, IssueVMResources, report.Issues[0].Type)
+	assert.Equal(t, "The colima VM has 2 CPUs and 2.0 GiB of memory, but docker-compose.yml needs 3.5 CPUs and 6.0 GiB", report.Issues[0].Message)
+	assert.Equal(t, "colima stop && colima start --cpu 4 --memory 6", report.Issues[0].FixCommand)
+
+	report = &RuntimeReport{ProjectRoot: "/Volumes/work/app", MaxDiskGB: DefaultMaxDiskGB, IsHealthy: true, Runtimes: []Runtime{colima}}
+	report.evaluate("darwin", "")
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, IssueVMFileSharing, report.Issues[0].Type)
+	assert.Equal(t, "colima stop && colima start --mount /Volumes/work/app:w", report.Issues[0].FixCommand)
+
+	readOnly := colima
+	readOnly.VM = &VM{Shares: []Share{{Path: "/Users/me"}}}
+	report = &RuntimeReport{ProjectRoot: "/Users/me/src/app", MaxDiskGB: DefaultMaxDiskGB, IsHealthy: true, Runtimes: []Runtime{readOnly}}
+	report.evaluate("darwin", "")
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, "warning", report.Issues[0].Severity)
+
+	stopped := Runtime{Name: "docker", Backend: BackendRancherDesktop, Context: "rancher-desktop", Error: "Cannot connect to the Docker daemon"}
+	report = &RuntimeReport{Required: true, IsHealthy: true, Runtimes: []Runtime{stopped}}
+	report.evaluate("darwin", "")
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, IssueVMStopped, report.Issues[0].Type)
+	assert.Equal(t, "rdctl start", report.Issues[0].FixCommand)
+}
+
+func TestResizeHint(t *testing.T) {
+	assert.Equal(t, "podman machine stop && podman machine set --cpus 4 --memory 8192 && podman machine start", resizeHint(BackendPodmanMachine, 4, 8))
+	assert.Equal(t, "Set CPUs to 4 and memory to 8 GB in Docker Desktop > Settings > Resources", resizeHint(BackendDockerDesktop, 4, 8))
+}
@@ -1,9 +1,8 @@
 package detector
 
 import (
-	"path/filepath"
+	"context"
 
-	"dev-env-sentinel/internal/common"
 	"dev-env-sentinel/internal/config"
 )
 
@@ -15,15 +14,21 @@ type DetectedEcosystem struct {
 	ProjectRoot string
 }
 
-// DetectEcosystems detects all ecosystems present in a project
-func DetectEcosystems(projectRoot string, configs []*config.EcosystemConfig) ([]*DetectedEcosystem, error) {
+// DetectEcosystems detects all ecosystems present in a project. The config of each detected
+// ecosystem only contains the entries whose `when` conditions hold for the project.
+// Ecosystems come in the order of the configs, which are discovered in lexical path order.
+// Detection stops with ctx's error once ctx is done.
+func DetectEcosystems(ctx context.Context, projectRoot string, configs []*config.EcosystemConfig) ([]*DetectedEcosystem, error) {
 	var detected []*DetectedEcosystem
 
 	for _, cfg := range configs {
-		if present, confidence := isEcosystemPresent(projectRoot, cfg); present {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if present, confidence := isEcosystemPresent(ctx, projectRoot, cfg); present {
 			detected = append(detected, &DetectedEcosystem{
 				ID:          cfg.Ecosystem.ID,
-				Config:      cfg,
+				Config:      cfg.Select(&projectFacts{ctx: ctx, root: projectRoot, cfg: cfg}),
 				Confidence:  confidence,
 				ProjectRoot: projectRoot,
 			})
@@ -34,60 +39,7 @@ func DetectEcosystems(projectRoot string, configs []*config.EcosystemConfig) ([]
 }
 
 // isEcosystemPresent checks if an ecosystem is present in a project
-func isEcosystemPresent(projectRoot string, cfg *config.EcosystemConfig) (bool, float64) {
-	detection := cfg.Ecosystem.Detection
-	
-	// Check required files
-	requiredCount := 0
-	for _, file := range detection.RequiredFiles {
-		path := filepath.Join(projectRoot, file)
-		if common.FileExists(path) {
-			requiredCount++
-		}
-	}
-
-	// All required files must be present
-	if len(detection.RequiredFiles) > 0 && requiredCount < len(detection.RequiredFiles) {
-		return false, 0
-	}
-
-	// Calculate confidence based on optional files and patterns
-	confidence := 1.0
-	if len(detection.RequiredFiles) > 0 {
-		confidence = float64(requiredCount) / float64(len(detection.RequiredFiles))
-	}
-
-	// Boost confidence with optional files
-	optionalCount := 0
-	for _, file := range detection.OptionalFiles {
-		path := filepath.Join(projectRoot, file)
-		if common.FileExists(path) {
-			optionalCount++
-		}
-	}
-	if len(detection.OptionalFiles) > 0 {
-		confidence += float64(optionalCount) / float64(len(detection.OptionalFiles)) * 0.2
-		if confidence > 1.0 {
-			confidence = 1.0
-		}
-	}
-
-	// Check directory patterns
-	patternCount := 0
-	for _, pattern := range detection.DirectoryPatterns {
-		expanded := common.ExpandPattern(pattern)
-		path := filepath.Join(projectRoot, expanded)
-		if common.DirExists(path) {
-			patternCount++
-		}
-	}
-	if len(detection.DirectoryPatterns) > 0 {
-		confidence += float64(patternCount) / float64(len(detection.DirectoryPatterns)) * 0.1
-		if confidence > 1.0 {
-			confidence = 1.0
-		}
-	}
-
-	return confidence >= 0.5, confidence
+func isEcosystemPresent(ctx context.Context, projectRoot string, cfg *config.EcosystemConfig) (bool, float64) {
+	explanation := Explain(ctx, projectRoot, cfg)
+	return explanation.Detected, explanation.Confidence
 }
-
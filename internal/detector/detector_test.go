@@ -1,6 +1,7 @@
 package detector
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -114,7 +115,7 @@ func TestDetectEcosystems(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			projectRoot, configs := tt.setup(t)
 
-			ecosystems, err := DetectEcosystems(projectRoot, configs)
+			ecosystems, err := DetectEcosystems(context.Background(), projectRoot, configs)
 			if tt.wantErr {
 				assert.Error(t, err)
 				return
@@ -286,7 +287,7 @@ func TestIsEcosystemPresent(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			projectRoot, cfg := tt.setup(t)
 
-			present, confidence := isEcosystemPresent(projectRoot, cfg)
+			present, confidence := isEcosystemPresent(context.Background(), projectRoot, cfg)
 			assert.Equal(t, tt.expected, present)
 			if present {
 				assert.GreaterOrEqual(t, confidence, tt.minConfidence)
@@ -312,7 +313,7 @@ func TestDetectedEcosystem_Structure(t *testing.T) {
 		},
 	}
 
-	ecosystems, err := DetectEcosystems(tmpDir, []*config.EcosystemConfig{cfg})
+	ecosystems, err := DetectEcosystems(context.Background(), tmpDir, []*config.EcosystemConfig{cfg})
 	require.NoError(t, err)
 	require.Len(t, ecosystems, 1)
 
@@ -0,0 +1,122 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/config"
+)
+
+// Detection weights: optional files and directory patterns raise the confidence of
+// an ecosystem by up to these amounts when all of them are present
+const (
+	OptionalFilesWeight     = 0.2
+	DirectoryPatternsWeight = 0.1
+	// ConfidenceThreshold is the confidence an ecosystem needs to be detected
+	ConfidenceThreshold = 0.5
+)
+
+// RuleMatch is the outcome of one detection rule: a file or directory pattern and whether it exists
+type RuleMatch struct {
+	Pattern string `json:"pattern"`
+	Found   bool   `json:"found"`
+}
+
+// Explanation shows how an ecosystem's detection rules apply to a project: which files and
+// directories were found or missing, and how they add up to its confidence
+type Explanation struct {
+	EcosystemID string      `json:"ecosystem_id"`
+	ProjectRoot string      `json:"project_root"`
+	Detected    bool        `json:"detected"`
+	Confidence  float64     `json:"confidence"`
+	Required    []RuleMatch `json:"required,omitempty"`
+	Optional    []RuleMatch `json:"optional,omitempty"`
+	Directories []RuleMatch `json:"directories,omitempty"`
+	Steps       []string    `json:"steps"` // The confidence calculation, one step per line
+}
+
+// Explain evaluates an ecosystem's detection rules against a project
+func Explain(ctx context.Context, projectRoot string, cfg *config.EcosystemConfig) *Explanation {
+	detection := cfg.Ecosystem.Detection
+	e := &Explanation{
+		EcosystemID: cfg.Ecosystem.ID,
+		ProjectRoot: projectRoot,
+		Required:    matchFiles(ctx, projectRoot, detection.RequiredFiles),
+		Optional:    matchFiles(ctx, projectRoot, detection.OptionalFiles),
+		Directories: matchDirs(projectRoot, detection.DirectoryPatterns),
+	}
+
+	// All required files must be present
+	requiredCount := countFound(e.Required)
+	if requiredCount < len(e.Required) {
+		e.Steps = append(e.Steps, fmt.Sprintf("required files: %d of %d present; all are needed, so the ecosystem is not detected", requiredCount, len(e.Required)))
+		return e
+	}
+	confidence := 1.0
+	if len(e.Required) > 0 {
+		e.Steps = append(e.Steps, fmt.Sprintf("required files: all %d present, confidence 1.00", len(e.Required)))
+	} else {
+		e.Steps = append(e.Steps, "required files: none configured, confidence 1.00")
+	}
+
+	// Optional files and directory patterns boost confidence
+	confidence = e.boost(confidence, "optional files", e.Optional, OptionalFilesWeight)
+	confidence = e.boost(confidence, "directory patterns", e.Directories, DirectoryPatternsWeight)
+
+	e.Confidence = confidence
+	e.Detected = confidence >= ConfidenceThreshold
+	if e.Detected {
+		e.Steps = append(e.Steps, fmt.Sprintf("confidence %.2f >= %.2f: detected", confidence, ConfidenceThreshold))
+	} else {
+		e.Steps = append(e.Steps, fmt.Sprintf("confidence %.2f < %.2f: not detected", confidence, ConfidenceThreshold))
+	}
+	return e
+}
+
+// boost adds the share of found rules times their weight to the confidence, capped at 1
+func (e *Explanation) boost(confidence float64, name string, matches []RuleMatch, weight float64) float64 {
+	if len(matches) == 0 {
+		return confidence
+	}
+	found := countFound(matches)
+	boosted := confidence + float64(found)/float64(len(matches))*weight
+	step := fmt.Sprintf("%s: %d of %d present, %.2f + %d/%d * %.1f = %.2f", name, found, len(matches), confidence, found, len(matches), weight, boosted)
+	if boosted > 1.0 {
+		boosted = 1.0
+		step += ", capped at 1.00"
+	}
+	e.Steps = append(e.Steps, step)
+	return boosted
+}
+
+// matchFiles checks which files or glob patterns exist in the project
+func matchFiles(ctx context.Context, projectRoot string, files []string) []RuleMatch {
+	matches := make([]RuleMatch, 0, len(files))
+	for _, file := range files {
+		matches = append(matches, RuleMatch{Pattern: file, Found: common.PatternExists(ctx, filepath.Join(projectRoot, file))})
+	}
+	return matches
+}
+
+// matchDirs checks which directory patterns exist in the project
+func matchDirs(projectRoot string, patterns []string) []RuleMatch {
+	matches := make([]RuleMatch, 0, len(patterns))
+	for _, pattern := range patterns {
+		path := filepath.Join(projectRoot, common.ExpandPattern(pattern))
+		matches = append(matches, RuleMatch{Pattern: pattern, Found: common.DirExists(path)})
+	}
+	return matches
+}
+
+// countFound returns the number of rules that matched
+func countFound(matches []RuleMatch) int {
+	count := 0
+	for _, m := range matches {
+		if m.Found {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,42 @@
+package detector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte("{}"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "node_modules"), 0755))
+
+	cfg := &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		ID: "npm",
+		Detection: config.Detection{
+			RequiredFiles:     []string{"package.json"},
+			OptionalFiles:     []string{"package-lock.json", "yarn.lock"},
+			DirectoryPatterns: []string{"node_modules"},
+		},
+	}}
+
+	e := Explain(context.Background(), tmpDir, cfg)
+	assert.True(t, e.Detected)
+	assert.Equal(t, 1.0, e.Confidence)
+	assert.Equal(t, []RuleMatch{{Pattern: "package.json", Found: true}}, e.Required)
+	assert.Equal(t, []RuleMatch{{Pattern: "package-lock.json"}, {Pattern: "yarn.lock"}}, e.Optional)
+	assert.Equal(t, []RuleMatch{{Pattern: "node_modules", Found: true}}, e.Directories)
+	assert.Contains(t, e.Steps, "optional files: 0 of 2 present, 1.00 + 0/2 * 0.2 = 1.00")
+	assert.Contains(t, e.Steps, "directory patterns: 1 of 1 present, 1.00 + 1/1 * 0.1 = 1.10, capped at 1.00")
+
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, "package.json")))
+	e = Explain(context.Background(), tmpDir, cfg)
+	assert.False(t, e.Detected)
+	assert.Equal(t, []RuleMatch{{Pattern: "package.json"}}, e.Required)
+	assert.Equal(t, []string{"required files: 0 of 1 present; all are needed, so the ecosystem is not detected"}, e.Steps)
+}
@@ -0,0 +1,47 @@
+package detector
+
+import (
+	"context"
+	"path/filepath"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/version"
+)
+
+// projectFacts answers `when` conditions of an ecosystem config for a project.
+// The version is only detected, by running the config's version command, when a
+// condition asks for it.
+type projectFacts struct {
+	ctx      context.Context
+	root     string
+	cfg      *config.EcosystemConfig
+	detected bool
+	version  string
+}
+
+// FileExists reports whether a path or glob relative to the project root matches
+func (f *projectFacts) FileExists(pattern string) bool {
+	return common.PatternExists(f.ctx, filepath.Join(f.root, common.ExpandPattern(pattern)))
+}
+
+// DirExists reports whether a directory relative to the project root exists
+func (f *projectFacts) DirExists(path string) bool {
+	return common.DirExists(filepath.Join(f.root, common.ExpandPattern(path)))
+}
+
+// CompareVersion compares the ecosystem's installed version with v
+func (f *projectFacts) CompareVersion(v string) (int, bool) {
+	if !f.detected {
+		f.detected = true
+		if versionCfg := f.cfg.Ecosystem.VersionConfig; versionCfg.VersionCommand != "" || len(versionCfg.VersionArgs) > 0 {
+			if info, err := version.DetectVersion(f.ctx, f.cfg); err == nil {
+				f.version = info.Version
+			}
+		}
+	}
+	if f.version == "" {
+		return 0, false
+	}
+	return version.Compare(f.version, v), true
+}
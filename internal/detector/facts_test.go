@@ -0,0 +1,54 @@
+package detector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectEcosystems_Conditions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("version command requires sh")
+	}
+
+	cfg := &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		ID: "docker",
+		VersionConfig: config.VersionConfig{
+			VersionCommand: "echo 'Docker version 24.0.7'",
+			VersionPattern: `Docker version (\d+\.\d+\.\d+)`,
+		},
+		Infrastructure: config.Infrastructure{Services: []config.Service{
+			{Name: "docker", When: `file_exists("Dockerfile*") || file_exists("*compose.y*ml")`},
+			{Name: "docker-compose", When: `file_exists("*compose.y*ml")`},
+			{Name: "buildx", When: `dir_exists(".docker") && version >= 23`},
+			{Name: "legacy-builder", When: `version < 23`},
+		}},
+	}}
+
+	names := func(projectRoot string) []string {
+		ecosystems, err := DetectEcosystems(context.Background(), projectRoot, []*config.EcosystemConfig{cfg})
+		require.NoError(t, err)
+		require.Len(t, ecosystems, 1)
+		var names []string
+		for _, service := range ecosystems[0].Config.Ecosystem.Infrastructure.Services {
+			names = append(names, service.Name)
+		}
+		return names
+	}
+
+	assert.Empty(t, names(t.TempDir()), "no docker checks outside dockerized projects")
+
+	dockerized := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dockerized, "Dockerfile.dev"), []byte("FROM alpine\n"), 0644))
+	assert.Equal(t, []string{"docker"}, names(dockerized))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dockerized, "compose.yaml"), []byte("services: {}\n"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dockerized, ".docker"), 0755))
+	assert.Equal(t, []string{"docker", "docker-compose", "buildx"}, names(dockerized))
+}
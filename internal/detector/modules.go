@@ -0,0 +1,137 @@
+package detector
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	pathpkg "path"
+	"path/filepath"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/config"
+)
+
+// Module is a module of a multi-module build
+type Module struct {
+	Layout     string   // One of config.ModuleLayouts
+	Dir        string   // Relative to the project root, slash separated
+	Name       string   // Maven artifactId, Nx project or package name
+	Aggregator bool     // Only builds other modules, like a Maven pom packaging; has no outputs of its own
+	DependsOn  []string // Dirs of the modules it depends on, when the layout records them
+}
+
+// Selector returns how a build tool selects the module, e.g. :core for mvn -pl. Names that
+// use properties aren't known until the build runs, so their directory is used instead.
+func (m Module) Selector() string {
+	switch m.Layout {
+	case config.ModulesNx:
+		return m.Name
+	case config.ModulesTurbo:
+		if m.Name != "" {
+			return m.Name
+		}
+		return "./" + m.Dir
+	}
+	if m.Name != "" && !strings.Contains(m.Name, "${") {
+		return ":" + m.Name
+	}
+	return m.Dir
+}
+
+// Selectors joins the selectors of modules for config.ModulesPlaceholder
+func Selectors(modules []Module) string {
+	selectors := make([]string, len(modules))
+	separator := ","
+	for i, m := range modules {
+		selectors[i] = m.Selector()
+		if m.Layout == config.ModulesTurbo {
+			separator = " --filter="
+		}
+	}
+	return strings.Join(selectors, separator)
+}
+
+// mavenPOM is the part of a pom.xml that lists modules
+type mavenPOM struct {
+	ArtifactID string   `xml:"artifactId"`
+	Packaging  string   `xml:"packaging"`
+	Modules    []string `xml:"modules>module"`
+}
+
+// Modules lists the modules of a multi-module build in a layout of config.ModuleLayouts. Maven
+// modules come nested modules after their parent, in the order the build files list them; Nx
+// projects and workspace packages by directory. A project that isn't a multi-module build has
+// none. Modules outside the project are left out.
+func Modules(ctx context.Context, projectRoot, layout string) ([]Module, error) {
+	switch layout {
+	case config.ModulesMaven:
+		root, err := readBuildFile(projectRoot, ".", "pom.xml")
+		if err != nil || root == nil {
+			return nil, err
+		}
+		return mavenModules(projectRoot, ".", root, map[string]bool{".": true}, nil)
+	case config.ModulesNx:
+		return nxProjects(ctx, projectRoot)
+	case config.ModulesTurbo:
+		return workspacePackages(ctx, projectRoot, layout, nil)
+	default:
+		return nil, fmt.Errorf("unknown module layout: %s", layout)
+	}
+}
+
+// mavenModules appends the modules a pom.xml in dir lists, and theirs
+func mavenModules(projectRoot, dir string, pom *mavenPOM, visited map[string]bool, modules []Module) ([]Module, error) {
+	for _, entry := range pom.Modules {
+		entry = strings.TrimSpace(filepath.ToSlash(entry))
+		if entry == "" {
+			continue
+		}
+		// A module is a directory with a pom.xml, or a path to another build file
+		moduleDir := pathpkg.Join(dir, entry)
+		file := "pom.xml"
+		if strings.HasSuffix(moduleDir, ".xml") {
+			moduleDir, file = pathpkg.Split(moduleDir)
+			moduleDir = pathpkg.Clean(moduleDir)
+		}
+		if moduleDir == ".." || strings.HasPrefix(moduleDir, "../") || visited[moduleDir] {
+			continue
+		}
+		visited[moduleDir] = true
+
+		child, err := readBuildFile(projectRoot, moduleDir, file)
+		if err != nil {
+			return nil, err
+		}
+		if child == nil {
+			continue // Listed but missing; Maven reports that itself
+		}
+		modules = append(modules, Module{
+			Layout:     config.ModulesMaven,
+			Dir:        moduleDir,
+			Name:       strings.TrimSpace(child.ArtifactID),
+			Aggregator: strings.TrimSpace(child.Packaging) == "pom",
+		})
+		if modules, err = mavenModules(projectRoot, moduleDir, child, visited, modules); err != nil {
+			return nil, err
+		}
+	}
+	return modules, nil
+}
+
+// readBuildFile parses a Maven build file, or returns nil when it doesn't exist
+func readBuildFile(projectRoot, dir, file string) (*mavenPOM, error) {
+	path := filepath.Join(projectRoot, filepath.FromSlash(dir), file)
+	if !common.FileExists(path) {
+		return nil, nil
+	}
+	data, err := common.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pom mavenPOM
+	if err := xml.Unmarshal(data, &pom); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &pom, nil
+}
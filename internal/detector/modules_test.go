@@ -0,0 +1,129 @@
+package detector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModules(t *testing.T) {
+	tmpDir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	// Not a multi-module build
+	modules, err := Modules(context.Background(), tmpDir, config.ModulesMaven)
+	require.NoError(t, err)
+	assert.Empty(t, modules)
+
+	write("pom.xml", `<project><artifactId>root</artifactId><packaging>pom</packaging>
+  <modules>
+    <module>core</module>
+    <module>services</module>
+    <module>tools/build.xml</module>
+    <module>../shared</module>
+    <module>missing</module>
+  </modules>
+</project>`)
+	write("core/pom.xml", `<project><artifactId>core</artifactId></project>`)
+	write("services/pom.xml", `<project><artifactId>services</artifactId><packaging>pom</packaging>
+  <modules><module>api</module><module>../core</module></modules>
+</project>`)
+	write("services/api/pom.xml", `<project><artifactId>${project.parent.artifactId}-api</artifactId></project>`)
+	write("tools/build.xml", `<project><artifactId>tools</artifactId></project>`)
+
+	modules, err = Modules(context.Background(), tmpDir, config.ModulesMaven)
+	require.NoError(t, err)
+	assert.Equal(t, []Module{
+		{Layout: config.ModulesMaven, Dir: "core", Name: "core"},
+		{Layout: config.ModulesMaven, Dir: "services", Name: "services", Aggregator: true},
+		{Layout: config.ModulesMaven, Dir: "services/api", Name: "${project.parent.artifactId}-api"},
+		{Layout: config.ModulesMaven, Dir: "tools", Name: "tools"},
+	}, modules)
+
+	selectors := make([]string, len(modules))
+	for i, m := range modules {
+		selectors[i] = m.Selector()
+	}
+	assert.Equal(t, []string{":core", ":services", "services/api", ":tools"}, selectors)
+	assert.Equal(t, ":core,services/api", Selectors([]Module{modules[0], modules[2]}))
+
+	_, err = Modules(context.Background(), tmpDir, "gradle")
+	assert.Error(t, err)
+
+	write("pom.xml", `<project><modules>`)
+	_, err = Modules(context.Background(), tmpDir, config.ModulesMaven)
+	assert.Error(t, err)
+}
+
+func TestModules_Workspaces(t *testing.T) {
+	tmpDir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	write("package.json", `{"name": "repo", "workspaces": ["packages/*", "apps/*", "!apps/legacy"]}`)
+	write("packages/core/package.json", `{"name": "@acme/core"}`)
+	write("packages/ui/package.json", `{"name": "@acme/ui", "dependencies": {"@acme/core": "*", "react": "^18"}}`)
+	write("packages/notes/README.md", "")
+	write("apps/web/package.json", `{"name": "web", "devDependencies": {"@acme/ui": "workspace:*"}}`)
+	write("apps/web/node_modules/dep/package.json", `{"name": "dep"}`)
+	write("apps/legacy/package.json", `{"name": "legacy"}`)
+
+	modules, err := Modules(context.Background(), tmpDir, config.ModulesTurbo)
+	require.NoError(t, err)
+	assert.Equal(t, []Module{
+		{Layout: config.ModulesTurbo, Dir: "apps/web", Name: "web", DependsOn: []string{"packages/ui"}},
+		{Layout: config.ModulesTurbo, Dir: "packages/core", Name: "@acme/core"},
+		{Layout: config.ModulesTurbo, Dir: "packages/ui", Name: "@acme/ui", DependsOn: []string{"packages/core"}},
+	}, modules)
+	assert.Equal(t, "@acme/core --filter=web", Selectors([]Module{modules[1], modules[0]}))
+
+	// pnpm lists its workspaces on its own
+	write("package.json", `{"name": "repo"}`)
+	write("pnpm-workspace.yaml", "packages:\n  - 'packages/*'\n")
+	modules, err = Modules(context.Background(), tmpDir, config.ModulesTurbo)
+	require.NoError(t, err)
+	assert.Len(t, modules, 2)
+
+	// Nx before its first run: project.json names projects
+	write("libs/util/project.json", `{"name": "util"}`)
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, "pnpm-workspace.yaml")))
+	modules, err = Modules(context.Background(), tmpDir, config.ModulesNx)
+	require.NoError(t, err)
+	names := make([]string, len(modules))
+	for i, m := range modules {
+		names[i] = m.Name
+	}
+	assert.Equal(t, []string{"legacy", "web", "util", "@acme/core", "@acme/ui"}, names)
+
+	// The project graph Nx cached
+	write(".nx/workspace-data/project-graph.json", `{
+  "nodes": {
+    "repo": {"name": "repo", "data": {"root": "."}},
+    "core": {"name": "core", "data": {"root": "libs/core"}},
+    "shop": {"name": "shop", "data": {"root": "apps/shop"}}
+  },
+  "dependencies": {
+    "shop": [{"source": "shop", "target": "core"}, {"source": "shop", "target": "npm:react"}],
+    "core": []
+  }
+}`)
+	modules, err = Modules(context.Background(), tmpDir, config.ModulesNx)
+	require.NoError(t, err)
+	assert.Equal(t, []Module{
+		{Layout: config.ModulesNx, Dir: "apps/shop", Name: "shop", DependsOn: []string{"libs/core"}},
+		{Layout: config.ModulesNx, Dir: "libs/core", Name: "core"},
+	}, modules)
+	assert.Equal(t, "shop,core", Selectors(modules))
+}
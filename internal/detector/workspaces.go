@@ -0,0 +1,262 @@
+package detector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	pathpkg "path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// nxGraphFiles are where Nx caches its project graph, the current location first
+var nxGraphFiles = []string{".nx/workspace-data/project-graph.json", "node_modules/.cache/nx/project-graph.json"}
+
+// nxDefaultDirs hold the projects of an Nx workspace that doesn't use package manager workspaces
+var nxDefaultDirs = []string{"apps/*", "libs/*", "packages/*"}
+
+// nxGraph is the part of a cached Nx project graph that lists projects and their dependencies
+type nxGraph struct {
+	Nodes map[string]struct {
+		Name string `json:"name"`
+		Data struct {
+			Root string `json:"root"`
+		} `json:"data"`
+	} `json:"nodes"`
+	Dependencies map[string][]struct {
+		Target string `json:"target"`
+	} `json:"dependencies"`
+}
+
+// packageJSON is the part of a package.json that names a package, its dependencies and the
+// workspaces it holds
+type packageJSON struct {
+	Name                 string            `json:"name"`
+	Workspaces           json.RawMessage   `json:"workspaces"` // A list of globs, or {"packages": [...]} for Yarn
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// nxProjects lists the projects of the project graph Nx cached on its last run. Before Nx has
+// run, the workspace packages and project.json files stand in for it.
+func nxProjects(ctx context.Context, projectRoot string) ([]Module, error) {
+	for _, file := range nxGraphFiles {
+		path := filepath.Join(projectRoot, filepath.FromSlash(file))
+		if !common.FileExists(path) {
+			continue
+		}
+		data, err := common.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var graph nxGraph
+		if err := json.Unmarshal(data, &graph); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		dirs := make(map[string]string)
+		for name, node := range graph.Nodes {
+			if dir, ok := insideDir(node.Data.Root); ok {
+				dirs[name] = dir
+			}
+		}
+		var modules []Module
+		for name, dir := range dirs {
+			module := Module{Layout: config.ModulesNx, Dir: dir, Name: name}
+			// Dependencies on npm packages have targets outside the nodes
+			for _, dep := range graph.Dependencies[name] {
+				if depDir, ok := dirs[dep.Target]; ok && dep.Target != name {
+					module.DependsOn = append(module.DependsOn, depDir)
+				}
+			}
+			sort.Strings(module.DependsOn)
+			modules = append(modules, module)
+		}
+		sort.Slice(modules, func(i, j int) bool { return modules[i].Dir < modules[j].Dir })
+		return modules, nil
+	}
+	return workspacePackages(ctx, projectRoot, config.ModulesNx, nxDefaultDirs)
+}
+
+// workspacePackages lists the packages of the package manager workspaces, from the workspaces
+// of package.json or pnpm-workspace.yaml, or the fallback globs when there are none. Packages
+// depend on the others their package.json depends on. For Nx, a project.json names a project.
+func workspacePackages(ctx context.Context, projectRoot, layout string, fallback []string) ([]Module, error) {
+	globs, err := workspaceGlobs(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	if len(globs) == 0 {
+		globs = fallback
+	}
+
+	var include []string
+	exclude := make(map[string]bool)
+	for _, glob := range globs {
+		negated := strings.HasPrefix(glob, "!")
+		dirs, err := common.FindDirsByPattern(ctx, filepath.Join(projectRoot, filepath.FromSlash(strings.TrimPrefix(glob, "!"))))
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range dirs {
+			rel, err := filepath.Rel(projectRoot, dir)
+			if err != nil {
+				continue
+			}
+			rel, ok := insideDir(filepath.ToSlash(rel))
+			if !ok || strings.Contains("/"+rel+"/", "/node_modules/") {
+				continue
+			}
+			if negated {
+				exclude[rel] = true
+			} else {
+				include = append(include, rel)
+			}
+		}
+	}
+
+	var modules []Module
+	var manifests []*packageJSON
+	byName := make(map[string]string)
+	seen := make(map[string]bool)
+	for _, dir := range include {
+		if exclude[dir] || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+
+		pkg, err := readPackageJSON(filepath.Join(projectRoot, filepath.FromSlash(dir), "package.json"))
+		if err != nil {
+			return nil, err
+		}
+		name := ""
+		if pkg != nil {
+			name = pkg.Name
+		}
+		if layout == config.ModulesNx {
+			project, err := readProjectName(filepath.Join(projectRoot, filepath.FromSlash(dir), "project.json"))
+			if err != nil {
+				return nil, err
+			}
+			if project != "" {
+				name = project
+			} else if pkg == nil {
+				continue
+			} else if name == "" {
+				name = pathpkg.Base(dir) // Nx names a project after its directory
+			}
+		} else if pkg == nil {
+			continue
+		}
+
+		if pkg == nil {
+			pkg = &packageJSON{}
+		}
+		if pkg.Name != "" {
+			byName[pkg.Name] = dir
+		}
+		modules = append(modules, Module{Layout: layout, Dir: dir, Name: name})
+		manifests = append(manifests, pkg)
+	}
+
+	for i := range modules {
+		pkg := manifests[i]
+		for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies, pkg.PeerDependencies, pkg.OptionalDependencies} {
+			for dep := range deps {
+				if dir, ok := byName[dep]; ok && dir != modules[i].Dir {
+					modules[i].DependsOn = append(modules[i].DependsOn, dir)
+				}
+			}
+		}
+		sort.Strings(modules[i].DependsOn)
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Dir < modules[j].Dir })
+	return modules, nil
+}
+
+// workspaceGlobs returns the workspace globs of package.json, or of pnpm-workspace.yaml
+func workspaceGlobs(projectRoot string) ([]string, error) {
+	pkg, err := readPackageJSON(filepath.Join(projectRoot, "package.json"))
+	if err != nil {
+		return nil, err
+	}
+	if pkg != nil && len(pkg.Workspaces) > 0 {
+		var globs []string
+		if err := json.Unmarshal(pkg.Workspaces, &globs); err == nil {
+			return globs, nil
+		}
+		var yarn struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.Unmarshal(pkg.Workspaces, &yarn); err == nil {
+			return yarn.Packages, nil
+		}
+	}
+
+	path := filepath.Join(projectRoot, "pnpm-workspace.yaml")
+	if !common.FileExists(path) {
+		return nil, nil
+	}
+	data, err := common.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pnpm struct {
+		Packages []string `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal(data, &pnpm); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return pnpm.Packages, nil
+}
+
+// readPackageJSON parses a package.json, or returns nil when it doesn't exist
+func readPackageJSON(path string) (*packageJSON, error) {
+	if !common.FileExists(path) {
+		return nil, nil
+	}
+	data, err := common.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pkg packageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &pkg, nil
+}
+
+// readProjectName returns the project a project.json names, or "" when there's none
+func readProjectName(path string) (string, error) {
+	if !common.FileExists(path) {
+		return "", nil
+	}
+	data, err := common.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var project struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &project); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return project.Name, nil
+}
+
+// insideDir cleans a slash separated path relative to the project root, reporting whether it
+// is a directory below the root
+func insideDir(dir string) (string, bool) {
+	dir = pathpkg.Clean(strings.TrimSpace(dir))
+	if dir == "." || dir == ".." || strings.HasPrefix(dir, "../") || strings.HasPrefix(dir, "/") {
+		return "", false
+	}
+	return dir, true
+}
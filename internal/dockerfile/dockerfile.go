@@ -0,0 +1,301 @@
+// Package dockerfile compares the tool versions a project's development Dockerfile installs with
+// the local machine and the project's requirements, so containerized and local workflows don't
+// silently build and test with different toolchains.
+package dockerfile
+
+import (
+	"bufio"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+)
+
+// Install is a tool version a Dockerfile installs
+type Install struct {
+	Tool    string // e.g. "node", "java", "maven"
+	Version string // As pinned, e.g. "20" or "3.9.6"; empty when unpinned
+	Source  string // The instruction it was read from, e.g. "FROM node:20-alpine"
+	File    string
+	Line    int
+}
+
+// instruction is a Dockerfile instruction with its continuation lines joined
+type instruction struct {
+	keyword string // Upper case, e.g. "FROM"
+	args    string
+	line    int
+}
+
+// dockerfileNames match the file names of Dockerfiles
+var dockerfileNames = regexp.MustCompile(`(?i)^(?:(?:docker|container)file(?:[.-].+)?|.+\.(?:docker|container)file)$`)
+
+// devMarker matches Dockerfile names and directories meant for development
+var devMarker = regexp.MustCompile(`(?i)(?:^|[.\-_/])(?:dev|develop|development|local)(?:$|[.\-_/])|\.devcontainer`)
+
+// searchDirs are the directories Dockerfiles are looked for in
+var searchDirs = []string{".", ".devcontainer", "docker", filepath.Join("docker", "dev"), "dev"}
+
+// FindDockerfiles returns the project's Dockerfiles used for development: those with a dev
+// marker in their name or directory (Dockerfile.dev, .devcontainer/Dockerfile, ...), or every
+// Dockerfile found when none has one
+func FindDockerfiles(projectRoot string) []string {
+	var all, dev []string
+	for _, dir := range searchDirs {
+		names, err := common.ReadDir(filepath.Join(projectRoot, dir))
+		if err != nil {
+			continue
+		}
+		for _, name := range names {
+			if !dockerfileNames.MatchString(name) || common.DirExists(filepath.Join(projectRoot, dir, name)) {
+				continue
+			}
+			rel := filepath.ToSlash(filepath.Join(dir, name))
+			all = append(all, filepath.Join(projectRoot, rel))
+			if devMarker.MatchString(rel) {
+				dev = append(dev, filepath.Join(projectRoot, rel))
+			}
+		}
+	}
+	if len(dev) > 0 {
+		return dev
+	}
+	return all
+}
+
+// Parse reads the tool versions a Dockerfile installs: from the tags of its base images, the
+// <TOOL>_VERSION build args and variables, and the obvious install commands of its RUN steps
+func Parse(file string) ([]Install, error) {
+	instructions, err := readInstructions(file)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]string)
+	stages := make(map[string]bool)
+	var installs []Install
+	add := func(inst instruction, tool, version string) {
+		installs = append(installs, Install{
+			Tool:    tool,
+			Version: version,
+			Source:  inst.keyword + " " + inst.args,
+			File:    file,
+			Line:    inst.line,
+		})
+	}
+
+	for _, inst := range instructions {
+		args := expand(inst.args, vars)
+		switch inst.keyword {
+		case "ARG", "ENV":
+			for _, assignment := range assignments(args) {
+				name, value := assignment[0], assignment[1]
+				if _, ok := vars[name]; !ok || inst.keyword == "ENV" || value != "" {
+					vars[name] = value
+				}
+				if tool, ok := versionVars[strings.ToUpper(name)]; ok && value != "" {
+					add(inst, tool, leadingVersion(value))
+				}
+			}
+		case "FROM":
+			fields := strings.Fields(args)
+			for len(fields) > 0 && strings.HasPrefix(fields[0], "--") {
+				fields = fields[1:]
+			}
+			if len(fields) == 0 {
+				continue
+			}
+			if len(fields) >= 3 && strings.EqualFold(fields[1], "as") {
+				stages[strings.ToLower(fields[2])] = true
+			}
+			if stages[strings.ToLower(fields[0])] {
+				continue // Builds on an earlier stage
+			}
+			for _, install := range imageTools(fields[0]) {
+				add(inst, install.Tool, install.Version)
+			}
+		case "RUN":
+			for _, pattern := range installPatterns {
+				for _, match := range pattern.re.FindAllStringSubmatch(args, -1) {
+					tool := pattern.tool
+					if tool == "" {
+						tool = strings.ToLower(match[1])
+					}
+					add(inst, tool, leadingVersion(match[len(match)-1]))
+				}
+			}
+		}
+	}
+	return installs, nil
+}
+
+// readInstructions reads the instructions of a Dockerfile, joining continuation lines and
+// skipping comments
+func readInstructions(file string) ([]instruction, error) {
+	f, err := common.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var instructions []instruction
+	var current strings.Builder
+	start := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") || (line == "" && current.Len() == 0) {
+			continue
+		}
+		if current.Len() == 0 {
+			start = n
+		}
+		continued := strings.HasSuffix(line, "\\")
+		current.WriteString(strings.TrimSuffix(line, "\\"))
+		current.WriteString(" ")
+		if continued {
+			continue
+		}
+		if fields := strings.SplitN(strings.TrimSpace(current.String()), " ", 2); len(fields) == 2 {
+			instructions = append(instructions, instruction{keyword: strings.ToUpper(fields[0]), args: strings.TrimSpace(fields[1]), line: start})
+		}
+		current.Reset()
+	}
+	return instructions, scanner.Err()
+}
+
+// varRef matches ${NAME}, ${NAME:-default} and $NAME
+var varRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// expand substitutes build args and variables, leaving unknown ones as they are
+func expand(s string, vars map[string]string) string {
+	return varRef.ReplaceAllStringFunc(s, func(ref string) string {
+		match := varRef.FindStringSubmatch(ref)
+		name := match[1] + match[3]
+		if value, ok := vars[name]; ok && value != "" {
+			return value
+		}
+		if match[2] != "" {
+			return match[2]
+		}
+		return ref
+	})
+}
+
+// assignments parses the NAME=value pairs of ARG and ENV, and the legacy `ENV NAME value` form,
+// in order
+func assignments(args string) [][2]string {
+	fields := strings.Fields(args)
+	if len(fields) >= 2 && !strings.Contains(fields[0], "=") {
+		return [][2]string{{fields[0], strings.Join(fields[1:], " ")}}
+	}
+	var pairs [][2]string
+	for _, field := range fields {
+		name, value, _ := strings.Cut(field, "=")
+		pairs = append(pairs, [2]string{name, strings.Trim(value, `"'`)})
+	}
+	return pairs
+}
+
+// versionVars are the build args and variables that pin a tool version
+var versionVars = map[string]string{
+	"NODE_VERSION":   "node",
+	"NODEJS_VERSION": "node",
+	"JAVA_VERSION":   "java",
+	"JDK_VERSION":    "java",
+	"PYTHON_VERSION": "python",
+	"GO_VERSION":     "go",
+	"GOLANG_VERSION": "go",
+	"MAVEN_VERSION":  "maven",
+	"GRADLE_VERSION": "gradle",
+	"DOTNET_VERSION": "dotnet",
+	"RUBY_VERSION":   "ruby",
+	"PHP_VERSION":    "php",
+	"RUST_VERSION":   "rust",
+	"YARN_VERSION":   "yarn",
+	"PNPM_VERSION":   "pnpm",
+	"POETRY_VERSION": "poetry",
+}
+
+// imageRepos are the tools the official and common images of a repository provide
+var imageRepos = map[string]string{
+	"node":                "node",
+	"javascript-node":     "node",
+	"typescript-node":     "node",
+	"openjdk":             "java",
+	"eclipse-temurin":     "java",
+	"amazoncorretto":      "java",
+	"zulu-openjdk":        "java",
+	"ibm-semeru-runtimes": "java",
+	"sapmachine":          "java",
+	"java":                "java",
+	"maven":               "maven",
+	"gradle":              "gradle",
+	"python":              "python",
+	"golang":              "go",
+	"go":                  "go",
+	"rust":                "rust",
+	"ruby":                "ruby",
+	"php":                 "php",
+	"sdk":                 "dotnet", // mcr.microsoft.com/dotnet/sdk
+	"dotnet":              "dotnet",
+}
+
+// jdkTag matches the JDK a Maven or Gradle image tag ships with, e.g. 3.9-eclipse-temurin-17
+var jdkTag = regexp.MustCompile(`(?:jdk|temurin|corretto|zulu|openjdk|java|semeru)-?(\d+)`)
+
+// imageTools returns the tools a base image provides, with the versions its tag pins
+func imageTools(image string) []Install {
+	image, _, _ = strings.Cut(image, "@")
+	repo, tag := image, ""
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		repo, tag = image[:i], image[i+1:]
+	}
+	name := strings.ToLower(repo[strings.LastIndex(repo, "/")+1:])
+	tool, ok := imageRepos[name]
+	if !ok || (name == "sdk" && !strings.Contains(repo, "dotnet")) {
+		return nil
+	}
+
+	installs := []Install{{Tool: tool, Version: leadingVersion(tag)}}
+	if tool == "maven" || tool == "gradle" {
+		if match := jdkTag.FindStringSubmatch(tag); match != nil {
+			installs = append(installs, Install{Tool: "java", Version: match[1]})
+		}
+	}
+	return installs
+}
+
+// installPattern finds a tool version installed by a RUN step; tool is empty when the first
+// group names it, and the last group is the version
+type installPattern struct {
+	tool string
+	re   *regexp.Regexp
+}
+
+var installPatterns = []installPattern{
+	{"node", regexp.MustCompile(`\bnvm install v?(\d[\d.]*)`)},
+	{"node", regexp.MustCompile(`nodesource\.com/setup_(\d+)\.x`)},
+	{"node", regexp.MustCompile(`\bnodejs=(\d[\d.]*)`)},
+	{"", regexp.MustCompile(`\bsdk install (java|maven|gradle) (\d[\w.\-]*)`)},
+	{"java", regexp.MustCompile(`\b(?:openjdk|temurin|zulu|java)-?(\d+)-jd[kr]`)},
+	{"python", regexp.MustCompile(`\bapt(?:-get)? install[^&;|]*\bpython(3\.\d+)\b`)},
+	{"go", regexp.MustCompile(`golang-(1\.\d+)\b`)},
+	{"go", regexp.MustCompile(`go(1\.\d+(?:\.\d+)?)\.linux`)},
+	{"", regexp.MustCompile(`\b(?:npm (?:install|i) (?:-g|--global)|corepack prepare)\b[^&;|]*?\b(pnpm|yarn|npm)@(\d[\d.]*)`)},
+	{"", regexp.MustCompile(`\bpip3? install[^&;|]*?\b(poetry|pipenv)==(\d[\d.]*)`)},
+}
+
+// leadingVersionRe matches a version at the start of a tag or value
+var leadingVersionRe = regexp.MustCompile(`^v?(\d+(?:\.\d+)*)`)
+
+// leadingVersion returns the version at the start of a tag or value, e.g. "20" of "20-alpine"
+// and "17.0.9" of "17.0.9+9", or "" when it doesn't start with one
+func leadingVersion(s string) string {
+	if match := leadingVersionRe.FindStringSubmatch(s); match != nil {
+		return match[1]
+	}
+	return ""
+}
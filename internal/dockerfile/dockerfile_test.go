@@ -0,0 +1,129 @@
+package dockerfile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestFindDockerfiles(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "Dockerfile"), "FROM node:20\n")
+	assert.Equal(t, []string{filepath.Join(root, "Dockerfile")}, FindDockerfiles(root), "the only Dockerfile is used for dev")
+
+	writeFile(t, filepath.Join(root, "Dockerfile.dev"), "FROM node:20\n")
+	writeFile(t, filepath.Join(root, ".devcontainer", "Dockerfile"), "FROM node:20\n")
+	writeFile(t, filepath.Join(root, "docker", "api.Dockerfile"), "FROM node:20\n")
+	assert.Equal(t, []string{
+		filepath.Join(root, "Dockerfile.dev"),
+		filepath.Join(root, ".devcontainer", "Dockerfile"),
+	}, FindDockerfiles(root))
+}
+
+func TestParse(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "Dockerfile")
+	writeFile(t, file, `# Development image
+ARG JAVA_VERSION=17
+FROM maven:3.9.6-eclipse-temurin-${JAVA_VERSION} AS build
+FROM build AS dev
+ENV NODE_VERSION 20.11.0
+RUN curl -fsSL https://deb.nodesource.com/setup_20.x | bash - \
+    && apt-get install -y python3.11 nodejs \
+    && npm install -g pnpm@8.15.1
+RUN sdk install gradle 8.5
+FROM --platform=linux/amd64 python
+`)
+	installs, err := Parse(file)
+	require.NoError(t, err)
+
+	var got []string
+	for _, install := range installs {
+		got = append(got, fmt.Sprintf("%s %s @%d", install.Tool, install.Version, install.Line))
+	}
+	assert.Equal(t, []string{
+		"java 17 @2",
+		"maven 3.9.6 @3",
+		"java 17 @3",
+		"node 20.11.0 @5",
+		"node 20 @6",
+		"python 3.11 @6",
+		"pnpm 8.15.1 @6",
+		"gradle 8.5 @9",
+		"python  @10",
+	}, got)
+	assert.Equal(t, "FROM maven:3.9.6-eclipse-temurin-${JAVA_VERSION} AS build", installs[1].Source)
+}
+
+func TestImageTools(t *testing.T) {
+	assert.Equal(t, []Install{{Tool: "node", Version: "18"}}, imageTools("docker.io/library/node:18-alpine@sha256:abc"))
+	assert.Equal(t, []Install{{Tool: "dotnet", Version: "8.0"}}, imageTools("mcr.microsoft.com/dotnet/sdk:8.0"))
+	assert.Equal(t, []Install{{Tool: "gradle", Version: "8.5"}, {Tool: "java", Version: "21"}}, imageTools("gradle:8.5-jdk21"))
+	assert.Equal(t, []Install{{Tool: "node", Version: ""}}, imageTools("node:lts"))
+	assert.Nil(t, imageTools("registry.local:5000/sdk:1"))
+	assert.Nil(t, imageTools("ubuntu:22.04"))
+}
+
+func TestCheckParity(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "Dockerfile.dev"), `FROM eclipse-temurin:8-jdk
+RUN npm i -g yarn@1.22.19
+FROM node:latest
+FROM python:3.11-slim
+`)
+	defer func(f func(context.Context, string) (string, error)) { localVersion = f }(localVersion)
+	localVersion = func(ctx context.Context, tool string) (string, error) {
+		switch tool {
+		case "java":
+			return "17.0.9", nil
+		case "node":
+			return "20.11.0", nil
+		case "python":
+			return "3.11.7", nil
+		}
+		return "", fmt.Errorf("%s: command not found", tool)
+	}
+	ecosystems := []*detector.DetectedEcosystem{{ID: "java-maven", Config: &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		ID:            "java-maven",
+		VersionConfig: config.VersionConfig{Language: "java"},
+		Requirements:  config.Requirements{MinVersion: "11"},
+	}}}}
+
+	report, err := CheckParity(context.Background(), root, ecosystems)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Dockerfile.dev"}, report.Dockerfiles)
+	assert.Len(t, report.Tools, 4)
+	assert.False(t, report.IsHealthy)
+
+	types := map[string]Issue{}
+	for _, issue := range report.Issues {
+		types[issue.Type+" "+issue.Tool] = issue
+	}
+	assert.Len(t, types, 4, "python 3.11 matches 3.11.7")
+	mismatch := types[IssueVersionMismatch+" java"]
+	assert.Equal(t, "error", mismatch.Severity)
+	assert.Equal(t, "Dockerfile.dev:1 installs java 8 (FROM eclipse-temurin:8-jdk) but java 17.0.9 is installed locally", mismatch.Message)
+	assert.Contains(t, types[IssueRequirement+" java"].Message, "outside what java-maven requires: Version 8 is below minimum required 11")
+	assert.Contains(t, types[IssueMissingLocally+" yarn"].Message, "installs yarn 1.22.19 (RUN npm i -g yarn@1.22.19) but yarn is not installed locally")
+	assert.Contains(t, types[IssueUnpinned+" node"].Message, "pin it to the local 20.11.0, e.g. a tag starting with 20.11")
+}
+
+func TestSameVersion(t *testing.T) {
+	assert.True(t, sameVersion("20", "20.11.0"))
+	assert.True(t, sameVersion("3.9", "3.9.6"))
+	assert.False(t, sameVersion("3.9.6", "3.9.5"))
+	assert.False(t, sameVersion("3.9.6", "3.9"))
+	assert.Equal(t, "8.0", normalize("java", "1.8.0"))
+}
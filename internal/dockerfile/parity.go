@@ -0,0 +1,209 @@
+package dockerfile
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/version"
+)
+
+// Issue types reported by the Dockerfile parity check
+const (
+	IssueVersionMismatch = "dockerfile_version_mismatch"     // The container and this machine run different versions
+	IssueMissingLocally  = "dockerfile_tool_missing_locally" // The container has a tool this machine lacks
+	IssueRequirement     = "dockerfile_requirement"          // The container's version is outside the project's requirements
+	IssueUnpinned        = "dockerfile_unpinned"             // A base image tag doesn't pin the tool's version
+)
+
+// Tool is a tool a Dockerfile installs, with the version installed on this machine
+type Tool struct {
+	Install
+	Local string // Empty when the tool isn't installed locally
+}
+
+// Issue is a divergence between a Dockerfile and the local environment
+type Issue struct {
+	Type     string
+	Severity string // "error" or "warning"
+	Tool     string
+	Message  string
+	File     string
+	Line     int
+}
+
+// ParityReport contains the Dockerfile parity check results
+type ParityReport struct {
+	ProjectRoot string
+	Dockerfiles []string
+	Tools       []Tool
+	IsHealthy   bool
+	Issues      []Issue
+}
+
+// toolLanguages are the version_config languages whose requirements apply to a tool
+var toolLanguages = map[string][]string{
+	"node":   {"javascript", "typescript", "node"},
+	"java":   {"java"},
+	"python": {"python"},
+	"go":     {"go"},
+	"dotnet": {"csharp", "dotnet"},
+	"ruby":   {"ruby"},
+	"php":    {"php"},
+	"rust":   {"rust"},
+}
+
+// localVersion returns the version of a tool installed on this machine; tests replace it
+var localVersion = version.DetectToolVersion
+
+// CheckParity compares the tool versions the project's development Dockerfiles install with
+// the versions installed on this machine and with the requirements of the detected ecosystems
+func CheckParity(ctx context.Context, projectRoot string, ecosystems []*detector.DetectedEcosystem) (*ParityReport, error) {
+	report := &ParityReport{
+		ProjectRoot: projectRoot,
+		Dockerfiles: []string{},
+		Tools:       []Tool{},
+		IsHealthy:   true,
+		Issues:      []Issue{},
+	}
+
+	locals := make(map[string]string)
+	local := func(tool string) string {
+		if v, ok := locals[tool]; ok {
+			return v
+		}
+		v, err := localVersion(ctx, tool)
+		if err != nil {
+			v = ""
+		}
+		locals[tool] = normalize(tool, v)
+		return locals[tool]
+	}
+
+	for _, file := range FindDockerfiles(projectRoot) {
+		installs, err := Parse(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		rel, _ := filepath.Rel(projectRoot, file)
+		report.Dockerfiles = append(report.Dockerfiles, rel)
+
+		seen := make(map[string]bool)
+		for _, install := range installs {
+			install.Version = normalize(install.Tool, install.Version)
+			install.File = rel
+			if key := install.Tool + "@" + install.Version; seen[key] {
+				continue // e.g. an ARG pinning the version of the base image
+			} else {
+				seen[key] = true
+			}
+			tool := Tool{Install: install, Local: local(install.Tool)}
+			report.Tools = append(report.Tools, tool)
+			report.compare(rel, tool, ecosystems)
+		}
+	}
+	return report, nil
+}
+
+// compare records the divergences of one tool a Dockerfile installs
+func (r *ParityReport) compare(file string, tool Tool, ecosystems []*detector.DetectedEcosystem) {
+	where := fmt.Sprintf("%s:%d", file, tool.Line)
+	issue := Issue{Tool: tool.Tool, File: file, Line: tool.Line}
+
+	if tool.Version == "" {
+		if strings.HasPrefix(tool.Source, "FROM ") {
+			issue.Type, issue.Severity = IssueUnpinned, "warning"
+			issue.Message = fmt.Sprintf("%s doesn't pin the %s version (%s), so the container gets whatever the tag points to", where, tool.Tool, tool.Source)
+			if tool.Local != "" {
+				issue.Message += fmt.Sprintf("; pin it to the local %s, e.g. a tag starting with %s", tool.Local, majorMinor(tool.Local))
+			}
+			r.add(issue)
+		}
+		return
+	}
+
+	switch {
+	case tool.Local == "":
+		issue.Type, issue.Severity = IssueMissingLocally, "warning"
+		issue.Message = fmt.Sprintf("%s installs %s %s (%s) but %s is not installed locally", where, tool.Tool, tool.Version, tool.Source, tool.Tool)
+		r.add(issue)
+	case !sameVersion(tool.Version, tool.Local):
+		issue.Type, issue.Severity = IssueVersionMismatch, "warning"
+		if major(tool.Version) != major(tool.Local) {
+			issue.Severity = "error"
+		}
+		issue.Message = fmt.Sprintf("%s installs %s %s (%s) but %s %s is installed locally", where, tool.Tool, tool.Version, tool.Source, tool.Tool, tool.Local)
+		r.add(issue)
+	}
+
+	for _, eco := range ecosystems {
+		cfg := eco.Config
+		if !contains(toolLanguages[tool.Tool], cfg.Ecosystem.VersionConfig.Language) {
+			continue
+		}
+		validation := version.ValidateVersion(&version.VersionInfo{Language: tool.Tool, Version: tool.Version}, cfg)
+		for _, problem := range validation.Issues {
+			r.add(Issue{
+				Type:     IssueRequirement,
+				Severity: "error",
+				Tool:     tool.Tool,
+				Message:  fmt.Sprintf("%s installs %s %s, outside what %s requires: %s", where, tool.Tool, tool.Version, eco.ID, problem.Message),
+				File:     file,
+				Line:     tool.Line,
+			})
+		}
+	}
+}
+
+// add records an issue and marks the report unhealthy
+func (r *ParityReport) add(issue Issue) {
+	r.Issues = append(r.Issues, issue)
+	r.IsHealthy = false
+}
+
+// normalize drops the "1." of legacy Java versions, so 1.8.0 compares as 8.0
+func normalize(tool, v string) string {
+	if tool == "java" && strings.HasPrefix(v, "1.") && v != "1" {
+		return strings.TrimPrefix(v, "1.")
+	}
+	return v
+}
+
+// sameVersion reports whether a local version satisfies a pinned one: "20" matches 20.11.0,
+// "3.9" matches 3.9.6 and "3.9.6" only itself
+func sameVersion(pinned, local string) bool {
+	localParts := strings.Split(local, ".")
+	for i, part := range strings.Split(pinned, ".") {
+		if i >= len(localParts) || strings.TrimLeft(part, "0") != strings.TrimLeft(localParts[i], "0") {
+			return false
+		}
+	}
+	return true
+}
+
+// major returns the major version of a version
+func major(v string) string {
+	major, _, _ := strings.Cut(v, ".")
+	return major
+}
+
+// majorMinor returns the major and minor version of a version
+func majorMinor(v string) string {
+	parts := strings.SplitN(v, ".", 3)
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+	return strings.Join(parts, ".")
+}
+
+// contains reports whether a list contains a string
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,334 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/license"
+	"dev-env-sentinel/internal/settings"
+	"dev-env-sentinel/internal/state"
+)
+
+// Status of a single doctor check
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Check is the result of one readiness check
+type Check struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+// Report is the sentinel's readiness report
+type Report struct {
+	Checks  []Check `json:"checks"`
+	IsReady bool    `json:"is_ready"`
+}
+
+// Options configures where the doctor looks for the sentinel's own files
+type Options struct {
+	ConfigBaseDir string // Directory containing config/ and sentinel.yaml
+	StateRoot     string // User state directory
+}
+
+// dockerDialTimeout bounds the docker socket probe
+const dockerDialTimeout = 2 * time.Second
+
+// Run checks the sentinel's own prerequisites. The report is ready when no check failed.
+func Run(ctx context.Context, opts Options) *Report {
+	report := &Report{IsReady: true}
+
+	configs, configCheck := checkConfigs(opts.ConfigBaseDir)
+	report.add(configCheck)
+	report.add(checkConfigFiles(opts.ConfigBaseDir))
+	report.add(checkSettings(opts.ConfigBaseDir))
+	report.add(checkStateDir(opts.StateRoot))
+	report.add(checkShell())
+	if dockerEnabled(configs) {
+		report.add(checkDocker(ctx))
+	}
+	report.add(checkLicense())
+
+	return report
+}
+
+// add appends a check and updates readiness
+func (r *Report) add(check Check) {
+	if check.Status == StatusFail {
+		r.IsReady = false
+	}
+	r.Checks = append(r.Checks, check)
+}
+
+// checkConfigs verifies the config directory exists and yields ecosystem configs
+func checkConfigs(baseDir string) ([]*config.EcosystemConfig, Check) {
+	check := Check{Name: "config directory"}
+
+	// Config warnings are reported by the config files check
+	configs, _, err := config.DiscoverEcosystemConfigsWithWarnings(baseDir)
+	if err != nil {
+		check.Status = StatusFail
+		check.Message = fmt.Sprintf("failed to load configs from %s: %v", baseDir, err)
+		check.Hint = "Set SENTINEL_CONFIG_DIR to the directory containing config/"
+		return nil, check
+	}
+	if len(configs) == 0 {
+		check.Status = StatusFail
+		check.Message = fmt.Sprintf("no ecosystem configs found in %s", baseDir)
+		check.Hint = "Set SENTINEL_CONFIG_DIR to the directory containing config/"
+		return nil, check
+	}
+
+	check.Status = StatusOK
+	check.Message = fmt.Sprintf("%d ecosystem configs loaded from %s", len(configs), baseDir)
+	return configs, check
+}
+
+// checkConfigFiles parses every YAML file under config/ and reports the ones that
+// discovery silently skips because they fail to load
+func checkConfigFiles(baseDir string) Check {
+	check := Check{Name: "config files", Status: StatusOK}
+	configDir := filepath.Join(baseDir, "config")
+
+	var paths []string
+	filepath.Walk(configDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		paths = append(paths, path)
+		return nil
+	})
+
+	// Files are loaded together so configs can extend one another
+	_, errs := config.LoadEcosystemConfigs(paths)
+	var failed, unknown []string
+	for _, path := range paths {
+		rel, _ := filepath.Rel(baseDir, path)
+		if err := errs[path]; err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", rel, err))
+			continue
+		}
+		warnings, _ := config.UnknownKeys(path)
+		for _, w := range warnings {
+			w.File = rel
+			unknown = append(unknown, w.String())
+		}
+	}
+
+	total := len(paths)
+	if total == 0 {
+		check.Message = "no config files to parse"
+		return check
+	}
+	if len(failed) > 0 {
+		check.Status = StatusWarn
+		check.Message = fmt.Sprintf("%d of %d config files failed to parse and are ignored:\n%s", len(failed), total, strings.Join(failed, "\n"))
+		check.Hint = "Fix the YAML errors above; broken configs are skipped at startup"
+		return check
+	}
+	if len(unknown) > 0 {
+		check.Status = StatusWarn
+		check.Message = fmt.Sprintf("%d unknown config key(s) are ignored:\n%s", len(unknown), strings.Join(unknown, "\n"))
+		check.Hint = "Check the keys above for typos against docs/architecture/configuration-schema.md"
+		return check
+	}
+
+	check.Message = fmt.Sprintf("all %d config files parse", total)
+	return check
+}
+
+// checkSettings verifies the server settings file, if any, is valid
+func checkSettings(baseDir string) Check {
+	check := Check{Name: "server settings"}
+
+	s, err := settings.Discover(baseDir)
+	if err != nil {
+		check.Status = StatusFail
+		check.Message = err.Error()
+		check.Hint = "Fix sentinel.yaml or point SENTINEL_SETTINGS at a valid file"
+		return check
+	}
+
+	check.Status = StatusOK
+	check.Message = fmt.Sprintf("%d scheduled checks, %d notification sinks", len(s.Schedule), len(s.Notifications.Sinks))
+	return check
+}
+
+// checkStateDir verifies the state directory can be opened and written
+func checkStateDir(root string) Check {
+	check := Check{Name: "state directory"}
+
+	dir, err := state.Open(root)
+	if err != nil {
+		check.Status = StatusFail
+		check.Message = err.Error()
+		check.Hint = "Make the directory writable or set SENTINEL_STATE_DIR"
+		return check
+	}
+
+	probe := filepath.Join(dir.Root(), ".doctor-write-test")
+	if err := os.WriteFile(probe, []byte("ok"), 0644); err != nil {
+		check.Status = StatusFail
+		check.Message = fmt.Sprintf("%s is not writable: %v", dir.Root(), err)
+		check.Hint = "Make the directory writable or set SENTINEL_STATE_DIR"
+		return check
+	}
+	os.Remove(probe)
+
+	check.Status = StatusOK
+	check.Message = fmt.Sprintf("%s is writable", dir.Root())
+	return check
+}
+
+// checkShell verifies that sh, used to run every configured command, is available
+func checkShell() Check {
+	check := Check{Name: "shell"}
+
+	path, err := exec.LookPath("sh")
+	if err != nil {
+		check.Status = StatusFail
+		check.Message = "sh not found on PATH; configured check and fix commands cannot run"
+		if runtime.GOOS == "windows" {
+			check.Hint = "Install Git for Windows (Git Bash) or run the sentinel inside WSL"
+		} else {
+			check.Hint = "Install a POSIX shell and make sure it is on PATH"
+		}
+		return check
+	}
+
+	check.Status = StatusOK
+	check.Message = path
+	return check
+}
+
+// dockerEnabled reports whether any loaded config relies on docker
+func dockerEnabled(configs []*config.EcosystemConfig) bool {
+	for _, cfg := range configs {
+		if strings.Contains(cfg.Ecosystem.ID, "docker") {
+			return true
+		}
+		for _, service := range cfg.Ecosystem.Infrastructure.Services {
+			if strings.Contains(service.CheckCommand, "docker") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkDocker verifies the docker daemon socket is reachable
+func checkDocker(ctx context.Context) Check {
+	check := Check{Name: "docker socket"}
+
+	network, address, err := dockerAddress(os.Getenv("DOCKER_HOST"), runtime.GOOS)
+	if err != nil {
+		check.Status = StatusWarn
+		check.Message = err.Error()
+		return check
+	}
+	if network == "" {
+		check.Status = StatusWarn
+		check.Message = "docker named pipes cannot be probed; run `docker info` to verify access"
+		return check
+	}
+
+	dialer := net.Dialer{Timeout: dockerDialTimeout}
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		check.Status = StatusWarn
+		check.Message = fmt.Sprintf("cannot connect to docker at %s: %v", address, err)
+		check.Hint = "Start the docker daemon and make sure your user can access the socket (e.g. the docker group)"
+		return check
+	}
+	conn.Close()
+
+	check.Status = StatusOK
+	check.Message = fmt.Sprintf("docker reachable at %s", address)
+	return check
+}
+
+// dockerAddress resolves the docker daemon address from DOCKER_HOST. An empty
+// network means the address is a Windows named pipe, which is not probed.
+func dockerAddress(dockerHost, goos string) (network, address string, err error) {
+	if dockerHost == "" {
+		if goos == "windows" {
+			return "", "", nil
+		}
+		return "unix", "/var/run/docker.sock", nil
+	}
+
+	u, err := url.Parse(dockerHost)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid DOCKER_HOST %q: %w", dockerHost, err)
+	}
+
+	switch u.Scheme {
+	case "unix":
+		return "unix", u.Path, nil
+	case "tcp":
+		return "tcp", u.Host, nil
+	case "npipe":
+		return "", "", nil
+	default:
+		return "", "", fmt.Errorf("unsupported DOCKER_HOST scheme %q", u.Scheme)
+	}
+}
+
+// checkLicense reports the license state the server will start with
+func checkLicense() Check {
+	check := Check{Name: "license"}
+
+	storage := license.NewStorage()
+	key, err := storage.LoadLicense()
+	if err != nil {
+		check.Status = StatusWarn
+		check.Message = fmt.Sprintf("stored license could not be read: %v", err)
+		check.Hint = "Re-activate your license with the activate_pro tool"
+		return check
+	}
+	if envKey := os.Getenv("SENTINEL_LICENSE_KEY"); envKey != "" {
+		key = envKey
+	}
+
+	lic, err := license.NewLicenseValidator().ValidateLicense(key)
+	if err != nil {
+		check.Status = StatusWarn
+		check.Message = fmt.Sprintf("license key is invalid (%v); running in free tier", err)
+		check.Hint = "Check the key with check_license_status or re-activate it"
+		if notice := license.RenewalNotice(lic, storage.LoadEntitlement(key), time.Now()); notice != "" {
+			check.Hint = notice
+		}
+		return check
+	}
+
+	check.Status = StatusOK
+	check.Message = fmt.Sprintf("%s tier", lic.Tier)
+	if lic.ExpiresAt != nil {
+		check.Message += fmt.Sprintf(", expires %s", lic.ExpiresAt.Format("2006-01-02"))
+	}
+	if notice := license.RenewalNotice(lic, nil, time.Now()); notice != "" {
+		check.Status = StatusWarn
+		check.Hint = notice
+	}
+	return check
+}
@@ -0,0 +1,149 @@
+package doctor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// configBaseDir creates a base directory with one valid and optionally one broken config
+func configBaseDir(t *testing.T, broken bool) string {
+	baseDir := t.TempDir()
+	langDir := filepath.Join(baseDir, "config", "languages")
+	require.NoError(t, os.MkdirAll(langDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(langDir, "go.yaml"),
+		[]byte("ecosystem:\n  id: go\n  manifest:\n    primary_file: go.mod\n"), 0644))
+	if broken {
+		require.NoError(t, os.WriteFile(filepath.Join(langDir, "broken.yaml"),
+			[]byte("ecosystem:\n  id: a\n  id: b\n"), 0644))
+	}
+	return baseDir
+}
+
+func findCheck(t *testing.T, report *Report, name string) Check {
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("check %q not in report", name)
+	return Check{}
+}
+
+func TestRun_Ready(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SENTINEL_SETTINGS", "")
+	t.Setenv("SENTINEL_LICENSE_KEY", "")
+
+	report := Run(context.Background(), Options{
+		ConfigBaseDir: configBaseDir(t, false),
+		StateRoot:     filepath.Join(t.TempDir(), "state"),
+	})
+
+	assert.True(t, report.IsReady)
+	assert.Equal(t, StatusOK, findCheck(t, report, "config directory").Status)
+	assert.Equal(t, StatusOK, findCheck(t, report, "config files").Status)
+	assert.Equal(t, StatusOK, findCheck(t, report, "state directory").Status)
+	assert.Equal(t, StatusOK, findCheck(t, report, "shell").Status)
+	assert.Equal(t, "free tier", findCheck(t, report, "license").Message)
+	for _, check := range report.Checks {
+		assert.NotEqual(t, "docker socket", check.Name, "docker is only checked when configs use it")
+	}
+}
+
+func TestRun_Problems(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SENTINEL_SETTINGS", "")
+	t.Setenv("SENTINEL_LICENSE_KEY", "not-a-valid")
+
+	baseDir := configBaseDir(t, true)
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "sentinel.yaml"), []byte("schedule:\n  - cron: '@hourly'\n"), 0644))
+
+	// A file where the state directory should be makes it unusable
+	stateRoot := filepath.Join(t.TempDir(), "state")
+	require.NoError(t, os.WriteFile(stateRoot, []byte("not a dir"), 0644))
+
+	report := Run(context.Background(), Options{ConfigBaseDir: baseDir, StateRoot: stateRoot})
+
+	assert.False(t, report.IsReady)
+	files := findCheck(t, report, "config files")
+	assert.Equal(t, StatusWarn, files.Status)
+	assert.Contains(t, files.Message, "broken.yaml")
+	assert.Equal(t, StatusFail, findCheck(t, report, "server settings").Status)
+	assert.Equal(t, StatusFail, findCheck(t, report, "state directory").Status)
+	assert.Equal(t, StatusWarn, findCheck(t, report, "license").Status)
+}
+
+func TestRun_UnknownConfigKeys(t *testing.T) {
+	baseDir := configBaseDir(t, false)
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "config", "languages", "rust.yaml"),
+		[]byte("ecosystem:\n  id: rust\n  manifest:\n    primary_file: Cargo.toml\n    requred_files: [Cargo.lock]\n"), 0644))
+
+	report := Run(context.Background(), Options{ConfigBaseDir: baseDir, StateRoot: t.TempDir()})
+
+	files := findCheck(t, report, "config files")
+	assert.Equal(t, StatusWarn, files.Status)
+	assert.Contains(t, files.Message, filepath.Join("config", "languages", "rust.yaml")+":5: unknown key requred_files")
+}
+
+func TestRun_MissingConfig(t *testing.T) {
+	report := Run(context.Background(), Options{
+		ConfigBaseDir: filepath.Join(t.TempDir(), "missing"),
+		StateRoot:     t.TempDir(),
+	})
+
+	assert.False(t, report.IsReady)
+	check := findCheck(t, report, "config directory")
+	assert.Equal(t, StatusFail, check.Status)
+	assert.Contains(t, check.Hint, "SENTINEL_CONFIG_DIR")
+}
+
+func TestDockerEnabled(t *testing.T) {
+	docker := &config.EcosystemConfig{}
+	docker.Ecosystem.ID = "docker"
+	service := &config.EcosystemConfig{}
+	service.Ecosystem.Infrastructure.Services = []config.Service{{Name: "db", CheckCommand: "docker ps"}}
+
+	assert.True(t, dockerEnabled([]*config.EcosystemConfig{docker}))
+	assert.True(t, dockerEnabled([]*config.EcosystemConfig{service}))
+	assert.False(t, dockerEnabled([]*config.EcosystemConfig{{}}))
+}
+
+func TestDockerAddress(t *testing.T) {
+	tests := []struct {
+		host    string
+		goos    string
+		network string
+		address string
+		wantErr bool
+	}{
+		{"", "linux", "unix", "/var/run/docker.sock", false},
+		{"", "windows", "", "", false},
+		{"unix:///run/user/1000/docker.sock", "linux", "unix", "/run/user/1000/docker.sock", false},
+		{"tcp://127.0.0.1:2375", "linux", "tcp", "127.0.0.1:2375", false},
+		{"npipe:////./pipe/docker_engine", "windows", "", "", false},
+		{"ssh://user@host", "linux", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			network, address, err := dockerAddress(tt.host, tt.goos)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.network, network)
+			assert.Equal(t, tt.address, address)
+		})
+	}
+}
@@ -2,36 +2,166 @@ package features
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"strings"
 
 	"dev-env-sentinel/internal/license"
 )
 
-// FeatureManager manages feature flags and license-based feature access
+// EnvVar overrides feature flags locally: comma-separated names to enable, "-name" to disable
+const EnvVar = "SENTINEL_FEATURES"
+
+// Sources a flag's state can come from, from lowest to highest precedence
+const (
+	SourceDefault = "default" // Not enabled anywhere
+	SourceTier    = "tier"    // Included in the license tier
+	SourceLicense = "license" // Granted by a claim of the license key
+	SourceTrial   = "trial"   // Unlocked by a running trial
+	SourceConfig  = "config"  // features in sentinel.yaml
+	SourceEnv     = "env"     // SENTINEL_FEATURES
+)
+
+// Flag is the effective state of a feature flag
+type Flag struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Source  string `json:"source"`
+	Paid    bool   `json:"paid,omitempty"` // Only a license or a trial can enable it
+	Note    string `json:"note,omitempty"` // E.g. why a local override was ignored
+}
+
+// FeatureManager manages feature flags and license-based feature access. Flags come from the
+// license (tier, key claims and trials) and can be overridden locally, so capabilities can be
+// rolled out independently of the tiers; local overrides can't enable paid features though.
 type FeatureManager struct {
 	license *license.License
+	config  map[string]bool // Overrides from the settings file
+	env     map[string]bool // Overrides from SENTINEL_FEATURES
 }
 
 // NewFeatureManager creates a new feature manager
 func NewFeatureManager(lic *license.License) *FeatureManager {
 	return &FeatureManager{
 		license: lic,
+		env:     ParseOverrides(os.Getenv(EnvVar)),
+	}
+}
+
+// SetOverrides sets the feature flag overrides of the settings file
+func (fm *FeatureManager) SetOverrides(overrides map[string]bool) {
+	fm.config = overrides
+}
+
+// ParseOverrides parses a SENTINEL_FEATURES value: "a,b,-c" enables a and b and disables c.
+// Invalid names are skipped.
+func ParseOverrides(s string) map[string]bool {
+	overrides := make(map[string]bool)
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		enabled := !strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+		if license.ValidFlagName(name) {
+			overrides[name] = enabled
+		}
 	}
+	return overrides
 }
 
-// IsEnabled checks if a feature is enabled for the current license
+// IsEnabled checks if a feature is enabled
 func (fm *FeatureManager) IsEnabled(feature string) bool {
-	if fm.license == nil {
-		return false
+	return fm.Flag(feature).Enabled
+}
+
+// Flag resolves the effective state of a feature flag and where it comes from. An override
+// that doesn't change the state leaves the source as it is.
+func (fm *FeatureManager) Flag(name string) Flag {
+	flag := fm.licensed(name)
+	overrides := []struct {
+		source string
+		values map[string]bool
+	}{
+		{SourceConfig, fm.config},
+		{SourceEnv, fm.env},
+	}
+	for _, o := range overrides {
+		enabled, ok := o.values[name]
+		if !ok || enabled == flag.Enabled {
+			continue
+		}
+		if enabled && flag.Paid && !flag.Enabled {
+			flag.Note = fmt.Sprintf("%s override ignored: needs a license or a trial", o.source)
+			continue
+		}
+		flag.Enabled = enabled
+		flag.Source = o.source
+		flag.Note = ""
+	}
+	return flag
+}
+
+// licensed resolves a flag from the license alone
+func (fm *FeatureManager) licensed(name string) Flag {
+	flag := Flag{Name: name, Source: SourceDefault, Paid: isPaid(name)}
+	lic := fm.license
+	if lic == nil {
+		return flag
+	}
+	switch {
+	case lic.TrialFor(name) != nil:
+		flag.Enabled, flag.Source = true, SourceTrial
+	case lic.IsValid && contains(lic.Claims, name):
+		flag.Enabled, flag.Source = true, SourceLicense
+	case contains(lic.Features, name) && (lic.IsValid || !flag.Paid):
+		flag.Enabled, flag.Source = true, SourceTier
 	}
-	return fm.license.HasFeature(feature)
+	return flag
+}
+
+// Flags returns the effective state of every known flag, sorted by name: the features of all
+// tiers, and the flags named by the license, trials or overrides
+func (fm *FeatureManager) Flags() []Flag {
+	names := make(map[string]bool)
+	for _, f := range license.PaidFeatures() {
+		names[f] = true
+	}
+	if fm.license != nil {
+		for _, f := range fm.license.Features {
+			names[f] = true
+		}
+		for _, f := range fm.license.Claims {
+			names[f] = true
+		}
+		for _, t := range fm.license.Trials {
+			names[t.Feature] = true
+		}
+	}
+	for name := range fm.config {
+		names[name] = true
+	}
+	for name := range fm.env {
+		names[name] = true
+	}
+
+	flags := make([]Flag, 0, len(names))
+	for name := range names {
+		flags = append(flags, fm.Flag(name))
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	return flags
 }
 
 // RequireFeature returns an error if the feature is not available
 func (fm *FeatureManager) RequireFeature(feature string) error {
-	if !fm.IsEnabled(feature) {
+	if flag := fm.Flag(feature); !flag.Enabled {
+		tier := ""
+		if fm.license != nil {
+			tier = fm.license.Tier
+		}
 		return &FeatureNotAvailableError{
 			Feature: feature,
-			Tier:    fm.license.Tier,
+			Tier:    tier,
+			Source:  flag.Source,
 		}
 	}
 	return nil
@@ -41,7 +171,7 @@ func (fm *FeatureManager) RequireFeature(feature string) error {
 func (fm *FeatureManager) GetUpgradeMessage(feature string) string {
 	stripeLink := license.GetStripePaymentLink()
 	apifyURL := license.GetApifyActorURL()
-	
+
 	return fmt.Sprintf(
 		"The feature '%s' is only available in the Pro tier. "+
 			"To unlock auto-fixes and advanced features, purchase a license:\n\n"+
@@ -56,9 +186,27 @@ func (fm *FeatureManager) GetUpgradeMessage(feature string) string {
 type FeatureNotAvailableError struct {
 	Feature string
 	Tier    string
+	Source  string // Where the flag was turned off, e.g. "config"; "default" or "tier" for the license
 }
 
 func (e *FeatureNotAvailableError) Error() string {
+	if e.Source == SourceConfig || e.Source == SourceEnv {
+		return fmt.Sprintf("feature '%s' is disabled by a local override (%s)", e.Feature, e.Source)
+	}
 	return fmt.Sprintf("feature '%s' is not available in tier '%s'", e.Feature, e.Tier)
 }
 
+// isPaid reports whether only a license or a trial can enable a feature
+func isPaid(feature string) bool {
+	return contains(license.PaidFeatures(), feature)
+}
+
+// contains reports whether list holds s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
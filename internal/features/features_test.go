@@ -2,6 +2,7 @@ package features
 
 import (
 	"testing"
+	"time"
 
 	"dev-env-sentinel/internal/license"
 	"github.com/stretchr/testify/assert"
@@ -143,3 +144,61 @@ func TestFeatureNotAvailableError(t *testing.T) {
 	assert.Contains(t, msg, "free")
 }
 
+
+func TestFlag_Sources(t *testing.T) {
+	t.Setenv(EnvVar, "new_dashboard,-env_var_audit,docker_orchestration")
+	now := time.Now()
+	lic := &license.License{
+		IsValid:  true,
+		Tier:     "pro",
+		Features: []string{"env_var_audit", "reconcile_environment"},
+		Claims:   []string{"lsp_code_actions"},
+		Trials:   []license.Trial{{Feature: "auto_fix", StartedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)}},
+	}
+	fm := NewFeatureManager(lic)
+	fm.SetOverrides(map[string]bool{"reconcile_environment": false, "new_dashboard": false, "beta_ui": true})
+
+	tests := []struct {
+		name    string
+		enabled bool
+		source  string
+	}{
+		{"reconcile_environment", false, SourceConfig},
+		{"auto_fix", true, SourceTrial},
+		{"lsp_code_actions", true, SourceLicense},
+		{"env_var_audit", false, SourceEnv},
+		{"new_dashboard", true, SourceEnv},
+		{"beta_ui", true, SourceConfig},
+		{"advanced_diagnostics", false, SourceDefault},
+		{"unknown_flag", false, SourceDefault},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flag := fm.Flag(tt.name)
+			assert.Equal(t, tt.enabled, flag.Enabled)
+			assert.Equal(t, tt.source, flag.Source)
+		})
+	}
+
+	docker := fm.Flag("docker_orchestration")
+	assert.False(t, docker.Enabled, "local overrides can't enable paid features")
+	assert.True(t, docker.Paid)
+	assert.Contains(t, docker.Note, "needs a license")
+
+	err := fm.RequireFeature("reconcile_environment")
+	assert.ErrorContains(t, err, "disabled by a local override (config)")
+
+	var names []string
+	for _, flag := range fm.Flags() {
+		names = append(names, flag.Name)
+	}
+	assert.IsIncreasing(t, names)
+	assert.Contains(t, names, "beta_ui")
+	assert.Contains(t, names, "lsp_code_actions")
+	assert.Contains(t, names, "custom_configs")
+}
+
+func TestParseOverrides(t *testing.T) {
+	assert.Equal(t, map[string]bool{"a": true, "b_2": false}, ParseOverrides(" a, -b_2 ,,Bad-Name"))
+	assert.Empty(t, ParseOverrides(""))
+}
@@ -0,0 +1,160 @@
+package flaky
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/runner"
+	"dev-env-sentinel/internal/snapshot"
+)
+
+const (
+	// MinFlips is the number of health changes at one source revision that makes a check flaky.
+	// A single outage and recovery is two flips; a third means the component keeps changing.
+	MinFlips = 3
+	// MinFlakeRate is the share of comparable consecutive runs that must flip
+	MinFlakeRate = 0.1
+)
+
+// Component is a check of a scheduled job and how often its result flips between runs
+type Component struct {
+	Job         string
+	ProjectRoot string
+	Check       string
+	Runs        int
+	Failures    int
+	Transitions int // Consecutive run pairs at the same source revision
+	Flips       int // Transitions where the health changed
+	FlakeRate   float64
+	Flaky       bool
+	LastFlip    time.Time
+	Daily       []Period // Flake rate over time, oldest first
+}
+
+// Period is the flip count of a component for one day
+type Period struct {
+	Day         time.Time
+	Transitions int
+	Flips       int
+}
+
+// FlakyReport lists the checks of the run history ordered by flake rate
+type FlakyReport struct {
+	Since      time.Time
+	Runs       int
+	Components []Component
+}
+
+// Flaky returns the components classified as flaky
+func (r *FlakyReport) Flaky() []Component {
+	var flaky []Component
+	for _, c := range r.Components {
+		if c.Flaky {
+			flaky = append(flaky, c)
+		}
+	}
+	return flaky
+}
+
+// Analyze finds checks whose result flips between consecutive runs although the source
+// did not change. Entries must be ordered oldest first; entries before since are ignored.
+// Runs without a recorded revision are compared with each other, since no source change
+// can be attributed to them.
+func Analyze(entries []snapshot.Entry, since time.Time) *FlakyReport {
+	report := &FlakyReport{Since: since, Components: []Component{}}
+
+	index := make(map[string]int)
+	previous := make(map[string]snapshot.Entry)
+	days := make(map[string]map[string]*Period)
+
+	for _, entry := range entries {
+		if entry.Timestamp.Before(since) {
+			continue
+		}
+		report.Runs++
+
+		key := entry.Job + "/" + entry.Check
+		i, ok := index[key]
+		if !ok {
+			i = len(report.Components)
+			index[key] = i
+			report.Components = append(report.Components, Component{Job: entry.Job, ProjectRoot: entry.ProjectRoot, Check: entry.Check})
+			days[key] = make(map[string]*Period)
+		}
+		c := &report.Components[i]
+		c.Runs++
+		if !healthy(entry) {
+			c.Failures++
+		}
+
+		last, seen := previous[key]
+		previous[key] = entry
+		if !seen || last.Revision != entry.Revision {
+			continue
+		}
+
+		day := entry.Timestamp.UTC().Truncate(24 * time.Hour)
+		period, ok := days[key][day.Format("2006-01-02")]
+		if !ok {
+			period = &Period{Day: day}
+			days[key][day.Format("2006-01-02")] = period
+		}
+
+		c.Transitions++
+		period.Transitions++
+		if healthy(last) != healthy(entry) {
+			c.Flips++
+			period.Flips++
+			c.LastFlip = entry.Timestamp
+		}
+	}
+
+	for i := range report.Components {
+		c := &report.Components[i]
+		if c.Transitions > 0 {
+			c.FlakeRate = float64(c.Flips) / float64(c.Transitions)
+		}
+		c.Flaky = c.Flips >= MinFlips && c.FlakeRate >= MinFlakeRate
+
+		for _, period := range days[c.Job+"/"+c.Check] {
+			c.Daily = append(c.Daily, *period)
+		}
+		sort.Slice(c.Daily, func(a, b int) bool { return c.Daily[a].Day.Before(c.Daily[b].Day) })
+	}
+
+	sort.SliceStable(report.Components, func(a, b int) bool {
+		return report.Components[a].FlakeRate > report.Components[b].FlakeRate
+	})
+	return report
+}
+
+// healthy reports whether a recorded check result is healthy
+func healthy(entry snapshot.Entry) bool {
+	return entry.Error == "" && entry.Healthy
+}
+
+// Revision identifies the state of the project source: the git HEAD commit plus a
+// hash of uncommitted changes. It returns "" outside a git repository.
+func Revision(ctx context.Context, projectRoot string) string {
+	head, err := runner.Run(ctx, projectRoot, "git rev-parse HEAD")
+	if err != nil {
+		return ""
+	}
+	revision := strings.TrimSpace(string(head))
+
+	// Untracked files only appear in the status, content changes only in the diff
+	status, _ := runner.Run(ctx, projectRoot, "git status --porcelain")
+	if len(strings.TrimSpace(string(status))) == 0 {
+		return revision
+	}
+	diff, _ := runner.Run(ctx, projectRoot, "git diff HEAD")
+
+	h := sha256.New()
+	h.Write(status)
+	h.Write(diff)
+	return revision + "+" + hex.EncodeToString(h.Sum(nil))[:12]
+}
@@ -0,0 +1,120 @@
+package flaky
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/snapshot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runs builds a history of one check from a sequence of results, one per hour
+func runs(start time.Time, check, revision string, results ...bool) []snapshot.Entry {
+	var entries []snapshot.Entry
+	for i, healthy := range results {
+		entries = append(entries, snapshot.Entry{
+			Job:       "api",
+			Check:     check,
+			Healthy:   healthy,
+			Revision:  revision,
+			Timestamp: start.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	return entries
+}
+
+func TestAnalyze(t *testing.T) {
+	start := time.Date(2026, 10, 1, 20, 0, 0, 0, time.UTC)
+
+	var history []snapshot.Entry
+	history = append(history, runs(start, "check_infrastructure_parity", "abc", true, false, true, true, false, true)...)
+	history = append(history, runs(start, "env_var_audit", "abc", true, true, true, true, true, true)...)
+	history = append(history, runs(start, "verify_build_freshness", "abc", true, false, false, false, false, true)...)
+
+	// Every result change coincides with a source change
+	history = append(history, runs(start, "check_locale", "r1", true)...)
+	history = append(history, runs(start.Add(time.Hour), "check_locale", "r2", false)...)
+	history = append(history, runs(start.Add(2*time.Hour), "check_locale", "r3", true)...)
+	history = append(history, runs(start.Add(3*time.Hour), "check_locale", "r4", false)...)
+
+	report := Analyze(history, time.Time{})
+	assert.Equal(t, len(history), report.Runs)
+	require.Len(t, report.Components, 4)
+
+	infra := report.Components[0]
+	assert.Equal(t, "check_infrastructure_parity", infra.Check)
+	assert.Equal(t, 6, infra.Runs)
+	assert.Equal(t, 2, infra.Failures)
+	assert.Equal(t, 5, infra.Transitions)
+	assert.Equal(t, 4, infra.Flips)
+	assert.InDelta(t, 0.8, infra.FlakeRate, 0.001)
+	assert.True(t, infra.Flaky)
+	assert.Equal(t, start.Add(5*time.Hour), infra.LastFlip)
+
+	// The runs span two days
+	require.Len(t, infra.Daily, 2)
+	assert.Equal(t, Period{Day: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), Transitions: 3, Flips: 2}, infra.Daily[0])
+	assert.Equal(t, Period{Day: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), Transitions: 2, Flips: 2}, infra.Daily[1])
+
+	byCheck := make(map[string]Component)
+	for _, c := range report.Components {
+		byCheck[c.Check] = c
+	}
+	assert.False(t, byCheck["verify_build_freshness"].Flaky, "one outage and recovery is not flaky")
+	assert.Equal(t, 2, byCheck["verify_build_freshness"].Flips)
+	assert.False(t, byCheck["check_locale"].Flaky, "changes explained by source changes")
+	assert.Zero(t, byCheck["check_locale"].Transitions)
+	assert.Zero(t, byCheck["env_var_audit"].Flips)
+
+	assert.Equal(t, []Component{infra}, report.Flaky())
+}
+
+func TestAnalyze_Since(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	history := runs(start, "check_infrastructure_parity", "", true, false, true, false, true, true, true)
+
+	report := Analyze(history, start.Add(4*time.Hour))
+	assert.Equal(t, 3, report.Runs)
+	assert.Zero(t, report.Components[0].Flips)
+	assert.Empty(t, report.Flaky())
+}
+
+func TestRevision(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("requires git")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	ctx := context.Background()
+
+	root := t.TempDir()
+	assert.Empty(t, Revision(ctx, root), "not a git repository")
+
+	file := filepath.Join(root, "main.go")
+	require.NoError(t, os.WriteFile(file, []byte("package main\n"), 0644))
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		require.NoError(t, cmd.Run())
+	}
+
+	clean := Revision(ctx, root)
+	assert.Len(t, clean, 40)
+	assert.Equal(t, clean, Revision(ctx, root))
+
+	require.NoError(t, os.WriteFile(file, []byte("package main\n\nfunc main() {}\n"), 0644))
+	dirty := Revision(ctx, root)
+	assert.Contains(t, dirty, clean+"+")
+
+	require.NoError(t, os.WriteFile(file, []byte("package main\n\nfunc main() { println() }\n"), 0644))
+	assert.NotEqual(t, dirty, Revision(ctx, root), "further edits change the revision")
+}
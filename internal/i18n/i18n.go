@@ -0,0 +1,220 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// EnvVar selects the language and character set of report output, e.g. "de", "C" or "en.ascii"
+const EnvVar = "SENTINEL_LANG"
+
+// DefaultLanguage is the language reports are written in
+const DefaultLanguage = "en"
+
+//go:embed catalogs/*.json
+var catalogFiles embed.FS
+
+// Locale is the language, character set and symbols report output is rendered in
+type Locale struct {
+	Lang    string    // Language of the catalog used, e.g. "en" or "de"
+	ASCII   bool      // Replace emoji and other non-ASCII characters for clients that can't render them
+	Symbols SymbolSet // Status markers to use; empty for unicode, or ascii when ASCII is set
+}
+
+// Default renders reports in English with emoji
+var Default = Locale{Lang: DefaultLanguage}
+
+// Parse reads a locale name in the POSIX style, language[_territory][.charset], e.g. "de",
+// "de_DE.UTF-8" or "en.ascii". C, POSIX and ascii, and any charset other than UTF-8, select
+// ASCII-safe output. Languages without a catalog fall back to English.
+func Parse(value string) Locale {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if i := strings.IndexByte(value, '@'); i >= 0 {
+		value = value[:i]
+	}
+	switch value {
+	case "":
+		return Default
+	case "c", "posix", "ascii":
+		return Locale{Lang: DefaultLanguage, ASCII: true}
+	}
+
+	loc := Locale{Lang: value}
+	if i := strings.IndexByte(value, '.'); i >= 0 {
+		charset := value[i+1:]
+		loc.Lang = value[:i]
+		loc.ASCII = charset != "utf-8" && charset != "utf8"
+	}
+	if i := strings.IndexAny(loc.Lang, "_-"); i >= 0 {
+		loc.Lang = loc.Lang[:i]
+	}
+	if _, ok := catalogs()[loc.Lang]; !ok {
+		loc.Lang = DefaultLanguage
+	}
+	return loc
+}
+
+// FromEnv returns the locale selected by SENTINEL_LANG. Output does not depend on LANG or
+// LC_ALL, so the same checks produce the same report on every machine.
+func FromEnv() Locale {
+	return Parse(os.Getenv(EnvVar))
+}
+
+// Languages returns the languages reports can be rendered in, sorted
+func Languages() []string {
+	languages := []string{DefaultLanguage}
+	for lang := range catalogs() {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Localize renders report text in the locale: lines matching a catalog message are
+// translated, keeping their indentation, bullet and status marker, status markers are
+// rendered in the locale's symbol set, and the result is made ASCII-safe if the locale
+// asks for it. The result is always valid UTF-8.
+func (l Locale) Localize(text string) string {
+	text = strings.ToValidUTF8(text, "\uFFFD")
+	if messages, ok := catalogs()[l.Lang]; ok {
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			lines[i] = messages.translate(line)
+		}
+		text = strings.Join(lines, "\n")
+	}
+	text = l.Symbols.Apply(text)
+	if l.ASCII {
+		text = ToASCII(text)
+	}
+	return text
+}
+
+// transliterations are the ASCII replacements of punctuation and letters reports may contain
+var transliterations = strings.NewReplacer(
+	"—", "-",
+	"–", "-",
+	"…", "...",
+	"→", "->",
+	"‘", "'",
+	"’", "'",
+	"“", `"`,
+	"”", `"`,
+	"ä", "ae",
+	"ö", "oe",
+	"ü", "ue",
+	"Ä", "Ae",
+	"Ö", "Oe",
+	"Ü", "Ue",
+	"ß", "ss",
+)
+
+// ToASCII replaces report symbols with ASCII equivalents, transliterates punctuation and
+// letters, and replaces any other non-ASCII character with "?"
+func ToASCII(s string) string {
+	s = transliterations.Replace(SymbolsASCII.Apply(s))
+	return strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII {
+			return '?'
+		}
+		return r
+	}, s)
+}
+
+// message is a catalog entry: an English line, with %s for its variable parts, and its translation
+type message struct {
+	pattern     *regexp.Regexp
+	translation string
+}
+
+// catalog holds the translations of one language
+type catalog []message
+
+var (
+	loadOnce sync.Once
+	loaded   map[string]catalog
+)
+
+// catalogs returns the built-in catalogs by language
+func catalogs() map[string]catalog {
+	loadOnce.Do(func() {
+		loaded = make(map[string]catalog)
+		paths, _ := catalogFiles.ReadDir("catalogs")
+		for _, entry := range paths {
+			lang := strings.TrimSuffix(entry.Name(), ".json")
+			messages, err := loadCatalog(path.Join("catalogs", entry.Name()))
+			if err != nil {
+				panic(fmt.Sprintf("invalid message catalog %s: %v", entry.Name(), err))
+			}
+			loaded[lang] = messages
+		}
+	})
+	return loaded
+}
+
+// loadCatalog reads a catalog file mapping English lines to their translations
+func loadCatalog(name string) (catalog, error) {
+	data, err := catalogFiles.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var entries map[string]string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	messages := make(catalog, 0, len(keys))
+	for _, key := range keys {
+		parts := strings.Split(key, "%s")
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+		messages = append(messages, message{
+			pattern:     regexp.MustCompile("^" + strings.Join(parts, "(.*)") + "$"),
+			translation: entries[key],
+		})
+	}
+	return messages, nil
+}
+
+// translate translates a line if it matches a message
+func (c catalog) translate(line string) string {
+	prefix, text := splitMarker(line)
+	for _, msg := range c {
+		match := msg.pattern.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		args := make([]interface{}, len(match)-1)
+		for i, arg := range match[1:] {
+			args[i] = arg
+		}
+		return prefix + fmt.Sprintf(msg.translation, args...)
+	}
+	return line
+}
+
+// splitMarker splits a line into its indentation, bullet and status marker, and its text
+func splitMarker(line string) (string, string) {
+	for i, r := range line {
+		if r == ' ' || r == '\t' || r == '-' || (r > unicode.MaxASCII && !unicode.IsLetter(r)) {
+			continue
+		}
+		return line[:i], line[i:]
+	}
+	return line, ""
+}
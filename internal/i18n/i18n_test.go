@@ -0,0 +1,70 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Locale
+	}{
+		{"", Locale{Lang: "en"}},
+		{"en", Locale{Lang: "en"}},
+		{"de", Locale{Lang: "de"}},
+		{"de_DE.UTF-8", Locale{Lang: "de"}},
+		{"de-AT", Locale{Lang: "de"}},
+		{"de_DE.ISO-8859-1", Locale{Lang: "de", ASCII: true}},
+		{"en.ascii", Locale{Lang: "en", ASCII: true}},
+		{"C", Locale{Lang: "en", ASCII: true}},
+		{"POSIX", Locale{Lang: "en", ASCII: true}},
+		{"xx_YY.UTF-8", Locale{Lang: "en"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Parse(tt.value))
+		})
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("LANG", "de_DE.UTF-8")
+	t.Setenv(EnvVar, "")
+	assert.Equal(t, Default, FromEnv(), "only SENTINEL_LANG selects the output locale")
+
+	t.Setenv(EnvVar, "C")
+	assert.True(t, FromEnv().ASCII)
+}
+
+func TestLanguages(t *testing.T) {
+	assert.Equal(t, []string{"de", "en"}, Languages())
+}
+
+func TestLocalize(t *testing.T) {
+	report := "❌ Build freshness issues found for java:\n\n- error: target is stale\n  Fix: mvn compile\n"
+
+	assert.Equal(t, report, Default.Localize(report))
+
+	german := Locale{Lang: "de"}.Localize(report)
+	assert.Equal(t, "❌ Probleme mit der Build-Aktualität für java gefunden:\n\n- error: target is stale\n  Behebung: mvn compile\n", german)
+
+	ascii := Locale{Lang: "de", ASCII: true}.Localize(report)
+	assert.Equal(t, "[FAIL] Probleme mit der Build-Aktualitaet fuer java gefunden:\n\n- error: target is stale\n  Behebung: mvn compile\n", ascii)
+}
+
+func TestToASCII(t *testing.T) {
+	assert.Equal(t, "[OK] healthy", ToASCII("✅ healthy"))
+	assert.Equal(t, "[WARN] 2 flaky", ToASCII("⚠️ 2 flaky"))
+	assert.Equal(t, "Upgrade to Pro\n* Auto-fix", ToASCII("🚀 Upgrade to Pro\n• Auto-fix"))
+	assert.Equal(t, "a -> b - c...", ToASCII("a → b — c…"))
+	assert.Equal(t, "caf? ?", ToASCII("café 日"))
+}
+
+func TestCatalogsLoad(t *testing.T) {
+	for lang, messages := range catalogs() {
+		assert.NotEmpty(t, messages, lang)
+	}
+}
@@ -0,0 +1,85 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SymbolSet selects how status markers such as ✅ and ❌ are rendered
+type SymbolSet string
+
+// Symbol sets
+const (
+	SymbolsUnicode SymbolSet = "unicode" // Emoji, as written by the formatters
+	SymbolsASCII   SymbolSet = "ascii"   // Bracketed words: [OK], [FAIL], [WARN]
+	SymbolsNone    SymbolSet = "none"    // No markers, for clients that add their own
+)
+
+// marker is a status symbol used in reports and its ASCII rendering. Decorative markers
+// have no ASCII rendering and are dropped.
+type marker struct {
+	unicode string
+	ascii   string
+}
+
+// markers are the symbols report formatters use. Variants with the emoji presentation
+// selector come before the bare characters.
+var markers = []marker{
+	{"✅", "[OK]"},
+	{"✓", "[OK]"},
+	{"❌", "[FAIL]"},
+	{"⚠️", "[WARN]"},
+	{"⚠", "[WARN]"},
+	{"⏳", "[QUEUED]"},
+	{"🔄", "[RUNNING]"},
+	{"📝", "[NOTE]"},
+	{"📋", "[INFO]"},
+	{"💡", "[TIP]"},
+	{"🚀", ""},
+	{"📁", ""},
+}
+
+// bullet is the list bullet of license and upgrade messages
+const bullet = "•"
+
+var (
+	asciiSymbols = newSymbolReplacer(func(m marker) string { return m.ascii }, "*")
+	noSymbols    = newSymbolReplacer(func(m marker) string { return "" }, "-")
+)
+
+// newSymbolReplacer replaces each marker, and the space after it when it is dropped
+func newSymbolReplacer(render func(marker) string, bulletTo string) *strings.Replacer {
+	var pairs []string
+	for _, m := range markers {
+		if to := render(m); to != "" {
+			pairs = append(pairs, m.unicode, to)
+		} else {
+			pairs = append(pairs, m.unicode+" ", "", m.unicode, "")
+		}
+	}
+	return strings.NewReplacer(append(pairs, bullet, bulletTo)...)
+}
+
+// ParseSymbols reads a symbol set name; empty selects unicode
+func ParseSymbols(name string) (SymbolSet, error) {
+	switch set := SymbolSet(strings.ToLower(strings.TrimSpace(name))); set {
+	case "", SymbolsUnicode:
+		return SymbolsUnicode, nil
+	case SymbolsASCII, SymbolsNone:
+		return set, nil
+	default:
+		return "", fmt.Errorf("unknown symbol set %q (expected unicode, ascii or none)", name)
+	}
+}
+
+// Apply renders the status markers of report text in the symbol set
+func (s SymbolSet) Apply(text string) string {
+	switch s {
+	case SymbolsASCII:
+		return asciiSymbols.Replace(text)
+	case SymbolsNone:
+		return noSymbols.Replace(text)
+	default:
+		return text
+	}
+}
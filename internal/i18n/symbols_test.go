@@ -0,0 +1,39 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSymbols(t *testing.T) {
+	for name, expected := range map[string]SymbolSet{"": SymbolsUnicode, "unicode": SymbolsUnicode, "ASCII": SymbolsASCII, "none": SymbolsNone} {
+		set, err := ParseSymbols(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, set, name)
+	}
+
+	_, err := ParseSymbols("emoji")
+	assert.ErrorContains(t, err, "unknown symbol set")
+}
+
+func TestSymbolSet_Apply(t *testing.T) {
+	text := "🚀 Upgrade to Pro\n• Auto-fix\n✅ redis: healthy\n⚠️ 2 flaky"
+
+	assert.Equal(t, text, SymbolsUnicode.Apply(text))
+	assert.Equal(t, "Upgrade to Pro\n* Auto-fix\n[OK] redis: healthy\n[WARN] 2 flaky", SymbolsASCII.Apply(text))
+	assert.Equal(t, "Upgrade to Pro\n- Auto-fix\nredis: healthy\n2 flaky", SymbolsNone.Apply(text))
+}
+
+func TestLocalize_Symbols(t *testing.T) {
+	assert.Equal(t, "[OK] Alle Infrastrukturdienste sind in Ordnung", Locale{Lang: "de", Symbols: SymbolsASCII}.Localize("✅ All infrastructure services are healthy"))
+	assert.Equal(t, "Alle Infrastrukturdienste sind in Ordnung", Locale{Lang: "de", Symbols: SymbolsNone}.Localize("✅ All infrastructure services are healthy"))
+	// ASCII-safe output without markers
+	assert.Equal(t, "passed", Locale{Lang: "en", ASCII: true, Symbols: SymbolsNone}.Localize("✅ passed"))
+}
+
+func TestLocalize_ValidUTF8(t *testing.T) {
+	assert.Equal(t, "output: �", Default.Localize("output: \xff"))
+	assert.Equal(t, "output: ?", Locale{Lang: "en", ASCII: true}.Localize("output: \xff"))
+}
@@ -0,0 +1,245 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/runner"
+)
+
+// defaultCacheMinRequests is how many hits and misses a cache needs before its hit rate is
+// checked, so a freshly zeroed cache isn't reported
+const defaultCacheMinRequests = 20
+
+// cacheProbeTimeout bounds connecting to a remote build cache
+const cacheProbeTimeout = 3 * time.Second
+
+// remoteCachePorts are the ports of remote cache URLs that don't name one, by scheme
+var remoteCachePorts = map[string]string{
+	"http": "80", "https": "443", "grpc": "80", "grpcs": "443",
+	"redis": "6379", "rediss": "6379", "memcached": "11211",
+}
+
+// BuildCacheStatus is the result of checking a build cache, such as the Gradle build cache,
+// sccache or ccache
+type BuildCacheStatus struct {
+	Name       string
+	Remote     string // URL of the remote cache, when one is configured; passwords are masked
+	HasStats   bool   // Whether hits and misses were read
+	Hits       int
+	Misses     int
+	Errors     int     // Failed reads and writes of the cache
+	HitRate    float64 // Percent of hits and misses that were hits; 0 without any
+	Healthy    bool
+	Problems   []string
+	Fixes      []string // Fix of each problem, in the same order; empty for problems without one
+	Ecosystems []string // Ecosystems configuring the cache, in combined reports
+}
+
+// CheckBuildCaches checks the build caches an ecosystem configures that the project uses:
+// whether a setting silently turns one off, whether its remote accepts connections, and how
+// often it's hit. The statistics of sccache and ccache add up since they were last zeroed.
+func CheckBuildCaches(ctx context.Context, projectRoot string, cfg *config.EcosystemConfig) []BuildCacheStatus {
+	var statuses []BuildCacheStatus
+	for _, cache := range cfg.Ecosystem.Infrastructure.BuildCaches {
+		if len(cache.Configured) > 0 && !anySettingFound(ctx, projectRoot, cache.Configured) {
+			continue
+		}
+		statuses = append(statuses, checkBuildCache(ctx, projectRoot, cache))
+	}
+	return statuses
+}
+
+// AddBuildCaches adds build cache statuses to a report; problems of unhealthy caches become
+// issues, each followed by its fix
+func (r *InfrastructureReport) AddBuildCaches(caches []BuildCacheStatus) {
+	for _, cache := range caches {
+		r.BuildCaches = append(r.BuildCaches, cache)
+		if cache.Healthy {
+			continue
+		}
+		r.IsHealthy = false
+		for i, problem := range cache.Problems {
+			r.Issues = append(r.Issues, fmt.Sprintf("%s: %s", cache.Name, problem))
+			if cache.Fixes[i] != "" {
+				r.Issues = append(r.Issues, fmt.Sprintf("  Fix: %s", cache.Fixes[i]))
+			}
+		}
+	}
+}
+
+// checkBuildCache checks a build cache the project uses
+func checkBuildCache(ctx context.Context, projectRoot string, cache config.BuildCache) BuildCacheStatus {
+	status := BuildCacheStatus{Name: cache.Name, Healthy: true}
+	problem := func(message, fix string) {
+		status.Healthy = false
+		status.Problems = append(status.Problems, message)
+		status.Fixes = append(status.Fixes, fix)
+	}
+
+	for _, rule := range cache.Disabled {
+		if _, found := findSetting(ctx, projectRoot, rule.CacheSetting); found != rule.Absent {
+			problem(rule.Message, rule.Fix)
+		}
+	}
+
+	for _, setting := range cache.Remote {
+		remote, found := findSetting(ctx, projectRoot, setting)
+		if !found {
+			continue
+		}
+		status.Remote = maskPassword(remote)
+		if err := probeRemoteCache(ctx, remote); err != nil {
+			problem(fmt.Sprintf("remote cache %s is unreachable: %v", status.Remote, err), "")
+		}
+		break
+	}
+
+	if cache.StatsCommand == "" {
+		return status
+	}
+	output, err := runner.Output(ctx, projectRoot, cache.StatsCommand)
+	if err != nil {
+		problem(fmt.Sprintf("statistics unavailable, %s failed: %v", cache.StatsCommand, err), "")
+		return status
+	}
+	status.HasStats = true
+	status.Hits = sumCounts(string(output), cache.Hits)
+	status.Misses = sumCounts(string(output), cache.Misses)
+	status.Errors = sumCounts(string(output), cache.Errors)
+	requests := status.Hits + status.Misses
+	if requests > 0 {
+		status.HitRate = 100 * float64(status.Hits) / float64(requests)
+	}
+
+	if status.Errors > 0 {
+		problem(fmt.Sprintf("%d failed cache reads or writes", status.Errors), "")
+	}
+	minRequests := cache.MinRequests
+	if minRequests == 0 {
+		minRequests = defaultCacheMinRequests
+	}
+	if cache.MinHitRate > 0 && requests >= minRequests && status.HitRate < cache.MinHitRate {
+		problem(fmt.Sprintf("hit rate %.0f%% (%d of %d) is below %.0f%%", status.HitRate, status.Hits, requests, cache.MinHitRate), "")
+	}
+	return status
+}
+
+// anySettingFound reports whether any of some settings is found
+func anySettingFound(ctx context.Context, projectRoot string, settings []config.CacheSetting) bool {
+	for _, setting := range settings {
+		if _, found := findSetting(ctx, projectRoot, setting); found {
+			return true
+		}
+	}
+	return false
+}
+
+// findSetting looks a setting up in its environment variable or project files. The value is
+// the first group of the pattern, or what it matched, or the whole variable without a pattern.
+func findSetting(ctx context.Context, projectRoot string, setting config.CacheSetting) (string, bool) {
+	re, err := regexp.Compile(setting.Pattern)
+	if err != nil {
+		return "", false
+	}
+	match := func(content string) (string, bool) {
+		m := re.FindStringSubmatch(content)
+		if m == nil {
+			return "", false
+		}
+		if len(m) > 1 {
+			return strings.TrimSpace(m[1]), true
+		}
+		return strings.TrimSpace(m[0]), true
+	}
+
+	if setting.Env != "" {
+		value := os.Getenv(setting.Env)
+		if value == "" {
+			return "", false
+		}
+		if setting.Pattern == "" {
+			return value, true
+		}
+		return match(value)
+	}
+
+	paths, err := common.FindFilesByPattern(ctx, filepath.Join(projectRoot, common.ExpandPattern(setting.File)))
+	if err != nil {
+		return "", false
+	}
+	for _, path := range paths {
+		data, err := common.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if value, found := match(string(data)); found {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// probeRemoteCache connects to the host of a remote cache URL. URLs without a host, such as
+// file paths, and schemes without a known port aren't probed.
+func probeRemoteCache(ctx context.Context, remote string) error {
+	u, err := url.Parse(remote)
+	if err != nil || u.Hostname() == "" {
+		return nil
+	}
+	address := u.Host
+	if u.Port() == "" {
+		port, ok := remoteCachePorts[strings.ToLower(u.Scheme)]
+		if !ok {
+			return nil
+		}
+		address = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	dialer := net.Dialer{Timeout: cacheProbeTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("%s not accepting connections", address)
+	}
+	return conn.Close()
+}
+
+// maskPassword masks the password of a URL
+func maskPassword(remote string) string {
+	u, err := url.Parse(remote)
+	if err != nil || u.User == nil {
+		return remote
+	}
+	return u.Redacted()
+}
+
+// sumCounts adds up the first groups of a pattern's matches in statistics output
+func sumCounts(output, pattern string) int {
+	if pattern == "" {
+		return 0
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return 0
+	}
+	total := 0
+	for _, m := range re.FindAllStringSubmatch(output, -1) {
+		if len(m) < 2 {
+			continue
+		}
+		if n, err := strconv.Atoi(m[1]); err == nil {
+			total += n
+		}
+	}
+	return total
+}
@@ -3,29 +3,71 @@ package infra
 import (
 	"context"
 	"fmt"
-	"os/exec"
 	"regexp"
 	"strings"
 	"time"
 
 	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/installer"
+	"dev-env-sentinel/internal/runner"
+	"dev-env-sentinel/internal/version"
 )
 
+// IssueServiceVersionMismatch is the issue of a service running another version than the
+// config's expected_version or min_version
+const IssueServiceVersionMismatch = "service_version_mismatch"
+
+// IssueServiceUnavailable is the issue of a service that isn't running or can't be reached
+const IssueServiceUnavailable = "service_unavailable"
+
+// DefaultPorts are the ports services listen on by default
+var DefaultPorts = map[string]int{
+	"postgres":      5432,
+	"mysql":         3306,
+	"mariadb":       3306,
+	"redis":         6379,
+	"mongodb":       27017,
+	"rabbitmq":      5672,
+	"kafka":         9092,
+	"elasticsearch": 9200,
+	"opensearch":    9200,
+}
+
 // ServiceStatus represents the status of a service
 type ServiceStatus struct {
 	Name      string
 	Running   bool
 	Version   string
-	ExpectedVersion string
+	ExpectedVersion string // Constraint the version was checked against, e.g. "17" or ">= 17"
 	Healthy   bool
 	Message   string
+	Issue     string   // Issue type when unhealthy because of the version
+	Suggestions []string // How to get a matching version
+	LogHints   []string // Error lines of the service's recent logs, when unhealthy
+	Install    []installer.Command // How to install the service's program when it's missing
+	Ecosystems []string // Ecosystems requiring the service, in combined reports
+}
+
+// RuntimeStatus is a language runtime installed more than once, or with an installed version
+// fitting the requirements better than the active one
+type RuntimeStatus struct {
+	Language      string
+	Installations []version.Installation
+	Better        *version.Installation
+	Switch        string // Command that makes Better the active one
+	Ecosystems    []string
 }
 
 // InfrastructureReport contains infrastructure check results
 type InfrastructureReport struct {
 	Services []ServiceStatus
+	Daemons  []DaemonStatus
+	Limits   []LimitStatus
+	BuildCaches []BuildCacheStatus
+	Runtimes []RuntimeStatus
 	IsHealthy bool
 	Issues   []string
+	Ecosystems []string // Ecosystems checked, when the reports of several are combined
 }
 
 // CheckInfrastructure checks infrastructure parity for an ecosystem
@@ -37,8 +79,24 @@ func CheckInfrastructure(ctx context.Context, cfg *config.EcosystemConfig) (*Inf
 	}
 
 	// Check language version if configured
-	if cfg.Ecosystem.VersionConfig.Language != "" {
+	if language := cfg.Ecosystem.VersionConfig.Language; language != "" {
 		versionResult, err := CheckVersion(ctx, cfg)
+		if err == nil {
+			report.addRuntime(language, versionResult)
+		}
+		if err == nil && !versionResult.Detected && versionResult.Better != nil {
+			// Not found where the version command looks, but installed elsewhere
+			report.IsHealthy = false
+			report.Issues = append(report.Issues, fmt.Sprintf("%s version could not be detected: %s", language, versionResult.Error))
+			report.Issues = append(report.Issues, fmt.Sprintf("  Suggestion: %s", versionResult.BetterSuggestion(language)))
+		}
+		if err == nil && versionResult.Missing {
+			report.IsHealthy = false
+			report.Issues = append(report.Issues, fmt.Sprintf("%s is not installed: %s is not on the PATH", language, version.Program(cfg.Ecosystem.VersionConfig)))
+			for _, suggestion := range installSuggestions(versionResult.Install) {
+				report.Issues = append(report.Issues, fmt.Sprintf("  Suggestion: %s", suggestion))
+			}
+		}
 		if err == nil && versionResult.Detected {
 			if !versionResult.IsValid {
 				report.IsHealthy = false
@@ -61,17 +119,136 @@ func CheckInfrastructure(ctx context.Context, cfg *config.EcosystemConfig) (*Inf
 			continue
 		}
 
+		if status.Running {
+			checkServiceVersion(status, service, cfg.Ecosystem.VersionConfig)
+		}
 		report.Services = append(report.Services, *status)
 
 		if !status.Healthy {
 			report.IsHealthy = false
 			report.Issues = append(report.Issues, status.Message)
+			for _, suggestion := range status.Suggestions {
+				report.Issues = append(report.Issues, fmt.Sprintf("  Suggestion: %s", suggestion))
+			}
 		}
 	}
 
 	return report, nil
 }
 
+// CombineReports merges the reports of several ecosystems, given in the same order as their
+// IDs, into one. A service checked with the same result by several ecosystems is listed once,
+// with all the ecosystems that require it; repeated issues are listed once.
+func CombineReports(ecosystems []string, reports []*InfrastructureReport) *InfrastructureReport {
+	if len(reports) == 1 {
+		return reports[0]
+	}
+
+	combined := &InfrastructureReport{
+		Services:   []ServiceStatus{},
+		IsHealthy:  true,
+		Issues:     []string{},
+		Ecosystems: ecosystems,
+	}
+	index := make(map[string]int)
+	seenIssues := make(map[string]bool)
+	for i, report := range reports {
+		if !report.IsHealthy {
+			combined.IsHealthy = false
+		}
+		for _, service := range report.Services {
+			key := service.Name + "\x00" + service.Message
+			if j, ok := index[key]; ok {
+				combined.Services[j].Ecosystems = append(combined.Services[j].Ecosystems, ecosystems[i])
+				continue
+			}
+			service.Ecosystems = []string{ecosystems[i]}
+			index[key] = len(combined.Services)
+			combined.Services = append(combined.Services, service)
+		}
+		for _, daemon := range report.Daemons {
+			key := "daemon\x00" + daemon.Name + "\x00" + strings.Join(daemon.Problems, "\x00")
+			if j, ok := index[key]; ok {
+				combined.Daemons[j].Ecosystems = append(combined.Daemons[j].Ecosystems, ecosystems[i])
+				continue
+			}
+			daemon.Ecosystems = []string{ecosystems[i]}
+			index[key] = len(combined.Daemons)
+			combined.Daemons = append(combined.Daemons, daemon)
+		}
+		for _, runtime := range report.Runtimes {
+			key := "runtime\x00" + runtime.Language + "\x00" + runtime.Switch
+			if j, ok := index[key]; ok {
+				combined.Runtimes[j].Ecosystems = append(combined.Runtimes[j].Ecosystems, ecosystems[i])
+				continue
+			}
+			runtime.Ecosystems = []string{ecosystems[i]}
+			index[key] = len(combined.Runtimes)
+			combined.Runtimes = append(combined.Runtimes, runtime)
+		}
+		for _, cache := range report.BuildCaches {
+			key := "cache\x00" + cache.Name + "\x00" + strings.Join(cache.Problems, "\x00")
+			if j, ok := index[key]; ok {
+				combined.BuildCaches[j].Ecosystems = append(combined.BuildCaches[j].Ecosystems, ecosystems[i])
+				continue
+			}
+			cache.Ecosystems = []string{ecosystems[i]}
+			index[key] = len(combined.BuildCaches)
+			combined.BuildCaches = append(combined.BuildCaches, cache)
+		}
+		// Indented lines are details of the issue before them and are kept with it
+		for _, block := range issueBlocks(report.Issues) {
+			key := strings.Join(block, "\n")
+			if !seenIssues[key] {
+				seenIssues[key] = true
+				combined.Issues = append(combined.Issues, block...)
+			}
+		}
+	}
+	return combined
+}
+
+// issueBlocks groups issue lines into issues, each followed by its indented detail lines
+func issueBlocks(issues []string) [][]string {
+	var blocks [][]string
+	for _, issue := range issues {
+		if strings.HasPrefix(issue, " ") && len(blocks) > 0 {
+			blocks[len(blocks)-1] = append(blocks[len(blocks)-1], issue)
+			continue
+		}
+		blocks = append(blocks, []string{issue})
+	}
+	return blocks
+}
+
+// SuggestDevShell replaces the suggestions to install or switch versions with entering the dev
+// shell, for projects whose toolchain a declared environment such as a Nix flake provides
+func (r *InfrastructureReport) SuggestDevShell(hint string) {
+	var issues []string
+	for _, block := range issueBlocks(r.Issues) {
+		replaced := false
+		for _, line := range block {
+			if strings.HasPrefix(line, "  Suggestion: ") {
+				replaced = true
+				continue
+			}
+			issues = append(issues, line)
+		}
+		if replaced {
+			issues = append(issues, "  Suggestion: "+hint)
+		}
+	}
+	if issues == nil {
+		issues = []string{}
+	}
+	r.Issues = issues
+	for i := range r.Services {
+		if len(r.Services[i].Suggestions) > 0 {
+			r.Services[i].Suggestions = []string{hint}
+		}
+	}
+}
+
 // checkService checks a single service
 func checkService(ctx context.Context, service config.Service) (*ServiceStatus, error) {
 	status := &ServiceStatus{
@@ -81,10 +258,18 @@ func checkService(ctx context.Context, service config.Service) (*ServiceStatus,
 	}
 
 	// Execute check command
-	cmd := exec.CommandContext(ctx, "sh", "-c", service.CheckCommand)
-	output, err := cmd.Output()
+	output, err := runner.Output(ctx, "", service.CheckCommand)
 	if err != nil {
 		status.Message = fmt.Sprintf("Service check failed: %v", err)
+		if program := commandProgram(service.CheckCommand); service.Type == "command" && installer.Missing(ctx, program) {
+			status.Message = fmt.Sprintf("%s is not installed: %s is not on the PATH", service.Name, program)
+			version := service.ExpectedVersion
+			if version == "" {
+				version = service.MinVersion
+			}
+			status.Install = installer.Advise(service.Packages, version, installer.Available(ctx))
+			status.Suggestions = installSuggestions(status.Install)
+		}
 		return status, nil
 	}
 
@@ -113,6 +298,81 @@ func checkService(ctx context.Context, service config.Service) (*ServiceStatus,
 	return status, nil
 }
 
+// commandProgram returns the program a shell command runs first, e.g. "mvn" for "mvn --version"
+func commandProgram(command string) string {
+	if fields := strings.Fields(command); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// checkServiceVersion compares the version of a running service with the config's
+// expected_version and min_version. A version that can't be extracted isn't reported as a
+// mismatch, only noted. Upgrade commands come from the version managers of the ecosystem
+// when the service is its language.
+func checkServiceVersion(status *ServiceStatus, service config.Service, versionCfg config.VersionConfig) {
+	var constraint, want string
+	switch {
+	case service.ExpectedVersion != "":
+		constraint, want = service.ExpectedVersion, service.ExpectedVersion
+	case service.MinVersion != "":
+		constraint, want = ">= "+service.MinVersion, service.MinVersion
+	default:
+		return
+	}
+	status.ExpectedVersion = constraint
+
+	if status.Version == "" {
+		status.Message += fmt.Sprintf(" (version not detected, expected %s)", constraint)
+		return
+	}
+
+	var problem string
+	if service.ExpectedVersion != "" && !matchesVersion(status.Version, service.ExpectedVersion) {
+		problem = fmt.Sprintf("%s %s is running, but the project expects %s", service.Name, status.Version, service.ExpectedVersion)
+	}
+	if problem == "" && service.MinVersion != "" && version.Compare(status.Version, service.MinVersion) < 0 {
+		problem = fmt.Sprintf("%s %s is running, but the project needs %s or newer", service.Name, status.Version, service.MinVersion)
+		want = service.MinVersion
+	}
+	if problem == "" {
+		return
+	}
+
+	status.Healthy = false
+	status.Issue = IssueServiceVersionMismatch
+	status.Message = problem
+	if version.Compare(status.Version, want) < 0 {
+		status.Suggestions = append(status.Suggestions, fmt.Sprintf("Upgrade %s to %s", service.Name, want))
+	} else {
+		status.Suggestions = append(status.Suggestions, fmt.Sprintf("Switch %s to %s", service.Name, want))
+	}
+	if service.Name != versionCfg.Language {
+		return
+	}
+	for _, manager := range versionCfg.VersionManagers {
+		if manager.InstallCommand == "" {
+			continue
+		}
+		command := strings.ReplaceAll(manager.InstallCommand, "{version}", want)
+		if manager.SwitchCommand != "" {
+			command += " && " + strings.ReplaceAll(manager.SwitchCommand, "{version}", want)
+		}
+		status.Suggestions = append(status.Suggestions, fmt.Sprintf("With %s: %s", manager.Name, command))
+	}
+}
+
+// matchesVersion reports whether a version matches an expected version in the parts the
+// expected version gives, so 17.0.9 matches 17 and 17.0 but not 17.1
+func matchesVersion(actual, expected string) bool {
+	parts := strings.Split(actual, ".")
+	n := len(strings.Split(expected, "."))
+	if len(parts) > n {
+		parts = parts[:n]
+	}
+	return version.Compare(strings.Join(parts, "."), expected) == 0
+}
+
 // extractVersion extracts version from output using regex
 func extractVersion(output, pattern string) (string, error) {
 	re, err := regexp.Compile(pattern)
@@ -133,8 +393,7 @@ func CheckServiceHealth(ctx context.Context, checkCommand string, timeout time.D
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "sh", "-c", checkCommand)
-	output, err := cmd.Output()
+	output, err := runner.Output(ctx, "", checkCommand)
 	if err != nil {
 		return false, "", err
 	}
@@ -2,6 +2,8 @@ package infra
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 	"time"
@@ -195,3 +197,175 @@ func TestCheckServiceHealth_Timeout(t *testing.T) {
 	assert.False(t, healthy)
 }
 
+
+func TestCombineReports(t *testing.T) {
+	redis := ServiceStatus{Name: "redis", Message: "Service is not running"}
+	java := &InfrastructureReport{
+		Services: []ServiceStatus{redis},
+		Issues:   []string{"Service redis is not running", "  Start it with: redis-server"},
+	}
+	node := &InfrastructureReport{
+		Services: []ServiceStatus{redis, {Name: "postgres", Healthy: true, Running: true, Message: "Service is healthy"}},
+		Issues:   []string{"Service redis is not running", "  Start it with: redis-server"},
+	}
+
+	combined := CombineReports([]string{"java", "javascript"}, []*InfrastructureReport{java, node})
+	assert.False(t, combined.IsHealthy)
+	require.Len(t, combined.Services, 2)
+	assert.Equal(t, []string{"java", "javascript"}, combined.Services[0].Ecosystems)
+	assert.Equal(t, []string{"javascript"}, combined.Services[1].Ecosystems)
+	assert.Equal(t, []string{"Service redis is not running", "  Start it with: redis-server"}, combined.Issues)
+}
+
+func TestCheckServiceVersion(t *testing.T) {
+	versionCfg := config.VersionConfig{
+		Language:        "java",
+		VersionManagers: []config.VersionManager{{Name: "sdkman", InstallCommand: "sdk install java {version}", SwitchCommand: "sdk use java {version}"}},
+	}
+	tests := []struct {
+		name        string
+		service     config.Service
+		version     string
+		healthy     bool
+		constraint  string
+		message     string
+		suggestions []string
+	}{
+		{
+			name:    "no constraint",
+			service: config.Service{Name: "node"},
+			version: "18.2.0",
+			healthy: true,
+			message: "node is running",
+		},
+		{
+			name:       "expected major matches",
+			service:    config.Service{Name: "java", ExpectedVersion: "17"},
+			version:    "17.0",
+			healthy:    true,
+			constraint: "17",
+			message:    "java is running",
+		},
+		{
+			name:        "older than min version",
+			service:     config.Service{Name: "java", MinVersion: "17"},
+			version:     "11.0",
+			constraint:  ">= 17",
+			message:     "java 11.0 is running, but the project needs 17 or newer",
+			suggestions: []string{"Upgrade java to 17", "With sdkman: sdk install java 17 && sdk use java 17"},
+		},
+		{
+			name:        "newer than expected",
+			service:     config.Service{Name: "node", ExpectedVersion: "18.2"},
+			version:     "20.11.1",
+			constraint:  "18.2",
+			message:     "node 20.11.1 is running, but the project expects 18.2",
+			suggestions: []string{"Switch node to 18.2"},
+		},
+		{
+			name:       "version not detected",
+			service:    config.Service{Name: "postgres", MinVersion: "15"},
+			healthy:    true,
+			constraint: ">= 15",
+			message:    "postgres is running (version not detected, expected >= 15)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := &ServiceStatus{Name: tt.service.Name, Running: true, Healthy: true, Version: tt.version, Message: tt.service.Name + " is running"}
+			checkServiceVersion(status, tt.service, versionCfg)
+
+			assert.Equal(t, tt.healthy, status.Healthy)
+			assert.Equal(t, tt.constraint, status.ExpectedVersion)
+			assert.Equal(t, tt.message, status.Message)
+			assert.Equal(t, tt.suggestions, status.Suggestions)
+			if !tt.healthy {
+				assert.Equal(t, IssueServiceVersionMismatch, status.Issue)
+			}
+		})
+	}
+}
+
+func TestCheckInfrastructure_VersionMismatch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows - requires sh")
+	}
+
+	cfg := &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		ID: "test",
+		Infrastructure: config.Infrastructure{Services: []config.Service{{
+			Name:           "tool",
+			Type:           "command",
+			CheckCommand:   "echo 'tool 1.4.2'",
+			VersionExtract: `tool (\d+\.\d+\.\d+)`,
+			MinVersion:     "2.0",
+		}}},
+	}}
+
+	report, err := CheckInfrastructure(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.False(t, report.IsHealthy)
+	assert.Equal(t, []string{"tool 1.4.2 is running, but the project needs 2.0 or newer", "  Suggestion: Upgrade tool to 2.0"}, report.Issues)
+}
+
+func TestSuggestDevShell(t *testing.T) {
+	report := &InfrastructureReport{
+		Services: []ServiceStatus{
+			{Name: "node", Issue: IssueServiceVersionMismatch, Suggestions: []string{"Switch node to 20", "With nvm: nvm install 20"}},
+			{Name: "redis", Message: "Service check failed"},
+		},
+		Issues: []string{
+			"node 18.19.0 is running, but the project expects 20",
+			"  Suggestion: Switch node to 20",
+			"  Suggestion: With nvm: nvm install 20",
+			"Service check failed",
+		},
+	}
+	report.SuggestDevShell("Enter the dev shell flake.nix declares: nix develop")
+
+	assert.Equal(t, []string{
+		"node 18.19.0 is running, but the project expects 20",
+		"  Suggestion: Enter the dev shell flake.nix declares: nix develop",
+		"Service check failed",
+	}, report.Issues)
+	assert.Equal(t, []string{"Enter the dev shell flake.nix declares: nix develop"}, report.Services[0].Suggestions)
+	assert.Empty(t, report.Services[1].Suggestions)
+}
+
+func TestCheckInfrastructure_InstalledElsewhere(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows - requires sh")
+	}
+
+	// Installed by a version manager, but not on the PATH
+	tmpDir := t.TempDir()
+	for _, version := range []string{"11.0.2", "17.0.9"} {
+		dir := filepath.Join(tmpDir, version, "bin")
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "fakejava"), []byte("#!/bin/sh\necho 'openjdk version \""+version+"\"'\n"), 0755))
+	}
+	cfg := &config.EcosystemConfig{
+		Ecosystem: config.Ecosystem{
+			ID: "java",
+			VersionConfig: config.VersionConfig{
+				Language:         "java",
+				VersionArgs:      []string{"fakejava", "-version"},
+				VersionPattern:   `openjdk version "([^"]+)"`,
+				VersionManagers:  []config.VersionManager{{Name: "asdf", SwitchCommand: "asdf shell java {version}"}},
+				InstallLocations: []config.InstallLocation{{Source: "asdf", Path: filepath.Join(tmpDir, "*", "bin", "fakejava")}},
+			},
+			Requirements: config.Requirements{MinVersion: "17"},
+		},
+	}
+
+	report, err := CheckInfrastructure(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.False(t, report.IsHealthy)
+	require.Len(t, report.Runtimes, 1)
+	assert.Len(t, report.Runtimes[0].Installations, 2)
+	assert.Equal(t, "asdf shell java 17.0.9", report.Runtimes[0].Switch)
+	require.Len(t, report.Issues, 2)
+	assert.Contains(t, report.Issues[0], "java version could not be detected: failed to execute version command: fakejava not found on PATH")
+	assert.Equal(t, "  Suggestion: java 17.0.9 is already installed at "+filepath.Join(tmpDir, "17.0.9", "bin", "fakejava")+" (asdf), switch to it without installing: asdf shell java 17.0.9", report.Issues[1])
+}
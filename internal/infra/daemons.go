@@ -0,0 +1,288 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/runner"
+)
+
+// Process is a running process as listed by ps
+type Process struct {
+	PID     int
+	PPID    int
+	Elapsed time.Duration // Time since the process started
+	Args    string        // Full command line
+}
+
+// DaemonStatus is the result of checking a long-running dev daemon, such as the Gradle daemon,
+// a bundler's watcher or a dev server
+type DaemonStatus struct {
+	Name           string
+	Processes      []Process // Running instances belonging to the project
+	Healthy        bool
+	Problems       []string
+	RestartCommand string
+	Ecosystems     []string // Ecosystems configuring the daemon, in combined reports
+}
+
+// processListCommand lists pid, parent pid, elapsed time and command line of all processes
+const processListCommand = "ps -eo pid=,ppid=,etime=,args="
+
+// CheckDaemons checks the daemons an ecosystem configures for a project: whether they are
+// stuck, run another version than the project uses, have lost the process that started them,
+// or were started before files they only read on startup changed. Daemons that are not
+// running are not reported. Process listing is not supported on Windows.
+func CheckDaemons(ctx context.Context, projectRoot string, cfg *config.EcosystemConfig) ([]DaemonStatus, error) {
+	daemons := cfg.Ecosystem.Infrastructure.Daemons
+	if len(daemons) == 0 || runtime.GOOS == "windows" {
+		return nil, nil
+	}
+
+	output, err := runner.Output(ctx, "", processListCommand)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+	processes := parseProcesses(string(output))
+
+	now := time.Now()
+	var statuses []DaemonStatus
+	for _, daemon := range daemons {
+		status, err := checkDaemon(ctx, projectRoot, daemon, processes, now)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", daemon.Name, err)
+		}
+		if len(status.Processes) > 0 {
+			statuses = append(statuses, *status)
+		}
+	}
+	return statuses, nil
+}
+
+// AddDaemons adds daemon statuses to a report; problems of unhealthy daemons become issues,
+// followed by the command that restarts the daemon
+func (r *InfrastructureReport) AddDaemons(daemons []DaemonStatus) {
+	for _, daemon := range daemons {
+		r.Daemons = append(r.Daemons, daemon)
+		if daemon.Healthy {
+			continue
+		}
+		r.IsHealthy = false
+		for _, problem := range daemon.Problems {
+			r.Issues = append(r.Issues, fmt.Sprintf("%s: %s", daemon.Name, problem))
+		}
+		if daemon.RestartCommand != "" {
+			r.Issues = append(r.Issues, fmt.Sprintf("  Restart: %s", daemon.RestartCommand))
+		}
+	}
+}
+
+// checkDaemon checks the running instances of a daemon
+func checkDaemon(ctx context.Context, projectRoot string, daemon config.Daemon, processes []Process, now time.Time) (*DaemonStatus, error) {
+	status := &DaemonStatus{
+		Name:           daemon.Name,
+		Healthy:        true,
+		RestartCommand: daemon.RestartCommand,
+	}
+
+	re, err := regexp.Compile(daemon.Process)
+	if err != nil {
+		return nil, fmt.Errorf("invalid process pattern: %w", err)
+	}
+	for _, p := range processes {
+		if !re.MatchString(p.Args) {
+			continue
+		}
+		if daemon.ProjectScoped && !strings.Contains(p.Args, projectRoot) {
+			continue
+		}
+		status.Processes = append(status.Processes, p)
+	}
+	if len(status.Processes) == 0 {
+		return status, nil
+	}
+
+	if daemon.MaxInstances > 0 && len(status.Processes) > daemon.MaxInstances {
+		status.Problems = append(status.Problems, fmt.Sprintf("%d instances running (pids %s), expected at most %d",
+			len(status.Processes), pids(status.Processes), daemon.MaxInstances))
+	}
+
+	if !daemon.Detached {
+		for _, p := range status.Processes {
+			if p.PPID == 1 {
+				status.Problems = append(status.Problems, fmt.Sprintf("orphaned process (pid %d) whose parent has exited, running for %s", p.PID, formatElapsed(p.Elapsed)))
+			}
+		}
+	}
+
+	problems, err := versionProblems(projectRoot, daemon, status.Processes)
+	if err != nil {
+		return nil, err
+	}
+	status.Problems = append(status.Problems, problems...)
+
+	for _, p := range status.Processes {
+		if changed := changedSince(ctx, projectRoot, daemon.RestartOn, now.Add(-p.Elapsed)); changed != "" {
+			status.Problems = append(status.Problems, fmt.Sprintf("process (pid %d) was started before %s changed and may serve outdated code", p.PID, changed))
+		}
+	}
+
+	if daemon.StatusCommand != "" {
+		if problem := checkResponding(ctx, daemon); problem != "" {
+			status.Problems = append(status.Problems, problem)
+		}
+	}
+
+	status.Healthy = len(status.Problems) == 0
+	return status, nil
+}
+
+// versionProblems reports processes running another version than the project declares
+func versionProblems(projectRoot string, daemon config.Daemon, processes []Process) ([]string, error) {
+	if daemon.VersionExtract == "" || daemon.ExpectedVersion.File == "" {
+		return nil, nil
+	}
+	data, err := common.ReadFile(filepath.Join(projectRoot, daemon.ExpectedVersion.File))
+	if err != nil {
+		// The project doesn't pin a version
+		return nil, nil
+	}
+	expected, err := extractVersion(string(data), daemon.ExpectedVersion.Pattern)
+	if err != nil {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(daemon.VersionExtract)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version pattern: %w", err)
+	}
+	var problems []string
+	for _, p := range processes {
+		match := re.FindStringSubmatch(p.Args)
+		if len(match) < 2 || match[1] == expected {
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("process (pid %d) runs version %s, but %s declares %s", p.PID, match[1], daemon.ExpectedVersion.File, expected))
+	}
+	return problems, nil
+}
+
+// changedSince returns the first file matching the patterns that was modified after a time
+func changedSince(ctx context.Context, projectRoot string, patterns []string, since time.Time) string {
+	for _, pattern := range patterns {
+		matches, err := common.Glob(ctx, filepath.Join(projectRoot, pattern), common.FollowLinks)
+		if err != nil {
+			continue
+		}
+		for _, match := range matches {
+			info, err := common.Stat(match)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			if info.ModTime().After(since) {
+				if rel, err := filepath.Rel(projectRoot, match); err == nil {
+					return rel
+				}
+				return match
+			}
+		}
+	}
+	return ""
+}
+
+// checkResponding runs a daemon's status command, returning a problem if it fails or its
+// output doesn't match the status pattern
+func checkResponding(ctx context.Context, daemon config.Daemon) string {
+	responding, output, err := CheckServiceHealth(ctx, daemon.StatusCommand, 10*time.Second)
+	if err != nil || !responding {
+		return fmt.Sprintf("not responding: %s failed", daemon.StatusCommand)
+	}
+	if daemon.StatusPattern != "" {
+		matched, err := regexp.MatchString(daemon.StatusPattern, output)
+		if err != nil || !matched {
+			return fmt.Sprintf("not responding: %s did not report %s", daemon.StatusCommand, daemon.StatusPattern)
+		}
+	}
+	return ""
+}
+
+// parseProcesses parses the output of processListCommand, skipping lines it can't read
+func parseProcesses(output string) []Process {
+	var processes []Process
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		ppid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		elapsed, err := parseElapsed(fields[2])
+		if err != nil {
+			continue
+		}
+		processes = append(processes, Process{PID: pid, PPID: ppid, Elapsed: elapsed, Args: strings.Join(fields[3:], " ")})
+	}
+	return processes
+}
+
+// parseElapsed parses an elapsed time in the [[dd-]hh:]mm:ss format of ps
+func parseElapsed(value string) (time.Duration, error) {
+	var days int
+	if i := strings.IndexByte(value, '-'); i >= 0 {
+		d, err := strconv.Atoi(value[:i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid elapsed time %q", value)
+		}
+		days = d
+		value = value[i+1:]
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid elapsed time %q", value)
+	}
+	seconds := 0
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, fmt.Errorf("invalid elapsed time %q", value)
+		}
+		seconds = seconds*60 + n
+	}
+	return time.Duration(days)*24*time.Hour + time.Duration(seconds)*time.Second, nil
+}
+
+// formatElapsed formats a process age for reports, e.g. "3d 4h" or "12m"
+func formatElapsed(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd %dh", int(d.Hours())/24, int(d.Hours())%24)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+}
+
+// pids lists the process IDs of processes
+func pids(processes []Process) string {
+	ids := make([]string, len(processes))
+	for i, p := range processes {
+		ids[i] = strconv.Itoa(p.PID)
+	}
+	return strings.Join(ids, ", ")
+}
@@ -0,0 +1,186 @@
+package infra
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProcesses(t *testing.T) {
+	output := `    1     0  12-03:04:05 /sbin/init
+  812     1       05:09 java -cp gradle-launcher-8.5.jar org.gradle.launcher.daemon.bootstrap.GradleDaemon 8.5
+  901   812    01:02:03 node /work/app/node_modules/.bin/webpack serve --mode development
+garbage line
+`
+	processes := parseProcesses(output)
+	require.Len(t, processes, 3)
+
+	assert.Equal(t, Process{PID: 1, PPID: 0, Elapsed: 12*24*time.Hour + 3*time.Hour + 4*time.Minute + 5*time.Second, Args: "/sbin/init"}, processes[0])
+	assert.Equal(t, 812, processes[1].PID)
+	assert.Equal(t, 5*time.Minute+9*time.Second, processes[1].Elapsed)
+	assert.Equal(t, "node /work/app/node_modules/.bin/webpack serve --mode development", processes[2].Args)
+	assert.Equal(t, time.Hour+2*time.Minute+3*time.Second, processes[2].Elapsed)
+}
+
+func TestParseElapsed_Invalid(t *testing.T) {
+	for _, value := range []string{"", "12", "a:b", "1:2:3:4", "x-01:02"} {
+		_, err := parseElapsed(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestCheckDaemon_NotRunning(t *testing.T) {
+	daemon := config.Daemon{Name: "gradle daemon", Process: "GradleDaemon"}
+	status, err := checkDaemon(context.Background(), t.TempDir(), daemon, []Process{{PID: 5, PPID: 1, Args: "sshd"}}, time.Now())
+	require.NoError(t, err)
+
+	assert.Empty(t, status.Processes)
+	assert.True(t, status.Healthy)
+}
+
+func TestCheckDaemon_VersionMismatch(t *testing.T) {
+	projectRoot := t.TempDir()
+	writeFile(t, filepath.Join(projectRoot, "gradle/wrapper/gradle-wrapper.properties"),
+		"distributionUrl=https\\://services.gradle.org/distributions/gradle-8.5-bin.zip\n")
+
+	daemon := config.Daemon{
+		Name:           "gradle daemon",
+		Process:        `GradleDaemon`,
+		Detached:       true,
+		VersionExtract: `GradleDaemon (\d+(?:\.\d+)+)`,
+		ExpectedVersion: config.ExpectedVersion{
+			File:    "gradle/wrapper/gradle-wrapper.properties",
+			Pattern: `gradle-(\d+(?:\.\d+)+)-(?:bin|all)\.zip`,
+		},
+		RestartCommand: "gradle --stop",
+	}
+	processes := []Process{
+		{PID: 10, PPID: 1, Elapsed: time.Hour, Args: "java org.gradle.launcher.daemon.bootstrap.GradleDaemon 8.5"},
+		{PID: 11, PPID: 1, Elapsed: time.Hour, Args: "java org.gradle.launcher.daemon.bootstrap.GradleDaemon 7.6.1"},
+	}
+	status, err := checkDaemon(context.Background(), projectRoot, daemon, processes, time.Now())
+	require.NoError(t, err)
+
+	assert.False(t, status.Healthy)
+	assert.Len(t, status.Processes, 2)
+	// Detached daemons are not orphaned
+	require.Len(t, status.Problems, 1)
+	assert.Contains(t, status.Problems[0], "pid 11")
+	assert.Contains(t, status.Problems[0], "runs version 7.6.1, but gradle/wrapper/gradle-wrapper.properties declares 8.5")
+}
+
+func TestCheckDaemon_VersionNotPinned(t *testing.T) {
+	daemon := config.Daemon{
+		Name:            "gradle daemon",
+		Process:         `GradleDaemon`,
+		VersionExtract:  `GradleDaemon (\d+(?:\.\d+)+)`,
+		ExpectedVersion: config.ExpectedVersion{File: "gradle/wrapper/gradle-wrapper.properties", Pattern: `gradle-([\d.]+)-`},
+	}
+	processes := []Process{{PID: 10, PPID: 4, Args: "java GradleDaemon 7.6"}}
+	status, err := checkDaemon(context.Background(), t.TempDir(), daemon, processes, time.Now())
+	require.NoError(t, err)
+
+	assert.True(t, status.Healthy)
+}
+
+func TestCheckDaemon_OrphanedAndTooMany(t *testing.T) {
+	projectRoot := t.TempDir()
+	daemon := config.Daemon{Name: "webpack-dev-server", Process: `webpack(\.js)? serve`, ProjectScoped: true, MaxInstances: 1}
+	processes := []Process{
+		{PID: 20, PPID: 1, Elapsed: 3*24*time.Hour + 4*time.Hour, Args: "node " + projectRoot + "/node_modules/.bin/webpack serve"},
+		{PID: 21, PPID: 300, Elapsed: time.Minute, Args: "node " + projectRoot + "/node_modules/.bin/webpack serve"},
+		{PID: 22, PPID: 1, Elapsed: time.Minute, Args: "node /other/project/node_modules/.bin/webpack serve"},
+	}
+	status, err := checkDaemon(context.Background(), projectRoot, daemon, processes, time.Now())
+	require.NoError(t, err)
+
+	// Instances of other projects are ignored
+	require.Len(t, status.Processes, 2)
+	assert.False(t, status.Healthy)
+	require.Len(t, status.Problems, 2)
+	assert.Equal(t, "2 instances running (pids 20, 21), expected at most 1", status.Problems[0])
+	assert.Equal(t, "orphaned process (pid 20) whose parent has exited, running for 3d 4h", status.Problems[1])
+}
+
+func TestCheckDaemon_RestartOn(t *testing.T) {
+	projectRoot := t.TempDir()
+	config1 := filepath.Join(projectRoot, "webpack.config.js")
+	writeFile(t, config1, "module.exports = {}")
+	now := time.Now()
+	require.NoError(t, os.Chtimes(config1, now.Add(-time.Minute), now.Add(-time.Minute)))
+
+	daemon := config.Daemon{Name: "webpack-dev-server", Process: `webpack serve`, RestartOn: []string{"webpack.config.*", "missing.lock"}}
+	processes := []Process{
+		{PID: 30, PPID: 300, Elapsed: time.Hour, Args: "webpack serve"},
+		{PID: 31, PPID: 300, Elapsed: time.Second, Args: "webpack serve"},
+	}
+	status, err := checkDaemon(context.Background(), projectRoot, daemon, processes, now)
+	require.NoError(t, err)
+
+	require.Len(t, status.Problems, 1)
+	assert.Equal(t, "process (pid 30) was started before webpack.config.js changed and may serve outdated code", status.Problems[0])
+}
+
+func TestCheckDaemon_StatusCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows - requires sh")
+	}
+
+	processes := []Process{{PID: 40, PPID: 300, Args: "node react-native start"}}
+	daemon := config.Daemon{Name: "metro", Process: "react-native start", StatusCommand: "echo packager-status:running", StatusPattern: "packager-status:running"}
+	status, err := checkDaemon(context.Background(), t.TempDir(), daemon, processes, time.Now())
+	require.NoError(t, err)
+	assert.True(t, status.Healthy)
+
+	daemon.StatusCommand = "echo starting"
+	status, err = checkDaemon(context.Background(), t.TempDir(), daemon, processes, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"not responding: echo starting did not report packager-status:running"}, status.Problems)
+
+	daemon.StatusCommand = "exit 7"
+	status, err = checkDaemon(context.Background(), t.TempDir(), daemon, processes, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"not responding: exit 7 failed"}, status.Problems)
+}
+
+func TestCheckDaemon_InvalidPattern(t *testing.T) {
+	_, err := checkDaemon(context.Background(), t.TempDir(), config.Daemon{Name: "broken", Process: "("}, nil, time.Now())
+	assert.Error(t, err)
+}
+
+func TestAddDaemons(t *testing.T) {
+	report := &InfrastructureReport{IsHealthy: true}
+	report.AddDaemons([]DaemonStatus{
+		{Name: "nx daemon", Healthy: true, Processes: []Process{{PID: 1}}},
+		{Name: "metro", Problems: []string{"not responding: curl failed"}, RestartCommand: "npx react-native start --reset-cache"},
+	})
+
+	assert.False(t, report.IsHealthy)
+	assert.Len(t, report.Daemons, 2)
+	assert.Equal(t, []string{"metro: not responding: curl failed", "  Restart: npx react-native start --reset-cache"}, report.Issues)
+}
+
+func TestCombineReports_Daemons(t *testing.T) {
+	daemon := DaemonStatus{Name: "nx daemon", Problems: []string{"orphaned process (pid 3) whose parent has exited, running for 5m"}}
+	combined := CombineReports([]string{"npm", "react"}, []*InfrastructureReport{
+		{Daemons: []DaemonStatus{daemon}},
+		{Daemons: []DaemonStatus{daemon}, IsHealthy: true},
+	})
+
+	require.Len(t, combined.Daemons, 1)
+	assert.Equal(t, []string{"npm", "react"}, combined.Daemons[0].Ecosystems)
+	assert.False(t, combined.IsHealthy)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
@@ -0,0 +1,253 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/runner"
+)
+
+// LimitStatus is the result of comparing an OS limit with what the ecosystems of a project need
+type LimitStatus struct {
+	Name       string // e.g. "fs.inotify.max_user_watches"
+	Value      int    // Current value; 0 for checks without a value, such as the project's file system
+	Required   int    // Highest value any ecosystem needs
+	Healthy    bool
+	Message    string
+	Fix        string
+	Ecosystems []string // Ecosystems whose needs the limit doesn't meet
+}
+
+// OSLimits are the OS limits file watchers and builds run into. Zero values are unknown.
+type OSLimits struct {
+	OS               string
+	Watches          int    // Linux fs.inotify.max_user_watches
+	WatcherInstances int    // Linux fs.inotify.max_user_instances
+	OpenFiles        int    // Soft open-file limit of the shell commands run in
+	MaxFilesPerProc  int    // macOS kern.maxfilesperproc, which caps ulimit -n
+	FileSystem       string // Type of the file system the project is on, e.g. "ext4" or "nfs"
+}
+
+// procSys is where Linux exposes kernel parameters
+var procSys = "/proc/sys"
+
+// unwatchableFileSystems don't deliver change events to inotify or FSEvents for changes made
+// by other machines or the host, so file watchers on them miss edits
+var unwatchableFileSystems = map[string]bool{
+	"nfs": true, "nfs4": true, "cifs": true, "smbfs": true, "smb2": true, "smb3": true,
+	"9p": true, "v9fs": true, "afpfs": true, "webdav": true, "fuse.sshfs": true, "sshfs": true,
+	"vboxsf": true, "prl_fs": true, "vmhgfs": true, "fuse.vmhgfs-fuse": true,
+}
+
+// CheckLimits compares the OS limits with the highest needs the ecosystems declare:
+// inotify watches and instances on Linux, the open-file limit, and whether file watchers
+// work on the file system holding the project. Limits are not checked on Windows.
+func CheckLimits(ctx context.Context, projectRoot string, configs []*config.EcosystemConfig) []LimitStatus {
+	if runtime.GOOS == "windows" || !declaresLimits(configs) {
+		return nil
+	}
+	return evaluateLimits(readLimits(ctx, runtime.GOOS, projectRoot), configs)
+}
+
+// AddLimits adds limit statuses to a report; limits that are too low become issues,
+// followed by the fix
+func (r *InfrastructureReport) AddLimits(limits []LimitStatus) {
+	for _, limit := range limits {
+		r.Limits = append(r.Limits, limit)
+		if limit.Healthy {
+			continue
+		}
+		r.IsHealthy = false
+		r.Issues = append(r.Issues, limit.Message)
+		if limit.Fix != "" {
+			r.Issues = append(r.Issues, fmt.Sprintf("  Fix: %s", limit.Fix))
+		}
+	}
+}
+
+// declaresLimits reports whether any ecosystem declares an OS limit it needs
+func declaresLimits(configs []*config.EcosystemConfig) bool {
+	for _, cfg := range configs {
+		if cfg.Ecosystem.Infrastructure.Limits != (config.Limits{}) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateLimits compares OS limits with the needs of the ecosystems
+func evaluateLimits(limits OSLimits, configs []*config.EcosystemConfig) []LimitStatus {
+	var statuses []LimitStatus
+	watches := func(l config.Limits) int { return l.Watches }
+	instances := func(l config.Limits) int { return l.WatcherInstances }
+	openFiles := func(l config.Limits) int { return l.OpenFiles }
+
+	if limits.OS == "linux" {
+		if status, ok := compareLimit("fs.inotify.max_user_watches", limits.Watches, configs, watches); ok {
+			if !status.Healthy {
+				status.Message = fmt.Sprintf("fs.inotify.max_user_watches is %d, below the %d required by %s; file watchers stop seeing changes once it is reached",
+					status.Value, status.Required, strings.Join(status.Ecosystems, ", "))
+				status.Fix = sysctlFix("fs.inotify.max_user_watches", status.Required)
+			}
+			statuses = append(statuses, status)
+		}
+		if status, ok := compareLimit("fs.inotify.max_user_instances", limits.WatcherInstances, configs, instances); ok {
+			if !status.Healthy {
+				status.Message = fmt.Sprintf("fs.inotify.max_user_instances is %d, below the %d required by %s; editors, dev servers and test watchers each use instances",
+					status.Value, status.Required, strings.Join(status.Ecosystems, ", "))
+				status.Fix = sysctlFix("fs.inotify.max_user_instances", status.Required)
+			}
+			statuses = append(statuses, status)
+		}
+	}
+
+	if status, ok := compareLimit("open files", limits.OpenFiles, configs, openFiles); ok {
+		if !status.Healthy {
+			status.Message = fmt.Sprintf("The open-file limit (ulimit -n) is %d, below the %d required by %s; builds and watchers fail with EMFILE or \"too many open files\"",
+				status.Value, status.Required, strings.Join(status.Ecosystems, ", "))
+			status.Fix = fmt.Sprintf("ulimit -n %d (add it to your shell profile to keep it)", status.Required)
+			if limits.OS == "darwin" && limits.MaxFilesPerProc > 0 && limits.MaxFilesPerProc < status.Required {
+				status.Fix = fmt.Sprintf("sudo launchctl limit maxfiles %d unlimited && ulimit -n %d (add it to your shell profile to keep it)", status.Required, status.Required)
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	if limits.FileSystem != "" && unwatchableFileSystems[limits.FileSystem] {
+		var ecosystems []string
+		for _, cfg := range configs {
+			if l := cfg.Ecosystem.Infrastructure.Limits; l.Watches > 0 || l.WatcherInstances > 0 {
+				ecosystems = append(ecosystems, cfg.Ecosystem.ID)
+			}
+		}
+		if len(ecosystems) > 0 {
+			statuses = append(statuses, LimitStatus{
+				Name: "file system",
+				Message: fmt.Sprintf("The project is on a %s file system, where file watchers don't see all changes; %s may not pick up edits",
+					limits.FileSystem, strings.Join(ecosystems, ", ")),
+				Fix:        "move the project to a local disk, or make the watchers poll (e.g. CHOKIDAR_USEPOLLING=true, WATCHPACK_POLLING=true)",
+				Ecosystems: ecosystems,
+			})
+		}
+	}
+	return statuses
+}
+
+// compareLimit compares a limit with the highest need of the ecosystems; ok is false when the
+// limit is unknown or no ecosystem needs it
+func compareLimit(name string, value int, configs []*config.EcosystemConfig, need func(config.Limits) int) (LimitStatus, bool) {
+	status := LimitStatus{Name: name, Value: value, Healthy: true}
+	if value <= 0 {
+		return status, false
+	}
+	for _, cfg := range configs {
+		required := need(cfg.Ecosystem.Infrastructure.Limits)
+		if required > status.Required {
+			status.Required = required
+		}
+		if required > value {
+			status.Healthy = false
+			status.Ecosystems = append(status.Ecosystems, cfg.Ecosystem.ID)
+		}
+	}
+	return status, status.Required > 0
+}
+
+// sysctlFix returns the command that raises a kernel parameter now and after reboots; each
+// parameter gets its own file so fixes don't overwrite each other
+func sysctlFix(name string, value int) string {
+	return fmt.Sprintf("echo %s=%d | sudo tee /etc/sysctl.d/60-%s.conf && sudo sysctl --system", name, value, strings.ReplaceAll(name, ".", "-"))
+}
+
+// readLimits reads the current OS limits; limits that can't be read are left at 0
+func readLimits(ctx context.Context, goos, projectRoot string) OSLimits {
+	limits := OSLimits{OS: goos}
+	switch goos {
+	case "linux":
+		limits.Watches = readIntFile(filepath.Join(procSys, "fs/inotify/max_user_watches"))
+		limits.WatcherInstances = readIntFile(filepath.Join(procSys, "fs/inotify/max_user_instances"))
+	case "darwin":
+		if output, err := runner.Output(ctx, "", "sysctl -n kern.maxfilesperproc"); err == nil {
+			limits.MaxFilesPerProc = parseLimit(string(output))
+		}
+	}
+	if output, err := runner.Output(ctx, "", "ulimit -n"); err == nil {
+		limits.OpenFiles = parseLimit(string(output))
+	}
+	if output, err := runner.Output(ctx, "", "mount"); err == nil {
+		limits.FileSystem = fileSystemOf(string(output), projectRoot)
+	}
+	return limits
+}
+
+// readIntFile reads a file holding a number, returning 0 if it can't be read
+func readIntFile(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	return parseLimit(string(data))
+}
+
+// parseLimit parses a limit as printed by sysctl or ulimit; "unlimited" is the largest int32
+func parseLimit(value string) int {
+	value = strings.TrimSpace(value)
+	if value == "unlimited" {
+		return math.MaxInt32
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// fileSystemOf returns the type of the mount holding a path from the output of mount, in the
+// Linux ("dev on /mnt type nfs4 (rw)") or macOS ("dev on /Volumes/x (smbfs, nodev)") format
+func fileSystemOf(mounts, path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	best, fsType := "", ""
+	for _, line := range strings.Split(mounts, "\n") {
+		i := strings.Index(line, " on ")
+		if i < 0 {
+			continue
+		}
+		rest := line[i+len(" on "):]
+		var mountPoint, kind string
+		if j := strings.Index(rest, " type "); j >= 0 {
+			mountPoint = rest[:j]
+			kind = strings.Fields(rest[j+len(" type "):] + " ")[0]
+		} else if j := strings.LastIndex(rest, " ("); j >= 0 {
+			mountPoint = rest[:j]
+			kind = strings.TrimSuffix(strings.SplitN(rest[j+2:], ",", 2)[0], ")")
+		} else {
+			continue
+		}
+		if !withinMount(path, mountPoint) || len(mountPoint) < len(best) {
+			continue
+		}
+		best, fsType = mountPoint, kind
+	}
+	return fsType
+}
+
+// withinMount reports whether a path is at or below a mount point
+func withinMount(path, mountPoint string) bool {
+	if mountPoint == "/" {
+		return strings.HasPrefix(path, "/")
+	}
+	return path == mountPoint || strings.HasPrefix(path, mountPoint+string(filepath.Separator))
+}
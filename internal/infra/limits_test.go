@@ -0,0 +1,120 @@
+package infra
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func limitsConfig(id string, limits config.Limits) *config.EcosystemConfig {
+	return &config.EcosystemConfig{Ecosystem: config.Ecosystem{ID: id, Infrastructure: config.Infrastructure{Limits: limits}}}
+}
+
+func TestEvaluateLimits_Linux(t *testing.T) {
+	configs := []*config.EcosystemConfig{
+		limitsConfig("webpack", config.Limits{Watches: 524288, WatcherInstances: 512, OpenFiles: 10240}),
+		limitsConfig("java-gradle", config.Limits{Watches: 65536, OpenFiles: 4096}),
+		limitsConfig("java-maven", config.Limits{}),
+	}
+	limits := OSLimits{OS: "linux", Watches: 65536, WatcherInstances: 1024, OpenFiles: 1024, FileSystem: "ext4"}
+
+	statuses := evaluateLimits(limits, configs)
+	require.Len(t, statuses, 3)
+
+	watches := statuses[0]
+	assert.Equal(t, "fs.inotify.max_user_watches", watches.Name)
+	assert.False(t, watches.Healthy)
+	assert.Equal(t, 524288, watches.Required)
+	assert.Equal(t, []string{"webpack"}, watches.Ecosystems)
+	assert.Contains(t, watches.Message, "fs.inotify.max_user_watches is 65536, below the 524288 required by webpack")
+	assert.Equal(t, "echo fs.inotify.max_user_watches=524288 | sudo tee /etc/sysctl.d/60-fs-inotify-max_user_watches.conf && sudo sysctl --system", watches.Fix)
+
+	instances := statuses[1]
+	assert.True(t, instances.Healthy)
+	assert.Equal(t, 512, instances.Required)
+
+	openFiles := statuses[2]
+	assert.False(t, openFiles.Healthy)
+	assert.Equal(t, []string{"webpack", "java-gradle"}, openFiles.Ecosystems)
+	assert.Equal(t, "ulimit -n 10240 (add it to your shell profile to keep it)", openFiles.Fix)
+}
+
+func TestEvaluateLimits_Darwin(t *testing.T) {
+	configs := []*config.EcosystemConfig{limitsConfig("webpack", config.Limits{Watches: 524288, OpenFiles: 10240})}
+	limits := OSLimits{OS: "darwin", OpenFiles: 256, MaxFilesPerProc: 2048, FileSystem: "smbfs"}
+
+	statuses := evaluateLimits(limits, configs)
+	// inotify limits only exist on Linux
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "open files", statuses[0].Name)
+	assert.Equal(t, "sudo launchctl limit maxfiles 10240 unlimited && ulimit -n 10240 (add it to your shell profile to keep it)", statuses[0].Fix)
+
+	assert.Equal(t, "file system", statuses[1].Name)
+	assert.False(t, statuses[1].Healthy)
+	assert.Contains(t, statuses[1].Message, "The project is on a smbfs file system")
+	assert.Equal(t, []string{"webpack"}, statuses[1].Ecosystems)
+}
+
+func TestEvaluateLimits_UnknownValues(t *testing.T) {
+	configs := []*config.EcosystemConfig{limitsConfig("webpack", config.Limits{Watches: 524288, OpenFiles: 10240})}
+
+	assert.Empty(t, evaluateLimits(OSLimits{OS: "linux"}, configs))
+}
+
+func TestAddLimits(t *testing.T) {
+	report := &InfrastructureReport{IsHealthy: true}
+	report.AddLimits([]LimitStatus{
+		{Name: "fs.inotify.max_user_instances", Value: 1024, Required: 512, Healthy: true},
+		{Name: "open files", Value: 256, Required: 4096, Message: "The open-file limit (ulimit -n) is 256", Fix: "ulimit -n 4096"},
+	})
+
+	assert.False(t, report.IsHealthy)
+	assert.Len(t, report.Limits, 2)
+	assert.Equal(t, []string{"The open-file limit (ulimit -n) is 256", "  Fix: ulimit -n 4096"}, report.Issues)
+}
+
+func TestParseLimit(t *testing.T) {
+	assert.Equal(t, 8192, parseLimit("8192\n"))
+	assert.Equal(t, math.MaxInt32, parseLimit("unlimited"))
+	assert.Equal(t, 0, parseLimit("n/a"))
+}
+
+func TestFileSystemOf(t *testing.T) {
+	linux := `/dev/sda1 on / type ext4 (rw,relatime)
+server:/export on /mnt/share type nfs4 (rw,relatime,vers=4.2)
+C:\ on /mnt/c type 9p (rw,noatime,aname=drvfs)`
+	assert.Equal(t, "nfs4", fileSystemOf(linux, "/mnt/share/app"))
+	assert.Equal(t, "9p", fileSystemOf(linux, "/mnt/c/Users/dev/app"))
+	assert.Equal(t, "ext4", fileSystemOf(linux, "/mnt/sharewithsuffix"))
+
+	darwin := `/dev/disk3s1s1 on / (apfs, sealed, local, read-only, journaled)
+/dev/disk3s5 on /System/Volumes/Data (apfs, local, journaled, nobrowse)
+//dev@nas/projects on /Volumes/projects (smbfs, nodev, nosuid, mounted by dev)`
+	assert.Equal(t, "smbfs", fileSystemOf(darwin, "/Volumes/projects/app"))
+	assert.Equal(t, "apfs", fileSystemOf(darwin, "/System/Volumes/Data/Users/dev/app"))
+}
+
+func TestReadLimits_Linux(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows - requires sh")
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "fs/inotify/max_user_watches"), "8192\n")
+	writeFile(t, filepath.Join(dir, "fs/inotify/max_user_instances"), "128\n")
+	original := procSys
+	procSys = dir
+	defer func() { procSys = original }()
+
+	limits := readLimits(context.Background(), "linux", t.TempDir())
+	assert.Equal(t, "linux", limits.OS)
+	assert.Equal(t, 8192, limits.Watches)
+	assert.Equal(t, 128, limits.WatcherInstances)
+	assert.Positive(t, limits.OpenFiles)
+}
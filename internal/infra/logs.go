@@ -0,0 +1,220 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/runner"
+)
+
+// Defaults of service log scraping
+const (
+	DefaultLogLines   = 200
+	DefaultLogMatches = 5
+)
+
+// defaultLogPatterns match the error lines of most services' logs
+var defaultLogPatterns = []string{
+	`(?i)\b(error|fatal|panic|exception|critical|severe)\b`,
+	`(?i)(connection refused|permission denied|address already in use|no space left|out of memory|timed? ?out)`,
+}
+
+// maxLogLineLength is the length matched log lines are cut to
+const maxLogLineLength = 300
+
+// logTailBytes is how much of the end of a log file is read
+const logTailBytes = 256 * 1024
+
+// AddServiceLogs reads the recent logs of unhealthy services that configure logs, and adds
+// their error lines to the services and, after each service's issue, to the report's issues
+func (r *InfrastructureReport) AddServiceLogs(ctx context.Context, projectRoot string, cfg *config.EcosystemConfig) {
+	logs := make(map[string]*config.ServiceLogs)
+	for _, service := range cfg.Ecosystem.Infrastructure.Services {
+		if service.Logs != nil {
+			logs[service.Name] = service.Logs
+		}
+	}
+	if len(logs) == 0 {
+		return
+	}
+
+	details := make(map[int][]string) // Service index -> lines added after its issue
+	for i := range r.Services {
+		status := &r.Services[i]
+		serviceLogs, ok := logs[status.Name]
+		if status.Healthy || !ok {
+			continue
+		}
+		text, err := readServiceLogs(ctx, projectRoot, serviceLogs)
+		if err == nil {
+			status.LogHints, err = MatchLogLines(text, serviceLogs.Patterns, serviceLogs.MaxMatches)
+		}
+		if err != nil {
+			details[i] = []string{fmt.Sprintf("  Logs unavailable: %v", err)}
+			continue
+		}
+		for _, hint := range status.LogHints {
+			details[i] = append(details[i], "  Log: "+hint)
+		}
+	}
+
+	// Unhealthy services' issues are in the order of the services; their messages may repeat
+	var issues []string
+	next := 0
+	for _, block := range issueBlocks(r.Issues) {
+		issues = append(issues, block...)
+		for next < len(r.Services) && r.Services[next].Healthy {
+			next++
+		}
+		if next < len(r.Services) && block[0] == r.Services[next].Message {
+			issues = append(issues, details[next]...)
+			next++
+		}
+	}
+	r.Issues = issues
+}
+
+// readServiceLogs returns the recent lines of a service's logs
+func readServiceLogs(ctx context.Context, projectRoot string, logs *config.ServiceLogs) (string, error) {
+	lines := logs.Lines
+	if lines <= 0 {
+		lines = DefaultLogLines
+	}
+
+	var command string
+	switch {
+	case logs.Container != "":
+		// docker logs writes the container's stderr to its own stderr
+		command = fmt.Sprintf("docker logs --tail %d %s 2>&1", lines, shellQuote(logs.Container))
+	case logs.Unit != "":
+		command = fmt.Sprintf("journalctl --no-pager --output cat -n %d -u %s", lines, shellQuote(logs.Unit))
+	default:
+		path := logs.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(projectRoot, path)
+		}
+		return tailFile(path, lines)
+	}
+
+	output, err := runner.Output(ctx, "", command)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", errors.New(strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		if len(output) > 0 {
+			return "", errors.New(strings.TrimSpace(string(output)))
+		}
+		return "", err
+	}
+	return string(output), nil
+}
+
+// tailFile returns the last lines of a file, reading at most logTailBytes
+func tailFile(path string, lines int) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	offset := info.Size() - logTailBytes
+	if offset > 0 {
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return "", err
+		}
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+
+	all := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if offset > 0 && len(all) > 1 {
+		all = all[1:] // The first line is cut
+	}
+	if len(all) > lines {
+		all = all[len(all)-lines:]
+	}
+	return strings.Join(all, "\n"), nil
+}
+
+// MatchLogLines returns the most recent distinct log lines matching any of the patterns, or
+// the default error patterns when there are none, oldest first. A line repeated in the logs
+// is returned once with its count.
+func MatchLogLines(text string, patterns []string, maxMatches int) ([]string, error) {
+	if len(patterns) == 0 {
+		patterns = defaultLogPatterns
+	}
+	if maxMatches <= 0 {
+		maxMatches = DefaultLogMatches
+	}
+	var regexps []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log pattern %q: %w", pattern, err)
+		}
+		regexps = append(regexps, re)
+	}
+
+	var order []string // Distinct matched lines, by their last occurrence
+	counts := make(map[string]int)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || !matchesAny(regexps, line) {
+			continue
+		}
+		if len(line) > maxLogLineLength {
+			line = line[:maxLogLineLength] + "..."
+		}
+		if counts[line] > 0 {
+			for i, seen := range order {
+				if seen == line {
+					order = append(order[:i], order[i+1:]...)
+					break
+				}
+			}
+		}
+		counts[line]++
+		order = append(order, line)
+	}
+
+	if len(order) > maxMatches {
+		order = order[len(order)-maxMatches:]
+	}
+	matches := make([]string, 0, len(order))
+	for _, line := range order {
+		if counts[line] > 1 {
+			line = fmt.Sprintf("%s (x%d)", line, counts[line])
+		}
+		matches = append(matches, line)
+	}
+	return matches, nil
+}
+
+func matchesAny(regexps []*regexp.Regexp, line string) bool {
+	for _, re := range regexps {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// shellQuote quotes a value for use as a single sh argument
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
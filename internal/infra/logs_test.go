@@ -0,0 +1,89 @@
+package infra
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchLogLines(t *testing.T) {
+	logs := `2024-05-01 10:00:00 LOG:  database system is ready to accept connections
+FATAL:  password authentication failed for user "app"
+LOG:  checkpoint starting
+FATAL:  password authentication failed for user "app"
+ERROR:  relation "users" does not exist
+`
+	matches, err := MatchLogLines(logs, nil, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`FATAL:  password authentication failed for user "app" (x2)`,
+		`ERROR:  relation "users" does not exist`,
+	}, matches)
+
+	matches, err = MatchLogLines(logs, []string{`relation .* does not exist`}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`ERROR:  relation "users" does not exist`}, matches)
+
+	matches, err = MatchLogLines(logs, nil, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`ERROR:  relation "users" does not exist`}, matches, "the most recent lines are kept")
+
+	_, err = MatchLogLines(logs, []string{"("}, 0)
+	assert.Error(t, err)
+}
+
+func TestTailFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "service.log")
+	var lines []string
+	for i := 0; i < 10; i++ {
+		lines = append(lines, strings.Repeat("x", i))
+	}
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644))
+
+	text, err := tailFile(path, 3)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join(lines[7:], "\n"), text)
+
+	_, err = tailFile(filepath.Join(t.TempDir(), "missing.log"), 3)
+	assert.Error(t, err)
+}
+
+func TestAddServiceLogs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows - requires sh")
+	}
+
+	projectRoot := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(projectRoot, "logs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectRoot, "logs", "redis.log"), []byte("Ready to accept connections\n# Can't save in background: fork: Cannot allocate memory - Out of memory\n"), 0644))
+
+	cfg := &config.EcosystemConfig{
+		Ecosystem: config.Ecosystem{
+			ID: "test",
+			Infrastructure: config.Infrastructure{
+				Services: []config.Service{
+					{Name: "redis", CheckCommand: "exit 1", Logs: &config.ServiceLogs{File: "logs/redis.log"}},
+					{Name: "postgres", CheckCommand: "exit 1", Logs: &config.ServiceLogs{File: "logs/missing.log"}},
+					{Name: "api", CheckCommand: "exit 1"},
+				},
+			},
+		},
+	}
+
+	report, err := CheckInfrastructure(context.Background(), cfg)
+	require.NoError(t, err)
+	report.AddServiceLogs(context.Background(), projectRoot, cfg)
+
+	assert.Equal(t, []string{"# Can't save in background: fork: Cannot allocate memory - Out of memory"}, report.Services[0].LogHints)
+	require.Len(t, report.Issues, 5)
+	assert.Equal(t, "  Log: # Can't save in background: fork: Cannot allocate memory - Out of memory", report.Issues[1])
+	assert.Contains(t, report.Issues[3], "  Logs unavailable:")
+	assert.Equal(t, report.Services[2].Message, report.Issues[4])
+}
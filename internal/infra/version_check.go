@@ -5,18 +5,28 @@ import (
 	"fmt"
 
 	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/installer"
 	"dev-env-sentinel/internal/version"
 )
 
 // CheckVersion checks language version and runtime compatibility
 func CheckVersion(ctx context.Context, cfg *config.EcosystemConfig) (*VersionCheckResult, error) {
+	installations := version.FindInstallations(ctx, cfg)
+
 	// Detect current version
 	versionInfo, err := version.DetectVersion(ctx, cfg)
 	if err != nil {
-		return &VersionCheckResult{
-			Detected: false,
-			Error:    err.Error(),
-		}, nil
+		result := &VersionCheckResult{
+			Detected:      false,
+			Error:         err.Error(),
+			Installations: installations,
+		}
+		result.setBetter(installations, nil, cfg)
+		if result.Better == nil && installer.Missing(ctx, version.Program(cfg.Ecosystem.VersionConfig)) {
+			result.Missing = true
+			result.Install = installer.Advise(cfg.Ecosystem.VersionConfig.Packages, installVersion(cfg.Ecosystem.Requirements), installer.Available(ctx))
+		}
+		return result, nil
 	}
 
 	// Validate version
@@ -28,6 +38,12 @@ func CheckVersion(ctx context.Context, cfg *config.EcosystemConfig) (*VersionChe
 		IsValid:       validation.IsValid,
 		Issues:        []string{},
 		Suggestions:   []string{},
+		Installations: installations,
+	}
+	result.setBetter(installations, versionInfo, cfg)
+	if !result.IsValid && result.Better != nil {
+		// Switching to what's installed beats installing
+		result.Suggestions = append([]string{result.BetterSuggestion(cfg.Ecosystem.VersionConfig.Language)}, result.Suggestions...)
 	}
 
 	// Convert issues to strings
@@ -61,5 +77,54 @@ type VersionCheckResult struct {
 	Issues      []string
 	Suggestions []string
 	Error       string
+	Installations []version.Installation // Copies of the language's program on this machine
+	Better      *version.Installation // An installed version fitting the requirements better than the active one
+	Switch      string                // Command that makes Better the active one
+	Missing     bool                  // The language's program is nowhere on this machine
+	Install     []installer.Command   // How to install the language when it's missing, with the installers on this machine
+}
+
+// installVersion returns the version of a runtime to install: the first preferred one, else the minimum
+func installVersion(req config.Requirements) string {
+	if len(req.PreferredVersions) > 0 {
+		return req.PreferredVersions[0]
+	}
+	return req.MinVersion
+}
+
+// installSuggestions turns install commands into suggestions
+func installSuggestions(commands []installer.Command) []string {
+	suggestions := make([]string, len(commands))
+	for i, command := range commands {
+		suggestions[i] = fmt.Sprintf("Install with %s: %s", command.Installer, command.Command)
+	}
+	return suggestions
 }
 
+// setBetter looks for an installation fitting the requirements better than the active version
+func (r *VersionCheckResult) setBetter(installations []version.Installation, active *version.VersionInfo, cfg *config.EcosystemConfig) {
+	r.Better = version.BetterInstallation(installations, active, cfg)
+	if r.Better != nil {
+		r.Switch = r.Better.SwitchCommand(cfg.Ecosystem.VersionConfig)
+	}
+}
+
+// BetterSuggestion suggests switching to the better installed version
+func (r *VersionCheckResult) BetterSuggestion(language string) string {
+	return fmt.Sprintf("%s %s is already installed at %s (%s), switch to it without installing: %s", language, r.Better.Version, r.Better.Path, r.Better.Source, r.Switch)
+}
+
+
+// addRuntime adds the installations of a language to a report when there is a choice: more than
+// one is installed, or one fits the requirements better than the active one
+func (r *InfrastructureReport) addRuntime(language string, result *VersionCheckResult) {
+	if len(result.Installations) < 2 && result.Better == nil {
+		return
+	}
+	r.Runtimes = append(r.Runtimes, RuntimeStatus{
+		Language:      language,
+		Installations: result.Installations,
+		Better:        result.Better,
+		Switch:        result.Switch,
+	})
+}
@@ -0,0 +1,131 @@
+// Package installer works out how to install a missing tool with the package managers and
+// version managers available on a machine, such as brew, apt, winget or sdkman.
+package installer
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"dev-env-sentinel/internal/runner"
+)
+
+// Installer is a package manager or version manager that installs tools
+type Installer struct {
+	Name      string   // As packages are keyed in configs, e.g. "brew"
+	Platforms []string // Operating systems it installs on, as GOOS values
+	Program   string   // Program on the PATH when it's available
+	Marker    string   // File that's there when it's available, for managers that are shell functions
+	Command   string   // Install command; {package} is replaced with the package
+	Automatic bool     // Its installs can run unattended as fixes; not when they need sudo, an elevated shell or a shell function
+}
+
+// Known are the installers packages can be given for, in order of preference: version
+// managers, then package managers installing for the user, then system ones
+var Known = []Installer{
+	{Name: "asdf", Platforms: []string{"darwin", "linux"}, Program: "asdf", Command: "asdf install {package}", Automatic: true},
+	{Name: "sdkman", Platforms: []string{"darwin", "linux"}, Marker: "$HOME/.sdkman/bin/sdkman-init.sh", Command: "sdk install {package}"},
+	{Name: "brew", Platforms: []string{"darwin", "linux"}, Program: "brew", Command: "brew install {package}", Automatic: true},
+	{Name: "scoop", Platforms: []string{"windows"}, Program: "scoop", Command: "scoop install {package}", Automatic: true},
+	{Name: "winget", Platforms: []string{"windows"}, Program: "winget", Command: "winget install --exact --id {package} --accept-package-agreements --accept-source-agreements", Automatic: true},
+	{Name: "choco", Platforms: []string{"windows"}, Program: "choco", Command: "choco install -y {package}"},
+	{Name: "apt", Platforms: []string{"linux"}, Program: "apt-get", Command: "sudo apt-get install -y {package}"},
+}
+
+// goos is the operating system installers are found for; replaced by tests
+var goos = runtime.GOOS
+
+// Command is a command that installs a tool with an installer
+type Command struct {
+	Installer string `json:"installer"`
+	Command   string `json:"command"`
+	Automatic bool   `json:"automatic"` // It can run unattended as a fix
+}
+
+// Lookup returns the known installer with a name
+func Lookup(name string) (Installer, bool) {
+	for _, installer := range Known {
+		if installer.Name == name {
+			return installer, true
+		}
+	}
+	return Installer{}, false
+}
+
+// Names returns the names of the known installers
+func Names() []string {
+	names := make([]string, len(Known))
+	for i, installer := range Known {
+		names[i] = installer.Name
+	}
+	return names
+}
+
+// Available returns the known installers found on this machine, in order of preference. Only
+// this machine is searched, so none are found when commands run with another executor.
+func Available(ctx context.Context) []Installer {
+	if !runner.IsLocal(runner.ExecutorFrom(ctx)) {
+		return nil
+	}
+	var available []Installer
+	for _, installer := range Known {
+		if !installer.supports(goos) {
+			continue
+		}
+		if installer.Program != "" {
+			if _, err := exec.LookPath(installer.Program); err != nil {
+				continue
+			}
+		}
+		if installer.Marker != "" {
+			if _, err := os.Stat(os.ExpandEnv(installer.Marker)); err != nil {
+				continue
+			}
+		}
+		available = append(available, installer)
+	}
+	return available
+}
+
+// Advise returns the commands that install a tool with the installers that package it, in the
+// installers' order. Packages are keyed by installer name; {version} in a package is replaced
+// with version, and packages needing a version are left out without one.
+func Advise(packages map[string]string, version string, installers []Installer) []Command {
+	var commands []Command
+	for _, installer := range installers {
+		pkg := packages[installer.Name]
+		if pkg == "" || (version == "" && strings.Contains(pkg, "{version}")) {
+			continue
+		}
+		pkg = strings.ReplaceAll(pkg, "{version}", version)
+		commands = append(commands, Command{
+			Installer: installer.Name,
+			Command:   strings.ReplaceAll(installer.Command, "{package}", pkg),
+			Automatic: installer.Automatic,
+		})
+	}
+	return commands
+}
+
+// Missing reports whether a program isn't on the PATH of this machine. Only plain program names
+// are looked up: programs given as a path or with a variable, and all programs when commands run
+// with another executor, aren't missing.
+func Missing(ctx context.Context, program string) bool {
+	if program == "" || strings.ContainsAny(program, `/\$=`) || !runner.IsLocal(runner.ExecutorFrom(ctx)) {
+		return false
+	}
+	_, err := exec.LookPath(program)
+	return err != nil
+}
+
+// supports reports whether the installer installs on an operating system
+func (i Installer) supports(os string) bool {
+	for _, platform := range i.Platforms {
+		if platform == os {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package installer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdvise(t *testing.T) {
+	brew, _ := Lookup("brew")
+	apt, _ := Lookup("apt")
+	packages := map[string]string{"brew": "openjdk@{version}", "apt": "default-jdk", "winget": "EclipseAdoptium.Temurin.{version}.JDK"}
+
+	commands := Advise(packages, "21", []Installer{brew, apt})
+	assert.Equal(t, []Command{
+		{Installer: "brew", Command: "brew install openjdk@21", Automatic: true},
+		{Installer: "apt", Command: "sudo apt-get install -y default-jdk"},
+	}, commands)
+
+	// Without a version only packages that don't need one are advised
+	commands = Advise(packages, "", []Installer{brew, apt})
+	assert.Equal(t, []Command{{Installer: "apt", Command: "sudo apt-get install -y default-jdk"}}, commands)
+
+	assert.Empty(t, Advise(nil, "21", []Installer{brew}))
+}
+
+func TestLookup(t *testing.T) {
+	installer, ok := Lookup("winget")
+	require.True(t, ok)
+	assert.Equal(t, []string{"windows"}, installer.Platforms)
+
+	_, ok = Lookup("pacman")
+	assert.False(t, ok)
+
+	assert.Equal(t, []string{"asdf", "sdkman", "brew", "scoop", "winget", "choco", "apt"}, Names())
+}
+
+func TestAvailable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	defer func(previous string) { goos = previous }(goos)
+	goos = "linux"
+
+	bin := t.TempDir()
+	for _, program := range []string{"brew", "apt-get", "winget"} {
+		require.NoError(t, os.WriteFile(filepath.Join(bin, program), []byte("#!/bin/sh\n"), 0755))
+	}
+	t.Setenv("PATH", bin)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	names := func() []string {
+		var names []string
+		for _, installer := range Available(context.Background()) {
+			names = append(names, installer.Name)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"brew", "apt"}, names())
+
+	// sdkman is a shell function, found by its init script
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".sdkman", "bin"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".sdkman", "bin", "sdkman-init.sh"), nil, 0644))
+	assert.Equal(t, []string{"sdkman", "brew", "apt"}, names())
+
+	goos = "windows"
+	assert.Equal(t, []string{"winget"}, names())
+}
+
+func TestMissing(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "mvn"), []byte("#!/bin/sh\n"), 0755))
+	t.Setenv("PATH", bin)
+
+	ctx := context.Background()
+	assert.False(t, Missing(ctx, "mvn"))
+	assert.True(t, Missing(ctx, "gradle"))
+
+	// Paths, variables and assignments aren't looked up
+	assert.False(t, Missing(ctx, "./mvnw"))
+	assert.False(t, Missing(ctx, "${JBOSS_HOME}/bin/standalone.sh"))
+	assert.False(t, Missing(ctx, "JAVA_HOME=/opt/jdk"))
+	assert.False(t, Missing(ctx, ""))
+}
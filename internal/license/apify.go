@@ -0,0 +1,206 @@
+package license
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ApifyAPIEnvVar overrides the Apify API base URL
+const ApifyAPIEnvVar = "SENTINEL_APIFY_API_URL"
+
+// defaultApifyAPI is the Apify API base URL
+const defaultApifyAPI = "https://api.apify.com/v2"
+
+// apifyCacheFile caches the last token verification next to the stored license
+const apifyCacheFile = "apify-token.json"
+
+const (
+	apifyCacheTTL     = 6 * time.Hour      // A verification is reused this long
+	apifyOfflineGrace = 7 * 24 * time.Hour // and this long while the API is unreachable
+)
+
+// apifyClient calls the Apify API
+var apifyClient = &http.Client{Timeout: 5 * time.Second}
+
+// apifyVerification is the outcome of checking a token with the Apify API
+type apifyVerification struct {
+	TokenHash string    `json:"token_hash"` // The token itself is never written to the cache
+	Entitled  bool      `json:"entitled"`
+	Reason    string    `json:"reason,omitempty"` // Why the token isn't entitled to Pro
+	CheckedAt time.Time `json:"checked_at"`
+	MAC       string    `json:"mac"` // HMAC of the fields keyed with the token, so the cache can't be written without it
+}
+
+// apifyUser is the part of the token's user the entitlement check reads
+type apifyUser struct {
+	Data struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+// apifyRun is the part of an Actor run the entitlement check reads
+type apifyRun struct {
+	Data struct {
+		ActID  string `json:"actId"`
+		Status string `json:"status"`
+	} `json:"data"`
+}
+
+// validateApifyToken validates an Apify token with the Apify API. The token must belong to
+// an Apify user, and Pro is granted only inside a running run of the sentinel Actor, which
+// Apify starts only for users renting it. Verifications are cached, and reused for a grace
+// period while the API is unreachable.
+func (lv *LicenseValidator) validateApifyToken(token string) (*License, error) {
+	invalid := &License{Key: token, IsValid: false, Tier: "free"}
+	if !strings.HasPrefix(token, "apify_") || len(token) <= 10 {
+		return invalid, fmt.Errorf("invalid Apify token")
+	}
+
+	cached, hasCache := lv.loadApifyVerification(token)
+
+	verification := cached
+	if !hasCache || time.Since(cached.CheckedAt) > apifyCacheTTL {
+		fresh, err := verifyApifyToken(context.Background(), apifyAPI(), token)
+		switch {
+		case err == nil:
+			fresh.TokenHash = hashKey(token)
+			verification = fresh
+			lv.saveApifyVerification(verification, token)
+		case hasCache && time.Since(cached.CheckedAt) <= apifyOfflineGrace:
+			// Offline: trust the last verification for a while
+		default:
+			return invalid, fmt.Errorf("could not verify Apify token: %w", err)
+		}
+	}
+
+	if !verification.Entitled {
+		return invalid, fmt.Errorf("Apify token not entitled to Pro: %s", verification.Reason)
+	}
+	return &License{
+		Key:      token,
+		IsValid:  true,
+		Tier:     "pro",
+		Features: lv.getFeaturesForTier("pro"),
+	}, nil
+}
+
+// verifyApifyToken asks the Apify API whether a token is valid and entitled to Pro. It fails
+// only when the API couldn't answer; a rejected token is a verification that isn't entitled.
+func verifyApifyToken(ctx context.Context, api, token string) (apifyVerification, error) {
+	verification := apifyVerification{CheckedAt: time.Now().UTC()}
+
+	var user apifyUser
+	status, err := apifyGet(ctx, api+"/users/me", token, &user)
+	if err != nil {
+		return verification, err
+	}
+	if status != http.StatusOK || user.Data.ID == "" {
+		verification.Reason = fmt.Sprintf("the token was rejected by Apify (HTTP %d)", status)
+		return verification, nil
+	}
+
+	runID := os.Getenv("APIFY_ACTOR_RUN_ID")
+	if runID == "" {
+		verification.Reason = "Apify tokens unlock Pro only in runs of the sentinel Actor"
+		return verification, nil
+	}
+	var run apifyRun
+	status, err = apifyGet(ctx, api+"/actor-runs/"+runID, token, &run)
+	if err != nil {
+		return verification, err
+	}
+	actorID := os.Getenv("SENTINEL_APIFY_ACTOR_ID")
+	if actorID == "" {
+		actorID = os.Getenv("APIFY_ACTOR_ID")
+	}
+	switch {
+	case actorID == "":
+		verification.Reason = "the sentinel Actor's ID is unknown (APIFY_ACTOR_ID is unset)"
+	case status != http.StatusOK || run.Data.ActID == "":
+		verification.Reason = fmt.Sprintf("run %s is not visible to the token", runID)
+	case run.Data.ActID != actorID:
+		verification.Reason = fmt.Sprintf("run %s is not a run of the sentinel Actor", runID)
+	case run.Data.Status != "RUNNING":
+		verification.Reason = fmt.Sprintf("run %s is %s", runID, strings.ToLower(run.Data.Status))
+	default:
+		verification.Entitled = true
+	}
+	return verification, nil
+}
+
+// apifyGet calls the Apify API and decodes a successful JSON response into v. Server errors
+// fail like network errors, since they say nothing about the token.
+func apifyGet(ctx context.Context, url, token string, v interface{}) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := apifyClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return resp.StatusCode, fmt.Errorf("Apify API returned %s", resp.Status)
+	}
+	if resp.StatusCode == http.StatusOK && v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return resp.StatusCode, fmt.Errorf("invalid Apify API response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// apifyAPI returns the Apify API base URL
+func apifyAPI() string {
+	if api := os.Getenv(ApifyAPIEnvVar); api != "" {
+		return strings.TrimSuffix(api, "/")
+	}
+	return defaultApifyAPI
+}
+
+// mac authenticates a cached verification with the token it was made for
+func (v apifyVerification) mac(token string) string {
+	h := hmac.New(sha256.New, []byte(token))
+	fmt.Fprintf(h, "%s\n%t\n%s\n%s", v.TokenHash, v.Entitled, v.Reason, v.CheckedAt.UTC().Format(time.RFC3339Nano))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// loadApifyVerification returns the cached verification of a token. Entries that weren't
+// written for the token, or were checked in the future, are ignored.
+func (lv *LicenseValidator) loadApifyVerification(token string) (apifyVerification, bool) {
+	var cached apifyVerification
+	data, err := os.ReadFile(filepath.Join(lv.cacheDir, apifyCacheFile))
+	if err != nil || json.Unmarshal(data, &cached) != nil || cached.TokenHash != hashKey(token) {
+		return apifyVerification{}, false
+	}
+	if !hmac.Equal([]byte(cached.MAC), []byte(cached.mac(token))) || cached.CheckedAt.After(time.Now()) {
+		return apifyVerification{}, false
+	}
+	return cached, true
+}
+
+// saveApifyVerification caches a verification; failing to is harmless
+func (lv *LicenseValidator) saveApifyVerification(verification apifyVerification, token string) {
+	if lv.cacheDir == "" {
+		return
+	}
+	verification.MAC = verification.mac(token)
+	data, err := json.MarshalIndent(verification, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(lv.cacheDir, 0755); err == nil {
+		os.WriteFile(filepath.Join(lv.cacheDir, apifyCacheFile), data, 0600)
+	}
+}
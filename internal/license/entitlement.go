@@ -0,0 +1,93 @@
+package license
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RenewalWindow is how long before a license expires renewal reminders start
+const RenewalWindow = 14 * 24 * time.Hour
+
+// entitlementFile caches the last validated license state next to the stored license
+const entitlementFile = "entitlement.json"
+
+// Entitlement is the validated state of a license key, cached so that a key that stops
+// validating (expired, revoked, a rental that ended) is reported as lapsed instead of the
+// server silently falling back to the free tier
+type Entitlement struct {
+	KeyHash     string     `json:"key_hash"` // SHA-256 of the key; the key itself is in license.json
+	Tier        string     `json:"tier"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	ValidatedAt time.Time  `json:"validated_at"`
+}
+
+// SaveEntitlement caches the state of a valid license; others aren't cached
+func (s *Storage) SaveEntitlement(lic *License) error {
+	if lic == nil || !lic.IsValid || lic.Key == "" {
+		return nil
+	}
+	if err := os.MkdirAll(s.configDir, 0755); err != nil {
+		return err
+	}
+	entitlement := Entitlement{
+		KeyHash:     hashKey(lic.Key),
+		Tier:        lic.Tier,
+		ExpiresAt:   lic.ExpiresAt,
+		ValidatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	data, err := json.MarshalIndent(entitlement, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.configDir, entitlementFile), data, 0600)
+}
+
+// LoadEntitlement returns the cached state of a key, or nil when the cache is of another key
+func (s *Storage) LoadEntitlement(key string) *Entitlement {
+	data, err := os.ReadFile(filepath.Join(s.configDir, entitlementFile))
+	if err != nil {
+		return nil
+	}
+	var entitlement Entitlement
+	if json.Unmarshal(data, &entitlement) != nil || key == "" || entitlement.KeyHash != hashKey(key) {
+		return nil
+	}
+	return &entitlement
+}
+
+// RenewalNotice returns a reminder for a license that expires within RenewalWindow of now or
+// expired while the server ran, or for a key that validated before (lapsed, from the cache)
+// but no longer does. It is empty when there is nothing to renew.
+func RenewalNotice(lic *License, lapsed *Entitlement, now time.Time) string {
+	renew := fmt.Sprintf("Renew at %s and activate the new key with 'activate_pro'.", GetStripePaymentLink())
+	switch {
+	case lic == nil:
+		return ""
+	case lic.IsValid && lic.ExpiresAt != nil && !now.Before(*lic.ExpiresAt):
+		return fmt.Sprintf("⚠️ Your %s license expired on %s; it stays active until the server restarts. %s",
+			lic.Tier, lic.ExpiresAt.Format("2006-01-02"), renew)
+	case lic.IsValid && lic.ExpiresAt != nil && lic.ExpiresAt.Sub(now) <= RenewalWindow:
+		days := int((lic.ExpiresAt.Sub(now) + 24*time.Hour - 1) / (24 * time.Hour))
+		return fmt.Sprintf("⏳ Your %s license expires on %s (in %d days). %s",
+			lic.Tier, lic.ExpiresAt.Format("2006-01-02"), days, renew)
+	case !lic.IsValid && lapsed != nil:
+		if lapsed.ExpiresAt != nil && !now.Before(*lapsed.ExpiresAt) {
+			return fmt.Sprintf("⚠️ Your %s license expired on %s; running in the free tier. %s",
+				lapsed.Tier, lapsed.ExpiresAt.Format("2006-01-02"), renew)
+		}
+		return fmt.Sprintf("⚠️ Your %s license, last validated %s, is no longer valid; running in the free tier. %s",
+			lapsed.Tier, lapsed.ValidatedAt.Format("2006-01-02"), renew)
+	}
+	return ""
+}
+
+// hashKey identifies a license key without storing it again
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,6 +1,7 @@
 package license
 
 import (
+	"crypto/ed25519"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
@@ -17,11 +18,23 @@ type License struct {
 	Tier      string // "free", "pro", "enterprise"
 	ExpiresAt *time.Time
 	Features  []string
+	Legacy    bool    // An HMAC key, accepted until LegacyCutoff
+	Trials    []Trial // Trials started on this machine; active ones unlock their feature
+	Claims    []string // Feature flags granted by a signed key on top of its tier
 }
 
+// devSecret is the HMAC secret used when SENTINEL_LICENSE_SECRET is unset. It is public, so
+// legacy keys are never accepted with it.
+const devSecret = "dev-secret-key-change-in-production"
+
+// GeneratableTiers are the tiers license keys can be generated for
+var GeneratableTiers = []string{"pro", "enterprise"}
+
 // LicenseValidator validates license keys
 type LicenseValidator struct {
-	secretKey string // Secret key for HMAC validation
+	secretKey string            // Secret key for validating legacy HMAC keys
+	publicKey ed25519.PublicKey // Key verifying signed license keys
+	cacheDir  string            // Where Apify token verifications are cached
 }
 
 // NewLicenseValidator creates a new license validator
@@ -31,10 +44,31 @@ func NewLicenseValidator() *LicenseValidator {
 	secretKey := os.Getenv("SENTINEL_LICENSE_SECRET")
 	if secretKey == "" {
 		// Default secret for development (should be changed in production)
-		secretKey = "dev-secret-key-change-in-production"
+		secretKey = devSecret
 	}
 	return &LicenseValidator{
 		secretKey: secretKey,
+		publicKey: trustedKey,
+		cacheDir:  NewStorage().configDir,
+	}
+}
+
+// NewLicenseValidatorWithSecret creates a license validator checking legacy HMAC keys with an
+// explicit secret
+func NewLicenseValidatorWithSecret(secretKey string) *LicenseValidator {
+	return &LicenseValidator{
+		secretKey: secretKey,
+		publicKey: trustedKey,
+		cacheDir:  NewStorage().configDir,
+	}
+}
+
+// NewLicenseValidatorWithPublicKey creates a license validator verifying signed keys with a
+// public key, e.g. a self-hosted deployment's own
+func NewLicenseValidatorWithPublicKey(publicKey ed25519.PublicKey) *LicenseValidator {
+	return &LicenseValidator{
+		publicKey: publicKey,
+		cacheDir:  NewStorage().configDir,
 	}
 }
 
@@ -54,7 +88,11 @@ func (lv *LicenseValidator) ValidateLicense(key string) (*License, error) {
 		return lv.validateApifyToken(key)
 	}
 
-	// Validate standard license key format: tier-hmac-timestamp
+	if strings.HasPrefix(key, signedPrefix) {
+		return lv.validateSigned(key)
+	}
+
+	// Legacy key format: tier-hmac-timestamp
 	parts := strings.Split(key, "-")
 	if len(parts) != 3 {
 		return &License{
@@ -68,6 +106,15 @@ func (lv *LicenseValidator) ValidateLicense(key string) (*License, error) {
 	timestamp := parts[2]
 	providedHMAC := parts[1]
 
+	// Anyone can compute keys for the public development secret, and after the transition
+	// window no HMAC key is trusted
+	if lv.secretKey == "" || lv.secretKey == devSecret {
+		return &License{Key: key, IsValid: false, Tier: "free"}, fmt.Errorf("legacy license keys need SENTINEL_LICENSE_SECRET; ask for a signed key")
+	}
+	if !time.Now().Before(LegacyCutoff) {
+		return &License{Key: key, IsValid: false, Tier: "free"}, fmt.Errorf("legacy license keys are no longer accepted since %s; ask for a signed key", LegacyCutoff.Format("2006-01-02"))
+	}
+
 	// Verify HMAC
 	expectedHMAC := lv.computeHMAC(fmt.Sprintf("%s-%s", tier, timestamp))
 	if !hmac.Equal([]byte(providedHMAC), []byte(expectedHMAC)) {
@@ -104,28 +151,10 @@ func (lv *LicenseValidator) ValidateLicense(key string) (*License, error) {
 		Tier:      tier,
 		ExpiresAt: expiresAt,
 		Features:  features,
+		Legacy:    true,
 	}, nil
 }
 
-// validateApifyToken validates an Apify token
-func (lv *LicenseValidator) validateApifyToken(token string) (*License, error) {
-	// In Apify deployments, the token is validated by Apify's infrastructure
-	// We just need to check if it's present and has the right format
-	if strings.HasPrefix(token, "apify_") && len(token) > 10 {
-		return &License{
-			Key:      token,
-			IsValid:  true,
-			Tier:     "pro",
-			Features: lv.getFeaturesForTier("pro"),
-		}, nil
-	}
-	return &License{
-		Key:     token,
-		IsValid: false,
-		Tier:    "free",
-	}, fmt.Errorf("invalid Apify token")
-}
-
 // computeHMAC computes HMAC-SHA256 of the message
 func (lv *LicenseValidator) computeHMAC(message string) string {
 	h := hmac.New(sha256.New, []byte(lv.secretKey))
@@ -168,6 +197,9 @@ func (lv *LicenseValidator) getFeaturesForTier(tier string) []string {
 
 // HasFeature checks if a license has a specific feature
 func (l *License) HasFeature(feature string) bool {
+	if l.TrialFor(feature) != nil {
+		return true
+	}
 	if !l.IsValid {
 		return false
 	}
@@ -176,9 +208,42 @@ func (l *License) HasFeature(feature string) bool {
 			return true
 		}
 	}
+	for _, f := range l.Claims {
+		if f == feature {
+			return true
+		}
+	}
 	return false
 }
 
+// PaidFeatures returns the features a paid tier includes but the free tier doesn't. Only a
+// license or a trial can enable them.
+func PaidFeatures() []string {
+	lv := &LicenseValidator{}
+	free := make(map[string]bool)
+	for _, f := range lv.getFeaturesForTier("free") {
+		free[f] = true
+	}
+	var paid []string
+	for _, f := range lv.getFeaturesForTier("enterprise") {
+		if !free[f] {
+			paid = append(paid, f)
+		}
+	}
+	return paid
+}
+
+// TrialFor returns the active trial unlocking a feature, if any
+func (l *License) TrialFor(feature string) *Trial {
+	now := time.Now()
+	for i, t := range l.Trials {
+		if t.Feature == feature && t.Active(now) {
+			return &l.Trials[i]
+		}
+	}
+	return nil
+}
+
 // GetStripePaymentLink returns the Stripe payment link for Pro license
 func GetStripePaymentLink() string {
 	// This should be set via environment variable or config
@@ -1,7 +1,13 @@
 package license
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -33,46 +39,136 @@ func TestValidateLicense_InvalidFormat(t *testing.T) {
 	assert.Equal(t, "free", lic.Tier)
 }
 
+// fakeApify serves the Apify API for tokens starting with apify_valid, with one run of
+// actor "sentinel" in a status
+func fakeApify(t *testing.T, runStatus string) *httptest.Server {
+	t.Helper()
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer apify_valid") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/users/me":
+			fmt.Fprint(w, `{"data":{"id":"u1","username":"dev"}}`)
+		case "/actor-runs/run1":
+			fmt.Fprintf(w, `{"data":{"id":"run1","actId":"sentinel","status":%q}}`, runStatus)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(api.Close)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	t.Setenv(ApifyAPIEnvVar, api.URL)
+	t.Setenv("APIFY_ACTOR_ID", "sentinel")
+	t.Setenv("SENTINEL_APIFY_ACTOR_ID", "")
+	t.Setenv("APIFY_ACTOR_RUN_ID", "run1")
+	return api
+}
+
 func TestValidateLicense_ApifyToken(t *testing.T) {
-	validator := NewLicenseValidator()
-	
 	tests := []struct {
-		name    string
-		token   string
-		wantErr bool
+		name      string
+		token     string
+		runStatus string
+		runID     string
+		err       string
 	}{
-		{
-			name:    "valid Apify token",
-			token:   "apify_1234567890abcdef",
-			wantErr: false,
-		},
-		{
-			name:    "invalid Apify token (too short)",
-			token:   "apify_123",
-			wantErr: true,
-		},
-		{
-			name:    "not Apify token",
-			token:   "pro-abc-123",
-			wantErr: true,
-		},
+		{name: "rented actor run", token: "apify_valid_1234", runStatus: "RUNNING", runID: "run1"},
+		{name: "finished run", token: "apify_valid_1234", runStatus: "SUCCEEDED", runID: "run1", err: "run run1 is succeeded"},
+		{name: "outside a run", token: "apify_valid_1234", runStatus: "RUNNING", err: "only in runs of the sentinel Actor"},
+		{name: "run of another actor", token: "apify_valid_1234", runStatus: "RUNNING", runID: "run2", err: "run run2 is not visible"},
+		{name: "rejected token", token: "apify_1234567890abcdef", runStatus: "RUNNING", runID: "run1", err: "rejected by Apify"},
+		{name: "too short", token: "apify_123", err: "invalid Apify token"},
+		{name: "not Apify token", token: "pro-abc-123", err: "legacy license keys"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			lic, err := validator.ValidateLicense(tt.token)
-			if tt.wantErr {
-				assert.Error(t, err)
+			fakeApify(t, tt.runStatus)
+			t.Setenv("APIFY_ACTOR_RUN_ID", tt.runID)
+			lic, err := NewLicenseValidator().ValidateLicense(tt.token)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
 				assert.False(t, lic.IsValid)
-			} else {
-				assert.NoError(t, err)
-				assert.True(t, lic.IsValid)
-				assert.Equal(t, "pro", lic.Tier)
+				return
 			}
+			require.NoError(t, err)
+			assert.True(t, lic.IsValid)
+			assert.Equal(t, "pro", lic.Tier)
 		})
 	}
 }
 
+func TestValidateLicense_ApifyTokenOffline(t *testing.T) {
+	api := fakeApify(t, "RUNNING")
+	lic, err := NewLicenseValidator().ValidateLicense("apify_valid_1234")
+	require.NoError(t, err)
+	assert.True(t, lic.IsValid)
+	data, err := os.ReadFile(filepath.Join(os.Getenv("HOME"), ".dev-env-sentinel", apifyCacheFile))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "apify_valid_1234", "the token isn't written to the cache")
+
+	// A stale verification is still used while the API is unreachable
+	cachePath := filepath.Join(os.Getenv("HOME"), ".dev-env-sentinel", apifyCacheFile)
+	var cached apifyVerification
+	require.NoError(t, json.Unmarshal(data, &cached))
+	writeCache := func(v apifyVerification, token string) {
+		v.MAC = v.mac(token)
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(cachePath, data, 0600))
+	}
+	cached.CheckedAt = time.Now().Add(-apifyCacheTTL - time.Hour)
+	writeCache(cached, "apify_valid_1234")
+	api.Close()
+	lic, err = NewLicenseValidator().ValidateLicense("apify_valid_1234")
+	require.NoError(t, err)
+	assert.True(t, lic.IsValid)
+
+	_, err = NewLicenseValidator().ValidateLicense("apify_valid_other")
+	assert.ErrorContains(t, err, "could not verify Apify token")
+
+	// Entries that weren't written by a verification are ignored: edited, or checked in the future
+	edited := cached
+	edited.Entitled = false
+	edited.MAC = edited.mac("apify_valid_1234")
+	edited.Entitled = true
+	data, err = json.Marshal(edited)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(cachePath, data, 0600))
+	_, err = NewLicenseValidator().ValidateLicense("apify_valid_1234")
+	assert.ErrorContains(t, err, "could not verify Apify token")
+
+	future := cached
+	future.CheckedAt = time.Now().Add(365 * 24 * time.Hour)
+	writeCache(future, "apify_valid_1234")
+	_, err = NewLicenseValidator().ValidateLicense("apify_valid_1234")
+	assert.ErrorContains(t, err, "could not verify Apify token")
+}
+
+func TestValidateLicense_ApifyTokenFailsClosed(t *testing.T) {
+	fakeApify(t, "RUNNING")
+
+	// Without the Actor's ID any run would do
+	t.Setenv("APIFY_ACTOR_ID", "")
+	_, err := NewLicenseValidator().ValidateLicense("apify_valid_1234")
+	assert.ErrorContains(t, err, "Actor's ID is unknown")
+
+	// Answers other than 200 with the user don't accept the token
+	for _, status := range []int{http.StatusNotFound, http.StatusTeapot} {
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+		t.Setenv(ApifyAPIEnvVar, api.URL)
+		t.Setenv("HOME", t.TempDir())
+		_, err = NewLicenseValidator().ValidateLicense("apify_valid_1234")
+		assert.ErrorContains(t, err, "rejected by Apify", status)
+		api.Close()
+	}
+}
+
 func TestValidateLicense_Expired(t *testing.T) {
 	// Create an expired license key (format: tier-hmac-timestamp)
 	// For testing, we'll use a past date
@@ -210,3 +306,181 @@ func TestComputeHMAC(t *testing.T) {
 	assert.Len(t, hmac1, 16) // Should be 16 characters
 }
 
+
+// legacyKey computes an HMAC key the way keys were issued before signed keys
+func legacyKey(secret, tier, timestamp string) string {
+	return fmt.Sprintf("%s-%s-%s", tier, NewLicenseValidatorWithSecret(secret).computeHMAC(tier+"-"+timestamp), timestamp)
+}
+
+func TestSignLicense(t *testing.T) {
+	public, private, err := GenerateKeyPair()
+	require.NoError(t, err)
+	privateKey, err := ParsePrivateKey(private)
+	require.NoError(t, err)
+	publicKey, err := ParsePublicKey(public)
+	require.NoError(t, err)
+	validator := NewLicenseValidatorWithPublicKey(publicKey)
+
+	key, err := SignLicense(privateKey, "enterprise", nil)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, "v2."))
+	lic, err := validator.ValidateLicense(key)
+	require.NoError(t, err)
+	assert.True(t, lic.IsValid)
+	assert.False(t, lic.Legacy)
+	assert.Equal(t, "enterprise", lic.Tier)
+	assert.Nil(t, lic.ExpiresAt)
+	assert.True(t, lic.HasFeature("custom_configs"))
+
+	expires := time.Now().AddDate(1, 0, 0)
+	key, err = SignLicense(privateKey, "pro", &expires)
+	require.NoError(t, err)
+	lic, err = validator.ValidateLicense(key)
+	require.NoError(t, err)
+	assert.Equal(t, "pro", lic.Tier)
+	require.NotNil(t, lic.ExpiresAt)
+	assert.Equal(t, expires.UTC().Format("2006-01-02"), lic.ExpiresAt.Format("2006-01-02"))
+	assert.Empty(t, lic.Claims)
+
+	// Keys can grant feature flags on top of their tier
+	claimed, err := SignLicense(privateKey, "pro", nil, "docker_orchestration", "beta_ui")
+	require.NoError(t, err)
+	lic, err = validator.ValidateLicense(claimed)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docker_orchestration", "beta_ui"}, lic.Claims)
+	assert.True(t, lic.HasFeature("docker_orchestration"))
+
+	// A key verifies only with the public key of its signer, and can't be altered
+	_, err = NewLicenseValidator().ValidateLicense(key)
+	assert.Error(t, err)
+	payload := strings.Split(key, ".")[1]
+	forged := strings.Replace(key, payload, strings.Replace(payload, "cHJv", "ZW50", 1), 1)
+	lic, err = validator.ValidateLicense(forged)
+	assert.EqualError(t, err, "invalid license key")
+	assert.False(t, lic.IsValid)
+}
+
+func TestSignLicense_Refused(t *testing.T) {
+	_, private, err := GenerateKeyPair()
+	require.NoError(t, err)
+	privateKey, err := ParsePrivateKey(private)
+	require.NoError(t, err)
+
+	_, err = SignLicense(privateKey, "free", nil)
+	assert.EqualError(t, err, "unknown tier: free (expected pro or enterprise)")
+	past := time.Now().AddDate(0, 0, -1)
+	_, err = SignLicense(privateKey, "pro", &past)
+	assert.ErrorContains(t, err, "is in the past")
+	_, err = SignLicense(privateKey, "pro", nil, "Beta UI")
+	assert.ErrorContains(t, err, "invalid feature flag name")
+
+	_, err = ParsePrivateKey("not-a-key")
+	assert.Error(t, err)
+}
+
+func TestSignedLicense_TrustedKey(t *testing.T) {
+	public, private, err := GenerateKeyPair()
+	require.NoError(t, err)
+	privateKey, err := ParsePrivateKey(private)
+	require.NoError(t, err)
+	key, err := SignLicense(privateKey, "pro", nil)
+	require.NoError(t, err)
+
+	// The environment can't replace the embedded key, or anyone could mint Pro keys
+	t.Setenv("SENTINEL_LICENSE_PUBLIC_KEY", public)
+	lic, err := NewLicenseValidator().ValidateLicense(key)
+	assert.Error(t, err)
+	assert.False(t, lic.IsValid)
+
+	publicKey, err := ParsePublicKey(public)
+	require.NoError(t, err)
+	restore := TrustKeyForTesting(publicKey)
+	lic, err = NewLicenseValidator().ValidateLicense(key)
+	require.NoError(t, err)
+	assert.True(t, lic.IsValid)
+	restore()
+	_, err = NewLicenseValidator().ValidateLicense(key)
+	assert.Error(t, err)
+}
+
+func TestSign(t *testing.T) {
+	public, private, err := GenerateKeyPair()
+	require.NoError(t, err)
+	publicKey, err := ParsePublicKey(public)
+	require.NoError(t, err)
+	privateKey, err := ParsePrivateKey(private)
+	require.NoError(t, err)
+
+	data := []byte(`{"configs": []}`)
+	signature := Sign(privateKey, "sentinel-config-index", data)
+	assert.True(t, Verify(publicKey, "sentinel-config-index", data, signature+"\n"))
+	assert.False(t, Verify(publicKey, "sentinel-config-index", []byte(`{"configs": null}`), signature))
+	assert.False(t, Verify(publicKey, "sentinel-release", data, signature), "a signature is only valid in its domain")
+	assert.False(t, Verify(publicKey, "sentinel-config-index", data, "not base64"))
+}
+
+func TestValidateLicense_Legacy(t *testing.T) {
+	key := legacyKey("team-secret", "pro", "lifetime")
+
+	lic, err := NewLicenseValidatorWithSecret("team-secret").ValidateLicense(key)
+	require.NoError(t, err)
+	assert.True(t, lic.IsValid)
+	assert.True(t, lic.Legacy)
+
+	_, err = NewLicenseValidatorWithSecret(devSecret).ValidateLicense(legacyKey(devSecret, "pro", "lifetime"))
+	assert.ErrorContains(t, err, "legacy license keys need SENTINEL_LICENSE_SECRET", "keys for the public secret are forgeable")
+
+	cutoff := LegacyCutoff
+	defer func() { LegacyCutoff = cutoff }()
+	LegacyCutoff = time.Now().Add(-time.Hour)
+	lic, err = NewLicenseValidatorWithSecret("team-secret").ValidateLicense(key)
+	assert.ErrorContains(t, err, "no longer accepted")
+	assert.False(t, lic.IsValid)
+}
+
+func TestHasFeature_Trial(t *testing.T) {
+	now := time.Now()
+	lic := &License{IsValid: false, Tier: "free", Trials: []Trial{
+		{Feature: "reconcile_environment", StartedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)},
+		{Feature: "auto_fix", StartedAt: now.AddDate(0, 0, -8), ExpiresAt: now.AddDate(0, 0, -1)},
+	}}
+	assert.True(t, lic.HasFeature("reconcile_environment"))
+	assert.NotNil(t, lic.TrialFor("reconcile_environment"))
+	assert.False(t, lic.HasFeature("auto_fix"), "ended trials unlock nothing")
+	assert.Equal(t, []string{"reconcile_environment", "auto_fix", "advanced_diagnostics"}, TrialFeatures())
+}
+
+func TestRenewalNotice(t *testing.T) {
+	t.Setenv("SENTINEL_STRIPE_PAYMENT_LINK", "https://buy.example.com/renew")
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	in := func(d time.Duration) *time.Time {
+		at := now.Add(d)
+		return &at
+	}
+
+	tests := []struct {
+		name   string
+		lic    *License
+		lapsed *Entitlement
+		want   string
+	}{
+		{"lifetime", &License{IsValid: true, Tier: "pro"}, nil, ""},
+		{"far from expiry", &License{IsValid: true, Tier: "pro", ExpiresAt: in(30 * 24 * time.Hour)}, nil, ""},
+		{"within window", &License{IsValid: true, Tier: "pro", ExpiresAt: in(4*24*time.Hour - time.Hour)}, nil, "expires on 2026-10-20 (in 4 days)"},
+		{"expired mid-session", &License{IsValid: true, Tier: "pro", ExpiresAt: in(-time.Hour)}, nil, "stays active until the server restarts"},
+		{"free", &License{Tier: "free"}, nil, ""},
+		{"lapsed by expiry", &License{Tier: "pro"}, &Entitlement{Tier: "pro", ExpiresAt: in(-48 * time.Hour)}, "expired on 2026-10-14; running in the free tier"},
+		{"lapsed otherwise", &License{Tier: "free"}, &Entitlement{Tier: "pro", ValidatedAt: now.AddDate(0, 0, -3)}, "last validated 2026-10-13, is no longer valid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notice := RenewalNotice(tt.lic, tt.lapsed, now)
+			if tt.want == "" {
+				assert.Empty(t, notice)
+				return
+			}
+			assert.Contains(t, notice, tt.want)
+			assert.Contains(t, notice, "https://buy.example.com/renew")
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// signedPrefix marks license keys signed with Ed25519: v2.<payload>.<signature>, both
+// base64url encoded
+const signedPrefix = "v2."
+
+// EmbeddedPublicKey verifies the signed license keys sold for the sentinel, and the other
+// artifacts the maintainers sign, such as the community config index. Only the maintainers
+// hold its private key, so reading the source doesn't let anyone forge keys.
+const EmbeddedPublicKey = "6ZkjmyVNULTqSQx+S4Y7cd/LAecIMkmk4Wu1yD81730="
+
+// LegacyCutoff ends the transition from HMAC license keys: they are rejected from then on
+var LegacyCutoff = time.Date(2027, time.July, 1, 0, 0, 0, 0, time.UTC)
+
+// Payload is the signed content of a license key
+type Payload struct {
+	Tier     string    `json:"tier"`
+	Expires  string    `json:"expires,omitempty"` // YYYY-MM-DD from which the key is invalid; never when empty
+	IssuedAt time.Time `json:"issued_at"`
+	Features []string  `json:"features,omitempty"` // Feature flags granted on top of the tier
+}
+
+// GenerateKeyPair creates an Ed25519 key pair for signing license keys, base64 encoded
+func GenerateKeyPair() (publicKey, privateKey string, err error) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate key pair: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(public), base64.StdEncoding.EncodeToString(private), nil
+}
+
+// ParsePublicKey decodes a base64 Ed25519 public key
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 public key")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// ParsePrivateKey decodes a base64 Ed25519 private key
+func ParsePrivateKey(s string) (ed25519.PrivateKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 private key")
+	}
+	return ed25519.PrivateKey(key), nil
+}
+
+// Sign signs data for another purpose than a license key, such as the config index, and
+// returns the base64 signature. The domain is signed ahead of the data, so a signature made
+// for one purpose never verifies for another.
+func Sign(privateKey ed25519.PrivateKey, domain string, data []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, append([]byte(domain+"\n"), data...)))
+}
+
+// Verify checks a base64 signature created by Sign for a domain
+func Verify(publicKey ed25519.PublicKey, domain string, data []byte, signature string) bool {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signature))
+	return err == nil && ed25519.Verify(publicKey, append([]byte(domain+"\n"), data...), sig)
+}
+
+// trustedKey verifies license keys. It's the embedded key: a deployment can't replace it, or
+// anyone could sign their own Pro keys.
+var trustedKey, _ = ParsePublicKey(EmbeddedPublicKey)
+
+// TrustKeyForTesting makes validators trust another public key until the returned function
+// restores the embedded one. Only tests call it, to sign keys the server accepts.
+func TrustKeyForTesting(publicKey ed25519.PublicKey) (restore func()) {
+	previous := trustedKey
+	trustedKey = publicKey
+	return func() { trustedKey = previous }
+}
+
+// SignLicense creates a license key for a tier, valid until the start of the expiry day or
+// forever when expiresAt is nil, that also grants the given feature flags. Keys verify with
+// the public key of privateKey.
+func SignLicense(privateKey ed25519.PrivateKey, tier string, expiresAt *time.Time, features ...string) (string, error) {
+	known := false
+	for _, t := range GeneratableTiers {
+		if t == tier {
+			known = true
+		}
+	}
+	if !known {
+		return "", fmt.Errorf("unknown tier: %s (expected %s)", tier, strings.Join(GeneratableTiers, " or "))
+	}
+
+	for _, f := range features {
+		if !ValidFlagName(f) {
+			return "", fmt.Errorf("invalid feature flag name: %q", f)
+		}
+	}
+
+	payload := Payload{Tier: tier, IssuedAt: time.Now().UTC().Truncate(time.Second), Features: features}
+	if expiresAt != nil {
+		if !expiresAt.After(time.Now()) {
+			return "", fmt.Errorf("expiry %s is in the past", expiresAt.Format("2006-01-02"))
+		}
+		payload.Expires = expiresAt.UTC().Format("2006-01-02")
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	signature := ed25519.Sign(privateKey, data)
+	return signedPrefix + base64.RawURLEncoding.EncodeToString(data) + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// validateSigned verifies an Ed25519-signed license key
+func (lv *LicenseValidator) validateSigned(key string) (*License, error) {
+	invalid := &License{Key: key, IsValid: false, Tier: "free"}
+	parts := strings.Split(strings.TrimPrefix(key, signedPrefix), ".")
+	if len(parts) != 2 {
+		return invalid, fmt.Errorf("invalid license key format")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return invalid, fmt.Errorf("invalid license key format")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !ed25519.Verify(lv.publicKey, data, signature) {
+		return invalid, fmt.Errorf("invalid license key")
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return invalid, fmt.Errorf("invalid license key payload")
+	}
+	var expiresAt *time.Time
+	if payload.Expires != "" {
+		expTime, err := time.Parse("2006-01-02", payload.Expires)
+		if err != nil {
+			return invalid, fmt.Errorf("invalid license key payload")
+		}
+		if time.Now().After(expTime) {
+			return &License{Key: key, IsValid: false, Tier: payload.Tier, ExpiresAt: &expTime}, fmt.Errorf("license expired")
+		}
+		expiresAt = &expTime
+	}
+
+	return &License{
+		Key:       key,
+		IsValid:   true,
+		Tier:      payload.Tier,
+		ExpiresAt: expiresAt,
+		Features:  lv.getFeaturesForTier(payload.Tier),
+		Claims:    payload.Features,
+	}, nil
+}
+
+// ValidFlagName reports whether a feature flag name is lowercase letters, digits and
+// underscores, starting with a letter
+func ValidFlagName(name string) bool {
+	if name == "" || name[0] < 'a' || name[0] > 'z' {
+		return false
+	}
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' {
+			return false
+		}
+	}
+	return true
+}
@@ -24,58 +24,70 @@ func NewStorage() *Storage {
 	}
 }
 
+// licenseFile holds the license key, and a copy of the trial records
+const licenseFile = "license.json"
+
 // SaveLicense saves a license key to disk
 func (s *Storage) SaveLicense(key string) error {
-	// Ensure config directory exists
-	if err := os.MkdirAll(s.configDir, 0755); err != nil {
+	data, err := s.loadLicenseFile()
+	if err != nil {
 		return err
 	}
+	data["key"] = key
+	return s.saveLicenseFile(data)
+}
 
-	licenseFile := filepath.Join(s.configDir, "license.json")
-	data := map[string]string{
-		"key": key,
+// LoadLicense loads a license key from disk
+func (s *Storage) LoadLicense() (string, error) {
+	data, err := s.loadLicenseFile()
+	if err != nil {
+		return "", err
 	}
+	return data["key"], nil // No license file is OK
+}
 
-	file, err := os.Create(licenseFile)
+// ClearLicense removes the stored license. The copy of the trial records stays.
+func (s *Storage) ClearLicense() error {
+	data, err := s.loadLicenseFile()
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(data)
+	delete(data, "key")
+	if len(data) > 0 {
+		return s.saveLicenseFile(data)
+	}
+	err = os.Remove(filepath.Join(s.configDir, licenseFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
 }
 
-// LoadLicense loads a license key from disk
-func (s *Storage) LoadLicense() (string, error) {
-	licenseFile := filepath.Join(s.configDir, "license.json")
-	
+// loadLicenseFile reads the fields of the license file; a missing file has none
+func (s *Storage) loadLicenseFile() (map[string]string, error) {
 	data := make(map[string]string)
-	file, err := os.Open(licenseFile)
+	content, err := os.ReadFile(filepath.Join(s.configDir, licenseFile))
 	if err != nil {
 		if os.IsNotExist(err) {
-			return "", nil // No license file is OK
+			return data, nil
 		}
-		return "", err
+		return nil, err
 	}
-	defer file.Close()
-
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&data); err != nil {
-		return "", err
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, err
 	}
-
-	return data["key"], nil
+	return data, nil
 }
 
-// ClearLicense removes the stored license
-func (s *Storage) ClearLicense() error {
-	licenseFile := filepath.Join(s.configDir, "license.json")
-	err := os.Remove(licenseFile)
-	if err != nil && !os.IsNotExist(err) {
+// saveLicenseFile writes the fields of the license file
+func (s *Storage) saveLicenseFile(data map[string]string) error {
+	// Ensure config directory exists
+	if err := os.MkdirAll(s.configDir, 0755); err != nil {
 		return err
 	}
-	return nil
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.configDir, licenseFile), append(content, '\n'), 0600)
 }
-
@@ -3,7 +3,9 @@ package license
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -96,3 +98,93 @@ func TestSaveLicense_CreatesDirectory(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestStartTrial(t *testing.T) {
+	storage := &Storage{configDir: t.TempDir()}
+	now := time.Now()
+
+	trial, err := storage.StartTrial("machine-a", "reconcile_environment", DefaultTrialDays, now)
+	require.NoError(t, err)
+	assert.True(t, trial.Active(now))
+	assert.False(t, trial.Active(now.AddDate(0, 0, DefaultTrialDays+1)))
+	assert.False(t, trial.Active(now.Add(-time.Hour)), "setting the clock back doesn't revive a trial")
+
+	trials, err := storage.LoadTrials("machine-a")
+	require.NoError(t, err)
+	assert.Equal(t, []Trial{trial}, trials)
+
+	_, err = storage.StartTrial("machine-a", "reconcile_environment", 3, now.AddDate(0, 1, 0))
+	assert.ErrorContains(t, err, "already used on this machine")
+	_, err = storage.StartTrial("machine-a", "verify_build_freshness", 3, now)
+	assert.ErrorContains(t, err, "unknown trial feature")
+	_, err = storage.StartTrial("machine-a", "auto_fix", MaxTrialDays+1, now)
+	assert.ErrorContains(t, err, "between 1 and 14")
+
+	// Records copied to another machine unlock nothing there
+	trials, err = storage.LoadTrials("machine-b")
+	require.NoError(t, err)
+	assert.Empty(t, trials)
+}
+
+func TestLoadTrials_Tampered(t *testing.T) {
+	storage := &Storage{configDir: t.TempDir()}
+	trial, err := storage.StartTrial("machine-a", "reconcile_environment", 3, time.Now())
+	require.NoError(t, err)
+
+	path := filepath.Join(storage.configDir, trialFile)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	extended := strings.Replace(string(data), trial.ExpiresAt.Format(time.RFC3339), trial.ExpiresAt.AddDate(1, 0, 0).Format(time.RFC3339), 1)
+	require.NotEqual(t, string(data), extended)
+	require.NoError(t, os.WriteFile(path, []byte(extended), 0600))
+
+	_, err = storage.LoadTrials("machine-a")
+	assert.ErrorIs(t, err, ErrTrialsTampered)
+	_, err = storage.StartTrial("machine-a", "auto_fix", 3, time.Now())
+	assert.ErrorIs(t, err, ErrTrialsTampered)
+}
+
+func TestLoadTrials_TrialFileDeleted(t *testing.T) {
+	storage := &Storage{configDir: t.TempDir()}
+	now := time.Now()
+	trial, err := storage.StartTrial("machine-a", "reconcile_environment", 3, now)
+	require.NoError(t, err)
+
+	// The copy in the license file survives saving and clearing the license
+	require.NoError(t, storage.SaveLicense("test-key"))
+	require.NoError(t, storage.ClearLicense())
+	require.NoError(t, os.Remove(filepath.Join(storage.configDir, trialFile)))
+
+	trials, err := storage.LoadTrials("machine-a")
+	require.NoError(t, err)
+	assert.Equal(t, []Trial{trial}, trials)
+	_, err = storage.StartTrial("machine-a", "reconcile_environment", 3, now.AddDate(0, 1, 0))
+	assert.ErrorContains(t, err, "already used on this machine")
+
+	// Tampering with the copy is detected like tampering with the trial file
+	path := filepath.Join(storage.configDir, "license.json")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(data), "reconcile_environment", "auto_fix", 1)), 0600))
+	_, err = storage.LoadTrials("machine-a")
+	assert.ErrorIs(t, err, ErrTrialsTampered)
+}
+
+func TestEntitlement(t *testing.T) {
+	storage := &Storage{configDir: t.TempDir()}
+	assert.Nil(t, storage.LoadEntitlement("v2.key"))
+
+	require.NoError(t, storage.SaveEntitlement(&License{Key: "v2.invalid", IsValid: false, Tier: "pro"}))
+	assert.Nil(t, storage.LoadEntitlement("v2.invalid"), "invalid licenses aren't cached")
+
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, storage.SaveEntitlement(&License{Key: "v2.key", IsValid: true, Tier: "pro", ExpiresAt: &expires}))
+	entitlement := storage.LoadEntitlement("v2.key")
+	require.NotNil(t, entitlement)
+	assert.Equal(t, "pro", entitlement.Tier)
+	assert.True(t, expires.Equal(*entitlement.ExpiresAt))
+	assert.Nil(t, storage.LoadEntitlement("v2.other"))
+
+	data, err := os.ReadFile(filepath.Join(storage.configDir, entitlementFile))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "v2.key", "the key isn't stored again")
+}
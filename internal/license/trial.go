@@ -0,0 +1,195 @@
+package license
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTrialDays = 7  // Trial length when none is asked for
+	MaxTrialDays     = 14 // Longest trial
+)
+
+// trialFile records the trials started on this machine, next to the stored license
+const trialFile = "trials.json"
+
+// licenseTrialsField holds a copy of the trial records in the license file, so deleting
+// trialFile doesn't make the trials available again
+const licenseTrialsField = "trials"
+
+// trialSigningKey keys the signature of the trial records. It is in the source, so the
+// signature only stops casual edits (extending a trial, copying records between machines),
+// not someone rebuilding the sentinel.
+const trialSigningKey = "dev-env-sentinel trial records"
+
+// ErrTrialsTampered is returned when the trial records don't match their signature
+var ErrTrialsTampered = errors.New("trial records were modified; trials are unavailable on this machine")
+
+// Trial unlocks a premium feature on one machine for a limited time
+type Trial struct {
+	Feature   string    `json:"feature"`
+	StartedAt time.Time `json:"started_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Active reports whether the trial unlocks its feature at now. Setting the clock back before
+// the start doesn't revive a trial.
+func (t Trial) Active(now time.Time) bool {
+	return !now.Before(t.StartedAt) && now.Before(t.ExpiresAt)
+}
+
+// trialRecords is the signed content of the trial file
+type trialRecords struct {
+	MachineID string  `json:"machine_id"`
+	Trials    []Trial `json:"trials"`
+	Signature string  `json:"signature"`
+}
+
+// TrialFeatures returns the features that can be trialed: those of Pro missing from Free
+func TrialFeatures() []string {
+	lv := &LicenseValidator{}
+	free := make(map[string]bool)
+	for _, f := range lv.getFeaturesForTier("free") {
+		free[f] = true
+	}
+	var features []string
+	for _, f := range lv.getFeaturesForTier("pro") {
+		if !free[f] {
+			features = append(features, f)
+		}
+	}
+	return features
+}
+
+// LoadTrials returns the trials started on a machine. Records of another machine are ignored,
+// and edited records fail with ErrTrialsTampered.
+func (s *Storage) LoadTrials(machineID string) ([]Trial, error) {
+	records, err := s.loadTrialRecords(machineID)
+	if err != nil {
+		return nil, err
+	}
+	return records.Trials, nil
+}
+
+// StartTrial starts a trial of a feature on a machine for days days. Each feature can be
+// trialed once per machine.
+func (s *Storage) StartTrial(machineID, feature string, days int, now time.Time) (Trial, error) {
+	known := false
+	for _, f := range TrialFeatures() {
+		if f == feature {
+			known = true
+		}
+	}
+	if !known {
+		return Trial{}, fmt.Errorf("unknown trial feature: %s (expected one of %s)", feature, strings.Join(TrialFeatures(), ", "))
+	}
+	if days < 1 || days > MaxTrialDays {
+		return Trial{}, fmt.Errorf("trial days must be between 1 and %d", MaxTrialDays)
+	}
+
+	records, err := s.loadTrialRecords(machineID)
+	if err != nil {
+		return Trial{}, err
+	}
+	for _, t := range records.Trials {
+		if t.Feature == feature {
+			if t.Active(now) {
+				return Trial{}, fmt.Errorf("the %s trial is already running until %s", feature, t.ExpiresAt.Format("2006-01-02 15:04"))
+			}
+			return Trial{}, fmt.Errorf("the %s trial was already used on this machine", feature)
+		}
+	}
+
+	now = now.UTC().Truncate(time.Second)
+	trial := Trial{Feature: feature, StartedAt: now, ExpiresAt: now.AddDate(0, 0, days)}
+	records.Trials = append(records.Trials, trial)
+	sort.Slice(records.Trials, func(i, j int) bool { return records.Trials[i].Feature < records.Trials[j].Feature })
+	if err := s.saveTrialRecords(records); err != nil {
+		return Trial{}, err
+	}
+	return trial, nil
+}
+
+// loadTrialRecords reads and verifies the trial file and its copy in the license file, which
+// restores the records when one of them is deleted. No records, or records written on another
+// machine, yield empty records for machineID.
+func (s *Storage) loadTrialRecords(machineID string) (trialRecords, error) {
+	empty := trialRecords{MachineID: machineID}
+	data, err := os.ReadFile(filepath.Join(s.configDir, trialFile))
+	if err != nil && !os.IsNotExist(err) {
+		return empty, err
+	}
+	licenseData, err := s.loadLicenseFile()
+	if err != nil {
+		return empty, err
+	}
+
+	var records *trialRecords
+	for _, content := range []string{string(data), licenseData[licenseTrialsField]} {
+		if content == "" {
+			continue
+		}
+		var r trialRecords
+		if err := json.Unmarshal([]byte(content), &r); err != nil {
+			return empty, ErrTrialsTampered
+		}
+		if !hmac.Equal([]byte(r.Signature), []byte(signTrials(r))) {
+			return empty, ErrTrialsTampered
+		}
+		// Records only grow, so the copy with more trials is the latest
+		if records == nil || len(r.Trials) > len(records.Trials) {
+			records = &r
+		}
+	}
+	if records == nil {
+		return empty, nil
+	}
+	if records.MachineID != machineID {
+		// Copied from another machine, or the home directory moved to a new one
+		return empty, nil
+	}
+	return *records, nil
+}
+
+// saveTrialRecords signs and writes the trial file and its copy in the license file
+func (s *Storage) saveTrialRecords(records trialRecords) error {
+	if err := os.MkdirAll(s.configDir, 0755); err != nil {
+		return err
+	}
+	records.Signature = signTrials(records)
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(s.configDir, trialFile), data, 0600); err != nil {
+		return err
+	}
+	licenseData, err := s.loadLicenseFile()
+	if err != nil {
+		return err
+	}
+	compact, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	licenseData[licenseTrialsField] = string(compact)
+	return s.saveLicenseFile(licenseData)
+}
+
+// signTrials computes the signature of trial records, binding them to their machine
+func signTrials(records trialRecords) string {
+	h := hmac.New(sha256.New, []byte(trialSigningKey+"/"+records.MachineID))
+	for _, t := range records.Trials {
+		fmt.Fprintf(h, "%s|%d|%d\n", t.Feature, t.StartedAt.Unix(), t.ExpiresAt.Unix())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
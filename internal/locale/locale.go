@@ -0,0 +1,160 @@
+package locale
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/config"
+)
+
+// LocaleReport contains time-zone, locale and clock check results
+type LocaleReport struct {
+	EcosystemID  string
+	Timezone     string
+	Locale       string
+	ClockOffset  time.Duration
+	ClockChecked bool
+	IsHealthy    bool
+	Issues       []string
+}
+
+// CheckLocale runs the locale checks configured for an ecosystem
+func CheckLocale(ctx context.Context, cfg *config.EcosystemConfig) (*LocaleReport, error) {
+	localeCfg := cfg.Ecosystem.Locale
+	report := &LocaleReport{
+		EcosystemID: cfg.Ecosystem.ID,
+		Timezone:    currentTimezone(),
+		Locale:      EffectiveLocale(),
+		IsHealthy:   true,
+		Issues:      []string{},
+	}
+
+	if !localeCfg.Enabled {
+		return report, nil
+	}
+
+	if localeCfg.RequiredTimezone != "" {
+		if issue := checkTimezone(localeCfg.RequiredTimezone, time.Now()); issue != "" {
+			report.IsHealthy = false
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+
+	if issue := checkLocaleName(report.Locale, localeCfg); issue != "" {
+		report.IsHealthy = false
+		report.Issues = append(report.Issues, issue)
+	}
+
+	if localeCfg.MaxClockDrift != "" {
+		maxDrift, err := time.ParseDuration(localeCfg.MaxClockDrift)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max_clock_drift %q: %w", localeCfg.MaxClockDrift, err)
+		}
+
+		server := localeCfg.NTPServer
+		if server == "" {
+			server = defaultNTPServer
+		}
+
+		offset, err := QueryClockOffset(ctx, server)
+		if err == nil {
+			report.ClockChecked = true
+			report.ClockOffset = offset
+			if absDuration(offset) > maxDrift {
+				report.IsHealthy = false
+				report.Issues = append(report.Issues, fmt.Sprintf(
+					"System clock is off by %s (max %s); token validation and TLS may fail. Resync with NTP (e.g. 'sudo timedatectl set-ntp true')",
+					offset.Round(time.Millisecond), maxDrift))
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// EffectiveLocale returns the locale the C library would use for character handling
+func EffectiveLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// currentTimezone returns a readable name for the local time zone
+func currentTimezone() string {
+	if tz := os.Getenv("TZ"); tz != "" {
+		return tz
+	}
+	name, offset := time.Now().Zone()
+	return fmt.Sprintf("%s (UTC%+03d:%02d)", name, offset/3600, abs(offset%3600)/60)
+}
+
+// checkTimezone compares the local UTC offset with the required zone's offset at the given instant
+func checkTimezone(required string, now time.Time) string {
+	loc, err := time.LoadLocation(required)
+	if err != nil {
+		return fmt.Sprintf("Unknown required timezone %q: %v", required, err)
+	}
+
+	_, localOffset := now.Zone()
+	_, requiredOffset := now.In(loc).Zone()
+	if localOffset != requiredOffset {
+		return fmt.Sprintf("Local timezone (%s) differs from required %s; set TZ=%s", currentTimezone(), required, required)
+	}
+	return ""
+}
+
+// checkLocaleName validates the effective locale against UTF-8 and allow-list requirements
+func checkLocaleName(current string, localeCfg config.Locale) string {
+	if current == "" || current == "C" || current == "POSIX" {
+		if localeCfg.RequireUTF8 || len(localeCfg.RequiredLocales) > 0 {
+			return "No locale configured (LANG/LC_ALL unset or C/POSIX); set LANG to a UTF-8 locale such as en_US.UTF-8"
+		}
+		return ""
+	}
+
+	if localeCfg.RequireUTF8 && !isUTF8Locale(current) {
+		return fmt.Sprintf("Locale %s is not UTF-8; non-ASCII source files and test fixtures may be misread", current)
+	}
+
+	if len(localeCfg.RequiredLocales) > 0 {
+		for _, required := range localeCfg.RequiredLocales {
+			if normalizeLocale(required) == normalizeLocale(current) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("Locale %s is not one of the required locales: %s", current, strings.Join(localeCfg.RequiredLocales, ", "))
+	}
+
+	return ""
+}
+
+// isUTF8Locale reports whether a locale name uses the UTF-8 codeset
+func isUTF8Locale(name string) bool {
+	normalized := normalizeLocale(name)
+	return strings.HasSuffix(normalized, ".utf8") || strings.Contains(normalized, ".utf8@")
+}
+
+// normalizeLocale lowercases a locale and strips dashes from its codeset ("en_US.UTF-8" -> "en_us.utf8")
+func normalizeLocale(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "-", "")
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,122 @@
+package locale
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveLocale(t *testing.T) {
+	tests := []struct {
+		name     string
+		lcAll    string
+		lcCtype  string
+		lang     string
+		expected string
+	}{
+		{"LC_ALL wins", "de_DE.UTF-8", "fr_FR.UTF-8", "en_US.UTF-8", "de_DE.UTF-8"},
+		{"LC_CTYPE over LANG", "", "fr_FR.UTF-8", "en_US.UTF-8", "fr_FR.UTF-8"},
+		{"LANG fallback", "", "", "en_US.UTF-8", "en_US.UTF-8"},
+		{"nothing set", "", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LC_ALL", tt.lcAll)
+			t.Setenv("LC_CTYPE", tt.lcCtype)
+			t.Setenv("LANG", tt.lang)
+
+			assert.Equal(t, tt.expected, EffectiveLocale())
+		})
+	}
+}
+
+func TestCheckLocaleName(t *testing.T) {
+	tests := []struct {
+		name      string
+		current   string
+		cfg       config.Locale
+		expectErr bool
+	}{
+		{"utf8 required and present", "en_US.UTF-8", config.Locale{RequireUTF8: true}, false},
+		{"utf8 alias spelling", "en_US.utf8", config.Locale{RequireUTF8: true}, false},
+		{"utf8 required but latin1", "en_US.ISO-8859-1", config.Locale{RequireUTF8: true}, true},
+		{"posix locale with requirement", "C", config.Locale{RequireUTF8: true}, true},
+		{"posix locale without requirement", "C", config.Locale{}, false},
+		{"required locale matches", "en_US.UTF-8", config.Locale{RequiredLocales: []string{"en_US.utf8"}}, false},
+		{"required locale mismatch", "de_DE.UTF-8", config.Locale{RequiredLocales: []string{"en_US.UTF-8"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issue := checkLocaleName(tt.current, tt.cfg)
+			if tt.expectErr {
+				assert.NotEmpty(t, issue)
+			} else {
+				assert.Empty(t, issue)
+			}
+		})
+	}
+}
+
+func TestCheckTimezone(t *testing.T) {
+	t.Setenv("TZ", "UTC")
+	now := time.Now().In(time.UTC)
+
+	assert.Empty(t, checkTimezone("UTC", now))
+	assert.NotEmpty(t, checkTimezone("Asia/Tokyo", now))
+	assert.Contains(t, checkTimezone("Not/AZone", now), "Unknown required timezone")
+}
+
+func TestCheckLocale_Disabled(t *testing.T) {
+	t.Setenv("LANG", "C")
+	cfg := &config.EcosystemConfig{
+		Ecosystem: config.Ecosystem{
+			ID:     "test",
+			Locale: config.Locale{Enabled: false, RequireUTF8: true},
+		},
+	}
+
+	report, err := CheckLocale(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.True(t, report.IsHealthy)
+}
+
+func TestCheckLocale_ClockDrift(t *testing.T) {
+	t.Setenv("LC_ALL", "en_US.UTF-8")
+	addr := startFakeNTPServer(t, 10*time.Second)
+
+	cfg := &config.EcosystemConfig{
+		Ecosystem: config.Ecosystem{
+			ID: "test",
+			Locale: config.Locale{
+				Enabled:       true,
+				RequireUTF8:   true,
+				MaxClockDrift: "2s",
+				NTPServer:     addr,
+			},
+		},
+	}
+
+	report, err := CheckLocale(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.True(t, report.ClockChecked)
+	assert.False(t, report.IsHealthy)
+	require.Len(t, report.Issues, 1)
+	assert.Contains(t, report.Issues[0], "System clock is off")
+}
+
+func TestCheckLocale_InvalidDrift(t *testing.T) {
+	cfg := &config.EcosystemConfig{
+		Ecosystem: config.Ecosystem{
+			Locale: config.Locale{Enabled: true, MaxClockDrift: "soon"},
+		},
+	}
+
+	_, err := CheckLocale(context.Background(), cfg)
+	assert.Error(t, err)
+}
@@ -0,0 +1,72 @@
+package locale
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	defaultNTPServer = "pool.ntp.org:123"
+
+	// ntpEpochOffset is the number of seconds between the NTP epoch (1900) and the Unix epoch (1970)
+	ntpEpochOffset = 2208988800
+)
+
+// QueryClockOffset asks an NTP server for the time and returns how far the local clock is off.
+// A positive offset means the local clock is behind the server.
+func QueryClockOffset(ctx context.Context, server string) (time.Duration, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "123")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", server)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reach NTP server %s: %w", server, err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	// SNTP client request: LI=0, VN=4, Mode=3
+	request := make([]byte, 48)
+	request[0] = 0x23
+
+	sent := time.Now()
+	if _, err := conn.Write(request); err != nil {
+		return 0, fmt.Errorf("failed to send NTP request: %w", err)
+	}
+
+	response := make([]byte, 48)
+	if _, err := conn.Read(response); err != nil {
+		return 0, fmt.Errorf("failed to read NTP response: %w", err)
+	}
+	received := time.Now()
+
+	// Transmit timestamp lives at bytes 40-47
+	serverTime := ntpToTime(binary.BigEndian.Uint32(response[40:44]), binary.BigEndian.Uint32(response[44:48]))
+	if serverTime.IsZero() {
+		return 0, fmt.Errorf("NTP server %s returned an empty timestamp", server)
+	}
+
+	// Compare against the midpoint of the round trip
+	localMidpoint := sent.Add(received.Sub(sent) / 2)
+	return serverTime.Sub(localMidpoint), nil
+}
+
+// ntpToTime converts an NTP timestamp to time.Time
+func ntpToTime(seconds, fraction uint32) time.Time {
+	if seconds == 0 && fraction == 0 {
+		return time.Time{}
+	}
+	nanos := (int64(fraction) * 1e9) >> 32
+	return time.Unix(int64(seconds)-ntpEpochOffset, nanos)
+}
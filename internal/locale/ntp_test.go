@@ -0,0 +1,74 @@
+package locale
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// timeToNTP converts time.Time to an NTP timestamp
+func timeToNTP(t time.Time) (uint32, uint32) {
+	seconds := uint32(t.Unix() + ntpEpochOffset)
+	fraction := uint32((int64(t.Nanosecond()) << 32) / 1e9)
+	return seconds, fraction
+}
+
+// startFakeNTPServer answers SNTP requests with the local time shifted by skew
+func startFakeNTPServer(t *testing.T, skew time.Duration) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 48)
+		for {
+			_, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			response := make([]byte, 48)
+			response[0] = 0x24 // LI=0, VN=4, Mode=4 (server)
+			seconds, fraction := timeToNTP(time.Now().Add(skew))
+			binary.BigEndian.PutUint32(response[40:44], seconds)
+			binary.BigEndian.PutUint32(response[44:48], fraction)
+			conn.WriteTo(response, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestQueryClockOffset(t *testing.T) {
+	tests := []struct {
+		name string
+		skew time.Duration
+	}{
+		{"in sync", 0},
+		{"local clock behind", 30 * time.Second},
+		{"local clock ahead", -45 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := startFakeNTPServer(t, tt.skew)
+
+			offset, err := QueryClockOffset(context.Background(), addr)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.skew.Seconds(), offset.Seconds(), 0.5)
+		})
+	}
+}
+
+func TestNTPTimestampRoundTrip(t *testing.T) {
+	now := time.Now()
+	seconds, fraction := timeToNTP(now)
+	converted := ntpToTime(seconds, fraction)
+
+	assert.WithinDuration(t, now, converted, time.Microsecond)
+	assert.True(t, ntpToTime(0, 0).IsZero())
+}
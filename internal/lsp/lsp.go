@@ -0,0 +1,314 @@
+// Package lsp publishes environment issues as Language Server Protocol diagnostics over stdio,
+// so editors with an LSP client show environment drift as squiggles on the offending files
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"dev-env-sentinel/internal/report"
+)
+
+// Source names the sentinel as the origin of its diagnostics in the editor
+const Source = "sentinel"
+
+// LSP diagnostic severities
+const (
+	severityError   = 1
+	severityWarning = 2
+)
+
+// Checker runs the checks of a project. Findings need a File, relative to the project root,
+// to be shown; findings without one are dropped.
+type Checker func(ctx context.Context, projectRoot string) ([]report.Finding, error)
+
+// Server is a diagnostics-only language server. It checks the workspace when the editor
+// connects and again whenever a file is saved.
+type Server struct {
+	check  Checker
+	in     *bufio.Reader
+	out    io.Writer
+	outMu  sync.Mutex
+	root   string
+	queued chan struct{}
+
+	publishedMu sync.Mutex
+	published   map[string]bool // URIs with diagnostics, cleared when their issues are gone
+}
+
+// NewServer creates a language server reading requests from in and writing to out
+func NewServer(check Checker, in io.Reader, out io.Writer) *Server {
+	return &Server{
+		check:     check,
+		in:        bufio.NewReader(in),
+		out:       out,
+		queued:    make(chan struct{}, 1),
+		published: make(map[string]bool),
+	}
+}
+
+// message is a JSON-RPC request, response or notification
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *responseError  `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Diagnostic is an LSP diagnostic
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Code     string `json:"code,omitempty"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+// Range is a zero-based LSP text range
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Position is a zero-based line and character offset
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Serve handles messages until the editor sends exit or closes the stream
+func (s *Server) Serve(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go s.runChecks(ctx)
+
+	for {
+		msg, err := s.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch msg.Method {
+		case "initialize":
+			s.root = rootPath(msg.Params)
+			s.respond(msg.ID, map[string]interface{}{
+				"capabilities": map[string]interface{}{
+					"textDocumentSync": map[string]interface{}{"openClose": true, "change": 0, "save": true},
+				},
+				"serverInfo": map[string]interface{}{"name": "dev-env-sentinel"},
+			})
+		case "initialized", "textDocument/didSave":
+			s.schedule()
+		case "shutdown":
+			s.respond(msg.ID, nil)
+		case "exit":
+			return nil
+		default:
+			// Other notifications are ignored; other requests aren't supported
+			if len(msg.ID) > 0 {
+				s.write(message{JSONRPC: "2.0", ID: msg.ID, Error: &responseError{Code: -32601, Message: "method not found: " + msg.Method}})
+			}
+		}
+	}
+}
+
+// schedule queues a check run. Saves arriving while a run is queued are folded into it.
+func (s *Server) schedule() {
+	select {
+	case s.queued <- struct{}{}:
+	default:
+	}
+}
+
+// runChecks checks the workspace each time a run is scheduled
+func (s *Server) runChecks(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.queued:
+		}
+		if s.root == "" {
+			continue
+		}
+
+		findings, err := s.check(ctx, s.root)
+		if err != nil {
+			s.notify("window/logMessage", map[string]interface{}{"type": severityError, "message": "sentinel: " + err.Error()})
+			continue
+		}
+		s.publish(findings)
+	}
+}
+
+// publish sends the diagnostics of each file with findings, and clears the diagnostics of
+// files whose issues have been resolved since the last run
+func (s *Server) publish(findings []report.Finding) {
+	byURI := make(map[string][]Diagnostic)
+	for _, f := range findings {
+		if f.File == "" {
+			continue
+		}
+		uri := fileURI(filepath.Join(s.root, filepath.FromSlash(f.File)))
+		byURI[uri] = append(byURI[uri], toDiagnostic(f))
+	}
+
+	s.publishedMu.Lock()
+	defer s.publishedMu.Unlock()
+	for uri := range s.published {
+		if _, ok := byURI[uri]; !ok {
+			byURI[uri] = []Diagnostic{}
+		}
+	}
+
+	uris := make([]string, 0, len(byURI))
+	for uri := range byURI {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+
+	s.published = make(map[string]bool)
+	for _, uri := range uris {
+		diagnostics := byURI[uri]
+		if len(diagnostics) > 0 {
+			s.published[uri] = true
+		}
+		s.notify("textDocument/publishDiagnostics", map[string]interface{}{"uri": uri, "diagnostics": diagnostics})
+	}
+}
+
+// toDiagnostic converts a finding to a diagnostic spanning its line, or the first line of
+// the file when the finding has no line
+func toDiagnostic(f report.Finding) Diagnostic {
+	line := 0
+	if f.Line > 0 {
+		line = f.Line - 1
+	}
+	severity := severityError
+	if f.Severity == report.SeverityWarning {
+		severity = severityWarning
+	}
+	return Diagnostic{
+		Range:    Range{Start: Position{Line: line}, End: Position{Line: line + 1}},
+		Severity: severity,
+		Code:     f.Check,
+		Source:   Source,
+		Message:  f.Message,
+	}
+}
+
+func (s *Server) respond(id json.RawMessage, result interface{}) {
+	if result == nil {
+		// A null result must still be sent
+		result = json.RawMessage("null")
+	}
+	s.write(message{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *Server) notify(method string, params interface{}) {
+	data, _ := json.Marshal(params)
+	s.write(message{JSONRPC: "2.0", Method: method, Params: data})
+}
+
+// read reads one message framed by a Content-Length header
+func (s *Server) read() (*message, error) {
+	header, err := textproto.NewReader(s.in).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length: %q", header.Get("Content-Length"))
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, err
+	}
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+	return &msg, nil
+}
+
+// write writes one message framed by a Content-Length header
+func (s *Server) write(msg message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(data), data)
+}
+
+// rootPath returns the workspace root of initialize params: rootUri, the first workspace
+// folder, or the deprecated rootPath
+func rootPath(params json.RawMessage) string {
+	var init struct {
+		RootURI          string `json:"rootUri"`
+		RootPath         string `json:"rootPath"`
+		WorkspaceFolders []struct {
+			URI string `json:"uri"`
+		} `json:"workspaceFolders"`
+	}
+	if err := json.Unmarshal(params, &init); err != nil {
+		return ""
+	}
+	switch {
+	case init.RootURI != "":
+		return uriPath(init.RootURI)
+	case len(init.WorkspaceFolders) > 0:
+		return uriPath(init.WorkspaceFolders[0].URI)
+	default:
+		return init.RootPath
+	}
+}
+
+// uriPath converts a file URI to a path
+func uriPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	path := u.Path
+	// file:///C:/src has the path /C:/src
+	if runtime.GOOS == "windows" && len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path)
+}
+
+// fileURI converts an absolute path to a file URI
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,137 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"dev-env-sentinel/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// client drives a server over in-memory pipes
+type client struct {
+	t   *testing.T
+	in  io.Writer
+	out *bufio.Reader
+}
+
+func startServer(t *testing.T, check Checker) *client {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	server := NewServer(check, inReader, outWriter)
+
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(context.Background()) }()
+	t.Cleanup(func() {
+		inWriter.Close()
+		<-done
+		outWriter.Close()
+	})
+	return &client{t: t, in: inWriter, out: bufio.NewReader(outReader)}
+}
+
+func (c *client) send(id int, method string, params interface{}) {
+	msg := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+	if id > 0 {
+		msg["id"] = id
+	}
+	data, err := json.Marshal(msg)
+	require.NoError(c.t, err)
+	_, err = fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	require.NoError(c.t, err)
+}
+
+func (c *client) receive() map[string]interface{} {
+	header, err := textproto.NewReader(c.out).ReadMIMEHeader()
+	require.NoError(c.t, err)
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	require.NoError(c.t, err)
+	body := make([]byte, length)
+	_, err = io.ReadFull(c.out, body)
+	require.NoError(c.t, err)
+
+	var msg map[string]interface{}
+	require.NoError(c.t, json.Unmarshal(body, &msg))
+	return msg
+}
+
+func TestServer_PublishesDiagnostics(t *testing.T) {
+	root := t.TempDir()
+	runs := make(chan []report.Finding, 2)
+	runs <- []report.Finding{
+		{Check: "env_var_audit", Severity: "error", Message: "Environment variable API_KEY is not set", File: "src/App.java", Line: 12},
+		{Check: "verify_build_freshness", Severity: "warning", Message: "pom.xml is newer than target/app.jar", File: "pom.xml"},
+		{Check: "check_infrastructure_parity", Severity: "error", Message: "docker is not running"},
+	}
+	runs <- []report.Finding{
+		{Check: "verify_build_freshness", Severity: "warning", Message: "pom.xml is newer than target/app.jar", File: "pom.xml"},
+	}
+	var checked string
+	c := startServer(t, func(ctx context.Context, projectRoot string) ([]report.Finding, error) {
+		checked = projectRoot
+		return <-runs, nil
+	})
+
+	c.send(1, "initialize", map[string]interface{}{"rootUri": fileURI(root)})
+	resp := c.receive()
+	assert.Equal(t, float64(1), resp["id"])
+	capabilities := resp["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	assert.Equal(t, true, capabilities["textDocumentSync"].(map[string]interface{})["save"])
+
+	c.send(0, "initialized", map[string]interface{}{})
+	pom := c.receive()
+	app := c.receive()
+	assert.Equal(t, root, checked)
+	assert.Equal(t, "textDocument/publishDiagnostics", pom["method"])
+
+	params := pom["params"].(map[string]interface{})
+	assert.Equal(t, fileURI(filepath.Join(root, "pom.xml")), params["uri"])
+	diagnostic := params["diagnostics"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, float64(severityWarning), diagnostic["severity"])
+	assert.Equal(t, "sentinel", diagnostic["source"])
+	assert.Equal(t, "verify_build_freshness", diagnostic["code"])
+
+	params = app["params"].(map[string]interface{})
+	assert.Equal(t, fileURI(filepath.Join(root, "src", "App.java")), params["uri"])
+	diagnostic = params["diagnostics"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, float64(11), diagnostic["range"].(map[string]interface{})["start"].(map[string]interface{})["line"])
+
+	// Once API_KEY is set, saving clears the diagnostics of App.java
+	c.send(0, "textDocument/didSave", map[string]interface{}{"textDocument": map[string]interface{}{"uri": fileURI(filepath.Join(root, ".env"))}})
+	pom = c.receive()
+	app = c.receive()
+	assert.Len(t, pom["params"].(map[string]interface{})["diagnostics"], 1)
+	assert.Empty(t, app["params"].(map[string]interface{})["diagnostics"])
+
+	c.send(2, "shutdown", nil)
+	resp = c.receive()
+	assert.Equal(t, float64(2), resp["id"])
+	assert.Contains(t, resp, "result")
+}
+
+func TestServer_UnknownRequest(t *testing.T) {
+	c := startServer(t, func(context.Context, string) ([]report.Finding, error) { return nil, nil })
+
+	c.send(1, "textDocument/hover", map[string]interface{}{})
+	resp := c.receive()
+	assert.Equal(t, float64(-32601), resp["error"].(map[string]interface{})["code"])
+}
+
+func TestRootPath(t *testing.T) {
+	root := t.TempDir()
+	assert.Equal(t, root, rootPath(json.RawMessage(`{"rootUri": "`+fileURI(root)+`"}`)))
+	assert.Equal(t, root, rootPath(json.RawMessage(`{"workspaceFolders": [{"uri": "`+fileURI(root)+`"}]}`)))
+	assert.Equal(t, "", rootPath(json.RawMessage(`{"rootUri": "untitled:Untitled-1"}`)))
+}
+
+func TestFileURI(t *testing.T) {
+	assert.Equal(t, "file:///home/dev/my%20app/pom.xml", fileURI("/home/dev/my app/pom.xml"))
+}
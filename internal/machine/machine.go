@@ -0,0 +1,201 @@
+// Package machine describes the platform the sentinel runs on, so results recorded on
+// different machines can be told apart by platform rather than by configuration.
+package machine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Fingerprint is an anonymized description of a machine. It holds no host or user names;
+// ID is a hash that tells machines apart without identifying them.
+type Fingerprint struct {
+	ID        string `json:"id"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	CPUs      int    `json:"cpus"`
+	Container string `json:"container,omitempty"` // Container runtime the sentinel runs in, e.g. "docker"
+	VM        string `json:"vm,omitempty"`        // Hypervisor of a virtual machine, e.g. "kvm" or "wsl"
+	Shell     string `json:"shell,omitempty"`     // Name of the user's shell, e.g. "zsh"
+}
+
+var (
+	current     *Fingerprint
+	currentOnce sync.Once
+)
+
+// Current returns the fingerprint of this machine, detected once per process
+func Current() *Fingerprint {
+	currentOnce.Do(func() {
+		current = detect("/", os.Getenv)
+	})
+	return current
+}
+
+// detect fingerprints the machine whose filesystem is rooted at root
+func detect(root string, getenv func(string) string) *Fingerprint {
+	return &Fingerprint{
+		ID:        machineID(root),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+		Container: detectContainer(root, getenv),
+		VM:        detectVM(root, getenv),
+		Shell:     detectShell(getenv),
+	}
+}
+
+// machineID hashes the OS machine id, or the host name where there is none
+func machineID(root string) string {
+	var id string
+	for _, path := range []string{"etc/machine-id", "var/lib/dbus/machine-id"} {
+		if data, err := os.ReadFile(filepath.Join(root, path)); err == nil {
+			id = strings.TrimSpace(string(data))
+			break
+		}
+	}
+	if id == "" {
+		id, _ = os.Hostname()
+	}
+	sum := sha256.Sum256([]byte("dev-env-sentinel/" + id))
+	return hex.EncodeToString(sum[:6])
+}
+
+// cgroupRuntimes maps markers in /proc/1/cgroup to container runtimes
+var cgroupRuntimes = []struct{ marker, runtime string }{
+	{"kubepods", "kubernetes"},
+	{"docker", "docker"},
+	{"libpod", "podman"},
+	{"containerd", "containerd"},
+	{"lxc", "lxc"},
+}
+
+// detectContainer returns the container runtime the process runs in, or ""
+func detectContainer(root string, getenv func(string) string) string {
+	if getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return "kubernetes"
+	}
+	if exists(filepath.Join(root, ".dockerenv")) {
+		return "docker"
+	}
+	if exists(filepath.Join(root, "run", ".containerenv")) {
+		return "podman"
+	}
+	// Set by systemd-nspawn, podman and others to the runtime's name
+	if name := getenv("container"); name != "" {
+		return name
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "proc", "1", "cgroup")); err == nil {
+		for _, r := range cgroupRuntimes {
+			if strings.Contains(string(data), r.marker) {
+				return r.runtime
+			}
+		}
+	}
+	return ""
+}
+
+// dmiVendors maps DMI system vendors and product names to hypervisors
+var dmiVendors = []struct{ marker, vm string }{
+	{"qemu", "kvm"},
+	{"kvm", "kvm"},
+	{"vmware", "vmware"},
+	{"virtualbox", "virtualbox"},
+	{"innotek", "virtualbox"},
+	{"parallels", "parallels"},
+	{"xen", "xen"},
+	{"virtual machine", "hyper-v"},
+	{"amazon ec2", "amazon-ec2"},
+	{"google compute engine", "gce"},
+}
+
+// detectVM returns the hypervisor of a Linux virtual machine or WSL, or "". Other systems
+// report no VM.
+func detectVM(root string, getenv func(string) string) string {
+	if getenv("WSL_DISTRO_NAME") != "" {
+		return "wsl"
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "proc", "sys", "kernel", "osrelease")); err == nil &&
+		strings.Contains(strings.ToLower(string(data)), "microsoft") {
+		return "wsl"
+	}
+
+	var dmi string
+	for _, name := range []string{"sys_vendor", "product_name"} {
+		if data, err := os.ReadFile(filepath.Join(root, "sys", "class", "dmi", "id", name)); err == nil {
+			dmi += strings.ToLower(string(data)) + " "
+		}
+	}
+	for _, v := range dmiVendors {
+		if strings.Contains(dmi, v.marker) {
+			return v.vm
+		}
+	}
+	return ""
+}
+
+// detectShell returns the name of the user's shell, without its path
+func detectShell(getenv func(string) string) string {
+	shell := getenv("SHELL")
+	if shell == "" {
+		shell = getenv("ComSpec")
+	}
+	if shell == "" {
+		return ""
+	}
+	name := filepath.Base(strings.ReplaceAll(shell, `\`, "/"))
+	return strings.TrimSuffix(strings.ToLower(name), ".exe")
+}
+
+// exists reports whether a path exists
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// String summarizes the platform, e.g. "linux/amd64, 8 CPUs, docker container, zsh"
+func (f *Fingerprint) String() string {
+	parts := []string{fmt.Sprintf("%s/%s", f.OS, f.Arch), fmt.Sprintf("%d CPUs", f.CPUs)}
+	if f.Container != "" {
+		parts = append(parts, f.Container+" container")
+	}
+	if f.VM != "" {
+		parts = append(parts, f.VM+" VM")
+	}
+	if f.Shell != "" {
+		parts = append(parts, f.Shell)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Differences lists how another machine's platform differs from this one, e.g.
+// "os: darwin (here linux)". Machines differing only in ID have no differences.
+func (f *Fingerprint) Differences(other *Fingerprint) []string {
+	var diffs []string
+	add := func(name, theirs, ours string) {
+		if theirs != ours {
+			diffs = append(diffs, fmt.Sprintf("%s: %s (here %s)", name, orNone(theirs), orNone(ours)))
+		}
+	}
+	add("os", other.OS, f.OS)
+	add("arch", other.Arch, f.Arch)
+	add("cpus", fmt.Sprint(other.CPUs), fmt.Sprint(f.CPUs))
+	add("container", other.Container, f.Container)
+	add("vm", other.VM, f.VM)
+	add("shell", other.Shell, f.Shell)
+	return diffs
+}
+
+// orNone names an empty value
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,96 @@
+package machine
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFiles creates files under root
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+// env returns a getenv reading vars
+func env(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		env       map[string]string
+		container string
+		vm        string
+		shell     string
+	}{
+		{name: "bare metal", env: map[string]string{"SHELL": "/usr/bin/zsh"}, shell: "zsh"},
+		{name: "docker", files: map[string]string{".dockerenv": ""}, container: "docker"},
+		{name: "podman", files: map[string]string{"run/.containerenv": ""}, container: "podman"},
+		{name: "kubernetes", env: map[string]string{"KUBERNETES_SERVICE_HOST": "10.0.0.1"}, container: "kubernetes"},
+		{name: "cgroup", files: map[string]string{"proc/1/cgroup": "0::/system.slice/containerd.service\n"}, container: "containerd"},
+		{name: "kvm", files: map[string]string{"sys/class/dmi/id/sys_vendor": "QEMU\n"}, vm: "kvm"},
+		{name: "hyper-v", files: map[string]string{"sys/class/dmi/id/sys_vendor": "Microsoft Corporation\n", "sys/class/dmi/id/product_name": "Virtual Machine\n"}, vm: "hyper-v"},
+		{name: "wsl", files: map[string]string{"proc/sys/kernel/osrelease": "5.15.90.1-microsoft-standard-WSL2\n"}, vm: "wsl"},
+		{name: "windows shell", env: map[string]string{"ComSpec": `C:\Windows\system32\cmd.exe`}, shell: "cmd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+
+			f := detect(root, env(tt.env))
+			assert.Equal(t, runtime.GOOS, f.OS)
+			assert.Equal(t, runtime.GOARCH, f.Arch)
+			assert.Equal(t, runtime.NumCPU(), f.CPUs)
+			assert.Equal(t, tt.container, f.Container)
+			assert.Equal(t, tt.vm, f.VM)
+			assert.Equal(t, tt.shell, f.Shell)
+		})
+	}
+}
+
+func TestMachineID(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"etc/machine-id": "4f1b2c3d4e5f60718293a4b5c6d7e8f9\n"})
+
+	id := machineID(root)
+	assert.Len(t, id, 12)
+	assert.NotContains(t, id, "4f1b2c", "the machine id is hashed")
+	assert.Equal(t, id, machineID(root), "the id is stable")
+
+	other := t.TempDir()
+	writeFiles(t, other, map[string]string{"etc/machine-id": "0123456789abcdef0123456789abcdef\n"})
+	assert.NotEqual(t, id, machineID(other))
+}
+
+func TestFingerprint_StringAndDifferences(t *testing.T) {
+	laptop := &Fingerprint{ID: "a1", OS: "darwin", Arch: "arm64", CPUs: 10, Shell: "zsh"}
+	ci := &Fingerprint{ID: "b2", OS: "linux", Arch: "amd64", CPUs: 4, Container: "docker", Shell: "bash"}
+
+	assert.Equal(t, "darwin/arm64, 10 CPUs, zsh", laptop.String())
+	assert.Equal(t, "linux/amd64, 4 CPUs, docker container, bash", ci.String())
+
+	assert.Equal(t, []string{
+		"os: linux (here darwin)",
+		"arch: amd64 (here arm64)",
+		"cpus: 4 (here 10)",
+		"container: docker (here none)",
+		"shell: bash (here zsh)",
+	}, laptop.Differences(ci))
+
+	twin := *laptop
+	twin.ID = "c3"
+	assert.Empty(t, laptop.Differences(&twin))
+}
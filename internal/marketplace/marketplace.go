@@ -0,0 +1,318 @@
+// Package marketplace installs community-maintained ecosystem configs from a signed index into
+// the user config layer
+package marketplace
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/license"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultIndexURL is the community config index; SENTINEL_CONFIG_INDEX overrides it, e.g. for
+// a team's own index
+const DefaultIndexURL = "https://raw.githubusercontent.com/adrianmikula/sentinel-configs/main"
+
+// IndexEnvVar overrides the index configs are fetched from: an http(s) URL or a directory
+const IndexEnvVar = "SENTINEL_CONFIG_INDEX"
+
+// PublicKeyEnvVar replaces the embedded public key, for indexes signed with their own key
+const PublicKeyEnvVar = "SENTINEL_CONFIG_INDEX_PUBLIC_KEY"
+
+// IndexFile is the manifest at the root of an index, listing its configs with their checksums
+const IndexFile = "index.json"
+
+// SignatureFile holds the base64 Ed25519 signature of IndexFile
+const SignatureFile = IndexFile + ".sig"
+
+// signatureDomain is signed with IndexFile, so no other signature by the same key, such as a
+// license key's, verifies as an index signature
+const signatureDomain = "sentinel-config-index"
+
+// namePattern restricts config names to IDs that are safe as file names
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// Entry is a config listed in an index
+type Entry struct {
+	Name        string `json:"name"` // The config's ecosystem ID
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version,omitempty"`
+	Path        string `json:"path"`   // Of the config file, relative to the index
+	SHA256      string `json:"sha256"` // Of the config file, hex encoded
+}
+
+// Index is the signed manifest of an index
+type Index struct {
+	URL     string  `json:"url,omitempty"` // Where it was fetched from; not part of the manifest
+	Configs []Entry `json:"configs"`
+}
+
+// Installed is a config installed into the user config layer
+type Installed struct {
+	Entry
+	Index  string `json:"index"`
+	File   string `json:"file"`
+	Status string `json:"status"` // "installed", "updated" or "unchanged"
+}
+
+// Client fetches configs from an index
+type Client struct {
+	indexURL  string
+	publicKey ed25519.PublicKey
+	http      *http.Client
+}
+
+// NewClient creates a client for an index: indexURL, else SENTINEL_CONFIG_INDEX, else the
+// community index. Its manifest is verified with SENTINEL_CONFIG_INDEX_PUBLIC_KEY, else the
+// maintainers' key.
+func NewClient(indexURL string) (*Client, error) {
+	if indexURL == "" {
+		indexURL = os.Getenv(IndexEnvVar)
+	}
+	if indexURL == "" {
+		indexURL = DefaultIndexURL
+	}
+	// The community index is signed with the maintainers' key, the one license keys are signed with
+	publicKey := license.EmbeddedPublicKey
+	if key := os.Getenv(PublicKeyEnvVar); key != "" {
+		publicKey = key
+	}
+	key, err := license.ParsePublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config index public key: %w", err)
+	}
+	return &Client{
+		indexURL:  strings.TrimSuffix(indexURL, "/"),
+		publicKey: key,
+		http:      &http.Client{Timeout: time.Minute},
+	}, nil
+}
+
+// Index fetches the index manifest and verifies its signature
+func (c *Client) Index(ctx context.Context) (*Index, error) {
+	data, err := c.get(ctx, IndexFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the config index: %w", err)
+	}
+	signature, err := c.get(ctx, SignatureFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the config index signature: %w", err)
+	}
+	if !license.Verify(c.publicKey, signatureDomain, data, string(signature)) {
+		return nil, fmt.Errorf("the config index at %s is not signed with the trusted key (set %s for an index with its own key)", c.indexURL, PublicKeyEnvVar)
+	}
+
+	index := &Index{}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("invalid config index: %w", err)
+	}
+	index.URL = c.indexURL
+	return index, nil
+}
+
+// Add downloads the config with a name from the index, verifies it against the checksum in the
+// signed manifest and writes it to dir as <name>.yaml, replacing an older copy. Configs that
+// don't extend another are validated before they're written; the others when they're loaded.
+func (c *Client) Add(ctx context.Context, name, dir string) (*Installed, error) {
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid config name: %q", name)
+	}
+	index, err := c.Index(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var entry *Entry
+	for i := range index.Configs {
+		if index.Configs[i].Name == name {
+			entry = &index.Configs[i]
+		}
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("%s is not in the config index at %s", name, c.indexURL)
+	}
+
+	data, err := c.get(ctx, entry.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", entry.Path, err)
+	}
+	sum := sha256.Sum256(data)
+	if actual := hex.EncodeToString(sum[:]); actual != strings.ToLower(entry.SHA256) {
+		return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", entry.Path, entry.SHA256, actual)
+	}
+	if err := validate(name, data); err != nil {
+		return nil, err
+	}
+
+	installed := &Installed{Entry: *entry, Index: c.indexURL, File: filepath.Join(dir, name+".yaml"), Status: "installed"}
+	if existing, err := os.ReadFile(installed.File); err == nil {
+		installed.Status = "updated"
+		if bytes.Equal(existing, data) {
+			installed.Status = "unchanged"
+			return installed, nil
+		}
+	}
+	if err := writeFile(installed.File, data); err != nil {
+		return nil, err
+	}
+	return installed, nil
+}
+
+// SignIndex updates the checksums of an index manifest from the config files next to it and
+// signs it, writing the manifest and its signature file. It's run by index maintainers.
+func SignIndex(indexPath string, privateKey ed25519.PrivateKey) (*Index, error) {
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", indexPath, err)
+	}
+	index := &Index{}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("invalid config index: %w", err)
+	}
+
+	dir := filepath.Dir(indexPath)
+	names := make(map[string]bool)
+	for i, entry := range index.Configs {
+		if !namePattern.MatchString(entry.Name) {
+			return nil, fmt.Errorf("configs[%d]: invalid config name: %q", i, entry.Name)
+		}
+		if names[entry.Name] {
+			return nil, fmt.Errorf("configs[%d]: duplicate config name: %s", i, entry.Name)
+		}
+		names[entry.Name] = true
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(entry.Path)))
+		if err != nil {
+			return nil, fmt.Errorf("configs[%d]: %w", i, err)
+		}
+		if err := validate(entry.Name, content); err != nil {
+			return nil, fmt.Errorf("configs[%d]: %w", i, err)
+		}
+		sum := sha256.Sum256(content)
+		index.Configs[i].SHA256 = hex.EncodeToString(sum[:])
+	}
+
+	index.URL = ""
+	data, err = json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	data = append(data, '\n')
+	if err := writeFile(indexPath, data); err != nil {
+		return nil, err
+	}
+	signature := license.Sign(privateKey, signatureDomain, data) + "\n"
+	if err := writeFile(filepath.Join(dir, SignatureFile), []byte(signature)); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// validate checks that a config file is the config with a name
+func validate(name string, data []byte) error {
+	var doc struct {
+		Ecosystem struct {
+			ID      string `yaml:"id"`
+			Extends string `yaml:"extends"`
+		} `yaml:"ecosystem"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("invalid config %s: %w", name, err)
+	}
+	if doc.Ecosystem.ID != name {
+		return fmt.Errorf("invalid config %s: its ecosystem ID is %q", name, doc.Ecosystem.ID)
+	}
+	if doc.Ecosystem.Extends != "" {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp("", "sentinel-config-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := config.LoadEcosystemConfig(tmp.Name()); err != nil {
+		return fmt.Errorf("invalid config %s: %w", name, err)
+	}
+	return nil
+}
+
+// get reads a file of the index, relative to its URL or directory
+func (c *Client) get(ctx context.Context, name string) ([]byte, error) {
+	if !strings.HasPrefix(c.indexURL, "http://") && !strings.HasPrefix(c.indexURL, "https://") {
+		return os.ReadFile(filepath.Join(c.indexURL, filepath.FromSlash(path.Clean("/"+name))))
+	}
+
+	base, err := url.Parse(c.indexURL + "/")
+	if err != nil {
+		return nil, err
+	}
+	ref, err := url.Parse(name)
+	if err != nil {
+		return nil, err
+	}
+	target := base.ResolveReference(ref).String()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "dev-env-sentinel")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: HTTP %d", target, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// writeFile replaces a file by renaming a new one over it, so readers never see half a file
+func writeFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".new-*")
+	if err != nil {
+		return fmt.Errorf("failed to write to %s: %w", dir, err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
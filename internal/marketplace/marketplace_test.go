@@ -0,0 +1,125 @@
+package marketplace
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const quarkusConfig = `ecosystem:
+  id: "java-quarkus"
+  name: "Java Quarkus"
+  manifest:
+    primary_file: "pom.xml"
+`
+
+// newIndex writes a signed index with one config and returns its directory, trusting its key
+func newIndex(t *testing.T) (string, ed25519.PrivateKey) {
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	t.Setenv(PublicKeyEnvVar, base64.StdEncoding.EncodeToString(public))
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "configs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "configs", "java-quarkus.yaml"), []byte(quarkusConfig), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, IndexFile), []byte(`{"configs": [
+  {"name": "java-quarkus", "description": "Quarkus dev mode and native builds", "version": "1.0.0", "path": "configs/java-quarkus.yaml"}
+]}`), 0644))
+
+	index, err := SignIndex(filepath.Join(dir, IndexFile), private)
+	require.NoError(t, err)
+	require.Len(t, index.Configs, 1)
+	assert.Len(t, index.Configs[0].SHA256, 64)
+	return dir, private
+}
+
+func TestAdd(t *testing.T) {
+	indexDir, _ := newIndex(t)
+	server := httptest.NewServer(http.FileServer(http.Dir(indexDir)))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+	index, err := client.Index(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, server.URL, index.URL)
+	assert.Equal(t, "Quarkus dev mode and native builds", index.Configs[0].Description)
+
+	userDir := filepath.Join(t.TempDir(), "configs")
+	installed, err := client.Add(context.Background(), "java-quarkus", userDir)
+	require.NoError(t, err)
+	assert.Equal(t, "installed", installed.Status)
+	assert.Equal(t, filepath.Join(userDir, "java-quarkus.yaml"), installed.File)
+	content, err := os.ReadFile(installed.File)
+	require.NoError(t, err)
+	assert.Equal(t, quarkusConfig, string(content))
+
+	installed, err = client.Add(context.Background(), "java-quarkus", userDir)
+	require.NoError(t, err)
+	assert.Equal(t, "unchanged", installed.Status)
+
+	_, err = client.Add(context.Background(), "java-micronaut", userDir)
+	assert.EqualError(t, err, "java-micronaut is not in the config index at "+server.URL)
+	_, err = client.Add(context.Background(), "../evil", userDir)
+	assert.Error(t, err)
+}
+
+func TestAdd_Tampered(t *testing.T) {
+	indexDir, private := newIndex(t)
+	t.Setenv(IndexEnvVar, indexDir)
+	client, err := NewClient("")
+	require.NoError(t, err)
+
+	// A config changed after the index was signed
+	configPath := filepath.Join(indexDir, "configs", "java-quarkus.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(quarkusConfig+"  extends: \"java-maven\"\n"), 0644))
+	_, err = client.Add(context.Background(), "java-quarkus", t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch for configs/java-quarkus.yaml")
+
+	// A manifest changed after it was signed
+	_, err = SignIndex(filepath.Join(indexDir, IndexFile), private)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(indexDir, IndexFile), []byte(`{"configs": []}`), 0644))
+	_, err = client.Index(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not signed with the trusted key")
+
+	// A signature of the manifest made for another purpose, such as a license key's
+	data, err := os.ReadFile(filepath.Join(indexDir, IndexFile))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(indexDir, SignatureFile), []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(private, data))), 0644))
+	_, err = client.Index(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not signed with the trusted key")
+
+	// An index signed with another key
+	t.Setenv(PublicKeyEnvVar, "")
+	client, err = NewClient(indexDir)
+	require.NoError(t, err)
+	_, err = SignIndex(filepath.Join(indexDir, IndexFile), private)
+	require.NoError(t, err)
+	_, err = client.Index(context.Background())
+	assert.Error(t, err)
+}
+
+func TestSignIndex_InvalidConfig(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("ecosystem:\n  id: \"broken\"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, IndexFile), []byte(`{"configs": [{"name": "broken", "path": "broken.yaml"}]}`), 0644))
+
+	_, err = SignIndex(filepath.Join(dir, IndexFile), private)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "configs[0]: invalid config broken")
+	assert.NoFileExists(t, filepath.Join(dir, SignatureFile))
+}
@@ -4,6 +4,7 @@
 package mcp
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -125,7 +126,7 @@ func BenchmarkEcosystemDetection_Small(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := detector.DetectEcosystems(projectRoot, configs)
+		_, err := detector.DetectEcosystems(context.Background(), projectRoot, configs)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -146,7 +147,7 @@ func BenchmarkEcosystemDetection_Medium(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := detector.DetectEcosystems(projectRoot, configs)
+		_, err := detector.DetectEcosystems(context.Background(), projectRoot, configs)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -167,7 +168,7 @@ func BenchmarkEcosystemDetection_Large(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := detector.DetectEcosystems(projectRoot, configs)
+		_, err := detector.DetectEcosystems(context.Background(), projectRoot, configs)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -186,7 +187,7 @@ func BenchmarkBuildFreshness_Small(b *testing.B) {
 		b.Fatal(err)
 	}
 
-	ecosystems, err := detector.DetectEcosystems(projectRoot, configs)
+	ecosystems, err := detector.DetectEcosystems(context.Background(), projectRoot, configs)
 	if err != nil {
 		b.Fatal(err)
 	}
@@ -196,7 +197,7 @@ func BenchmarkBuildFreshness_Small(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := verifier.VerifyBuildFreshness(projectRoot, ecosystems[0])
+		_, err := verifier.VerifyBuildFreshness(context.Background(), projectRoot, ecosystems[0])
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -215,7 +216,7 @@ func BenchmarkBuildFreshness_Large(b *testing.B) {
 		b.Fatal(err)
 	}
 
-	ecosystems, err := detector.DetectEcosystems(projectRoot, configs)
+	ecosystems, err := detector.DetectEcosystems(context.Background(), projectRoot, configs)
 	if err != nil {
 		b.Fatal(err)
 	}
@@ -225,7 +226,7 @@ func BenchmarkBuildFreshness_Large(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := verifier.VerifyBuildFreshness(projectRoot, ecosystems[0])
+		_, err := verifier.VerifyBuildFreshness(context.Background(), projectRoot, ecosystems[0])
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -250,7 +251,7 @@ func BenchmarkMCPTool_VerifyBuildFreshness(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := handleVerifyBuildFreshness(args, configs)
+		_, err := handleVerifyBuildFreshness(context.Background(), args, configs)
 		if err != nil {
 			b.Fatal(err)
 		}
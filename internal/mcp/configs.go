@@ -0,0 +1,215 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/marketplace"
+	"dev-env-sentinel/internal/runner"
+)
+
+// ConfigList is the result of the list_configs tool
+type ConfigList struct {
+	Configs     []config.Summary   `json:"configs"`
+	ProjectRoot string             `json:"project_root,omitempty"`
+	Detected    map[string]float64 `json:"detected,omitempty"` // Confidence of the ecosystems detected in ProjectRoot, by ID
+}
+
+// handleListConfigs handles the list_configs tool. It lists the loaded ecosystem configs with
+// their source files and detection rules, optionally marking those detected in project_root.
+func handleListConfigs(ctx context.Context, args map[string]interface{}, configs []*config.EcosystemConfig) (interface{}, error) {
+	list := &ConfigList{Configs: config.Summarize(configs)}
+	if id, _ := args["id"].(string); id != "" {
+		var matching []config.Summary
+		for _, summary := range list.Configs {
+			if summary.ID == id {
+				matching = append(matching, summary)
+			}
+		}
+		if len(matching) == 0 {
+			return nil, fmt.Errorf("unknown ecosystem: %s", id)
+		}
+		list.Configs = matching
+	}
+
+	if projectRoot, _ := args["project_root"].(string); projectRoot != "" {
+		detected, err := detector.DetectEcosystems(ctx, projectRoot, configs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect ecosystems: %w", err)
+		}
+		list.ProjectRoot = projectRoot
+		list.Detected = make(map[string]float64, len(detected))
+		for _, eco := range detected {
+			list.Detected[eco.ID] = eco.Confidence
+		}
+	}
+	return list, nil
+}
+
+// formatConfigList formats a list_configs result
+func formatConfigList(list *ConfigList) string {
+	if len(list.Configs) == 0 {
+		return "No ecosystem configs loaded. Run `sentinel doctor` to check the config directory."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📋 Ecosystem configs (%d):\n", len(list.Configs))
+	for _, c := range list.Configs {
+		fmt.Fprintf(&b, "\n- %s", c.ID)
+		if c.Name != "" {
+			fmt.Fprintf(&b, " (%s)", c.Name)
+		}
+		if c.Kind != "" {
+			fmt.Fprintf(&b, ", %s", c.Kind)
+		}
+		if c.Version != "" {
+			fmt.Fprintf(&b, ", config version %s", c.Version)
+		}
+		if list.ProjectRoot != "" {
+			if confidence, ok := list.Detected[c.ID]; ok {
+				fmt.Fprintf(&b, ": detected (confidence %.2f)", confidence)
+			} else {
+				b.WriteString(": not detected")
+			}
+		}
+		b.WriteString("\n")
+
+		for i, source := range c.Sources {
+			if i < len(c.Sources)-1 {
+				fmt.Fprintf(&b, "  Extends: %s\n", source)
+			} else {
+				fmt.Fprintf(&b, "  Source: %s\n", source)
+			}
+		}
+		if len(c.RequiredFiles) > 0 {
+			fmt.Fprintf(&b, "  Requires: %s\n", strings.Join(c.RequiredFiles, ", "))
+		} else {
+			b.WriteString("  Requires: nothing (detected in every project)\n")
+		}
+		if len(c.OptionalFiles) > 0 {
+			fmt.Fprintf(&b, "  Optional files: %s\n", strings.Join(c.OptionalFiles, ", "))
+		}
+		if len(c.DirectoryPatterns) > 0 {
+			fmt.Fprintf(&b, "  Directories: %s\n", strings.Join(c.DirectoryPatterns, ", "))
+		}
+	}
+	return b.String()
+}
+
+// handleFetchConfig handles the fetch_config tool. Without a name it lists the configs of the
+// index; with one it installs that config into the user config layer, which read-only mode
+// refuses.
+func handleFetchConfig(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	indexURL, _ := args["index"].(string)
+	client, err := marketplace.NewClient(indexURL)
+	if err != nil {
+		return nil, err
+	}
+	name, _ := args["name"].(string)
+	if name == "" {
+		return client.Index(ctx)
+	}
+	dir := config.UserConfigDir()
+	if dir == "" {
+		return nil, fmt.Errorf("no home directory to install configs into; set %s", config.UserConfigDirEnvVar)
+	}
+	if runner.ReadOnly() {
+		return nil, &runner.ErrReadOnly{Command: "sentinel config add " + name, Dir: dir}
+	}
+	return client.Add(ctx, name, dir)
+}
+
+// formatConfigIndex formats the configs of an index
+func formatConfigIndex(index *marketplace.Index) string {
+	if len(index.Configs) == 0 {
+		return fmt.Sprintf("The config index at %s lists no configs.", index.URL)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📦 Configs in %s (%d):\n\n", index.URL, len(index.Configs))
+	for _, entry := range index.Configs {
+		fmt.Fprintf(&b, "- %s", entry.Name)
+		if entry.Version != "" {
+			fmt.Fprintf(&b, " %s", entry.Version)
+		}
+		if entry.Description != "" {
+			fmt.Fprintf(&b, ": %s", entry.Description)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\nInstall one with fetch_config and its name, or `sentinel config add NAME`.")
+	return b.String()
+}
+
+// formatInstalledConfig formats a config installed with fetch_config
+func formatInstalledConfig(installed *marketplace.Installed) string {
+	name := installed.Name
+	if installed.Version != "" {
+		name += " " + installed.Version
+	}
+	switch installed.Status {
+	case "unchanged":
+		return fmt.Sprintf("✅ %s is already installed at %s", name, installed.File)
+	case "updated":
+		return fmt.Sprintf("✅ Updated %s at %s from %s. Restart the sentinel to load it.", name, installed.File, installed.Index)
+	}
+	return fmt.Sprintf("✅ Installed %s at %s from %s. Restart the sentinel to load it.", name, installed.File, installed.Index)
+}
+
+// handleExplainDetection handles the explain_detection tool. It shows which detection rules
+// of an ecosystem matched in a project and how they add up to its confidence.
+func handleExplainDetection(ctx context.Context, args map[string]interface{}, configs []*config.EcosystemConfig) (interface{}, error) {
+	projectRoot, ok := args["project_root"].(string)
+	if !ok {
+		return nil, fmt.Errorf("project_root is required")
+	}
+	id, _ := args["ecosystem"].(string)
+	if id == "" {
+		return nil, fmt.Errorf("ecosystem is required")
+	}
+
+	for _, cfg := range configs {
+		if cfg.Ecosystem.ID == id {
+			return detector.Explain(ctx, projectRoot, cfg), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown ecosystem: %s (list_configs shows the loaded configs)", id)
+}
+
+// formatExplanation formats a detection explanation
+func formatExplanation(e *detector.Explanation) string {
+	var b strings.Builder
+	if e.Detected {
+		fmt.Fprintf(&b, "✅ %s is detected in %s (confidence %.2f)\n", e.EcosystemID, e.ProjectRoot, e.Confidence)
+	} else {
+		fmt.Fprintf(&b, "❌ %s is not detected in %s\n", e.EcosystemID, e.ProjectRoot)
+	}
+
+	writeRules(&b, "Required files", e.Required)
+	writeRules(&b, "Optional files", e.Optional)
+	writeRules(&b, "Directory patterns", e.Directories)
+
+	b.WriteString("\nConfidence:\n")
+	for _, step := range e.Steps {
+		fmt.Fprintf(&b, "- %s\n", step)
+	}
+	return b.String()
+}
+
+// writeRules lists detection rules and whether each matched
+func writeRules(b *strings.Builder, title string, rules []detector.RuleMatch) {
+	if len(rules) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n%s:\n", title)
+	for _, rule := range rules {
+		if rule.Found {
+			fmt.Fprintf(b, "✓ %s\n", rule.Pattern)
+		} else {
+			fmt.Fprintf(b, "- %s (missing)\n", rule.Pattern)
+		}
+	}
+}
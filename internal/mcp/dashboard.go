@@ -0,0 +1,194 @@
+package mcp
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"dev-env-sentinel/internal/profile"
+	"dev-env-sentinel/internal/registry"
+	"dev-env-sentinel/internal/snapshot"
+)
+
+// dashboardJob is the job name recorded for checks triggered ad hoc from the dashboard
+const dashboardJob = "dashboard"
+
+// dashboardHistoryLimit is the number of history rows rendered on the dashboard
+const dashboardHistoryLimit = 50
+
+//go:embed dashboard.html
+var dashboardHTML string
+
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"status": func(entry snapshot.Entry) string {
+		if entry.Error != "" || !entry.Healthy {
+			return "❌"
+		}
+		return "✅"
+	},
+	"timestamp": func(t time.Time) string {
+		return t.Format(time.RFC3339)
+	},
+}).Parse(dashboardHTML))
+
+// dashboardData is the view model rendered by the dashboard template
+type dashboardData struct {
+	Latest         []snapshot.Entry
+	History        []snapshot.Entry
+	Reconciliation []snapshot.Entry
+	Jobs           []string
+	Tools          []string
+	Message        string
+	Project        *registry.Project
+}
+
+// handleDashboard renders the dashboard page
+func (t *SSETransport) handleDashboard(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		target, err := scopedServer(server, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := dashboardTemplate.Execute(w, target.dashboardData(r.URL.Query().Get("message"))); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to render dashboard: %v", err), http.StatusInternalServerError)
+		}
+	}
+}
+
+// handleDashboardRun runs a scheduled job or a single check triggered from the dashboard
+func (t *SSETransport) handleDashboardRun(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if !sameOrigin(r) {
+			http.Error(w, "Cross-origin requests are not allowed", http.StatusForbidden)
+			return
+		}
+
+		target, err := scopedServer(server, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		message, err := target.runDashboardTrigger(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		location := "/dashboard?message=" + url.QueryEscape(message)
+		if target.project != nil {
+			location += "&project=" + url.QueryEscape(target.project.ID)
+		}
+		http.Redirect(w, r, location, http.StatusSeeOther)
+	}
+}
+
+// runDashboardTrigger runs whatever the submitted dashboard form asks for
+func (s *Server) runDashboardTrigger(r *http.Request) (string, error) {
+	if err := r.ParseForm(); err != nil {
+		return "", fmt.Errorf("invalid form: %w", err)
+	}
+
+	if job := r.PostForm.Get("job"); job != "" {
+		check, ok := s.schedule[job]
+		if !ok {
+			return "", fmt.Errorf("unknown scheduled job: %s", job)
+		}
+		s.runScheduledCheck(r.Context(), check)
+		return fmt.Sprintf("Ran scheduled job %s", job), nil
+	}
+
+	tool := r.PostForm.Get("tool")
+	if s.registry != nil {
+		return "", fmt.Errorf("checks run on a registered project; open its dashboard with ?project=ID")
+	}
+	projectRoot := r.PostForm.Get("project_root")
+	if projectRoot == "" && s.project != nil {
+		projectRoot = s.project.Root
+	}
+	if tool == "" || projectRoot == "" {
+		return "", fmt.Errorf("tool and project_root are required")
+	}
+	if _, ok := s.tools[tool]; !ok {
+		return "", fmt.Errorf("unknown tool: %s", tool)
+	}
+	if profile.IsMutating(tool) {
+		return "", fmt.Errorf("%s changes the environment and can't be run from the dashboard", tool)
+	}
+
+	entry := s.runCheck(r.Context(), dashboardJob, tool, map[string]interface{}{
+		"project_root": projectRoot,
+	})
+	s.snapshots.Record(entry)
+
+	return fmt.Sprintf("Ran %s on %s", tool, projectRoot), nil
+}
+
+// dashboardData collects everything the dashboard renders
+func (s *Server) dashboardData(message string) dashboardData {
+	data := dashboardData{
+		Latest:  s.snapshots.Latest(),
+		History: s.snapshots.History(dashboardHistoryLimit),
+		Message: message,
+		Project: s.project,
+	}
+
+	for _, entry := range s.snapshots.History(0) {
+		if entry.Check == "reconcile_environment" {
+			data.Reconciliation = append(data.Reconciliation, entry)
+		}
+	}
+
+	for name := range s.schedule {
+		data.Jobs = append(data.Jobs, name)
+	}
+	sort.Strings(data.Jobs)
+
+	for _, name := range s.toolNames() {
+		if !profile.IsMutating(name) {
+			data.Tools = append(data.Tools, name)
+		}
+	}
+
+	return data
+}
+
+// sameOrigin reports whether a request comes from a page of the dashboard itself, so other sites
+// can't make a browser submit its forms. Requests without the headers browsers send, such as
+// those of curl, are allowed.
+func sameOrigin(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "":
+	case "same-origin", "none":
+		return true
+	default:
+		return false
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		origin = r.Header.Get("Referer")
+	}
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"dev-env-sentinel/internal/auditor"
+	"dev-env-sentinel/internal/settings"
+	"dev-env-sentinel/internal/snapshot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboard_Render(t *testing.T) {
+	server := NewServer()
+	server.RegisterTool("env_var_audit", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return &auditor.EnvVarReport{IsHealthy: true}, nil
+	})
+	server.schedule["api"] = settings.ScheduledCheck{Name: "api", ProjectRoot: "/work/api", Checks: []string{"env_var_audit"}}
+	server.snapshots.Record(snapshot.Entry{Job: "api", ProjectRoot: "/work/api", Check: "env_var_audit", Summary: "missing <API_KEY>"})
+	server.snapshots.Record(snapshot.Entry{Job: "api", ProjectRoot: "/work/api", Check: "reconcile_environment", Healthy: true, Summary: "rebuilt target"})
+
+	transport := NewSSETransport("")
+	rec := httptest.NewRecorder()
+	transport.handleDashboard(server)(rec, httptest.NewRequest(http.MethodGet, "/dashboard?message=hello", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "hello")
+	assert.Contains(t, body, "Run api")
+	assert.Contains(t, body, `<option value="env_var_audit">`)
+	assert.Contains(t, body, "missing &lt;API_KEY&gt;", "summaries are HTML escaped")
+	assert.Contains(t, body, "rebuilt target")
+}
+
+func TestDashboard_Run(t *testing.T) {
+	server := NewServer()
+	server.RegisterTool("env_var_audit", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return &auditor.EnvVarReport{IsHealthy: args["project_root"] == "/work/api"}, nil
+	})
+	server.RegisterTool("reconcile_environment", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		t.Fatal("fixes must not run from the dashboard")
+		return nil, nil
+	})
+	server.schedule["api"] = settings.ScheduledCheck{Name: "api", ProjectRoot: "/work/api", Checks: []string{"env_var_audit"}}
+	handler := NewSSETransport("").handleDashboardRun(server)
+
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/dashboard/run", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	rec := post(url.Values{"job": {"api"}})
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	entry, ok := server.snapshots.Get("api", "env_var_audit")
+	require.True(t, ok)
+	assert.True(t, entry.Healthy)
+
+	rec = post(url.Values{"tool": {"env_var_audit"}, "project_root": {"/work/web"}})
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	entry, ok = server.snapshots.Get(dashboardJob, "env_var_audit")
+	require.True(t, ok)
+	assert.False(t, entry.Healthy)
+	assert.Equal(t, "/work/web", entry.ProjectRoot)
+
+	assert.Equal(t, http.StatusBadRequest, post(url.Values{"job": {"missing"}}).Code)
+	assert.Equal(t, http.StatusBadRequest, post(url.Values{"tool": {"no_such_tool"}, "project_root": {"/p"}}).Code)
+	assert.Equal(t, http.StatusBadRequest, post(url.Values{}).Code)
+
+	// Tools that change the environment can't be triggered
+	assert.Equal(t, http.StatusBadRequest, post(url.Values{"tool": {"reconcile_environment"}, "project_root": {"/work/web"}}).Code)
+
+	// Forms submitted from other sites are rejected
+	crossSite := func(header, value string) int {
+		req := httptest.NewRequest(http.MethodPost, "/dashboard/run", strings.NewReader(url.Values{"job": {"api"}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set(header, value)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusForbidden, crossSite("Sec-Fetch-Site", "cross-site"))
+	assert.Equal(t, http.StatusForbidden, crossSite("Origin", "https://evil.example"))
+	assert.Equal(t, http.StatusForbidden, crossSite("Referer", "https://evil.example/page"))
+	assert.Equal(t, http.StatusSeeOther, crossSite("Origin", "http://example.com"))
+	assert.Equal(t, http.StatusSeeOther, crossSite("Sec-Fetch-Site", "same-origin"))
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/dashboard/run", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
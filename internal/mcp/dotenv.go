@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"dev-env-sentinel/internal/auditor"
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/runner"
+)
+
+// DotenvResult is the outcome of the generate_dotenv tool
+type DotenvResult struct {
+	Generated *auditor.GeneratedDotenv
+	Path      string
+	Written   bool
+}
+
+// handleGenerateDotenv handles the generate_dotenv tool. It previews a candidate .env
+// built from .env.example unless write is set; existing files are only replaced with overwrite.
+func handleGenerateDotenv(args map[string]interface{}) (interface{}, error) {
+	projectRoot, ok := args["project_root"].(string)
+	if !ok {
+		return nil, fmt.Errorf("project_root is required")
+	}
+
+	output := ".env"
+	if value, ok := args["output"].(string); ok && value != "" {
+		output = value
+	}
+	path := filepath.Join(projectRoot, output)
+	if inside, err := common.IsSubpath(projectRoot, path); err != nil || !inside {
+		return nil, fmt.Errorf("output must be inside the project root: %s", output)
+	}
+
+	generated, err := auditor.GenerateDotenv(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DotenvResult{Generated: generated, Path: path}
+	write, _ := args["write"].(bool)
+	if !write || runner.ReadOnly() {
+		return result, nil
+	}
+
+	overwrite, _ := args["overwrite"].(bool)
+	if common.FileExists(path) && !overwrite {
+		return nil, fmt.Errorf("%s already exists; set overwrite to replace it or choose another output", output)
+	}
+	if err := os.WriteFile(path, []byte(generated.Content), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	result.Written = true
+	return result, nil
+}
+
+// formatDotenvResult formats a generate_dotenv result
+func formatDotenvResult(result *DotenvResult) string {
+	generated := result.Generated
+	msg := ""
+	if result.Written {
+		msg += fmt.Sprintf("✅ Wrote %s from %s\n", result.Path, generated.Template)
+	} else {
+		msg += fmt.Sprintf("📝 Candidate %s from %s (not written; set write to save it):\n\n", result.Path, generated.Template)
+		msg += generated.Content + "\n"
+	}
+	if len(generated.FromTemplate) > 0 {
+		msg += fmt.Sprintf("Example values from %s (%d): %s\n", generated.Template, len(generated.FromTemplate), strings.Join(generated.FromTemplate, ", "))
+	}
+	if len(generated.Kept) > 0 {
+		msg += fmt.Sprintf("Kept from existing .env (%d): %s\n", len(generated.Kept), strings.Join(generated.Kept, ", "))
+	}
+	return msg
+}
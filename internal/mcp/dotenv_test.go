@@ -0,0 +1,51 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGenerateDotenv(t *testing.T) {
+	t.Setenv("SENTINEL_READ_ONLY", "")
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".env.example"), []byte("PORT=8080\nAPI_KEY=\n"), 0644))
+
+	// Preview by default
+	result, err := handleGenerateDotenv(map[string]interface{}{"project_root": root})
+	require.NoError(t, err)
+	preview := result.(*DotenvResult)
+	assert.False(t, preview.Written)
+	assert.NoFileExists(t, filepath.Join(root, ".env"))
+	assert.Contains(t, formatResult(preview), "PORT=8080")
+
+	result, err = handleGenerateDotenv(map[string]interface{}{"project_root": root, "write": true})
+	require.NoError(t, err)
+	assert.True(t, result.(*DotenvResult).Written)
+	content, err := os.ReadFile(filepath.Join(root, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "PORT=8080\nAPI_KEY=\n", string(content))
+
+	// Existing files are only replaced on request
+	_, err = handleGenerateDotenv(map[string]interface{}{"project_root": root, "write": true})
+	assert.ErrorContains(t, err, "already exists")
+	_, err = handleGenerateDotenv(map[string]interface{}{"project_root": root, "write": true, "overwrite": true})
+	assert.NoError(t, err)
+
+	_, err = handleGenerateDotenv(map[string]interface{}{"project_root": root, "output": "../.env"})
+	assert.ErrorContains(t, err, "inside the project root")
+}
+
+func TestHandleGenerateDotenv_ReadOnly(t *testing.T) {
+	t.Setenv("SENTINEL_READ_ONLY", "true")
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".env.example"), []byte("PORT=8080\n"), 0644))
+
+	result, err := handleGenerateDotenv(map[string]interface{}{"project_root": root, "write": true})
+	require.NoError(t, err)
+	assert.False(t, result.(*DotenvResult).Written)
+	assert.NoFileExists(t, filepath.Join(root, ".env"))
+}
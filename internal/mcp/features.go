@@ -0,0 +1,35 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+
+	"dev-env-sentinel/internal/features"
+)
+
+// handleListFeatures handles the list_features tool. It lists the effective feature flags and
+// where each comes from: the license tier, claims of the key, a trial or a local override.
+func handleListFeatures(server *Server) (interface{}, error) {
+	return server.featureManager.Flags(), nil
+}
+
+// formatFeatureFlags formats a list_features result
+func formatFeatureFlags(flags []features.Flag) string {
+	var b strings.Builder
+	b.WriteString("🚩 Feature flags:\n")
+	for _, flag := range flags {
+		state := "off"
+		if flag.Enabled {
+			state = "on"
+		}
+		fmt.Fprintf(&b, "\n- %s: %s (%s)", flag.Name, state, flag.Source)
+		if flag.Paid {
+			b.WriteString(", paid")
+		}
+		if flag.Note != "" {
+			fmt.Fprintf(&b, " - %s", flag.Note)
+		}
+	}
+	fmt.Fprintf(&b, "\n\nOverride flags with `features:` in sentinel.yaml or %s=name,-name; paid features need a license or 'start_trial'.", features.EnvVar)
+	return b.String()
+}
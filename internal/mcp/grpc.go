@@ -0,0 +1,346 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"dev-env-sentinel/internal/auditor"
+	"dev-env-sentinel/internal/infra"
+	"dev-env-sentinel/internal/queue"
+	"dev-env-sentinel/internal/reconciler"
+	"dev-env-sentinel/internal/redact"
+	"dev-env-sentinel/internal/verifier"
+	sentinelv1 "dev-env-sentinel/pkg/api/sentinel/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ProjectMetadataKey is the gRPC metadata key selecting the registered project a call is
+// scoped to, like the X-Sentinel-Project header over HTTP
+const ProjectMetadataKey = "x-sentinel-project"
+
+// GRPCTransport serves the tools over the versioned gRPC API in api/proto, for platform
+// backends that don't speak MCP
+type GRPCTransport struct {
+	port string
+}
+
+// NewGRPCTransport creates a new gRPC transport
+func NewGRPCTransport(port string) *GRPCTransport {
+	return &GRPCTransport{port: port}
+}
+
+// Start starts the server with gRPC transport. It stops gracefully when ctx is done.
+func (t *GRPCTransport) Start(ctx context.Context, server *Server) error {
+	addr := ":" + t.port
+	if t.port == "" {
+		addr = ":50051" // Default port
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Starting MCP server with gRPC transport on %s\n", addr)
+	return ServeGRPC(ctx, listener, server)
+}
+
+// ServeGRPC serves the gRPC API of a server on a listener until ctx is done
+func ServeGRPC(ctx context.Context, listener net.Listener, server *Server) error {
+	grpcServer := grpc.NewServer()
+	sentinelv1.RegisterSentinelServiceServer(grpcServer, &grpcService{server: server})
+
+	go func() {
+		<-ctx.Done()
+		grpcServer.GracefulStop()
+	}()
+	return grpcServer.Serve(listener)
+}
+
+// grpcService implements the gRPC API with the server's tool handlers
+type grpcService struct {
+	sentinelv1.UnimplementedSentinelServiceServer
+	server *Server
+}
+
+func (g *grpcService) ListTools(ctx context.Context, req *sentinelv1.ListToolsRequest) (*sentinelv1.ListToolsResponse, error) {
+	target, err := g.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &sentinelv1.ListToolsResponse{}
+	for _, name := range target.servedTools() {
+		resp.Tools = append(resp.Tools, &sentinelv1.Tool{Name: name, Description: getToolDescription(name)})
+	}
+	return resp, nil
+}
+
+func (g *grpcService) CallTool(ctx context.Context, req *sentinelv1.CallToolRequest) (*sentinelv1.CallToolResponse, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	args := req.GetArguments().AsMap()
+	target, result, err := g.call(ctx, req.GetName(), args)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := resultValue(result)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode result: %v", err)
+	}
+	return &sentinelv1.CallToolResponse{
+		Text:   target.renderResult(req.GetName(), args, result),
+		Result: value,
+	}, nil
+}
+
+func (g *grpcService) VerifyBuildFreshness(ctx context.Context, req *sentinelv1.VerifyBuildFreshnessRequest) (*sentinelv1.FreshnessReport, error) {
+	if req.GetProjectRoot() == "" {
+		return nil, status.Error(codes.InvalidArgument, "project_root is required")
+	}
+	args := map[string]interface{}{"project_root": req.GetProjectRoot()}
+	if req.GetSuite() != "" {
+		args["suite"] = req.GetSuite()
+	}
+	_, result, err := g.call(ctx, "verify_build_freshness", args)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &sentinelv1.FreshnessReport{Healthy: true, Suite: req.GetSuite(), Summary: formatResult(result)}
+	if report, ok := result.(*verifier.FreshnessReport); ok {
+		resp.Healthy = report.IsHealthy
+		resp.Ecosystems = reportEcosystems(report)
+		for _, issue := range report.Issues {
+			converted := &sentinelv1.Issue{
+				Type:       issue.Type,
+				Severity:   issue.Severity,
+				Message:    issue.Message,
+				File:       issue.File,
+				Ecosystems: issue.Ecosystems,
+			}
+			if issue.FixAvailable {
+				converted.FixCommand = issue.FixCommand
+			}
+			resp.Issues = append(resp.Issues, converted)
+		}
+	}
+	return resp, nil
+}
+
+func (g *grpcService) CheckInfrastructure(ctx context.Context, req *sentinelv1.CheckInfrastructureRequest) (*sentinelv1.InfrastructureReport, error) {
+	if req.GetProjectRoot() == "" {
+		return nil, status.Error(codes.InvalidArgument, "project_root is required")
+	}
+	_, result, err := g.call(ctx, "check_infrastructure_parity", map[string]interface{}{"project_root": req.GetProjectRoot()})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &sentinelv1.InfrastructureReport{Healthy: true, Summary: formatResult(result)}
+	if report, ok := result.(*infra.InfrastructureReport); ok {
+		resp.Healthy = report.IsHealthy
+		resp.Issues = report.Issues
+		resp.Ecosystems = report.Ecosystems
+		for _, s := range report.Services {
+			resp.Services = append(resp.Services, &sentinelv1.Service{
+				Name:       s.Name,
+				Running:    s.Running,
+				Healthy:    s.Healthy,
+				Version:    s.Version,
+				Message:    s.Message,
+				Ecosystems: s.Ecosystems,
+			})
+		}
+		for _, d := range report.Daemons {
+			daemon := &sentinelv1.Daemon{Name: d.Name, Healthy: d.Healthy, Problems: d.Problems, RestartCommand: d.RestartCommand}
+			for _, p := range d.Processes {
+				daemon.Pids = append(daemon.Pids, int32(p.PID))
+			}
+			resp.Daemons = append(resp.Daemons, daemon)
+		}
+		for _, l := range report.Limits {
+			resp.Limits = append(resp.Limits, &sentinelv1.OSLimit{
+				Name:     l.Name,
+				Value:    int64(l.Value),
+				Required: int64(l.Required),
+				Healthy:  l.Healthy,
+				Message:  l.Message,
+				Fix:      l.Fix,
+			})
+		}
+	}
+	return resp, nil
+}
+
+func (g *grpcService) AuditEnvironment(ctx context.Context, req *sentinelv1.AuditEnvironmentRequest) (*sentinelv1.EnvReport, error) {
+	if req.GetProjectRoot() == "" {
+		return nil, status.Error(codes.InvalidArgument, "project_root is required")
+	}
+	_, result, err := g.call(ctx, "env_var_audit", map[string]interface{}{"project_root": req.GetProjectRoot()})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &sentinelv1.EnvReport{Healthy: true, Summary: formatResult(result)}
+	if report, ok := result.(*auditor.EnvVarReport); ok {
+		resp.Healthy = report.IsHealthy
+		resp.Missing = report.Missing
+		resp.Issues = report.Issues
+		resp.Ecosystems = report.Ecosystems
+		for _, ref := range report.References {
+			resp.References = append(resp.References, &sentinelv1.EnvReference{
+				Name:         ref.Name,
+				File:         ref.File,
+				Line:         int32(ref.Line),
+				IsSet:        ref.IsSet,
+				Source:       ref.Source,
+				DefaultValue: ref.Default,
+			})
+		}
+	}
+	return resp, nil
+}
+
+func (g *grpcService) ReconcileEnvironment(ctx context.Context, req *sentinelv1.ReconcileEnvironmentRequest) (*sentinelv1.ReconcileReport, error) {
+	if req.GetProjectRoot() == "" {
+		return nil, status.Error(codes.InvalidArgument, "project_root is required")
+	}
+	target, err := g.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !target.featureManager.IsEnabled("reconcile_environment") {
+		return nil, status.Error(codes.PermissionDenied, target.featureManager.GetUpgradeMessage("reconcile_environment"))
+	}
+	_, result, err := g.call(ctx, "reconcile_environment", map[string]interface{}{"project_root": req.GetProjectRoot()})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &sentinelv1.ReconcileReport{Success: true, Summary: formatResult(result)}
+	if report, ok := result.(*reconciler.ReconciliationReport); ok {
+		resp.Success = report.IsSuccess
+		resp.Message = report.Message
+		resp.Fixed = fixResults(report.Fixed)
+		resp.Failed = fixResults(report.Failed)
+		resp.Planned = fixResults(report.Planned)
+		resp.Manual = fixResults(report.Manual)
+	}
+	return resp, nil
+}
+
+// scoped returns the server a call is scoped to: the registered project named in the call's
+// metadata, or the shared server
+func (g *grpcService) scoped(ctx context.Context) (*Server, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ids := md.Get(ProjectMetadataKey)
+	if len(ids) == 0 || ids[0] == "" {
+		return g.server, nil
+	}
+	target, err := g.server.Project(ids[0])
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return target, nil
+}
+
+// call runs a tool like an MCP tools/call request, on the execution queue when there is one
+func (g *grpcService) call(ctx context.Context, name string, args map[string]interface{}) (*Server, interface{}, error) {
+	target, err := g.scoped(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	handler, ok := target.tools[name]
+	if !ok {
+		return nil, nil, status.Errorf(codes.NotFound, "unknown tool: %s", name)
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if auth := md.Get("authorization"); len(auth) > 0 {
+		ctx = g.server.authorize(ctx, auth[0])
+	}
+	if err := target.checkScope(ctx, name); err != nil {
+		return nil, nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	if target.queue == nil || unqueuedTools[name] {
+		result, err := handler(ctx, args)
+		if err != nil {
+			return nil, nil, toolError(ctx, target, args, err)
+		}
+		return target, result, nil
+	}
+
+	_, args = isAsync(args)
+	job, err := target.startJob(name, handler, args)
+	if err != nil {
+		return nil, nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	job, err = target.queue.Wait(ctx, job.ID)
+	if err != nil {
+		return nil, nil, toolError(ctx, target, args, err)
+	}
+	if job.Status == queue.StatusFailed {
+		return nil, nil, status.Error(codes.Unknown, target.localeFor(args).Localize(job.Error))
+	}
+	return target, job.Result, nil
+}
+
+// toolError converts the error of a tool call to a gRPC status
+func toolError(ctx context.Context, server *Server, args map[string]interface{}, err error) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		return status.Error(codes.Internal, panicErr.Error())
+	}
+	return status.Error(codes.Unknown, server.localeFor(args).Localize(redact.String(err.Error())))
+}
+
+// resultValue converts a tool result to its JSON value
+func resultValue(result interface{}) (*structpb.Value, error) {
+	data, err := json.Marshal(redact.Value(result))
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return structpb.NewValue(decoded)
+}
+
+// reportEcosystems returns the ecosystems of a freshness report, combined or not
+func reportEcosystems(report *verifier.FreshnessReport) []string {
+	if len(report.Ecosystems) > 0 {
+		return report.Ecosystems
+	}
+	if report.EcosystemID != "" {
+		return []string{report.EcosystemID}
+	}
+	return nil
+}
+
+func fixResults(results []reconciler.FixResult) []*sentinelv1.FixResult {
+	var converted []*sentinelv1.FixResult
+	for _, r := range results {
+		converted = append(converted, &sentinelv1.FixResult{
+			IssueType:   r.IssueType,
+			Command:     r.Command,
+			Message:     r.Message,
+			Error:       r.Error,
+			Fingerprint: r.Fingerprint,
+		})
+	}
+	return converted
+}
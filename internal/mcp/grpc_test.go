@@ -0,0 +1,160 @@
+package mcp
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/features"
+	"dev-env-sentinel/internal/license"
+	sentinelv1 "dev-env-sentinel/pkg/api/sentinel/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// newGRPCClient serves a server's gRPC API in memory and returns a client for it
+func newGRPCClient(t *testing.T, server *Server) sentinelv1.SentinelServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	ctx, cancel := context.WithCancel(context.Background())
+	go ServeGRPC(ctx, listener, server)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+		cancel()
+	})
+	return sentinelv1.NewSentinelServiceClient(conn)
+}
+
+func newMavenServer(t *testing.T) *Server {
+	t.Helper()
+	configs := []*config.EcosystemConfig{{Ecosystem: config.Ecosystem{
+		ID:        "java-maven",
+		Detection: config.Detection{RequiredFiles: []string{"pom.xml"}},
+		Verification: config.Verification{BuildFreshness: config.BuildFreshness{Commands: []config.VerificationCommand{
+			{Name: "pom_vs_jar", Type: "timestamp_compare", Source: "pom.xml", Target: "target/app.jar"},
+		}}},
+	}}}
+	server := NewServer()
+	lic, _ := license.NewLicenseValidator().ValidateLicense("")
+	server.license, server.featureManager = lic, features.NewFeatureManager(lic)
+	RegisterAllTools(server, configs)
+	return server
+}
+
+func TestGRPC_ListTools(t *testing.T) {
+	client := newGRPCClient(t, newMavenServer(t))
+
+	resp, err := client.ListTools(context.Background(), &sentinelv1.ListToolsRequest{})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Tools)
+	assert.Equal(t, "activate_pro", resp.Tools[0].Name, "tools are sorted by name")
+
+	var verify *sentinelv1.Tool
+	for _, tool := range resp.Tools {
+		if tool.Name == "verify_build_freshness" {
+			verify = tool
+		}
+	}
+	require.NotNil(t, verify)
+	assert.Equal(t, getToolDescription("verify_build_freshness"), verify.Description)
+}
+
+func TestGRPC_VerifyBuildFreshness(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "pom.xml"), []byte("<project></project>"), 0644))
+	client := newGRPCClient(t, newMavenServer(t))
+
+	report, err := client.VerifyBuildFreshness(context.Background(), &sentinelv1.VerifyBuildFreshnessRequest{ProjectRoot: root})
+	require.NoError(t, err)
+	assert.False(t, report.Healthy)
+	assert.Equal(t, []string{"java-maven"}, report.Ecosystems)
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, "missing_target", report.Issues[0].Type)
+	assert.Contains(t, report.Summary, "Target file not found: target/app.jar")
+
+	_, err = client.VerifyBuildFreshness(context.Background(), &sentinelv1.VerifyBuildFreshnessRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPC_VerifyBuildFreshness_NoEcosystems(t *testing.T) {
+	client := newGRPCClient(t, newMavenServer(t))
+
+	report, err := client.VerifyBuildFreshness(context.Background(), &sentinelv1.VerifyBuildFreshnessRequest{ProjectRoot: t.TempDir()})
+	require.NoError(t, err)
+	assert.True(t, report.Healthy)
+	assert.Empty(t, report.Issues)
+	assert.Equal(t, "No ecosystems detected in project", report.Summary)
+}
+
+func TestGRPC_CallTool(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "pom.xml"), []byte("<project></project>"), 0644))
+	client := newGRPCClient(t, newMavenServer(t))
+
+	args, err := structpb.NewStruct(map[string]interface{}{"project_root": root})
+	require.NoError(t, err)
+	resp, err := client.CallTool(context.Background(), &sentinelv1.CallToolRequest{Name: "verify_build_freshness", Arguments: args})
+	require.NoError(t, err)
+	assert.Contains(t, resp.Text, "Target file not found")
+	assert.Equal(t, false, resp.Result.GetStructValue().AsMap()["IsHealthy"])
+
+	_, err = client.CallTool(context.Background(), &sentinelv1.CallToolRequest{Name: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.CallTool(context.Background(), &sentinelv1.CallToolRequest{Name: "env_var_audit"})
+	assert.Equal(t, codes.Unknown, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "project_root is required")
+}
+
+func TestGRPC_ReconcileNeedsLicense(t *testing.T) {
+	client := newGRPCClient(t, newMavenServer(t))
+
+	_, err := client.ReconcileEnvironment(context.Background(), &sentinelv1.ReconcileEnvironmentRequest{ProjectRoot: t.TempDir()})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestGRPC_UnknownProject(t *testing.T) {
+	server, _ := newRegistryServer(t)
+	client := newGRPCClient(t, server)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), ProjectMetadataKey, "missing")
+	_, err := client.ListTools(ctx, &sentinelv1.ListToolsRequest{})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "unknown project: missing")
+}
+
+func TestGRPC_Unscoped(t *testing.T) {
+	server, _ := newRegistryServer(t)
+	server.adminToken = "s3cret"
+	client := newGRPCClient(t, server)
+
+	resp, err := client.ListTools(context.Background(), &sentinelv1.ListToolsRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Tools, 6, "only the registry and machine tools are served unscoped")
+
+	_, err = client.CallTool(context.Background(), &sentinelv1.CallToolRequest{Name: "check_portability"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.CallTool(context.Background(), &sentinelv1.CallToolRequest{Name: "list_projects"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.CallTool(context.Background(), &sentinelv1.CallToolRequest{Name: "activate_pro"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer s3cret")
+	_, err = client.CallTool(ctx, &sentinelv1.CallToolRequest{Name: "list_projects"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,263 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/queue"
+	"dev-env-sentinel/internal/runner"
+)
+
+// unqueuedTools answer immediately instead of waiting behind queued tool calls
+var unqueuedTools = map[string]bool{
+	"start_job":      true,
+	"get_job_status": true,
+	"get_job_result": true,
+}
+
+// maxResultWait bounds how long get_job_result blocks for a job to finish
+const maxResultWait = 60 * time.Second
+
+// JobStatus is the result of the get_job_status tool
+type JobStatus struct {
+	queue.Job
+	Output string // Formatted result of a finished job
+}
+
+// SetQueue sets the execution queue and registers the job tools. HTTP tool calls always run
+// on the queue; with either transport, long operations can be started as background jobs
+// with start_job or "async": true. It must be called after the tool policy is set.
+func (s *Server) SetQueue(q *queue.Queue) {
+	s.queue = q
+	s.registerJobTools()
+}
+
+// registerJobTools registers the tools that start queued jobs and report on them
+func (s *Server) registerJobTools() {
+	s.RegisterTool("start_job", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleStartJob(s, args)
+	})
+	s.RegisterTool("get_job_status", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleGetJobStatus(s, args)
+	})
+	s.RegisterTool("get_job_result", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleGetJobResult(ctx, s, args)
+	})
+}
+
+// isAsync reports whether a tool call asks to run as a background job, and returns
+// the arguments without the "async" flag
+func isAsync(args map[string]interface{}) (bool, map[string]interface{}) {
+	async, _ := args["async"].(bool)
+	if !async {
+		return false, args
+	}
+	scoped := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		if k != "async" {
+			scoped[k] = v
+		}
+	}
+	return true, scoped
+}
+
+// startJob queues a tool call as a job owned by this server's project
+func (s *Server) startJob(name string, handler ToolHandler, args map[string]interface{}) (queue.Job, error) {
+	return s.queue.Submit(name, s.jobOwner(), func(ctx context.Context) (interface{}, error) {
+		return handler(runner.WithLogKey(ctx, queue.JobID(ctx)), args)
+	})
+}
+
+// jobOwner identifies the jobs a server may see: its project, or the shared server
+func (s *Server) jobOwner() string {
+	if s.project != nil {
+		return s.project.ID
+	}
+	return ""
+}
+
+// queuedToolCallResponse runs a tool call on the execution queue. With "async": true in the
+// arguments it returns the job ID right away; otherwise it waits for the result.
+// Either way the response carries the job's queue position in _meta.
+func (s *Server) queuedToolCallResponse(ctx context.Context, id interface{}, name string, handler ToolHandler, args map[string]interface{}) map[string]interface{} {
+	async, args := isAsync(args)
+	loc := s.localeFor(args)
+
+	job, err := s.startJob(name, handler, args)
+	if err != nil {
+		return map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      id,
+			"error": map[string]interface{}{
+				"code":    -1,
+				"message": loc.Localize(err.Error()),
+			},
+		}
+	}
+	meta := map[string]interface{}{
+		"job_id":   job.ID,
+		"position": job.Position,
+		"eta_ms":   job.ETA.Milliseconds(),
+	}
+
+	if async {
+		return map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      id,
+			"result": map[string]interface{}{
+				"content": []map[string]interface{}{
+					{
+						"type": "text",
+						"text": loc.Localize(formatQueuedJob(job)),
+					},
+				},
+				"_meta": map[string]interface{}{"queue": meta},
+			},
+		}
+	}
+
+	job, err = s.queue.Wait(ctx, job.ID)
+	meta["waited_ms"] = job.Waited().Milliseconds()
+	if err != nil || job.Status == queue.StatusFailed {
+		message := job.Error
+		if err != nil {
+			message = fmt.Sprintf("stopped waiting for job %s: %v", job.ID, err)
+		}
+		return map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      id,
+			"error": map[string]interface{}{
+				"code":    -1,
+				"message": loc.Localize(message),
+				"data":    map[string]interface{}{"queue": meta},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"result": map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": s.renderResult(name, args, job.Result),
+				},
+			},
+			"_meta": map[string]interface{}{"queue": meta},
+		},
+	}
+}
+
+// handleStartJob handles the start_job tool
+func handleStartJob(server *Server, args map[string]interface{}) (interface{}, error) {
+	name, _ := args["tool"].(string)
+	if name == "" {
+		return nil, fmt.Errorf("tool is required")
+	}
+	handler, ok := server.tools[name]
+	if !ok || unqueuedTools[name] {
+		return nil, fmt.Errorf("unknown tool: %s", name)
+	}
+	toolArgs, _ := args["arguments"].(map[string]interface{})
+
+	job, err := server.startJob(name, handler, toolArgs)
+	if err != nil {
+		return nil, err
+	}
+	return formatQueuedJob(job), nil
+}
+
+// lookupJob returns a job visible to the server. Jobs of other projects are reported
+// as unknown rather than forbidden.
+func lookupJob(server *Server, args map[string]interface{}) (queue.Job, error) {
+	id, _ := args["job_id"].(string)
+	if id == "" {
+		return queue.Job{}, fmt.Errorf("job_id is required")
+	}
+	job, ok := server.queue.Get(id)
+	if !ok || job.Owner != server.jobOwner() {
+		return queue.Job{}, fmt.Errorf("unknown job: %s (finished jobs are kept for the last %d calls)", id, queue.MaxFinishedJobs)
+	}
+	return job, nil
+}
+
+// handleGetJobResult handles the get_job_result tool. It returns the job's result as the tool
+// itself would have, optionally waiting up to wait_seconds for the job to finish.
+func handleGetJobResult(ctx context.Context, server *Server, args map[string]interface{}) (interface{}, error) {
+	job, err := lookupJob(server, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if wait, ok := args["wait_seconds"].(float64); ok && wait > 0 && !job.Finished() {
+		timeout := time.Duration(wait * float64(time.Second))
+		if timeout > maxResultWait {
+			timeout = maxResultWait
+		}
+		waitCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		job, _ = server.queue.Wait(waitCtx, job.ID)
+	}
+
+	switch job.Status {
+	case queue.StatusSucceeded:
+		return job.Result, nil
+	case queue.StatusFailed:
+		return nil, fmt.Errorf("job %s (%s) failed: %s", job.ID, job.Name, job.Error)
+	default:
+		return &JobStatus{Job: job}, nil
+	}
+}
+
+// handleGetJobStatus handles the get_job_status tool
+func handleGetJobStatus(server *Server, args map[string]interface{}) (interface{}, error) {
+	job, err := lookupJob(server, args)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &JobStatus{Job: job}
+	if job.Status == queue.StatusSucceeded {
+		status.Output = formatResult(job.Result)
+	}
+	return status, nil
+}
+
+// formatQueuedJob formats the response to an asynchronous tool call
+func formatQueuedJob(job queue.Job) string {
+	msg := fmt.Sprintf("⏳ %s queued as job %s", job.Name, job.ID)
+	if job.Position > 0 {
+		msg += fmt.Sprintf(" at position %d", job.Position)
+		if job.ETA > 0 {
+			msg += fmt.Sprintf(" (starts in about %s)", job.ETA.Round(time.Second))
+		}
+	}
+	return msg + ". Poll get_job_status or get_job_result with this job_id for the result."
+}
+
+// formatJobStatus formats a job status
+func formatJobStatus(status *JobStatus) string {
+	var b strings.Builder
+	switch status.Status {
+	case queue.StatusQueued:
+		fmt.Fprintf(&b, "⏳ Job %s (%s) is queued at position %d", status.ID, status.Name, status.Position)
+		if status.ETA > 0 {
+			fmt.Fprintf(&b, ", starting in about %s", status.ETA.Round(time.Second))
+		}
+		b.WriteString("\n")
+	case queue.StatusRunning:
+		fmt.Fprintf(&b, "🔄 Job %s (%s) has been running for %s\n", status.ID, status.Name, time.Since(status.StartedAt).Round(time.Second))
+		if p := status.Progress; p != nil {
+			fmt.Fprintf(&b, "Progress: %d/%d %s\n", p.Done, p.Total, p.Message)
+		}
+	case queue.StatusSucceeded:
+		fmt.Fprintf(&b, "✅ Job %s (%s) finished in %s:\n\n%s\n", status.ID, status.Name, status.FinishedAt.Sub(status.StartedAt).Round(time.Millisecond), status.Output)
+	case queue.StatusFailed:
+		fmt.Fprintf(&b, "❌ Job %s (%s) failed: %s\n", status.ID, status.Name, status.Error)
+		fmt.Fprintf(&b, "Full command output: get_command_log with job_id %s\n", status.ID)
+	}
+	return b.String()
+}
@@ -0,0 +1,196 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/queue"
+	"dev-env-sentinel/internal/runner"
+	"dev-env-sentinel/internal/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueuedToolCalls(t *testing.T) {
+	server := NewServer()
+	release := make(chan struct{})
+	server.RegisterTool("slow_check", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		<-release
+		if _, ok := args["async"]; ok {
+			return nil, fmt.Errorf("async reached the tool")
+		}
+		return "done", nil
+	})
+	q := queue.New(1, 10)
+	defer q.Close()
+	server.SetQueue(q)
+	handler := NewSSETransport("").handleMessage(server)
+
+	call := func(body string) map[string]interface{} {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPost, "/message", strings.NewReader(body)))
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response
+	}
+	text := func(response map[string]interface{}) string {
+		result := response["result"].(map[string]interface{})
+		return result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+	}
+
+	started := call(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow_check","arguments":{"async":true}}}`)
+	assert.Contains(t, text(started), "slow_check queued as job job-")
+	meta := started["result"].(map[string]interface{})["_meta"].(map[string]interface{})["queue"].(map[string]interface{})
+	jobID := meta["job_id"].(string)
+
+	status := call(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"get_job_status","arguments":{"job_id":"` + jobID + `"}}}`)
+	assert.NotContains(t, text(status), "✅", "get_job_status is answered while the job runs")
+
+	close(release)
+	require.Eventually(t, func() bool {
+		job, _ := q.Get(jobID)
+		return job.Finished()
+	}, time.Second, time.Millisecond)
+
+	status = call(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"get_job_status","arguments":{"job_id":"` + jobID + `"}}}`)
+	assert.Contains(t, text(status), "✅ Job "+jobID+" (slow_check) finished")
+	assert.Contains(t, text(status), "done")
+
+	sync := call(`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"slow_check","arguments":{}}}`)
+	assert.Equal(t, "done", text(sync))
+	assert.Contains(t, sync["result"].(map[string]interface{})["_meta"].(map[string]interface{})["queue"], "waited_ms")
+}
+
+func TestGetJobStatus_ScopedToProject(t *testing.T) {
+	server, _ := newRegistryServer(t)
+	q := queue.New(1, 10)
+	defer q.Close()
+	server.SetQueue(q)
+	_, err := server.CallTool(context.Background(), "register_project", map[string]interface{}{"id": "api", "project_root": t.TempDir()})
+	require.NoError(t, err)
+
+	job, err := q.Submit("env_var_audit", "", func(ctx context.Context) (interface{}, error) { return "ok", nil })
+	require.NoError(t, err)
+
+	_, err = server.CallTool(context.Background(), "get_job_status", map[string]interface{}{"job_id": job.ID})
+	require.NoError(t, err)
+
+	api, err := server.Project("api")
+	require.NoError(t, err)
+	_, err = api.CallTool(context.Background(), "get_job_status", map[string]interface{}{"job_id": job.ID})
+	assert.ErrorContains(t, err, "unknown job", "projects cannot see other jobs")
+}
+
+func TestStartJobAndGetJobResult(t *testing.T) {
+	server := NewServer()
+	release := make(chan struct{})
+	server.RegisterTool("reconcile_environment", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		<-release
+		if args["project_root"] != "/work/api" {
+			return nil, fmt.Errorf("unexpected project_root %v", args["project_root"])
+		}
+		return "reconciled", nil
+	})
+	q := queue.New(1, 10)
+	defer q.Close()
+	server.SetQueue(q)
+
+	started, err := server.CallTool(context.Background(), "start_job", map[string]interface{}{
+		"tool":      "reconcile_environment",
+		"arguments": map[string]interface{}{"project_root": "/work/api"},
+	})
+	require.NoError(t, err)
+	jobID := strings.Fields(strings.SplitAfter(started.(string), "queued as job ")[1])[0]
+
+	pending, err := server.CallTool(context.Background(), "get_job_result", map[string]interface{}{"job_id": jobID})
+	require.NoError(t, err)
+	assert.IsType(t, &JobStatus{}, pending, "an unfinished job reports its status")
+
+	close(release)
+	result, err := server.CallTool(context.Background(), "get_job_result", map[string]interface{}{"job_id": jobID, "wait_seconds": float64(5)})
+	require.NoError(t, err)
+	assert.Equal(t, "reconciled", result)
+
+	_, err = server.CallTool(context.Background(), "start_job", map[string]interface{}{"tool": "get_job_result"})
+	assert.ErrorContains(t, err, "unknown tool")
+	_, err = server.CallTool(context.Background(), "get_job_result", map[string]interface{}{"job_id": "job-missing"})
+	assert.ErrorContains(t, err, "unknown job")
+}
+
+func TestGetCommandLog_Job(t *testing.T) {
+	dir, err := state.Open(t.TempDir())
+	require.NoError(t, err)
+
+	server := NewServer()
+	server.SetState(dir)
+	server.RegisterTool("get_command_log", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleGetCommandLog(server, args)
+	})
+	server.RegisterTool("check_infrastructure_parity", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		output, _ := runner.Run(ctx, "", "echo pinging db; echo connection refused >&2; exit 2")
+		return nil, fmt.Errorf("db unreachable: %s", strings.TrimSpace(string(output)))
+	})
+	q := queue.New(1, 10)
+	defer q.Close()
+	server.SetQueue(q)
+
+	started, err := server.CallTool(context.Background(), "start_job", map[string]interface{}{"tool": "check_infrastructure_parity"})
+	require.NoError(t, err)
+	jobID := strings.Fields(strings.SplitAfter(started.(string), "queued as job ")[1])[0]
+	_, err = server.CallTool(context.Background(), "get_job_result", map[string]interface{}{"job_id": jobID, "wait_seconds": float64(5)})
+	require.Error(t, err)
+
+	log, err := server.CallTool(context.Background(), "get_command_log", map[string]interface{}{"job_id": jobID})
+	require.NoError(t, err)
+	assert.Contains(t, log, "check, exit 2 after")
+	assert.Contains(t, log, "--- stdout\npinging db\n--- stderr\nconnection refused\n")
+
+	_, err = server.CallTool(context.Background(), "get_command_log", map[string]interface{}{"job_id": jobID, "fingerprint": "abc"})
+	assert.ErrorContains(t, err, "exactly one of fingerprint and job_id")
+	_, err = server.CallTool(context.Background(), "get_command_log", map[string]interface{}{"fingerprint": "0123456789abcdef"})
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestSSE_JobEvents(t *testing.T) {
+	server := NewServer()
+	server.RegisterTool("check_infrastructure_parity", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	q := queue.New(1, 10)
+	defer q.Close()
+	server.SetQueue(q)
+
+	ts := httptest.NewServer(NewSSETransport("").handleSSE(server))
+	defer ts.Close()
+	resp, err := http.Get(ts.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Contains(t, line, "connected")
+
+	_, err = server.CallTool(context.Background(), "start_job", map[string]interface{}{"tool": "check_infrastructure_parity"})
+	require.NoError(t, err)
+
+	var statuses []string
+	for len(statuses) < 3 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var job queue.Job
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &job))
+		statuses = append(statuses, job.Status)
+	}
+	assert.Equal(t, []string{queue.StatusQueued, queue.StatusRunning, queue.StatusSucceeded}, statuses)
+}
@@ -0,0 +1,121 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/onboard"
+)
+
+// onboardStatusIcons mark the steps of an onboarding plan by status
+var onboardStatusIcons = map[string]string{
+	onboard.StatusMissing: "⬜",
+	onboard.StatusFixed:   "✅",
+	onboard.StatusFailed:  "❌",
+	onboard.StatusManual:  "✋",
+	onboard.StatusBlocked: "⏸️",
+}
+
+// handleOnboardProject handles the onboard_project tool. It lists everything the machine is
+// missing for the project in setup order and, with fix (PREMIUM FEATURE), sets up the
+// fixable steps one at a time, reporting progress as each step starts.
+func handleOnboardProject(ctx context.Context, server *Server, args map[string]interface{}, configs []*config.EcosystemConfig) (interface{}, error) {
+	projectRoot, ok := args["project_root"].(string)
+	if !ok {
+		return nil, fmt.Errorf("project_root is required")
+	}
+	fix, _ := args["fix"].(bool)
+	if fix {
+		if !server.policy.AllowsFixes() {
+			return nil, fmt.Errorf("fix commands are disabled by the %s tool profile", server.policy.Name())
+		}
+		if err := server.featureManager.RequireFeature("reconcile_environment"); err != nil {
+			upgradeMsg := server.featureManager.GetUpgradeMessage("reconcile_environment")
+			return upgradeMsg, fmt.Errorf("premium feature not available: %w", err)
+		}
+	}
+
+	ecosystems, err := detector.DetectEcosystems(ctx, projectRoot, configs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect ecosystems: %w", err)
+	}
+	if len(ecosystems) == 0 {
+		return "No ecosystems detected in project", nil
+	}
+
+	plan, err := onboard.Assess(ctx, projectRoot, ecosystems)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assess the environment: %w", err)
+	}
+	if !fix {
+		return plan, nil
+	}
+
+	var confirmed []string
+	if confirm, ok := args["confirm"].([]interface{}); ok {
+		for _, fingerprint := range confirm {
+			if s, ok := fingerprint.(string); ok {
+				confirmed = append(confirmed, s)
+			}
+		}
+	}
+	err = plan.Execute(ctx, confirmed, func(done, total int, step onboard.Step) {
+		message := "Done"
+		if done < total {
+			message = fmt.Sprintf("Step %d/%d: %s", done+1, total, step.Title)
+		}
+		reportProgress(ctx, done, total, message)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// formatOnboardingPlan formats an onboard_project result
+func formatOnboardingPlan(plan *onboard.Plan) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🧭 Onboarding %s (%s)\n", plan.ProjectRoot, strings.Join(plan.Ecosystems, ", "))
+	if len(plan.Ready) > 0 {
+		fmt.Fprintf(&b, "\nReady: %s\n", strings.Join(plan.Ready, ", "))
+	}
+	if len(plan.Steps) == 0 {
+		b.WriteString("\n✅ Nothing is missing; the machine is ready for this project.\n")
+		return b.String()
+	}
+
+	if plan.Executed {
+		fmt.Fprintf(&b, "\nSetup (%d of %d steps done):\n", len(plan.Steps)-plan.Missing(), len(plan.Steps))
+	} else {
+		fmt.Fprintf(&b, "\nMissing (%d steps, in setup order):\n", len(plan.Steps))
+	}
+	fixable := 0
+	for i, step := range plan.Steps {
+		fmt.Fprintf(&b, "\n%d. %s %s [%s]\n", i+1, onboardStatusIcons[step.Status], step.Title, step.Kind)
+		if step.Detail != "" {
+			fmt.Fprintf(&b, "   %s\n", step.Detail)
+		}
+		if step.Error != "" {
+			fmt.Fprintf(&b, "   Error: %s\n", step.Error)
+		}
+		if step.Status != onboard.StatusFixed {
+			for _, command := range step.Commands {
+				fmt.Fprintf(&b, "   $ %s\n", command)
+			}
+		}
+		if step.Fingerprint != "" && step.Status != onboard.StatusFixed {
+			fmt.Fprintf(&b, "   Fingerprint: %s%s\n", step.Fingerprint, formatEstimate(step.Estimate))
+		}
+		if step.Fixable {
+			fixable++
+		}
+	}
+
+	if !plan.Executed && fixable > 0 {
+		fmt.Fprintf(&b, "\n💡 %d step(s) can be set up automatically: call onboard_project with fix: true (Pro). Steps needing confirmation run once their fingerprints are passed in confirm.\n", fixable)
+	}
+	return b.String()
+}
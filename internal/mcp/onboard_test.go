@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/onboard"
+	"dev-env-sentinel/internal/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleOnboardProject(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SENTINEL_LICENSE_KEY", "")
+	t.Setenv("SENTINEL_READ_ONLY", "")
+	t.Setenv("NODE_ENV", "")
+	os.Unsetenv("NODE_ENV")
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte("{}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "app.js"), []byte("const env = process.env.NODE_ENV\n"), 0644))
+	configs := []*config.EcosystemConfig{{Ecosystem: config.Ecosystem{
+		ID:          "node-npm",
+		Detection:   config.Detection{RequiredFiles: []string{"package.json"}},
+		Environment: config.Environment{VariablePatterns: []string{`process\.env\.([A-Z_][A-Z0-9_]*)`}},
+	}}}
+	args := map[string]interface{}{"project_root": tmpDir}
+
+	server := NewServer()
+	result, err := handleOnboardProject(context.Background(), server, args, configs)
+	require.NoError(t, err)
+	plan := result.(*onboard.Plan)
+	require.Len(t, plan.Steps, 1)
+	assert.Equal(t, "env_var:NODE_ENV", plan.Steps[0].ID)
+	text := formatOnboardingPlan(plan)
+	assert.Contains(t, text, "Missing (1 steps, in setup order)")
+	assert.Contains(t, text, "1. ⬜ Set NODE_ENV [env_var]")
+	assert.Contains(t, text, "call onboard_project with fix: true")
+
+	args["fix"] = true
+	_, err = handleOnboardProject(context.Background(), server, args, configs)
+	assert.ErrorContains(t, err, "premium feature not available")
+
+	require.NoError(t, server.UpdateLicense(proLicenseKey(t)))
+	var progress []string
+	ctx := withProgress(context.Background(), func(done, total int, message string) {
+		progress = append(progress, message)
+	})
+	result, err = handleOnboardProject(ctx, server, args, configs)
+	require.NoError(t, err)
+	plan = result.(*onboard.Plan)
+	assert.Equal(t, onboard.StatusFixed, plan.Steps[0].Status)
+	assert.Equal(t, []string{"Step 1/1: Set NODE_ENV", "Done"}, progress)
+	assert.Contains(t, formatOnboardingPlan(plan), "Setup (1 of 1 steps done)")
+	assert.FileExists(t, filepath.Join(tmpDir, ".env"))
+
+	result, err = handleOnboardProject(context.Background(), server, map[string]interface{}{"project_root": t.TempDir()}, configs)
+	require.NoError(t, err)
+	assert.Equal(t, "No ecosystems detected in project", result)
+}
+
+func TestHandleOnboardProject_FixesDisabled(t *testing.T) {
+	policy, err := profile.NewPolicy(profile.ReadOnly, nil)
+	require.NoError(t, err)
+
+	server := NewServer()
+	server.SetToolPolicy(policy)
+	assert.True(t, policy.AllowsTool("onboard_project"), "listing what is missing is read-only")
+
+	_, err = handleOnboardProject(context.Background(), server, map[string]interface{}{"project_root": t.TempDir(), "fix": true}, nil)
+	assert.ErrorContains(t, err, "disabled by the read-only tool profile")
+}
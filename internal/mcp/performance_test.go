@@ -1,6 +1,7 @@
 package mcp
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -134,7 +135,7 @@ func TestToolExecutionTime_Requirement(t *testing.T) {
 
 	// Test verify_build_freshness tool
 	start := time.Now()
-	_, err = handleVerifyBuildFreshness(args, configs)
+	_, err = handleVerifyBuildFreshness(context.Background(), args, configs)
 	elapsed := time.Since(start)
 
 	if err != nil {
@@ -170,7 +171,7 @@ func TestLargeProjectPerformance(t *testing.T) {
 
 	// Test ecosystem detection
 	start := time.Now()
-	ecosystems, err := detector.DetectEcosystems(projectRoot, configs)
+	ecosystems, err := detector.DetectEcosystems(context.Background(), projectRoot, configs)
 	detectionTime := time.Since(start)
 
 	if err != nil {
@@ -183,7 +184,7 @@ func TestLargeProjectPerformance(t *testing.T) {
 
 	// Test build freshness verification
 	start = time.Now()
-	_, err = verifier.VerifyBuildFreshness(projectRoot, ecosystems[0])
+	_, err = verifier.VerifyBuildFreshness(context.Background(), projectRoot, ecosystems[0])
 	verificationTime := time.Since(start)
 
 	if err != nil {
@@ -225,7 +226,7 @@ func TestEcosystemDetectionPerformance_Small(t *testing.T) {
 	}
 
 	start := time.Now()
-	_, err = detector.DetectEcosystems(projectRoot, configs)
+	_, err = detector.DetectEcosystems(context.Background(), projectRoot, configs)
 	elapsed := time.Since(start)
 
 	if err != nil {
@@ -259,7 +260,7 @@ func TestEcosystemDetectionPerformance_Medium(t *testing.T) {
 	}
 
 	start := time.Now()
-	_, err = detector.DetectEcosystems(projectRoot, configs)
+	_, err = detector.DetectEcosystems(context.Background(), projectRoot, configs)
 	elapsed := time.Since(start)
 
 	if err != nil {
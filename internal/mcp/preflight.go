@@ -0,0 +1,91 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/preflight"
+)
+
+// preflightBudget is how long preflight_check waits for its checks; replaced by tests
+var preflightBudget = preflight.DefaultBudget
+
+// handlePreflightCheck handles the preflight_check tool. It runs the quick suite of build
+// freshness, the env var audit and the service checks at once, and answers pass or fail with
+// the top blocking issues within the budget.
+func handlePreflightCheck(ctx context.Context, server *Server, args map[string]interface{}, configs []*config.EcosystemConfig) (interface{}, error) {
+	projectRoot, ok := args["project_root"].(string)
+	if !ok {
+		return nil, fmt.Errorf("project_root is required")
+	}
+
+	ecosystems, err := detector.DetectEcosystems(ctx, projectRoot, configs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect ecosystems: %w", err)
+	}
+	if len(ecosystems) == 0 {
+		return "No ecosystems detected in project", nil
+	}
+
+	checkArgs := map[string]interface{}{"project_root": projectRoot}
+	checks := []preflight.Check{
+		{Name: "verify_build_freshness", Run: func(ctx context.Context) (interface{}, error) {
+			return handleVerifyBuildFreshness(ctx, map[string]interface{}{"project_root": projectRoot, "suite": config.SuiteQuick}, configs)
+		}},
+		{Name: "env_var_audit", Run: func(ctx context.Context) (interface{}, error) {
+			return handleEnvVarAudit(ctx, checkArgs, configs)
+		}},
+		{Name: "check_infrastructure_parity", Run: func(ctx context.Context) (interface{}, error) {
+			return handleCheckInfrastructureParity(ctx, checkArgs, configs)
+		}},
+	}
+	return preflight.Run(ctx, projectRoot, checks, server.preflight, preflightBudget), nil
+}
+
+// formatPreflightReport formats a preflight_check result
+func formatPreflightReport(report *preflight.Report) string {
+	var b strings.Builder
+	elapsed := report.Elapsed.Round(time.Millisecond)
+	if report.Passed {
+		fmt.Fprintf(&b, "✅ Preflight passed (%s)\n", elapsed)
+	} else {
+		fmt.Fprintf(&b, "❌ Preflight failed: %d blocking issue(s) (%s)\n", report.TotalBlockers, elapsed)
+	}
+
+	for i, blocker := range report.Blockers {
+		fmt.Fprintf(&b, "\n%d. %s: %s [%s]\n", i+1, blocker.Severity, blocker.Message, blocker.Check)
+		if blocker.Fix != "" {
+			fmt.Fprintf(&b, "   Fix: %s\n", blocker.Fix)
+		}
+		if blocker.Fingerprint != "" {
+			fmt.Fprintf(&b, "   Run only this fix: reconcile_issue with fingerprint %s\n", blocker.Fingerprint)
+		}
+	}
+	if more := report.TotalBlockers - len(report.Blockers); more > 0 {
+		fmt.Fprintf(&b, "\n%d more blocking issue(s); run the checks for the full list\n", more)
+	}
+	if report.Warnings > 0 {
+		fmt.Fprintf(&b, "\n%d warning(s) that don't block a run\n", report.Warnings)
+	}
+
+	if len(report.Cached) > 0 {
+		var cached []string
+		for check, age := range report.Cached {
+			cached = append(cached, fmt.Sprintf("%s (%s ago)", check, age))
+		}
+		sort.Strings(cached)
+		fmt.Fprintf(&b, "\nFrom the previous run, still refreshing: %s\n", strings.Join(cached, ", "))
+	}
+	if len(report.Pending) > 0 {
+		fmt.Fprintf(&b, "\n⚠️ Not finished in time, so not included: %s; their results are used by the next preflight\n", strings.Join(report.Pending, ", "))
+	}
+	for _, e := range report.Errors {
+		fmt.Fprintf(&b, "\n⚠️ Check failed: %s\n", e)
+	}
+	return b.String()
+}
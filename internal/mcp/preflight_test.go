@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/preflight"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlePreflightCheck(t *testing.T) {
+	t.Setenv("NODE_ENV", "")
+	os.Unsetenv("NODE_ENV")
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte("{}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "app.js"), []byte("const env = process.env.NODE_ENV\n"), 0644))
+	configs := []*config.EcosystemConfig{{Ecosystem: config.Ecosystem{
+		ID:          "node-npm",
+		Detection:   config.Detection{RequiredFiles: []string{"package.json"}},
+		Environment: config.Environment{VariablePatterns: []string{`process\.env\.([A-Z_][A-Z0-9_]*)`}},
+	}}}
+	budget := preflightBudget
+	preflightBudget = 5 * time.Second
+	defer func() { preflightBudget = budget }()
+
+	server := NewServer()
+	result, err := handlePreflightCheck(context.Background(), server, map[string]interface{}{"project_root": tmpDir}, configs)
+	require.NoError(t, err)
+	report := result.(*preflight.Report)
+	assert.False(t, report.Passed)
+	assert.False(t, IsHealthyResult(report))
+	require.Len(t, report.Blockers, 1)
+	assert.Equal(t, "env_var_audit", report.Blockers[0].Check)
+
+	text := formatPreflightReport(report)
+	assert.Contains(t, text, "❌ Preflight failed: 1 blocking issue(s)")
+	assert.Contains(t, text, "\n1. error: Environment variable NODE_ENV is not set [env_var_audit]\n   Fix: Set NODE_ENV in the environment or .env\n")
+
+	result, err = handlePreflightCheck(context.Background(), server, map[string]interface{}{"project_root": t.TempDir()}, configs)
+	require.NoError(t, err)
+	assert.Equal(t, "No ecosystems detected in project", result)
+}
+
+func TestFormatPreflightReport(t *testing.T) {
+	report := &preflight.Report{
+		Passed:        false,
+		TotalBlockers: 4,
+		Blockers: []preflight.Blocker{
+			{Check: "verify_build_freshness", Type: "stale_build", Severity: "error", Message: "Build is stale", Fix: "npm run build", Fingerprint: "abc"},
+		},
+		Warnings: 2,
+		Cached:   map[string]string{"check_infrastructure_parity": "12s"},
+		Pending:  []string{},
+		Elapsed:  120 * time.Millisecond,
+	}
+
+	text := formatPreflightReport(report)
+	assert.Contains(t, text, "❌ Preflight failed: 4 blocking issue(s) (120ms)\n")
+	assert.Contains(t, text, "   Run only this fix: reconcile_issue with fingerprint abc\n")
+	assert.Contains(t, text, "3 more blocking issue(s); run the checks for the full list")
+	assert.Contains(t, text, "2 warning(s) that don't block a run")
+	assert.Contains(t, text, "From the previous run, still refreshing: check_infrastructure_parity (12s ago)")
+}
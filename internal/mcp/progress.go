@@ -0,0 +1,49 @@
+package mcp
+
+import (
+	"context"
+
+	"dev-env-sentinel/internal/queue"
+)
+
+type progressKey struct{}
+
+// progressFunc sends the progress of a tool call to the client that made it
+type progressFunc func(done, total int, message string)
+
+// withProgress returns a context whose tool call reports progress to fn
+func withProgress(ctx context.Context, fn progressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// reportProgress reports how far a long tool call has got: to the client, when it asked for
+// progress notifications with a progressToken, and on the call's job when it runs on the
+// queue, where get_job_status and the SSE job events show it
+func reportProgress(ctx context.Context, done, total int, message string) {
+	queue.SetProgress(ctx, queue.Progress{Done: done, Total: total, Message: message})
+	if fn, ok := ctx.Value(progressKey{}).(progressFunc); ok {
+		fn(done, total, message)
+	}
+}
+
+// progressNotifier returns the progress function of a stdio tool call, which sends MCP
+// notifications/progress messages, or nil when the call has no progress token
+func (s *Server) progressNotifier(params map[string]interface{}) progressFunc {
+	meta, _ := params["_meta"].(map[string]interface{})
+	token, ok := meta["progressToken"]
+	if !ok || token == nil {
+		return nil
+	}
+	return func(done, total int, message string) {
+		s.writeJSON(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  "notifications/progress",
+			"params": map[string]interface{}{
+				"progressToken": token,
+				"progress":      done,
+				"total":         total,
+				"message":       message,
+			},
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/reconciler"
+	"dev-env-sentinel/internal/registry"
+	"dev-env-sentinel/internal/remote"
+	"dev-env-sentinel/internal/runner"
+	"dev-env-sentinel/internal/settings"
+	"dev-env-sentinel/internal/snapshot"
+	"dev-env-sentinel/internal/state"
+)
+
+// ProjectHeader is the HTTP header selecting the registered project a request is scoped to.
+// The "project" query parameter does the same for clients that cannot set headers.
+const ProjectHeader = "X-Sentinel-Project"
+
+// AdminTokenEnvVar holds the token callers of the registry tools must present, as
+// "Authorization: Bearer <token>" over HTTP or authorization metadata over gRPC. Without it the
+// registry can't be changed remotely.
+const AdminTokenEnvVar = "SENTINEL_ADMIN_TOKEN"
+
+// ProjectsEnvVar enables the project registry of an HTTP or gRPC deployment. Without it the
+// server serves a single project, and tool calls don't need a project scope.
+const ProjectsEnvVar = "SENTINEL_PROJECTS"
+
+// registryTools manage the project registry. With a registry they are the only tools the shared
+// server answers without a project scope, and only for callers with the admin token.
+var registryTools = map[string]bool{
+	"register_project":   true,
+	"list_projects":      true,
+	"unregister_project": true,
+}
+
+// machineTools change state shared by every project on the machine: the license, trials and
+// the config layer. Projects don't get them; with a registry only the admin calls them unscoped.
+var machineTools = map[string]bool{
+	"activate_pro": true,
+	"start_trial":  true,
+	"fetch_config": true,
+}
+
+// ProjectsEnabled reports whether SENTINEL_PROJECTS enables the project registry
+func ProjectsEnabled() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(ProjectsEnvVar))) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// adminKey marks contexts of calls that presented the admin token
+type adminKey struct{}
+
+// SetRegistry enables the project registry of a shared HTTP deployment and registers the
+// tools managing it. It must be called after the tool policy is set. The admin token
+// defaults to $SENTINEL_ADMIN_TOKEN.
+func (s *Server) SetRegistry(reg *registry.Registry) {
+	s.registry = reg
+	s.tenants = make(map[string]*Server)
+	if s.adminToken == "" {
+		s.adminToken = os.Getenv(AdminTokenEnvVar)
+	}
+
+	s.RegisterTool("register_project", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleRegisterProject(ctx, s, args)
+	})
+	s.RegisterTool("list_projects", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return s.registry.List(), nil
+	})
+	s.RegisterTool("unregister_project", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleUnregisterProject(s, args)
+	})
+}
+
+// authorize marks a context as the admin's when the Authorization value carries the admin token
+func (s *Server) authorize(ctx context.Context, authorization string) context.Context {
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	if s.adminToken == "" || token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+		return ctx
+	}
+	return context.WithValue(ctx, adminKey{}, true)
+}
+
+// checkScope rejects tool calls the server can't answer unscoped: with a registry, the shared
+// server only serves the registry and machine tools, to the admin, so tenants can't skip their
+// project scope
+func (s *Server) checkScope(ctx context.Context, name string) error {
+	if s.registry == nil {
+		return nil
+	}
+	if !registryTools[name] && !machineTools[name] {
+		return fmt.Errorf("%s must be scoped to a registered project with the %s header or ?project=ID", name, ProjectHeader)
+	}
+	if s.adminToken == "" {
+		return fmt.Errorf("%s is disabled; set %s on the server to manage projects", name, AdminTokenEnvVar)
+	}
+	if admin, _ := ctx.Value(adminKey{}).(bool); !admin {
+		return fmt.Errorf("%s needs the admin token (Authorization: Bearer <token>)", name)
+	}
+	return nil
+}
+
+// servedTools returns the tools listed to clients: with a registry, the shared server only
+// lists the registry and machine tools
+func (s *Server) servedTools() []string {
+	if s.registry == nil {
+		return s.toolNames()
+	}
+	var names []string
+	for _, name := range s.toolNames() {
+		if registryTools[name] || machineTools[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Project returns the server scoped to a registered project. Each project server has its
+// own state directory, so snapshots, history and caches are isolated between projects.
+func (s *Server) Project(id string) (*Server, error) {
+	if s.registry == nil {
+		return nil, fmt.Errorf("project registry is not enabled")
+	}
+	project, ok := s.registry.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("unknown project: %s", id)
+	}
+
+	s.tenantsMu.Lock()
+	defer s.tenantsMu.Unlock()
+	if tenant, ok := s.tenants[id]; ok {
+		return tenant, nil
+	}
+
+	tenant := &Server{
+		tools:          make(map[string]ToolHandler),
+		license:        s.license,
+		featureManager: s.featureManager,
+		featureFlags:   s.featureFlags,
+		lapsed:         s.lapsed,
+		snapshots:      snapshot.NewStore(),
+		notifier:       s.notifier,
+		schedule:       make(map[string]settings.ScheduledCheck),
+		policy:         s.policy,
+		project:        &project,
+		locale:         s.locale,
+		headroom:       s.headroom,
+		fixPolicy:      s.fixPolicy,
+		executor:       s.executor,
+		toolTimeout:    s.toolTimeout,
+		strictPrivacy:  s.strictPrivacy,
+		retention:      s.retention,
+		fixLoops:       reconciler.NewCooldown(nil, s.fixLoops.Limits()),
+		fixDurations:   s.fixDurations,
+		preflight:      s.preflight,
+		templates:      s.templates,
+		template:       s.template,
+		machine:        s.machine,
+		telemetry:      s.telemetry,
+		tickets:        s.tickets,
+	}
+	if project.IsRemote() {
+		// Checks read the files over SFTP and commands run on the host
+		fs := remote.NewFS(project.Host, project.RemoteRoot)
+		unmount := common.Mount(project.Root, fs)
+		tenant.unmount = func() {
+			unmount()
+			fs.Close()
+		}
+		tenant.executor = runner.SSH{Host: project.Host, Workdir: project.RemoteRoot, LocalRoot: project.Root}
+	}
+	RegisterAllTools(tenant, s.configs)
+	for name := range machineTools {
+		delete(tenant.tools, name)
+	}
+	for name, handler := range tenant.tools {
+		tenant.tools[name] = scopeToProject(project, handler)
+	}
+	if s.queue != nil {
+		// Projects share the server's workers but only see their own jobs
+		tenant.queue = s.queue
+		tenant.registerJobTools()
+	}
+
+	dir, err := state.Open(s.registry.StateRoot(id))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: state directory of project %s unavailable, snapshots will not persist: %v\n", id, err)
+	} else {
+		tenant.SetState(dir)
+	}
+
+	s.tenants[id] = tenant
+	return tenant, nil
+}
+
+// scopeToProject restricts a tool to a project: project_root defaults to the project's root,
+// relative roots are resolved against it and roots outside it are rejected
+func scopeToProject(project registry.Project, handler ToolHandler) ToolHandler {
+	return func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		scoped := make(map[string]interface{}, len(args)+1)
+		for k, v := range args {
+			scoped[k] = v
+		}
+
+		root, _ := scoped["project_root"].(string)
+		if root == "" {
+			root = project.Root
+		} else if !filepath.IsAbs(root) {
+			root = filepath.Join(project.Root, root)
+		}
+		inside, err := common.IsSubpath(project.Root, root)
+		if err != nil || !inside {
+			return nil, fmt.Errorf("project_root %s is outside project %s (%s)", root, project.ID, project.Root)
+		}
+		scoped["project_root"] = root
+
+		return handler(ctx, scoped)
+	}
+}
+
+// handleRegisterProject handles the register_project tool
+func handleRegisterProject(ctx context.Context, server *Server, args map[string]interface{}) (interface{}, error) {
+	id, _ := args["id"].(string)
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	projectRoot, _ := args["project_root"].(string)
+	gitURL, _ := args["git_url"].(string)
+	sshHost, _ := args["ssh_host"].(string)
+
+	if sshHost != "" {
+		if gitURL != "" || projectRoot == "" {
+			return nil, fmt.Errorf("ssh_host takes the project_root on the host, not git_url")
+		}
+		fs := remote.NewFS(sshHost, projectRoot)
+		err := fs.Check()
+		fs.Close()
+		if err != nil {
+			return nil, fmt.Errorf("cannot read project_root %s on %s: %w", projectRoot, sshHost, err)
+		}
+		project, err := server.registry.RegisterRemote(id, sshHost, projectRoot)
+		if err != nil {
+			return nil, err
+		}
+		return fmt.Sprintf("✅ Registered project %s (%s on %s). Its checks and fixes run there. Scope requests to it with the %s header or ?project=%s.", project.ID, project.RemoteRoot, project.Host, ProjectHeader, project.ID), nil
+	}
+
+	project, err := server.registry.Register(ctx, id, projectRoot, gitURL)
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Sprintf("✅ Registered project %s (%s). Scope requests to it with the %s header or ?project=%s.", project.ID, project.Root, ProjectHeader, project.ID), nil
+}
+
+// handleUnregisterProject handles the unregister_project tool
+func handleUnregisterProject(server *Server, args map[string]interface{}) (interface{}, error) {
+	id, _ := args["id"].(string)
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+
+	// Release the project's store before its state directory is removed
+	server.tenantsMu.Lock()
+	if tenant, ok := server.tenants[id]; ok {
+		if tenant.store != nil {
+			tenant.store.Close()
+		}
+		if tenant.unmount != nil {
+			tenant.unmount()
+		}
+		delete(server.tenants, id)
+	}
+	server.tenantsMu.Unlock()
+
+	project, err := server.registry.Unregister(id)
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Sprintf("✅ Unregistered project %s; its history and caches were removed", project.ID), nil
+}
+
+// formatProjects formats the registered projects
+func formatProjects(projects []registry.Project) string {
+	if len(projects) == 0 {
+		return "No projects registered. Use register_project to add one."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📁 Registered projects (%d):\n\n", len(projects))
+	for _, p := range projects {
+		if p.IsRemote() {
+			fmt.Fprintf(&b, "- %s: %s on %s", p.ID, p.RemoteRoot, p.Host)
+		} else {
+			fmt.Fprintf(&b, "- %s: %s", p.ID, p.Root)
+		}
+		if p.GitURL != "" {
+			fmt.Fprintf(&b, " (cloned from %s)", p.GitURL)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
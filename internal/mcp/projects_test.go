@@ -0,0 +1,226 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/registry"
+	"dev-env-sentinel/internal/runner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRegistryServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	stateRoot := t.TempDir()
+	reg, err := registry.Open(stateRoot)
+	require.NoError(t, err)
+
+	server := NewServer()
+	RegisterAllTools(server, nil)
+	server.SetRegistry(reg)
+	return server, stateRoot
+}
+
+func TestProject_IsolatesState(t *testing.T) {
+	server, stateRoot := newRegistryServer(t)
+	apiRoot, webRoot := t.TempDir(), t.TempDir()
+
+	_, err := server.CallTool(context.Background(), "register_project", map[string]interface{}{"id": "api", "project_root": apiRoot})
+	require.NoError(t, err)
+	_, err = server.CallTool(context.Background(), "register_project", map[string]interface{}{"id": "web", "project_root": webRoot})
+	require.NoError(t, err)
+
+	projects, err := server.CallTool(context.Background(), "list_projects", nil)
+	require.NoError(t, err)
+	assert.Contains(t, formatResult(projects), "- api: "+apiRoot)
+
+	api, err := server.Project("api")
+	require.NoError(t, err)
+	web, err := server.Project("web")
+	require.NoError(t, err)
+	same, err := server.Project("api")
+	require.NoError(t, err)
+	assert.Same(t, api, same)
+
+	require.NotNil(t, api.stateDir)
+	assert.Equal(t, filepath.Join(stateRoot, registry.ProjectsDir, "api"), api.stateDir.Root())
+	assert.NotEqual(t, api.stateDir.Root(), web.stateDir.Root())
+	assert.NotContains(t, api.tools, "register_project", "projects cannot manage the registry")
+
+	_, err = api.CallTool(context.Background(), "check_portability", map[string]interface{}{"project_root": webRoot})
+	assert.ErrorContains(t, err, "outside project api")
+
+	_, err = server.Project("missing")
+	assert.ErrorContains(t, err, "unknown project: missing")
+
+	_, err = server.CallTool(context.Background(), "unregister_project", map[string]interface{}{"id": "api"})
+	require.NoError(t, err)
+	assert.NoDirExists(t, filepath.Join(stateRoot, registry.ProjectsDir, "api"))
+	_, err = server.Project("api")
+	assert.Error(t, err)
+}
+
+func TestProject_Remote(t *testing.T) {
+	server, _ := newRegistryServer(t)
+
+	_, err := server.CallTool(context.Background(), "register_project", map[string]interface{}{"id": "api", "ssh_host": "devbox", "git_url": "https://example.com/api.git"})
+	assert.ErrorContains(t, err, "ssh_host takes the project_root")
+
+	// Registering checks the directory over ssh, so the test registers directly
+	project, err := server.registry.RegisterRemote("api", "devbox", "/srv/api")
+	require.NoError(t, err)
+
+	projects, err := server.CallTool(context.Background(), "list_projects", nil)
+	require.NoError(t, err)
+	assert.Contains(t, formatResult(projects), "- api: /srv/api on devbox")
+
+	api, err := server.Project("api")
+	require.NoError(t, err)
+	assert.Equal(t, runner.SSH{Host: "devbox", Workdir: "/srv/api", LocalRoot: project.Root}, api.executor)
+	assert.True(t, common.IsMounted(filepath.Join(project.Root, "pom.xml")), "file checks read the host's files")
+
+	// Calls can't move the project's commands off its host
+	for _, executor := range []string{"local", "ssh:otherhost", "docker:x"} {
+		_, err = api.CallTool(context.Background(), "check_portability", map[string]interface{}{"executor": executor})
+		assert.ErrorContains(t, err, "executor can't be set for calls scoped to project api")
+	}
+
+	_, err = server.CallTool(context.Background(), "unregister_project", map[string]interface{}{"id": "api"})
+	require.NoError(t, err)
+	assert.False(t, common.IsMounted(project.Root))
+}
+
+func TestScopeToProject(t *testing.T) {
+	root := t.TempDir()
+	var got string
+	handler := scopeToProject(registry.Project{ID: "api", Root: root}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		got = args["project_root"].(string)
+		return nil, nil
+	})
+
+	_, err := handler(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, root, got)
+
+	_, err = handler(context.Background(), map[string]interface{}{"project_root": "services/auth"})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "services", "auth"), got)
+
+	_, err = handler(context.Background(), map[string]interface{}{"project_root": "../other"})
+	assert.Error(t, err)
+}
+
+func TestHandleMessage_ProjectScope(t *testing.T) {
+	server, _ := newRegistryServer(t)
+	_, err := server.CallTool(context.Background(), "register_project", map[string]interface{}{"id": "api", "project_root": t.TempDir()})
+	require.NoError(t, err)
+	handler := NewSSETransport("").handleMessage(server)
+
+	call := func(target, project string) map[string]interface{} {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+		if project != "" {
+			req.Header.Set(ProjectHeader, project)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response
+	}
+	toolNames := func(response map[string]interface{}) []string {
+		var names []string
+		for _, tool := range response["result"].(map[string]interface{})["tools"].([]interface{}) {
+			names = append(names, tool.(map[string]interface{})["name"].(string))
+		}
+		return names
+	}
+
+	assert.Contains(t, toolNames(call("/message", "")), "register_project")
+	assert.NotContains(t, toolNames(call("/message", "api")), "register_project")
+	assert.NotContains(t, toolNames(call("/message?project=api", "")), "register_project")
+
+	response := call("/message", "missing")
+	assert.Contains(t, response["error"].(map[string]interface{})["message"], "unknown project: missing")
+}
+
+func TestHandleMessage_Unscoped(t *testing.T) {
+	server, _ := newRegistryServer(t)
+	server.adminToken = "s3cret"
+	handler := NewSSETransport("").handleMessage(server)
+
+	call := func(project, authorization, tool string, args map[string]interface{}) map[string]interface{} {
+		body, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": map[string]interface{}{"name": tool, "arguments": args}})
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/message", strings.NewReader(string(body)))
+		if project != "" {
+			req.Header.Set(ProjectHeader, project)
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response
+	}
+	errorMessage := func(response map[string]interface{}) string {
+		e, _ := response["error"].(map[string]interface{})
+		message, _ := e["message"].(string)
+		return message
+	}
+
+	// Managing the registry needs the admin token
+	root := t.TempDir()
+	register := map[string]interface{}{"id": "api", "project_root": root}
+	assert.Contains(t, errorMessage(call("", "", "register_project", register)), "needs the admin token")
+	assert.Contains(t, errorMessage(call("", "Bearer wrong", "register_project", register)), "needs the admin token")
+	assert.Empty(t, errorMessage(call("", "Bearer s3cret", "register_project", register)))
+	assert.Contains(t, errorMessage(call("", "", "unregister_project", map[string]interface{}{"id": "api"})), "needs the admin token")
+
+	// Other tools only run scoped to a project
+	assert.Contains(t, errorMessage(call("", "Bearer s3cret", "check_portability", map[string]interface{}{"project_root": t.TempDir()})), "must be scoped to a registered project")
+	assert.Empty(t, errorMessage(call("api", "", "check_portability", nil)))
+	assert.NotContains(t, errorMessage(call("api", "", "register_project", register)), "admin token", "projects have no registry tools")
+
+	// The license, trials and config layer are shared by every project, so only the admin changes them
+	for _, tool := range []string{"activate_pro", "start_trial", "fetch_config"} {
+		assert.Contains(t, errorMessage(call("api", "", tool, nil)), "Unknown tool", tool)
+		assert.Contains(t, errorMessage(call("", "", tool, nil)), "needs the admin token", tool)
+	}
+
+	// Without a token the registry can't be changed remotely
+	server.adminToken = ""
+	assert.Contains(t, errorMessage(call("", "Bearer s3cret", "list_projects", nil)), "set "+AdminTokenEnvVar)
+}
+
+func TestHandleMessage_NoRegistry(t *testing.T) {
+	t.Setenv(ProjectsEnvVar, "")
+	assert.False(t, ProjectsEnabled(), "the registry is opt-in")
+
+	// A single-project deployment answers unscoped calls
+	server := NewServer()
+	RegisterAllTools(server, nil)
+	handler := NewSSETransport("").handleMessage(server)
+	body, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": map[string]interface{}{
+		"name": "env_var_audit", "arguments": map[string]interface{}{"project_root": t.TempDir()},
+	}})
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/message", strings.NewReader(string(body))))
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Nil(t, response["error"])
+	assert.NotNil(t, response["result"])
+
+	t.Setenv(ProjectsEnvVar, "true")
+	assert.True(t, ProjectsEnabled())
+}
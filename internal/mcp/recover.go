@@ -0,0 +1,185 @@
+package mcp
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/buildinfo"
+	"dev-env-sentinel/internal/privacy"
+	"dev-env-sentinel/internal/redact"
+	"dev-env-sentinel/internal/state"
+)
+
+// codeInternalError is the JSON-RPC error code for a tool handler that panicked
+const codeInternalError = -32603
+
+// crashDumpDir is the directory of crash dumps within the logs state category
+const crashDumpDir = "crashes"
+
+// maxCrashDumps bounds how many crash dumps are kept
+const maxCrashDumps = 20
+
+// PanicError is the error of a tool call whose handler panicked. The server keeps running.
+type PanicError struct {
+	Tool      string
+	Value     interface{}
+	Stack     []byte
+	CrashDump string // Path of the crash dump, when one was written
+}
+
+func (e *PanicError) Error() string {
+	msg := fmt.Sprintf("internal error in %s: %v", e.Tool, e.Value)
+	if e.CrashDump != "" {
+		msg += fmt.Sprintf(" (crash dump: %s)", e.CrashDump)
+	}
+	return msg
+}
+
+// crashDump is the content of a crash dump file
+type crashDump struct {
+	Time      time.Time      `json:"time"`
+	Tool      string         `json:"tool"`
+	Arguments []string       `json:"arguments"` // Argument names only; values may hold secrets
+	Panic     string         `json:"panic"`
+	Stack     string         `json:"stack"`
+	Build     buildinfo.Info `json:"build"`
+}
+
+// recoverTool converts a panic in a tool handler into a PanicError, writing a crash dump to
+// the state directory when SENTINEL_CRASH_DUMPS is set. It must be deferred by the handler.
+func (s *Server) recoverTool(name string, args map[string]interface{}, err *error) {
+	value := recover()
+	if value == nil {
+		return
+	}
+	panicErr := &PanicError{Tool: name, Value: value, Stack: debug.Stack()}
+	fmt.Fprintf(os.Stderr, "panic in tool %s: %v\n%s", name, value, panicErr.Stack)
+
+	if s.stateDir != nil && os.Getenv("SENTINEL_CRASH_DUMPS") == "true" {
+		path, dumpErr := writeCrashDump(s.stateDir, panicErr, args, s.strictPrivacy)
+		if dumpErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to write crash dump: %v\n", dumpErr)
+		}
+		panicErr.CrashDump = path
+	}
+	*err = panicErr
+}
+
+// writeCrashDump writes a crash dump to logs/crashes, removing the oldest beyond maxCrashDumps.
+// In strict privacy mode the stack is sanitized and paths in the panic value are withheld.
+func writeCrashDump(dir *state.Dir, panicErr *PanicError, args map[string]interface{}, strict bool) (string, error) {
+	dump := crashDump{
+		Time:      time.Now().UTC(),
+		Tool:      panicErr.Tool,
+		Arguments: []string{},
+		Panic:     fmt.Sprint(panicErr.Value),
+		Stack:     string(panicErr.Stack),
+		Build:     buildinfo.Get(),
+	}
+	if strict {
+		dump.Panic = privacy.Text(dump.Panic, "")
+		dump.Stack = strings.Join(sanitizeStack(panicErr.Stack), "\n")
+	}
+	for name := range args {
+		dump.Arguments = append(dump.Arguments, name)
+	}
+	sort.Strings(dump.Arguments)
+
+	name := filepath.Join(crashDumpDir, fmt.Sprintf("%s-%s.json", dump.Time.Format("20060102T150405.000000000"), panicErr.Tool))
+	if err := dir.WriteJSON(state.CategoryLogs, name, dump); err != nil {
+		return "", err
+	}
+
+	// Dump names sort by time
+	crashes := dir.Path(state.CategoryLogs, crashDumpDir)
+	if entries, err := os.ReadDir(crashes); err == nil && len(entries) > maxCrashDumps {
+		for _, entry := range entries[:len(entries)-maxCrashDumps] {
+			os.Remove(filepath.Join(crashes, entry.Name()))
+		}
+	}
+	return dir.Path(state.CategoryLogs, name), nil
+}
+
+// toolError returns the JSON-RPC error object of a failed tool call. Panics are internal errors
+// carrying a sanitized stack in data.
+func (s *Server) toolError(args map[string]interface{}, err error) map[string]interface{} {
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		return map[string]interface{}{
+			"code":    -1,
+			"message": s.localeFor(args).Localize(redact.String(err.Error())),
+		}
+	}
+
+	data := map[string]interface{}{
+		"tool":  panicErr.Tool,
+		"stack": sanitizeStack(panicErr.Stack),
+	}
+	if panicErr.CrashDump != "" {
+		data["crash_dump"] = panicErr.CrashDump
+	}
+	return map[string]interface{}{
+		"code":    codeInternalError,
+		"message": panicErr.Error(),
+		"data":    data,
+	}
+}
+
+var (
+	// Frame arguments: (0xc000123450, {0x1, 0x2}, 0x3?)
+	stackArgs = regexp.MustCompile(`\((0x[0-9a-f]+\??|\.\.\.|\{[^}]*\})(, (0x[0-9a-f]+\??|\.\.\.|\{[^}]*\}))*\)$`)
+	// Frame locations: \t/home/dev/src/dev-env-sentinel/internal/mcp/tools.go:42 +0x1d
+	stackFile = regexp.MustCompile(`^\t(.*?):(\d+)( \+0x[0-9a-f]+)?$`)
+)
+
+// sanitizeStack strips a goroutine stack down to the frames after the panic, without argument
+// values or the absolute paths of the machine the binary was built or runs on
+func sanitizeStack(stack []byte) []string {
+	lines := strings.Split(strings.TrimSpace(string(stack)), "\n")
+
+	// Skip the goroutine header and the frames of debug.Stack, the recovery and panic itself
+	start := 1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "panic(") {
+			start = i + 2
+			break
+		}
+	}
+
+	var frames []string
+	for i := start; i+1 < len(lines); i += 2 {
+		fn := stackArgs.ReplaceAllString(lines[i], "(...)")
+		location := lines[i+1]
+		if m := stackFile.FindStringSubmatch(location); m != nil {
+			location = trimSourcePath(m[1]) + ":" + m[2]
+		}
+		frames = append(frames, fn+" "+strings.TrimSpace(location))
+	}
+	return frames
+}
+
+// trimSourcePath shortens a source path to its module- or GOROOT-relative form
+func trimSourcePath(path string) string {
+	path = filepath.ToSlash(path)
+	if strings.HasPrefix(path, "dev-env-sentinel/") {
+		// Built with -trimpath
+		return path
+	}
+	if i := strings.LastIndex(path, "/dev-env-sentinel/"); i >= 0 {
+		return path[i+1:]
+	}
+	if i := strings.LastIndex(path, "/src/"); i >= 0 {
+		return path[i+len("/src/"):]
+	}
+	if i := strings.LastIndex(path, "/pkg/mod/"); i >= 0 {
+		return path[i+len("/pkg/mod/"):]
+	}
+	return filepath.Base(path)
+}
@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"dev-env-sentinel/internal/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPanickingServer(t *testing.T) *Server {
+	t.Helper()
+	server := NewServer()
+	server.RegisterTool("explode", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		var report map[string]string
+		report["issues"] = "none" // Assignment to a nil map
+		return report, nil
+	})
+	server.RegisterTool("ok_tool", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return "fine", nil
+	})
+	return server
+}
+
+func TestToolPanic_IsInternalError(t *testing.T) {
+	server := newPanickingServer(t)
+
+	resp := server.handleToolCallResponse(context.Background(), map[string]interface{}{
+		"id":     1,
+		"params": map[string]interface{}{"name": "explode", "arguments": map[string]interface{}{"api_key": "secret"}},
+	})
+	rpcErr := resp["error"].(map[string]interface{})
+	assert.Equal(t, codeInternalError, rpcErr["code"])
+	assert.Contains(t, rpcErr["message"], "internal error in explode: assignment to entry in nil map")
+
+	data := rpcErr["data"].(map[string]interface{})
+	stack := data["stack"].([]string)
+	require.NotEmpty(t, stack)
+	assert.Contains(t, stack[0], "newPanickingServer", "the stack starts at the panicking frame")
+	for _, frame := range stack {
+		assert.NotContains(t, frame, "0xc0", "argument values are removed")
+		assert.False(t, strings.Contains(frame, " /"), "absolute paths are removed: %s", frame)
+	}
+	assert.NotContains(t, data, "crash_dump")
+
+	// The server keeps serving
+	resp = server.handleToolCallResponse(context.Background(), map[string]interface{}{
+		"id":     2,
+		"params": map[string]interface{}{"name": "ok_tool"},
+	})
+	assert.Contains(t, resp, "result")
+}
+
+func TestToolPanic_CrashDump(t *testing.T) {
+	t.Setenv("SENTINEL_CRASH_DUMPS", "true")
+	dir, err := state.Open(t.TempDir())
+	require.NoError(t, err)
+	server := newPanickingServer(t)
+	server.SetState(dir)
+
+	_, err = server.CallTool(context.Background(), "explode", map[string]interface{}{"api_key": "secret"})
+	var panicErr *PanicError
+	require.ErrorAs(t, err, &panicErr)
+	require.NotEmpty(t, panicErr.CrashDump)
+
+	data, err := os.ReadFile(panicErr.CrashDump)
+	require.NoError(t, err)
+	var dump crashDump
+	require.NoError(t, json.Unmarshal(data, &dump))
+	assert.Equal(t, "explode", dump.Tool)
+	assert.Equal(t, []string{"api_key"}, dump.Arguments)
+	assert.NotContains(t, string(data), "secret")
+	assert.Contains(t, dump.Stack, "panic(")
+}
+
+func TestSanitizeStack(t *testing.T) {
+	stack := []byte(`goroutine 7 [running]:
+runtime/debug.Stack()
+	/usr/local/go/src/runtime/debug/stack.go:26 +0x5e
+panic({0x9c4f20?, 0xc000012345?})
+	/usr/local/go/src/runtime/panic.go:785 +0x132
+dev-env-sentinel/internal/mcp.handleVerifyBuildFreshness(0xc0001a2000, {0xc000010018, 0x1, 0x1})
+	/home/dev/src/dev-env-sentinel/internal/mcp/tools.go:160 +0x2b
+dev-env-sentinel/internal/mcp.RegisterAllTools.func1({0xb2d5a8, 0xc0000a6000}, 0xc0001a2000?)
+	dev-env-sentinel/internal/mcp/tools.go:31 +0x85
+`)
+	assert.Equal(t, []string{
+		"dev-env-sentinel/internal/mcp.handleVerifyBuildFreshness(...) dev-env-sentinel/internal/mcp/tools.go:160",
+		"dev-env-sentinel/internal/mcp.RegisterAllTools.func1(...) dev-env-sentinel/internal/mcp/tools.go:31",
+	}, sanitizeStack(stack))
+}
@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"dev-env-sentinel/internal/snapshot"
+)
+
+// snapshotResourcePrefix is the URI prefix for snapshot resources
+const snapshotResourcePrefix = "sentinel://snapshots"
+
+// handleResourcesListResponse handles resources/list and returns response map
+func (s *Server) handleResourcesListResponse(msg map[string]interface{}) map[string]interface{} {
+	resources := []map[string]interface{}{
+		{
+			"uri":         snapshotResourcePrefix,
+			"name":        "Environment snapshot",
+			"description": "Latest results of all scheduled checks",
+			"mimeType":    "application/json",
+		},
+	}
+
+	for _, job := range s.snapshots.Jobs() {
+		resources = append(resources, map[string]interface{}{
+			"uri":         snapshotResourcePrefix + "/" + job,
+			"name":        fmt.Sprintf("Snapshot: %s", job),
+			"description": fmt.Sprintf("Latest results of scheduled check %s", job),
+			"mimeType":    "application/json",
+		})
+	}
+
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      msg["id"],
+		"result": map[string]interface{}{
+			"resources": resources,
+		},
+	}
+}
+
+// handleResourcesReadResponse handles resources/read and returns response map
+func (s *Server) handleResourcesReadResponse(msg map[string]interface{}) map[string]interface{} {
+	params, _ := msg["params"].(map[string]interface{})
+	uri, _ := params["uri"].(string)
+
+	if uri != snapshotResourcePrefix && !strings.HasPrefix(uri, snapshotResourcePrefix+"/") {
+		return map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      msg["id"],
+			"error": map[string]interface{}{
+				"code":    -32602,
+				"message": fmt.Sprintf("Unknown resource: %s", uri),
+			},
+		}
+	}
+
+	entries := s.snapshots.Latest()
+	if job := strings.TrimPrefix(uri, snapshotResourcePrefix+"/"); job != uri {
+		entries = s.snapshots.ForJob(job)
+	}
+
+	if entries == nil {
+		entries = []snapshot.Entry{}
+	}
+	data, _ := json.MarshalIndent(entries, "", "  ")
+
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      msg["id"],
+		"result": map[string]interface{}{
+			"contents": []map[string]interface{}{
+				{
+					"uri":      uri,
+					"mimeType": "application/json",
+					"text":     string(data),
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,339 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"dev-env-sentinel/internal/auditor"
+	"dev-env-sentinel/internal/dockerfile"
+	"dev-env-sentinel/internal/flaky"
+	"dev-env-sentinel/internal/infra"
+	"dev-env-sentinel/internal/locale"
+	"dev-env-sentinel/internal/mirror"
+	"dev-env-sentinel/internal/nix"
+	"dev-env-sentinel/internal/notify"
+	"dev-env-sentinel/internal/portability"
+	"dev-env-sentinel/internal/preflight"
+	"dev-env-sentinel/internal/reconciler"
+	"dev-env-sentinel/internal/redact"
+	"dev-env-sentinel/internal/scheduler"
+	"dev-env-sentinel/internal/settings"
+	"dev-env-sentinel/internal/snapshot"
+	"dev-env-sentinel/internal/storage"
+	"dev-env-sentinel/internal/trends"
+	"dev-env-sentinel/internal/triage"
+	"dev-env-sentinel/internal/trust"
+	"dev-env-sentinel/internal/verifier"
+	"dev-env-sentinel/internal/wrapper"
+)
+
+// StartScheduler registers the configured scheduled checks and starts running them in the background.
+// Tools must already be registered on the server. Results are recorded in the server's snapshot store.
+func StartScheduler(ctx context.Context, server *Server, checks []settings.ScheduledCheck) (*scheduler.Scheduler, error) {
+	sched := scheduler.New()
+
+	for _, check := range checks {
+		for _, tool := range check.Checks {
+			if _, ok := server.tools[tool]; !ok {
+				return nil, fmt.Errorf("scheduled check %s references unknown tool: %s", check.Name, tool)
+			}
+		}
+
+		check := check
+		server.schedule[check.Name] = check
+		if err := sched.Add(check.Name, check.Cron, func(ctx context.Context) {
+			server.runScheduledCheck(ctx, check)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	sched.Start(ctx)
+	// Warm up snapshots immediately instead of waiting for the first activation
+	go sched.RunNow(ctx)
+
+	return sched, nil
+}
+
+// runScheduledCheck runs every tool of a scheduled check, records the results
+// and notifies the configured sinks when a check changes health
+func (s *Server) runScheduledCheck(ctx context.Context, check settings.ScheduledCheck) {
+	s.runChecks(ctx, check, true)
+}
+
+// runChecks runs every tool of a check, records the results in snapshots and history and,
+// with notifyDrift, notifies the sinks when a check changes health. It returns the results.
+func (s *Server) runChecks(ctx context.Context, check settings.ScheduledCheck, notifyDrift bool) []snapshot.Entry {
+	args := map[string]interface{}{
+		"project_root": check.ProjectRoot,
+	}
+
+	var entries, drifted, recovered []snapshot.Entry
+
+	for _, tool := range check.Checks {
+		entries = append(entries, s.runCheck(ctx, check.Name, tool, args))
+	}
+
+	// Mark checks whose results keep flipping at the same source revision
+	since := time.Now().Add(-flakyWindow)
+	history := s.checkHistory(storage.Query{Job: check.Name, Since: since})
+	components := flaky.Analyze(append(history, entries...), since)
+	isFlaky := make(map[string]bool)
+	for _, c := range components.Flaky() {
+		isFlaky[c.Job+"/"+c.Check] = true
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		entry.Flaky = isFlaky[check.Name+"/"+entry.Check]
+
+		if previous, ok := s.snapshots.Get(check.Name, entry.Check); ok {
+			wasHealthy := isHealthyEntry(previous)
+			if wasHealthy && !isHealthyEntry(*entry) {
+				drifted = append(drifted, *entry)
+			} else if !wasHealthy && isHealthyEntry(*entry) {
+				recovered = append(recovered, *entry)
+			}
+		}
+
+		s.snapshots.Record(*entry)
+		s.persistHistory(*entry)
+	}
+	s.persistSnapshots()
+
+	if notifyDrift && len(drifted) > 0 {
+		s.sendNotification(ctx, notify.NewDriftNotification(notify.EventDrift, check.Name, check.ProjectRoot, drifted))
+	}
+	if notifyDrift && len(recovered) > 0 {
+		s.sendNotification(ctx, notify.NewDriftNotification(notify.EventRecovery, check.Name, check.ProjectRoot, recovered))
+	}
+	return entries
+}
+
+// runCheck runs a single tool and converts its result into a snapshot entry without recording it
+func (s *Server) runCheck(ctx context.Context, job, tool string, args map[string]interface{}) snapshot.Entry {
+	entry := snapshot.Entry{
+		Job:     job,
+		Check:   tool,
+		Machine: s.machine,
+	}
+	entry.ProjectRoot, _ = args["project_root"].(string)
+	if entry.ProjectRoot != "" {
+		entry.Revision = flaky.Revision(ctx, entry.ProjectRoot)
+	}
+
+	handler, ok := s.tools[tool]
+	if !ok {
+		entry.Error = fmt.Sprintf("unknown tool: %s", tool)
+		entry.Timestamp = time.Now()
+		return entry
+	}
+
+	result, err := handler(ctx, args)
+	if err != nil {
+		entry.Error = redact.String(err.Error())
+	} else {
+		entry.Healthy = IsHealthyResult(result)
+		entry.Summary = formatResult(result)
+		if envReport, ok := result.(*auditor.EnvVarReport); ok {
+			entry.MissingEnvVars = envReport.Missing
+		}
+	}
+	entry.Timestamp = time.Now()
+
+	return entry
+}
+
+// sendNotification delivers a notification, logging failures since scheduled checks have no caller
+func (s *Server) sendNotification(ctx context.Context, n notify.Notification) {
+	if err := s.notifier.Send(ctx, n); err != nil {
+		fmt.Fprintf(os.Stderr, "error sending %s notification for %s: %v\n", n.Event, n.Job, err)
+	}
+}
+
+// isHealthyEntry reports whether a recorded check result is healthy
+func isHealthyEntry(entry snapshot.Entry) bool {
+	return entry.Error == "" && entry.Healthy
+}
+
+// IsHealthyResult reports whether a tool result represents a healthy environment
+func IsHealthyResult(result interface{}) bool {
+	switch v := result.(type) {
+	case *verifier.FreshnessReport:
+		return v.IsHealthy
+	case *infra.InfrastructureReport:
+		return v.IsHealthy
+	case *auditor.EnvVarReport:
+		return v.IsHealthy
+	case *reconciler.ReconciliationReport:
+		return v.IsSuccess
+	case *trust.TrustReport:
+		return v.IsHealthy
+	case *locale.LocaleReport:
+		return v.IsHealthy
+	case *portability.PortabilityReport:
+		return v.IsHealthy
+	case *wrapper.WrapperReport:
+		return v.IsHealthy
+	case *mirror.MirrorReport:
+		return v.IsHealthy
+	case *dockerfile.ParityReport:
+		return v.IsHealthy
+	case *nix.Report:
+		return v.IsHealthy
+	case *preflight.Report:
+		return v.Passed
+	case *triage.Report:
+		return len(v.Matches) == 0
+	default:
+		return true
+	}
+}
+
+// handleGetEnvironmentSnapshot handles the get_environment_snapshot tool
+func handleGetEnvironmentSnapshot(server *Server, args map[string]interface{}) (interface{}, error) {
+	var entries []snapshot.Entry
+	if job, ok := args["job"].(string); ok && job != "" {
+		entries = server.snapshots.ForJob(job)
+		if len(entries) == 0 {
+			return nil, fmt.Errorf("no snapshot recorded for job: %s", job)
+		}
+	} else {
+		entries = server.snapshots.Latest()
+	}
+
+	if len(entries) == 0 {
+		return "No snapshots recorded yet. Configure scheduled checks in sentinel.yaml to keep health data fresh.", nil
+	}
+
+	return entries, nil
+}
+
+// formatSnapshot formats the latest scheduled check results
+func formatSnapshot(entries []snapshot.Entry) string {
+	msg := "Environment Snapshot:\n"
+	currentJob := ""
+	for _, entry := range entries {
+		if entry.Job != currentJob {
+			currentJob = entry.Job
+			msg += fmt.Sprintf("\n%s (%s)\n", entry.Job, entry.ProjectRoot)
+			if entry.Machine != nil {
+				msg += fmt.Sprintf("Machine: %s\n", entry.Machine)
+			}
+		}
+
+		status := "✅"
+		if entry.Error != "" || !entry.Healthy {
+			status = "❌"
+		}
+		flakyMarker := ""
+		if entry.Flaky {
+			flakyMarker = " ⚠️ flaky"
+		}
+		msg += fmt.Sprintf("%s %s%s - checked %s\n", status, entry.Check, flakyMarker, entry.Timestamp.Format(time.RFC3339))
+		if entry.Error != "" {
+			msg += fmt.Sprintf("  Error: %s\n", entry.Error)
+		} else if !entry.Healthy {
+			msg += fmt.Sprintf("  %s\n", entry.Summary)
+		}
+	}
+	return msg
+}
+
+// handleGetFlakyComponents handles the get_flaky_components tool
+func handleGetFlakyComponents(server *Server, args map[string]interface{}) (interface{}, error) {
+	days := flakyWindow
+	if v, ok := args["days"].(float64); ok {
+		if v <= 0 {
+			return nil, fmt.Errorf("days must be positive")
+		}
+		days = time.Duration(v * float64(24*time.Hour))
+	}
+
+	since := time.Now().Add(-days)
+	job, _ := args["job"].(string)
+	report := flaky.Analyze(server.checkHistory(storage.Query{Job: job, Since: since}), since)
+	if report.Runs == 0 {
+		return "No check history recorded yet. Configure scheduled checks in sentinel.yaml to build up run history.", nil
+	}
+	return report, nil
+}
+
+// formatFlakyReport formats flake rates of scheduled checks
+func formatFlakyReport(report *flaky.FlakyReport) string {
+	flakyComponents := report.Flaky()
+	msg := fmt.Sprintf("✅ No flaky environment components in %d run(s) since %s\n", report.Runs, report.Since.Format("2006-01-02"))
+	if len(flakyComponents) > 0 {
+		msg = fmt.Sprintf("⚠️ %d flaky environment component(s) in %d run(s) since %s:\n", len(flakyComponents), report.Runs, report.Since.Format("2006-01-02"))
+	}
+
+	for _, c := range report.Components {
+		if c.Flips == 0 {
+			continue
+		}
+		marker := "-"
+		if c.Flaky {
+			marker = "⚠️"
+		}
+		msg += fmt.Sprintf("\n%s %s/%s: %d flip(s) in %d comparable run(s) (%.0f%% flake rate), last flip %s\n",
+			marker, c.Job, c.Check, c.Flips, c.Transitions, c.FlakeRate*100, c.LastFlip.Format(time.RFC3339))
+		for _, period := range c.Daily {
+			msg += fmt.Sprintf("  %s: %d/%d\n", period.Day.Format("2006-01-02"), period.Flips, period.Transitions)
+		}
+	}
+	return msg
+}
+
+// defaultTrendRuns is the number of recent runs summarized by get_environment_trends
+const defaultTrendRuns = 200
+
+// handleGetEnvironmentTrends handles the get_environment_trends tool
+func handleGetEnvironmentTrends(server *Server, args map[string]interface{}) (interface{}, error) {
+	limit := defaultTrendRuns
+	if v, ok := args["limit"].(float64); ok {
+		if v < 1 {
+			return nil, fmt.Errorf("limit must be at least 1")
+		}
+		limit = int(v)
+	}
+
+	job, _ := args["job"].(string)
+	history := server.checkHistory(storage.Query{Job: job, Limit: limit})
+	if len(history) == 0 {
+		return "No check history recorded yet. Configure scheduled checks in sentinel.yaml to build up run history.", nil
+	}
+	return trends.Summarize(history), nil
+}
+
+// formatTrendReport formats trends over the check history
+func formatTrendReport(report *trends.TrendReport) string {
+	msg := fmt.Sprintf("📋 Environment trends over %d run(s), %s to %s\n",
+		report.Runs, report.From.Format("2006-01-02"), report.To.Format("2006-01-02"))
+
+	if report.BuildChecks > 0 {
+		msg += fmt.Sprintf("\nBuild was stale in %d of %d freshness check(s) (%.0f%%)\n",
+			report.StaleBuilds, report.BuildChecks, 100*float64(report.StaleBuilds)/float64(report.BuildChecks))
+	}
+
+	msg += "\nChecks:\n"
+	for _, c := range report.Checks {
+		msg += fmt.Sprintf("- %s/%s: failed %d of %d run(s) (%.0f%%)", c.Job, c.Check, c.Failures, c.Runs, c.FailureRate*100)
+		if c.Fixes > 0 {
+			msg += fmt.Sprintf(", fixed %d time(s) in %s on average", c.Fixes, c.AverageFixTime.Round(time.Minute))
+		}
+		if !c.FailingSince.IsZero() {
+			msg += fmt.Sprintf(", failing since %s", c.FailingSince.Format(time.RFC3339))
+		}
+		msg += "\n"
+	}
+
+	if len(report.MissingEnvVars) > 0 {
+		msg += "\nEnvironment variables missing repeatedly:\n"
+		for _, v := range report.MissingEnvVars {
+			msg += fmt.Sprintf("- %s (%d runs)\n", v.Name, v.Runs)
+		}
+	}
+	return msg
+}
@@ -0,0 +1,242 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/auditor"
+	"dev-env-sentinel/internal/machine"
+	"dev-env-sentinel/internal/notify"
+	"dev-env-sentinel/internal/settings"
+	"dev-env-sentinel/internal/snapshot"
+	"dev-env-sentinel/internal/trends"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartScheduler(t *testing.T) {
+	server := NewServer()
+	server.RegisterTool("env_var_audit", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return &auditor.EnvVarReport{IsHealthy: false, Missing: []string{"API_KEY"}}, nil
+	})
+	server.RegisterTool("broken_check", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := StartScheduler(ctx, server, []settings.ScheduledCheck{
+		{Name: "api", Cron: "@hourly", ProjectRoot: "/work/api", Checks: []string{"env_var_audit", "broken_check"}},
+	})
+	require.NoError(t, err)
+
+	// The warm-up run records results without waiting for the first activation
+	assert.Eventually(t, func() bool { return len(server.snapshots.Latest()) == 2 }, 2*time.Second, 10*time.Millisecond)
+
+	entries := server.snapshots.ForJob("api")
+	assert.Equal(t, "broken_check", entries[0].Check)
+	assert.Equal(t, "boom", entries[0].Error)
+	assert.Equal(t, "env_var_audit", entries[1].Check)
+	assert.False(t, entries[1].Healthy)
+	assert.Contains(t, entries[1].Summary, "API_KEY")
+	assert.Equal(t, "/work/api", entries[1].ProjectRoot)
+	assert.Equal(t, machine.Current(), entries[1].Machine, "results record the platform they ran on")
+	assert.Contains(t, formatSnapshot(entries), "Machine: "+machine.Current().String())
+}
+
+func TestRunScheduledCheck_NotifiesOnDrift(t *testing.T) {
+	var posts int64
+	var lastEvent atomic.Value
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		lastEvent.Store(body["event"])
+		atomic.AddInt64(&posts, 1)
+	}))
+	defer hook.Close()
+
+	healthy := true
+	server := NewServer()
+	server.RegisterTool("env_var_audit", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return &auditor.EnvVarReport{IsHealthy: healthy}, nil
+	})
+	notifier, err := notify.NewDispatcher(settings.Notifications{
+		NotifyOnRecovery: true,
+		Sinks:            []settings.NotificationSink{{Type: "webhook", URL: hook.URL}},
+	})
+	require.NoError(t, err)
+	server.SetNotifier(notifier)
+
+	check := settings.ScheduledCheck{Name: "api", ProjectRoot: "/work/api", Checks: []string{"env_var_audit"}}
+
+	// The first run only establishes a baseline
+	server.runScheduledCheck(context.Background(), check)
+	assert.Equal(t, int64(0), atomic.LoadInt64(&posts))
+
+	// Staying healthy does not notify
+	server.runScheduledCheck(context.Background(), check)
+	assert.Equal(t, int64(0), atomic.LoadInt64(&posts))
+
+	healthy = false
+	server.runScheduledCheck(context.Background(), check)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&posts))
+	assert.Equal(t, "drift", lastEvent.Load())
+
+	// Staying unhealthy does not notify again
+	server.runScheduledCheck(context.Background(), check)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&posts))
+
+	healthy = true
+	server.runScheduledCheck(context.Background(), check)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&posts))
+	assert.Equal(t, "recovery", lastEvent.Load())
+}
+
+func TestStartScheduler_UnknownTool(t *testing.T) {
+	server := NewServer()
+
+	_, err := StartScheduler(context.Background(), server, []settings.ScheduledCheck{
+		{Name: "api", Cron: "@hourly", ProjectRoot: "/work/api", Checks: []string{"no_such_tool"}},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown tool")
+}
+
+func TestHandleGetEnvironmentSnapshot(t *testing.T) {
+	server := NewServer()
+
+	result, err := handleGetEnvironmentSnapshot(server, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Contains(t, result, "No snapshots recorded yet")
+
+	server.snapshots.Record(snapshot.Entry{Job: "api", ProjectRoot: "/work/api", Check: "env_var_audit", Summary: "missing API_KEY"})
+
+	result, err = handleGetEnvironmentSnapshot(server, map[string]interface{}{"job": "api"})
+	require.NoError(t, err)
+	formatted := formatResult(result)
+	assert.Contains(t, formatted, "api (/work/api)")
+	assert.Contains(t, formatted, "missing API_KEY")
+
+	_, err = handleGetEnvironmentSnapshot(server, map[string]interface{}{"job": "missing"})
+	assert.Error(t, err)
+}
+
+func TestResourcesResponses(t *testing.T) {
+	server := NewServer()
+	server.snapshots.Record(snapshot.Entry{Job: "api", Check: "env_var_audit", Healthy: true})
+
+	list := server.handleResourcesListResponse(map[string]interface{}{"id": 1})
+	resources := list["result"].(map[string]interface{})["resources"].([]map[string]interface{})
+	require.Len(t, resources, 2)
+	assert.Equal(t, "sentinel://snapshots", resources[0]["uri"])
+	assert.Equal(t, "sentinel://snapshots/api", resources[1]["uri"])
+
+	read := server.handleResourcesReadResponse(map[string]interface{}{
+		"id":     2,
+		"params": map[string]interface{}{"uri": "sentinel://snapshots/api"},
+	})
+	contents := read["result"].(map[string]interface{})["contents"].([]map[string]interface{})
+	var entries []snapshot.Entry
+	require.NoError(t, json.Unmarshal([]byte(contents[0]["text"].(string)), &entries))
+	require.Len(t, entries, 1)
+	assert.Equal(t, "env_var_audit", entries[0].Check)
+
+	unknown := server.handleResourcesReadResponse(map[string]interface{}{
+		"id":     3,
+		"params": map[string]interface{}{"uri": "file:///etc/passwd"},
+	})
+	assert.NotNil(t, unknown["error"])
+}
+
+func TestRunScheduledCheck_MarksFlaky(t *testing.T) {
+	results := []bool{true, false, true, false, true}
+	run := 0
+	server := NewServer()
+	server.RegisterTool("check_infrastructure_parity", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		healthy := results[run]
+		run++
+		return &auditor.EnvVarReport{IsHealthy: healthy}, nil
+	})
+
+	check := settings.ScheduledCheck{Name: "api", ProjectRoot: t.TempDir(), Checks: []string{"check_infrastructure_parity"}}
+	for range results {
+		server.runScheduledCheck(context.Background(), check)
+	}
+
+	entry, ok := server.snapshots.Get("api", "check_infrastructure_parity")
+	require.True(t, ok)
+	assert.True(t, entry.Flaky)
+	assert.Contains(t, formatSnapshot([]snapshot.Entry{entry}), "check_infrastructure_parity ⚠️ flaky")
+
+	result, err := handleGetFlakyComponents(server, map[string]interface{}{"job": "api"})
+	require.NoError(t, err)
+	formatted := formatResult(result)
+	assert.Contains(t, formatted, "1 flaky environment component(s) in 5 run(s)")
+	assert.Contains(t, formatted, "api/check_infrastructure_parity: 4 flip(s) in 4 comparable run(s) (100% flake rate)")
+
+	result, err = handleGetFlakyComponents(server, map[string]interface{}{"job": "other"})
+	require.NoError(t, err)
+	assert.Contains(t, result, "No check history recorded yet")
+
+	_, err = handleGetFlakyComponents(server, map[string]interface{}{"days": float64(0)})
+	assert.Error(t, err)
+}
+
+func TestHandleGetEnvironmentTrends(t *testing.T) {
+	server := NewServer()
+
+	result, err := handleGetEnvironmentTrends(server, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Contains(t, result, "No check history recorded yet")
+
+	missing := [][]string{{"API_KEY"}, nil, {"API_KEY", "REDIS_URL"}}
+	run := 0
+	server.RegisterTool("env_var_audit", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		report := &auditor.EnvVarReport{IsHealthy: len(missing[run]) == 0, Missing: missing[run]}
+		run++
+		return report, nil
+	})
+	check := settings.ScheduledCheck{Name: "api", ProjectRoot: t.TempDir(), Checks: []string{"env_var_audit"}}
+	for range missing {
+		server.runScheduledCheck(context.Background(), check)
+	}
+
+	result, err = handleGetEnvironmentTrends(server, map[string]interface{}{"job": "api"})
+	require.NoError(t, err)
+	formatted := formatResult(result)
+	assert.Contains(t, formatted, "Environment trends over 3 run(s)")
+	assert.Contains(t, formatted, "api/env_var_audit: failed 2 of 3 run(s) (67%), fixed 1 time(s)")
+	assert.Contains(t, formatted, "- API_KEY (2 runs)")
+	assert.NotContains(t, formatted, "REDIS_URL")
+
+	result, err = handleGetEnvironmentTrends(server, map[string]interface{}{"limit": float64(1)})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.(*trends.TrendReport).Runs)
+
+	_, err = handleGetEnvironmentTrends(server, map[string]interface{}{"limit": float64(0)})
+	assert.Error(t, err)
+}
+
+func TestRunCheck_RedactsSecrets(t *testing.T) {
+	server := NewServer()
+	server.RegisterTool("env_var_audit", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return "DATABASE_PASSWORD=hunter2-staging", nil
+	})
+	server.RegisterTool("broken_check", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return nil, errors.New("auth failed with Bearer sk_live_0123456789abcdefABCD")
+	})
+
+	server.runScheduledCheck(context.Background(), settings.ScheduledCheck{Name: "api", Checks: []string{"env_var_audit", "broken_check"}})
+
+	entries := server.snapshots.ForJob("api")
+	require.Len(t, entries, 2)
+	assert.Equal(t, "auth failed with Bearer [REDACTED]", entries[0].Error)
+	assert.Equal(t, "DATABASE_PASSWORD=[REDACTED]", entries[1].Summary)
+}
@@ -3,16 +3,57 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"dev-env-sentinel/internal/auditor"
+	"dev-env-sentinel/internal/buildinfo"
+	"dev-env-sentinel/internal/cmdlog"
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/container"
+	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/dockerfile"
 	"dev-env-sentinel/internal/features"
+	"dev-env-sentinel/internal/flaky"
+	"dev-env-sentinel/internal/i18n"
 	"dev-env-sentinel/internal/infra"
 	"dev-env-sentinel/internal/license"
+	"dev-env-sentinel/internal/locale"
+	"dev-env-sentinel/internal/nix"
+	"dev-env-sentinel/internal/machine"
+	"dev-env-sentinel/internal/marketplace"
+	"dev-env-sentinel/internal/mirror"
+	"dev-env-sentinel/internal/notify"
+	"dev-env-sentinel/internal/onboard"
+	"dev-env-sentinel/internal/portability"
+	"dev-env-sentinel/internal/preflight"
+	"dev-env-sentinel/internal/profile"
+	"dev-env-sentinel/internal/queue"
 	"dev-env-sentinel/internal/reconciler"
+	"dev-env-sentinel/internal/redact"
+	"dev-env-sentinel/internal/registry"
+	"dev-env-sentinel/internal/resources"
+	"dev-env-sentinel/internal/runner"
+	"dev-env-sentinel/internal/settings"
+	"dev-env-sentinel/internal/snapshot"
+	"dev-env-sentinel/internal/state"
+	"dev-env-sentinel/internal/storage"
+	"dev-env-sentinel/internal/telemetry"
+	"dev-env-sentinel/internal/templates"
+	"dev-env-sentinel/internal/tickets"
+	"dev-env-sentinel/internal/trace"
+	"dev-env-sentinel/internal/trends"
+	"dev-env-sentinel/internal/triage"
+	"dev-env-sentinel/internal/trust"
 	"dev-env-sentinel/internal/verifier"
+	"dev-env-sentinel/internal/wrapper"
 )
 
 // Server represents the MCP server
@@ -20,6 +61,46 @@ type Server struct {
 	tools          map[string]ToolHandler
 	license        *license.License
 	featureManager *features.FeatureManager
+	featureFlags   map[string]bool // Feature flag overrides from the settings file
+	lapsed         *license.Entitlement // The stored key's last valid state, when it no longer validates
+	noticeMu       sync.Mutex
+	noticeLogged   time.Time // When the renewal reminder was last logged
+	snapshots      *snapshot.Store
+	notifier       *notify.Dispatcher
+	schedule       map[string]settings.ScheduledCheck
+	stateDir       *state.Dir
+	store          storage.Store
+	strictPrivacy  bool            // Persist no command output, variable values or paths outside the project
+	retention      state.Retention // How long and how much check history and logs are kept
+	retentionMu    sync.Mutex
+	lastRetention  time.Time // When history and logs were last expired
+	policy         *profile.Policy
+	configs        []*config.EcosystemConfig
+	registry       *registry.Registry
+	project        *registry.Project // Set on servers scoped to a registered project
+	adminToken     string            // Token callers of the registry tools must present
+	tenants        map[string]*Server
+	tenantsMu      sync.Mutex
+	queue          *queue.Queue     // Executes HTTP tool calls on a bounded number of workers
+	commandLog     *cmdlog.Log      // Full output of the commands run by tools
+	locale         i18n.Locale      // Language and character set of tool output, unless a call sets lang
+	headroom       resources.Policy // Free resources heavy fixes need
+	fixPolicy      reconciler.FixPolicy // Which fixes run without confirmation, per issue severity
+	executor       runner.Executor      // Where commands run unless a call sets executor
+	toolTimeout    time.Duration        // Longest a tool call may run; 0 for no limit
+	unmount        func()               // Releases the files of a project on an ssh host
+	fixLoops       *reconciler.Cooldown // Recent fix failures, to refuse fixes that keep failing
+	fixDurations   *reconciler.FixDurations // How long fixes took, to estimate how long they take
+	preflight      *preflight.Cache // Latest check results, for preflight checks that run out of time
+	tracer         *trace.Log       // JSON-RPC messages, when SENTINEL_TRACE is set
+	templates      *templates.Set   // Team templates for tool output
+	template       string           // Template used unless a call sets template
+	machine        *machine.Fingerprint // Platform recorded with scheduled check results
+	telemetry      *telemetry.Recorder  // Anonymous usage statistics, when the user opted in
+	tickets        *tickets.Exporter    // Files issues with GitHub or Jira, when configured
+	slack          settings.Slack       // Slash command endpoint of the HTTP transport
+	webhooks       settings.Webhooks    // Push webhook endpoint of the HTTP transport
+	pushMu         sync.Mutex           // Serializes push verifications, which share a checkout
 }
 
 // ToolHandler is a function that handles a tool call
@@ -38,7 +119,14 @@ func NewServer() *Server {
 	
 	// Validate license
 	validator := license.NewLicenseValidator()
-	lic, _ := validator.ValidateLicense(key)
+	lic, err := validator.ValidateLicense(key)
+	var lapsed *license.Entitlement
+	if err == nil {
+		storage.SaveEntitlement(lic)
+	} else {
+		lapsed = storage.LoadEntitlement(key)
+	}
+	lic.Trials, _ = storage.LoadTrials(machine.Current().ID)
 	
 	// Create feature manager
 	featureManager := features.NewFeatureManager(lic)
@@ -47,6 +135,15 @@ func NewServer() *Server {
 		tools:          make(map[string]ToolHandler),
 		license:        lic,
 		featureManager: featureManager,
+		lapsed:         lapsed,
+		snapshots:      snapshot.NewStore(),
+		schedule:       make(map[string]settings.ScheduledCheck),
+		locale:         i18n.FromEnv(),
+		headroom:       resources.Default,
+		fixLoops:       reconciler.NewCooldown(nil, reconciler.DefaultLoopLimits),
+		fixDurations:   reconciler.NewFixDurations(nil),
+		preflight:      preflight.NewCache(),
+		machine:        machine.Current(),
 	}
 }
 
@@ -58,17 +155,221 @@ func (s *Server) UpdateLicense(key string) error {
 		return err
 	}
 	
+	storage := license.NewStorage()
+	lic.Trials, _ = storage.LoadTrials(s.machine.ID)
 	s.license = lic
-	s.featureManager = features.NewFeatureManager(lic)
+	s.featureManager = s.newFeatureManager(lic)
+	s.lapsed = nil
 	
 	// Save to storage
-	storage := license.NewStorage()
+	storage.SaveEntitlement(lic)
 	return storage.SaveLicense(key)
 }
 
-// RegisterTool registers a tool handler
+// RenewalNotice returns the reminder to renew a license that expires soon or has lapsed, if any
+func (s *Server) RenewalNotice() string {
+	return license.RenewalNotice(s.license, s.lapsed, time.Now())
+}
+
+// LogRenewalNotice logs the renewal reminder to stderr, at most once a day, and returns it
+func (s *Server) LogRenewalNotice() string {
+	notice := s.RenewalNotice()
+	if notice == "" {
+		return ""
+	}
+	s.noticeMu.Lock()
+	defer s.noticeMu.Unlock()
+	if time.Since(s.noticeLogged) >= 24*time.Hour {
+		s.noticeLogged = time.Now()
+		fmt.Fprintf(os.Stderr, "warning: %s\n", notice)
+	}
+	return notice
+}
+
+// renewalNotice returns the renewal reminder appended to a tool's result. License tools show
+// the license state themselves.
+func (s *Server) renewalNotice(tool string) string {
+	if licenseTools[tool] {
+		return ""
+	}
+	if notice := s.LogRenewalNotice(); notice != "" {
+		return "\n\n" + notice
+	}
+	return ""
+}
+
+// licenseTools report the license state themselves, without renewal reminders
+var licenseTools = map[string]bool{
+	"get_pro_license":      true,
+	"activate_pro":         true,
+	"check_license_status": true,
+	"start_trial":          true,
+	"list_features":        true,
+}
+
+// StartTrial starts a time-boxed trial of a premium feature on this machine and unlocks it
+func (s *Server) StartTrial(feature string, days int) (license.Trial, error) {
+	storage := license.NewStorage()
+	trial, err := storage.StartTrial(s.machine.ID, feature, days, time.Now())
+	if err != nil {
+		return trial, err
+	}
+	lic := *s.license
+	lic.Trials, _ = storage.LoadTrials(s.machine.ID)
+	s.license = &lic
+	s.featureManager = s.newFeatureManager(&lic)
+	return trial, nil
+}
+
+// SetFeatureFlags sets the feature flag overrides of the settings file
+func (s *Server) SetFeatureFlags(overrides map[string]bool) {
+	s.featureFlags = overrides
+	s.featureManager.SetOverrides(overrides)
+}
+
+// newFeatureManager creates the feature manager for a license, keeping the flag overrides
+func (s *Server) newFeatureManager(lic *license.License) *features.FeatureManager {
+	featureManager := features.NewFeatureManager(lic)
+	featureManager.SetOverrides(s.featureFlags)
+	return featureManager
+}
+
+// SetTickets sets the exporter export_issue files tickets with
+func (s *Server) SetTickets(exporter *tickets.Exporter) {
+	exporter.SetStrictPrivacy(s.strictPrivacy)
+	s.tickets = exporter
+}
+
+// SetNotifier sets the dispatcher used to report drift found by scheduled checks
+func (s *Server) SetNotifier(notifier *notify.Dispatcher) {
+	s.notifier = notifier
+}
+
+// CallTool invokes a registered tool directly, without going through a transport
+func (s *Server) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	handler, ok := s.tools[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown tool: %s", name)
+	}
+	return handler(ctx, args)
+}
+
+// toolNames returns the names of the registered tools in alphabetical order, so tool lists
+// don't change between calls
+func (s *Server) toolNames() []string {
+	names := make([]string, 0, len(s.tools))
+	for name := range s.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetToolPolicy sets the policy deciding which tools are exposed and whether fixes may run.
+// It must be set before tools are registered.
+func (s *Server) SetToolPolicy(policy *profile.Policy) {
+	s.policy = policy
+}
+
+// SetTemplates sets the team templates tool output can be rendered with, and the template
+// used when a call doesn't select one; empty for the built-in rendering
+func (s *Server) SetTemplates(set *templates.Set, defaultName string) {
+	s.templates = set
+	s.template = defaultName
+}
+
+// SetTracer sets the log JSON-RPC messages are traced to; nil turns tracing off
+func (s *Server) SetTracer(tracer *trace.Log) {
+	s.tracer = tracer
+}
+
+// SetHeadroom sets the free memory, CPU and disk heavy fixes need, and whether they are refused
+// without it. It must be set before tools are registered.
+func (s *Server) SetHeadroom(policy resources.Policy) {
+	s.headroom = policy
+}
+
+// SetFixPolicy sets per issue severity whether fixes run automatically, need confirmation or
+// never run
+func (s *Server) SetFixPolicy(policy reconciler.FixPolicy) {
+	s.fixPolicy = policy
+}
+
+// SetExecutor sets where the commands of checks and fixes run unless a call sets executor
+func (s *Server) SetExecutor(executor runner.Executor) {
+	s.executor = executor
+}
+
+// SetToolTimeout sets the longest a tool call may run; 0 for no limit
+func (s *Server) SetToolTimeout(timeout time.Duration) {
+	s.toolTimeout = timeout
+}
+
+// executorFor returns the executor a tool call asks for, else the server's. Calls scoped to a
+// registered project can't choose one, so they can't leave the host the project is pinned to.
+func (s *Server) executorFor(args map[string]interface{}) (runner.Executor, error) {
+	if spec, ok := args["executor"].(string); ok && spec != "" {
+		if s.project != nil {
+			return nil, fmt.Errorf("executor can't be set for calls scoped to project %s", s.project.ID)
+		}
+		return runner.ParseExecutor(spec)
+	}
+	return s.executor, nil
+}
+
+// SetFixLoopLimits sets after how many recent failures a fix is refused for manual action
+func (s *Server) SetFixLoopLimits(limits reconciler.LoopLimits) {
+	s.fixLoops.SetLimits(limits)
+}
+
+// SetTelemetry sets the recorder of anonymous usage statistics
+func (s *Server) SetTelemetry(recorder *telemetry.Recorder) {
+	s.telemetry = recorder
+}
+
+// RegisterTool registers a tool handler. Tools not allowed by the tool policy are skipped.
+// The commands a tool runs are recorded in the command log once a state directory is set,
+// and a panicking handler fails its call with a PanicError instead of crashing the server.
+// With a tool timeout, the call's context has a deadline that detection, file scans and
+// commands stop at.
 func (s *Server) RegisterTool(name string, handler ToolHandler) {
-	s.tools[name] = handler
+	if !s.policy.AllowsTool(name) {
+		return
+	}
+	s.tools[name] = func(ctx context.Context, args map[string]interface{}) (result interface{}, err error) {
+		start := time.Now()
+		defer func() { s.telemetry.Record(name, time.Since(start), err, issueTypes(result)) }()
+		defer s.recoverTool(name, args, &err)
+		if s.toolTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, s.toolTimeout)
+			defer cancel()
+		}
+		// An unknown template is rejected before the tool runs, not after a fix has
+		if tmpl, ok := args["template"].(string); ok && tmpl != "" && !s.templates.Has(tmpl) {
+			return nil, fmt.Errorf("unknown template: %s (available: %s)", tmpl, strings.Join(append([]string{templates.Default}, s.templates.Names()...), ", "))
+		}
+		if err := ValidateVerbosity(verbosityFor(args)); err != nil {
+			return nil, err
+		}
+		executor, err := s.executorFor(args)
+		if err != nil {
+			return nil, err
+		}
+		ctx = runner.WithExecutor(ctx, executor)
+		if s.commandLog != nil {
+			ctx = runner.WithRecorder(ctx, s.commandLog)
+		}
+		ctx = resources.WithPolicy(ctx, s.headroom)
+		ctx = reconciler.WithFixPolicy(ctx, s.fixPolicy)
+		ctx = reconciler.WithCooldown(ctx, s.fixLoops)
+		ctx = reconciler.WithFixDurations(ctx, s.fixDurations)
+		result, err = handler(ctx, args)
+		if errors.Is(err, context.DeadlineExceeded) && s.toolTimeout > 0 {
+			err = fmt.Errorf("%s did not finish within the tool timeout of %s: %w", name, s.toolTimeout, err)
+		}
+		return result, err
+	}
 }
 
 // Start starts the MCP server with the default transport (detected automatically)
@@ -89,6 +390,9 @@ func (s *Server) initialize() error {
 	if err := s.readJSON(&initReq); err != nil {
 		return err
 	}
+	if symbols, ok := clientSymbols(initReq); ok {
+		s.SetSymbols(symbols)
+	}
 
 	// Send initialize response
 	initResp := map[string]interface{}{
@@ -97,11 +401,12 @@ func (s *Server) initialize() error {
 		"result": map[string]interface{}{
 			"protocolVersion": "2024-11-05",
 			"capabilities": map[string]interface{}{
-				"tools": map[string]interface{}{},
+				"tools":     map[string]interface{}{},
+				"resources": map[string]interface{}{},
 			},
 			"serverInfo": map[string]interface{}{
 				"name":    "dev-env-sentinel",
-				"version": "0.1.0",
+				"version": buildinfo.Get().Version,
 			},
 		},
 	}
@@ -110,7 +415,7 @@ func (s *Server) initialize() error {
 }
 
 // messageLoop processes incoming messages
-func (s *Server) messageLoop() error {
+func (s *Server) messageLoop(ctx context.Context) error {
 	for {
 		var msg map[string]interface{}
 		if err := s.readJSON(&msg); err != nil {
@@ -122,7 +427,7 @@ func (s *Server) messageLoop() error {
 
 		// Handle different message types
 		if method, ok := msg["method"].(string); ok {
-			if err := s.handleMethod(method, msg); err != nil {
+			if err := s.handleMethod(ctx, method, msg); err != nil {
 				// Log error but continue
 				continue
 			}
@@ -131,12 +436,16 @@ func (s *Server) messageLoop() error {
 }
 
 // handleMethod handles a method call
-func (s *Server) handleMethod(method string, msg map[string]interface{}) error {
+func (s *Server) handleMethod(ctx context.Context, method string, msg map[string]interface{}) error {
 	switch method {
 	case "tools/list":
 		return s.handleToolsList(msg)
 	case "tools/call":
-		return s.handleToolCall(msg)
+		return s.handleToolCall(ctx, msg)
+	case "resources/list":
+		return s.writeJSON(s.handleResourcesListResponse(msg))
+	case "resources/read":
+		return s.writeJSON(s.handleResourcesReadResponse(msg))
 	default:
 		// Unknown method - ignore
 		return nil
@@ -145,28 +454,11 @@ func (s *Server) handleMethod(method string, msg map[string]interface{}) error {
 
 // handleToolsList handles the tools/list request
 func (s *Server) handleToolsList(msg map[string]interface{}) error {
-	tools := []map[string]interface{}{}
-
-	for name := range s.tools {
-		tools = append(tools, map[string]interface{}{
-			"name":        name,
-			"description": getToolDescription(name),
-		})
-	}
-
-	resp := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"id":      msg["id"],
-		"result": map[string]interface{}{
-			"tools": tools,
-		},
-	}
-
-	return s.writeJSON(resp)
+	return s.writeJSON(s.handleToolsListResponse(msg))
 }
 
 // handleToolCall handles a tool call request
-func (s *Server) handleToolCall(msg map[string]interface{}) error {
+func (s *Server) handleToolCall(ctx context.Context, msg map[string]interface{}) error {
 	params, ok := msg["params"].(map[string]interface{})
 	if !ok {
 		return fmt.Errorf("invalid params")
@@ -184,17 +476,27 @@ func (s *Server) handleToolCall(msg map[string]interface{}) error {
 
 	args, _ := params["arguments"].(map[string]interface{})
 
-	// Execute tool
-	result, err := handler(context.Background(), args)
+	// Long operations can run as background jobs so the client isn't blocked
+	var result interface{}
+	var err error
+	if async, jobArgs := isAsync(args); async && s.queue != nil && !unqueuedTools[name] {
+		var job queue.Job
+		if job, err = s.startJob(name, handler, jobArgs); err == nil {
+			result = formatQueuedJob(job)
+		}
+	} else {
+		// Execute tool
+		if notify := s.progressNotifier(params); notify != nil {
+			ctx = withProgress(ctx, notify)
+		}
+		result, err = handler(ctx, args)
+	}
 	if err != nil {
 		// Send error response
 		resp := map[string]interface{}{
 			"jsonrpc": "2.0",
 			"id":      msg["id"],
-			"error": map[string]interface{}{
-				"code":    -1,
-				"message": err.Error(),
-			},
+			"error":   s.toolError(args, err),
 		}
 		return s.writeJSON(resp)
 	}
@@ -207,7 +509,7 @@ func (s *Server) handleToolCall(msg map[string]interface{}) error {
 			"content": []map[string]interface{}{
 				{
 					"type": "text",
-					"text": formatResult(result),
+					"text": s.renderResult(name, args, result),
 				},
 			},
 		},
@@ -216,14 +518,83 @@ func (s *Server) handleToolCall(msg map[string]interface{}) error {
 	return s.writeJSON(resp)
 }
 
+// RenderResult renders a tool result as text the way tool calls return it, with the call's
+// lang, template and verbosity arguments
+func (s *Server) RenderResult(tool string, args map[string]interface{}, result interface{}) string {
+	return s.renderResult(tool, args, result)
+}
+
+// renderResult renders a tool result as text: with the template the call or the server
+// selects when there is one for the tool, otherwise with the built-in rendering at the call's
+// verbosity in the call's locale. A template that fails falls back to the built-in rendering.
+// Secrets are masked either way.
+func (s *Server) renderResult(tool string, args map[string]interface{}, result interface{}) string {
+	text := applyVerbosity(formatResult(result), verbosityFor(args), result)
+	text = s.localeFor(args).Localize(text + s.renewalNotice(tool))
+	name := s.template
+	if tmpl, ok := args["template"].(string); ok && tmpl != "" {
+		name = tmpl
+	}
+
+	rendered, ok, err := s.templates.Render(name, templates.Data{Tool: tool, Result: result, Text: text})
+	if !ok {
+		return text
+	}
+	if err != nil {
+		return text + fmt.Sprintf("\n\n⚠️ Template %s failed: %v", name, err)
+	}
+	// Templates see the result itself, secrets included
+	return redact.String(rendered)
+}
+
+// localeFor returns the locale a tool call's output is rendered in: its lang argument,
+// or the server's locale from SENTINEL_LANG
+func (s *Server) localeFor(args map[string]interface{}) i18n.Locale {
+	loc := s.locale
+	if lang, ok := args["lang"].(string); ok && lang != "" {
+		loc = i18n.Parse(lang)
+		loc.Symbols = s.locale.Symbols
+	}
+	if name, ok := args["symbols"].(string); ok {
+		if symbols, err := i18n.ParseSymbols(name); err == nil {
+			loc.Symbols = symbols
+		}
+	}
+	return loc
+}
+
+// SetSymbols sets the status markers tool output uses: unicode emoji, ascii words or none
+func (s *Server) SetSymbols(symbols i18n.SymbolSet) {
+	s.locale.Symbols = symbols
+}
+
+// clientSymbols returns the symbol set a client asks for in its initialize request,
+// as capabilities.experimental.symbols
+func clientSymbols(initReq map[string]interface{}) (i18n.SymbolSet, bool) {
+	params, _ := initReq["params"].(map[string]interface{})
+	capabilities, _ := params["capabilities"].(map[string]interface{})
+	experimental, _ := capabilities["experimental"].(map[string]interface{})
+	name, ok := experimental["symbols"].(string)
+	if !ok {
+		return "", false
+	}
+	symbols, err := i18n.ParseSymbols(name)
+	return symbols, err == nil
+}
+
 // readJSON reads a JSON message from stdin
 func (s *Server) readJSON(v interface{}) error {
 	decoder := json.NewDecoder(os.Stdin)
-	return decoder.Decode(v)
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	s.tracer.Record(trace.In, "stdio", v)
+	return nil
 }
 
 // writeJSON writes a JSON message to stdout
 func (s *Server) writeJSON(v interface{}) error {
+	s.tracer.Record(trace.Out, "stdio", v)
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(v)
@@ -235,16 +606,59 @@ func getToolDescription(name string) string {
 		"verify_build_freshness":    "Verify that build artifacts are up-to-date with source manifests",
 		"check_infrastructure_parity": "Check if required services are running and correct versions",
 		"env_var_audit":            "Audit environment variables for missing or incorrect values",
+		"check_trust_stores":       "Check that custom CA certificates are trusted by each ecosystem's tooling",
+		"check_locale":             "Check time zone, locale and system clock drift against ecosystem requirements",
+		"check_portability":        "Check shell scripts for CRLF line endings and missing executable bits, and .gitattributes/core.autocrlf settings",
+		"check_build_wrappers":     "Verify Maven/Gradle wrapper scripts, jars and distribution URLs/checksums, and suggest regeneration fixes",
+		"check_mirrors":            "Probe declared Maven/Gradle mirrors and npm registries for reachability and latency",
+		"check_container_runtime":  "Check that Docker or Podman is installed and its daemon reachable, with the current context, disk usage of images/volumes versus max_disk_gb (default 50), rootless vs rootful mismatches, and on macOS/Windows the Docker Desktop, Colima, Rancher Desktop, OrbStack or Podman VM: whether it runs, its CPUs/memory versus the compose file's reservations (or min_cpus/min_memory_gb), and whether it shares the project directory",
+		"check_dockerfile_parity":  "Compare the tool versions the project's development Dockerfile installs (FROM tags, *_VERSION args, obvious install commands) with the local machine and the project's version requirements",
+		"check_nix_environment":    "For projects with flake.nix, shell.nix or devenv.nix: check the server runs inside the declared dev shell and that the tools on the PATH are the versions it declares, suggesting nix develop or direnv instead of installing tools by hand",
+		"preflight_check":          "Call before attempting a build or test run: runs the quick suite, env var audit and service checks across ecosystems and answers pass/fail with the top 3 blocking issues and their fixes, within about 500ms (slow checks are answered from their previous run)",
+		"get_environment_snapshot": "Get the latest results of scheduled background checks",
+		"get_flaky_components":     "Find scheduled checks whose results flip between runs without source changes, with flake rates over time",
+		"get_environment_trends":   "Summarize recent check history: how often the build is stale, env vars that go missing repeatedly, average fix time",
+		"purge_state":              "Clear cached data, snapshots, logs and history from the sentinel state directories",
+		"register_project":         "Register a project root, git URL or directory on an ssh host under an ID so HTTP requests can be scoped to it",
+		"list_projects":            "List the projects registered with this server",
+		"unregister_project":       "Remove a registered project and its history and caches",
+		"start_job":                "Start a long-running tool (e.g. reconcile_environment, check_infrastructure_parity) as a background job and return its job ID",
+		"get_job_status":           "Get the queue position, progress or result of a background job",
+		"get_job_result":           "Get the result of a background job, optionally waiting up to wait_seconds for it to finish",
+		"get_command_log":          "Get the full stdout/stderr of the commands run by a fix (fingerprint) or a background job (job_id)",
+		"generate_dotenv":          "Generate a candidate .env from .env.example, keeping existing values (preview unless write is set)",
+		"generate_setup_script":    "Generate a reviewable setup script (format sh, powershell or devcontainer) from the project's ecosystem requirements and current issues, to commit as the team's bootstrap (preview unless write is set)",
+		"list_configs":             "List the loaded ecosystem configs with their source files and detection rules, and which are detected in project_root",
+		"fetch_config":             "List the community ecosystem configs of the signed config index (index overrides its URL), or with name download that config, verify its checksum and install it into the user config layer; it's loaded when the server restarts",
+		"explain_detection":        "Explain why an ecosystem is or isn't detected in project_root: which detection files matched and the confidence math",
+		"dump_trace":               "Get the most recent JSON-RPC messages recorded with SENTINEL_TRACE, secrets redacted, to attach to client compatibility bug reports",
+		"get_server_version":       "Get the server's build version and commit, and with check_updates whether a newer release is available",
 		"reconcile_environment":     "Automatically fix detected environment issues (Pro feature)",
+		"reconcile_issue":           "Run only the fix with a fingerprint from a prior reconcile_environment report, e.g. one the user approved or one that failed (Pro feature)",
+		"triage_test_failure":       "Match a captured test or build log (log, or log_file) against known environment failures (missing env vars, refused connections to service ports, Java class and compiler version errors, Node engine mismatches) and map them to issue types, fixes and the check that confirms them; project_root adds the project's services and configured fixes (Pro feature)",
+		"onboard_project":           "Walk a new machine through a project's setup: list every missing runtime, service, env var and build step in dependency order, and with fix set up the fixable ones step by step (Pro feature)",
 		"get_pro_license":          "Get information about purchasing a Pro license",
 		"activate_pro":             "Activate a Pro license with a license key",
 		"check_license_status":     "Check current license status and available features",
+		"export_issue":             "File a GitHub issue or Jira ticket for an environment issue: a finding of check in project_root (selected by fingerprint when there are several) or the failing checks of a scheduled job's snapshot; an open ticket with the same fingerprint is returned instead of a duplicate",
+		"list_features":            "List the effective feature flags and their source: license tier, license key claims, trial, or local overrides (features in sentinel.yaml, SENTINEL_FEATURES)",
+		"start_trial":              "Unlock a premium feature (default reconcile_environment) on this machine for days days (default 7, at most 14) to evaluate it before purchasing; once per feature",
 	}
 	return descriptions[name]
 }
 
-// formatResult formats a result for MCP response
+// FormatResult formats a tool result as human-readable text
+func FormatResult(result interface{}) string {
+	return formatResult(result)
+}
+
+// formatResult formats a result for MCP response, with its secrets masked
 func formatResult(result interface{}) string {
+	return redact.String(formatUnredacted(result))
+}
+
+// formatUnredacted formats a result as formatResult does, secrets included
+func formatUnredacted(result interface{}) string {
 	switch v := result.(type) {
 	case string:
 		return v
@@ -256,6 +670,60 @@ func formatResult(result interface{}) string {
 		return formatEnvVarReport(v)
 	case *reconciler.ReconciliationReport:
 		return formatReconciliationReport(v)
+	case *trust.TrustReport:
+		return formatTrustReport(v)
+	case *locale.LocaleReport:
+		return formatLocaleReport(v)
+	case *portability.PortabilityReport:
+		return formatPortabilityReport(v)
+	case *wrapper.WrapperReport:
+		return formatWrapperReport(v)
+	case *mirror.MirrorReport:
+		return formatMirrorReport(v)
+	case *container.RuntimeReport:
+		return formatContainerRuntimeReport(v)
+	case *dockerfile.ParityReport:
+		return formatDockerfileParityReport(v)
+	case *nix.Report:
+		return formatNixReport(v)
+	case *preflight.Report:
+		return formatPreflightReport(v)
+	case *triage.Report:
+		return formatTriageReport(v)
+	case []snapshot.Entry:
+		return formatSnapshot(v)
+	case *flaky.FlakyReport:
+		return formatFlakyReport(v)
+	case *trends.TrendReport:
+		return formatTrendReport(v)
+	case []*state.PurgeReport:
+		return formatPurgeReports(v)
+	case []registry.Project:
+		return formatProjects(v)
+	case *JobStatus:
+		return formatJobStatus(v)
+	case *DotenvResult:
+		return formatDotenvResult(v)
+	case *ConfigList:
+		return formatConfigList(v)
+	case *marketplace.Index:
+		return formatConfigIndex(v)
+	case *marketplace.Installed:
+		return formatInstalledConfig(v)
+	case *detector.Explanation:
+		return formatExplanation(v)
+	case *ServerVersion:
+		return formatServerVersion(v)
+	case *TraceDump:
+		return formatTraceDump(v)
+	case []features.Flag:
+		return formatFeatureFlags(v)
+	case *IssueExport:
+		return formatIssueExport(v)
+	case *onboard.Plan:
+		return formatOnboardingPlan(v)
+	case *SetupScriptResult:
+		return formatSetupScriptResult(v)
 	default:
 		data, _ := json.MarshalIndent(v, "", "  ")
 		return string(data)
@@ -264,34 +732,229 @@ func formatResult(result interface{}) string {
 
 // formatFreshnessReport formats a freshness report
 func formatFreshnessReport(report *verifier.FreshnessReport) string {
+	suite := ""
+	if report.Suite != "" && report.Suite != config.SuiteFull {
+		suite = fmt.Sprintf(" (%s suite)", report.Suite)
+	}
+	unverified := formatUnverified(report.Commands)
 	if report.IsHealthy {
-		return fmt.Sprintf("✅ Build freshness check passed for %s", report.EcosystemID)
+		msg := fmt.Sprintf("✅ Build freshness check passed for %s%s", report.EcosystemID, suite)
+		if unverified != "" {
+			msg += "\n\n" + unverified
+		}
+		return msg
 	}
 
-	msg := fmt.Sprintf("❌ Build freshness issues found for %s:\n\n", report.EcosystemID)
+	msg := fmt.Sprintf("❌ Build freshness issues found for %s%s:\n\n", report.EcosystemID, suite)
 	for _, issue := range report.Issues {
-		msg += fmt.Sprintf("- %s: %s\n", issue.Severity, issue.Message)
+		msg += fmt.Sprintf("- %s: %s%s\n", issue.Severity, issue.Message, affected(report.Ecosystems, issue.Ecosystems))
+		msg += formatEvidence(issue.Evidence)
 		if issue.FixAvailable {
-			msg += fmt.Sprintf("  Fix: %s\n", issue.FixCommand)
+			msg += fmt.Sprintf("  Fix: %s%s\n", issue.FixCommand, formatFixTraits(issue.Fix))
 		}
+		msg += formatDoc(issue.Doc)
+	}
+	if unverified != "" {
+		msg += "\n" + unverified
 	}
 	return msg
 }
 
+// formatFixTraits notes what to weigh before running a fix, e.g. " (destructive, ~5m)", or
+// " (heavy, typically ~3m on this machine)" once the machine has a history of the fix
+func formatFixTraits(fix *verifier.FixDescriptor) string {
+	if fix == nil || (!fix.Destructive && !fix.Heavy && fix.Estimate == "") {
+		return ""
+	}
+	var traits []string
+	if fix.Destructive {
+		traits = append(traits, "destructive")
+	}
+	if fix.Heavy {
+		traits = append(traits, "heavy")
+	}
+	if fix.Estimate != "" {
+		traits = append(traits, fix.Estimate)
+	} else if fix.EstimatedSeconds >= 60 {
+		traits = append(traits, fmt.Sprintf("~%dm", fix.EstimatedSeconds/60))
+	} else {
+		traits = append(traits, fmt.Sprintf("~%ds", fix.EstimatedSeconds))
+	}
+	return " (" + strings.Join(traits, ", ") + ")"
+}
+
+// formatDoc formats the team runbook linked to an issue type: its link, then its snippet
+// indented under the issue
+func formatDoc(doc *config.IssueDoc) string {
+	if doc == nil {
+		return ""
+	}
+	msg := "  Docs:"
+	if doc.Title != "" {
+		msg += " " + doc.Title
+	}
+	if doc.URL != "" {
+		if doc.Title != "" {
+			msg += ","
+		}
+		msg += " " + doc.URL
+	}
+	msg += "\n"
+	if doc.Snippet == "" {
+		return msg
+	}
+	for _, line := range strings.Split(strings.TrimRight(doc.Snippet, "\n"), "\n") {
+		if line == "" {
+			msg += "\n"
+			continue
+		}
+		msg += "    " + line + "\n"
+	}
+	return msg
+}
+
+// formatUnverified lists the verification commands that errored or were skipped, whose checks
+// the report can't vouch for
+func formatUnverified(results []verifier.CommandResult) string {
+	msg := ""
+	for _, r := range results {
+		name := r.Name
+		if r.Ecosystem != "" {
+			name += " (" + r.Ecosystem + ")"
+		}
+		switch r.Status {
+		case verifier.StatusError:
+			msg += fmt.Sprintf("- %s: %s\n", name, r.Error)
+		case verifier.StatusSkipped:
+			msg += fmt.Sprintf("- %s: skipped, verification type %q is not supported yet\n", name, r.Type)
+		}
+	}
+	if msg == "" {
+		return ""
+	}
+	return "⚠️ Not verified:\n" + msg
+}
+
+// formatEvidence formats the files a freshness issue was based on, one per line
+func formatEvidence(e *verifier.Evidence) string {
+	if e == nil {
+		return ""
+	}
+	msg := ""
+	if e.SourcePattern != nil {
+		msg += formatGlobEvidence("Source pattern", e.SourcePattern)
+	}
+	if e.Source != nil {
+		msg += formatFileEvidence("Source", e.Source)
+	}
+	if e.TargetPattern != nil {
+		msg += formatGlobEvidence("Target pattern", e.TargetPattern)
+	}
+	if e.Selection != nil {
+		msg += formatSelectionEvidence(e.Selection)
+	}
+	if e.Target != nil {
+		msg += formatFileEvidence("Target", e.Target)
+	}
+	if e.Orphaned != nil {
+		msg += fmt.Sprintf("  Orphaned outputs: %s\n", listMatches(e.Orphaned))
+	}
+	if e.MissingOutputs != nil {
+		msg += fmt.Sprintf("  Sources without output: %s\n", listMatches(e.MissingOutputs))
+	}
+	return msg
+}
+
+// formatSelectionEvidence formats how many targets are stale and each strategy's verdict
+func formatSelectionEvidence(sel *verifier.SelectionEvidence) string {
+	results := make([]string, 0, len(config.TargetSelections))
+	for _, strategy := range config.TargetSelections {
+		verdict := "stale"
+		if sel.Results[strategy] {
+			verdict = "fresh"
+		}
+		results = append(results, fmt.Sprintf("%s: %s", strategy, verdict))
+	}
+	msg := fmt.Sprintf("  Targets: %d of %d older than the source; %s (using %s)\n", sel.StaleCount, sel.Targets, strings.Join(results, ", "), sel.Strategy)
+	if sel.StaleCount > 0 {
+		stale := strings.Join(sel.StaleTargets, ", ")
+		if sel.StaleCount > len(sel.StaleTargets) {
+			stale += fmt.Sprintf(", and %d more", sel.StaleCount-len(sel.StaleTargets))
+		}
+		msg += fmt.Sprintf("  Stale targets: %s\n", stale)
+	}
+	return msg
+}
+
+// formatFileEvidence formats a compared file with its modification time and short hash
+func formatFileEvidence(label string, f *verifier.FileEvidence) string {
+	hash := f.SHA256
+	if len(hash) > 12 {
+		hash = hash[:12]
+	}
+	if hash == "" {
+		hash = "unreadable"
+	}
+	path := f.Path
+	if f.LinkTarget != "" {
+		path += " -> " + f.LinkTarget
+	}
+	return fmt.Sprintf("  %s: %s (modified %s, sha256 %s)\n", label, path, f.ModTime.Format(time.RFC3339Nano), hash)
+}
+
+// formatGlobEvidence formats a glob pattern and the files it matched
+func formatGlobEvidence(label string, g *verifier.GlobEvidence) string {
+	if g.MatchCount == 0 {
+		return fmt.Sprintf("  %s: %s matched no files\n", label, g.Pattern)
+	}
+	return fmt.Sprintf("  %s: %s matched %d file(s): %s\n", label, g.Pattern, g.MatchCount, listMatches(g))
+}
+
+// listMatches lists the files of glob evidence, counting those left out
+func listMatches(g *verifier.GlobEvidence) string {
+	matches := strings.Join(g.Matches, ", ")
+	if g.MatchCount > len(g.Matches) {
+		matches += fmt.Sprintf(", and %d more", g.MatchCount-len(g.Matches))
+	}
+	return matches
+}
+
 // formatInfrastructureReport formats an infrastructure report
 func formatInfrastructureReport(report *infra.InfrastructureReport) string {
 	if report.IsHealthy {
-		return "✅ All infrastructure services are healthy"
+		msg := "✅ All infrastructure services are healthy"
+		if len(report.Runtimes) > 0 || len(report.BuildCaches) > 0 {
+			msg += "\n\n"
+			for _, runtime := range report.Runtimes {
+				msg += formatRuntimeStatus(runtime, report.Ecosystems)
+			}
+			for _, cache := range report.BuildCaches {
+				msg += formatBuildCacheStatus(cache, report.Ecosystems)
+			}
+		}
+		return msg
 	}
 
 	msg := "❌ Infrastructure issues found:\n\n"
+	for _, runtime := range report.Runtimes {
+		msg += formatRuntimeStatus(runtime, report.Ecosystems)
+	}
 	for _, service := range report.Services {
 		if !service.Healthy {
-			msg += fmt.Sprintf("- %s: %s\n", service.Name, service.Message)
+			msg += fmt.Sprintf("- %s: %s%s\n", service.Name, service.Message, affected(report.Ecosystems, service.Ecosystems))
 		} else {
-			msg += fmt.Sprintf("✅ %s: %s\n", service.Name, service.Message)
+			msg += fmt.Sprintf("✅ %s: %s%s\n", service.Name, service.Message, affected(report.Ecosystems, service.Ecosystems))
 		}
 	}
+	for _, daemon := range report.Daemons {
+		msg += formatDaemonStatus(daemon, report.Ecosystems)
+	}
+	for _, limit := range report.Limits {
+		msg += formatLimitStatus(limit, report.Ecosystems)
+	}
+	for _, cache := range report.BuildCaches {
+		msg += formatBuildCacheStatus(cache, report.Ecosystems)
+	}
 	if len(report.Issues) > 0 {
 		msg += "\nIssues:\n"
 		for _, issue := range report.Issues {
@@ -301,16 +964,95 @@ func formatInfrastructureReport(report *infra.InfrastructureReport) string {
 	return msg
 }
 
+// formatRuntimeStatus formats the installations of a language runtime, marking the active one
+func formatRuntimeStatus(runtime infra.RuntimeStatus, ecosystems []string) string {
+	msg := fmt.Sprintf("✅ %s: %d installations%s\n", runtime.Language, len(runtime.Installations), affected(ecosystems, runtime.Ecosystems))
+	if runtime.Better != nil {
+		msg = fmt.Sprintf("- %s: %d installations, %s fits better (%s)%s\n", runtime.Language, len(runtime.Installations), runtime.Better.Version, runtime.Switch, affected(ecosystems, runtime.Ecosystems))
+	}
+	for _, inst := range runtime.Installations {
+		version := inst.Version
+		if version == "" {
+			version = "unknown version"
+		}
+		notes := inst.Source
+		if inst.Active {
+			notes += ", active"
+		}
+		msg += fmt.Sprintf("  %s: %s (%s)\n", version, inst.Path, notes)
+	}
+	return msg
+}
+
+// formatDaemonStatus formats the summary line of a daemon; its problems are listed with the issues
+func formatDaemonStatus(daemon infra.DaemonStatus, ecosystems []string) string {
+	pids := make([]string, len(daemon.Processes))
+	for i, p := range daemon.Processes {
+		pids[i] = strconv.Itoa(p.PID)
+	}
+	running := fmt.Sprintf("running (pid %s)", strings.Join(pids, ", "))
+	if len(pids) > 1 {
+		running = fmt.Sprintf("%d running (pids %s)", len(pids), strings.Join(pids, ", "))
+	}
+	if daemon.Healthy {
+		return fmt.Sprintf("✅ %s: %s%s\n", daemon.Name, running, affected(ecosystems, daemon.Ecosystems))
+	}
+	return fmt.Sprintf("- %s: %s, %d problem(s)%s\n", daemon.Name, running, len(daemon.Problems), affected(ecosystems, daemon.Ecosystems))
+}
+
+// formatLimitStatus formats the summary line of an OS limit; limits that are too low are
+// explained with the issues
+func formatLimitStatus(limit infra.LimitStatus, ecosystems []string) string {
+	if limit.Value == 0 {
+		return fmt.Sprintf("- %s: watchers unreliable%s\n", limit.Name, affected(ecosystems, limit.Ecosystems))
+	}
+	if limit.Healthy {
+		return fmt.Sprintf("✅ %s: %d (needs %d)\n", limit.Name, limit.Value, limit.Required)
+	}
+	return fmt.Sprintf("- %s: %d, needs %d%s\n", limit.Name, limit.Value, limit.Required, affected(ecosystems, limit.Ecosystems))
+}
+
+// formatBuildCacheStatus formats the summary line of a build cache, with its hit rate when its
+// statistics were read; its problems are listed with the issues
+func formatBuildCacheStatus(cache infra.BuildCacheStatus, ecosystems []string) string {
+	var details []string
+	if cache.HasStats {
+		if requests := cache.Hits + cache.Misses; requests > 0 {
+			details = append(details, fmt.Sprintf("%.0f%% hit rate (%d of %d)", cache.HitRate, cache.Hits, requests))
+		} else {
+			details = append(details, "no requests yet")
+		}
+	}
+	if cache.Remote != "" {
+		details = append(details, "remote "+cache.Remote)
+	}
+	if !cache.Healthy {
+		details = append(details, fmt.Sprintf("%d problem(s)", len(cache.Problems)))
+		return fmt.Sprintf("- %s: %s%s\n", cache.Name, strings.Join(details, ", "), affected(ecosystems, cache.Ecosystems))
+	}
+	if len(details) == 0 {
+		details = append(details, "enabled")
+	}
+	return fmt.Sprintf("✅ %s: %s%s\n", cache.Name, strings.Join(details, ", "), affected(ecosystems, cache.Ecosystems))
+}
+
 // formatEnvVarReport formats an environment variable report
 func formatEnvVarReport(report *auditor.EnvVarReport) string {
 	if report.IsHealthy {
-		return "✅ All required environment variables are set"
+		msg := "✅ All required environment variables are set"
+		if report.Template != nil && len(report.Template.Undeclared) > 0 {
+			msg += fmt.Sprintf("\n\nVariables in .env not declared in %s: %s\n", report.Template.Template, strings.Join(report.Template.Undeclared, ", "))
+		}
+		return msg
 	}
 
 	msg := fmt.Sprintf("❌ Environment variable issues found:\n\n")
+	if len(report.SpringProfiles) > 0 {
+		msg += fmt.Sprintf("Spring profiles: %s\n\n", strings.Join(report.SpringProfiles, ", "))
+	}
 	msg += fmt.Sprintf("Missing variables (%d):\n", len(report.Missing))
 	for _, name := range report.Missing {
-		msg += fmt.Sprintf("- %s\n", name)
+		msg += fmt.Sprintf("- %s%s\n", name, affected(report.Ecosystems, report.MissingIn[name]))
 	}
 	if len(report.Issues) > 0 {
 		msg += "\nIssues:\n"
@@ -318,9 +1060,21 @@ func formatEnvVarReport(report *auditor.EnvVarReport) string {
 			msg += fmt.Sprintf("- %s\n", issue)
 		}
 	}
+	if report.Template != nil && !report.Template.IsHealthy {
+		msg += fmt.Sprintf("\nFix: run generate_dotenv to create a candidate .env from %s\n", report.Template.Template)
+	}
 	return msg
 }
 
+// affected lists the ecosystems an entry of a combined report applies to, e.g. " (java, node)".
+// Reports of a single ecosystem list none.
+func affected(combined, ecosystems []string) string {
+	if len(combined) < 2 || len(ecosystems) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", strings.Join(ecosystems, ", "))
+}
+
 // formatReconciliationReport formats a reconciliation report
 func formatReconciliationReport(report *reconciler.ReconciliationReport) string {
 	msg := fmt.Sprintf("Reconciliation Results:\n\n")
@@ -340,9 +1094,291 @@ func formatReconciliationReport(report *reconciler.ReconciliationReport) string
 			if fix.Error != "" {
 				msg += fmt.Sprintf("  Error: %s\n", fix.Error)
 			}
+			if fix.Fingerprint != "" {
+				msg += fmt.Sprintf("  Full output: get_command_log with fingerprint %s\n", fix.Fingerprint)
+				msg += fmt.Sprintf("  Retry only this fix: reconcile_issue with fingerprint %s\n", fix.Fingerprint)
+			}
+			msg += formatDoc(fix.Doc)
+		}
+	}
+
+	if len(report.Planned) > 0 {
+		if report.Plan {
+			msg += fmt.Sprintf("📝 Planned, not executed (%d):\n", len(report.Planned))
+		} else {
+			msg += fmt.Sprintf("📝 Read-only mode, not executed (%d):\n", len(report.Planned))
+		}
+		for _, fix := range report.Planned {
+			msg += fmt.Sprintf("- %s: %s%s\n", fix.IssueType, fix.Message, formatEstimate(fix.Estimate))
+			if fix.Fingerprint != "" {
+				msg += fmt.Sprintf("  Run only this fix: reconcile_issue with fingerprint %s\n", fix.Fingerprint)
+			}
+		}
+	}
+
+	if len(report.Pending) > 0 {
+		msg += fmt.Sprintf("⏸️ Needs confirmation (%d):\n", len(report.Pending))
+		for _, fix := range report.Pending {
+			msg += fmt.Sprintf("- %s (%s): %s%s\n", fix.IssueType, fix.Severity, fix.Command, formatEstimate(fix.Estimate))
+		}
+		msg += "To run them, call reconcile_environment again with confirm set to their fingerprints, or reconcile_issue for one of them:\n"
+		for _, fix := range report.Pending {
+			msg += fmt.Sprintf("  %s\n", fix.Fingerprint)
+		}
+	}
+
+	if len(report.Manual) > 0 {
+		msg += fmt.Sprintf("📋 Needs manual action (%d):\n", len(report.Manual))
+		for _, fix := range report.Manual {
+			msg += fmt.Sprintf("- %s: %s\n", fix.IssueType, fix.Message)
+			msg += formatDoc(fix.Doc)
 		}
 	}
 	
 	return msg
 }
 
+// formatEstimate formats the learned duration of a fix, e.g. " (typically ~3m on this machine)"
+func formatEstimate(estimate string) string {
+	if estimate == "" {
+		return ""
+	}
+	return " (" + estimate + ")"
+}
+
+
+// formatTrustReport formats a certificate trust report
+func formatTrustReport(report *trust.TrustReport) string {
+	if report.CustomCA == nil || !report.CustomCA.Required {
+		msg := "✅ No custom CA required (no CA bundle configured)"
+		for _, hint := range report.Hints {
+			msg += fmt.Sprintf("\n💡 %s", hint)
+		}
+		return msg
+	}
+	if report.IsHealthy {
+		return fmt.Sprintf("✅ Custom CA (%s) is trusted by all ecosystem tooling", report.CustomCA.Reason)
+	}
+
+	msg := fmt.Sprintf("❌ Certificate trust issues found (%s):\n\n", report.CustomCA.Reason)
+	if report.CustomCA.Subject != "" {
+		msg += fmt.Sprintf("CA: %s (%s)\n\n", report.CustomCA.Subject, report.CustomCA.File)
+	}
+	for _, store := range report.Stores {
+		if store.Configured {
+			msg += fmt.Sprintf("✅ %s/%s: %s\n", store.EcosystemID, store.Name, store.Message)
+			continue
+		}
+		msg += fmt.Sprintf("- %s/%s: %s\n", store.EcosystemID, store.Name, store.Message)
+		if store.FixCommand != "" {
+			msg += fmt.Sprintf("  Fix: %s\n", store.FixCommand)
+		}
+	}
+	if len(report.Stores) == 0 {
+		for _, issue := range report.Issues {
+			msg += fmt.Sprintf("- %s\n", issue)
+		}
+	}
+	return msg
+}
+
+// formatLocaleReport formats a time-zone/locale/clock report
+func formatLocaleReport(report *locale.LocaleReport) string {
+	summary := fmt.Sprintf("Timezone: %s\nLocale: %s\n", report.Timezone, report.Locale)
+	if report.ClockChecked {
+		summary += fmt.Sprintf("Clock offset: %s\n", report.ClockOffset.Round(time.Millisecond))
+	}
+
+	if report.IsHealthy {
+		return fmt.Sprintf("✅ Locale checks passed for %s\n\n%s", report.EcosystemID, summary)
+	}
+
+	msg := fmt.Sprintf("❌ Locale issues found for %s:\n\n%s\nIssues:\n", report.EcosystemID, summary)
+	for _, issue := range report.Issues {
+		msg += fmt.Sprintf("- %s\n", issue)
+	}
+	return msg
+}
+
+// formatPortabilityReport formats a line-ending and file-mode report
+func formatPortabilityReport(report *portability.PortabilityReport) string {
+	if report.IsHealthy {
+		return fmt.Sprintf("✅ No line-ending or file-mode issues in %d shell script(s)", len(report.Scripts))
+	}
+
+	msg := "❌ Cross-platform issues found:\n\n"
+	for _, issue := range report.Issues {
+		msg += fmt.Sprintf("- %s\n", issue.Message)
+		if issue.FixCommand != "" {
+			msg += fmt.Sprintf("  Fix: %s\n", issue.FixCommand)
+		}
+	}
+	return msg
+}
+
+// formatWrapperReport formats a build wrapper integrity report
+func formatWrapperReport(report *wrapper.WrapperReport) string {
+	if len(report.Wrappers) == 0 {
+		return "No Maven or Gradle wrapper found"
+	}
+	if report.IsHealthy {
+		var tools []string
+		for _, w := range report.Wrappers {
+			if w.Version != "" {
+				tools = append(tools, fmt.Sprintf("%s %s", w.Tool, w.Version))
+			} else {
+				tools = append(tools, w.Tool)
+			}
+		}
+		return fmt.Sprintf("✅ Build wrappers are intact (%s)", strings.Join(tools, ", "))
+	}
+
+	msg := "❌ Build wrapper issues found:\n\n"
+	for _, issue := range report.Issues {
+		msg += fmt.Sprintf("- %s\n", issue.Message)
+		if issue.FixCommand != "" {
+			msg += fmt.Sprintf("  Fix: %s\n", issue.FixCommand)
+		}
+	}
+	return msg
+}
+
+// formatMirrorReport formats an artifact repository mirror report
+func formatMirrorReport(report *mirror.MirrorReport) string {
+	if len(report.Mirrors) == 0 {
+		return "No Maven, Gradle or npm mirrors declared"
+	}
+
+	msg := "✅ All mirrors are reachable:\n\n"
+	if !report.IsHealthy {
+		msg = "❌ Mirror issues found:\n\n"
+		for _, issue := range report.Issues {
+			msg += fmt.Sprintf("- %s\n", issue.Message)
+			if issue.FixCommand != "" {
+				msg += fmt.Sprintf("  Fix: %s\n", issue.FixCommand)
+			}
+		}
+		msg += "\nMirrors:\n"
+	}
+	for _, m := range report.Mirrors {
+		status := m.Latency.Round(time.Millisecond).String()
+		if !m.Reachable {
+			status = "unreachable"
+		}
+		msg += fmt.Sprintf("- %s %s (%s)\n", m.Ecosystem, m.URL, status)
+	}
+	return msg
+}
+
+// formatContainerRuntimeReport formats a container runtime report
+func formatContainerRuntimeReport(report *container.RuntimeReport) string {
+	if len(report.Runtimes) == 0 && report.IsHealthy {
+		return "No container runtime installed (not needed by this project)"
+	}
+
+	msg := "✅ Container runtime is healthy:\n\n"
+	if !report.IsHealthy {
+		msg = "❌ Container runtime issues found:\n\n"
+		for _, issue := range report.Issues {
+			msg += fmt.Sprintf("- %s\n", issue.Message)
+			if issue.FixCommand != "" {
+				msg += fmt.Sprintf("  Fix: %s\n", issue.FixCommand)
+			}
+		}
+		if len(report.Runtimes) == 0 {
+			return msg
+		}
+		msg += "\nRuntimes:\n"
+	}
+	for _, rt := range report.Runtimes {
+		line := fmt.Sprintf("- %s %s", rt.Name, rt.ClientVersion)
+		if !rt.Reachable {
+			msg += line + ": daemon unreachable\n"
+			continue
+		}
+		mode := "rootful"
+		if rt.Rootless {
+			mode = "rootless"
+		}
+		line += fmt.Sprintf(", daemon %s (%s)", rt.ServerVersion, mode)
+		if rt.Context != "" {
+			line += ", context " + rt.Context
+		}
+		msg += line + "\n"
+		if rt.VM != nil {
+			msg += fmt.Sprintf("  %s VM: %d CPUs, %s memory\n", rt.Backend, rt.VM.CPUs, container.FormatBytes(rt.VM.MemoryBytes))
+		}
+		for _, usage := range rt.DiskUsage {
+			msg += fmt.Sprintf("  %s: %d, %s (%s reclaimable)\n", usage.Type, usage.Count, container.FormatBytes(usage.Size), container.FormatBytes(usage.Reclaimable))
+		}
+	}
+	return msg
+}
+
+// formatDockerfileParityReport formats a Dockerfile parity report
+func formatDockerfileParityReport(report *dockerfile.ParityReport) string {
+	if len(report.Dockerfiles) == 0 {
+		return "No Dockerfile found"
+	}
+	if len(report.Tools) == 0 {
+		return fmt.Sprintf("No tool versions found in %s", strings.Join(report.Dockerfiles, ", "))
+	}
+
+	msg := fmt.Sprintf("✅ %s match the local environment:\n\n", strings.Join(report.Dockerfiles, ", "))
+	if !report.IsHealthy {
+		msg = "❌ Dockerfile and local environment diverge:\n\n"
+		for _, issue := range report.Issues {
+			msg += fmt.Sprintf("- %s\n", issue.Message)
+		}
+		msg += "\nTools:\n"
+	}
+	for _, tool := range report.Tools {
+		pinned, local := tool.Version, tool.Local
+		if pinned == "" {
+			pinned = "unpinned"
+		}
+		if local == "" {
+			local = "not installed"
+		}
+		msg += fmt.Sprintf("- %s: container %s, local %s (%s:%d)\n", tool.Tool, pinned, local, tool.File, tool.Line)
+	}
+	return msg
+}
+
+// formatNixReport formats a Nix environment report
+func formatNixReport(report *nix.Report) string {
+	if report.Kind == "" {
+		return "No Nix environment declared (flake.nix, shell.nix or devenv.nix)"
+	}
+
+	shell := "outside the dev shell"
+	if report.Active {
+		shell = "inside the dev shell (" + report.ActiveVia + ")"
+	}
+	msg := fmt.Sprintf("✅ %s: running %s, tools match\n", strings.Join(report.Files, ", "), shell)
+	if !report.IsHealthy {
+		msg = fmt.Sprintf("❌ %s: running %s\n\n", strings.Join(report.Files, ", "), shell)
+		suggested := make(map[string]bool)
+		for _, issue := range report.Issues {
+			msg += fmt.Sprintf("- %s\n", issue.Message)
+			if issue.Suggestion != "" && !suggested[issue.Suggestion] {
+				suggested[issue.Suggestion] = true
+				msg += fmt.Sprintf("  Suggestion: %s\n", issue.Suggestion)
+			}
+		}
+	}
+	if len(report.Tools) > 0 {
+		msg += "\nTools:\n"
+	}
+	for _, tool := range report.Tools {
+		declared, active := tool.Version, tool.Active
+		if declared == "" {
+			declared = "any version"
+		}
+		if active == "" {
+			active = "not on the PATH"
+		}
+		msg += fmt.Sprintf("- %s: declared %s (%s), active %s\n", tool.Tool, declared, tool.Attr, active)
+	}
+	return msg
+}
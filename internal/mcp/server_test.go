@@ -6,11 +6,21 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"dev-env-sentinel/internal/auditor"
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/i18n"
 	"dev-env-sentinel/internal/infra"
+	"dev-env-sentinel/internal/license"
+	"dev-env-sentinel/internal/locale"
 	"dev-env-sentinel/internal/reconciler"
+	"dev-env-sentinel/internal/runner"
+	"dev-env-sentinel/internal/templates"
+	"dev-env-sentinel/internal/trust"
 	"dev-env-sentinel/internal/verifier"
+	"dev-env-sentinel/internal/version"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -105,6 +115,42 @@ func TestFormatFreshnessReport(t *testing.T) {
 	assert.Contains(t, formatted, "mvn clean")
 }
 
+func TestFormatFreshnessReport_Evidence(t *testing.T) {
+	modTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	report := &verifier.FreshnessReport{
+		EcosystemID: "java-maven",
+		Issues: []verifier.Issue{{
+			Type:     "stale_build",
+			Severity: "error",
+			Message:  "pom.xml is newer than build output (target/app.jar)",
+			Evidence: &verifier.Evidence{
+				Source: &verifier.FileEvidence{Path: "pom.xml", ModTime: modTime.Add(time.Minute), SHA256: "0123456789abcdef"},
+				Target: &verifier.FileEvidence{Path: "target/app.jar", ModTime: modTime},
+				TargetPattern: &verifier.GlobEvidence{
+					Pattern:    "target/*.jar",
+					Matches:    []string{"target/app.jar"},
+					MatchCount: 3,
+				},
+			},
+		}},
+	}
+
+	formatted := formatFreshnessReport(report)
+	assert.Contains(t, formatted, "  Source: pom.xml (modified 2026-03-01T12:01:00Z, sha256 0123456789ab)\n")
+	assert.Contains(t, formatted, "  Target pattern: target/*.jar matched 3 file(s): target/app.jar, and 2 more\n")
+	assert.Contains(t, formatted, "  Target: target/app.jar (modified 2026-03-01T12:00:00Z, sha256 unreadable)\n")
+
+	report.Issues[0].Evidence.Selection = &verifier.SelectionEvidence{
+		Strategy:     "all",
+		Targets:      3,
+		StaleCount:   1,
+		StaleTargets: []string{"target/app.jar"},
+		Results:      map[string]bool{"newest": true, "any": true},
+	}
+	formatted = formatFreshnessReport(report)
+	assert.Contains(t, formatted, "  Targets: 1 of 3 older than the source; newest: fresh, oldest: stale, any: fresh, all: stale (using all)\n  Stale targets: target/app.jar\n")
+}
+
 func TestFormatFreshnessReport_Healthy(t *testing.T) {
 	report := &verifier.FreshnessReport{
 		EcosystemID: "java-maven",
@@ -117,6 +163,87 @@ func TestFormatFreshnessReport_Healthy(t *testing.T) {
 	assert.Contains(t, formatted, "java-maven")
 }
 
+func TestFormatFreshnessReport_Unverified(t *testing.T) {
+	report := &verifier.FreshnessReport{
+		EcosystemID: "java-maven, npm",
+		IsHealthy:   true,
+		Commands: []verifier.CommandResult{
+			{Name: "pom_vs_jar", Ecosystem: "java-maven", Status: verifier.StatusPass},
+			{Name: "check_dependency_cache", Ecosystem: "npm", Status: verifier.StatusError, Error: "failed to read package-lock.json"},
+			{Name: "lint", Type: "command", Ecosystem: "npm", Status: verifier.StatusSkipped},
+		},
+	}
+
+	formatted := formatFreshnessReport(report)
+	assert.Contains(t, formatted, "✅ Build freshness check passed for java-maven, npm\n\n⚠️ Not verified:\n")
+	assert.Contains(t, formatted, "- check_dependency_cache (npm): failed to read package-lock.json\n")
+	assert.Contains(t, formatted, "- lint (npm): skipped, verification type \"command\" is not supported yet\n")
+	assert.NotContains(t, formatted, "pom_vs_jar")
+}
+
+func TestFormatFreshnessReport_Docs(t *testing.T) {
+	report := &verifier.FreshnessReport{
+		EcosystemID: "java-maven",
+		Issues: []verifier.Issue{{
+			Type:         "missing_target",
+			Severity:     "error",
+			Message:      "Target file not found: target/app.jar",
+			FixAvailable: true,
+			FixCommand:   "mvn package",
+			Doc:          &config.IssueDoc{IssueType: "missing_target", Title: "First build", URL: "https://wiki.example.com/first-build", Snippet: "Ask #platform for\nrepository access first."},
+		}},
+	}
+
+	formatted := formatFreshnessReport(report)
+	assert.Contains(t, formatted, "  Fix: mvn package\n  Docs: First build, https://wiki.example.com/first-build\n    Ask #platform for\n    repository access first.\n")
+}
+
+func TestFormatFreshnessReport_FixTraits(t *testing.T) {
+	report := &verifier.FreshnessReport{
+		EcosystemID: "node-npm",
+		Issues: []verifier.Issue{{
+			Type:         "stale_build",
+			Severity:     "error",
+			Message:      "Build is stale",
+			FixAvailable: true,
+			FixCommand:   "rm -rf dist && npm run build",
+			Fix:          &verifier.FixDescriptor{Command: "rm -rf dist && npm run build", Destructive: true, Heavy: true, EstimatedSeconds: 300},
+		}},
+	}
+
+	formatted := formatFreshnessReport(report)
+	assert.Contains(t, formatted, "  Fix: rm -rf dist && npm run build (destructive, heavy, ~5m)\n")
+}
+
+func TestFormatFreshnessReport_LearnedEstimate(t *testing.T) {
+	report := &verifier.FreshnessReport{
+		EcosystemID: "node-npm",
+		Issues: []verifier.Issue{{
+			Type:         "stale_build",
+			Severity:     "error",
+			Message:      "Build is stale",
+			FixAvailable: true,
+			FixCommand:   "npm run build",
+			Fix:          &verifier.FixDescriptor{Command: "npm run build", EstimatedSeconds: 180, Estimate: "typically ~3m on this machine"},
+		}},
+	}
+
+	formatted := formatFreshnessReport(report)
+	assert.Contains(t, formatted, "  Fix: npm run build (typically ~3m on this machine)\n")
+}
+
+func TestFormatReconciliationReport_Docs(t *testing.T) {
+	report := reconciler.NewReport()
+	report.Manual = append(report.Manual, reconciler.FixResult{
+		IssueType: reconciler.IssueMissingEnvVar,
+		Message:   "API_KEY looks like a secret and is never generated",
+		Doc:       &config.IssueDoc{IssueType: reconciler.IssueMissingEnvVar, URL: "https://wiki.example.com/secrets"},
+	})
+
+	formatted := formatReconciliationReport(report)
+	assert.Contains(t, formatted, "- missing_env_var: API_KEY looks like a secret and is never generated\n  Docs: https://wiki.example.com/secrets\n")
+}
+
 func TestFormatInfrastructureReport(t *testing.T) {
 	report := &infra.InfrastructureReport{
 		IsHealthy: false,
@@ -141,6 +268,80 @@ func TestFormatInfrastructureReport(t *testing.T) {
 	assert.Contains(t, formatted, "Service issue")
 }
 
+func TestFormatInfrastructureReport_Daemons(t *testing.T) {
+	report := &infra.InfrastructureReport{IsHealthy: true}
+	report.AddDaemons([]infra.DaemonStatus{
+		{Name: "nx daemon", Healthy: true, Processes: []infra.Process{{PID: 12}}},
+		{
+			Name:           "gradle daemon",
+			Processes:      []infra.Process{{PID: 20}, {PID: 21}},
+			Problems:       []string{"process (pid 21) runs version 7.6, but gradle/wrapper/gradle-wrapper.properties declares 8.5"},
+			RestartCommand: "gradle --stop",
+		},
+	})
+
+	formatted := formatInfrastructureReport(report)
+	assert.Contains(t, formatted, "✅ nx daemon: running (pid 12)\n")
+	assert.Contains(t, formatted, "- gradle daemon: 2 running (pids 20, 21), 1 problem(s)\n")
+	assert.Contains(t, formatted, "- gradle daemon: process (pid 21) runs version 7.6")
+	assert.Contains(t, formatted, "-   Restart: gradle --stop")
+}
+
+func TestFormatInfrastructureReport_Limits(t *testing.T) {
+	report := &infra.InfrastructureReport{IsHealthy: true}
+	report.AddLimits([]infra.LimitStatus{
+		{Name: "open files", Value: 65536, Required: 10240, Healthy: true},
+		{Name: "fs.inotify.max_user_watches", Value: 8192, Required: 524288, Message: "fs.inotify.max_user_watches is 8192", Fix: "sudo sysctl fs.inotify.max_user_watches=524288", Ecosystems: []string{"webpack"}},
+		{Name: "file system", Message: "The project is on a nfs file system", Ecosystems: []string{"webpack"}},
+	})
+
+	formatted := formatInfrastructureReport(report)
+	assert.Contains(t, formatted, "✅ open files: 65536 (needs 10240)\n")
+	assert.Contains(t, formatted, "- fs.inotify.max_user_watches: 8192, needs 524288\n")
+	assert.Contains(t, formatted, "- file system: watchers unreliable\n")
+	assert.Contains(t, formatted, "-   Fix: sudo sysctl fs.inotify.max_user_watches=524288")
+}
+
+func TestFormatInfrastructureReport_BuildCaches(t *testing.T) {
+	report := &infra.InfrastructureReport{IsHealthy: true}
+	report.AddBuildCaches([]infra.BuildCacheStatus{
+		{Name: "sccache", Healthy: true, HasStats: true, Hits: 170, Misses: 30, HitRate: 85, Remote: "redis://cache:6379"},
+	})
+
+	// Hit rates are shown even when everything is healthy
+	formatted := formatInfrastructureReport(report)
+	assert.Contains(t, formatted, "✅ All infrastructure services are healthy")
+	assert.Contains(t, formatted, "✅ sccache: 85% hit rate (170 of 200), remote redis://cache:6379\n")
+
+	report.AddBuildCaches([]infra.BuildCacheStatus{
+		{Name: "gradle build cache", Problems: []string{"org.gradle.caching isn't true"}, Fixes: []string{"echo 'org.gradle.caching=true' >> gradle.properties"}},
+	})
+	formatted = formatInfrastructureReport(report)
+	assert.Contains(t, formatted, "- gradle build cache: 1 problem(s)\n")
+	assert.Contains(t, formatted, "- gradle build cache: org.gradle.caching isn't true\n")
+	assert.Contains(t, formatted, "-   Fix: echo 'org.gradle.caching=true' >> gradle.properties")
+}
+
+func TestFormatInfrastructureReport_Runtimes(t *testing.T) {
+	better := version.Installation{Path: "/home/dev/.sdkman/candidates/java/21.0.2-tem/bin/java", Source: "sdkman", Name: "21.0.2-tem", Version: "21.0.2"}
+	report := &infra.InfrastructureReport{IsHealthy: true, Runtimes: []infra.RuntimeStatus{{
+		Language: "java",
+		Installations: []version.Installation{
+			{Path: "/usr/bin/java", Source: "system", Version: "17.0.9", Active: true},
+			better,
+			{Path: "/opt/jdk/bin/java", Source: version.SourcePath, Error: "version pattern not found in output"},
+		},
+		Better: &better,
+		Switch: "sdk use java 21.0.2-tem",
+	}}}
+
+	formatted := formatInfrastructureReport(report)
+	assert.Contains(t, formatted, "- java: 3 installations, 21.0.2 fits better (sdk use java 21.0.2-tem)\n")
+	assert.Contains(t, formatted, "  17.0.9: /usr/bin/java (system, active)\n")
+	assert.Contains(t, formatted, "  21.0.2: /home/dev/.sdkman/candidates/java/21.0.2-tem/bin/java (sdkman)\n")
+	assert.Contains(t, formatted, "  unknown version: /opt/jdk/bin/java (PATH)\n")
+}
+
 func TestFormatEnvVarReport(t *testing.T) {
 	report := &auditor.EnvVarReport{
 		IsHealthy: false,
@@ -154,6 +355,88 @@ func TestFormatEnvVarReport(t *testing.T) {
 	assert.Contains(t, formatted, "Missing API_KEY")
 }
 
+func TestFormatEnvVarReport_Combined(t *testing.T) {
+	report := auditor.CombineEnvVarReports([]string{"java", "javascript"}, []*auditor.EnvVarReport{
+		{Missing: []string{"API_KEY"}},
+		{Missing: []string{"API_KEY"}},
+	})
+
+	formatted := formatEnvVarReport(report)
+	assert.Contains(t, formatted, "Missing variables (1):")
+	assert.Contains(t, formatted, "- API_KEY (java, javascript)")
+}
+
+func TestToolCallResponse_Lang(t *testing.T) {
+	t.Setenv("SENTINEL_LANG", "C")
+	server := NewServer()
+	server.RegisterTool("status", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return &infra.InfrastructureReport{IsHealthy: true}, nil
+	})
+
+	text := func(args map[string]interface{}) string {
+		resp := server.handleToolCallResponse(context.Background(), map[string]interface{}{
+			"id":     1,
+			"params": map[string]interface{}{"name": "status", "arguments": args},
+		})
+		result := resp["result"].(map[string]interface{})
+		return result["content"].([]map[string]interface{})[0]["text"].(string)
+	}
+
+	// SENTINEL_LANG sets the default; a call's lang argument overrides it
+	assert.Equal(t, "[OK] All infrastructure services are healthy", text(nil))
+	assert.Equal(t, "✅ Alle Infrastrukturdienste sind in Ordnung", text(map[string]interface{}{"lang": "de"}))
+}
+
+func TestLocaleFor_Symbols(t *testing.T) {
+	t.Setenv("SENTINEL_LANG", "")
+	server := NewServer()
+	server.SetSymbols(i18n.SymbolsNone)
+
+	assert.Equal(t, "healthy", server.localeFor(nil).Localize("✅ healthy"))
+	assert.Equal(t, "healthy", server.localeFor(map[string]interface{}{"lang": "de"}).Localize("✅ healthy"), "lang keeps the configured symbols")
+	assert.Equal(t, "[OK] healthy", server.localeFor(map[string]interface{}{"symbols": "ascii"}).Localize("✅ healthy"))
+}
+
+func TestRenderResult_Templates(t *testing.T) {
+	t.Setenv("SENTINEL_LANG", "")
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "acme.tmpl"), []byte("ACME | {{.Text}}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "acme.verify_build_freshness.tmpl"), []byte("{{.Tool}}: {{len .Result.Issues}} issue(s)"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.tmpl"), []byte("{{.Result.Missing}}"), 0644))
+	set, err := templates.Load(dir)
+	require.NoError(t, err)
+
+	server := NewServer()
+	report := &verifier.FreshnessReport{EcosystemID: "java-maven", IsHealthy: true, Issues: []verifier.Issue{}}
+	builtIn := formatFreshnessReport(report)
+	assert.Equal(t, builtIn, server.renderResult("verify_build_freshness", nil, report), "no templates")
+
+	server.SetTemplates(set, "acme")
+	assert.Equal(t, "verify_build_freshness: 0 issue(s)", server.renderResult("verify_build_freshness", nil, report))
+	assert.Equal(t, "ACME | done", server.renderResult("purge_state", nil, "done"))
+	assert.Equal(t, "done", server.renderResult("purge_state", map[string]interface{}{"template": "default"}, "done"), "a call can select the built-in rendering")
+	assert.Contains(t, server.renderResult("purge_state", map[string]interface{}{"template": "broken"}, "done"), "done\n\n⚠️ Template broken failed:")
+
+	server.RegisterTool("echo", func(ctx context.Context, args map[string]interface{}) (interface{}, error) { return "echo", nil })
+	_, err = server.CallTool(context.Background(), "echo", map[string]interface{}{"template": "compact"})
+	assert.EqualError(t, err, "unknown template: compact (available: default, acme, broken)")
+	_, err = server.CallTool(context.Background(), "echo", map[string]interface{}{"template": "acme"})
+	assert.NoError(t, err)
+}
+
+func TestClientSymbols(t *testing.T) {
+	symbols, ok := clientSymbols(map[string]interface{}{
+		"params": map[string]interface{}{
+			"capabilities": map[string]interface{}{"experimental": map[string]interface{}{"symbols": "ascii"}},
+		},
+	})
+	assert.True(t, ok)
+	assert.Equal(t, i18n.SymbolsASCII, symbols)
+
+	_, ok = clientSymbols(map[string]interface{}{"params": map[string]interface{}{}})
+	assert.False(t, ok)
+}
+
 func TestFormatReconciliationReport(t *testing.T) {
 	report := &reconciler.ReconciliationReport{
 		IsSuccess: true,
@@ -181,6 +464,64 @@ func TestFormatReconciliationReport(t *testing.T) {
 	assert.Contains(t, formatted, "Command error")
 }
 
+func TestFormatReconciliationReport_Planned(t *testing.T) {
+	report := &reconciler.ReconciliationReport{
+		IsSuccess: true,
+		Planned: []reconciler.FixResult{
+			{IssueType: "stale_build", Planned: true, Message: "Would have run: mvn compile"},
+		},
+	}
+
+	formatted := formatReconciliationReport(report)
+	assert.Contains(t, formatted, "Read-only mode, not executed (1)")
+	assert.Contains(t, formatted, "Would have run: mvn compile")
+}
+
+func TestFormatTrustReport(t *testing.T) {
+	report := &trust.TrustReport{
+		CustomCA: &trust.CustomCA{
+			Required: true,
+			Reason:   "custom CA bundle declared via SENTINEL_CA_CERT",
+			File:     "/etc/corp-ca.pem",
+			Subject:  "Corp Proxy CA",
+		},
+		IsHealthy: false,
+		Stores: []trust.StoreStatus{
+			{EcosystemID: "javascript", Name: "node_extra_ca_certs", Message: "NODE_EXTRA_CA_CERTS is not set", FixCommand: "export NODE_EXTRA_CA_CERTS=/etc/corp-ca.pem"},
+			{EcosystemID: "java", Name: "java_cacerts", Configured: true, Message: "custom CA is installed"},
+		},
+	}
+
+	formatted := formatTrustReport(report)
+	assert.Contains(t, formatted, "Corp Proxy CA")
+	assert.Contains(t, formatted, "NODE_EXTRA_CA_CERTS is not set")
+	assert.Contains(t, formatted, "export NODE_EXTRA_CA_CERTS=/etc/corp-ca.pem")
+	assert.Contains(t, formatted, "java_cacerts")
+
+	notRequired := formatTrustReport(&trust.TrustReport{CustomCA: &trust.CustomCA{}, IsHealthy: true})
+	assert.Contains(t, notRequired, "No custom CA required")
+
+	proxy := formatTrustReport(&trust.TrustReport{CustomCA: &trust.CustomCA{Proxy: "HTTPS_PROXY"}, IsHealthy: true, Hints: []string{"Proxy configured via HTTPS_PROXY"}})
+	assert.Contains(t, proxy, "💡 Proxy configured via HTTPS_PROXY")
+}
+
+func TestFormatLocaleReport(t *testing.T) {
+	report := &locale.LocaleReport{
+		EcosystemID:  "java",
+		Timezone:     "UTC",
+		Locale:       "C",
+		ClockChecked: true,
+		ClockOffset:  12 * time.Second,
+		IsHealthy:    false,
+		Issues:       []string{"Locale C is not UTF-8"},
+	}
+
+	formatted := formatLocaleReport(report)
+	assert.Contains(t, formatted, "java")
+	assert.Contains(t, formatted, "Clock offset: 12s")
+	assert.Contains(t, formatted, "Locale C is not UTF-8")
+}
+
 func TestHandleToolsList(t *testing.T) {
 	server := NewServer()
 	server.RegisterTool("test_tool", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
@@ -198,6 +539,23 @@ func TestHandleToolsList(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestHandleToolsListResponse_Sorted(t *testing.T) {
+	server := NewServer()
+	for _, name := range []string{"verify_build_freshness", "check_locale", "env_var_audit"} {
+		server.RegisterTool(name, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return nil, nil
+		})
+	}
+
+	resp := server.handleToolsListResponse(map[string]interface{}{"id": 1})
+	tools := resp["result"].(map[string]interface{})["tools"].([]map[string]interface{})
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool["name"].(string))
+	}
+	assert.Equal(t, []string{"check_locale", "env_var_audit", "verify_build_freshness"}, names)
+}
+
 func TestHandleToolCall(t *testing.T) {
 	server := NewServer()
 	
@@ -215,7 +573,7 @@ func TestHandleToolCall(t *testing.T) {
 	}
 
 	// We can't easily test without mocking stdout, but we can verify it doesn't panic
-	err := server.handleToolCall(msg)
+	err := server.handleToolCall(context.Background(), msg)
 	assert.NoError(t, err)
 }
 
@@ -228,7 +586,7 @@ func TestHandleToolCall_InvalidParams(t *testing.T) {
 		"params": "invalid", // Not a map
 	}
 
-	err := server.handleToolCall(msg)
+	err := server.handleToolCall(context.Background(), msg)
 	assert.Error(t, err)
 }
 
@@ -244,7 +602,7 @@ func TestHandleToolCall_UnknownTool(t *testing.T) {
 		},
 	}
 
-	err := server.handleToolCall(msg)
+	err := server.handleToolCall(context.Background(), msg)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "unknown tool")
 }
@@ -295,7 +653,7 @@ func TestHandleMethod(t *testing.T) {
 			
 			// We can't fully test without proper message structure,
 			// but we can verify it doesn't panic
-			err := server.handleMethod(tt.method, msg)
+			err := server.handleMethod(context.Background(), tt.method, msg)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -313,12 +671,73 @@ func TestUpdateLicense(t *testing.T) {
 	err := server.UpdateLicense("invalid-key")
 	assert.Error(t, err)
 
-	// Test with Apify token (should succeed)
-	err = server.UpdateLicense("apify_1234567890abcdef")
+	// Test with a signed license key (should succeed)
+	err = server.UpdateLicense(proLicenseKey(t))
 	assert.NoError(t, err)
 	assert.NotNil(t, server.license)
 }
 
+func TestRegisterTool_Executor(t *testing.T) {
+	server := NewServer()
+	var got []runner.Executor
+	server.RegisterTool("probe", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		got = append(got, runner.ExecutorFrom(ctx))
+		return "ok", nil
+	})
+
+	_, err := server.CallTool(context.Background(), "probe", map[string]interface{}{})
+	require.NoError(t, err)
+	server.SetExecutor(runner.DockerExec{Container: "app"})
+	_, err = server.CallTool(context.Background(), "probe", map[string]interface{}{})
+	require.NoError(t, err)
+	_, err = server.CallTool(context.Background(), "probe", map[string]interface{}{"executor": "ssh:devbox"})
+	require.NoError(t, err)
+	assert.Equal(t, []runner.Executor{runner.Local{}, runner.DockerExec{Container: "app"}, runner.SSH{Host: "devbox"}}, got)
+
+	_, err = server.CallTool(context.Background(), "probe", map[string]interface{}{"executor": "kubectl:pod"})
+	assert.ErrorContains(t, err, "unknown executor")
+	assert.Len(t, got, 3, "the tool doesn't run with an invalid executor")
+}
+
+func TestRegisterTool_Timeout(t *testing.T) {
+	server := NewServer()
+	server.RegisterTool("scan", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return detector.DetectEcosystems(ctx, t.TempDir(), []*config.EcosystemConfig{{}})
+	})
+	server.RegisterTool("wait", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	// A call cancelled by its client
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := server.CallTool(ctx, "scan", map[string]interface{}{})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	server.SetToolTimeout(20 * time.Millisecond)
+	_, err = server.CallTool(context.Background(), "wait", map[string]interface{}{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "wait did not finish within the tool timeout of 20ms")
+}
+
+// proLicenseKey signs a Pro license key the server accepts, keeping the stored license out
+// of the real home directory
+func proLicenseKey(t *testing.T) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	public, private, err := license.GenerateKeyPair()
+	require.NoError(t, err)
+	publicKey, err := license.ParsePublicKey(public)
+	require.NoError(t, err)
+	t.Cleanup(license.TrustKeyForTesting(publicKey))
+	privateKey, err := license.ParsePrivateKey(private)
+	require.NoError(t, err)
+	key, err := license.SignLicense(privateKey, "pro", nil)
+	require.NoError(t, err)
+	return key
+}
+
 func TestFormatResult_JSON(t *testing.T) {
 	// Test that complex objects are JSON marshaled
 	complexObj := map[string]interface{}{
@@ -336,3 +755,34 @@ func TestFormatResult_JSON(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+
+func TestRenderResult_RenewalNotice(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := NewServer()
+	assert.Equal(t, "ok", server.RenderResult("check_locale", nil, "ok"))
+
+	expires := time.Now().Add(3 * 24 * time.Hour)
+	server.license = &license.License{IsValid: true, Tier: "pro", ExpiresAt: &expires}
+	assert.Contains(t, server.RenderResult("check_locale", nil, "ok"), "Your pro license expires on")
+	assert.Equal(t, "ok", server.RenderResult("check_license_status", nil, "ok"), "license tools show the state themselves")
+}
+
+func TestNewServer_LapsedLicense(t *testing.T) {
+	key := proLicenseKey(t)
+	storage := license.NewStorage()
+	lic, err := license.NewLicenseValidator().ValidateLicense(key)
+	require.NoError(t, err)
+	require.NoError(t, storage.SaveEntitlement(lic))
+	require.NoError(t, storage.SaveLicense(key))
+	t.Setenv("SENTINEL_LICENSE_KEY", "")
+
+	// The key no longer verifies, e.g. after the deployment rotated its signing key
+	public, _, err := license.GenerateKeyPair()
+	require.NoError(t, err)
+	publicKey, err := license.ParsePublicKey(public)
+	require.NoError(t, err)
+	t.Cleanup(license.TrustKeyForTesting(publicKey))
+	server := NewServer()
+	assert.False(t, server.license.IsValid)
+	assert.Contains(t, server.RenewalNotice(), "Your pro license, last validated")
+}
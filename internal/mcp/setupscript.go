@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/onboard"
+	"dev-env-sentinel/internal/runner"
+)
+
+// SetupScriptResult is the outcome of the generate_setup_script tool
+type SetupScriptResult struct {
+	Script  *onboard.Script
+	Path    string
+	Written bool
+}
+
+// handleGenerateSetupScript handles the generate_setup_script tool. It previews a setup script
+// derived from the project's ecosystems and current issues unless write is set; existing files
+// are only replaced with overwrite.
+func handleGenerateSetupScript(ctx context.Context, args map[string]interface{}, configs []*config.EcosystemConfig) (interface{}, error) {
+	projectRoot, ok := args["project_root"].(string)
+	if !ok {
+		return nil, fmt.Errorf("project_root is required")
+	}
+	format, _ := args["format"].(string)
+
+	ecosystems, err := detector.DetectEcosystems(ctx, projectRoot, configs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect ecosystems: %w", err)
+	}
+	if len(ecosystems) == 0 {
+		return "No ecosystems detected in project", nil
+	}
+	plan, err := onboard.Assess(ctx, projectRoot, ecosystems)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assess the environment: %w", err)
+	}
+	script, err := plan.Script(format)
+	if err != nil {
+		return nil, err
+	}
+
+	output := script.Path
+	if value, ok := args["output"].(string); ok && value != "" {
+		output = value
+	}
+	path := filepath.Join(projectRoot, output)
+	if inside, err := common.IsSubpath(projectRoot, path); err != nil || !inside {
+		return nil, fmt.Errorf("output must be inside the project root: %s", output)
+	}
+
+	result := &SetupScriptResult{Script: script, Path: path}
+	write, _ := args["write"].(bool)
+	if !write || runner.ReadOnly() {
+		return result, nil
+	}
+
+	overwrite, _ := args["overwrite"].(bool)
+	if common.FileExists(path) && !overwrite {
+		return nil, fmt.Errorf("%s already exists; set overwrite to replace it or choose another output", output)
+	}
+	mode := os.FileMode(0644)
+	if script.Format == onboard.FormatShell {
+		mode = 0755
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(output), err)
+	}
+	if err := os.WriteFile(path, []byte(script.Content), mode); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	result.Written = true
+	return result, nil
+}
+
+// formatSetupScriptResult formats a generate_setup_script result
+func formatSetupScriptResult(result *SetupScriptResult) string {
+	msg := ""
+	if result.Written {
+		msg += fmt.Sprintf("✅ Wrote %s setup script %s; review it and commit it\n", result.Script.Format, result.Path)
+	} else {
+		msg += fmt.Sprintf("📝 Candidate %s setup script %s (not written; set write to save it):\n\n", result.Script.Format, result.Path)
+		msg += result.Script.Content + "\n"
+	}
+	if len(result.Script.Manual) > 0 {
+		msg += fmt.Sprintf("Checked but not set up by the script (%d): %s\n", len(result.Script.Manual), strings.Join(result.Script.Manual, "; "))
+	}
+	return msg
+}
@@ -0,0 +1,55 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGenerateSetupScript(t *testing.T) {
+	t.Setenv("SENTINEL_READ_ONLY", "")
+	t.Setenv("NODE_ENV", "")
+	os.Unsetenv("NODE_ENV")
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "package.json"), []byte("{}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "app.js"), []byte("const env = process.env.NODE_ENV\n"), 0644))
+	configs := []*config.EcosystemConfig{{Ecosystem: config.Ecosystem{
+		ID:           "node-npm",
+		Detection:    config.Detection{RequiredFiles: []string{"package.json"}},
+		Environment:  config.Environment{VariablePatterns: []string{`process\.env\.([A-Z_][A-Z0-9_]*)`}},
+		Dependencies: config.Dependencies{ResolveCommand: "npm install"},
+	}}}
+	ctx := context.Background()
+
+	// Preview by default
+	result, err := handleGenerateSetupScript(ctx, map[string]interface{}{"project_root": root}, configs)
+	require.NoError(t, err)
+	preview := result.(*SetupScriptResult)
+	assert.False(t, preview.Written)
+	assert.NoFileExists(t, filepath.Join(root, "setup.sh"))
+	text := formatResult(preview)
+	assert.Contains(t, text, "echo 'NODE_ENV=development' >> .env")
+	assert.Contains(t, text, "npm install")
+
+	result, err = handleGenerateSetupScript(ctx, map[string]interface{}{"project_root": root, "write": true}, configs)
+	require.NoError(t, err)
+	assert.True(t, result.(*SetupScriptResult).Written)
+	info, err := os.Stat(filepath.Join(root, "setup.sh"))
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&0100, "the script is executable")
+
+	_, err = handleGenerateSetupScript(ctx, map[string]interface{}{"project_root": root, "write": true}, configs)
+	assert.ErrorContains(t, err, "already exists")
+
+	result, err = handleGenerateSetupScript(ctx, map[string]interface{}{"project_root": root, "format": "devcontainer", "write": true}, configs)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(root, ".devcontainer", "devcontainer.json"))
+
+	_, err = handleGenerateSetupScript(ctx, map[string]interface{}{"project_root": root, "output": "../setup.sh"}, configs)
+	assert.ErrorContains(t, err, "inside the project root")
+}
@@ -0,0 +1,281 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/queue"
+	"dev-env-sentinel/internal/settings"
+	"dev-env-sentinel/internal/snapshot"
+)
+
+// slackJob is the job name recorded for checks triggered by Slack slash commands
+const slackJob = "slack"
+
+// slackMaxSkew is how old a signed Slack request may be before it is rejected as a replay
+const slackMaxSkew = 5 * time.Minute
+
+// slackMaxBody bounds slash command payloads, which are a few hundred bytes
+const slackMaxBody = 64 << 10
+
+// defaultChecks are the checks Slack status commands and push webhooks run unless the
+// settings name others; the same as `sentinel check`
+var defaultChecks = []string{"verify_build_freshness", "check_infrastructure_parity", "env_var_audit"}
+
+// slackAckTimeout is how long a command may run before the request is acknowledged and the
+// result is posted to the command's response URL instead; Slack gives up after 3 seconds
+var slackAckTimeout = 2500 * time.Millisecond
+
+// slackClient posts delayed results to response URLs
+var slackClient = &http.Client{Timeout: 10 * time.Second}
+
+// slackResponse is a slash command reply; ephemeral replies are only shown to the caller
+type slackResponse struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+// SetSlack configures the Slack slash command endpoint; the signing secret defaults to
+// $SLACK_SIGNING_SECRET
+func (s *Server) SetSlack(cfg settings.Slack) {
+	if cfg.SigningSecret == "" {
+		cfg.SigningSecret = os.Getenv("SLACK_SIGNING_SECRET")
+	}
+	s.slack = cfg
+}
+
+// handleSlackCommand answers Slack slash commands: `status <project-id>` runs the status
+// checks on a registered project (or a scheduled job's project) and replies with a summary
+func (t *SSETransport) handleSlackCommand(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if server.slack.SigningSecret == "" {
+			http.Error(w, "Slack commands are not configured; set slack.signing_secret or SLACK_SIGNING_SECRET", http.StatusNotFound)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, slackMaxBody))
+		if err != nil {
+			http.Error(w, "Failed to read request", http.StatusBadRequest)
+			return
+		}
+		if err := verifySlackSignature(server.slack.SigningSecret, r.Header, body, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			http.Error(w, "Invalid form", http.StatusBadRequest)
+			return
+		}
+
+		reply := server.runSlackCommand(r.Context(), form.Get("command"), form.Get("text"), form.Get("response_url"))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(reply)
+	}
+}
+
+// runSlackCommand runs the text of a slash command. The checks of a status command run as a
+// job on the execution queue; when they outlast Slack's timeout, the reply names the job and
+// the result follows via the response URL.
+func (s *Server) runSlackCommand(ctx context.Context, command, text, responseURL string) slackResponse {
+	if command == "" {
+		command = "/sentinel"
+	}
+	args := strings.Fields(text)
+	if len(args) == 0 || args[0] != "status" || len(args) > 2 {
+		return slackResponse{ResponseType: "ephemeral", Text: fmt.Sprintf(
+			"Usage: `%s status <project-id>` runs %s and reports the results.\nProjects: %s",
+			command, strings.Join(s.slackChecks(), ", "), strings.Join(s.slackTargets(), ", "))}
+	}
+
+	var id string
+	if len(args) == 2 {
+		id = args[1]
+	} else if targets := s.slackTargets(); len(targets) == 1 {
+		id = targets[0]
+	} else {
+		return slackResponse{ResponseType: "ephemeral", Text: fmt.Sprintf(
+			"Which project? `%s status <project-id>` with one of: %s", command, strings.Join(targets, ", "))}
+	}
+
+	target, projectRoot, err := s.slackTarget(id)
+	if err != nil {
+		return slackResponse{ResponseType: "ephemeral", Text: "❌ " + err.Error()}
+	}
+	checks := s.slackChecks()
+	job, err := target.startJob(slackJob, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		// Checks read the checkout, which a push verification may be updating
+		target.pushMu.Lock()
+		defer target.pushMu.Unlock()
+		var entries []snapshot.Entry
+		for _, check := range checks {
+			entry := target.runCheck(ctx, slackJob, check, map[string]interface{}{"project_root": projectRoot})
+			target.snapshots.Record(entry)
+			entries = append(entries, entry)
+		}
+		return entries, nil
+	}, nil)
+	if err != nil {
+		return slackResponse{ResponseType: "ephemeral", Text: "❌ " + err.Error()}
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, slackAckTimeout)
+	defer cancel()
+	if finished, err := target.queue.Wait(waitCtx, job.ID); err == nil {
+		return slackJobReply(id, projectRoot, finished)
+	}
+	go func() {
+		finished, _ := target.queue.Wait(context.Background(), job.ID)
+		if err := postSlackResponse(responseURL, slackJobReply(id, projectRoot, finished)); err != nil {
+			fmt.Fprintf(os.Stderr, "error posting Slack command result: %v\n", err)
+		}
+	}()
+	return slackResponse{ResponseType: "ephemeral", Text: fmt.Sprintf("⏳ Running checks as job %s, the result will follow shortly.", job.ID)}
+}
+
+// slackJobReply replies with the results of a finished status job
+func slackJobReply(id, projectRoot string, job queue.Job) slackResponse {
+	entries, ok := job.Result.([]snapshot.Entry)
+	if job.Status == queue.StatusFailed || !ok {
+		return slackResponse{ResponseType: "ephemeral", Text: fmt.Sprintf("❌ Job %s failed: %s", job.ID, job.Error)}
+	}
+	return slackResponse{ResponseType: "in_channel", Text: formatSlackStatus(id, projectRoot, entries)}
+}
+
+// slackTarget resolves the ID of a status command to the server and project root to check:
+// a registered project, or else a scheduled job
+func (s *Server) slackTarget(id string) (*Server, string, error) {
+	if s.registry != nil {
+		if project, ok := s.registry.Get(id); ok {
+			tenant, err := s.Project(id)
+			if err != nil {
+				return nil, "", err
+			}
+			return tenant, project.Root, nil
+		}
+	}
+	if job, ok := s.schedule[id]; ok {
+		return s, job.ProjectRoot, nil
+	}
+	return nil, "", fmt.Errorf("unknown project: %s (known: %s)", id, strings.Join(s.slackTargets(), ", "))
+}
+
+// slackTargets returns the IDs status accepts: registered projects and scheduled jobs, sorted
+func (s *Server) slackTargets() []string {
+	seen := make(map[string]bool)
+	if s.registry != nil {
+		for _, project := range s.registry.List() {
+			seen[project.ID] = true
+		}
+	}
+	for name := range s.schedule {
+		seen[name] = true
+	}
+	var ids []string
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// slackChecks returns the checks status runs
+func (s *Server) slackChecks() []string {
+	if len(s.slack.Checks) > 0 {
+		return s.slack.Checks
+	}
+	return defaultChecks
+}
+
+// formatSlackStatus formats the results of a status command in Slack markup, with the first
+// line of each failing check's result
+func formatSlackStatus(id, projectRoot string, entries []snapshot.Entry) string {
+	failing := 0
+	var lines []string
+	for _, entry := range entries {
+		if entry.Error == "" && entry.Healthy {
+			lines = append(lines, fmt.Sprintf("✅ `%s`", entry.Check))
+			continue
+		}
+		failing++
+		detail := entry.Summary
+		if entry.Error != "" {
+			detail = entry.Error
+		}
+		lines = append(lines, fmt.Sprintf("❌ `%s`: %s", entry.Check, slackFirstLine(detail)))
+	}
+
+	headline := fmt.Sprintf("*%s* is healthy", id)
+	if failing > 0 {
+		headline = fmt.Sprintf("*%s* has %d failing check(s)", id, failing)
+	}
+	return fmt.Sprintf("%s (`%s`)\n%s", headline, projectRoot, strings.Join(lines, "\n"))
+}
+
+// slackFirstLine returns the first non-empty line of a result, without Slack control characters
+func slackFirstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(line)
+		}
+	}
+	return ""
+}
+
+// verifySlackSignature checks the signature Slack computes over a request with the app's
+// signing secret, rejecting requests older than slackMaxSkew
+func verifySlackSignature(secret string, header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing or invalid Slack request timestamp")
+	}
+	if skew := now.Sub(time.Unix(seconds, 0)); skew > slackMaxSkew || skew < -slackMaxSkew {
+		return fmt.Errorf("stale Slack request")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature"))) {
+		return fmt.Errorf("invalid Slack signature")
+	}
+	return nil
+}
+
+// postSlackResponse posts a delayed reply to a slash command's response URL
+func postSlackResponse(responseURL string, reply slackResponse) error {
+	if responseURL == "" {
+		return fmt.Errorf("no response URL")
+	}
+	data, err := json.Marshal(reply)
+	if err != nil {
+		return err
+	}
+	resp, err := slackClient.Post(responseURL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("response URL returned %s", resp.Status)
+	}
+	return nil
+}
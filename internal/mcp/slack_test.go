@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/auditor"
+	"dev-env-sentinel/internal/queue"
+	"dev-env-sentinel/internal/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slackRequest builds a slash command request signed with secret
+func slackRequest(secret string, form url.Values, at time.Time) *http.Request {
+	body := form.Encode()
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+	req := httptest.NewRequest(http.MethodPost, "/slack/command", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestSlackCommand(t *testing.T) {
+	t.Setenv("SLACK_SIGNING_SECRET", "")
+	server := NewServer()
+	server.RegisterTool("env_var_audit", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return &auditor.EnvVarReport{Issues: []string{"Missing environment variable: API_KEY"}}, nil
+	})
+	server.RegisterTool("check_locale", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	server.schedule["api"] = settings.ScheduledCheck{Name: "api", ProjectRoot: "/work/api"}
+	server.SetQueue(queue.New(1, 10))
+	handler := NewSSETransport("").handleSlackCommand(server)
+
+	send := func(req *http.Request) (*httptest.ResponseRecorder, slackResponse) {
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		var reply slackResponse
+		json.Unmarshal(rec.Body.Bytes(), &reply)
+		return rec, reply
+	}
+	status := url.Values{"command": {"/sentinel"}, "text": {"status api"}}
+
+	rec, _ := send(slackRequest("secret", status, time.Now()))
+	assert.Equal(t, http.StatusNotFound, rec.Code, "off without a signing secret")
+
+	server.SetSlack(settings.Slack{SigningSecret: "secret", Checks: []string{"check_locale", "env_var_audit"}})
+	rec, _ = send(slackRequest("wrong", status, time.Now()))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec, _ = send(slackRequest("secret", status, time.Now().Add(-10*time.Minute)))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "replayed requests are rejected")
+
+	rec, reply := send(slackRequest("secret", status, time.Now()))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "in_channel", reply.ResponseType)
+	assert.Contains(t, reply.Text, "*api* has 1 failing check(s) (`/work/api`)")
+	assert.Contains(t, reply.Text, "✅ `check_locale`")
+	assert.Contains(t, reply.Text, "❌ `env_var_audit`")
+	_, ok := server.snapshots.Get(slackJob, "env_var_audit")
+	assert.True(t, ok, "results are recorded")
+
+	_, reply = send(slackRequest("secret", url.Values{"command": {"/env"}, "text": {"status"}}, time.Now()))
+	assert.Equal(t, "in_channel", reply.ResponseType, "the only project is the default")
+	_, reply = send(slackRequest("secret", url.Values{"command": {"/env"}, "text": {"status web"}}, time.Now()))
+	assert.Equal(t, "ephemeral", reply.ResponseType)
+	assert.Contains(t, reply.Text, "unknown project: web (known: api)")
+	_, reply = send(slackRequest("secret", url.Values{"command": {"/env"}, "text": {"help"}}, time.Now()))
+	assert.Contains(t, reply.Text, "Usage: `/env status <project-id>` runs check_locale, env_var_audit")
+}
+
+func TestSlackCommand_Delayed(t *testing.T) {
+	posted := make(chan slackResponse, 1)
+	responseURL := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reply slackResponse
+		json.NewDecoder(r.Body).Decode(&reply)
+		posted <- reply
+	}))
+	defer responseURL.Close()
+	defer func(timeout time.Duration) { slackAckTimeout = timeout }(slackAckTimeout)
+	slackAckTimeout = 10 * time.Millisecond
+
+	server := NewServer()
+	server.RegisterTool("verify_build_freshness", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		time.Sleep(100 * time.Millisecond)
+		return "fresh", nil
+	})
+	server.schedule["api"] = settings.ScheduledCheck{Name: "api", ProjectRoot: "/work/api"}
+	server.SetSlack(settings.Slack{SigningSecret: "secret", Checks: []string{"verify_build_freshness"}})
+	q := queue.New(1, 10)
+	server.SetQueue(q)
+
+	rec := httptest.NewRecorder()
+	NewSSETransport("").handleSlackCommand(server)(rec, slackRequest("secret", url.Values{
+		"text": {"status api"}, "response_url": {responseURL.URL},
+	}, time.Now()))
+	var ack slackResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &ack))
+	assert.Contains(t, ack.Text, "result will follow")
+	// The checks run as a job on the execution queue, which the reply names
+	id := strings.TrimSuffix(strings.Fields(strings.TrimPrefix(ack.Text, "⏳ Running checks as job "))[0], ",")
+	job, ok := q.Get(id)
+	require.True(t, ok, ack.Text)
+	assert.Equal(t, slackJob, job.Name)
+
+	select {
+	case reply := <-posted:
+		assert.Equal(t, "in_channel", reply.ResponseType)
+		assert.Contains(t, reply.Text, "*api* is healthy")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the result was not posted to the response URL")
+	}
+}
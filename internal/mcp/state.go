@@ -0,0 +1,324 @@
+package mcp
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/cmdlog"
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/privacy"
+	"dev-env-sentinel/internal/reconciler"
+	"dev-env-sentinel/internal/runner"
+	"dev-env-sentinel/internal/snapshot"
+	"dev-env-sentinel/internal/state"
+	"dev-env-sentinel/internal/storage"
+)
+
+// flakyWindow is how far back the run history is analyzed for flaky checks
+const flakyWindow = 30 * 24 * time.Hour
+
+// retentionInterval is how often check history and logs are expired while the server runs
+const retentionInterval = time.Hour
+
+// SetState sets the state directory used to persist snapshots and history,
+// restoring the last persisted snapshot into memory. Records are kept in the
+// configured storage backend; the history files are used if it cannot be opened.
+func (s *Server) SetState(dir *state.Dir) {
+	s.stateDir = dir
+	s.store = nil
+	s.commandLog = nil
+	s.fixLoops = reconciler.NewCooldown(dir, s.fixLoops.Limits())
+	s.fixDurations = reconciler.NewFixDurations(dir)
+	if dir == nil {
+		return
+	}
+
+	store, err := storage.Open(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v; using history files instead\n", err)
+		store = storage.NewFileStore(dir)
+	}
+	s.store = store
+	s.commandLog = cmdlog.New(dir)
+	s.commandLog.SetStrictPrivacy(s.strictPrivacy)
+
+	if entries, err := store.Latest(); err == nil {
+		for _, entry := range entries {
+			s.snapshots.Record(entry)
+		}
+	}
+	s.applyRetention()
+}
+
+// SetStrictPrivacy sets whether check history, snapshots, command logs and crash dumps are
+// persisted, and tickets filed, without command output, environment variable values and paths
+// outside the project
+func (s *Server) SetStrictPrivacy(strict bool) {
+	s.strictPrivacy = strict
+	if s.commandLog != nil {
+		s.commandLog.SetStrictPrivacy(strict)
+	}
+	s.tickets.SetStrictPrivacy(strict)
+}
+
+// SetRetention sets how long and how much check history and logs are kept, and removes what
+// is already past it
+func (s *Server) SetRetention(retention state.Retention) {
+	s.retention = retention
+	s.applyRetention()
+}
+
+// applyRetention bounds the history of the state directory and expires old records
+func (s *Server) applyRetention() {
+	if s.stateDir == nil {
+		return
+	}
+	if s.retention.MaxHistoryBytes > 0 {
+		s.stateDir.SetMaxHistoryBytes(s.retention.MaxHistoryBytes)
+		if s.store != nil {
+			s.store.SetMaxHistoryBytes(s.retention.MaxHistoryBytes)
+		}
+	}
+	s.expireState(time.Now())
+}
+
+// expireState removes the check results and log files older than the retention age, and the
+// oldest log files past the log size bound
+func (s *Server) expireState(now time.Time) {
+	s.retentionMu.Lock()
+	s.lastRetention = now
+	s.retentionMu.Unlock()
+
+	var before time.Time
+	if s.retention.MaxAge > 0 {
+		before = now.Add(-s.retention.MaxAge)
+		if s.store != nil {
+			if _, err := s.store.Expire(before); err != nil {
+				fmt.Fprintf(os.Stderr, "error expiring check history: %v\n", err)
+			}
+		}
+	}
+	if !before.IsZero() || s.retention.MaxLogBytes > 0 {
+		if _, err := s.stateDir.ExpireLogs(before, s.retention.MaxLogBytes); err != nil {
+			fmt.Fprintf(os.Stderr, "error expiring logs: %v\n", err)
+		}
+	}
+}
+
+// expireStateDue expires old records when they weren't expired within retentionInterval
+func (s *Server) expireStateDue() {
+	if s.stateDir == nil || (s.retention.MaxAge == 0 && s.retention.MaxLogBytes == 0) {
+		return
+	}
+	now := time.Now()
+	s.retentionMu.Lock()
+	due := now.Sub(s.lastRetention) >= retentionInterval
+	s.retentionMu.Unlock()
+	if due {
+		s.expireState(now)
+	}
+}
+
+// persisted returns a check result as it is written to disk
+func (s *Server) persisted(entry snapshot.Entry) snapshot.Entry {
+	if s.strictPrivacy {
+		return privacy.Entry(entry)
+	}
+	return entry
+}
+
+// persistHistory appends a check result to the persisted history
+func (s *Server) persistHistory(entry snapshot.Entry) {
+	if s.store == nil {
+		return
+	}
+	if err := s.store.AppendCheck(s.persisted(entry)); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing check history: %v\n", err)
+	}
+	s.expireStateDue()
+}
+
+// checkHistory returns the recorded check results matching q, oldest first. The persisted
+// history is preferred since it survives restarts; otherwise the in-memory history is used.
+func (s *Server) checkHistory(q storage.Query) []snapshot.Entry {
+	if s.store != nil {
+		if entries, err := s.store.Checks(q); err == nil {
+			return entries
+		}
+	}
+
+	history := s.snapshots.History(0)
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+	return storage.Filter(history, q)
+}
+
+// persistSnapshots writes the latest snapshot to the store
+func (s *Server) persistSnapshots() {
+	if s.store == nil {
+		return
+	}
+	latest := s.snapshots.Latest()
+	for i := range latest {
+		latest[i] = s.persisted(latest[i])
+	}
+	if err := s.store.SaveLatest(latest); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing snapshot: %v\n", err)
+	}
+}
+
+// handleGetCommandLog handles the get_command_log tool
+func handleGetCommandLog(server *Server, args map[string]interface{}) (interface{}, error) {
+	fingerprint, _ := args["fingerprint"].(string)
+	jobID, _ := args["job_id"].(string)
+	if (fingerprint == "") == (jobID == "") {
+		return nil, fmt.Errorf("exactly one of fingerprint and job_id is required")
+	}
+	if server.commandLog == nil {
+		return nil, fmt.Errorf("command logs are unavailable: no state directory")
+	}
+
+	key := fingerprint
+	if jobID != "" {
+		// Jobs of other projects are reported as unknown
+		if server.queue != nil {
+			if job, ok := server.queue.Get(jobID); ok && job.Owner != server.jobOwner() {
+				return nil, fmt.Errorf("unknown job: %s", jobID)
+			}
+		}
+		key = jobID
+	}
+
+	log, err := server.commandLog.Read(key)
+	if err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// handlePurgeState handles the purge_state tool
+func handlePurgeState(server *Server, args map[string]interface{}) (interface{}, error) {
+	categories, err := stringListArg(args, "categories")
+	if err != nil {
+		return nil, err
+	}
+	dryRun, _ := args["dry_run"].(bool)
+	if runner.ReadOnly() {
+		// Deleting state is a state-changing action; only report what would be removed
+		dryRun = true
+	}
+
+	var dirs []*state.Dir
+	if server.stateDir != nil {
+		dirs = append(dirs, server.stateDir)
+	}
+
+	// Only purge a project-local state directory that already exists; never create one here
+	if projectRoot, ok := args["project_root"].(string); ok && projectRoot != "" {
+		projectState := state.ProjectRoot(projectRoot)
+		if common.DirExists(projectState) {
+			dir, err := state.Open(projectState)
+			if err != nil {
+				return nil, err
+			}
+			dirs = append(dirs, dir)
+		}
+	}
+
+	if len(dirs) == 0 {
+		return "No state directories to purge", nil
+	}
+
+	var reports []*state.PurgeReport
+	for _, dir := range dirs {
+		report, err := dir.Purge(categories, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	// Records in the database are not covered by removing the category directories
+	if server.store != nil {
+		removed, err := server.store.Purge(categories, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		reports[0].Removed = append(reports[0].Removed, removed...)
+	}
+
+	if !dryRun && (len(categories) == 0 || contains(categories, state.CategorySnapshots)) {
+		server.snapshots.Clear()
+	}
+
+	return reports, nil
+}
+
+// stringListArg reads an argument given either as a list of strings or a comma-separated string
+func stringListArg(args map[string]interface{}, name string) ([]string, error) {
+	switch v := args[name].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		var values []string
+		for _, value := range strings.Split(v, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		return values, nil
+	case []string:
+		return v, nil
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			value, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a list of strings", name)
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("%s must be a list of strings", name)
+	}
+}
+
+// formatPurgeReports formats purge results
+func formatPurgeReports(reports []*state.PurgeReport) string {
+	msg := ""
+	for _, report := range reports {
+		verb := "Removed"
+		if report.DryRun {
+			verb = "Would remove"
+		}
+
+		if len(report.Removed) == 0 {
+			msg += fmt.Sprintf("✅ Nothing to purge in %s\n", report.Root)
+			continue
+		}
+
+		msg += fmt.Sprintf("✅ %s %s from %s:\n", verb, formatBytes(report.FreedBytes), report.Root)
+		for _, path := range report.Removed {
+			msg += fmt.Sprintf("- %s\n", path)
+		}
+	}
+	return msg
+}
+
+// formatBytes formats a byte count for display
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,175 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/auditor"
+	"dev-env-sentinel/internal/privacy"
+	"dev-env-sentinel/internal/settings"
+	"dev-env-sentinel/internal/snapshot"
+	"dev-env-sentinel/internal/state"
+	"dev-env-sentinel/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledChecksPersistState(t *testing.T) {
+	dir, err := state.Open(t.TempDir())
+	require.NoError(t, err)
+
+	server := NewServer()
+	server.SetState(dir)
+	server.RegisterTool("env_var_audit", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return &auditor.EnvVarReport{IsHealthy: true}, nil
+	})
+
+	check := settings.ScheduledCheck{Name: "api", ProjectRoot: "/work/api", Checks: []string{"env_var_audit"}}
+	server.runScheduledCheck(context.Background(), check)
+	server.runScheduledCheck(context.Background(), check)
+
+	records, err := server.store.Checks(storage.Query{})
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	// A new server restores the persisted snapshot
+	restored := NewServer()
+	restored.SetState(dir)
+	entry, ok := restored.snapshots.Get("api", "env_var_audit")
+	require.True(t, ok)
+	assert.True(t, entry.Healthy)
+}
+
+func TestScheduledChecks_StrictPrivacy(t *testing.T) {
+	dir, err := state.Open(t.TempDir())
+	require.NoError(t, err)
+
+	server := NewServer()
+	server.SetStrictPrivacy(true)
+	server.SetState(dir)
+	server.RegisterTool("env_var_audit", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return &auditor.EnvVarReport{IsHealthy: false, Missing: []string{"API_KEY"}}, nil
+	})
+	server.RegisterTool("verify_build_freshness", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return nil, errors.New("cannot read /home/me/.m2/settings.xml")
+	})
+
+	check := settings.ScheduledCheck{Name: "api", ProjectRoot: "/work/api", Checks: []string{"env_var_audit", "verify_build_freshness"}}
+	server.runScheduledCheck(context.Background(), check)
+
+	// The running server still reports the details
+	live, ok := server.snapshots.Get("api", "verify_build_freshness")
+	require.True(t, ok)
+	assert.Contains(t, live.Error, "/home/me")
+
+	history, err := server.store.Checks(storage.Query{})
+	require.NoError(t, err)
+	latest, err := server.store.Latest()
+	require.NoError(t, err)
+	for _, entry := range append(history, latest...) {
+		if entry.Check == "env_var_audit" {
+			assert.Equal(t, privacy.Withheld, entry.Summary)
+			assert.Equal(t, []string{"API_KEY"}, entry.MissingEnvVars)
+		} else {
+			assert.Equal(t, privacy.Withheld, entry.Error)
+		}
+		assert.Equal(t, "/work/api", entry.ProjectRoot)
+		assert.False(t, entry.Healthy)
+	}
+	assert.Len(t, history, 2)
+}
+
+func TestSetRetention(t *testing.T) {
+	dir, err := state.Open(t.TempDir())
+	require.NoError(t, err)
+	server := NewServer()
+	server.SetState(dir)
+
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, server.store.AppendCheck(snapshot.Entry{Job: "api", Check: "env_var_audit", Timestamp: old}))
+	require.NoError(t, server.store.AppendCheck(snapshot.Entry{Job: "api", Check: "env_var_audit", Timestamp: time.Now()}))
+	logFile := dir.Path(state.CategoryLogs, "commands/job-1.log")
+	require.NoError(t, os.MkdirAll(filepath.Dir(logFile), 0755))
+	require.NoError(t, os.WriteFile(logFile, []byte("$ mvn install\n"), 0600))
+	require.NoError(t, os.Chtimes(logFile, old, old))
+
+	server.SetRetention(state.Retention{MaxAge: 24 * time.Hour})
+
+	history, err := server.store.Checks(storage.Query{})
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+	assert.NoFileExists(t, logFile)
+}
+
+func TestHandlePurgeState(t *testing.T) {
+	root := t.TempDir()
+	dir, err := state.Open(root)
+	require.NoError(t, err)
+	require.NoError(t, dir.AppendHistory(storage.HistoryChecks, map[string]string{"check": "a"}))
+	require.NoError(t, dir.WriteJSON(state.CategorySnapshots, storage.LatestSnapshotFile, []snapshot.Entry{}))
+
+	project := t.TempDir()
+	projectState := filepath.Join(project, state.ProjectDirName)
+	require.NoError(t, os.MkdirAll(filepath.Join(projectState, state.CategoryCache), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectState, state.CategoryCache, "x"), []byte("cached"), 0644))
+
+	server := NewServer()
+	server.SetState(dir)
+	server.snapshots.Record(snapshot.Entry{Job: "api", Check: "env_var_audit"})
+
+	// Dry run removes nothing
+	result, err := handlePurgeState(server, map[string]interface{}{"dry_run": true, "project_root": project})
+	require.NoError(t, err)
+	assert.Contains(t, formatResult(result), "Would remove")
+	assert.DirExists(t, filepath.Join(root, state.CategoryHistory))
+	assert.NotEmpty(t, server.snapshots.Latest())
+
+	result, err = handlePurgeState(server, map[string]interface{}{"categories": []interface{}{"history"}})
+	require.NoError(t, err)
+	reports := result.([]*state.PurgeReport)
+	require.Len(t, reports, 1)
+	assert.NoDirExists(t, filepath.Join(root, state.CategoryHistory))
+	assert.DirExists(t, filepath.Join(root, state.CategorySnapshots))
+	assert.NotEmpty(t, server.snapshots.Latest(), "in-memory snapshots survive unless snapshots are purged")
+
+	result, err = handlePurgeState(server, map[string]interface{}{"categories": "snapshots, cache", "project_root": project})
+	require.NoError(t, err)
+	assert.Len(t, result.([]*state.PurgeReport), 2)
+	assert.NoDirExists(t, filepath.Join(projectState, state.CategoryCache))
+	assert.Empty(t, server.snapshots.Latest())
+
+	_, err = handlePurgeState(server, map[string]interface{}{"categories": []interface{}{"license"}})
+	assert.Error(t, err)
+
+	// A project without a .sentinel directory does not get one created
+	other := t.TempDir()
+	_, err = handlePurgeState(NewServer(), map[string]interface{}{"project_root": other})
+	require.NoError(t, err)
+	assert.NoDirExists(t, filepath.Join(other, state.ProjectDirName))
+}
+
+func TestHandlePurgeState_ReadOnly(t *testing.T) {
+	t.Setenv("SENTINEL_READ_ONLY", "true")
+	root := t.TempDir()
+	dir, err := state.Open(root)
+	require.NoError(t, err)
+	require.NoError(t, dir.AppendHistory(storage.HistoryChecks, map[string]string{"check": "a"}))
+
+	server := NewServer()
+	server.SetState(dir)
+
+	result, err := handlePurgeState(server, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.True(t, result.([]*state.PurgeReport)[0].DryRun)
+	assert.DirExists(t, filepath.Join(root, state.CategoryHistory))
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", formatBytes(512))
+	assert.Equal(t, "1.5 KiB", formatBytes(1536))
+	assert.Equal(t, "2.0 MiB", formatBytes(2<<20))
+}
@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"dev-env-sentinel/internal/auditor"
+	"dev-env-sentinel/internal/container"
+	"dev-env-sentinel/internal/dockerfile"
+	"dev-env-sentinel/internal/mirror"
+	"dev-env-sentinel/internal/nix"
+	"dev-env-sentinel/internal/portability"
+	"dev-env-sentinel/internal/preflight"
+	"dev-env-sentinel/internal/reconciler"
+	"dev-env-sentinel/internal/triage"
+	"dev-env-sentinel/internal/verifier"
+	"dev-env-sentinel/internal/wrapper"
+)
+
+// issueTypes returns the issue types a tool result reports, for usage statistics. Only the
+// type names are returned, never messages, since those hold paths and values. Reports whose
+// issues are plain messages contribute nothing.
+func issueTypes(result interface{}) []string {
+	var types []string
+	switch v := result.(type) {
+	case *verifier.FreshnessReport:
+		for _, issue := range v.Issues {
+			types = append(types, issue.Type)
+		}
+	case *auditor.EnvVarReport:
+		for range v.Missing {
+			types = append(types, "missing_env_var")
+		}
+	case *portability.PortabilityReport:
+		for _, issue := range v.Issues {
+			types = append(types, issue.Type)
+		}
+	case *mirror.MirrorReport:
+		for _, issue := range v.Issues {
+			types = append(types, issue.Type)
+		}
+	case *container.RuntimeReport:
+		for _, issue := range v.Issues {
+			types = append(types, issue.Type)
+		}
+	case *wrapper.WrapperReport:
+		for _, issue := range v.Issues {
+			types = append(types, issue.Type)
+		}
+	case *dockerfile.ParityReport:
+		for _, issue := range v.Issues {
+			types = append(types, issue.Type)
+		}
+	case *nix.Report:
+		for _, issue := range v.Issues {
+			types = append(types, issue.Type)
+		}
+	case *preflight.Report:
+		for _, blocker := range v.Blockers {
+			types = append(types, blocker.Type)
+		}
+	case *triage.Report:
+		for _, match := range v.Matches {
+			types = append(types, match.IssueType)
+		}
+	case *reconciler.ReconciliationReport:
+		for _, r := range v.Fixed {
+			types = append(types, "fixed:"+r.IssueType)
+		}
+		for _, r := range v.Failed {
+			types = append(types, "fix_failed:"+r.IssueType)
+		}
+	}
+	return types
+}
@@ -0,0 +1,53 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"dev-env-sentinel/internal/state"
+	"dev-env-sentinel/internal/telemetry"
+	"dev-env-sentinel/internal/verifier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_RecordsTelemetry(t *testing.T) {
+	t.Setenv("DO_NOT_TRACK", "")
+	t.Setenv("SENTINEL_TELEMETRY", "")
+	dir, err := state.Open(t.TempDir())
+	require.NoError(t, err)
+	_, err = telemetry.SetEnabled(dir, true)
+	require.NoError(t, err)
+	recorder := telemetry.NewRecorder(dir, "")
+
+	server := NewServer()
+	server.SetTelemetry(recorder)
+	server.RegisterTool("verify_build_freshness", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return &verifier.FreshnessReport{Issues: []verifier.Issue{
+			{Type: "stale_build", Message: "/home/dev/secret-project/src/A.java is newer", File: "src/A.java"},
+		}}, nil
+	})
+	server.RegisterTool("explode", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+
+	_, err = server.CallTool(context.Background(), "verify_build_freshness", map[string]interface{}{"project_root": "/home/dev/secret-project"})
+	require.NoError(t, err)
+	_, err = server.CallTool(context.Background(), "explode", nil)
+	require.Error(t, err)
+	require.NoError(t, recorder.Flush(context.Background()))
+
+	batches, err := telemetry.Queued(dir)
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	assert.Equal(t, 1, batches[0].Tools["verify_build_freshness"].Calls)
+	assert.Equal(t, 1, batches[0].Tools["explode"].Errors)
+	assert.Equal(t, map[string]int{"stale_build": 1}, batches[0].IssueTypes)
+
+	payload, err := json.Marshal(batches[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(payload), "secret-project", "paths never reach the payload")
+	assert.NotContains(t, string(payload), "A.java")
+}
@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"dev-env-sentinel/internal/profile"
+	"dev-env-sentinel/internal/report"
+	"dev-env-sentinel/internal/runner"
+	"dev-env-sentinel/internal/tickets"
+)
+
+// IssueExport is the result of the export_issue tool: the ticket filed or found for an issue,
+// the issue that would be filed in read-only mode, or the issues to choose from when the check
+// found several
+type IssueExport struct {
+	Issue      *tickets.Issue  `json:"issue,omitempty"`
+	Ticket     *tickets.Ticket `json:"ticket,omitempty"`
+	Candidates []tickets.Issue `json:"candidates,omitempty"`
+	Planned    bool            `json:"planned,omitempty"` // Read-only mode: no ticket was filed
+}
+
+// handleExportIssue handles the export_issue tool. It files a ticket for a finding of a
+// check, selected by fingerprint when the check finds several, or for the failing checks of a
+// scheduled job's latest snapshot. In read-only mode the issue is returned without filing it.
+func handleExportIssue(ctx context.Context, server *Server, args map[string]interface{}) (interface{}, error) {
+	if server.tickets == nil || len(server.tickets.Trackers()) == 0 {
+		return nil, fmt.Errorf("no ticket tracker configured; add tickets.github or tickets.jira to sentinel.yaml")
+	}
+	tracker, _ := args["tracker"].(string)
+
+	var issue tickets.Issue
+	if job, _ := args["job"].(string); job != "" {
+		entries := server.snapshots.ForJob(job)
+		if len(entries) == 0 {
+			return nil, fmt.Errorf("no snapshot recorded for job: %s", job)
+		}
+		var err error
+		if issue, err = tickets.FromSnapshot(job, entries); err != nil {
+			return nil, err
+		}
+	} else {
+		check, _ := args["check"].(string)
+		projectRoot, _ := args["project_root"].(string)
+		if check == "" || projectRoot == "" {
+			return nil, fmt.Errorf("check and project_root, or job, are required")
+		}
+		if profile.IsMutating(check) || check == "export_issue" {
+			return nil, fmt.Errorf("%s is not a check", check)
+		}
+		result, err := server.CallTool(ctx, check, map[string]interface{}{"project_root": projectRoot})
+		if err != nil {
+			return nil, fmt.Errorf("%s failed: %w", check, err)
+		}
+
+		fingerprint, _ := args["fingerprint"].(string)
+		var candidates []tickets.Issue
+		for _, finding := range report.Collect(check, projectRoot, result) {
+			candidate := tickets.FromFinding(projectRoot, finding)
+			if fingerprint == "" || candidate.Fingerprint == fingerprint {
+				candidates = append(candidates, candidate)
+			}
+		}
+		switch {
+		case len(candidates) == 0 && fingerprint != "":
+			return nil, fmt.Errorf("no current issue of %s has fingerprint %s", check, fingerprint)
+		case len(candidates) == 0:
+			return fmt.Sprintf("%s found no issues in %s; nothing to export.", check, projectRoot), nil
+		case len(candidates) > 1:
+			return &IssueExport{Candidates: candidates}, nil
+		}
+		issue = candidates[0]
+	}
+
+	if runner.ReadOnly() {
+		return &IssueExport{Issue: &issue, Planned: true}, nil
+	}
+	ticket, err := server.tickets.Export(ctx, server.stateDir, issue, tracker)
+	if err != nil {
+		return nil, err
+	}
+	return &IssueExport{Issue: &issue, Ticket: ticket}, nil
+}
+
+// formatIssueExport formats an export_issue result
+func formatIssueExport(export *IssueExport) string {
+	if export.Planned {
+		return fmt.Sprintf("📝 Read-only mode: no ticket filed. Would export:\n\nIssue: %s\nFingerprint: %s",
+			export.Issue.Summary, export.Issue.Fingerprint)
+	}
+	if export.Ticket == nil {
+		var b strings.Builder
+		fmt.Fprintf(&b, "Found %d issues; call export_issue again with the fingerprint of the one to export:\n", len(export.Candidates))
+		for _, issue := range export.Candidates {
+			location := ""
+			if issue.File != "" {
+				location = " (" + issue.File + ")"
+			}
+			fmt.Fprintf(&b, "\n- %s: %s%s", issue.Fingerprint, issue.Summary, location)
+		}
+		return b.String()
+	}
+
+	verb := "Filed"
+	if !export.Ticket.Created {
+		verb = "Already tracked in"
+	}
+	return fmt.Sprintf("🎫 %s %s ticket %s: %s\n\nIssue: %s\nFingerprint: %s",
+		verb, export.Ticket.Tracker, export.Ticket.ID, export.Ticket.URL, export.Issue.Summary, export.Ticket.Fingerprint)
+}
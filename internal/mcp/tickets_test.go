@@ -0,0 +1,86 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"dev-env-sentinel/internal/auditor"
+	"dev-env-sentinel/internal/settings"
+	"dev-env-sentinel/internal/snapshot"
+	"dev-env-sentinel/internal/tickets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleExportIssue(t *testing.T) {
+	t.Setenv("SENTINEL_READ_ONLY", "")
+	var titles []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/issues":
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": []interface{}{}})
+		case "/repos/acme/app/issues":
+			var issue map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&issue)
+			titles = append(titles, issue["title"].(string))
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{"number": len(titles), "html_url": "https://github.com/acme/app/issues/1"})
+		}
+	}))
+	defer api.Close()
+
+	server := NewServer()
+	_, err := handleExportIssue(context.Background(), server, map[string]interface{}{"job": "nightly"})
+	assert.ErrorContains(t, err, "no ticket tracker configured")
+
+	exporter, err := tickets.New(settings.Tickets{GitHub: &settings.GitHubTracker{Repo: "acme/app", APIURL: api.URL, Token: "gh-token"}})
+	require.NoError(t, err)
+	server.SetTickets(exporter)
+	server.RegisterTool("env_var_audit", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return &auditor.EnvVarReport{References: []auditor.EnvVarReference{
+			{Name: "API_KEY", File: "/work/app/main.go", Line: 3},
+			{Name: "DB_URL", File: "/work/app/db.go", Line: 9},
+		}}, nil
+	})
+
+	_, err = handleExportIssue(context.Background(), server, map[string]interface{}{"check": "reconcile_environment", "project_root": "/work/app"})
+	assert.ErrorContains(t, err, "is not a check")
+
+	// Several findings: the candidates are listed for the caller to pick one
+	result, err := handleExportIssue(context.Background(), server, map[string]interface{}{"check": "env_var_audit", "project_root": "/work/app"})
+	require.NoError(t, err)
+	export := result.(*IssueExport)
+	require.Len(t, export.Candidates, 2)
+	assert.Nil(t, export.Ticket)
+	assert.Contains(t, formatIssueExport(export), export.Candidates[1].Fingerprint)
+
+	result, err = handleExportIssue(context.Background(), server, map[string]interface{}{
+		"check": "env_var_audit", "project_root": "/work/app", "fingerprint": export.Candidates[1].Fingerprint,
+	})
+	require.NoError(t, err)
+	export = result.(*IssueExport)
+	require.NotNil(t, export.Ticket)
+	assert.True(t, export.Ticket.Created)
+	assert.Equal(t, []string{"[sentinel] env_var_audit: Environment variable DB_URL is not set"}, titles)
+	assert.Contains(t, formatIssueExport(export), "Filed github ticket #1")
+
+	// A snapshot's failing checks
+	server.snapshots.Record(snapshot.Entry{Job: "nightly", ProjectRoot: "/work/app", Check: "env_var_audit", Summary: "API_KEY missing"})
+	_, err = handleExportIssue(context.Background(), server, map[string]interface{}{"job": "nightly"})
+	require.NoError(t, err)
+	assert.Equal(t, "[sentinel] nightly: env_var_audit failing", titles[1])
+
+	// Read-only mode returns the issue without filing it
+	t.Setenv("SENTINEL_READ_ONLY", "true")
+	server.snapshots.Record(snapshot.Entry{Job: "weekly", ProjectRoot: "/work/app", Check: "check_locale", Summary: "LANG unset"})
+	result, err = handleExportIssue(context.Background(), server, map[string]interface{}{"job": "weekly"})
+	require.NoError(t, err)
+	export = result.(*IssueExport)
+	assert.True(t, export.Planned)
+	assert.Nil(t, export.Ticket)
+	assert.Len(t, titles, 2)
+	assert.Contains(t, formatIssueExport(export), "Read-only mode: no ticket filed")
+}
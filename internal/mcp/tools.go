@@ -2,44 +2,170 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"dev-env-sentinel/internal/apify"
 	"dev-env-sentinel/internal/auditor"
 	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/container"
 	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/dockerfile"
 	"dev-env-sentinel/internal/infra"
 	"dev-env-sentinel/internal/license"
+	"dev-env-sentinel/internal/locale"
+	"dev-env-sentinel/internal/mirror"
+	"dev-env-sentinel/internal/nix"
+	"dev-env-sentinel/internal/portability"
 	"dev-env-sentinel/internal/reconciler"
+	"dev-env-sentinel/internal/trust"
 	"dev-env-sentinel/internal/verifier"
+	"dev-env-sentinel/internal/wrapper"
 )
 
 // RegisterAllTools registers all MCP tools
 func RegisterAllTools(server *Server, configs []*config.EcosystemConfig) {
 	tracker := apify.NewEventTracker()
+	server.configs = configs
 
 	// Free tier tools
 	server.RegisterTool("verify_build_freshness", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		tracker.TrackEvent(apify.EventVerifyBuildFreshness, "verify_build_freshness", extractMetadata(args))
-		return handleVerifyBuildFreshness(args, configs)
+		return handleVerifyBuildFreshness(ctx, args, configs)
 	})
 
 	server.RegisterTool("check_infrastructure_parity", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		tracker.TrackEvent(apify.EventCheckInfrastructure, "check_infrastructure_parity", extractMetadata(args))
-		return handleCheckInfrastructureParity(args, configs)
+		return handleCheckInfrastructureParity(ctx, args, configs)
 	})
 
 	server.RegisterTool("env_var_audit", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		tracker.TrackEvent(apify.EventEnvVarAudit, "env_var_audit", extractMetadata(args))
-		return handleEnvVarAudit(args, configs)
+		return handleEnvVarAudit(ctx, args, configs)
+	})
+
+	server.RegisterTool("check_trust_stores", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		tracker.TrackEvent(apify.EventCheckTrustStores, "check_trust_stores", extractMetadata(args))
+		return handleCheckTrustStores(ctx, args, configs)
+	})
+
+	server.RegisterTool("check_locale", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		tracker.TrackEvent(apify.EventCheckLocale, "check_locale", extractMetadata(args))
+		return handleCheckLocale(ctx, args, configs)
+	})
+
+	server.RegisterTool("check_portability", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		tracker.TrackEvent(apify.EventCheckPortability, "check_portability", extractMetadata(args))
+		return handleCheckPortability(ctx, args)
+	})
+
+	server.RegisterTool("check_build_wrappers", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		tracker.TrackEvent(apify.EventCheckBuildWrappers, "check_build_wrappers", extractMetadata(args))
+		return handleCheckBuildWrappers(args)
+	})
+
+	server.RegisterTool("check_mirrors", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		tracker.TrackEvent(apify.EventCheckMirrors, "check_mirrors", extractMetadata(args))
+		return handleCheckMirrors(ctx, args)
+	})
+
+	server.RegisterTool("check_container_runtime", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		tracker.TrackEvent(apify.EventCheckContainerRuntime, "check_container_runtime", extractMetadata(args))
+		return handleCheckContainerRuntime(ctx, args)
+	})
+
+	server.RegisterTool("check_dockerfile_parity", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		tracker.TrackEvent(apify.EventCheckDockerfileParity, "check_dockerfile_parity", extractMetadata(args))
+		return handleCheckDockerfileParity(ctx, args, configs)
+	})
+
+	server.RegisterTool("check_nix_environment", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		tracker.TrackEvent(apify.EventCheckNixEnvironment, "check_nix_environment", extractMetadata(args))
+		return handleCheckNixEnvironment(ctx, args)
+	})
+
+	server.RegisterTool("preflight_check", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		tracker.TrackEvent(apify.EventPreflightCheck, "preflight_check", extractMetadata(args))
+		return handlePreflightCheck(ctx, server, args, configs)
+	})
+
+	server.RegisterTool("get_environment_snapshot", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleGetEnvironmentSnapshot(server, args)
+	})
+
+	server.RegisterTool("get_flaky_components", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleGetFlakyComponents(server, args)
+	})
+
+	server.RegisterTool("get_environment_trends", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleGetEnvironmentTrends(server, args)
+	})
+
+	server.RegisterTool("purge_state", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handlePurgeState(server, args)
+	})
+
+	server.RegisterTool("export_issue", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleExportIssue(ctx, server, args)
+	})
+
+	server.RegisterTool("get_command_log", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleGetCommandLog(server, args)
+	})
+
+	server.RegisterTool("generate_dotenv", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleGenerateDotenv(args)
+	})
+
+	server.RegisterTool("list_configs", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleListConfigs(ctx, args, configs)
+	})
+
+	server.RegisterTool("fetch_config", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleFetchConfig(ctx, args)
+	})
+
+	server.RegisterTool("generate_setup_script", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleGenerateSetupScript(ctx, args, configs)
+	})
+
+	server.RegisterTool("explain_detection", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleExplainDetection(ctx, args, configs)
+	})
+
+	server.RegisterTool("dump_trace", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleDumpTrace(server, args)
+	})
+
+	server.RegisterTool("get_server_version", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleGetServerVersion(ctx, args)
 	})
 
 	// Premium tier tool (gated)
 	server.RegisterTool("reconcile_environment", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		// Track billable event BEFORE execution
 		tracker.TrackEvent(apify.EventReconcileEnvironment, "reconcile_environment", extractMetadata(args))
-		return handleReconcileEnvironment(server, args, configs)
+		return handleReconcileEnvironment(ctx, server, args, configs)
+	})
+
+	server.RegisterTool("reconcile_issue", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		tracker.TrackEvent(apify.EventAutoFix, "reconcile_issue", extractMetadata(args))
+		return handleReconcileIssue(ctx, server, args, configs)
+	})
+
+	server.RegisterTool("triage_test_failure", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		tracker.TrackEvent(apify.EventAdvancedDiagnostics, "triage_test_failure", extractMetadata(args))
+		return handleTriageTestFailure(ctx, server, args, configs)
+	})
+
+	server.RegisterTool("onboard_project", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		if fix, _ := args["fix"].(bool); fix {
+			tracker.TrackEvent(apify.EventAutoFix, "onboard_project", extractMetadata(args))
+		}
+		return handleOnboardProject(ctx, server, args, configs)
 	})
 
 	// Monetization tools
@@ -52,6 +178,14 @@ func RegisterAllTools(server *Server, configs []*config.EcosystemConfig) {
 		return handleActivatePro(server, args)
 	})
 
+	server.RegisterTool("start_trial", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleStartTrial(server, args)
+	})
+
+	server.RegisterTool("list_features", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return handleListFeatures(server)
+	})
+
 	server.RegisterTool("check_license_status", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		tracker.TrackEvent(apify.EventCheckLicenseStatus, "check_license_status", extractMetadata(args))
 		return handleCheckLicenseStatus(server)
@@ -75,14 +209,21 @@ func extractMetadata(args map[string]interface{}) map[string]string {
 }
 
 // handleVerifyBuildFreshness handles the verify_build_freshness tool
-func handleVerifyBuildFreshness(args map[string]interface{}, configs []*config.EcosystemConfig) (interface{}, error) {
+func handleVerifyBuildFreshness(ctx context.Context, args map[string]interface{}, configs []*config.EcosystemConfig) (interface{}, error) {
 	projectRoot, ok := args["project_root"].(string)
 	if !ok {
 		return nil, fmt.Errorf("project_root is required")
 	}
 
+	suite, _ := args["suite"].(string)
+	if suite != "" {
+		if err := config.ValidateSuite(configs, suite); err != nil {
+			return nil, err
+		}
+	}
+
 	// Detect ecosystems
-	ecosystems, err := detector.DetectEcosystems(projectRoot, configs)
+	ecosystems, err := detector.DetectEcosystems(ctx, projectRoot, configs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to detect ecosystems: %w", err)
 	}
@@ -91,13 +232,16 @@ func handleVerifyBuildFreshness(args map[string]interface{}, configs []*config.E
 		return "No ecosystems detected in project", nil
 	}
 
-	// Verify build freshness for each ecosystem
+	// Verify build freshness for each ecosystem, running only the commands of the suite
 	var reports []*verifier.FreshnessReport
 	for _, eco := range ecosystems {
-		report, err := verifier.VerifyBuildFreshness(projectRoot, eco)
+		scoped := *eco
+		scoped.Config = eco.Config.ForSuite(suite)
+		report, err := verifier.VerifyBuildFreshness(ctx, projectRoot, &scoped)
 		if err != nil {
 			continue
 		}
+		report.Suite = suite
 		reports = append(reports, report)
 	}
 
@@ -105,19 +249,30 @@ func handleVerifyBuildFreshness(args map[string]interface{}, configs []*config.E
 		return "No verification reports generated", nil
 	}
 
-	// Return first report (can be extended to return all)
-	return reports[0], nil
+	// Issues reported by several ecosystems are listed once
+	combined := verifier.CombineReports(reports)
+	durations := reconciler.FixDurationsFrom(ctx)
+	for _, issue := range combined.Issues {
+		if issue.Fix != nil {
+			issue.Fix.Fingerprint = reconciler.Fingerprint(projectRoot, issue.Type, issue.Fix.Command)
+			if typical, _, ok := durations.Estimate(projectRoot, issue.Type); ok {
+				issue.Fix.EstimatedSeconds = int(typical.Round(time.Second).Seconds())
+				issue.Fix.Estimate = durations.Describe(projectRoot, issue.Type)
+			}
+		}
+	}
+	return combined, nil
 }
 
 // handleCheckInfrastructureParity handles the check_infrastructure_parity tool
-func handleCheckInfrastructureParity(args map[string]interface{}, configs []*config.EcosystemConfig) (interface{}, error) {
+func handleCheckInfrastructureParity(ctx context.Context, args map[string]interface{}, configs []*config.EcosystemConfig) (interface{}, error) {
 	projectRoot, ok := args["project_root"].(string)
 	if !ok {
 		return nil, fmt.Errorf("project_root is required")
 	}
 
 	// Detect ecosystems
-	ecosystems, err := detector.DetectEcosystems(projectRoot, configs)
+	ecosystems, err := detector.DetectEcosystems(ctx, projectRoot, configs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to detect ecosystems: %w", err)
 	}
@@ -127,32 +282,52 @@ func handleCheckInfrastructureParity(args map[string]interface{}, configs []*con
 	}
 
 	// Check infrastructure for each ecosystem
+	var reported []string
 	var reports []*infra.InfrastructureReport
+	var checked []*config.EcosystemConfig
 	for _, eco := range ecosystems {
-		report, err := infra.CheckInfrastructure(context.Background(), eco.Config)
+		report, err := infra.CheckInfrastructure(ctx, eco.Config)
 		if err != nil {
 			continue
 		}
+		daemons, err := infra.CheckDaemons(ctx, projectRoot, eco.Config)
+		if err != nil {
+			report.Issues = append(report.Issues, fmt.Sprintf("Daemon check failed: %v", err))
+		}
+		report.AddDaemons(daemons)
+		report.AddBuildCaches(infra.CheckBuildCaches(ctx, projectRoot, eco.Config))
+		report.AddServiceLogs(ctx, projectRoot, eco.Config)
+		reported = append(reported, eco.Config.Ecosystem.ID)
 		reports = append(reports, report)
+		checked = append(checked, eco.Config)
 	}
 
 	if len(reports) == 0 {
 		return "No infrastructure reports generated", nil
 	}
 
-	// Return first report (can be extended to return all)
-	return reports[0], nil
+	// Issues reported by several ecosystems are listed once
+	combined := infra.CombineReports(reported, reports)
+	// OS limits are shared, so they are checked once against the highest needs
+	combined.AddLimits(infra.CheckLimits(ctx, projectRoot, checked))
+	// A Nix dev shell provides the toolchain, so entering it beats switching versions by hand
+	if env := nix.Detect(projectRoot); env != nil {
+		if active, _ := env.Active(); !active {
+			combined.SuggestDevShell(env.Hint())
+		}
+	}
+	return combined, nil
 }
 
 // handleEnvVarAudit handles the env_var_audit tool
-func handleEnvVarAudit(args map[string]interface{}, configs []*config.EcosystemConfig) (interface{}, error) {
+func handleEnvVarAudit(ctx context.Context, args map[string]interface{}, configs []*config.EcosystemConfig) (interface{}, error) {
 	projectRoot, ok := args["project_root"].(string)
 	if !ok {
 		return nil, fmt.Errorf("project_root is required")
 	}
 
 	// Detect ecosystems
-	ecosystems, err := detector.DetectEcosystems(projectRoot, configs)
+	ecosystems, err := detector.DetectEcosystems(ctx, projectRoot, configs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to detect ecosystems: %w", err)
 	}
@@ -162,12 +337,14 @@ func handleEnvVarAudit(args map[string]interface{}, configs []*config.EcosystemC
 	}
 
 	// Audit environment variables for each ecosystem
+	var reported []string
 	var reports []*auditor.EnvVarReport
 	for _, eco := range ecosystems {
-		report, err := auditor.AuditEnvironmentVariables(projectRoot, eco.Config)
+		report, err := auditor.AuditEnvironmentVariables(ctx, projectRoot, eco.Config)
 		if err != nil {
 			continue
 		}
+		reported = append(reported, eco.Config.Ecosystem.ID)
 		reports = append(reports, report)
 	}
 
@@ -175,12 +352,220 @@ func handleEnvVarAudit(args map[string]interface{}, configs []*config.EcosystemC
 		return "No environment variable reports generated", nil
 	}
 
-	// Return first report (can be extended to return all)
-	return reports[0], nil
+	// Issues reported by several ecosystems are listed once
+	combined := auditor.CombineEnvVarReports(reported, reports)
+	// Missing variables the .envrc exports are fixed at once by getting direnv to load it
+	direnv, err := auditor.AuditDirenv(ctx, projectRoot, combined.Missing)
+	if err != nil {
+		combined.Issues = append(combined.Issues, err.Error())
+	}
+	combined.AddDirenv(direnv)
+	return combined, nil
+}
+
+// handleCheckTrustStores handles the check_trust_stores tool
+func handleCheckTrustStores(ctx context.Context, args map[string]interface{}, configs []*config.EcosystemConfig) (interface{}, error) {
+	projectRoot, ok := args["project_root"].(string)
+	if !ok {
+		return nil, fmt.Errorf("project_root is required")
+	}
+
+	// Detect ecosystems
+	ecosystems, err := detector.DetectEcosystems(ctx, projectRoot, configs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect ecosystems: %w", err)
+	}
+
+	if len(ecosystems) == 0 {
+		return "No ecosystems detected in project", nil
+	}
+
+	// Check every ecosystem's trust stores against the same custom CA
+	ca := trust.DetectCustomCA()
+	combined := &trust.TrustReport{
+		CustomCA:  ca,
+		Stores:    []trust.StoreStatus{},
+		IsHealthy: true,
+		Issues:    []string{},
+	}
+	for _, eco := range ecosystems {
+		report, err := trust.CheckTrustStores(ctx, eco.Config, ca)
+		if err != nil {
+			continue
+		}
+		combined.Stores = append(combined.Stores, report.Stores...)
+		for _, issue := range report.Issues {
+			if !contains(combined.Issues, issue) {
+				combined.Issues = append(combined.Issues, issue)
+			}
+		}
+		for _, hint := range report.Hints {
+			if !contains(combined.Hints, hint) {
+				combined.Hints = append(combined.Hints, hint)
+			}
+		}
+		if !report.IsHealthy {
+			combined.IsHealthy = false
+		}
+	}
+
+	return combined, nil
+}
+
+// handleCheckLocale handles the check_locale tool
+func handleCheckLocale(ctx context.Context, args map[string]interface{}, configs []*config.EcosystemConfig) (interface{}, error) {
+	projectRoot, ok := args["project_root"].(string)
+	if !ok {
+		return nil, fmt.Errorf("project_root is required")
+	}
+
+	// Detect ecosystems
+	ecosystems, err := detector.DetectEcosystems(ctx, projectRoot, configs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect ecosystems: %w", err)
+	}
+
+	if len(ecosystems) == 0 {
+		return "No ecosystems detected in project", nil
+	}
+
+	// Locale checks are opt-in per ecosystem; merge the results of those that enable them
+	var combined *locale.LocaleReport
+	var checked []string
+	for _, eco := range ecosystems {
+		if !eco.Config.Ecosystem.Locale.Enabled {
+			continue
+		}
+		report, err := locale.CheckLocale(ctx, eco.Config)
+		if err != nil {
+			return nil, fmt.Errorf("locale check failed for %s: %w", eco.ID, err)
+		}
+		checked = append(checked, eco.ID)
+		if combined == nil {
+			combined = report
+			continue
+		}
+		for _, issue := range report.Issues {
+			if !contains(combined.Issues, issue) {
+				combined.Issues = append(combined.Issues, issue)
+			}
+		}
+		if report.ClockChecked && !combined.ClockChecked {
+			combined.ClockChecked = true
+			combined.ClockOffset = report.ClockOffset
+		}
+		combined.IsHealthy = combined.IsHealthy && report.IsHealthy
+	}
+
+	if combined == nil {
+		return "No detected ecosystem enables locale checks", nil
+	}
+	combined.EcosystemID = strings.Join(checked, ", ")
+
+	return combined, nil
+}
+
+// handleCheckPortability handles the check_portability tool. The check is project-wide,
+// so it does not depend on the detected ecosystems.
+func handleCheckPortability(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	projectRoot, ok := args["project_root"].(string)
+	if !ok {
+		return nil, fmt.Errorf("project_root is required")
+	}
+
+	return portability.CheckPortability(ctx, projectRoot)
+}
+
+// handleCheckBuildWrappers handles the check_build_wrappers tool
+func handleCheckBuildWrappers(args map[string]interface{}) (interface{}, error) {
+	projectRoot, ok := args["project_root"].(string)
+	if !ok {
+		return nil, fmt.Errorf("project_root is required")
+	}
+
+	return wrapper.CheckWrappers(projectRoot)
+}
+
+// handleCheckMirrors handles the check_mirrors tool
+func handleCheckMirrors(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	projectRoot, ok := args["project_root"].(string)
+	if !ok {
+		return nil, fmt.Errorf("project_root is required")
+	}
+
+	return mirror.CheckMirrors(ctx, projectRoot)
+}
+
+// handleCheckContainerRuntime handles the check_container_runtime tool
+func handleCheckContainerRuntime(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	projectRoot, ok := args["project_root"].(string)
+	if !ok {
+		return nil, fmt.Errorf("project_root is required")
+	}
+
+	var opts container.Options
+	if maxDisk, ok := args["max_disk_gb"].(float64); ok {
+		if maxDisk <= 0 {
+			return nil, fmt.Errorf("max_disk_gb must be positive")
+		}
+		opts.MaxDiskGB = maxDisk
+	}
+	if minCPUs, ok := args["min_cpus"].(float64); ok {
+		if minCPUs <= 0 {
+			return nil, fmt.Errorf("min_cpus must be positive")
+		}
+		opts.MinCPUs = minCPUs
+	}
+	if minMemory, ok := args["min_memory_gb"].(float64); ok {
+		if minMemory <= 0 {
+			return nil, fmt.Errorf("min_memory_gb must be positive")
+		}
+		opts.MinMemoryGB = minMemory
+	}
+	return container.CheckRuntime(ctx, projectRoot, opts)
+}
+
+// handleCheckDockerfileParity handles the check_dockerfile_parity tool
+func handleCheckDockerfileParity(ctx context.Context, args map[string]interface{}, configs []*config.EcosystemConfig) (interface{}, error) {
+	projectRoot, ok := args["project_root"].(string)
+	if !ok {
+		return nil, fmt.Errorf("project_root is required")
+	}
+
+	ecosystems, err := detector.DetectEcosystems(ctx, projectRoot, configs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect ecosystems: %w", err)
+	}
+	return dockerfile.CheckParity(ctx, projectRoot, ecosystems)
+}
+
+// handleCheckNixEnvironment handles the check_nix_environment tool
+func handleCheckNixEnvironment(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	projectRoot, ok := args["project_root"].(string)
+	if !ok {
+		return nil, fmt.Errorf("project_root is required")
+	}
+
+	return nix.CheckEnvironment(ctx, projectRoot)
+}
+
+// contains checks if a slice contains a string
+func contains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
 }
 
 // handleReconcileEnvironment handles the reconcile_environment tool (PREMIUM FEATURE)
-func handleReconcileEnvironment(server *Server, args map[string]interface{}, configs []*config.EcosystemConfig) (interface{}, error) {
+func handleReconcileEnvironment(ctx context.Context, server *Server, args map[string]interface{}, configs []*config.EcosystemConfig) (interface{}, error) {
+	// Fix commands may be disabled by the operator's tool profile
+	if !server.policy.AllowsFixes() {
+		return nil, fmt.Errorf("fix commands are disabled by the %s tool profile", server.policy.Name())
+	}
+
 	// Check if feature is available
 	if err := server.featureManager.RequireFeature("reconcile_environment"); err != nil {
 		upgradeMsg := server.featureManager.GetUpgradeMessage("reconcile_environment")
@@ -192,8 +577,20 @@ func handleReconcileEnvironment(server *Server, args map[string]interface{}, con
 		return nil, fmt.Errorf("project_root is required")
 	}
 
+	// plan runs nothing; confirm runs the fixes the fix policy holds for confirmation
+	var approval reconciler.Approval
+	approval.Plan, _ = args["plan"].(bool)
+	if confirm, ok := args["confirm"].([]interface{}); ok {
+		for _, fingerprint := range confirm {
+			if s, ok := fingerprint.(string); ok {
+				approval.Confirmed = append(approval.Confirmed, s)
+			}
+		}
+	}
+	ctx = reconciler.WithApproval(ctx, approval)
+
 	// Detect ecosystems
-	ecosystems, err := detector.DetectEcosystems(projectRoot, configs)
+	ecosystems, err := detector.DetectEcosystems(ctx, projectRoot, configs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to detect ecosystems: %w", err)
 	}
@@ -203,25 +600,91 @@ func handleReconcileEnvironment(server *Server, args map[string]interface{}, con
 	}
 
 	// First, verify build freshness to get issues
-	var allIssues []verifier.Issue
+	allIssues := fixableIssues(ctx, projectRoot, ecosystems)
+
+	// Missing environment variables are fixed through the project's .env
+	var envReports []*auditor.EnvVarReport
+	missingVars := 0
 	for _, eco := range ecosystems {
-		report, err := verifier.VerifyBuildFreshness(projectRoot, eco)
+		envReport, err := auditor.AuditEnvironmentVariables(ctx, projectRoot, eco.Config)
 		if err != nil {
 			continue
 		}
-		allIssues = append(allIssues, report.Issues...)
+		envReports = append(envReports, envReport)
+		missingVars += len(envReport.Missing)
 	}
 
-	if len(allIssues) == 0 {
+	if len(allIssues) == 0 && missingVars == 0 {
 		return "No issues found to reconcile", nil
 	}
 
 	// Reconcile issues for first ecosystem (can be extended)
-	report, err := reconciler.ReconcileEnvironment(context.Background(), projectRoot, allIssues, ecosystems[0])
+	report := reconciler.NewReport()
+	if len(allIssues) > 0 {
+		report, err = reconciler.ReconcileEnvironment(ctx, projectRoot, allIssues, ecosystems[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to reconcile environment: %w", err)
+		}
+	}
+	if err := reconciler.ReconcileEnvVars(ctx, projectRoot, envReports, report); err != nil {
+		return nil, fmt.Errorf("failed to reconcile environment variables: %w", err)
+	}
+	report.AttachDocs(ecosystems)
+	report.Summarize()
+
+	return report, nil
+}
+
+// fixableIssues verifies build freshness for the ecosystems and returns their issues
+func fixableIssues(ctx context.Context, projectRoot string, ecosystems []*detector.DetectedEcosystem) []verifier.Issue {
+	var issues []verifier.Issue
+	for _, eco := range ecosystems {
+		report, err := verifier.VerifyBuildFreshness(ctx, projectRoot, eco)
+		if err != nil {
+			continue
+		}
+		issues = append(issues, report.Issues...)
+	}
+	return issues
+}
+
+// handleReconcileIssue runs the single fix with a fingerprint from a prior report (PREMIUM FEATURE)
+func handleReconcileIssue(ctx context.Context, server *Server, args map[string]interface{}, configs []*config.EcosystemConfig) (interface{}, error) {
+	if !server.policy.AllowsFixes() {
+		return nil, fmt.Errorf("fix commands are disabled by the %s tool profile", server.policy.Name())
+	}
+
+	if err := server.featureManager.RequireFeature("reconcile_environment"); err != nil {
+		upgradeMsg := server.featureManager.GetUpgradeMessage("reconcile_environment")
+		return upgradeMsg, fmt.Errorf("premium feature not available: %w", err)
+	}
+
+	projectRoot, ok := args["project_root"].(string)
+	if !ok {
+		return nil, fmt.Errorf("project_root is required")
+	}
+	fingerprint, ok := args["fingerprint"].(string)
+	if !ok || fingerprint == "" {
+		return nil, fmt.Errorf("fingerprint is required")
+	}
+	plan, _ := args["plan"].(bool)
+	ctx = reconciler.WithApproval(ctx, reconciler.Approval{Plan: plan})
+
+	ecosystems, err := detector.DetectEcosystems(ctx, projectRoot, configs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to reconcile environment: %w", err)
+		return nil, fmt.Errorf("failed to detect ecosystems: %w", err)
 	}
 
+	report, err := reconciler.ReconcileFingerprint(ctx, projectRoot, fixableIssues(ctx, projectRoot, ecosystems), ecosystems, fingerprint)
+	if errors.Is(err, reconciler.ErrFingerprintNotFound) {
+		return nil, fmt.Errorf("%w; the issue may already be fixed, call reconcile_environment with plan set for the current fingerprints", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	report.AttachDocs(ecosystems)
+	report.Summarize()
+
 	return report, nil
 }
 
@@ -275,6 +738,33 @@ func handleActivatePro(server *Server, args map[string]interface{}) (interface{}
 	return msg, nil
 }
 
+// handleStartTrial unlocks a premium feature on this machine for a few days
+func handleStartTrial(server *Server, args map[string]interface{}) (interface{}, error) {
+	feature := "reconcile_environment"
+	if f, ok := args["feature"].(string); ok && f != "" {
+		feature = f
+	}
+	days := license.DefaultTrialDays
+	if d, ok := args["days"].(float64); ok {
+		days = int(d)
+	}
+
+	if server.license.IsValid && server.license.HasFeature(feature) && server.license.TrialFor(feature) == nil {
+		return nil, fmt.Errorf("your %s license already includes %s", server.license.Tier, feature)
+	}
+	trial, err := server.StartTrial(feature, days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start trial: %w", err)
+	}
+
+	return fmt.Sprintf(
+		"✅ Trial of %s started on this machine.\n\n"+
+			"Unlocked until: %s\n\n"+
+			"Each feature can be trialed once per machine. Use 'get_pro_license' to keep it afterwards.",
+		trial.Feature, trial.ExpiresAt.Local().Format("2006-01-02 15:04"),
+	), nil
+}
+
 // handleCheckLicenseStatus returns current license status
 func handleCheckLicenseStatus(server *Server) (interface{}, error) {
 	lic := server.license
@@ -301,12 +791,40 @@ func handleCheckLicenseStatus(server *Server) (interface{}, error) {
 		msg += fmt.Sprintf("• %s\n", feature)
 	}
 
+	if len(lic.Trials) > 0 {
+		msg += "\nTrials on this machine:\n"
+		now := time.Now()
+		for _, t := range lic.Trials {
+			if t.Active(now) {
+				msg += fmt.Sprintf("• %s: active, %s left (until %s)\n", t.Feature, trialRemaining(t.ExpiresAt.Sub(now)), t.ExpiresAt.Local().Format("2006-01-02 15:04"))
+			} else {
+				msg += fmt.Sprintf("• %s: ended %s\n", t.Feature, t.ExpiresAt.Local().Format("2006-01-02"))
+			}
+		}
+	}
+	if notice := server.RenewalNotice(); notice != "" {
+		msg += "\n" + notice + "\n"
+	}
+	if lic.IsValid && lic.Legacy {
+		msg += fmt.Sprintf("\n⚠️ This is a legacy license key, accepted until %s. Ask for a signed key and activate it with 'activate_pro'.\n", license.LegacyCutoff.Format("2006-01-02"))
+	}
 	if !lic.IsValid && lic.Tier != "free" {
 		msg += "\n⚠️ Your license is invalid or expired. Use 'get_pro_license' to purchase a new one."
 	} else if lic.Tier == "free" {
 		msg += "\n💡 Upgrade to Pro to unlock auto-fix and advanced features. Use 'get_pro_license' for details."
+		if len(lic.Trials) == 0 {
+			msg += fmt.Sprintf("\n💡 Try auto-fix free for %d days with 'start_trial'.", license.DefaultTrialDays)
+		}
 	}
 
 	return msg, nil
 }
 
+// trialRemaining formats the time left in a trial in days or hours, rounded up
+func trialRemaining(d time.Duration) string {
+	if d > 24*time.Hour {
+		return fmt.Sprintf("%d days", int((d+24*time.Hour-1)/(24*time.Hour)))
+	}
+	return fmt.Sprintf("%d hours", int((d+time.Hour-1)/time.Hour))
+}
+
@@ -1,11 +1,19 @@
 package mcp
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/features"
+	"dev-env-sentinel/internal/locale"
+	"dev-env-sentinel/internal/profile"
+	"dev-env-sentinel/internal/runner"
+	"dev-env-sentinel/internal/trust"
+	"dev-env-sentinel/internal/verifier"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -52,7 +60,7 @@ func TestHandleVerifyBuildFreshness(t *testing.T) {
 		"project_root": tmpDir,
 	}
 
-	result, err := handleVerifyBuildFreshness(args, configs)
+	result, err := handleVerifyBuildFreshness(context.Background(), args, configs)
 	require.NoError(t, err)
 	assert.NotNil(t, result)
 }
@@ -64,7 +72,7 @@ func TestHandleVerifyBuildFreshness_NoProjectRoot(t *testing.T) {
 		// Missing project_root
 	}
 
-	_, err := handleVerifyBuildFreshness(args, configs)
+	_, err := handleVerifyBuildFreshness(context.Background(), args, configs)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "project_root is required")
 }
@@ -77,11 +85,44 @@ func TestHandleVerifyBuildFreshness_NoEcosystems(t *testing.T) {
 		"project_root": tmpDir,
 	}
 
-	result, err := handleVerifyBuildFreshness(args, configs)
+	result, err := handleVerifyBuildFreshness(context.Background(), args, configs)
 	require.NoError(t, err)
 	assert.Equal(t, "No ecosystems detected in project", result)
 }
 
+func TestHandleVerifyBuildFreshness_Suite(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "pom.xml"), []byte("<project></project>"), 0644))
+
+	configs := []*config.EcosystemConfig{{Ecosystem: config.Ecosystem{
+		ID:        "java-maven",
+		Detection: config.Detection{RequiredFiles: []string{"pom.xml"}},
+		Verification: config.Verification{BuildFreshness: config.BuildFreshness{Commands: []config.VerificationCommand{
+			// Without build output, every timestamp check reports missing output
+			{Name: "pom_vs_classes", Type: "timestamp_compare", Source: "pom.xml", TargetPattern: "target/**/*.class"},
+			{Name: "pom_vs_jar", Type: "timestamp_compare", Source: "pom.xml", TargetPattern: "target/*.jar", Suites: []string{"full", "release"}},
+		}}},
+	}}}
+
+	full, err := handleVerifyBuildFreshness(context.Background(), map[string]interface{}{"project_root": tmpDir}, configs)
+	require.NoError(t, err)
+	assert.Len(t, full.(*verifier.FreshnessReport).Issues, 2)
+
+	quick, err := handleVerifyBuildFreshness(context.Background(), map[string]interface{}{"project_root": tmpDir, "suite": "quick"}, configs)
+	require.NoError(t, err)
+	report := quick.(*verifier.FreshnessReport)
+	assert.Equal(t, "quick", report.Suite)
+	assert.Len(t, report.Issues, 1, "commands tagged with other suites are skipped")
+	assert.Contains(t, formatFreshnessReport(report), "for java-maven (quick suite)")
+
+	release, err := handleVerifyBuildFreshness(context.Background(), map[string]interface{}{"project_root": tmpDir, "suite": "release"}, configs)
+	require.NoError(t, err)
+	assert.Len(t, release.(*verifier.FreshnessReport).Issues, 2)
+
+	_, err = handleVerifyBuildFreshness(context.Background(), map[string]interface{}{"project_root": tmpDir, "suite": "nightly"}, configs)
+	assert.ErrorContains(t, err, "unknown suite nightly (available: full, pre-commit, quick, release)")
+}
+
 func TestHandleCheckInfrastructureParity(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -104,7 +145,7 @@ func TestHandleCheckInfrastructureParity(t *testing.T) {
 		"project_root": tmpDir,
 	}
 
-	result, err := handleCheckInfrastructureParity(args, configs)
+	result, err := handleCheckInfrastructureParity(context.Background(), args, configs)
 	require.NoError(t, err)
 	assert.NotNil(t, result)
 }
@@ -131,11 +172,172 @@ func TestHandleEnvVarAudit(t *testing.T) {
 		"project_root": tmpDir,
 	}
 
-	result, err := handleEnvVarAudit(args, configs)
+	result, err := handleEnvVarAudit(context.Background(), args, configs)
 	require.NoError(t, err)
 	assert.NotNil(t, result)
 }
 
+func TestHandleCheckTrustStores(t *testing.T) {
+	tmpDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte("{}"), 0644)
+	require.NoError(t, err)
+
+	// Proxy without a declared CA file is a hint, not a trust issue
+	t.Setenv("SENTINEL_CA_CERT", "")
+	t.Setenv("SSL_CERT_FILE", "")
+	t.Setenv("REQUESTS_CA_BUNDLE", "")
+	t.Setenv("NODE_EXTRA_CA_CERTS", "")
+	t.Setenv("PIP_CERT", "")
+	t.Setenv("HTTPS_PROXY", "http://proxy.corp:3128")
+
+	configs := []*config.EcosystemConfig{
+		{
+			Ecosystem: config.Ecosystem{
+				ID:        "npm",
+				Detection: config.Detection{RequiredFiles: []string{"package.json"}},
+				Trust: config.Trust{Stores: []config.TrustStore{
+					{Name: "node", Type: "env_var", EnvVar: "NODE_EXTRA_CA_CERTS"},
+				}},
+			},
+		},
+	}
+
+	args := map[string]interface{}{
+		"project_root": tmpDir,
+	}
+
+	result, err := handleCheckTrustStores(context.Background(), args, configs)
+	require.NoError(t, err)
+	report, ok := result.(*trust.TrustReport)
+	require.True(t, ok)
+	assert.True(t, report.IsHealthy)
+	assert.Empty(t, report.Issues)
+	assert.Len(t, report.Hints, 1)
+}
+
+func TestHandleCheckTrustStores_NoProjectRoot(t *testing.T) {
+	_, err := handleCheckTrustStores(context.Background(), map[string]interface{}{}, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "project_root is required")
+}
+
+func TestHandleCheckLocale_NotEnabled(t *testing.T) {
+	tmpDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte("{}"), 0644)
+	require.NoError(t, err)
+
+	configs := []*config.EcosystemConfig{
+		{
+			Ecosystem: config.Ecosystem{
+				ID:        "npm",
+				Detection: config.Detection{RequiredFiles: []string{"package.json"}},
+				Trust: config.Trust{Stores: []config.TrustStore{
+					{Name: "node", Type: "env_var", EnvVar: "NODE_EXTRA_CA_CERTS"},
+				}},
+			},
+		},
+	}
+
+	result, err := handleCheckLocale(context.Background(), map[string]interface{}{"project_root": tmpDir}, configs)
+	require.NoError(t, err)
+	assert.Equal(t, "No detected ecosystem enables locale checks", result)
+}
+
+func TestHandleCheckLocale(t *testing.T) {
+	tmpDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte("{}"), 0644)
+	require.NoError(t, err)
+	t.Setenv("LC_ALL", "en_US.ISO-8859-1")
+
+	configs := []*config.EcosystemConfig{
+		{
+			Ecosystem: config.Ecosystem{
+				ID:        "npm",
+				Detection: config.Detection{RequiredFiles: []string{"package.json"}},
+				Locale:    config.Locale{Enabled: true, RequireUTF8: true},
+			},
+		},
+	}
+
+	result, err := handleCheckLocale(context.Background(), map[string]interface{}{"project_root": tmpDir}, configs)
+	require.NoError(t, err)
+	report, ok := result.(*locale.LocaleReport)
+	require.True(t, ok)
+	assert.False(t, report.IsHealthy)
+	assert.Equal(t, "npm", report.EcosystemID)
+}
+
+func TestHandleListConfigs(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte("{}"), 0644))
+
+	configs := []*config.EcosystemConfig{
+		{Ecosystem: config.Ecosystem{ID: "npm", Detection: config.Detection{RequiredFiles: []string{"package.json"}}}},
+		{Ecosystem: config.Ecosystem{ID: "maven", Detection: config.Detection{RequiredFiles: []string{"pom.xml"}}}},
+	}
+
+	result, err := handleListConfigs(context.Background(), map[string]interface{}{"project_root": tmpDir}, configs)
+	require.NoError(t, err)
+	list, ok := result.(*ConfigList)
+	require.True(t, ok)
+	require.Len(t, list.Configs, 2)
+	assert.Equal(t, map[string]float64{"npm": 1}, list.Detected)
+
+	formatted := formatConfigList(list)
+	assert.Contains(t, formatted, "- maven: not detected\n  Requires: pom.xml")
+	assert.Contains(t, formatted, "- npm: detected (confidence 1.00)")
+
+	result, err = handleListConfigs(context.Background(), map[string]interface{}{"id": "npm"}, configs)
+	require.NoError(t, err)
+	assert.Len(t, result.(*ConfigList).Configs, 1)
+
+	_, err = handleListConfigs(context.Background(), map[string]interface{}{"id": "gradle"}, configs)
+	assert.ErrorContains(t, err, "unknown ecosystem: gradle")
+}
+
+func TestHandleExplainDetection(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte("{}"), 0644))
+
+	configs := []*config.EcosystemConfig{
+		{Ecosystem: config.Ecosystem{ID: "npm", Detection: config.Detection{
+			RequiredFiles: []string{"package.json"},
+			OptionalFiles: []string{"package-lock.json"},
+		}}},
+	}
+
+	result, err := handleExplainDetection(context.Background(), map[string]interface{}{"project_root": tmpDir, "ecosystem": "npm"}, configs)
+	require.NoError(t, err)
+	explanation, ok := result.(*detector.Explanation)
+	require.True(t, ok)
+	assert.True(t, explanation.Detected)
+
+	formatted := formatExplanation(explanation)
+	assert.Contains(t, formatted, "npm is detected")
+	assert.Contains(t, formatted, "✓ package.json")
+	assert.Contains(t, formatted, "- package-lock.json (missing)")
+	assert.Contains(t, formatted, "- optional files: 0 of 1 present")
+
+	_, err = handleExplainDetection(context.Background(), map[string]interface{}{"project_root": tmpDir}, configs)
+	assert.ErrorContains(t, err, "ecosystem is required")
+
+	_, err = handleExplainDetection(context.Background(), map[string]interface{}{"project_root": tmpDir, "ecosystem": "maven"}, configs)
+	assert.ErrorContains(t, err, "unknown ecosystem: maven")
+}
+
+func TestHandleFetchConfig_ReadOnly(t *testing.T) {
+	t.Setenv("SENTINEL_READ_ONLY", "true")
+	dir := t.TempDir()
+	t.Setenv(config.UserConfigDirEnvVar, dir)
+
+	_, err := handleFetchConfig(context.Background(), map[string]interface{}{"index": t.TempDir(), "name": "bazel"})
+	assert.True(t, runner.IsReadOnly(err), "installing a config is refused: %v", err)
+	assert.ErrorContains(t, err, "would have run: sentinel config add bazel")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
 func TestHandleReconcileEnvironment(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -159,7 +361,8 @@ func TestHandleReconcileEnvironment(t *testing.T) {
 	}
 
 	server := NewServer()
-	result, err := handleReconcileEnvironment(server, args, configs)
+	require.NoError(t, server.UpdateLicense(proLicenseKey(t)))
+	result, err := handleReconcileEnvironment(context.Background(), server, args, configs)
 	require.NoError(t, err)
 	
 	// Should return "No issues found to reconcile" if no issues
@@ -179,5 +382,95 @@ func TestRegisterAllTools(t *testing.T) {
 	assert.NotNil(t, server.tools["check_infrastructure_parity"])
 	assert.NotNil(t, server.tools["env_var_audit"])
 	assert.NotNil(t, server.tools["reconcile_environment"])
+	assert.NotNil(t, server.tools["check_trust_stores"])
+	assert.NotNil(t, server.tools["check_locale"])
+}
+
+
+func TestRegisterAllTools_Profiles(t *testing.T) {
+	tests := []struct {
+		profile string
+		visible []string
+		hidden  []string
+	}{
+		{profile.ReadOnly, []string{"verify_build_freshness", "get_environment_snapshot", "get_pro_license"}, []string{"reconcile_environment", "purge_state", "activate_pro"}},
+		{profile.CI, []string{"env_var_audit", "check_locale"}, []string{"reconcile_environment", "get_pro_license", "activate_pro"}},
+		{profile.Full, []string{"reconcile_environment", "purge_state", "activate_pro"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			policy, err := profile.NewPolicy(tt.profile, nil)
+			require.NoError(t, err)
+
+			server := NewServer()
+			server.SetToolPolicy(policy)
+			RegisterAllTools(server, nil)
+
+			for _, name := range tt.visible {
+				assert.Contains(t, server.tools, name)
+			}
+			for _, name := range tt.hidden {
+				assert.NotContains(t, server.tools, name)
+			}
+		})
+	}
 }
 
+func TestHandleReconcileEnvironment_FixesDisabled(t *testing.T) {
+	policy, err := profile.NewPolicy(profile.ReadOnly, nil)
+	require.NoError(t, err)
+
+	server := NewServer()
+	server.SetToolPolicy(policy)
+
+	_, err = handleReconcileEnvironment(context.Background(), server, map[string]interface{}{"project_root": t.TempDir()}, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "disabled by the read-only tool profile")
+}
+
+func TestHandleStartTrial(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SENTINEL_LICENSE_KEY", "")
+	server := NewServer()
+	require.Error(t, server.featureManager.RequireFeature("reconcile_environment"))
+
+	status, err := handleCheckLicenseStatus(server)
+	require.NoError(t, err)
+	assert.Contains(t, status, "start_trial")
+
+	result, err := handleStartTrial(server, map[string]interface{}{"days": float64(3)})
+	require.NoError(t, err)
+	assert.Contains(t, result, "Trial of reconcile_environment started")
+	assert.NoError(t, server.featureManager.RequireFeature("reconcile_environment"))
+
+	status, err = handleCheckLicenseStatus(server)
+	require.NoError(t, err)
+	assert.Contains(t, status, "reconcile_environment: active, 3 days left")
+
+	_, err = handleStartTrial(server, nil)
+	assert.ErrorContains(t, err, "already running")
+
+	// The trial survives a restart
+	assert.NoError(t, NewServer().featureManager.RequireFeature("reconcile_environment"))
+}
+
+func TestHandleListFeatures(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SENTINEL_LICENSE_KEY", "")
+	t.Setenv(features.EnvVar, "")
+	server := NewServer()
+	server.SetFeatureFlags(map[string]bool{"beta_ui": true, "reconcile_environment": true})
+
+	result, err := handleListFeatures(server)
+	require.NoError(t, err)
+	text := formatResult(result)
+	assert.Contains(t, text, "- beta_ui: on (config)")
+	assert.Contains(t, text, "- reconcile_environment: off (default), paid - config override ignored")
+
+	// Overrides survive license changes
+	_, err = server.StartTrial("reconcile_environment", 1)
+	require.NoError(t, err)
+	assert.True(t, server.featureManager.IsEnabled("beta_ui"))
+	assert.Equal(t, features.SourceTrial, server.featureManager.Flag("reconcile_environment").Source)
+}
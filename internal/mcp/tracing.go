@@ -0,0 +1,55 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+
+	"dev-env-sentinel/internal/trace"
+)
+
+// defaultTraceMessages is the number of recent messages returned by dump_trace
+const defaultTraceMessages = 100
+
+// TraceDump is the result of the dump_trace tool
+type TraceDump struct {
+	Path     string        `json:"path"`
+	Messages []trace.Entry `json:"messages"`
+}
+
+// handleDumpTrace handles the dump_trace tool
+func handleDumpTrace(server *Server, args map[string]interface{}) (interface{}, error) {
+	limit := defaultTraceMessages
+	if v, ok := args["limit"].(float64); ok {
+		if v < 1 {
+			return nil, fmt.Errorf("limit must be at least 1")
+		}
+		limit = int(v)
+	}
+
+	if server.tracer == nil {
+		return "Tracing is off. Set SENTINEL_TRACE=1 in the server's environment and restart it to record JSON-RPC messages.", nil
+	}
+	messages, err := server.tracer.Tail(limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trace: %w", err)
+	}
+	return &TraceDump{Path: server.tracer.Path(), Messages: messages}, nil
+}
+
+// formatTraceDump formats a dump_trace result as one JSON message per line
+func formatTraceDump(dump *TraceDump) string {
+	if len(dump.Messages) == 0 {
+		return fmt.Sprintf("No JSON-RPC messages traced yet (%s)", dump.Path)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📋 Last %d JSON-RPC message(s), secrets redacted (full trace: %s)\n\n", len(dump.Messages), dump.Path)
+	for _, entry := range dump.Messages {
+		arrow := "→"
+		if entry.Direction == trace.Out {
+			arrow = "←"
+		}
+		fmt.Fprintf(&b, "%s %s %s %s\n", entry.Time.Format("15:04:05.000"), arrow, entry.Transport, entry.Message)
+	}
+	return b.String()
+}
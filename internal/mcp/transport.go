@@ -6,6 +6,10 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+
+	"dev-env-sentinel/internal/buildinfo"
+	"dev-env-sentinel/internal/queue"
+	"dev-env-sentinel/internal/trace"
 )
 
 // Transport defines the interface for MCP transport layers
@@ -29,7 +33,7 @@ func (t *StdioTransport) Start(ctx context.Context, server *Server) error {
 	}
 
 	// Start message loop
-	return server.messageLoop()
+	return server.messageLoop(ctx)
 }
 
 // SSETransport implements SSE+HTTP transport (for Apify/cloud deployments)
@@ -52,6 +56,10 @@ func (t *SSETransport) Start(ctx context.Context, server *Server) error {
 	http.HandleFunc("/sse", t.handleSSE(server))
 	http.HandleFunc("/message", t.handleMessage(server))
 	http.HandleFunc("/health", t.handleHealth)
+	http.HandleFunc("/dashboard", t.handleDashboard(server))
+	http.HandleFunc("/dashboard/run", t.handleDashboardRun(server))
+	http.HandleFunc("/slack/command", t.handleSlackCommand(server))
+	http.HandleFunc("/webhook/push", t.handlePushWebhook(server))
 
 	addr := ":" + t.port
 	if t.port == "" {
@@ -65,6 +73,21 @@ func (t *SSETransport) Start(ctx context.Context, server *Server) error {
 // handleSSE handles Server-Sent Events connections
 func (t *SSETransport) handleSSE(server *Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		target, err := scopedServer(server, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		// Subscribe before announcing the connection so no job event is missed after it
+		var events <-chan queue.Job
+		if target.queue != nil && target.registry == nil {
+			// Unscoped connections to a shared server would see every project's jobs
+			var stop func()
+			events, stop = target.queue.Subscribe()
+			defer stop()
+		}
+
 		// Set SSE headers
 		w.Header().Set("Content-Type", "text/event-stream")
 		w.Header().Set("Cache-Control", "no-cache")
@@ -82,8 +105,26 @@ func (t *SSETransport) handleSSE(server *Server) http.HandlerFunc {
 			flusher.Flush()
 		}
 
-		// Keep connection alive (Apify will handle the actual message flow)
-		<-r.Context().Done()
+		// Keep connection alive (Apify will handle the actual message flow),
+		// streaming the state changes of background jobs visible to the client
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case job, ok := <-events:
+				if !ok {
+					return
+				}
+				if job.Owner != target.jobOwner() {
+					continue
+				}
+				data, _ := json.Marshal(job)
+				fmt.Fprintf(w, "event: job\ndata: %s\n\n", data)
+				if flusher, ok := w.(http.Flusher); ok {
+					flusher.Flush()
+				}
+			}
+		}
 	}
 }
 
@@ -105,6 +146,24 @@ func (t *SSETransport) handleMessage(server *Server) http.HandlerFunc {
 			http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
 			return
 		}
+		server.tracer.Record(trace.In, "http", msg)
+
+		// Requests for a registered project are served by that project's server
+		target, err := scopedServer(server, r)
+		if err != nil {
+			response := map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      msg["id"],
+				"error": map[string]interface{}{
+					"code":    -32602,
+					"message": err.Error(),
+				},
+			}
+			server.tracer.Record(trace.Out, "http", response)
+			encoder := json.NewEncoder(w)
+			encoder.Encode(response)
+			return
+		}
 
 		// Handle the message
 		var response map[string]interface{}
@@ -118,19 +177,25 @@ func (t *SSETransport) handleMessage(server *Server) http.HandlerFunc {
 					"result": map[string]interface{}{
 						"protocolVersion": "2024-11-05",
 						"capabilities": map[string]interface{}{
-							"tools": map[string]interface{}{},
+							"tools":     map[string]interface{}{},
+							"resources": map[string]interface{}{},
 						},
 						"serverInfo": map[string]interface{}{
 							"name":    "dev-env-sentinel",
-							"version": "0.1.0",
+							"version": buildinfo.Get().Version,
 						},
 					},
 				}
 				response = initResp
 			case "tools/list":
-				response = server.handleToolsListResponse(msg)
+				response = target.handleToolsListResponse(msg)
 			case "tools/call":
-				response = server.handleToolCallResponse(msg)
+				ctx := server.authorize(r.Context(), r.Header.Get("Authorization"))
+				response = target.handleToolCallResponse(ctx, msg)
+			case "resources/list":
+				response = target.handleResourcesListResponse(msg)
+			case "resources/read":
+				response = target.handleResourcesReadResponse(msg)
 			default:
 				response = map[string]interface{}{
 					"jsonrpc": "2.0",
@@ -153,12 +218,26 @@ func (t *SSETransport) handleMessage(server *Server) http.HandlerFunc {
 		}
 
 		// Send response
+		server.tracer.Record(trace.Out, "http", response)
 		encoder := json.NewEncoder(w)
 		encoder.SetIndent("", "  ")
 		encoder.Encode(response)
 	}
 }
 
+// scopedServer returns the server a request is scoped to: the registered project named by the
+// project header or query parameter, or the shared server when neither is given
+func scopedServer(server *Server, r *http.Request) (*Server, error) {
+	id := r.Header.Get(ProjectHeader)
+	if id == "" {
+		id = r.URL.Query().Get("project")
+	}
+	if id == "" {
+		return server, nil
+	}
+	return server.Project(id)
+}
+
 // handleHealth handles health check requests
 func (t *SSETransport) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -170,7 +249,7 @@ func (t *SSETransport) handleHealth(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleToolsListResponse(msg map[string]interface{}) map[string]interface{} {
 	tools := []map[string]interface{}{}
 
-	for name := range s.tools {
+	for _, name := range s.servedTools() {
 		tools = append(tools, map[string]interface{}{
 			"name":        name,
 			"description": getToolDescription(name),
@@ -187,7 +266,7 @@ func (s *Server) handleToolsListResponse(msg map[string]interface{}) map[string]
 }
 
 // handleToolCallResponse handles tools/call and returns response map
-func (s *Server) handleToolCallResponse(msg map[string]interface{}) map[string]interface{} {
+func (s *Server) handleToolCallResponse(ctx context.Context, msg map[string]interface{}) map[string]interface{} {
 	params, ok := msg["params"].(map[string]interface{})
 	if !ok {
 		return map[string]interface{}{
@@ -224,18 +303,30 @@ func (s *Server) handleToolCallResponse(msg map[string]interface{}) map[string]i
 		}
 	}
 
+	if err := s.checkScope(ctx, name); err != nil {
+		return map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      msg["id"],
+			"error": map[string]interface{}{
+				"code":    -32602,
+				"message": err.Error(),
+			},
+		}
+	}
+
 	args, _ := params["arguments"].(map[string]interface{})
 
+	if s.queue != nil && !unqueuedTools[name] {
+		return s.queuedToolCallResponse(ctx, msg["id"], name, handler, args)
+	}
+
 	// Execute tool
-	result, err := handler(context.Background(), args)
+	result, err := handler(ctx, args)
 	if err != nil {
 		return map[string]interface{}{
 			"jsonrpc": "2.0",
 			"id":      msg["id"],
-			"error": map[string]interface{}{
-				"code":    -1,
-				"message": err.Error(),
-			},
+			"error":   s.toolError(args, err),
 		}
 	}
 
@@ -246,7 +337,7 @@ func (s *Server) handleToolCallResponse(msg map[string]interface{}) map[string]i
 			"content": []map[string]interface{}{
 				{
 					"type": "text",
-					"text": formatResult(result),
+					"text": s.renderResult(name, args, result),
 				},
 			},
 		},
@@ -269,6 +360,11 @@ func DetectTransport() Transport {
 		return NewSSETransport(port)
 	}
 
+	// gRPC for platform backends that don't speak MCP
+	if os.Getenv("SENTINEL_TRANSPORT") == "grpc" {
+		return NewGRPCTransport(os.Getenv("SENTINEL_GRPC_PORT"))
+	}
+
 	// Default to stdio
 	return NewStdioTransport()
 }
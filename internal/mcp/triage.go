@@ -0,0 +1,116 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/triage"
+)
+
+// handleTriageTestFailure handles the triage_test_failure tool (PREMIUM FEATURE). It matches a
+// captured test or build log, passed as log or read from log_file, against the known
+// environment failures. With project_root, refused ports are named after the project's services
+// and its configured fixes are used.
+func handleTriageTestFailure(ctx context.Context, server *Server, args map[string]interface{}, configs []*config.EcosystemConfig) (interface{}, error) {
+	if err := server.featureManager.RequireFeature("advanced_diagnostics"); err != nil {
+		upgradeMsg := server.featureManager.GetUpgradeMessage("advanced_diagnostics")
+		return upgradeMsg, fmt.Errorf("premium feature not available: %w", err)
+	}
+
+	projectRoot, _ := args["project_root"].(string)
+	text, _ := args["log"].(string)
+	logFile, _ := args["log_file"].(string)
+	if text == "" && logFile == "" {
+		return nil, fmt.Errorf("log or log_file is required")
+	}
+
+	var ecosystems []*detector.DetectedEcosystem
+	if projectRoot != "" {
+		var err error
+		ecosystems, err = detector.DetectEcosystems(ctx, projectRoot, configs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect ecosystems: %w", err)
+		}
+	}
+
+	var log io.Reader = strings.NewReader(text)
+	source := triage.SourceText
+	if text == "" {
+		path, err := resolveLogFile(projectRoot, logFile)
+		if err != nil {
+			return nil, err
+		}
+		f, err := common.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log_file: %w", err)
+		}
+		defer f.Close()
+		log, source = f, path
+	}
+	return triage.Triage(log, source, projectRoot, ecosystems)
+}
+
+// resolveLogFile resolves log_file against the project root and rejects files outside it,
+// symbolic links included
+func resolveLogFile(projectRoot, logFile string) (string, error) {
+	if projectRoot == "" {
+		return "", fmt.Errorf("log_file requires project_root; pass the log's content as log instead")
+	}
+	path := logFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectRoot, path)
+	}
+	outside := fmt.Errorf("log_file must be inside the project root: %s", logFile)
+	if inside, err := common.IsSubpath(projectRoot, path); err != nil || !inside {
+		return "", outside
+	}
+	root, err := common.GetFileInfo(projectRoot)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve project_root: %w", err)
+	}
+	file, err := common.GetFileInfo(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open log_file: %w", err)
+	}
+	if inside, err := common.IsSubpath(root.Resolved, file.Resolved); err != nil || !inside {
+		return "", outside
+	}
+	return path, nil
+}
+
+// formatTriageReport formats a triage_test_failure result
+func formatTriageReport(report *triage.Report) string {
+	if len(report.Matches) == 0 {
+		return fmt.Sprintf("✅ No known environment failure in %s (%d lines); the failure is likely in the code or the tests\n", report.Source, report.Lines)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "❌ %d environment failure(s) in %s (%d lines):\n", len(report.Matches), report.Source, report.Lines)
+	for i, match := range report.Matches {
+		seen := fmt.Sprintf("line %d", match.Line)
+		if match.Count > 1 {
+			seen += fmt.Sprintf(", %d lines", match.Count)
+		}
+		if match.Ecosystem != "" {
+			seen += fmt.Sprintf(", %s signature %s", match.Ecosystem, match.Signature)
+		}
+		fmt.Fprintf(&b, "\n%d. %s: %s (%s)\n", i+1, match.IssueType, match.Message, seen)
+		fmt.Fprintf(&b, "   > %s\n", match.Excerpt)
+		if match.Fix != "" {
+			fmt.Fprintf(&b, "   Fix: %s\n", match.Fix)
+		}
+		if match.Fingerprint != "" {
+			fmt.Fprintf(&b, "   Run only this fix: reconcile_issue with fingerprint %s\n", match.Fingerprint)
+		}
+		if match.Check != "" {
+			fmt.Fprintf(&b, "   Confirm on this machine: %s\n", match.Check)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"dev-env-sentinel/internal/triage"
+)
+
+func TestHandleTriageTestFailure(t *testing.T) {
+	server := NewServer()
+	args := map[string]interface{}{"log": "Error: connect ECONNREFUSED 127.0.0.1:5432"}
+	_, err := handleTriageTestFailure(context.Background(), server, args, nil)
+	assert.ErrorContains(t, err, "premium feature not available")
+
+	require.NoError(t, server.UpdateLicense(proLicenseKey(t)))
+	result, err := handleTriageTestFailure(context.Background(), server, args, nil)
+	require.NoError(t, err)
+	report := result.(*triage.Report)
+	require.Len(t, report.Matches, 1)
+	text := formatTriageReport(report)
+	assert.Contains(t, text, "1 environment failure(s) in log (1 lines)")
+	assert.Contains(t, text, "service_unavailable: Connection to postgres on port 5432 was refused")
+	assert.Contains(t, text, "> Error: connect ECONNREFUSED 127.0.0.1:5432")
+	assert.Contains(t, text, "Confirm on this machine: check_infrastructure_parity")
+
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "test.log"), []byte("Tests run: 3, Failures: 1\n"), 0644))
+	result, err = handleTriageTestFailure(context.Background(), server, map[string]interface{}{"project_root": tmpDir, "log_file": "test.log"}, nil)
+	require.NoError(t, err)
+	assert.Contains(t, formatTriageReport(result.(*triage.Report)), "No known environment failure")
+
+	_, err = handleTriageTestFailure(context.Background(), server, map[string]interface{}{}, nil)
+	assert.ErrorContains(t, err, "log or log_file is required")
+}
+
+func TestHandleTriageTestFailure_LogFileOutsideProject(t *testing.T) {
+	server := NewServer()
+	require.NoError(t, server.UpdateLicense(proLicenseKey(t)))
+
+	outside := filepath.Join(t.TempDir(), "secret.log")
+	require.NoError(t, os.WriteFile(outside, []byte("token=abc\n"), 0644))
+	projectRoot := t.TempDir()
+	require.NoError(t, os.Symlink(outside, filepath.Join(projectRoot, "linked.log")))
+
+	for _, logFile := range []string{outside, "../secret.log", "linked.log"} {
+		_, err := handleTriageTestFailure(context.Background(), server, map[string]interface{}{"project_root": projectRoot, "log_file": logFile}, nil)
+		assert.ErrorContains(t, err, "log_file must be inside the project root", logFile)
+	}
+	_, err := handleTriageTestFailure(context.Background(), server, map[string]interface{}{"log_file": outside}, nil)
+	assert.ErrorContains(t, err, "log_file requires project_root")
+}
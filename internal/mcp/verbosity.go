@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"dev-env-sentinel/internal/redact"
+)
+
+// Verbosity levels of tool output, selected per call with the verbosity argument
+const (
+	VerbositySummary  = "summary"  // Headlines and one line per finding
+	VerbosityNormal   = "normal"   // The built-in rendering, with evidence and suggestions
+	VerbosityDetailed = "detailed" // The built-in rendering and the full structured result
+)
+
+// Verbosities are the accepted values of the verbosity argument
+var Verbosities = []string{VerbositySummary, VerbosityNormal, VerbosityDetailed}
+
+// summaryHints start the unindented lines of a rendering that suggest rather than report
+var summaryHints = []string{"💡", "Fix:", "Suggestion:", "Hint:"}
+
+// ValidateVerbosity rejects an unknown verbosity level
+func ValidateVerbosity(verbosity string) error {
+	for _, v := range Verbosities {
+		if verbosity == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown verbosity: %s (expected %s)", verbosity, strings.Join(Verbosities, ", "))
+}
+
+// verbosityFor returns the verbosity a tool call asks for, normal by default
+func verbosityFor(args map[string]interface{}) string {
+	if verbosity, ok := args["verbosity"].(string); ok && verbosity != "" {
+		return verbosity
+	}
+	return VerbosityNormal
+}
+
+// applyVerbosity trims or extends the built-in rendering of a result. A summary keeps the
+// headlines and finding lines and drops the indented evidence, per-file detail and
+// suggestions under them; detailed appends the structured result with every field.
+func applyVerbosity(text, verbosity string, result interface{}) string {
+	switch verbosity {
+	case VerbositySummary:
+		return summarize(text)
+	case VerbosityDetailed:
+		if _, ok := result.(string); ok || result == nil {
+			return text
+		}
+		data, err := json.MarshalIndent(redact.Value(result), "", "  ")
+		if err != nil {
+			return text
+		}
+		return strings.TrimRight(text, "\n") + "\n\nDetails:\n" + string(data) + "\n"
+	default:
+		return text
+	}
+}
+
+// summarize drops the indented and suggestion lines of a rendering, and the blank lines
+// left behind
+func summarize(text string) string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") || isHint(line) {
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+	summary := strings.Join(lines, "\n")
+	if strings.HasSuffix(text, "\n") {
+		summary += "\n"
+	}
+	return summary
+}
+
+// isHint reports whether an unindented line suggests rather than reports
+func isHint(line string) bool {
+	for _, prefix := range summaryHints {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"dev-env-sentinel/internal/verifier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderResult_Verbosity(t *testing.T) {
+	t.Setenv("SENTINEL_LANG", "")
+	server := NewServer()
+	report := &verifier.FreshnessReport{
+		EcosystemID: "node-npm",
+		Issues: []verifier.Issue{{
+			Type:         "stale_build",
+			Severity:     "error",
+			Message:      "Build is stale",
+			FixAvailable: true,
+			FixCommand:   "npm run build",
+			Evidence: &verifier.Evidence{
+				Source: &verifier.FileEvidence{Path: "package.json"},
+			},
+		}},
+	}
+
+	normal := server.renderResult("verify_build_freshness", nil, report)
+	assert.Contains(t, normal, "  Fix: npm run build\n")
+	assert.Equal(t, normal, server.renderResult("verify_build_freshness", map[string]interface{}{"verbosity": "normal"}, report))
+
+	summary := server.renderResult("verify_build_freshness", map[string]interface{}{"verbosity": "summary"}, report)
+	assert.Equal(t, "❌ Build freshness issues found for node-npm:\n\n- error: Build is stale\n", summary)
+
+	detailed := server.renderResult("verify_build_freshness", map[string]interface{}{"verbosity": "detailed"}, report)
+	assert.Contains(t, detailed, normal[:len(normal)-1]+"\n\nDetails:\n{")
+	assert.Contains(t, detailed, `"path": "package.json"`)
+	assert.Equal(t, "done", server.renderResult("purge_state", map[string]interface{}{"verbosity": "detailed"}, "done"), "text results have no details")
+
+	server.RegisterTool("echo", func(ctx context.Context, args map[string]interface{}) (interface{}, error) { return "echo", nil })
+	_, err := server.CallTool(context.Background(), "echo", map[string]interface{}{"verbosity": "verbose"})
+	assert.EqualError(t, err, "unknown verbosity: verbose (expected summary, normal, detailed)")
+	_, err = server.CallTool(context.Background(), "echo", map[string]interface{}{"verbosity": "summary"})
+	require.NoError(t, err)
+}
+
+func TestSummarize(t *testing.T) {
+	text := "❌ Infrastructure issues found:\n\n- postgres: not running\n  Suggestion: docker compose up -d postgres\n\n💡 Run reconcile_environment to fix\n\nIssues:\n- Port 5432 is in use\n"
+	assert.Equal(t, "❌ Infrastructure issues found:\n\n- postgres: not running\n\nIssues:\n- Port 5432 is in use\n", summarize(text))
+	assert.Equal(t, "✅ healthy", summarize("✅ healthy"))
+}
@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"dev-env-sentinel/internal/buildinfo"
+	"dev-env-sentinel/internal/selfupdate"
+)
+
+// ServerVersion is the result of the get_server_version tool
+type ServerVersion struct {
+	buildinfo.Info
+	Latest          string `json:"latest,omitempty"`
+	LatestURL       string `json:"latest_url,omitempty"`
+	UpdateAvailable bool   `json:"update_available,omitempty"`
+	UpdateError     string `json:"update_error,omitempty"`
+}
+
+// handleGetServerVersion handles the get_server_version tool. With check_updates it also looks
+// up the latest release; a failed lookup is reported in the result rather than as an error.
+func handleGetServerVersion(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	result := &ServerVersion{Info: buildinfo.Get()}
+	if check, _ := args["check_updates"].(bool); !check {
+		return result, nil
+	}
+
+	release, err := selfupdate.NewUpdater().Latest(ctx)
+	if err != nil {
+		result.UpdateError = err.Error()
+		return result, nil
+	}
+	result.Latest = release.Version
+	result.LatestURL = release.URL
+	result.UpdateAvailable = selfupdate.Newer(release.Version, result.Version)
+	return result, nil
+}
+
+// formatServerVersion formats a get_server_version result
+func formatServerVersion(v *ServerVersion) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "dev-env-sentinel %s\n", v.Info)
+	if v.Date != "" {
+		fmt.Fprintf(&b, "Commit date: %s\n", v.Date)
+	}
+	switch {
+	case v.UpdateError != "":
+		fmt.Fprintf(&b, "\n⚠️  %s\n", v.UpdateError)
+	case v.UpdateAvailable:
+		fmt.Fprintf(&b, "\n⬆️  Version %s is available: %s\n", v.Latest, v.LatestURL)
+		b.WriteString("Run `sentinel self-update` or `npm install -g dev-env-sentinel@latest` to update.\n")
+	case v.Latest != "":
+		fmt.Fprintf(&b, "\n✅ Up to date (latest release: %s)\n", v.Latest)
+	}
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetServerVersion(t *testing.T) {
+	result, err := handleGetServerVersion(context.Background(), map[string]interface{}{})
+	require.NoError(t, err)
+	version := result.(*ServerVersion)
+	assert.NotEmpty(t, version.Version)
+	assert.Empty(t, version.Latest, "releases are only looked up with check_updates")
+	assert.Contains(t, formatResult(version), "dev-env-sentinel "+version.Version)
+}
+
+func TestHandleGetServerVersion_CheckUpdates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tag_name": "v99.0.0", "html_url": "https://example.com/v99.0.0"}`)
+	}))
+	defer server.Close()
+	t.Setenv("SENTINEL_UPDATE_URL", server.URL)
+
+	result, err := handleGetServerVersion(context.Background(), map[string]interface{}{"check_updates": true})
+	require.NoError(t, err)
+	version := result.(*ServerVersion)
+	assert.Equal(t, "99.0.0", version.Latest)
+	assert.True(t, version.UpdateAvailable)
+	assert.Contains(t, formatResult(version), "Version 99.0.0 is available")
+
+	// A failed lookup is part of the result
+	server.Close()
+	result, err = handleGetServerVersion(context.Background(), map[string]interface{}{"check_updates": true})
+	require.NoError(t, err)
+	assert.Contains(t, result.(*ServerVersion).UpdateError, "failed to check for updates")
+}
@@ -0,0 +1,238 @@
+package mcp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"dev-env-sentinel/internal/registry"
+	"dev-env-sentinel/internal/settings"
+)
+
+// pushJob is the job name recorded for checks run on pushes
+const pushJob = "push"
+
+// webhookMaxBody bounds push event payloads, which list the pushed commits
+const webhookMaxBody = 5 << 20
+
+// pushEvent is the part of a GitHub or GitLab push event the webhook reads
+type pushEvent struct {
+	Ref        string `json:"ref"`
+	After      string `json:"after"`
+	Deleted    bool   `json:"deleted"`
+	Repository struct {
+		CloneURL   string `json:"clone_url"`    // GitHub
+		SSHURL     string `json:"ssh_url"`      // GitHub
+		HTMLURL    string `json:"html_url"`     // GitHub
+		GitHTTPURL string `json:"git_http_url"` // GitLab
+		GitSSHURL  string `json:"git_ssh_url"`  // GitLab
+		Homepage   string `json:"homepage"`     // GitLab
+	} `json:"repository"`
+}
+
+// webhookReply is the JSON body of a webhook response
+type webhookReply struct {
+	Status  string `json:"status"` // "queued", "ignored" or "pong"
+	Reason  string `json:"reason,omitempty"`
+	JobID   string `json:"job_id,omitempty"`
+	Project string `json:"project,omitempty"`
+	Ref     string `json:"ref,omitempty"`
+	Commit  string `json:"commit,omitempty"`
+}
+
+// SetWebhooks configures the push webhook endpoint; the secret defaults to
+// $SENTINEL_WEBHOOK_SECRET
+func (s *Server) SetWebhooks(cfg settings.Webhooks) {
+	if cfg.Secret == "" {
+		cfg.Secret = os.Getenv("SENTINEL_WEBHOOK_SECRET")
+	}
+	s.webhooks = cfg
+}
+
+// handlePushWebhook accepts GitHub and GitLab push events for registered projects and queues
+// a verification run of the pushed commit. The project is selected like other scoped
+// requests, or else matched by the repository URL it was cloned from.
+func (t *SSETransport) handlePushWebhook(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if server.webhooks.Secret == "" {
+			http.Error(w, "Push webhooks are not configured; set webhooks.secret or SENTINEL_WEBHOOK_SECRET", http.StatusNotFound)
+			return
+		}
+		if server.registry == nil || server.queue == nil {
+			http.Error(w, "Push webhooks need the project registry and execution queue", http.StatusServiceUnavailable)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, webhookMaxBody))
+		if err != nil {
+			http.Error(w, "Failed to read request", http.StatusBadRequest)
+			return
+		}
+		if err := verifyWebhookSignature(server.webhooks.Secret, r.Header, body); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		switch event := r.Header.Get("X-GitHub-Event") + r.Header.Get("X-Gitlab-Event"); event {
+		case "ping":
+			writeWebhookReply(w, http.StatusOK, webhookReply{Status: "pong"})
+			return
+		case "push", "Push Hook":
+		default:
+			writeWebhookReply(w, http.StatusOK, webhookReply{Status: "ignored", Reason: fmt.Sprintf("not a push event: %q", event)})
+			return
+		}
+
+		var push pushEvent
+		if err := json.Unmarshal(body, &push); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid push event: %v", err), http.StatusBadRequest)
+			return
+		}
+		project, err := server.pushProject(r, push)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		reply, err := server.queuePushVerification(project, push)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		status := http.StatusAccepted
+		if reply.Status != "queued" {
+			status = http.StatusOK
+		}
+		writeWebhookReply(w, status, reply)
+	}
+}
+
+// pushProject returns the registered project a push event is for
+func (s *Server) pushProject(r *http.Request, push pushEvent) (registry.Project, error) {
+	id := r.Header.Get(ProjectHeader)
+	if id == "" {
+		id = r.URL.Query().Get("project")
+	}
+	if id != "" {
+		project, ok := s.registry.Get(id)
+		if !ok {
+			return registry.Project{}, fmt.Errorf("unknown project: %s", id)
+		}
+		return project, nil
+	}
+
+	repo := push.Repository
+	urls := make(map[string]bool)
+	for _, u := range []string{repo.CloneURL, repo.SSHURL, repo.HTMLURL, repo.GitHTTPURL, repo.GitSSHURL, repo.Homepage} {
+		if u != "" {
+			urls[normalizeGitURL(u)] = true
+		}
+	}
+	for _, project := range s.registry.List() {
+		if project.GitURL != "" && urls[normalizeGitURL(project.GitURL)] {
+			return project, nil
+		}
+	}
+	return registry.Project{}, fmt.Errorf("no registered project was cloned from the pushed repository; add ?project=ID to the webhook URL")
+}
+
+// queuePushVerification queues the checks of a push, unless the push deleted a branch, pushed
+// a tag or pushed a branch the settings don't verify
+func (s *Server) queuePushVerification(project registry.Project, push pushEvent) (webhookReply, error) {
+	reply := webhookReply{Project: project.ID, Ref: push.Ref, Commit: push.After}
+	branch, ok := strings.CutPrefix(push.Ref, "refs/heads/")
+	switch {
+	case !ok:
+		reply.Status, reply.Reason = "ignored", "not a branch push"
+		return reply, nil
+	case push.Deleted || strings.Trim(push.After, "0") == "":
+		reply.Status, reply.Reason = "ignored", "branch deleted"
+		return reply, nil
+	case len(s.webhooks.Branches) > 0 && !contains(s.webhooks.Branches, branch):
+		reply.Status, reply.Reason = "ignored", fmt.Sprintf("branch %s is not verified (webhooks.branches)", branch)
+		return reply, nil
+	case project.GitURL == "":
+		// Its directory can't be checked out at the pushed commit, so results wouldn't be the commit's
+		reply.Status, reply.Reason = "ignored", "the project was registered with a project_root, not cloned from a git_url"
+		return reply, nil
+	}
+
+	tenant, err := s.Project(project.ID)
+	if err != nil {
+		return reply, err
+	}
+	checks := s.webhooks.Checks
+	if len(checks) == 0 {
+		checks = defaultChecks
+	}
+	check := settings.ScheduledCheck{Name: pushJob, ProjectRoot: project.Root, Checks: checks}
+	reg, notifyDrift := s.registry, s.webhooks.Notify
+
+	job, err := tenant.startJob("verify_push", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		// Checks read the checkout, so pushes to one project are verified one at a time
+		tenant.pushMu.Lock()
+		defer tenant.pushMu.Unlock()
+		if err := reg.Update(ctx, project.ID, push.Ref); err != nil {
+			return nil, fmt.Errorf("failed to check out %s: %w", push.Ref, err)
+		}
+		return tenant.runChecks(ctx, check, notifyDrift), nil
+	}, nil)
+	if err != nil {
+		return reply, err
+	}
+	reply.Status, reply.JobID = "queued", job.ID
+	return reply, nil
+}
+
+// verifyWebhookSignature checks a GitHub signature (X-Hub-Signature-256) or GitLab token
+// (X-Gitlab-Token) against the webhook secret
+func verifyWebhookSignature(secret string, header http.Header, body []byte) error {
+	if signature := header.Get("X-Hub-Signature-256"); signature != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		if hmac.Equal([]byte("sha256="+hex.EncodeToString(mac.Sum(nil))), []byte(signature)) {
+			return nil
+		}
+	} else if token := header.Get("X-Gitlab-Token"); token != "" {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1 {
+			return nil
+		}
+	}
+	return fmt.Errorf("missing or invalid webhook signature")
+}
+
+// normalizeGitURL reduces the HTTPS, SSH and scp-like forms of a repository URL to
+// host/path, so a push event matches the URL a project was cloned from
+func normalizeGitURL(u string) string {
+	u = strings.ToLower(strings.TrimSpace(u))
+	if scheme := strings.Index(u, "://"); scheme >= 0 {
+		u = u[scheme+3:]
+	} else if colon := strings.Index(u, ":"); colon >= 0 && !strings.Contains(u[:colon], "/") {
+		// scp-like syntax: git@github.com:acme/app.git
+		u = u[:colon] + "/" + u[colon+1:]
+	}
+	if at := strings.Index(u, "@"); at >= 0 && at < strings.Index(u+"/", "/") {
+		u = u[at+1:]
+	}
+	u = strings.TrimSuffix(strings.TrimSuffix(u, "/"), ".git")
+	return u
+}
+
+// writeWebhookReply writes a webhook response
+func writeWebhookReply(w http.ResponseWriter, status int, reply webhookReply) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(reply)
+}
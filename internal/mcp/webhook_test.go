@@ -0,0 +1,135 @@
+package mcp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/queue"
+	"dev-env-sentinel/internal/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// githubPush builds a GitHub push event request signed with secret
+func githubPush(secret, target, event, body string) *http.Request {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestPushWebhook(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("SENTINEL_WEBHOOK_SECRET", "")
+
+	origin := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"symbolic-ref", "HEAD", "refs/heads/main"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = origin
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	server, _ := newRegistryServer(t)
+	q := queue.New(1, 10)
+	defer q.Close()
+	server.SetQueue(q)
+	_, err := server.CallTool(context.Background(), "register_project", map[string]interface{}{"id": "api", "git_url": "file://" + filepath.ToSlash(origin)})
+	require.NoError(t, err)
+	_, err = server.CallTool(context.Background(), "register_project", map[string]interface{}{"id": "web", "project_root": t.TempDir()})
+	require.NoError(t, err)
+	handler := NewSSETransport("").handlePushWebhook(server)
+
+	send := func(req *http.Request) (*httptest.ResponseRecorder, webhookReply) {
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		var reply webhookReply
+		json.Unmarshal(rec.Body.Bytes(), &reply)
+		return rec, reply
+	}
+	push := `{"ref": "refs/heads/main", "after": "4f0f8b7c", "repository": {"clone_url": "https://github.com/acme/api.git"}}`
+
+	rec, _ := send(githubPush("secret", "/webhook/push?project=api", "push", push))
+	assert.Equal(t, http.StatusNotFound, rec.Code, "off without a secret")
+
+	server.SetWebhooks(settings.Webhooks{Secret: "secret", Checks: []string{"env_var_audit"}, Branches: []string{"main"}})
+	rec, _ = send(githubPush("wrong", "/webhook/push?project=api", "push", push))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	_, reply := send(githubPush("secret", "/webhook/push?project=api", "ping", `{}`))
+	assert.Equal(t, "pong", reply.Status)
+	rec, _ = send(githubPush("secret", "/webhook/push", "push", push))
+	assert.Equal(t, http.StatusNotFound, rec.Code, "the project was cloned from another URL")
+
+	rec, reply = send(githubPush("secret", "/webhook/push?project=api", "push", push))
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	assert.Equal(t, "queued", reply.Status)
+	assert.Equal(t, "4f0f8b7c", reply.Commit)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	job, err := q.Wait(ctx, reply.JobID)
+	require.NoError(t, err)
+	assert.Empty(t, job.Error)
+	api, err := server.Project("api")
+	require.NoError(t, err)
+	_, ok := api.snapshots.Get(pushJob, "env_var_audit")
+	assert.True(t, ok, "the result is recorded in the project's history")
+
+	for body, reason := range map[string]string{
+		`{"ref": "refs/heads/feature", "after": "4f0f8b7c"}`:                 "branch feature is not verified",
+		`{"ref": "refs/tags/v1.0", "after": "4f0f8b7c"}`:                     "not a branch push",
+		`{"ref": "refs/heads/main", "after": "0000000000", "deleted": true}`: "branch deleted",
+	} {
+		rec, reply = send(githubPush("secret", "/webhook/push?project=api", "push", body))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "ignored", reply.Status)
+		assert.Contains(t, reply.Reason, reason)
+	}
+
+	// A directory registered with project_root can't be checked out at the pushed commit
+	rec, reply = send(githubPush("secret", "/webhook/push?project=web", "push", push))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ignored", reply.Status)
+	assert.Contains(t, reply.Reason, "registered with a project_root")
+
+	// GitLab authenticates with the secret token itself
+	req := httptest.NewRequest(http.MethodPost, "/webhook/push?project=api", strings.NewReader(push))
+	req.Header.Set("X-Gitlab-Event", "Push Hook")
+	req.Header.Set("X-Gitlab-Token", "secret")
+	rec, reply = send(req)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	_, err = q.Wait(ctx, reply.JobID)
+	require.NoError(t, err)
+}
+
+func TestNormalizeGitURL(t *testing.T) {
+	for _, u := range []string{
+		"https://github.com/Acme/API.git",
+		"git@github.com:acme/api.git",
+		"ssh://git@github.com/acme/api",
+		"https://github.com/acme/api/",
+	} {
+		assert.Equal(t, "github.com/acme/api", normalizeGitURL(u), u)
+	}
+}
@@ -0,0 +1,226 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Issue types reported by the mirror check
+const (
+	IssueUnreachable  = "mirror_unreachable"
+	IssueSlow         = "mirror_slow"
+	IssueUnauthorized = "mirror_unauthorized"
+)
+
+const (
+	// probeTimeout bounds each probe; package managers wait far longer before giving up
+	probeTimeout = 5 * time.Second
+	// slowThreshold is the latency above which a mirror is reported as slow
+	slowThreshold = 2 * time.Second
+)
+
+// probePaths are known artifacts requested from each kind of repository
+var probePaths = map[string]string{
+	"maven":  "org/apache/maven/plugins/maven-clean-plugin/maven-metadata.xml",
+	"gradle": "org/apache/maven/plugins/maven-clean-plugin/maven-metadata.xml",
+	"npm":    "npm",
+}
+
+// Mirror is a declared artifact repository and its probe result
+type Mirror struct {
+	Ecosystem string // "maven", "gradle" or "npm"
+	ID        string // Mirror/repository id or npm scope, if any
+	URL       string // Repository URL with credentials removed
+	Source    string // File that declares the mirror
+	Reachable bool
+	Status    int // HTTP status of the probe, 0 if no response
+	Latency   time.Duration
+	Error     string
+
+	url string // Repository URL as declared, used for probing
+}
+
+// Issue is a mirror problem with an optional fix
+type Issue struct {
+	Type       string
+	File       string
+	Message    string
+	FixCommand string
+}
+
+// MirrorReport contains mirror reachability results
+type MirrorReport struct {
+	Mirrors   []Mirror
+	IsHealthy bool
+	Issues    []Issue
+}
+
+// CheckMirrors finds the Maven, Gradle and npm mirrors and registries declared by the
+// project and user configuration, and probes each one for reachability and latency
+func CheckMirrors(ctx context.Context, projectRoot string) (*MirrorReport, error) {
+	report := &MirrorReport{
+		Mirrors:   []Mirror{},
+		IsHealthy: true,
+		Issues:    []Issue{},
+	}
+
+	home, _ := os.UserHomeDir()
+	mirrors, err := discover(projectRoot, home)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{
+		Timeout: probeTimeout,
+		// A redirect is a response; probing where it leads would hide the mirror's own latency
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	var wg sync.WaitGroup
+	for i := range mirrors {
+		wg.Add(1)
+		go func(m *Mirror) {
+			defer wg.Done()
+			probe(ctx, client, m)
+		}(&mirrors[i])
+	}
+	wg.Wait()
+
+	for _, m := range mirrors {
+		report.Mirrors = append(report.Mirrors, m)
+		if issue, ok := evaluate(m); ok {
+			report.Issues = append(report.Issues, issue)
+			report.IsHealthy = false
+		}
+	}
+	return report, nil
+}
+
+// discover collects the declared mirrors, deduplicated by ecosystem and URL
+func discover(projectRoot, home string) ([]Mirror, error) {
+	var all []Mirror
+	for _, find := range []func(string, string) ([]Mirror, error){mavenMirrors, gradleMirrors, npmMirrors} {
+		found, err := find(projectRoot, home)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, found...)
+	}
+
+	seen := make(map[string]bool)
+	var mirrors []Mirror
+	for _, m := range all {
+		u, err := url.Parse(m.url)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			continue
+		}
+		u.User = nil
+		m.URL = u.String()
+		key := m.Ecosystem + " " + strings.TrimSuffix(m.URL, "/")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		mirrors = append(mirrors, m)
+	}
+
+	sort.SliceStable(mirrors, func(i, j int) bool { return mirrors[i].Ecosystem < mirrors[j].Ecosystem })
+	return mirrors, nil
+}
+
+// probe requests a known artifact from a mirror and records the outcome
+func probe(ctx context.Context, client *http.Client, m *Mirror) {
+	target := strings.TrimSuffix(m.url, "/") + "/" + probePaths[m.Ecosystem]
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, target, nil)
+	if err != nil {
+		m.Error = err.Error()
+		return
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	m.Latency = time.Since(start)
+	if err != nil {
+		m.Error = probeError(err)
+		return
+	}
+	resp.Body.Close()
+
+	m.Status = resp.StatusCode
+	m.Reachable = resp.StatusCode < http.StatusInternalServerError
+	if !m.Reachable {
+		m.Error = resp.Status
+	}
+}
+
+// probeError shortens transport errors to their cause
+func probeError(err error) string {
+	if urlErr, ok := err.(*url.Error); ok {
+		if urlErr.Timeout() {
+			return fmt.Sprintf("no response within %s", probeTimeout)
+		}
+		return urlErr.Err.Error()
+	}
+	return err.Error()
+}
+
+// evaluate turns a probe result into an issue, if there is one
+func evaluate(m Mirror) (Issue, bool) {
+	name := m.URL
+	if m.ID != "" {
+		name = fmt.Sprintf("%s (%s)", m.ID, m.URL)
+	}
+
+	switch {
+	case !m.Reachable:
+		return Issue{
+			Type:       IssueUnreachable,
+			File:       m.Source,
+			Message:    fmt.Sprintf("%s mirror %s is unreachable: %s; builds will hang until the connection times out. %s", m.Ecosystem, name, m.Error, fallbackAdvice(m)),
+			FixCommand: fallbackCommand(m),
+		}, true
+	case m.Status == http.StatusUnauthorized || m.Status == http.StatusForbidden:
+		return Issue{
+			Type:    IssueUnauthorized,
+			File:    m.Source,
+			Message: fmt.Sprintf("%s mirror %s rejected the request (%d); check the credentials configured for it", m.Ecosystem, name, m.Status),
+		}, true
+	case m.Latency > slowThreshold:
+		return Issue{
+			Type:    IssueSlow,
+			File:    m.Source,
+			Message: fmt.Sprintf("%s mirror %s is slow (%s to respond); check VPN or proxy settings", m.Ecosystem, name, m.Latency.Round(time.Millisecond)),
+		}, true
+	}
+	return Issue{}, false
+}
+
+// fallbackAdvice describes how to build without an unreachable mirror
+func fallbackAdvice(m Mirror) string {
+	switch m.Ecosystem {
+	case "maven":
+		return "Connect to the VPN, or fall back to Maven Central by removing the mirror from settings.xml; 'mvn -o' builds offline from the local repository."
+	case "gradle":
+		return "Connect to the VPN, or add mavenCentral() ahead of it as a fallback; 'gradle --offline' builds from the dependency cache."
+	default:
+		if m.ID != "" {
+			return fmt.Sprintf("Packages in scope %s cannot be installed until it is reachable.", m.ID)
+		}
+		return "Connect to the VPN, or fall back to the public registry."
+	}
+}
+
+// fallbackCommand returns a command that switches to the public repository, if one applies
+func fallbackCommand(m Mirror) string {
+	if m.Ecosystem == "npm" && m.ID == "" {
+		return "npm config set registry https://registry.npmjs.org/ --location=project"
+	}
+	return ""
+}
@@ -0,0 +1,227 @@
+package mirror
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+	"gopkg.in/yaml.v3"
+)
+
+// mavenSettings is the part of settings.xml that declares mirrors and repositories
+type mavenSettings struct {
+	Mirrors []struct {
+		ID  string `xml:"id"`
+		URL string `xml:"url"`
+	} `xml:"mirrors>mirror"`
+	Profiles []mavenRepositories `xml:"profiles>profile"`
+}
+
+// mavenRepositories holds the repositories of a pom.xml or settings.xml profile
+type mavenRepositories struct {
+	Repositories       []mavenRepository `xml:"repositories>repository"`
+	PluginRepositories []mavenRepository `xml:"pluginRepositories>pluginRepository"`
+}
+
+type mavenRepository struct {
+	ID  string `xml:"id"`
+	URL string `xml:"url"`
+}
+
+// mavenPOM is the part of pom.xml that declares repositories
+type mavenPOM struct {
+	mavenRepositories
+	Profiles []mavenRepositories `xml:"profiles>profile"`
+}
+
+// gradleRepositoryURL matches repository URLs such as `url = uri("https://...")`,
+// `url "https://..."` and `setUrl("https://...")`. Interpolated URLs are skipped.
+var gradleRepositoryURL = regexp.MustCompile(`(?:\burl|setUrl)\s*(?:=\s*)?\(?\s*(?:uri\(\s*)?["'](https?://[^"'$]+)["']`)
+
+// mavenMirrors reads mirrors from the user and project settings.xml and repositories from pom.xml
+func mavenMirrors(projectRoot, home string) ([]Mirror, error) {
+	var mirrors []Mirror
+
+	var settingsFiles []string
+	if home != "" {
+		settingsFiles = append(settingsFiles, filepath.Join(home, ".m2", "settings.xml"))
+	}
+	settingsFiles = append(settingsFiles, filepath.Join(projectRoot, ".mvn", "settings.xml"))
+
+	for _, file := range settingsFiles {
+		var settings mavenSettings
+		found, err := readXML(file, &settings)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		for _, m := range settings.Mirrors {
+			mirrors = append(mirrors, Mirror{Ecosystem: "maven", ID: m.ID, Source: file, url: strings.TrimSpace(m.URL)})
+		}
+		for _, profile := range settings.Profiles {
+			mirrors = append(mirrors, profile.mirrors(file)...)
+		}
+	}
+
+	pomFile := filepath.Join(projectRoot, "pom.xml")
+	var pom mavenPOM
+	found, err := readXML(pomFile, &pom)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		mirrors = append(mirrors, pom.mirrors(pomFile)...)
+		for _, profile := range pom.Profiles {
+			mirrors = append(mirrors, profile.mirrors(pomFile)...)
+		}
+	}
+	return mirrors, nil
+}
+
+// mirrors converts the declared repositories into mirrors
+func (r mavenRepositories) mirrors(source string) []Mirror {
+	var mirrors []Mirror
+	for _, repo := range append(r.Repositories, r.PluginRepositories...) {
+		mirrors = append(mirrors, Mirror{Ecosystem: "maven", ID: repo.ID, Source: source, url: strings.TrimSpace(repo.URL)})
+	}
+	return mirrors
+}
+
+// readXML decodes an XML file, reporting false if it does not exist
+func readXML(file string, v interface{}) (bool, error) {
+	content, err := common.ReadFile(file)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if err := xml.Unmarshal(content, v); err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	return true, nil
+}
+
+// gradleMirrors reads repository URLs from the project build scripts and the user's init scripts
+func gradleMirrors(projectRoot, home string) ([]Mirror, error) {
+	var files []string
+	for _, name := range []string{"settings.gradle", "settings.gradle.kts", "build.gradle", "build.gradle.kts"} {
+		files = append(files, filepath.Join(projectRoot, name))
+	}
+
+	gradleHome := os.Getenv("GRADLE_USER_HOME")
+	if gradleHome == "" && home != "" {
+		gradleHome = filepath.Join(home, ".gradle")
+	}
+	if gradleHome != "" {
+		files = append(files, filepath.Join(gradleHome, "init.gradle"), filepath.Join(gradleHome, "init.gradle.kts"))
+		initScripts, _ := filepath.Glob(filepath.Join(gradleHome, "init.d", "*.gradle*"))
+		files = append(files, initScripts...)
+	}
+
+	var mirrors []Mirror
+	for _, file := range files {
+		content, err := common.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		for _, m := range gradleRepositoryURL.FindAllStringSubmatch(string(content), -1) {
+			mirrors = append(mirrors, Mirror{Ecosystem: "gradle", Source: file, url: m[1]})
+		}
+	}
+	return mirrors, nil
+}
+
+// npmMirrors reads registries from the project and user .npmrc and the project .yarnrc.yml
+func npmMirrors(projectRoot, home string) ([]Mirror, error) {
+	files := []string{filepath.Join(projectRoot, ".npmrc")}
+	if home != "" {
+		files = append(files, filepath.Join(home, ".npmrc"))
+	}
+
+	var mirrors []Mirror
+	for _, file := range files {
+		found, err := readNpmrc(file)
+		if err != nil {
+			return nil, err
+		}
+		mirrors = append(mirrors, found...)
+	}
+
+	yarnrc := filepath.Join(projectRoot, ".yarnrc.yml")
+	content, err := common.ReadFile(yarnrc)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", yarnrc, err)
+	}
+	if err == nil {
+		var config struct {
+			NpmRegistryServer string `yaml:"npmRegistryServer"`
+			NpmScopes         map[string]struct {
+				NpmRegistryServer string `yaml:"npmRegistryServer"`
+			} `yaml:"npmScopes"`
+		}
+		if err := yaml.Unmarshal(content, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", yarnrc, err)
+		}
+		if config.NpmRegistryServer != "" {
+			mirrors = append(mirrors, Mirror{Ecosystem: "npm", Source: yarnrc, url: config.NpmRegistryServer})
+		}
+		scopes := make([]string, 0, len(config.NpmScopes))
+		for scope := range config.NpmScopes {
+			scopes = append(scopes, scope)
+		}
+		sort.Strings(scopes)
+		for _, scope := range scopes {
+			if server := config.NpmScopes[scope].NpmRegistryServer; server != "" {
+				mirrors = append(mirrors, Mirror{Ecosystem: "npm", ID: "@" + scope, Source: yarnrc, url: server})
+			}
+		}
+	}
+	return mirrors, nil
+}
+
+// readNpmrc reads `registry=` and `@scope:registry=` entries, expanding ${VAR} references as npm does
+func readNpmrc(file string) ([]Mirror, error) {
+	f, err := common.Open(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	defer f.Close()
+
+	var mirrors []Mirror
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = os.ExpandEnv(strings.Trim(strings.TrimSpace(value), `"'`))
+
+		switch {
+		case key == "registry":
+			mirrors = append(mirrors, Mirror{Ecosystem: "npm", Source: file, url: value})
+		case strings.HasPrefix(key, "@") && strings.HasSuffix(key, ":registry"):
+			mirrors = append(mirrors, Mirror{Ecosystem: "npm", ID: strings.TrimSuffix(key, ":registry"), Source: file, url: value})
+		}
+	}
+	return mirrors, scanner.Err()
+}
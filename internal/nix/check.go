@@ -0,0 +1,154 @@
+package nix
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"dev-env-sentinel/internal/version"
+)
+
+// Issue types reported by the Nix environment check
+const (
+	IssueShellInactive   = "nix_shell_inactive"     // The sentinel doesn't run inside the declared dev shell
+	IssueVersionMismatch = "nix_version_mismatch"   // The active tool isn't the version the environment declares
+	IssueToolMissing     = "nix_tool_missing"       // A declared tool isn't on the PATH
+	IssueOutsideStore    = "nix_tool_outside_store" // Inside the dev shell, a tool resolves outside the Nix store
+)
+
+// storeDir is where Nix installs packages
+const storeDir = "/nix/store/"
+
+// Tool is a tool the environment declares, with the one active on the PATH
+type Tool struct {
+	Package
+	Active string // Version on the PATH; empty when missing
+	Path   string // Resolved executable
+}
+
+// Issue is a divergence between the declared Nix environment and the active one
+type Issue struct {
+	Type       string
+	Severity   string // "error" or "warning"
+	Tool       string
+	Message    string
+	Suggestion string
+	File       string
+	Line       int
+}
+
+// Report contains the Nix environment check results
+type Report struct {
+	ProjectRoot string
+	Kind        string // Empty when the project declares no Nix environment
+	Files       []string
+	Active      bool
+	ActiveVia   string // How the dev shell was entered
+	Activation  []string
+	Tools       []Tool
+	IsHealthy   bool
+	Issues      []Issue
+}
+
+// activeVersion and resolve are replaced by tests
+var (
+	activeVersion = version.DetectToolVersion
+	resolve       = func(binary string) (string, error) {
+		path, err := exec.LookPath(binary)
+		if err != nil {
+			return "", err
+		}
+		if real, err := filepath.EvalSymlinks(path); err == nil {
+			return real, nil
+		}
+		return path, nil
+	}
+)
+
+// CheckEnvironment compares the tools a project's Nix environment declares with the ones on
+// the PATH, and whether the sentinel runs inside the dev shell at all
+func CheckEnvironment(ctx context.Context, projectRoot string) (*Report, error) {
+	report := &Report{ProjectRoot: projectRoot, Files: []string{}, Tools: []Tool{}, IsHealthy: true, Issues: []Issue{}}
+	env := Detect(projectRoot)
+	if env == nil {
+		return report, nil
+	}
+	report.Kind, report.Files, report.Activation = env.Kind, env.Files, env.Activation
+	report.Active, report.ActiveVia = env.Active()
+
+	packages, err := env.Packages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the Nix environment: %w", err)
+	}
+
+	hint := env.Hint()
+	if !report.Active {
+		report.add(Issue{
+			Type:       IssueShellInactive,
+			Severity:   "warning",
+			Message:    fmt.Sprintf("The project declares its toolchain in %s, but the sentinel doesn't run inside that dev shell, so tools come from the system PATH", strings.Join(env.Files, ", ")),
+			Suggestion: hint + ", then restart the editor from that shell",
+		})
+	}
+
+	for _, pkg := range packages {
+		tool := Tool{Package: pkg}
+		if v, err := activeVersion(ctx, pkg.Tool); err == nil {
+			tool.Active = v
+			if pkg.Tool == "java" && strings.HasPrefix(v, "1.") {
+				tool.Active = strings.TrimPrefix(v, "1.") // 1.8.0 is jdk8
+			}
+		}
+		if tool.Active != "" {
+			tool.Path, _ = resolve(version.ToolBinary(pkg.Tool))
+		}
+		report.Tools = append(report.Tools, tool)
+		report.compare(tool, hint)
+	}
+	return report, nil
+}
+
+// compare records the divergences of one declared tool; outside the dev shell they are what
+// entering it fixes, inside it they mean the PATH or the declaration is off
+func (r *Report) compare(tool Tool, hint string) {
+	where := fmt.Sprintf("%s:%d", tool.File, tool.Line)
+	issue := Issue{Tool: tool.Tool, Severity: "error", File: tool.File, Line: tool.Line, Suggestion: hint}
+	if r.Active {
+		issue.Suggestion = fmt.Sprintf("Check that nothing earlier on the PATH shadows the dev shell's %s, or change %s", tool.Tool, where)
+	}
+
+	switch {
+	case tool.Active == "":
+		issue.Type = IssueToolMissing
+		issue.Message = fmt.Sprintf("%s declares %s (%s) but it isn't on the PATH", where, tool.Tool, tool.Attr)
+	case tool.Version != "" && !sameVersion(tool.Version, tool.Active):
+		issue.Type = IssueVersionMismatch
+		issue.Message = fmt.Sprintf("%s declares %s %s (%s) but %s %s is active", where, tool.Tool, tool.Version, tool.Attr, tool.Tool, tool.Active)
+	case r.Active && tool.Path != "" && !strings.HasPrefix(tool.Path, storeDir):
+		issue.Type, issue.Severity = IssueOutsideStore, "warning"
+		issue.Message = fmt.Sprintf("Inside the dev shell, %s resolves to %s instead of the Nix store", tool.Tool, tool.Path)
+	default:
+		return
+	}
+	r.add(issue)
+}
+
+// add records an issue and marks the report unhealthy
+func (r *Report) add(issue Issue) {
+	r.Issues = append(r.Issues, issue)
+	r.IsHealthy = false
+}
+
+// sameVersion reports whether an active version matches a declared one in the parts the
+// declared one gives, so 20.11.0 matches 20 and 3.11.6 matches 3.11
+func sameVersion(declared, active string) bool {
+	activeParts := strings.Split(active, ".")
+	for i, part := range strings.Split(declared, ".") {
+		if i >= len(activeParts) || part != activeParts[i] {
+			return false
+		}
+	}
+	return true
+}
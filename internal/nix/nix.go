@@ -0,0 +1,109 @@
+// Package nix recognizes projects whose toolchain a Nix flake, shell.nix or devenv declares,
+// so the sentinel checks the environment against that declaration and suggests entering the
+// dev shell rather than installing or switching tools by hand.
+package nix
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+)
+
+// Kinds of Nix environments, by the file that declares them
+const (
+	KindDevenv = "devenv" // devenv.nix, entered with devenv shell
+	KindFlake  = "flake"  // flake.nix, entered with nix develop
+	KindShell  = "shell"  // shell.nix, entered with nix-shell
+)
+
+// kindFiles are the files that declare each kind of environment, in order of precedence
+var kindFiles = []struct {
+	kind  string
+	files []string
+	enter string
+}{
+	{KindDevenv, []string{"devenv.nix", "devenv.yaml"}, "devenv shell"},
+	{KindFlake, []string{"flake.nix"}, "nix develop"},
+	{KindShell, []string{"shell.nix"}, "nix-shell"},
+}
+
+// envrcNix matches the direnv stdlib functions that load a Nix environment
+var envrcNix = regexp.MustCompile(`(?m)^\s*(use (?:flake|nix|devenv)|use_flake|use_nix)\b`)
+
+// Environment is the Nix environment a project declares
+type Environment struct {
+	ProjectRoot string
+	Kind        string
+	Files       []string // Declaring files, relative to the project root
+	Direnv      bool     // .envrc loads the environment
+	Activation  []string // Commands that enter the dev shell, the preferred first
+}
+
+// Detect returns the Nix environment a project declares, or nil when it declares none
+func Detect(projectRoot string) *Environment {
+	var env *Environment
+	for _, kind := range kindFiles {
+		for _, name := range kind.files {
+			if !common.FileExists(filepath.Join(projectRoot, name)) {
+				continue
+			}
+			if env == nil {
+				env = &Environment{ProjectRoot: projectRoot, Kind: kind.kind, Activation: []string{kind.enter}}
+			}
+			env.Files = append(env.Files, name)
+		}
+	}
+	if env == nil {
+		return nil
+	}
+
+	if data, err := common.ReadFile(filepath.Join(projectRoot, ".envrc")); err == nil && envrcNix.Match(data) {
+		env.Direnv = true
+		env.Activation = append([]string{"direnv allow"}, env.Activation...)
+	} else {
+		env.Activation = append(env.Activation, fmt.Sprintf("echo '%s' >> .envrc && direnv allow", envrcLine[env.Kind]))
+	}
+	return env
+}
+
+// envrcLine is the .envrc line that loads each kind of environment with direnv
+var envrcLine = map[string]string{
+	KindDevenv: "use devenv",
+	KindFlake:  "use flake",
+	KindShell:  "use nix",
+}
+
+// Active reports whether the sentinel runs inside the project's dev shell, and how it was
+// entered. The sentinel sees the environment its client started it with, so a shell entered
+// in a terminal doesn't count for an editor started outside it.
+func (e *Environment) Active() (bool, string) {
+	if root := os.Getenv("DEVENV_ROOT"); root != "" && within(e.ProjectRoot, root) {
+		return true, "devenv shell"
+	}
+	if dir := os.Getenv("DIRENV_DIR"); e.Direnv && dir != "" && within(e.ProjectRoot, strings.TrimPrefix(dir, "-")) {
+		return true, "direnv"
+	}
+	if shell := os.Getenv("IN_NIX_SHELL"); shell != "" {
+		return true, fmt.Sprintf("nix shell (IN_NIX_SHELL=%s)", shell)
+	}
+	return false, ""
+}
+
+// Hint tells how to enter the dev shell
+func (e *Environment) Hint() string {
+	hint := fmt.Sprintf("Enter the dev shell %s declares: %s", strings.Join(e.Files, ", "), e.Activation[0])
+	if len(e.Activation) > 1 {
+		hint += fmt.Sprintf(" (or %s)", strings.Join(e.Activation[1:], ", or "))
+	}
+	return hint
+}
+
+// within reports whether a project root is the directory of a shell or inside it
+func within(projectRoot, dir string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(projectRoot))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
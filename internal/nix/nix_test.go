@@ -0,0 +1,213 @@
+package nix
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// outsideShell clears the variables a dev shell sets
+func outsideShell(t *testing.T) {
+	for _, name := range []string{"IN_NIX_SHELL", "DEVENV_ROOT", "DIRENV_DIR"} {
+		t.Setenv(name, "")
+	}
+}
+
+const flake = `{
+  inputs.nixpkgs.url = "github:NixOS/nixpkgs/nixos-24.05";
+  outputs = { self, nixpkgs }: let pkgs = nixpkgs.legacyPackages.x86_64-linux; in {
+    devShells.x86_64-linux.default = pkgs.mkShell {
+      # pkgs.nodejs_18 was the previous version
+      packages = with pkgs; [
+        nodejs_20
+        pnpm
+        jdk17
+        maven
+      ];
+      buildInputs = [ pkgs.python311 ];
+    };
+  };
+}
+`
+
+func TestDetect(t *testing.T) {
+	outsideShell(t)
+	root := t.TempDir()
+	assert.Nil(t, Detect(root))
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "flake.nix"), []byte(flake), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "shell.nix"), []byte("{ }"), 0644))
+	env := Detect(root)
+	require.NotNil(t, env)
+	assert.Equal(t, KindFlake, env.Kind)
+	assert.Equal(t, []string{"flake.nix", "shell.nix"}, env.Files)
+	assert.False(t, env.Direnv)
+	assert.Equal(t, []string{"nix develop", "echo 'use flake' >> .envrc && direnv allow"}, env.Activation)
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".envrc"), []byte("dotenv_if_exists\nuse flake\n"), 0644))
+	env = Detect(root)
+	assert.True(t, env.Direnv)
+	assert.Equal(t, []string{"direnv allow", "nix develop"}, env.Activation)
+	assert.Equal(t, "Enter the dev shell flake.nix, shell.nix declares: direnv allow (or nix develop)", env.Hint())
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "devenv.nix"), []byte("{ }"), 0644))
+	env = Detect(root)
+	assert.Equal(t, KindDevenv, env.Kind)
+	assert.Equal(t, "direnv allow", env.Activation[0])
+	assert.Equal(t, "devenv shell", env.Activation[1])
+}
+
+func TestActive(t *testing.T) {
+	outsideShell(t)
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "devenv.nix"), []byte("{ }"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".envrc"), []byte("use devenv\n"), 0644))
+	env := Detect(root)
+
+	active, _ := env.Active()
+	assert.False(t, active)
+
+	t.Setenv("DEVENV_ROOT", t.TempDir())
+	active, _ = env.Active()
+	assert.False(t, active, "the devenv shell of another project")
+
+	t.Setenv("DIRENV_DIR", "-"+root)
+	active, via := env.Active()
+	assert.True(t, active)
+	assert.Equal(t, "direnv", via)
+
+	t.Setenv("DEVENV_ROOT", root)
+	_, via = env.Active()
+	assert.Equal(t, "devenv shell", via)
+}
+
+func TestPackages(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "flake.nix"), []byte(flake), 0644))
+	packages, err := Detect(root).Packages()
+	require.NoError(t, err)
+
+	var got []string
+	for _, pkg := range packages {
+		got = append(got, fmt.Sprintf("%s %s %s:%d", pkg.Tool, pkg.Version, pkg.File, pkg.Line))
+	}
+	assert.Equal(t, []string{
+		"python 3.11 flake.nix:12",
+		"node 20 flake.nix:7",
+		"pnpm  flake.nix:8",
+		"java 17 flake.nix:9",
+		"maven  flake.nix:10",
+	}, got)
+}
+
+func TestPackages_Devenv(t *testing.T) {
+	root := t.TempDir()
+	devenv := `{ pkgs, ... }: {
+  languages.javascript.enable = true;
+  languages.javascript.package = pkgs.nodejs_18;
+  languages.python.enable = true;
+  languages.python.version = "3.12";
+  packages = [ pkgs.git ];
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(root, "devenv.nix"), []byte(devenv), 0644))
+	packages, err := Detect(root).Packages()
+	require.NoError(t, err)
+	require.Len(t, packages, 2)
+	assert.Equal(t, "node", packages[0].Tool)
+	assert.Equal(t, "18", packages[0].Version)
+	assert.Equal(t, "python", packages[1].Tool)
+	assert.Equal(t, "3.12", packages[1].Version)
+	assert.Equal(t, 5, packages[1].Line)
+}
+
+func TestPackageTool(t *testing.T) {
+	tests := map[string]string{
+		"nodejs":          "node ",
+		"nodejs-18_x":     "node 18",
+		"nodejs_22":       "node 22",
+		"jdk":             "java ",
+		"openjdk21":       "java 21",
+		"temurin-bin-17":  "java 17",
+		"jdk17_headless":  "java 17",
+		"python3":         "python 3",
+		"python312":       "python 3.12",
+		"go_1_22":         "go 1.22",
+		"ruby_3_3":        "ruby 3.3",
+		"php83":           "php 8.3",
+		"dotnet-sdk_8":    "dotnet 8",
+		"gradle_8":        "gradle 8",
+		"python3Packages": "",
+		"git":             "",
+	}
+	for attr, want := range tests {
+		pkg, ok := packageTool(attr)
+		got := ""
+		if ok {
+			got = pkg.Tool + " " + pkg.Version
+		}
+		assert.Equal(t, want, got, attr)
+	}
+}
+
+func TestCheckEnvironment(t *testing.T) {
+	outsideShell(t)
+	root := t.TempDir()
+	ctx := context.Background()
+
+	report, err := CheckEnvironment(ctx, root)
+	require.NoError(t, err)
+	assert.True(t, report.IsHealthy)
+	assert.Empty(t, report.Kind)
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "flake.nix"), []byte(flake), 0644))
+	defer func(f func(context.Context, string) (string, error)) { activeVersion = f }(activeVersion)
+	defer func(f func(string) (string, error)) { resolve = f }(resolve)
+	active := map[string]string{"node": "18.19.0", "pnpm": "8.15.1", "java": "1.8.0", "python": "3.11.6"}
+	activeVersion = func(ctx context.Context, tool string) (string, error) {
+		if v, ok := active[tool]; ok {
+			return v, nil
+		}
+		return "", fmt.Errorf("%s: command not found", tool)
+	}
+	resolve = func(binary string) (string, error) { return "/usr/bin/" + binary, nil }
+
+	report, err = CheckEnvironment(ctx, root)
+	require.NoError(t, err)
+	assert.False(t, report.IsHealthy)
+	assert.False(t, report.Active)
+	types := make(map[string][]string)
+	for _, issue := range report.Issues {
+		types[issue.Type] = append(types[issue.Type], issue.Tool)
+		if issue.Type != IssueShellInactive {
+			assert.Equal(t, "Enter the dev shell flake.nix declares: nix develop (or echo 'use flake' >> .envrc && direnv allow)", issue.Suggestion)
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		IssueShellInactive:   {""},
+		IssueVersionMismatch: {"node", "java"},
+		IssueToolMissing:     {"maven"},
+	}, types)
+
+	// Inside the shell, the same tools resolving outside the store are shadowed
+	t.Setenv("IN_NIX_SHELL", "impure")
+	active = map[string]string{"node": "20.11.1", "pnpm": "8.15.1", "java": "17.0.10", "python": "3.11.6", "maven": "3.9.6"}
+	report, err = CheckEnvironment(ctx, root)
+	require.NoError(t, err)
+	assert.True(t, report.Active)
+	assert.Len(t, report.Issues, 5)
+	for _, issue := range report.Issues {
+		assert.Equal(t, IssueOutsideStore, issue.Type)
+		assert.Equal(t, "warning", issue.Severity)
+	}
+
+	resolve = func(binary string) (string, error) { return storeDir + "abc-" + binary + "/bin/" + binary, nil }
+	report, err = CheckEnvironment(ctx, root)
+	require.NoError(t, err)
+	assert.True(t, report.IsHealthy)
+}
@@ -0,0 +1,151 @@
+package nix
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+)
+
+// Package is a tool the Nix environment puts in the dev shell
+type Package struct {
+	Tool    string // e.g. "node", "java"
+	Version string // As the attribute pins it, e.g. "20" of nodejs_20; empty when unpinned
+	Attr    string // The nixpkgs attribute, e.g. "nodejs_20", or the devenv option
+	File    string
+	Line    int
+}
+
+// packageAttrs map nixpkgs attributes to the tool they provide; the groups of a match, joined
+// with dots, are the version
+var packageAttrs = []struct {
+	tool string
+	re   *regexp.Regexp
+}{
+	{"node", regexp.MustCompile(`^nodejs(?:-slim)?(?:[-_](\d+)(?:_x)?)?$`)},
+	{"java", regexp.MustCompile(`^(?:jdk|openjdk|temurin-bin-?|zulu|corretto)(\d+)?(?:_headless)?$`)},
+	{"python", regexp.MustCompile(`^python(\d)(\d+)?$`)},
+	{"go", regexp.MustCompile(`^go(?:_(\d+)_(\d+))?$`)},
+	{"maven", regexp.MustCompile(`^maven$`)},
+	{"gradle", regexp.MustCompile(`^gradle(?:_(\d+))?$`)},
+	{"ruby", regexp.MustCompile(`^ruby(?:_(\d+)_(\d+))?$`)},
+	{"php", regexp.MustCompile(`^php(\d)?(\d)?$`)},
+	{"dotnet", regexp.MustCompile(`^dotnet-sdk(?:_(\d+))?$`)},
+	{"rust", regexp.MustCompile(`^(?:rustc|cargo)$`)},
+	{"yarn", regexp.MustCompile(`^yarn$`)},
+	{"pnpm", regexp.MustCompile(`^pnpm(?:_(\d+))?$`)},
+	{"poetry", regexp.MustCompile(`^poetry$`)},
+}
+
+// devenvLanguages are the devenv languages.<name> options of each tool
+var devenvLanguages = map[string]string{
+	"javascript": "node",
+	"typescript": "node",
+	"java":       "java",
+	"python":     "python",
+	"go":         "go",
+	"ruby":       "ruby",
+	"php":        "php",
+	"dotnet":     "dotnet",
+	"rust":       "rust",
+}
+
+var (
+	// pkgsRef matches a qualified attribute, e.g. pkgs.nodejs_20
+	pkgsRef = regexp.MustCompile(`\bpkgs\.([A-Za-z_][\w-]*)`)
+	// packageList matches the lists of packages of a shell, e.g. buildInputs = with pkgs; [ ... ]
+	packageList = regexp.MustCompile(`(?s)\b(?:packages|buildInputs|nativeBuildInputs)\s*=\s*(?:with pkgs;\s*)?\[(.*?)\]`)
+	// listWord matches the attributes of a package list
+	listWord = regexp.MustCompile(`[A-Za-z_][\w-]*`)
+	// devenvLanguage matches languages.<name>.enable and languages.<name>.version
+	devenvLanguage = regexp.MustCompile(`\blanguages\.(\w+)\.(enable\s*=\s*true|version\s*=\s*"([\d.]+)")`)
+)
+
+// Packages returns the tools the environment's files put in the dev shell
+func (e *Environment) Packages() ([]Package, error) {
+	var packages []Package
+	seen := make(map[string]int)
+	add := func(pkg Package) {
+		if i, ok := seen[pkg.Tool]; ok {
+			if packages[i].Version == "" && pkg.Version != "" {
+				packages[i] = pkg // A pinned version wins over enabling the language
+			}
+			return
+		}
+		seen[pkg.Tool] = len(packages)
+		packages = append(packages, pkg)
+	}
+
+	for _, name := range e.Files {
+		if !strings.HasSuffix(name, ".nix") {
+			continue
+		}
+		data, err := common.ReadFile(filepath.Join(e.ProjectRoot, name))
+		if err != nil {
+			return nil, err
+		}
+		content := stripComments(string(data))
+		line := func(offset int) int { return strings.Count(content[:offset], "\n") + 1 }
+
+		for _, match := range pkgsRef.FindAllStringSubmatchIndex(content, -1) {
+			if pkg, ok := packageTool(content[match[2]:match[3]]); ok {
+				pkg.File, pkg.Line = name, line(match[0])
+				add(pkg)
+			}
+		}
+		for _, list := range packageList.FindAllStringSubmatchIndex(content, -1) {
+			for _, word := range listWord.FindAllStringIndex(content[list[2]:list[3]], -1) {
+				start := list[2] + word[0]
+				if start > 0 && content[start-1] == '.' {
+					continue // Already read as pkgs.<attr>
+				}
+				if pkg, ok := packageTool(content[start : list[2]+word[1]]); ok {
+					pkg.File, pkg.Line = name, line(start)
+					add(pkg)
+				}
+			}
+		}
+		for _, match := range devenvLanguage.FindAllStringSubmatchIndex(content, -1) {
+			tool, ok := devenvLanguages[content[match[2]:match[3]]]
+			if !ok {
+				continue
+			}
+			pkg := Package{Tool: tool, Attr: content[match[0]:match[1]], File: name, Line: line(match[0])}
+			if match[6] >= 0 {
+				pkg.Version = content[match[6]:match[7]]
+			}
+			add(pkg)
+		}
+	}
+	return packages, nil
+}
+
+// packageTool returns the tool a nixpkgs attribute provides, with the version it pins
+func packageTool(attr string) (Package, bool) {
+	for _, candidate := range packageAttrs {
+		match := candidate.re.FindStringSubmatch(attr)
+		if match == nil {
+			continue
+		}
+		var parts []string
+		for _, group := range match[1:] {
+			if group != "" {
+				parts = append(parts, group)
+			}
+		}
+		return Package{Tool: candidate.tool, Version: strings.Join(parts, "."), Attr: attr}, true
+	}
+	return Package{}, false
+}
+
+// stripComments blanks the # comments of a Nix file, keeping line numbers
+func stripComments(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if j := strings.Index(line, "#"); j >= 0 {
+			lines[i] = line[:j]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,255 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/settings"
+	"dev-env-sentinel/internal/snapshot"
+)
+
+// Event types carried by notifications
+const (
+	EventDrift    = "drift"
+	EventRecovery = "recovery"
+)
+
+// Notification describes an environment health change worth telling someone about
+type Notification struct {
+	Event       string           `json:"event"`
+	Job         string           `json:"job"`
+	ProjectRoot string           `json:"project_root"`
+	Title       string           `json:"title"`
+	Message     string           `json:"message"`
+	Entries     []snapshot.Entry `json:"entries"`
+	Timestamp   time.Time        `json:"timestamp"`
+}
+
+// Sink delivers notifications to a destination
+type Sink interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Dispatcher fans notifications out to every configured sink
+type Dispatcher struct {
+	sinks            []Sink
+	notifyOnRecovery bool
+}
+
+// NewDispatcher creates a dispatcher from the notifications section of the server settings
+func NewDispatcher(cfg settings.Notifications) (*Dispatcher, error) {
+	d := &Dispatcher{notifyOnRecovery: cfg.NotifyOnRecovery}
+
+	for _, sinkCfg := range cfg.Sinks {
+		sink, err := newSink(sinkCfg)
+		if err != nil {
+			return nil, err
+		}
+		d.sinks = append(d.sinks, sink)
+	}
+
+	return d, nil
+}
+
+// newSink creates a sink from its settings
+func newSink(cfg settings.NotificationSink) (Sink, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	switch cfg.Type {
+	case "desktop":
+		return &DesktopSink{goos: runtime.GOOS}, nil
+	case "webhook":
+		return &WebhookSink{url: cfg.URL, headers: cfg.Headers, client: client}, nil
+	case "slack":
+		return &WebhookSink{url: cfg.URL, headers: cfg.Headers, client: client, format: slackPayload}, nil
+	case "teams":
+		return &WebhookSink{url: cfg.URL, headers: cfg.Headers, client: client, format: teamsPayload}, nil
+	default:
+		return nil, fmt.Errorf("unknown notification sink type: %s", cfg.Type)
+	}
+}
+
+// Enabled reports whether any sink is configured
+func (d *Dispatcher) Enabled() bool {
+	return d != nil && len(d.sinks) > 0
+}
+
+// Send delivers a notification to all sinks, skipping recovery events unless enabled.
+// Every sink is attempted; failures are joined into the returned error.
+func (d *Dispatcher) Send(ctx context.Context, n Notification) error {
+	if !d.Enabled() {
+		return nil
+	}
+	if n.Event == EventRecovery && !d.notifyOnRecovery {
+		return nil
+	}
+	if n.Timestamp.IsZero() {
+		n.Timestamp = time.Now()
+	}
+
+	var errs []error
+	for _, sink := range d.sinks {
+		if err := sink.Notify(ctx, n); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewDriftNotification builds a notification for a job whose checks changed health
+func NewDriftNotification(event, job, projectRoot string, changed []snapshot.Entry) Notification {
+	title := fmt.Sprintf("Environment drift detected: %s", job)
+	if event == EventRecovery {
+		title = fmt.Sprintf("Environment recovered: %s", job)
+	}
+
+	var lines []string
+	for _, entry := range changed {
+		detail := entry.Summary
+		if entry.Error != "" {
+			detail = entry.Error
+		}
+		check := entry.Check
+		if entry.Flaky {
+			check += " (flaky)"
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", check, firstLine(detail)))
+	}
+
+	return Notification{
+		Event:       event,
+		Job:         job,
+		ProjectRoot: projectRoot,
+		Title:       title,
+		Message:     strings.Join(lines, "\n"),
+		Entries:     changed,
+		Timestamp:   time.Now(),
+	}
+}
+
+// DesktopSink shows an OS desktop notification
+type DesktopSink struct {
+	goos string
+}
+
+// Notify shows the notification using the platform's notification command
+func (s *DesktopSink) Notify(ctx context.Context, n Notification) error {
+	name, args := desktopCommand(s.goos, n.Title, n.Message)
+	if name == "" {
+		return fmt.Errorf("desktop notifications are not supported on %s", s.goos)
+	}
+
+	if err := exec.CommandContext(ctx, name, args...).Run(); err != nil {
+		return fmt.Errorf("desktop notification failed: %w", err)
+	}
+	return nil
+}
+
+// desktopCommand returns the command used to show a desktop notification on a platform
+func desktopCommand(goos, title, message string) (string, []string) {
+	switch goos {
+	case "linux":
+		return "notify-send", []string{title, message}
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return "osascript", []string{"-e", script}
+	case "windows":
+		script := fmt.Sprintf(
+			"Add-Type -AssemblyName System.Windows.Forms; "+
+				"$n = New-Object System.Windows.Forms.NotifyIcon; "+
+				"$n.Icon = [System.Drawing.SystemIcons]::Warning; $n.Visible = $true; "+
+				"$n.ShowBalloonTip(10000, '%s', '%s', 'Warning')",
+			strings.ReplaceAll(title, "'", "''"), strings.ReplaceAll(message, "'", "''"))
+		return "powershell", []string{"-NoProfile", "-Command", script}
+	default:
+		return "", nil
+	}
+}
+
+// appleScriptString quotes a string for AppleScript
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// WebhookSink POSTs notifications as JSON; format customizes the payload (Slack, Teams)
+type WebhookSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+	format  func(n Notification) interface{}
+}
+
+// Notify POSTs the notification to the webhook URL
+func (s *WebhookSink) Notify(ctx context.Context, n Notification) error {
+	var payload interface{} = n
+	if s.format != nil {
+		payload = s.format(n)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range s.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// slackPayload formats a notification for a Slack incoming webhook
+func slackPayload(n Notification) interface{} {
+	return map[string]interface{}{
+		"text": fmt.Sprintf("*%s*\n%s\n```%s```", n.Title, n.ProjectRoot, n.Message),
+	}
+}
+
+// teamsPayload formats a notification as a Microsoft Teams MessageCard
+func teamsPayload(n Notification) interface{} {
+	color := "D70000"
+	if n.Event == EventRecovery {
+		color = "2EB886"
+	}
+	return map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "http://schema.org/extensions",
+		"summary":    n.Title,
+		"themeColor": color,
+		"title":      n.Title,
+		"text":       fmt.Sprintf("%s<br><pre>%s</pre>", n.ProjectRoot, n.Message),
+	}
+}
+
+// firstLine returns the first non-empty line of a message
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			return trimmed
+		}
+	}
+	return ""
+}
@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"dev-env-sentinel/internal/settings"
+	"dev-env-sentinel/internal/snapshot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingServer captures JSON bodies POSTed to it
+func recordingServer(t *testing.T, status int) (*httptest.Server, func() []map[string]interface{}) {
+	var mu sync.Mutex
+	var bodies []map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		body["_auth"] = r.Header.Get("Authorization")
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []map[string]interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]map[string]interface{}(nil), bodies...)
+	}
+}
+
+func driftNotification() Notification {
+	return NewDriftNotification(EventDrift, "api", "/work/api", []snapshot.Entry{
+		{Job: "api", Check: "env_var_audit", Summary: "❌ Environment variable issues found:\n\nMissing API_KEY"},
+		{Job: "api", Check: "verify_build_freshness", Error: "project_root is required"},
+	})
+}
+
+func TestNewDriftNotification(t *testing.T) {
+	n := driftNotification()
+	assert.Equal(t, "Environment drift detected: api", n.Title)
+	assert.Contains(t, n.Message, "env_var_audit: ❌ Environment variable issues found:")
+	assert.Contains(t, n.Message, "verify_build_freshness: project_root is required")
+
+	recovered := NewDriftNotification(EventRecovery, "api", "/work/api", nil)
+	assert.Equal(t, "Environment recovered: api", recovered.Title)
+}
+
+func TestDispatcher_Webhooks(t *testing.T) {
+	webhook, webhookBodies := recordingServer(t, http.StatusOK)
+	slack, slackBodies := recordingServer(t, http.StatusOK)
+	teams, teamsBodies := recordingServer(t, http.StatusOK)
+
+	d, err := NewDispatcher(settings.Notifications{
+		Sinks: []settings.NotificationSink{
+			{Type: "webhook", URL: webhook.URL, Headers: map[string]string{"Authorization": "Bearer secret"}},
+			{Type: "slack", URL: slack.URL},
+			{Type: "teams", URL: teams.URL},
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, d.Send(context.Background(), driftNotification()))
+
+	generic := webhookBodies()
+	require.Len(t, generic, 1)
+	assert.Equal(t, "drift", generic[0]["event"])
+	assert.Equal(t, "api", generic[0]["job"])
+	assert.Len(t, generic[0]["entries"], 2)
+	assert.Equal(t, "Bearer secret", generic[0]["_auth"])
+
+	slackMsgs := slackBodies()
+	require.Len(t, slackMsgs, 1)
+	assert.Contains(t, slackMsgs[0]["text"], "*Environment drift detected: api*")
+
+	teamsMsgs := teamsBodies()
+	require.Len(t, teamsMsgs, 1)
+	assert.Equal(t, "MessageCard", teamsMsgs[0]["@type"])
+	assert.Equal(t, "D70000", teamsMsgs[0]["themeColor"])
+}
+
+func TestDispatcher_RecoveryOptIn(t *testing.T) {
+	webhook, bodies := recordingServer(t, http.StatusOK)
+	recovery := NewDriftNotification(EventRecovery, "api", "/work/api", nil)
+
+	d, err := NewDispatcher(settings.Notifications{Sinks: []settings.NotificationSink{{Type: "webhook", URL: webhook.URL}}})
+	require.NoError(t, err)
+	require.NoError(t, d.Send(context.Background(), recovery))
+	assert.Empty(t, bodies(), "recovery notifications are off by default")
+
+	d, err = NewDispatcher(settings.Notifications{NotifyOnRecovery: true, Sinks: []settings.NotificationSink{{Type: "webhook", URL: webhook.URL}}})
+	require.NoError(t, err)
+	require.NoError(t, d.Send(context.Background(), recovery))
+	assert.Len(t, bodies(), 1)
+}
+
+func TestDispatcher_SinkFailure(t *testing.T) {
+	failing, _ := recordingServer(t, http.StatusInternalServerError)
+	working, bodies := recordingServer(t, http.StatusOK)
+
+	d, err := NewDispatcher(settings.Notifications{
+		Sinks: []settings.NotificationSink{
+			{Type: "webhook", URL: failing.URL},
+			{Type: "webhook", URL: working.URL},
+		},
+	})
+	require.NoError(t, err)
+
+	err = d.Send(context.Background(), driftNotification())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "status 500")
+	assert.Len(t, bodies(), 1, "later sinks still receive the notification")
+}
+
+func TestDispatcher_Disabled(t *testing.T) {
+	var d *Dispatcher
+	assert.False(t, d.Enabled())
+	assert.NoError(t, d.Send(context.Background(), driftNotification()))
+}
+
+func TestDesktopCommand(t *testing.T) {
+	tests := []struct {
+		goos     string
+		expected string
+	}{
+		{"linux", "notify-send"},
+		{"darwin", "osascript"},
+		{"windows", "powershell"},
+		{"plan9", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			name, args := desktopCommand(tt.goos, `Drift "api"`, "env_var_audit: missing")
+			assert.Equal(t, tt.expected, name)
+			if tt.goos == "darwin" {
+				assert.Contains(t, args[1], `with title "Drift \"api\""`)
+			}
+		})
+	}
+}
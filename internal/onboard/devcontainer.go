@@ -0,0 +1,161 @@
+package onboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"dev-env-sentinel/internal/container"
+	"dev-env-sentinel/internal/infra"
+)
+
+// devcontainerImage is the base image features are installed on
+const devcontainerImage = "mcr.microsoft.com/devcontainers/base:ubuntu"
+
+// devcontainerFeatures are the dev container features that install a language runtime or tool
+var devcontainerFeatures = map[string]string{
+	"java":       "ghcr.io/devcontainers/features/java:1",
+	"javascript": "ghcr.io/devcontainers/features/node:1",
+	"typescript": "ghcr.io/devcontainers/features/node:1",
+	"node":       "ghcr.io/devcontainers/features/node:1",
+	"python":     "ghcr.io/devcontainers/features/python:1",
+	"csharp":     "ghcr.io/devcontainers/features/dotnet:2",
+	"go":         "ghcr.io/devcontainers/features/go:1",
+	"rust":       "ghcr.io/devcontainers/features/rust:1",
+	"ruby":       "ghcr.io/devcontainers/features/ruby:1",
+	"php":        "ghcr.io/devcontainers/features/php:1",
+	"docker":     "ghcr.io/devcontainers/features/docker-in-docker:2",
+}
+
+// toolFeature is the feature that installs a tool, and the option that enables it when the
+// feature doesn't always install it
+type toolFeature struct {
+	feature string
+	option  string
+}
+
+// toolFeatures are the tools a feature installs, by command
+var toolFeatures = map[string]toolFeature{
+	"mvn":    {"java", "installMaven"},
+	"gradle": {"java", "installGradle"},
+	"java":   {"java", ""},
+	"node":   {"javascript", ""},
+	"npm":    {"javascript", ""},
+	"python": {"python", ""},
+	"pip":    {"python", ""},
+	"dotnet": {"csharp", ""},
+	"docker": {"docker", ""},
+}
+
+// devcontainerDefinition is the part of devcontainer.json the sentinel generates
+type devcontainerDefinition struct {
+	Name              string                       `json:"name"`
+	Image             string                       `json:"image"`
+	Features          map[string]map[string]string `json:"features,omitempty"`
+	ForwardPorts      []int                        `json:"forwardPorts,omitempty"`
+	ContainerEnv      map[string]string            `json:"containerEnv,omitempty"`
+	RemoteEnv         map[string]string            `json:"remoteEnv,omitempty"`
+	PostCreateCommand string                       `json:"postCreateCommand,omitempty"`
+}
+
+// devcontainer renders the plan as a candidate dev container definition: the runtimes and tools
+// as features in the version the project needs, the ports of the compose file and services as
+// forwarded ports, variables with a safe value as container environment, variables only a human
+// can set as passed through from the host, and dependency installs as the post-create command
+func (p *Plan) devcontainer(script *Script) (string, error) {
+	definition := devcontainerDefinition{
+		Name:     filepath.Base(p.ProjectRoot),
+		Image:    devcontainerImage,
+		Features: make(map[string]map[string]string),
+	}
+	feature := func(name string) map[string]string {
+		id, ok := devcontainerFeatures[name]
+		if !ok {
+			return nil
+		}
+		if definition.Features[id] == nil {
+			definition.Features[id] = map[string]string{}
+		}
+		return definition.Features[id]
+	}
+	for _, req := range p.requirements() {
+		if req.language != "" {
+			options := feature(req.language)
+			if options == nil {
+				script.Manual = append(script.Manual, "Install "+req.label)
+				continue
+			}
+			if req.version != "" {
+				options["version"] = req.version
+			}
+			continue
+		}
+		tool, ok := toolFeatures[req.binary]
+		if !ok {
+			script.Manual = append(script.Manual, "Install "+req.label)
+			continue
+		}
+		if options := feature(tool.feature); options != nil && tool.option != "" {
+			options[tool.option] = "true"
+		}
+	}
+
+	ports, err := container.ComposePorts(p.ProjectRoot)
+	if err != nil {
+		return "", err
+	}
+	for _, eco := range p.ecosystems {
+		for _, service := range eco.Config.Ecosystem.Infrastructure.Services {
+			if port, ok := infra.DefaultPorts[service.Name]; ok && !containsInt(ports, port) {
+				ports = append(ports, port)
+			}
+		}
+	}
+	sort.Ints(ports)
+	definition.ForwardPorts = ports
+
+	fixable, manual := p.envSteps()
+	for _, step := range fixable {
+		name, value, _ := strings.Cut(step.assignment, "=")
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		if definition.ContainerEnv == nil {
+			definition.ContainerEnv = make(map[string]string)
+		}
+		definition.ContainerEnv[name] = value
+	}
+	for _, step := range manual {
+		name := strings.TrimPrefix(step.ID, KindEnvVar+":")
+		if definition.RemoteEnv == nil {
+			definition.RemoteEnv = make(map[string]string)
+		}
+		definition.RemoteEnv[name] = fmt.Sprintf("${localEnv:%s}", name)
+		script.Manual = append(script.Manual, fmt.Sprintf("%s on the host (passed through with remoteEnv)", step.Title))
+	}
+
+	commands, held := p.buildCommands()
+	definition.PostCreateCommand = strings.Join(commands, " && ")
+	for _, fix := range held {
+		script.Manual = append(script.Manual, "Confirm under the fix policy: "+fix)
+	}
+
+	data, err := json.MarshalIndent(definition, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// containsInt reports whether a list contains a number
+func containsInt(list []int, n int) bool {
+	for _, item := range list {
+		if item == n {
+			return true
+		}
+	}
+	return false
+}
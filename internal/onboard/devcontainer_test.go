@@ -0,0 +1,51 @@
+package onboard
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScript_Devcontainer(t *testing.T) {
+	plan := scriptPlan()
+	plan.ProjectRoot = filepath.Join(t.TempDir(), "api")
+	require.NoError(t, os.Mkdir(plan.ProjectRoot, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(plan.ProjectRoot, "compose.yaml"), []byte("services:\n  cache:\n    image: redis\n    ports: [\"6379:6379\"]\n"), 0644))
+	eco := plan.ecosystems[0].Config
+	eco.Ecosystem.Infrastructure.Services = append(eco.Ecosystem.Infrastructure.Services,
+		config.Service{Name: "postgres", Type: "command", CheckCommand: "pg_isready"},
+		config.Service{Name: "docker", Type: "command", CheckCommand: "docker info"},
+	)
+	plan.Steps = append(plan.Steps, Step{ID: "env_var:CACHE_DIR", Kind: KindEnvVar, Fixable: true, assignment: `CACHE_DIR="/tmp/my cache"`})
+
+	script, err := plan.Script(FormatDevcontainer)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(".devcontainer", "devcontainer.json"), script.Path)
+
+	var definition devcontainerDefinition
+	require.NoError(t, json.Unmarshal([]byte(script.Content), &definition))
+	assert.Equal(t, "api", definition.Name)
+	assert.Equal(t, map[string]string{"version": "17", "installMaven": "true"}, definition.Features["ghcr.io/devcontainers/features/java:1"])
+	assert.Contains(t, definition.Features, "ghcr.io/devcontainers/features/docker-in-docker:2")
+	assert.Equal(t, []int{5432, 6379}, definition.ForwardPorts)
+	assert.Equal(t, map[string]string{"SPRING_PROFILES_ACTIVE": "dev", "CACHE_DIR": "/tmp/my cache"}, definition.ContainerEnv)
+	assert.Equal(t, map[string]string{"API_TOKEN": "${localEnv:API_TOKEN}"}, definition.RemoteEnv)
+	assert.Equal(t, "mvn dependency:resolve && mvn compile", definition.PostCreateCommand)
+	assert.Equal(t, []string{
+		"Install postgres",
+		"Set API_TOKEN on the host (passed through with remoteEnv)",
+		"Confirm under the fix policy: Fix stale cache: mvn clean",
+	}, script.Manual)
+}
+
+func TestTargetVersion(t *testing.T) {
+	plan := &Plan{versions: map[string]string{"java": "21"}}
+	assert.Equal(t, "17", plan.targetVersion("java", config.Requirements{MinVersion: "11", PreferredVersions: []string{"17"}}))
+	assert.Equal(t, "21", plan.targetVersion("java", config.Requirements{MinVersion: "11"}), "the supported version found here")
+	assert.Equal(t, "3.10", plan.targetVersion("python", config.Requirements{MinVersion: "3.10"}))
+}
@@ -0,0 +1,564 @@
+// Package onboard works out what a new contributor's machine is missing to work on a project
+// (runtimes, services, environment variables and build steps) and sets up what it can, in
+// the order the steps depend on each other.
+package onboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"dev-env-sentinel/internal/auditor"
+	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/infra"
+	"dev-env-sentinel/internal/installer"
+	"dev-env-sentinel/internal/nix"
+	"dev-env-sentinel/internal/reconciler"
+	"dev-env-sentinel/internal/runner"
+	"dev-env-sentinel/internal/verifier"
+	"dev-env-sentinel/internal/version"
+)
+
+// Step kinds, in the order they are set up
+const (
+	KindRuntime = "runtime" // Language runtimes in a supported version
+	KindService = "service" // Databases, brokers and other services the project talks to
+	KindEnvVar  = "env_var" // Environment variables the code reads
+	KindBuild   = "build"   // Dependencies and build outputs
+)
+
+// Step statuses
+const (
+	StatusMissing = "missing" // Still to do
+	StatusFixed   = "fixed"
+	StatusFailed  = "failed"
+	StatusManual  = "manual"  // Needs a human; Commands or Detail say how
+	StatusBlocked = "blocked" // Waits for a step it depends on
+)
+
+// kindRank orders the kinds: runtimes before anything runs on them, services and variables
+// before the build that may need them
+var kindRank = map[string]int{KindRuntime: 0, KindService: 1, KindEnvVar: 2, KindBuild: 3}
+
+// Step is a missing prerequisite and how to set it up
+type Step struct {
+	ID          string   `json:"id"` // e.g. "runtime:java", "env_var:API_KEY"
+	Kind        string   `json:"kind"`
+	Title       string   `json:"title"`
+	Detail      string   `json:"detail,omitempty"`
+	Commands    []string `json:"commands,omitempty"`    // Commands that set it up, run by a fix or by hand
+	Fixable     bool     `json:"fixable"`               // A fix can set it up
+	Fingerprint string   `json:"fingerprint,omitempty"` // Fix of a build step, as reconcile_issue takes it, or install:<name> of an install
+	Estimate    string   `json:"estimate,omitempty"`    // How long the fix typically takes on this machine
+	Confirm     bool     `json:"needs_confirmation,omitempty"`
+	Ecosystems  []string `json:"ecosystems,omitempty"`
+	DependsOn   []string `json:"depends_on,omitempty"`
+	Status      string   `json:"status"`
+	Error       string   `json:"error,omitempty"`
+
+	assignment string // NAME=value an env var fix writes to .env
+	install    string // Command a runtime or service fix installs it with
+}
+
+// Plan is the onboarding of a machine to a project: what is in place and the missing steps,
+// each after the steps it depends on
+type Plan struct {
+	ProjectRoot string   `json:"project_root"`
+	Ecosystems  []string `json:"ecosystems"`
+	Ready       []string `json:"ready,omitempty"` // Prerequisites already in place
+	Steps       []Step   `json:"steps"`
+	Executed    bool     `json:"executed"` // Fixes ran, so step statuses are outcomes
+
+	envReports []*auditor.EnvVarReport
+	envIDs     []string // Ecosystem of each env report
+	ecosystems []*detector.DetectedEcosystem
+	versions   map[string]string // Major version of each supported runtime found, by language
+	devShell   *nix.Environment  // Nix environment providing the runtimes and tools, if declared
+}
+
+// Progress receives each step as execution starts it; done steps precede it out of total
+type Progress func(done, total int, step Step)
+
+// Assess lists what the machine is missing for the detected ecosystems of a project
+func Assess(ctx context.Context, projectRoot string, ecosystems []*detector.DetectedEcosystem) (*Plan, error) {
+	plan := &Plan{ProjectRoot: projectRoot, ecosystems: ecosystems}
+	for _, eco := range ecosystems {
+		plan.Ecosystems = append(plan.Ecosystems, eco.ID)
+		plan.assessRuntime(ctx, eco)
+		plan.assessServices(ctx, eco)
+		if report, err := auditor.AuditEnvironmentVariables(ctx, projectRoot, eco.Config); err == nil {
+			plan.envReports = append(plan.envReports, report)
+			plan.envIDs = append(plan.envIDs, eco.ID)
+		}
+	}
+	if err := plan.assessEnvVars(ctx); err != nil {
+		return nil, err
+	}
+	if err := plan.assessBuild(ctx); err != nil {
+		return nil, err
+	}
+	plan.preferDevShell()
+
+	sorted, err := order(plan.Steps)
+	if err != nil {
+		return nil, err
+	}
+	plan.Steps = sorted
+	return plan, nil
+}
+
+// Missing returns the number of steps not done
+func (p *Plan) Missing() int {
+	missing := 0
+	for _, step := range p.Steps {
+		if step.Status != StatusFixed {
+			missing++
+		}
+	}
+	return missing
+}
+
+// assessRuntime checks that the ecosystem's language runtime is installed in a supported version
+func (p *Plan) assessRuntime(ctx context.Context, eco *detector.DetectedEcosystem) {
+	cfg := eco.Config
+	language := cfg.Ecosystem.VersionConfig.Language
+	if language == "" {
+		return
+	}
+	step := Step{ID: KindRuntime + ":" + eco.ID, Kind: KindRuntime, Ecosystems: []string{eco.ID}, Status: StatusMissing}
+	required := cfg.Ecosystem.Requirements.MinVersion
+
+	info, err := version.DetectVersion(ctx, cfg)
+	if err != nil {
+		step.Title = fmt.Sprintf("Install %s", language)
+		if required != "" {
+			step.Title += fmt.Sprintf(" %s or newer", required)
+		}
+		step.Detail = fmt.Sprintf("%s was not found: %v", language, err)
+		var managers []string
+		for _, manager := range cfg.Ecosystem.VersionConfig.VersionManagers {
+			managers = append(managers, manager.Name)
+		}
+		program := version.Program(cfg.Ecosystem.VersionConfig)
+		if better := version.BetterInstallation(version.FindInstallations(ctx, cfg), nil, cfg); better != nil {
+			step.Title = fmt.Sprintf("Switch to the installed %s %s", language, better.Version)
+			step.Detail += fmt.Sprintf(". %s %s is already installed at %s", language, better.Version, better.Path)
+			step.Commands = []string{better.SwitchCommand(cfg.Ecosystem.VersionConfig)}
+		} else if install := installer.Advise(cfg.Ecosystem.VersionConfig.Packages, p.targetVersion(language, cfg.Ecosystem.Requirements), installer.Available(ctx)); len(install) > 0 && installer.Missing(ctx, program) {
+			adviseInstall(&step, program, install)
+		} else if len(managers) > 0 {
+			step.Detail += fmt.Sprintf(". Version managers that can install it: %s", strings.Join(managers, ", "))
+		}
+		p.Steps = append(p.Steps, step)
+		return
+	}
+
+	validation := version.ValidateVersion(info, cfg)
+	if validation.IsValid {
+		p.Ready = append(p.Ready, fmt.Sprintf("%s %s", language, info.Version))
+		if p.versions == nil {
+			p.versions = make(map[string]string)
+		}
+		p.versions[language] = info.Major
+		return
+	}
+	step.Title = fmt.Sprintf("Switch %s %s to a supported version", language, info.Version)
+	var issues []string
+	for _, issue := range validation.Issues {
+		issues = append(issues, issue.Message)
+	}
+	step.Detail = strings.Join(issues, "; ")
+	if better := version.BetterInstallation(version.FindInstallations(ctx, cfg), info, cfg); better != nil {
+		// Nothing to install
+		step.Detail += fmt.Sprintf("; %s %s is already installed at %s", language, better.Version, better.Path)
+		step.Commands = []string{better.SwitchCommand(cfg.Ecosystem.VersionConfig)}
+		p.Steps = append(p.Steps, step)
+		return
+	}
+	for _, suggestion := range validation.Suggestions {
+		step.Commands = append(step.Commands, suggestion.Commands...)
+	}
+	p.Steps = append(p.Steps, step)
+}
+
+// preferDevShell points the runtime and service version steps at the project's Nix dev shell,
+// which provides the tools, instead of installing or switching them by hand
+func (p *Plan) preferDevShell() {
+	p.devShell = nix.Detect(p.ProjectRoot)
+	if p.devShell == nil {
+		return
+	}
+	active, _ := p.devShell.Active()
+	for i := range p.Steps {
+		step := &p.Steps[i]
+		if step.Kind != KindRuntime && (step.Kind != KindService || len(step.Commands) == 0) {
+			continue
+		}
+		if step.Detail != "" {
+			step.Detail += ". "
+		}
+		if active {
+			step.Detail += fmt.Sprintf("The dev shell is active, so change the version in %s", strings.Join(p.devShell.Files, ", "))
+			step.Commands = nil
+			step.Fixable, step.Confirm, step.Fingerprint, step.install = false, false, "", ""
+			continue
+		}
+		step.Detail += p.devShell.Hint()
+		step.Commands = []string{p.devShell.Activation[0]}
+		// The dev shell provides it, so nothing is installed
+		step.Fixable, step.Confirm, step.Fingerprint, step.install = false, false, "", ""
+	}
+}
+
+// assessServices checks the services the ecosystem needs are running in the expected version
+func (p *Plan) assessServices(ctx context.Context, eco *detector.DetectedEcosystem) {
+	if len(eco.Config.Ecosystem.Infrastructure.Services) == 0 {
+		return
+	}
+	report, err := infra.CheckInfrastructure(ctx, eco.Config)
+	if err != nil {
+		return
+	}
+	for _, service := range report.Services {
+		if service.Healthy {
+			p.Ready = append(p.Ready, strings.TrimSpace(service.Name+" "+service.Version))
+			continue
+		}
+		id := KindService + ":" + service.Name
+		if existing := p.step(id); existing != nil {
+			existing.Ecosystems = append(existing.Ecosystems, eco.ID)
+			continue
+		}
+		title := fmt.Sprintf("Start %s", service.Name)
+		if service.Running {
+			title = fmt.Sprintf("Run %s in the expected version", service.Name)
+		}
+		step := Step{
+			ID:         id,
+			Kind:       KindService,
+			Title:      title,
+			Detail:     service.Message,
+			Commands:   service.Suggestions,
+			Ecosystems: []string{eco.ID},
+			Status:     StatusMissing,
+		}
+		if len(service.Install) > 0 {
+			step.Title = fmt.Sprintf("Install %s", service.Name)
+			adviseInstall(&step, service.Name, service.Install)
+		}
+		p.Steps = append(p.Steps, step)
+	}
+}
+
+// adviseInstall sets a step up to install what's missing with the first install command for the
+// installers on this machine, naming the others as alternatives. When that command runs
+// unattended, the step is a fix that runs once install:<name> is confirmed.
+func adviseInstall(step *Step, name string, install []installer.Command) {
+	step.Commands = []string{install[0].Command}
+	if len(install) > 1 {
+		var alternatives []string
+		for _, command := range install[1:] {
+			alternatives = append(alternatives, command.Command)
+		}
+		step.Detail += fmt.Sprintf(". Or install it with: %s", strings.Join(alternatives, " or "))
+	}
+	if install[0].Automatic {
+		step.Fixable = true
+		step.Confirm = true
+		step.Fingerprint = "install:" + name
+		step.install = install[0].Command
+	}
+}
+
+// assessEnvVars lists the variables the code reads that aren't set, with the value a fix
+// writes to .env when there is a safe one
+func (p *Plan) assessEnvVars(ctx context.Context) error {
+	report := reconciler.NewReport()
+	planCtx := reconciler.WithApproval(ctx, reconciler.Approval{Plan: true})
+	if err := reconciler.ReconcileEnvVars(planCtx, p.ProjectRoot, p.envReports, report); err != nil {
+		return err
+	}
+	fixes := make(map[string]reconciler.FixResult)
+	for _, result := range report.Planned {
+		name, _, _ := strings.Cut(result.Command, "=")
+		fixes[name] = result
+	}
+
+	seen := make(map[string]bool)
+	for i, envReport := range p.envReports {
+		for _, name := range envReport.Missing {
+			id := KindEnvVar + ":" + name
+			if seen[name] {
+				p.step(id).Ecosystems = append(p.step(id).Ecosystems, p.envIDs[i])
+				continue
+			}
+			seen[name] = true
+			step := Step{ID: id, Kind: KindEnvVar, Title: fmt.Sprintf("Set %s", name), Ecosystems: []string{p.envIDs[i]}, Status: StatusMissing}
+			if fix, ok := fixes[name]; ok {
+				step.Fixable = true
+				step.Detail = fix.Message
+				step.Commands = []string{fmt.Sprintf("echo '%s' >> .env", fix.Command)}
+				step.assignment = fix.Command
+			} else {
+				for _, manual := range report.Manual {
+					if strings.HasPrefix(manual.Message, name+" ") {
+						step.Detail = manual.Message
+					}
+				}
+			}
+			p.Steps = append(p.Steps, step)
+		}
+	}
+	return nil
+}
+
+// assessBuild plans the fixes for stale or missing dependencies and build outputs. Build steps
+// depend on the ecosystem's runtime.
+func (p *Plan) assessBuild(ctx context.Context) error {
+	planCtx := reconciler.WithApproval(ctx, reconciler.Approval{Plan: true})
+	for _, eco := range p.ecosystems {
+		issues := ecosystemIssues(ctx, p.ProjectRoot, eco)
+		if len(issues) == 0 {
+			continue
+		}
+		report, err := reconciler.ReconcileEnvironment(planCtx, p.ProjectRoot, issues, eco)
+		if err != nil {
+			return err
+		}
+
+		var dependsOn []string
+		if runtime := p.step(KindRuntime + ":" + eco.ID); runtime != nil {
+			dependsOn = []string{runtime.ID}
+		}
+		groups := []struct {
+			results []reconciler.FixResult
+			fixable bool
+			confirm bool
+		}{
+			{report.Planned, true, false},
+			{report.Pending, true, true},
+			{report.Manual, false, false},
+			{report.Failed, false, false},
+		}
+		for _, group := range groups {
+			for _, result := range group.results {
+				id := KindBuild + ":" + result.IssueType
+				if result.Fingerprint != "" {
+					id = KindBuild + ":" + result.Fingerprint
+				}
+				if existing := p.step(id); existing != nil {
+					existing.Ecosystems = append(existing.Ecosystems, eco.ID)
+					continue
+				}
+				step := Step{
+					ID:          id,
+					Kind:        KindBuild,
+					Title:       fmt.Sprintf("Fix %s", strings.ReplaceAll(result.IssueType, "_", " ")),
+					Detail:      result.Message,
+					Fixable:     group.fixable && result.Fingerprint != "",
+					Fingerprint: result.Fingerprint,
+					Estimate:    result.Estimate,
+					Confirm:     group.confirm,
+					Ecosystems:  []string{eco.ID},
+					DependsOn:   dependsOn,
+					Status:      StatusMissing,
+				}
+				if result.Command != "" {
+					step.Commands = []string{result.Command}
+				}
+				p.Steps = append(p.Steps, step)
+			}
+		}
+	}
+	return nil
+}
+
+// Execute sets up the fixable steps in order: variables with a safe value are written to .env,
+// missing runtimes and services are installed and build fixes run one at a time. Installs, and
+// fixes needing confirmation under the fix policy, run only when their fingerprint is in
+// confirmed. Everything else without a fix is left for a human, and steps depending on a step
+// that isn't done are blocked.
+func (p *Plan) Execute(ctx context.Context, confirmed []string, progress Progress) error {
+	p.Executed = true
+	status := make(map[string]string)
+	envDone := false
+	var envFixed map[string]bool
+
+	for i := range p.Steps {
+		step := &p.Steps[i]
+		if progress != nil {
+			progress(i, len(p.Steps), *step)
+		}
+
+		var waiting []string
+		for _, dep := range step.DependsOn {
+			if status[dep] != StatusFixed {
+				waiting = append(waiting, dep)
+			}
+		}
+		switch {
+		case len(waiting) > 0:
+			step.Status = StatusBlocked
+			step.Error = fmt.Sprintf("waiting for %s", strings.Join(waiting, ", "))
+		case !step.Fixable:
+			step.Status = StatusManual
+		case step.Kind == KindEnvVar:
+			if !envDone {
+				envDone = true
+				fixed, err := p.fixEnvVars(ctx)
+				if err != nil {
+					return err
+				}
+				envFixed = fixed
+			}
+			name := strings.TrimPrefix(step.ID, KindEnvVar+":")
+			step.Status = StatusFailed
+			if envFixed[name] {
+				step.Status = StatusFixed
+			}
+		case step.install != "" && !contains(confirmed, step.Fingerprint):
+			step.Status = StatusManual
+			step.Error = fmt.Sprintf("installing needs confirmation; confirm %s", step.Fingerprint)
+		case step.install != "":
+			p.install(ctx, step)
+		case step.Kind == KindBuild && step.Confirm && !contains(confirmed, step.Fingerprint):
+			step.Status = StatusManual
+			step.Error = fmt.Sprintf("the fix policy needs confirmation; confirm fingerprint %s", step.Fingerprint)
+		case step.Kind == KindBuild:
+			p.fixBuild(ctx, step)
+		default:
+			step.Status = StatusManual
+		}
+		status[step.ID] = step.Status
+	}
+	if progress != nil && len(p.Steps) > 0 {
+		progress(len(p.Steps), len(p.Steps), p.Steps[len(p.Steps)-1])
+	}
+	return nil
+}
+
+// install runs the install command of a step
+func (p *Plan) install(ctx context.Context, step *Step) {
+	if _, err := runner.RunMutating(ctx, p.ProjectRoot, step.install); err != nil {
+		step.Status, step.Error = StatusFailed, err.Error()
+		return
+	}
+	step.Status, step.Detail = StatusFixed, fmt.Sprintf("Installed with %s", step.install)
+}
+
+// fixEnvVars writes the variables that have a safe value to .env and returns their names
+func (p *Plan) fixEnvVars(ctx context.Context) (map[string]bool, error) {
+	report := reconciler.NewReport()
+	if err := reconciler.ReconcileEnvVars(ctx, p.ProjectRoot, p.envReports, report); err != nil {
+		return nil, fmt.Errorf("failed to write environment variables: %w", err)
+	}
+	fixed := make(map[string]bool)
+	for _, result := range report.Fixed {
+		name, _, _ := strings.Cut(result.Command, "=")
+		fixed[name] = true
+	}
+	return fixed, nil
+}
+
+// fixBuild runs the fix of a build step against the project's current issues
+func (p *Plan) fixBuild(ctx context.Context, step *Step) {
+	var issues []verifier.Issue
+	for _, eco := range p.ecosystems {
+		issues = append(issues, ecosystemIssues(ctx, p.ProjectRoot, eco)...)
+	}
+	report, err := reconciler.ReconcileFingerprint(ctx, p.ProjectRoot, issues, p.ecosystems, step.Fingerprint)
+	switch {
+	case errors.Is(err, reconciler.ErrFingerprintNotFound):
+		// An earlier step fixed it too
+		step.Status = StatusFixed
+		step.Detail = "Already resolved by an earlier step"
+	case err != nil:
+		step.Status, step.Error = StatusFailed, err.Error()
+	case len(report.Fixed) > 0:
+		step.Status, step.Detail = StatusFixed, report.Fixed[0].Message
+	case len(report.Failed) > 0:
+		step.Status, step.Error = StatusFailed, report.Failed[0].Error
+		if step.Error == "" {
+			step.Error = report.Failed[0].Message
+		}
+	case len(report.Planned) > 0:
+		step.Status, step.Detail = StatusManual, report.Planned[0].Message
+	default:
+		step.Status = StatusManual
+	}
+}
+
+// ecosystemIssues returns the build freshness issues of an ecosystem
+func ecosystemIssues(ctx context.Context, projectRoot string, eco *detector.DetectedEcosystem) []verifier.Issue {
+	report, err := verifier.VerifyBuildFreshness(ctx, projectRoot, eco)
+	if err != nil {
+		return nil
+	}
+	return report.Issues
+}
+
+// step returns the step with an ID, or nil
+func (p *Plan) step(id string) *Step {
+	for i := range p.Steps {
+		if p.Steps[i].ID == id {
+			return &p.Steps[i]
+		}
+	}
+	return nil
+}
+
+// order sorts steps so each comes after the steps it depends on, by kind and then in the order
+// they were found otherwise
+func order(steps []Step) ([]Step, error) {
+	index := make(map[string]int, len(steps))
+	for i, step := range steps {
+		index[step.ID] = i
+	}
+	candidates := make([]int, len(steps))
+	for i := range steps {
+		candidates[i] = i
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return kindRank[steps[candidates[a]].Kind] < kindRank[steps[candidates[b]].Kind]
+	})
+
+	placed := make(map[string]bool, len(steps))
+	sorted := make([]Step, 0, len(steps))
+	for len(sorted) < len(steps) {
+		progressed := false
+		for _, i := range candidates {
+			step := steps[i]
+			if placed[step.ID] {
+				continue
+			}
+			ready := true
+			for _, dep := range step.DependsOn {
+				if _, known := index[dep]; known && !placed[dep] {
+					ready = false
+				}
+			}
+			if ready {
+				placed[step.ID] = true
+				sorted = append(sorted, step)
+				progressed = true
+				break
+			}
+		}
+		if !progressed {
+			return nil, fmt.Errorf("onboarding steps depend on each other in a cycle")
+		}
+	}
+	return sorted, nil
+}
+
+// contains reports whether a list contains a string
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
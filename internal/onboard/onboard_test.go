@@ -0,0 +1,174 @@
+package onboard
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnboard(t *testing.T) {
+	t.Setenv("SENTINEL_READ_ONLY", "")
+	for _, name := range []string{"NODE_ENV", "API_TOKEN"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "app.js"), []byte("const env = process.env.NODE_ENV\nconst token = process.env.API_TOKEN\n"), 0644))
+
+	eco := &detector.DetectedEcosystem{ID: "acme", Config: &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		ID: "acme",
+		VersionConfig: config.VersionConfig{
+			Language:       "acme",
+			VersionCommand: "sentinel-missing-runtime --version",
+			VersionPattern: `(\d+\.\d+\.\d+)`,
+		},
+		Environment: config.Environment{VariablePatterns: []string{`process\.env\.([A-Z_][A-Z0-9_]*)`}},
+	}}}
+
+	plan, err := Assess(context.Background(), root, []*detector.DetectedEcosystem{eco})
+	require.NoError(t, err)
+	require.Len(t, plan.Steps, 3)
+	assert.Equal(t, "runtime:acme", plan.Steps[0].ID, "runtimes come first")
+	assert.Equal(t, "Install acme", plan.Steps[0].Title)
+	assert.False(t, plan.Steps[0].Fixable)
+	byID := map[string]Step{}
+	for _, step := range plan.Steps {
+		byID[step.ID] = step
+	}
+	assert.True(t, byID["env_var:NODE_ENV"].Fixable)
+	assert.False(t, byID["env_var:API_TOKEN"].Fixable, "secrets are never given a value")
+	assert.NoFileExists(t, filepath.Join(root, ".env"), "assessing changes nothing")
+
+	var events []int
+	require.NoError(t, plan.Execute(context.Background(), nil, func(done, total int, step Step) {
+		assert.Equal(t, 3, total)
+		events = append(events, done)
+	}))
+	assert.Equal(t, []int{0, 1, 2, 3}, events)
+	assert.True(t, plan.Executed)
+	assert.Equal(t, StatusManual, plan.Steps[0].Status)
+	for _, step := range plan.Steps {
+		switch step.ID {
+		case "env_var:NODE_ENV":
+			assert.Equal(t, StatusFixed, step.Status)
+		case "env_var:API_TOKEN":
+			assert.Equal(t, StatusManual, step.Status)
+		}
+	}
+	assert.Equal(t, 2, plan.Missing())
+
+	content, err := os.ReadFile(filepath.Join(root, ".env"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "NODE_ENV=development")
+	assert.NotContains(t, string(content), "API_TOKEN")
+}
+
+func TestOnboard_Install(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	t.Setenv("SENTINEL_READ_ONLY", "")
+	t.Setenv("HOME", t.TempDir())
+
+	// brew is the only installer on the PATH
+	bin := t.TempDir()
+	log := filepath.Join(t.TempDir(), "brew.log")
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "brew"), []byte("#!/bin/sh\necho \"$@\" >> "+log+"\n"), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+"/usr/bin"+string(os.PathListSeparator)+"/bin")
+
+	eco := &detector.DetectedEcosystem{ID: "acme", Config: &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		ID: "acme",
+		VersionConfig: config.VersionConfig{
+			Language:       "acme",
+			VersionArgs:    []string{"sentinel-missing-runtime", "--version"},
+			VersionPattern: `(\d+\.\d+\.\d+)`,
+			Packages:       map[string]string{"brew": "acme@{version}", "winget": "Acme.Runtime.{version}"},
+		},
+		Requirements: config.Requirements{MinVersion: "2"},
+	}}}
+
+	plan, err := Assess(context.Background(), t.TempDir(), []*detector.DetectedEcosystem{eco})
+	require.NoError(t, err)
+	require.Len(t, plan.Steps, 1)
+	step := plan.Steps[0]
+	assert.Equal(t, []string{"brew install acme@2"}, step.Commands)
+	assert.True(t, step.Fixable)
+	assert.Equal(t, "install:sentinel-missing-runtime", step.Fingerprint)
+
+	// Installing waits for confirmation
+	require.NoError(t, plan.Execute(context.Background(), nil, nil))
+	assert.Equal(t, StatusManual, plan.Steps[0].Status)
+	assert.Equal(t, "installing needs confirmation; confirm install:sentinel-missing-runtime", plan.Steps[0].Error)
+	assert.NoFileExists(t, log)
+
+	plan.Steps[0] = step
+	require.NoError(t, plan.Execute(context.Background(), []string{"install:sentinel-missing-runtime"}, nil))
+	assert.Equal(t, StatusFixed, plan.Steps[0].Status)
+	content, err := os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Equal(t, "install acme@2\n", string(content))
+}
+
+func TestOrder(t *testing.T) {
+	steps := []Step{
+		{ID: "build:b", Kind: KindBuild, DependsOn: []string{"build:a"}},
+		{ID: "build:a", Kind: KindBuild, DependsOn: []string{"runtime:go"}},
+		{ID: "env_var:PORT", Kind: KindEnvVar},
+		{ID: "runtime:go", Kind: KindRuntime},
+		{ID: "service:postgres", Kind: KindService, DependsOn: []string{"runtime:unlisted"}},
+	}
+	sorted, err := order(steps)
+	require.NoError(t, err)
+	var ids []string
+	for _, step := range sorted {
+		ids = append(ids, step.ID)
+	}
+	assert.Equal(t, []string{"runtime:go", "service:postgres", "env_var:PORT", "build:a", "build:b"}, ids)
+
+	_, err = order([]Step{
+		{ID: "a", Kind: KindBuild, DependsOn: []string{"b"}},
+		{ID: "b", Kind: KindBuild, DependsOn: []string{"a"}},
+	})
+	assert.Error(t, err)
+}
+
+func TestExecute_Blocked(t *testing.T) {
+	plan := &Plan{Steps: []Step{
+		{ID: "runtime:go", Kind: KindRuntime, Status: StatusMissing},
+		{ID: "build:deps", Kind: KindBuild, Fixable: true, Fingerprint: "abc", DependsOn: []string{"runtime:go"}, Status: StatusMissing},
+	}}
+	require.NoError(t, plan.Execute(context.Background(), nil, nil))
+	assert.Equal(t, StatusManual, plan.Steps[0].Status)
+	assert.Equal(t, StatusBlocked, plan.Steps[1].Status)
+	assert.Equal(t, "waiting for runtime:go", plan.Steps[1].Error)
+}
+
+func TestPreferDevShell(t *testing.T) {
+	for _, name := range []string{"IN_NIX_SHELL", "DEVENV_ROOT", "DIRENV_DIR"} {
+		t.Setenv(name, "")
+	}
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "flake.nix"), []byte("{ }\n"), 0644))
+	plan := &Plan{ProjectRoot: root, Steps: []Step{
+		{ID: "runtime:node", Kind: KindRuntime, Title: "Switch node 18.19.0 to a supported version", Commands: []string{"nvm install 20"}},
+		{ID: "service:postgres", Kind: KindService, Title: "Start postgres"},
+	}}
+
+	plan.preferDevShell()
+	assert.Equal(t, []string{"nix develop"}, plan.Steps[0].Commands)
+	assert.Contains(t, plan.Steps[0].Detail, "Enter the dev shell flake.nix declares: nix develop")
+	assert.Empty(t, plan.Steps[1].Commands, "starting a service isn't the dev shell's job")
+
+	t.Setenv("IN_NIX_SHELL", "impure")
+	plan.Steps[0].Commands, plan.Steps[0].Detail = []string{"nvm install 20"}, ""
+	plan.preferDevShell()
+	assert.Empty(t, plan.Steps[0].Commands)
+	assert.Equal(t, "The dev shell is active, so change the version in flake.nix", plan.Steps[0].Detail)
+}
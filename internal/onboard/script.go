@@ -0,0 +1,349 @@
+package onboard
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/version"
+)
+
+// Setup script formats
+const (
+	FormatShell        = "sh"
+	FormatPowerShell   = "powershell"
+	FormatDevcontainer = "devcontainer"
+)
+
+// Formats lists the setup script formats
+var Formats = []string{FormatShell, FormatPowerShell, FormatDevcontainer}
+
+// scriptPaths are where a format is committed, relative to the project root
+var scriptPaths = map[string]string{
+	FormatShell:        "setup.sh",
+	FormatPowerShell:   "setup.ps1",
+	FormatDevcontainer: filepath.Join(".devcontainer", "devcontainer.json"),
+}
+
+// Script is a setup script generated from a plan, for a team to review and commit
+type Script struct {
+	Format  string   `json:"format"`
+	Path    string   `json:"path"` // Where it is committed, relative to the project root
+	Content string   `json:"content"`
+	Manual  []string `json:"manual,omitempty"` // Prerequisites the script can only report, not set up
+}
+
+// scriptSection is a part of a setup script: what it sets up and the lines that do it
+type scriptSection struct {
+	title string
+	lines []string
+}
+
+// Script generates a setup script in a format: the runtimes and tools the detected ecosystems
+// require, the environment variables the code reads, and the commands that install the
+// dependencies and fix the build issues found. It is derived from the ecosystem configs as well
+// as this machine's issues, so it also sets up machines missing what this one has.
+func (p *Plan) Script(format string) (*Script, error) {
+	if format == "" {
+		format = FormatShell
+	}
+	script := &Script{Format: format, Path: scriptPaths[format]}
+	switch format {
+	case FormatShell:
+		script.Content = p.shellScript(script)
+	case FormatPowerShell:
+		script.Content = p.powerShellScript(script)
+	case FormatDevcontainer:
+		content, err := p.devcontainer(script)
+		if err != nil {
+			return nil, err
+		}
+		script.Content = content
+	default:
+		return nil, fmt.Errorf("unknown format %s (available: %s)", format, strings.Join(Formats, ", "))
+	}
+	return script, nil
+}
+
+// requirement is a command a machine needs, with how to get it
+type requirement struct {
+	binary   string
+	label    string
+	language string
+	version  string
+	install  []string
+}
+
+// requirements returns the runtimes and tools the ecosystems need, runtimes first
+func (p *Plan) requirements() []requirement {
+	var runtimes, tools []requirement
+	seen := make(map[string]bool)
+	for _, eco := range p.ecosystems {
+		cfg := eco.Config.Ecosystem
+		if language := cfg.VersionConfig.Language; language != "" {
+			binary := version.Program(cfg.VersionConfig)
+			if binary != "" && !seen[binary] {
+				seen[binary] = true
+				req := requirement{binary: binary, label: language, language: language, version: p.targetVersion(language, cfg.Requirements)}
+				if req.version != "" {
+					req.label += " " + req.version
+				}
+				if supported := supportedRange(cfg.Requirements); supported != "" {
+					req.label += fmt.Sprintf(" (supported: %s)", supported)
+				}
+				switch {
+				case p.devShell != nil:
+					req.install = p.devShell.Activation // The dev shell provides it
+				case req.version != "":
+					for _, manager := range cfg.VersionConfig.VersionManagers {
+						if manager.InstallCommand != "" {
+							req.install = append(req.install, strings.ReplaceAll(manager.InstallCommand, "{version}", req.version))
+						}
+					}
+				}
+				runtimes = append(runtimes, req)
+			}
+		}
+		for _, service := range cfg.Infrastructure.Services {
+			binary := commandName(service.CheckCommand)
+			if service.Type != "command" || binary == "" || seen[binary] {
+				continue
+			}
+			seen[binary] = true
+			label := service.Name
+			if service.ExpectedVersion != "" {
+				label += " " + service.ExpectedVersion
+			} else if service.MinVersion != "" {
+				label += fmt.Sprintf(" %s or newer", service.MinVersion)
+			}
+			req := requirement{binary: binary, label: label}
+			if step := p.step(KindService + ":" + service.Name); step != nil {
+				req.install = step.Commands
+			}
+			tools = append(tools, req)
+		}
+	}
+	return append(runtimes, tools...)
+}
+
+// buildCommands returns the commands that install dependencies and fix the build issues found,
+// and the fixes left out because the fix policy holds them for confirmation
+func (p *Plan) buildCommands() (commands, held []string) {
+	seen := make(map[string]bool)
+	add := func(command string) {
+		if command != "" && !seen[command] {
+			seen[command] = true
+			commands = append(commands, command)
+		}
+	}
+	for _, eco := range p.ecosystems {
+		add(eco.Config.Ecosystem.Dependencies.ResolveCommand)
+	}
+	for _, step := range p.Steps {
+		if step.Kind != KindBuild || !step.Fixable {
+			continue
+		}
+		if step.Confirm {
+			held = append(held, fmt.Sprintf("%s: %s", step.Title, strings.Join(step.Commands, " && ")))
+			continue
+		}
+		for _, command := range step.Commands {
+			add(command)
+		}
+	}
+	return commands, held
+}
+
+// envSteps returns the env var steps with a value to write, and those a human has to set
+func (p *Plan) envSteps() (fixable, manual []Step) {
+	for _, step := range p.Steps {
+		switch {
+		case step.Kind != KindEnvVar:
+		case step.assignment != "":
+			fixable = append(fixable, step)
+		default:
+			manual = append(manual, step)
+		}
+	}
+	return fixable, manual
+}
+
+// header returns the comment lines every format starts with
+func (p *Plan) header() []string {
+	return []string{
+		fmt.Sprintf("Sets up a machine to work on %s (%s).", filepath.Base(p.ProjectRoot), strings.Join(p.Ecosystems, ", ")),
+		"Generated by dev-env-sentinel from the detected ecosystems' requirements and the issues",
+		"found on the machine that generated it. Review it before running it from the project root.",
+	}
+}
+
+// shellScript renders the plan as a POSIX shell script
+func (p *Plan) shellScript(script *Script) string {
+	var sections []scriptSection
+
+	var checks []string
+	for _, req := range p.requirements() {
+		checks = append(checks, fmt.Sprintf("if ! command -v %s >/dev/null 2>&1; then", req.binary))
+		checks = append(checks, fmt.Sprintf("  echo %s >&2", shQuote("Missing "+req.label)))
+		for _, install := range req.install {
+			checks = append(checks, fmt.Sprintf("  echo %s >&2", shQuote("  e.g. "+install)))
+		}
+		checks = append(checks, "  missing=1", "fi")
+		script.Manual = append(script.Manual, "Install "+req.label)
+	}
+	sections = append(sections, scriptSection{"Runtimes and tools", checks})
+
+	fixable, manual := p.envSteps()
+	var env []string
+	for _, step := range fixable {
+		name := strings.TrimPrefix(step.ID, KindEnvVar+":")
+		env = append(env, fmt.Sprintf("grep -q '^%s=' .env 2>/dev/null || echo %s >> .env", name, shQuote(step.assignment)))
+	}
+	for _, step := range manual {
+		name := strings.TrimPrefix(step.ID, KindEnvVar+":")
+		env = append(env, fmt.Sprintf("if [ -z \"${%s:-}\" ] && ! grep -q '^%s=' .env 2>/dev/null; then", name, name))
+		env = append(env, fmt.Sprintf("  echo %s >&2", shQuote(manualEnvMessage(step))), "  missing=1", "fi")
+		script.Manual = append(script.Manual, step.Title)
+	}
+	sections = append(sections, scriptSection{"Environment variables", env})
+
+	commands, held := p.buildCommands()
+	build := []string{
+		`if [ "$missing" -ne 0 ]; then`,
+		`  echo "Set up the prerequisites above, then run this script again." >&2`,
+		"  exit 1",
+		"fi",
+	}
+	build = append(build, commands...)
+	for _, fix := range held {
+		build = append(build, "# Needs confirmation under the fix policy: "+fix)
+	}
+	sections = append(sections, scriptSection{"Dependencies and build", build})
+
+	lines := []string{"#!/usr/bin/env sh"}
+	for _, line := range p.header() {
+		lines = append(lines, "# "+line)
+	}
+	lines = append(lines, "set -eu", "missing=0")
+	return renderSections(lines, sections, "# ")
+}
+
+// powerShellScript renders the plan as a PowerShell script
+func (p *Plan) powerShellScript(script *Script) string {
+	var sections []scriptSection
+
+	var checks []string
+	for _, req := range p.requirements() {
+		checks = append(checks, fmt.Sprintf("if (-not (Get-Command %s -ErrorAction SilentlyContinue)) {", req.binary))
+		checks = append(checks, fmt.Sprintf("    Write-Warning %s", psQuote("Missing "+req.label)))
+		for _, install := range req.install {
+			checks = append(checks, fmt.Sprintf("    Write-Warning %s", psQuote("  e.g. "+install)))
+		}
+		checks = append(checks, "    $missing = $true", "}")
+		script.Manual = append(script.Manual, "Install "+req.label)
+	}
+	sections = append(sections, scriptSection{"Runtimes and tools", checks})
+
+	fixable, manual := p.envSteps()
+	var env []string
+	for _, step := range fixable {
+		name := strings.TrimPrefix(step.ID, KindEnvVar+":")
+		env = append(env, fmt.Sprintf("if (-not (Select-String -Path .env -Pattern '^%s=' -Quiet -ErrorAction SilentlyContinue)) { Add-Content -Path .env -Value %s }", name, psQuote(step.assignment)))
+	}
+	for _, step := range manual {
+		name := strings.TrimPrefix(step.ID, KindEnvVar+":")
+		env = append(env, fmt.Sprintf("if (-not $env:%s -and -not (Select-String -Path .env -Pattern '^%s=' -Quiet -ErrorAction SilentlyContinue)) {", name, name))
+		env = append(env, fmt.Sprintf("    Write-Warning %s", psQuote(manualEnvMessage(step))), "    $missing = $true", "}")
+		script.Manual = append(script.Manual, step.Title)
+	}
+	sections = append(sections, scriptSection{"Environment variables", env})
+
+	commands, held := p.buildCommands()
+	build := []string{
+		"if ($missing) {",
+		`    Write-Error "Set up the prerequisites above, then run this script again."`,
+		"}",
+	}
+	for _, command := range commands {
+		build = append(build, command, "if ($LASTEXITCODE -ne 0) { exit $LASTEXITCODE }")
+	}
+	for _, fix := range held {
+		build = append(build, "# Needs confirmation under the fix policy: "+fix)
+	}
+	sections = append(sections, scriptSection{"Dependencies and build", build})
+
+	var lines []string
+	for _, line := range p.header() {
+		lines = append(lines, "# "+line)
+	}
+	lines = append(lines, "$ErrorActionPreference = 'Stop'", "$missing = $false")
+	return renderSections(lines, sections, "# ")
+}
+
+// renderSections joins a script's preamble and numbered sections, skipping empty sections
+func renderSections(lines []string, sections []scriptSection, comment string) string {
+	n := 0
+	for _, section := range sections {
+		if len(section.lines) == 0 {
+			continue
+		}
+		n++
+		lines = append(lines, "", fmt.Sprintf("%s%d. %s", comment, n, section.title))
+		lines = append(lines, section.lines...)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// manualEnvMessage is what a script prints for a variable it can't set
+func manualEnvMessage(step Step) string {
+	name := strings.TrimPrefix(step.ID, KindEnvVar+":")
+	if step.Detail != "" {
+		return fmt.Sprintf("Set %s in .env: %s", name, step.Detail)
+	}
+	return fmt.Sprintf("Set %s in .env", name)
+}
+
+// commandName returns the program a command line runs
+func commandName(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// targetVersion returns the version of a runtime a setup installs: the first preferred one, else
+// the supported one found on this machine, else the minimum
+func (p *Plan) targetVersion(language string, req config.Requirements) string {
+	if len(req.PreferredVersions) > 0 {
+		return req.PreferredVersions[0]
+	}
+	if version := p.versions[language]; version != "" {
+		return version
+	}
+	return req.MinVersion
+}
+
+// supportedRange describes the versions requirements accept, or "" when any is
+func supportedRange(req config.Requirements) string {
+	switch {
+	case req.MinVersion != "" && req.MaxVersion != "":
+		return fmt.Sprintf("%s to %s", req.MinVersion, req.MaxVersion)
+	case req.MinVersion != "":
+		return req.MinVersion + " or newer"
+	case req.MaxVersion != "":
+		return "up to " + req.MaxVersion
+	}
+	return ""
+}
+
+// shQuote quotes a string for a POSIX shell
+func shQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// psQuote quotes a string for PowerShell
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,78 @@
+package onboard
+
+import (
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptPlan is the plan of a Maven project missing two variables and with a held build fix
+func scriptPlan() *Plan {
+	eco := &detector.DetectedEcosystem{ID: "java-maven", Config: &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		ID: "java-maven",
+		VersionConfig: config.VersionConfig{
+			Language:        "java",
+			VersionCommand:  "java -version 2>&1",
+			VersionManagers: []config.VersionManager{{Name: "sdkman", InstallCommand: "sdk install java {version}"}},
+		},
+		Requirements:   config.Requirements{MinVersion: "11", PreferredVersions: []string{"17", "21"}},
+		Infrastructure: config.Infrastructure{Services: []config.Service{{Name: "maven", Type: "command", CheckCommand: "mvn --version"}}},
+		Dependencies:   config.Dependencies{ResolveCommand: "mvn dependency:resolve"},
+	}}}
+	return &Plan{
+		ProjectRoot: "/work/api",
+		Ecosystems:  []string{"java-maven"},
+		ecosystems:  []*detector.DetectedEcosystem{eco},
+		Steps: []Step{
+			{ID: "env_var:SPRING_PROFILES_ACTIVE", Kind: KindEnvVar, Title: "Set SPRING_PROFILES_ACTIVE", Fixable: true, assignment: "SPRING_PROFILES_ACTIVE=dev"},
+			{ID: "env_var:API_TOKEN", Kind: KindEnvVar, Title: "Set API_TOKEN", Detail: "API_TOKEN looks like a secret"},
+			{ID: "build:abc", Kind: KindBuild, Title: "Fix stale build", Fixable: true, Commands: []string{"mvn compile"}},
+			{ID: "build:def", Kind: KindBuild, Title: "Fix stale cache", Fixable: true, Confirm: true, Commands: []string{"mvn clean"}},
+		},
+	}
+}
+
+func TestScript_Shell(t *testing.T) {
+	script, err := scriptPlan().Script("")
+	require.NoError(t, err)
+	assert.Equal(t, FormatShell, script.Format)
+	assert.Equal(t, "setup.sh", script.Path)
+	for _, line := range []string{
+		"#!/usr/bin/env sh",
+		"# Sets up a machine to work on api (java-maven).",
+		"if ! command -v java >/dev/null 2>&1; then",
+		"  echo 'Missing java 17 (supported: 11 or newer)' >&2",
+		"  echo '  e.g. sdk install java 17' >&2",
+		"if ! command -v mvn >/dev/null 2>&1; then",
+		"grep -q '^SPRING_PROFILES_ACTIVE=' .env 2>/dev/null || echo 'SPRING_PROFILES_ACTIVE=dev' >> .env",
+		`if [ -z "${API_TOKEN:-}" ] && ! grep -q '^API_TOKEN=' .env 2>/dev/null; then`,
+		"  echo 'Set API_TOKEN in .env: API_TOKEN looks like a secret' >&2",
+		"mvn dependency:resolve\nmvn compile\n",
+		"# Needs confirmation under the fix policy: Fix stale cache: mvn clean",
+	} {
+		assert.Contains(t, script.Content, line)
+	}
+	assert.Contains(t, script.Manual, "Set API_TOKEN")
+}
+
+func TestScript_PowerShell(t *testing.T) {
+	script, err := scriptPlan().Script(FormatPowerShell)
+	require.NoError(t, err)
+	assert.Equal(t, "setup.ps1", script.Path)
+	for _, line := range []string{
+		"if (-not (Get-Command java -ErrorAction SilentlyContinue)) {",
+		"if (-not (Select-String -Path .env -Pattern '^SPRING_PROFILES_ACTIVE=' -Quiet -ErrorAction SilentlyContinue)) { Add-Content -Path .env -Value 'SPRING_PROFILES_ACTIVE=dev' }",
+		"if (-not $env:API_TOKEN -and",
+		"mvn dependency:resolve\nif ($LASTEXITCODE -ne 0) { exit $LASTEXITCODE }",
+	} {
+		assert.Contains(t, script.Content, line)
+	}
+}
+
+func TestScript_UnknownFormat(t *testing.T) {
+	_, err := scriptPlan().Script("makefile")
+	assert.ErrorContains(t, err, "unknown format makefile (available: sh, powershell, devcontainer)")
+}
@@ -0,0 +1,89 @@
+package portability
+
+import (
+	"path"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+)
+
+// attributeRule is one pattern line of a .gitattributes file
+type attributeRule struct {
+	pattern string
+	attrs   map[string]string // Attribute name -> value ("set" for bare, "unset" for -name)
+}
+
+// gitAttributes holds the rules of a .gitattributes file in file order
+type gitAttributes []attributeRule
+
+// parseGitAttributes reads a .gitattributes file
+func parseGitAttributes(file string) (gitAttributes, error) {
+	content, err := common.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules gitAttributes
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		rule := attributeRule{pattern: fields[0], attrs: make(map[string]string)}
+		for _, attr := range fields[1:] {
+			switch {
+			case strings.HasPrefix(attr, "-"):
+				rule.attrs[attr[1:]] = "unset"
+			case strings.Contains(attr, "="):
+				parts := strings.SplitN(attr, "=", 2)
+				rule.attrs[parts[0]] = parts[1]
+			case attr == "binary":
+				rule.attrs["text"] = "unset"
+				rule.attrs["diff"] = "unset"
+			default:
+				rule.attrs[attr] = "set"
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// eol returns the line ending git enforces on checkout for a path ("lf", "crlf" or "").
+// Later rules override earlier ones, as in git.
+func (g gitAttributes) eol(rel string) string {
+	eol, text := "", ""
+	for _, rule := range g {
+		if !rule.matches(rel) {
+			continue
+		}
+		if value, ok := rule.attrs["text"]; ok {
+			text = value
+		}
+		if value, ok := rule.attrs["eol"]; ok {
+			eol = value
+		}
+	}
+	if text == "unset" {
+		return ""
+	}
+	return eol
+}
+
+// matches reports whether a rule's pattern applies to a slash-separated relative path.
+// Patterns without a slash match the file name at any depth.
+func (r attributeRule) matches(rel string) bool {
+	pattern := r.pattern
+	if !strings.Contains(strings.TrimSuffix(pattern, "/"), "/") {
+		ok, _ := path.Match(pattern, path.Base(rel))
+		return ok
+	}
+
+	pattern = strings.TrimPrefix(pattern, "/")
+	if strings.HasPrefix(pattern, "**/") {
+		ok, _ := path.Match(strings.TrimPrefix(pattern, "**/"), path.Base(rel))
+		return ok
+	}
+	ok, _ := path.Match(pattern, rel)
+	return ok
+}
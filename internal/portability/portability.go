@@ -0,0 +1,270 @@
+package portability
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/runner"
+)
+
+// Issue types reported by the portability check
+const (
+	IssueCRLFScript    = "crlf_script"
+	IssueNotExecutable = "not_executable"
+	IssueGitAttributes = "gitattributes"
+	IssueAutoCRLF      = "core_autocrlf"
+)
+
+// wrapperScripts must always be executable and use LF endings
+var wrapperScripts = map[string]bool{"mvnw": true, "gradlew": true}
+
+// skipDirs are not scanned for scripts
+var skipDirs = map[string]bool{
+	".git": true, "node_modules": true, "target": true, "build": true,
+	"dist": true, "vendor": true, ".venv": true, "venv": true, ".gradle": true,
+}
+
+// maxScanBytes bounds how much of each script is read to look for CRLF endings
+const maxScanBytes = 64 * 1024
+
+// Issue is a portability problem with an optional fix
+type Issue struct {
+	Type       string
+	File       string
+	Message    string
+	FixCommand string
+}
+
+// PortabilityReport contains line-ending and file-mode check results
+type PortabilityReport struct {
+	Scripts   []string // Shell scripts found in the project, relative to the root
+	IsHealthy bool
+	Issues    []Issue
+}
+
+// CheckPortability checks shell scripts for CRLF endings and missing executable
+// bits, and checks .gitattributes and core.autocrlf for settings that cause them
+func CheckPortability(ctx context.Context, projectRoot string) (*PortabilityReport, error) {
+	report := &PortabilityReport{
+		Scripts:   []string{},
+		IsHealthy: true,
+		Issues:    []Issue{},
+	}
+
+	scripts, err := findScripts(ctx, projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan for scripts: %w", err)
+	}
+
+	for _, script := range scripts {
+		rel, _ := filepath.Rel(projectRoot, script)
+		rel = filepath.ToSlash(rel)
+		report.Scripts = append(report.Scripts, rel)
+
+		crlf, err := hasCRLF(script)
+		if err == nil && crlf {
+			report.add(Issue{
+				Type:       IssueCRLFScript,
+				File:       script,
+				Message:    fmt.Sprintf("%s has Windows (CRLF) line endings; it will fail with '/bin/sh^M: bad interpreter'", rel),
+				FixCommand: crlfFixCommand(rel),
+			})
+		}
+
+		// Executable bits are meaningless on Windows file systems
+		if runtime.GOOS != "windows" {
+			if info, err := common.Stat(script); err == nil && info.Mode().Perm()&0111 == 0 {
+				fix := fmt.Sprintf("chmod +x %s", rel)
+				if indexMode(ctx, projectRoot, rel) == "100644" {
+					// Record the bit in git so fresh clones get it too
+					fix += fmt.Sprintf(" && git update-index --chmod=+x %s", rel)
+				}
+				report.add(Issue{
+					Type:       IssueNotExecutable,
+					File:       script,
+					Message:    fmt.Sprintf("%s is not executable ('permission denied' when run directly)", rel),
+					FixCommand: fix,
+				})
+			}
+		}
+	}
+
+	if len(scripts) == 0 {
+		return report, nil
+	}
+
+	attributes, err := parseGitAttributes(filepath.Join(projectRoot, ".gitattributes"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read .gitattributes: %w", err)
+	}
+
+	var unprotected []string
+	for _, rel := range report.Scripts {
+		if attributes.eol(rel) != "lf" {
+			unprotected = append(unprotected, rel)
+		}
+	}
+	if len(unprotected) > 0 {
+		report.add(Issue{
+			Type:       IssueGitAttributes,
+			File:       filepath.Join(projectRoot, ".gitattributes"),
+			Message:    fmt.Sprintf(".gitattributes does not force LF endings for shell scripts (%s); checkouts with core.autocrlf=true will convert them to CRLF", strings.Join(unprotected, ", ")),
+			FixCommand: fmt.Sprintf("printf '%s' >> .gitattributes", strings.Join(suggestedAttributes(unprotected), `\n`)+`\n`),
+		})
+	}
+
+	if autocrlf := gitConfig(ctx, projectRoot, "core.autocrlf"); autocrlf == "true" && runtime.GOOS != "windows" {
+		report.add(Issue{
+			Type:       IssueAutoCRLF,
+			Message:    "git core.autocrlf is 'true' on a non-Windows system; files are checked out with CRLF endings",
+			FixCommand: "git config core.autocrlf input",
+		})
+	}
+
+	return report, nil
+}
+
+// add records an issue and marks the report unhealthy
+func (r *PortabilityReport) add(issue Issue) {
+	r.Issues = append(r.Issues, issue)
+	r.IsHealthy = false
+}
+
+// findScripts finds shell scripts: *.sh/*.bash files, wrapper scripts and files with a shell shebang
+func findScripts(ctx context.Context, projectRoot string) ([]string, error) {
+	var scripts []string
+	err := common.WalkDir(projectRoot, func(path string, d os.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != projectRoot && skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if isScript(path) {
+			scripts = append(scripts, path)
+		}
+		return nil
+	})
+	sort.Strings(scripts)
+	return scripts, err
+}
+
+// isScript reports whether a file is a shell script
+func isScript(path string) bool {
+	name := filepath.Base(path)
+	if wrapperScripts[name] {
+		return true
+	}
+	switch filepath.Ext(name) {
+	case ".sh", ".bash":
+		return true
+	case "":
+		return hasShellShebang(path)
+	}
+	return false
+}
+
+// hasShellShebang reports whether an extensionless file starts with a shell shebang
+func hasShellShebang(path string) bool {
+	f, err := common.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(io.LimitReader(f, 128)).ReadString('\n')
+	if err != nil && line == "" {
+		return false
+	}
+	if !strings.HasPrefix(line, "#!") {
+		return false
+	}
+	for _, shell := range []string{"/sh", "/bash", "/zsh", "env sh", "env bash", "env zsh"} {
+		if strings.Contains(line, shell) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCRLF reports whether the start of a file contains CRLF line endings
+func hasCRLF(path string) (bool, error) {
+	f, err := common.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxScanBytes))
+	if err != nil {
+		return false, err
+	}
+	return bytes.Contains(data, []byte("\r\n")), nil
+}
+
+// crlfFixCommand converts a file to LF endings, preferring dos2unix when installed
+func crlfFixCommand(rel string) string {
+	if _, err := exec.LookPath("dos2unix"); err == nil {
+		return fmt.Sprintf("dos2unix %s", rel)
+	}
+	return fmt.Sprintf("sed -i.bak 's/\\r$//' %s && rm %s.bak", rel, rel)
+}
+
+// suggestedAttributes returns .gitattributes rules that force LF for the given scripts
+func suggestedAttributes(scripts []string) []string {
+	seen := make(map[string]bool)
+	var rules []string
+	for _, script := range scripts {
+		rule := filepath.Base(script) + " text eol=lf"
+		switch ext := filepath.Ext(script); ext {
+		case ".sh", ".bash":
+			rule = "*" + ext + " text eol=lf"
+		}
+		if !seen[rule] {
+			seen[rule] = true
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// indexMode returns the file mode git records for a path ("100644", "100755"), or "" if unknown
+func indexMode(ctx context.Context, projectRoot, rel string) string {
+	output, err := runner.Run(ctx, projectRoot, fmt.Sprintf("git ls-files -s -- '%s'", rel))
+	if err != nil {
+		return ""
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// gitConfig returns a git config value for the project, or "" if unset or git is unavailable
+func gitConfig(ctx context.Context, projectRoot, key string) string {
+	output, err := runner.Run(ctx, projectRoot, "git config --get "+key)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(string(output)))
+}
@@ -0,0 +1,142 @@
+package portability
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// isolateGit keeps the user's git config out of the checks
+func isolateGit(t *testing.T) {
+	t.Helper()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+}
+
+func writeFile(t *testing.T, path, content string, mode os.FileMode) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), mode))
+	require.NoError(t, os.Chmod(path, mode))
+}
+
+func issueTypes(report *PortabilityReport) map[string][]string {
+	types := make(map[string][]string)
+	for _, issue := range report.Issues {
+		types[issue.Type] = append(types[issue.Type], filepath.Base(issue.File))
+	}
+	return types
+}
+
+func TestCheckPortability(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh and POSIX file modes")
+	}
+	isolateGit(t)
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "mvnw"), "#!/bin/sh\necho ok\n", 0644)
+	writeFile(t, filepath.Join(root, "scripts", "deploy.sh"), "#!/bin/bash\r\necho deploy\r\n", 0755)
+	writeFile(t, filepath.Join(root, "bin", "run"), "#!/usr/bin/env bash\necho run\n", 0755)
+	writeFile(t, filepath.Join(root, "README"), "not a script\r\n", 0644)
+	writeFile(t, filepath.Join(root, "node_modules", "pkg", "x.sh"), "echo\r\n", 0644)
+	writeFile(t, filepath.Join(root, ".gitattributes"), "* text=auto\n*.sh text eol=lf\n", 0644)
+
+	report, err := CheckPortability(context.Background(), root)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"bin/run", "mvnw", "scripts/deploy.sh"}, report.Scripts)
+	assert.False(t, report.IsHealthy)
+
+	types := issueTypes(report)
+	assert.Equal(t, []string{"deploy.sh"}, types[IssueCRLFScript])
+	assert.Equal(t, []string{"mvnw"}, types[IssueNotExecutable])
+	assert.Equal(t, []string{".gitattributes"}, types[IssueGitAttributes])
+	assert.Empty(t, types[IssueAutoCRLF])
+
+	for _, issue := range report.Issues {
+		switch issue.Type {
+		case IssueNotExecutable:
+			assert.Equal(t, "chmod +x mvnw", issue.FixCommand)
+		case IssueGitAttributes:
+			assert.Contains(t, issue.Message, "bin/run, mvnw")
+			assert.Contains(t, issue.FixCommand, `run text eol=lf\nmvnw text eol=lf`)
+		}
+	}
+}
+
+func TestCheckPortability_Healthy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires POSIX file modes")
+	}
+	isolateGit(t)
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "gradlew"), "#!/bin/sh\n", 0755)
+	writeFile(t, filepath.Join(root, ".gitattributes"), "gradlew text eol=lf\n", 0644)
+
+	report, err := CheckPortability(context.Background(), root)
+	require.NoError(t, err)
+	assert.True(t, report.IsHealthy, report.Issues)
+
+	empty, err := CheckPortability(context.Background(), t.TempDir())
+	require.NoError(t, err)
+	assert.True(t, empty.IsHealthy)
+	assert.Empty(t, empty.Scripts)
+}
+
+func TestCheckPortability_Git(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires POSIX file modes")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("requires git")
+	}
+	isolateGit(t)
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "gradlew"), "#!/bin/sh\n", 0644)
+	writeFile(t, filepath.Join(root, ".gitattributes"), "gradlew text eol=lf\n", 0644)
+	for _, args := range [][]string{{"init", "-q"}, {"config", "core.autocrlf", "true"}, {"add", "gradlew"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		require.NoError(t, cmd.Run())
+	}
+
+	report, err := CheckPortability(context.Background(), root)
+	require.NoError(t, err)
+
+	var fixes = make(map[string]string)
+	for _, issue := range report.Issues {
+		fixes[issue.Type] = issue.FixCommand
+	}
+	assert.Equal(t, "chmod +x gradlew && git update-index --chmod=+x gradlew", fixes[IssueNotExecutable])
+	assert.Equal(t, "git config core.autocrlf input", fixes[IssueAutoCRLF])
+}
+
+func TestGitAttributesEOL(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ".gitattributes")
+	require.NoError(t, os.WriteFile(file, []byte(`# comment
+* text=auto
+*.sh text eol=lf
+/tools/*.sh -text
+**/gradlew eol=lf
+legacy.sh eol=crlf
+`), 0644))
+
+	attributes, err := parseGitAttributes(file)
+	require.NoError(t, err)
+
+	assert.Equal(t, "lf", attributes.eol("build.sh"))
+	assert.Equal(t, "lf", attributes.eol("deep/dir/build.sh"))
+	assert.Equal(t, "", attributes.eol("tools/gen.sh"), "-text disables conversion")
+	assert.Equal(t, "lf", attributes.eol("sub/gradlew"))
+	assert.Equal(t, "crlf", attributes.eol("legacy.sh"), "later rules win")
+	assert.Equal(t, "", attributes.eol("mvnw"))
+}
@@ -0,0 +1,221 @@
+// Package preflight answers whether a project's environment is ready for a build or test run
+// within a small time budget, so agents can check it before every attempt. Checks that don't
+// finish in time are answered from their previous run and keep running to refresh it.
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"dev-env-sentinel/internal/auditor"
+	"dev-env-sentinel/internal/infra"
+	"dev-env-sentinel/internal/verifier"
+)
+
+// DefaultBudget is how long a preflight waits for its checks
+const DefaultBudget = 500 * time.Millisecond
+
+// MaxBlockers is how many blocking issues a report lists
+const MaxBlockers = 3
+
+// checkTimeout bounds a check that keeps running after the budget to refresh the cache
+const checkTimeout = 2 * time.Minute
+
+// Check is a check a preflight runs, by tool name
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (interface{}, error)
+}
+
+// Blocker is an issue that stops a build or test run from succeeding, with its fix
+type Blocker struct {
+	Check       string
+	Type        string
+	Severity    string
+	Message     string
+	Fix         string // Command or action that fixes it, if known
+	Fingerprint string // Fix to pass to reconcile_issue, for build fixes
+}
+
+// Report is the outcome of a preflight
+type Report struct {
+	ProjectRoot   string
+	Passed        bool
+	Blockers      []Blocker // The most severe blocking issues, at most MaxBlockers
+	TotalBlockers int
+	Warnings      int               // Issues that don't block a run
+	Cached        map[string]string // Checks answered from their previous run -> age of that run
+	Pending       []string          // Checks still running, with no previous run to answer from
+	Errors        []string          // Checks that failed to run
+	Elapsed       time.Duration
+}
+
+// severityRank orders blockers, most severe first
+var severityRank = map[string]int{"critical": 0, "error": 1}
+
+// Cache keeps the latest result of each check per project
+type Cache struct {
+	mu      sync.Mutex
+	results map[string]cached
+	now     func() time.Time
+}
+
+type cached struct {
+	result interface{}
+	at     time.Time
+}
+
+// NewCache creates an empty cache
+func NewCache() *Cache {
+	return &Cache{results: make(map[string]cached), now: time.Now}
+}
+
+// store keeps the result of a check that ran
+func (c *Cache) store(projectRoot, check string, result interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results[projectRoot+"\x00"+check] = cached{result: result, at: c.now()}
+}
+
+// load returns the latest result of a check and its age
+func (c *Cache) load(projectRoot, check string) (interface{}, time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.results[projectRoot+"\x00"+check]
+	if !ok {
+		return nil, 0, false
+	}
+	return entry.result, c.now().Sub(entry.at), true
+}
+
+// outcome is the result of a check run
+type outcome struct {
+	check  string
+	result interface{}
+	err    error
+}
+
+// Run runs the checks concurrently and reports on those that finish within the budget. The
+// others are answered from the cache and keep running in the background to refresh it.
+func Run(ctx context.Context, projectRoot string, checks []Check, cache *Cache, budget time.Duration) *Report {
+	start := time.Now()
+	report := &Report{ProjectRoot: projectRoot, Blockers: []Blocker{}, Cached: map[string]string{}, Pending: []string{}, Errors: []string{}}
+
+	done := make(chan outcome, len(checks))
+	background := context.WithoutCancel(ctx)
+	for _, check := range checks {
+		go func(check Check) {
+			checkCtx, cancel := context.WithTimeout(background, checkTimeout)
+			defer cancel()
+			result, err := check.Run(checkCtx)
+			if err == nil {
+				cache.store(projectRoot, check.Name, result)
+			}
+			done <- outcome{check: check.Name, result: result, err: err}
+		}(check)
+	}
+
+	results := make(map[string]outcome)
+	timer := time.NewTimer(budget)
+	defer timer.Stop()
+wait:
+	for len(results) < len(checks) {
+		select {
+		case o := <-done:
+			results[o.check] = o
+		case <-timer.C:
+			break wait
+		case <-ctx.Done():
+			break wait
+		}
+	}
+
+	var blockers []Blocker
+	for _, check := range checks {
+		o, finished := results[check.Name]
+		switch {
+		case finished && o.err != nil:
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", check.Name, o.err))
+			continue
+		case !finished:
+			result, age, ok := cache.load(projectRoot, check.Name)
+			if !ok {
+				report.Pending = append(report.Pending, check.Name)
+				continue
+			}
+			report.Cached[check.Name] = age.Round(time.Second).String()
+			o.result = result
+		}
+		found, warnings := Collect(check.Name, o.result)
+		blockers = append(blockers, found...)
+		report.Warnings += warnings
+	}
+
+	sort.SliceStable(blockers, func(i, j int) bool {
+		return severityRank[blockers[i].Severity] < severityRank[blockers[j].Severity]
+	})
+	report.TotalBlockers = len(blockers)
+	if len(blockers) > MaxBlockers {
+		blockers = blockers[:MaxBlockers]
+	}
+	report.Blockers = append(report.Blockers, blockers...)
+	report.Passed = report.TotalBlockers == 0
+	report.Elapsed = time.Since(start)
+	return report
+}
+
+// Collect returns the blocking issues of a check's result with their fixes, and how many of
+// its issues don't block a run
+func Collect(check string, result interface{}) ([]Blocker, int) {
+	var blockers []Blocker
+	warnings := 0
+	switch v := result.(type) {
+	case *verifier.FreshnessReport:
+		for _, issue := range v.Issues {
+			if _, blocking := severityRank[issue.Severity]; !blocking {
+				warnings++
+				continue
+			}
+			blocker := Blocker{Check: check, Type: issue.Type, Severity: issue.Severity, Message: issue.Message}
+			if issue.FixAvailable {
+				blocker.Fix = issue.FixCommand
+			}
+			if issue.Fix != nil {
+				blocker.Fingerprint = issue.Fix.Fingerprint
+			}
+			blockers = append(blockers, blocker)
+		}
+	case *auditor.EnvVarReport:
+		for _, name := range v.Missing {
+			blocker := Blocker{Check: check, Type: "missing_env_var", Severity: "error", Message: fmt.Sprintf("Environment variable %s is not set", name)}
+			switch {
+			case v.Direnv.Fixes(name):
+				blocker.Fix = v.Direnv.Fix
+			case v.Template != nil:
+				blocker.Fix = fmt.Sprintf("generate_dotenv from %s", v.Template.Template)
+			default:
+				blocker.Fix = fmt.Sprintf("Set %s in the environment or .env", name)
+			}
+			blockers = append(blockers, blocker)
+		}
+	case *infra.InfrastructureReport:
+		for _, service := range v.Services {
+			if service.Healthy {
+				continue
+			}
+			issueType := service.Issue
+			if issueType == "" {
+				issueType = infra.IssueServiceUnavailable
+			}
+			blocker := Blocker{Check: check, Type: issueType, Severity: "error", Message: fmt.Sprintf("%s: %s", service.Name, service.Message)}
+			if len(service.Suggestions) > 0 {
+				blocker.Fix = service.Suggestions[0]
+			}
+			blockers = append(blockers, blocker)
+		}
+	}
+	return blockers, warnings
+}
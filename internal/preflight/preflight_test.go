@@ -0,0 +1,107 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/auditor"
+	"dev-env-sentinel/internal/infra"
+	"dev-env-sentinel/internal/verifier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func result(v interface{}) func(ctx context.Context) (interface{}, error) {
+	return func(ctx context.Context) (interface{}, error) { return v, nil }
+}
+
+func TestRun_RanksAndLimitsBlockers(t *testing.T) {
+	freshness := &verifier.FreshnessReport{Issues: []verifier.Issue{
+		{Type: "stale_build", Severity: "error", Message: "Build is stale", FixAvailable: true, FixCommand: "npm run build", Fix: &verifier.FixDescriptor{Fingerprint: "abc"}},
+		{Type: "orphaned_outputs", Severity: "warning", Message: "Orphaned outputs"},
+		{Type: "stale_cache", Severity: "critical", Message: "Cache is corrupt", FixAvailable: true, FixCommand: "npm cache clean --force"},
+	}}
+	env := &auditor.EnvVarReport{Missing: []string{"API_URL", "DB_URL"}}
+	services := &infra.InfrastructureReport{Services: []infra.ServiceStatus{
+		{Name: "postgres", Healthy: true},
+		{Name: "redis", Message: "not running", Suggestions: []string{"docker compose up -d redis"}},
+	}}
+	checks := []Check{
+		{Name: "verify_build_freshness", Run: result(freshness)},
+		{Name: "env_var_audit", Run: result(env)},
+		{Name: "check_infrastructure_parity", Run: result(services)},
+	}
+
+	report := Run(context.Background(), "/project", checks, NewCache(), time.Second)
+	assert.False(t, report.Passed)
+	assert.Equal(t, 5, report.TotalBlockers)
+	assert.Equal(t, 1, report.Warnings)
+	require.Len(t, report.Blockers, MaxBlockers)
+	assert.Equal(t, Blocker{Check: "verify_build_freshness", Type: "stale_cache", Severity: "critical", Message: "Cache is corrupt", Fix: "npm cache clean --force"}, report.Blockers[0])
+	assert.Equal(t, Blocker{Check: "verify_build_freshness", Type: "stale_build", Severity: "error", Message: "Build is stale", Fix: "npm run build", Fingerprint: "abc"}, report.Blockers[1])
+	assert.Equal(t, "Environment variable API_URL is not set", report.Blockers[2].Message)
+	assert.Equal(t, "Set API_URL in the environment or .env", report.Blockers[2].Fix)
+	assert.Empty(t, report.Pending)
+	assert.Empty(t, report.Cached)
+}
+
+func TestRun_SlowCheckUsesCache(t *testing.T) {
+	cache := NewCache()
+	release := make(chan struct{})
+	slow := func(ctx context.Context) (interface{}, error) {
+		<-release
+		return &infra.InfrastructureReport{Services: []infra.ServiceStatus{{Name: "redis", Message: "not running"}}}, nil
+	}
+	checks := []Check{
+		{Name: "env_var_audit", Run: result(&auditor.EnvVarReport{})},
+		{Name: "check_infrastructure_parity", Run: slow},
+	}
+
+	// Without an earlier result the slow check is left out, and keeps running
+	report := Run(context.Background(), "/project", checks, cache, 20*time.Millisecond)
+	assert.True(t, report.Passed)
+	assert.Equal(t, []string{"check_infrastructure_parity"}, report.Pending)
+	assert.Less(t, report.Elapsed, time.Second)
+
+	close(release)
+	require.Eventually(t, func() bool {
+		_, _, ok := cache.load("/project", "check_infrastructure_parity")
+		return ok
+	}, time.Second, 5*time.Millisecond)
+
+	// The next preflight answers from that run while the check refreshes
+	release = make(chan struct{})
+	defer close(release)
+	report = Run(context.Background(), "/project", checks, cache, 20*time.Millisecond)
+	assert.False(t, report.Passed)
+	assert.Empty(t, report.Pending)
+	assert.Contains(t, report.Cached, "check_infrastructure_parity")
+	require.Len(t, report.Blockers, 1)
+	assert.Equal(t, "redis: not running", report.Blockers[0].Message)
+}
+
+func TestRun_FailedCheck(t *testing.T) {
+	checks := []Check{{Name: "env_var_audit", Run: func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("permission denied")
+	}}}
+
+	report := Run(context.Background(), "/project", checks, NewCache(), time.Second)
+	assert.True(t, report.Passed)
+	assert.Equal(t, []string{"env_var_audit: permission denied"}, report.Errors)
+}
+
+func TestCollect_EnvVarFixes(t *testing.T) {
+	env := &auditor.EnvVarReport{
+		Missing:  []string{"API_KEY", "PORT"},
+		Direnv:   &auditor.DirenvReport{Provides: []string{"API_KEY"}, Fix: "direnv allow"},
+		Template: &auditor.DotenvTemplateReport{Template: ".env.example"},
+	}
+
+	blockers, warnings := Collect("env_var_audit", env)
+	assert.Zero(t, warnings)
+	require.Len(t, blockers, 2)
+	assert.Equal(t, "direnv allow", blockers[0].Fix)
+	assert.Equal(t, "generate_dotenv from .env.example", blockers[1].Fix)
+}
@@ -0,0 +1,69 @@
+// Package privacy strips what the strict privacy mode doesn't allow on disk from check results
+// and command records: command output, environment variable values and paths outside the
+// project.
+package privacy
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"dev-env-sentinel/internal/runner"
+	"dev-env-sentinel/internal/snapshot"
+)
+
+// Withheld replaces what strict privacy mode doesn't keep
+const Withheld = "[withheld]"
+
+// assignment matches NAME=value environment variable assignments, keeping the name
+var assignment = regexp.MustCompile(`\b([A-Z_][A-Z0-9_]*=)("[^"]*"|'[^']*'|[^\s"']+)`)
+
+// absolutePath matches absolute paths and paths in a home directory, after a boundary such as
+// a space, a quote or the = of an option
+var absolutePath = regexp.MustCompile(`(^|[\s"'=:(,])((?:~|[A-Za-z]:)?[/\\][^\s"'(),;:]*)`)
+
+// Entry returns a check result as strict privacy mode persists it: health, revision, the names
+// of missing variables and where it ran are kept, the report text and error are withheld
+func Entry(entry snapshot.Entry) snapshot.Entry {
+	if entry.Summary != "" {
+		entry.Summary = Withheld
+	}
+	if entry.Error != "" {
+		entry.Error = Withheld
+	}
+	return entry
+}
+
+// Command returns a command record as strict privacy mode logs it: the command line with
+// variable values and outside paths withheld, where and how long it ran, and its exit code
+func Command(record runner.Record) runner.Record {
+	record.Command = Text(record.Command, record.Dir)
+	record.Stdout = nil
+	record.Stderr = nil
+	if record.Error != "" {
+		record.Error = Withheld
+	}
+	return record
+}
+
+// Text withholds the values of environment variable assignments and the paths outside root
+// in a text. An empty root withholds every absolute path.
+func Text(s, root string) string {
+	s = assignment.ReplaceAllString(s, "${1}"+Withheld)
+	return absolutePath.ReplaceAllStringFunc(s, func(match string) string {
+		m := absolutePath.FindStringSubmatch(match)
+		if Inside(m[2], root) {
+			return match
+		}
+		return m[1] + Withheld
+	})
+}
+
+// Inside reports whether a path is root or below it
+func Inside(path, root string) bool {
+	if root == "" || strings.HasPrefix(path, "~") {
+		return false
+	}
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,72 @@
+package privacy
+
+import (
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/runner"
+	"dev-env-sentinel/internal/snapshot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"path inside root", "mvn -f /work/api/pom.xml install", "mvn -f /work/api/pom.xml install"},
+		{"path outside root", "cp /etc/hosts target/", "cp [withheld] target/"},
+		{"sibling of root", "ls /work/api-old", "ls [withheld]"},
+		{"option value", "mvn -Dmaven.repo.local=/home/me/.m2 install", "mvn -Dmaven.repo.local=[withheld] install"},
+		{"home directory", "cat ~/.npmrc", "cat [withheld]"},
+		{"windows path", `dir C:\Users\me`, "dir [withheld]"},
+		{"assignment", "JAVA_HOME=/usr/lib/jvm/java-17 DB_HOST=db.internal mvn test", "JAVA_HOME=[withheld] DB_HOST=[withheld] mvn test"},
+		{"quoted assignment", `export GREETING="hello world"`, "export GREETING=[withheld]"},
+		{"relative paths", "go test ./... && cat target/log.txt", "go test ./... && cat target/log.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Text(tt.in, "/work/api"))
+		})
+	}
+
+	assert.Equal(t, "open [withheld]: no such file", Text("open /work/api/pom.xml: no such file", ""))
+}
+
+func TestEntry(t *testing.T) {
+	entry := snapshot.Entry{
+		Job:            "api",
+		ProjectRoot:    "/work/api",
+		Check:          "env_var_audit",
+		Summary:        "DB_HOST=db.internal is set but JAVA_HOME points to /opt/jdk",
+		Error:          "exit status 1: /usr/bin/mvn not found",
+		Revision:       "abc123",
+		MissingEnvVars: []string{"API_KEY"},
+		Timestamp:      time.Now(),
+	}
+	got := Entry(entry)
+	assert.Equal(t, Withheld, got.Summary)
+	assert.Equal(t, Withheld, got.Error)
+	assert.Equal(t, "/work/api", got.ProjectRoot)
+	assert.Equal(t, "abc123", got.Revision)
+	assert.Equal(t, []string{"API_KEY"}, got.MissingEnvVars)
+	assert.Equal(t, "", Entry(snapshot.Entry{Healthy: true}).Error, "a result without error keeps none")
+}
+
+func TestCommand(t *testing.T) {
+	record := Command(runner.Record{
+		Command:  "JAVA_HOME=/opt/jdk mvn -f /work/api/pom.xml -s /home/me/settings.xml install",
+		Dir:      "/work/api",
+		ExitCode: 1,
+		Error:    "exit status 1",
+		Stdout:   []byte("[INFO] Building api"),
+		Stderr:   []byte("[ERROR] /home/me/.m2 is not writable"),
+	})
+	assert.Equal(t, "JAVA_HOME=[withheld] mvn -f /work/api/pom.xml -s [withheld] install", record.Command)
+	assert.Equal(t, "/work/api", record.Dir)
+	assert.Equal(t, 1, record.ExitCode)
+	assert.Equal(t, Withheld, record.Error)
+	assert.Nil(t, record.Stdout)
+	assert.Nil(t, record.Stderr)
+}
@@ -0,0 +1,152 @@
+package profile
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Built-in profile names
+const (
+	ReadOnly = "read-only"
+	CI       = "ci"
+	Full     = "full"
+)
+
+// mutatingTools change the environment or the sentinel's own state.
+// New tools that execute fix commands or write files must be added here.
+var mutatingTools = map[string]bool{
+	"reconcile_environment": true,
+	"reconcile_issue":       true,
+	"purge_state":           true,
+	"generate_dotenv":       true,
+	"generate_setup_script": true,
+	"fetch_config":          true,
+	"activate_pro":          true,
+	"start_trial":           true,
+	"export_issue":          true,
+	"register_project":      true,
+	"unregister_project":    true,
+}
+
+// interactiveTools only make sense with a human in the loop and are hidden in CI
+var interactiveTools = map[string]bool{
+	"get_pro_license": true,
+	"activate_pro":    true,
+	"start_trial":     true,
+}
+
+// Profile defines which tools are exposed and which kinds of commands may run
+type Profile struct {
+	Name            string
+	Description     string
+	AllowMutating   bool // Expose tools that change the environment or sentinel state
+	AllowFixes      bool // Allow fix commands to execute
+	HideInteractive bool // Hide tools that need a human (license purchase/activation)
+}
+
+// profiles are the built-in profiles
+var profiles = map[string]Profile{
+	ReadOnly: {
+		Name:        ReadOnly,
+		Description: "Diagnostics only; no fix commands and no state changes",
+	},
+	CI: {
+		Name:            CI,
+		Description:     "Diagnostics for pipelines; no fixes and no interactive license tools",
+		HideInteractive: true,
+	},
+	Full: {
+		Name:          Full,
+		Description:   "All tools, including auto-fix",
+		AllowMutating: true,
+		AllowFixes:    true,
+	},
+}
+
+// IsMutating reports whether a tool changes the environment or the sentinel's own state
+func IsMutating(name string) bool {
+	return mutatingTools[name]
+}
+
+// Names returns the built-in profile names
+func Names() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Policy decides which tools are registered and whether fix commands may execute
+type Policy struct {
+	profile  Profile
+	disabled map[string]bool
+}
+
+// NewPolicy creates a policy from a profile name (empty means full) and explicitly disabled tools
+func NewPolicy(name string, disabled []string) (*Policy, error) {
+	if name == "" {
+		name = Full
+	}
+
+	p, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown tool profile: %s (expected one of %s)", name, strings.Join(Names(), ", "))
+	}
+
+	policy := &Policy{profile: p, disabled: make(map[string]bool)}
+	for _, tool := range disabled {
+		if tool = strings.TrimSpace(tool); tool != "" {
+			policy.disabled[tool] = true
+		}
+	}
+	return policy, nil
+}
+
+// Resolve builds the policy from settings, letting SENTINEL_PROFILE override the
+// profile and adding tools listed in SENTINEL_DISABLED_TOOLS (comma-separated)
+func Resolve(name string, disabled []string) (*Policy, error) {
+	if env := os.Getenv("SENTINEL_PROFILE"); env != "" {
+		name = env
+	}
+	if env := os.Getenv("SENTINEL_DISABLED_TOOLS"); env != "" {
+		disabled = append(append([]string{}, disabled...), strings.Split(env, ",")...)
+	}
+	return NewPolicy(name, disabled)
+}
+
+// Name returns the active profile name
+func (p *Policy) Name() string {
+	if p == nil {
+		return Full
+	}
+	return p.profile.Name
+}
+
+// AllowsTool reports whether a tool should be exposed. A nil policy allows everything.
+func (p *Policy) AllowsTool(name string) bool {
+	if p == nil {
+		return true
+	}
+	if p.disabled[name] {
+		return false
+	}
+	if mutatingTools[name] && !p.profile.AllowMutating {
+		return false
+	}
+	if interactiveTools[name] && p.profile.HideInteractive {
+		return false
+	}
+	return true
+}
+
+// AllowsFixes reports whether fix commands may execute. A nil policy allows them.
+func (p *Policy) AllowsFixes() bool {
+	if p == nil {
+		return true
+	}
+	return p.profile.AllowFixes && !p.disabled["reconcile_environment"]
+}
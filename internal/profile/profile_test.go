@@ -0,0 +1,82 @@
+package profile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Profiles(t *testing.T) {
+	tests := []struct {
+		profile string
+		tool    string
+		allowed bool
+	}{
+		{ReadOnly, "verify_build_freshness", true},
+		{ReadOnly, "get_pro_license", true},
+		{ReadOnly, "reconcile_environment", false},
+		{ReadOnly, "purge_state", false},
+		{ReadOnly, "activate_pro", false},
+		{CI, "env_var_audit", true},
+		{CI, "get_pro_license", false},
+		{CI, "reconcile_environment", false},
+		{Full, "reconcile_environment", true},
+		{Full, "purge_state", true},
+		{"", "reconcile_environment", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.profile+"/"+tt.tool, func(t *testing.T) {
+			policy, err := NewPolicy(tt.profile, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.allowed, policy.AllowsTool(tt.tool))
+		})
+	}
+}
+
+func TestPolicy_Disabled(t *testing.T) {
+	policy, err := NewPolicy(Full, []string{"check_locale", " reconcile_environment "})
+	require.NoError(t, err)
+
+	assert.False(t, policy.AllowsTool("check_locale"))
+	assert.False(t, policy.AllowsTool("reconcile_environment"))
+	assert.False(t, policy.AllowsFixes(), "disabling reconcile_environment also blocks fixes")
+	assert.True(t, policy.AllowsTool("env_var_audit"))
+}
+
+func TestPolicy_Fixes(t *testing.T) {
+	readOnly, _ := NewPolicy(ReadOnly, nil)
+	full, _ := NewPolicy(Full, nil)
+	var unset *Policy
+
+	assert.False(t, readOnly.AllowsFixes())
+	assert.True(t, full.AllowsFixes())
+	assert.True(t, unset.AllowsFixes())
+	assert.True(t, unset.AllowsTool("reconcile_environment"))
+	assert.Equal(t, Full, unset.Name())
+}
+
+func TestNewPolicy_Unknown(t *testing.T) {
+	_, err := NewPolicy("admin", nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "ci, full, read-only")
+}
+
+func TestResolve_Environment(t *testing.T) {
+	t.Setenv("SENTINEL_PROFILE", "ci")
+	t.Setenv("SENTINEL_DISABLED_TOOLS", "check_locale,check_trust_stores")
+
+	policy, err := Resolve(Full, []string{"env_var_audit"})
+	require.NoError(t, err)
+	assert.Equal(t, CI, policy.Name())
+	assert.False(t, policy.AllowsTool("check_locale"))
+	assert.False(t, policy.AllowsTool("check_trust_stores"))
+	assert.False(t, policy.AllowsTool("env_var_audit"), "settings and env disabled lists are merged")
+
+	t.Setenv("SENTINEL_PROFILE", "")
+	t.Setenv("SENTINEL_DISABLED_TOOLS", "")
+	policy, err = Resolve(ReadOnly, nil)
+	require.NoError(t, err)
+	assert.Equal(t, ReadOnly, policy.Name())
+}
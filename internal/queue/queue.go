@@ -0,0 +1,363 @@
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWorkers is the number of tool calls executed concurrently
+	DefaultWorkers = 2
+	// DefaultQueueSize is the number of calls that may wait for a worker
+	DefaultQueueSize = 100
+	// MaxFinishedJobs is the number of finished jobs kept for status queries
+	MaxFinishedJobs = 500
+)
+
+// Job states
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Func is the work of a job
+type Func func(ctx context.Context) (interface{}, error)
+
+type jobIDKey struct{}
+
+type queueKey struct{}
+
+// JobID returns the ID of the job running with ctx, or "" outside a job
+func JobID(ctx context.Context) string {
+	id, _ := ctx.Value(jobIDKey{}).(string)
+	return id
+}
+
+// ErrQueueFull is returned when a job is submitted to a full queue
+type ErrQueueFull struct {
+	Queued int
+}
+
+func (e *ErrQueueFull) Error() string {
+	return fmt.Sprintf("server busy: %d requests are already queued, try again later", e.Queued)
+}
+
+// Job is a point-in-time view of a submitted job
+type Job struct {
+	ID         string        `json:"id"`
+	Name       string        `json:"name"`
+	Owner      string        `json:"owner,omitempty"` // Who may see the job, e.g. a project ID
+	Status     string        `json:"status"`
+	Position   int           `json:"position,omitempty"` // 1-based place in the queue while queued
+	ETA        time.Duration `json:"eta,omitempty"`      // Estimated time until the job starts
+	EnqueuedAt time.Time     `json:"enqueued_at"`
+	StartedAt  time.Time     `json:"started_at,omitempty"`
+	FinishedAt time.Time     `json:"finished_at,omitempty"`
+	Progress   *Progress     `json:"progress,omitempty"` // Reported by the job while it runs
+	Result     interface{}   `json:"-"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// Progress is how far a running job has got
+type Progress struct {
+	Done    int    `json:"done"`
+	Total   int    `json:"total"`
+	Message string `json:"message,omitempty"`
+}
+
+// SetProgress records the progress of the job running with ctx and publishes it to the
+// subscribers. Outside a job it does nothing.
+func SetProgress(ctx context.Context, progress Progress) {
+	q, _ := ctx.Value(queueKey{}).(*Queue)
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[JobID(ctx)]
+	if !ok || j.Finished() {
+		return
+	}
+	j.Progress = &progress
+	q.publish(j)
+}
+
+// Finished reports whether the job has completed
+func (j Job) Finished() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed
+}
+
+// Waited returns how long the job waited for a worker
+func (j Job) Waited() time.Duration {
+	if j.StartedAt.IsZero() {
+		return time.Since(j.EnqueuedAt)
+	}
+	return j.StartedAt.Sub(j.EnqueuedAt)
+}
+
+// job is the mutable state of a submitted job, guarded by the queue's lock
+type job struct {
+	Job
+	fn   Func
+	done chan struct{}
+}
+
+// Queue runs jobs on a fixed number of workers in submission order, so bursts of
+// requests wait instead of all running at once
+type Queue struct {
+	mu        sync.Mutex
+	ready     *sync.Cond
+	workers   int
+	maxQueued int
+	pending   []*job
+	jobs      map[string]*job
+	finished  []string // IDs of finished jobs, oldest first
+	average   time.Duration
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closed    bool
+
+	subscribers map[int]chan Job
+	nextSub     int
+}
+
+// subscriberBuffer is the number of events a subscriber may fall behind before missing some
+const subscriberBuffer = 64
+
+// New starts a queue with the given number of workers and queue size. Zero values use the defaults.
+func New(workers, maxQueued int) *Queue {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	if maxQueued <= 0 {
+		maxQueued = DefaultQueueSize
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &Queue{
+		workers:   workers,
+		maxQueued: maxQueued,
+		jobs:      make(map[string]*job),
+		ctx:       ctx,
+		cancel:    cancel,
+
+		subscribers: make(map[int]chan Job),
+	}
+	q.ready = sync.NewCond(&q.mu)
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+// Workers returns the number of workers
+func (q *Queue) Workers() int {
+	return q.workers
+}
+
+// Submit queues a job and returns its state at submission
+func (q *Queue) Submit(name, owner string, fn Func) (Job, error) {
+	id, err := newID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return Job{}, fmt.Errorf("queue is closed")
+	}
+	if len(q.pending) >= q.maxQueued {
+		return Job{}, &ErrQueueFull{Queued: len(q.pending)}
+	}
+
+	j := &job{
+		Job:  Job{ID: id, Name: name, Owner: owner, Status: StatusQueued, EnqueuedAt: time.Now()},
+		fn:   fn,
+		done: make(chan struct{}),
+	}
+	q.jobs[id] = j
+	q.pending = append(q.pending, j)
+	q.ready.Signal()
+	q.publish(j)
+	return q.view(j), nil
+}
+
+// Subscribe returns a channel receiving every job state change (queued, running, finished)
+// and a function ending the subscription. A subscriber that falls behind misses events
+// instead of blocking the queue.
+func (q *Queue) Subscribe() (<-chan Job, func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	id := q.nextSub
+	q.nextSub++
+	events := make(chan Job, subscriberBuffer)
+	q.subscribers[id] = events
+
+	return events, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		if _, ok := q.subscribers[id]; ok {
+			delete(q.subscribers, id)
+			close(events)
+		}
+	}
+}
+
+// publish sends a job's state to the subscribers. Callers hold the lock.
+func (q *Queue) publish(j *job) {
+	view := q.view(j)
+	for _, events := range q.subscribers {
+		select {
+		case events <- view:
+		default:
+		}
+	}
+}
+
+// Get returns the current state of a job
+func (q *Queue) Get(id string) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return q.view(j), true
+}
+
+// Wait blocks until a job finishes or ctx is done and returns its state
+func (q *Queue) Wait(ctx context.Context, id string) (Job, error) {
+	q.mu.Lock()
+	j, ok := q.jobs[id]
+	q.mu.Unlock()
+	if !ok {
+		return Job{}, fmt.Errorf("unknown job: %s", id)
+	}
+
+	select {
+	case <-j.done:
+	case <-ctx.Done():
+		return q.mustGet(id), ctx.Err()
+	}
+	return q.mustGet(id), nil
+}
+
+// Close stops the workers after their current jobs and cancels the contexts of running jobs.
+// Queued jobs are failed.
+func (q *Queue) Close() {
+	q.mu.Lock()
+	q.closed = true
+	for _, j := range q.pending {
+		q.finish(j, nil, fmt.Errorf("server shutting down"))
+	}
+	q.pending = nil
+	q.ready.Broadcast()
+	q.mu.Unlock()
+	q.cancel()
+}
+
+// mustGet returns a job known to exist
+func (q *Queue) mustGet(id string) Job {
+	job, _ := q.Get(id)
+	return job
+}
+
+// work runs queued jobs until the queue is closed
+func (q *Queue) work() {
+	for {
+		q.mu.Lock()
+		for len(q.pending) == 0 && !q.closed {
+			q.ready.Wait()
+		}
+		if q.closed {
+			q.mu.Unlock()
+			return
+		}
+		j := q.pending[0]
+		q.pending = q.pending[1:]
+		j.Status = StatusRunning
+		j.StartedAt = time.Now()
+		q.publish(j)
+		q.mu.Unlock()
+
+		ctx := context.WithValue(context.WithValue(q.ctx, jobIDKey{}, j.ID), queueKey{}, q)
+		result, err := run(ctx, j.fn)
+
+		q.mu.Lock()
+		q.finish(j, result, err)
+		q.mu.Unlock()
+	}
+}
+
+// run executes a job, turning a panic into an error so one bad call cannot stop a worker
+func run(ctx context.Context, fn Func) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+// finish records the outcome of a job. Callers hold the lock.
+func (q *Queue) finish(j *job, result interface{}, err error) {
+	j.FinishedAt = time.Now()
+	j.Result = result
+	j.Status = StatusSucceeded
+	if err != nil {
+		j.Status = StatusFailed
+		j.Error = err.Error()
+	}
+	close(j.done)
+	q.publish(j)
+
+	if !j.StartedAt.IsZero() {
+		// Moving average of run times, used to estimate how long queued jobs wait
+		duration := j.FinishedAt.Sub(j.StartedAt)
+		if q.average == 0 {
+			q.average = duration
+		} else {
+			q.average = (q.average*4 + duration) / 5
+		}
+	}
+
+	q.finished = append(q.finished, j.ID)
+	for len(q.finished) > MaxFinishedJobs {
+		delete(q.jobs, q.finished[0])
+		q.finished = q.finished[1:]
+	}
+}
+
+// view returns a copy of a job with its queue position and ETA. Callers hold the lock.
+func (q *Queue) view(j *job) Job {
+	view := j.Job
+	if j.Status != StatusQueued {
+		return view
+	}
+	for i, pending := range q.pending {
+		if pending == j {
+			view.Position = i + 1
+			break
+		}
+	}
+	// Jobs ahead are started in rounds of one per worker
+	view.ETA = time.Duration((view.Position+q.workers-1)/q.workers) * q.average
+	return view
+}
+
+// newID returns a random, unguessable job ID
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job id: %w", err)
+	}
+	return "job-" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,128 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_LimitsConcurrency(t *testing.T) {
+	q := New(2, 10)
+	defer q.Close()
+
+	var running, peak int32
+	release := make(chan struct{})
+	var ids []string
+	for i := 0; i < 5; i++ {
+		job, err := q.Submit("verify_build_freshness", "", func(ctx context.Context) (interface{}, error) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			<-release
+			atomic.AddInt32(&running, -1)
+			return "ok", nil
+		})
+		require.NoError(t, err)
+		ids = append(ids, job.ID)
+	}
+
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&running) == 2 }, time.Second, time.Millisecond)
+	last, ok := q.Get(ids[4])
+	require.True(t, ok)
+	assert.Equal(t, StatusQueued, last.Status)
+	assert.Equal(t, 3, last.Position, "two jobs run, two wait ahead")
+
+	close(release)
+	for _, id := range ids {
+		job, err := q.Wait(context.Background(), id)
+		require.NoError(t, err)
+		assert.Equal(t, StatusSucceeded, job.Status)
+		assert.Equal(t, "ok", job.Result)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&peak))
+}
+
+func TestQueue_Full(t *testing.T) {
+	q := New(1, 1)
+	defer q.Close()
+
+	block := make(chan struct{})
+	defer close(block)
+	wait := func(ctx context.Context) (interface{}, error) { <-block; return nil, nil }
+
+	first, err := q.Submit("a", "", wait)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { job, _ := q.Get(first.ID); return job.Status == StatusRunning }, time.Second, time.Millisecond)
+
+	_, err = q.Submit("b", "", wait)
+	require.NoError(t, err)
+	_, err = q.Submit("c", "", wait)
+	var full *ErrQueueFull
+	assert.ErrorAs(t, err, &full)
+}
+
+func TestQueue_FailuresAndPanics(t *testing.T) {
+	q := New(1, 10)
+	defer q.Close()
+
+	failed, err := q.Submit("fail", "", func(ctx context.Context) (interface{}, error) { return nil, errors.New("boom") })
+	require.NoError(t, err)
+	panicked, err := q.Submit("panic", "", func(ctx context.Context) (interface{}, error) { panic("bad input") })
+	require.NoError(t, err)
+
+	job, err := q.Wait(context.Background(), failed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, job.Status)
+	assert.Equal(t, "boom", job.Error)
+
+	job, err = q.Wait(context.Background(), panicked.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, job.Status)
+	assert.Contains(t, job.Error, "job panicked: bad input")
+
+	// The worker survives the panic
+	ok, err := q.Submit("ok", "", func(ctx context.Context) (interface{}, error) { return 1, nil })
+	require.NoError(t, err)
+	job, err = q.Wait(context.Background(), ok.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusSucceeded, job.Status)
+
+	_, err = q.Wait(context.Background(), "job-missing")
+	assert.ErrorContains(t, err, "unknown job")
+}
+
+func TestQueue_Progress(t *testing.T) {
+	q := New(1, 10)
+	defer q.Close()
+	events, stop := q.Subscribe()
+	defer stop()
+
+	SetProgress(context.Background(), Progress{Done: 1, Total: 2}) // Outside a job: ignored
+	job, err := q.Submit("onboard_project", "", func(ctx context.Context) (interface{}, error) {
+		SetProgress(ctx, Progress{Done: 1, Total: 3, Message: "Install java"})
+		return nil, nil
+	})
+	require.NoError(t, err)
+	_, err = q.Wait(context.Background(), job.ID)
+	require.NoError(t, err)
+
+	var progress *Progress
+	for len(events) > 0 {
+		if event := <-events; event.Status == StatusRunning && event.Progress != nil {
+			progress = event.Progress
+		}
+	}
+	require.NotNil(t, progress, "progress is published to subscribers")
+	assert.Equal(t, Progress{Done: 1, Total: 3, Message: "Install java"}, *progress)
+	finished, _ := q.Get(job.ID)
+	assert.Equal(t, progress, finished.Progress)
+}
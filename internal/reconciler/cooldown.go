@@ -0,0 +1,193 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"dev-env-sentinel/internal/state"
+)
+
+// failuresFile is the file in the cache category of a state directory holding recent fix failures
+const failuresFile = "fix-failures.json"
+
+// LoopLimits decide when a fix that keeps failing is refused: after MaxFailures failures
+// within Window, until the oldest of them is older than Window or the fix succeeds
+type LoopLimits struct {
+	MaxFailures int
+	Window      time.Duration
+}
+
+// DefaultLoopLimits refuse a fix after three failures within half an hour
+var DefaultLoopLimits = LoopLimits{MaxFailures: 3, Window: 30 * time.Minute}
+
+// FixFailure is a failed run of a fix, kept as evidence when the fix is refused as a loop
+type FixFailure struct {
+	Fingerprint string    `json:"fingerprint"`
+	IssueType   string    `json:"issue_type"`
+	Command     string    `json:"command"`
+	Time        time.Time `json:"time"`
+	Message     string    `json:"message"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// Cooldown remembers recent fix failures by fingerprint so that an agent calling
+// reconciliation again and again cannot run a failing fix forever. With a state directory
+// the failures survive restarts of the server.
+type Cooldown struct {
+	mu       sync.Mutex
+	limits   LoopLimits
+	dir      *state.Dir
+	failures map[string][]FixFailure
+	now      func() time.Time
+}
+
+// NewCooldown creates a cooldown, loading the failures persisted in dir if it is not nil
+func NewCooldown(dir *state.Dir, limits LoopLimits) *Cooldown {
+	c := &Cooldown{limits: limits, dir: dir, failures: make(map[string][]FixFailure), now: time.Now}
+	if dir != nil {
+		dir.ReadJSON(state.CategoryCache, failuresFile, &c.failures)
+		if c.failures == nil {
+			c.failures = make(map[string][]FixFailure)
+		}
+	}
+	return c
+}
+
+// Limits returns the limits of the cooldown; the default limits for a nil cooldown
+func (c *Cooldown) Limits() LoopLimits {
+	if c == nil {
+		return DefaultLoopLimits
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.limits
+}
+
+// SetLimits changes when fixes are refused; a MaxFailures of zero or less never refuses them
+func (c *Cooldown) SetLimits(limits LoopLimits) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.limits = limits
+}
+
+// Refused returns the recent failures of a fix, oldest first, if there are enough of them
+// to refuse running it again
+func (c *Cooldown) Refused(fingerprint string) ([]FixFailure, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	recent := c.recent(fingerprint)
+	if c.limits.MaxFailures <= 0 || len(recent) < c.limits.MaxFailures {
+		return nil, false
+	}
+	return recent, true
+}
+
+// Record records the outcome of a fix that ran: a failure is kept as evidence, a success
+// clears the failures of the fix
+func (c *Cooldown) Record(result FixResult) {
+	if result.Fingerprint == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if result.Success {
+		if _, ok := c.failures[result.Fingerprint]; !ok {
+			return
+		}
+		delete(c.failures, result.Fingerprint)
+	} else {
+		c.failures[result.Fingerprint] = append(c.recent(result.Fingerprint), FixFailure{
+			Fingerprint: result.Fingerprint,
+			IssueType:   result.IssueType,
+			Command:     result.Command,
+			Time:        c.now(),
+			Message:     result.Message,
+			Error:       result.Error,
+		})
+	}
+	c.persist()
+}
+
+// recent returns the failures of a fix within the window. Callers hold the lock.
+func (c *Cooldown) recent(fingerprint string) []FixFailure {
+	since := c.now().Add(-c.limits.Window)
+	var recent []FixFailure
+	for _, f := range c.failures[fingerprint] {
+		if f.Time.After(since) {
+			recent = append(recent, f)
+		}
+	}
+	return recent
+}
+
+// persist writes the failures within the window to the state directory. Callers hold the lock.
+func (c *Cooldown) persist() {
+	if c.dir == nil {
+		return
+	}
+	for fingerprint := range c.failures {
+		if recent := c.recent(fingerprint); len(recent) > 0 {
+			c.failures[fingerprint] = recent
+		} else {
+			delete(c.failures, fingerprint)
+		}
+	}
+	c.dir.WriteJSON(state.CategoryCache, failuresFile, c.failures)
+}
+
+// LoopError refuses a fix that failed too often recently and carries the failures as evidence
+type LoopError struct {
+	Fingerprint string
+	Failures    []FixFailure
+	Window      time.Duration
+}
+
+func (e *LoopError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "manual intervention required: fix %s failed %d times in the last %s and is not run again", e.Fingerprint, len(e.Failures), e.Window)
+	for _, f := range e.Failures {
+		fmt.Fprintf(&b, "\n- %s %s: %s", f.Time.Format(time.RFC3339), f.Command, f.Message)
+		if f.Error != "" {
+			fmt.Fprintf(&b, " (%s)", f.Error)
+		}
+	}
+	fmt.Fprintf(&b, "\nFull output: get_command_log with fingerprint %s", e.Fingerprint)
+	return b.String()
+}
+
+type cooldownKey struct{}
+
+// WithCooldown returns a context whose fixes are refused once they keep failing
+func WithCooldown(ctx context.Context, cooldown *Cooldown) context.Context {
+	return context.WithValue(ctx, cooldownKey{}, cooldown)
+}
+
+// cooldownFrom returns the cooldown of a context, or nil
+func cooldownFrom(ctx context.Context) *Cooldown {
+	cooldown, _ := ctx.Value(cooldownKey{}).(*Cooldown)
+	return cooldown
+}
+
+// checkLoop refuses a fix the context's cooldown holds back. It returns the result for
+// manual action, with the failures as evidence, or nil.
+func checkLoop(ctx context.Context, result FixResult, command string) *FixResult {
+	cooldown := cooldownFrom(ctx)
+	if cooldown == nil {
+		return nil
+	}
+	failures, refused := cooldown.Refused(result.Fingerprint)
+	if !refused {
+		return nil
+	}
+	last := failures[len(failures)-1]
+	result.Command = command
+	result.Blocked = true
+	result.Failures = failures
+	result.Error = "manual intervention required"
+	result.Message = fmt.Sprintf("Manual intervention required: the fix failed %d times since %s (last: %s), so it is not run again. Full output: get_command_log with fingerprint %s",
+		len(failures), failures[0].Time.Format("15:04"), last.Message, result.Fingerprint)
+	return &result
+}
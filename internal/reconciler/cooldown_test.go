@@ -0,0 +1,82 @@
+package reconciler
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/state"
+	"dev-env-sentinel/internal/verifier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCooldown_RefusesFixLoop(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows - requires sh")
+	}
+	tmpDir := t.TempDir()
+	dir, err := state.Open(t.TempDir())
+	require.NoError(t, err)
+
+	eco := &detector.DetectedEcosystem{Config: &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		ID: "test",
+		Reconciliation: config.Reconciliation{Fixes: []config.Fix{
+			{IssueType: "stale_build", Command: "echo compile error; exit 1", Description: "Rebuild"},
+		}},
+	}}}
+	issues := []verifier.Issue{{Type: "stale_build", FixAvailable: true}}
+	fingerprint := Fingerprint(tmpDir, "stale_build", "echo compile error; exit 1")
+	cooldown := NewCooldown(dir, LoopLimits{MaxFailures: 2, Window: time.Hour})
+	ctx := WithCooldown(context.Background(), cooldown)
+
+	for i := 0; i < 2; i++ {
+		report, err := ReconcileEnvironment(ctx, tmpDir, issues, eco)
+		require.NoError(t, err)
+		require.Len(t, report.Failed, 1)
+	}
+
+	// The third attempt is left to a human, with the failures as evidence
+	report, err := ReconcileEnvironment(ctx, tmpDir, issues, eco)
+	require.NoError(t, err)
+	assert.Empty(t, report.Failed)
+	require.Len(t, report.Manual, 1)
+	assert.Contains(t, report.Manual[0].Message, "Manual intervention required")
+	assert.Len(t, report.Manual[0].Failures, 2)
+
+	// The failures survive a restart, and a single fix is refused with an error
+	ctx = WithCooldown(context.Background(), NewCooldown(dir, LoopLimits{MaxFailures: 2, Window: time.Hour}))
+	_, err = ReconcileFingerprint(ctx, tmpDir, issues, []*detector.DetectedEcosystem{eco}, fingerprint)
+	var loop *LoopError
+	require.ErrorAs(t, err, &loop)
+	assert.Len(t, loop.Failures, 2)
+	assert.Contains(t, err.Error(), "compile error")
+}
+
+func TestCooldown_WindowAndSuccess(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	cooldown := NewCooldown(nil, LoopLimits{MaxFailures: 2, Window: 10 * time.Minute})
+	cooldown.now = func() time.Time { return now }
+
+	failed := FixResult{IssueType: "stale_build", Fingerprint: "abc", Message: "Fix command failed"}
+	cooldown.Record(failed)
+	now = now.Add(5 * time.Minute)
+	cooldown.Record(failed)
+	_, refused := cooldown.Refused("abc")
+	assert.True(t, refused)
+
+	// The first failure leaves the window
+	now = now.Add(6 * time.Minute)
+	_, refused = cooldown.Refused("abc")
+	assert.False(t, refused)
+
+	cooldown.Record(failed)
+	_, refused = cooldown.Refused("abc")
+	assert.True(t, refused)
+	cooldown.Record(FixResult{Fingerprint: "abc", Success: true})
+	_, refused = cooldown.Refused("abc")
+	assert.False(t, refused)
+}
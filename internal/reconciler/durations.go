@@ -0,0 +1,153 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"dev-env-sentinel/internal/state"
+)
+
+// durationsHistory is the history file of a state directory holding the durations of fixes
+const durationsHistory = "fix-durations"
+
+// durationSamples is how many recent runs of a fix the estimate is learned from
+const durationSamples = 10
+
+// FixRun is the duration of a fix that ran successfully
+type FixRun struct {
+	ProjectRoot string    `json:"project_root"`
+	IssueType   string    `json:"issue_type"`
+	Fingerprint string    `json:"fingerprint,omitempty"`
+	Seconds     float64   `json:"seconds"`
+	Time        time.Time `json:"time"`
+}
+
+// FixDurations remembers how long fixes took per project and issue type, so plans and issues
+// can say how long a fix typically takes on this machine. With a state directory the runs are
+// kept in its history and survive restarts of the server.
+type FixDurations struct {
+	mu   sync.Mutex
+	dir  *state.Dir
+	runs map[string][]FixRun
+	now  func() time.Time
+}
+
+// NewFixDurations creates a fix duration history, loading the runs recorded in dir if it is not nil
+func NewFixDurations(dir *state.Dir) *FixDurations {
+	d := &FixDurations{dir: dir, runs: make(map[string][]FixRun), now: time.Now}
+	if dir == nil {
+		return d
+	}
+	records, _ := dir.ReadHistory(durationsHistory)
+	for _, record := range records {
+		var run FixRun
+		if err := json.Unmarshal(record, &run); err != nil || run.IssueType == "" {
+			continue
+		}
+		d.add(run)
+	}
+	return d
+}
+
+// Record records how long a fix for an issue type took in a project
+func (d *FixDurations) Record(projectRoot, issueType, fingerprint string, elapsed time.Duration) {
+	if d == nil || issueType == "" || elapsed <= 0 {
+		return
+	}
+	run := FixRun{
+		ProjectRoot: projectRoot,
+		IssueType:   issueType,
+		Fingerprint: fingerprint,
+		Seconds:     elapsed.Seconds(),
+		Time:        d.now().UTC(),
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.add(run)
+	if d.dir != nil {
+		d.dir.AppendHistory(durationsHistory, run)
+	}
+}
+
+// Estimate returns the median duration of the recent runs of fixes for an issue type in a
+// project, and how many runs it is learned from; false when no fix for it has run yet
+func (d *FixDurations) Estimate(projectRoot, issueType string) (time.Duration, int, bool) {
+	if d == nil {
+		return 0, 0, false
+	}
+	d.mu.Lock()
+	runs := d.runs[durationKey(projectRoot, issueType)]
+	seconds := make([]float64, len(runs))
+	for i, run := range runs {
+		seconds[i] = run.Seconds
+	}
+	d.mu.Unlock()
+	if len(seconds) == 0 {
+		return 0, 0, false
+	}
+
+	sort.Float64s(seconds)
+	median := seconds[len(seconds)/2]
+	if len(seconds)%2 == 0 {
+		median = (seconds[len(seconds)/2-1] + median) / 2
+	}
+	return time.Duration(median * float64(time.Second)), len(seconds), true
+}
+
+// Describe returns the learned estimate of a fix as a note, e.g. "typically ~3m on this
+// machine", or "" when no fix for the issue type has run in the project yet
+func (d *FixDurations) Describe(projectRoot, issueType string) string {
+	typical, _, ok := d.Estimate(projectRoot, issueType)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("typically %s on this machine", FormatDuration(typical))
+}
+
+// FormatDuration formats a fix duration roughly, e.g. <1s, ~40s, ~3m or ~1h
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return "<1s"
+	case d < time.Minute:
+		return fmt.Sprintf("~%ds", int(d.Round(time.Second).Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("~%dm", int(d.Round(time.Minute).Minutes()))
+	default:
+		return fmt.Sprintf("~%dh", int(d.Round(time.Hour).Hours()))
+	}
+}
+
+// add keeps a run among the recent runs of its project and issue type. Callers hold the
+// lock or own the history.
+func (d *FixDurations) add(run FixRun) {
+	key := durationKey(run.ProjectRoot, run.IssueType)
+	runs := append(d.runs[key], run)
+	if len(runs) > durationSamples {
+		runs = runs[len(runs)-durationSamples:]
+	}
+	d.runs[key] = runs
+}
+
+// durationKey identifies the fixes of an issue type in a project
+func durationKey(projectRoot, issueType string) string {
+	return projectRoot + "\x00" + issueType
+}
+
+type durationsKey struct{}
+
+// WithFixDurations returns a context whose fixes record their durations and whose plans
+// include the learned estimates
+func WithFixDurations(ctx context.Context, durations *FixDurations) context.Context {
+	return context.WithValue(ctx, durationsKey{}, durations)
+}
+
+// FixDurationsFrom returns the fix duration history of a context, or nil
+func FixDurationsFrom(ctx context.Context) *FixDurations {
+	durations, _ := ctx.Value(durationsKey{}).(*FixDurations)
+	return durations
+}
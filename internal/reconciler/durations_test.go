@@ -0,0 +1,96 @@
+package reconciler
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/state"
+	"dev-env-sentinel/internal/verifier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixDurations_Estimate(t *testing.T) {
+	dir, err := state.Open(t.TempDir())
+	require.NoError(t, err)
+
+	durations := NewFixDurations(dir)
+	_, _, ok := durations.Estimate("/project", "stale_build")
+	assert.False(t, ok)
+	assert.Empty(t, durations.Describe("/project", "stale_build"))
+
+	for _, d := range []time.Duration{2 * time.Minute, 10 * time.Minute, 3 * time.Minute} {
+		durations.Record("/project", "stale_build", "abc", d)
+	}
+	durations.Record("/other", "stale_build", "def", time.Second)
+
+	typical, samples, ok := durations.Estimate("/project", "stale_build")
+	require.True(t, ok)
+	assert.Equal(t, 3*time.Minute, typical)
+	assert.Equal(t, 3, samples)
+	assert.Equal(t, "typically ~3m on this machine", durations.Describe("/project", "stale_build"))
+
+	// The runs survive a restart
+	typical, samples, ok = NewFixDurations(dir).Estimate("/project", "stale_build")
+	require.True(t, ok)
+	assert.Equal(t, 3*time.Minute, typical)
+	assert.Equal(t, 3, samples)
+}
+
+func TestFixDurations_KeepsRecentRuns(t *testing.T) {
+	durations := NewFixDurations(nil)
+	for i := 0; i < durationSamples; i++ {
+		durations.Record("/project", "stale_build", "", time.Hour)
+	}
+	for i := 0; i < durationSamples; i++ {
+		durations.Record("/project", "stale_build", "", 20*time.Second)
+	}
+
+	typical, samples, ok := durations.Estimate("/project", "stale_build")
+	require.True(t, ok)
+	assert.Equal(t, 20*time.Second, typical)
+	assert.Equal(t, durationSamples, samples)
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "<1s", FormatDuration(300*time.Millisecond))
+	assert.Equal(t, "~40s", FormatDuration(40*time.Second))
+	assert.Equal(t, "~3m", FormatDuration(170*time.Second))
+	assert.Equal(t, "~2h", FormatDuration(100*time.Minute))
+}
+
+func TestExecuteFix_LearnsDuration(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows - requires sh")
+	}
+	tmpDir := t.TempDir()
+	eco := &detector.DetectedEcosystem{Config: &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		ID: "test",
+		Reconciliation: config.Reconciliation{Fixes: []config.Fix{
+			{IssueType: "stale_build", Command: "sleep 0.1", Description: "Rebuild"},
+		}},
+	}}}
+	issues := []verifier.Issue{{Type: "stale_build", FixAvailable: true}}
+	durations := NewFixDurations(nil)
+	ctx := WithFixDurations(context.Background(), durations)
+
+	// Without a history a plan carries no estimate
+	plan, err := ReconcileEnvironment(WithApproval(ctx, Approval{Plan: true}), tmpDir, issues, eco)
+	require.NoError(t, err)
+	require.Len(t, plan.Planned, 1)
+	assert.Empty(t, plan.Planned[0].Estimate)
+
+	report, err := ReconcileEnvironment(ctx, tmpDir, issues, eco)
+	require.NoError(t, err)
+	require.Len(t, report.Fixed, 1)
+	assert.GreaterOrEqual(t, report.Fixed[0].Duration, 100*time.Millisecond)
+
+	plan, err = ReconcileEnvironment(WithApproval(ctx, Approval{Plan: true}), tmpDir, issues, eco)
+	require.NoError(t, err)
+	require.Len(t, plan.Planned, 1)
+	assert.Equal(t, "typically <1s on this machine", plan.Planned[0].Estimate)
+}
@@ -0,0 +1,198 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"dev-env-sentinel/internal/auditor"
+	"dev-env-sentinel/internal/runner"
+)
+
+// IssueMissingEnvVar is the issue type for environment variables that are referenced but not set
+const IssueMissingEnvVar = "missing_env_var"
+
+// secretName matches variable names that are likely to hold credentials
+var secretName = regexp.MustCompile(`(?i)(SECRET|PASSWORD|PASSWD|PWD|TOKEN|API_?KEY|PRIVATE|CREDENTIAL|AUTH|_KEY$|^KEY$|SALT|SIGNING|CERT|DSN$)`)
+
+// safeDefaults are values that are safe to use for well-known variables in a local environment
+var safeDefaults = map[string]string{
+	"NODE_ENV":               "development",
+	"APP_ENV":                "development",
+	"RAILS_ENV":              "development",
+	"FLASK_ENV":              "development",
+	"RACK_ENV":               "development",
+	"LOG_LEVEL":              "info",
+	"DEBUG":                  "false",
+	"TZ":                     "UTC",
+	"HOST":                   "localhost",
+	"PORT":                   "8080",
+	"ASPNETCORE_ENVIRONMENT": "Development",
+	"DOTNET_ENVIRONMENT":     "Development",
+}
+
+// envFixHeader marks the block of variables the sentinel appended to .env
+const envFixHeader = "# Added by dev-env-sentinel"
+
+// IsSecretName reports whether a variable name looks like it holds a secret
+func IsSecretName(name string) bool {
+	return secretName.MatchString(name)
+}
+
+// ReconcileEnvVars fixes missing environment variables from the audit reports. Variables
+// with a default in code, an example value in .env.example or a known-safe default are
+// written to the project's .env; secret-like variables are never written and get
+// instructions instead. Results are added to report.
+func ReconcileEnvVars(ctx context.Context, projectRoot string, envReports []*auditor.EnvVarReport, report *ReconciliationReport) error {
+	templateValues, err := auditor.TemplateValues(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to read dotenv template: %w", err)
+	}
+	local, err := auditor.LocalDotenvValues(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to read .env: %w", err)
+	}
+
+	missing, codeDefaults := collectMissing(envReports)
+	direnv, err := auditor.AuditDirenv(ctx, projectRoot, missing)
+	if err != nil {
+		return err
+	}
+
+	var pending []FixResult
+	for _, name := range missing {
+		result := FixResult{IssueType: IssueMissingEnvVar}
+
+		// Loading the .envrc sets it along with the rest, without writing anything
+		if direnv.Fixes(name) {
+			result.Message = fmt.Sprintf("%s is exported by .envrc, which direnv hasn't loaded: %s", name, direnv.Fix)
+			report.Manual = append(report.Manual, result)
+			continue
+		}
+
+		if _, inDotenv := local[name]; inDotenv {
+			result.Message = fmt.Sprintf("%s is defined in .env but not loaded into the environment; load .env (e.g. with dotenv) or export it", name)
+			report.Manual = append(report.Manual, result)
+			continue
+		}
+
+		if IsSecretName(name) {
+			result.Message = fmt.Sprintf("%s looks like a secret and is never generated; add it to .env yourself from your team's secret store", name)
+			if _, documented := templateValues[name]; documented {
+				result.Message += fmt.Sprintf(" (see %s)", filepath.Base(auditor.FindDotenvTemplate(projectRoot)))
+			}
+			report.Manual = append(report.Manual, result)
+			continue
+		}
+
+		value, source, ok := safeValue(name, codeDefaults, templateValues)
+		if !ok {
+			result.Message = fmt.Sprintf("%s has no documented default; add it to .env or .env.example", name)
+			report.Manual = append(report.Manual, result)
+			continue
+		}
+
+		result.Command = auditor.FormatDotenvLine(name, value)
+		result.Message = fmt.Sprintf("Set %s in .env (%s)", result.Command, source)
+		pending = append(pending, result)
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	report.Plan = report.Plan || approvalFrom(ctx).Plan
+	if runner.ReadOnly() || report.Plan {
+		for _, result := range pending {
+			result.Planned = true
+			report.Planned = append(report.Planned, result)
+		}
+		return nil
+	}
+
+	lines := make([]string, len(pending))
+	for i, result := range pending {
+		lines[i] = result.Command
+	}
+	writeErr := appendDotenv(filepath.Join(projectRoot, ".env"), lines)
+	for _, result := range pending {
+		if writeErr != nil {
+			result.Error = writeErr.Error()
+			report.Failed = append(report.Failed, result)
+			report.IsSuccess = false
+			continue
+		}
+		result.Success = true
+		report.Fixed = append(report.Fixed, result)
+	}
+	return nil
+}
+
+// collectMissing merges the missing variables of all reports and the defaults that any
+// reference supplies in code
+func collectMissing(envReports []*auditor.EnvVarReport) ([]string, map[string]string) {
+	seen := make(map[string]bool)
+	var missing []string
+	defaults := make(map[string]string)
+	for _, envReport := range envReports {
+		for _, name := range envReport.Missing {
+			if !seen[name] {
+				seen[name] = true
+				missing = append(missing, name)
+			}
+		}
+		for _, ref := range envReport.References {
+			if ref.HasDefault && ref.Default != "" {
+				defaults[ref.Name] = ref.Default
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing, defaults
+}
+
+// safeValue picks the value to write for a non-secret variable and describes where it came from
+func safeValue(name string, codeDefaults, templateValues map[string]string) (string, string, bool) {
+	if value, ok := codeDefaults[name]; ok {
+		return value, "default from code", true
+	}
+	if value, ok := templateValues[name]; ok && value != "" {
+		return value, "example from template", true
+	}
+	if value, ok := safeDefaults[name]; ok {
+		return value, "known-safe default", true
+	}
+	return "", "", false
+}
+
+// appendDotenv appends variables to a .env file, creating it if needed
+func appendDotenv(path string, lines []string) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var b strings.Builder
+	if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		b.WriteString("\n")
+	}
+	if len(existing) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString(envFixHeader + "\n")
+	for _, line := range lines {
+		b.WriteString(line + "\n")
+	}
+	_, err = f.WriteString(b.String())
+	return err
+}
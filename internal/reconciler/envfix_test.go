@@ -0,0 +1,86 @@
+package reconciler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dev-env-sentinel/internal/auditor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSecretName(t *testing.T) {
+	for _, name := range []string{"JWT_SECRET", "DB_PASSWORD", "GITHUB_TOKEN", "STRIPE_API_KEY", "SIGNING_KEY", "SENTRY_DSN"} {
+		assert.True(t, IsSecretName(name), name)
+	}
+	for _, name := range []string{"PORT", "NODE_ENV", "DATABASE_URL", "LOG_LEVEL"} {
+		assert.False(t, IsSecretName(name), name)
+	}
+}
+
+func TestReconcileEnvVars(t *testing.T) {
+	t.Setenv("SENTINEL_READ_ONLY", "")
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".env.example"), []byte("DATABASE_URL=postgres://localhost/app\nAPI_TOKEN=changeme\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".env"), []byte("LOADED_ELSEWHERE=1"), 0644))
+
+	envReports := []*auditor.EnvVarReport{{
+		Missing: []string{"DATABASE_URL", "API_TOKEN", "NODE_ENV", "CACHE_DIR", "LOADED_ELSEWHERE", "UNKNOWN_VAR"},
+		References: []auditor.EnvVarReference{
+			{Name: "CACHE_DIR", HasDefault: true, Default: "/tmp/my cache"},
+		},
+	}}
+
+	report := NewReport()
+	require.NoError(t, ReconcileEnvVars(context.Background(), root, envReports, report))
+	report.Summarize()
+
+	var fixed []string
+	for _, fix := range report.Fixed {
+		fixed = append(fixed, fix.Command)
+	}
+	assert.Equal(t, []string{`CACHE_DIR="/tmp/my cache"`, "DATABASE_URL=postgres://localhost/app", "NODE_ENV=development"}, fixed)
+	assert.Len(t, report.Manual, 3)
+	assert.Contains(t, report.Manual[0].Message, "API_TOKEN looks like a secret")
+	assert.Contains(t, report.Manual[1].Message, "LOADED_ELSEWHERE is defined in .env but not loaded")
+	assert.Contains(t, report.Manual[2].Message, "UNKNOWN_VAR has no documented default")
+	assert.Equal(t, "Fixed 3 issue(s), 3 issue(s) need manual action", report.Message)
+
+	content, err := os.ReadFile(filepath.Join(root, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "LOADED_ELSEWHERE=1\n\n# Added by dev-env-sentinel\nCACHE_DIR=\"/tmp/my cache\"\nDATABASE_URL=postgres://localhost/app\nNODE_ENV=development\n", string(content))
+	assert.NotContains(t, string(content), "changeme", "secrets are never written")
+}
+
+func TestReconcileEnvVars_ReadOnly(t *testing.T) {
+	t.Setenv("SENTINEL_READ_ONLY", "true")
+	root := t.TempDir()
+
+	report := NewReport()
+	require.NoError(t, ReconcileEnvVars(context.Background(), root, []*auditor.EnvVarReport{{Missing: []string{"NODE_ENV"}}}, report))
+
+	require.Len(t, report.Planned, 1)
+	assert.Equal(t, "NODE_ENV=development", report.Planned[0].Command)
+	assert.NoFileExists(t, filepath.Join(root, ".env"))
+}
+
+func TestReconcileEnvVars_Direnv(t *testing.T) {
+	t.Setenv("SENTINEL_READ_ONLY", "")
+	t.Setenv("DIRENV_DIR", "")
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".envrc"), []byte("export DATABASE_URL=postgres://localhost/app\n"), 0644))
+
+	envReports := []*auditor.EnvVarReport{{Missing: []string{"DATABASE_URL", "NODE_ENV"}}}
+	report := NewReport()
+	require.NoError(t, ReconcileEnvVars(context.Background(), root, envReports, report))
+
+	require.Len(t, report.Fixed, 1)
+	assert.Equal(t, "NODE_ENV=development", report.Fixed[0].Command)
+	require.Len(t, report.Manual, 1)
+	assert.Contains(t, report.Manual[0].Message, "DATABASE_URL is exported by .envrc, which direnv hasn't loaded: ")
+	content, err := os.ReadFile(filepath.Join(root, ".env"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "DATABASE_URL", "the .envrc sets it")
+}
@@ -0,0 +1,122 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+)
+
+// Fix policy decisions
+const (
+	FixAuto    = "auto"    // Run the fix without asking
+	FixConfirm = "confirm" // Plan the fix; run it once a call confirms its fingerprint
+	FixNever   = "never"   // Never run the fix; list it for manual action
+)
+
+// Severities fix policies are set for. Issues are warnings or errors; a fix config can raise
+// its issue type to critical, e.g. for fixes that delete data.
+const (
+	SeverityWarning  = "warning"
+	SeverityError    = "error"
+	SeverityCritical = "critical"
+)
+
+// FixPolicy decides per issue severity whether fixes run automatically. Unset severities
+// run automatically.
+type FixPolicy struct {
+	Warning  string
+	Error    string
+	Critical string
+}
+
+// For returns the decision for an issue severity
+func (p FixPolicy) For(severity string) string {
+	var decision string
+	switch severity {
+	case SeverityWarning:
+		decision = p.Warning
+	case SeverityCritical:
+		decision = p.Critical
+	default:
+		decision = p.Error
+	}
+	if decision == "" {
+		return FixAuto
+	}
+	return decision
+}
+
+// ParseFixDecision validates a fix policy decision
+func ParseFixDecision(value string) (string, error) {
+	switch value {
+	case "", FixAuto:
+		return FixAuto, nil
+	case FixConfirm, FixNever:
+		return value, nil
+	}
+	return "", fmt.Errorf("unknown fix policy %q (use auto, confirm or never)", value)
+}
+
+// Approval is what a reconciliation call allows beyond the policy: running nothing (plan),
+// or running fixes that need confirmation, by fingerprint
+type Approval struct {
+	Plan      bool
+	Confirmed []string
+}
+
+func (a Approval) confirms(fingerprint string) bool {
+	for _, f := range a.Confirmed {
+		if f == fingerprint {
+			return true
+		}
+	}
+	return false
+}
+
+type fixPolicyKey struct{}
+type approvalKey struct{}
+
+// WithFixPolicy returns a context whose fixes are run under a fix policy
+func WithFixPolicy(ctx context.Context, policy FixPolicy) context.Context {
+	return context.WithValue(ctx, fixPolicyKey{}, policy)
+}
+
+// FixPolicyFrom returns the fix policy of a context; without one every fix runs
+func FixPolicyFrom(ctx context.Context) FixPolicy {
+	policy, _ := ctx.Value(fixPolicyKey{}).(FixPolicy)
+	return policy
+}
+
+// WithApproval returns a context whose reconciliation plans or confirms fixes
+func WithApproval(ctx context.Context, approval Approval) context.Context {
+	return context.WithValue(ctx, approvalKey{}, approval)
+}
+
+func approvalFrom(ctx context.Context) Approval {
+	approval, _ := ctx.Value(approvalKey{}).(Approval)
+	return approval
+}
+
+// gate decides whether a fix runs under the context's policy and approval. It returns the
+// result of a fix that doesn't run, or nil.
+func gate(ctx context.Context, result FixResult, command string) *FixResult {
+	approval := approvalFrom(ctx)
+	result.Command = command
+	switch FixPolicyFrom(ctx).For(result.Severity) {
+	case FixNever:
+		result.Blocked = true
+		result.Message = fmt.Sprintf("The fix policy never runs fixes for %s issues automatically. To fix it yourself, run: %s", result.Severity, command)
+		return &result
+	case FixConfirm:
+		if !approval.confirms(result.Fingerprint) {
+			result.Pending = true
+			result.Message = fmt.Sprintf("Needs confirmation (%s): %s. Confirm with fingerprint %s", result.Severity, command, result.Fingerprint)
+			return &result
+		}
+	}
+	if approval.Plan {
+		result.Planned = true
+		result.Message = fmt.Sprintf("Would run: %s", command)
+		return &result
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package reconciler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/verifier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixPolicy_For(t *testing.T) {
+	policy := FixPolicy{Error: FixConfirm, Critical: FixNever}
+	assert.Equal(t, FixAuto, policy.For(SeverityWarning))
+	assert.Equal(t, FixConfirm, policy.For(SeverityError))
+	assert.Equal(t, FixConfirm, policy.For(""), "issues without a severity are treated as errors")
+	assert.Equal(t, FixNever, policy.For(SeverityCritical))
+	assert.Equal(t, FixAuto, FixPolicy{}.For(SeverityCritical))
+
+	_, err := ParseFixDecision("ask")
+	assert.Error(t, err)
+}
+
+func TestReconcileEnvironment_FixPolicy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows - requires sh")
+	}
+	tmpDir := t.TempDir()
+
+	eco := &detector.DetectedEcosystem{Config: &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		ID: "test",
+		Reconciliation: config.Reconciliation{Fixes: []config.Fix{
+			{IssueType: "stale_cache", Command: "touch cache-cleared", Description: "Clear the cache"},
+			{IssueType: "stale_build", Command: "touch rebuilt", Description: "Rebuild"},
+			{IssueType: "corrupt_db", Command: "touch db-dropped", Description: "Drop the database", Severity: SeverityCritical},
+		}},
+	}}}
+	issues := []verifier.Issue{
+		{Type: "stale_cache", Severity: SeverityWarning, FixAvailable: true},
+		{Type: "stale_build", Severity: SeverityError, FixAvailable: true},
+		{Type: "corrupt_db", Severity: SeverityError, FixAvailable: true},
+	}
+	ctx := WithFixPolicy(context.Background(), FixPolicy{Warning: FixAuto, Error: FixConfirm, Critical: FixNever})
+
+	// Plan: nothing runs
+	report, err := ReconcileEnvironment(WithApproval(ctx, Approval{Plan: true}), tmpDir, issues, eco)
+	require.NoError(t, err)
+	require.Len(t, report.Planned, 1)
+	require.Len(t, report.Pending, 1)
+	require.Len(t, report.Manual, 1)
+	assert.Equal(t, "stale_cache", report.Planned[0].IssueType)
+	assert.True(t, report.IsSuccess)
+	assert.NoFileExists(t, filepath.Join(tmpDir, "cache-cleared"))
+
+	// Apply: warnings run, errors wait for confirmation, critical issues never run
+	report, err = ReconcileEnvironment(ctx, tmpDir, issues, eco)
+	require.NoError(t, err)
+	require.Len(t, report.Fixed, 1)
+	require.Len(t, report.Pending, 1)
+	pending := report.Pending[0]
+	assert.Equal(t, "stale_build", pending.IssueType)
+	assert.Equal(t, SeverityError, pending.Severity)
+	assert.Contains(t, pending.Message, pending.Fingerprint)
+	assert.Equal(t, SeverityCritical, report.Manual[0].Severity)
+	assert.FileExists(t, filepath.Join(tmpDir, "cache-cleared"))
+	assert.NoFileExists(t, filepath.Join(tmpDir, "rebuilt"))
+
+	// Confirm the pending fix by fingerprint
+	report, err = ReconcileEnvironment(WithApproval(ctx, Approval{Confirmed: []string{pending.Fingerprint}}), tmpDir, issues, eco)
+	require.NoError(t, err)
+	assert.Len(t, report.Fixed, 2)
+	assert.Empty(t, report.Pending)
+	assert.Len(t, report.Manual, 1)
+	assert.FileExists(t, filepath.Join(tmpDir, "rebuilt"))
+	_, err = os.Stat(filepath.Join(tmpDir, "db-dropped"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestReconcileFingerprint(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows - requires sh")
+	}
+	tmpDir := t.TempDir()
+
+	eco := &detector.DetectedEcosystem{Config: &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		ID: "test",
+		Reconciliation: config.Reconciliation{Fixes: []config.Fix{
+			{IssueType: "stale_cache", Command: "touch cache-cleared", Description: "Clear the cache"},
+			{IssueType: "stale_build", Command: "touch rebuilt", Description: "Rebuild"},
+			{IssueType: "corrupt_db", Command: "touch db-dropped", Description: "Drop the database", Severity: SeverityCritical},
+		}},
+	}}}
+	ecosystems := []*detector.DetectedEcosystem{eco}
+	issues := []verifier.Issue{
+		{Type: "stale_cache", Severity: SeverityWarning, FixAvailable: true},
+		{Type: "stale_build", Severity: SeverityError, FixAvailable: true},
+		{Type: "corrupt_db", Severity: SeverityError, FixAvailable: true},
+	}
+	ctx := WithFixPolicy(context.Background(), FixPolicy{Error: FixConfirm, Critical: FixNever})
+
+	// Only the named fix runs, and naming it confirms it
+	report, err := ReconcileFingerprint(ctx, tmpDir, issues, ecosystems, Fingerprint(tmpDir, "stale_build", "touch rebuilt"))
+	require.NoError(t, err)
+	require.Len(t, report.Fixed, 1)
+	assert.Equal(t, "stale_build", report.Fixed[0].IssueType)
+	assert.FileExists(t, filepath.Join(tmpDir, "rebuilt"))
+	assert.NoFileExists(t, filepath.Join(tmpDir, "cache-cleared"))
+
+	// Fixes the policy never runs stay manual
+	report, err = ReconcileFingerprint(ctx, tmpDir, issues, ecosystems, Fingerprint(tmpDir, "corrupt_db", "touch db-dropped"))
+	require.NoError(t, err)
+	assert.Len(t, report.Manual, 1)
+	assert.NoFileExists(t, filepath.Join(tmpDir, "db-dropped"))
+
+	// A fingerprint of no current issue runs nothing
+	_, err = ReconcileFingerprint(ctx, tmpDir, issues[:1], ecosystems, Fingerprint(tmpDir, "stale_build", "touch rebuilt"))
+	assert.ErrorIs(t, err, ErrFingerprintNotFound)
+	assert.NoFileExists(t, filepath.Join(tmpDir, "cache-cleared"))
+}
@@ -2,13 +2,17 @@ package reconciler
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"os/exec"
 	"strings"
 	"time"
 
 	"dev-env-sentinel/internal/config"
 	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/resources"
+	"dev-env-sentinel/internal/runner"
 	"dev-env-sentinel/internal/verifier"
 )
 
@@ -16,6 +20,10 @@ import (
 type ReconciliationReport struct {
 	Fixed     []FixResult
 	Failed    []FixResult
+	Planned   []FixResult // Fixes not executed because read-only mode is on or the call only plans
+	Pending   []FixResult // Fixes the fix policy runs only once confirmed by fingerprint
+	Manual    []FixResult // Issues that need a human, with instructions in Message
+	Plan      bool        // The call only planned fixes
 	IsSuccess bool
 	Message   string
 }
@@ -25,18 +33,31 @@ type FixResult struct {
 	IssueType string
 	Command   string
 	Success   bool
+	Planned   bool   // Command was not run because read-only mode is on or the call only plans
+	Pending   bool   // Command awaits confirmation under the fix policy
+	Blocked   bool   // The fix policy never runs the command automatically
+	Severity  string // Severity of the issue, as the fix policy sees it
 	Message   string
 	Error     string
+	// Fingerprint identifies the fix in a project; the full output of its commands is
+	// logged under it
+	Fingerprint string
+	Doc         *config.IssueDoc // Team runbook for the issue type, if the config links one
+	Failures    []FixFailure     // Recent failures, when the fix is refused because it keeps failing
+	Duration    time.Duration    // How long the fix took, when it ran successfully
+	Estimate    string           // How long the fix typically takes, learned from its earlier runs
+}
+
+// Fingerprint identifies a fix command for an issue type in a project
+func Fingerprint(projectRoot, issueType, command string) string {
+	sum := sha256.Sum256([]byte(projectRoot + "\x00" + issueType + "\x00" + command))
+	return hex.EncodeToString(sum[:8])
 }
 
 // ReconcileEnvironment reconciles environment issues
 func ReconcileEnvironment(ctx context.Context, projectRoot string, issues []verifier.Issue, ecosystem *detector.DetectedEcosystem) (*ReconciliationReport, error) {
-	report := &ReconciliationReport{
-		Fixed:     []FixResult{},
-		Failed:    []FixResult{},
-		IsSuccess: true,
-	}
-
+	report := NewReport()
+	report.Plan = approvalFrom(ctx).Plan
 	cfg := ecosystem.Config
 
 	// Group issues by type and find fixes
@@ -51,33 +72,100 @@ func ReconcileEnvironment(ctx context.Context, projectRoot string, issues []veri
 				IssueType: issue.Type,
 				Success:   false,
 				Message:   "No fix available for this issue type",
+				Doc:       issue.Doc,
 			})
 			report.IsSuccess = false
 			continue
 		}
 
 		// Execute fix
-		result := executeFix(ctx, projectRoot, fix, issue)
-		if result.Success {
-			report.Fixed = append(report.Fixed, result)
+		report.add(executeFix(ctx, projectRoot, fix, issue))
+	}
+
+	report.Summarize()
+	return report, nil
+}
+
+// NewReport creates an empty, successful reconciliation report
+func NewReport() *ReconciliationReport {
+	return &ReconciliationReport{
+		Fixed:     []FixResult{},
+		Failed:    []FixResult{},
+		Planned:   []FixResult{},
+		Pending:   []FixResult{},
+		Manual:    []FixResult{},
+		IsSuccess: true,
+	}
+}
+
+// add sorts a fix result into the report
+func (r *ReconciliationReport) add(result FixResult) {
+	switch {
+	case result.Planned:
+		r.Planned = append(r.Planned, result)
+	case result.Pending:
+		r.Pending = append(r.Pending, result)
+	case result.Blocked:
+		r.Manual = append(r.Manual, result)
+	case result.Success:
+		r.Fixed = append(r.Fixed, result)
+	default:
+		r.Failed = append(r.Failed, result)
+		r.IsSuccess = false
+	}
+}
+
+// Summarize sets the summary message from the report's results
+func (r *ReconciliationReport) Summarize() {
+	var parts []string
+	if len(r.Fixed) > 0 {
+		parts = append(parts, fmt.Sprintf("Fixed %d issue(s)", len(r.Fixed)))
+	}
+	if len(r.Failed) > 0 {
+		parts = append(parts, fmt.Sprintf("Failed to fix %d issue(s)", len(r.Failed)))
+	}
+	if len(r.Planned) > 0 {
+		if r.Plan {
+			parts = append(parts, fmt.Sprintf("Planned %d fix(es) without running them", len(r.Planned)))
 		} else {
-			report.Failed = append(report.Failed, result)
-			report.IsSuccess = false
+			parts = append(parts, fmt.Sprintf("Read-only mode: planned %d fix(es) without running them", len(r.Planned)))
 		}
 	}
-
-	// Generate summary message
-	if len(report.Fixed) > 0 {
-		report.Message = fmt.Sprintf("Fixed %d issue(s)", len(report.Fixed))
+	if len(r.Pending) > 0 {
+		parts = append(parts, fmt.Sprintf("%d fix(es) need confirmation", len(r.Pending)))
+	}
+	if len(r.Manual) > 0 {
+		parts = append(parts, fmt.Sprintf("%d issue(s) need manual action", len(r.Manual)))
 	}
-	if len(report.Failed) > 0 {
-		if report.Message != "" {
-			report.Message += ", "
+	r.Message = strings.Join(parts, ", ")
+}
+
+// AttachDocs links the results without a runbook to the first one an ecosystem's config
+// lists for their issue type, such as the team's secret store page for missing_env_var
+func (r *ReconciliationReport) AttachDocs(ecosystems []*detector.DetectedEcosystem) {
+	for _, results := range [][]FixResult{r.Fixed, r.Failed, r.Planned, r.Pending, r.Manual} {
+		for i := range results {
+			if results[i].Doc != nil {
+				continue
+			}
+			for _, eco := range ecosystems {
+				if doc := findDoc(eco.Config, results[i].IssueType); doc != nil {
+					results[i].Doc = doc
+					break
+				}
+			}
 		}
-		report.Message += fmt.Sprintf("Failed to fix %d issue(s)", len(report.Failed))
 	}
+}
 
-	return report, nil
+// findDoc finds the runbook a config links to an issue type
+func findDoc(cfg *config.EcosystemConfig, issueType string) *config.IssueDoc {
+	for i := range cfg.Ecosystem.Docs {
+		if cfg.Ecosystem.Docs[i].IssueType == issueType {
+			return &cfg.Ecosystem.Docs[i]
+		}
+	}
+	return nil
 }
 
 // findFix finds a fix configuration for an issue type
@@ -91,31 +179,83 @@ func findFix(cfg *config.EcosystemConfig, issueType string) *config.Fix {
 }
 
 // executeFix executes a fix command
-func executeFix(ctx context.Context, projectRoot string, fix *config.Fix, issue verifier.Issue) FixResult {
-	result := FixResult{
+func executeFix(ctx context.Context, projectRoot string, fix *config.Fix, issue verifier.Issue) (result FixResult) {
+	result = FixResult{
 		IssueType: fix.IssueType,
 		Command:   fix.Command,
 		Success:   false,
+		Severity:  issue.Severity,
+		Doc:       issue.Doc,
+	}
+	if fix.Severity != "" {
+		result.Severity = fix.Severity
 	}
 
-	// Use fix command from config, or fall back to issue fix command
+	// Use fix command from config, or fall back to issue fix command. A targeted command
+	// is the issue's, with the modules to rebuild filled in.
 	command := fix.Command
-	if command == "" {
+	if command == "" || fix.Targeted() {
 		command = issue.FixCommand
 	}
+	result.Command = command
 
 	if command == "" {
 		result.Message = "No fix command available"
 		return result
 	}
+	result.Fingerprint = Fingerprint(projectRoot, fix.IssueType, command)
+	ctx = runner.WithLogKey(ctx, result.Fingerprint)
+	durations := FixDurationsFrom(ctx)
+	result.Estimate = durations.Describe(projectRoot, fix.IssueType)
+
+	// A fix that keeps failing is left to a human instead of being retried in a loop
+	if looping := checkLoop(ctx, result, command); looping != nil {
+		return *looping
+	}
+
+	// The fix policy may hold the fix for confirmation or leave it to a human
+	if gated := gate(ctx, result, command); gated != nil {
+		return *gated
+	}
+
+	// Heavy fixes are not started on a machine that is already at capacity
+	warning, refused := checkHeadroom(ctx, projectRoot, fix)
+	if refused {
+		result.Command = command
+		result.Message = warning
+		result.Error = "insufficient resources"
+		return result
+	}
+	if warning != "" {
+		defer func() { result.Message += fmt.Sprintf(" (warning: %s)", warning) }()
+	}
 
 	// Execute fix command
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	fixCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "sh", "-c", command)
-	cmd.Dir = projectRoot
-	output, err := cmd.CombinedOutput()
+	start := time.Now()
+	output, err := runner.RunMutating(fixCtx, projectRoot, command)
+	if runner.IsReadOnly(err) {
+		result.Command = command
+		result.Planned = true
+		result.Message = fmt.Sprintf("Would have run: %s", command)
+		return result
+	}
+	if cooldown := cooldownFrom(ctx); cooldown != nil {
+		defer func() {
+			ran := result
+			ran.Command = command
+			cooldown.Record(ran)
+		}()
+	}
+	// Successful runs, health checks and verification included, teach the estimate
+	defer func() {
+		if result.Success {
+			result.Duration = time.Since(start)
+			durations.Record(projectRoot, fix.IssueType, result.Fingerprint, result.Duration)
+		}
+	}()
 
 	if err != nil {
 		result.Error = err.Error()
@@ -123,14 +263,26 @@ func executeFix(ctx context.Context, projectRoot string, fix *config.Fix, issue
 		return result
 	}
 
+	// Wait for a started service to become healthy, with its own timeout
+	if fix.Verify != nil {
+		wait := WaitHealthy(ctx, projectRoot, fix.Verify)
+		elapsed := wait.Elapsed.Round(time.Second)
+		if !wait.Healthy {
+			result.Message = fmt.Sprintf("Fix executed but not healthy after %s (%d checks): %s", elapsed, wait.Checks, wait.Last)
+			result.Error = "health check timed out"
+			return result
+		}
+		result.Success = true
+		result.Message = fmt.Sprintf("Fix executed and healthy after %s (%d checks): %s", elapsed, wait.Checks, fix.Description)
+		return result
+	}
+
 	// Verify fix if verify command provided
 	if fix.VerifyCommand != "" {
 		verifyCtx, verifyCancel := context.WithTimeout(ctx, 1*time.Minute)
 		defer verifyCancel()
 
-		verifyCmd := exec.CommandContext(verifyCtx, "sh", "-c", fix.VerifyCommand)
-		verifyCmd.Dir = projectRoot
-		verifyOutput, verifyErr := verifyCmd.CombinedOutput()
+		verifyOutput, verifyErr := runner.Run(verifyCtx, projectRoot, fix.VerifyCommand)
 
 		if verifyErr != nil {
 			result.Success = false
@@ -149,6 +301,23 @@ func executeFix(ctx context.Context, projectRoot string, fix *config.Fix, issue
 	return result
 }
 
+// checkHeadroom checks the free memory, CPU and disk before a heavy fix. It returns what is
+// short, and whether the fix must not run under the policy of the context.
+func checkHeadroom(ctx context.Context, projectRoot string, fix *config.Fix) (string, bool) {
+	if !fix.IsHeavy() || runner.ReadOnly() {
+		return "", false
+	}
+	policy := resources.PolicyFrom(ctx)
+	shortfalls := policy.Shortfalls(resources.Measure(ctx, projectRoot), fix.Requires.MemoryMB, fix.Requires.DiskMB)
+	if len(shortfalls) == 0 {
+		return "", false
+	}
+	if policy.Refuse {
+		return fmt.Sprintf("Not started, the machine is short on resources: %s. Free up resources or raise the limits in the headroom settings, then retry", strings.Join(shortfalls, "; ")), true
+	}
+	return fmt.Sprintf("the machine is short on resources: %s", strings.Join(shortfalls, "; ")), false
+}
+
 // ReconcileIssue reconciles a single issue
 func ReconcileIssue(ctx context.Context, projectRoot string, issue verifier.Issue, ecosystem *detector.DetectedEcosystem) (*FixResult, error) {
 	if !issue.FixAvailable {
@@ -165,3 +334,43 @@ func ReconcileIssue(ctx context.Context, projectRoot string, issue verifier.Issu
 	return &result, nil
 }
 
+// ReconcileFingerprint runs the one fix with a fingerprint from a prior report, among the
+// fixes for the current issues of the ecosystems. Naming the fingerprint confirms the fix
+// for the fix policy; fixes the policy never runs are still left to a human. It returns
+// ErrFingerprintNotFound when no current issue has a fix with the fingerprint, e.g. because
+// the issue is already fixed, and a LoopError when the fix keeps failing.
+func ReconcileFingerprint(ctx context.Context, projectRoot string, issues []verifier.Issue, ecosystems []*detector.DetectedEcosystem, fingerprint string) (*ReconciliationReport, error) {
+	for _, issue := range issues {
+		if !issue.FixAvailable {
+			continue
+		}
+		for _, eco := range ecosystems {
+			fix := findFix(eco.Config, issue.Type)
+			if fix == nil {
+				continue
+			}
+			command := fix.Command
+			if command == "" || fix.Targeted() {
+				command = issue.FixCommand
+			}
+			if command == "" || Fingerprint(projectRoot, fix.IssueType, command) != fingerprint {
+				continue
+			}
+
+			approval := approvalFrom(ctx)
+			approval.Confirmed = append(approval.Confirmed, fingerprint)
+			result := executeFix(WithApproval(ctx, approval), projectRoot, fix, issue)
+			if len(result.Failures) > 0 {
+				return nil, &LoopError{Fingerprint: fingerprint, Failures: result.Failures, Window: cooldownFrom(ctx).Limits().Window}
+			}
+			report := NewReport()
+			report.Plan = approval.Plan
+			report.add(result)
+			return report, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrFingerprintNotFound, fingerprint)
+}
+
+// ErrFingerprintNotFound is returned for a fingerprint that matches no fix of a current issue
+var ErrFingerprintNotFound = errors.New("no current issue has a fix with fingerprint")
@@ -11,6 +11,7 @@ import (
 
 	"dev-env-sentinel/internal/config"
 	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/resources"
 	"dev-env-sentinel/internal/verifier"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -291,6 +292,28 @@ func TestExecuteFix_NoCommand(t *testing.T) {
 	assert.Contains(t, result.Message, "No fix command available")
 }
 
+func TestExecuteFix_Targeted(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	fix := &config.Fix{
+		IssueType: "stale_module",
+		Command:   "echo {modules}",
+	}
+
+	issue := verifier.Issue{
+		Type:         "stale_module",
+		FixAvailable: true,
+		FixCommand:   "echo :core,:api",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The issue's command has the stale modules filled in
+	result := executeFix(ctx, tmpDir, fix, issue)
+	assert.Equal(t, "echo :core,:api", result.Command)
+}
+
 func TestReconcileIssue(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("Skipping test on Windows - requires sh")
@@ -393,3 +416,98 @@ func TestReconcileIssue_NoFixConfig(t *testing.T) {
 	assert.Contains(t, err.Error(), "no fix configuration found")
 }
 
+
+func TestReconcileEnvironment_ReadOnly(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	t.Setenv("SENTINEL_READ_ONLY", "true")
+
+	tmpDir := t.TempDir()
+	cfg := &config.EcosystemConfig{
+		Ecosystem: config.Ecosystem{
+			ID: "java-maven",
+			Reconciliation: config.Reconciliation{
+				Fixes: []config.Fix{
+					{IssueType: "stale_build", Command: "touch fixed", Description: "Rebuild"},
+				},
+			},
+		},
+	}
+	ecosystem := &detector.DetectedEcosystem{ID: "java-maven", Config: cfg, ProjectRoot: tmpDir}
+	issues := []verifier.Issue{{Type: "stale_build", FixAvailable: true}}
+
+	report, err := ReconcileEnvironment(context.Background(), tmpDir, issues, ecosystem)
+	require.NoError(t, err)
+
+	assert.True(t, report.IsSuccess)
+	assert.Empty(t, report.Fixed)
+	require.Len(t, report.Planned, 1)
+	assert.True(t, report.Planned[0].Planned)
+	assert.Equal(t, "Would have run: touch fixed", report.Planned[0].Message)
+	assert.Contains(t, report.Message, "Read-only mode")
+	assert.NoFileExists(t, filepath.Join(tmpDir, "fixed"))
+}
+
+func TestExecuteFix_HeavyRefused(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("measures memory from /proc")
+	}
+
+	tmpDir := t.TempDir()
+	fix := &config.Fix{IssueType: "stale_build", Command: "touch fixed", Description: "Rebuild", Heavy: true}
+	// No machine has this much memory free
+	ctx := resources.WithPolicy(context.Background(), resources.Policy{MinMemoryMB: 1 << 30, Refuse: true})
+
+	result := executeFix(ctx, tmpDir, fix, verifier.Issue{Type: "stale_build"})
+	assert.False(t, result.Success)
+	assert.Equal(t, "insufficient resources", result.Error)
+	assert.Contains(t, result.Message, "Not started, the machine is short on resources:")
+	assert.Contains(t, result.Message, "needs 1073741824 MiB")
+	assert.NoFileExists(t, filepath.Join(tmpDir, "fixed"))
+}
+
+func TestExecuteFix_HeavyWarns(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("measures memory from /proc")
+	}
+
+	tmpDir := t.TempDir()
+	fix := &config.Fix{IssueType: "stale_build", Command: "touch fixed", Description: "Rebuild", Requires: config.FixRequirements{MemoryMB: 1 << 30}}
+
+	result := executeFix(context.Background(), tmpDir, fix, verifier.Issue{Type: "stale_build"})
+	assert.True(t, result.Success)
+	assert.Contains(t, result.Message, "Fix executed: Rebuild (warning: the machine is short on resources:")
+	assert.FileExists(t, filepath.Join(tmpDir, "fixed"))
+}
+
+func TestExecuteFix_LightFixNotChecked(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	tmpDir := t.TempDir()
+	fix := &config.Fix{IssueType: "stale_lock", Command: "touch fixed", Description: "Update lock"}
+	ctx := resources.WithPolicy(context.Background(), resources.Policy{MinMemoryMB: 1 << 30, Refuse: true})
+
+	result := executeFix(ctx, tmpDir, fix, verifier.Issue{Type: "stale_lock"})
+	assert.True(t, result.Success)
+	assert.Equal(t, "Fix executed: Update lock", result.Message)
+}
+
+func TestAttachDocs(t *testing.T) {
+	cfg := &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		ID:   "node",
+		Docs: []config.IssueDoc{{IssueType: IssueMissingEnvVar, URL: "https://wiki.example.com/secrets"}},
+	}}
+	own := &config.IssueDoc{IssueType: "stale_build", URL: "https://wiki.example.com/rebuild"}
+	report := NewReport()
+	report.Manual = append(report.Manual, FixResult{IssueType: IssueMissingEnvVar})
+	report.Failed = append(report.Failed, FixResult{IssueType: "stale_build", Doc: own}, FixResult{IssueType: "stale_lock"})
+
+	report.AttachDocs([]*detector.DetectedEcosystem{{ID: "node", Config: cfg}})
+	require.NotNil(t, report.Manual[0].Doc)
+	assert.Equal(t, "https://wiki.example.com/secrets", report.Manual[0].Doc.URL)
+	assert.Same(t, own, report.Failed[0].Doc, "docs attached from the issue are kept")
+	assert.Nil(t, report.Failed[1].Doc)
+}
@@ -0,0 +1,120 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/runner"
+)
+
+// Defaults of fix health checks
+const (
+	DefaultVerifyTimeout     = 60 * time.Second
+	DefaultVerifyInterval    = 1 * time.Second
+	DefaultVerifyMaxInterval = 10 * time.Second
+)
+
+// probeTimeout bounds a single port or HTTP check
+const probeTimeout = 5 * time.Second
+
+// WaitResult is the outcome of waiting for a health check to pass
+type WaitResult struct {
+	Healthy bool
+	Checks  int           // Times the health check ran
+	Elapsed time.Duration // Time until it passed or the wait gave up
+	Last    string        // Why the last check failed
+}
+
+// WaitHealthy runs a fix's health check until it passes or its timeout expires, waiting
+// longer between checks each time up to the max interval
+func WaitHealthy(ctx context.Context, projectRoot string, verify *config.FixVerify) WaitResult {
+	timeout := parseDuration(verify.Timeout, DefaultVerifyTimeout)
+	interval := parseDuration(verify.Interval, DefaultVerifyInterval)
+	maxInterval := parseDuration(verify.MaxInterval, DefaultVerifyMaxInterval)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	var result WaitResult
+	for {
+		result.Checks++
+		err := probe(ctx, projectRoot, verify)
+		result.Elapsed = time.Since(start)
+		if err == nil {
+			result.Healthy = true
+			return result
+		}
+		result.Last = err.Error()
+
+		select {
+		case <-ctx.Done():
+			return result
+		case <-time.After(interval):
+		}
+		interval *= 2
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+// probe runs a health check once
+func probe(ctx context.Context, projectRoot string, verify *config.FixVerify) error {
+	switch {
+	case verify.Port != "":
+		dialer := net.Dialer{Timeout: probeTimeout}
+		conn, err := dialer.DialContext(ctx, "tcp", verify.Port)
+		if err != nil {
+			return fmt.Errorf("%s not accepting connections", verify.Port)
+		}
+		return conn.Close()
+	case verify.HTTP != "":
+		return probeHTTP(ctx, verify.HTTP, verify.ExpectStatus)
+	default:
+		output, err := runner.Run(ctx, projectRoot, verify.Command)
+		if err != nil {
+			if msg := strings.TrimSpace(string(output)); msg != "" {
+				return fmt.Errorf("%s", msg)
+			}
+			return err
+		}
+		return nil
+	}
+}
+
+func probeHTTP(ctx context.Context, url string, expectStatus int) error {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s unreachable", url)
+	}
+	resp.Body.Close()
+
+	healthy := resp.StatusCode >= 200 && resp.StatusCode < 400
+	if expectStatus != 0 {
+		healthy = resp.StatusCode == expectStatus
+	}
+	if !healthy {
+		return fmt.Errorf("%s answered %s", url, resp.Status)
+	}
+	return nil
+}
+
+func parseDuration(value string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
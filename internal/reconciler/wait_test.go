@@ -0,0 +1,84 @@
+package reconciler
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/verifier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitHealthy_HTTP(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	result := WaitHealthy(context.Background(), t.TempDir(), &config.FixVerify{HTTP: server.URL, Timeout: "5s", Interval: "10ms"})
+	assert.True(t, result.Healthy)
+	assert.Equal(t, 3, result.Checks)
+}
+
+func TestWaitHealthy_ExpectStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	result := WaitHealthy(context.Background(), t.TempDir(), &config.FixVerify{HTTP: server.URL, ExpectStatus: http.StatusOK, Timeout: "100ms", Interval: "10ms"})
+	assert.False(t, result.Healthy)
+	assert.Greater(t, result.Checks, 1)
+	assert.Contains(t, result.Last, "answered 204 No Content")
+}
+
+func TestWaitHealthy_Port(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+
+	result := WaitHealthy(context.Background(), t.TempDir(), &config.FixVerify{Port: addr, Timeout: "1s"})
+	assert.True(t, result.Healthy)
+	assert.Equal(t, 1, result.Checks)
+
+	listener.Close()
+	result = WaitHealthy(context.Background(), t.TempDir(), &config.FixVerify{Port: addr, Timeout: "50ms", Interval: "10ms"})
+	assert.False(t, result.Healthy)
+	assert.Contains(t, result.Last, "not accepting connections")
+}
+
+func TestExecuteFix_WaitsForHealthy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows - requires sh")
+	}
+	tmpDir := t.TempDir()
+
+	// The service "boots" on the second check
+	fix := &config.Fix{
+		IssueType:   "service_down",
+		Command:     "echo starting",
+		Description: "Start the service",
+		Verify:      &config.FixVerify{Command: "test -f booted || { touch booted; exit 1; }", Timeout: "5s", Interval: "10ms"},
+	}
+	result := executeFix(context.Background(), tmpDir, fix, verifier.Issue{Type: "service_down", FixAvailable: true})
+	assert.True(t, result.Success, result.Message)
+	assert.Contains(t, result.Message, "healthy after")
+	assert.Contains(t, result.Message, "(2 checks)")
+
+	fix.Verify = &config.FixVerify{Command: "echo still booting; exit 1", Timeout: "50ms", Interval: "10ms"}
+	result = executeFix(context.Background(), tmpDir, fix, verifier.Issue{Type: "service_down", FixAvailable: true})
+	assert.False(t, result.Success)
+	assert.Contains(t, result.Message, "not healthy after")
+	assert.Contains(t, result.Message, "still booting")
+}
@@ -0,0 +1,302 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/runner"
+)
+
+// File is the registry document in the state directory root
+const File = "projects.json"
+
+// ProjectsDir is the state directory subdirectory holding one state directory per project
+const ProjectsDir = "projects"
+
+// checkoutDir is the directory in a project's state directory that git URLs are cloned into
+const checkoutDir = "checkout"
+
+// mountDir is the directory in a project's state directory that a project on an ssh host is
+// mounted at
+const mountDir = "remote"
+
+// idPattern restricts project IDs to names that are safe as directory names and URL parameters
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// refPattern restricts the refs Update checks out to branch and tag names
+var refPattern = regexp.MustCompile(`^refs/(heads|tags)/[A-Za-z0-9._/-]+$`)
+
+// Project is a repository registered with a shared server
+type Project struct {
+	ID           string    `json:"id"`
+	Root         string    `json:"root"`
+	GitURL       string    `json:"git_url,omitempty"`
+	Host         string    `json:"host,omitempty"`        // ssh host the project lives on; Root is where it's mounted
+	RemoteRoot   string    `json:"remote_root,omitempty"` // Directory of the project on Host
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// IsRemote reports whether a project lives on an ssh host
+func (p Project) IsRemote() bool {
+	return p.Host != ""
+}
+
+// Registry maps project IDs to project roots. Each project gets its own state directory
+// so history and caches of different repositories never mix.
+type Registry struct {
+	mu       sync.Mutex
+	root     string
+	projects map[string]Project
+}
+
+// Open loads the registry of a state directory root, creating an empty one if needed
+func Open(stateRoot string) (*Registry, error) {
+	r := &Registry{root: stateRoot, projects: make(map[string]Project)}
+
+	data, err := os.ReadFile(filepath.Join(stateRoot, File))
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project registry: %w", err)
+	}
+
+	var projects []Project
+	if err := json.Unmarshal(data, &projects); err != nil {
+		return nil, fmt.Errorf("invalid project registry %s: %w", filepath.Join(stateRoot, File), err)
+	}
+	for _, p := range projects {
+		r.projects[p.ID] = p
+	}
+	return r, nil
+}
+
+// ValidateID checks that a project ID can be used as a directory name
+func ValidateID(id string) error {
+	if !idPattern.MatchString(id) {
+		return fmt.Errorf("invalid project id %q: use up to 64 letters, digits, '.', '_' or '-', starting with a letter or digit", id)
+	}
+	return nil
+}
+
+// StateRoot returns the state directory of a project
+func (r *Registry) StateRoot(id string) string {
+	return filepath.Join(r.root, ProjectsDir, id)
+}
+
+// Register adds a project given either an existing directory or a git URL, which is
+// cloned into the project's state directory. IDs must be unique.
+func (r *Registry) Register(ctx context.Context, id, projectRoot, gitURL string) (Project, error) {
+	if err := ValidateID(id); err != nil {
+		return Project{}, err
+	}
+	if (projectRoot == "") == (gitURL == "") {
+		return Project{}, fmt.Errorf("exactly one of project_root and git_url is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.projects[id]; ok {
+		return Project{}, fmt.Errorf("project %s is already registered (%s)", id, existing.Root)
+	}
+
+	project := Project{ID: id, GitURL: gitURL, RegisteredAt: time.Now()}
+	if gitURL != "" {
+		root, err := r.clone(ctx, id, gitURL)
+		if err != nil {
+			return Project{}, err
+		}
+		project.Root = root
+	} else {
+		root, err := filepath.Abs(projectRoot)
+		if err != nil {
+			return Project{}, fmt.Errorf("invalid project_root: %w", err)
+		}
+		if !common.DirExists(root) {
+			return Project{}, fmt.Errorf("project_root %s is not a directory", root)
+		}
+		project.Root = root
+	}
+
+	if err := r.add(project); err != nil {
+		return Project{}, err
+	}
+	return project, nil
+}
+
+// RegisterRemote adds a project living in a directory on an ssh host, such as an alias of
+// ~/.ssh/config. Its root is a directory in its state directory, where callers mount the
+// remote files; commands are meant to run on the host. The host isn't contacted here.
+func (r *Registry) RegisterRemote(id, host, remoteRoot string) (Project, error) {
+	if err := ValidateID(id); err != nil {
+		return Project{}, err
+	}
+	if host == "" || strings.HasPrefix(host, "-") || strings.ContainsAny(host, " \t\n") {
+		return Project{}, fmt.Errorf("invalid ssh host: %q", host)
+	}
+	if !path.IsAbs(remoteRoot) {
+		return Project{}, fmt.Errorf("project_root on an ssh host must be absolute: %s", remoteRoot)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.projects[id]; ok {
+		return Project{}, fmt.Errorf("project %s is already registered (%s)", id, existing.Root)
+	}
+
+	root := filepath.Join(r.StateRoot(id), mountDir)
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return Project{}, fmt.Errorf("failed to create project directory: %w", err)
+	}
+	project := Project{ID: id, Root: root, Host: host, RemoteRoot: path.Clean(remoteRoot), RegisteredAt: time.Now()}
+	if err := r.add(project); err != nil {
+		os.RemoveAll(r.StateRoot(id))
+		return Project{}, err
+	}
+	return project, nil
+}
+
+// add stores a new project. Callers hold the lock.
+func (r *Registry) add(project Project) error {
+	r.projects[project.ID] = project
+	if err := r.save(); err != nil {
+		delete(r.projects, project.ID)
+		return err
+	}
+	return nil
+}
+
+// clone checks out a git URL into the state directory of a project
+func (r *Registry) clone(ctx context.Context, id, gitURL string) (string, error) {
+	if strings.HasPrefix(gitURL, "-") {
+		return "", fmt.Errorf("invalid git_url: %s", gitURL)
+	}
+
+	dir := r.StateRoot(id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create project directory: %w", err)
+	}
+	checkout := filepath.Join(dir, checkoutDir)
+	if common.DirExists(checkout) {
+		return "", fmt.Errorf("%s already exists; unregister the project first", checkout)
+	}
+
+	// Checkouts live in the state directory of this machine, whatever executor the call uses
+	ctx = runner.WithExecutor(ctx, runner.Local{})
+	output, err := runner.RunMutating(ctx, dir, "git clone --depth 1 -- "+shellQuote(gitURL)+" "+checkoutDir)
+	if err != nil {
+		os.RemoveAll(dir)
+		if runner.IsReadOnly(err) {
+			return "", err
+		}
+		return "", &common.ErrCommandFailed{Command: "git clone " + gitURL, Output: strings.TrimSpace(string(output)), Err: err}
+	}
+	return checkout, nil
+}
+
+// Update checks out the latest commit of a ref, e.g. refs/heads/main, in the checkout of a
+// project registered with a git URL. Projects registered with project_root are left untouched.
+func (r *Registry) Update(ctx context.Context, id, ref string) error {
+	project, ok := r.Get(id)
+	if !ok {
+		return fmt.Errorf("unknown project: %s", id)
+	}
+	if project.GitURL == "" {
+		return nil
+	}
+	if !refPattern.MatchString(ref) || strings.Contains(ref, "..") {
+		return fmt.Errorf("invalid ref: %s", ref)
+	}
+
+	ctx = runner.WithExecutor(ctx, runner.Local{})
+	output, err := runner.RunMutating(ctx, project.Root, "git fetch -q --depth 1 origin "+shellQuote(ref)+" && git checkout -q --force --detach FETCH_HEAD")
+	if err != nil {
+		if runner.IsReadOnly(err) {
+			return err
+		}
+		return &common.ErrCommandFailed{Command: "git fetch " + ref, Output: strings.TrimSpace(string(output)), Err: err}
+	}
+	return nil
+}
+
+// Unregister removes a project and its state directory, including a cloned checkout.
+// Directories registered with project_root are left untouched.
+func (r *Registry) Unregister(id string) (Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	project, ok := r.projects[id]
+	if !ok {
+		return Project{}, fmt.Errorf("unknown project: %s", id)
+	}
+
+	delete(r.projects, id)
+	if err := r.save(); err != nil {
+		r.projects[id] = project
+		return Project{}, err
+	}
+	if err := os.RemoveAll(r.StateRoot(id)); err != nil {
+		return project, fmt.Errorf("project unregistered but its state was not removed: %w", err)
+	}
+	return project, nil
+}
+
+// Get returns a registered project
+func (r *Registry) Get(id string) (Project, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	project, ok := r.projects[id]
+	return project, ok
+}
+
+// List returns the registered projects ordered by ID
+func (r *Registry) List() []Project {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sorted()
+}
+
+// sorted returns the projects ordered by ID. Callers hold the lock.
+func (r *Registry) sorted() []Project {
+	projects := make([]Project, 0, len(r.projects))
+	for _, p := range r.projects {
+		projects = append(projects, p)
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+	return projects
+}
+
+// save writes the registry document atomically. Callers hold the lock.
+func (r *Registry) save() error {
+	data, err := json.MarshalIndent(r.sorted(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal project registry: %w", err)
+	}
+	if err := os.MkdirAll(r.root, 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	path := filepath.Join(r.root, File)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write project registry: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// shellQuote quotes a value for use as a single sh argument
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,146 @@
+package registry
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegister_Directory(t *testing.T) {
+	stateRoot, projectRoot := t.TempDir(), t.TempDir()
+
+	reg, err := Open(stateRoot)
+	require.NoError(t, err)
+	assert.Empty(t, reg.List())
+
+	project, err := reg.Register(context.Background(), "api", projectRoot, "")
+	require.NoError(t, err)
+	assert.Equal(t, projectRoot, project.Root)
+
+	_, err = reg.Register(context.Background(), "api", projectRoot, "")
+	assert.ErrorContains(t, err, "already registered")
+	_, err = reg.Register(context.Background(), "../etc", projectRoot, "")
+	assert.ErrorContains(t, err, "invalid project id")
+	_, err = reg.Register(context.Background(), "web", filepath.Join(projectRoot, "missing"), "")
+	assert.ErrorContains(t, err, "is not a directory")
+	_, err = reg.Register(context.Background(), "web", "", "")
+	assert.ErrorContains(t, err, "exactly one of project_root and git_url")
+
+	// The registry survives a restart
+	reopened, err := Open(stateRoot)
+	require.NoError(t, err)
+	got, ok := reopened.Get("api")
+	require.True(t, ok)
+	assert.Equal(t, projectRoot, got.Root)
+	assert.Equal(t, filepath.Join(stateRoot, ProjectsDir, "api"), reopened.StateRoot("api"))
+}
+
+func TestRegister_GitURL(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	origin := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = origin
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	stateRoot := t.TempDir()
+	reg, err := Open(stateRoot)
+	require.NoError(t, err)
+
+	project, err := reg.Register(context.Background(), "cloned", "", "file://"+filepath.ToSlash(origin))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(stateRoot, ProjectsDir, "cloned", "checkout"), project.Root)
+	assert.DirExists(t, filepath.Join(project.Root, ".git"))
+
+	// A push is checked out by updating to the ref
+	require.NoError(t, os.WriteFile(filepath.Join(origin, "pushed.txt"), []byte("x"), 0644))
+	for _, args := range [][]string{
+		{"add", "pushed.txt"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "push"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = origin
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	branch, err := exec.Command("git", "-C", origin, "symbolic-ref", "HEAD").Output()
+	require.NoError(t, err)
+	require.NoError(t, reg.Update(context.Background(), "cloned", strings.TrimSpace(string(branch))))
+	assert.FileExists(t, filepath.Join(project.Root, "pushed.txt"))
+	assert.ErrorContains(t, reg.Update(context.Background(), "cloned", "--upload-pack=evil"), "invalid ref")
+
+	_, err = reg.Unregister("cloned")
+	require.NoError(t, err)
+	assert.NoDirExists(t, reg.StateRoot("cloned"), "the checkout is removed with the project")
+	_, ok := reg.Get("cloned")
+	assert.False(t, ok)
+
+	_, err = reg.Register(context.Background(), "broken", "", filepath.Join(origin, "missing"))
+	assert.Error(t, err)
+	assert.NoDirExists(t, reg.StateRoot("broken"))
+}
+
+func TestUnregister_KeepsProjectDirectory(t *testing.T) {
+	reg, err := Open(t.TempDir())
+	require.NoError(t, err)
+	projectRoot := t.TempDir()
+
+	_, err = reg.Register(context.Background(), "api", projectRoot, "")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(reg.StateRoot("api"), 0755))
+
+	_, err = reg.Unregister("api")
+	require.NoError(t, err)
+	assert.DirExists(t, projectRoot)
+	assert.NoDirExists(t, reg.StateRoot("api"))
+
+	_, err = reg.Unregister("api")
+	assert.ErrorContains(t, err, "unknown project: api")
+}
+
+func TestRegisterRemote(t *testing.T) {
+	stateRoot := t.TempDir()
+	reg, err := Open(stateRoot)
+	require.NoError(t, err)
+
+	project, err := reg.RegisterRemote("api", "devbox", "/srv/api/")
+	require.NoError(t, err)
+	assert.True(t, project.IsRemote())
+	assert.Equal(t, "/srv/api", project.RemoteRoot)
+	assert.Equal(t, filepath.Join(reg.StateRoot("api"), "remote"), project.Root)
+	assert.DirExists(t, project.Root, "the mount point exists so local tools see a directory")
+
+	_, err = reg.RegisterRemote("web", "-oProxyCommand=x", "/srv/web")
+	assert.ErrorContains(t, err, "invalid ssh host")
+	_, err = reg.RegisterRemote("web", "devbox", "srv/web")
+	assert.ErrorContains(t, err, "must be absolute")
+	_, err = reg.RegisterRemote("api", "devbox", "/srv/api")
+	assert.ErrorContains(t, err, "already registered")
+
+	reopened, err := Open(stateRoot)
+	require.NoError(t, err)
+	got, ok := reopened.Get("api")
+	require.True(t, ok)
+	assert.Equal(t, "devbox", got.Host)
+	assert.Equal(t, "/srv/api", got.RemoteRoot)
+
+	_, err = reg.Unregister("api")
+	require.NoError(t, err)
+	assert.NoDirExists(t, reg.StateRoot("api"))
+}
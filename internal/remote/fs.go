@@ -0,0 +1,266 @@
+package remote
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	pathpkg "path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"dev-env-sentinel/internal/common"
+)
+
+var _ common.FileSystem = (*FS)(nil)
+
+// maxStderr bounds the ssh output kept to explain a failed connection
+const maxStderr = 4096
+
+// FS is a directory on an ssh host, read over the host's sftp subsystem. It connects on first
+// use and again after the connection fails, and serves the file checks of a project mounted
+// with common.Mount. Names are slash separated and relative to Root.
+type FS struct {
+	Host string // ssh destination, such as an alias of ~/.ssh/config
+	Root string // Absolute directory on the host
+
+	dial     func() (*Client, error)
+	mu       sync.Mutex
+	client   *Client
+	realRoot string // Root with symbolic links resolved
+}
+
+// NewFS returns the file system of a directory on an ssh host
+func NewFS(host, root string) *FS {
+	return &FS{Host: host, Root: pathpkg.Clean(root), dial: func() (*Client, error) { return dialSSH(host) }}
+}
+
+// dialSSH starts the sftp subsystem on a host. Authentication must not prompt.
+func dialSSH(host string) (*Client, error) {
+	cmd := exec.Command("ssh", "-o", "BatchMode=yes", "-s", "--", host, "sftp")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr := &limitedBuffer{max: maxStderr}
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to run ssh: %w", err)
+	}
+
+	client, err := NewClient(&sshConn{Reader: stdout, WriteCloser: stdin, cmd: cmd})
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("failed to start sftp on %s: %s", host, msg)
+		}
+		return nil, fmt.Errorf("failed to start sftp on %s: %w", host, err)
+	}
+	return client, nil
+}
+
+// sshConn is the stdin and stdout of an ssh process
+type sshConn struct {
+	io.Reader
+	io.WriteCloser
+	cmd *exec.Cmd
+}
+
+// Close ends the ssh process
+func (c *sshConn) Close() error {
+	c.WriteCloser.Close()
+	c.cmd.Process.Kill()
+	c.cmd.Wait()
+	return nil
+}
+
+// limitedBuffer keeps the first bytes written to it
+type limitedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if room := b.max - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// connect returns the session, starting one if there is none or the last one failed
+func (f *FS) connect() (*Client, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.client != nil && f.client.Err() == nil {
+		return f.client, nil
+	}
+	if f.client != nil {
+		f.client.Close()
+		f.client = nil
+	}
+
+	client, err := f.dial()
+	if err != nil {
+		return nil, err
+	}
+	realRoot, err := client.RealPath(f.Root)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to resolve %s on %s: %w", f.Root, f.Host, err)
+	}
+	f.client, f.realRoot = client, realRoot
+	return client, nil
+}
+
+// Check connects and confirms that Root is a directory
+func (f *FS) Check() error {
+	info, err := f.Stat(".")
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s on %s is not a directory", f.Root, f.Host)
+	}
+	return nil
+}
+
+// Close ends the session; the next call starts a new one
+func (f *FS) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.client == nil {
+		return nil
+	}
+	err := f.client.Close()
+	f.client = nil
+	return err
+}
+
+// remotePath returns the path on the host of a name
+func (f *FS) remotePath(name string) string {
+	return pathpkg.Join(f.Root, name)
+}
+
+// Lstat returns the attributes of a name without following a symbolic link
+func (f *FS) Lstat(name string) (os.FileInfo, error) {
+	client, err := f.connect()
+	if err != nil {
+		return nil, err
+	}
+	return client.Lstat(f.remotePath(name))
+}
+
+// Stat returns the attributes of a name, following symbolic links
+func (f *FS) Stat(name string) (os.FileInfo, error) {
+	client, err := f.connect()
+	if err != nil {
+		return nil, err
+	}
+	info, err := client.Stat(f.remotePath(name))
+	if err != nil {
+		return nil, err
+	}
+	return &statInfo{FileInfo: info, fs: f, name: name}, nil
+}
+
+// ReadDir returns the names in a directory in lexical order
+func (f *FS) ReadDir(name string) ([]string, error) {
+	client, err := f.connect()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := client.ReadDir(f.remotePath(name))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	return names, nil
+}
+
+// Open opens a file for reading
+func (f *FS) Open(name string) (io.ReadCloser, error) {
+	client, err := f.connect()
+	if err != nil {
+		return nil, err
+	}
+	file, err := client.Open(f.remotePath(name))
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// RealPath resolves the symbolic links of a name, relative to Root
+func (f *FS) RealPath(name string) (string, error) {
+	client, err := f.connect()
+	if err != nil {
+		return "", err
+	}
+	real, err := client.RealPath(f.remotePath(name))
+	if err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	root := f.realRoot
+	f.mu.Unlock()
+	rel, err := filepath.Rel(filepath.FromSlash(root), filepath.FromSlash(real))
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// SameFile reports whether two results of Stat resolve to the same path. SFTP has no inode
+// numbers, so real paths stand in for them.
+func (f *FS) SameFile(a, b os.FileInfo) bool {
+	ai, ok := a.(*statInfo)
+	if !ok {
+		return false
+	}
+	bi, ok := b.(*statInfo)
+	if !ok {
+		return false
+	}
+	aReal, aErr := ai.realPath()
+	bReal, bErr := bi.realPath()
+	return aErr == nil && bErr == nil && aReal == bReal
+}
+
+// statInfo is a result of Stat that can resolve its real path for SameFile
+type statInfo struct {
+	os.FileInfo
+	fs   *FS
+	name string
+
+	once sync.Once
+	real string
+	err  error
+}
+
+func (i *statInfo) realPath() (string, error) {
+	i.once.Do(func() { i.real, i.err = i.fs.RealPath(i.name) })
+	return i.real, i.err
+}
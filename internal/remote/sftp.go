@@ -0,0 +1,497 @@
+// Package remote reads the files of a project on a remote dev server over the sftp subsystem
+// of ssh, so timestamp and hash checks see the remote checkout while its commands run there
+// through the ssh executor.
+package remote
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	pathpkg "path"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SFTP version 3 packet types (draft-ietf-secsh-filexfer-02)
+const (
+	fxpInit     = 1
+	fxpVersion  = 2
+	fxpOpen     = 3
+	fxpClose    = 4
+	fxpRead     = 5
+	fxpLstat    = 7
+	fxpOpendir  = 11
+	fxpReaddir  = 12
+	fxpRealpath = 16
+	fxpStat     = 17
+	fxpStatus   = 101
+	fxpHandle   = 102
+	fxpData     = 103
+	fxpName     = 104
+	fxpAttrs    = 105
+)
+
+// Status codes
+const (
+	fxOK               = 0
+	fxEOF              = 1
+	fxNoSuchFile       = 2
+	fxPermissionDenied = 3
+)
+
+// Attribute flags
+const (
+	attrSize        = 0x1
+	attrUIDGID      = 0x2
+	attrPermissions = 0x4
+	attrACModTime   = 0x8
+	attrExtended    = 0x80000000
+)
+
+// sftpVersion is the protocol version the client speaks
+const sftpVersion = 3
+
+// openRead is the pflags of OPEN for reading
+const openRead = 0x1
+
+// maxPacket bounds a packet the server sends
+const maxPacket = 256 * 1024
+
+// readSize is the data a READ asks for
+const readSize = 32 * 1024
+
+// StatusError is a failure the server reported
+type StatusError struct {
+	Code    uint32
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("sftp: %s (status %d)", e.Message, e.Code)
+	}
+	return fmt.Sprintf("sftp: status %d", e.Code)
+}
+
+// Client is an SFTP version 3 client over a connection, such as the sftp subsystem of ssh.
+// Requests are sent one at a time.
+type Client struct {
+	mu     sync.Mutex
+	conn   io.ReadWriteCloser
+	r      *bufio.Reader
+	nextID uint32
+	err    error // Failure of the connection; every later request fails with it
+}
+
+// NewClient starts an SFTP session on a connection
+func NewClient(conn io.ReadWriteCloser) (*Client, error) {
+	c := &Client{conn: conn, r: bufio.NewReader(conn)}
+	if err := c.send(fxpInit, appendUint32(nil, sftpVersion)); err != nil {
+		return nil, err
+	}
+	typ, data, err := c.receive()
+	if err != nil {
+		return nil, err
+	}
+	if typ != fxpVersion {
+		return nil, fmt.Errorf("sftp: expected version, got packet type %d", typ)
+	}
+	d := decoder{b: data}
+	if version := d.uint32(); d.err != nil || version != sftpVersion {
+		return nil, fmt.Errorf("sftp: unsupported server version %d", version)
+	}
+	return c, nil
+}
+
+// Err returns the failure of the connection, or nil while it works
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close ends the session
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Lstat returns the attributes of a path without following a symbolic link
+func (c *Client) Lstat(path string) (os.FileInfo, error) {
+	return c.stat(fxpLstat, "lstat", path)
+}
+
+// Stat returns the attributes of a path, following symbolic links
+func (c *Client) Stat(path string) (os.FileInfo, error) {
+	return c.stat(fxpStat, "stat", path)
+}
+
+func (c *Client) stat(typ byte, op, path string) (os.FileInfo, error) {
+	rtyp, data, err := c.request(typ, appendString(nil, path))
+	if err != nil {
+		return nil, err
+	}
+	if rtyp != fxpAttrs {
+		return nil, responseError(op, path, rtyp, data)
+	}
+	d := decoder{b: data}
+	info := d.attrs(pathpkg.Base(path))
+	if d.err != nil {
+		return nil, d.err
+	}
+	return info, nil
+}
+
+// RealPath returns the absolute path a path stands for, with symbolic links resolved
+func (c *Client) RealPath(path string) (string, error) {
+	typ, data, err := c.request(fxpRealpath, appendString(nil, path))
+	if err != nil {
+		return "", err
+	}
+	if typ != fxpName {
+		return "", responseError("realpath", path, typ, data)
+	}
+	d := decoder{b: data}
+	if count := d.uint32(); count != 1 {
+		return "", fmt.Errorf("sftp: realpath %s returned %d names", path, count)
+	}
+	real := d.string()
+	return real, d.err
+}
+
+// ReadDir returns the entries of a directory, without . and .., ordered by name
+func (c *Client) ReadDir(path string) ([]os.FileInfo, error) {
+	handle, err := c.handle(fxpOpendir, "opendir", path, appendString(nil, path))
+	if err != nil {
+		return nil, err
+	}
+	defer c.closeHandle(handle)
+
+	var entries []os.FileInfo
+	for {
+		typ, data, err := c.request(fxpReaddir, appendString(nil, handle))
+		if err != nil {
+			return nil, err
+		}
+		if typ != fxpName {
+			if isEOF(typ, data) {
+				break
+			}
+			return nil, responseError("readdir", path, typ, data)
+		}
+		d := decoder{b: data}
+		count := d.uint32()
+		for i := uint32(0); i < count && d.err == nil; i++ {
+			name := d.string()
+			d.string() // Long name, as ls -l shows it
+			info := d.attrs(name)
+			if name != "." && name != ".." {
+				entries = append(entries, info)
+			}
+		}
+		if d.err != nil {
+			return nil, d.err
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// Open opens a file for reading
+func (c *Client) Open(path string) (*File, error) {
+	payload := appendString(nil, path)
+	payload = appendUint32(payload, openRead)
+	payload = appendUint32(payload, 0) // No attributes
+	handle, err := c.handle(fxpOpen, "open", path, payload)
+	if err != nil {
+		return nil, err
+	}
+	return &File{c: c, handle: handle}, nil
+}
+
+// File is a remote file open for reading
+type File struct {
+	c      *Client
+	handle string
+	offset uint64
+}
+
+// Read reads the next bytes of the file
+func (f *File) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	n := len(p)
+	if n > readSize {
+		n = readSize
+	}
+	payload := appendString(nil, f.handle)
+	payload = appendUint64(payload, f.offset)
+	payload = appendUint32(payload, uint32(n))
+	typ, data, err := f.c.request(fxpRead, payload)
+	if err != nil {
+		return 0, err
+	}
+	if typ != fxpData {
+		if isEOF(typ, data) {
+			return 0, io.EOF
+		}
+		return 0, responseError("read", f.handle, typ, data)
+	}
+	d := decoder{b: data}
+	chunk := d.string()
+	if d.err != nil {
+		return 0, d.err
+	}
+	copied := copy(p, chunk)
+	f.offset += uint64(copied)
+	return copied, nil
+}
+
+// Close closes the file
+func (f *File) Close() error {
+	return f.c.closeHandle(f.handle)
+}
+
+// handle sends a request answered with a handle
+func (c *Client) handle(typ byte, op, path string, payload []byte) (string, error) {
+	rtyp, data, err := c.request(typ, payload)
+	if err != nil {
+		return "", err
+	}
+	if rtyp != fxpHandle {
+		return "", responseError(op, path, rtyp, data)
+	}
+	d := decoder{b: data}
+	handle := d.string()
+	return handle, d.err
+}
+
+// closeHandle closes a file or directory handle
+func (c *Client) closeHandle(handle string) error {
+	typ, data, err := c.request(fxpClose, appendString(nil, handle))
+	if err != nil {
+		return err
+	}
+	if typ != fxpStatus || statusOf(data).Code != fxOK {
+		return responseError("close", handle, typ, data)
+	}
+	return nil
+}
+
+// request sends a request and returns the type and payload of its response, after the id
+func (c *Client) request(typ byte, payload []byte) (byte, []byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return 0, nil, c.err
+	}
+
+	c.nextID++
+	id := c.nextID
+	if err := c.send(typ, append(appendUint32(nil, id), payload...)); err != nil {
+		c.err = err
+		return 0, nil, err
+	}
+	rtyp, data, err := c.receive()
+	if err == nil && len(data) < 4 {
+		err = fmt.Errorf("sftp: short packet of type %d", rtyp)
+	}
+	if err == nil && binary.BigEndian.Uint32(data) != id {
+		err = fmt.Errorf("sftp: response to request %d, expected %d", binary.BigEndian.Uint32(data), id)
+	}
+	if err != nil {
+		c.err = err
+		return 0, nil, err
+	}
+	return rtyp, data[4:], nil
+}
+
+// send writes a packet
+func (c *Client) send(typ byte, payload []byte) error {
+	return writePacket(c.conn, typ, payload)
+}
+
+// receive reads a packet
+func (c *Client) receive() (byte, []byte, error) {
+	return readPacket(c.r)
+}
+
+// writePacket writes a packet: its length, type and payload
+func writePacket(w io.Writer, typ byte, payload []byte) error {
+	packet := appendUint32(make([]byte, 0, 5+len(payload)), uint32(1+len(payload)))
+	packet = append(packet, typ)
+	packet = append(packet, payload...)
+	_, err := w.Write(packet)
+	return err
+}
+
+// readPacket reads a packet's type and payload
+func readPacket(r io.Reader) (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, nil, fmt.Errorf("sftp: connection closed: %w", err)
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	if length < 1 || length > maxPacket {
+		return 0, nil, fmt.Errorf("sftp: invalid packet length %d", length)
+	}
+	data := make([]byte, length-1)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return 0, nil, fmt.Errorf("sftp: connection closed: %w", err)
+	}
+	return header[4], data, nil
+}
+
+// statusOf decodes a status response
+func statusOf(data []byte) *StatusError {
+	d := decoder{b: data}
+	status := &StatusError{Code: d.uint32(), Message: d.string()}
+	if d.err != nil {
+		return &StatusError{Code: 4, Message: "malformed status"}
+	}
+	return status
+}
+
+// isEOF reports whether a response is the end-of-file status
+func isEOF(typ byte, data []byte) bool {
+	return typ == fxpStatus && statusOf(data).Code == fxEOF
+}
+
+// responseError turns an unexpected response into an error; missing files and denied
+// access satisfy os.IsNotExist and os.IsPermission
+func responseError(op, path string, typ byte, data []byte) error {
+	if typ != fxpStatus {
+		return fmt.Errorf("sftp: %s %s: unexpected packet type %d", op, path, typ)
+	}
+	status := statusOf(data)
+	var err error = status
+	switch status.Code {
+	case fxNoSuchFile:
+		err = fs.ErrNotExist
+	case fxPermissionDenied:
+		err = fs.ErrPermission
+	}
+	return &fs.PathError{Op: op, Path: path, Err: err}
+}
+
+// fileInfo is the attributes of a remote file
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+}
+
+func (i *fileInfo) Name() string       { return i.name }
+func (i *fileInfo) Size() int64        { return i.size }
+func (i *fileInfo) Mode() os.FileMode  { return i.mode }
+func (i *fileInfo) ModTime() time.Time { return i.modTime }
+func (i *fileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *fileInfo) Sys() interface{}   { return nil }
+
+// fileMode converts POSIX permission bits to a file mode
+func fileMode(perm uint32) os.FileMode {
+	mode := os.FileMode(perm & 0777)
+	switch perm & 0170000 {
+	case 0040000:
+		mode |= os.ModeDir
+	case 0120000:
+		mode |= os.ModeSymlink
+	case 0010000:
+		mode |= os.ModeNamedPipe
+	case 0140000:
+		mode |= os.ModeSocket
+	case 0020000:
+		mode |= os.ModeDevice | os.ModeCharDevice
+	case 0060000:
+		mode |= os.ModeDevice
+	}
+	return mode
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return binary.BigEndian.AppendUint32(b, v)
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	return binary.BigEndian.AppendUint64(b, v)
+}
+
+func appendString(b []byte, s string) []byte {
+	return append(appendUint32(b, uint32(len(s))), s...)
+}
+
+// errShortPacket is the error of a decoder that ran past the end of its packet
+var errShortPacket = errors.New("sftp: short packet")
+
+// decoder reads the fields of a packet, recording the first error
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) uint32() uint32 {
+	if d.err != nil || len(d.b) < 4 {
+		d.err = errShortPacket
+		return 0
+	}
+	v := binary.BigEndian.Uint32(d.b)
+	d.b = d.b[4:]
+	return v
+}
+
+func (d *decoder) uint64() uint64 {
+	if d.err != nil || len(d.b) < 8 {
+		d.err = errShortPacket
+		return 0
+	}
+	v := binary.BigEndian.Uint64(d.b)
+	d.b = d.b[8:]
+	return v
+}
+
+func (d *decoder) string() string {
+	n := d.uint32()
+	if d.err != nil || uint32(len(d.b)) < n {
+		d.err = errShortPacket
+		return ""
+	}
+	s := string(d.b[:n])
+	d.b = d.b[n:]
+	return s
+}
+
+// attrs reads the attributes of a file. SFTP version 3 times have a resolution of seconds.
+func (d *decoder) attrs(name string) *fileInfo {
+	info := &fileInfo{name: name}
+	flags := d.uint32()
+	if flags&attrSize != 0 {
+		info.size = int64(d.uint64())
+	}
+	if flags&attrUIDGID != 0 {
+		d.uint32()
+		d.uint32()
+	}
+	if flags&attrPermissions != 0 {
+		info.mode = fileMode(d.uint32())
+	}
+	if flags&attrACModTime != 0 {
+		d.uint32() // Access time
+		info.modTime = time.Unix(int64(d.uint32()), 0)
+	}
+	if flags&attrExtended != 0 {
+		for n := d.uint32(); n > 0 && d.err == nil; n-- {
+			d.string()
+			d.string()
+		}
+	}
+	return info
+}
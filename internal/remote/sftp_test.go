@@ -0,0 +1,217 @@
+package remote
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serve answers the SFTP requests the client sends with the files of this machine
+func serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	handles := map[string]interface{}{}
+	next := 0
+
+	status := func(id uint32, code uint32, msg string) []byte {
+		b := appendUint32(appendUint32(nil, id), code)
+		return appendString(appendString(b, msg), "")
+	}
+	attrs := func(b []byte, info os.FileInfo) []byte {
+		perm := uint32(info.Mode().Perm())
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			perm |= 0120000
+		case info.IsDir():
+			perm |= 0040000
+		default:
+			perm |= 0100000
+		}
+		b = appendUint32(b, attrSize|attrPermissions|attrACModTime)
+		b = appendUint64(b, uint64(info.Size()))
+		b = appendUint32(b, perm)
+		b = appendUint32(b, uint32(info.ModTime().Unix()))
+		return appendUint32(b, uint32(info.ModTime().Unix()))
+	}
+	fail := func(id uint32, err error) (byte, []byte) {
+		if errors.Is(err, os.ErrNotExist) {
+			return fxpStatus, status(id, fxNoSuchFile, "No such file")
+		}
+		return fxpStatus, status(id, 4, err.Error())
+	}
+
+	for {
+		typ, data, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		d := decoder{b: data}
+		if typ == fxpInit {
+			writePacket(conn, fxpVersion, appendUint32(nil, sftpVersion))
+			continue
+		}
+		id := d.uint32()
+		rtyp, resp := func() (byte, []byte) {
+			switch typ {
+			case fxpLstat, fxpStat:
+				stat := os.Stat
+				if typ == fxpLstat {
+					stat = os.Lstat
+				}
+				info, err := stat(d.string())
+				if err != nil {
+					return fail(id, err)
+				}
+				return fxpAttrs, attrs(appendUint32(nil, id), info)
+			case fxpRealpath:
+				real, err := filepath.EvalSymlinks(d.string())
+				if err != nil {
+					return fail(id, err)
+				}
+				b := appendString(appendUint32(appendUint32(nil, id), 1), real)
+				return fxpName, appendUint32(appendString(b, real), 0)
+			case fxpOpendir, fxpOpen:
+				path := d.string()
+				var handle interface{}
+				if typ == fxpOpendir {
+					entries, err := os.ReadDir(path)
+					if err != nil {
+						return fail(id, err)
+					}
+					handle = &entries
+				} else {
+					f, err := os.Open(path)
+					if err != nil {
+						return fail(id, err)
+					}
+					handle = f
+				}
+				next++
+				name := string(rune('a' + next))
+				handles[name] = handle
+				return fxpHandle, appendString(appendUint32(nil, id), name)
+			case fxpReaddir:
+				entries := handles[d.string()].(*[]os.DirEntry)
+				if len(*entries) == 0 {
+					return fxpStatus, status(id, fxEOF, "")
+				}
+				b := appendUint32(appendUint32(nil, id), uint32(len(*entries)))
+				for _, entry := range *entries {
+					info, _ := entry.Info()
+					b = attrs(appendString(appendString(b, entry.Name()), "-rw-r--r-- "+entry.Name()), info)
+				}
+				*entries = nil
+				return fxpName, b
+			case fxpRead:
+				f := handles[d.string()].(*os.File)
+				offset, length := d.uint64(), d.uint32()
+				buf := make([]byte, length)
+				n, err := f.ReadAt(buf, int64(offset))
+				if n == 0 && err == io.EOF {
+					return fxpStatus, status(id, fxEOF, "")
+				}
+				return fxpData, appendString(appendUint32(nil, id), string(buf[:n]))
+			case fxpClose:
+				handle := d.string()
+				if f, ok := handles[handle].(*os.File); ok {
+					f.Close()
+				}
+				delete(handles, handle)
+				return fxpStatus, status(id, fxOK, "")
+			}
+			return fxpStatus, status(id, 8, "unsupported")
+		}()
+		if err := writePacket(conn, rtyp, resp); err != nil {
+			return
+		}
+	}
+}
+
+// pipeFS returns the FS of a local directory read through the fake server, and a counter of
+// the connections it made
+func pipeFS(t *testing.T, root string) (*FS, *int) {
+	t.Helper()
+	dials := 0
+	fs := &FS{Host: "devbox", Root: root, dial: func() (*Client, error) {
+		dials++
+		client, server := net.Pipe()
+		go serve(server)
+		return NewClient(client)
+	}}
+	t.Cleanup(func() { fs.Close() })
+	return fs, &dials
+}
+
+func TestFS(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "target"), 0755))
+	content := make([]byte, 3*readSize+10)
+	for i := range content {
+		content[i] = byte(i)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(root, "target", "app.jar"), content, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "pom.xml"), []byte("<project/>"), 0644))
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(filepath.Join(root, "pom.xml"), old, old))
+	require.NoError(t, os.Symlink("target", filepath.Join(root, "out")))
+
+	fs, dials := pipeFS(t, root)
+	require.NoError(t, fs.Check())
+
+	info, err := fs.Stat("pom.xml")
+	require.NoError(t, err)
+	assert.Equal(t, "pom.xml", info.Name())
+	assert.Equal(t, int64(10), info.Size())
+	assert.True(t, info.ModTime().Equal(old))
+	assert.False(t, info.IsDir())
+
+	info, err = fs.Lstat("out")
+	require.NoError(t, err)
+	assert.True(t, info.Mode()&os.ModeSymlink != 0)
+	out, err := fs.Stat("out")
+	require.NoError(t, err)
+	assert.True(t, out.IsDir())
+	target, err := fs.Stat("target")
+	require.NoError(t, err)
+	assert.True(t, fs.SameFile(out, target), "links resolve to the same real path")
+
+	real, err := fs.RealPath("out")
+	require.NoError(t, err)
+	assert.Equal(t, "target", real)
+
+	_, err = fs.Stat("missing")
+	assert.True(t, os.IsNotExist(err))
+
+	names, err := fs.ReadDir(".")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"out", "pom.xml", "target"}, names)
+
+	f, err := fs.Open("target/app.jar")
+	require.NoError(t, err)
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	assert.Equal(t, content, data)
+
+	// A dropped connection is replaced on the next call
+	fs.client.Close()
+	_, err = fs.Stat("pom.xml")
+	assert.Error(t, err)
+	_, err = fs.Stat("pom.xml")
+	require.NoError(t, err)
+	assert.Equal(t, 2, *dials)
+}
+
+func TestFileMode(t *testing.T) {
+	assert.Equal(t, os.ModeDir|0755, fileMode(0040755))
+	assert.Equal(t, os.ModeSymlink|0777, fileMode(0120777))
+	assert.Equal(t, os.FileMode(0644), fileMode(0100644))
+}
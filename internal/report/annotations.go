@@ -0,0 +1,96 @@
+package report
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteGitHub writes findings as GitHub Actions workflow commands (::error / ::warning)
+// so they show up as annotations on the pull request
+func WriteGitHub(w io.Writer, findings []Finding) error {
+	for _, f := range findings {
+		props := []string{"title=" + escapeGitHubProperty("sentinel: "+f.Check)}
+		if f.File != "" {
+			props = append([]string{"file=" + escapeGitHubProperty(f.File)}, props...)
+			if f.Line > 0 {
+				props = append(props, fmt.Sprintf("line=%d", f.Line))
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "::%s %s::%s\n", f.Severity, strings.Join(props, ","), escapeGitHubData(f.Message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeGitHubData escapes a workflow command message
+func escapeGitHubData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// escapeGitHubProperty escapes a workflow command property value
+func escapeGitHubProperty(s string) string {
+	s = escapeGitHubData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
+
+// gitLabIssue is one entry of a GitLab code quality report
+type gitLabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitLabLocation `json:"location"`
+}
+
+type gitLabLocation struct {
+	Path  string      `json:"path"`
+	Lines gitLabLines `json:"lines"`
+}
+
+type gitLabLines struct {
+	Begin int `json:"begin"`
+}
+
+// WriteGitLab writes findings as a GitLab code quality report (JSON array),
+// suitable for artifacts:reports:codequality
+func WriteGitLab(w io.Writer, findings []Finding) error {
+	issues := make([]gitLabIssue, 0, len(findings))
+	for _, f := range findings {
+		path := f.File
+		if path == "" {
+			// GitLab requires a location; attribute project-wide issues to the project root
+			path = "."
+		}
+		line := f.Line
+		if line == 0 {
+			line = 1
+		}
+
+		severity := "major"
+		if f.Severity == SeverityWarning {
+			severity = "minor"
+		}
+
+		sum := md5.Sum([]byte(strings.Join([]string{f.Check, f.Message, f.File, fmt.Sprint(f.Line)}, "\x00")))
+		issues = append(issues, gitLabIssue{
+			Description: f.Message,
+			CheckName:   f.Check,
+			Fingerprint: hex.EncodeToString(sum[:]),
+			Severity:    severity,
+			Location:    gitLabLocation{Path: path, Lines: gitLabLines{Begin: line}},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(issues)
+}
@@ -0,0 +1,206 @@
+package report
+
+import (
+	"path/filepath"
+	"strings"
+
+	"dev-env-sentinel/internal/auditor"
+	"dev-env-sentinel/internal/container"
+	"dev-env-sentinel/internal/dockerfile"
+	"dev-env-sentinel/internal/infra"
+	"dev-env-sentinel/internal/locale"
+	"dev-env-sentinel/internal/mirror"
+	"dev-env-sentinel/internal/nix"
+	"dev-env-sentinel/internal/portability"
+	"dev-env-sentinel/internal/preflight"
+	"dev-env-sentinel/internal/triage"
+	"dev-env-sentinel/internal/trust"
+	"dev-env-sentinel/internal/verifier"
+	"dev-env-sentinel/internal/wrapper"
+)
+
+// Severity levels used by findings
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Finding is a single environment issue flattened out of a tool report,
+// with an optional location so CI systems can show it inline
+type Finding struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+}
+
+// Collect flattens a tool result into findings. File paths are made relative to projectRoot.
+// Results without issues (including plain string results) produce no findings.
+func Collect(check, projectRoot string, result interface{}) []Finding {
+	var findings []Finding
+
+	switch v := result.(type) {
+	case *verifier.FreshnessReport:
+		for _, issue := range v.Issues {
+			severity := SeverityError
+			if issue.Severity == SeverityWarning {
+				severity = SeverityWarning
+			}
+			findings = append(findings, Finding{
+				Check:    check,
+				Severity: severity,
+				Message:  issue.Message,
+				File:     relativePath(projectRoot, issue.File),
+			})
+		}
+	case *auditor.EnvVarReport:
+		// Point each unset variable at the code that references it
+		for _, ref := range v.References {
+			if ref.IsSet || ref.HasDefault {
+				continue
+			}
+			findings = append(findings, Finding{
+				Check:    check,
+				Severity: SeverityError,
+				Message:  "Environment variable " + ref.Name + " is not set",
+				File:     relativePath(projectRoot, ref.File),
+				Line:     ref.Line,
+			})
+		}
+		for _, issue := range v.Issues {
+			if strings.HasPrefix(issue, "Missing environment variable:") {
+				continue
+			}
+			findings = append(findings, Finding{Check: check, Severity: SeverityError, Message: issue})
+		}
+	case *infra.InfrastructureReport:
+		findings = append(findings, collectIssues(check, v.Issues)...)
+	case *trust.TrustReport:
+		findings = append(findings, collectIssues(check, v.Issues)...)
+	case *locale.LocaleReport:
+		findings = append(findings, collectIssues(check, v.Issues)...)
+	case *portability.PortabilityReport:
+		for _, issue := range v.Issues {
+			severity := SeverityError
+			if issue.Type == portability.IssueGitAttributes || issue.Type == portability.IssueAutoCRLF {
+				severity = SeverityWarning
+			}
+			findings = append(findings, Finding{
+				Check:    check,
+				Severity: severity,
+				Message:  issue.Message,
+				File:     relativePath(projectRoot, issue.File),
+			})
+		}
+	case *mirror.MirrorReport:
+		for _, issue := range v.Issues {
+			severity := SeverityError
+			if issue.Type == mirror.IssueSlow {
+				severity = SeverityWarning
+			}
+			findings = append(findings, Finding{
+				Check:    check,
+				Severity: severity,
+				Message:  issue.Message,
+				File:     relativePath(projectRoot, issue.File),
+			})
+		}
+	case *container.RuntimeReport:
+		for _, issue := range v.Issues {
+			severity := SeverityError
+			if issue.Severity == "warning" {
+				severity = SeverityWarning
+			}
+			findings = append(findings, Finding{
+				Check:    check,
+				Severity: severity,
+				Message:  issue.Message,
+			})
+		}
+	case *wrapper.WrapperReport:
+		for _, issue := range v.Issues {
+			findings = append(findings, Finding{
+				Check:    check,
+				Severity: SeverityError,
+				Message:  issue.Message,
+				File:     relativePath(projectRoot, issue.File),
+			})
+		}
+	case *dockerfile.ParityReport:
+		for _, issue := range v.Issues {
+			severity := SeverityError
+			if issue.Severity == "warning" {
+				severity = SeverityWarning
+			}
+			findings = append(findings, Finding{
+				Check:    check,
+				Severity: severity,
+				Message:  issue.Message,
+				File:     issue.File,
+				Line:     issue.Line,
+			})
+		}
+	case *nix.Report:
+		for _, issue := range v.Issues {
+			severity := SeverityError
+			if issue.Severity == "warning" {
+				severity = SeverityWarning
+			}
+			findings = append(findings, Finding{
+				Check:    check,
+				Severity: severity,
+				Message:  issue.Message,
+				File:     issue.File,
+				Line:     issue.Line,
+			})
+		}
+	case *triage.Report:
+		for _, match := range v.Matches {
+			finding := Finding{Check: check, Severity: SeverityError, Message: match.Message}
+			if v.Source != triage.SourceText {
+				finding.File = relativePath(projectRoot, v.Source)
+				finding.Line = match.Line
+			}
+			findings = append(findings, finding)
+		}
+	case *preflight.Report:
+		// Only the listed blockers; the checks themselves report the rest
+		for _, blocker := range v.Blockers {
+			findings = append(findings, Finding{Check: blocker.Check, Severity: SeverityError, Message: blocker.Message})
+		}
+	}
+
+	return findings
+}
+
+// collectIssues converts plain issue strings into findings. Indented lines
+// are details of the previous issue and are folded into its message.
+func collectIssues(check string, issues []string) []Finding {
+	var findings []Finding
+	for _, issue := range issues {
+		if strings.TrimSpace(issue) == "" {
+			continue
+		}
+		if strings.HasPrefix(issue, " ") && len(findings) > 0 {
+			last := &findings[len(findings)-1]
+			last.Message += "\n" + strings.TrimSpace(issue)
+			continue
+		}
+		findings = append(findings, Finding{Check: check, Severity: SeverityError, Message: strings.TrimSpace(issue)})
+	}
+	return findings
+}
+
+// relativePath returns path relative to projectRoot using forward slashes
+func relativePath(projectRoot, path string) string {
+	if path == "" {
+		return ""
+	}
+	if filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(projectRoot, path); err == nil {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(path)
+}
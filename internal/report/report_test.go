@@ -0,0 +1,84 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"dev-env-sentinel/internal/auditor"
+	"dev-env-sentinel/internal/infra"
+	"dev-env-sentinel/internal/verifier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollect(t *testing.T) {
+	root := t.TempDir()
+
+	findings := Collect("env_var_audit", root, &auditor.EnvVarReport{
+		References: []auditor.EnvVarReference{
+			{Name: "API_KEY", File: filepath.Join(root, "src", "App.java"), Line: 12},
+			{Name: "HOME", File: filepath.Join(root, "src", "App.java"), Line: 13, IsSet: true},
+		},
+		Issues: []string{
+			"Missing environment variable: API_KEY",
+			"Variable DB_URL declared in config but not set",
+		},
+	})
+	require.Len(t, findings, 2)
+	assert.Equal(t, Finding{Check: "env_var_audit", Severity: "error", Message: "Environment variable API_KEY is not set", File: "src/App.java", Line: 12}, findings[0])
+	assert.Equal(t, "Variable DB_URL declared in config but not set", findings[1].Message)
+	assert.Empty(t, findings[1].File)
+
+	findings = Collect("verify_build_freshness", root, &verifier.FreshnessReport{
+		Issues: []verifier.Issue{{Severity: "warning", Message: "Target file not found: target/app.jar", File: "target/app.jar"}},
+	})
+	require.Len(t, findings, 1)
+	assert.Equal(t, "warning", findings[0].Severity)
+	assert.Equal(t, "target/app.jar", findings[0].File)
+
+	findings = Collect("check_infrastructure_parity", root, &infra.InfrastructureReport{
+		Issues: []string{"Language version incompatibility detected", "  - java 11 < 17", "postgres: not running"},
+	})
+	require.Len(t, findings, 2)
+	assert.Equal(t, "Language version incompatibility detected\n- java 11 < 17", findings[0].Message)
+
+	assert.Empty(t, Collect("env_var_audit", root, "No ecosystems detected in project"))
+}
+
+func TestWriteGitHub(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteGitHub(&buf, []Finding{
+		{Check: "env_var_audit", Severity: "error", Message: "Environment variable API_KEY is not set", File: "src/App.java", Line: 12},
+		{Check: "check_infrastructure_parity", Severity: "warning", Message: "50% done\nnext line"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		"::error file=src/App.java,title=sentinel%3A env_var_audit,line=12::Environment variable API_KEY is not set\n"+
+			"::warning title=sentinel%3A check_infrastructure_parity::50%25 done%0Anext line\n",
+		buf.String())
+}
+
+func TestWriteGitLab(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteGitLab(&buf, []Finding{
+		{Check: "env_var_audit", Severity: "error", Message: "API_KEY is not set", File: "src/App.java", Line: 12},
+		{Check: "verify_build_freshness", Severity: "warning", Message: "stale"},
+	})
+	require.NoError(t, err)
+
+	var issues []map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &issues))
+	require.Len(t, issues, 2)
+	assert.Equal(t, "major", issues[0]["severity"])
+	assert.Equal(t, "src/App.java", issues[0]["location"].(map[string]interface{})["path"])
+	assert.Len(t, issues[0]["fingerprint"], 32)
+	assert.Equal(t, "minor", issues[1]["severity"])
+	assert.Equal(t, ".", issues[1]["location"].(map[string]interface{})["path"])
+
+	buf.Reset()
+	require.NoError(t, WriteGitLab(&buf, nil))
+	assert.Equal(t, "[]\n", buf.String())
+}
@@ -0,0 +1,176 @@
+// Package resources measures the memory, CPU and disk headroom of the machine, so heavy
+// fixes such as full rebuilds are not started on a machine that is already at capacity
+package resources
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"dev-env-sentinel/internal/runner"
+)
+
+// Usage is the free capacity of the machine. Zero values are unknown and never reported.
+type Usage struct {
+	FreeMemoryMB int
+	FreeDiskMB   int     // Free space on the file system of the project
+	Load         float64 // 1-minute load average
+	CPUs         int
+}
+
+// Policy sets the minimum headroom heavy fixes need and what happens without it
+type Policy struct {
+	MinMemoryMB int
+	MinDiskMB   int
+	MaxLoad     float64 // Highest 1-minute load average per CPU
+	Refuse      bool    // Refuse heavy fixes without enough headroom instead of warning
+}
+
+// Default policy: heavy fixes want 1 GiB of memory and 2 GiB of disk free, and the CPUs
+// not already fully busy; the fix still runs, with a warning, when they are not
+var Default = Policy{MinMemoryMB: 1024, MinDiskMB: 2048, MaxLoad: 1.0}
+
+type policyKey struct{}
+
+// WithPolicy returns a context whose heavy fixes are checked against policy
+func WithPolicy(ctx context.Context, policy Policy) context.Context {
+	return context.WithValue(ctx, policyKey{}, policy)
+}
+
+// PolicyFrom returns the policy of a context, or Default
+func PolicyFrom(ctx context.Context) Policy {
+	if policy, ok := ctx.Value(policyKey{}).(Policy); ok {
+		return policy
+	}
+	return Default
+}
+
+// Shortfalls lists how the usage falls short of the policy, raised to at least memoryMB
+// and diskMB of free memory and disk
+func (p Policy) Shortfalls(usage Usage, memoryMB, diskMB int) []string {
+	if memoryMB < p.MinMemoryMB {
+		memoryMB = p.MinMemoryMB
+	}
+	if diskMB < p.MinDiskMB {
+		diskMB = p.MinDiskMB
+	}
+
+	var shortfalls []string
+	if usage.FreeMemoryMB > 0 && memoryMB > 0 && usage.FreeMemoryMB < memoryMB {
+		shortfalls = append(shortfalls, fmt.Sprintf("%d MiB of memory available, needs %d MiB", usage.FreeMemoryMB, memoryMB))
+	}
+	if usage.FreeDiskMB > 0 && diskMB > 0 && usage.FreeDiskMB < diskMB {
+		shortfalls = append(shortfalls, fmt.Sprintf("%d MiB of disk free, needs %d MiB", usage.FreeDiskMB, diskMB))
+	}
+	if usage.Load > 0 && usage.CPUs > 0 && p.MaxLoad > 0 && usage.Load/float64(usage.CPUs) > p.MaxLoad {
+		shortfalls = append(shortfalls, fmt.Sprintf("load average %.2f on %d CPUs, at most %.2f per CPU", usage.Load, usage.CPUs, p.MaxLoad))
+	}
+	return shortfalls
+}
+
+// procDir is where Linux exposes memory and load statistics
+var procDir = "/proc"
+
+// Measure reads the free memory, load and free disk space for a directory. Values that
+// can't be read on this platform are left at 0.
+func Measure(ctx context.Context, dir string) Usage {
+	usage := Usage{CPUs: runtime.NumCPU()}
+	switch runtime.GOOS {
+	case "linux":
+		usage.FreeMemoryMB = readMemAvailable(procDir + "/meminfo")
+		if data, err := os.ReadFile(procDir + "/loadavg"); err == nil {
+			usage.Load = parseLoad(string(data))
+		}
+	case "darwin":
+		if output, err := runner.Output(ctx, "", "vm_stat"); err == nil {
+			usage.FreeMemoryMB = parseVMStat(string(output))
+		}
+		if output, err := runner.Output(ctx, "", "sysctl -n vm.loadavg"); err == nil {
+			usage.Load = parseLoad(strings.Trim(strings.TrimSpace(string(output)), "{} "))
+		}
+	case "windows":
+		return usage
+	}
+	if output, err := runner.Output(ctx, "", "df -Pk "+shellQuote(dir)); err == nil {
+		usage.FreeDiskMB = parseDF(string(output))
+	}
+	return usage
+}
+
+// readMemAvailable reads MemAvailable from /proc/meminfo in MiB
+func readMemAvailable(path string) int {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemAvailable:" {
+			kb, _ := strconv.Atoi(fields[1])
+			return kb / 1024
+		}
+	}
+	return 0
+}
+
+// parseLoad reads the 1-minute load average, the first field of /proc/loadavg or vm.loadavg
+func parseLoad(value string) float64 {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return 0
+	}
+	load, _ := strconv.ParseFloat(fields[0], 64)
+	return load
+}
+
+var (
+	vmStatPageSize = regexp.MustCompile(`page size of (\d+) bytes`)
+	vmStatPages    = regexp.MustCompile(`^Pages (free|inactive|speculative|purgeable):\s+(\d+)\.`)
+)
+
+// parseVMStat estimates available memory in MiB from macOS vm_stat output: free, inactive,
+// speculative and purgeable pages can all be handed to a new process
+func parseVMStat(output string) int {
+	pageSize := 4096
+	if match := vmStatPageSize.FindStringSubmatch(output); match != nil {
+		pageSize, _ = strconv.Atoi(match[1])
+	}
+	pages := 0
+	for _, line := range strings.Split(output, "\n") {
+		if match := vmStatPages.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			n, _ := strconv.Atoi(match[2])
+			pages += n
+		}
+	}
+	return pages * pageSize / (1024 * 1024)
+}
+
+// parseDF reads the available space in MiB from POSIX df -Pk output
+func parseDF(output string) int {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) < 2 {
+		return 0
+	}
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 4 {
+		return 0
+	}
+	kb, err := strconv.Atoi(fields[3])
+	if err != nil {
+		return 0
+	}
+	return kb / 1024
+}
+
+// shellQuote quotes a path for the shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
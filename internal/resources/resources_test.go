@@ -0,0 +1,90 @@
+package resources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShortfalls(t *testing.T) {
+	policy := Policy{MinMemoryMB: 1024, MinDiskMB: 2048, MaxLoad: 1.0}
+	usage := Usage{FreeMemoryMB: 512, FreeDiskMB: 10240, Load: 9.5, CPUs: 8}
+
+	assert.Equal(t, []string{
+		"512 MiB of memory available, needs 1024 MiB",
+		"load average 9.50 on 8 CPUs, at most 1.00 per CPU",
+	}, policy.Shortfalls(usage, 0, 0))
+
+	// A fix's own requirements raise the minimums
+	assert.Equal(t, []string{
+		"512 MiB of memory available, needs 1024 MiB",
+		"10240 MiB of disk free, needs 20480 MiB",
+		"load average 9.50 on 8 CPUs, at most 1.00 per CPU",
+	}, policy.Shortfalls(usage, 256, 20480))
+}
+
+func TestShortfalls_Enough(t *testing.T) {
+	usage := Usage{FreeMemoryMB: 8192, FreeDiskMB: 50000, Load: 2, CPUs: 8}
+	assert.Empty(t, Default.Shortfalls(usage, 2048, 5120))
+}
+
+func TestShortfalls_UnknownUsage(t *testing.T) {
+	assert.Empty(t, Default.Shortfalls(Usage{}, 2048, 5120))
+}
+
+func TestPolicyFrom(t *testing.T) {
+	assert.Equal(t, Default, PolicyFrom(context.Background()))
+
+	policy := Policy{MinMemoryMB: 4096, Refuse: true}
+	assert.Equal(t, policy, PolicyFrom(WithPolicy(context.Background(), policy)))
+}
+
+func TestParseLoad(t *testing.T) {
+	assert.Equal(t, 0.52, parseLoad("0.52 0.58 0.59 1/467 12345\n"))
+	assert.Equal(t, 3.1, parseLoad("3.10 2.80 2.50"))
+	assert.Equal(t, 0.0, parseLoad(""))
+}
+
+func TestParseVMStat(t *testing.T) {
+	output := `Mach Virtual Memory Statistics: (page size of 16384 bytes)
+Pages free:                               12800.
+Pages active:                            400000.
+Pages inactive:                           38400.
+Pages speculative:                        12800.
+Pages purgeable:                             0.
+`
+	// (12800 + 38400 + 12800) pages of 16 KiB
+	assert.Equal(t, 1000, parseVMStat(output))
+}
+
+func TestParseDF(t *testing.T) {
+	output := `Filesystem     1024-blocks      Used Available Capacity Mounted on
+/dev/sda1        102400000  81920000  20480000      80% /
+`
+	assert.Equal(t, 20000, parseDF(output))
+	assert.Equal(t, 0, parseDF("df: /missing: No such file or directory"))
+}
+
+func TestMeasure_Linux(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Reads /proc")
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "meminfo"), []byte("MemTotal:       16384000 kB\nMemFree:          204800 kB\nMemAvailable:    2097152 kB\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "loadavg"), []byte("1.50 1.00 0.50 2/300 999\n"), 0644))
+	original := procDir
+	procDir = dir
+	defer func() { procDir = original }()
+
+	usage := Measure(context.Background(), t.TempDir())
+	assert.Equal(t, 2048, usage.FreeMemoryMB)
+	assert.Equal(t, 1.5, usage.Load)
+	assert.Equal(t, runtime.NumCPU(), usage.CPUs)
+	assert.Positive(t, usage.FreeDiskMB)
+}
@@ -0,0 +1,168 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Executor runs the shell commands of checks and fixes on a target: this machine, a container
+// or a remote dev box. The same commands run on each, so their results feed the same reports.
+type Executor interface {
+	// Command returns the command that runs a shell command in dir on the target; an empty
+	// dir is the target's default directory
+	Command(ctx context.Context, dir, command string) *exec.Cmd
+	// String names the target in specs, records and reports, e.g. ssh:devbox
+	String() string
+}
+
+// Executor kinds, as written in specs
+const (
+	ExecutorLocal  = "local"
+	ExecutorDocker = "docker"
+	ExecutorSSH    = "ssh"
+)
+
+// Local runs commands on this machine
+type Local struct{}
+
+// Command runs the command with sh in dir
+func (Local) Command(ctx context.Context, dir, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	return cmd
+}
+
+func (Local) String() string { return ExecutorLocal }
+
+// DockerExec runs commands in a running container with docker exec
+type DockerExec struct {
+	Container string
+	Workdir   string // Directory commands run in instead of the one asked for, e.g. where the project is mounted
+}
+
+// Command runs the command with sh inside the container
+func (d DockerExec) Command(ctx context.Context, dir, command string) *exec.Cmd {
+	args := []string{"exec"}
+	if dir = targetDir(dir, d.Workdir); dir != "" {
+		args = append(args, "--workdir", dir)
+	}
+	args = append(args, d.Container, "sh", "-c", command)
+	return exec.CommandContext(ctx, "docker", args...)
+}
+
+func (d DockerExec) String() string { return spec(ExecutorDocker, d.Container, d.Workdir) }
+
+// SSH runs commands on a remote host, given as an ssh destination such as an alias of
+// ~/.ssh/config or user@host. Authentication must not prompt.
+type SSH struct {
+	Host    string
+	Workdir string // Directory commands run in instead of the one asked for, e.g. the remote checkout
+	// LocalRoot is the local directory a remote project is mounted at. Commands asked to run
+	// in it or below run in the same place under Workdir.
+	LocalRoot string
+}
+
+// Command runs the command with sh on the host
+func (s SSH) Command(ctx context.Context, dir, command string) *exec.Cmd {
+	remote := "sh -c " + ShellQuote(command)
+	if dir = s.remoteDir(dir); dir != "" {
+		remote = "cd " + ShellQuote(dir) + " && " + remote
+	}
+	return exec.CommandContext(ctx, "ssh", "-o", "BatchMode=yes", "--", s.Host, remote)
+}
+
+func (s SSH) String() string { return spec(ExecutorSSH, s.Host, s.Workdir) }
+
+// remoteDir returns the directory on the host a command asked to run in dir runs in
+func (s SSH) remoteDir(dir string) string {
+	if s.LocalRoot != "" && s.Workdir != "" && dir != "" {
+		if rel, err := filepath.Rel(s.LocalRoot, dir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return path.Join(s.Workdir, filepath.ToSlash(rel))
+		}
+	}
+	return targetDir(dir, s.Workdir)
+}
+
+// ParseExecutor parses an executor spec: local, docker:<container>[:<workdir>] or
+// ssh:<host>[:<workdir>]. An empty spec is local.
+func ParseExecutor(s string) (Executor, error) {
+	kind, rest, _ := strings.Cut(strings.TrimSpace(s), ":")
+	target, workdir, _ := strings.Cut(rest, ":")
+	switch kind {
+	case "", ExecutorLocal:
+		if rest != "" {
+			return nil, fmt.Errorf("invalid executor %q: local takes no target", s)
+		}
+		return Local{}, nil
+	case ExecutorDocker:
+		if err := checkTarget(s, target, "container"); err != nil {
+			return nil, err
+		}
+		return DockerExec{Container: target, Workdir: workdir}, nil
+	case ExecutorSSH:
+		if err := checkTarget(s, target, "host"); err != nil {
+			return nil, err
+		}
+		return SSH{Host: target, Workdir: workdir}, nil
+	default:
+		return nil, fmt.Errorf("unknown executor %q (use local, docker:<container> or ssh:<host>)", s)
+	}
+}
+
+// checkTarget checks the container or host of an executor spec
+func checkTarget(s, target, what string) error {
+	if target == "" {
+		return fmt.Errorf("invalid executor %q: a %s is required", s, what)
+	}
+	if strings.HasPrefix(target, "-") {
+		// It would be read as an option of docker or ssh
+		return fmt.Errorf("invalid executor %q: the %s must not start with -", s, what)
+	}
+	return nil
+}
+
+// IsLocal reports whether an executor runs commands on this machine
+func IsLocal(e Executor) bool {
+	_, ok := e.(Local)
+	return e == nil || ok
+}
+
+type executorKey struct{}
+
+// WithExecutor returns a context whose commands run with executor
+func WithExecutor(ctx context.Context, executor Executor) context.Context {
+	return context.WithValue(ctx, executorKey{}, executor)
+}
+
+// ExecutorFrom returns the executor of a context, or Local when it has none
+func ExecutorFrom(ctx context.Context) Executor {
+	if executor, ok := ctx.Value(executorKey{}).(Executor); ok && executor != nil {
+		return executor
+	}
+	return Local{}
+}
+
+// ShellQuote quotes s as a single POSIX shell word
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// targetDir returns the directory a command runs in on a target with a workdir
+func targetDir(dir, workdir string) string {
+	if workdir != "" && dir != "" {
+		return workdir
+	}
+	return dir
+}
+
+// spec formats an executor spec
+func spec(kind, target, workdir string) string {
+	if workdir != "" {
+		return kind + ":" + target + ":" + workdir
+	}
+	return kind + ":" + target
+}
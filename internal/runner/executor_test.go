@@ -0,0 +1,111 @@
+package runner
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExecutor(t *testing.T) {
+	tests := []struct {
+		spec string
+		want Executor
+	}{
+		{"", Local{}},
+		{"local", Local{}},
+		{"docker:app-web-1", DockerExec{Container: "app-web-1"}},
+		{"docker:app-web-1:/workspace", DockerExec{Container: "app-web-1", Workdir: "/workspace"}},
+		{"ssh:devbox", SSH{Host: "devbox"}},
+		{"ssh:me@devbox.internal:/home/me/app", SSH{Host: "me@devbox.internal", Workdir: "/home/me/app"}},
+	}
+	for _, tt := range tests {
+		executor, err := ParseExecutor(tt.spec)
+		require.NoError(t, err, tt.spec)
+		assert.Equal(t, tt.want, executor)
+		if tt.spec != "" {
+			assert.Equal(t, tt.spec, executor.String(), "specs round-trip")
+		}
+	}
+
+	for _, spec := range []string{"docker", "ssh:", "ssh:-oProxyCommand=x", "local:box", "kubectl:pod"} {
+		_, err := ParseExecutor(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestExecutor_Command(t *testing.T) {
+	ctx := context.Background()
+
+	cmd := DockerExec{Container: "app"}.Command(ctx, "/src/app", "mvn -q compile")
+	assert.Equal(t, []string{"docker", "exec", "--workdir", "/src/app", "app", "sh", "-c", "mvn -q compile"}, cmd.Args)
+	assert.Empty(t, cmd.Dir, "docker runs in the server's directory")
+
+	cmd = DockerExec{Container: "app", Workdir: "/workspace"}.Command(ctx, "/home/me/app", "ls")
+	assert.Equal(t, []string{"docker", "exec", "--workdir", "/workspace", "app", "sh", "-c", "ls"}, cmd.Args)
+
+	cmd = DockerExec{Container: "app", Workdir: "/workspace"}.Command(ctx, "", "java -version")
+	assert.Equal(t, []string{"docker", "exec", "app", "sh", "-c", "java -version"}, cmd.Args, "probes without a directory run in the container's default")
+
+	cmd = SSH{Host: "devbox"}.Command(ctx, "/home/me/my app", "echo 'hi'")
+	assert.Equal(t, []string{"ssh", "-o", "BatchMode=yes", "--", "devbox", `cd '/home/me/my app' && sh -c 'echo '\''hi'\'''`}, cmd.Args)
+
+	remote := SSH{Host: "devbox", Workdir: "/srv/app", LocalRoot: "/state/projects/app/remote"}
+	cmd = remote.Command(ctx, "/state/projects/app/remote/api", "mvn -q compile")
+	assert.Equal(t, "cd '/srv/app/api' && sh -c 'mvn -q compile'", cmd.Args[len(cmd.Args)-1], "subdirectories of a mounted project map to the host")
+	cmd = remote.Command(ctx, "/elsewhere", "ls")
+	assert.Equal(t, "cd '/srv/app' && sh -c 'ls'", cmd.Args[len(cmd.Args)-1])
+
+	cmd = Local{}.Command(ctx, "/tmp", "true")
+	assert.Equal(t, []string{"sh", "-c", "true"}, cmd.Args)
+	assert.Equal(t, "/tmp", cmd.Dir)
+}
+
+// wrapped runs commands locally under another name, to test what runs through an executor
+type wrapped struct{ commands *[]string }
+
+func (w wrapped) Command(ctx context.Context, dir, command string) *exec.Cmd {
+	*w.commands = append(*w.commands, command)
+	return Local{}.Command(ctx, dir, command)
+}
+
+func (w wrapped) String() string { return "test:box" }
+
+func TestRun_Executor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	assert.Equal(t, Local{}, ExecutorFrom(context.Background()))
+	assert.True(t, IsLocal(ExecutorFrom(context.Background())))
+
+	var commands []string
+	var records []Record
+	ctx := WithExecutor(context.Background(), wrapped{commands: &commands})
+	ctx = WithLogKey(WithRecorder(ctx, recorderFunc(func(r Record) { records = append(records, r) })), "job-1")
+
+	output, err := Run(ctx, t.TempDir(), "echo remote")
+	require.NoError(t, err)
+	assert.Equal(t, "remote\n", string(output))
+	assert.Equal(t, []string{"echo remote"}, commands)
+	require.Len(t, records, 1)
+	assert.Equal(t, "test:box", records[0].Executor)
+
+	// Argv commands are quoted for the target's shell
+	output, err = RunArgs(ctx, "", []string{"echo", "it's remote"})
+	require.NoError(t, err)
+	assert.Equal(t, "it's remote\n", string(output))
+	assert.Equal(t, `'echo' 'it'\''s remote'`, commands[1])
+	assert.Equal(t, commands[1], records[1].Command)
+
+	_, err = Run(WithExecutor(ctx, Local{}), "", "true")
+	require.NoError(t, err)
+	assert.Empty(t, records[2].Executor, "local commands name no executor")
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'plain'`, ShellQuote("plain"))
+	assert.Equal(t, `'it'\''s'`, ShellQuote("it's"))
+}
@@ -0,0 +1,139 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Record is an executed command with its full output
+type Record struct {
+	Keys     []string // Log keys the command ran under, e.g. a job ID or fix fingerprint
+	Executor string   // Where the command ran, e.g. ssh:devbox; empty for this machine
+	Command  string
+	Dir      string
+	Mutating bool
+	Started  time.Time
+	Duration time.Duration
+	ExitCode int // -1 when the command could not be started or was killed
+	Error    string
+	Stdout   []byte
+	Stderr   []byte
+}
+
+// Recorder receives the commands run with a context carrying it
+type Recorder interface {
+	Record(record Record)
+}
+
+type recorderKey struct{}
+type logKeysKey struct{}
+
+// WithRecorder returns a context whose commands are reported to recorder
+func WithRecorder(ctx context.Context, recorder Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, recorder)
+}
+
+// WithLogKey returns a context whose commands are also recorded under key
+func WithLogKey(ctx context.Context, key string) context.Context {
+	if key == "" {
+		return ctx
+	}
+	keys, _ := ctx.Value(logKeysKey{}).([]string)
+	return context.WithValue(ctx, logKeysKey{}, append(append([]string(nil), keys...), key))
+}
+
+// run executes a shell command with the context's executor, returning its combined output or, with stdoutOnly, its
+// stdout. Stdout and stderr are captured separately for the context's recorder, if any.
+func run(ctx context.Context, dir, command string, mutating, stdoutOnly bool) ([]byte, error) {
+	executor := ExecutorFrom(ctx)
+	return execute(ctx, executor, executor.Command(ctx, dir, command), dir, command, mutating, stdoutOnly)
+}
+
+// execute runs a command built for executor, recording it as command
+func execute(ctx context.Context, executor Executor, cmd *exec.Cmd, dir, command string, mutating, stdoutOnly bool) ([]byte, error) {
+	recorder, _ := ctx.Value(recorderKey{}).(Recorder)
+	keys, _ := ctx.Value(logKeysKey{}).([]string)
+	if recorder == nil || len(keys) == 0 {
+		if stdoutOnly {
+			return cmd.Output()
+		}
+		return cmd.CombinedOutput()
+	}
+
+	var stdout, stderr bytes.Buffer
+	combined := &syncBuffer{}
+	if stdoutOnly {
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+	} else {
+		cmd.Stdout = &teeWriter{combined: combined, own: &stdout}
+		cmd.Stderr = &teeWriter{combined: combined, own: &stderr}
+	}
+
+	started := time.Now()
+	err := cmd.Run()
+	record := Record{
+		Keys:     keys,
+		Command:  command,
+		Dir:      dir,
+		Mutating: mutating,
+		Started:  started,
+		Duration: time.Since(started),
+		ExitCode: -1,
+		Stdout:   stdout.Bytes(),
+		Stderr:   stderr.Bytes(),
+	}
+	if !IsLocal(executor) {
+		record.Executor = executor.String()
+	}
+	if cmd.ProcessState != nil {
+		record.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	recorder.Record(record)
+
+	if stdoutOnly {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// Match cmd.Output, which attaches stderr to the error
+			exitErr.Stderr = stderr.Bytes()
+		}
+		return stdout.Bytes(), err
+	}
+	return combined.Bytes(), err
+}
+
+// syncBuffer is a buffer safe for the concurrent writes of a command's stdout and stderr
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Bytes()
+}
+
+// teeWriter writes one output stream to the combined output and to the stream's own buffer
+type teeWriter struct {
+	combined *syncBuffer
+	own      *bytes.Buffer
+}
+
+func (w *teeWriter) Write(p []byte) (int, error) {
+	w.combined.Write(p)
+	return w.own.Write(p)
+}
@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorderFunc func(Record)
+
+func (f recorderFunc) Record(record Record) { f(record) }
+
+func TestRun_Recorder(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	var records []Record
+	ctx := WithRecorder(context.Background(), recorderFunc(func(r Record) { records = append(records, r) }))
+
+	// Without a log key nothing is recorded
+	_, err := Run(ctx, "", "echo quiet")
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	ctx = WithLogKey(WithLogKey(ctx, "job-1"), "abc123")
+	output, err := Run(ctx, "", "echo out; echo err >&2; exit 3")
+	require.Error(t, err)
+	assert.Contains(t, string(output), "out\n", "combined output is still returned")
+	assert.Contains(t, string(output), "err\n")
+
+	require.Len(t, records, 1)
+	record := records[0]
+	assert.Equal(t, []string{"job-1", "abc123"}, record.Keys)
+	assert.Equal(t, "out\n", string(record.Stdout))
+	assert.Equal(t, "err\n", string(record.Stderr))
+	assert.Equal(t, 3, record.ExitCode)
+	assert.False(t, record.Mutating)
+
+	stdout, err := Output(ctx, "", "echo version 1.2; echo warning >&2; exit 1")
+	assert.Equal(t, "version 1.2\n", string(stdout))
+	var exitErr *exec.ExitError
+	require.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, "warning\n", string(exitErr.Stderr))
+
+	t.Setenv("SENTINEL_READ_ONLY", "")
+	_, err = RunMutating(ctx, t.TempDir(), "echo fixed")
+	require.NoError(t, err)
+	assert.True(t, records[len(records)-1].Mutating)
+	assert.True(t, strings.HasSuffix(records[len(records)-1].Command, "echo fixed"))
+}
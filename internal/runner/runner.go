@@ -0,0 +1,107 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ErrReadOnly is returned when a state-changing command is refused in read-only mode.
+// It carries the command that would have run so callers can report a plan instead.
+type ErrReadOnly struct {
+	Command string
+	Dir     string
+}
+
+func (e *ErrReadOnly) Error() string {
+	return fmt.Sprintf("read-only mode: would have run: %s", e.Command)
+}
+
+// IsReadOnly reports whether err is an ErrReadOnly
+func IsReadOnly(err error) bool {
+	var readOnly *ErrReadOnly
+	return errors.As(err, &readOnly)
+}
+
+// ReadOnly reports whether SENTINEL_READ_ONLY is enabled. When it is, state-changing
+// commands (fixes, docker actions, installs) are never executed, regardless of license.
+func ReadOnly() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("SENTINEL_READ_ONLY"))) {
+	case "1", "true", "yes", "on":
+		return true
+	default:
+		return false
+	}
+}
+
+// Run executes a read-only shell command (a check or probe) in dir and returns its combined output
+func Run(ctx context.Context, dir, command string) ([]byte, error) {
+	return run(ctx, dir, command, false, false)
+}
+
+// Output executes a read-only shell command in dir and returns its stdout. Like exec's
+// Output, stderr is attached to a returned *exec.ExitError.
+func Output(ctx context.Context, dir, command string) ([]byte, error) {
+	return run(ctx, dir, command, false, true)
+}
+
+// RunMutating executes a state-changing shell command in dir and returns its combined output.
+// In read-only mode the command is not run and an *ErrReadOnly is returned.
+func RunMutating(ctx context.Context, dir, command string) ([]byte, error) {
+	if ReadOnly() {
+		return nil, &ErrReadOnly{Command: command, Dir: dir}
+	}
+	return run(ctx, dir, command, true, false)
+}
+
+// ErrNotFound is returned when the program of an argv command isn't on the PATH of this machine
+type ErrNotFound struct {
+	Program string
+	Path    []string // Directories of the PATH that were searched
+}
+
+func (e *ErrNotFound) Error() string {
+	if len(e.Path) == 0 {
+		return fmt.Sprintf("%s not found: PATH is empty", e.Program)
+	}
+	return fmt.Sprintf("%s not found on PATH (searched %s)", e.Program, strings.Join(e.Path, string(os.PathListSeparator)))
+}
+
+// RunArgs executes a read-only command given as argv in dir and returns its combined output.
+// On this machine the program runs directly, without a shell, so arguments need no quoting and
+// it works where sh doesn't; a program missing from the PATH returns an *ErrNotFound. Other
+// executors run the arguments quoted for sh.
+func RunArgs(ctx context.Context, dir string, argv []string) ([]byte, error) {
+	if len(argv) == 0 {
+		return nil, errors.New("empty command")
+	}
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		quoted[i] = ShellQuote(arg)
+	}
+	command := strings.Join(quoted, " ")
+
+	executor := ExecutorFrom(ctx)
+	if !IsLocal(executor) {
+		return execute(ctx, executor, executor.Command(ctx, dir, command), dir, command, false, false)
+	}
+	program := argv[0]
+	if !strings.ContainsRune(program, '/') && !strings.ContainsRune(program, filepath.Separator) {
+		// Programs given as a path, such as ./mvnw, run relative to dir without a lookup
+		path, err := exec.LookPath(program)
+		if err != nil {
+			if errors.Is(err, exec.ErrNotFound) {
+				return nil, &ErrNotFound{Program: program, Path: filepath.SplitList(os.Getenv("PATH"))}
+			}
+			return nil, err
+		}
+		program = path
+	}
+	cmd := exec.CommandContext(ctx, program, argv[1:]...)
+	cmd.Dir = dir
+	return execute(ctx, Local{}, cmd, dir, command, false, false)
+}
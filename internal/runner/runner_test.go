@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnly(t *testing.T) {
+	for _, value := range []string{"1", "true", "TRUE", "yes", "on"} {
+		t.Setenv("SENTINEL_READ_ONLY", value)
+		assert.True(t, ReadOnly(), value)
+	}
+	for _, value := range []string{"", "0", "false", "no"} {
+		t.Setenv("SENTINEL_READ_ONLY", value)
+		assert.False(t, ReadOnly(), value)
+	}
+}
+
+func TestRunMutating(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "marker")
+	command := "touch marker && echo done"
+
+	t.Setenv("SENTINEL_READ_ONLY", "true")
+	_, err := RunMutating(context.Background(), dir, command)
+	require.Error(t, err)
+	assert.True(t, IsReadOnly(err))
+	assert.Equal(t, "read-only mode: would have run: touch marker && echo done", err.Error())
+	assert.NoFileExists(t, marker)
+
+	// Read-only commands still run
+	output, err := Run(context.Background(), dir, "echo probe")
+	require.NoError(t, err)
+	assert.Equal(t, "probe", strings.TrimSpace(string(output)))
+
+	t.Setenv("SENTINEL_READ_ONLY", "")
+	output, err = RunMutating(context.Background(), dir, command)
+	require.NoError(t, err)
+	assert.Equal(t, "done", strings.TrimSpace(string(output)))
+	_, err = os.Stat(marker)
+	assert.NoError(t, err)
+}
+
+func TestRunArgs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires printf")
+	}
+
+	// Arguments reach the program as given, without a shell to expand or split them
+	output, err := RunArgs(context.Background(), t.TempDir(), []string{"printf", "%s|", "two words", "$HOME", "it's"})
+	require.NoError(t, err)
+	assert.Equal(t, "two words|$HOME|it's|", string(output))
+
+	_, err = RunArgs(context.Background(), "", []string{"sentinel-no-such-program", "--version"})
+	var notFound *ErrNotFound
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, "sentinel-no-such-program", notFound.Program)
+	assert.Contains(t, err.Error(), "sentinel-no-such-program not found on PATH (searched ")
+
+	t.Setenv("PATH", "")
+	_, err = RunArgs(context.Background(), "", []string{"printf", "x"})
+	assert.EqualError(t, err, "printf not found: PATH is empty")
+
+	_, err = RunArgs(context.Background(), "", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_Invalid(t *testing.T) {
+	specs := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 7",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every soon",
+		"@every 10ms",
+		"0 0 31 2 *",
+		"0 0 31 2,4,6 *",
+	}
+
+	for _, spec := range specs {
+		t.Run(spec, func(t *testing.T) {
+			_, err := Parse(spec)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	base := time.Date(2026, time.March, 10, 14, 7, 30, 0, time.UTC) // Tuesday
+
+	tests := []struct {
+		name     string
+		spec     string
+		expected time.Time
+	}{
+		{"every minute", "* * * * *", time.Date(2026, time.March, 10, 14, 8, 0, 0, time.UTC)},
+		{"every 15 minutes", "*/15 * * * *", time.Date(2026, time.March, 10, 14, 15, 0, 0, time.UTC)},
+		{"hourly descriptor", "@hourly", time.Date(2026, time.March, 10, 15, 0, 0, 0, time.UTC)},
+		{"daily descriptor", "@daily", time.Date(2026, time.March, 11, 0, 0, 0, 0, time.UTC)},
+		{"working hours on weekdays", "30 9-17 * * 1-5", time.Date(2026, time.March, 10, 14, 30, 0, 0, time.UTC)},
+		{"weekend only", "0 8 * * 0,6", time.Date(2026, time.March, 14, 8, 0, 0, 0, time.UTC)},
+		{"first of month", "@monthly", time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"specific date", "0 12 25 12 *", time.Date(2026, time.December, 25, 12, 0, 0, 0, time.UTC)},
+		{"dom or dow when both set", "0 0 15 * 5", time.Date(2026, time.March, 13, 0, 0, 0, 0, time.UTC)},
+		{"leap day", "0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, schedule.Next(base))
+		})
+	}
+}
+
+func TestCronSchedule_NextHalfHourOffset(t *testing.T) {
+	kolkata := time.FixedZone("IST", 5*3600+30*60)
+	base := time.Date(2026, time.March, 10, 9, 7, 0, 0, kolkata)
+
+	schedule, err := Parse("0 12 * * *")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, time.March, 10, 12, 0, 0, 0, kolkata), schedule.Next(base))
+
+	schedule, err = Parse("15 */2 * * *")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, time.March, 10, 10, 15, 0, 0, kolkata), schedule.Next(base))
+}
+
+func TestEverySchedule_Next(t *testing.T) {
+	schedule, err := Parse("@every 5m")
+	require.NoError(t, err)
+
+	base := time.Date(2026, time.March, 10, 14, 7, 30, 0, time.UTC)
+	assert.Equal(t, base.Add(5*time.Minute), schedule.Next(base))
+}
@@ -0,0 +1,183 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// JobFunc is the work performed when a job fires
+type JobFunc func(ctx context.Context)
+
+// job is a registered scheduled job
+type job struct {
+	name     string
+	schedule Schedule
+	run      JobFunc
+	next     time.Time
+	running  bool
+}
+
+// Scheduler runs jobs according to their schedules in a background goroutine
+type Scheduler struct {
+	mu   sync.Mutex
+	jobs []*job
+	now  func() time.Time
+	logf func(format string, args ...interface{})
+	wg   sync.WaitGroup
+}
+
+// New creates an empty scheduler
+func New() *Scheduler {
+	return &Scheduler{
+		now: time.Now,
+		logf: func(format string, args ...interface{}) {
+			fmt.Fprintf(os.Stderr, format, args...)
+		},
+	}
+}
+
+// Add registers a job with a cron expression
+func (s *Scheduler) Add(name, spec string, run JobFunc) error {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		if j.name == name {
+			return fmt.Errorf("job %s already registered", name)
+		}
+	}
+	s.jobs = append(s.jobs, &job{name: name, schedule: schedule, run: run})
+	return nil
+}
+
+// Jobs returns the registered job names with their next activation time
+func (s *Scheduler) Jobs() map[string]time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make(map[string]time.Time, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs[j.name] = j.next
+	}
+	return jobs
+}
+
+// RunNow runs every job once immediately and waits for them to finish (used to warm up results at startup)
+func (s *Scheduler) RunNow(ctx context.Context) {
+	s.mu.Lock()
+	jobs := append([]*job(nil), s.jobs...)
+	s.mu.Unlock()
+
+	for _, j := range jobs {
+		s.dispatch(ctx, j)
+	}
+	s.wg.Wait()
+}
+
+// Start runs the scheduling loop until the context is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	now := s.now()
+	for _, j := range s.jobs {
+		s.advance(j, now)
+	}
+	s.mu.Unlock()
+
+	go s.loop(ctx)
+}
+
+// advance sets the job's next activation after now, warning when its schedule has none left
+// (caller holds the lock)
+func (s *Scheduler) advance(j *job, now time.Time) {
+	j.next = j.schedule.Next(now)
+	if j.next.IsZero() {
+		s.logf("warning: scheduled job %s has no activation after %s and will not run again\n", j.name, now.Format(time.RFC3339))
+	}
+}
+
+// Wait blocks until all in-flight job runs have finished
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// loop sleeps until the earliest job is due, then dispatches every due job
+func (s *Scheduler) loop(ctx context.Context) {
+	for {
+		s.mu.Lock()
+		next := s.earliest()
+		s.mu.Unlock()
+
+		if next.IsZero() {
+			<-ctx.Done()
+			return
+		}
+
+		timer := time.NewTimer(next.Sub(s.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.mu.Lock()
+		now := s.now()
+		var due []*job
+		for _, j := range s.jobs {
+			if !j.next.IsZero() && !j.next.After(now) {
+				due = append(due, j)
+				s.advance(j, now)
+			}
+		}
+		s.mu.Unlock()
+
+		for _, j := range due {
+			s.dispatch(ctx, j)
+		}
+	}
+}
+
+// earliest returns the soonest next activation across jobs (caller holds the lock)
+func (s *Scheduler) earliest() time.Time {
+	var times []time.Time
+	for _, j := range s.jobs {
+		if !j.next.IsZero() {
+			times = append(times, j.next)
+		}
+	}
+	if len(times) == 0 {
+		return time.Time{}
+	}
+	sort.Slice(times, func(a, b int) bool { return times[a].Before(times[b]) })
+	return times[0]
+}
+
+// dispatch runs a job in its own goroutine, skipping it if the previous run is still in flight
+func (s *Scheduler) dispatch(ctx context.Context, j *job) {
+	s.mu.Lock()
+	if j.running {
+		s.mu.Unlock()
+		return
+	}
+	j.running = true
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.mu.Lock()
+			j.running = false
+			s.mu.Unlock()
+		}()
+		j.run(ctx)
+	}()
+}
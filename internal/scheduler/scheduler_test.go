@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler_Add(t *testing.T) {
+	s := New()
+
+	require.NoError(t, s.Add("quick", "@every 1m", func(ctx context.Context) {}))
+	assert.Error(t, s.Add("quick", "@every 1m", func(ctx context.Context) {}), "duplicate names are rejected")
+	assert.Error(t, s.Add("broken", "not a cron", func(ctx context.Context) {}))
+	assert.Len(t, s.Jobs(), 1)
+}
+
+func TestScheduler_RunNow(t *testing.T) {
+	s := New()
+	var runs int32
+
+	require.NoError(t, s.Add("a", "@hourly", func(ctx context.Context) { atomic.AddInt32(&runs, 1) }))
+	require.NoError(t, s.Add("b", "@daily", func(ctx context.Context) { atomic.AddInt32(&runs, 1) }))
+
+	s.RunNow(context.Background())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&runs))
+}
+
+func TestScheduler_Start(t *testing.T) {
+	s := New()
+	var runs int32
+
+	// Use a clock that advances one second per reading so jobs become due without real waiting
+	start := time.Now()
+	var ticks int64
+	s.now = func() time.Time { return start.Add(time.Duration(atomic.AddInt64(&ticks, 1)) * time.Second) }
+	require.NoError(t, s.Add("fast", "@every 1s", func(ctx context.Context) { atomic.AddInt32(&runs, 1) }))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.Start(ctx)
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) >= 2 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	s.Wait()
+}
+
+// neverSchedule has no activation left
+type neverSchedule struct{}
+
+func (neverSchedule) Next(after time.Time) time.Time { return time.Time{} }
+
+func TestScheduler_WarnsWithoutNextActivation(t *testing.T) {
+	s := New()
+	var warnings []string
+	s.logf = func(format string, args ...interface{}) { warnings = append(warnings, fmt.Sprintf(format, args...)) }
+	s.jobs = append(s.jobs, &job{name: "never", schedule: neverSchedule{}, run: func(ctx context.Context) {}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "scheduled job never has no activation")
+	assert.True(t, s.Jobs()["never"].IsZero())
+}
+
+func TestScheduler_SkipsOverlappingRuns(t *testing.T) {
+	s := New()
+	var runs int32
+	release := make(chan struct{})
+
+	require.NoError(t, s.Add("slow", "@hourly", func(ctx context.Context) {
+		atomic.AddInt32(&runs, 1)
+		<-release
+	}))
+
+	j := s.jobs[0]
+	s.dispatch(context.Background(), j)
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) == 1 }, time.Second, time.Millisecond)
+	s.dispatch(context.Background(), j) // Still running, should be skipped
+
+	close(release)
+	s.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+}
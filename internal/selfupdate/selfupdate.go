@@ -0,0 +1,290 @@
+// Package selfupdate replaces the running binary with the latest GitHub release, for installs
+// managed outside package managers
+package selfupdate
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/license"
+)
+
+// DefaultAPIURL is the GitHub API serving the releases; SENTINEL_UPDATE_URL overrides it,
+// e.g. for GitHub Enterprise or a mirror with the same API
+const DefaultAPIURL = "https://api.github.com"
+
+// DefaultRepo is the repository the releases are published to
+const DefaultRepo = "adrianmikula/DevEnvMCP"
+
+// ChecksumsAsset is the release asset listing the SHA-256 of each binary
+const ChecksumsAsset = "SHA256SUMS"
+
+// SignatureAsset holds the base64 Ed25519 signature of ChecksumsAsset, made by scripts/release.js
+const SignatureAsset = ChecksumsAsset + ".sig"
+
+// signatureDomain is signed with ChecksumsAsset, so no other signature by the same key, such as
+// a license key's, verifies as a release signature
+const signatureDomain = "sentinel-release-checksums"
+
+// Release is a published release
+type Release struct {
+	Version string            `json:"version"` // Without the leading v
+	URL     string            `json:"url"`
+	Assets  map[string]string `json:"-"` // Download URL by asset name
+}
+
+// Updater checks for and installs releases
+type Updater struct {
+	apiURL    string
+	repo      string
+	publicKey ed25519.PublicKey // Key the release checksums are signed with
+	client    *http.Client
+}
+
+// NewUpdater creates an updater for the sentinel's releases
+func NewUpdater() *Updater {
+	apiURL := os.Getenv("SENTINEL_UPDATE_URL")
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	// Releases are signed with the maintainers' key, the one license keys are signed with
+	publicKey, _ := license.ParsePublicKey(license.EmbeddedPublicKey)
+	return &Updater{
+		apiURL:    strings.TrimSuffix(apiURL, "/"),
+		repo:      DefaultRepo,
+		publicKey: publicKey,
+		client:    &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+// Latest returns the latest published release
+func (u *Updater) Latest(ctx context.Context) (*Release, error) {
+	body, err := u.get(ctx, fmt.Sprintf("%s/repos/%s/releases/latest", u.apiURL, u.repo))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for updates: %w", err)
+	}
+
+	var resp struct {
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+		Assets  []struct {
+			Name string `json:"name"`
+			URL  string `json:"browser_download_url"`
+		} `json:"assets"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid release response: %w", err)
+	}
+	if resp.TagName == "" {
+		return nil, fmt.Errorf("invalid release response: no tag name")
+	}
+
+	release := &Release{
+		Version: strings.TrimPrefix(resp.TagName, "v"),
+		URL:     resp.HTMLURL,
+		Assets:  make(map[string]string, len(resp.Assets)),
+	}
+	for _, asset := range resp.Assets {
+		release.Assets[asset.Name] = asset.URL
+	}
+	return release, nil
+}
+
+// Apply downloads the release binary for this platform, verifies it against the release's
+// checksums and replaces the binary at exePath with it. The checksums are only trusted when
+// they're signed with the maintainers' key, so a tampered release or mirror can't replace them.
+func (u *Updater) Apply(ctx context.Context, release *Release, exePath string) error {
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+	binaryURL, ok := release.Assets[name]
+	if !ok {
+		return fmt.Errorf("release %s has no binary for %s/%s (%s)", release.Version, runtime.GOOS, runtime.GOARCH, name)
+	}
+	sumsURL, ok := release.Assets[ChecksumsAsset]
+	if !ok {
+		return fmt.Errorf("release %s has no %s to verify the binary", release.Version, ChecksumsAsset)
+	}
+
+	signatureURL, ok := release.Assets[SignatureAsset]
+	if !ok {
+		return fmt.Errorf("release %s has no %s to verify its checksums", release.Version, SignatureAsset)
+	}
+
+	sums, err := u.get(ctx, sumsURL)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", ChecksumsAsset, err)
+	}
+	signature, err := u.get(ctx, signatureURL)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", SignatureAsset, err)
+	}
+	if !license.Verify(u.publicKey, signatureDomain, sums, string(signature)) {
+		return fmt.Errorf("%s of release %s is not signed with the release key", ChecksumsAsset, release.Version)
+	}
+	expected, err := checksumOf(sums, name)
+	if err != nil {
+		return err
+	}
+	binary, err := u.get(ctx, binaryURL)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	sum := sha256.Sum256(binary)
+	if actual := hex.EncodeToString(sum[:]); actual != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", name, expected, actual)
+	}
+
+	return Replace(exePath, binary)
+}
+
+// AssetName returns the name of the release binary for a platform, as built by scripts/build.js
+func AssetName(goos, goarch string) string {
+	name := "sentinel-" + goos
+	if goarch != "amd64" {
+		name += "-" + goarch
+	}
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Replace atomically replaces the binary at exePath. The new binary is written next to it and
+// renamed over it; on Windows, where a running executable can't be overwritten, the old binary
+// is moved aside to exePath.old first.
+func Replace(exePath string, binary []byte) error {
+	info, err := os.Stat(exePath)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", exePath, err)
+	}
+
+	dir := filepath.Dir(exePath)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(exePath)+".new-*")
+	if err != nil {
+		return fmt.Errorf("failed to write to %s: %w", dir, err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write new binary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write new binary: %w", err)
+	}
+	if err := os.Chmod(tmpPath, info.Mode().Perm()|0111); err != nil {
+		return fmt.Errorf("failed to make new binary executable: %w", err)
+	}
+
+	if runtime.GOOS == "windows" {
+		oldPath := exePath + ".old"
+		os.Remove(oldPath)
+		if err := os.Rename(exePath, oldPath); err != nil {
+			return fmt.Errorf("failed to move %s aside: %w", exePath, err)
+		}
+		if err := os.Rename(tmpPath, exePath); err != nil {
+			// Put the old binary back so the install keeps working
+			os.Rename(oldPath, exePath)
+			return fmt.Errorf("failed to replace %s: %w", exePath, err)
+		}
+		return nil
+	}
+	if err := os.Rename(tmpPath, exePath); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", exePath, err)
+	}
+	return nil
+}
+
+// Newer reports whether version is newer than current. Versions are compared as
+// dot-separated numbers; pre-release suffixes (1.2.0-beta) sort before the release.
+func Newer(version, current string) bool {
+	return compare(version, current) > 0
+}
+
+func compare(a, b string) int {
+	a, b = strings.TrimPrefix(a, "v"), strings.TrimPrefix(b, "v")
+	aCore, aPre, _ := strings.Cut(a, "-")
+	bCore, bPre, _ := strings.Cut(b, "-")
+
+	aParts, bParts := strings.Split(aCore, "."), strings.Split(bCore, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var x, y int
+		if i < len(aParts) {
+			x, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			y, _ = strconv.Atoi(bParts[i])
+		}
+		if x != y {
+			if x > y {
+				return 1
+			}
+			return -1
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return strings.Compare(aPre, bPre)
+}
+
+// checksumOf finds the SHA-256 of a file in sha256sum output
+func checksumOf(sums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s is not listed in %s", name, ChecksumsAsset)
+}
+
+func (u *Updater) get(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "dev-env-sentinel")
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" && sameOrigin(req.URL, u.apiURL) {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: HTTP %d", rawURL, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// sameOrigin reports whether a request goes to the scheme and host of the API, so the token
+// isn't sent to hosts that merely share its prefix, such as api.github.com.evil.example
+func sameOrigin(target *url.URL, apiURL string) bool {
+	api, err := url.Parse(apiURL)
+	return err == nil && api.Host != "" && strings.EqualFold(target.Scheme, api.Scheme) && strings.EqualFold(target.Host, api.Host)
+}
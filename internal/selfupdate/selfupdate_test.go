@@ -0,0 +1,147 @@
+package selfupdate
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"dev-env-sentinel/internal/license"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReleaseServer serves a latest release with a binary for this platform and its checksums,
+// signed with a new key. It returns an updater trusting that key.
+func newReleaseServer(t *testing.T, binary []byte, sums string) *Updater {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	signature := license.Sign(private, signatureDomain, []byte(sums))
+
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/repos/"+DefaultRepo+"/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"tag_name": "v1.2.0", "html_url": "https://example.com/v1.2.0", "assets": [
+			{"name": %q, "browser_download_url": "%s/download/%s"},
+			{"name": "SHA256SUMS", "browser_download_url": "%s/download/SHA256SUMS"},
+			{"name": "SHA256SUMS.sig", "browser_download_url": "%s/download/SHA256SUMS.sig"}]}`,
+			name, server.URL, name, server.URL, server.URL)
+	})
+	mux.HandleFunc("/download/"+name, func(w http.ResponseWriter, r *http.Request) { w.Write(binary) })
+	mux.HandleFunc("/download/SHA256SUMS", func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, sums) })
+	mux.HandleFunc("/download/SHA256SUMS.sig", func(w http.ResponseWriter, r *http.Request) { fmt.Fprintln(w, signature) })
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	t.Setenv("SENTINEL_UPDATE_URL", server.URL)
+
+	updater := NewUpdater()
+	updater.publicKey = public
+	return updater
+}
+
+func TestUpdater_Apply(t *testing.T) {
+	binary := []byte("new binary")
+	sum := sha256.Sum256(binary)
+	updater := newReleaseServer(t, binary, hex.EncodeToString(sum[:])+"  "+AssetName(runtime.GOOS, runtime.GOARCH)+"\n")
+
+	exePath := filepath.Join(t.TempDir(), "sentinel")
+	require.NoError(t, os.WriteFile(exePath, []byte("old binary"), 0755))
+
+	release, err := updater.Latest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.0", release.Version)
+
+	require.NoError(t, updater.Apply(context.Background(), release, exePath))
+	data, err := os.ReadFile(exePath)
+	require.NoError(t, err)
+	assert.Equal(t, binary, data)
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(exePath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	}
+}
+
+func TestUpdater_Apply_ChecksumMismatch(t *testing.T) {
+	updater := newReleaseServer(t, []byte("tampered"), "0000  "+AssetName(runtime.GOOS, runtime.GOARCH)+"\n")
+
+	exePath := filepath.Join(t.TempDir(), "sentinel")
+	require.NoError(t, os.WriteFile(exePath, []byte("old binary"), 0755))
+
+	release, err := updater.Latest(context.Background())
+	require.NoError(t, err)
+
+	err = updater.Apply(context.Background(), release, exePath)
+	assert.ErrorContains(t, err, "checksum mismatch")
+	data, _ := os.ReadFile(exePath)
+	assert.Equal(t, "old binary", string(data))
+}
+
+func TestUpdater_Apply_Unsigned(t *testing.T) {
+	binary := []byte("tampered")
+	sum := sha256.Sum256(binary)
+	updater := newReleaseServer(t, binary, hex.EncodeToString(sum[:])+"  "+AssetName(runtime.GOOS, runtime.GOARCH)+"\n")
+
+	exePath := filepath.Join(t.TempDir(), "sentinel")
+	require.NoError(t, os.WriteFile(exePath, []byte("old binary"), 0755))
+	release, err := updater.Latest(context.Background())
+	require.NoError(t, err)
+
+	// Checksums matching the binary but signed with another key, as by whoever replaced both
+	other, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	updater.publicKey = other
+	assert.ErrorContains(t, updater.Apply(context.Background(), release, exePath), "SHA256SUMS of release 1.2.0 is not signed with the release key")
+
+	// A release without a signature
+	delete(release.Assets, SignatureAsset)
+	assert.ErrorContains(t, updater.Apply(context.Background(), release, exePath), "has no SHA256SUMS.sig")
+
+	data, _ := os.ReadFile(exePath)
+	assert.Equal(t, "old binary", string(data))
+}
+
+func TestAssetName(t *testing.T) {
+	assert.Equal(t, "sentinel-linux", AssetName("linux", "amd64"))
+	assert.Equal(t, "sentinel-linux-arm64", AssetName("linux", "arm64"))
+	assert.Equal(t, "sentinel-windows.exe", AssetName("windows", "amd64"))
+	assert.Equal(t, "sentinel-windows-arm64.exe", AssetName("windows", "arm64"))
+}
+
+func TestNewer(t *testing.T) {
+	assert.True(t, Newer("1.2.0", "1.1.9"))
+	assert.True(t, Newer("v1.10.0", "1.9.0"))
+	assert.True(t, Newer("1.2.0", "1.2.0-beta"))
+	assert.False(t, Newer("1.2.0", "1.2.0"))
+	assert.False(t, Newer("1.2.0-beta", "1.2.0"))
+	assert.False(t, Newer("0.9.0", "1.0"))
+}
+
+func TestSameOrigin(t *testing.T) {
+	tests := []struct {
+		target string
+		same   bool
+	}{
+		{"https://api.github.com/repos/acme/app/releases/latest", true},
+		{"https://API.github.com/repos/acme/app", true},
+		{"https://api.github.com.evil.example/repos/acme/app", false},
+		{"https://api.github.com:8443/repos/acme/app", false},
+		{"http://api.github.com/repos/acme/app", false},
+		{"https://github.com/acme/app/releases/download/v1.0.0/sentinel-linux", false},
+	}
+	for _, tt := range tests {
+		target, err := url.Parse(tt.target)
+		require.NoError(t, err)
+		assert.Equal(t, tt.same, sameOrigin(target, DefaultAPIURL), tt.target)
+	}
+}
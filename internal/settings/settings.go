@@ -0,0 +1,453 @@
+package settings
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/i18n"
+	"dev-env-sentinel/internal/license"
+	"dev-env-sentinel/internal/reconciler"
+	"dev-env-sentinel/internal/redact"
+	"dev-env-sentinel/internal/resources"
+	"dev-env-sentinel/internal/runner"
+	"dev-env-sentinel/internal/state"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFileName is the server settings file looked up next to the config directory
+const DefaultFileName = "sentinel.yaml"
+
+// ServerSettings holds operator-level settings for the sentinel server itself
+// (as opposed to ecosystem configs, which describe languages and tools)
+type ServerSettings struct {
+	Schedule      []ScheduledCheck `yaml:"schedule"`
+	Notifications Notifications    `yaml:"notifications"`
+	Tools         Tools            `yaml:"tools"`
+	Execution     Execution        `yaml:"execution"`
+	Output        Output           `yaml:"output"`
+	Headroom      Headroom         `yaml:"headroom"`
+	FixPolicy     FixPolicy        `yaml:"fix_policy"`
+	Telemetry     Telemetry        `yaml:"telemetry"`
+	Features      map[string]bool  `yaml:"features"` // Feature flag overrides; can't enable paid features
+	Tickets       Tickets          `yaml:"tickets"`
+	Slack         Slack            `yaml:"slack"`
+	Webhooks      Webhooks         `yaml:"webhooks"`
+	Redaction     Redaction        `yaml:"redaction"`
+	Privacy       Privacy          `yaml:"privacy"`
+	Retention     Retention        `yaml:"retention"`
+}
+
+// Privacy sets what Sentinel keeps on disk. In strict mode check history, snapshots, command
+// logs and crash dumps keep no command output, environment variable values or paths outside
+// the project, and protocol traces are not recorded.
+type Privacy struct {
+	Mode string `yaml:"mode"` // "standard" (default) or "strict"
+}
+
+// Strict reports whether the strict privacy mode is on
+func (p Privacy) Strict() bool {
+	return p.Mode == "strict"
+}
+
+// Retention bounds how long and how much check history and logs are kept
+type Retention struct {
+	MaxAge       string `yaml:"max_age"`        // e.g. 720h (default: no age limit)
+	MaxHistoryMB int    `yaml:"max_history_mb"` // Check history size in MiB (default: 1 per history file, or 20000 results in the database)
+	MaxLogMB     int    `yaml:"max_log_mb"`     // Total size of command logs, traces and crash dumps in MiB (default: no limit)
+}
+
+// Policy returns the retention policy of the state directory
+func (r Retention) Policy() state.Retention {
+	policy := state.Retention{
+		MaxHistoryBytes: int64(r.MaxHistoryMB) << 20,
+		MaxLogBytes:     int64(r.MaxLogMB) << 20,
+	}
+	if age, err := time.ParseDuration(r.MaxAge); err == nil && age > 0 {
+		policy.MaxAge = age
+	}
+	return policy
+}
+
+// Redaction adds to the secrets masked in tool responses, check history, command logs and
+// traces. Known credential formats and random-looking tokens are masked regardless.
+type Redaction struct {
+	Patterns  []string `yaml:"patterns"`   // Regular expressions of further secrets, e.g. an internal token format
+	Entropy   float64  `yaml:"entropy"`    // Bits per character from which random-looking tokens are masked (default 4.2); negative turns it off
+	MinLength int      `yaml:"min_length"` // Shortest token the entropy check looks at (default 20)
+}
+
+// Options returns the redaction engine options of the settings
+func (r Redaction) Options() redact.Options {
+	return redact.Options{Patterns: r.Patterns, Entropy: r.Entropy, MinLength: r.MinLength}
+}
+
+// Webhooks configures the /webhook/push endpoint of the HTTP transport, which verifies a
+// registered project on every push GitHub or GitLab reports. It stays off without a secret.
+type Webhooks struct {
+	Secret   string   `yaml:"secret"`   // Secret set on the webhook; default: $SENTINEL_WEBHOOK_SECRET
+	Checks   []string `yaml:"checks"`   // Checks run per push (default: the checks of `sentinel check`)
+	Branches []string `yaml:"branches"` // Branches verified (default: all)
+	Notify   bool     `yaml:"notify"`   // Notify the sinks when a push changes a check's health
+}
+
+// Slack configures the /slack/command endpoint of the HTTP transport, which answers Slack
+// slash commands such as `/sentinel status api`. It stays off without a signing secret.
+type Slack struct {
+	SigningSecret string   `yaml:"signing_secret"` // Default: $SLACK_SIGNING_SECRET
+	Checks        []string `yaml:"checks"`         // Checks run by status (default: the checks of `sentinel check`)
+}
+
+// Tickets configures the trackers export_issue files environment issues in. Title and Body
+// are text/templates executed with a tickets.Issue; the defaults list the issue's details.
+type Tickets struct {
+	GitHub *GitHubTracker `yaml:"github"`
+	Jira   *JiraTracker   `yaml:"jira"`
+	Title  string         `yaml:"title"`
+	Body   string         `yaml:"body"`
+}
+
+// GitHubTracker files GitHub issues
+type GitHubTracker struct {
+	Repo   string   `yaml:"repo"`    // owner/name
+	APIURL string   `yaml:"api_url"` // GitHub Enterprise API URL (default https://api.github.com)
+	Token  string   `yaml:"token"`   // Default: $GITHUB_TOKEN
+	Labels []string `yaml:"labels"`
+}
+
+// JiraTracker files Jira issues
+type JiraTracker struct {
+	URL       string   `yaml:"url"`        // e.g. https://example.atlassian.net
+	Project   string   `yaml:"project"`    // Project key, e.g. DEV
+	IssueType string   `yaml:"issue_type"` // Default: Task
+	Email     string   `yaml:"email"`      // Default: $JIRA_EMAIL; without one the token is sent as a bearer token (Data Center)
+	Token     string   `yaml:"token"`      // Default: $JIRA_API_TOKEN
+	Labels    []string `yaml:"labels"`
+}
+
+// Telemetry sets where anonymous usage statistics are sent once the user opts in with
+// `sentinel telemetry enable`. Without an endpoint they stay queued locally.
+type Telemetry struct {
+	Endpoint string `yaml:"endpoint"` // HTTP(S) URL batches are POSTed to as JSON
+}
+
+// FixPolicy sets per issue severity whether reconcile_environment runs fixes on its own
+// ("auto", the default), only once a call confirms them ("confirm"), or never ("never")
+type FixPolicy struct {
+	Warning  string `yaml:"warning"`
+	Error    string `yaml:"error"`
+	Critical string `yaml:"critical"` // Issue types a fix config marks severity: critical
+	// A fix that failed LoopFailures times within LoopWindow is refused for manual action
+	// (defaults 3 and 30m); a negative LoopFailures never refuses fixes
+	LoopFailures int    `yaml:"loop_failures"`
+	LoopWindow   string `yaml:"loop_window"`
+}
+
+// Policy returns the fix policy
+func (f FixPolicy) Policy() reconciler.FixPolicy {
+	return reconciler.FixPolicy{Warning: f.Warning, Error: f.Error, Critical: f.Critical}
+}
+
+// LoopLimits returns when a fix that keeps failing is refused, with defaults for unset values
+func (f FixPolicy) LoopLimits() reconciler.LoopLimits {
+	limits := reconciler.DefaultLoopLimits
+	if f.LoopFailures != 0 {
+		limits.MaxFailures = f.LoopFailures
+	}
+	if window, err := time.ParseDuration(f.LoopWindow); err == nil && window > 0 {
+		limits.Window = window
+	}
+	return limits
+}
+
+// Headroom sets the free resources heavy fixes (full rebuilds, docker compose up) need
+type Headroom struct {
+	MinMemoryMB int     `yaml:"min_memory_mb"` // Free memory (default 1024)
+	MinDiskMB   int     `yaml:"min_disk_mb"`   // Free disk on the project's file system (default 2048)
+	MaxLoad     float64 `yaml:"max_load"`      // Highest 1-minute load average per CPU (default 1.0)
+	Mode        string  `yaml:"mode"`          // "warn" (default) runs the fix with a warning, "refuse" doesn't run it
+}
+
+// Policy returns the headroom policy, with defaults for unset values
+func (h Headroom) Policy() resources.Policy {
+	policy := resources.Default
+	if h.MinMemoryMB > 0 {
+		policy.MinMemoryMB = h.MinMemoryMB
+	}
+	if h.MinDiskMB > 0 {
+		policy.MinDiskMB = h.MinDiskMB
+	}
+	if h.MaxLoad > 0 {
+		policy.MaxLoad = h.MaxLoad
+	}
+	policy.Refuse = h.Mode == "refuse"
+	return policy
+}
+
+// Output controls how tool results are rendered for clients
+type Output struct {
+	Symbols   string `yaml:"symbols"`   // Status markers: "unicode" (default), "ascii" or "none"
+	Templates string `yaml:"templates"` // Directory of text/templates for tool output
+	Template  string `yaml:"template"`  // Template used unless a call sets template (default: built-in rendering)
+}
+
+// Execution bounds how many tool calls the HTTP transport runs at once, and sets where the
+// commands of checks and fixes run
+type Execution struct {
+	Workers   int    `yaml:"workers"`    // Concurrent tool calls (default 2)
+	QueueSize int    `yaml:"queue_size"` // Calls that may wait for a worker before requests are rejected (default 100)
+	Executor  string `yaml:"executor"`   // local (default), docker:<container>[:<workdir>] or ssh:<host>[:<workdir>]
+	// Longest a tool call may run, e.g. 10m (default: no limit). Detection, file scans and
+	// commands stop at the deadline and the call fails.
+	ToolTimeout string `yaml:"tool_timeout"`
+}
+
+// Timeout returns the longest a tool call may run, or 0 for no limit
+func (e Execution) Timeout() time.Duration {
+	if timeout, err := time.ParseDuration(e.ToolTimeout); err == nil && timeout > 0 {
+		return timeout
+	}
+	return 0
+}
+
+// CommandExecutor returns the executor of the execution settings; validated settings always
+// have a valid one
+func (e Execution) CommandExecutor() runner.Executor {
+	executor, err := runner.ParseExecutor(e.Executor)
+	if err != nil {
+		return runner.Local{}
+	}
+	return executor
+}
+
+// Tools controls which tools the server exposes
+type Tools struct {
+	Profile  string   `yaml:"profile"`  // "read-only", "ci" or "full" (default)
+	Disabled []string `yaml:"disabled"` // Tools hidden regardless of profile
+}
+
+// ScheduledCheck defines checks to re-run periodically in the background
+type ScheduledCheck struct {
+	Name        string   `yaml:"name"`
+	Cron        string   `yaml:"cron"` // 5-field cron expression or @every/@hourly/@daily
+	ProjectRoot string   `yaml:"project_root"`
+	Checks      []string `yaml:"checks"` // Tool names, e.g. verify_build_freshness
+}
+
+// Notifications configures where drift alerts are delivered
+type Notifications struct {
+	NotifyOnRecovery bool               `yaml:"notify_on_recovery"`
+	Sinks            []NotificationSink `yaml:"sinks"`
+}
+
+// NotificationSink defines a single notification destination
+type NotificationSink struct {
+	Type    string            `yaml:"type"` // "desktop", "webhook", "slack", "teams"
+	URL     string            `yaml:"url,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"` // Extra headers for webhook sinks
+}
+
+// Load reads server settings from a YAML file
+func Load(path string) (*ServerSettings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &common.ErrNotFound{Resource: "settings file", Path: path}
+	}
+
+	var s ServerSettings
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, &common.ErrInvalidConfig{Message: fmt.Sprintf("failed to parse settings YAML: %v", err)}
+	}
+
+	if err := validate(&s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// Discover finds the server settings file and loads it.
+// Lookup order: SENTINEL_SETTINGS, <baseDir>/sentinel.yaml, ~/.dev-env-sentinel/sentinel.yaml.
+// Returns empty settings when no file exists.
+func Discover(baseDir string) (*ServerSettings, error) {
+	if path := os.Getenv("SENTINEL_SETTINGS"); path != "" {
+		return Load(path)
+	}
+
+	candidates := []string{filepath.Join(baseDir, DefaultFileName)}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(homeDir, ".dev-env-sentinel", DefaultFileName))
+	}
+
+	for _, path := range candidates {
+		if common.FileExists(path) {
+			return Load(path)
+		}
+	}
+
+	return &ServerSettings{}, nil
+}
+
+// validate validates the settings structure
+func validate(s *ServerSettings) error {
+	names := make(map[string]bool)
+	for i, check := range s.Schedule {
+		field := fmt.Sprintf("schedule[%d]", i)
+		if check.Name == "" {
+			return &common.ErrInvalidConfig{Field: field + ".name", Message: "required"}
+		}
+		if names[check.Name] {
+			return &common.ErrInvalidConfig{Field: field + ".name", Message: fmt.Sprintf("duplicate name %q", check.Name)}
+		}
+		names[check.Name] = true
+		if check.Cron == "" {
+			return &common.ErrInvalidConfig{Field: field + ".cron", Message: "required"}
+		}
+		if check.ProjectRoot == "" {
+			return &common.ErrInvalidConfig{Field: field + ".project_root", Message: "required"}
+		}
+		if len(check.Checks) == 0 {
+			return &common.ErrInvalidConfig{Field: field + ".checks", Message: "at least one check required"}
+		}
+	}
+	if s.Execution.Workers < 0 {
+		return &common.ErrInvalidConfig{Field: "execution.workers", Message: "must not be negative"}
+	}
+	if s.Execution.QueueSize < 0 {
+		return &common.ErrInvalidConfig{Field: "execution.queue_size", Message: "must not be negative"}
+	}
+	if _, err := runner.ParseExecutor(s.Execution.Executor); err != nil {
+		return &common.ErrInvalidConfig{Field: "execution.executor", Message: err.Error()}
+	}
+	if s.Execution.ToolTimeout != "" {
+		if timeout, err := time.ParseDuration(s.Execution.ToolTimeout); err != nil || timeout <= 0 {
+			return &common.ErrInvalidConfig{Field: "execution.tool_timeout", Message: fmt.Sprintf("invalid duration %q", s.Execution.ToolTimeout)}
+		}
+	}
+	if s.Headroom.MinMemoryMB < 0 || s.Headroom.MinDiskMB < 0 || s.Headroom.MaxLoad < 0 {
+		return &common.ErrInvalidConfig{Field: "headroom", Message: "minimums must not be negative"}
+	}
+	switch s.Headroom.Mode {
+	case "", "warn", "refuse":
+	default:
+		return &common.ErrInvalidConfig{Field: "headroom.mode", Message: fmt.Sprintf("unknown mode %q (use warn or refuse)", s.Headroom.Mode)}
+	}
+	decisions := []struct{ field, value string }{
+		{"warning", s.FixPolicy.Warning},
+		{"error", s.FixPolicy.Error},
+		{"critical", s.FixPolicy.Critical},
+	}
+	for _, d := range decisions {
+		if _, err := reconciler.ParseFixDecision(d.value); err != nil {
+			return &common.ErrInvalidConfig{Field: "fix_policy." + d.field, Message: err.Error()}
+		}
+	}
+	if s.FixPolicy.LoopWindow != "" {
+		if window, err := time.ParseDuration(s.FixPolicy.LoopWindow); err != nil || window <= 0 {
+			return &common.ErrInvalidConfig{Field: "fix_policy.loop_window", Message: fmt.Sprintf("invalid duration %q", s.FixPolicy.LoopWindow)}
+		}
+	}
+	if s.Redaction.MinLength < 0 {
+		return &common.ErrInvalidConfig{Field: "redaction.min_length", Message: "must not be negative"}
+	}
+	if _, err := redact.New(s.Redaction.Options()); err != nil {
+		return &common.ErrInvalidConfig{Field: "redaction.patterns", Message: err.Error()}
+	}
+	switch s.Privacy.Mode {
+	case "", "standard", "strict":
+	default:
+		return &common.ErrInvalidConfig{Field: "privacy.mode", Message: fmt.Sprintf("unknown mode %q (use standard or strict)", s.Privacy.Mode)}
+	}
+	if s.Retention.MaxAge != "" {
+		if age, err := time.ParseDuration(s.Retention.MaxAge); err != nil || age <= 0 {
+			return &common.ErrInvalidConfig{Field: "retention.max_age", Message: fmt.Sprintf("invalid duration %q", s.Retention.MaxAge)}
+		}
+	}
+	if s.Retention.MaxHistoryMB < 0 {
+		return &common.ErrInvalidConfig{Field: "retention.max_history_mb", Message: "must not be negative"}
+	}
+	if s.Retention.MaxLogMB < 0 {
+		return &common.ErrInvalidConfig{Field: "retention.max_log_mb", Message: "must not be negative"}
+	}
+	if _, err := i18n.ParseSymbols(s.Output.Symbols); err != nil {
+		return &common.ErrInvalidConfig{Field: "output.symbols", Message: err.Error()}
+	}
+	if s.Output.Template != "" && s.Output.Templates == "" {
+		return &common.ErrInvalidConfig{Field: "output.template", Message: "requires output.templates"}
+	}
+	if s.Telemetry.Endpoint != "" {
+		if !isHTTPURL(s.Telemetry.Endpoint) {
+			return &common.ErrInvalidConfig{Field: "telemetry.endpoint", Message: fmt.Sprintf("invalid URL %q", s.Telemetry.Endpoint)}
+		}
+	}
+	flags := make([]string, 0, len(s.Features))
+	for name := range s.Features {
+		flags = append(flags, name)
+	}
+	sort.Strings(flags)
+	for _, name := range flags {
+		if !license.ValidFlagName(name) {
+			return &common.ErrInvalidConfig{Field: "features", Message: fmt.Sprintf("invalid flag name %q (use lowercase letters, digits and underscores)", name)}
+		}
+	}
+	if gh := s.Tickets.GitHub; gh != nil {
+		if owner, name, ok := strings.Cut(gh.Repo, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return &common.ErrInvalidConfig{Field: "tickets.github.repo", Message: fmt.Sprintf("expected owner/name, got %q", gh.Repo)}
+		}
+		if gh.APIURL != "" && !isHTTPURL(gh.APIURL) {
+			return &common.ErrInvalidConfig{Field: "tickets.github.api_url", Message: fmt.Sprintf("invalid URL %q", gh.APIURL)}
+		}
+	}
+	if jira := s.Tickets.Jira; jira != nil {
+		if !isHTTPURL(jira.URL) {
+			return &common.ErrInvalidConfig{Field: "tickets.jira.url", Message: fmt.Sprintf("invalid URL %q", jira.URL)}
+		}
+		if jira.Project == "" {
+			return &common.ErrInvalidConfig{Field: "tickets.jira.project", Message: "required"}
+		}
+	}
+	for i, check := range s.Slack.Checks {
+		if check == "" {
+			return &common.ErrInvalidConfig{Field: fmt.Sprintf("slack.checks[%d]", i), Message: "must not be empty"}
+		}
+	}
+	for i, check := range s.Webhooks.Checks {
+		if check == "" {
+			return &common.ErrInvalidConfig{Field: fmt.Sprintf("webhooks.checks[%d]", i), Message: "must not be empty"}
+		}
+	}
+	ticketTemplates := []struct{ field, text string }{
+		{"tickets.title", s.Tickets.Title},
+		{"tickets.body", s.Tickets.Body},
+	}
+	for _, t := range ticketTemplates {
+		if _, err := template.New(t.field).Parse(t.text); err != nil {
+			return &common.ErrInvalidConfig{Field: t.field, Message: err.Error()}
+		}
+	}
+	for i, sink := range s.Notifications.Sinks {
+		field := fmt.Sprintf("notifications.sinks[%d]", i)
+		switch sink.Type {
+		case "desktop":
+		case "webhook", "slack", "teams":
+			if sink.URL == "" {
+				return &common.ErrInvalidConfig{Field: field + ".url", Message: fmt.Sprintf("required for %s sinks", sink.Type)}
+			}
+		default:
+			return &common.ErrInvalidConfig{Field: field + ".type", Message: fmt.Sprintf("unknown sink type %q", sink.Type)}
+		}
+	}
+	return nil
+}
+
+// isHTTPURL reports whether s is an HTTP(S) URL with a host
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,213 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/redact"
+	"dev-env-sentinel/internal/resources"
+	"dev-env-sentinel/internal/runner"
+	"dev-env-sentinel/internal/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSettings(t *testing.T, dir, content string) string {
+	path := filepath.Join(dir, DefaultFileName)
+	err := os.WriteFile(path, []byte(content), 0644)
+	require.NoError(t, err)
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeSettings(t, t.TempDir(), `
+schedule:
+  - name: "api-quick"
+    cron: "*/15 * * * *"
+    project_root: "/work/api"
+    checks:
+      - verify_build_freshness
+      - env_var_audit
+`)
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, s.Schedule, 1)
+	assert.Equal(t, "api-quick", s.Schedule[0].Name)
+	assert.Equal(t, "*/15 * * * *", s.Schedule[0].Cron)
+	assert.Equal(t, []string{"verify_build_freshness", "env_var_audit"}, s.Schedule[0].Checks)
+}
+
+func TestLoad_Notifications(t *testing.T) {
+	path := writeSettings(t, t.TempDir(), `
+notifications:
+  notify_on_recovery: true
+  sinks:
+    - type: desktop
+    - type: webhook
+      url: "https://hooks.example.com/sentinel"
+      headers:
+        Authorization: "Bearer token"
+`)
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	assert.True(t, s.Notifications.NotifyOnRecovery)
+	require.Len(t, s.Notifications.Sinks, 2)
+	assert.Equal(t, "desktop", s.Notifications.Sinks[0].Type)
+	assert.Equal(t, "Bearer token", s.Notifications.Sinks[1].Headers["Authorization"])
+}
+
+func TestLoad_Tools(t *testing.T) {
+	path := writeSettings(t, t.TempDir(), `
+tools:
+  profile: read-only
+  disabled:
+    - check_locale
+`)
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "read-only", s.Tools.Profile)
+	assert.Equal(t, []string{"check_locale"}, s.Tools.Disabled)
+}
+
+func TestLoad_Execution(t *testing.T) {
+	path := writeSettings(t, t.TempDir(), "execution:\n  workers: 4\n  queue_size: 20\n  executor: ssh:devbox:/home/me/app\n  tool_timeout: 10m\n")
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, Execution{Workers: 4, QueueSize: 20, Executor: "ssh:devbox:/home/me/app", ToolTimeout: "10m"}, s.Execution)
+	assert.Equal(t, 10*time.Minute, s.Execution.Timeout())
+	assert.Zero(t, Execution{}.Timeout())
+	assert.Equal(t, runner.SSH{Host: "devbox", Workdir: "/home/me/app"}, s.Execution.CommandExecutor())
+	assert.Equal(t, runner.Local{}, Execution{}.CommandExecutor())
+}
+
+func TestLoad_Output(t *testing.T) {
+	path := writeSettings(t, t.TempDir(), "output:\n  symbols: ascii\n  templates: templates\n  template: acme\n")
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "ascii", s.Output.Symbols)
+	assert.Equal(t, "templates", s.Output.Templates)
+	assert.Equal(t, "acme", s.Output.Template)
+}
+
+func TestLoad_Headroom(t *testing.T) {
+	path := writeSettings(t, t.TempDir(), "headroom:\n  min_memory_mb: 4096\n  mode: refuse\n")
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	policy := s.Headroom.Policy()
+	assert.Equal(t, 4096, policy.MinMemoryMB)
+	assert.Equal(t, resources.Default.MinDiskMB, policy.MinDiskMB)
+	assert.Equal(t, resources.Default.MaxLoad, policy.MaxLoad)
+	assert.True(t, policy.Refuse)
+
+	assert.Equal(t, resources.Default, Headroom{}.Policy())
+}
+
+func TestLoad_Redaction(t *testing.T) {
+	path := writeSettings(t, t.TempDir(), "redaction:\n  patterns: ['acme_[a-z0-9]{12}']\n  entropy: -1\n")
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, redact.Options{Patterns: []string{"acme_[a-z0-9]{12}"}, Entropy: -1}, s.Redaction.Options())
+}
+
+func TestLoad_PrivacyAndRetention(t *testing.T) {
+	path := writeSettings(t, t.TempDir(), "privacy:\n  mode: strict\nretention:\n  max_age: 720h\n  max_history_mb: 5\n  max_log_mb: 50\n")
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	assert.True(t, s.Privacy.Strict())
+	assert.Equal(t, state.Retention{MaxAge: 720 * time.Hour, MaxHistoryBytes: 5 << 20, MaxLogBytes: 50 << 20}, s.Retention.Policy())
+
+	assert.False(t, Privacy{}.Strict())
+	assert.Equal(t, state.Retention{}, Retention{}.Policy())
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		field   string
+	}{
+		{"missing name", "schedule:\n  - cron: '@hourly'\n    project_root: /p\n    checks: [a]\n", "schedule[0].name"},
+		{"missing cron", "schedule:\n  - name: a\n    project_root: /p\n    checks: [a]\n", "schedule[0].cron"},
+		{"missing project root", "schedule:\n  - name: a\n    cron: '@hourly'\n    checks: [a]\n", "schedule[0].project_root"},
+		{"missing checks", "schedule:\n  - name: a\n    cron: '@hourly'\n    project_root: /p\n", "schedule[0].checks"},
+		{"unknown sink", "notifications:\n  sinks:\n    - type: pager\n", "notifications.sinks[0].type"},
+		{"webhook without url", "notifications:\n  sinks:\n    - type: slack\n", "notifications.sinks[0].url"},
+		{"negative workers", "execution:\n  workers: -1\n", "execution.workers"},
+		{"negative queue size", "execution:\n  queue_size: -5\n", "execution.queue_size"},
+		{"unknown executor", "execution:\n  executor: kubectl:pod\n", "execution.executor"},
+		{"invalid tool timeout", "execution:\n  tool_timeout: soon\n", "execution.tool_timeout"},
+		{"unknown symbols", "output:\n  symbols: emoji\n", "output.symbols"},
+		{"template without directory", "output:\n  template: acme\n", "output.template"},
+		{"unknown headroom mode", "headroom:\n  mode: block\n", "headroom.mode"},
+		{"unknown fix policy", "fix_policy:\n  error: ask\n", "fix_policy.error"},
+		{"invalid loop window", "fix_policy:\n  loop_window: soon\n", "fix_policy.loop_window"},
+		{"invalid feature flag", "features:\n  Beta-UI: true\n", "features"},
+		{"invalid telemetry endpoint", "telemetry:\n  endpoint: stats.example.com\n", "telemetry.endpoint"},
+		{"invalid ticket repo", "tickets:\n  github:\n    repo: app\n", "tickets.github.repo"},
+		{"jira without project", "tickets:\n  jira:\n    url: https://example.atlassian.net\n", "tickets.jira.project"},
+		{"invalid ticket template", "tickets:\n  title: \"{{.Summary\"\n", "tickets.title"},
+		{"empty slack check", "slack:\n  checks: [\"\"]\n", "slack.checks[0]"},
+		{"empty webhook check", "webhooks:\n  checks: [\"\"]\n", "webhooks.checks[0]"},
+		{"invalid redaction pattern", "redaction:\n  patterns: ['(']\n", "redaction.patterns"},
+		{"empty redaction pattern", "redaction:\n  patterns: ['x*']\n", "redaction.patterns"},
+		{"negative redaction length", "redaction:\n  min_length: -1\n", "redaction.min_length"},
+		{"unknown privacy mode", "privacy:\n  mode: paranoid\n", "privacy.mode"},
+		{"invalid retention age", "retention:\n  max_age: 30d\n", "retention.max_age"},
+		{"negative history size", "retention:\n  max_history_mb: -1\n", "retention.max_history_mb"},
+		{"negative log size", "retention:\n  max_log_mb: -1\n", "retention.max_log_mb"},
+		{"negative headroom", "headroom:\n  min_memory_mb: -1\n", "headroom"},
+		{"duplicate name", "schedule:\n  - {name: a, cron: '@hourly', project_root: /p, checks: [a]}\n  - {name: a, cron: '@daily', project_root: /p, checks: [a]}\n", "schedule[1].name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeSettings(t, t.TempDir(), tt.content)
+			_, err := Load(path)
+			require.Error(t, err)
+			var cfgErr *common.ErrInvalidConfig
+			require.ErrorAs(t, err, &cfgErr)
+			assert.Equal(t, tt.field, cfgErr.Field)
+		})
+	}
+}
+
+func TestLoad_NotFound(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	var notFound *common.ErrNotFound
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestDiscover(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SENTINEL_SETTINGS", "")
+
+	// No settings file anywhere returns empty settings
+	baseDir := t.TempDir()
+	s, err := Discover(baseDir)
+	require.NoError(t, err)
+	assert.Empty(t, s.Schedule)
+
+	// Settings next to the config directory are picked up
+	writeSettings(t, baseDir, "schedule:\n  - {name: a, cron: '@hourly', project_root: /p, checks: [env_var_audit]}\n")
+	s, err = Discover(baseDir)
+	require.NoError(t, err)
+	assert.Len(t, s.Schedule, 1)
+
+	// SENTINEL_SETTINGS takes precedence
+	explicit := writeSettings(t, t.TempDir(), "schedule: []\n")
+	t.Setenv("SENTINEL_SETTINGS", explicit)
+	s, err = Discover(baseDir)
+	require.NoError(t, err)
+	assert.Empty(t, s.Schedule)
+}
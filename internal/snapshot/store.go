@@ -0,0 +1,136 @@
+package snapshot
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"dev-env-sentinel/internal/machine"
+)
+
+// Entry is the latest result of one check for one scheduled job
+type Entry struct {
+	Job            string               `json:"job"`
+	ProjectRoot    string               `json:"project_root"`
+	Check          string               `json:"check"`
+	Healthy        bool                 `json:"healthy"`
+	Summary        string               `json:"summary"`
+	Error          string               `json:"error,omitempty"`
+	Revision       string               `json:"revision,omitempty"`         // Source revision the check ran against
+	MissingEnvVars []string             `json:"missing_env_vars,omitempty"` // Variables an env_var_audit found missing
+	Flaky          bool                 `json:"flaky,omitempty"`            // The check's result keeps flipping without source changes
+	Machine        *machine.Fingerprint `json:"machine,omitempty"`          // Platform the check ran on
+	Timestamp      time.Time            `json:"timestamp"`
+}
+
+// maxHistory bounds the number of entries kept in the run history
+const maxHistory = 500
+
+// Store keeps the latest result of every scheduled check, plus a bounded run history, in memory
+type Store struct {
+	mu      sync.RWMutex
+	latest  map[string]Entry
+	history []Entry
+}
+
+// NewStore creates an empty snapshot store
+func NewStore() *Store {
+	return &Store{
+		latest: make(map[string]Entry),
+	}
+}
+
+// Record stores an entry, replacing any previous result for the same job and check
+func (s *Store) Record(entry Entry) {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latest[entry.Job+"/"+entry.Check] = entry
+
+	s.history = append(s.history, entry)
+	if len(s.history) > maxHistory {
+		s.history = s.history[len(s.history)-maxHistory:]
+	}
+}
+
+// History returns up to limit recorded entries, newest first. A limit of zero or less returns all of them.
+func (s *Store) History(limit int) []Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if limit <= 0 || limit > len(s.history) {
+		limit = len(s.history)
+	}
+
+	entries := make([]Entry, 0, limit)
+	for i := len(s.history) - 1; i >= 0 && len(entries) < limit; i-- {
+		entries = append(entries, s.history[i])
+	}
+	return entries
+}
+
+// Clear removes all latest entries and history
+func (s *Store) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latest = make(map[string]Entry)
+	s.history = nil
+}
+
+// Get returns the latest entry for a job and check
+func (s *Store) Get(job, check string) (Entry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.latest[job+"/"+check]
+	return entry, ok
+}
+
+// Latest returns all latest entries ordered by job and check
+func (s *Store) Latest() []Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make([]Entry, 0, len(s.latest))
+	for _, entry := range s.latest {
+		entries = append(entries, entry)
+	}
+	sortEntries(entries)
+	return entries
+}
+
+// ForJob returns the latest entries of a single job ordered by check
+func (s *Store) ForJob(job string) []Entry {
+	var entries []Entry
+	for _, entry := range s.Latest() {
+		if entry.Job == job {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Jobs returns the names of all jobs that have recorded results
+func (s *Store) Jobs() []string {
+	seen := make(map[string]bool)
+	var jobs []string
+	for _, entry := range s.Latest() {
+		if !seen[entry.Job] {
+			seen[entry.Job] = true
+			jobs = append(jobs, entry.Job)
+		}
+	}
+	return jobs
+}
+
+// sortEntries orders entries by job then check
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Job != entries[j].Job {
+			return entries[i].Job < entries[j].Job
+		}
+		return entries[i].Check < entries[j].Check
+	})
+}
@@ -0,0 +1,77 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_RecordAndLatest(t *testing.T) {
+	store := NewStore()
+
+	store.Record(Entry{Job: "web", Check: "env_var_audit", Healthy: false, Summary: "missing API_KEY"})
+	store.Record(Entry{Job: "api", Check: "verify_build_freshness", Healthy: true})
+	store.Record(Entry{Job: "api", Check: "env_var_audit", Healthy: true})
+
+	latest := store.Latest()
+	require.Len(t, latest, 3)
+	assert.Equal(t, "api", latest[0].Job)
+	assert.Equal(t, "env_var_audit", latest[0].Check)
+	assert.Equal(t, "verify_build_freshness", latest[1].Check)
+	assert.Equal(t, "web", latest[2].Job)
+	assert.False(t, latest[0].Timestamp.IsZero(), "timestamp defaults to now")
+}
+
+func TestStore_RecordReplaces(t *testing.T) {
+	store := NewStore()
+	first := time.Now().Add(-time.Minute)
+
+	store.Record(Entry{Job: "api", Check: "env_var_audit", Healthy: false, Timestamp: first})
+	store.Record(Entry{Job: "api", Check: "env_var_audit", Healthy: true})
+
+	latest := store.Latest()
+	require.Len(t, latest, 1)
+	assert.True(t, latest[0].Healthy)
+	assert.True(t, latest[0].Timestamp.After(first))
+}
+
+func TestStore_ForJobAndJobs(t *testing.T) {
+	store := NewStore()
+	store.Record(Entry{Job: "web", Check: "a"})
+	store.Record(Entry{Job: "api", Check: "b"})
+	store.Record(Entry{Job: "api", Check: "a"})
+
+	assert.Equal(t, []string{"api", "web"}, store.Jobs())
+	entries := store.ForJob("api")
+	require.Len(t, entries, 2)
+	assert.Equal(t, "a", entries[0].Check)
+	assert.Empty(t, store.ForJob("missing"))
+
+	entry, ok := store.Get("web", "a")
+	assert.True(t, ok)
+	assert.Equal(t, "web", entry.Job)
+	_, ok = store.Get("web", "b")
+	assert.False(t, ok)
+}
+
+func TestStore_History(t *testing.T) {
+	store := NewStore()
+	for i := 0; i < maxHistory+10; i++ {
+		store.Record(Entry{Job: "api", Check: "env_var_audit", Healthy: i%2 == 0})
+	}
+	store.Record(Entry{Job: "api", Check: "reconcile_environment"})
+
+	all := store.History(0)
+	assert.Len(t, all, maxHistory, "history is bounded")
+	assert.Equal(t, "reconcile_environment", all[0].Check, "newest entry comes first")
+
+	recent := store.History(3)
+	require.Len(t, recent, 3)
+	assert.Equal(t, "env_var_audit", recent[1].Check)
+
+	store.Clear()
+	assert.Empty(t, store.Latest())
+	assert.Empty(t, store.History(0))
+}
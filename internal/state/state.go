@@ -0,0 +1,400 @@
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SchemaVersion is the version of the state directory layout written by this build
+const SchemaVersion = 1
+
+// DefaultMaxHistoryBytes bounds the size of each history file
+const DefaultMaxHistoryBytes int64 = 1 << 20
+
+// ProjectDirName is the name of the per-project state directory
+const ProjectDirName = ".sentinel"
+
+// Categories of state that can be purged. Each maps to a subdirectory of the state root.
+const (
+	CategoryCache     = "cache"
+	CategorySnapshots = "snapshots"
+	CategoryLogs      = "logs"
+	CategoryHistory   = "history"
+)
+
+// Categories lists every purgeable category
+var Categories = []string{CategoryCache, CategorySnapshots, CategoryLogs, CategoryHistory}
+
+// Retention bounds how long and how much history and logs are kept. Zero fields keep the
+// defaults: no age limit, DefaultMaxHistoryBytes per history file and no bound on logs.
+type Retention struct {
+	MaxAge          time.Duration // Records and log files older than this are removed
+	MaxHistoryBytes int64         // Size bound of each history file
+	MaxLogBytes     int64         // Size bound of the logs category; the oldest files are removed first
+}
+
+// versionFile records the schema version of a state directory
+const versionFile = "state.json"
+
+// migrations upgrade a state directory from the key version to the next one.
+// Version 0 is a directory created before the state layer existed (license.json only).
+var migrations = map[int]func(root string) error{
+	0: func(root string) error { return nil },
+}
+
+// versionInfo is the content of state.json
+type versionInfo struct {
+	SchemaVersion int       `json:"schema_version"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Dir is a managed state directory (~/.dev-env-sentinel or <project>/.sentinel)
+type Dir struct {
+	root            string
+	maxHistoryBytes int64
+	mu              sync.Mutex
+}
+
+// DefaultRoot returns the user state directory: SENTINEL_STATE_DIR or ~/.dev-env-sentinel
+func DefaultRoot() string {
+	if dir := os.Getenv("SENTINEL_STATE_DIR"); dir != "" {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "."
+	}
+	return filepath.Join(homeDir, ".dev-env-sentinel")
+}
+
+// ProjectRoot returns the project-local state directory for a project
+func ProjectRoot(projectRoot string) string {
+	return filepath.Join(projectRoot, ProjectDirName)
+}
+
+// Open opens a state directory, creating it and migrating its schema as needed
+func Open(root string) (*Dir, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	d := &Dir{root: root, maxHistoryBytes: DefaultMaxHistoryBytes}
+	if err := d.migrate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Root returns the state directory path
+func (d *Dir) Root() string {
+	return d.root
+}
+
+// SetMaxHistoryBytes changes the per-file history size bound
+func (d *Dir) SetMaxHistoryBytes(max int64) {
+	d.maxHistoryBytes = max
+}
+
+// migrate brings the directory up to SchemaVersion
+func (d *Dir) migrate() error {
+	version := 0
+	data, err := os.ReadFile(filepath.Join(d.root, versionFile))
+	if err == nil {
+		var info versionInfo
+		if err := json.Unmarshal(data, &info); err != nil {
+			return fmt.Errorf("invalid %s in %s: %w", versionFile, d.root, err)
+		}
+		version = info.SchemaVersion
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read state version: %w", err)
+	}
+
+	if version > SchemaVersion {
+		return fmt.Errorf("state directory %s uses schema version %d, newer than supported version %d", d.root, version, SchemaVersion)
+	}
+	if version == SchemaVersion && err == nil {
+		return nil
+	}
+
+	for v := version; v < SchemaVersion; v++ {
+		migrate, ok := migrations[v]
+		if !ok {
+			return fmt.Errorf("no migration from state schema version %d", v)
+		}
+		if err := migrate(d.root); err != nil {
+			return fmt.Errorf("failed to migrate state from version %d: %w", v, err)
+		}
+	}
+
+	return d.writeJSONFile(filepath.Join(d.root, versionFile), versionInfo{
+		SchemaVersion: SchemaVersion,
+		UpdatedAt:     time.Now(),
+	})
+}
+
+// Path returns the path of a file within a state category
+func (d *Dir) Path(category, name string) string {
+	return filepath.Join(d.root, category, name)
+}
+
+// WriteJSON atomically writes a JSON document into a state category
+func (d *Dir) WriteJSON(category, name string, v interface{}) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.writeJSONFile(d.Path(category, name), v)
+}
+
+// ReadJSON reads a JSON document from a state category
+func (d *Dir) ReadJSON(category, name string, v interface{}) error {
+	data, err := os.ReadFile(d.Path(category, name))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeJSONFile writes v to path via a temporary file so readers never see partial data
+func (d *Dir) writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// AppendHistory appends a record to a JSON Lines history file, dropping the
+// oldest records when the file grows past the size bound
+func (d *Dir) AppendHistory(name string, record interface{}) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history record: %w", err)
+	}
+	line = append(line, '\n')
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	path := d.Path(CategoryHistory, name+".jsonl")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+
+	data := append(existing, line...)
+	for int64(len(data)) > d.maxHistoryBytes {
+		idx := bytes.IndexByte(data, '\n')
+		if idx < 0 || idx == len(data)-1 {
+			// A single record larger than the bound is kept on its own
+			break
+		}
+		data = data[idx+1:]
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReadHistory returns the records of a history file, oldest first
+func (d *Dir) ReadHistory(name string) ([]json.RawMessage, error) {
+	file, err := os.Open(d.Path(CategoryHistory, name+".jsonl"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []json.RawMessage
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), int(d.maxHistoryBytes)+1)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || !json.Valid(line) {
+			continue
+		}
+		records = append(records, append(json.RawMessage(nil), line...))
+	}
+	return records, scanner.Err()
+}
+
+// ExpireHistory removes the records of a history file for which expired returns true, and
+// returns how many it removed
+func (d *Dir) ExpireHistory(name string, expired func(record json.RawMessage) bool) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	records, err := d.ReadHistory(name)
+	if err != nil {
+		return 0, err
+	}
+
+	var kept []byte
+	removed := 0
+	for _, record := range records {
+		if expired(record) {
+			removed++
+			continue
+		}
+		kept = append(append(kept, record...), '\n')
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+
+	path := d.Path(CategoryHistory, name+".jsonl")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, kept, 0644); err != nil {
+		return 0, fmt.Errorf("failed to write history: %w", err)
+	}
+	return removed, os.Rename(tmp, path)
+}
+
+// ExpireLogs removes the files of the logs category last written before before (unless it is
+// zero), then the oldest files until the category fits in maxBytes (unless it is zero), and
+// returns the removed paths
+func (d *Dir) ExpireLogs(before time.Time, maxBytes int64) ([]string, error) {
+	type logFile struct {
+		path     string
+		size     int64
+		modified time.Time
+	}
+	var files []logFile
+	err := filepath.Walk(filepath.Join(d.root, CategoryLogs), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.Mode().IsRegular() {
+			files = append(files, logFile{path: path, size: info.Size(), modified: info.ModTime()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect logs: %w", err)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modified.Before(files[j].modified) })
+
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	var removed []string
+	for _, f := range files {
+		expired := !before.IsZero() && f.modified.Before(before)
+		if !expired && (maxBytes <= 0 || total <= maxBytes) {
+			continue
+		}
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove %s: %w", f.path, err)
+		}
+		removed = append(removed, f.path)
+		total -= f.size
+	}
+	return removed, nil
+}
+
+// PurgeReport describes what a purge removed (or would remove in a dry run)
+type PurgeReport struct {
+	Root       string
+	DryRun     bool
+	Removed    []string
+	FreedBytes int64
+}
+
+// Purge removes the given state categories. Only the managed category subdirectories
+// are touched; the license, settings and schema version files are always kept.
+func (d *Dir) Purge(categories []string, dryRun bool) (*PurgeReport, error) {
+	if len(categories) == 0 {
+		categories = Categories
+	}
+
+	for _, category := range categories {
+		if !isCategory(category) {
+			return nil, fmt.Errorf("unknown state category: %s (expected one of %s)", category, strings.Join(Categories, ", "))
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	report := &PurgeReport{Root: d.root, DryRun: dryRun}
+	// Sorted as a copy, as categories may be the caller's slice or Categories itself
+	categories = append([]string(nil), categories...)
+	sort.Strings(categories)
+	for _, category := range categories {
+		path := filepath.Join(d.root, category)
+		size, err := dirSize(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to inspect %s: %w", path, err)
+		}
+
+		if !dryRun {
+			if err := os.RemoveAll(path); err != nil {
+				return nil, fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+		}
+		report.Removed = append(report.Removed, path)
+		report.FreedBytes += size
+	}
+
+	return report, nil
+}
+
+// isCategory checks whether a name is a known state category
+func isCategory(name string) bool {
+	for _, category := range Categories {
+		if category == name {
+			return true
+		}
+	}
+	return false
+}
+
+// dirSize returns the total size of regular files under path
+func dirSize(path string) (int64, error) {
+	if _, err := os.Lstat(path); err != nil {
+		return 0, err
+	}
+
+	var size int64
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
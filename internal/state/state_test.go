@@ -0,0 +1,193 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen_WritesSchemaVersion(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "state")
+
+	d, err := Open(root)
+	require.NoError(t, err)
+	assert.Equal(t, root, d.Root())
+
+	var info versionInfo
+	data, err := os.ReadFile(filepath.Join(root, versionFile))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &info))
+	assert.Equal(t, SchemaVersion, info.SchemaVersion)
+
+	// Reopening an up-to-date directory is a no-op
+	_, err = Open(root)
+	assert.NoError(t, err)
+}
+
+func TestOpen_LegacyDirectory(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "license.json"), []byte(`{"key":"abc"}`), 0644))
+
+	_, err := Open(root)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(root, versionFile))
+	assert.FileExists(t, filepath.Join(root, "license.json"))
+}
+
+func TestOpen_NewerSchema(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, versionFile), []byte(`{"schema_version": 99}`), 0644))
+
+	_, err := Open(root)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "newer than supported")
+}
+
+func TestWriteAndReadJSON(t *testing.T) {
+	d, err := Open(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, d.WriteJSON(CategorySnapshots, "latest.json", map[string]int{"checks": 3}))
+
+	var got map[string]int
+	require.NoError(t, d.ReadJSON(CategorySnapshots, "latest.json", &got))
+	assert.Equal(t, 3, got["checks"])
+	assert.NoFileExists(t, d.Path(CategorySnapshots, "latest.json.tmp"))
+}
+
+func TestAppendHistory_Bounded(t *testing.T) {
+	d, err := Open(t.TempDir())
+	require.NoError(t, err)
+	d.SetMaxHistoryBytes(100)
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, d.AppendHistory("checks", map[string]int{"run": i}))
+	}
+
+	info, err := os.Stat(d.Path(CategoryHistory, "checks.jsonl"))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, info.Size(), int64(100))
+
+	records, err := d.ReadHistory("checks")
+	require.NoError(t, err)
+	require.NotEmpty(t, records)
+	assert.JSONEq(t, `{"run": 19}`, string(records[len(records)-1]), "newest records are kept")
+	assert.Less(t, len(records), 20, "oldest records are dropped")
+
+	missing, err := d.ReadHistory("missing")
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+}
+
+func TestExpireHistory(t *testing.T) {
+	d, err := Open(t.TempDir())
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, d.AppendHistory("checks", map[string]int{"run": i}))
+	}
+
+	removed, err := d.ExpireHistory("checks", func(record json.RawMessage) bool {
+		var r map[string]int
+		return json.Unmarshal(record, &r) == nil && r["run"] < 3
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, removed)
+
+	records, err := d.ReadHistory("checks")
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.JSONEq(t, `{"run": 3}`, string(records[0]))
+
+	removed, err = d.ExpireHistory("missing", func(json.RawMessage) bool { return true })
+	assert.NoError(t, err)
+	assert.Zero(t, removed)
+}
+
+func TestExpireLogs(t *testing.T) {
+	d, err := Open(t.TempDir())
+	require.NoError(t, err)
+	now := time.Now()
+	write := func(name string, size int, age time.Duration) string {
+		path := d.Path(CategoryLogs, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, make([]byte, size), 0644))
+		require.NoError(t, os.Chtimes(path, now.Add(-age), now.Add(-age)))
+		return path
+	}
+	stale := write("commands/job-1.log", 10, 48*time.Hour)
+	old := write("commands/job-2.log", 100, 3*time.Hour)
+	recent := write("trace.jsonl", 100, time.Hour)
+	newest := write("crashes/dump.json", 100, time.Minute)
+
+	removed, err := d.ExpireLogs(now.Add(-24*time.Hour), 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{stale}, removed)
+
+	removed, err = d.ExpireLogs(time.Time{}, 250)
+	require.NoError(t, err)
+	assert.Equal(t, []string{old}, removed, "the oldest files go first")
+	assert.FileExists(t, recent)
+	assert.FileExists(t, newest)
+
+	empty, err := Open(t.TempDir())
+	require.NoError(t, err)
+	removed, err = empty.ExpireLogs(now, 1)
+	require.NoError(t, err)
+	assert.Empty(t, removed, "a directory without logs")
+}
+
+func TestPurge(t *testing.T) {
+	root := t.TempDir()
+	d, err := Open(root)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(root, "license.json"), []byte(`{"key":"abc"}`), 0644))
+	require.NoError(t, d.WriteJSON(CategorySnapshots, "latest.json", []string{"a"}))
+	require.NoError(t, d.AppendHistory("checks", map[string]int{"run": 1}))
+
+	// Dry run reports without removing
+	report, err := d.Purge(nil, true)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Len(t, report.Removed, 2)
+	assert.Greater(t, report.FreedBytes, int64(0))
+	assert.DirExists(t, filepath.Join(root, CategorySnapshots))
+
+	report, err = d.Purge([]string{CategorySnapshots}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(root, CategorySnapshots)}, report.Removed)
+	assert.NoDirExists(t, filepath.Join(root, CategorySnapshots))
+	assert.DirExists(t, filepath.Join(root, CategoryHistory))
+
+	_, err = d.Purge(nil, false)
+	require.NoError(t, err)
+	assert.NoDirExists(t, filepath.Join(root, CategoryHistory))
+	assert.FileExists(t, filepath.Join(root, "license.json"), "license is never purged")
+	assert.FileExists(t, filepath.Join(root, versionFile))
+
+	_, err = d.Purge([]string{"license"}, false)
+	assert.Error(t, err)
+
+	// Neither the caller's categories nor Categories are reordered
+	categories := []string{CategorySnapshots, CategoryHistory}
+	order := append([]string(nil), Categories...)
+	_, err = d.Purge(categories, true)
+	require.NoError(t, err)
+	_, err = d.Purge(nil, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{CategorySnapshots, CategoryHistory}, categories)
+	assert.Equal(t, order, Categories)
+}
+
+func TestDefaultRoot(t *testing.T) {
+	t.Setenv("SENTINEL_STATE_DIR", "/custom/state")
+	assert.Equal(t, "/custom/state", DefaultRoot())
+
+	t.Setenv("SENTINEL_STATE_DIR", "")
+	assert.Equal(t, ".dev-env-sentinel", filepath.Base(DefaultRoot()))
+	assert.Equal(t, filepath.Join("/work/api", ".sentinel"), ProjectRoot("/work/api"))
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"dev-env-sentinel/internal/machine"
+	"dev-env-sentinel/internal/snapshot"
+)
+
+// Export is a portable JSON copy of a store's contents
+type Export struct {
+	ExportedAt time.Time            `json:"exported_at"`
+	Machine    *machine.Fingerprint `json:"machine,omitempty"` // Platform the export was made on
+	Latest     []snapshot.Entry     `json:"latest"`
+	History    []snapshot.Entry     `json:"history"`
+}
+
+// importer is implemented by stores that import an export in one transaction
+type importer interface {
+	Import(export *Export) error
+}
+
+// ExportStore copies the latest snapshot and the full history out of a store
+func ExportStore(store Store) (*Export, error) {
+	latest, err := store.Latest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to export snapshot: %w", err)
+	}
+	history, err := store.Checks(Query{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export history: %w", err)
+	}
+	if latest == nil {
+		latest = []snapshot.Entry{}
+	}
+	if history == nil {
+		history = []snapshot.Entry{}
+	}
+	return &Export{ExportedAt: time.Now().UTC(), Machine: machine.Current(), Latest: latest, History: history}, nil
+}
+
+// ImportStore adds an export's history to a store and replaces its latest snapshot
+func ImportStore(store Store, export *Export) error {
+	if i, ok := store.(importer); ok {
+		return i.Import(export)
+	}
+
+	for _, entry := range export.History {
+		if err := store.AppendCheck(entry); err != nil {
+			return err
+		}
+	}
+	if len(export.Latest) > 0 {
+		return store.SaveLatest(export.Latest)
+	}
+	return nil
+}
+
+// WriteExport writes an export as indented JSON
+func WriteExport(w io.Writer, export *Export) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(export)
+}
+
+// ReadExport reads an export written by WriteExport
+func ReadExport(r io.Reader) (*Export, error) {
+	var export Export
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("invalid export: %w", err)
+	}
+	return &export, nil
+}
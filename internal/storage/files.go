@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"dev-env-sentinel/internal/snapshot"
+	"dev-env-sentinel/internal/state"
+)
+
+// HistoryChecks is the history file that check results are appended to
+const HistoryChecks = "checks"
+
+// LatestSnapshotFile is the file holding the persisted latest snapshot
+const LatestSnapshotFile = "latest.json"
+
+// FileStore keeps history in size-bounded JSON Lines files and the latest
+// snapshot in a JSON document of the state directory
+type FileStore struct {
+	dir *state.Dir
+}
+
+// NewFileStore creates a file store in a state directory
+func NewFileStore(dir *state.Dir) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// AppendCheck appends a check result to the history file
+func (f *FileStore) AppendCheck(entry snapshot.Entry) error {
+	return f.dir.AppendHistory(HistoryChecks, entry)
+}
+
+// Checks reads the history file and filters it in memory
+func (f *FileStore) Checks(q Query) ([]snapshot.Entry, error) {
+	records, err := f.dir.ReadHistory(HistoryChecks)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]snapshot.Entry, 0, len(records))
+	for _, record := range records {
+		var entry snapshot.Entry
+		if json.Unmarshal(record, &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return Filter(entries, q), nil
+}
+
+// SaveLatest writes the latest snapshot document
+func (f *FileStore) SaveLatest(entries []snapshot.Entry) error {
+	return f.dir.WriteJSON(state.CategorySnapshots, LatestSnapshotFile, entries)
+}
+
+// Latest reads the latest snapshot document
+func (f *FileStore) Latest() ([]snapshot.Entry, error) {
+	var entries []snapshot.Entry
+	err := f.dir.ReadJSON(state.CategorySnapshots, LatestSnapshotFile, &entries)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return entries, err
+}
+
+// SetMaxHistoryBytes bounds the size of each history file of the state directory
+func (f *FileStore) SetMaxHistoryBytes(max int64) {
+	f.dir.SetMaxHistoryBytes(max)
+}
+
+// Expire rewrites the history file without the results recorded before a time
+func (f *FileStore) Expire(before time.Time) (int, error) {
+	return f.dir.ExpireHistory(HistoryChecks, func(record json.RawMessage) bool {
+		var entry snapshot.Entry
+		return json.Unmarshal(record, &entry) == nil && entry.Timestamp.Before(before)
+	})
+}
+
+// Purge does nothing: the files live in the category directories the state directory purges
+func (f *FileStore) Purge(categories []string, dryRun bool) ([]string, error) {
+	return nil, nil
+}
+
+// Close does nothing
+func (f *FileStore) Close() error {
+	return nil
+}
@@ -0,0 +1,335 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/snapshot"
+	"dev-env-sentinel/internal/state"
+
+	_ "modernc.org/sqlite" // cgo-free SQLite driver
+)
+
+// DatabaseFile is the SQLite database in the state directory root
+const DatabaseFile = "sentinel.db"
+
+// DefaultMaxHistoryRows bounds the number of check results kept in the database
+const DefaultMaxHistoryRows = 20000
+
+// schema creates the tables of database version 1. Entries are stored as JSON so new
+// fields need no migration; the columns beside them exist for querying.
+const schema = `
+CREATE TABLE IF NOT EXISTS checks (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	job        TEXT    NOT NULL,
+	check_name TEXT    NOT NULL,
+	healthy    INTEGER NOT NULL,
+	timestamp  INTEGER NOT NULL,
+	entry      TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS checks_job_check_time ON checks (job, check_name, timestamp);
+CREATE INDEX IF NOT EXISTS checks_time ON checks (timestamp);
+CREATE TABLE IF NOT EXISTS latest (
+	job        TEXT NOT NULL,
+	check_name TEXT NOT NULL,
+	entry      TEXT NOT NULL,
+	PRIMARY KEY (job, check_name)
+);
+`
+
+// databaseVersion is the PRAGMA user_version written by this build
+const databaseVersion = 1
+
+// SQLiteStore keeps history and the latest snapshot in an embedded SQLite database
+type SQLiteStore struct {
+	db       *sql.DB
+	path     string
+	maxRows  int
+	maxBytes int64 // Bound of the stored entries' size; 0 for none
+}
+
+// OpenSQLite opens (creating if needed) the database of a state directory. A new database
+// imports the history and snapshot files written by the file store.
+func OpenSQLite(dir *state.Dir) (*SQLiteStore, error) {
+	path := filepath.Join(dir.Root(), DatabaseFile)
+	created := !common.FileExists(path)
+
+	// secure_delete overwrites deleted records, so expired history doesn't linger in free pages
+	db, err := sql.Open("sqlite", "file:"+filepath.ToSlash(path)+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=secure_delete(true)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	// A single connection serializes writers; SQLite allows only one at a time anyway
+	db.SetMaxOpenConns(1)
+
+	s := &SQLiteStore{db: db, path: path, maxRows: DefaultMaxHistoryRows}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if created {
+		if err := s.importFiles(NewFileStore(dir)); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to import history files: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// Path returns the database file path
+func (s *SQLiteStore) Path() string {
+	return s.path
+}
+
+// SetMaxHistoryRows changes the number of check results kept
+func (s *SQLiteStore) SetMaxHistoryRows(max int) {
+	s.maxRows = max
+}
+
+// SetMaxHistoryBytes bounds the total size of the stored check results
+func (s *SQLiteStore) SetMaxHistoryBytes(max int64) {
+	s.maxBytes = max
+}
+
+// migrate brings the database schema up to databaseVersion
+func (s *SQLiteStore) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read database version of %s: %w", s.path, err)
+	}
+	if version > databaseVersion {
+		return fmt.Errorf("database %s uses version %d, newer than supported version %d", s.path, version, databaseVersion)
+	}
+	if version == databaseVersion {
+		return nil
+	}
+
+	if _, err := s.db.Exec(schema); err != nil {
+		return fmt.Errorf("failed to create database schema: %w", err)
+	}
+	if _, err := s.db.Exec(fmt.Sprintf("PRAGMA user_version = %d", databaseVersion)); err != nil {
+		return fmt.Errorf("failed to write database version: %w", err)
+	}
+	return nil
+}
+
+// importFiles copies the records of a file store into the database
+func (s *SQLiteStore) importFiles(files *FileStore) error {
+	history, err := files.Checks(Query{})
+	if err != nil {
+		return err
+	}
+	latest, err := files.Latest()
+	if err != nil {
+		return err
+	}
+	return s.Import(&Export{Latest: latest, History: history})
+}
+
+// AppendCheck inserts a check result, dropping the oldest results past the row bound
+func (s *SQLiteStore) AppendCheck(entry snapshot.Entry) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to record check: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertCheck(tx, entry); err != nil {
+		return err
+	}
+	if s.maxRows > 0 {
+		if _, err := tx.Exec(`DELETE FROM checks WHERE id <= (SELECT id FROM checks ORDER BY id DESC LIMIT 1 OFFSET ?)`, s.maxRows); err != nil {
+			return fmt.Errorf("failed to trim history: %w", err)
+		}
+	}
+	if s.maxBytes > 0 {
+		// Drop the results past the newest ones that fit, keeping the newest even if it doesn't
+		if _, err := tx.Exec(`DELETE FROM checks WHERE id < (SELECT MAX(id) FROM checks) AND id <= (
+			SELECT id FROM (SELECT id, SUM(LENGTH(entry)) OVER (ORDER BY id DESC) AS total FROM checks)
+			WHERE total > ? ORDER BY id DESC LIMIT 1)`, s.maxBytes); err != nil {
+			return fmt.Errorf("failed to trim history: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// insertCheck inserts one check result
+func insertCheck(tx *sql.Tx, entry snapshot.Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal check: %w", err)
+	}
+	_, err = tx.Exec(`INSERT INTO checks (job, check_name, healthy, timestamp, entry) VALUES (?, ?, ?, ?, ?)`,
+		entry.Job, entry.Check, entry.Error == "" && entry.Healthy, entry.Timestamp.UnixNano(), string(data))
+	if err != nil {
+		return fmt.Errorf("failed to record check: %w", err)
+	}
+	return nil
+}
+
+// Expire deletes the check results recorded before a time
+func (s *SQLiteStore) Expire(before time.Time) (int, error) {
+	result, err := s.db.Exec(`DELETE FROM checks WHERE timestamp < ?`, before.UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire history: %w", err)
+	}
+	removed, _ := result.RowsAffected()
+	return int(removed), nil
+}
+
+// Checks queries the history, oldest first
+func (s *SQLiteStore) Checks(q Query) ([]snapshot.Entry, error) {
+	var where []string
+	var args []interface{}
+	if q.Job != "" {
+		where = append(where, "job = ?")
+		args = append(args, q.Job)
+	}
+	if q.Check != "" {
+		where = append(where, "check_name = ?")
+		args = append(args, q.Check)
+	}
+	if !q.Since.IsZero() {
+		where = append(where, "timestamp >= ?")
+		args = append(args, q.Since.UnixNano())
+	}
+
+	query := "SELECT entry FROM checks"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if q.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+
+	entries, err := s.queryEntries(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	// Newest first from the query; callers expect oldest first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// SaveLatest replaces the latest snapshot
+func (s *SQLiteStore) SaveLatest(entries []snapshot.Entry) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceLatest(tx, entries); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// replaceLatest replaces the rows of the latest table
+func replaceLatest(tx *sql.Tx, entries []snapshot.Entry) error {
+	if _, err := tx.Exec(`DELETE FROM latest`); err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal snapshot: %w", err)
+		}
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO latest (job, check_name, entry) VALUES (?, ?, ?)`, entry.Job, entry.Check, string(data)); err != nil {
+			return fmt.Errorf("failed to save snapshot: %w", err)
+		}
+	}
+	return nil
+}
+
+// Latest returns the latest snapshot ordered by job and check
+func (s *SQLiteStore) Latest() ([]snapshot.Entry, error) {
+	return s.queryEntries(`SELECT entry FROM latest ORDER BY job, check_name`)
+}
+
+// queryEntries runs a query selecting one JSON entry column
+func (s *SQLiteStore) queryEntries(query string, args ...interface{}) ([]snapshot.Entry, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", s.path, err)
+	}
+	defer rows.Close()
+
+	entries := []snapshot.Entry{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", s.path, err)
+		}
+		var entry snapshot.Entry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// Import adds exported history and replaces the latest snapshot when the export has one
+func (s *SQLiteStore) Import(export *Export) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to import: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, entry := range export.History {
+		if err := insertCheck(tx, entry); err != nil {
+			return err
+		}
+	}
+	if len(export.Latest) > 0 {
+		if err := replaceLatest(tx, export.Latest); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Purge deletes the history and snapshot records of the purged categories
+func (s *SQLiteStore) Purge(categories []string, dryRun bool) ([]string, error) {
+	tables := map[string]string{state.CategoryHistory: "checks", state.CategorySnapshots: "latest"}
+
+	var removed []string
+	for _, category := range []string{state.CategoryHistory, state.CategorySnapshots} {
+		if !purgesCategory(categories, category) {
+			continue
+		}
+		table := tables[category]
+
+		var count int
+		if err := s.db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil {
+			return nil, fmt.Errorf("failed to count %s records: %w", category, err)
+		}
+		if count == 0 {
+			continue
+		}
+		if !dryRun {
+			if _, err := s.db.Exec("DELETE FROM " + table); err != nil {
+				return nil, fmt.Errorf("failed to purge %s records: %w", category, err)
+			}
+		}
+		removed = append(removed, fmt.Sprintf("%s (%d %s records)", s.path, count, category))
+	}
+	return removed, nil
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"dev-env-sentinel/internal/snapshot"
+	"dev-env-sentinel/internal/state"
+)
+
+// Storage backends, selected with SENTINEL_STATE_BACKEND
+const (
+	BackendSQLite = "sqlite"
+	BackendFiles  = "files"
+)
+
+// Query selects recorded check results. Zero fields match everything.
+type Query struct {
+	Job   string
+	Check string
+	Since time.Time
+	Limit int // Keep only the newest Limit results
+}
+
+// Store persists check history and the latest snapshot
+type Store interface {
+	// AppendCheck records a check result in the history
+	AppendCheck(entry snapshot.Entry) error
+	// Checks returns the recorded check results matching q, oldest first
+	Checks(q Query) ([]snapshot.Entry, error)
+	// SaveLatest replaces the persisted latest snapshot
+	SaveLatest(entries []snapshot.Entry) error
+	// Latest returns the persisted latest snapshot
+	Latest() ([]snapshot.Entry, error)
+	// SetMaxHistoryBytes bounds the size of the history; the oldest results are dropped first
+	SetMaxHistoryBytes(max int64)
+	// Expire removes the check results recorded before a time and returns how many it removed
+	Expire(before time.Time) (int, error)
+	// Purge removes the records of the given state categories (all when empty) that the
+	// state directory's own purge does not cover, and returns what was or would be removed
+	Purge(categories []string, dryRun bool) ([]string, error)
+	// Close releases the store
+	Close() error
+}
+
+// Backend returns the configured storage backend: SENTINEL_STATE_BACKEND or sqlite
+func Backend() string {
+	if backend := os.Getenv("SENTINEL_STATE_BACKEND"); backend != "" {
+		return backend
+	}
+	return BackendSQLite
+}
+
+// Open opens the configured store for a state directory
+func Open(dir *state.Dir) (Store, error) {
+	switch backend := Backend(); backend {
+	case BackendSQLite:
+		return OpenSQLite(dir)
+	case BackendFiles:
+		return NewFileStore(dir), nil
+	default:
+		return nil, fmt.Errorf("unknown state backend: %s (expected %s or %s)", backend, BackendSQLite, BackendFiles)
+	}
+}
+
+// Filter applies a query to check results ordered oldest first
+func Filter(entries []snapshot.Entry, q Query) []snapshot.Entry {
+	var matched []snapshot.Entry
+	for _, entry := range entries {
+		if q.matches(entry) {
+			matched = append(matched, entry)
+		}
+	}
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[len(matched)-q.Limit:]
+	}
+	return matched
+}
+
+// matches reports whether an entry satisfies the query's filters
+func (q Query) matches(entry snapshot.Entry) bool {
+	return (q.Job == "" || entry.Job == q.Job) &&
+		(q.Check == "" || entry.Check == q.Check) &&
+		!entry.Timestamp.Before(q.Since)
+}
+
+// purgesCategory reports whether a purge of categories includes category
+func purgesCategory(categories []string, category string) bool {
+	if len(categories) == 0 {
+		return true
+	}
+	for _, c := range categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/machine"
+	"dev-env-sentinel/internal/snapshot"
+	"dev-env-sentinel/internal/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openStore(t *testing.T, backend string) Store {
+	t.Helper()
+	t.Setenv("SENTINEL_STATE_BACKEND", backend)
+	dir, err := state.Open(t.TempDir())
+	require.NoError(t, err)
+	store, err := Open(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func entry(job, check string, healthy bool, at time.Time) snapshot.Entry {
+	return snapshot.Entry{Job: job, Check: check, Healthy: healthy, Timestamp: at}
+}
+
+func TestStores(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, backend := range []string{BackendSQLite, BackendFiles} {
+		t.Run(backend, func(t *testing.T) {
+			store := openStore(t, backend)
+
+			latest, err := store.Latest()
+			require.NoError(t, err)
+			assert.Empty(t, latest)
+
+			history := []snapshot.Entry{
+				entry("api", "env_var_audit", true, start),
+				entry("web", "env_var_audit", false, start.Add(time.Hour)),
+				entry("api", "verify_build_freshness", false, start.Add(2*time.Hour)),
+				entry("api", "env_var_audit", false, start.Add(3*time.Hour)),
+			}
+			for _, e := range history {
+				require.NoError(t, store.AppendCheck(e))
+			}
+
+			all, err := store.Checks(Query{})
+			require.NoError(t, err)
+			require.Len(t, all, 4)
+			assert.True(t, all[0].Timestamp.Equal(start), "oldest first")
+
+			api, err := store.Checks(Query{Job: "api", Check: "env_var_audit"})
+			require.NoError(t, err)
+			require.Len(t, api, 2)
+			assert.False(t, api[1].Healthy)
+
+			recent, err := store.Checks(Query{Since: start.Add(2 * time.Hour)})
+			require.NoError(t, err)
+			assert.Len(t, recent, 2)
+
+			limited, err := store.Checks(Query{Job: "api", Limit: 2})
+			require.NoError(t, err)
+			require.Len(t, limited, 2)
+			assert.Equal(t, "verify_build_freshness", limited[0].Check, "the newest results are kept")
+
+			require.NoError(t, store.SaveLatest([]snapshot.Entry{history[3], history[1]}))
+			latest, err = store.Latest()
+			require.NoError(t, err)
+			require.Len(t, latest, 2)
+			assert.Equal(t, "api", latest[0].Job)
+
+			// Export and import round trip into a fresh store of the same backend
+			export, err := ExportStore(store)
+			require.NoError(t, err)
+			assert.Equal(t, machine.Current(), export.Machine)
+			var buf bytes.Buffer
+			require.NoError(t, WriteExport(&buf, export))
+
+			imported, err := ReadExport(&buf)
+			require.NoError(t, err)
+			assert.Equal(t, export.Machine, imported.Machine)
+			target := openStore(t, backend)
+			require.NoError(t, ImportStore(target, imported))
+
+			copied, err := target.Checks(Query{})
+			require.NoError(t, err)
+			assert.Len(t, copied, 4)
+			latest, err = target.Latest()
+			require.NoError(t, err)
+			assert.Len(t, latest, 2)
+		})
+	}
+}
+
+func TestSQLiteStore_ImportsFiles(t *testing.T) {
+	dir, err := state.Open(t.TempDir())
+	require.NoError(t, err)
+
+	files := NewFileStore(dir)
+	require.NoError(t, files.AppendCheck(entry("api", "env_var_audit", true, time.Now())))
+	require.NoError(t, files.SaveLatest([]snapshot.Entry{entry("api", "env_var_audit", true, time.Now())}))
+
+	store, err := OpenSQLite(dir)
+	require.NoError(t, err)
+
+	history, err := store.Checks(Query{})
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+	latest, err := store.Latest()
+	require.NoError(t, err)
+	assert.Len(t, latest, 1)
+
+	// Files are imported only when the database is created
+	require.NoError(t, store.Close())
+	store, err = OpenSQLite(dir)
+	require.NoError(t, err)
+	defer store.Close()
+	history, err = store.Checks(Query{})
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+}
+
+func TestSQLiteStore_RetentionAndPurge(t *testing.T) {
+	dir, err := state.Open(t.TempDir())
+	require.NoError(t, err)
+	store, err := OpenSQLite(dir)
+	require.NoError(t, err)
+	defer store.Close()
+	store.SetMaxHistoryRows(3)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, store.AppendCheck(entry("api", "env_var_audit", i%2 == 0, time.Unix(int64(i), 0))))
+	}
+	require.NoError(t, store.SaveLatest([]snapshot.Entry{entry("api", "env_var_audit", true, time.Now())}))
+
+	history, err := store.Checks(Query{})
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, int64(2), history[0].Timestamp.Unix(), "the oldest results are dropped")
+
+	removed, err := store.Purge([]string{state.CategoryHistory}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{store.Path() + " (3 history records)"}, removed)
+	history, _ = store.Checks(Query{})
+	assert.Len(t, history, 3, "dry run keeps records")
+
+	removed, err = store.Purge(nil, false)
+	require.NoError(t, err)
+	assert.Len(t, removed, 2)
+	history, _ = store.Checks(Query{})
+	assert.Empty(t, history)
+	latest, _ := store.Latest()
+	assert.Empty(t, latest)
+}
+
+func TestStores_Retention(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, backend := range []string{BackendSQLite, BackendFiles} {
+		t.Run(backend, func(t *testing.T) {
+			store := openStore(t, backend)
+			for i := 0; i < 5; i++ {
+				require.NoError(t, store.AppendCheck(entry("api", "env_var_audit", true, start.Add(time.Duration(i)*time.Hour))))
+			}
+
+			removed, err := store.Expire(start.Add(2 * time.Hour))
+			require.NoError(t, err)
+			assert.Equal(t, 2, removed)
+			history, err := store.Checks(Query{})
+			require.NoError(t, err)
+			require.Len(t, history, 3)
+			assert.True(t, history[0].Timestamp.Equal(start.Add(2*time.Hour)))
+
+			// Room for about two results
+			store.SetMaxHistoryBytes(400)
+			require.NoError(t, store.AppendCheck(entry("api", "env_var_audit", false, start.Add(5*time.Hour))))
+			history, err = store.Checks(Query{})
+			require.NoError(t, err)
+			assert.Less(t, len(history), 4, "the oldest results are dropped")
+			assert.NotEmpty(t, history)
+			assert.False(t, history[len(history)-1].Healthy, "the newest result is kept")
+		})
+	}
+}
+
+func TestOpen_UnknownBackend(t *testing.T) {
+	t.Setenv("SENTINEL_STATE_BACKEND", "postgres")
+	dir, err := state.Open(t.TempDir())
+	require.NoError(t, err)
+	_, err = Open(dir)
+	assert.ErrorContains(t, err, "unknown state backend: postgres")
+}
@@ -0,0 +1,308 @@
+// Package telemetry collects anonymous usage statistics when the user opts in. Tool calls are
+// aggregated locally into batches of counts and durations per tool and counts per issue type;
+// paths, arguments and output never leave the machine. Batches wait in the state directory,
+// where they can be inspected, until they are sent to the configured endpoint.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"dev-env-sentinel/internal/buildinfo"
+	"dev-env-sentinel/internal/state"
+)
+
+// consentFile records the user's choice in the state directory root, so purging state
+// categories never changes it
+const consentFile = "telemetry.json"
+
+// queueDir holds batches waiting to be sent, in the cache category
+const queueDir = "telemetry"
+
+// maxQueued bounds the batches kept while the endpoint is unreachable; the oldest are dropped
+const maxQueued = 50
+
+// EndpointEnvVar overrides the endpoint batches are sent to
+const EndpointEnvVar = "SENTINEL_TELEMETRY_ENDPOINT"
+
+// Consent is the user's telemetry choice
+type Consent struct {
+	Enabled   bool      `json:"enabled"`
+	InstallID string    `json:"install_id,omitempty"` // Random, unrelated to the machine or user
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ToolStats aggregates the calls of one tool
+type ToolStats struct {
+	Calls      int   `json:"calls"`
+	Errors     int   `json:"errors"`
+	DurationMS int64 `json:"duration_ms"` // Total duration of the calls
+}
+
+// Batch is the payload sent to the endpoint: usage aggregated over a period
+type Batch struct {
+	InstallID  string                `json:"install_id"`
+	Version    string                `json:"version"`
+	OS         string                `json:"os"`
+	Arch       string                `json:"arch"`
+	Start      time.Time             `json:"start"`
+	End        time.Time             `json:"end"`
+	Tools      map[string]*ToolStats `json:"tools"`
+	IssueTypes map[string]int        `json:"issue_types,omitempty"`
+}
+
+// LoadConsent reads the user's choice; telemetry is off until enabled
+func LoadConsent(dir *state.Dir) Consent {
+	var consent Consent
+	data, err := os.ReadFile(filepath.Join(dir.Root(), consentFile))
+	if err == nil {
+		json.Unmarshal(data, &consent)
+	}
+	return consent
+}
+
+// SetEnabled records the user's choice. Enabling creates the install ID batches are sent
+// with; disabling deletes the batches not sent yet.
+func SetEnabled(dir *state.Dir, enabled bool) (Consent, error) {
+	consent := LoadConsent(dir)
+	consent.Enabled = enabled
+	consent.UpdatedAt = time.Now().UTC()
+	if enabled && consent.InstallID == "" {
+		id := make([]byte, 8)
+		if _, err := rand.Read(id); err != nil {
+			return consent, fmt.Errorf("failed to create install id: %w", err)
+		}
+		consent.InstallID = hex.EncodeToString(id)
+	}
+
+	data, err := json.MarshalIndent(consent, "", "  ")
+	if err != nil {
+		return consent, err
+	}
+	if err := os.WriteFile(filepath.Join(dir.Root(), consentFile), data, 0600); err != nil {
+		return consent, fmt.Errorf("failed to save telemetry choice: %w", err)
+	}
+	if !enabled {
+		if err := os.RemoveAll(dir.Path(state.CategoryCache, queueDir)); err != nil {
+			return consent, fmt.Errorf("failed to delete queued telemetry: %w", err)
+		}
+	}
+	return consent, nil
+}
+
+// Disabled reports why telemetry is off regardless of the user's choice, or "". DO_NOT_TRACK
+// and SENTINEL_TELEMETRY=off turn it off for an environment, e.g. CI.
+func Disabled() string {
+	if v := os.Getenv("DO_NOT_TRACK"); v != "" && v != "0" {
+		return "DO_NOT_TRACK is set"
+	}
+	switch strings.ToLower(os.Getenv("SENTINEL_TELEMETRY")) {
+	case "off", "0", "false":
+		return "SENTINEL_TELEMETRY is off"
+	}
+	return ""
+}
+
+// Endpoint returns the endpoint batches are sent to: SENTINEL_TELEMETRY_ENDPOINT, else the
+// configured one
+func Endpoint(configured string) string {
+	if endpoint := os.Getenv(EndpointEnvVar); endpoint != "" {
+		return endpoint
+	}
+	return configured
+}
+
+// Recorder aggregates tool calls into a batch. A nil Recorder records nothing.
+type Recorder struct {
+	dir      *state.Dir
+	endpoint string
+	client   *http.Client
+	mu       sync.Mutex
+	batch    *Batch
+	flushMu  sync.Mutex // Serializes flushes so a batch is never sent twice
+}
+
+// NewRecorder returns a recorder queuing batches in dir and sending them to endpoint, or nil
+// when the user hasn't opted in
+func NewRecorder(dir *state.Dir, endpoint string) *Recorder {
+	consent := LoadConsent(dir)
+	if !consent.Enabled || Disabled() != "" {
+		return nil
+	}
+	r := &Recorder{dir: dir, endpoint: endpoint, client: &http.Client{Timeout: 10 * time.Second}}
+	r.batch = r.newBatch(consent.InstallID)
+	return r
+}
+
+// newBatch starts an empty batch
+func (r *Recorder) newBatch(installID string) *Batch {
+	return &Batch{
+		InstallID:  installID,
+		Version:    buildinfo.Get().Version,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		Start:      time.Now().UTC(),
+		Tools:      make(map[string]*ToolStats),
+		IssueTypes: make(map[string]int),
+	}
+}
+
+// Record adds a tool call and the types of the issues it reported
+func (r *Recorder) Record(tool string, duration time.Duration, err error, issueTypes []string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats, ok := r.batch.Tools[tool]
+	if !ok {
+		stats = &ToolStats{}
+		r.batch.Tools[tool] = stats
+	}
+	stats.Calls++
+	if err != nil {
+		stats.Errors++
+	}
+	stats.DurationMS += duration.Milliseconds()
+	for _, issueType := range issueTypes {
+		r.batch.IssueTypes[issueType]++
+	}
+}
+
+// Flush queues the current batch, if it recorded anything, and sends the queued batches
+func (r *Recorder) Flush(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	r.flushMu.Lock()
+	defer r.flushMu.Unlock()
+
+	r.mu.Lock()
+	batch := r.batch
+	if len(batch.Tools) > 0 {
+		r.batch = r.newBatch(batch.InstallID)
+	}
+	r.mu.Unlock()
+
+	if len(batch.Tools) > 0 {
+		batch.End = time.Now().UTC()
+		if err := enqueue(r.dir, batch); err != nil {
+			return err
+		}
+	}
+	if r.endpoint == "" {
+		return nil
+	}
+	_, err := send(ctx, r.dir, r.endpoint, r.client)
+	return err
+}
+
+// Run flushes the recorder every interval until ctx is done
+func (r *Recorder) Run(ctx context.Context, interval time.Duration) {
+	if r == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.Flush(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// enqueue writes a batch to the queue, dropping the oldest batches beyond maxQueued
+func enqueue(dir *state.Dir, batch *Batch) error {
+	name := fmt.Sprintf("%d.json", batch.End.UnixNano())
+	if err := dir.WriteJSON(state.CategoryCache, filepath.Join(queueDir, name), batch); err != nil {
+		return fmt.Errorf("failed to queue telemetry: %w", err)
+	}
+	files, err := queued(dir)
+	if err != nil {
+		return err
+	}
+	for len(files) > maxQueued {
+		os.Remove(files[0])
+		files = files[1:]
+	}
+	return nil
+}
+
+// queued returns the paths of the queued batches, oldest first
+func queued(dir *state.Dir) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir.Path(state.CategoryCache, queueDir), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Queued returns the batches waiting to be sent, oldest first
+func Queued(dir *state.Dir) ([]Batch, error) {
+	files, err := queued(dir)
+	if err != nil {
+		return nil, err
+	}
+	batches := []Batch{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var batch Batch
+		if err := json.Unmarshal(data, &batch); err != nil {
+			continue
+		}
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
+
+// send posts the queued batches to the endpoint, deleting each once accepted. It stops at
+// the first failure so the remaining batches are retried later.
+func send(ctx context.Context, dir *state.Dir, endpoint string, client *http.Client) (int, error) {
+	files, err := queued(dir)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+		if err != nil {
+			return sent, fmt.Errorf("invalid telemetry endpoint: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return sent, fmt.Errorf("failed to send telemetry: %w", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return sent, fmt.Errorf("telemetry endpoint returned %s", resp.Status)
+		}
+		os.Remove(file)
+		sent++
+	}
+	return sent, nil
+}
@@ -0,0 +1,137 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openDir opens a state directory with telemetry enabled
+func openDir(t *testing.T) *state.Dir {
+	t.Helper()
+	t.Setenv("DO_NOT_TRACK", "")
+	t.Setenv("SENTINEL_TELEMETRY", "")
+	dir, err := state.Open(t.TempDir())
+	require.NoError(t, err)
+	_, err = SetEnabled(dir, true)
+	require.NoError(t, err)
+	return dir
+}
+
+func TestConsent(t *testing.T) {
+	t.Setenv("DO_NOT_TRACK", "")
+	dir, err := state.Open(t.TempDir())
+	require.NoError(t, err)
+	assert.False(t, LoadConsent(dir).Enabled, "telemetry is off until enabled")
+	assert.Nil(t, NewRecorder(dir, ""))
+
+	consent, err := SetEnabled(dir, true)
+	require.NoError(t, err)
+	assert.True(t, LoadConsent(dir).Enabled)
+	assert.Len(t, consent.InstallID, 16)
+	assert.NotNil(t, NewRecorder(dir, ""))
+
+	t.Setenv("DO_NOT_TRACK", "1")
+	assert.Equal(t, "DO_NOT_TRACK is set", Disabled())
+	assert.Nil(t, NewRecorder(dir, ""), "DO_NOT_TRACK overrides the user's choice")
+	t.Setenv("DO_NOT_TRACK", "")
+
+	r := NewRecorder(dir, "")
+	r.Record("env_var_audit", time.Second, nil, nil)
+	require.NoError(t, r.Flush(context.Background()))
+	_, err = SetEnabled(dir, false)
+	require.NoError(t, err)
+	batches, err := Queued(dir)
+	require.NoError(t, err)
+	assert.Empty(t, batches, "disabling deletes queued batches")
+	assert.Equal(t, consent.InstallID, LoadConsent(dir).InstallID)
+}
+
+func TestRecorder_AggregatesAndQueues(t *testing.T) {
+	dir := openDir(t)
+	r := NewRecorder(dir, "")
+
+	r.Record("verify_build_freshness", 1200*time.Millisecond, nil, []string{"stale_build", "stale_build"})
+	r.Record("verify_build_freshness", 800*time.Millisecond, errors.New("boom"), nil)
+	r.Record("env_var_audit", 50*time.Millisecond, nil, []string{"missing_env_var"})
+	require.NoError(t, r.Flush(context.Background()))
+	// An empty batch isn't queued
+	require.NoError(t, r.Flush(context.Background()))
+
+	batches, err := Queued(dir)
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	batch := batches[0]
+	assert.Equal(t, LoadConsent(dir).InstallID, batch.InstallID)
+	assert.Equal(t, &ToolStats{Calls: 2, Errors: 1, DurationMS: 2000}, batch.Tools["verify_build_freshness"])
+	assert.Equal(t, &ToolStats{Calls: 1, DurationMS: 50}, batch.Tools["env_var_audit"])
+	assert.Equal(t, map[string]int{"stale_build": 2, "missing_env_var": 1}, batch.IssueTypes)
+	assert.False(t, batch.End.Before(batch.Start))
+
+	var nilRecorder *Recorder
+	nilRecorder.Record("env_var_audit", time.Second, nil, nil)
+	assert.NoError(t, nilRecorder.Flush(context.Background()))
+}
+
+func TestRecorder_SendsQueuedBatches(t *testing.T) {
+	var mu sync.Mutex
+	var received []Batch
+	status := http.StatusServiceUnavailable
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if status == http.StatusAccepted {
+			body, _ := io.ReadAll(r.Body)
+			var batch Batch
+			json.Unmarshal(body, &batch)
+			received = append(received, batch)
+		}
+		w.WriteHeader(status)
+	}))
+	defer endpoint.Close()
+
+	dir := openDir(t)
+	r := NewRecorder(dir, endpoint.URL)
+	r.Record("env_var_audit", time.Second, nil, nil)
+	assert.Error(t, r.Flush(context.Background()))
+	batches, err := Queued(dir)
+	require.NoError(t, err)
+	assert.Len(t, batches, 1, "batches the endpoint refused stay queued")
+
+	mu.Lock()
+	status = http.StatusAccepted
+	mu.Unlock()
+	r.Record("check_locale", time.Second, nil, nil)
+	require.NoError(t, r.Flush(context.Background()))
+
+	batches, err = Queued(dir)
+	require.NoError(t, err)
+	assert.Empty(t, batches)
+	require.Len(t, received, 2)
+	assert.Contains(t, received[0].Tools, "env_var_audit")
+	assert.Contains(t, received[1].Tools, "check_locale")
+}
+
+func TestEnqueue_DropsOldest(t *testing.T) {
+	dir := openDir(t)
+	start := time.Now()
+	for i := 0; i < maxQueued+5; i++ {
+		batch := &Batch{End: start.Add(time.Duration(i) * time.Second), Tools: map[string]*ToolStats{"env_var_audit": {Calls: i}}}
+		require.NoError(t, enqueue(dir, batch))
+	}
+
+	batches, err := Queued(dir)
+	require.NoError(t, err)
+	require.Len(t, batches, maxQueued)
+	assert.Equal(t, 5, batches[0].Tools["env_var_audit"].Calls)
+}
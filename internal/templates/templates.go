@@ -0,0 +1,136 @@
+// Package templates renders tool results with Go text/templates provided by a team, so the
+// text shown to agents and users can be reordered, trimmed or branded. Structured results,
+// such as the JSON of the gRPC API, are not affected.
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Ext is the file extension of templates
+const Ext = ".tmpl"
+
+// Default names the built-in rendering; selecting it turns templates off for a call
+const Default = "default"
+
+// Data is what a template is executed with
+type Data struct {
+	Tool   string      // Tool that produced the result, e.g. "verify_build_freshness"
+	Result interface{} // The tool's structured result, as returned to API clients
+	Text   string      // The built-in rendering of the result
+}
+
+// Set is the templates of a directory. A template named compact is read from compact.tmpl;
+// compact.verify_build_freshness.tmpl, if present, is used for that tool instead. Tools
+// without a template of the selected name keep the built-in rendering.
+type Set struct {
+	templates map[string]*template.Template // File name without extension -> template
+}
+
+// Load parses the templates of a directory
+func Load(dir string) (*Set, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("template directory not found: %s", dir)
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+Ext))
+	if err != nil {
+		return nil, err
+	}
+
+	set := &Set{templates: make(map[string]*template.Template)}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key := strings.TrimSuffix(filepath.Base(path), Ext)
+		tmpl, err := template.New(key).Funcs(funcs).Option("missingkey=zero").Parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid template %s: %w", filepath.Base(path), err)
+		}
+		set.templates[key] = tmpl
+	}
+	return set, nil
+}
+
+// Names returns the names templates can be selected by, sorted
+func (s *Set) Names() []string {
+	if s == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for key := range s.templates {
+		name, _, _ := strings.Cut(key, ".")
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Has reports whether a template name can be selected. The built-in rendering always can.
+func (s *Set) Has(name string) bool {
+	if name == Default {
+		return true
+	}
+	for _, n := range s.Names() {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns the template of a name for a tool, or nil when the tool keeps the built-in
+// rendering
+func (s *Set) lookup(name, tool string) *template.Template {
+	if s == nil || name == "" || name == Default {
+		return nil
+	}
+	if tmpl, ok := s.templates[name+"."+tool]; ok {
+		return tmpl
+	}
+	return s.templates[name]
+}
+
+// Render renders a tool result with a named template. ok is false when there is no template
+// of that name for the tool, and the built-in text should be used.
+func (s *Set) Render(name string, data Data) (text string, ok bool, err error) {
+	tmpl := s.lookup(name, data.Tool)
+	if tmpl == nil {
+		return "", false, nil
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", true, err
+	}
+	return buf.String(), true, nil
+}
+
+// funcs are the helpers available to templates besides the text/template built-ins
+var funcs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+	// indent prefixes every line of s with n spaces
+	"indent": func(n int, s string) string {
+		pad := strings.Repeat(" ", n)
+		return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+	},
+	// json renders a value as indented JSON
+	"json": func(v interface{}) (string, error) {
+		data, err := json.MarshalIndent(v, "", "  ")
+		return string(data), err
+	},
+}
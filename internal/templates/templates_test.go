@@ -0,0 +1,102 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type report struct {
+	IsHealthy bool
+	Issues    []string
+}
+
+func writeTemplate(t *testing.T, dir, name, text string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(text), 0644))
+}
+
+func TestSet_Render(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "acme.tmpl", "ACME dev env\n{{.Text}}")
+	writeTemplate(t, dir, "acme.verify_build_freshness.tmpl", "{{if .Result.IsHealthy}}fresh{{else}}{{join .Result.Issues \"; \" | upper}}{{end}}")
+	writeTemplate(t, dir, "README.md", "not a template")
+
+	set, err := Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acme"}, set.Names())
+	assert.True(t, set.Has("acme"))
+	assert.True(t, set.Has(Default))
+	assert.False(t, set.Has("compact"))
+
+	text, ok, err := set.Render("acme", Data{Tool: "verify_build_freshness", Result: &report{Issues: []string{"stale jar", "missing lock"}}})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "STALE JAR; MISSING LOCK", text)
+
+	text, ok, err = set.Render("acme", Data{Tool: "env_var_audit", Text: "✅ All set"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "ACME dev env\n✅ All set", text)
+
+	_, ok, _ = set.Render(Default, Data{Tool: "env_var_audit"})
+	assert.False(t, ok)
+}
+
+func TestSet_ToolSpecificOnly(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "compact.env_var_audit.tmpl", "{{len .Result.Issues}} issue(s)")
+
+	set, err := Load(dir)
+	require.NoError(t, err)
+	assert.True(t, set.Has("compact"))
+
+	_, ok, err := set.Render("compact", Data{Tool: "verify_build_freshness"})
+	require.NoError(t, err)
+	assert.False(t, ok, "tools without a template keep the built-in rendering")
+}
+
+func TestSet_ExecuteError(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "broken.tmpl", "{{.Result.Missing}}")
+
+	set, err := Load(dir)
+	require.NoError(t, err)
+	_, ok, err := set.Render("broken", Data{Tool: "env_var_audit", Result: &report{}})
+	assert.True(t, ok)
+	assert.Error(t, err)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorContains(t, err, "template directory not found")
+
+	dir := t.TempDir()
+	writeTemplate(t, dir, "bad.tmpl", "{{if}}")
+	_, err = Load(dir)
+	assert.ErrorContains(t, err, "invalid template bad.tmpl")
+}
+
+func TestNilSet(t *testing.T) {
+	var set *Set
+	assert.Nil(t, set.Names())
+	assert.False(t, set.Has("acme"))
+	assert.True(t, set.Has(Default))
+	_, ok, err := set.Render("acme", Data{})
+	assert.False(t, ok)
+	assert.NoError(t, err)
+}
+
+func TestFuncs(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "funcs.tmpl", "{{indent 2 \"a\\nb\"}}|{{json .Result}}|{{trim \"  x \"}}|{{lower \"ABC\"}}")
+
+	set, err := Load(dir)
+	require.NoError(t, err)
+	text, _, err := set.Render("funcs", Data{Result: map[string]int{"issues": 2}})
+	require.NoError(t, err)
+	assert.Equal(t, "  a\n  b|{\n  \"issues\": 2\n}|x|abc", text)
+}
@@ -0,0 +1,125 @@
+package tickets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"dev-env-sentinel/internal/settings"
+)
+
+// defaultGitHubAPI is the GitHub API base URL
+const defaultGitHubAPI = "https://api.github.com"
+
+// githubMarker carries the fingerprint in the body of GitHub issues, hidden when rendered
+const githubMarker = "<!-- sentinel-fingerprint: %s -->"
+
+// gitHubTracker files GitHub issues in a repository
+type gitHubTracker struct {
+	repo   string
+	api    string
+	token  string
+	labels []string
+}
+
+// githubIssue is the part of a GitHub issue the tracker reads
+type githubIssue struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+}
+
+// newGitHubTracker creates a GitHub tracker; the token defaults to $GITHUB_TOKEN
+func newGitHubTracker(cfg settings.GitHubTracker) *gitHubTracker {
+	t := &gitHubTracker{repo: cfg.Repo, api: strings.TrimSuffix(cfg.APIURL, "/"), token: cfg.Token, labels: cfg.Labels}
+	if t.api == "" {
+		t.api = defaultGitHubAPI
+	}
+	if t.token == "" {
+		t.token = os.Getenv("GITHUB_TOKEN")
+	}
+	return t
+}
+
+// Find searches the repository's open issues for the fingerprint marker
+func (t *gitHubTracker) Find(ctx context.Context, fingerprint string) (*Ticket, error) {
+	query := fmt.Sprintf(`repo:%s is:issue is:open in:body "sentinel-fingerprint: %s"`, t.repo, fingerprint)
+	var result struct {
+		Items []githubIssue `json:"items"`
+	}
+	if err := t.do(ctx, http.MethodGet, "/search/issues?q="+url.QueryEscape(query), nil, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Items) == 0 {
+		return nil, nil
+	}
+	return t.ticket(result.Items[0], fingerprint), nil
+}
+
+// Create opens an issue, with the fingerprint marker at the end of its body
+func (t *gitHubTracker) Create(ctx context.Context, title, body, fingerprint string) (*Ticket, error) {
+	request := map[string]interface{}{
+		"title": title,
+		"body":  strings.TrimRight(body, "\n") + "\n\n" + fmt.Sprintf(githubMarker, fingerprint) + "\n",
+	}
+	if len(t.labels) > 0 {
+		request["labels"] = t.labels
+	}
+	var issue githubIssue
+	if err := t.do(ctx, http.MethodPost, "/repos/"+t.repo+"/issues", request, &issue); err != nil {
+		return nil, err
+	}
+	return t.ticket(issue, fingerprint), nil
+}
+
+// ticket converts a GitHub issue
+func (t *gitHubTracker) ticket(issue githubIssue, fingerprint string) *Ticket {
+	return &Ticket{Tracker: GitHub, ID: fmt.Sprintf("#%d", issue.Number), URL: issue.HTMLURL, Fingerprint: fingerprint}
+}
+
+// do calls the GitHub API and decodes the JSON response into v
+func (t *gitHubTracker) do(ctx context.Context, method, path string, request, v interface{}) error {
+	if t.token == "" {
+		return fmt.Errorf("no GitHub token; set tickets.github.token or GITHUB_TOKEN")
+	}
+	var body io.Reader
+	if request != nil {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, t.api+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return doJSON(req, v)
+}
+
+// doJSON sends a request and decodes a successful JSON response into v
+func doJSON(req *http.Request, v interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", req.URL.Host, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", req.URL.Host, err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package tickets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"dev-env-sentinel/internal/settings"
+)
+
+// jiraLabelPrefix prefixes the label carrying the fingerprint of Jira issues; labels can be
+// searched exactly with JQL, unlike descriptions
+const jiraLabelPrefix = "sentinel-"
+
+// jiraTracker files Jira issues in a project
+type jiraTracker struct {
+	url       string
+	project   string
+	issueType string
+	email     string
+	token     string
+	labels    []string
+}
+
+// newJiraTracker creates a Jira tracker; credentials default to $JIRA_EMAIL and $JIRA_API_TOKEN
+func newJiraTracker(cfg settings.JiraTracker) *jiraTracker {
+	t := &jiraTracker{
+		url:       strings.TrimSuffix(cfg.URL, "/"),
+		project:   cfg.Project,
+		issueType: cfg.IssueType,
+		email:     cfg.Email,
+		token:     cfg.Token,
+		labels:    cfg.Labels,
+	}
+	if t.issueType == "" {
+		t.issueType = "Task"
+	}
+	if t.email == "" {
+		t.email = os.Getenv("JIRA_EMAIL")
+	}
+	if t.token == "" {
+		t.token = os.Getenv("JIRA_API_TOKEN")
+	}
+	return t
+}
+
+// Find searches the project's unresolved issues for the fingerprint label
+func (t *jiraTracker) Find(ctx context.Context, fingerprint string) (*Ticket, error) {
+	jql := fmt.Sprintf(`project = "%s" AND labels = "%s" AND statusCategory != Done ORDER BY created DESC`, t.project, jiraLabelPrefix+fingerprint)
+	var result struct {
+		Issues []struct {
+			Key string `json:"key"`
+		} `json:"issues"`
+	}
+	if err := t.do(ctx, http.MethodGet, "/rest/api/2/search?fields=key&maxResults=1&jql="+url.QueryEscape(jql), nil, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Issues) == 0 {
+		return nil, nil
+	}
+	return t.ticket(result.Issues[0].Key, fingerprint), nil
+}
+
+// Create files an issue labeled with the fingerprint
+func (t *jiraTracker) Create(ctx context.Context, title, body, fingerprint string) (*Ticket, error) {
+	request := map[string]interface{}{
+		"fields": map[string]interface{}{
+			"project":     map[string]string{"key": t.project},
+			"issuetype":   map[string]string{"name": t.issueType},
+			"summary":     title,
+			"description": body,
+			"labels":      append(append([]string{}, t.labels...), jiraLabelPrefix+fingerprint),
+		},
+	}
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := t.do(ctx, http.MethodPost, "/rest/api/2/issue", request, &created); err != nil {
+		return nil, err
+	}
+	return t.ticket(created.Key, fingerprint), nil
+}
+
+// ticket converts a Jira issue key
+func (t *jiraTracker) ticket(key, fingerprint string) *Ticket {
+	return &Ticket{Tracker: Jira, ID: key, URL: t.url + "/browse/" + key, Fingerprint: fingerprint}
+}
+
+// do calls the Jira REST API and decodes the JSON response into v. With an email the token
+// is an Atlassian Cloud API token; without one, a Data Center personal access token.
+func (t *jiraTracker) do(ctx context.Context, method, path string, request, v interface{}) error {
+	if t.token == "" {
+		return fmt.Errorf("no Jira token; set tickets.jira.token or JIRA_API_TOKEN")
+	}
+	var body io.Reader
+	if request != nil {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, t.url+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if t.email != "" {
+		req.SetBasicAuth(t.email, t.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return doJSON(req, v)
+}
@@ -0,0 +1,310 @@
+// Package tickets files environment issues that keep coming back as GitHub or Jira tickets,
+// so they get escalated out of chat. Each issue has a fingerprint; exporting it again finds
+// the open ticket instead of filing a duplicate.
+package tickets
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"dev-env-sentinel/internal/machine"
+	"dev-env-sentinel/internal/privacy"
+	"dev-env-sentinel/internal/redact"
+	"dev-env-sentinel/internal/report"
+	"dev-env-sentinel/internal/settings"
+	"dev-env-sentinel/internal/snapshot"
+	"dev-env-sentinel/internal/state"
+)
+
+// Tracker names
+const (
+	GitHub = "github"
+	Jira   = "jira"
+)
+
+// DefaultTitle and DefaultBody are the ticket templates used unless the settings set others
+const (
+	DefaultTitle = `[sentinel] {{.Check}}: {{.Summary}}`
+	DefaultBody  = `Dev-Env Sentinel found an environment issue worth tracking.
+
+Project: {{.ProjectRoot}}
+Check: {{.Check}}{{if .Severity}} ({{.Severity}}){{end}}
+{{- if .File}}
+File: {{.File}}{{if .Line}}:{{.Line}}{{end}}{{end}}
+{{- if .Machine}}
+Machine: {{.Machine}}{{end}}
+Found: {{.FoundAt.Format "2006-01-02 15:04 MST"}}
+{{range .Details}}
+- {{.}}{{end}}
+`
+)
+
+// cacheFile records the tickets filed from this state directory, by tracker and fingerprint
+const cacheFile = "tickets.json"
+
+// searchLag is how long a new ticket may be missing from tracker searches; within it the
+// ticket recorded locally is trusted instead
+const searchLag = time.Hour
+
+// maxTitle bounds ticket titles; Jira rejects summaries over 255 characters
+const maxTitle = 200
+
+// Issue is an environment problem to file a ticket for. The title and body templates are
+// executed with it.
+type Issue struct {
+	Fingerprint string               `json:"fingerprint"`
+	ProjectRoot string               `json:"project_root"`
+	Check       string               `json:"check"` // Tool that found the issue, or the scheduled job of a snapshot
+	Severity    string               `json:"severity,omitempty"`
+	Summary     string               `json:"summary"`
+	Details     []string             `json:"details,omitempty"`
+	File        string               `json:"file,omitempty"`
+	Line        int                  `json:"line,omitempty"`
+	Machine     *machine.Fingerprint `json:"machine,omitempty"`
+	FoundAt     time.Time            `json:"found_at"`
+}
+
+// Ticket is a ticket filed for an issue
+type Ticket struct {
+	Tracker     string    `json:"tracker"`
+	ID          string    `json:"id"` // e.g. "#42" or "DEV-7"
+	URL         string    `json:"url"`
+	Fingerprint string    `json:"fingerprint"`
+	Created     bool      `json:"created"` // False when an open ticket for the issue already existed
+	CreatedAt   time.Time `json:"created_at,omitempty"`
+}
+
+// Tracker files and finds tickets in an issue tracker
+type Tracker interface {
+	// Find returns the open ticket carrying a fingerprint, or nil
+	Find(ctx context.Context, fingerprint string) (*Ticket, error)
+	// Create files a ticket carrying a fingerprint
+	Create(ctx context.Context, title, body, fingerprint string) (*Ticket, error)
+}
+
+// Exporter files issues with the configured trackers
+type Exporter struct {
+	trackers map[string]Tracker
+	title    *template.Template
+	body     *template.Template
+	strict   bool // Strict privacy mode: no variable values or paths outside the project
+}
+
+// client calls the tracker APIs
+var client = &http.Client{Timeout: 15 * time.Second}
+
+// New creates an exporter for the trackers of the settings
+func New(cfg settings.Tickets) (*Exporter, error) {
+	e := &Exporter{trackers: make(map[string]Tracker)}
+	if cfg.GitHub != nil {
+		e.trackers[GitHub] = newGitHubTracker(*cfg.GitHub)
+	}
+	if cfg.Jira != nil {
+		e.trackers[Jira] = newJiraTracker(*cfg.Jira)
+	}
+
+	titleText, bodyText := DefaultTitle, DefaultBody
+	if cfg.Title != "" {
+		titleText = cfg.Title
+	}
+	if cfg.Body != "" {
+		bodyText = cfg.Body
+	}
+	var err error
+	if e.title, err = template.New("title").Option("missingkey=zero").Parse(titleText); err != nil {
+		return nil, fmt.Errorf("invalid ticket title template: %w", err)
+	}
+	if e.body, err = template.New("body").Option("missingkey=zero").Parse(bodyText); err != nil {
+		return nil, fmt.Errorf("invalid ticket body template: %w", err)
+	}
+	return e, nil
+}
+
+// SetStrictPrivacy sets whether tickets are filed without the values of environment variables
+// and the paths outside the project
+func (e *Exporter) SetStrictPrivacy(strict bool) {
+	if e != nil {
+		e.strict = strict
+	}
+}
+
+// Trackers returns the names of the configured trackers, sorted
+func (e *Exporter) Trackers() []string {
+	if e == nil {
+		return nil
+	}
+	var names []string
+	for name := range e.trackers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Export files a ticket for an issue with a tracker, or with the only configured one when
+// tracker is empty. An open ticket with the issue's fingerprint is returned instead of filing
+// a duplicate. Tickets filed are recorded in dir, if set, to bridge the delay before trackers
+// find new tickets by search.
+func (e *Exporter) Export(ctx context.Context, dir *state.Dir, issue Issue, tracker string) (*Ticket, error) {
+	names := e.Trackers()
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no ticket tracker configured; add tickets.github or tickets.jira to sentinel.yaml")
+	}
+	if tracker == "" {
+		if len(names) > 1 {
+			return nil, fmt.Errorf("several ticket trackers are configured; set tracker to one of %s", strings.Join(names, ", "))
+		}
+		tracker = names[0]
+	}
+	t, ok := e.trackers[tracker]
+	if !ok {
+		return nil, fmt.Errorf("ticket tracker %s is not configured (configured: %s)", tracker, strings.Join(names, ", "))
+	}
+
+	existing, err := t.Find(ctx, issue.Fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s for existing tickets: %w", tracker, err)
+	}
+	if existing != nil {
+		return existing, nil
+	}
+	cache := loadCache(dir)
+	key := tracker + "/" + issue.Fingerprint
+	if recent, ok := cache[key]; ok && time.Since(recent.CreatedAt) < searchLag {
+		recent.Created = false
+		return &recent, nil
+	}
+
+	title, err := render(e.title, issue)
+	if err != nil {
+		return nil, fmt.Errorf("ticket title template failed: %w", err)
+	}
+	title = strings.Join(strings.Fields(e.redact(title, issue)), " ")
+	if len(title) > maxTitle {
+		title = title[:maxTitle-3] + "..."
+	}
+	body, err := render(e.body, issue)
+	if err != nil {
+		return nil, fmt.Errorf("ticket body template failed: %w", err)
+	}
+	body = e.redact(body, issue)
+
+	ticket, err := t.Create(ctx, title, body, issue.Fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to file %s ticket: %w", tracker, err)
+	}
+	ticket.Created = true
+	ticket.CreatedAt = time.Now().UTC()
+	if dir != nil {
+		cache[key] = *ticket
+		dir.WriteJSON(state.CategoryCache, cacheFile, cache)
+	}
+	return ticket, nil
+}
+
+// redact masks the secrets in ticket text, which leaves the machine, and in strict privacy
+// mode withholds variable values and paths outside the issue's project
+func (e *Exporter) redact(text string, issue Issue) string {
+	if e.strict {
+		text = privacy.Text(text, issue.ProjectRoot)
+	}
+	return redact.String(text)
+}
+
+// FromFinding returns the issue of a finding of a check. Its fingerprint depends on the
+// project, check, file and message, so the same problem found again maps to the same ticket.
+func FromFinding(projectRoot string, finding report.Finding) Issue {
+	return Issue{
+		Fingerprint: Fingerprint(projectRoot, finding.Check, finding.File, finding.Message),
+		ProjectRoot: projectRoot,
+		Check:       finding.Check,
+		Severity:    finding.Severity,
+		Summary:     firstLine(finding.Message),
+		Details:     []string{finding.Message},
+		File:        finding.File,
+		Line:        finding.Line,
+		Machine:     machine.Current(),
+		FoundAt:     time.Now(),
+	}
+}
+
+// FromSnapshot returns the issue of a scheduled job's failing checks, from its latest snapshot
+func FromSnapshot(job string, entries []snapshot.Entry) (Issue, error) {
+	var failing []snapshot.Entry
+	for _, entry := range entries {
+		if entry.Job == job && !entry.Healthy {
+			failing = append(failing, entry)
+		}
+	}
+	if len(failing) == 0 {
+		return Issue{}, fmt.Errorf("no failing checks in the latest snapshot of job: %s", job)
+	}
+
+	issue := Issue{
+		ProjectRoot: failing[0].ProjectRoot,
+		Check:       job,
+		Severity:    report.SeverityError,
+		Machine:     failing[0].Machine,
+	}
+	var checks []string
+	for _, entry := range failing {
+		checks = append(checks, entry.Check)
+		detail := entry.Summary
+		if entry.Error != "" {
+			detail = entry.Error
+		}
+		if entry.Flaky {
+			detail += " (flaky)"
+		}
+		issue.Details = append(issue.Details, fmt.Sprintf("%s: %s", entry.Check, firstLine(detail)))
+		if entry.Timestamp.After(issue.FoundAt) {
+			issue.FoundAt = entry.Timestamp
+		}
+	}
+	sort.Strings(checks)
+	issue.Summary = fmt.Sprintf("%s failing", strings.Join(checks, ", "))
+	issue.Fingerprint = Fingerprint(issue.ProjectRoot, job, strings.Join(checks, ","))
+	return issue, nil
+}
+
+// Fingerprint identifies an issue by its project and the parts that describe it
+func Fingerprint(projectRoot string, parts ...string) string {
+	sum := sha256.Sum256([]byte(projectRoot + "\x00" + strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// render executes a ticket template
+func render(tmpl *template.Template, issue Issue) (string, error) {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, issue); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// loadCache reads the tickets recorded in a state directory
+func loadCache(dir *state.Dir) map[string]Ticket {
+	cache := make(map[string]Ticket)
+	if dir != nil {
+		dir.ReadJSON(state.CategoryCache, cacheFile, &cache)
+	}
+	return cache
+}
+
+// firstLine returns the first non-empty line of a message
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			return trimmed
+		}
+	}
+	return ""
+}
@@ -0,0 +1,222 @@
+package tickets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/report"
+	"dev-env-sentinel/internal/settings"
+	"dev-env-sentinel/internal/snapshot"
+	"dev-env-sentinel/internal/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGitHub serves the GitHub search and issue endpoints, finding issues once indexed
+type fakeGitHub struct {
+	mu      sync.Mutex
+	created []map[string]interface{}
+	indexed bool // Whether search finds the created issues yet
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer gh-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/search/issues":
+		var items []githubIssue
+		for i, issue := range f.created {
+			if f.indexed && strings.Contains(r.URL.Query().Get("q"), "sentinel-fingerprint: ") &&
+				strings.Contains(issue["body"].(string), strings.Trim(strings.SplitN(r.URL.Query().Get("q"), "sentinel-fingerprint: ", 2)[1], `"`)) {
+				items = append(items, githubIssue{Number: i + 1, HTMLURL: "https://github.com/acme/app/issues/" + string(rune('1'+i))})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/app/issues":
+		var issue map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&issue)
+		f.created = append(f.created, issue)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(githubIssue{Number: len(f.created), HTMLURL: "https://github.com/acme/app/issues/" + string(rune('0'+len(f.created)))})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func testIssue() Issue {
+	finding := report.Finding{Check: "env_var_audit", Severity: report.SeverityError, Message: "Environment variable DATABASE_URL is not set", File: "src/db.go", Line: 12}
+	return FromFinding("/work/app", finding)
+}
+
+func TestExport_GitHub(t *testing.T) {
+	gh := &fakeGitHub{}
+	api := httptest.NewServer(gh)
+	defer api.Close()
+	dir, err := state.Open(t.TempDir())
+	require.NoError(t, err)
+
+	exporter, err := New(settings.Tickets{GitHub: &settings.GitHubTracker{Repo: "acme/app", APIURL: api.URL, Token: "gh-token", Labels: []string{"dev-env"}}})
+	require.NoError(t, err)
+	issue := testIssue()
+
+	ticket, err := exporter.Export(context.Background(), dir, issue, "")
+	require.NoError(t, err)
+	assert.True(t, ticket.Created)
+	assert.Equal(t, "#1", ticket.ID)
+	require.Len(t, gh.created, 1)
+	assert.Equal(t, "[sentinel] env_var_audit: Environment variable DATABASE_URL is not set", gh.created[0]["title"])
+	body := gh.created[0]["body"].(string)
+	assert.Contains(t, body, "File: src/db.go:12")
+	assert.Contains(t, body, "<!-- sentinel-fingerprint: "+issue.Fingerprint+" -->")
+	assert.Equal(t, []interface{}{"dev-env"}, gh.created[0]["labels"])
+
+	// Before search indexes the new issue, the local record prevents a duplicate
+	ticket, err = exporter.Export(context.Background(), dir, issue, GitHub)
+	require.NoError(t, err)
+	assert.False(t, ticket.Created)
+	assert.Equal(t, "#1", ticket.ID)
+
+	// Afterwards search finds it, even without the local record
+	gh.indexed = true
+	ticket, err = exporter.Export(context.Background(), nil, issue, GitHub)
+	require.NoError(t, err)
+	assert.False(t, ticket.Created)
+	assert.Len(t, gh.created, 1)
+
+	// Another issue gets its own ticket
+	other := issue
+	other.Fingerprint = Fingerprint("/work/app", "env_var_audit", "", "other")
+	ticket, err = exporter.Export(context.Background(), dir, other, GitHub)
+	require.NoError(t, err)
+	assert.True(t, ticket.Created)
+	assert.Len(t, gh.created, 2)
+}
+
+func TestExport_Redacted(t *testing.T) {
+	gh := &fakeGitHub{}
+	api := httptest.NewServer(gh)
+	defer api.Close()
+	exporter, err := New(settings.Tickets{GitHub: &settings.GitHubTracker{Repo: "acme/app", APIURL: api.URL, Token: "gh-token"}})
+	require.NoError(t, err)
+
+	finding := report.Finding{Check: "env_var_audit", Severity: report.SeverityError,
+		Message: "DATABASE_URL=postgres://app:hunter2@db/app is unreachable, see /home/dev/notes.txt"}
+	_, err = exporter.Export(context.Background(), nil, FromFinding("/work/app", finding), "")
+	require.NoError(t, err)
+	require.Len(t, gh.created, 1)
+	for _, field := range []string{"title", "body"} {
+		text := gh.created[0][field].(string)
+		assert.NotContains(t, text, "hunter2", field)
+		assert.Contains(t, text, "/home/dev/notes.txt", field)
+	}
+
+	// Strict privacy mode also withholds variable values and paths outside the project
+	exporter.SetStrictPrivacy(true)
+	finding.Message += " (strict)"
+	_, err = exporter.Export(context.Background(), nil, FromFinding("/work/app", finding), "")
+	require.NoError(t, err)
+	require.Len(t, gh.created, 2)
+	for _, field := range []string{"title", "body"} {
+		text := gh.created[1][field].(string)
+		assert.Contains(t, text, "DATABASE_URL=[withheld]", field)
+		assert.NotContains(t, text, "/home/dev", field)
+	}
+	assert.Contains(t, gh.created[1]["body"], "Project: /work/app")
+}
+
+func TestExport_Jira(t *testing.T) {
+	var created map[string]interface{}
+	var jql string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "dev@example.com" || pass != "jira-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/rest/api/2/search":
+			jql = r.URL.Query().Get("jql")
+			json.NewEncoder(w).Encode(map[string]interface{}{"issues": []interface{}{}})
+		case "/rest/api/2/issue":
+			json.NewDecoder(r.Body).Decode(&created)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{"key": "DEV-7"})
+		}
+	}))
+	defer api.Close()
+	t.Setenv("JIRA_EMAIL", "dev@example.com")
+	t.Setenv("JIRA_API_TOKEN", "jira-token")
+
+	exporter, err := New(settings.Tickets{
+		Jira:  &settings.JiraTracker{URL: api.URL, Project: "DEV"},
+		Title: "Env: {{.Summary}}",
+		Body:  "{{.ProjectRoot}} / {{.Fingerprint}}",
+	})
+	require.NoError(t, err)
+	issue := testIssue()
+
+	ticket, err := exporter.Export(context.Background(), nil, issue, "")
+	require.NoError(t, err)
+	assert.Equal(t, "DEV-7", ticket.ID)
+	assert.Equal(t, api.URL+"/browse/DEV-7", ticket.URL)
+	assert.Contains(t, jql, `labels = "sentinel-`+issue.Fingerprint+`"`)
+	fields := created["fields"].(map[string]interface{})
+	assert.Equal(t, "Env: Environment variable DATABASE_URL is not set", fields["summary"])
+	assert.Equal(t, "/work/app / "+issue.Fingerprint, fields["description"])
+	assert.Equal(t, map[string]interface{}{"name": "Task"}, fields["issuetype"])
+	assert.Equal(t, []interface{}{"sentinel-" + issue.Fingerprint}, fields["labels"])
+}
+
+func TestExport_Trackers(t *testing.T) {
+	exporter, err := New(settings.Tickets{})
+	require.NoError(t, err)
+	_, err = exporter.Export(context.Background(), nil, testIssue(), "")
+	assert.ErrorContains(t, err, "no ticket tracker configured")
+
+	t.Setenv("GITHUB_TOKEN", "")
+	exporter, err = New(settings.Tickets{
+		GitHub: &settings.GitHubTracker{Repo: "acme/app"},
+		Jira:   &settings.JiraTracker{URL: "https://example.atlassian.net", Project: "DEV"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"github", "jira"}, exporter.Trackers())
+	_, err = exporter.Export(context.Background(), nil, testIssue(), "")
+	assert.ErrorContains(t, err, "set tracker to one of github, jira")
+	_, err = exporter.Export(context.Background(), nil, testIssue(), "gitlab")
+	assert.ErrorContains(t, err, "gitlab is not configured")
+	_, err = exporter.Export(context.Background(), nil, testIssue(), GitHub)
+	assert.ErrorContains(t, err, "no GitHub token")
+
+	_, err = New(settings.Tickets{Title: "{{.Summary"})
+	assert.ErrorContains(t, err, "invalid ticket title template")
+}
+
+func TestFromSnapshot(t *testing.T) {
+	now := time.Now()
+	entries := []snapshot.Entry{
+		{Job: "nightly", ProjectRoot: "/work/app", Check: "verify_build_freshness", Healthy: false, Summary: "stale build\nmore", Timestamp: now},
+		{Job: "nightly", ProjectRoot: "/work/app", Check: "check_locale", Healthy: true, Timestamp: now},
+		{Job: "nightly", ProjectRoot: "/work/app", Check: "env_var_audit", Healthy: false, Error: "boom", Flaky: true, Timestamp: now},
+	}
+
+	issue, err := FromSnapshot("nightly", entries)
+	require.NoError(t, err)
+	assert.Equal(t, "env_var_audit, verify_build_freshness failing", issue.Summary)
+	assert.Equal(t, []string{"verify_build_freshness: stale build", "env_var_audit: boom (flaky)"}, issue.Details)
+	again, err := FromSnapshot("nightly", []snapshot.Entry{entries[2], entries[0]})
+	require.NoError(t, err)
+	assert.Equal(t, issue.Fingerprint, again.Fingerprint, "the fingerprint doesn't depend on order")
+
+	_, err = FromSnapshot("nightly", entries[1:2])
+	assert.ErrorContains(t, err, "no failing checks")
+}
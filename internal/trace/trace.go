@@ -0,0 +1,132 @@
+// Package trace records the JSON-RPC messages exchanged with MCP clients, with secrets
+// redacted, so client compatibility bugs can be reported with the exact protocol traffic
+package trace
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"dev-env-sentinel/internal/redact"
+	"dev-env-sentinel/internal/state"
+)
+
+// FileName is the trace file in the logs category of the state directory
+const FileName = "trace.jsonl"
+
+// MaxBytes is the size past which the trace file is rotated. One rotated file is kept.
+const MaxBytes = 5 << 20
+
+// Directions of traced messages
+const (
+	In  = "in"
+	Out = "out"
+)
+
+// Redacted replaces secret values in traced messages
+const Redacted = redact.Mask
+
+// Enabled reports whether tracing is turned on with SENTINEL_TRACE
+func Enabled() bool {
+	switch strings.ToLower(os.Getenv("SENTINEL_TRACE")) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// Entry is one traced message
+type Entry struct {
+	Time      time.Time       `json:"time"`
+	Direction string          `json:"direction"`
+	Transport string          `json:"transport"`
+	Message   json.RawMessage `json:"message"`
+}
+
+// Log appends traced messages to a rotating file
+type Log struct {
+	mu   sync.Mutex
+	path string
+}
+
+// New creates a trace log in a state directory
+func New(dir *state.Dir) *Log {
+	return &Log{path: dir.Path(state.CategoryLogs, FileName)}
+}
+
+// Path returns the path of the trace file
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Record traces a message sent or received over a transport. Failures are ignored: tracing
+// never gets in the way of serving the client.
+func (l *Log) Record(direction, transport string, msg interface{}) {
+	if l == nil {
+		return
+	}
+	redacted, err := json.Marshal(Redact(msg))
+	if err != nil {
+		return
+	}
+	line, err := json.Marshal(Entry{Time: time.Now().UTC(), Direction: direction, Transport: transport, Message: redacted})
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return
+	}
+	if info, err := os.Stat(l.path); err == nil && info.Size()+int64(len(line)) > MaxBytes {
+		os.Rename(l.path, l.path+".1")
+	}
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	file.Write(line)
+	file.Close()
+}
+
+// Tail returns the last n traced entries, oldest first, including the rotated file
+func (l *Log) Tail(n int) ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var entries []Entry
+	for _, path := range []string{l.path + ".1", l.path} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 64*1024), MaxBytes)
+		for scanner.Scan() {
+			var entry Entry
+			if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	if n > 0 && len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+	return entries, nil
+}
+
+// Redact returns a copy of a JSON-compatible value with its secrets masked by the redaction
+// engine: values of secret-looking keys, credentials and random-looking tokens in strings
+func Redact(v interface{}) interface{} {
+	return redact.Value(v)
+}
@@ -0,0 +1,64 @@
+package trace
+
+import (
+	"encoding/json"
+	"testing"
+
+	"dev-env-sentinel/internal/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	msg := map[string]interface{}{
+		"method": "tools/call",
+		"params": map[string]interface{}{
+			"name": "activate_pro",
+			"arguments": map[string]interface{}{
+				"license_key":  "pro-abc-123",
+				"project_root": "/work/api",
+			},
+		},
+		"result": []map[string]interface{}{
+			{"type": "text", "text": "Sent Authorization: Bearer eyJhbGciOi.abc and API_TOKEN=s3cr3t to the registry"},
+		},
+	}
+
+	redacted := Redact(msg).(map[string]interface{})
+	args := redacted["params"].(map[string]interface{})["arguments"].(map[string]interface{})
+	assert.Equal(t, Redacted, args["license_key"])
+	assert.Equal(t, "/work/api", args["project_root"])
+
+	text := redacted["result"].([]interface{})[0].(map[string]interface{})["text"]
+	assert.Equal(t, "Sent Authorization: Bearer [REDACTED] and API_TOKEN=[REDACTED] to the registry", text)
+
+	// The original message is left untouched
+	assert.Equal(t, "pro-abc-123", msg["params"].(map[string]interface{})["arguments"].(map[string]interface{})["license_key"])
+}
+
+func TestLog_RecordAndTail(t *testing.T) {
+	dir, err := state.Open(t.TempDir())
+	require.NoError(t, err)
+	log := New(dir)
+
+	log.Record(In, "stdio", map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "tools/list"})
+	log.Record(Out, "stdio", map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": map[string]interface{}{"token": "abc"}})
+	log.Record(In, "http", map[string]interface{}{"jsonrpc": "2.0", "id": 2, "method": "tools/call"})
+
+	entries, err := log.Tail(2)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, Out, entries[0].Direction)
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "id": 1, "result": {"token": "[REDACTED]"}}`, string(entries[0].Message))
+	assert.Equal(t, "http", entries[1].Transport)
+
+	var nilLog *Log
+	nilLog.Record(In, "stdio", json.RawMessage(`{}`)) // Tracing off
+}
+
+func TestEnabled(t *testing.T) {
+	t.Setenv("SENTINEL_TRACE", "1")
+	assert.True(t, Enabled())
+	t.Setenv("SENTINEL_TRACE", "")
+	assert.False(t, Enabled())
+}
@@ -0,0 +1,120 @@
+package trends
+
+import (
+	"sort"
+	"time"
+
+	"dev-env-sentinel/internal/snapshot"
+)
+
+// freshnessCheck is the tool whose failures mean the build was stale
+const freshnessCheck = "verify_build_freshness"
+
+// CheckTrend summarizes the results of one check of a scheduled job
+type CheckTrend struct {
+	Job            string
+	Check          string
+	Runs           int
+	Failures       int
+	FailureRate    float64
+	Fixes          int           // Times the check went from failing back to healthy
+	AverageFixTime time.Duration // Average time from the first failing run to the next healthy run
+	FailingSince   time.Time     // Zero unless the latest run failed
+}
+
+// EnvVarTrend counts the runs in which an environment variable was missing
+type EnvVarTrend struct {
+	Name string
+	Runs int
+}
+
+// TrendReport summarizes a window of the check history
+type TrendReport struct {
+	From           time.Time
+	To             time.Time
+	Runs           int
+	BuildChecks    int // Freshness check runs
+	StaleBuilds    int // Freshness check runs that found a stale build
+	Checks         []CheckTrend
+	MissingEnvVars []EnvVarTrend // Variables missing in more than one run, most frequent first
+}
+
+// Summarize computes trends over check results ordered oldest first
+func Summarize(entries []snapshot.Entry) *TrendReport {
+	report := &TrendReport{
+		Checks:         []CheckTrend{},
+		MissingEnvVars: []EnvVarTrend{},
+	}
+	if len(entries) == 0 {
+		return report
+	}
+	report.From = entries[0].Timestamp
+	report.To = entries[len(entries)-1].Timestamp
+
+	index := make(map[string]int)
+	fixTimes := make(map[string]time.Duration)
+	missing := make(map[string]int)
+
+	for _, entry := range entries {
+		report.Runs++
+		healthy := entry.Error == "" && entry.Healthy
+
+		key := entry.Job + "/" + entry.Check
+		i, ok := index[key]
+		if !ok {
+			i = len(report.Checks)
+			index[key] = i
+			report.Checks = append(report.Checks, CheckTrend{Job: entry.Job, Check: entry.Check})
+		}
+		c := &report.Checks[i]
+		c.Runs++
+
+		switch {
+		case !healthy:
+			c.Failures++
+			if c.FailingSince.IsZero() {
+				c.FailingSince = entry.Timestamp
+			}
+		case !c.FailingSince.IsZero():
+			c.Fixes++
+			fixTimes[key] += entry.Timestamp.Sub(c.FailingSince)
+			c.FailingSince = time.Time{}
+		}
+
+		if entry.Check == freshnessCheck {
+			report.BuildChecks++
+			if !healthy {
+				report.StaleBuilds++
+			}
+		}
+
+		for _, name := range entry.MissingEnvVars {
+			missing[name]++
+		}
+	}
+
+	for i := range report.Checks {
+		c := &report.Checks[i]
+		c.FailureRate = float64(c.Failures) / float64(c.Runs)
+		if c.Fixes > 0 {
+			c.AverageFixTime = fixTimes[c.Job+"/"+c.Check] / time.Duration(c.Fixes)
+		}
+	}
+	sort.SliceStable(report.Checks, func(a, b int) bool {
+		return report.Checks[a].FailureRate > report.Checks[b].FailureRate
+	})
+
+	for name, runs := range missing {
+		if runs > 1 {
+			report.MissingEnvVars = append(report.MissingEnvVars, EnvVarTrend{Name: name, Runs: runs})
+		}
+	}
+	sort.Slice(report.MissingEnvVars, func(a, b int) bool {
+		if report.MissingEnvVars[a].Runs != report.MissingEnvVars[b].Runs {
+			return report.MissingEnvVars[a].Runs > report.MissingEnvVars[b].Runs
+		}
+		return report.MissingEnvVars[a].Name < report.MissingEnvVars[b].Name
+	})
+
+	return report
+}
@@ -0,0 +1,58 @@
+package trends
+
+import (
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/snapshot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return start.Add(time.Duration(hours) * time.Hour) }
+
+	entries := []snapshot.Entry{
+		{Job: "api", Check: "verify_build_freshness", Healthy: false, Timestamp: at(0)},
+		{Job: "api", Check: "env_var_audit", Healthy: false, MissingEnvVars: []string{"API_KEY", "REDIS_URL"}, Timestamp: at(0)},
+		{Job: "api", Check: "verify_build_freshness", Healthy: false, Timestamp: at(1)},
+		{Job: "api", Check: "env_var_audit", Healthy: true, Timestamp: at(1)},
+		{Job: "api", Check: "verify_build_freshness", Healthy: true, Timestamp: at(3)},
+		{Job: "api", Check: "env_var_audit", Healthy: false, MissingEnvVars: []string{"API_KEY"}, Timestamp: at(3)},
+		{Job: "api", Check: "verify_build_freshness", Healthy: false, Timestamp: at(4)},
+		{Job: "api", Check: "env_var_audit", Healthy: true, Timestamp: at(5)},
+		{Job: "api", Check: "verify_build_freshness", Healthy: true, Timestamp: at(5)},
+		{Job: "api", Check: "check_locale", Error: "boom", Timestamp: at(5)},
+	}
+
+	report := Summarize(entries)
+	assert.Equal(t, 10, report.Runs)
+	assert.Equal(t, at(0), report.From)
+	assert.Equal(t, at(5), report.To)
+	assert.Equal(t, 5, report.BuildChecks)
+	assert.Equal(t, 3, report.StaleBuilds)
+
+	require.Len(t, report.Checks, 3)
+	assert.Equal(t, "check_locale", report.Checks[0].Check, "ordered by failure rate")
+	assert.Equal(t, at(5), report.Checks[0].FailingSince)
+
+	freshness := report.Checks[1]
+	assert.Equal(t, "verify_build_freshness", freshness.Check)
+	assert.InDelta(t, 0.6, freshness.FailureRate, 0.001)
+	assert.Equal(t, 2, freshness.Fixes)
+	assert.Equal(t, 2*time.Hour, freshness.AverageFixTime, "fixed after 3h and 1h")
+	assert.True(t, freshness.FailingSince.IsZero())
+
+	envVars := report.Checks[2]
+	assert.Equal(t, 2, envVars.Fixes)
+	assert.Equal(t, 90*time.Minute, envVars.AverageFixTime)
+
+	assert.Equal(t, []EnvVarTrend{{Name: "API_KEY", Runs: 2}}, report.MissingEnvVars)
+}
+
+func TestSummarize_Empty(t *testing.T) {
+	report := Summarize(nil)
+	assert.Zero(t, report.Runs)
+	assert.Empty(t, report.Checks)
+}
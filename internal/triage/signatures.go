@@ -0,0 +1,152 @@
+package triage
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"dev-env-sentinel/internal/infra"
+	"dev-env-sentinel/internal/reconciler"
+)
+
+// IssueVersionTooOld is the issue type of a runtime older than the code or a dependency needs,
+// as the version validator reports it
+const IssueVersionTooOld = "version_too_old"
+
+// signature is a kind of environment failure recognized in test and build logs
+type signature struct {
+	id        string
+	issueType string
+	check     string // Tool that confirms the issue on this machine
+	patterns  []*regexp.Regexp
+	// explain turns the submatches of a pattern into what the log shows and how to fix it,
+	// given the services the project declares
+	explain func(match []string, services []string) (subject, message, fix string)
+}
+
+// signatures is the library of environment failures, in the order matches are reported
+var signatures = []signature{
+	{
+		id:        "missing_env_var",
+		issueType: reconciler.IssueMissingEnvVar,
+		check:     "env_var_audit",
+		patterns: []*regexp.Regexp{
+			// Spring
+			regexp.MustCompile(`Could not resolve placeholder '([A-Z_][A-Z0-9_]*)'`),
+			// Python os.environ["NAME"]
+			regexp.MustCompile(`KeyError: '([A-Z_][A-Z0-9_]*)'`),
+			// Messages of config loaders and hand-written checks
+			regexp.MustCompile(`[Ee]nvironment variable ['"]?([A-Z_][A-Z0-9_]*)['"]? (?:is )?(?:not set|not defined|undefined|missing|required)`),
+			regexp.MustCompile(`[Mm]issing (?:required )?(?:environment|env) (?:variable|var)s?:? ['"]?([A-Z_][A-Z0-9_]*)`),
+			regexp.MustCompile(`\b([A-Z][A-Z0-9]*_[A-Z0-9_]+) (?:is not set|must be set|is required|is undefined)`),
+		},
+		explain: func(match []string, services []string) (string, string, string) {
+			name := match[1]
+			return name, fmt.Sprintf("Environment variable %s is not set", name),
+				fmt.Sprintf("Set %s in the environment or .env; env_var_audit lists every missing variable, and reconcile_environment adds those with a safe default to .env", name)
+		},
+	},
+	{
+		id:        "connection_refused",
+		issueType: infra.IssueServiceUnavailable,
+		check:     "check_infrastructure_parity",
+		patterns: []*regexp.Regexp{
+			// Node: connect ECONNREFUSED 127.0.0.1:5432
+			regexp.MustCompile(`ECONNREFUSED\s+\[?[0-9A-Za-z.:-]*?\]?:(\d{2,5})\b`),
+			// JDBC and psql: Connection to localhost:5432 refused
+			regexp.MustCompile(`[Cc]onnection to [\w.-]+:(\d{2,5}) refused`),
+			// Go: dial tcp 127.0.0.1:6379: connect: connection refused
+			regexp.MustCompile(`dial tcp [\w.\[\]:-]*?:(\d{2,5}): connect: connection refused`),
+			// Python and others: host:port ... Connection refused
+			regexp.MustCompile(`\b(?:localhost|127\.0\.0\.1|0\.0\.0\.0|\[::1\]):(\d{2,5})\b.{0,80}?[Cc]onnection refused`),
+			regexp.MustCompile(`[Cc]onnection refused.{0,80}?\b(?:localhost|127\.0\.0\.1|0\.0\.0\.0|\[::1\]):(\d{2,5})\b`),
+		},
+		explain: func(match []string, services []string) (string, string, string) {
+			port, _ := strconv.Atoi(match[1])
+			if service := serviceOnPort(port, services); service != "" {
+				return service, fmt.Sprintf("Connection to %s on port %d was refused: it isn't running or listens elsewhere", service, port),
+					fmt.Sprintf("Start %s (e.g. docker compose up -d %s), then confirm with check_infrastructure_parity", service, service)
+			}
+			subject := fmt.Sprintf("port %d", port)
+			return subject, fmt.Sprintf("Connection to port %d was refused: the service behind it isn't running", port),
+				fmt.Sprintf("Start the service the tests expect on port %d, then confirm with check_infrastructure_parity", port)
+		},
+	},
+	{
+		id:        "class_version",
+		issueType: IssueVersionTooOld,
+		check:     "check_infrastructure_parity",
+		patterns: []*regexp.Regexp{
+			// UnsupportedClassVersionError since Java 9
+			regexp.MustCompile(`compiled by a more recent version of the Java Runtime \(class file version (\d+)\.\d+\), this version of the Java Runtime only recognizes class file versions up to (\d+)`),
+			// UnsupportedClassVersionError up to Java 8
+			regexp.MustCompile(`Unsupported major\.minor version (\d+)\.\d+`),
+		},
+		explain: func(match []string, services []string) (string, string, string) {
+			classVersion, _ := strconv.Atoi(match[1])
+			needed := classVersion - 44 // Class file version 52 is Java 8
+			message := fmt.Sprintf("Classes were compiled for Java %d, newer than the Java runtime running them", needed)
+			if len(match) > 2 && match[2] != "" {
+				running, _ := strconv.Atoi(match[2])
+				message = fmt.Sprintf("Classes were compiled for Java %d, but the runtime is Java %d", needed, running-44)
+			}
+			return fmt.Sprintf("java %d", needed), message,
+				fmt.Sprintf("Run the tests with Java %d or newer (set JAVA_HOME or switch with your version manager), or rebuild the classes for the current runtime", needed)
+		},
+	},
+	{
+		id:        "compiler_release",
+		issueType: IssueVersionTooOld,
+		check:     "check_infrastructure_parity",
+		patterns: []*regexp.Regexp{
+			// javac through Maven or Gradle: invalid target release: 17, release version 21 not supported
+			regexp.MustCompile(`invalid (?:target|source) release:? (\d+)`),
+			regexp.MustCompile(`release version (\d+) not supported`),
+		},
+		explain: func(match []string, services []string) (string, string, string) {
+			return "java " + match[1], fmt.Sprintf("The build targets Java %s, which the JDK running it doesn't support", match[1]),
+				fmt.Sprintf("Build with JDK %s or newer (set JAVA_HOME or switch with your version manager)", match[1])
+		},
+	},
+	{
+		id:        "node_engine",
+		issueType: IssueVersionTooOld,
+		check:     "check_infrastructure_parity",
+		patterns: []*regexp.Regexp{
+			// Yarn
+			regexp.MustCompile(`The engine "node" is incompatible with this module\. Expected version "([^"]+)"\. Got "([^"]+)"`),
+		},
+		explain: func(match []string, services []string) (string, string, string) {
+			return "node " + match[1], fmt.Sprintf("A dependency needs Node.js %s, but the build runs %s", match[1], match[2]),
+				fmt.Sprintf("Switch to a Node.js version matching %s (e.g. nvm install and nvm use)", match[1])
+		},
+	},
+}
+
+// checkFor returns the tool that confirms issues of a type, from the built-in signatures
+// reporting it, or ""
+func checkFor(issueType string) string {
+	for _, sig := range signatures {
+		if sig.issueType == issueType {
+			return sig.check
+		}
+	}
+	return ""
+}
+
+// serviceOnPort returns the service that listens on a port by default, or "". Of the services
+// sharing a port, one the project declares is preferred, else the alphabetically first.
+func serviceOnPort(port int, declared []string) string {
+	for _, name := range declared {
+		if infra.DefaultPorts[name] == port {
+			return name
+		}
+	}
+	service := ""
+	for name, p := range infra.DefaultPorts {
+		if p == port && (service == "" || name < service) {
+			service = name
+		}
+	}
+	return service
+}
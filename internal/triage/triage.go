@@ -0,0 +1,173 @@
+// Package triage recognizes environment failures in captured test and build logs, such as a
+// missing variable, a refused connection to a service or a runtime too old for the classes,
+// and maps them to the issue types and fixes of the checks.
+package triage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/reconciler"
+)
+
+// SourceText is the source of a log passed as text rather than read from a file
+const SourceText = "log"
+
+// maxExcerpt bounds the log line quoted for a match
+const maxExcerpt = 240
+
+// maxLineBytes bounds a single log line; longer lines fail the scan
+const maxLineBytes = 1024 * 1024
+
+// Match is an environment failure found in a log
+type Match struct {
+	Signature   string // Kind of failure, e.g. connection_refused, or the name of a config's signature
+	Ecosystem   string // Ecosystem whose config defines the signature; empty for built-in ones
+	IssueType   string // Issue type of the checks, e.g. service_unavailable
+	Subject     string // Variable, service or runtime the failure is about
+	Message     string
+	Line        int    // First log line showing it, from 1
+	Excerpt     string // That line, trimmed
+	Count       int    // Log lines showing it
+	Check       string // Tool that confirms the issue on this machine
+	Fix         string
+	Fingerprint string // Fix of the project's config for the issue type, to pass to reconcile_issue
+}
+
+// Report is the triage of a log
+type Report struct {
+	ProjectRoot string
+	Source      string // Log file, or SourceText
+	Lines       int
+	Matches     []Match
+	Ecosystems  []string // Ecosystems of the project whose services and fixes were used
+}
+
+// Triage scans a log for environment failures. With a project, the failure signatures of its
+// ecosystems' configs are matched before the built-in ones, refused ports are named after the
+// services they declare, and fixes they define for an issue type are used.
+func Triage(log io.Reader, source, projectRoot string, ecosystems []*detector.DetectedEcosystem) (*Report, error) {
+	report := &Report{ProjectRoot: projectRoot, Source: source, Matches: []Match{}}
+	var services []string
+	for _, eco := range ecosystems {
+		report.Ecosystems = append(report.Ecosystems, eco.ID)
+		for _, service := range eco.Config.Ecosystem.Infrastructure.Services {
+			services = append(services, service.Name)
+		}
+	}
+
+	index := make(map[string]int)
+	scanner := bufio.NewScanner(log)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+	for scanner.Scan() {
+		report.Lines++
+		line := scanner.Text()
+		if matchConfigSignatures(report, index, line, ecosystems) {
+			continue
+		}
+		for _, sig := range signatures {
+			for _, pattern := range sig.patterns {
+				match := pattern.FindStringSubmatch(line)
+				if match == nil {
+					continue
+				}
+				subject, message, fix := sig.explain(match, services)
+				key := sig.id + "\x00" + subject
+				if i, ok := index[key]; ok {
+					report.Matches[i].Count++
+					break
+				}
+				index[key] = len(report.Matches)
+				report.Matches = append(report.Matches, Match{
+					Signature: sig.id,
+					IssueType: sig.issueType,
+					Subject:   subject,
+					Message:   message,
+					Line:      report.Lines,
+					Excerpt:   excerpt(line),
+					Count:     1,
+					Check:     sig.check,
+					Fix:       fix,
+				})
+				break
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the log: %w", err)
+	}
+
+	for i := range report.Matches {
+		applyConfigFix(&report.Matches[i], projectRoot, ecosystems)
+	}
+	return report, nil
+}
+
+// matchConfigSignatures records the first failure signature of the ecosystems' configs a line
+// shows, and reports whether there was one
+func matchConfigSignatures(report *Report, index map[string]int, line string, ecosystems []*detector.DetectedEcosystem) bool {
+	for _, eco := range ecosystems {
+		sig, message, fix := config.MatchFailureSignature(eco.Config.Ecosystem.FailureSignatures, line)
+		if sig == nil {
+			continue
+		}
+		key := eco.ID + "\x00" + sig.Name + "\x00" + message
+		if i, ok := index[key]; ok {
+			report.Matches[i].Count++
+			return true
+		}
+		check := sig.Check
+		if check == "" {
+			check = checkFor(sig.IssueType)
+		}
+		index[key] = len(report.Matches)
+		report.Matches = append(report.Matches, Match{
+			Signature: sig.Name,
+			Ecosystem: eco.ID,
+			IssueType: sig.IssueType,
+			Subject:   message,
+			Message:   message,
+			Line:      report.Lines,
+			Excerpt:   excerpt(line),
+			Count:     1,
+			Check:     check,
+			Fix:       fix,
+		})
+		return true
+	}
+	return false
+}
+
+// applyConfigFix replaces the generic fix of a match with the fix the project's config defines
+// for its issue type, if there is one. Config signatures with a fix of their own keep it.
+func applyConfigFix(match *Match, projectRoot string, ecosystems []*detector.DetectedEcosystem) {
+	if match.Ecosystem != "" && match.Fix != "" {
+		return
+	}
+	for _, eco := range ecosystems {
+		for _, fix := range eco.Config.Ecosystem.Reconciliation.Fixes {
+			if fix.IssueType != match.IssueType || fix.Command == "" {
+				continue
+			}
+			match.Fix = fix.Command
+			if fix.Description != "" {
+				match.Fix += fmt.Sprintf(" (%s)", fix.Description)
+			}
+			match.Fingerprint = reconciler.Fingerprint(projectRoot, fix.IssueType, fix.Command)
+			return
+		}
+	}
+}
+
+// excerpt trims a log line for quoting
+func excerpt(line string) string {
+	line = strings.TrimSpace(line)
+	if len(line) > maxExcerpt {
+		line = strings.ToValidUTF8(line[:maxExcerpt], "") + "…"
+	}
+	return line
+}
@@ -0,0 +1,129 @@
+package triage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/reconciler"
+)
+
+func TestTriage_Signatures(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		issueType string
+		subject   string
+	}{
+		{"spring placeholder", "Caused by: java.lang.IllegalArgumentException: Could not resolve placeholder 'DATABASE_URL' in value \"${DATABASE_URL}\"", reconciler.IssueMissingEnvVar, "DATABASE_URL"},
+		{"python environ", "KeyError: 'API_TOKEN'", reconciler.IssueMissingEnvVar, "API_TOKEN"},
+		{"plain message", "Error: environment variable \"STRIPE_KEY\" is not set", reconciler.IssueMissingEnvVar, "STRIPE_KEY"},
+		{"node", "Error: connect ECONNREFUSED 127.0.0.1:5432", "service_unavailable", "postgres"},
+		{"jdbc", "org.postgresql.util.PSQLException: Connection to localhost:5432 refused.", "service_unavailable", "postgres"},
+		{"go", "dial tcp 127.0.0.1:6379: connect: connection refused", "service_unavailable", "redis"},
+		{"unknown port", "Error: connect ECONNREFUSED 127.0.0.1:4000", "service_unavailable", "port 4000"},
+		{"class version", "java.lang.UnsupportedClassVersionError: com/acme/App has been compiled by a more recent version of the Java Runtime (class file version 65.0), this version of the Java Runtime only recognizes class file versions up to 61.0", IssueVersionTooOld, "java 21"},
+		{"old class version", "Unsupported major.minor version 52.0", IssueVersionTooOld, "java 8"},
+		{"compiler release", "[ERROR] Fatal error compiling: error: release version 21 not supported", IssueVersionTooOld, "java 21"},
+		{"node engine", `error acme@1.0.0: The engine "node" is incompatible with this module. Expected version ">=20". Got "18.19.0"`, IssueVersionTooOld, "node >=20"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Triage(strings.NewReader(tt.line), SourceText, "", nil)
+			require.NoError(t, err)
+			require.Len(t, report.Matches, 1)
+			assert.Equal(t, tt.issueType, report.Matches[0].IssueType)
+			assert.Equal(t, tt.subject, report.Matches[0].Subject)
+			assert.NotEmpty(t, report.Matches[0].Fix)
+		})
+	}
+}
+
+func TestTriage_NoMatch(t *testing.T) {
+	log := "Tests run: 12, Failures: 1\nexpected: <3> but was: <4>\n"
+	report, err := Triage(strings.NewReader(log), SourceText, "", nil)
+	require.NoError(t, err)
+	assert.Empty(t, report.Matches)
+	assert.Equal(t, 2, report.Lines)
+}
+
+func TestTriage_DeduplicatesRepeatedFailures(t *testing.T) {
+	log := strings.Join([]string{
+		"FAIL TestUsers",
+		"  dial tcp 127.0.0.1:5432: connect: connection refused",
+		"FAIL TestOrders",
+		"  dial tcp 127.0.0.1:5432: connect: connection refused",
+		"  dial tcp 127.0.0.1:6379: connect: connection refused",
+	}, "\n")
+	report, err := Triage(strings.NewReader(log), SourceText, "", nil)
+	require.NoError(t, err)
+	require.Len(t, report.Matches, 2)
+	assert.Equal(t, "postgres", report.Matches[0].Subject)
+	assert.Equal(t, 2, report.Matches[0].Line)
+	assert.Equal(t, 2, report.Matches[0].Count)
+	assert.Equal(t, "redis", report.Matches[1].Subject)
+	assert.Equal(t, 1, report.Matches[1].Count)
+}
+
+func TestTriage_ProjectServicesAndFixes(t *testing.T) {
+	eco := &detector.DetectedEcosystem{ID: "java", Config: &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		Infrastructure: config.Infrastructure{Services: []config.Service{{Name: "mysql"}}},
+		Reconciliation: config.Reconciliation{Fixes: []config.Fix{
+			{IssueType: "service_unavailable", Command: "docker compose up -d", Description: "Start the services"},
+		}},
+	}}}
+
+	report, err := Triage(strings.NewReader("Connection to localhost:3306 refused"), "build.log", "/work/app", []*detector.DetectedEcosystem{eco})
+	require.NoError(t, err)
+	require.Len(t, report.Matches, 1)
+	match := report.Matches[0]
+	assert.Equal(t, "mysql", match.Subject, "the declared service is preferred over mariadb")
+	assert.Equal(t, "docker compose up -d (Start the services)", match.Fix)
+	assert.Equal(t, reconciler.Fingerprint("/work/app", "service_unavailable", "docker compose up -d"), match.Fingerprint)
+	assert.Equal(t, []string{"java"}, report.Ecosystems)
+}
+
+func TestServiceOnPort(t *testing.T) {
+	assert.Equal(t, "mariadb", serviceOnPort(3306, nil))
+	assert.Equal(t, "mysql", serviceOnPort(3306, []string{"redis", "mysql"}))
+	assert.Equal(t, "", serviceOnPort(1234, nil))
+}
+
+func TestTriage_ConfigSignatures(t *testing.T) {
+	eco := &detector.DetectedEcosystem{ID: "python", Config: &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		FailureSignatures: []config.FailureSignature{
+			{Name: "module_not_installed", Pattern: `No module named '(?P<module>[\w.]+)'`, IssueType: "missing_dependency", Message: "Python module {module} is not installed", Fix: "pip install -r requirements.txt"},
+			{Name: "no_broker", Pattern: `kombu.*Connection refused`, IssueType: "service_unavailable", Message: "The Celery broker isn't running"},
+		},
+		Reconciliation: config.Reconciliation{Fixes: []config.Fix{{IssueType: "service_unavailable", Command: "docker compose up -d rabbitmq"}}},
+	}}}
+	log := strings.Join([]string{
+		"E   ModuleNotFoundError: No module named 'requests'",
+		"E   ModuleNotFoundError: No module named 'requests'",
+		"kombu.exceptions.OperationalError: [Errno 111] Connection refused",
+		"KeyError: 'SECRET_KEY'",
+	}, "\n")
+
+	report, err := Triage(strings.NewReader(log), SourceText, "/work/app", []*detector.DetectedEcosystem{eco})
+	require.NoError(t, err)
+	require.Len(t, report.Matches, 3)
+
+	module := report.Matches[0]
+	assert.Equal(t, "module_not_installed", module.Signature)
+	assert.Equal(t, "python", module.Ecosystem)
+	assert.Equal(t, "Python module requests is not installed", module.Message)
+	assert.Equal(t, 2, module.Count)
+	assert.Equal(t, "pip install -r requirements.txt", module.Fix, "the signature's own fix is kept")
+	assert.Empty(t, module.Fingerprint)
+
+	broker := report.Matches[1]
+	assert.Equal(t, "docker compose up -d rabbitmq", broker.Fix, "the config's fix is used when the signature has none")
+	assert.Equal(t, "check_infrastructure_parity", broker.Check, "the check of the built-in signatures for the issue type")
+	assert.NotEmpty(t, broker.Fingerprint)
+
+	assert.Equal(t, "missing_env_var", report.Matches[2].Signature, "built-in signatures still apply")
+}
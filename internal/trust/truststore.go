@@ -0,0 +1,221 @@
+package trust
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/runner"
+)
+
+// caFileEnvVars lists environment variables that commonly point at a custom CA bundle,
+// in order of precedence. SENTINEL_CA_CERT lets users declare the corporate CA explicitly.
+var caFileEnvVars = []string{
+	"SENTINEL_CA_CERT",
+	"SSL_CERT_FILE",
+	"REQUESTS_CA_BUNDLE",
+	"NODE_EXTRA_CA_CERTS",
+	"PIP_CERT",
+}
+
+// proxyEnvVars lists environment variables indicating traffic goes through a (possibly TLS-intercepting) proxy
+var proxyEnvVars = []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"}
+
+// CustomCA describes a detected custom CA requirement
+type CustomCA struct {
+	Required    bool
+	Reason      string
+	File        string
+	Source      string // Environment variable the CA file came from
+	Subject     string
+	Fingerprint string // SHA-256, colon-separated uppercase hex (keytool format)
+	Proxy       string // Proxy environment variable set, a hint that a custom CA may be needed
+}
+
+// StoreStatus represents the verification result of a single trust store
+type StoreStatus struct {
+	EcosystemID string
+	Name        string
+	Configured  bool
+	Message     string
+	FixCommand  string
+}
+
+// TrustReport contains certificate and trust store diagnostics
+type TrustReport struct {
+	CustomCA  *CustomCA
+	Stores    []StoreStatus
+	IsHealthy bool
+	Issues    []string
+	Hints     []string // Things worth checking that aren't failures
+}
+
+// DetectCustomCA determines whether the environment needs a custom CA and where it lives
+func DetectCustomCA() *CustomCA {
+	ca := &CustomCA{}
+
+	for _, name := range caFileEnvVars {
+		if path := os.Getenv(name); path != "" {
+			ca.File = path
+			ca.Source = name
+			break
+		}
+	}
+
+	if ca.File != "" {
+		ca.Required = true
+		ca.Reason = fmt.Sprintf("custom CA bundle declared via %s", ca.Source)
+		if subject, fingerprint, err := readCertificate(ca.File); err == nil {
+			ca.Subject = subject
+			ca.Fingerprint = fingerprint
+		}
+		return ca
+	}
+
+	// Most proxies don't intercept TLS, so a proxy alone doesn't make a custom CA required
+	for _, name := range proxyEnvVars {
+		if os.Getenv(name) != "" {
+			ca.Proxy = name
+			return ca
+		}
+	}
+
+	return ca
+}
+
+// CheckTrustStores verifies the custom CA is installed in every trust store the ecosystem declares
+func CheckTrustStores(ctx context.Context, cfg *config.EcosystemConfig, ca *CustomCA) (*TrustReport, error) {
+	report := &TrustReport{
+		CustomCA:  ca,
+		Stores:    []StoreStatus{},
+		IsHealthy: true,
+		Issues:    []string{},
+	}
+
+	if ca == nil || len(cfg.Ecosystem.Trust.Stores) == 0 {
+		return report, nil
+	}
+
+	if !ca.Required {
+		if ca.Proxy != "" {
+			report.Hints = append(report.Hints, fmt.Sprintf("Proxy configured via %s; if it intercepts TLS, set SENTINEL_CA_CERT to your corporate CA bundle to check the trust stores", ca.Proxy))
+		}
+		return report, nil
+	}
+
+	if !common.FileExists(ca.File) {
+		report.IsHealthy = false
+		report.Issues = append(report.Issues, fmt.Sprintf("CA file declared via %s does not exist: %s", ca.Source, ca.File))
+		return report, nil
+	}
+
+	for _, store := range cfg.Ecosystem.Trust.Stores {
+		status := checkStore(ctx, store, ca)
+		status.EcosystemID = cfg.Ecosystem.ID
+		report.Stores = append(report.Stores, status)
+
+		if !status.Configured {
+			report.IsHealthy = false
+			report.Issues = append(report.Issues, fmt.Sprintf("%s: %s", store.Name, status.Message))
+		}
+	}
+
+	return report, nil
+}
+
+// checkStore verifies a single trust store
+func checkStore(ctx context.Context, store config.TrustStore, ca *CustomCA) StoreStatus {
+	status := StoreStatus{
+		Name:       store.Name,
+		FixCommand: strings.ReplaceAll(store.FixCommand, "{ca_file}", ca.File),
+	}
+
+	switch store.Type {
+	case "env_var":
+		value := os.Getenv(store.EnvVar)
+		if value == "" {
+			status.Message = fmt.Sprintf("%s is not set; TLS downloads will not trust the custom CA", store.EnvVar)
+			return status
+		}
+		if !common.FileExists(value) {
+			status.Message = fmt.Sprintf("%s points to a missing file: %s", store.EnvVar, value)
+			return status
+		}
+		status.Configured = true
+		status.Message = fmt.Sprintf("%s is set to %s", store.EnvVar, value)
+	case "command":
+		output, err := runCheckCommand(ctx, store.CheckCommand)
+		if err != nil {
+			status.Message = fmt.Sprintf("trust store check failed: %v", err)
+			return status
+		}
+		if !outputMatches(output, store.Match, ca) {
+			status.Message = "custom CA is not installed"
+			return status
+		}
+		status.Configured = true
+		status.Message = "custom CA is installed"
+	default:
+		status.Message = fmt.Sprintf("unknown trust store type: %s", store.Type)
+	}
+
+	return status
+}
+
+// outputMatches checks whether command output references the custom CA
+func outputMatches(output, match string, ca *CustomCA) bool {
+	if match == "path" {
+		return strings.Contains(output, ca.File)
+	}
+	if ca.Fingerprint == "" {
+		return false
+	}
+	normalized := strings.ToUpper(strings.ReplaceAll(output, " ", ""))
+	return strings.Contains(normalized, ca.Fingerprint) ||
+		strings.Contains(normalized, strings.ReplaceAll(ca.Fingerprint, ":", ""))
+}
+
+// runCheckCommand executes a trust store check command with a timeout
+func runCheckCommand(ctx context.Context, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	output, err := runner.Run(ctx, "", command)
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// readCertificate parses the first PEM certificate in a file and returns its subject and SHA-256 fingerprint
+func readCertificate(path string) (string, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return "", "", fmt.Errorf("no PEM certificate found in %s", path)
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	sum := sha256.Sum256(cert.Raw)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+
+	return cert.Subject.CommonName, strings.Join(parts, ":"), nil
+}
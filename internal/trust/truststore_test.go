@@ -0,0 +1,195 @@
+package trust
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCA writes a self-signed CA certificate to a temp file
+func writeTestCA(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Corp Proxy CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "corp-ca.pem")
+	err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	require.NoError(t, err)
+	return path
+}
+
+// clearCAEnv unsets all CA/proxy variables for the duration of a test
+func clearCAEnv(t *testing.T) {
+	for _, name := range append(caFileEnvVars, proxyEnvVars...) {
+		t.Setenv(name, "")
+	}
+}
+
+func TestDetectCustomCA_None(t *testing.T) {
+	clearCAEnv(t)
+
+	ca := DetectCustomCA()
+	assert.False(t, ca.Required)
+}
+
+func TestDetectCustomCA_ProxyOnly(t *testing.T) {
+	clearCAEnv(t)
+	t.Setenv("HTTPS_PROXY", "http://proxy.corp:3128")
+
+	ca := DetectCustomCA()
+	assert.False(t, ca.Required, "a proxy alone is only a hint")
+	assert.Empty(t, ca.File)
+	assert.Equal(t, "HTTPS_PROXY", ca.Proxy)
+}
+
+func TestDetectCustomCA_FromFile(t *testing.T) {
+	clearCAEnv(t)
+	caFile := writeTestCA(t)
+	t.Setenv("SENTINEL_CA_CERT", caFile)
+
+	ca := DetectCustomCA()
+	assert.True(t, ca.Required)
+	assert.Equal(t, caFile, ca.File)
+	assert.Equal(t, "SENTINEL_CA_CERT", ca.Source)
+	assert.Equal(t, "Corp Proxy CA", ca.Subject)
+	assert.Len(t, ca.Fingerprint, 95) // 32 bytes as colon-separated hex
+}
+
+func TestCheckTrustStores(t *testing.T) {
+	caFile := writeTestCA(t)
+	cfg := &config.EcosystemConfig{
+		Ecosystem: config.Ecosystem{
+			ID: "javascript",
+			Trust: config.Trust{
+				Stores: []config.TrustStore{
+					{
+						Name:       "node",
+						Type:       "env_var",
+						EnvVar:     "NODE_EXTRA_CA_CERTS",
+						FixCommand: "export NODE_EXTRA_CA_CERTS={ca_file}",
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		nodeCACerts   string
+		expectHealthy bool
+	}{
+		{"store missing", "", false},
+		{"store points to missing file", filepath.Join(t.TempDir(), "nope.pem"), false},
+		{"store configured", caFile, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearCAEnv(t)
+			t.Setenv("SENTINEL_CA_CERT", caFile)
+			t.Setenv("NODE_EXTRA_CA_CERTS", tt.nodeCACerts)
+
+			report, err := CheckTrustStores(context.Background(), cfg, DetectCustomCA())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectHealthy, report.IsHealthy)
+			require.Len(t, report.Stores, 1)
+			assert.Equal(t, "javascript", report.Stores[0].EcosystemID)
+			if !tt.expectHealthy {
+				assert.Contains(t, report.Stores[0].FixCommand, caFile)
+			}
+		})
+	}
+}
+
+func TestCheckTrustStores_NotRequired(t *testing.T) {
+	cfg := &config.EcosystemConfig{}
+
+	report, err := CheckTrustStores(context.Background(), cfg, &CustomCA{})
+	require.NoError(t, err)
+	assert.True(t, report.IsHealthy)
+	assert.Empty(t, report.Stores)
+}
+
+func TestCheckTrustStores_ProxyWithoutCAFile(t *testing.T) {
+	ca := &CustomCA{Proxy: "HTTPS_PROXY"}
+
+	// Without trust stores there's nothing to check
+	report, err := CheckTrustStores(context.Background(), &config.EcosystemConfig{}, ca)
+	require.NoError(t, err)
+	assert.True(t, report.IsHealthy)
+	assert.Empty(t, report.Hints)
+
+	cfg := &config.EcosystemConfig{Ecosystem: config.Ecosystem{Trust: config.Trust{
+		Stores: []config.TrustStore{{Name: "node", Type: "env_var", EnvVar: "NODE_EXTRA_CA_CERTS"}},
+	}}}
+	report, err = CheckTrustStores(context.Background(), cfg, ca)
+	require.NoError(t, err)
+	assert.True(t, report.IsHealthy, "a proxy is a hint, not a failure")
+	assert.Empty(t, report.Issues)
+	require.Len(t, report.Hints, 1)
+	assert.Contains(t, report.Hints[0], "SENTINEL_CA_CERT")
+}
+
+func TestCheckTrustStores_CommandFingerprint(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows - requires sh")
+	}
+
+	caFile := writeTestCA(t)
+	clearCAEnv(t)
+	t.Setenv("SENTINEL_CA_CERT", caFile)
+	ca := DetectCustomCA()
+
+	tests := []struct {
+		name          string
+		command       string
+		expectHealthy bool
+	}{
+		{"fingerprint present", "echo 'Certificate fingerprint (SHA-256): " + ca.Fingerprint + "'", true},
+		{"fingerprint absent", "echo 'Certificate fingerprint (SHA-256): 00:11'", false},
+		{"command fails", "exit 1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.EcosystemConfig{
+				Ecosystem: config.Ecosystem{
+					ID: "java",
+					Trust: config.Trust{
+						Stores: []config.TrustStore{
+							{Name: "java_cacerts", Type: "command", CheckCommand: tt.command, Match: "fingerprint"},
+						},
+					},
+				},
+			}
+
+			report, err := CheckTrustStores(context.Background(), cfg, ca)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectHealthy, report.IsHealthy)
+		})
+	}
+}
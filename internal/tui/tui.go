@@ -0,0 +1,390 @@
+// Package tui presents the health of a project in an interactive terminal UI: the checks
+// with their findings, and the fixes that can be run, re-checked as the project changes.
+//
+// The UI follows the model/update/view pattern: key presses and finished work arrive as
+// messages, Update changes the model and returns the work to start, and View renders it.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/i18n"
+	"dev-env-sentinel/internal/report"
+)
+
+// Check is the result of one check
+type Check struct {
+	Name     string
+	Healthy  bool
+	Error    string // The check could not run
+	Findings []report.Finding
+}
+
+// Fix is a fix for an issue of the project
+type Fix struct {
+	IssueType   string
+	Command     string
+	Fingerprint string
+	Message     string
+	Runnable    bool // The fix can be run from the UI; otherwise Message says what to do
+}
+
+// Backend runs the checks and fixes the UI shows
+type Backend interface {
+	// Check runs the checks
+	Check(ctx context.Context) []Check
+	// Fixes plans the fixes for the current issues without running them. When fixes can't be
+	// listed, e.g. because they are a Pro feature, it returns why.
+	Fixes(ctx context.Context) ([]Fix, string)
+	// Fix runs the fix with a fingerprint and describes the outcome
+	Fix(ctx context.Context, fingerprint string) (string, error)
+}
+
+// Msg is an event the model reacts to
+type Msg interface{}
+
+// KeyMsg is a key press, e.g. "q", "up" or "enter"
+type KeyMsg string
+
+// TickMsg asks for a periodic re-check
+type TickMsg struct{}
+
+// checkedMsg carries the results of a check run
+type checkedMsg struct {
+	checks  []Check
+	fixes   []Fix
+	fixNote string
+	at      time.Time
+}
+
+// fixedMsg carries the outcome of a fix
+type fixedMsg struct {
+	message string
+	err     error
+}
+
+// Cmd is work started by Update; its message is passed to Update when it is done
+type Cmd func(ctx context.Context) Msg
+
+// Model is the state of the UI
+type Model struct {
+	backend   Backend
+	title     string
+	checks    []Check
+	fixes     []Fix
+	fixNote   string
+	expanded  map[string]bool
+	cursor    int  // Selected row: the checks, then the fixes
+	confirm   *Fix // Fix awaiting confirmation
+	busy      string
+	status    string
+	checkedAt time.Time
+	quitting  bool
+}
+
+// NewModel creates the model of a UI titled with the project it checks
+func NewModel(backend Backend, title string) *Model {
+	return &Model{backend: backend, title: title, expanded: make(map[string]bool)}
+}
+
+// Init returns the first check run
+func (m *Model) Init() Cmd {
+	m.busy = "Checking..."
+	return m.check
+}
+
+// Quitting reports whether the user asked to quit
+func (m *Model) Quitting() bool {
+	return m.quitting
+}
+
+// check runs the checks and plans the fixes
+func (m *Model) check(ctx context.Context) Msg {
+	checks := m.backend.Check(ctx)
+	fixes, note := m.backend.Fixes(ctx)
+	return checkedMsg{checks: checks, fixes: fixes, fixNote: note, at: time.Now()}
+}
+
+// fix returns the work running a fix
+func (m *Model) fix(fingerprint string) Cmd {
+	return func(ctx context.Context) Msg {
+		message, err := m.backend.Fix(ctx, fingerprint)
+		return fixedMsg{message: message, err: err}
+	}
+}
+
+// rows is the number of selectable rows
+func (m *Model) rows() int {
+	return len(m.checks) + len(m.fixes)
+}
+
+// Update applies a message to the model and returns the work to start, or nil
+func (m *Model) Update(msg Msg) Cmd {
+	switch msg := msg.(type) {
+	case KeyMsg:
+		return m.key(string(msg))
+	case checkedMsg:
+		m.busy = ""
+		m.checks, m.fixes, m.fixNote, m.checkedAt = msg.checks, msg.fixes, msg.fixNote, msg.at
+		if m.cursor >= m.rows() {
+			m.cursor = m.rows() - 1
+		}
+		if m.cursor < 0 {
+			m.cursor = 0
+		}
+	case fixedMsg:
+		if msg.err != nil {
+			m.status = "Fix failed: " + msg.err.Error()
+		} else {
+			m.status = msg.message
+		}
+		m.busy = "Checking..."
+		return m.check
+	case TickMsg:
+		if m.busy == "" && m.confirm == nil {
+			m.busy = "Checking..."
+			return m.check
+		}
+	}
+	return nil
+}
+
+// key handles a key press
+func (m *Model) key(key string) Cmd {
+	if key == "ctrl+c" {
+		m.quitting = true
+		return nil
+	}
+	if m.confirm != nil {
+		fix := m.confirm
+		m.confirm = nil
+		if key != "y" && key != "Y" {
+			m.status = "Fix not run"
+			return nil
+		}
+		m.status = ""
+		m.busy = "Running " + fix.Command + "..."
+		return m.fix(fix.Fingerprint)
+	}
+
+	switch key {
+	case "q", "esc":
+		m.quitting = true
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < m.rows()-1 {
+			m.cursor++
+		}
+	case "enter", " ":
+		if m.cursor < len(m.checks) {
+			name := m.checks[m.cursor].Name
+			m.expanded[name] = !m.expanded[name]
+		}
+	case "f":
+		if m.busy != "" || m.cursor < len(m.checks) || m.cursor >= m.rows() {
+			return nil
+		}
+		fix := m.fixes[m.cursor-len(m.checks)]
+		if !fix.Runnable {
+			m.status = "This fix can't be run from here: " + fix.Message
+			return nil
+		}
+		m.confirm = &fix
+	case "r":
+		if m.busy == "" {
+			m.status = ""
+			m.busy = "Checking..."
+			return m.check
+		}
+	}
+	return nil
+}
+
+// View renders the model
+func (m *Model) View() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "sentinel: %s\n", m.title)
+	if !m.checkedAt.IsZero() {
+		fmt.Fprintf(&b, "Checked at %s\n", m.checkedAt.Format("15:04:05"))
+	}
+	b.WriteString("\n")
+
+	for i, check := range m.checks {
+		marker := "✅"
+		if !check.Healthy {
+			marker = "❌"
+		}
+		arrow := "+"
+		if m.expanded[check.Name] {
+			arrow = "-"
+		}
+		line := fmt.Sprintf("%s %s %s", arrow, marker, check.Name)
+		if len(check.Findings) > 0 {
+			line += fmt.Sprintf(" (%d)", len(check.Findings))
+		}
+		b.WriteString(m.row(i, line))
+		if !m.expanded[check.Name] {
+			continue
+		}
+		if check.Error != "" {
+			fmt.Fprintf(&b, "      %s\n", check.Error)
+		}
+		for _, f := range check.Findings {
+			fmt.Fprintf(&b, "      %-7s %s", f.Severity, f.Message)
+			if f.File != "" {
+				fmt.Fprintf(&b, " (%s)", f.File)
+			}
+			b.WriteString("\n")
+		}
+		if check.Error == "" && len(check.Findings) == 0 {
+			b.WriteString("      No issues\n")
+		}
+	}
+
+	b.WriteString("\nFixes\n")
+	switch {
+	case m.fixNote != "":
+		fmt.Fprintf(&b, "  %s\n", m.fixNote)
+	case len(m.fixes) == 0 && m.busy == "":
+		b.WriteString("  No fixes to run\n")
+	}
+	for i, fix := range m.fixes {
+		line := fix.IssueType + ": "
+		if fix.Runnable {
+			line += fix.Command
+		} else {
+			line += fix.Message
+		}
+		b.WriteString(m.row(len(m.checks)+i, line))
+	}
+
+	b.WriteString("\n")
+	switch {
+	case m.confirm != nil:
+		fmt.Fprintf(&b, "Run %s? [y/N]\n", m.confirm.Command)
+	case m.busy != "":
+		b.WriteString(m.busy + "\n")
+	case m.status != "":
+		b.WriteString(m.status + "\n")
+	default:
+		b.WriteString("\n")
+	}
+	b.WriteString("j/k move, enter expand, f fix, r re-check, q quit\n")
+	return b.String()
+}
+
+// row renders a selectable row, highlighting the selected one
+func (m *Model) row(i int, line string) string {
+	if i == m.cursor {
+		return "> " + line + "\n"
+	}
+	return "  " + line + "\n"
+}
+
+// Run shows the UI until the user quits. Keys are read from in, which should be a terminal in
+// raw mode; with an interval above zero the project is re-checked periodically.
+func Run(ctx context.Context, model *Model, in io.Reader, out io.Writer, symbols i18n.SymbolSet, interval time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	msgs := make(chan Msg)
+	send := func(msg Msg) {
+		select {
+		case msgs <- msg:
+		case <-ctx.Done():
+		}
+	}
+	start := func(cmd Cmd) {
+		if cmd != nil {
+			go func() { send(cmd(ctx)) }()
+		}
+	}
+
+	readErr := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := in.Read(buf)
+			for _, key := range ParseKeys(buf[:n]) {
+				send(KeyMsg(key))
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		go func() {
+			for {
+				select {
+				case <-ticker.C:
+					send(TickMsg{})
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	// Alternate screen, hidden cursor; both restored on exit
+	io.WriteString(out, "\x1b[?1049h\x1b[?25l")
+	defer io.WriteString(out, "\x1b[?25h\x1b[?1049l")
+
+	start(model.Init())
+	for {
+		view := symbols.Apply(model.View())
+		if _, err := io.WriteString(out, "\x1b[H\x1b[2J"+strings.ReplaceAll(view, "\n", "\r\n")); err != nil {
+			return err
+		}
+		select {
+		case msg := <-msgs:
+			start(model.Update(msg))
+			if model.Quitting() {
+				return nil
+			}
+		case err := <-readErr:
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// ParseKeys turns the bytes read from a raw terminal into key names
+func ParseKeys(b []byte) []string {
+	var keys []string
+	for i := 0; i < len(b); i++ {
+		switch c := b[i]; {
+		case c == 0x1b && i+2 < len(b) && b[i+1] == '[':
+			switch b[i+2] {
+			case 'A':
+				keys = append(keys, "up")
+			case 'B':
+				keys = append(keys, "down")
+			}
+			i += 2
+		case c == 0x1b:
+			keys = append(keys, "esc")
+		case c == 3:
+			keys = append(keys, "ctrl+c")
+		case c == '\r' || c == '\n':
+			keys = append(keys, "enter")
+		default:
+			keys = append(keys, string(rune(c)))
+		}
+	}
+	return keys
+}
@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/i18n"
+	"dev-env-sentinel/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackend reports an unhealthy check with a fix until the fix has run
+type fakeBackend struct {
+	fixed  []string
+	checks atomic.Int32
+	fail   bool
+}
+
+func (b *fakeBackend) Check(ctx context.Context) []Check {
+	b.checks.Add(1)
+	if len(b.fixed) > 0 {
+		return []Check{{Name: "verify_build_freshness", Healthy: true}, {Name: "env_var_audit", Healthy: true}}
+	}
+	return []Check{
+		{Name: "verify_build_freshness", Findings: []report.Finding{{Check: "verify_build_freshness", Severity: report.SeverityError, Message: "src/A.java is newer than target/A.class", File: "src/A.java"}}},
+		{Name: "env_var_audit", Healthy: true},
+	}
+}
+
+func (b *fakeBackend) Fixes(ctx context.Context) ([]Fix, string) {
+	if len(b.fixed) > 0 {
+		return nil, ""
+	}
+	return []Fix{
+		{IssueType: "stale_build", Command: "mvn compile", Fingerprint: "abc123", Runnable: true},
+		{IssueType: "missing_env_var", Message: "API_KEY looks like a secret; add it to .env yourself"},
+	}, ""
+}
+
+func (b *fakeBackend) Fix(ctx context.Context, fingerprint string) (string, error) {
+	if b.fail {
+		return "", errors.New("mvn: command not found")
+	}
+	b.fixed = append(b.fixed, fingerprint)
+	return "Fixed 1 issue(s)", nil
+}
+
+// run applies a message and runs the work it starts, like Run does
+func run(m *Model, msg Msg) {
+	for cmd := m.Update(msg); cmd != nil; {
+		cmd = m.Update(cmd(context.Background()))
+	}
+}
+
+func TestModel_ExpandAndFix(t *testing.T) {
+	backend := &fakeBackend{}
+	m := NewModel(backend, "/work/api")
+	run(m, m.Init()(context.Background()))
+
+	view := m.View()
+	assert.Contains(t, view, "> + ❌ verify_build_freshness (1)")
+	assert.NotContains(t, view, "src/A.java is newer")
+
+	run(m, KeyMsg("enter"))
+	assert.Contains(t, m.View(), "error   src/A.java is newer than target/A.class (src/A.java)")
+
+	// Fixes are listed after the checks; f asks before running one
+	run(m, KeyMsg("down"))
+	run(m, KeyMsg("down"))
+	run(m, KeyMsg("f"))
+	assert.Contains(t, m.View(), "Run mvn compile? [y/N]")
+	assert.Empty(t, backend.fixed)
+
+	run(m, KeyMsg("y"))
+	assert.Equal(t, []string{"abc123"}, backend.fixed)
+	view = m.View()
+	assert.Contains(t, view, "Fixed 1 issue(s)")
+	assert.Contains(t, view, "✅ verify_build_freshness")
+	assert.Contains(t, view, "No fixes to run")
+	assert.EqualValues(t, 2, backend.checks.Load(), "the project is re-checked after a fix")
+}
+
+func TestModel_ConfirmDeclinedAndManualFixes(t *testing.T) {
+	backend := &fakeBackend{fail: true}
+	m := NewModel(backend, "/work/api")
+	run(m, m.Init()(context.Background()))
+
+	for i := 0; i < 10; i++ {
+		run(m, KeyMsg("j"))
+	}
+	run(m, KeyMsg("f"))
+	assert.Contains(t, m.View(), "can't be run from here: API_KEY looks like a secret")
+
+	run(m, KeyMsg("k"))
+	run(m, KeyMsg("f"))
+	run(m, KeyMsg("n"))
+	assert.Contains(t, m.View(), "Fix not run")
+
+	run(m, KeyMsg("f"))
+	run(m, KeyMsg("y"))
+	assert.Contains(t, m.View(), "Fix failed: mvn: command not found")
+
+	run(m, KeyMsg("q"))
+	assert.True(t, m.Quitting())
+}
+
+func TestParseKeys(t *testing.T) {
+	assert.Equal(t, []string{"up", "down", "enter", "q", "ctrl+c", "esc"}, ParseKeys([]byte("\x1b[A\x1b[B\rq\x03\x1b")))
+}
+
+func TestRun(t *testing.T) {
+	backend := &fakeBackend{}
+	keys, input := io.Pipe()
+	var out bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(context.Background(), NewModel(backend, "/work/api"), keys, &out, i18n.SymbolsASCII, time.Hour)
+	}()
+
+	// Wait for the first check before quitting
+	require.Eventually(t, func() bool { _, err := input.Write([]byte("r")); return err == nil && backend.checks.Load() >= 2 }, 5*time.Second, 10*time.Millisecond)
+	input.Write([]byte("q"))
+	require.NoError(t, <-done)
+	assert.Contains(t, out.String(), "[FAIL] verify_build_freshness")
+	assert.True(t, strings.HasSuffix(out.String(), "\x1b[?25h\x1b[?1049l"), "the screen is restored")
+}
@@ -0,0 +1,418 @@
+package verifier
+
+import (
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+)
+
+// maxListedArtifacts limits the artifacts named in a cache issue message
+const maxListedArtifacts = 5
+
+// cachedArtifact is a dependency and where the local cache keeps it
+type cachedArtifact struct {
+	Name     string // e.g. org.slf4j:slf4j-api:2.0.9 or lodash@4.17.21
+	Path     string // Relative to the cache directory
+	Checksum func(path string) (bool, error)
+}
+
+// cacheProblem is a cached dependency that is absent or can't be used
+type cacheProblem struct {
+	Name   string
+	Reason string // Empty for a missing artifact
+}
+
+// verifyCache checks that the dependencies of a manifest or lockfile are in the local dependency
+// cache and intact. Missing ones are reported as missing_cache_artifacts, corrupted ones and
+// failed downloads as stale_cache.
+func verifyCache(cmd config.VerificationCommand, projectRoot string, ecosystem *detector.DetectedEcosystem) ([]Issue, error) {
+	// The cache of a project on a remote dev server is in that machine's home, which isn't mounted
+	if common.IsMounted(projectRoot) {
+		return nil, nil
+	}
+	cfg := ecosystem.Config
+	source := cmd.Source
+	if source == "" {
+		source = cfg.Ecosystem.Dependencies.LockFile
+	}
+	if source == "" {
+		source = cfg.Ecosystem.Manifest.PrimaryFile
+	}
+	sourcePath := filepath.Join(projectRoot, common.ExpandPattern(source))
+	if !common.FileExists(sourcePath) {
+		return nil, fmt.Errorf("source file not found: %s", sourcePath)
+	}
+
+	artifacts, err := dependencyArtifacts(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+	if pattern := cfg.Ecosystem.Cache.ArtifactPattern; pattern != "" {
+		if re, err := regexp.Compile(pattern); err == nil {
+			artifacts = filterArtifacts(artifacts, re)
+		}
+	}
+	if len(artifacts) == 0 {
+		return nil, nil
+	}
+
+	cacheDir := findCacheDir(cfg.Ecosystem.Cache.Locations)
+	if cacheDir == "" {
+		return []Issue{{
+			Type:         "missing_cache_artifacts",
+			Severity:     "warning",
+			Message:      fmt.Sprintf("No dependency cache found for %s (looked in %s)", source, strings.Join(cfg.Ecosystem.Cache.Locations, ", ")),
+			File:         source,
+			FixAvailable: getFixCommand(ecosystem, "missing_cache_artifacts") != "",
+			FixCommand:   getFixCommand(ecosystem, "missing_cache_artifacts"),
+		}}, nil
+	}
+
+	var missing, corrupted []cacheProblem
+	for _, artifact := range artifacts {
+		path := filepath.Join(cacheDir, artifact.Path)
+		problem := checkCachedArtifact(path, artifact)
+		switch {
+		case problem == nil:
+		case problem.Reason == "":
+			missing = append(missing, *problem)
+		default:
+			corrupted = append(corrupted, *problem)
+		}
+	}
+
+	var issues []Issue
+	if len(corrupted) > 0 {
+		issues = append(issues, Issue{
+			Type:         "stale_cache",
+			Severity:     "error",
+			Message:      fmt.Sprintf("%d of %d dependencies of %s are corrupted in the local cache %s: %s", len(corrupted), len(artifacts), source, cacheDir, listProblems(corrupted)),
+			File:         source,
+			FixAvailable: getFixCommand(ecosystem, "stale_cache") != "",
+			FixCommand:   getFixCommand(ecosystem, "stale_cache"),
+		})
+	}
+	if len(missing) > 0 {
+		issues = append(issues, Issue{
+			Type:         "missing_cache_artifacts",
+			Severity:     "warning",
+			Message:      fmt.Sprintf("%d of %d dependencies of %s are not in the local cache %s: %s", len(missing), len(artifacts), source, cacheDir, listProblems(missing)),
+			File:         source,
+			FixAvailable: getFixCommand(ecosystem, "missing_cache_artifacts") != "",
+			FixCommand:   getFixCommand(ecosystem, "missing_cache_artifacts"),
+		})
+	}
+	return issues, nil
+}
+
+// checkCachedArtifact checks that an artifact is cached, non-empty and matches its checksum
+func checkCachedArtifact(path string, artifact cachedArtifact) *cacheProblem {
+	info, err := os.Stat(path)
+	if err != nil {
+		// Maven leaves a marker after a failed download and won't retry until it expires
+		if common.FileExists(path + ".lastUpdated") {
+			return &cacheProblem{Name: artifact.Name, Reason: "failed download"}
+		}
+		return &cacheProblem{Name: artifact.Name}
+	}
+	if info.Size() == 0 {
+		return &cacheProblem{Name: artifact.Name, Reason: "empty file"}
+	}
+	if artifact.Checksum != nil {
+		if ok, err := artifact.Checksum(path); err == nil && !ok {
+			return &cacheProblem{Name: artifact.Name, Reason: "checksum mismatch"}
+		}
+	}
+	return nil
+}
+
+// listProblems names the first few problem artifacts
+func listProblems(problems []cacheProblem) string {
+	names := make([]string, 0, maxListedArtifacts)
+	for i, p := range problems {
+		if i == maxListedArtifacts {
+			names = append(names, fmt.Sprintf("and %d more", len(problems)-maxListedArtifacts))
+			break
+		}
+		if p.Reason != "" {
+			names = append(names, fmt.Sprintf("%s (%s)", p.Name, p.Reason))
+		} else {
+			names = append(names, p.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// findCacheDir returns the first cache location that exists. Locations using an unset
+// environment variable, such as ${APPDATA} outside Windows, are skipped.
+func findCacheDir(locations []string) string {
+	for _, location := range locations {
+		unset := false
+		dir := os.Expand(location, func(name string) string {
+			value := os.Getenv(name)
+			if value == "" {
+				unset = true
+			}
+			return value
+		})
+		if !unset && filepath.IsAbs(dir) && common.DirExists(dir) {
+			return dir
+		}
+	}
+	return ""
+}
+
+// filterArtifacts keeps the artifacts whose cache path matches the config's artifact pattern
+func filterArtifacts(artifacts []cachedArtifact, pattern *regexp.Regexp) []cachedArtifact {
+	var kept []cachedArtifact
+	for _, artifact := range artifacts {
+		if pattern.MatchString(filepath.ToSlash(artifact.Path)) {
+			kept = append(kept, artifact)
+		}
+	}
+	return kept
+}
+
+// dependencyArtifacts lists the cached artifacts a manifest or lockfile depends on
+func dependencyArtifacts(path string) ([]cachedArtifact, error) {
+	switch filepath.Base(path) {
+	case "pom.xml":
+		return mavenArtifacts(path)
+	case "package-lock.json":
+		return npmArtifacts(path)
+	default:
+		return nil, fmt.Errorf("cache check does not support %s", filepath.Base(path))
+	}
+}
+
+// pomProject is the part of a pom.xml the cache check reads
+type pomProject struct {
+	GroupID string `xml:"groupId"`
+	Version string `xml:"version"`
+	Parent  struct {
+		GroupID string `xml:"groupId"`
+		Version string `xml:"version"`
+	} `xml:"parent"`
+	Properties struct {
+		Entries []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"properties"`
+	Dependencies []struct {
+		GroupID    string `xml:"groupId"`
+		ArtifactID string `xml:"artifactId"`
+		Version    string `xml:"version"`
+		Type       string `xml:"type"`
+		Classifier string `xml:"classifier"`
+		Scope      string `xml:"scope"`
+	} `xml:"dependencies>dependency"`
+}
+
+// pomPropertyPattern matches ${property} references in a pom.xml
+var pomPropertyPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// mavenArtifacts lists the dependencies of a pom.xml in the local repository layout. Versions
+// managed elsewhere (a parent or BOM) or using unknown properties are skipped.
+func mavenArtifacts(path string) ([]cachedArtifact, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pom pomProject
+	if err := xml.Unmarshal(data, &pom); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	properties := map[string]string{}
+	for _, entry := range pom.Properties.Entries {
+		properties[entry.XMLName.Local] = strings.TrimSpace(entry.Value)
+	}
+	properties["project.version"] = firstNonEmpty(pom.Version, pom.Parent.Version)
+	properties["project.groupId"] = firstNonEmpty(pom.GroupID, pom.Parent.GroupID)
+	resolve := func(value string) string {
+		return pomPropertyPattern.ReplaceAllStringFunc(strings.TrimSpace(value), func(ref string) string {
+			if v, ok := properties[ref[2:len(ref)-1]]; ok {
+				return v
+			}
+			return ref
+		})
+	}
+
+	var artifacts []cachedArtifact
+	for _, dep := range pom.Dependencies {
+		groupID, artifactID, version := resolve(dep.GroupID), resolve(dep.ArtifactID), resolve(dep.Version)
+		if dep.Scope == "system" || dep.Scope == "import" || version == "" || strings.Contains(groupID+artifactID+version, "${") {
+			continue
+		}
+		ext := firstNonEmpty(dep.Type, "jar")
+		file := artifactID + "-" + version
+		if dep.Classifier != "" {
+			file += "-" + dep.Classifier
+		}
+		file += "." + ext
+		artifacts = append(artifacts, cachedArtifact{
+			Name:     groupID + ":" + artifactID + ":" + version,
+			Path:     filepath.Join(filepath.Join(strings.Split(groupID, ".")...), artifactID, version, file),
+			Checksum: mavenChecksum,
+		})
+	}
+	return artifacts, nil
+}
+
+// mavenChecksum compares an artifact with the .sha1 file Maven downloads next to it, if any
+func mavenChecksum(path string) (bool, error) {
+	data, err := os.ReadFile(path + ".sha1")
+	if err != nil {
+		return true, nil
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return true, nil
+	}
+	sum, err := hashOf(path, sha1.New())
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(hex.EncodeToString(sum), fields[0]), nil
+}
+
+// packageLock is the part of a package-lock.json the cache check reads
+type packageLock struct {
+	Packages map[string]struct {
+		Version   string `json:"version"`
+		Integrity string `json:"integrity"`
+		Link      bool   `json:"link"`
+	} `json:"packages"`
+	Dependencies map[string]lockDependency `json:"dependencies"` // lockfileVersion 1
+}
+
+// lockDependency is a dependency of a version 1 package-lock.json
+type lockDependency struct {
+	Version      string                    `json:"version"`
+	Integrity    string                    `json:"integrity"`
+	Dependencies map[string]lockDependency `json:"dependencies"`
+}
+
+// npmArtifacts lists the packages of a package-lock.json in the npm cache's content-addressed
+// layout, _cacache/content-v2/<algorithm>/<hex digest split 2/2/rest>
+func npmArtifacts(path string) ([]cachedArtifact, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var lock packageLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	integrities := map[string]string{}
+	for key, pkg := range lock.Packages {
+		if key == "" || pkg.Link || pkg.Integrity == "" {
+			continue
+		}
+		name := key[strings.LastIndex(key, "node_modules/")+len("node_modules/"):]
+		integrities[name+"@"+pkg.Version] = pkg.Integrity
+	}
+	if len(lock.Packages) == 0 {
+		collectLockDependencies(lock.Dependencies, integrities)
+	}
+
+	names := make([]string, 0, len(integrities))
+	for name := range integrities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var artifacts []cachedArtifact
+	for _, name := range names {
+		algorithm, digest, ok := parseIntegrity(integrities[name])
+		if !ok || len(digest) < 5 {
+			continue
+		}
+		expected := digest
+		artifacts = append(artifacts, cachedArtifact{
+			Name: name,
+			Path: filepath.Join("_cacache", "content-v2", algorithm, digest[:2], digest[2:4], digest[4:]),
+			Checksum: func(path string) (bool, error) {
+				h := sha512.New()
+				if algorithm == "sha1" {
+					h = sha1.New()
+				}
+				sum, err := hashOf(path, h)
+				return hex.EncodeToString(sum) == expected, err
+			},
+		})
+	}
+	return artifacts, nil
+}
+
+// collectLockDependencies collects the integrities of a version 1 lockfile's dependency tree
+func collectLockDependencies(deps map[string]lockDependency, integrities map[string]string) {
+	for name, dep := range deps {
+		if dep.Integrity != "" {
+			integrities[name+"@"+dep.Version] = dep.Integrity
+		}
+		collectLockDependencies(dep.Dependencies, integrities)
+	}
+}
+
+// parseIntegrity reads a Subresource Integrity string, preferring sha512, into an algorithm
+// and hex digest
+func parseIntegrity(integrity string) (string, string, bool) {
+	var algorithm, digest string
+	for _, entry := range strings.Fields(integrity) {
+		i := strings.IndexByte(entry, '-')
+		if i < 0 {
+			continue
+		}
+		algo := entry[:i]
+		if algo != "sha512" && algo != "sha1" || algorithm == "sha512" {
+			continue
+		}
+		sum, err := base64.StdEncoding.DecodeString(entry[i+1:])
+		if err != nil {
+			continue
+		}
+		algorithm, digest = algo, hex.EncodeToString(sum)
+	}
+	return algorithm, digest, algorithm != ""
+}
+
+// hashOf hashes a file's contents
+func hashOf(path string, h hash.Hash) ([]byte, error) {
+	f, err := common.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// firstNonEmpty returns the first of its arguments that isn't empty
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package verifier
+
+import (
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPom = `<project>
+  <groupId>com.example</groupId>
+  <artifactId>app</artifactId>
+  <version>1.0.0</version>
+  <properties>
+    <slf4j.version>2.0.9</slf4j.version>
+  </properties>
+  <dependencyManagement>
+    <dependencies>
+      <dependency><groupId>org.managed</groupId><artifactId>bom</artifactId><version>1.0</version></dependency>
+    </dependencies>
+  </dependencyManagement>
+  <dependencies>
+    <dependency><groupId>org.slf4j</groupId><artifactId>slf4j-api</artifactId><version>${slf4j.version}</version></dependency>
+    <dependency><groupId>com.google.guava</groupId><artifactId>guava</artifactId><version>33.0.0-jre</version></dependency>
+    <dependency><groupId>junit</groupId><artifactId>junit</artifactId><version>4.13.2</version><scope>test</scope></dependency>
+    <dependency><groupId>org.parent</groupId><artifactId>managed</artifactId></dependency>
+    <dependency><groupId>com.example</groupId><artifactId>parent-pom</artifactId><version>${project.version}</version><type>pom</type></dependency>
+  </dependencies>
+</project>`
+
+func mavenEcosystem(t *testing.T, repo string) *detector.DetectedEcosystem {
+	return &detector.DetectedEcosystem{ID: "java-maven", Config: &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		Cache: config.Cache{Locations: []string{"${SENTINEL_TEST_UNSET}/repository", repo}, ArtifactPattern: `.*\.jar$`},
+		Reconciliation: config.Reconciliation{Fixes: []config.Fix{
+			{IssueType: "stale_cache", Command: "mvn clean"},
+			{IssueType: "missing_cache_artifacts", Command: "mvn dependency:go-offline"},
+		}},
+	}}}
+}
+
+func writeCached(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestVerifyCache_Maven(t *testing.T) {
+	projectRoot := t.TempDir()
+	repo := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectRoot, "pom.xml"), []byte(testPom), 0644))
+	cmd := config.VerificationCommand{Name: "cache", Type: "cache_check", Source: "pom.xml"}
+
+	// An intact cache, with a matching checksum
+	slf4j := filepath.Join(repo, "org", "slf4j", "slf4j-api", "2.0.9", "slf4j-api-2.0.9.jar")
+	writeCached(t, slf4j, "slf4j")
+	sum := sha1.Sum([]byte("slf4j"))
+	writeCached(t, slf4j+".sha1", hex.EncodeToString(sum[:])+"  slf4j-api-2.0.9.jar\n")
+	guava := filepath.Join(repo, "com", "google", "guava", "guava", "33.0.0-jre", "guava-33.0.0-jre.jar")
+	writeCached(t, guava, "guava")
+	writeCached(t, filepath.Join(repo, "junit", "junit", "4.13.2", "junit-4.13.2.jar"), "junit")
+
+	issues, err := verifyCache(cmd, projectRoot, mavenEcosystem(t, repo))
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+
+	// A checksum mismatch, a failed download and a missing artifact
+	writeCached(t, slf4j, "truncated")
+	require.NoError(t, os.Remove(guava))
+	writeCached(t, guava+".lastUpdated", "")
+	require.NoError(t, os.RemoveAll(filepath.Join(repo, "junit")))
+
+	issues, err = verifyCache(cmd, projectRoot, mavenEcosystem(t, repo))
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, "stale_cache", issues[0].Type)
+	assert.Equal(t, fmt.Sprintf("2 of 3 dependencies of pom.xml are corrupted in the local cache %s: org.slf4j:slf4j-api:2.0.9 (checksum mismatch), com.google.guava:guava:33.0.0-jre (failed download)", repo), issues[0].Message)
+	assert.Equal(t, "mvn clean", issues[0].FixCommand)
+	assert.True(t, issues[0].FixAvailable)
+	assert.Equal(t, "missing_cache_artifacts", issues[1].Type)
+	assert.Equal(t, "warning", issues[1].Severity)
+	assert.Contains(t, issues[1].Message, "1 of 3 dependencies of pom.xml are not in the local cache")
+	assert.Contains(t, issues[1].Message, "junit:junit:4.13.2")
+	assert.Equal(t, "mvn dependency:go-offline", issues[1].FixCommand)
+}
+
+func TestVerifyCache_NoCache(t *testing.T) {
+	projectRoot := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectRoot, "pom.xml"), []byte(testPom), 0644))
+	cmd := config.VerificationCommand{Type: "cache_check", Source: "pom.xml"}
+
+	issues, err := verifyCache(cmd, projectRoot, mavenEcosystem(t, filepath.Join(projectRoot, "no-repo")))
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "missing_cache_artifacts", issues[0].Type)
+	assert.Contains(t, issues[0].Message, "No dependency cache found for pom.xml")
+}
+
+func TestVerifyCache_Npm(t *testing.T) {
+	projectRoot := t.TempDir()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	integrity := func(content string) string {
+		sum := sha512.Sum512([]byte(content))
+		return "sha512-" + base64.StdEncoding.EncodeToString(sum[:])
+	}
+	cachePath := func(content string) string {
+		sum := sha512.Sum512([]byte(content))
+		digest := hex.EncodeToString(sum[:])
+		return filepath.Join(home, ".npm", "_cacache", "content-v2", "sha512", digest[:2], digest[2:4], digest[4:])
+	}
+	lock := fmt.Sprintf(`{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app"},
+    "node_modules/lodash": {"version": "4.17.21", "integrity": %q},
+    "node_modules/left-pad": {"version": "1.3.0", "integrity": %q},
+    "node_modules/@scope/lib": {"version": "2.0.0", "integrity": %q},
+    "node_modules/local": {"resolved": "../local", "link": true}
+  }
+}`, integrity("lodash"), integrity("left-pad"), integrity("lib"))
+	require.NoError(t, os.WriteFile(filepath.Join(projectRoot, "package-lock.json"), []byte(lock), 0644))
+
+	writeCached(t, cachePath("lodash"), "lodash")
+	writeCached(t, cachePath("left-pad"), "corrupted")
+
+	ecosystem := &detector.DetectedEcosystem{ID: "npm", Config: &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		Dependencies: config.Dependencies{LockFile: "package-lock.json"},
+		Cache:        config.Cache{Locations: []string{"${HOME}/.npm"}, ArtifactPattern: ".*"},
+	}}}
+	issues, err := verifyCache(config.VerificationCommand{Type: "cache_check"}, projectRoot, ecosystem)
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Contains(t, issues[0].Message, "left-pad@1.3.0 (checksum mismatch)")
+	assert.False(t, issues[0].FixAvailable)
+	assert.Contains(t, issues[1].Message, "1 of 3 dependencies of package-lock.json are not in the local cache")
+	assert.Contains(t, issues[1].Message, "@scope/lib@2.0.0")
+}
+
+func TestParseIntegrity(t *testing.T) {
+	algorithm, digest, ok := parseIntegrity("sha1-AAEC sha512-AAECAw==")
+	assert.True(t, ok)
+	assert.Equal(t, "sha512", algorithm)
+	assert.Equal(t, "00010203", digest)
+
+	_, _, ok = parseIntegrity("md5-AAEC")
+	assert.False(t, ok)
+}
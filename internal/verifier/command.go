@@ -0,0 +1,162 @@
+package verifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/runner"
+)
+
+// IssueCommandFailed is the issue type of a command check that failed in a way none of its
+// outcomes classify
+const IssueCommandFailed = "command_failed"
+
+// defaultCommandTimeout bounds command checks without a timeout
+const defaultCommandTimeout = 2 * time.Minute
+
+// verifyCommand runs a command check and classifies its exit code and output with the first
+// matching outcome. A failure no outcome matches is reported as the first of the ecosystem's
+// failure signatures its output shows, else as command_failed; a success no outcome matches
+// is healthy.
+func verifyCommand(ctx context.Context, cmd config.VerificationCommand, projectRoot string, ecosystem *detector.DetectedEcosystem) (*Issue, error) {
+	timeout := defaultCommandTimeout
+	if cmd.Timeout != "" {
+		if parsed, err := time.ParseDuration(cmd.Timeout); err == nil && parsed > 0 {
+			timeout = parsed
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	output, err := runner.Run(ctx, projectRoot, cmd.Command)
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("command timed out after %s: %s", timeout, cmd.Command)
+	}
+	exitCode := 0
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("command could not run: %w", err)
+		}
+		exitCode = exitErr.ExitCode()
+	}
+
+	outcome, line := classifyOutcome(cmd.Classify, exitCode, string(output))
+	if outcome == nil {
+		if exitCode == 0 {
+			return nil, nil
+		}
+		if issue := signatureIssue(ecosystem, exitCode, string(output)); issue != nil {
+			return issue, nil
+		}
+		outcome = &config.CommandOutcome{IssueType: IssueCommandFailed}
+		line = lastLine(string(output))
+	}
+
+	message := outcome.Message
+	if message == "" {
+		message = commandLabel(cmd)
+	}
+	message = fmt.Sprintf("%s (exit %d)", message, exitCode)
+	if line != "" {
+		message += ": " + line
+	}
+	severity := outcome.Severity
+	if severity == "" {
+		severity = "error"
+	}
+
+	fixCommand := getFixCommand(ecosystem, outcome.IssueType)
+	return &Issue{
+		Type:         outcome.IssueType,
+		Severity:     severity,
+		Message:      message,
+		FixAvailable: hasFix(ecosystem, outcome.IssueType),
+		FixCommand:   fixCommand,
+	}, nil
+}
+
+// signatureIssue returns the issue of the first failure signature a failed command's output
+// shows, or nil
+func signatureIssue(ecosystem *detector.DetectedEcosystem, exitCode int, output string) *Issue {
+	signatures := ecosystem.Config.Ecosystem.FailureSignatures
+	if len(signatures) == 0 {
+		return nil
+	}
+	for _, line := range strings.Split(output, "\n") {
+		sig, message, fix := config.MatchFailureSignature(signatures, line)
+		if sig == nil {
+			continue
+		}
+		issue := &Issue{
+			Type:         sig.IssueType,
+			Severity:     "error",
+			Message:      fmt.Sprintf("%s (exit %d): %s", message, exitCode, strings.TrimSpace(line)),
+			FixAvailable: hasFix(ecosystem, sig.IssueType),
+			FixCommand:   getFixCommand(ecosystem, sig.IssueType),
+		}
+		if fix != "" && !issue.FixAvailable {
+			// Not a command reconcile_environment can run, so it is advice in the message
+			issue.Message += "; " + fix
+		}
+		return issue
+	}
+	return nil
+}
+
+// classifyOutcome returns the first outcome matching an exit code and output, with the first
+// output line its pattern matched
+func classifyOutcome(outcomes []config.CommandOutcome, exitCode int, output string) (*config.CommandOutcome, string) {
+	for i, outcome := range outcomes {
+		if outcome.ExitCode != nil && *outcome.ExitCode != exitCode {
+			continue
+		}
+		if outcome.Output == "" {
+			return &outcomes[i], ""
+		}
+		pattern, err := regexp.Compile(outcome.Output)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(output, "\n") {
+			if pattern.MatchString(line) {
+				return &outcomes[i], strings.TrimSpace(line)
+			}
+		}
+	}
+	return nil, ""
+}
+
+// hasFix reports whether the ecosystem's config has a fix for an issue type
+func hasFix(ecosystem *detector.DetectedEcosystem, issueType string) bool {
+	for _, fix := range ecosystem.Config.Ecosystem.Reconciliation.Fixes {
+		if fix.IssueType == issueType {
+			return true
+		}
+	}
+	return false
+}
+
+// commandLabel names a command check in issue messages
+func commandLabel(cmd config.VerificationCommand) string {
+	if cmd.Description != "" {
+		return cmd.Description
+	}
+	if cmd.Name != "" {
+		return cmd.Name + " failed"
+	}
+	return cmd.Command + " failed"
+}
+
+// lastLine returns the last non-empty line of an output
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
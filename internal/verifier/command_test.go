@@ -0,0 +1,108 @@
+package verifier
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyCommand_Classify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows - requires sh")
+	}
+	ecosystem := &detector.DetectedEcosystem{ID: "test", Config: &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		Reconciliation: config.Reconciliation{Fixes: []config.Fix{{IssueType: "missing_dependency", Command: "npm ci"}}},
+	}}}
+	two, three := 2, 3
+	classify := []config.CommandOutcome{
+		{ExitCode: &two, IssueType: "missing_dependency", Message: "Dependencies are not installed"},
+		{ExitCode: &three, Output: `error TS\d+`, IssueType: "compile_error"},
+		{Output: `(?i)deprecated`, IssueType: "deprecated_api", Severity: "warning"},
+	}
+	run := func(command string) *Issue {
+		issue, err := verifyCommand(context.Background(), config.VerificationCommand{Name: "check", Command: command, Classify: classify}, t.TempDir(), ecosystem)
+		require.NoError(t, err)
+		return issue
+	}
+
+	issue := run("exit 2")
+	require.NotNil(t, issue)
+	assert.Equal(t, "missing_dependency", issue.Type)
+	assert.Equal(t, "error", issue.Severity)
+	assert.Equal(t, "Dependencies are not installed (exit 2)", issue.Message)
+	assert.True(t, issue.FixAvailable)
+	assert.Equal(t, "npm ci", issue.FixCommand)
+
+	issue = run("echo 'src/a.ts(1,1): error TS2304: Cannot find name'; exit 3")
+	require.NotNil(t, issue)
+	assert.Equal(t, "compile_error", issue.Type)
+	assert.Contains(t, issue.Message, "error TS2304")
+	assert.False(t, issue.FixAvailable)
+
+	// Output outcomes also match successful runs
+	issue = run("echo 'warning: foo() is deprecated'")
+	require.NotNil(t, issue)
+	assert.Equal(t, "deprecated_api", issue.Type)
+	assert.Equal(t, "warning", issue.Severity)
+
+	// Unclassified failures are still reported, unclassified successes are healthy
+	issue = run("echo boom; exit 7")
+	require.NotNil(t, issue)
+	assert.Equal(t, IssueCommandFailed, issue.Type)
+	assert.Equal(t, "check failed (exit 7): boom", issue.Message)
+	assert.Nil(t, run("echo ok"))
+}
+
+func TestVerifyCommand_FailureSignatures(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows - requires sh")
+	}
+	ecosystem := &detector.DetectedEcosystem{ID: "test", Config: &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		Reconciliation: config.Reconciliation{Fixes: []config.Fix{{IssueType: "missing_dependency", Command: "npm ci"}}},
+		FailureSignatures: []config.FailureSignature{
+			{Name: "package", Pattern: `Cannot find module '([^']+)'`, IssueType: "missing_dependency", Message: "Package {1} is not installed"},
+			{Name: "db", Pattern: `Connection to \S+ refused`, IssueType: "service_unavailable", Message: "The database isn't running", Fix: "docker compose up -d db"},
+		},
+	}}}
+	zero := 0
+	classify := []config.CommandOutcome{{ExitCode: &zero, Output: "deprecated", IssueType: "deprecated_api"}}
+	run := func(command string) *Issue {
+		issue, err := verifyCommand(context.Background(), config.VerificationCommand{Name: "check", Command: command, Classify: classify}, t.TempDir(), ecosystem)
+		require.NoError(t, err)
+		return issue
+	}
+
+	issue := run(`echo "Error: Cannot find module 'left-pad'"; exit 1`)
+	require.NotNil(t, issue)
+	assert.Equal(t, "missing_dependency", issue.Type)
+	assert.Equal(t, "Package left-pad is not installed (exit 1): Error: Cannot find module 'left-pad'", issue.Message)
+	assert.True(t, issue.FixAvailable)
+	assert.Equal(t, "npm ci", issue.FixCommand)
+
+	// Without a config fix, the signature's fix is advice in the message
+	issue = run(`echo "Connection to localhost:5432 refused"; exit 1`)
+	require.NotNil(t, issue)
+	assert.Equal(t, "service_unavailable", issue.Type)
+	assert.False(t, issue.FixAvailable)
+	assert.Contains(t, issue.Message, "; docker compose up -d db")
+
+	issue = run("echo boom; exit 1")
+	require.NotNil(t, issue)
+	assert.Equal(t, IssueCommandFailed, issue.Type)
+	assert.Nil(t, run("echo ok"), "signatures only explain failures")
+}
+
+func TestVerifyCommand_Timeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows - requires sh")
+	}
+	ecosystem := &detector.DetectedEcosystem{ID: "test", Config: &config.EcosystemConfig{}}
+	cmd := config.VerificationCommand{Name: "slow", Command: "exec sleep 5", Timeout: "50ms", Classify: []config.CommandOutcome{{Output: "x", IssueType: "x"}}}
+	_, err := verifyCommand(context.Background(), cmd, t.TempDir(), ecosystem)
+	assert.ErrorContains(t, err, "timed out")
+}
@@ -0,0 +1,113 @@
+package verifier
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"time"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/config"
+)
+
+// maxEvidenceMatches limits the glob matches listed in evidence; MatchCount still counts them all
+const maxEvidenceMatches = 20
+
+// Evidence is what a timestamp comparison looked at, so a verdict can be checked by hand
+type Evidence struct {
+	Source         *FileEvidence      `json:"source,omitempty"`
+	Target         *FileEvidence      `json:"target,omitempty"`         // For a target pattern, its newest match
+	SourcePattern  *GlobEvidence      `json:"source_pattern,omitempty"` // Set when the source came from source_pattern
+	TargetPattern  *GlobEvidence      `json:"target_pattern,omitempty"`
+	Selection      *SelectionEvidence `json:"selection,omitempty"`       // How the targets of TargetPattern compare with the source
+	Orphaned       *GlobEvidence      `json:"orphaned,omitempty"`        // Outputs matching a target pattern that have no source
+	MissingOutputs *GlobEvidence      `json:"missing_outputs,omitempty"` // Sources that have no output
+	Modules        []ModuleEvidence   `json:"modules,omitempty"`         // Stale modules of a multi-module build
+}
+
+// FileEvidence is the state of one compared file
+type FileEvidence struct {
+	Path    string    `json:"path"` // Relative to the project root
+	ModTime time.Time `json:"mod_time"`
+	SHA256  string    `json:"sha256,omitempty"` // Empty when the file can't be read
+	// LinkTarget is the real file when Path is a symbolic link; its time and hash are reported
+	LinkTarget string `json:"link_target,omitempty"`
+}
+
+// GlobEvidence is a glob pattern and the files it matched
+type GlobEvidence struct {
+	Pattern    string   `json:"pattern"`
+	Matches    []string `json:"matches"` // Relative to the project root, at most maxEvidenceMatches
+	MatchCount int      `json:"match_count"`
+}
+
+// SelectionEvidence is how the targets of a pattern compare with the source, and the verdict
+// of each target selection strategy
+type SelectionEvidence struct {
+	Strategy     string          `json:"strategy"` // The strategy the verdict used
+	Targets      int             `json:"targets"`
+	StaleCount   int             `json:"stale_count"`   // Targets older than the source
+	StaleTargets []string        `json:"stale_targets"` // At most maxEvidenceMatches of them
+	Results      map[string]bool `json:"results"`       // Whether each strategy passes
+}
+
+// selectionEvidence records the stale targets of a pattern
+func selectionEvidence(projectRoot, strategy string, targets int, stale []*common.FileInfo) *SelectionEvidence {
+	if strategy == "" {
+		strategy = config.TargetNewest
+	}
+	s := &SelectionEvidence{Strategy: strategy, Targets: targets, StaleCount: len(stale), StaleTargets: []string{}}
+	for i, info := range stale {
+		if i == maxEvidenceMatches {
+			break
+		}
+		s.StaleTargets = append(s.StaleTargets, relativeTo(projectRoot, info.Path))
+	}
+	return s
+}
+
+// fileEvidence records the modification time and hash of a file
+func fileEvidence(projectRoot string, info *common.FileInfo) *FileEvidence {
+	f := &FileEvidence{
+		Path:    relativeTo(projectRoot, info.Path),
+		ModTime: info.ModTime,
+		SHA256:  hashFile(info.Resolved),
+	}
+	if info.IsLink {
+		f.LinkTarget = info.Resolved
+	}
+	return f
+}
+
+// globEvidence records the files a pattern matched
+func globEvidence(projectRoot, pattern string, matches []string) *GlobEvidence {
+	g := &GlobEvidence{Pattern: pattern, Matches: []string{}, MatchCount: len(matches)}
+	for i, match := range matches {
+		if i == maxEvidenceMatches {
+			break
+		}
+		g.Matches = append(g.Matches, relativeTo(projectRoot, match))
+	}
+	return g
+}
+
+// hashFile returns the hex SHA-256 of a file's contents, or "" if it can't be read
+func hashFile(path string) string {
+	sum, err := hashOf(path, sha256.New())
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(sum)
+}
+
+// relativeTo makes a path relative to the project root, leaving it unchanged if it's outside
+func relativeTo(projectRoot, path string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	rel, err := filepath.Rel(projectRoot, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,116 @@
+package verifier
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+)
+
+// FixTier is the license tier whose reconcile_environment and reconcile_issue run fixes; the
+// command itself can be run by hand on any tier
+const FixTier = "pro"
+
+// Rough fix durations, until the machine has a history of the fix
+const (
+	defaultFixDuration = 30 * time.Second
+	heavyFixDuration   = 5 * time.Minute
+)
+
+// FixDescriptor describes the fix of an issue for agents, so they can present it and reason
+// about running it without parsing the command
+type FixDescriptor struct {
+	Command          string
+	Argv             []string // The command as arguments; sh -c for commands that use shell syntax
+	Dir              string   // Directory the fix runs in
+	Tier             string   // License tier that runs the fix through the sentinel
+	EstimatedSeconds int      // Learned from the fix's earlier runs when Estimate is set, a rough guess otherwise
+	Estimate         string   // How long the fix typically takes on this machine, e.g. "typically ~3m on this machine"
+	Destructive      bool     // Deletes data, volumes, caches or uncommitted work, or the config marks the issue critical
+	Heavy            bool     // Needs enough free memory, CPU and disk to start
+	Description      string   // The config's description of the fix
+	Fingerprint      string   // Fix to pass to reconcile_issue; set by the server, which owns fix identities
+}
+
+// shellSyntax matches commands that need a shell: operators, redirections, expansions and globs
+var shellSyntax = regexp.MustCompile("[|&;<>()$`\\\\*?~{}\\[\\]#\\n]")
+
+// destructiveCommand matches commands that delete more than build outputs
+var destructiveCommand = regexp.MustCompile(`\brm\s+-[a-zA-Z]*[rf]|\bgit\s+(?:clean|reset\s+--hard|checkout\s+--\s)|\b(?:system|volume|image|container)\s+prune\b|\bvolume\s+rm\b|\bdown\s+(?:-v|--volumes)\b|\b(?:cache\s+(?:clean|purge)|dropdb|DROP\s+(?:DATABASE|TABLE))\b`)
+
+// describeFix returns the descriptor of an issue's fix, or nil when it has none
+func describeFix(projectRoot string, ecosystem *detector.DetectedEcosystem, issue Issue) *FixDescriptor {
+	if !issue.FixAvailable || issue.FixCommand == "" {
+		return nil
+	}
+	descriptor := &FixDescriptor{
+		Command:          issue.FixCommand,
+		Argv:             argv(issue.FixCommand),
+		Dir:              projectRoot,
+		Tier:             FixTier,
+		EstimatedSeconds: int(defaultFixDuration.Seconds()),
+		Destructive:      destructiveCommand.MatchString(issue.FixCommand),
+	}
+	if fix := findFix(ecosystem, issue.Type); fix != nil {
+		descriptor.Description = fix.Description
+		descriptor.Heavy = fix.IsHeavy()
+		if descriptor.Heavy {
+			descriptor.EstimatedSeconds = int(heavyFixDuration.Seconds())
+		}
+		if fix.Severity == "critical" {
+			descriptor.Destructive = true
+		}
+	}
+	return descriptor
+}
+
+// argv splits a command into arguments the way sh would for plain words and quotes, or
+// returns it as sh -c when it uses any other shell syntax
+func argv(command string) []string {
+	if shellSyntax.MatchString(command) {
+		return []string{"sh", "-c", command}
+	}
+	var args []string
+	var current strings.Builder
+	inWord := false
+	var quote rune
+	for _, r := range command {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				args = append(args, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return []string{"sh", "-c", command} // Unbalanced quotes are left to the shell to reject
+	}
+	if inWord {
+		args = append(args, current.String())
+	}
+	return args
+}
+
+// findFix returns the config's fix for an issue type
+func findFix(ecosystem *detector.DetectedEcosystem, issueType string) *config.Fix {
+	fixes := ecosystem.Config.Ecosystem.Reconciliation.Fixes
+	for i := range fixes {
+		if fixes[i].IssueType == issueType {
+			return &fixes[i]
+		}
+	}
+	return nil
+}
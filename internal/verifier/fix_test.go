@@ -0,0 +1,50 @@
+package verifier
+
+import (
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgv(t *testing.T) {
+	tests := map[string][]string{
+		"mvn clean install":             {"mvn", "clean", "install"},
+		`npm run "build:dev"  --silent`: {"npm", "run", "build:dev", "--silent"},
+		`git commit -m 'two words'`:     {"git", "commit", "-m", "two words"},
+		"npm ci && npm run build":       {"sh", "-c", "npm ci && npm run build"},
+		"rm -rf node_modules/.cache/*":  {"sh", "-c", "rm -rf node_modules/.cache/*"},
+		"echo $HOME":                    {"sh", "-c", "echo $HOME"},
+		`echo "unbalanced`:              {"sh", "-c", `echo "unbalanced`},
+	}
+	for command, want := range tests {
+		assert.Equal(t, want, argv(command), command)
+	}
+}
+
+func TestDescribeFix(t *testing.T) {
+	ecosystem := &detector.DetectedEcosystem{ID: "node", Config: &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		Reconciliation: config.Reconciliation{Fixes: []config.Fix{
+			{IssueType: "stale_build", Command: "npm run build", Heavy: true},
+			{IssueType: "stale_cache", Command: "npm cache clean --force"},
+			{IssueType: "corrupt_db", Command: "make reset-db", Severity: "critical"},
+		}},
+	}}}
+
+	assert.Nil(t, describeFix("/work/app", ecosystem, Issue{Type: "stale_build"}), "no fix available")
+
+	fix := describeFix("/work/app", ecosystem, Issue{Type: "stale_build", FixAvailable: true, FixCommand: "npm run build"})
+	assert.True(t, fix.Heavy)
+	assert.False(t, fix.Destructive)
+	assert.Equal(t, 300, fix.EstimatedSeconds)
+	assert.Equal(t, "/work/app", fix.Dir)
+
+	fix = describeFix("/work/app", ecosystem, Issue{Type: "stale_cache", FixAvailable: true, FixCommand: "npm cache clean --force"})
+	assert.True(t, fix.Destructive)
+	assert.False(t, fix.Heavy)
+
+	fix = describeFix("/work/app", ecosystem, Issue{Type: "corrupt_db", FixAvailable: true, FixCommand: "make reset-db"})
+	assert.True(t, fix.Destructive, "the config marks the issue critical")
+	assert.Equal(t, []string{"make", "reset-db"}, fix.Argv)
+}
@@ -1,8 +1,11 @@
 package verifier
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"dev-env-sentinel/internal/common"
@@ -13,8 +16,11 @@ import (
 // FreshnessReport contains the results of build freshness verification
 type FreshnessReport struct {
 	EcosystemID string
+	Suite       string // Check suite that was run; empty for the full suite
 	IsHealthy   bool
 	Issues      []Issue
+	Ecosystems  []string // Ecosystems verified, when the reports of several are combined
+	Commands    []CommandResult // Status of each verification command, in config order
 }
 
 // Issue represents a detected problem
@@ -22,12 +28,18 @@ type Issue struct {
 	Type        string
 	Severity    string
 	Message     string
+	File        string // Project-relative file the issue points at, if any
 	FixAvailable bool
 	FixCommand  string
+	Ecosystems  []string // Ecosystems reporting the issue, in combined reports
+	Evidence    *Evidence // Files and timestamps a timestamp comparison looked at
+	Doc         *config.IssueDoc // Team runbook for the issue type, if the config links one
+	Fix         *FixDescriptor   // Structured form of the fix, when one is available
 }
 
-// VerifyBuildFreshness verifies build freshness for a detected ecosystem
-func VerifyBuildFreshness(projectRoot string, ecosystem *detector.DetectedEcosystem) (*FreshnessReport, error) {
+// VerifyBuildFreshness verifies build freshness for a detected ecosystem. The context carries
+// where command checks run and bounds them.
+func VerifyBuildFreshness(ctx context.Context, projectRoot string, ecosystem *detector.DetectedEcosystem) (*FreshnessReport, error) {
 	report := &FreshnessReport{
 		EcosystemID: ecosystem.ID,
 		IsHealthy:   true,
@@ -37,37 +49,118 @@ func VerifyBuildFreshness(projectRoot string, ecosystem *detector.DetectedEcosys
 	cfg := ecosystem.Config
 	verification := cfg.Ecosystem.Verification.BuildFreshness
 
-	// Execute verification commands
-	for _, cmd := range verification.Commands {
-		issue, err := executeVerificationCommand(cmd, projectRoot, ecosystem)
-		if err != nil {
-			// Log error but continue with other checks
-			continue
-		}
-
-		if issue != nil {
+	// Execute verification commands; a command that errors is reported without stopping the others
+	issues, results := runCommands(ctx, verification.Commands, verification.MaxParallel, projectRoot, ecosystem)
+	for _, commandIssues := range issues {
+		if len(commandIssues) > 0 {
 			report.IsHealthy = false
-			report.Issues = append(report.Issues, *issue)
+			report.Issues = append(report.Issues, commandIssues...)
 		}
 	}
+	for i := range report.Issues {
+		report.Issues[i].Doc = getDoc(ecosystem, report.Issues[i].Type)
+		report.Issues[i].Fix = describeFix(projectRoot, ecosystem, report.Issues[i])
+	}
+	SortIssues(report.Issues)
+	report.Commands = results
 
 	return report, nil
 }
 
+// CombineReports merges the reports of several ecosystems into one. An issue reported
+// identically by several ecosystems is listed once, with all the ecosystems that reported it.
+func CombineReports(reports []*FreshnessReport) *FreshnessReport {
+	if len(reports) == 1 {
+		return reports[0]
+	}
+
+	combined := &FreshnessReport{
+		Suite:     reports[0].Suite,
+		IsHealthy: true,
+		Issues:    []Issue{},
+	}
+	index := make(map[string]int)
+	for _, report := range reports {
+		combined.Ecosystems = append(combined.Ecosystems, report.EcosystemID)
+		if !report.IsHealthy {
+			combined.IsHealthy = false
+		}
+		for _, result := range report.Commands {
+			result.Ecosystem = report.EcosystemID
+			combined.Commands = append(combined.Commands, result)
+		}
+		for _, issue := range report.Issues {
+			key := issue.Type + "\x00" + issue.Message + "\x00" + issue.File
+			if i, ok := index[key]; ok {
+				combined.Issues[i].Ecosystems = append(combined.Issues[i].Ecosystems, report.EcosystemID)
+				continue
+			}
+			issue.Ecosystems = []string{report.EcosystemID}
+			index[key] = len(combined.Issues)
+			combined.Issues = append(combined.Issues, issue)
+		}
+	}
+	combined.EcosystemID = strings.Join(combined.Ecosystems, ", ")
+	SortIssues(combined.Issues)
+	return combined
+}
+
+// severityOrder ranks issue severities, most severe first
+var severityOrder = map[string]int{"critical": 0, "error": 1, "warning": 2}
+
+// SortIssues orders issues most severe first. Issues of the same severity keep the order of
+// the commands that found them, and unknown severities come last.
+func SortIssues(issues []Issue) {
+	rank := func(severity string) int {
+		if r, ok := severityOrder[severity]; ok {
+			return r
+		}
+		return len(severityOrder)
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return rank(issues[i].Severity) < rank(issues[j].Severity)
+	})
+}
+
 // executeVerificationCommand executes a single verification command
-func executeVerificationCommand(cmd config.VerificationCommand, projectRoot string, ecosystem *detector.DetectedEcosystem) (*Issue, error) {
+func executeVerificationCommand(ctx context.Context, cmd config.VerificationCommand, projectRoot string, ecosystem *detector.DetectedEcosystem) ([]Issue, error) {
+	var issue *Issue
+	var err error
 	switch cmd.Type {
 	case "timestamp_compare":
-		return verifyTimestampCompare(cmd, projectRoot, ecosystem)
+		issue, err = verifyTimestampCompare(ctx, cmd, projectRoot, ecosystem)
 	case "command":
-		return verifyCommand(cmd, projectRoot)
+		issue, err = verifyCommand(ctx, cmd, projectRoot, ecosystem)
+	case "orphan_check":
+		issue, err = verifyOrphans(ctx, cmd, projectRoot, ecosystem)
+	case "partial_build_check":
+		issue, err = verifyPartialBuild(ctx, cmd, projectRoot, ecosystem)
+	case "module_check":
+		issue, err = verifyModules(ctx, cmd, projectRoot, ecosystem)
+	case "cache_check":
+		return verifyCache(cmd, projectRoot, ecosystem)
 	default:
 		return nil, fmt.Errorf("unknown verification command type: %s", cmd.Type)
 	}
+	if err != nil || issue == nil {
+		return nil, err
+	}
+	return []Issue{*issue}, nil
 }
 
 // verifyTimestampCompare verifies timestamp comparison
-func verifyTimestampCompare(cmd config.VerificationCommand, projectRoot string, ecosystem *detector.DetectedEcosystem) (*Issue, error) {
+func verifyTimestampCompare(ctx context.Context, cmd config.VerificationCommand, projectRoot string, ecosystem *detector.DetectedEcosystem) (*Issue, error) {
+	// A source pattern compares the most recently modified source
+	evidence := &Evidence{}
+	if cmd.Source == "" && cmd.SourcePattern != "" {
+		newest, matches, err := newestMatch(ctx, projectRoot, cmd.SourcePattern)
+		if err != nil || newest == "" {
+			return nil, err
+		}
+		cmd.Source = newest
+		evidence.SourcePattern = globEvidence(projectRoot, cmd.SourcePattern, matches)
+	}
+
 	// Resolve source path
 	sourcePath := filepath.Join(projectRoot, common.ExpandPattern(cmd.Source))
 	if !common.FileExists(sourcePath) {
@@ -78,10 +171,11 @@ func verifyTimestampCompare(cmd config.VerificationCommand, projectRoot string,
 	if err != nil {
 		return nil, err
 	}
+	evidence.Source = fileEvidence(projectRoot, sourceInfo)
 
 	// Handle target pattern
 	if cmd.TargetPattern != "" {
-		return verifyTimestampPattern(sourceInfo, cmd.TargetPattern, projectRoot, cmd, ecosystem)
+		return verifyTimestampPattern(ctx, sourceInfo, cmd.TargetPattern, projectRoot, cmd, ecosystem, evidence)
 	}
 
 	// Handle single target file
@@ -92,7 +186,9 @@ func verifyTimestampCompare(cmd config.VerificationCommand, projectRoot string,
 				Type:        "missing_target",
 				Severity:    "warning",
 				Message:     fmt.Sprintf("Target file not found: %s", cmd.Target),
+				File:        cmd.Target,
 				FixAvailable: false,
+				Evidence:    evidence,
 			}, nil
 		}
 
@@ -102,12 +198,15 @@ func verifyTimestampCompare(cmd config.VerificationCommand, projectRoot string,
 		}
 
 		if sourceInfo.ModTime.After(targetInfo.ModTime) {
+			evidence.Target = fileEvidence(projectRoot, targetInfo)
 			return &Issue{
 				Type:        "stale_build",
 				Severity:    "error",
 				Message:     fmt.Sprintf("%s is newer than %s", cmd.Source, cmd.Target),
+				File:        common.ExpandPattern(cmd.Source),
 				FixAvailable: true,
 				FixCommand:  getFixCommand(ecosystem, "stale_build"),
+				Evidence:    evidence,
 			}, nil
 		}
 	}
@@ -115,26 +214,104 @@ func verifyTimestampCompare(cmd config.VerificationCommand, projectRoot string,
 	return nil, nil
 }
 
-// verifyTimestampPattern verifies timestamp against a pattern
-func verifyTimestampPattern(sourceInfo *common.FileInfo, pattern string, projectRoot string, cmd config.VerificationCommand, ecosystem *detector.DetectedEcosystem) (*Issue, error) {
+// verifyTimestampPattern verifies timestamp against a pattern. The command's target selection
+// decides which targets must be newer than the source.
+func verifyTimestampPattern(ctx context.Context, sourceInfo *common.FileInfo, pattern string, projectRoot string, cmd config.VerificationCommand, ecosystem *detector.DetectedEcosystem, evidence *Evidence) (*Issue, error) {
 	expandedPattern := common.ExpandPattern(pattern)
 	fullPattern := filepath.Join(projectRoot, expandedPattern)
 
-	matches, err := common.FindFilesByPattern(fullPattern)
+	matches, err := common.FindFilesByPattern(ctx, fullPattern)
 	if err != nil {
 		return nil, err
 	}
+	evidence.TargetPattern = globEvidence(projectRoot, pattern, matches)
 
-	if len(matches) == 0 {
+	minMatches := cmd.MinMatches
+	if minMatches < 1 {
+		minMatches = 1
+	}
+	if len(matches) < minMatches {
+		message := fmt.Sprintf("No files found matching pattern: %s", pattern)
+		if len(matches) > 0 {
+			message = fmt.Sprintf("Only %d files found matching pattern %s, expected at least %d", len(matches), pattern, minMatches)
+		}
 		return &Issue{
 			Type:        "missing_build_output",
 			Severity:    "warning",
-			Message:     fmt.Sprintf("No files found matching pattern: %s", pattern),
+			Message:     message,
 			FixAvailable: false,
+			Evidence:    evidence,
 		}, nil
 	}
 
-	// Find newest file in matches
+	// Compare every match with the source
+	var newest, oldest *common.FileInfo
+	var stale []*common.FileInfo
+	for _, match := range matches {
+		info, err := common.GetFileInfo(match)
+		if err != nil {
+			continue
+		}
+		if newest == nil || info.ModTime.After(newest.ModTime) {
+			newest = info
+		}
+		if oldest == nil || info.ModTime.Before(oldest.ModTime) {
+			oldest = info
+		}
+		if sourceInfo.ModTime.After(info.ModTime) {
+			stale = append(stale, info)
+		}
+	}
+	if newest == nil {
+		return nil, nil
+	}
+
+	selection := selectionEvidence(projectRoot, cmd.TargetSelection, len(matches), stale)
+	selection.Results = map[string]bool{
+		config.TargetNewest: !sourceInfo.ModTime.After(newest.ModTime),
+		config.TargetOldest: !sourceInfo.ModTime.After(oldest.ModTime),
+		config.TargetAny:    len(stale) < len(matches),
+		config.TargetAll:    len(stale) == 0,
+	}
+	evidence.Selection = selection
+	if selection.Results[selection.Strategy] {
+		return nil, nil
+	}
+
+	var message string
+	switch selection.Strategy {
+	case config.TargetOldest:
+		evidence.Target = fileEvidence(projectRoot, oldest)
+		message = fmt.Sprintf("%s is newer than build output (%s)", cmd.Source, evidence.Target.Path)
+	case config.TargetAny:
+		evidence.Target = fileEvidence(projectRoot, newest)
+		message = fmt.Sprintf("%s is newer than all %d build outputs matching %s", cmd.Source, len(matches), pattern)
+	case config.TargetAll:
+		evidence.Target = fileEvidence(projectRoot, oldest)
+		message = fmt.Sprintf("%s is newer than %d of %d build outputs matching %s", cmd.Source, len(stale), len(matches), pattern)
+	default:
+		evidence.Target = fileEvidence(projectRoot, newest)
+		message = fmt.Sprintf("%s is newer than build output (%s)", cmd.Source, evidence.Target.Path)
+	}
+	return &Issue{
+		Type:        "stale_build",
+		Severity:    "error",
+		Message:     message,
+		File:        common.ExpandPattern(cmd.Source),
+		FixAvailable: true,
+		FixCommand:  getFixCommand(ecosystem, "stale_build"),
+		Evidence:    evidence,
+	}, nil
+}
+
+// newestMatch returns the most recently modified file matching a pattern, relative to the
+// project root, or "" when nothing matches, and all the files matched
+func newestMatch(ctx context.Context, projectRoot, pattern string) (string, []string, error) {
+	matches, err := common.FindFilesByPattern(ctx, filepath.Join(projectRoot, common.ExpandPattern(pattern)))
+	if err != nil {
+		return "", nil, err
+	}
+
 	var newestTime time.Time
 	var newestFile string
 	for _, match := range matches {
@@ -147,27 +324,11 @@ func verifyTimestampPattern(sourceInfo *common.FileInfo, pattern string, project
 			newestFile = match
 		}
 	}
-
-	// Compare with source
-	if sourceInfo.ModTime.After(newestTime) {
-		relPath, _ := filepath.Rel(projectRoot, newestFile)
-		return &Issue{
-			Type:        "stale_build",
-			Severity:    "error",
-			Message:     fmt.Sprintf("%s is newer than build output (%s)", cmd.Source, relPath),
-			FixAvailable: true,
-			FixCommand:  getFixCommand(ecosystem, "stale_build"),
-		}, nil
+	if newestFile == "" {
+		return "", matches, nil
 	}
-
-	return nil, nil
-}
-
-// verifyCommand executes a command-based verification
-func verifyCommand(cmd config.VerificationCommand, projectRoot string) (*Issue, error) {
-	// TODO: Implement command execution verification
-	// For now, return nil (no issue detected)
-	return nil, nil
+	newest, err := filepath.Rel(projectRoot, newestFile)
+	return newest, matches, err
 }
 
 // getFixCommand retrieves the fix command for an issue type
@@ -181,3 +342,13 @@ func getFixCommand(ecosystem *detector.DetectedEcosystem, issueType string) stri
 	return ""
 }
 
+// getDoc retrieves the runbook the config links to an issue type
+func getDoc(ecosystem *detector.DetectedEcosystem, issueType string) *config.IssueDoc {
+	for i, doc := range ecosystem.Config.Ecosystem.Docs {
+		if doc.IssueType == issueType {
+			return &ecosystem.Config.Ecosystem.Docs[i]
+		}
+	}
+	return nil
+}
+
@@ -1,6 +1,9 @@
 package verifier
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
 	"path/filepath"
 	"testing"
@@ -269,7 +272,7 @@ func TestVerifyBuildFreshness(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			projectRoot, ecosystem := tt.setup(t)
 
-			report, err := VerifyBuildFreshness(projectRoot, ecosystem)
+			report, err := VerifyBuildFreshness(context.Background(), projectRoot, ecosystem)
 			require.NoError(t, err)
 			require.NotNil(t, report)
 			assert.Equal(t, ecosystem.ID, report.EcosystemID)
@@ -323,6 +326,10 @@ func TestVerifyBuildFreshness_IssueDetails(t *testing.T) {
 					},
 				},
 			},
+			Docs: []config.IssueDoc{
+				{IssueType: "missing_target", URL: "https://wiki.example.com/first-build"},
+				{IssueType: "stale_build", Title: "Rebuilding", URL: "https://wiki.example.com/rebuild"},
+			},
 		},
 	}
 
@@ -350,7 +357,7 @@ func TestVerifyBuildFreshness_IssueDetails(t *testing.T) {
 	err = os.WriteFile(manifestPath, []byte("manifest"), 0644)
 	require.NoError(t, err)
 
-	report, err := VerifyBuildFreshness(tmpDir, ecosystem)
+	report, err := VerifyBuildFreshness(context.Background(), tmpDir, ecosystem)
 	require.NoError(t, err)
 	require.Len(t, report.Issues, 1)
 
@@ -360,5 +367,227 @@ func TestVerifyBuildFreshness_IssueDetails(t *testing.T) {
 	assert.NotEmpty(t, issue.Message)
 	assert.True(t, issue.FixAvailable)
 	assert.Equal(t, "mvn clean", issue.FixCommand)
+	require.NotNil(t, issue.Doc)
+	assert.Equal(t, "https://wiki.example.com/rebuild", issue.Doc.URL)
+	require.NotNil(t, issue.Fix)
+	assert.Equal(t, []string{"mvn", "clean"}, issue.Fix.Argv)
+	assert.Equal(t, tmpDir, issue.Fix.Dir)
+	assert.Equal(t, "pro", issue.Fix.Tier)
+	assert.Equal(t, "Clean build", issue.Fix.Description)
+	assert.Equal(t, 30, issue.Fix.EstimatedSeconds)
+	assert.False(t, issue.Fix.Destructive)
+}
+
+
+func TestCombineReports(t *testing.T) {
+	stale := Issue{Type: "stale_build", Severity: "warning", Message: "node_modules is stale", File: "package.json"}
+	java := &FreshnessReport{EcosystemID: "java", Issues: []Issue{stale}}
+	node := &FreshnessReport{EcosystemID: "javascript", Issues: []Issue{stale, {Type: "missing_lock", Message: "no lockfile"}}}
+	python := &FreshnessReport{EcosystemID: "python", IsHealthy: true, Issues: []Issue{}}
+
+	combined := CombineReports([]*FreshnessReport{java, node, python})
+	assert.False(t, combined.IsHealthy)
+	assert.Equal(t, "java, javascript, python", combined.EcosystemID)
+	require.Len(t, combined.Issues, 2)
+	assert.Equal(t, []string{"java", "javascript"}, combined.Issues[0].Ecosystems)
+	assert.Equal(t, []string{"javascript"}, combined.Issues[1].Ecosystems)
+	assert.Nil(t, java.Issues[0].Ecosystems, "input reports are not modified")
+
+	assert.Same(t, java, CombineReports([]*FreshnessReport{java}))
+}
+
+func TestSortIssues(t *testing.T) {
+	issues := []Issue{
+		{Type: "stale_build", Severity: "warning"},
+		{Type: "missing_lock", Severity: "error"},
+		{Type: "custom", Severity: "notice"},
+		{Type: "stale_lock", Severity: "warning"},
+		{Type: "corrupt_cache", Severity: "critical"},
+	}
+	SortIssues(issues)
+
+	types := make([]string, len(issues))
+	for i, issue := range issues {
+		types[i] = issue.Type
+	}
+	assert.Equal(t, []string{"corrupt_cache", "missing_lock", "stale_build", "stale_lock", "custom"}, types)
+}
+
+func TestVerifyTimestampCompare_SourcePattern(t *testing.T) {
+	tmpDir := t.TempDir()
+	cmd := config.VerificationCommand{
+		Name:          "check_sources",
+		Type:          "timestamp_compare",
+		SourcePattern: "src/*.java",
+		TargetPattern: "target/*.class",
+	}
+	ecosystem := &detector.DetectedEcosystem{ID: "java", Config: &config.EcosystemConfig{}, ProjectRoot: tmpDir}
+
+	// No matching sources: nothing to compare
+	issue, err := verifyTimestampCompare(context.Background(), cmd, tmpDir, ecosystem)
+	require.NoError(t, err)
+	assert.Nil(t, issue)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "src"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "target"), 0755))
+	old := time.Now().Add(-time.Hour)
+	for _, name := range []string{"src/A.java", "target/A.class"} {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.WriteFile(path, []byte("x"), 0644))
+		require.NoError(t, os.Chtimes(path, old, old))
+	}
+
+	issue, err = verifyTimestampCompare(context.Background(), cmd, tmpDir, ecosystem)
+	require.NoError(t, err)
+	assert.Nil(t, issue)
+
+	// The newest source is compared against the build output
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "src", "B.java"), []byte("x"), 0644))
+	issue, err = verifyTimestampCompare(context.Background(), cmd, tmpDir, ecosystem)
+	require.NoError(t, err)
+	require.NotNil(t, issue)
+	assert.Equal(t, "stale_build", issue.Type)
+	assert.Equal(t, filepath.Join("src", "B.java"), issue.File)
+}
+
+func TestVerifyTimestampCompare_Evidence(t *testing.T) {
+	tmpDir := t.TempDir()
+	ecosystem := &detector.DetectedEcosystem{ID: "java", Config: &config.EcosystemConfig{}, ProjectRoot: tmpDir}
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "target"), 0755))
+
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "pom.xml"), []byte("<project/>"), 0644))
+	for _, name := range []string{"target/a.jar", "target/b.jar"} {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.WriteFile(path, []byte(name), 0644))
+		require.NoError(t, os.Chtimes(path, old, old))
+	}
+
+	// A target pattern records its matches and the newest one compared
+	cmd := config.VerificationCommand{Type: "timestamp_compare", Source: "pom.xml", TargetPattern: "target/*.jar"}
+	issue, err := verifyTimestampCompare(context.Background(), cmd, tmpDir, ecosystem)
+	require.NoError(t, err)
+	require.NotNil(t, issue)
+	require.NotNil(t, issue.Evidence)
+	assert.Equal(t, "pom.xml", issue.Evidence.Source.Path)
+	assert.Equal(t, sha256Hex("<project/>"), issue.Evidence.Source.SHA256)
+	assert.True(t, issue.Evidence.Target.ModTime.Equal(old))
+	assert.Equal(t, &GlobEvidence{
+		Pattern:    "target/*.jar",
+		Matches:    []string{filepath.Join("target", "a.jar"), filepath.Join("target", "b.jar")},
+		MatchCount: 2,
+	}, issue.Evidence.TargetPattern)
+
+	// A single target records both files
+	cmd = config.VerificationCommand{Type: "timestamp_compare", Source: "pom.xml", Target: "target/a.jar"}
+	issue, err = verifyTimestampCompare(context.Background(), cmd, tmpDir, ecosystem)
+	require.NoError(t, err)
+	require.NotNil(t, issue)
+	assert.Equal(t, filepath.Join("target", "a.jar"), issue.Evidence.Target.Path)
+	assert.Equal(t, sha256Hex("target/a.jar"), issue.Evidence.Target.SHA256)
+	assert.Nil(t, issue.Evidence.TargetPattern)
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestVerifyTimestampCompare_SymlinkedOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	ecosystem := &detector.DetectedEcosystem{ID: "java", Config: &config.EcosystemConfig{}, ProjectRoot: tmpDir}
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "target"), 0755))
+
+	// The link is newer than the source, but the output it points at is older
+	old := time.Now().Add(-time.Hour)
+	jar := filepath.Join(tmpDir, "target", "app-1.0.jar")
+	require.NoError(t, os.WriteFile(jar, []byte("jar"), 0644))
+	require.NoError(t, os.Chtimes(jar, old, old))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "pom.xml"), []byte("<project/>"), 0644))
+	require.NoError(t, os.Symlink(jar, filepath.Join(tmpDir, "app.jar")))
+
+	cmd := config.VerificationCommand{Type: "timestamp_compare", Source: "pom.xml", Target: "app.jar"}
+	issue, err := verifyTimestampCompare(context.Background(), cmd, tmpDir, ecosystem)
+	require.NoError(t, err)
+	require.NotNil(t, issue)
+	assert.Equal(t, "stale_build", issue.Type)
+	realJar, err := filepath.EvalSymlinks(jar)
+	require.NoError(t, err)
+	assert.Equal(t, realJar, issue.Evidence.Target.LinkTarget)
+	assert.Equal(t, sha256Hex("jar"), issue.Evidence.Target.SHA256)
 }
 
+func TestVerifyTimestampCompare_DoubleStar(t *testing.T) {
+	tmpDir := t.TempDir()
+	ecosystem := &detector.DetectedEcosystem{ID: "java", Config: &config.EcosystemConfig{}, ProjectRoot: tmpDir}
+	classes := filepath.Join(tmpDir, "target", "classes", "com", "example")
+	require.NoError(t, os.MkdirAll(classes, 0755))
+	sources := filepath.Join(tmpDir, "src", "main", "java", "com", "example")
+	require.NoError(t, os.MkdirAll(sources, 0755))
+
+	old := time.Now().Add(-time.Hour)
+	class := filepath.Join(classes, "App.class")
+	require.NoError(t, os.WriteFile(class, []byte(""), 0644))
+	require.NoError(t, os.Chtimes(class, old, old))
+	require.NoError(t, os.WriteFile(filepath.Join(sources, "App.java"), []byte(""), 0644))
+
+	// Nested sources and classes are found through **
+	cmd := config.VerificationCommand{Type: "timestamp_compare", SourcePattern: "**/*.java", TargetPattern: "target/**/*.class"}
+	issue, err := verifyTimestampCompare(context.Background(), cmd, tmpDir, ecosystem)
+	require.NoError(t, err)
+	require.NotNil(t, issue)
+	assert.Equal(t, "stale_build", issue.Type)
+	assert.Equal(t, filepath.Join("src", "main", "java", "com", "example", "App.java"), issue.File)
+	assert.Equal(t, []string{filepath.Join("target", "classes", "com", "example", "App.class")}, issue.Evidence.TargetPattern.Matches)
+}
+
+func TestVerifyTimestampCompare_TargetSelection(t *testing.T) {
+	tmpDir := t.TempDir()
+	ecosystem := &detector.DetectedEcosystem{ID: "java", Config: &config.EcosystemConfig{}, ProjectRoot: tmpDir}
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "target"), 0755))
+
+	// Half the outputs were built before the source last changed
+	now := time.Now()
+	source := filepath.Join(tmpDir, "pom.xml")
+	require.NoError(t, os.WriteFile(source, []byte(""), 0644))
+	require.NoError(t, os.Chtimes(source, now.Add(-time.Hour), now.Add(-time.Hour)))
+	for name, age := range map[string]time.Duration{"A.class": 2 * time.Hour, "B.class": 3 * time.Hour, "C.class": 0, "D.class": 0} {
+		path := filepath.Join(tmpDir, "target", name)
+		require.NoError(t, os.WriteFile(path, []byte(""), 0644))
+		require.NoError(t, os.Chtimes(path, now.Add(-age), now.Add(-age)))
+	}
+
+	tests := []struct {
+		selection string
+		stale     bool
+		message   string
+	}{
+		{"", false, ""},
+		{config.TargetNewest, false, ""},
+		{config.TargetAny, false, ""},
+		{config.TargetOldest, true, "pom.xml is newer than build output (" + filepath.Join("target", "B.class") + ")"},
+		{config.TargetAll, true, "pom.xml is newer than 2 of 4 build outputs matching target/*.class"},
+	}
+	for _, tt := range tests {
+		cmd := config.VerificationCommand{Type: "timestamp_compare", Source: "pom.xml", TargetPattern: "target/*.class", TargetSelection: tt.selection}
+		issue, err := verifyTimestampCompare(context.Background(), cmd, tmpDir, ecosystem)
+		require.NoError(t, err, tt.selection)
+		if !tt.stale {
+			assert.Nil(t, issue, tt.selection)
+			continue
+		}
+		require.NotNil(t, issue, tt.selection)
+		assert.Equal(t, tt.message, issue.Message)
+		assert.Equal(t, map[string]bool{"newest": true, "oldest": false, "any": true, "all": false}, issue.Evidence.Selection.Results)
+		assert.Equal(t, 2, issue.Evidence.Selection.StaleCount)
+		assert.ElementsMatch(t, []string{filepath.Join("target", "A.class"), filepath.Join("target", "B.class")}, issue.Evidence.Selection.StaleTargets)
+	}
+
+	// Too few outputs are reported as missing
+	cmd := config.VerificationCommand{Type: "timestamp_compare", Source: "pom.xml", TargetPattern: "target/*.class", MinMatches: 5}
+	issue, err := verifyTimestampCompare(context.Background(), cmd, tmpDir, ecosystem)
+	require.NoError(t, err)
+	require.NotNil(t, issue)
+	assert.Equal(t, "missing_build_output", issue.Type)
+	assert.Equal(t, "Only 4 files found matching pattern target/*.class, expected at least 5", issue.Message)
+}
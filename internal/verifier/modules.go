@@ -0,0 +1,185 @@
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+)
+
+// ModuleEvidence is what a module check compared in one stale module
+type ModuleEvidence struct {
+	Module string        `json:"module"`           // How the build selects it, e.g. :core
+	Dir    string        `json:"dir"`              // Relative to the project root
+	Source *FileEvidence `json:"source,omitempty"` // Newest of the module's build file and sources
+	Target *FileEvidence `json:"target,omitempty"` // Newest output or task run; none when the module was never built
+	// Cache is how the build tool's last run of the task got the module's outputs: "local hit",
+	// "remote hit" or "miss", for layouts that record their runs
+	Cache string `json:"cache,omitempty"`
+	// DependsOn is the stale module that makes this one out of date, when it's stale only
+	// because it depends on one
+	DependsOn string `json:"depends_on,omitempty"`
+}
+
+// verifyModules checks every module of a multi-module build on its own: a module is stale when
+// its build file or a source changed after it was last built, or it has sources but was never
+// built. Nx and turbo record when they last ran a task for a project, from their cache or not,
+// which is used instead of output timestamps; outputs restored from a cache are as old as the
+// restore. Modules that depend on a stale module are stale too, where the layout records
+// dependencies. The stale modules are reported together, so the fix rebuilds just them and
+// what they depend on instead of the whole build. Projects that aren't multi-module builds
+// are left to the other commands.
+func verifyModules(ctx context.Context, cmd config.VerificationCommand, projectRoot string, ecosystem *detector.DetectedEcosystem) (*Issue, error) {
+	modules, err := detector.Modules(ctx, projectRoot, cmd.Modules)
+	if err != nil {
+		return nil, err
+	}
+	runs, err := taskRuns(ctx, projectRoot, cmd.Modules, firstNonEmpty(cmd.Task, "build"))
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []ModuleEvidence
+	var staleModules []detector.Module
+	isStale := make(map[string]bool)
+	built := 0
+	for _, module := range modules {
+		if module.Aggregator {
+			continue
+		}
+		built++
+		evidence, err := checkModule(ctx, cmd, projectRoot, module, runs)
+		if err != nil {
+			return nil, err
+		}
+		if evidence != nil {
+			stale = append(stale, *evidence)
+			staleModules = append(staleModules, module)
+			isStale[module.Dir] = true
+		}
+	}
+	if len(stale) == 0 {
+		return nil, nil
+	}
+
+	// Then what depends on them, until nothing more is affected
+	for changed := true; changed; {
+		changed = false
+		for _, module := range modules {
+			if isStale[module.Dir] || module.Aggregator {
+				continue
+			}
+			for _, dep := range module.DependsOn {
+				if isStale[dep] {
+					stale = append(stale, ModuleEvidence{Module: module.Selector(), Dir: module.Dir, DependsOn: dep})
+					staleModules = append(staleModules, module)
+					isStale[module.Dir] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	details := make([]string, len(stale))
+	for i, m := range stale {
+		switch {
+		case m.DependsOn != "":
+			details[i] = fmt.Sprintf("%s (depends on %s)", m.Dir, m.DependsOn)
+		case m.Target == nil:
+			details[i] = fmt.Sprintf("%s (not built)", m.Dir)
+		case m.Cache != "":
+			details[i] = fmt.Sprintf("%s (%s changed after the last %s, a cache %s)", m.Dir, m.Source.Path, firstNonEmpty(cmd.Task, "build"), m.Cache)
+		default:
+			details[i] = fmt.Sprintf("%s (%s changed after %s)", m.Dir, m.Source.Path, m.Target.Path)
+		}
+	}
+	evidence := &Evidence{Source: stale[0].Source, Target: stale[0].Target}
+	if len(stale) > maxEvidenceMatches {
+		evidence.Modules = stale[:maxEvidenceMatches]
+	} else {
+		evidence.Modules = stale
+	}
+
+	fixCommand := strings.ReplaceAll(getFixCommand(ecosystem, "stale_module"), config.ModulesPlaceholder, detector.Selectors(staleModules))
+	return &Issue{
+		Type:         "stale_module",
+		Severity:     "error",
+		Message:      fmt.Sprintf("%d of %d modules are out of date: %s", len(stale), built, strings.Join(details, ", ")),
+		File:         stale[0].Source.Path,
+		FixAvailable: fixCommand != "",
+		FixCommand:   fixCommand,
+		Evidence:     evidence,
+	}, nil
+}
+
+// checkModule returns the evidence of a stale module, or nil when it's up to date or has
+// nothing to build
+func checkModule(ctx context.Context, cmd config.VerificationCommand, projectRoot string, module detector.Module, runs map[string]taskRun) (*ModuleEvidence, error) {
+	dir := filepath.Join(projectRoot, filepath.FromSlash(module.Dir))
+
+	// A module may lack the build file, like an Nx project inferred from its package.json
+	source := firstNonEmpty(cmd.Source, "pom.xml")
+	newestSource, _ := common.GetFileInfo(filepath.Join(dir, common.ExpandPattern(source)))
+	sources := 0
+	for _, root := range cmd.SourceRoots {
+		for _, ext := range cmd.SourceExtensions {
+			matches, err := common.FindFilesByPattern(ctx, filepath.Join(dir, common.ExpandPattern(root), "**", "*"+ext))
+			if err != nil {
+				return nil, err
+			}
+			sources += len(matches)
+			if newest := newestFile(matches); newest != nil && (newestSource == nil || newest.ModTime.After(newestSource.ModTime)) {
+				newestSource = newest
+			}
+		}
+	}
+	if newestSource == nil {
+		return nil, nil
+	}
+
+	evidence := &ModuleEvidence{Module: module.Selector(), Dir: module.Dir, Source: fileEvidence(projectRoot, newestSource)}
+	if run, ok := runs[module.Name]; ok {
+		if !newestSource.ModTime.After(run.End) {
+			return nil, nil
+		}
+		evidence.Target = &FileEvidence{Path: relativeTo(projectRoot, run.File), ModTime: run.End}
+		evidence.Cache = run.Cache
+		return evidence, nil
+	}
+
+	outputs, err := common.FindFilesByPattern(ctx, filepath.Join(dir, common.ExpandPattern(cmd.TargetPattern)))
+	if err != nil {
+		return nil, err
+	}
+	newestOutput := newestFile(outputs)
+	switch {
+	case newestOutput == nil && sources == 0:
+		return nil, nil // Nothing to compile, e.g. a module of resources only
+	case newestOutput != nil && !newestSource.ModTime.After(newestOutput.ModTime):
+		return nil, nil
+	}
+	if newestOutput != nil {
+		evidence.Target = fileEvidence(projectRoot, newestOutput)
+	}
+	return evidence, nil
+}
+
+// newestFile returns the most recently modified of some files, or nil when there are none
+func newestFile(paths []string) *common.FileInfo {
+	var newest *common.FileInfo
+	for _, path := range paths {
+		info, err := common.GetFileInfo(path)
+		if err != nil {
+			continue
+		}
+		if newest == nil || info.ModTime.After(newest.ModTime) {
+			newest = info
+		}
+	}
+	return newest
+}
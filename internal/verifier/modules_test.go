@@ -0,0 +1,131 @@
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyModules(t *testing.T) {
+	tmpDir := t.TempDir()
+	ecosystem := &detector.DetectedEcosystem{ID: "java-maven", Config: &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		Reconciliation: config.Reconciliation{Fixes: []config.Fix{{IssueType: "stale_module", Command: "mvn -pl {modules} -am compile"}}},
+	}}}
+	write := func(name, content string, age time.Duration) {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		modTime := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	write("pom.xml", `<project><artifactId>root</artifactId><packaging>pom</packaging>
+  <modules><module>core</module><module>api</module><module>web</module><module>docs</module></modules>
+</project>`, 3*time.Hour)
+	for _, module := range []string{"core", "api", "web", "docs"} {
+		write(module+"/pom.xml", "<project><artifactId>"+module+"</artifactId></project>", 3*time.Hour)
+	}
+	write("core/src/main/java/Core.java", "", 2*time.Hour)
+	write("core/target/classes/Core.class", "", time.Hour)
+	write("api/src/main/java/Api.java", "", 2*time.Hour)
+	write("api/target/classes/Api.class", "", time.Hour)
+	write("web/src/main/java/Web.java", "", 2*time.Hour)
+	write("web/target/classes/Web.class", "", time.Hour)
+
+	cmd := config.VerificationCommand{
+		Name:             "modules",
+		Type:             "module_check",
+		Modules:          config.ModulesMaven,
+		Source:           "pom.xml",
+		TargetPattern:    "target/classes/**/*.class",
+		SourceRoots:      []string{"src/main/java"},
+		SourceExtensions: []string{".java"},
+	}
+
+	// Every module built after its sources; docs has nothing to build
+	issue, err := verifyModules(context.Background(), cmd, tmpDir, ecosystem)
+	require.NoError(t, err)
+	assert.Nil(t, issue)
+
+	// A source edited in core, api never built
+	write("core/src/main/java/Core.java", "", 0)
+	require.NoError(t, os.RemoveAll(filepath.Join(tmpDir, "api", "target")))
+
+	issue, err = verifyModules(context.Background(), cmd, tmpDir, ecosystem)
+	require.NoError(t, err)
+	require.NotNil(t, issue)
+	assert.Equal(t, "stale_module", issue.Type)
+	assert.Equal(t, "2 of 4 modules are out of date: core (core/src/main/java/Core.java changed after core/target/classes/Core.class), api (not built)", issue.Message)
+	assert.Equal(t, "mvn -pl :core,:api -am compile", issue.FixCommand)
+	assert.True(t, issue.FixAvailable)
+	require.Len(t, issue.Evidence.Modules, 2)
+	assert.Equal(t, ":core", issue.Evidence.Modules[0].Module)
+	assert.Nil(t, issue.Evidence.Modules[1].Target)
+
+	// A single-module project
+	single := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(single, "pom.xml"), []byte("<project><artifactId>app</artifactId></project>"), 0644))
+	issue, err = verifyModules(context.Background(), cmd, single, ecosystem)
+	require.NoError(t, err)
+	assert.Nil(t, issue)
+}
+
+func TestVerifyModules_Turbo(t *testing.T) {
+	tmpDir := t.TempDir()
+	ecosystem := &detector.DetectedEcosystem{ID: "turbo", Config: &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		Reconciliation: config.Reconciliation{Fixes: []config.Fix{{IssueType: "stale_module", Command: "npx turbo run build --filter={modules}"}}},
+	}}}
+	write := func(name, content string, age time.Duration) {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		modTime := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	write("package.json", `{"workspaces": ["packages/*"]}`, 3*time.Hour)
+	write("packages/core/package.json", `{"name": "core"}`, 3*time.Hour)
+	write("packages/core/src/index.ts", "", 2*time.Hour)
+	write("packages/ui/package.json", `{"name": "ui", "dependencies": {"core": "*"}}`, 3*time.Hour)
+	write("packages/ui/src/index.ts", "", 2*time.Hour)
+	write("packages/ui/dist/index.js", "", 3*time.Hour) // Restored from the cache with its old time
+
+	// The last build ran an hour ago, ui from the local cache
+	end := time.Now().Add(-time.Hour).UnixMilli()
+	write(".turbo/runs/run.json", fmt.Sprintf(`{"tasks": [
+  {"task": "build", "package": "core", "cache": {"status": "MISS"}, "execution": {"endTime": %d, "exitCode": 0}},
+  {"task": "build", "package": "ui", "cache": {"status": "HIT", "source": "LOCAL"}, "execution": {"endTime": %d, "exitCode": 0}}
+]}`, end, end), 0)
+
+	cmd := config.VerificationCommand{
+		Name:             "packages",
+		Type:             "module_check",
+		Modules:          config.ModulesTurbo,
+		Source:           "package.json",
+		TargetPattern:    "dist/**/*",
+		SourceRoots:      []string{"src"},
+		SourceExtensions: []string{".ts"},
+	}
+
+	issue, err := verifyModules(context.Background(), cmd, tmpDir, ecosystem)
+	require.NoError(t, err)
+	assert.Nil(t, issue)
+
+	// An edit in core makes ui, which depends on it, out of date too
+	write("packages/core/src/index.ts", "", 0)
+	issue, err = verifyModules(context.Background(), cmd, tmpDir, ecosystem)
+	require.NoError(t, err)
+	require.NotNil(t, issue)
+	assert.Equal(t, "2 of 2 modules are out of date: packages/core (packages/core/src/index.ts changed after the last build, a cache miss), packages/ui (depends on packages/core)", issue.Message)
+	assert.Equal(t, "npx turbo run build --filter=core --filter=ui", issue.FixCommand)
+	assert.Equal(t, "miss", issue.Evidence.Modules[0].Cache)
+	assert.Equal(t, ".turbo/runs/run.json", issue.Evidence.Modules[0].Target.Path)
+}
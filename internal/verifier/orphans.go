@@ -0,0 +1,118 @@
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+)
+
+// verifyOrphans finds build outputs that no longer correspond to any source, such as the
+// classes of a deleted source file still in target/classes. An output corresponds to a source
+// at the same path under a source root, with one of the source extensions in place of its own:
+// target/classes/com/example/App$Inner.class to src/main/java/com/example/App.java.
+func verifyOrphans(ctx context.Context, cmd config.VerificationCommand, projectRoot string, ecosystem *detector.DetectedEcosystem) (*Issue, error) {
+	if cmd.Target == "" || len(cmd.SourceExtensions) == 0 {
+		return nil, fmt.Errorf("orphan check %s needs target and source_extensions", cmd.Name)
+	}
+	targetDir := filepath.Join(projectRoot, common.ExpandPattern(cmd.Target))
+	if !common.DirExists(targetDir) {
+		return nil, nil
+	}
+
+	// Without any source root, every output would look orphaned
+	var roots []string
+	for _, root := range sourceRoots(cmd) {
+		dir := filepath.Join(projectRoot, common.ExpandPattern(root))
+		if common.DirExists(dir) {
+			roots = append(roots, dir)
+		}
+	}
+	if len(roots) == 0 {
+		return nil, nil
+	}
+
+	pattern := cmd.TargetPattern
+	if pattern == "" {
+		pattern = filepath.ToSlash(filepath.Join(cmd.Target, "**", "*"))
+	}
+	outputs, err := common.FindFilesByPattern(ctx, filepath.Join(projectRoot, common.ExpandPattern(pattern)))
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []string
+	for _, output := range outputs {
+		rel, err := filepath.Rel(targetDir, output)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if !hasSource(rel, roots, cmd.SourceExtensions) {
+			orphans = append(orphans, output)
+		}
+	}
+	if len(orphans) == 0 {
+		return nil, nil
+	}
+
+	evidence := &Evidence{Orphaned: globEvidence(projectRoot, pattern, orphans)}
+	return &Issue{
+		Type:         "orphaned_artifacts",
+		Severity:     "warning",
+		Message:      fmt.Sprintf("%d build outputs in %s have no source in %s", len(orphans), cmd.Target, strings.Join(sourceRoots(cmd), ", ")),
+		File:         cmd.Target,
+		FixAvailable: getFixCommand(ecosystem, "orphaned_artifacts") != "",
+		FixCommand:   getFixCommand(ecosystem, "orphaned_artifacts"),
+		Evidence:     evidence,
+	}, nil
+}
+
+// sourceRoots returns the directories an orphan check looks for sources in
+func sourceRoots(cmd config.VerificationCommand) []string {
+	if len(cmd.SourceRoots) > 0 {
+		return cmd.SourceRoots
+	}
+	return []string{cmd.Source}
+}
+
+// hasSource reports whether an output, relative to its output directory, has a source
+func hasSource(output string, roots []string, extensions []string) bool {
+	for _, stem := range sourceStems(output) {
+		for _, root := range roots {
+			for _, ext := range extensions {
+				if common.FileExists(filepath.Join(root, stem+ext)) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// sourceStems returns the paths, without extension, of the sources an output may come from:
+// its path with one or more extensions removed (app.min.js, app.min, app), and for a Java
+// nested or anonymous class, the path of its outer class (App$Inner to App)
+func sourceStems(output string) []string {
+	dir, name := filepath.Split(output)
+	if i := strings.IndexByte(name, '$'); i > 0 {
+		name = name[:i]
+	}
+
+	var stems []string
+	for {
+		i := strings.LastIndexByte(name, '.')
+		if i <= 0 {
+			break
+		}
+		name = name[:i]
+		stems = append(stems, dir+name)
+	}
+	if len(stems) == 0 {
+		stems = append(stems, dir+name)
+	}
+	return stems
+}
@@ -0,0 +1,76 @@
+package verifier
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyOrphans(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{
+		"src/main/java/com/example/App.java",
+		"target/generated-sources/annotations/com/example/AppMapperImpl.java",
+		"target/classes/com/example/App.class",
+		"target/classes/com/example/App$Inner.class",
+		"target/classes/com/example/App$1.class",
+		"target/classes/com/example/AppMapperImpl.class",
+		"target/classes/com/example/Deleted.class",
+		"target/classes/com/example/Deleted$Builder.class",
+		"target/classes/application.properties",
+	} {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(""), 0644))
+	}
+
+	ecosystem := &detector.DetectedEcosystem{ID: "java-maven", Config: &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		Reconciliation: config.Reconciliation{Fixes: []config.Fix{{IssueType: "orphaned_artifacts", Command: "mvn clean compile"}}},
+	}}}
+	cmd := config.VerificationCommand{
+		Name:             "orphans",
+		Type:             "orphan_check",
+		Target:           "target/classes",
+		TargetPattern:    "target/classes/**/*.class",
+		SourceRoots:      []string{"src/main/java", "target/generated-sources/annotations"},
+		SourceExtensions: []string{".java"},
+	}
+
+	issue, err := verifyOrphans(context.Background(), cmd, tmpDir, ecosystem)
+	require.NoError(t, err)
+	require.NotNil(t, issue)
+	assert.Equal(t, "orphaned_artifacts", issue.Type)
+	assert.Equal(t, "2 build outputs in target/classes have no source in src/main/java, target/generated-sources/annotations", issue.Message)
+	assert.Equal(t, "mvn clean compile", issue.FixCommand)
+	assert.Equal(t, []string{
+		filepath.Join("target", "classes", "com", "example", "Deleted$Builder.class"),
+		filepath.Join("target", "classes", "com", "example", "Deleted.class"),
+	}, issue.Evidence.Orphaned.Matches)
+
+	// Without any source root, nothing is reported
+	cmd.SourceRoots = []string{"src/main/scala"}
+	issue, err = verifyOrphans(context.Background(), cmd, tmpDir, ecosystem)
+	require.NoError(t, err)
+	assert.Nil(t, issue)
+}
+
+func TestSourceStems(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected []string
+	}{
+		{"com/example/App$Inner.class", []string{"com/example/App"}},
+		{"app.min.js", []string{"app.min", "app"}},
+		{"index.js.map", []string{"index.js", "index"}},
+		{"LICENSE", []string{"LICENSE"}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, sourceStems(filepath.FromSlash(tt.output)), tt.output)
+	}
+}
@@ -0,0 +1,159 @@
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+)
+
+// defaultMaxSpread is how far apart outputs of one build may be when a command sets no max_spread
+const defaultMaxSpread = 10 * time.Minute
+
+// verifyPartialBuild detects build outputs that were only partly regenerated: some outputs
+// were rebuilt after the source (a manifest such as pom.xml) changed while others are older
+// by more than the command's max_spread, or sources are missing their output while the output
+// directory has others. Unlike stale_build, a partial build is fixed by rebuilding, not cleaning.
+func verifyPartialBuild(ctx context.Context, cmd config.VerificationCommand, projectRoot string, ecosystem *detector.DetectedEcosystem) (*Issue, error) {
+	maxSpread := defaultMaxSpread
+	if cmd.MaxSpread != "" {
+		spread, err := time.ParseDuration(cmd.MaxSpread)
+		if err != nil {
+			return nil, err
+		}
+		maxSpread = spread
+	}
+
+	evidence := &Evidence{}
+	var findings []string
+
+	if cmd.TargetPattern != "" && cmd.Source != "" {
+		finding, err := mixedOutputs(ctx, cmd, projectRoot, maxSpread, evidence)
+		if err != nil {
+			return nil, err
+		}
+		if finding != "" {
+			findings = append(findings, finding)
+		}
+	}
+	if cmd.Target != "" && cmd.TargetExtension != "" && len(cmd.SourceExtensions) > 0 {
+		finding, err := missingOutputs(ctx, cmd, projectRoot, evidence)
+		if err != nil {
+			return nil, err
+		}
+		if finding != "" {
+			findings = append(findings, finding)
+		}
+	}
+	if len(findings) == 0 {
+		return nil, nil
+	}
+
+	message := strings.Join(findings, "; ")
+	return &Issue{
+		Type:         "partial_build",
+		Severity:     "error",
+		Message:      strings.ToUpper(message[:1]) + message[1:],
+		File:         firstNonEmpty(cmd.Target, cmd.Source),
+		FixAvailable: getFixCommand(ecosystem, "partial_build") != "",
+		FixCommand:   getFixCommand(ecosystem, "partial_build"),
+		Evidence:     evidence,
+	}, nil
+}
+
+// mixedOutputs reports outputs of which some were rebuilt after the source changed and some
+// not, spanning more than maxSpread
+func mixedOutputs(ctx context.Context, cmd config.VerificationCommand, projectRoot string, maxSpread time.Duration, evidence *Evidence) (string, error) {
+	sourcePath := filepath.Join(projectRoot, common.ExpandPattern(cmd.Source))
+	sourceInfo, err := common.GetFileInfo(sourcePath)
+	if err != nil {
+		return "", nil
+	}
+	matches, err := common.FindFilesByPattern(ctx, filepath.Join(projectRoot, common.ExpandPattern(cmd.TargetPattern)))
+	if err != nil {
+		return "", err
+	}
+
+	var newest, oldest *common.FileInfo
+	var stale []*common.FileInfo
+	for _, match := range matches {
+		info, err := common.GetFileInfo(match)
+		if err != nil {
+			continue
+		}
+		if newest == nil || info.ModTime.After(newest.ModTime) {
+			newest = info
+		}
+		if oldest == nil || info.ModTime.Before(oldest.ModTime) {
+			oldest = info
+		}
+		if sourceInfo.ModTime.After(info.ModTime) {
+			stale = append(stale, info)
+		}
+	}
+
+	// Outputs all older (stale_build) or all newer (fresh) than the source aren't partial
+	if len(stale) == 0 || len(stale) == len(matches) {
+		return "", nil
+	}
+	spread := newest.ModTime.Sub(oldest.ModTime)
+	if spread <= maxSpread {
+		return "", nil
+	}
+
+	evidence.Source = fileEvidence(projectRoot, sourceInfo)
+	evidence.TargetPattern = globEvidence(projectRoot, cmd.TargetPattern, matches)
+	evidence.Selection = selectionEvidence(projectRoot, config.TargetAll, len(matches), stale)
+	evidence.Target = fileEvidence(projectRoot, oldest)
+	return fmt.Sprintf("only %d of %d build outputs matching %s were rebuilt after %s changed (outputs span %s)",
+		len(matches)-len(stale), len(matches), cmd.TargetPattern, cmd.Source, spread.Round(time.Second)), nil
+}
+
+// missingOutputs reports sources without an output in an output directory that has outputs
+func missingOutputs(ctx context.Context, cmd config.VerificationCommand, projectRoot string, evidence *Evidence) (string, error) {
+	targetDir := filepath.Join(projectRoot, common.ExpandPattern(cmd.Target))
+	outputs, err := common.FindFilesByPattern(ctx, filepath.Join(targetDir, "**", "*"+cmd.TargetExtension))
+	if err != nil || len(outputs) == 0 {
+		// No outputs at all is a missing build, not a partial one
+		return "", err
+	}
+
+	var missing []string
+	var patterns []string
+	for _, root := range sourceRoots(cmd) {
+		rootDir := filepath.Join(projectRoot, common.ExpandPattern(root))
+		for _, ext := range cmd.SourceExtensions {
+			pattern := filepath.ToSlash(filepath.Join(root, "**", "*"+ext))
+			patterns = append(patterns, pattern)
+			sources, err := common.FindFilesByPattern(ctx, filepath.Join(rootDir, "**", "*"+ext))
+			if err != nil {
+				return "", err
+			}
+			for _, source := range sources {
+				rel, err := filepath.Rel(rootDir, source)
+				if err != nil {
+					continue
+				}
+				stem := strings.TrimSuffix(rel, ext)
+				// Names that aren't identifiers, such as package-info.java, have no output of their own
+				if strings.Contains(filepath.Base(stem), "-") {
+					continue
+				}
+				if !common.FileExists(filepath.Join(targetDir, stem+cmd.TargetExtension)) {
+					missing = append(missing, source)
+				}
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return "", nil
+	}
+
+	evidence.MissingOutputs = globEvidence(projectRoot, strings.Join(patterns, ", "), missing)
+	return fmt.Sprintf("%d sources have no %s output in %s", len(missing), cmd.TargetExtension, cmd.Target), nil
+}
@@ -0,0 +1,81 @@
+package verifier
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyPartialBuild(t *testing.T) {
+	tmpDir := t.TempDir()
+	ecosystem := &detector.DetectedEcosystem{ID: "java-maven", Config: &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		Reconciliation: config.Reconciliation{Fixes: []config.Fix{{IssueType: "partial_build", Command: "mvn compile"}}},
+	}}}
+	write := func(name string, age time.Duration) {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(""), 0644))
+		modTime := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	write("pom.xml", time.Hour)
+	write("src/main/java/com/example/A.java", 2*time.Hour)
+	write("src/main/java/com/example/B.java", 2*time.Hour)
+	write("src/main/java/com/example/package-info.java", 2*time.Hour)
+	write("target/classes/com/example/A.class", 0)
+	write("target/classes/com/example/B.class", 0)
+
+	cmd := config.VerificationCommand{
+		Name:             "partial",
+		Type:             "partial_build_check",
+		Source:           "pom.xml",
+		Target:           "target/classes",
+		TargetPattern:    "target/classes/**/*.class",
+		SourceRoots:      []string{"src/main/java"},
+		SourceExtensions: []string{".java"},
+		TargetExtension:  ".class",
+		MaxSpread:        "10m",
+	}
+
+	// A complete build after the pom changed
+	issue, err := verifyPartialBuild(context.Background(), cmd, tmpDir, ecosystem)
+	require.NoError(t, err)
+	assert.Nil(t, issue)
+
+	// One class is from a build before the pom changed
+	write("target/classes/com/example/B.class", 3*time.Hour)
+	issue, err = verifyPartialBuild(context.Background(), cmd, tmpDir, ecosystem)
+	require.NoError(t, err)
+	require.NotNil(t, issue)
+	assert.Equal(t, "partial_build", issue.Type)
+	assert.Equal(t, "Only 1 of 2 build outputs matching target/classes/**/*.class were rebuilt after pom.xml changed (outputs span 3h0m0s)", issue.Message)
+	assert.Equal(t, "mvn compile", issue.FixCommand)
+	assert.Equal(t, []string{filepath.Join("target", "classes", "com", "example", "B.class")}, issue.Evidence.Selection.StaleTargets)
+
+	// Outputs close together are one build, and all outputs older is a stale build instead
+	cmd.MaxSpread = "4h"
+	issue, err = verifyPartialBuild(context.Background(), cmd, tmpDir, ecosystem)
+	require.NoError(t, err)
+	assert.Nil(t, issue)
+	cmd.MaxSpread = "10m"
+	write("target/classes/com/example/A.class", 3*time.Hour)
+	issue, err = verifyPartialBuild(context.Background(), cmd, tmpDir, ecosystem)
+	require.NoError(t, err)
+	assert.Nil(t, issue)
+
+	// A source without its class
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, "target", "classes", "com", "example", "B.class")))
+	issue, err = verifyPartialBuild(context.Background(), cmd, tmpDir, ecosystem)
+	require.NoError(t, err)
+	require.NotNil(t, issue)
+	assert.Equal(t, "1 sources have no .class output in target/classes", issue.Message)
+	assert.Equal(t, []string{filepath.Join("src", "main", "java", "com", "example", "B.java")}, issue.Evidence.MissingOutputs.Matches)
+}
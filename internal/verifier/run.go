@@ -0,0 +1,102 @@
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+)
+
+// DefaultMaxParallel is the number of verification commands of an ecosystem run at once
+// unless the config sets build_freshness.max_parallel
+const DefaultMaxParallel = 4
+
+// Statuses of verification commands
+const (
+	StatusPass    = "pass"    // The command found no issue
+	StatusFail    = "fail"    // The command found issues
+	StatusError   = "error"   // The command could not be run; its check is unverified
+	StatusSkipped = "skipped" // The command's type isn't supported
+)
+
+// CommandResult is the outcome of one verification command
+type CommandResult struct {
+	Name      string
+	Type      string
+	Ecosystem string // In combined reports
+	Status    string
+	Issues    int
+	Error     string
+	Duration  time.Duration
+}
+
+// runCommands runs verification commands and returns their issues and results in config
+// order. Commands run concurrently, at most maxParallel at once; serial commands then run
+// one at a time, in order, once the concurrent ones have finished.
+func runCommands(ctx context.Context, commands []config.VerificationCommand, maxParallel int, projectRoot string, ecosystem *detector.DetectedEcosystem) ([][]Issue, []CommandResult) {
+	if maxParallel <= 0 {
+		maxParallel = DefaultMaxParallel
+	}
+	issues := make([][]Issue, len(commands))
+	results := make([]CommandResult, len(commands))
+	run := func(i int) {
+		issues[i], results[i] = runCommand(ctx, commands[i], projectRoot, ecosystem)
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, maxParallel)
+	for i, cmd := range commands {
+		if cmd.Serial {
+			continue
+		}
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			run(i)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, cmd := range commands {
+		if cmd.Serial {
+			run(i)
+		}
+	}
+	return issues, results
+}
+
+// runCommand runs one verification command, recording its status
+func runCommand(ctx context.Context, cmd config.VerificationCommand, projectRoot string, ecosystem *detector.DetectedEcosystem) (issues []Issue, result CommandResult) {
+	result = CommandResult{Name: cmd.Name, Type: cmd.Type}
+	started := time.Now()
+	defer func() {
+		result.Duration = time.Since(started)
+		// A broken check must not take the other commands down with it
+		if r := recover(); r != nil {
+			issues = nil
+			result.Status, result.Error = StatusError, fmt.Sprintf("panic: %v", r)
+		}
+	}()
+
+	if cmd.Type == "command" && len(cmd.Classify) == 0 {
+		// Only commands that classify their results into issues are run
+		result.Status = StatusSkipped
+		return nil, result
+	}
+
+	issues, err := executeVerificationCommand(ctx, cmd, projectRoot, ecosystem)
+	switch {
+	case err != nil:
+		result.Status, result.Error = StatusError, err.Error()
+	case len(issues) > 0:
+		result.Status, result.Issues = StatusFail, len(issues)
+	default:
+		result.Status = StatusPass
+	}
+	return issues, result
+}
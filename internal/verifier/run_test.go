@@ -0,0 +1,73 @@
+package verifier
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyBuildFreshness_CommandStatuses(t *testing.T) {
+	root := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "build"), 0755))
+	for _, name := range []string{"fresh.txt", "stale.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(root, name), []byte(name), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(root, "build", "fresh.out"), []byte("out"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "build", "stale.out"), []byte("out"), 0644))
+	require.NoError(t, os.Chtimes(filepath.Join(root, "fresh.txt"), old, old))
+	require.NoError(t, os.Chtimes(filepath.Join(root, "build", "stale.out"), old, old))
+
+	cfg := &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		ID: "test-ecosystem",
+		Verification: config.Verification{BuildFreshness: config.BuildFreshness{
+			MaxParallel: 2,
+			Commands: []config.VerificationCommand{
+				{Name: "fresh", Type: "timestamp_compare", Source: "fresh.txt", Target: "build/fresh.out"},
+				{Name: "broken", Type: "checksum_compare"},
+				{Name: "stale", Type: "timestamp_compare", Source: "stale.txt", Target: "build/stale.out", Serial: true},
+				{Name: "script", Type: "command", Command: "make check"},
+			},
+		}},
+	}}
+	report, err := VerifyBuildFreshness(context.Background(), root, &detector.DetectedEcosystem{ID: "test-ecosystem", Config: cfg})
+	require.NoError(t, err)
+
+	assert.False(t, report.IsHealthy)
+	require.Len(t, report.Issues, 1, "the broken command doesn't stop the others")
+	require.Len(t, report.Commands, 4)
+
+	statuses := map[string]string{}
+	for _, result := range report.Commands {
+		statuses[result.Name] = result.Status
+	}
+	assert.Equal(t, map[string]string{"fresh": StatusPass, "broken": StatusError, "stale": StatusFail, "script": StatusSkipped}, statuses)
+	assert.Equal(t, "fresh", report.Commands[0].Name, "results are in config order")
+	assert.Contains(t, report.Commands[1].Error, "unknown verification command type: checksum_compare")
+	assert.Equal(t, 1, report.Commands[2].Issues)
+}
+
+func TestRunCommands_ResultsInConfigOrder(t *testing.T) {
+	root := t.TempDir()
+	commands := []config.VerificationCommand{
+		{Name: "a", Type: "timestamp_compare", Source: "missing-a", Target: "x"},
+		{Name: "b", Type: "timestamp_compare", Source: "missing-b", Target: "x", Serial: true},
+		{Name: "c", Type: "timestamp_compare", Source: "missing-c", Target: "x"},
+	}
+	ecosystem := &detector.DetectedEcosystem{ID: "test", Config: &config.EcosystemConfig{}}
+
+	issues, results := runCommands(context.Background(), commands, 1, root, ecosystem)
+	require.Len(t, issues, 3)
+	require.Len(t, results, 3)
+	for i, result := range results {
+		assert.Equal(t, commands[i].Name, result.Name)
+		assert.NotEmpty(t, result.Status)
+	}
+}
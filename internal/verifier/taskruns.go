@@ -0,0 +1,130 @@
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/config"
+)
+
+// taskRun is the last successful run of a task for one project, as the build tool recorded it
+type taskRun struct {
+	End   time.Time
+	Cache string // "local hit", "remote hit" or "miss"
+	File  string // The record
+}
+
+// nxRunFiles are where Nx records its last run, the current location first
+var nxRunFiles = []string{".nx/cache/run.json", "node_modules/.cache/nx/run.json"}
+
+// turboRunsPattern matches the run summaries turbo run --summarize writes
+const turboRunsPattern = ".turbo/runs/*.json"
+
+// nxRun is the part of an Nx run record that lists its tasks
+type nxRun struct {
+	Tasks []struct {
+		Target      string `json:"target"`
+		ProjectName string `json:"projectName"`
+		EndTime     int64  `json:"endTime"` // Unix milliseconds
+		CacheStatus string `json:"cacheStatus"`
+		Status      int    `json:"status"`
+	} `json:"tasks"`
+}
+
+// turboRun is the part of a turbo run summary that lists its tasks
+type turboRun struct {
+	Tasks []struct {
+		Task    string `json:"task"`
+		Package string `json:"package"`
+		Cache   struct {
+			Status string `json:"status"` // HIT or MISS
+			Source string `json:"source"` // LOCAL or REMOTE
+		} `json:"cache"`
+		Execution struct {
+			EndTime  int64 `json:"endTime"` // Unix milliseconds
+			ExitCode int   `json:"exitCode"`
+		} `json:"execution"`
+	} `json:"tasks"`
+}
+
+// taskRuns returns the last successful run of a task per project name, from the records the
+// build tool of a module layout keeps. Layouts without records, and records the tool wrote in a
+// format this doesn't know, have none.
+func taskRuns(ctx context.Context, projectRoot, layout, task string) (map[string]taskRun, error) {
+	runs := make(map[string]taskRun)
+	record := func(project string, run taskRun) {
+		if last, ok := runs[project]; !ok || run.End.After(last.End) {
+			runs[project] = run
+		}
+	}
+
+	switch layout {
+	case config.ModulesNx:
+		for _, file := range nxRunFiles {
+			path := filepath.Join(projectRoot, filepath.FromSlash(file))
+			if !common.FileExists(path) {
+				continue
+			}
+			data, err := common.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			var run nxRun
+			if json.Unmarshal(data, &run) != nil {
+				continue
+			}
+			for _, t := range run.Tasks {
+				if t.Target != task || t.Status != 0 || t.EndTime == 0 {
+					continue
+				}
+				record(t.ProjectName, taskRun{End: time.UnixMilli(t.EndTime), Cache: nxCacheStatus(t.CacheStatus), File: path})
+			}
+		}
+
+	case config.ModulesTurbo:
+		paths, err := common.FindFilesByPattern(ctx, filepath.Join(projectRoot, filepath.FromSlash(turboRunsPattern)))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			data, err := common.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			var run turboRun
+			if json.Unmarshal(data, &run) != nil {
+				continue
+			}
+			for _, t := range run.Tasks {
+				if t.Task != task || t.Execution.ExitCode != 0 || t.Execution.EndTime == 0 {
+					continue
+				}
+				cache := "miss"
+				if strings.EqualFold(t.Cache.Status, "HIT") {
+					cache = "local hit"
+					if strings.EqualFold(t.Cache.Source, "REMOTE") {
+						cache = "remote hit"
+					}
+				}
+				record(t.Package, taskRun{End: time.UnixMilli(t.Execution.EndTime), Cache: cache, File: path})
+			}
+		}
+	}
+	return runs, nil
+}
+
+// nxCacheStatus names an Nx cache status like turbo's
+func nxCacheStatus(status string) string {
+	switch status {
+	case "local-cache-hit", "local-cache-kept-existing":
+		return "local hit"
+	case "remote-cache-hit":
+		return "remote hit"
+	default:
+		return "miss"
+	}
+}
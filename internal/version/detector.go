@@ -3,12 +3,12 @@ package version
 import (
 	"context"
 	"fmt"
-	"os/exec"
 	"regexp"
 	"strings"
 	"time"
 
 	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/runner"
 )
 
 // VersionInfo contains detected version information
@@ -31,7 +31,8 @@ type RuntimeVariantInfo struct {
 	FullName string
 }
 
-// DetectVersion detects the current language version
+// DetectVersion detects the current language version. The version_args of the config run
+// without a shell; otherwise version_command runs with sh.
 func DetectVersion(ctx context.Context, cfg *config.EcosystemConfig) (*VersionInfo, error) {
 	versionCfg := cfg.Ecosystem.VersionConfig
 	
@@ -39,8 +40,7 @@ func DetectVersion(ctx context.Context, cfg *config.EcosystemConfig) (*VersionIn
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "sh", "-c", versionCfg.VersionCommand)
-	output, err := cmd.CombinedOutput()
+	output, err := runVersionCommand(ctx, versionCfg, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute version command: %w", err)
 	}
@@ -80,6 +80,23 @@ func DetectVersion(ctx context.Context, cfg *config.EcosystemConfig) (*VersionIn
 	return info, nil
 }
 
+// runVersionCommand runs the version command of a config, with program in place of the one it
+// names unless program is empty
+func runVersionCommand(ctx context.Context, versionCfg config.VersionConfig, program string) ([]byte, error) {
+	if len(versionCfg.VersionArgs) > 0 {
+		argv := versionCfg.VersionArgs
+		if program != "" {
+			argv = append([]string{program}, argv[1:]...)
+		}
+		return runner.RunArgs(ctx, "", argv)
+	}
+	command := versionCfg.VersionCommand
+	if program != "" {
+		command = runner.ShellQuote(program) + strings.TrimPrefix(strings.TrimSpace(command), Program(versionCfg))
+	}
+	return runner.Run(ctx, "", command)
+}
+
 // ParsedVersion contains parsed version components
 type ParsedVersion struct {
 	Full      string
@@ -174,8 +191,7 @@ func detectRuntimeVariant(output string, versionCfg config.VersionConfig) (*Runt
 func detectVersionManager(ctx context.Context, versionCfg config.VersionConfig) string {
 	for _, manager := range versionCfg.VersionManagers {
 		ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
-		cmd := exec.CommandContext(ctx, "sh", "-c", manager.CheckCommand)
-		err := cmd.Run()
+		_, err := runner.Run(ctx, "", manager.CheckCommand)
 		cancel()
 		
 		if err == nil {
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/runner"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -264,3 +265,30 @@ func TestDetectVersion_InvalidPattern(t *testing.T) {
 	assert.Error(t, err)
 }
 
+
+func TestDetectVersion_Args(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires printf")
+	}
+
+	cfg := &config.EcosystemConfig{
+		Ecosystem: config.Ecosystem{
+			VersionConfig: config.VersionConfig{
+				Language:       "java",
+				VersionArgs:    []string{"printf", `openjdk version "%s"`, "21.0.2"},
+				VersionPattern: `openjdk version "([^"]+)"`,
+			},
+		},
+	}
+
+	info, err := DetectVersion(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "21.0.2", info.Version)
+
+	// A missing program is reported with the PATH that was searched
+	cfg.Ecosystem.VersionConfig.VersionArgs = []string{"sentinel-no-such-java", "-version"}
+	_, err = DetectVersion(context.Background(), cfg)
+	var notFound *runner.ErrNotFound
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, "sentinel-no-such-java", notFound.Program)
+}
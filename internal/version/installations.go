@@ -0,0 +1,191 @@
+package version
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/common"
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/runner"
+)
+
+// SourcePath is the source of installations found only on the PATH
+const SourcePath = "PATH"
+
+// Installation is an installed copy of a language's program
+type Installation struct {
+	Path           string // The program, e.g. /usr/lib/jvm/java-17-openjdk/bin/java
+	Source         string // The source of the install location it was found in, or PATH
+	Name           string // Directory the location's first wildcard matched, e.g. 17.0.9-tem; how a version manager names the version
+	Version        string // Semantic version; empty when it couldn't be detected
+	RuntimeVariant *RuntimeVariantInfo
+	Active         bool   // The one the version command runs, first on the PATH
+	Error          string // Why the version couldn't be detected
+}
+
+// Program returns the program a version config runs, e.g. "java"
+func Program(versionCfg config.VersionConfig) string {
+	if len(versionCfg.VersionArgs) > 0 {
+		return versionCfg.VersionArgs[0]
+	}
+	if fields := strings.Fields(versionCfg.VersionCommand); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// FindInstallations finds the copies of an ecosystem's language program on this machine: those
+// on the PATH and in the config's install locations, each with its version. Links to the same
+// program are listed once, with the source of its install location. Only this machine is
+// searched, so nothing is found when commands run with another executor.
+func FindInstallations(ctx context.Context, cfg *config.EcosystemConfig) []Installation {
+	versionCfg := cfg.Ecosystem.VersionConfig
+	program := Program(versionCfg)
+	if program == "" || !runner.IsLocal(runner.ExecutorFrom(ctx)) {
+		return nil
+	}
+
+	var installations []Installation
+	index := make(map[string]int) // By resolved path
+	add := func(path string, found Installation) {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			resolved = path
+		}
+		if i, ok := index[resolved]; ok {
+			if installations[i].Source == SourcePath {
+				// The PATH points into an install location, which says what installed it
+				installations[i].Source = found.Source
+				installations[i].Name = found.Name
+			}
+			return
+		}
+		index[resolved] = len(installations)
+		installations = append(installations, found)
+	}
+
+	if !strings.ContainsRune(program, '/') && !strings.ContainsRune(program, filepath.Separator) {
+		for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+			if dir == "" {
+				continue
+			}
+			path, err := exec.LookPath(filepath.Join(dir, program))
+			if err != nil {
+				continue
+			}
+			add(path, Installation{Path: path, Source: SourcePath, Active: len(installations) == 0})
+		}
+	}
+
+	for _, location := range versionCfg.InstallLocations {
+		pattern := common.ExpandPattern(location.Path)
+		paths, err := common.FindFilesByPattern(ctx, pattern)
+		if err != nil {
+			continue
+		}
+		for _, path := range paths {
+			add(path, Installation{Path: path, Source: location.Source, Name: wildcardMatch(pattern, path)})
+		}
+	}
+
+	for i := range installations {
+		installations[i].detect(ctx, versionCfg)
+	}
+	return installations
+}
+
+// detect runs the version command with the installation's program
+func (inst *Installation) detect(ctx context.Context, versionCfg config.VersionConfig) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	output, err := runVersionCommand(ctx, versionCfg, inst.Path)
+	if err != nil {
+		inst.Error = err.Error()
+		return
+	}
+	parsed, err := parseVersion(strings.TrimSpace(string(output)), versionCfg.VersionPattern)
+	if err != nil {
+		inst.Error = err.Error()
+		return
+	}
+	inst.Version = parsed.Semantic
+	if versionCfg.RuntimePattern != "" {
+		inst.RuntimeVariant, _ = detectRuntimeVariant(string(output), versionCfg)
+	}
+}
+
+// SwitchCommand returns the command that makes the installation the active one: the switch
+// command of the version manager that installed it, else putting its directory first on the PATH
+func (inst Installation) SwitchCommand(versionCfg config.VersionConfig) string {
+	for _, manager := range versionCfg.VersionManagers {
+		if manager.Name != inst.Source || manager.SwitchCommand == "" {
+			continue
+		}
+		version := inst.Name
+		if version == "" {
+			version = inst.Version
+		}
+		return strings.ReplaceAll(manager.SwitchCommand, "{version}", version)
+	}
+	return "export PATH=" + runner.ShellQuote(filepath.Dir(inst.Path)) + `:"$PATH"`
+}
+
+// BetterInstallation returns an installation that fits the config's requirements better than
+// the active version: one that meets them when the active one doesn't, or is preferred when the
+// active one isn't. Among several, preferred versions win in their order, then newer ones.
+// Active is nil when no version is active, e.g. when the program isn't on the PATH.
+func BetterInstallation(installations []Installation, active *VersionInfo, cfg *config.EcosystemConfig) *Installation {
+	activeRank := len(cfg.Ecosystem.Requirements.PreferredVersions) + 1 // Neither valid nor preferred
+	if active != nil {
+		activeRank = installationRank(active, cfg)
+	}
+
+	var best *Installation
+	bestRank := activeRank
+	for i := range installations {
+		inst := &installations[i]
+		if inst.Active || inst.Version == "" {
+			continue
+		}
+		rank := installationRank(&VersionInfo{Version: inst.Version, RuntimeVariant: inst.RuntimeVariant}, cfg)
+		if rank < bestRank || (best != nil && rank == bestRank && compareVersions(inst.Version, best.Version) > 0) {
+			best, bestRank = inst, rank
+		}
+	}
+	return best
+}
+
+// installationRank ranks a version against requirements: the index of the first preferred
+// version it matches, then valid versions, then the rest
+func installationRank(info *VersionInfo, cfg *config.EcosystemConfig) int {
+	preferred := cfg.Ecosystem.Requirements.PreferredVersions
+	if !ValidateVersion(info, cfg).IsValid {
+		return len(preferred) + 1
+	}
+	for i, version := range preferred {
+		if info.Version == version || strings.HasPrefix(info.Version, version+".") {
+			return i
+		}
+	}
+	return len(preferred)
+}
+
+// wildcardMatch returns the path element matched by the first element of a pattern with a
+// wildcard, e.g. 17.0.9-tem for $HOME/.sdkman/candidates/java/*/bin/java
+func wildcardMatch(pattern, path string) string {
+	patternParts := strings.Split(filepath.ToSlash(pattern), "/")
+	pathParts := strings.Split(filepath.ToSlash(path), "/")
+	for i, part := range patternParts {
+		if part == "**" || i >= len(pathParts) {
+			return ""
+		}
+		if strings.ContainsAny(part, "*?[") {
+			return pathParts[i]
+		}
+	}
+	return ""
+}
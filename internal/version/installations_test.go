@@ -0,0 +1,100 @@
+package version
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"dev-env-sentinel/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindInstallations(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	tmpDir := t.TempDir()
+	program := func(path, version string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\necho 'openjdk version \""+version+"\"' >&2\n"), 0755))
+	}
+	program(filepath.Join(tmpDir, "jdks", "11.0.2-tem", "bin", "fakejava"), "11.0.2")
+	program(filepath.Join(tmpDir, "jdks", "17.0.9-tem", "bin", "fakejava"), "17.0.9")
+	program(filepath.Join(tmpDir, "jdks", "21.0.2-tem", "bin", "fakejava"), "21.0.2")
+	program(filepath.Join(tmpDir, "opt", "bin", "fakejava"), "17.0.1")
+
+	// The PATH links to the oldest managed copy
+	binDir := filepath.Join(tmpDir, "bin")
+	require.NoError(t, os.MkdirAll(binDir, 0755))
+	require.NoError(t, os.Symlink(filepath.Join(tmpDir, "jdks", "11.0.2-tem", "bin", "fakejava"), filepath.Join(binDir, "fakejava")))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+filepath.Join(tmpDir, "opt", "bin"))
+	t.Setenv("TEST_JDKS", filepath.Join(tmpDir, "jdks"))
+
+	cfg := &config.EcosystemConfig{Ecosystem: config.Ecosystem{
+		VersionConfig: config.VersionConfig{
+			Language:       "java",
+			VersionArgs:    []string{"fakejava", "-version"},
+			VersionPattern: `openjdk version "([^"]+)"`,
+			VersionManagers: []config.VersionManager{
+				{Name: "sdkman", SwitchCommand: "sdk use java {version}"},
+			},
+			InstallLocations: []config.InstallLocation{
+				{Source: "sdkman", Path: "$TEST_JDKS/*/bin/fakejava"},
+			},
+		},
+		Requirements: config.Requirements{MinVersion: "17", PreferredVersions: []string{"21"}},
+	}}
+
+	installations := FindInstallations(context.Background(), cfg)
+	require.Len(t, installations, 4)
+	assert.Equal(t, Installation{Path: filepath.Join(binDir, "fakejava"), Source: "sdkman", Name: "11.0.2-tem", Version: "11.0.2", Active: true}, installations[0])
+	assert.Equal(t, SourcePath, installations[1].Source)
+	assert.Equal(t, "17.0.1", installations[1].Version)
+	assert.Equal(t, "17.0.9-tem", installations[2].Name)
+	assert.Equal(t, "21.0.2", installations[3].Version)
+
+	// The active 11 is too old; 21 is preferred over the newest 17
+	active := &VersionInfo{Version: "11.0.2"}
+	better := BetterInstallation(installations, active, cfg)
+	require.NotNil(t, better)
+	assert.Equal(t, "21.0.2", better.Version)
+	assert.Equal(t, "sdk use java 21.0.2-tem", better.SwitchCommand(cfg.Ecosystem.VersionConfig))
+
+	// Without a preference the newest supported copy wins
+	cfg.Ecosystem.Requirements.PreferredVersions = nil
+	cfg.Ecosystem.Requirements.MaxVersion = "20"
+	better = BetterInstallation(installations, active, cfg)
+	require.NotNil(t, better)
+	assert.Equal(t, "17.0.9", better.Version)
+
+	// A supported active version has nothing better
+	assert.Nil(t, BetterInstallation(installations, &VersionInfo{Version: "17.0.1"}, cfg))
+
+	// Copies outside a version manager are switched to with the PATH
+	assert.Equal(t, "export PATH='"+filepath.Join(tmpDir, "opt", "bin")+`':"$PATH"`, installations[1].SwitchCommand(cfg.Ecosystem.VersionConfig))
+}
+
+func TestFindInstallations_VersionCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	binDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "fakenode"), []byte("#!/bin/sh\necho v20.11.1\n"), 0755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cfg := &config.EcosystemConfig{Ecosystem: config.Ecosystem{VersionConfig: config.VersionConfig{
+		Language:       "node",
+		VersionCommand: "fakenode --version 2>&1",
+		VersionPattern: `v(\d+\.\d+\.\d+)`,
+	}}}
+
+	installations := FindInstallations(context.Background(), cfg)
+	require.Len(t, installations, 1)
+	assert.Equal(t, "20.11.1", installations[0].Version)
+	assert.True(t, installations[0].Active)
+}
@@ -0,0 +1,61 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"dev-env-sentinel/internal/runner"
+)
+
+// ToolCommands are the commands that print the version of a tool, by tool name
+var ToolCommands = map[string]string{
+	"node":   "node --version",
+	"java":   "java -version 2>&1",
+	"maven":  "mvn --version",
+	"gradle": "gradle --version",
+	"python": "python3 --version 2>&1 || python --version 2>&1",
+	"go":     "go version",
+	"dotnet": "dotnet --version",
+	"ruby":   "ruby --version",
+	"php":    "php --version",
+	"rust":   "rustc --version",
+	"yarn":   "yarn --version",
+	"pnpm":   "pnpm --version",
+	"npm":    "npm --version",
+	"poetry": "poetry --version",
+	"pipenv": "pipenv --version",
+}
+
+// toolVersionNumber matches the first version number in a tool's output
+var toolVersionNumber = regexp.MustCompile(`\d+(?:\.\d+)+|\d+`)
+
+// DetectToolVersion returns the version of a tool on the PATH, for tools that aren't the
+// language of an ecosystem config
+func DetectToolVersion(ctx context.Context, tool string) (string, error) {
+	command, ok := ToolCommands[tool]
+	if !ok {
+		return "", fmt.Errorf("no version command for %s", tool)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	output, err := runner.Run(ctx, "", command)
+	if err != nil {
+		return "", err
+	}
+	match := toolVersionNumber.FindString(string(output))
+	if match == "" {
+		return "", fmt.Errorf("no version in the output of %s", command)
+	}
+	return match, nil
+}
+
+// ToolBinary returns the executable a tool's version command runs, e.g. "mvn" for maven
+func ToolBinary(tool string) string {
+	if fields := strings.Fields(ToolCommands[tool]); len(fields) > 0 {
+		return fields[0]
+	}
+	return tool
+}
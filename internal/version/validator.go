@@ -138,6 +138,12 @@ func versionLessOrEqual(v1, v2 string) bool {
 	return compareVersions(v1, v2) <= 0
 }
 
+// Compare compares two dotted versions numerically: -1 if v1 < v2, 0 if equal, 1 if v1 > v2.
+// Missing parts count as zero, so "17" equals "17.0.0".
+func Compare(v1, v2 string) int {
+	return compareVersions(v1, v2)
+}
+
 // compareVersions compares two semantic versions
 // Returns: -1 if v1 < v2, 0 if v1 == v2, 1 if v1 > v2
 func compareVersions(v1, v2 string) int {
@@ -0,0 +1,384 @@
+package wrapper
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"dev-env-sentinel/internal/common"
+)
+
+// Issue types reported by the wrapper check
+const (
+	IssueMissingFile     = "wrapper_missing_file"
+	IssueNotExecutable   = "wrapper_not_executable"
+	IssueCorruptJar      = "wrapper_corrupt_jar"
+	IssueChecksum        = "wrapper_checksum_mismatch"
+	IssueDistributionURL = "wrapper_distribution_url"
+	IssueVersionMismatch = "wrapper_version_mismatch"
+)
+
+// Issue is a wrapper problem with an optional fix
+type Issue struct {
+	Type       string
+	File       string
+	Message    string
+	FixCommand string
+}
+
+// Wrapper describes a build tool wrapper found in the project
+type Wrapper struct {
+	Tool            string // "maven" or "gradle"
+	Script          string
+	Properties      string
+	Jar             string
+	DistributionURL string
+	Version         string // Version from the distribution URL
+}
+
+// WrapperReport contains wrapper integrity results
+type WrapperReport struct {
+	Wrappers  []Wrapper
+	IsHealthy bool
+	Issues    []Issue
+}
+
+// spec describes the layout of a wrapper
+type spec struct {
+	tool       string
+	script     string
+	properties string
+	jar        string
+	mainClass  string // Entry in the jar that proves it is a wrapper jar
+	urlVersion *regexp.Regexp
+	distsDir   func(home string) string
+}
+
+var (
+	mavenSpec = spec{
+		tool:       "maven",
+		script:     "mvnw",
+		properties: filepath.Join(".mvn", "wrapper", "maven-wrapper.properties"),
+		jar:        filepath.Join(".mvn", "wrapper", "maven-wrapper.jar"),
+		mainClass:  "org/apache/maven/wrapper/MavenWrapperMain.class",
+		urlVersion: regexp.MustCompile(`/apache-maven/([^/]+)/apache-maven-([^/]+)-bin\.(?:zip|tar\.gz)$`),
+		distsDir:   func(home string) string { return filepath.Join(home, ".m2", "wrapper", "dists") },
+	}
+	gradleSpec = spec{
+		tool:       "gradle",
+		script:     "gradlew",
+		properties: filepath.Join("gradle", "wrapper", "gradle-wrapper.properties"),
+		jar:        filepath.Join("gradle", "wrapper", "gradle-wrapper.jar"),
+		mainClass:  "org/gradle/wrapper/GradleWrapperMain.class",
+		urlVersion: regexp.MustCompile(`/gradle-([^/]+?)-(?:bin|all)\.zip$`),
+		distsDir: func(home string) string {
+			if gradleHome := os.Getenv("GRADLE_USER_HOME"); gradleHome != "" {
+				return filepath.Join(gradleHome, "wrapper", "dists")
+			}
+			return filepath.Join(home, ".gradle", "wrapper", "dists")
+		},
+	}
+)
+
+// gradleVersionDecl matches `gradleVersion = "8.5"` in a wrapper task
+var gradleVersionDecl = regexp.MustCompile(`gradleVersion\s*=\s*['"]([^'"]+)['"]`)
+
+// CheckWrappers verifies the Maven and Gradle wrappers of a project: the scripts, jar and
+// properties are present, the scripts are executable, the jar is intact, and the
+// distribution URL, version and checksums agree
+func CheckWrappers(projectRoot string) (*WrapperReport, error) {
+	report := &WrapperReport{
+		Wrappers:  []Wrapper{},
+		IsHealthy: true,
+		Issues:    []Issue{},
+	}
+
+	for _, s := range []spec{mavenSpec, gradleSpec} {
+		if err := s.check(projectRoot, report); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// add records an issue and marks the report unhealthy
+func (r *WrapperReport) add(issue Issue) {
+	r.Issues = append(r.Issues, issue)
+	r.IsHealthy = false
+}
+
+// check verifies one wrapper; projects without any of its files are skipped
+func (s spec) check(projectRoot string, report *WrapperReport) error {
+	script := filepath.Join(projectRoot, s.script)
+	propsPath := filepath.Join(projectRoot, s.properties)
+	jarPath := filepath.Join(projectRoot, s.jar)
+
+	if !common.FileExists(script) && !common.FileExists(propsPath) && !common.FileExists(jarPath) {
+		return nil
+	}
+
+	w := Wrapper{Tool: s.tool, Script: script, Properties: propsPath, Jar: jarPath}
+
+	var props map[string]string
+	if common.FileExists(propsPath) {
+		var err error
+		props, err = readProperties(propsPath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", propsPath, err)
+		}
+		w.DistributionURL = props["distributionUrl"]
+		if m := s.urlVersion.FindStringSubmatch(w.DistributionURL); m != nil {
+			w.Version = m[len(m)-1]
+		}
+	}
+	report.Wrappers = append(report.Wrappers, w)
+
+	regenerate := s.regenerateCommand(w.Version)
+
+	for _, file := range []string{s.script, s.properties} {
+		if !common.FileExists(filepath.Join(projectRoot, file)) {
+			report.add(Issue{
+				Type:       IssueMissingFile,
+				File:       filepath.Join(projectRoot, file),
+				Message:    fmt.Sprintf("%s wrapper is incomplete: %s is missing", s.tool, filepath.ToSlash(file)),
+				FixCommand: regenerate,
+			})
+		}
+	}
+
+	if common.FileExists(script) && runtime.GOOS != "windows" {
+		if info, err := common.Stat(script); err == nil && info.Mode().Perm()&0111 == 0 {
+			report.add(Issue{
+				Type:       IssueNotExecutable,
+				File:       script,
+				Message:    fmt.Sprintf("%s is not executable", s.script),
+				FixCommand: fmt.Sprintf("chmod +x %s", s.script),
+			})
+		}
+	}
+
+	// Maven's script-only wrapper downloads everything itself and ships no jar
+	scriptOnly := s.tool == "maven" && props["distributionType"] == "only-script"
+	if !scriptOnly {
+		s.checkJar(jarPath, props, regenerate, report)
+	}
+
+	if props != nil {
+		s.checkDistribution(projectRoot, w, props, regenerate, report)
+	}
+	return nil
+}
+
+// checkJar verifies the wrapper jar is present, a readable zip containing the wrapper
+// main class, and matches wrapperSha256Sum when the properties declare one
+func (s spec) checkJar(jarPath string, props map[string]string, regenerate string, report *WrapperReport) {
+	rel := filepath.ToSlash(s.jar)
+	if !common.FileExists(jarPath) {
+		report.add(Issue{
+			Type:       IssueMissingFile,
+			File:       jarPath,
+			Message:    fmt.Sprintf("%s wrapper is incomplete: %s is missing (check it is not excluded by .gitignore)", s.tool, rel),
+			FixCommand: regenerate,
+		})
+		return
+	}
+
+	if err := verifyJar(jarPath, s.mainClass); err != nil {
+		report.add(Issue{
+			Type:       IssueCorruptJar,
+			File:       jarPath,
+			Message:    fmt.Sprintf("%s is corrupted: %v (a Git LFS pointer or CRLF conversion can cause this)", rel, err),
+			FixCommand: regenerate,
+		})
+		return
+	}
+
+	if expected := props["wrapperSha256Sum"]; expected != "" {
+		if actual, err := sha256File(jarPath); err == nil && !strings.EqualFold(actual, expected) {
+			report.add(Issue{
+				Type:       IssueChecksum,
+				File:       jarPath,
+				Message:    fmt.Sprintf("%s checksum %s does not match wrapperSha256Sum %s", rel, actual, expected),
+				FixCommand: regenerate,
+			})
+		}
+	}
+}
+
+// checkDistribution verifies the distribution URL, its version against the build's
+// declared version, and the checksum of an already downloaded distribution
+func (s spec) checkDistribution(projectRoot string, w Wrapper, props map[string]string, regenerate string, report *WrapperReport) {
+	rel := filepath.ToSlash(s.properties)
+	if w.DistributionURL == "" {
+		report.add(Issue{
+			Type:       IssueDistributionURL,
+			File:       w.Properties,
+			Message:    fmt.Sprintf("%s does not declare a distributionUrl", rel),
+			FixCommand: regenerate,
+		})
+		return
+	}
+	if !strings.HasPrefix(w.DistributionURL, "https://") && !strings.HasPrefix(w.DistributionURL, "http://") && !strings.HasPrefix(w.DistributionURL, "file:") {
+		report.add(Issue{
+			Type:    IssueDistributionURL,
+			File:    w.Properties,
+			Message: fmt.Sprintf("%s has an invalid distributionUrl: %s", rel, w.DistributionURL),
+		})
+		return
+	}
+
+	// Maven URLs repeat the version in the directory and file name; they must agree
+	if m := s.urlVersion.FindStringSubmatch(w.DistributionURL); m != nil && len(m) == 3 && m[1] != m[2] {
+		report.add(Issue{
+			Type:       IssueVersionMismatch,
+			File:       w.Properties,
+			Message:    fmt.Sprintf("%s distributionUrl mixes versions %s and %s", rel, m[1], m[2]),
+			FixCommand: s.regenerateCommand(m[2]),
+		})
+	}
+
+	if declared := s.declaredVersion(projectRoot); declared != "" && w.Version != "" && declared != w.Version {
+		report.add(Issue{
+			Type:       IssueVersionMismatch,
+			File:       w.Properties,
+			Message:    fmt.Sprintf("%s wrapper uses %s but the build declares %s", s.tool, w.Version, declared),
+			FixCommand: s.regenerateCommand(declared),
+		})
+	}
+
+	expected := props["distributionSha256Sum"]
+	if expected == "" {
+		return
+	}
+	if _, err := hex.DecodeString(expected); err != nil || len(expected) != 64 {
+		report.add(Issue{
+			Type:    IssueChecksum,
+			File:    w.Properties,
+			Message: fmt.Sprintf("%s has a malformed distributionSha256Sum: %s", rel, expected),
+		})
+		return
+	}
+
+	// Only a distribution that was already downloaded can be verified offline
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	archive := findDistribution(s.distsDir(home), filepath.Base(w.DistributionURL))
+	if archive == "" {
+		return
+	}
+	if actual, err := sha256File(archive); err == nil && !strings.EqualFold(actual, expected) {
+		report.add(Issue{
+			Type:       IssueChecksum,
+			File:       archive,
+			Message:    fmt.Sprintf("Downloaded %s does not match distributionSha256Sum (got %s); the cached distribution is corrupted or the checksum is for another version", filepath.Base(archive), actual),
+			FixCommand: fmt.Sprintf("rm -rf %s", filepath.Dir(archive)),
+		})
+	}
+}
+
+// declaredVersion returns the tool version the build itself asks for, if any
+func (s spec) declaredVersion(projectRoot string) string {
+	if s.tool != "gradle" {
+		return ""
+	}
+	for _, name := range []string{"build.gradle.kts", "build.gradle"} {
+		content, err := common.ReadFile(filepath.Join(projectRoot, name))
+		if err != nil {
+			continue
+		}
+		if m := gradleVersionDecl.FindSubmatch(content); m != nil {
+			return string(m[1])
+		}
+	}
+	return ""
+}
+
+// regenerateCommand returns the command that regenerates the wrapper
+func (s spec) regenerateCommand(version string) string {
+	switch s.tool {
+	case "maven":
+		if version == "" {
+			return "mvn -N wrapper:wrapper"
+		}
+		return fmt.Sprintf("mvn -N wrapper:wrapper -Dmaven=%s", version)
+	default:
+		if version == "" {
+			return "gradle wrapper"
+		}
+		return fmt.Sprintf("gradle wrapper --gradle-version %s", version)
+	}
+}
+
+// verifyJar checks a jar is a readable zip that contains mainClass
+func verifyJar(path, mainClass string) error {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("not a valid jar: %w", err)
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.Name == mainClass {
+			return nil
+		}
+	}
+	return fmt.Errorf("missing %s", mainClass)
+}
+
+// findDistribution finds a downloaded distribution archive under the wrapper dists directory
+func findDistribution(distsDir, archiveName string) string {
+	dir := filepath.Join(distsDir, strings.TrimSuffix(strings.TrimSuffix(archiveName, ".zip"), ".tar.gz"))
+	matches, _ := filepath.Glob(filepath.Join(dir, "*", archiveName))
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[0]
+}
+
+// readProperties reads a Java .properties file, unescaping \: and \= in values
+func readProperties(path string) (map[string]string, error) {
+	content, err := common.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	props := make(map[string]string)
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		idx := strings.IndexAny(line, "=:")
+		if idx <= 0 {
+			continue
+		}
+		value := strings.TrimSpace(line[idx+1:])
+		value = strings.NewReplacer(`\:`, ":", `\=`, "=", `\\`, `\`).Replace(value)
+		props[strings.TrimSpace(line[:idx])] = value
+	}
+	return props, nil
+}
+
+// sha256File returns the hex SHA-256 of a file
+func sha256File(path string) (string, error) {
+	f, err := common.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,201 @@
+package wrapper
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const gradleURL = `https\://services.gradle.org/distributions/gradle-8.5-bin.zip`
+
+func writeFile(t *testing.T, path, content string, mode os.FileMode) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), mode))
+	require.NoError(t, os.Chmod(path, mode))
+}
+
+// writeJar writes a jar containing the given entries
+func writeJar(t *testing.T, path string, entries ...string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for _, entry := range entries {
+		w, err := zw.Create(entry)
+		require.NoError(t, err)
+		_, err = w.Write([]byte("class"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+}
+
+// gradleProject writes a complete Gradle wrapper with the given properties
+func gradleProject(t *testing.T, properties string) string {
+	t.Helper()
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "gradlew"), "#!/bin/sh\n", 0755)
+	writeFile(t, filepath.Join(root, gradleSpec.properties), properties, 0644)
+	writeJar(t, filepath.Join(root, gradleSpec.jar), gradleSpec.mainClass)
+	return root
+}
+
+func issueTypes(report *WrapperReport) []string {
+	var types []string
+	for _, issue := range report.Issues {
+		types = append(types, issue.Type)
+	}
+	return types
+}
+
+func TestCheckWrappers_Healthy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires POSIX file modes")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GRADLE_USER_HOME", "")
+
+	root := gradleProject(t, "distributionUrl="+gradleURL+"\n")
+	writeFile(t, filepath.Join(root, "build.gradle.kts"), "tasks.wrapper { gradleVersion = \"8.5\" }\n", 0644)
+
+	report, err := CheckWrappers(root)
+	require.NoError(t, err)
+	assert.True(t, report.IsHealthy, report.Issues)
+	require.Len(t, report.Wrappers, 1)
+	assert.Equal(t, "gradle", report.Wrappers[0].Tool)
+	assert.Equal(t, "8.5", report.Wrappers[0].Version)
+	assert.Equal(t, "https://services.gradle.org/distributions/gradle-8.5-bin.zip", report.Wrappers[0].DistributionURL)
+
+	empty, err := CheckWrappers(t.TempDir())
+	require.NoError(t, err)
+	assert.True(t, empty.IsHealthy)
+	assert.Empty(t, empty.Wrappers)
+}
+
+func TestCheckWrappers_BrokenFiles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires POSIX file modes")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "mvnw"), "#!/bin/sh\n", 0644)
+	writeFile(t, filepath.Join(root, mavenSpec.properties),
+		"distributionUrl=https://repo.maven.apache.org/maven2/org/apache/maven/apache-maven/3.9.6/apache-maven-3.9.5-bin.zip\n", 0644)
+	writeFile(t, filepath.Join(root, mavenSpec.jar), "version https://git-lfs.github.com/spec/v1\n", 0644)
+
+	report, err := CheckWrappers(root)
+	require.NoError(t, err)
+	assert.False(t, report.IsHealthy)
+	assert.Equal(t, []string{IssueNotExecutable, IssueCorruptJar, IssueVersionMismatch}, issueTypes(report))
+
+	for _, issue := range report.Issues {
+		switch issue.Type {
+		case IssueNotExecutable:
+			assert.Equal(t, "chmod +x mvnw", issue.FixCommand)
+		case IssueCorruptJar:
+			assert.Equal(t, "mvn -N wrapper:wrapper -Dmaven=3.9.5", issue.FixCommand)
+		case IssueVersionMismatch:
+			assert.Contains(t, issue.Message, "mixes versions 3.9.6 and 3.9.5")
+		}
+	}
+}
+
+func TestCheckWrappers_MissingFiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, gradleSpec.properties), "distributionUrl="+gradleURL+"\n", 0644)
+
+	report, err := CheckWrappers(root)
+	require.NoError(t, err)
+	require.Len(t, report.Issues, 2)
+	for _, issue := range report.Issues {
+		assert.Equal(t, IssueMissingFile, issue.Type)
+		assert.Equal(t, "gradle wrapper --gradle-version 8.5", issue.FixCommand)
+	}
+	assert.Contains(t, report.Issues[0].Message, "gradlew is missing")
+	assert.Contains(t, report.Issues[1].Message, "gradle/wrapper/gradle-wrapper.jar is missing")
+
+	// Maven's script-only wrapper has no jar
+	maven := t.TempDir()
+	writeFile(t, filepath.Join(maven, "mvnw"), "#!/bin/sh\n", 0755)
+	writeFile(t, filepath.Join(maven, mavenSpec.properties),
+		"distributionType=only-script\ndistributionUrl=https://repo.maven.apache.org/maven2/org/apache/maven/apache-maven/3.9.6/apache-maven-3.9.6-bin.zip\n", 0644)
+
+	report, err = CheckWrappers(maven)
+	require.NoError(t, err)
+	if runtime.GOOS != "windows" {
+		assert.True(t, report.IsHealthy, report.Issues)
+	}
+	assert.Equal(t, "3.9.6", report.Wrappers[0].Version)
+}
+
+func TestCheckWrappers_Versions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	root := gradleProject(t, "distributionUrl="+gradleURL+"\n")
+	writeFile(t, filepath.Join(root, "build.gradle"), "wrapper {\n    gradleVersion = '8.7'\n}\n", 0644)
+
+	report, err := CheckWrappers(root)
+	require.NoError(t, err)
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, IssueVersionMismatch, report.Issues[0].Type)
+	assert.Equal(t, "gradle wrapper uses 8.5 but the build declares 8.7", report.Issues[0].Message)
+	assert.Equal(t, "gradle wrapper --gradle-version 8.7", report.Issues[0].FixCommand)
+
+	invalid := gradleProject(t, "distributionUrl=services.gradle.org/gradle-8.5-bin.zip\n")
+	report, err = CheckWrappers(invalid)
+	require.NoError(t, err)
+	assert.Equal(t, []string{IssueDistributionURL}, issueTypes(report))
+}
+
+func TestCheckWrappers_Checksums(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GRADLE_USER_HOME", "")
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "gradlew"), "#!/bin/sh\n", 0755)
+	jar := filepath.Join(root, gradleSpec.jar)
+	writeJar(t, jar, gradleSpec.mainClass)
+	jarSum, err := sha256File(jar)
+	require.NoError(t, err)
+
+	// A cached distribution whose contents do not match the declared checksum
+	archive := filepath.Join(home, ".gradle", "wrapper", "dists", "gradle-8.5-bin", "abc123", "gradle-8.5-bin.zip")
+	writeFile(t, archive, "truncated", 0644)
+
+	writeFile(t, filepath.Join(root, gradleSpec.properties), "distributionUrl="+gradleURL+"\n"+
+		"distributionSha256Sum="+jarSum+"\n"+
+		"wrapperSha256Sum="+jarSum+"\n", 0644)
+
+	report, err := CheckWrappers(root)
+	require.NoError(t, err)
+	require.Equal(t, []string{IssueChecksum}, issueTypes(report))
+	assert.Equal(t, archive, report.Issues[0].File)
+	assert.Equal(t, "rm -rf "+filepath.Dir(archive), report.Issues[0].FixCommand)
+
+	// A jar that does not match wrapperSha256Sum
+	writeFile(t, filepath.Join(root, gradleSpec.properties), "distributionUrl="+gradleURL+"\n"+
+		"wrapperSha256Sum=0000000000000000000000000000000000000000000000000000000000000000\n", 0644)
+	report, err = CheckWrappers(root)
+	require.NoError(t, err)
+	require.Equal(t, []string{IssueChecksum}, issueTypes(report))
+	assert.Equal(t, jar, report.Issues[0].File)
+
+	// A malformed checksum
+	writeFile(t, filepath.Join(root, gradleSpec.properties), "distributionUrl="+gradleURL+"\ndistributionSha256Sum=abc\n", 0644)
+	report, err = CheckWrappers(root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{IssueChecksum}, issueTypes(report))
+	assert.Contains(t, report.Issues[0].Message, "malformed")
+}
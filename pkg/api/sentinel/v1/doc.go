@@ -0,0 +1,6 @@
+// Package sentinelv1 is the generated Go client and server code of the sentinel.v1 gRPC API,
+// defined in api/proto/sentinel/v1/sentinel.proto. Connect with NewSentinelServiceClient to a
+// server started with SENTINEL_TRANSPORT=grpc.
+package sentinelv1
+
+//go:generate protoc -I ../../../../api/proto --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative sentinel/v1/sentinel.proto
@@ -0,0 +1,315 @@
+package sentinel
+
+import (
+	"time"
+
+	"dev-env-sentinel/internal/auditor"
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/infra"
+	"dev-env-sentinel/internal/reconciler"
+	"dev-env-sentinel/internal/resources"
+	"dev-env-sentinel/internal/verifier"
+)
+
+// Ecosystem is a language or tool detected in a project
+type Ecosystem struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"` // 0.5 to 1; higher when more optional files are present
+}
+
+// VerifyOptions configure Verify
+type VerifyOptions struct {
+	Suite string // Check suite to run, e.g. "quick"; empty for all checks
+}
+
+// ReconcileOptions configure Reconcile
+type ReconcileOptions struct {
+	// Headroom overrides the free resources heavy fixes need; nil for the defaults
+	Headroom *Headroom
+	// FixPolicy holds fixes for confirmation or leaves them to a human, per issue severity;
+	// nil runs every fix
+	FixPolicy *FixPolicy
+	Plan      bool     // Run nothing; report what would run
+	Confirm   []string // Fingerprints of fixes held for confirmation to run
+}
+
+// FixPolicy sets per issue severity whether fixes run: "auto" (the default), "confirm" or
+// "never"
+type FixPolicy struct {
+	Warning  string
+	Error    string
+	Critical string
+}
+
+// Headroom sets the free memory, disk and CPU heavy fixes such as full rebuilds need
+type Headroom struct {
+	MinMemoryMB int
+	MinDiskMB   int
+	MaxLoad     float64 // Highest 1-minute load average per CPU
+	Refuse      bool    // Don't run heavy fixes without enough headroom, instead of warning
+}
+
+// policy converts the headroom to the engine's policy, with defaults for unset values
+func (h Headroom) policy() resources.Policy {
+	policy := resources.Default
+	if h.MinMemoryMB > 0 {
+		policy.MinMemoryMB = h.MinMemoryMB
+	}
+	if h.MinDiskMB > 0 {
+		policy.MinDiskMB = h.MinDiskMB
+	}
+	if h.MaxLoad > 0 {
+		policy.MaxLoad = h.MaxLoad
+	}
+	policy.Refuse = h.Refuse
+	return policy
+}
+
+// FreshnessReport is the result of Verify
+type FreshnessReport struct {
+	Healthy    bool            `json:"healthy"`
+	Suite      string          `json:"suite,omitempty"`
+	Issues     []Issue         `json:"issues"`
+	Ecosystems []string        `json:"ecosystems"` // Ecosystems verified
+	Commands   []CommandResult `json:"commands"`   // Status of each verification command run
+}
+
+// CommandResult is the outcome of one verification command
+type CommandResult struct {
+	Name      string        `json:"name"`
+	Ecosystem string        `json:"ecosystem"`
+	Status    string        `json:"status"` // "pass", "fail", "error" or "skipped"
+	Issues    int           `json:"issues,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// Issue is a problem Verify found
+type Issue struct {
+	Type       string   `json:"type"`     // e.g. "stale_build" or "stale_lock"
+	Severity   string   `json:"severity"` // "error" or "warning"
+	Message    string   `json:"message"`
+	File       string   `json:"file,omitempty"` // Project-relative file the issue points at
+	Fix        string   `json:"fix,omitempty"`  // Command that fixes the issue, if one is configured
+	Ecosystems []string `json:"ecosystems,omitempty"`
+	Doc        *Doc     `json:"doc,omitempty"` // Team runbook for the issue type, if the config links one
+}
+
+// Doc is a runbook a config links to an issue type: a URL, a markdown snippet, or both
+type Doc struct {
+	Title   string `json:"title,omitempty"`
+	URL     string `json:"url,omitempty"`
+	Snippet string `json:"snippet,omitempty"`
+}
+
+func newDoc(doc *config.IssueDoc) *Doc {
+	if doc == nil {
+		return nil
+	}
+	return &Doc{Title: doc.Title, URL: doc.URL, Snippet: doc.Snippet}
+}
+
+// InfrastructureReport is the result of CheckInfrastructure
+type InfrastructureReport struct {
+	Healthy    bool      `json:"healthy"`
+	Services   []Service `json:"services"`
+	Daemons    []Daemon  `json:"daemons,omitempty"`
+	Limits     []OSLimit `json:"limits,omitempty"`
+	Issues     []string  `json:"issues"` // Problems found, each followed by indented details and fixes
+	Ecosystems []string  `json:"ecosystems"`
+}
+
+// Service is a tool or service a project needs, such as a JDK or a database
+type Service struct {
+	Name            string   `json:"name"`
+	Running         bool     `json:"running"`
+	Healthy         bool     `json:"healthy"`
+	Version         string   `json:"version,omitempty"`
+	ExpectedVersion string   `json:"expected_version,omitempty"` // Constraint from the config, e.g. "17" or ">= 17"
+	Issue           string   `json:"issue,omitempty"`            // "service_version_mismatch" when the version doesn't match
+	Suggestions     []string `json:"suggestions,omitempty"`
+	LogHints        []string `json:"log_hints,omitempty"` // Error lines of the recent logs of an unhealthy service
+	Message         string   `json:"message"`
+	Ecosystems      []string `json:"ecosystems,omitempty"`
+}
+
+// Daemon is a running dev daemon, such as the Gradle daemon or a dev server
+type Daemon struct {
+	Name           string   `json:"name"`
+	PIDs           []int    `json:"pids"`
+	Healthy        bool     `json:"healthy"`
+	Problems       []string `json:"problems,omitempty"`
+	RestartCommand string   `json:"restart_command,omitempty"`
+}
+
+// OSLimit is an OS limit compared with what the project's tools need
+type OSLimit struct {
+	Name     string `json:"name"`
+	Value    int    `json:"value,omitempty"`
+	Required int    `json:"required,omitempty"`
+	Healthy  bool   `json:"healthy"`
+	Message  string `json:"message,omitempty"`
+	Fix      string `json:"fix,omitempty"`
+}
+
+// EnvReport is the result of Audit
+type EnvReport struct {
+	Healthy    bool                `json:"healthy"`
+	Missing    []string            `json:"missing"`
+	References []EnvReference      `json:"references"`
+	MissingIn  map[string][]string `json:"missing_in,omitempty"` // Missing variable -> ecosystems referencing it
+	Issues     []string            `json:"issues"`
+	Ecosystems []string            `json:"ecosystems"`
+}
+
+// EnvReference is a place where code or config reads an environment variable
+type EnvReference struct {
+	Name    string `json:"name"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	IsSet   bool   `json:"is_set"`
+	Source  string `json:"source,omitempty"`  // Where the value came from: "environment" or ".env"
+	Default string `json:"default,omitempty"` // Fallback supplied in code
+}
+
+// ReconcileReport is the result of Reconcile
+type ReconcileReport struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message"`
+	Fixed   []FixResult `json:"fixed"`
+	Failed  []FixResult `json:"failed"`
+	Planned []FixResult `json:"planned"` // Not run because read-only mode is on or Plan is set
+	Pending []FixResult `json:"pending"` // Held for confirmation by the fix policy
+	Manual  []FixResult `json:"manual"`  // Need a human; Message has instructions
+}
+
+// FixResult is the outcome of one fix
+type FixResult struct {
+	IssueType   string `json:"issue_type"`
+	Command     string `json:"command,omitempty"`
+	Message     string `json:"message"`
+	Error       string `json:"error,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"` // Identifies the command log of the fix, and confirms it
+	Severity    string `json:"severity,omitempty"`
+	Doc         *Doc   `json:"doc,omitempty"`
+}
+
+func newFreshnessReport(r *verifier.FreshnessReport, ecosystems []string) *FreshnessReport {
+	report := &FreshnessReport{Healthy: r.IsHealthy, Suite: r.Suite, Issues: []Issue{}, Ecosystems: ecosystems, Commands: []CommandResult{}}
+	for _, issue := range r.Issues {
+		converted := Issue{
+			Type:       issue.Type,
+			Severity:   issue.Severity,
+			Message:    issue.Message,
+			File:       issue.File,
+			Ecosystems: issue.Ecosystems,
+			Doc:        newDoc(issue.Doc),
+		}
+		if issue.FixAvailable {
+			converted.Fix = issue.FixCommand
+		}
+		report.Issues = append(report.Issues, converted)
+	}
+	for _, result := range r.Commands {
+		ecosystem := result.Ecosystem
+		if ecosystem == "" {
+			ecosystem = r.EcosystemID
+		}
+		report.Commands = append(report.Commands, CommandResult{
+			Name:      result.Name,
+			Ecosystem: ecosystem,
+			Status:    result.Status,
+			Issues:    result.Issues,
+			Error:     result.Error,
+			Duration:  result.Duration,
+		})
+	}
+	return report
+}
+
+func newInfrastructureReport(r *infra.InfrastructureReport, ecosystems []string) *InfrastructureReport {
+	report := &InfrastructureReport{Healthy: r.IsHealthy, Services: []Service{}, Issues: r.Issues, Ecosystems: ecosystems}
+	for _, s := range r.Services {
+		report.Services = append(report.Services, Service{
+			Name:            s.Name,
+			Running:         s.Running,
+			Healthy:         s.Healthy,
+			Version:         s.Version,
+			ExpectedVersion: s.ExpectedVersion,
+			Issue:           s.Issue,
+			Suggestions:     s.Suggestions,
+			LogHints:        s.LogHints,
+			Message:         s.Message,
+			Ecosystems:      s.Ecosystems,
+		})
+	}
+	for _, d := range r.Daemons {
+		daemon := Daemon{Name: d.Name, Healthy: d.Healthy, Problems: d.Problems, RestartCommand: d.RestartCommand}
+		for _, p := range d.Processes {
+			daemon.PIDs = append(daemon.PIDs, p.PID)
+		}
+		report.Daemons = append(report.Daemons, daemon)
+	}
+	for _, l := range r.Limits {
+		report.Limits = append(report.Limits, OSLimit{
+			Name:     l.Name,
+			Value:    l.Value,
+			Required: l.Required,
+			Healthy:  l.Healthy,
+			Message:  l.Message,
+			Fix:      l.Fix,
+		})
+	}
+	return report
+}
+
+func newEnvReport(r *auditor.EnvVarReport, ecosystems []string) *EnvReport {
+	report := &EnvReport{
+		Healthy:    r.IsHealthy,
+		Missing:    r.Missing,
+		References: []EnvReference{},
+		MissingIn:  r.MissingIn,
+		Issues:     r.Issues,
+		Ecosystems: ecosystems,
+	}
+	for _, ref := range r.References {
+		report.References = append(report.References, EnvReference{
+			Name:    ref.Name,
+			File:    ref.File,
+			Line:    ref.Line,
+			IsSet:   ref.IsSet,
+			Source:  ref.Source,
+			Default: ref.Default,
+		})
+	}
+	return report
+}
+
+func newReconcileReport(r *reconciler.ReconciliationReport) *ReconcileReport {
+	return &ReconcileReport{
+		Success: r.IsSuccess,
+		Message: r.Message,
+		Fixed:   newFixResults(r.Fixed),
+		Failed:  newFixResults(r.Failed),
+		Planned: newFixResults(r.Planned),
+		Pending: newFixResults(r.Pending),
+		Manual:  newFixResults(r.Manual),
+	}
+}
+
+func newFixResults(results []reconciler.FixResult) []FixResult {
+	converted := make([]FixResult, len(results))
+	for i, r := range results {
+		converted[i] = FixResult{
+			IssueType:   r.IssueType,
+			Command:     r.Command,
+			Message:     r.Message,
+			Error:       r.Error,
+			Fingerprint: r.Fingerprint,
+			Severity:    r.Severity,
+			Doc:         newDoc(r.Doc),
+		}
+	}
+	return converted
+}
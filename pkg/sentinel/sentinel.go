@@ -0,0 +1,268 @@
+// Package sentinel embeds the dev environment checks in other Go programs. It detects the
+// ecosystems of a project, verifies build freshness, audits environment variables, checks
+// infrastructure and reconciles issues, returning typed reports instead of MCP tool output.
+//
+// The types of this package are its stable API; they are converted from the engine's
+// internal reports, which may change between versions.
+//
+//	engine, err := sentinel.New(sentinel.Options{ConfigDir: "/opt/sentinel"})
+//	if err != nil {
+//		return err
+//	}
+//	report, err := engine.Verify(ctx, "/path/to/project", sentinel.VerifyOptions{Suite: "quick"})
+package sentinel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"dev-env-sentinel/internal/auditor"
+	"dev-env-sentinel/internal/config"
+	"dev-env-sentinel/internal/detector"
+	"dev-env-sentinel/internal/features"
+	"dev-env-sentinel/internal/infra"
+	"dev-env-sentinel/internal/license"
+	"dev-env-sentinel/internal/reconciler"
+	"dev-env-sentinel/internal/resources"
+	"dev-env-sentinel/internal/verifier"
+)
+
+// ErrNoEcosystems is returned when none of the loaded configs detect an ecosystem in a project
+var ErrNoEcosystems = errors.New("no ecosystems detected in project")
+
+// Options configure an Engine
+type Options struct {
+	// ConfigDir holds the config directory with the ecosystem configs. It defaults to
+	// SENTINEL_CONFIG_DIR, or the working directory.
+	ConfigDir string
+	// LicenseKey unlocks Pro features such as Reconcile. It defaults to SENTINEL_LICENSE_KEY,
+	// or the license the server saved.
+	LicenseKey string
+}
+
+// Engine runs checks against projects with a set of ecosystem configs. It is safe for
+// concurrent use.
+type Engine struct {
+	configs  []*config.EcosystemConfig
+	features *features.FeatureManager
+}
+
+// New loads the ecosystem configs and license and returns an engine
+func New(opts Options) (*Engine, error) {
+	configDir := opts.ConfigDir
+	if configDir == "" {
+		configDir = os.Getenv("SENTINEL_CONFIG_DIR")
+	}
+	if configDir == "" {
+		configDir = "."
+	}
+	configs, err := config.DiscoverEcosystemConfigs(configDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configs from %s: %w", configDir, err)
+	}
+
+	key := opts.LicenseKey
+	if key == "" {
+		key = os.Getenv("SENTINEL_LICENSE_KEY")
+	}
+	if key == "" {
+		key, _ = license.NewStorage().LoadLicense()
+	}
+	lic, _ := license.NewLicenseValidator().ValidateLicense(key)
+
+	return &Engine{configs: configs, features: features.NewFeatureManager(lic)}, nil
+}
+
+// Ecosystems lists the IDs of the loaded ecosystem configs
+func (e *Engine) Ecosystems() []string {
+	ids := make([]string, len(e.configs))
+	for i, cfg := range e.configs {
+		ids[i] = cfg.Ecosystem.ID
+	}
+	return ids
+}
+
+// Detect returns the ecosystems detected in a project
+func (e *Engine) Detect(ctx context.Context, projectRoot string) ([]Ecosystem, error) {
+	detected, err := detector.DetectEcosystems(ctx, projectRoot, e.configs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect ecosystems: %w", err)
+	}
+	ecosystems := make([]Ecosystem, len(detected))
+	for i, eco := range detected {
+		ecosystems[i] = Ecosystem{ID: eco.ID, Name: eco.Config.Ecosystem.Name, Confidence: eco.Confidence}
+	}
+	return ecosystems, nil
+}
+
+// Verify checks whether the build outputs, lock files and caches of a project are up to date
+func (e *Engine) Verify(ctx context.Context, projectRoot string, opts VerifyOptions) (*FreshnessReport, error) {
+	if opts.Suite != "" {
+		if err := config.ValidateSuite(e.configs, opts.Suite); err != nil {
+			return nil, err
+		}
+	}
+	ecosystems, err := e.detect(ctx, projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []*verifier.FreshnessReport
+	for _, eco := range ecosystems {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		scoped := *eco
+		scoped.Config = eco.Config.ForSuite(opts.Suite)
+		report, err := verifier.VerifyBuildFreshness(ctx, projectRoot, &scoped)
+		if err != nil {
+			continue
+		}
+		report.Suite = opts.Suite
+		reports = append(reports, report)
+	}
+	if len(reports) == 0 {
+		return &FreshnessReport{Healthy: true, Suite: opts.Suite}, nil
+	}
+	return newFreshnessReport(verifier.CombineReports(reports), reportedIDs(reports)), nil
+}
+
+// CheckInfrastructure checks the services, dev daemons and OS limits a project needs
+func (e *Engine) CheckInfrastructure(ctx context.Context, projectRoot string) (*InfrastructureReport, error) {
+	ecosystems, err := e.detect(ctx, projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var reported []string
+	var reports []*infra.InfrastructureReport
+	var checked []*config.EcosystemConfig
+	for _, eco := range ecosystems {
+		report, err := infra.CheckInfrastructure(ctx, eco.Config)
+		if err != nil {
+			continue
+		}
+		daemons, err := infra.CheckDaemons(ctx, projectRoot, eco.Config)
+		if err != nil {
+			report.Issues = append(report.Issues, fmt.Sprintf("Daemon check failed: %v", err))
+		}
+		report.AddDaemons(daemons)
+		report.AddServiceLogs(ctx, projectRoot, eco.Config)
+		reported = append(reported, eco.ID)
+		reports = append(reports, report)
+		checked = append(checked, eco.Config)
+	}
+	if len(reports) == 0 {
+		return &InfrastructureReport{Healthy: true}, nil
+	}
+
+	combined := infra.CombineReports(reported, reports)
+	combined.AddLimits(infra.CheckLimits(ctx, projectRoot, checked))
+	return newInfrastructureReport(combined, reported), nil
+}
+
+// Audit finds the environment variables a project's code and config files reference and
+// reports those that are not set
+func (e *Engine) Audit(ctx context.Context, projectRoot string) (*EnvReport, error) {
+	ecosystems, err := e.detect(ctx, projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	reports, reported, err := e.audit(ctx, projectRoot, ecosystems)
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return &EnvReport{Healthy: true}, nil
+	}
+	return newEnvReport(auditor.CombineEnvVarReports(reported, reports), reported), nil
+}
+
+// Reconcile runs the configured fixes for the issues Verify finds and adds missing
+// environment variables with safe defaults to the project's .env. It needs a Pro license.
+// With SENTINEL_READ_ONLY set, fixes are planned but not run.
+func (e *Engine) Reconcile(ctx context.Context, projectRoot string, opts ReconcileOptions) (*ReconcileReport, error) {
+	if err := e.features.RequireFeature("reconcile_environment"); err != nil {
+		return nil, err
+	}
+	ecosystems, err := e.detect(ctx, projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Headroom != nil {
+		ctx = resources.WithPolicy(ctx, opts.Headroom.policy())
+	}
+	if opts.FixPolicy != nil {
+		ctx = reconciler.WithFixPolicy(ctx, reconciler.FixPolicy(*opts.FixPolicy))
+	}
+	ctx = reconciler.WithApproval(ctx, reconciler.Approval{Plan: opts.Plan, Confirmed: opts.Confirm})
+
+	var issues []verifier.Issue
+	for _, eco := range ecosystems {
+		report, err := verifier.VerifyBuildFreshness(ctx, projectRoot, eco)
+		if err != nil {
+			continue
+		}
+		issues = append(issues, report.Issues...)
+	}
+	envReports, _, err := e.audit(ctx, projectRoot, ecosystems)
+	if err != nil {
+		return nil, err
+	}
+
+	report := reconciler.NewReport()
+	if len(issues) > 0 {
+		report, err = reconciler.ReconcileEnvironment(ctx, projectRoot, issues, ecosystems[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to reconcile environment: %w", err)
+		}
+	}
+	if err := reconciler.ReconcileEnvVars(ctx, projectRoot, envReports, report); err != nil {
+		return nil, fmt.Errorf("failed to reconcile environment variables: %w", err)
+	}
+	report.AttachDocs(ecosystems)
+	report.Summarize()
+	return newReconcileReport(report), nil
+}
+
+// detect detects a project's ecosystems, returning ErrNoEcosystems if there are none
+func (e *Engine) detect(ctx context.Context, projectRoot string) ([]*detector.DetectedEcosystem, error) {
+	ecosystems, err := detector.DetectEcosystems(ctx, projectRoot, e.configs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect ecosystems: %w", err)
+	}
+	if len(ecosystems) == 0 {
+		return nil, ErrNoEcosystems
+	}
+	return ecosystems, nil
+}
+
+// audit audits the environment variables of each ecosystem
+func (e *Engine) audit(ctx context.Context, projectRoot string, ecosystems []*detector.DetectedEcosystem) ([]*auditor.EnvVarReport, []string, error) {
+	var reports []*auditor.EnvVarReport
+	var reported []string
+	for _, eco := range ecosystems {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		report, err := auditor.AuditEnvironmentVariables(ctx, projectRoot, eco.Config)
+		if err != nil {
+			continue
+		}
+		reports = append(reports, report)
+		reported = append(reported, eco.ID)
+	}
+	return reports, reported, nil
+}
+
+// reportedIDs returns the ecosystems of freshness reports
+func reportedIDs(reports []*verifier.FreshnessReport) []string {
+	ids := make([]string, len(reports))
+	for i, report := range reports {
+		ids[i] = report.EcosystemID
+	}
+	return ids
+}
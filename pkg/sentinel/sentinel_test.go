@@ -0,0 +1,128 @@
+package sentinel
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+ecosystem:
+  id: "test"
+  name: "Test"
+  detection:
+    required_files: ["app.conf"]
+  manifest:
+    primary_file: "app.conf"
+  verification:
+    build_freshness:
+      commands:
+        - name: "check_conf_vs_output"
+          type: "timestamp_compare"
+          source: "app.conf"
+          target: "out/app.bin"
+`
+
+// newTestEngine returns an engine with the test config and without a Pro license
+func newTestEngine(t *testing.T) *Engine {
+	configDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "test.yaml"), []byte(testConfig), 0644))
+
+	engine, err := New(Options{ConfigDir: configDir, LicenseKey: "free-invalid-lifetime"})
+	require.NoError(t, err)
+	return engine
+}
+
+// newTestProject returns a project whose output is older than its manifest
+func newTestProject(t *testing.T) string {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "out"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "out", "app.bin"), []byte("bin"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "app.conf"), []byte("name = app\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "main.py"), []byte("import os\nkey = os.getenv(\"SENTINEL_TEST_UNSET_VAR\")\n"), 0644))
+
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(root, "out", "app.bin"), old, old))
+	return root
+}
+
+func TestNew_ConfigDirNotFound(t *testing.T) {
+	_, err := New(Options{ConfigDir: "/nonexistent/directory"})
+	assert.Error(t, err)
+}
+
+func TestEngine_Detect(t *testing.T) {
+	engine := newTestEngine(t)
+	assert.Equal(t, []string{"test"}, engine.Ecosystems())
+
+	ecosystems, err := engine.Detect(context.Background(), newTestProject(t))
+	require.NoError(t, err)
+	require.Len(t, ecosystems, 1)
+	assert.Equal(t, "test", ecosystems[0].ID)
+	assert.Equal(t, "Test", ecosystems[0].Name)
+
+	ecosystems, err = engine.Detect(context.Background(), t.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, ecosystems)
+}
+
+func TestEngine_Verify(t *testing.T) {
+	engine := newTestEngine(t)
+	root := newTestProject(t)
+
+	report, err := engine.Verify(context.Background(), root, VerifyOptions{})
+	require.NoError(t, err)
+	assert.False(t, report.Healthy)
+	assert.Equal(t, []string{"test"}, report.Ecosystems)
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, "stale_build", report.Issues[0].Type)
+	assert.Equal(t, "app.conf", report.Issues[0].File)
+
+	// Rebuilding the output makes the project fresh again
+	now := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(root, "out", "app.bin"), now, now))
+	report, err = engine.Verify(context.Background(), root, VerifyOptions{})
+	require.NoError(t, err)
+	assert.True(t, report.Healthy)
+	assert.Empty(t, report.Issues)
+}
+
+func TestEngine_NoEcosystems(t *testing.T) {
+	engine := newTestEngine(t)
+
+	_, err := engine.Verify(context.Background(), t.TempDir(), VerifyOptions{})
+	assert.ErrorIs(t, err, ErrNoEcosystems)
+	_, err = engine.Audit(context.Background(), t.TempDir())
+	assert.ErrorIs(t, err, ErrNoEcosystems)
+}
+
+func TestEngine_Audit(t *testing.T) {
+	engine := newTestEngine(t)
+
+	report, err := engine.Audit(context.Background(), newTestProject(t))
+	require.NoError(t, err)
+	assert.False(t, report.Healthy)
+	assert.Contains(t, report.Missing, "SENTINEL_TEST_UNSET_VAR")
+	require.NotEmpty(t, report.References)
+	assert.Equal(t, "main.py", filepath.Base(report.References[0].File))
+}
+
+func TestEngine_ReconcileNeedsLicense(t *testing.T) {
+	engine := newTestEngine(t)
+
+	_, err := engine.Reconcile(context.Background(), newTestProject(t), ReconcileOptions{})
+	assert.Error(t, err)
+}
+
+func TestHeadroomPolicy(t *testing.T) {
+	policy := Headroom{MinMemoryMB: 4096, Refuse: true}.policy()
+	assert.Equal(t, 4096, policy.MinMemoryMB)
+	assert.Equal(t, 2048, policy.MinDiskMB)
+	assert.Equal(t, 1.0, policy.MaxLoad)
+	assert.True(t, policy.Refuse)
+}
@@ -3,72 +3,71 @@
 const { execSync } = require('child_process');
 const fs = require('fs');
 const path = require('path');
+const { PLATFORMS, currentPlatform, byTarget } = require('./platforms');
 
-const PLATFORMS = {
-  windows: { GOOS: 'windows', GOARCH: 'amd64', ext: '.exe' },
-  linux: { GOOS: 'linux', GOARCH: 'amd64', ext: '' },
-  'linux-arm64': { GOOS: 'linux', GOARCH: 'arm64', ext: '' },
-  'windows-arm64': { GOOS: 'windows', GOARCH: 'arm64', ext: '.exe' }
-};
+const ROOT = path.join(__dirname, '..');
+const { version } = require('../package.json');
 
-function buildForPlatform(platform) {
-  const config = PLATFORMS[platform];
-  if (!config) {
-    console.error(`Unknown platform: ${platform}`);
-    process.exit(1);
-  }
+// buildForPlatform compiles the binary of a platform into outDir
+function buildForPlatform(platform, outDir = path.join(ROOT, 'bin')) {
+  console.log(`Building for ${platform.target}...`);
 
-  console.log(`Building for ${platform}...`);
-  
-  const binDir = path.join(__dirname, '..', 'bin');
-  if (!fs.existsSync(binDir)) {
-    fs.mkdirSync(binDir, { recursive: true });
+  if (!fs.existsSync(outDir)) {
+    fs.mkdirSync(outDir, { recursive: true });
   }
-
-  const outputName = `sentinel-${platform}${config.ext}`;
-  const outputPath = path.join(binDir, outputName);
+  const outputPath = path.join(outDir, platform.binary);
 
   const env = {
     ...process.env,
-    GOOS: config.GOOS,
-    GOARCH: config.GOARCH,
+    GOOS: platform.GOOS,
+    GOARCH: platform.GOARCH,
     CGO_ENABLED: '0'
   };
 
   try {
     execSync(
-      `go build -ldflags="-s -w" -o "${outputPath}" ./cmd/sentinel`,
+      `go build -trimpath -ldflags="-s -w -X dev-env-sentinel/internal/buildinfo.Version=${version}" -o "${outputPath}" ./cmd/sentinel`,
       {
         env,
         stdio: 'inherit',
-        cwd: path.join(__dirname, '..')
+        cwd: ROOT
       }
     );
-    console.log(`✓ Built ${outputName}`);
+    console.log(`✓ Built ${platform.binary}`);
   } catch (error) {
-    console.error(`✗ Failed to build for ${platform}:`, error.message);
+    console.error(`✗ Failed to build for ${platform.target}:`, error.message);
     process.exit(1);
   }
+  return outputPath;
 }
 
-function buildAll() {
+function buildAll(outDir) {
   console.log('Building for all platforms...');
-  Object.keys(PLATFORMS).forEach(platform => {
-    buildForPlatform(platform);
-  });
+  const outputs = Object.values(PLATFORMS).map(platform => buildForPlatform(platform, outDir));
   console.log('✓ All builds complete');
+  return outputs;
 }
 
+module.exports = { buildForPlatform, buildAll };
+
 // Main
-const target = process.argv[2] || 'all';
+if (require.main === module) {
+  const target = process.argv[2] || 'current';
 
-if (target === 'all') {
-  buildAll();
-} else if (PLATFORMS[target]) {
-  buildForPlatform(target);
-} else {
-  console.error(`Unknown target: ${target}`);
-  console.error(`Available targets: all, ${Object.keys(PLATFORMS).join(', ')}`);
-  process.exit(1);
+  if (target === 'all') {
+    buildAll();
+  } else if (target === 'current') {
+    const platform = currentPlatform();
+    if (!platform) {
+      console.error(`No build target for ${process.platform} ${process.arch}`);
+      process.exit(1);
+    }
+    buildForPlatform(platform);
+  } else if (byTarget(target)) {
+    buildForPlatform(byTarget(target));
+  } else {
+    console.error(`Unknown target: ${target}`);
+    console.error(`Available targets: all, current, ${Object.values(PLATFORMS).map(p => p.target).join(', ')}`);
+    process.exit(1);
+  }
 }
-
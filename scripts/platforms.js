@@ -0,0 +1,22 @@
+// Platforms the Go binary is built and released for, keyed by Node's os.platform()-os.arch()
+
+const os = require('os');
+
+const PLATFORMS = {
+  'win32-x64': { target: 'windows', GOOS: 'windows', GOARCH: 'amd64', binary: 'sentinel-windows.exe' },
+  'win32-arm64': { target: 'windows-arm64', GOOS: 'windows', GOARCH: 'arm64', binary: 'sentinel-windows-arm64.exe' },
+  'linux-x64': { target: 'linux', GOOS: 'linux', GOARCH: 'amd64', binary: 'sentinel-linux' },
+  'linux-arm64': { target: 'linux-arm64', GOOS: 'linux', GOARCH: 'arm64', binary: 'sentinel-linux-arm64' }
+};
+
+// currentPlatform returns the platform of this machine, or undefined when no binary is released for it
+function currentPlatform() {
+  return PLATFORMS[`${os.platform()}-${os.arch()}`];
+}
+
+// byTarget returns the platform with a build target name such as "linux-arm64"
+function byTarget(target) {
+  return Object.values(PLATFORMS).find(p => p.target === target);
+}
+
+module.exports = { PLATFORMS, currentPlatform, byTarget };
@@ -1,49 +1,137 @@
 #!/usr/bin/env node
 
+// Installs the binary for this platform into bin/. Locally built binaries are used as they are;
+// otherwise the binary of the package version is downloaded from its GitHub release and checked
+// against the release's SHA256SUMS, whose signature (SHA256SUMS.sig) must verify with the
+// release key, so a tampered release or mirror can't replace both.
+//
+// SENTINEL_BINARY_HOST overrides the download location (a mirror with the same layout), and
+// SENTINEL_SKIP_DOWNLOAD=true skips the download, e.g. for offline installs that build from source.
+
+const crypto = require('crypto');
 const fs = require('fs');
+const http = require('http');
+const https = require('https');
 const path = require('path');
-const os = require('os');
-
-// Determine the correct binary based on platform
-const platform = os.platform();
-const arch = os.arch();
-
-let binaryName;
-if (platform === 'win32') {
-  if (arch === 'arm64') {
-    binaryName = 'sentinel-windows-arm64.exe';
-  } else {
-    binaryName = 'sentinel-windows.exe';
-  }
-} else if (platform === 'linux') {
-  if (arch === 'arm64') {
-    binaryName = 'sentinel-linux-arm64';
-  } else {
-    binaryName = 'sentinel-linux';
+const { currentPlatform } = require('./platforms');
+
+const { version, repository } = require('../package.json');
+
+// Progress goes to stderr: the wrapper installs on first run, when stdout carries MCP messages
+const log = message => process.stderr.write(message + '\n');
+
+const DEFAULT_HOST = `${repository.url.replace(/^git\+/, '').replace(/\.git$/, '')}/releases/download`;
+
+// The maintainers' Ed25519 public key, license.EmbeddedPublicKey in the Go code, which
+// scripts/release.js signs the checksums with
+const RELEASE_PUBLIC_KEY = '6ZkjmyVNULTqSQx+S4Y7cd/LAecIMkmk4Wu1yD81730=';
+
+// Signed with the checksums, matching SIGNATURE_DOMAIN in scripts/release.js
+const SIGNATURE_DOMAIN = 'sentinel-release-checksums\n';
+
+// SPKI header of an Ed25519 public key, followed by its 32 bytes
+const ED25519_SPKI_PREFIX = Buffer.from('302a300506032b6570032100', 'hex');
+
+// verifyChecksums checks the signature of SHA256SUMS against the release key
+function verifyChecksums(sums, signature) {
+  const publicKey = crypto.createPublicKey({
+    key: Buffer.concat([ED25519_SPKI_PREFIX, Buffer.from(RELEASE_PUBLIC_KEY, 'base64')]),
+    format: 'der',
+    type: 'spki',
+  });
+  const sig = Buffer.from(signature.toString().trim(), 'base64');
+  if (!crypto.verify(null, Buffer.concat([Buffer.from(SIGNATURE_DOMAIN), sums]), publicKey, sig)) {
+    throw new Error('SHA256SUMS is not signed with the release key');
   }
-} else {
-  console.warn(`Unsupported platform: ${platform} ${arch}`);
-  console.warn('This package is designed for Windows and Linux only.');
-  process.exit(0);
 }
 
-const binDir = path.join(__dirname, '..', 'bin');
-const binaryPath = path.join(binDir, binaryName);
+// get fetches a URL into a buffer, following redirects
+function get(url, redirects = 5) {
+  return new Promise((resolve, reject) => {
+    const client = url.startsWith('http:') ? http : https;
+    client.get(url, { headers: { 'User-Agent': 'dev-env-sentinel-postinstall' } }, res => {
+      if (res.statusCode >= 300 && res.statusCode < 400 && res.headers.location && redirects > 0) {
+        res.resume();
+        resolve(get(new URL(res.headers.location, url).toString(), redirects - 1));
+        return;
+      }
+      if (res.statusCode !== 200) {
+        res.resume();
+        reject(new Error(`GET ${url}: HTTP ${res.statusCode}`));
+        return;
+      }
+      const chunks = [];
+      res.on('data', chunk => chunks.push(chunk));
+      res.on('end', () => resolve(Buffer.concat(chunks)));
+    }).on('error', reject);
+  });
+}
+
+// download fetches the release binary and verifies its checksum and the checksums' signature
+async function download(binaryName) {
+  const host = (process.env.SENTINEL_BINARY_HOST || DEFAULT_HOST).replace(/\/$/, '');
+  const base = `${host}/v${version}`;
 
-if (!fs.existsSync(binaryPath)) {
-  console.error(`Binary not found: ${binaryPath}`);
-  console.error('Please run "npm run build" first.');
-  process.exit(1);
+  log(`Downloading ${binaryName} v${version}...`);
+  const [binary, sums, signature] = await Promise.all([
+    get(`${base}/${binaryName}`), get(`${base}/SHA256SUMS`), get(`${base}/SHA256SUMS.sig`),
+  ]);
+  verifyChecksums(sums, signature);
+
+  const expected = sums.toString().split('\n')
+    .map(line => line.trim().split(/\s+/))
+    .find(([, name]) => name === binaryName);
+  if (!expected) {
+    throw new Error(`${binaryName} is not listed in SHA256SUMS`);
+  }
+  const actual = crypto.createHash('sha256').update(binary).digest('hex');
+  if (actual !== expected[0]) {
+    throw new Error(`checksum mismatch for ${binaryName}: expected ${expected[0]}, got ${actual}`);
+  }
+  return binary;
 }
 
-// Make binary executable on Linux
-if (platform !== 'win32') {
-  try {
-    fs.chmodSync(binaryPath, 0o755);
-  } catch (error) {
-    console.warn(`Warning: Could not make binary executable: ${error.message}`);
+async function main() {
+  const platform = currentPlatform();
+  if (!platform) {
+    console.warn(`Unsupported platform: ${process.platform} ${process.arch}`);
+    console.warn('This package is designed for Windows and Linux only.');
+    return;
+  }
+
+  const binDir = path.join(__dirname, '..', 'bin');
+  const binaryPath = path.join(binDir, platform.binary);
+
+  if (!fs.existsSync(binaryPath)) {
+    if (process.env.SENTINEL_SKIP_DOWNLOAD === 'true') {
+      console.warn(`Skipping download of ${platform.binary}; run "npm run build" to build it.`);
+      return;
+    }
+    const binary = await download(platform.binary);
+    // Write to a temporary file first so an interrupted install never leaves a partial binary
+    const tmpPath = `${binaryPath}.download`;
+    fs.writeFileSync(tmpPath, binary);
+    fs.renameSync(tmpPath, binaryPath);
+  }
+
+  // Make binary executable on Linux
+  if (process.platform !== 'win32') {
+    try {
+      fs.chmodSync(binaryPath, 0o755);
+    } catch (error) {
+      console.warn(`Warning: Could not make binary executable: ${error.message}`);
+    }
   }
+
+  log(`✓ Postinstall complete. Binary: ${platform.binary}`);
 }
 
-console.log(`✓ Postinstall complete. Binary: ${binaryName}`);
+module.exports = { main };
 
+if (require.main === module) {
+  main().catch(error => {
+    console.error(`✗ Failed to install the sentinel binary: ${error.message}`);
+    console.error('Set SENTINEL_BINARY_HOST to a mirror, or build from source with "npm run build".');
+    process.exit(1);
+  });
+}
@@ -0,0 +1,58 @@
+#!/usr/bin/env node
+
+// Builds the binaries of a release into dist/ with a SHA256SUMS file and its signature,
+// SHA256SUMS.sig. They are uploaded to the GitHub release tagged v<package version>, where
+// postinstall and sentinel self-update download them from. The checksums are signed with the
+// private key in SENTINEL_RELEASE_PRIVATE_KEY, a file created by sentinel license keygen.
+
+const crypto = require('crypto');
+const fs = require('fs');
+const path = require('path');
+const { buildAll } = require('./build');
+
+const ROOT = path.join(__dirname, '..');
+const DIST = path.join(ROOT, 'dist');
+
+const { version } = require('../package.json');
+
+// Signed with the checksums, matching signatureDomain in internal/selfupdate
+const SIGNATURE_DOMAIN = 'sentinel-release-checksums\n';
+
+// PKCS#8 header of an Ed25519 private key, followed by its 32-byte seed
+const ED25519_PKCS8_PREFIX = Buffer.from('302e020100300506032b657004220420', 'hex');
+
+// Loads the Ed25519 private key, base64 encoded as by sentinel license keygen: the seed
+// followed by the public key
+function loadPrivateKey() {
+  const file = process.env.SENTINEL_RELEASE_PRIVATE_KEY;
+  if (!file) {
+    throw new Error('SENTINEL_RELEASE_PRIVATE_KEY must point at the release signing key');
+  }
+  const key = Buffer.from(fs.readFileSync(file, 'utf8').trim(), 'base64');
+  if (key.length !== 64) {
+    throw new Error(`${file} is not an Ed25519 private key`);
+  }
+  return crypto.createPrivateKey({
+    key: Buffer.concat([ED25519_PKCS8_PREFIX, key.subarray(0, 32)]),
+    format: 'der',
+    type: 'pkcs8',
+  });
+}
+
+// Load the key first, so a missing key doesn't waste a build
+const privateKey = loadPrivateKey();
+
+fs.rmSync(DIST, { recursive: true, force: true });
+const outputs = buildAll(DIST);
+
+const sums = outputs.map(output => {
+  const hash = crypto.createHash('sha256').update(fs.readFileSync(output)).digest('hex');
+  return `${hash}  ${path.basename(output)}`;
+});
+const sumsFile = Buffer.from(sums.join('\n') + '\n');
+fs.writeFileSync(path.join(DIST, 'SHA256SUMS'), sumsFile);
+const signature = crypto.sign(null, Buffer.concat([Buffer.from(SIGNATURE_DOMAIN), sumsFile]), privateKey);
+fs.writeFileSync(path.join(DIST, 'SHA256SUMS.sig'), signature.toString('base64') + '\n');
+
+console.log(`✓ Release v${version} ready in dist/`);
+console.log(`  Upload with: gh release create v${version} dist/*`);